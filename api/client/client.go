@@ -11,6 +11,7 @@ func init() {
 	context.RegisterCustomKey(transactionHeaderKey, context.CustomHeaderKey)
 	context.RegisterCustomKey(instanceIDHeaderKey, context.CustomHeaderKey)
 	context.RegisterCustomKey(localDevicesHeaderKey, context.CustomHeaderKey)
+	context.RegisterCustomKey(localDeviceInfoHeaderKey, context.CustomHeaderKey)
 }
 
 // Client is the libStorage API client.
@@ -20,16 +21,53 @@ type client struct {
 	logRequests  bool
 	logResponses bool
 	serverName   string
+	retryPolicy  *RetryPolicy
+	retryBudget  *retryBudget
+	interceptors []Interceptor
+	etagCache    *etagCache
+	adminToken   string
+}
+
+// Option is an option used to configure the API client.
+type Option func(c *client)
+
+// WithRetryPolicy returns an option that configures the API client to retry
+// failed requests according to the provided policy.
+func WithRetryPolicy(p *RetryPolicy) Option {
+	return func(c *client) {
+		c.retryPolicy = p
+		if p != nil {
+			c.retryBudget = newRetryBudget(p.Budget)
+		}
+	}
+}
+
+// WithAdminToken returns an option that configures the API client to send
+// the server's admin token with the requests that only administrators may
+// make.
+func WithAdminToken(token string) Option {
+	return func(c *client) {
+		c.adminToken = token
+	}
 }
 
 // New returns a new API client.
-func New(host string, transport *http.Transport) types.APIClient {
-	return &client{
+func New(
+	host string,
+	transport *http.Transport,
+	opts ...Option) types.APIClient {
+
+	c := &client{
 		Client: http.Client{
 			Transport: transport,
 		},
-		host: host,
+		host:         host,
+		interceptors: registeredInterceptors(),
+	}
+	for _, o := range opts {
+		o(c)
 	}
+	return c
 }
 
 func (c *client) ServerName() string {
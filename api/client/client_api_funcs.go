@@ -5,7 +5,9 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io"
+	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/codedellemc/libstorage/api/types"
 )
@@ -83,6 +85,17 @@ func (c *client) ServiceInspect(
 	return reply, nil
 }
 
+func (c *client) ServiceCapabilities(
+	ctx types.Context, name string) (*types.StorageCapabilities, error) {
+
+	reply := &types.StorageCapabilities{}
+	url := fmt.Sprintf("/services/%s/capabilities", name)
+	if _, err := c.httpGet(ctx, url, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
 func (c *client) Volumes(
 	ctx types.Context,
 	attachments types.VolumeAttachmentsTypes) (types.ServiceVolumeMap, error) {
@@ -95,6 +108,28 @@ func (c *client) Volumes(
 	return reply, nil
 }
 
+func (c *client) AllVolumes(
+	ctx types.Context,
+	attachments types.VolumeAttachmentsTypes) (types.ServiceVolumeMap, error) {
+
+	reply := types.ServiceVolumeMap{}
+	url := fmt.Sprintf("/volumes?attachments=%v&managed=false", attachments)
+	if _, err := c.httpGet(ctx, url, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *client) RecycledVolumes(
+	ctx types.Context) (types.ServiceVolumeMap, error) {
+
+	reply := types.ServiceVolumeMap{}
+	if _, err := c.httpGet(ctx, "/volumes?deleted=true", &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
 func (c *client) VolumesByService(
 	ctx types.Context,
 	service string,
@@ -149,6 +184,30 @@ func (c *client) VolumeCreateFromSnapshot(
 	return &reply, nil
 }
 
+func (c *client) VolumeStats(
+	ctx types.Context,
+	service, volumeID string) (*types.VolumeStats, error) {
+
+	reply := types.VolumeStats{}
+	if _, err := c.httpGet(ctx, fmt.Sprintf(
+		"/volumes/%s/%s/stats", service, volumeID), &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (c *client) VolumeEvents(
+	ctx types.Context,
+	service, volumeID string) ([]*types.Event, error) {
+
+	reply := []*types.Event{}
+	if _, err := c.httpGet(ctx, fmt.Sprintf(
+		"/volumes/%s/%s/events", service, volumeID), &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
 func (c *client) VolumeCopy(
 	ctx types.Context,
 	service, volumeID string,
@@ -163,6 +222,70 @@ func (c *client) VolumeCopy(
 	return &reply, nil
 }
 
+func (c *client) VolumeImport(
+	ctx types.Context,
+	service string,
+	request *types.VolumeImportRequest) (*types.Volume, error) {
+
+	reply := types.Volume{}
+	if _, err := c.httpPost(ctx,
+		fmt.Sprintf("/volumes/%s?import", service),
+		request, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (c *client) VolumeUnmanage(
+	ctx types.Context,
+	service, volumeID string,
+	force bool) error {
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "/volumes/%s/%s?unmanage", service, volumeID)
+	if force {
+		fmt.Fprintf(buf, "&force")
+	}
+	if _, err := c.httpPost(ctx, buf.String(), nil, nil); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *client) VolumeResize(
+	ctx types.Context,
+	service, volumeID string,
+	size int64) (*types.Volume, error) {
+
+	reply := types.Volume{}
+	if _, err := c.httpPost(ctx,
+		fmt.Sprintf("/volumes/%s/%s?resize&size=%d", service, volumeID, size),
+		nil, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (c *client) VolumeRetype(
+	ctx types.Context,
+	service, volumeID, volumeType, migrationPolicy string) (
+	*types.Volume, error) {
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "/volumes/%s/%s?retype&type=%s",
+		service, volumeID, url.QueryEscape(volumeType))
+	if migrationPolicy != "" {
+		fmt.Fprintf(buf, "&%s=%s",
+			types.VolumeRetypeOptMigrationPolicy,
+			url.QueryEscape(migrationPolicy))
+	}
+	reply := types.Volume{}
+	if _, err := c.httpPost(ctx, buf.String(), nil, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
 func (c *client) VolumeRemove(
 	ctx types.Context,
 	service, volumeID string,
@@ -179,16 +302,64 @@ func (c *client) VolumeRemove(
 	return nil
 }
 
+func (c *client) VolumePurge(
+	ctx types.Context,
+	service, volumeID string) error {
+
+	if _, err := c.httpDelete(ctx, fmt.Sprintf(
+		"/volumes/%s/%s?purge", service, volumeID), nil); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *client) VolumeUndelete(
+	ctx types.Context,
+	service, volumeID string) (*types.Volume, error) {
+
+	reply := types.Volume{}
+	if _, err := c.httpPost(ctx, fmt.Sprintf(
+		"/volumes/%s/%s?undelete", service, volumeID), nil, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (c *client) VolumeForceDetach(
+	ctx types.Context,
+	service, volumeID, instanceID string) (*types.Volume, error) {
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "/volumes/%s/%s/attachments?force", service, volumeID)
+	if instanceID != "" {
+		fmt.Fprintf(buf, "&instanceID=%s", url.QueryEscape(instanceID))
+	}
+	if c.adminToken != "" {
+		fmt.Fprintf(buf, "&admin=%s", url.QueryEscape(c.adminToken))
+	}
+	reply := types.Volume{}
+	if _, err := c.httpDelete(ctx, buf.String(), &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
 func (c *client) VolumeAttach(
 	ctx types.Context,
 	service string,
 	volumeID string,
 	request *types.VolumeAttachRequest) (*types.Volume, string, error) {
 
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "/volumes/%s/%s?attach", service, volumeID)
+	if wait, _ := request.Opts["wait"].(bool); wait {
+		buf.WriteString("&wait=true")
+		if timeout, _ := request.Opts["timeout"].(string); timeout != "" {
+			fmt.Fprintf(buf, "&timeout=%s", url.QueryEscape(timeout))
+		}
+	}
 	reply := types.VolumeAttachResponse{}
-	if _, err := c.httpPost(ctx,
-		fmt.Sprintf("/volumes/%s/%s?attach",
-			service, volumeID), request, &reply); err != nil {
+	if _, err := c.httpPost(ctx, buf.String(), request, &reply); err != nil {
 		return nil, "", err
 	}
 	return reply.Volume, reply.AttachToken, nil
@@ -209,6 +380,20 @@ func (c *client) VolumeDetach(
 	return &reply, nil
 }
 
+func (c *client) VolumeMountReport(
+	ctx types.Context,
+	service string,
+	volumeID string,
+	request *types.VolumeMountReportRequest) error {
+
+	if _, err := c.httpPost(ctx,
+		fmt.Sprintf("/volumes/%s/%s?mountReport",
+			service, volumeID), request, nil); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (c *client) VolumeDetachAll(
 	ctx types.Context,
 	request *types.VolumeDetachRequest) (types.ServiceVolumeMap, error) {
@@ -250,6 +435,20 @@ func (c *client) VolumeSnapshot(
 	return &reply, nil
 }
 
+func (c *client) SnapshotGroupCreate(
+	ctx types.Context,
+	service string,
+	request *types.SnapshotGroupCreateRequest) (*types.SnapshotGroup, error) {
+
+	reply := types.SnapshotGroup{}
+	if _, err := c.httpPost(ctx,
+		fmt.Sprintf("/snapshot-groups/%s", service),
+		request, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
 func (c *client) Snapshots(
 	ctx types.Context) (types.ServiceSnapshotMap, error) {
 
@@ -309,6 +508,52 @@ func (c *client) SnapshotCopy(
 	return &reply, nil
 }
 
+func (c *client) Events(
+	ctx types.Context,
+	service string,
+	since int64,
+	wait time.Duration) ([]*types.Event, error) {
+
+	reply := []*types.Event{}
+	url := fmt.Sprintf(
+		"/events/%s?since=%d&wait=%d",
+		service, since, int64(wait/time.Second))
+	if _, err := c.httpGet(ctx, url, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// Health returns the health of the server and its storage services.
+func (c *client) Health(ctx types.Context) (*types.Health, error) {
+	reply := &types.Health{}
+	if _, err := c.httpGet(ctx, "/health", &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *client) Tasks(
+	ctx types.Context) (map[string]*types.Task, error) {
+
+	reply := map[string]*types.Task{}
+	if _, err := c.httpGet(ctx, "/tasks", &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *client) TaskInspect(
+	ctx types.Context, taskID int) (*types.Task, error) {
+
+	reply := types.Task{}
+	if _, err := c.httpGet(ctx,
+		fmt.Sprintf("/tasks/%d", taskID), &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
 func (c *client) Executors(
 	ctx types.Context) (map[string]*types.ExecutorInfo, error) {
 
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// ErrorCode returns the machine-readable code of an error returned by the
+// API client. An empty string is returned if the error has no code.
+func ErrorCode(err error) types.ErrorCode {
+	if httpErr, ok := err.(*types.ErrHTTP); ok {
+		return httpErr.Code
+	}
+	return ""
+}
+
+// IsVolumeNotFound returns a flag indicating whether the error occurred
+// because a volume could not be found.
+func IsVolumeNotFound(err error) bool {
+	return ErrorCode(err) == types.ErrCodeVolumeNotFound
+}
+
+// IsSnapshotNotFound returns a flag indicating whether the error occurred
+// because a snapshot could not be found.
+func IsSnapshotNotFound(err error) bool {
+	return ErrorCode(err) == types.ErrCodeSnapshotNotFound
+}
+
+// IsVolumeAttached returns a flag indicating whether the error occurred
+// because a volume is attached.
+func IsVolumeAttached(err error) bool {
+	return ErrorCode(err) == types.ErrCodeVolumeAttached
+}
+
+// IsVolumeInUse returns a flag indicating whether the error occurred because
+// a volume that is attached to one or more instances was removed without
+// being forced.
+func IsVolumeInUse(err error) bool {
+	return ErrorCode(err) == types.ErrCodeVolumeInUse
+}
+
+// IsQuotaExceeded returns a flag indicating whether the error occurred
+// because a storage quota was exceeded.
+func IsQuotaExceeded(err error) bool {
+	return ErrorCode(err) == types.ErrCodeQuotaExceeded
+}
+
+// IsDriverTimeout returns a flag indicating whether the error occurred
+// because a storage driver operation timed out.
+func IsDriverTimeout(err error) bool {
+	return ErrorCode(err) == types.ErrCodeDriverTimeout
+}
+
+// IsInstanceIDBinding returns a flag indicating whether the error occurred
+// because the request claimed an instance ID that is bound to a different
+// client certificate.
+func IsInstanceIDBinding(err error) bool {
+	return ErrorCode(err) == types.ErrCodeInstanceIDBinding
+}
+
+// IsDeadlineExceeded returns a flag indicating whether the error occurred
+// because the request did not complete before its deadline.
+func IsDeadlineExceeded(err error) bool {
+	return ErrorCode(err) == types.ErrCodeDeadlineExceeded
+}
+
+// IsServiceUnavailable returns a flag indicating whether the error occurred
+// because the storage service's circuit breaker is open or its task queue is
+// full.
+func IsServiceUnavailable(err error) bool {
+	return ErrorCode(err) == types.ErrCodeServiceUnavailable
+}
+
+// IsThrottled returns a flag indicating whether the error occurred because
+// the storage driver's backend throttled the request.
+func IsThrottled(err error) bool {
+	return ErrorCode(err) == types.ErrCodeThrottled
+}
+
+// IsPolicyDenied returns a flag indicating whether the error occurred
+// because the server's admission policy denied the request.
+func IsPolicyDenied(err error) bool {
+	return ErrorCode(err) == types.ErrCodePolicyDenied
+}
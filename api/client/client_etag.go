@@ -0,0 +1,73 @@
+package client
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// etagCacheSize is the maximum number of responses kept by the ETag cache.
+const etagCacheSize = 64
+
+type etagEntry struct {
+	etag string
+	body []byte
+}
+
+// etagCache stores the bodies of GET responses by their request paths so
+// that unchanged responses do not need to be transferred again.
+type etagCache struct {
+	sync.Mutex
+	entries map[string]*etagEntry
+}
+
+// WithETagCache returns an option that configures the API client to cache
+// the responses of GET requests that are tagged with an ETag. The ETag is
+// sent back in the If-None-Match header, and the cached response is used if
+// the server indicates the response has not been modified.
+func WithETagCache() Option {
+	return func(c *client) {
+		c.etagCache = &etagCache{entries: map[string]*etagEntry{}}
+	}
+}
+
+func (e *etagCache) get(path string) *etagEntry {
+	if e == nil {
+		return nil
+	}
+	e.Lock()
+	defer e.Unlock()
+	return e.entries[path]
+}
+
+func (e *etagCache) set(path, etag string, body []byte) {
+	e.Lock()
+	defer e.Unlock()
+	if _, ok := e.entries[path]; !ok && len(e.entries) >= etagCacheSize {
+		for k := range e.entries {
+			delete(e.entries, k)
+			break
+		}
+	}
+	e.entries[path] = &etagEntry{etag: etag, body: body}
+}
+
+// store caches the body of a response if it is tagged with an ETag. The
+// response's body is replaced so that it may still be read.
+func (e *etagCache) store(path string, res *http.Response) error {
+	if e == nil || res.StatusCode != http.StatusOK {
+		return nil
+	}
+	etag := res.Header.Get("ETag")
+	if etag == "" {
+		return nil
+	}
+	buf, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(buf))
+	e.set(path, etag, buf)
+	return nil
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestETagCacheStore(t *testing.T) {
+	newRes := func(status int, etag, body string) *http.Response {
+		res := &http.Response{
+			StatusCode: status,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+		}
+		if etag != "" {
+			res.Header.Set("ETag", etag)
+		}
+		return res
+	}
+
+	tests := []struct {
+		name   string
+		status int
+		etag   string
+		cached bool
+	}{
+		{"tagged", http.StatusOK, `"abc"`, true},
+		{"untagged", http.StatusOK, "", false},
+		{"error", http.StatusNotFound, `"abc"`, false},
+	}
+
+	for _, tt := range tests {
+		c := New("", nil, WithETagCache()).(*client)
+		res := newRes(tt.status, tt.etag, "body")
+		assert.NoError(t, c.etagCache.store("/volumes", res), tt.name)
+
+		// the response's body may still be read
+		buf, _ := ioutil.ReadAll(res.Body)
+		assert.Equal(t, "body", string(buf), tt.name)
+
+		e := c.etagCache.get("/volumes")
+		if !tt.cached {
+			assert.Nil(t, e, tt.name)
+			continue
+		}
+		if assert.NotNil(t, e, tt.name) {
+			assert.Equal(t, tt.etag, e.etag, tt.name)
+			assert.Equal(t, "body", string(e.body), tt.name)
+		}
+	}
+
+	// the cache is bounded
+	c := New("", nil, WithETagCache()).(*client)
+	for x := 0; x <= etagCacheSize; x++ {
+		c.etagCache.set(strconv.Itoa(x), `"abc"`, nil)
+	}
+	assert.Len(t, c.etagCache.entries, etagCacheSize)
+
+	// a client without the cache does not cache responses
+	c = New("", nil).(*client)
+	assert.NoError(t, c.etagCache.store(
+		"/volumes", newRes(http.StatusOK, `"abc"`, "body")))
+	assert.Nil(t, c.etagCache.get("/volumes"))
+}
@@ -7,12 +7,13 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"time"
 
 	"github.com/akutz/goof"
-	"golang.org/x/net/context/ctxhttp"
 
 	"github.com/codedellemc/libstorage/api/context"
 	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils/tracing"
 )
 
 type headerKey int
@@ -21,6 +22,7 @@ const (
 	transactionHeaderKey headerKey = iota
 	instanceIDHeaderKey
 	localDevicesHeaderKey
+	localDeviceInfoHeaderKey
 )
 
 func (k headerKey) String() string {
@@ -31,6 +33,8 @@ func (k headerKey) String() string {
 		return types.InstanceIDHeader
 	case localDevicesHeaderKey:
 		return types.LocalDevicesHeader
+	case localDeviceInfoHeaderKey:
+		return types.LocalDeviceInfoHeader
 	}
 	panic("invalid header key")
 }
@@ -82,14 +86,24 @@ func (c *client) httpDo(
 
 	if lds, ok := context.LocalDevices(ctx); ok {
 		ctx = ctx.WithValue(localDevicesHeaderKey, lds)
+		if ldis := localDeviceInfos(lds); len(ldis) > 0 {
+			ctx = ctx.WithValue(localDeviceInfoHeaderKey, ldis)
+		}
 	} else if ldsMap, ok := ctx.Value(
 		context.AllLocalDevicesKey).(types.LocalDevicesMap); ok {
 		if len(ldsMap) > 0 {
-			var ldsess []fmt.Stringer
+			var (
+				ldsess []fmt.Stringer
+				ldis   []string
+			)
 			for _, lds := range ldsMap {
 				ldsess = append(ldsess, lds)
+				ldis = append(ldis, localDeviceInfos(lds)...)
 			}
 			ctx = ctx.WithValue(localDevicesHeaderKey, ldsess)
+			if len(ldis) > 0 {
+				ctx = ctx.WithValue(localDeviceInfoHeaderKey, ldis)
+			}
 		}
 	}
 
@@ -131,9 +145,24 @@ func (c *client) httpDo(
 		}
 	}
 
-	c.logRequest(req)
+	// send the time remaining until the context's deadline so the server
+	// stops working on the request once the client is no longer waiting
+	if deadline, ok := ctx.Deadline(); ok {
+		if timeout := deadline.Sub(time.Now()); timeout > 0 {
+			req.Header.Set(types.TimeoutHeader, timeout.String())
+		}
+	}
+
+	var cached *etagEntry
+	if req.Method == http.MethodGet && reply != nil {
+		if cached = c.etagCache.get(path); cached != nil {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+	}
 
-	res, err := ctxhttp.Do(ctx, &c.Client, req)
+	ctx, finishSpan := tracing.StartClientSpan(ctx, req)
+	res, err := c.doWithRetry(ctx, req)
+	finishSpan(res, err)
 	if err != nil {
 		return nil, err
 	}
@@ -141,15 +170,24 @@ func (c *client) httpDo(
 
 	c.logResponse(res)
 
-	if res.StatusCode > 299 {
-		httpErr, err := goof.DecodeHTTPError(res.Body)
-		if err != nil {
-			return res, goof.WithField("status", res.StatusCode, "http error")
+	// the server answers with a 304 if the cached response is unchanged
+	if res.StatusCode == http.StatusNotModified && cached != nil {
+		if err := decRes(bytes.NewReader(cached.body), reply); err != nil {
+			return nil, err
 		}
-		return res, httpErr
+		return res, nil
+	}
+
+	if res.StatusCode > 299 {
+		return res, decHTTPError(res)
 	}
 
 	if req.Method != http.MethodHead && reply != nil {
+		if req.Method == http.MethodGet {
+			if err := c.etagCache.store(path, res); err != nil {
+				return nil, err
+			}
+		}
 		if err := decRes(res.Body, reply); err != nil {
 			return nil, err
 		}
@@ -158,6 +196,23 @@ func (c *client) httpDo(
 	return res, nil
 }
 
+// localDeviceInfos returns the header values that contain the information
+// about the local devices, if the executor described them.
+func localDeviceInfos(lds ...*types.LocalDevices) []string {
+	var ldis []string
+	for _, ld := range lds {
+		if len(ld.Devices) == 0 {
+			continue
+		}
+		buf, err := ld.MarshalDevicesText()
+		if err != nil {
+			continue
+		}
+		ldis = append(ldis, string(buf))
+	}
+	return ldis
+}
+
 func (c *client) setServerName(res *http.Response) {
 	c.serverName = res.Header.Get(types.ServerNameHeader)
 }
@@ -217,3 +272,21 @@ func decRes(body io.Reader, reply interface{}) error {
 	}
 	return nil
 }
+
+func decHTTPError(res *http.Response) error {
+	buf, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return goof.WithField("status", res.StatusCode, "http error")
+	}
+	httpErr, err := goof.DecodeHTTPError(bytes.NewReader(buf))
+	if err != nil {
+		return goof.WithField("status", res.StatusCode, "http error")
+	}
+	env := struct {
+		Code types.ErrorCode `json:"code"`
+	}{}
+	if err := json.Unmarshal(buf, &env); err != nil || env.Code == "" {
+		return httpErr
+	}
+	return &types.ErrHTTP{HTTPError: httpErr, Code: env.Code}
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"net/http"
+	"sync"
+
+	"golang.org/x/net/context/ctxhttp"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// Interceptor is a type that intercepts the requests sent and the responses
+// received by the API client. Interceptors may be used to inject headers,
+// apply tracing, record metrics, or otherwise mutate requests.
+type Interceptor interface {
+
+	// InterceptRequest is invoked before a request is sent. The request is
+	// not sent if an error is returned.
+	InterceptRequest(ctx types.Context, req *http.Request) error
+
+	// InterceptResponse is invoked after a request is sent with the response
+	// or the error that occurred while sending the request.
+	InterceptResponse(
+		ctx types.Context,
+		req *http.Request,
+		res *http.Response,
+		err error)
+}
+
+var (
+	interceptors    []Interceptor
+	interceptorsRWL = &sync.RWMutex{}
+)
+
+// RegisterInterceptor registers an interceptor that is added to all API
+// clients created after the interceptor is registered.
+func RegisterInterceptor(i Interceptor) {
+	interceptorsRWL.Lock()
+	defer interceptorsRWL.Unlock()
+	interceptors = append(interceptors, i)
+}
+
+func registeredInterceptors() []Interceptor {
+	interceptorsRWL.RLock()
+	defer interceptorsRWL.RUnlock()
+	return append([]Interceptor{}, interceptors...)
+}
+
+// WithInterceptors returns an option that adds the provided interceptors to
+// the API client. Request interceptors are invoked in the order in which they
+// were added, and response interceptors in the reverse order.
+func WithInterceptors(i ...Interceptor) Option {
+	return func(c *client) {
+		c.interceptors = append(c.interceptors, i...)
+	}
+}
+
+func (c *client) do(
+	ctx types.Context,
+	req *http.Request) (*http.Response, error) {
+
+	for _, i := range c.interceptors {
+		if err := i.InterceptRequest(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+
+	c.logRequest(req)
+	res, err := ctxhttp.Do(ctx, &c.Client, req)
+
+	for x := len(c.interceptors) - 1; x >= 0; x-- {
+		c.interceptors[x].InterceptResponse(ctx, req, res, err)
+	}
+
+	return res, err
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// recordingInterceptor records its invocations in calls and fails the
+// requests it intercepts with err if err is not nil.
+type recordingInterceptor struct {
+	name  string
+	err   error
+	calls *[]string
+}
+
+func (i *recordingInterceptor) InterceptRequest(
+	ctx types.Context, req *http.Request) error {
+	*i.calls = append(*i.calls, "req:"+i.name)
+	return i.err
+}
+
+func (i *recordingInterceptor) InterceptResponse(
+	ctx types.Context,
+	req *http.Request,
+	res *http.Response,
+	err error) {
+	*i.calls = append(*i.calls, "res:"+i.name)
+}
+
+func TestInterceptors(t *testing.T) {
+	errDenied := errors.New("denied")
+
+	tests := []struct {
+		name   string
+		errs   []error
+		calls  []string
+		sent   bool
+		expErr error
+	}{
+		{
+			"ordered",
+			[]error{nil, nil},
+			[]string{"req:0", "req:1", "res:1", "res:0"},
+			true,
+			nil,
+		},
+		{
+			"first fails",
+			[]error{errDenied, nil},
+			[]string{"req:0"},
+			false,
+			errDenied,
+		},
+		{
+			"last fails",
+			[]error{nil, errDenied},
+			[]string{"req:0", "req:1"},
+			false,
+			errDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		sent := false
+		srv := httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, req *http.Request) {
+				sent = true
+			}))
+
+		var (
+			calls []string
+			is    []Interceptor
+		)
+		for x, err := range tt.errs {
+			is = append(is, &recordingInterceptor{
+				name:  strconv.Itoa(x),
+				err:   err,
+				calls: &calls,
+			})
+		}
+
+		c := New("", nil, WithInterceptors(is...)).(*client)
+		req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+		res, err := c.do(context.Background(), req)
+		if res != nil {
+			res.Body.Close()
+		}
+		srv.Close()
+
+		assert.Equal(t, tt.expErr, err, tt.name)
+		assert.Equal(t, tt.sent, sent, tt.name)
+		assert.Equal(t, tt.sent, res != nil, tt.name)
+		assert.Equal(t, tt.calls, calls, tt.name)
+	}
+}
+
+func TestRegisteredInterceptors(t *testing.T) {
+	defer func(v []Interceptor) {
+		interceptorsRWL.Lock()
+		interceptors = v
+		interceptorsRWL.Unlock()
+	}(registeredInterceptors())
+
+	var calls []string
+	RegisterInterceptor(&recordingInterceptor{name: "global", calls: &calls})
+	c := New("", nil, WithInterceptors(
+		&recordingInterceptor{name: "client", calls: &calls})).(*client)
+
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	_, err := c.do(context.Background(), req)
+	assert.NoError(t, err)
+
+	// registered interceptors precede the client's own
+	assert.Equal(t, []string{
+		"req:global", "req:client", "res:client", "res:global",
+	}, calls)
+}
@@ -9,6 +9,8 @@ import (
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/akutz/gotil"
+
+	"github.com/codedellemc/libstorage/api/utils"
 )
 
 func (c *client) logRequest(req *http.Request) {
@@ -29,7 +31,7 @@ func (c *client) logRequest(req *http.Request) {
 		return
 	}
 
-	gotil.WriteIndented(w, buf)
+	gotil.WriteIndented(w, utils.RedactHTTP(buf))
 	fmt.Fprintln(w)
 }
 
@@ -54,7 +56,7 @@ func (c *client) logResponse(res *http.Response) {
 	}
 
 	bw := &bytes.Buffer{}
-	gotil.WriteIndented(bw, buf)
+	gotil.WriteIndented(bw, utils.RedactHTTP(buf))
 
 	scanner := bufio.NewScanner(bw)
 	for {
@@ -0,0 +1,191 @@
+package client
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// retryBudgetRefill is the portion of a retry that is returned to the retry
+// budget each time a request succeeds without being retried.
+const retryBudgetRefill = 0.1
+
+// RetryPolicy configures how the API client retries failed requests.
+//
+// Requests whose method is idempotent are retried if they fail due to a
+// transport error, or because the server or its storage backend is
+// throttling the client (429) or the server is temporarily unavailable
+// (503). Other requests are retried only if the
+// server is throttling them or is unavailable and includes a Retry-After
+// header in its response, since only then is it certain that the server did
+// not act on them.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a request is attempted,
+	// including the first attempt. A value less than two disables retries.
+	MaxAttempts int
+
+	// InitialBackoff is the upper bound of the delay before the first retry.
+	// The upper bound doubles with each subsequent retry, and the actual
+	// delay is a random duration between zero and the upper bound.
+	InitialBackoff time.Duration
+
+	// MaxBackoff is the maximum delay between two attempts.
+	MaxBackoff time.Duration
+
+	// Budget is the number of retries the client may perform before it must
+	// be replenished by requests that succeed without being retried. A value
+	// of zero indicates an unlimited budget.
+	Budget int
+}
+
+type retryBudget struct {
+	sync.Mutex
+	tokens float64
+	max    float64
+}
+
+func newRetryBudget(max int) *retryBudget {
+	if max <= 0 {
+		return nil
+	}
+	return &retryBudget{tokens: float64(max), max: float64(max)}
+}
+
+func (b *retryBudget) withdraw() bool {
+	if b == nil {
+		return true
+	}
+	b.Lock()
+	defer b.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (b *retryBudget) deposit() {
+	if b == nil {
+		return
+	}
+	b.Lock()
+	defer b.Unlock()
+	if b.tokens += retryBudgetRefill; b.tokens > b.max {
+		b.tokens = b.max
+	}
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func isRetryable(req *http.Request, res *http.Response, err error) bool {
+	if err != nil {
+		return isIdempotent(req.Method)
+	}
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return isIdempotent(req.Method) || res.Header.Get("Retry-After") != ""
+	}
+	return false
+}
+
+// backoff returns the delay before the next attempt. The server's
+// Retry-After header is honored if present.
+func (p *RetryPolicy) backoff(attempt int, res *http.Response) time.Duration {
+	if res != nil {
+		if ra, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil {
+			d := time.Duration(ra) * time.Second
+			if p.MaxBackoff > 0 && d > p.MaxBackoff {
+				d = p.MaxBackoff
+			}
+			return d
+		}
+	}
+
+	ceil := p.InitialBackoff << uint(attempt-1)
+	if ceil <= 0 || (p.MaxBackoff > 0 && ceil > p.MaxBackoff) {
+		ceil = p.MaxBackoff
+	}
+	if ceil <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceil)))
+}
+
+func (c *client) doWithRetry(
+	ctx types.Context,
+	req *http.Request) (*http.Response, error) {
+
+	if c.retryPolicy == nil || c.retryPolicy.MaxAttempts < 2 {
+		return c.do(ctx, req)
+	}
+
+	// buffer the request body so it may be sent again
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+
+	for attempt := 1; ; attempt++ {
+
+		if body != nil {
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		res, err := c.do(ctx, req)
+
+		if attempt >= c.retryPolicy.MaxAttempts ||
+			!isRetryable(req, res, err) {
+			if attempt == 1 && err == nil && res.StatusCode < 300 {
+				c.retryBudget.deposit()
+			}
+			return res, err
+		}
+
+		if !c.retryBudget.withdraw() {
+			ctx.Warn("retry budget exhausted")
+			return res, err
+		}
+
+		wait := c.retryPolicy.backoff(attempt, res)
+
+		fields := log.Fields{
+			"method":  req.Method,
+			"url":     req.URL.String(),
+			"attempt": attempt,
+			"wait":    wait,
+		}
+		if err != nil {
+			fields["error"] = err.Error()
+		} else {
+			fields["status"] = res.StatusCode
+			io.Copy(ioutil.Discard, res.Body)
+			res.Body.Close()
+		}
+		ctx.WithFields(fields).Warn("retrying request")
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
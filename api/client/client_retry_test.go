@@ -0,0 +1,97 @@
+package client
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/context"
+)
+
+// newRetryTestServer returns a server that responds to each request with the
+// next of the provided statuses, and to any further requests with 200. The
+// Retry-After header is set on the responses if retryAfter is not empty.
+func newRetryTestServer(
+	retryAfter string,
+	statuses ...int) (*httptest.Server, *[]string) {
+
+	var (
+		lock   sync.Mutex
+		bodies []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			lock.Lock()
+			defer lock.Unlock()
+			buf, _ := ioutil.ReadAll(req.Body)
+			bodies = append(bodies, string(buf))
+			if len(bodies) > len(statuses) {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(statuses[len(bodies)-1])
+		}))
+	return srv, &bodies
+}
+
+func newRetryTestClient(maxAttempts int) *client {
+	return New("", nil, WithRetryPolicy(&RetryPolicy{
+		MaxAttempts: maxAttempts,
+	})).(*client)
+}
+
+func TestRetryIdempotent(t *testing.T) {
+	srv, bodies := newRetryTestServer("",
+		http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	res, err := newRetryTestClient(3).doWithRetry(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Len(t, *bodies, 3)
+}
+
+func TestRetryMaxAttempts(t *testing.T) {
+	srv, bodies := newRetryTestServer("",
+		http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	res, err := newRetryTestClient(2).doWithRetry(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	assert.Len(t, *bodies, 2)
+}
+
+func TestRetryNotIdempotent(t *testing.T) {
+	srv, bodies := newRetryTestServer("", http.StatusServiceUnavailable)
+	defer srv.Close()
+
+	req, _ := http.NewRequest(
+		http.MethodPost, srv.URL, strings.NewReader(`{"name":"vol"}`))
+	res, err := newRetryTestClient(3).doWithRetry(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	assert.Len(t, *bodies, 1)
+}
+
+func TestRetryNotIdempotentWithRetryAfter(t *testing.T) {
+	srv, bodies := newRetryTestServer("0", http.StatusTooManyRequests)
+	defer srv.Close()
+
+	req, _ := http.NewRequest(
+		http.MethodPost, srv.URL, strings.NewReader(`{"name":"vol"}`))
+	res, err := newRetryTestClient(3).doWithRetry(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, []string{`{"name":"vol"}`, `{"name":"vol"}`}, *bodies)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
 	gcontext "github.com/gorilla/context"
@@ -88,6 +89,28 @@ func WithRequestRoute(
 	return newContext(parent, RouteKey, route, req, nil)
 }
 
+// WithDeadline returns a copy of the parent context with the deadline
+// adjusted to be no later than d.
+func WithDeadline(
+	parent types.Context,
+	d time.Time) (types.Context, context.CancelFunc) {
+
+	dctx, cancel := context.WithDeadline(parent, d)
+	ctx := newContext(dctx, nil, nil, nil, nil)
+	if pctx, ok := parent.(*lsc); ok {
+		ctx.logger = pctx.logger
+	}
+	return ctx, cancel
+}
+
+// WithTimeout returns WithDeadline(parent, time.Now().Add(timeout)).
+func WithTimeout(
+	parent types.Context,
+	timeout time.Duration) (types.Context, context.CancelFunc) {
+
+	return WithDeadline(parent, time.Now().Add(timeout))
+}
+
 // WithStorageService returns a new context with the StorageService as the
 // value and attempts to assign the service's associated InstanceID and
 // LocalDevices (by way of the service's StorageDriver) to the context as well.
@@ -268,6 +291,53 @@ func SetLogLevel(ctx context.Context, lvl log.Level) {
 	}
 }
 
+// SetLogFormatter sets the context's log formatter.
+func SetLogFormatter(ctx context.Context, formatter log.Formatter) {
+	if logCtx, ok := ctx.(*lsc); ok {
+		if logCtx.loggerInherited {
+			parentLogger := logCtx.logger
+			logCtx.logger = &log.Logger{
+				Formatter: formatter,
+				Out:       parentLogger.Out,
+				Hooks:     parentLogger.Hooks,
+				Level:     parentLogger.Level,
+			}
+			logCtx.loggerInherited = false
+			return
+		}
+		logCtx.logger.Formatter = formatter
+	}
+}
+
+// WithComponent returns a new context for the named component. If a log
+// level is configured for the component, or for one of the component's
+// parents, the new context logs at that level. A component's parents are
+// derived by removing the component name's dot-separated suffixes, such that
+// the parent of "driver.rbd" is "driver".
+func WithComponent(parent context.Context, name string) types.Context {
+
+	ctx := newContext(parent, ComponentKey, name, nil, nil)
+
+	levels, ok := parent.Value(LogLevelsKey).(map[string]log.Level)
+	if !ok || len(levels) == 0 {
+		return ctx
+	}
+
+	for n := strings.ToLower(name); n != ""; {
+		if lvl, ok := levels[n]; ok {
+			SetLogLevel(ctx, lvl)
+			break
+		}
+		i := strings.LastIndex(n, ".")
+		if i < 0 {
+			break
+		}
+		n = n[:i]
+	}
+
+	return ctx
+}
+
 // GetLogLevel gets the context's log level.
 func GetLogLevel(ctx context.Context) (log.Level, bool) {
 	if logCtx, ok := ctx.(*lsc); ok {
@@ -313,6 +383,68 @@ func LocalDevices(ctx context.Context) (*types.LocalDevices, bool) {
 	return v, ok
 }
 
+// SetTaskProgress reports the progress, as a percentage, of the task that is
+// executing the operation. This function is a no-op if the operation is not
+// being executed by a task.
+func SetTaskProgress(ctx context.Context, percent int) {
+	if f, ok := ctx.Value(TaskProgressKey).(func(int)); ok {
+		f(percent)
+	}
+}
+
+// RecordMountStep records a step of the workflow mounting a volume before
+// the step is executed. This function is a no-op if the workflow is not
+// being journaled.
+func RecordMountStep(
+	ctx context.Context, step types.MountStep, value string) {
+
+	if f, ok := ctx.Value(MountStepKey).(func(types.MountStep, string)); ok {
+		f(step, value)
+	}
+}
+
+// RecordMountToken records the ID of the volume attached by the workflow
+// mounting the volume and the token the server issued with which the
+// workflow's result is reported to the server. This function is a no-op if
+// the workflow's result is not being reported.
+func RecordMountToken(ctx context.Context, volumeID, token string) {
+	if f, ok := ctx.Value(MountTokenKey).(func(string, string)); ok {
+		f(volumeID, token)
+	}
+}
+
+// APIVersion returns the version of the API requested by the client. Version
+// 1 is returned if no version was requested. This value is valid only on the
+// server.
+func APIVersion(ctx context.Context) types.APIVersion {
+	if v, ok := ctx.Value(APIVersionKey).(types.APIVersion); ok {
+		return v
+	}
+	return types.APIVersion1
+}
+
+// VolumeMetadata returns the metadata with which a storage driver should
+// stamp the volume it creates. This value is valid only on the server.
+func VolumeMetadata(ctx context.Context) (map[string]string, bool) {
+	v, ok := ctx.Value(VolumeMetadataKey).(map[string]string)
+	return v, ok
+}
+
+// Admin returns a flag indicating whether or not the request's
+// authenticated user is a configured administrator. This value is valid
+// only on the server.
+func Admin(ctx context.Context) bool {
+	v, _ := ctx.Value(AdminKey).(bool)
+	return v
+}
+
+// Tenant returns the tenant to which the volumes visible to the request are
+// scoped. This value is valid only on the server.
+func Tenant(ctx context.Context) (string, bool) {
+	v, ok := ctx.Value(TenantKey).(string)
+	return v, ok
+}
+
 // Transaction returns the context's Transaction. This value is valid on both
 // the client and the server.
 func Transaction(ctx context.Context) (*types.Transaction, bool) {
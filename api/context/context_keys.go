@@ -36,6 +36,44 @@ const (
 	// SessionKey is the key for the storage driver's session.
 	SessionKey
 
+	// SpanKey is the key for the active trace span.
+	SpanKey
+
+	// LogLevelsKey is the key for the map[string]logrus.Level value that
+	// maps components to their log levels.
+	LogLevelsKey
+
+	// TaskProgressKey is the key for the func(int) value that reports the
+	// progress, as a percentage, of the task executing the operation.
+	TaskProgressKey
+
+	// RouteVarsKey is the key for the map[string]string value that holds
+	// the variables parsed from the request's path, ex. the volume ID.
+	RouteVarsKey
+
+	// MountStepKey is the key for the func(types.MountStep, string) value
+	// that records a step of the workflow mounting a volume before the step
+	// is executed.
+	MountStepKey
+
+	// MountTokenKey is the key for the func(string, string) value that
+	// records the ID of the volume attached by a mount and the token with
+	// which the mount's result is reported to the server.
+	MountTokenKey
+
+	// APIVersionKey is the key for the types.APIVersion value that is the
+	// version of the API requested by the client.
+	APIVersionKey
+
+	// VolumeMetadataKey is the key for the map[string]string value that
+	// holds the metadata with which a storage driver stamps the volumes it
+	// creates.
+	VolumeMetadataKey
+
+	// AdminKey is the key for the bool value that indicates whether or not
+	// the request's authenticated user is a configured administrator.
+	AdminKey
+
 	// keyLoggable is the minimum value from which the succeeding keys should
 	// be checked when logging.
 	keyLoggable
@@ -73,12 +111,20 @@ const (
 	// UserKey is a context key.
 	UserKey
 
+	// TenantKey is the key for the tenant of the request's authenticated
+	// user. The key is not set if tenancy is disabled or the user is an
+	// administrator.
+	TenantKey
+
 	// HostKey is a context key.
 	HostKey
 
 	// TLSKey is a context key.
 	TLSKey
 
+	// ComponentKey is a context key.
+	ComponentKey
+
 	// keyEOF should always be the final key
 	keyEOF
 )
@@ -103,8 +149,10 @@ var (
 		TransactionKey:    "tx",
 		DriverKey:         "storageDriver",
 		UserKey:           "user",
+		TenantKey:         "tenant",
 		HostKey:           "host",
 		TLSKey:            "tls",
+		ComponentKey:      "component",
 	}
 )
 
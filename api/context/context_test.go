@@ -3,6 +3,7 @@ package context
 import (
 	"os"
 	"testing"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -110,3 +111,46 @@ func TestContextIDLogCustomLoggerKey(t *testing.T) {
 	ctx = ctx.WithValue(testLogKeyHello, "world")
 	ctx.Info("testing custom log keys")
 }
+
+func TestWithTimeout(t *testing.T) {
+
+	ctx, cancel := WithTimeout(
+		Background().WithValue(ServerKey, serverName), time.Millisecond)
+	defer cancel()
+
+	_, ok := ctx.Deadline()
+	assert.True(t, ok)
+
+	v, ok := Server(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, serverName, v)
+
+	// values added after the deadline should not affect it
+	ctx = ctx.WithValue(ServiceKey, &service{})
+	<-ctx.Done()
+	assert.Error(t, ctx.Err())
+}
+
+func TestWithComponent(t *testing.T) {
+
+	parent := Background().WithValue(LogLevelsKey, map[string]log.Level{
+		"driver":     log.InfoLevel,
+		"driver.rbd": log.DebugLevel,
+	})
+	SetLogLevel(parent, log.WarnLevel)
+
+	ctx := WithComponent(parent, "driver.rbd")
+	lvl, _ := GetLogLevel(ctx)
+	assert.Equal(t, log.DebugLevel, lvl)
+
+	ctx = WithComponent(parent, "driver.ebs")
+	lvl, _ = GetLogLevel(ctx)
+	assert.Equal(t, log.InfoLevel, lvl)
+
+	ctx = WithComponent(parent, "server")
+	lvl, _ = GetLogLevel(ctx)
+	assert.Equal(t, log.WarnLevel, lvl)
+
+	lvl, _ = GetLogLevel(parent)
+	assert.Equal(t, log.WarnLevel, lvl)
+}
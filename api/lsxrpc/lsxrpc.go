@@ -0,0 +1,219 @@
+// Package lsxrpc provides the gRPC service with which a client calls an
+// executor that runs as a long-lived daemon rather than invoking the
+// executor binary as a new process for each operation.
+//
+// The service's messages are encoded as JSON so that the service does not
+// require generated protocol buffer code.
+package lsxrpc
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/akutz/goof"
+	"github.com/akutz/gotil"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// ServiceName is the name of the executor service.
+const ServiceName = "libstorage.Executor"
+
+const (
+	execMethod = "/" + ServiceName + "/Exec"
+	waitMethod = "/" + ServiceName + "/Wait"
+)
+
+// ExecRequest is a request to run an executor command.
+type ExecRequest struct {
+	// Args are the arguments with which the executor binary would be
+	// invoked, ex. ["ebs", "localDevices", "0"].
+	Args []string `json:"args"`
+}
+
+// ExecResponse is the result of running an executor command.
+type ExecResponse struct {
+	// Output is what the executor binary would write to stdout.
+	Output []byte `json:"output,omitempty"`
+
+	// ExitCode is the code with which the executor binary would exit.
+	ExitCode int `json:"exitCode"`
+
+	// Error is the message of the error that caused a non-zero exit code.
+	Error string `json:"error,omitempty"`
+}
+
+// WaitRequest is a request to wait for a device to be presented to the
+// host.
+type WaitRequest struct {
+	// Driver is the name of the executor.
+	Driver string `json:"driver"`
+
+	// ScanType is the type of scan used to find the device.
+	ScanType types.DeviceScanType `json:"scanType"`
+
+	// Token is the attach token that identifies the device.
+	Token string `json:"token"`
+
+	// Timeout is how long to wait for the device, ex. "30s".
+	Timeout string `json:"timeout"`
+}
+
+// WaitEvent is sent by the executor each time it scans the host's devices
+// while it waits for a device. The last event has Done set.
+type WaitEvent struct {
+	// LocalDevices are the devices found by the scan.
+	LocalDevices *types.LocalDevices `json:"localDevices,omitempty"`
+
+	// Matched is a flag indicating whether or not the device was found.
+	Matched bool `json:"matched,omitempty"`
+
+	// Done is a flag indicating whether or not the wait is complete.
+	Done bool `json:"done,omitempty"`
+
+	// ExitCode is the code with which the executor binary would exit.
+	ExitCode int `json:"exitCode,omitempty"`
+
+	// Error is the message of the error that ended the wait.
+	Error string `json:"error,omitempty"`
+}
+
+// ExecutorServer is the interface implemented by executor daemons.
+type ExecutorServer interface {
+	// Exec runs an executor command.
+	Exec(ctx context.Context, req *ExecRequest) (*ExecResponse, error)
+
+	// Wait waits for a device, sending an event for each scan of the host's
+	// devices.
+	Wait(req *WaitRequest, stream WaitServer) error
+}
+
+// WaitServer is the server side of a Wait stream.
+type WaitServer interface {
+	grpc.ServerStream
+
+	// Send sends an event to the client.
+	Send(event *WaitEvent) error
+}
+
+type waitServer struct {
+	grpc.ServerStream
+}
+
+func (s *waitServer) Send(event *WaitEvent) error {
+	return s.ServerStream.SendMsg(event)
+}
+
+// jsonCodec encodes the service's messages as JSON.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) String() string {
+	return "json"
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*ExecutorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Exec",
+			Handler:    execHandler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Wait",
+			Handler:       waitHandler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "lsxrpc",
+}
+
+func execHandler(
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+
+	req := &ExecRequest{}
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExecutorServer).Exec(ctx, req)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: execMethod,
+	}
+	handler := func(
+		ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExecutorServer).Exec(ctx, req.(*ExecRequest))
+	}
+	return interceptor(ctx, req, info, handler)
+}
+
+func waitHandler(srv interface{}, stream grpc.ServerStream) error {
+	req := &WaitRequest{}
+	if err := stream.RecvMsg(req); err != nil {
+		return err
+	}
+	return srv.(ExecutorServer).Wait(req, &waitServer{stream})
+}
+
+// NewServer returns a new gRPC server that serves the executor service.
+func NewServer(impl ExecutorServer) *grpc.Server {
+	s := grpc.NewServer(grpc.CustomCodec(jsonCodec{}))
+	s.RegisterService(&serviceDesc, impl)
+	return s
+}
+
+// Listen listens at the provided executor endpoint. The executor service is
+// not authenticated, so it is only served over a UNIX socket that is
+// accessible only to the user that runs the daemon.
+func Listen(addr string) (net.Listener, error) {
+	laddr, err := parseEndpoint(addr)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(laddr), 0755); err != nil {
+		return nil, err
+	}
+
+	// remove a socket file left behind by a previous daemon, but not one on
+	// which a running daemon is still listening
+	if err := utils.RemoveStaleSocket(laddr); err != nil {
+		return nil, err
+	}
+
+	return utils.ListenUnix(laddr, 0600)
+}
+
+// parseEndpoint returns the path of the UNIX socket at the provided executor
+// endpoint.
+func parseEndpoint(addr string) (string, error) {
+	proto, laddr, err := gotil.ParseAddress(addr)
+	if err != nil {
+		return "", goof.WithFieldE(
+			"address", addr, "invalid executor endpoint", err)
+	}
+	if proto != "unix" {
+		return "", goof.WithField(
+			"address", addr, "executor endpoint must be a unix socket")
+	}
+	return laddr, nil
+}
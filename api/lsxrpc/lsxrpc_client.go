@@ -0,0 +1,94 @@
+package lsxrpc
+
+import (
+	"io"
+	"net"
+	"time"
+
+	"github.com/akutz/goof"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// Client calls an executor daemon.
+type Client struct {
+	conn *grpc.ClientConn
+}
+
+// Dial returns a client for the executor daemon listening at the provided
+// address, ex. unix:///var/run/libstorage/lsx.sock. The connection is
+// established in the background, so an unavailable daemon causes an error
+// when the client is used rather than when it is dialed.
+func Dial(addr string) (*Client, error) {
+	laddr, err := parseEndpoint(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := func(_ string, timeout time.Duration) (net.Conn, error) {
+		return net.DialTimeout("unix", laddr, timeout)
+	}
+
+	conn, err := grpc.Dial(
+		laddr,
+		grpc.WithInsecure(),
+		grpc.WithCodec(jsonCodec{}),
+		grpc.WithDialer(dialer))
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn}, nil
+}
+
+// Close closes the client's connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Exec runs an executor command.
+func (c *Client) Exec(
+	ctx context.Context, req *ExecRequest) (*ExecResponse, error) {
+
+	res := &ExecResponse{}
+	if err := grpc.Invoke(ctx, execMethod, req, res, c.conn); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Wait waits for a device. The provided function, which may be nil, is
+// called with each event the executor sends, and the last event is
+// returned.
+func (c *Client) Wait(
+	ctx context.Context,
+	req *WaitRequest,
+	progress func(event *WaitEvent)) (*WaitEvent, error) {
+
+	stream, err := grpc.NewClientStream(
+		ctx, &serviceDesc.Streams[0], c.conn, waitMethod)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(req); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	for {
+		event := &WaitEvent{}
+		if err := stream.RecvMsg(event); err != nil {
+			if err == io.EOF {
+				return nil, goof.New("executor ended wait without result")
+			}
+			return nil, err
+		}
+		if progress != nil {
+			progress(event)
+		}
+		if event.Done {
+			return event, nil
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package lsxrpc
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+type testServer struct{}
+
+func (s *testServer) Exec(
+	ctx context.Context, req *ExecRequest) (*ExecResponse, error) {
+
+	if len(req.Args) < 2 || req.Args[1] != types.LSXCmdNextDevice {
+		return &ExecResponse{
+			ExitCode: types.LSXExitCodeNotImplemented,
+			Error:    types.ErrNotImplemented.Error(),
+		}, nil
+	}
+	return &ExecResponse{Output: []byte("/dev/xvdb\n")}, nil
+}
+
+func (s *testServer) Wait(req *WaitRequest, stream WaitServer) error {
+	ld := &types.LocalDevices{
+		Driver:    req.Driver,
+		DeviceMap: map[string]string{"/dev/xvda": "/dev/xvda"},
+	}
+	if err := stream.Send(&WaitEvent{LocalDevices: ld}); err != nil {
+		return err
+	}
+	ld.DeviceMap[req.Token] = req.Token
+	return stream.Send(&WaitEvent{LocalDevices: ld, Matched: true, Done: true})
+}
+
+func TestClient(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lsxrpc")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	sock := path.Join(dir, "lsx.sock")
+	l, err := Listen("unix://" + sock)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	// the socket is accessible only to the user that runs the daemon
+	fi, err := os.Stat(sock)
+	if assert.NoError(t, err) {
+		assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+	}
+
+	srv := NewServer(&testServer{})
+	go srv.Serve(l)
+	defer srv.Stop()
+
+	c, err := Dial("unix://" + sock)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer c.Close()
+
+	ctx := context.Background()
+
+	res, err := c.Exec(ctx, &ExecRequest{
+		Args: []string{"vfs", types.LSXCmdNextDevice},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, res.ExitCode)
+	assert.Equal(t, "/dev/xvdb\n", string(res.Output))
+
+	res, err = c.Exec(ctx, &ExecRequest{
+		Args: []string{"vfs", types.LSXCmdMounts},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, types.LSXExitCodeNotImplemented, res.ExitCode)
+
+	events := 0
+	event, err := c.Wait(
+		ctx,
+		&WaitRequest{Driver: "vfs", Token: "/dev/xvdb", Timeout: "1s"},
+		func(*WaitEvent) { events++ })
+	assert.NoError(t, err)
+	assert.Equal(t, 2, events)
+	assert.True(t, event.Matched)
+	assert.Equal(t, "vfs", event.LocalDevices.Driver)
+	assert.Equal(t, "/dev/xvdb", event.LocalDevices.DeviceMap["/dev/xvdb"])
+}
+
+func TestListenExistingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lsxrpc")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	// a file at the endpoint that is not a socket is not removed
+	sock := path.Join(dir, "lsx.sock")
+	if !assert.NoError(t, ioutil.WriteFile(sock, []byte("data"), 0644)) {
+		t.FailNow()
+	}
+	_, err = Listen("unix://" + sock)
+	assert.Error(t, err)
+	buf, err := ioutil.ReadFile(sock)
+	assert.NoError(t, err)
+	assert.Equal(t, "data", string(buf))
+
+	// a socket on which a daemon is listening is not replaced
+	os.Remove(sock)
+	l1, err := Listen("unix://" + sock)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	_, err = Listen("unix://" + sock)
+	assert.Error(t, err)
+	l1.Close()
+
+	// but a socket left behind by a previous daemon is
+	l2, err := net.ListenUnix("unix", &net.UnixAddr{Name: sock, Net: "unix"})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	l2.SetUnlinkOnClose(false)
+	l2.Close()
+	l3, err := Listen("unix://" + sock)
+	if assert.NoError(t, err) {
+		l3.Close()
+	}
+}
+
+func TestTCPEndpoint(t *testing.T) {
+	// the executor service is not authenticated, so it is not served over
+	// TCP
+	_, err := Listen("tcp://127.0.0.1:7980")
+	assert.Error(t, err)
+	_, err = Dial("tcp://127.0.0.1:7980")
+	assert.Error(t, err)
+}
@@ -27,6 +27,9 @@ var (
 	intDriverCtors    = map[string]types.NewIntegrationDriver{}
 	intDriverCtorsRWL = &sync.RWMutex{}
 
+	secretsProviderCtors    = map[string]types.NewSecretsProvider{}
+	secretsProviderCtorsRWL = &sync.RWMutex{}
+
 	routers    = []types.Router{}
 	routersRWL = &sync.RWMutex{}
 )
@@ -75,6 +78,13 @@ func RegisterIntegrationDriver(name string, ctor types.NewIntegrationDriver) {
 	intDriverCtors[strings.ToLower(name)] = ctor
 }
 
+// RegisterSecretsProvider registers a SecretsProvider.
+func RegisterSecretsProvider(name string, ctor types.NewSecretsProvider) {
+	secretsProviderCtorsRWL.Lock()
+	defer secretsProviderCtorsRWL.Unlock()
+	secretsProviderCtors[strings.ToLower(name)] = ctor
+}
+
 // NewStorageExecutor returns a new instance of the executor specified by the
 // executor name.
 func NewStorageExecutor(name string) (types.StorageExecutor, error) {
@@ -180,6 +190,27 @@ func NewIntegrationDriver(name string) (types.IntegrationDriver, error) {
 	return NewIntegrationDriverManager(ctor()), nil
 }
 
+// NewSecretsProvider returns a new instance of the secrets provider
+// specified by the provider name.
+func NewSecretsProvider(name string) (types.SecretsProvider, error) {
+
+	var ok bool
+	var ctor types.NewSecretsProvider
+
+	func() {
+		secretsProviderCtorsRWL.RLock()
+		defer secretsProviderCtorsRWL.RUnlock()
+		ctor, ok = secretsProviderCtors[strings.ToLower(name)]
+	}()
+
+	if !ok {
+		return nil, goof.WithField(
+			"provider", name, "invalid secrets provider name")
+	}
+
+	return ctor(), nil
+}
+
 // StorageExecutors returns a channel on which new instances of all registered
 // storage executors can be received.
 func StorageExecutors() <-chan types.StorageExecutor {
@@ -1,8 +1,33 @@
 package registry
 
 import (
+	"sync"
+
 	gofig "github.com/akutz/gofig/types"
+
+	"github.com/codedellemc/libstorage/api/types"
 )
 
 // NewConfig is a function that returns a new Config object.
 var NewConfig func() gofig.Config
+
+var (
+	configSchemas    = []*types.ConfigSchema{}
+	configSchemasRWL = &sync.RWMutex{}
+)
+
+// RegisterConfigSchema registers a schema that describes config keys.
+func RegisterConfigSchema(schema *types.ConfigSchema) {
+	configSchemasRWL.Lock()
+	defer configSchemasRWL.Unlock()
+	configSchemas = append(configSchemas, schema)
+}
+
+// ConfigSchemas returns the registered config schemas.
+func ConfigSchemas() []*types.ConfigSchema {
+	configSchemasRWL.RLock()
+	defer configSchemasRWL.RUnlock()
+	schemas := make([]*types.ConfigSchema, len(configSchemas))
+	copy(schemas, configSchemas)
+	return schemas
+}
@@ -1,6 +1,7 @@
 package registry
 
 import (
+	"path"
 	"sync"
 
 	log "github.com/Sirupsen/logrus"
@@ -17,6 +18,19 @@ type idm struct {
 	ctx    types.Context
 	config gofig.Config
 	used   map[string]int
+
+	// orphans are the keys of the orphans found by the previous
+	// reconciliation
+	orphans       map[string]bool
+	reconcileLock sync.Mutex
+
+	// mounting counts the mounts in progress by volume ID and name so that
+	// the reconciler does not detach a volume that is being mounted
+	mounting map[string]int
+
+	// journal records the steps of mounts in progress; it is nil if the
+	// mount journal is disabled
+	journal *mountJournal
 }
 
 // NewIntegrationDriverManager returns a new integration driver manager.
@@ -38,7 +52,9 @@ func (d *idm) Init(ctx types.Context, config gofig.Config) error {
 	d.config = config
 	d.used = map[string]int{}
 
+	d.initJournal(ctx)
 	d.initPathCache(ctx)
+	d.initReconciler(ctx)
 
 	ctx.WithFields(log.Fields{
 		types.ConfigIgVolOpsPathCacheEnabled:  d.pathCacheEnabled(),
@@ -47,6 +63,7 @@ func (d *idm) Init(ctx types.Context, config gofig.Config) error {
 		types.ConfigIgVolOpsMountPreempt:      d.preempt(),
 		types.ConfigIgVolOpsCreateDisable:     d.disableCreate(),
 		types.ConfigIgVolOpsRemoveDisable:     d.disableRemove(),
+		types.ConfigIgVolOpsReconcileInterval: d.reconcileInterval(),
 	}).Info("libStorage integration driver successfully initialized")
 
 	return nil
@@ -143,8 +160,36 @@ func (d *idm) Mount(
 		"opts":       opts}
 	ctx.WithFields(fields).Debug("mounting volume")
 
+	if d.journal != nil {
+		var mountPath string
+		if volumeName != "" {
+			mountPath = path.Join(
+				d.config.GetString(types.ConfigIgVolOpsMountPath), volumeName)
+		}
+		key, record := d.journal.begin(ctx, volumeID, volumeName, mountPath)
+		ctx = ctx.WithValue(context.MountStepKey, record)
+		defer d.journal.end(ctx, key)
+	}
+
+	// the server is told whether or not the volume it attached for the
+	// mount was mounted
+	var attachedID, mountToken string
+	ctx = ctx.WithValue(
+		context.MountTokenKey,
+		func(volumeID, token string) {
+			attachedID, mountToken = volumeID, token
+		})
+
+	// the volume's usage count is incremented only once it is mounted, so
+	// until then the volume is marked as being mounted
+	d.beginMount(volumeID, volumeName)
+	defer d.endMount(volumeID, volumeName)
+
 	mp, vol, err := d.IntegrationDriver.Mount(
 		ctx.Join(d.ctx), volumeID, volumeName, opts)
+	if mountToken != "" {
+		d.reportMount(ctx.Join(d.ctx), attachedID, mountToken, mp, err)
+	}
 	if err != nil {
 		return "", nil, err
 	}
@@ -159,6 +204,37 @@ func (d *idm) Mount(
 	return mp, vol, err
 }
 
+// reportMount reports to the server whether or not the volume the server
+// attached for a mount was mounted.
+func (d *idm) reportMount(
+	ctx types.Context,
+	volumeID, token, mountPoint string,
+	mountErr error) {
+
+	serviceName, ok := context.ServiceName(ctx)
+	if !ok {
+		return
+	}
+	client, ok := context.Client(ctx)
+	if !ok || client.API() == nil {
+		return
+	}
+
+	req := &types.VolumeMountReportRequest{
+		Token:      token,
+		MountPoint: mountPoint,
+	}
+	if mountErr != nil {
+		req.Error = mountErr.Error()
+	}
+
+	if err := client.API().VolumeMountReport(
+		ctx, serviceName, volumeID, req); err != nil {
+		ctx.WithField("volumeID", volumeID).WithError(err).Warn(
+			"error reporting volume mount")
+	}
+}
+
 func (d *idm) Unmount(
 	ctx types.Context,
 	volumeID, volumeName string,
@@ -269,6 +345,17 @@ func (d *idm) Detach(
 
 }
 
+// GrowFileSystem grows the file system of the volume if the driver grows
+// the file systems of the volumes it mounts. Otherwise nil is returned.
+func (d *idm) GrowFileSystem(ctx types.Context, volumeID string) error {
+	g, ok := d.IntegrationDriver.(types.IntegrationDriverFileSystemGrower)
+	if !ok {
+		return nil
+	}
+	ctx.WithField("volumeID", volumeID).Debug("growing file system")
+	return g.GrowFileSystem(ctx.Join(d.ctx), volumeID)
+}
+
 func (d *idm) initCount(volumeName string) {
 	d.Lock()
 	defer d.Unlock()
@@ -310,6 +397,45 @@ func (d *idm) addCount(volumeName string, delta int) {
 	}).Debug("set count")
 }
 
+// beginMount marks the volume as being mounted. A reconciliation in
+// progress is waited for so that it does not detach the volume once the
+// mount begins.
+func (d *idm) beginMount(volumeID, volumeName string) {
+	d.reconcileLock.Lock()
+	defer d.reconcileLock.Unlock()
+	d.Lock()
+	defer d.Unlock()
+	if d.mounting == nil {
+		d.mounting = map[string]int{}
+	}
+	for _, k := range []string{volumeID, volumeName} {
+		if k != "" {
+			d.mounting[k]++
+		}
+	}
+}
+
+func (d *idm) endMount(volumeID, volumeName string) {
+	d.Lock()
+	defer d.Unlock()
+	for _, k := range []string{volumeID, volumeName} {
+		if k == "" {
+			continue
+		}
+		if d.mounting[k]--; d.mounting[k] <= 0 {
+			delete(d.mounting, k)
+		}
+	}
+}
+
+// isMounting returns a flag indicating whether or not the volume with the
+// provided ID or name is being mounted.
+func (d *idm) isMounting(volumeID, volumeName string) bool {
+	d.RLock()
+	defer d.RUnlock()
+	return d.mounting[volumeID] > 0 || d.mounting[volumeName] > 0
+}
+
 func (d *idm) isCounted(volumeName string) bool {
 	d.RLock()
 	defer d.RUnlock()
@@ -0,0 +1,224 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	apiutils "github.com/codedellemc/libstorage/api/utils"
+)
+
+// mountJournal records the steps of the workflows that mount volumes so
+// that a workflow interrupted by a crash is rolled back when the client is
+// restarted, rather than leaving a volume attached but never mounted.
+type mountJournal struct {
+	sync.Mutex
+	path    string
+	seq     int
+	entries map[string]*mountJournalEntry
+}
+
+// mountJournalEntry is an incomplete mount workflow.
+type mountJournalEntry struct {
+	VolumeID   string            `json:"volumeID,omitempty"`
+	VolumeName string            `json:"volumeName,omitempty"`
+	Steps      []types.MountStep `json:"steps,omitempty"`
+	MountPoint string            `json:"mountPoint,omitempty"`
+	Time       int64             `json:"time"`
+}
+
+func (e *mountJournalEntry) performed(step types.MountStep) bool {
+	for _, s := range e.Steps {
+		if s == step {
+			return true
+		}
+	}
+	return false
+}
+
+func (j *mountJournal) load() error {
+	buf, err := ioutil.ReadFile(j.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err := json.Unmarshal(buf, &j.entries); err != nil {
+		return goof.WithFieldE(
+			"path", j.path, "error reading mount journal", err)
+	}
+	return nil
+}
+
+// save writes the journal. The caller must hold the journal's lock.
+func (j *mountJournal) save() error {
+	buf, err := json.MarshalIndent(j.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(j.path), 0755); err != nil {
+		return err
+	}
+	// write to a temporary file first so that a crash while writing does
+	// not corrupt the journal
+	tmp := j.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, j.path)
+}
+
+// begin records the start of a mount workflow and returns the key of the
+// workflow's entry and the function that records the workflow's steps. The
+// key is made of the volume's ID, or its name if the ID is not yet known,
+// the path at which the volume is mounted, and a sequence number so that
+// concurrent mounts of the same volume do not overwrite each other's
+// entries.
+func (j *mountJournal) begin(
+	ctx types.Context,
+	volumeID, volumeName, mountPath string) (
+	string, func(types.MountStep, string)) {
+
+	j.Lock()
+	defer j.Unlock()
+
+	id := volumeID
+	if id == "" {
+		id = volumeName
+	}
+	var key string
+	for {
+		j.seq++
+		key = fmt.Sprintf("%s@%s#%d", id, mountPath, j.seq)
+		if _, ok := j.entries[key]; !ok {
+			break
+		}
+	}
+
+	e := &mountJournalEntry{
+		VolumeID:   volumeID,
+		VolumeName: volumeName,
+		Time:       time.Now().Unix(),
+	}
+	j.entries[key] = e
+	j.saveOrLog(ctx)
+
+	return key, func(step types.MountStep, value string) {
+		j.Lock()
+		defer j.Unlock()
+
+		e.Steps = append(e.Steps, step)
+		switch step {
+		case types.MountStepAttach:
+			e.VolumeID = value
+		case types.MountStepMount:
+			e.MountPoint = value
+		}
+		j.saveOrLog(ctx)
+	}
+}
+
+// end removes a completed mount workflow from the journal.
+func (j *mountJournal) end(ctx types.Context, key string) {
+	j.Lock()
+	defer j.Unlock()
+	delete(j.entries, key)
+	j.saveOrLog(ctx)
+}
+
+// saveOrLog saves the journal. A journal that cannot be saved does not fail
+// the mount. The caller must hold the journal's lock.
+func (j *mountJournal) saveOrLog(ctx types.Context) {
+	if err := j.save(); err != nil {
+		ctx.WithField("path", j.path).WithError(err).Error(
+			"error saving mount journal")
+	}
+}
+
+func (d *idm) initJournal(ctx types.Context) {
+	path := d.config.GetString(types.ConfigIgVolOpsMountJournal)
+	if path == "" {
+		ctx.Debug("mount journal disabled")
+		return
+	}
+
+	j := &mountJournal{
+		path:    path,
+		entries: map[string]*mountJournalEntry{},
+	}
+	if err := j.load(); err != nil {
+		ctx.WithError(err).Error("error loading mount journal")
+	}
+	d.journal = j
+
+	if len(j.entries) == 0 {
+		return
+	}
+
+	if name, ok := context.ServiceName(ctx); !ok || name == "" {
+		ctx.Info("mount journal recovery disabled; no service name in ctx")
+		return
+	}
+
+	j.Lock()
+	defer j.Unlock()
+
+	for key, e := range j.entries {
+		fields := log.Fields{
+			"volumeID":   e.VolumeID,
+			"volumeName": e.VolumeName,
+			"steps":      e.Steps,
+			"mountPoint": e.MountPoint,
+		}
+		if err := d.rollbackMount(ctx, e); err != nil {
+			ctx.WithFields(fields).WithError(err).Error(
+				"error rolling back interrupted mount")
+			continue
+		}
+		ctx.WithFields(fields).Warn("rolled back interrupted mount")
+		delete(j.entries, key)
+	}
+	j.saveOrLog(ctx)
+}
+
+// rollbackMount undoes the steps of an interrupted mount workflow. The
+// caller that requested the mount received an error when the workflow was
+// interrupted, so the volume is returned to the state it was in before the
+// workflow began.
+func (d *idm) rollbackMount(
+	ctx types.Context, e *mountJournalEntry) error {
+
+	store := apiutils.NewStore()
+
+	// a volume the workflow attached is unmounted and detached by the
+	// integration driver
+	if e.performed(types.MountStepAttach) {
+		_, err := d.IntegrationDriver.Unmount(ctx, e.VolumeID, "", store)
+		return err
+	}
+
+	// otherwise the volume was already attached, and only the mount is
+	// undone
+	if e.performed(types.MountStepMount) && e.MountPoint != "" {
+		client := context.MustClient(ctx)
+		mounted, err := client.OS().IsMounted(ctx, e.MountPoint, store)
+		if err != nil {
+			return err
+		}
+		if mounted {
+			return client.OS().Unmount(ctx, e.MountPoint, store)
+		}
+	}
+
+	return nil
+}
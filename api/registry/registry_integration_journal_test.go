@@ -0,0 +1,71 @@
+package registry
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+func TestMountJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	j := &mountJournal{
+		path:    path.Join(dir, "mount-journal.json"),
+		entries: map[string]*mountJournalEntry{},
+	}
+
+	// concurrent mounts of the same volume have their own entries
+	key1, record1 := j.begin(ctx, "", "data", "/volumes/data")
+	key2, record2 := j.begin(ctx, "", "data", "/volumes/data")
+	key3, _ := j.begin(ctx, "vol-1", "", "")
+	assert.NotEqual(t, key1, key2)
+	assert.Contains(t, key1, "data@/volumes/data")
+	assert.Contains(t, key3, "vol-1@")
+	assert.Len(t, j.entries, 3)
+
+	record1(types.MountStepAttach, "vol-2")
+	record2(types.MountStepMount, "/volumes/data")
+	assert.Equal(t, "vol-2", j.entries[key1].VolumeID)
+	assert.True(t, j.entries[key1].performed(types.MountStepAttach))
+	assert.False(t, j.entries[key1].performed(types.MountStepMount))
+	assert.Equal(t, "", j.entries[key2].VolumeID)
+	assert.Equal(t, "/volumes/data", j.entries[key2].MountPoint)
+
+	// a completed mount removes only its own entry
+	j.end(ctx, key2)
+	assert.Len(t, j.entries, 2)
+	assert.NotNil(t, j.entries[key1])
+
+	// the entries are read back when the journal is loaded
+	loaded := &mountJournal{
+		path:    j.path,
+		entries: map[string]*mountJournalEntry{},
+	}
+	assert.NoError(t, loaded.load())
+	if !assert.Len(t, loaded.entries, 2) {
+		t.FailNow()
+	}
+	e := loaded.entries[key1]
+	if !assert.NotNil(t, e) {
+		t.FailNow()
+	}
+	assert.Equal(t, "vol-2", e.VolumeID)
+	assert.Equal(t, "data", e.VolumeName)
+	assert.Equal(t, []types.MountStep{types.MountStepAttach}, e.Steps)
+
+	// a new mount does not reuse the key of a loaded entry
+	key4, _ := loaded.begin(ctx, "", "data", "/volumes/data")
+	assert.NotEqual(t, key1, key4)
+	assert.Len(t, loaded.entries, 3)
+}
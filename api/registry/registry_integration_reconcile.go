@@ -0,0 +1,357 @@
+package registry
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	apiutils "github.com/codedellemc/libstorage/api/utils"
+)
+
+var (
+	// sysBlockDir is the directory in which the kernel describes the
+	// instance's block devices and their partitions and holders.
+	sysBlockDir = "/sys/block"
+
+	// procSwaps is the file that lists the instance's swap devices.
+	procSwaps = "/proc/swaps"
+)
+
+func (d *idm) initReconciler(ctx types.Context) {
+	interval := d.reconcileInterval()
+	if interval <= 0 {
+		ctx.Debug("reconciliation disabled")
+		return
+	}
+
+	if name, ok := context.ServiceName(ctx); !ok || name == "" {
+		ctx.Info("reconciliation disabled; no service name in ctx")
+		return
+	}
+
+	ctx.WithFields(log.Fields{
+		"interval": interval,
+		"dryRun":   d.reconcileDryRun(),
+	}).Info("reconciling volumes periodically")
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := d.Reconcile(ctx, d.reconcileDryRun()); err != nil {
+					ctx.WithError(err).Error("error reconciling volumes")
+				}
+			}
+		}
+	}()
+}
+
+// Reconcile compares the volumes the storage driver reports are attached to
+// the instance with the instance's local devices and mounts and with the
+// integration driver's usage counts. Attached volumes that the integration
+// driver has mounted and that are no longer mounted or used are detached, and
+// the mounts of such volumes that are no longer attached are unmounted.
+// Volumes the integration driver has not mounted, and volumes it is in the
+// midst of mounting, are never detached or unmounted.
+//
+// An orphan is reconciled only if it was also found by the previous
+// reconciliation so that volumes in the midst of being mounted or unmounted
+// are not mistaken for orphans. Local devices for which the storage driver
+// reports no attachment are only reported. If dryRun is true none of the
+// orphans are reconciled.
+func (d *idm) Reconcile(
+	ctx types.Context, dryRun bool) (*types.ReconcileReport, error) {
+
+	d.reconcileLock.Lock()
+	defer d.reconcileLock.Unlock()
+
+	ctx = ctx.Join(d.ctx)
+	client := context.MustClient(ctx)
+
+	report := &types.ReconcileReport{
+		Time:   time.Now().Unix(),
+		DryRun: dryRun,
+	}
+
+	vols, err := client.Storage().Volumes(
+		ctx,
+		&types.VolumesOpts{
+			Attachments: types.VolAttReqWithDevMapOnlyVolsAttachedToInstance,
+			Opts:        apiutils.NewStore(),
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	ld, err := client.Executor().LocalDevices(
+		ctx,
+		&types.LocalDevicesOpts{
+			ScanType: types.DeviceScanQuick,
+			Opts:     apiutils.NewStore(),
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	mounts, err := client.OS().Mounts(ctx, "", "", apiutils.NewStore())
+	if err != nil {
+		return nil, err
+	}
+
+	mountDir := d.config.GetString(types.ConfigIgVolOpsMountPath)
+	if mountDir != "" {
+		mountDir = path.Clean(mountDir)
+	}
+	usedDevices := map[string]bool{}
+	mountedNames := map[string]bool{}
+	for _, m := range mounts {
+		addUsedDevice(usedDevices, m.Source)
+		if mountDir != "" && path.Dir(m.MountPoint) == mountDir {
+			mountedNames[path.Base(m.MountPoint)] = true
+		}
+	}
+	for _, device := range swapDevices() {
+		addUsedDevice(usedDevices, device)
+	}
+
+	attached := map[string]*types.Volume{}
+	attachedNames := map[string]bool{}
+	for _, v := range vols {
+		attached[v.ID] = v
+		attachedNames[v.Name] = true
+
+		// volumes attached by other means may be used in ways that
+		// cannot be detected, such as by a database that opens the
+		// device, so only the volumes the integration driver has
+		// mounted are considered. volumes being mounted are not yet
+		// mounted or counted, but are in use.
+		if !d.isCounted(v.Name) || d.isMounting(v.ID, v.Name) {
+			continue
+		}
+
+		device := ""
+		if ld != nil {
+			device = ld.DeviceMap[v.ID]
+		}
+		if device == "" && len(v.Attachments) > 0 {
+			device = v.Attachments[0].DeviceName
+		}
+		if d.isVolumeInUse(v, device, usedDevices, mountedNames) {
+			continue
+		}
+		report.Orphans = append(report.Orphans, &types.Orphan{
+			Type:       types.OrphanAttachment,
+			VolumeID:   v.ID,
+			VolumeName: v.Name,
+			DeviceName: device,
+		})
+	}
+
+	report.Orphans = append(
+		report.Orphans, d.mountOrphans(mounts, mountDir, attachedNames)...)
+
+	if ld != nil {
+		for volumeID, device := range ld.DeviceMap {
+			if _, ok := attached[volumeID]; !ok {
+				report.Orphans = append(report.Orphans, &types.Orphan{
+					Type:       types.OrphanDevice,
+					VolumeID:   volumeID,
+					DeviceName: device,
+				})
+			}
+		}
+	}
+
+	found := map[string]bool{}
+	for _, o := range report.Orphans {
+		key := orphanKey(o)
+		found[key] = true
+
+		fields := log.Fields{
+			"type":       o.Type,
+			"volumeID":   o.VolumeID,
+			"volumeName": o.VolumeName,
+			"deviceName": o.DeviceName,
+			"mountPoint": o.MountPoint,
+		}
+
+		if dryRun || o.Type == types.OrphanDevice || !d.orphans[key] {
+			ctx.WithFields(fields).Info("found orphan")
+			continue
+		}
+
+		if err := d.reconcileOrphan(ctx, o); err != nil {
+			o.Error = err.Error()
+			ctx.WithFields(fields).WithError(err).Error(
+				"error reconciling orphan")
+			continue
+		}
+		o.Reconciled = true
+		delete(found, key)
+		ctx.WithFields(fields).Info("reconciled orphan")
+	}
+	d.orphans = found
+
+	return report, nil
+}
+
+// mountOrphans returns the mounts in the volume mount path of volumes that
+// are no longer attached. The mount path is shared by the services of the
+// instance, so only the mounts of the volumes the integration driver has
+// mounted are considered.
+func (d *idm) mountOrphans(
+	mounts []*types.MountInfo,
+	mountDir string,
+	attachedNames map[string]bool) []*types.Orphan {
+
+	var orphans []*types.Orphan
+	for _, m := range mounts {
+		if mountDir == "" || path.Dir(m.MountPoint) != mountDir {
+			continue
+		}
+		name := path.Base(m.MountPoint)
+		if attachedNames[name] || !d.isCounted(name) ||
+			d.isMounting("", name) {
+			continue
+		}
+		orphans = append(orphans, &types.Orphan{
+			Type:       types.OrphanMount,
+			VolumeName: name,
+			DeviceName: m.Source,
+			MountPoint: m.MountPoint,
+		})
+	}
+	return orphans
+}
+
+// isVolumeInUse returns a flag indicating whether or not the attached volume
+// is mounted, has been mounted by the integration driver and not yet
+// unmounted, or has a device that is in use. A volume whose device is not
+// known is considered in use.
+func (d *idm) isVolumeInUse(
+	v *types.Volume,
+	device string,
+	usedDevices, mountedNames map[string]bool) bool {
+
+	if mountedNames[v.Name] {
+		return true
+	}
+
+	d.RLock()
+	c := d.used[v.Name]
+	d.RUnlock()
+
+	if c > 0 {
+		return true
+	}
+	return device == "" || isDeviceInUse(device, usedDevices)
+}
+
+// isDeviceInUse returns a flag indicating whether or not the device or one
+// of its partitions is a mount source or swap device or is held by another
+// device, such as an LVM logical volume or a device-mapper target. A device
+// that is not described under /sys/block is considered in use.
+func isDeviceInUse(device string, usedDevices map[string]bool) bool {
+	if p, err := filepath.EvalSymlinks(device); err == nil {
+		device = p
+	}
+
+	dir := path.Join(sysBlockDir, path.Base(device))
+	if _, err := os.Stat(dir); err != nil {
+		return true
+	}
+
+	dirs := []string{dir}
+	if fis, err := ioutil.ReadDir(dir); err == nil {
+		for _, fi := range fis {
+			p := path.Join(dir, fi.Name())
+			if _, err := os.Stat(path.Join(p, "partition")); err == nil {
+				dirs = append(dirs, p)
+			}
+		}
+	}
+
+	for _, p := range dirs {
+		if usedDevices[path.Join("/dev", path.Base(p))] {
+			return true
+		}
+		holders, err := ioutil.ReadDir(path.Join(p, "holders"))
+		if err == nil && len(holders) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// addUsedDevice records the device and the device to which it links, if
+// any, as in use.
+func addUsedDevice(usedDevices map[string]bool, device string) {
+	usedDevices[device] = true
+	if p, err := filepath.EvalSymlinks(device); err == nil {
+		usedDevices[p] = true
+	}
+}
+
+// swapDevices returns the instance's swap devices.
+func swapDevices() []string {
+	f, err := os.Open(procSwaps)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var devices []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || !strings.HasPrefix(fields[0], "/dev/") {
+			continue
+		}
+		devices = append(devices, fields[0])
+	}
+	return devices
+}
+
+func (d *idm) reconcileOrphan(ctx types.Context, o *types.Orphan) error {
+	client := context.MustClient(ctx)
+	switch o.Type {
+	case types.OrphanAttachment:
+		_, err := client.Storage().VolumeDetach(
+			ctx,
+			o.VolumeID,
+			&types.VolumeDetachOpts{Opts: apiutils.NewStore()})
+		return err
+	case types.OrphanMount:
+		return client.OS().Unmount(ctx, o.MountPoint, apiutils.NewStore())
+	}
+	return nil
+}
+
+func orphanKey(o *types.Orphan) string {
+	return string(o.Type) + ":" + o.VolumeID + ":" + o.MountPoint
+}
+
+func (d *idm) reconcileInterval() time.Duration {
+	dur, err := time.ParseDuration(
+		d.config.GetString(types.ConfigIgVolOpsReconcileInterval))
+	if err != nil {
+		return 0
+	}
+	return dur
+}
+
+func (d *idm) reconcileDryRun() bool {
+	return d.config.GetBool(types.ConfigIgVolOpsReconcileDryRun)
+}
@@ -0,0 +1,159 @@
+package registry
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// newTestSysBlock creates a /sys/block tree with an unused disk, xvdf, a
+// disk with a partition, xvdg, a disk held by a device-mapper device, xvdh,
+// and a disk whose partition is held by a device-mapper device, xvdi.
+func newTestSysBlock(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "sysblock")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	for _, p := range []string{
+		"xvdf/holders",
+		"xvdg/holders",
+		"xvdg/xvdg1/holders",
+		"xvdh/holders/dm-0",
+		"xvdi/holders",
+		"xvdi/xvdi1/holders/dm-1",
+	} {
+		if !assert.NoError(t, os.MkdirAll(path.Join(dir, p), 0755)) {
+			t.FailNow()
+		}
+	}
+	for _, p := range []string{"xvdg/xvdg1/partition", "xvdi/xvdi1/partition"} {
+		if !assert.NoError(t, ioutil.WriteFile(
+			path.Join(dir, p), []byte("1\n"), 0644)) {
+			t.FailNow()
+		}
+	}
+	return dir
+}
+
+func TestIsDeviceInUse(t *testing.T) {
+	dir := newTestSysBlock(t)
+	defer os.RemoveAll(dir)
+
+	defer func(v string) { sysBlockDir = v }(sysBlockDir)
+	sysBlockDir = dir
+
+	used := map[string]bool{}
+	assert.False(t, isDeviceInUse("/dev/xvdf", used))
+	assert.False(t, isDeviceInUse("/dev/xvdg", used))
+	assert.True(t, isDeviceInUse("/dev/xvdh", used))
+	assert.True(t, isDeviceInUse("/dev/xvdi", used))
+
+	// a device that cannot be found is assumed to be in use
+	assert.True(t, isDeviceInUse("/dev/xvdz", used))
+
+	// a disk is in use if it or one of its partitions is mounted
+	used["/dev/xvdf"] = true
+	used["/dev/xvdg1"] = true
+	assert.True(t, isDeviceInUse("/dev/xvdf", used))
+	assert.True(t, isDeviceInUse("/dev/xvdg", used))
+}
+
+func TestSwapDevices(t *testing.T) {
+	f, err := ioutil.TempFile("", "swaps")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.Remove(f.Name())
+	f.WriteString(
+		"Filename\t\t\t\tType\t\tSize\tUsed\tPriority\n" +
+			"/dev/xvdg1                              partition\t" +
+			"1048572\t0\t-2\n" +
+			"/swapfile                               file\t\t" +
+			"524284\t0\t-3\n")
+	f.Close()
+
+	defer func(v string) { procSwaps = v }(procSwaps)
+	procSwaps = f.Name()
+	assert.Equal(t, []string{"/dev/xvdg1"}, swapDevices())
+
+	procSwaps = f.Name() + ".missing"
+	assert.Empty(t, swapDevices())
+}
+
+func TestIsVolumeInUse(t *testing.T) {
+	dir := newTestSysBlock(t)
+	defer os.RemoveAll(dir)
+
+	defer func(v string) { sysBlockDir = v }(sysBlockDir)
+	sysBlockDir = dir
+
+	d := &idm{used: map[string]int{"vol1": 0, "vol2": 1}}
+	used := map[string]bool{}
+	mounted := map[string]bool{}
+
+	vol1 := &types.Volume{Name: "vol1"}
+	vol2 := &types.Volume{Name: "vol2"}
+
+	// an unmounted volume whose device is not used
+	assert.False(t, d.isVolumeInUse(vol1, "/dev/xvdf", used, mounted))
+
+	// a volume whose device is unknown or held by another device
+	assert.True(t, d.isVolumeInUse(vol1, "", used, mounted))
+	assert.True(t, d.isVolumeInUse(vol1, "/dev/xvdh", used, mounted))
+
+	// a volume still mounted by the integration driver
+	assert.True(t, d.isVolumeInUse(vol2, "/dev/xvdf", used, mounted))
+
+	// a volume mounted in the volume mount path
+	mounted["vol1"] = true
+	assert.True(t, d.isVolumeInUse(vol1, "/dev/xvdf", used, mounted))
+}
+
+func TestMountOrphans(t *testing.T) {
+	d := &idm{used: map[string]int{"vol1": 1, "vol2": 0}}
+	mounts := []*types.MountInfo{
+		{Source: "/dev/xvdf", MountPoint: "/var/lib/libstorage/volumes/vol1"},
+		{Source: "/dev/xvdg", MountPoint: "/var/lib/libstorage/volumes/vol2"},
+		{Source: "/dev/xvdh", MountPoint: "/var/lib/libstorage/volumes/vol3"},
+		{Source: "/dev/xvdi", MountPoint: "/mnt/vol1"},
+	}
+
+	// vol3 is mounted by another service's integration driver
+	orphans := d.mountOrphans(
+		mounts, "/var/lib/libstorage/volumes", map[string]bool{"vol2": true})
+	if assert.Len(t, orphans, 1) {
+		assert.Equal(t, types.OrphanMount, orphans[0].Type)
+		assert.Equal(t, "vol1", orphans[0].VolumeName)
+		assert.Equal(t, "/dev/xvdf", orphans[0].DeviceName)
+	}
+
+	assert.Empty(t, d.mountOrphans(mounts, "", nil))
+
+	// vol1 is being mounted again
+	d.beginMount("", "vol1")
+	assert.Empty(t, d.mountOrphans(
+		mounts, "/var/lib/libstorage/volumes", map[string]bool{"vol2": true}))
+}
+
+func TestMounting(t *testing.T) {
+	d := &idm{}
+	assert.False(t, d.isMounting("vol-1", "vol1"))
+
+	d.beginMount("vol-1", "vol1")
+	d.beginMount("", "vol1")
+	assert.True(t, d.isMounting("vol-1", ""))
+	assert.True(t, d.isMounting("", "vol1"))
+
+	d.endMount("vol-1", "vol1")
+	assert.False(t, d.isMounting("vol-1", ""))
+	assert.True(t, d.isMounting("", "vol1"))
+
+	d.endMount("", "vol1")
+	assert.False(t, d.isMounting("vol-1", "vol1"))
+	assert.Empty(t, d.mounting)
+}
@@ -4,7 +4,9 @@ import (
 	"os"
 	"path"
 
+	"github.com/codedellemc/libstorage/api/context"
 	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils/metrics"
 )
 
 type odm struct {
@@ -18,38 +20,63 @@ func NewOSDriverManager(
 	return &odm{OSDriver: d}
 }
 
+// withComponent returns a context that logs as the "os.<op>" component.
+func (d *odm) withComponent(ctx types.Context, op string) types.Context {
+	return context.WithComponent(ctx.Join(d.Context), "os."+op)
+}
+
+// startOp times an OS driver operation.
+func (d *odm) startOp(ctx types.Context, op string) func(error) {
+	return metrics.StartDriverOp(
+		ctx, metrics.KindOS, d.OSDriver.Name(), op)
+}
+
 func (d *odm) Mounts(
 	ctx types.Context,
 	deviceName, mountPoint string,
 	opts types.Store) ([]*types.MountInfo, error) {
-	ctx = ctx.Join(d.Context)
+	ctx = d.withComponent(ctx, "mounts")
 
-	return d.OSDriver.Mounts(ctx.Join(d.Context), deviceName, mountPoint, opts)
+	finish := d.startOp(ctx, "Mounts")
+	mounts, err := d.OSDriver.Mounts(ctx, deviceName, mountPoint, opts)
+	finish(err)
+	return mounts, err
 }
 
 func (d *odm) Mount(
 	ctx types.Context,
 	deviceName, mountPoint string,
 	opts *types.DeviceMountOpts) error {
-	ctx = ctx.Join(d.Context)
+	ctx = d.withComponent(ctx, "mount")
 
-	return d.OSDriver.Mount(ctx.Join(d.Context), deviceName, mountPoint, opts)
+	finish := d.startOp(ctx, "Mount")
+	err := d.OSDriver.Mount(ctx, deviceName, mountPoint, opts)
+	finish(err)
+	return err
 }
 
 func (d *odm) Unmount(
 	ctx types.Context,
 	mountPoint string,
 	opts types.Store) error {
+	ctx = d.withComponent(ctx, "unmount")
 
-	return d.OSDriver.Unmount(ctx.Join(d.Context), mountPoint, opts)
+	finish := d.startOp(ctx, "Unmount")
+	err := d.OSDriver.Unmount(ctx, mountPoint, opts)
+	finish(err)
+	return err
 }
 
 func (d *odm) IsMounted(
 	ctx types.Context,
 	mountPoint string,
 	opts types.Store) (bool, error) {
+	ctx = d.withComponent(ctx, "mounts")
 
-	return d.OSDriver.IsMounted(ctx.Join(d.Context), mountPoint, opts)
+	finish := d.startOp(ctx, "IsMounted")
+	mounted, err := d.OSDriver.IsMounted(ctx, mountPoint, opts)
+	finish(err)
+	return mounted, err
 }
 
 func (d *odm) Format(
@@ -57,7 +84,7 @@ func (d *odm) Format(
 	deviceName string,
 	opts *types.DeviceFormatOpts) error {
 
-	ctx = ctx.Join(d.Context)
+	ctx = d.withComponent(ctx, "format")
 
 	if !path.IsAbs(deviceName) {
 		return nil
@@ -67,5 +94,47 @@ func (d *odm) Format(
 		return nil
 	}
 
-	return d.OSDriver.Format(ctx, deviceName, opts)
+	finish := d.startOp(ctx, "Format")
+	err := d.OSDriver.Format(ctx, deviceName, opts)
+	finish(err)
+	return err
+}
+
+// MirrorAssemble assembles the devices into a mirror if the driver supports
+// mirroring devices. Otherwise types.ErrNotImplemented is returned.
+func (d *odm) MirrorAssemble(
+	ctx types.Context,
+	name string,
+	devices []string,
+	opts types.Store) (string, error) {
+
+	od, ok := d.OSDriver.(types.ProvidesDeviceMirroring)
+	if !ok {
+		return "", types.ErrNotImplemented
+	}
+	ctx = d.withComponent(ctx, "mirror")
+
+	finish := d.startOp(ctx, "MirrorAssemble")
+	device, err := od.MirrorAssemble(ctx, name, devices, opts)
+	finish(err)
+	return device, err
+}
+
+// MirrorStop stops a mirror if the driver supports mirroring devices.
+// Otherwise types.ErrNotImplemented is returned.
+func (d *odm) MirrorStop(
+	ctx types.Context,
+	name string,
+	opts types.Store) error {
+
+	od, ok := d.OSDriver.(types.ProvidesDeviceMirroring)
+	if !ok {
+		return types.ErrNotImplemented
+	}
+	ctx = d.withComponent(ctx, "mirror")
+
+	finish := d.startOp(ctx, "MirrorStop")
+	err := od.MirrorStop(ctx, name, opts)
+	finish(err)
+	return err
 }
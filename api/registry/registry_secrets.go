@@ -0,0 +1,65 @@
+package registry
+
+import (
+	"strings"
+	"sync"
+
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+var (
+	secretsProviders    = map[string]types.SecretsProvider{}
+	secretsProvidersRWL = &sync.Mutex{}
+)
+
+// getSecretsProvider returns the initialized secrets provider with the
+// specified name. A provider is initialized with the config provided the
+// first time it is requested.
+func getSecretsProvider(
+	ctx types.Context,
+	config gofig.Config,
+	name string) (types.SecretsProvider, error) {
+
+	name = strings.ToLower(name)
+
+	secretsProvidersRWL.Lock()
+	defer secretsProvidersRWL.Unlock()
+
+	if p, ok := secretsProviders[name]; ok {
+		return p, nil
+	}
+
+	p, err := NewSecretsProvider(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.Init(ctx, config); err != nil {
+		return nil, err
+	}
+
+	secretsProviders[name] = p
+	return p, nil
+}
+
+// ResolveSecret returns the value of the secret referenced by the provided
+// value in the format secret://provider/path#key.
+func ResolveSecret(
+	ctx types.Context,
+	config gofig.Config,
+	ref string) (string, error) {
+
+	provider, path, key, ok := types.ParseSecretRef(ref)
+	if !ok {
+		return "", goof.New("invalid secret reference")
+	}
+
+	p, err := getSecretsProvider(ctx, config, provider)
+	if err != nil {
+		return "", err
+	}
+
+	return p.Secret(ctx, path, key)
+}
@@ -1,6 +1,11 @@
 package registry
 
-import "github.com/codedellemc/libstorage/api/types"
+import (
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils/metrics"
+	"github.com/codedellemc/libstorage/api/utils/tracing"
+)
 
 type sdm struct {
 	types.StorageDriver
@@ -8,8 +13,8 @@ type sdm struct {
 }
 
 type sdmWithLogin struct {
-	types.StorageDriverWithLogin
-	types.Context
+	*sdm
+	login types.StorageDriverWithLogin
 }
 
 // NewStorageDriverManager returns a new storage driver manager.
@@ -21,7 +26,25 @@ func NewStorageDriverManager(
 // NewStorageDriverManagerWithLogin returns a new storage driver manager.
 func NewStorageDriverManagerWithLogin(
 	d types.StorageDriverWithLogin) types.StorageDriverWithLogin {
-	return &sdmWithLogin{StorageDriverWithLogin: d}
+	return &sdmWithLogin{sdm: &sdm{StorageDriver: d}, login: d}
+}
+
+// startSpan starts a trace span for a storage driver operation and times the
+// operation. The span's context logs as the "driver.<name>" component.
+func (d *sdm) startSpan(
+	ctx types.Context, op string) (types.Context, func(error)) {
+
+	name := d.StorageDriver.Name()
+	ctx, finishSpan := tracing.StartSpan(
+		context.WithComponent(ctx.Join(d.Context), "driver."+name),
+		"driver."+op,
+		map[string]interface{}{"driver": name})
+	finishOp := metrics.StartDriverOp(ctx, metrics.KindStorage, name, op)
+
+	return ctx, func(err error) {
+		finishOp(err)
+		finishSpan(err)
+	}
 }
 
 func (d *sdm) API() types.APIClient {
@@ -38,30 +61,110 @@ func (d *sdm) XCLI() types.StorageExecutorCLI {
 	return nil
 }
 
+func (d *sdm) Capabilities(
+	ctx types.Context) (*types.StorageCapabilities, error) {
+
+	sd, ok := d.StorageDriver.(types.ProvidesStorageCapabilities)
+	if !ok {
+		return &types.StorageCapabilities{}, nil
+	}
+	caps, err := sd.Capabilities(ctx.Join(d.Context))
+	if err != nil || caps == nil {
+		return caps, err
+	}
+	// a volume can be resized only if the driver implements VolumeResize,
+	// whatever the driver reports. a driver that proxies another server
+	// reports the capabilities the server has already checked and resizes
+	// volumes through its API client instead.
+	if _, ok := d.StorageDriver.(types.ProvidesAPIClient); ok {
+		return caps, nil
+	}
+	if _, ok := d.StorageDriver.(types.ProvidesVolumeResize); !ok {
+		caps.Resize = false
+	}
+	return caps, nil
+}
+
+func (d *sdm) HealthCheck(ctx types.Context) error {
+
+	if sd, ok := d.StorageDriver.(types.ProvidesHealthCheck); ok {
+		ctx, finish := d.startSpan(ctx, "HealthCheck")
+		err := sd.HealthCheck(ctx)
+		finish(err)
+		return err
+	}
+	return nil
+}
+
+func (d *sdm) VolumeCreateValidate(
+	ctx types.Context,
+	name string,
+	opts *types.VolumeCreateOpts) error {
+
+	if sd, ok := d.StorageDriver.(types.ProvidesValidation); ok {
+		return sd.VolumeCreateValidate(ctx.Join(d.Context), name, opts)
+	}
+	return nil
+}
+
+func (d *sdm) VolumeAttachValidate(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeAttachOpts) error {
+
+	if sd, ok := d.StorageDriver.(types.ProvidesValidation); ok {
+		return sd.VolumeAttachValidate(ctx.Join(d.Context), volumeID, opts)
+	}
+	return nil
+}
+
+func (d *sdm) VolumeRemoveValidate(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeRemoveOpts) error {
+
+	if sd, ok := d.StorageDriver.(types.ProvidesValidation); ok {
+		return sd.VolumeRemoveValidate(ctx.Join(d.Context), volumeID, opts)
+	}
+	return nil
+}
+
 func (d *sdm) NextDeviceInfo(
 	ctx types.Context) (*types.NextDeviceInfo, error) {
 
-	return d.StorageDriver.NextDeviceInfo(ctx.Join(d.Context))
+	ctx, finish := d.startSpan(ctx, "NextDeviceInfo")
+	info, err := d.StorageDriver.NextDeviceInfo(ctx)
+	finish(err)
+	return info, err
 }
 
 func (d *sdm) Type(
 	ctx types.Context) (types.StorageType, error) {
 
-	return d.StorageDriver.Type(ctx.Join(d.Context))
+	ctx, finish := d.startSpan(ctx, "Type")
+	st, err := d.StorageDriver.Type(ctx)
+	finish(err)
+	return st, err
 }
 
 func (d *sdm) InstanceInspect(
 	ctx types.Context,
 	opts types.Store) (*types.Instance, error) {
 
-	return d.StorageDriver.InstanceInspect(ctx.Join(d.Context), opts)
+	ctx, finish := d.startSpan(ctx, "InstanceInspect")
+	i, err := d.StorageDriver.InstanceInspect(ctx, opts)
+	finish(err)
+	return i, err
 }
 
 func (d *sdm) Volumes(
 	ctx types.Context,
 	opts *types.VolumesOpts) ([]*types.Volume, error) {
 
-	return d.StorageDriver.Volumes(ctx.Join(d.Context), opts)
+	ctx, finish := d.startSpan(ctx, "Volumes")
+	vols, err := d.StorageDriver.Volumes(ctx, opts)
+	finish(err)
+	return vols, err
 }
 
 func (d *sdm) VolumeInspect(
@@ -69,7 +172,10 @@ func (d *sdm) VolumeInspect(
 	volumeID string,
 	opts *types.VolumeInspectOpts) (*types.Volume, error) {
 
-	return d.StorageDriver.VolumeInspect(ctx.Join(d.Context), volumeID, opts)
+	ctx, finish := d.startSpan(ctx, "VolumeInspect")
+	vol, err := d.StorageDriver.VolumeInspect(ctx, volumeID, opts)
+	finish(err)
+	return vol, err
 }
 
 func (d *sdm) VolumeCreate(
@@ -77,7 +183,10 @@ func (d *sdm) VolumeCreate(
 	name string,
 	opts *types.VolumeCreateOpts) (*types.Volume, error) {
 
-	return d.StorageDriver.VolumeCreate(ctx.Join(d.Context), name, opts)
+	ctx, finish := d.startSpan(ctx, "VolumeCreate")
+	vol, err := d.StorageDriver.VolumeCreate(ctx, name, opts)
+	finish(err)
+	return vol, err
 }
 
 func (d *sdm) VolumeCreateFromSnapshot(
@@ -86,8 +195,11 @@ func (d *sdm) VolumeCreateFromSnapshot(
 	volumeName string,
 	opts *types.VolumeCreateOpts) (*types.Volume, error) {
 
-	return d.StorageDriver.VolumeCreateFromSnapshot(
-		ctx.Join(d.Context), snapshotID, volumeName, opts)
+	ctx, finish := d.startSpan(ctx, "VolumeCreateFromSnapshot")
+	vol, err := d.StorageDriver.VolumeCreateFromSnapshot(
+		ctx, snapshotID, volumeName, opts)
+	finish(err)
+	return vol, err
 }
 
 func (d *sdm) VolumeCopy(
@@ -96,8 +208,10 @@ func (d *sdm) VolumeCopy(
 	volumeName string,
 	opts types.Store) (*types.Volume, error) {
 
-	return d.StorageDriver.VolumeCopy(
-		ctx.Join(d.Context), volumeID, volumeName, opts)
+	ctx, finish := d.startSpan(ctx, "VolumeCopy")
+	vol, err := d.StorageDriver.VolumeCopy(ctx, volumeID, volumeName, opts)
+	finish(err)
+	return vol, err
 }
 
 func (d *sdm) VolumeSnapshot(
@@ -106,8 +220,261 @@ func (d *sdm) VolumeSnapshot(
 	snapshotName string,
 	opts types.Store) (*types.Snapshot, error) {
 
-	return d.StorageDriver.VolumeSnapshot(
-		ctx.Join(d.Context), volumeID, snapshotName, opts)
+	ctx, finish := d.startSpan(ctx, "VolumeSnapshot")
+	snap, err := d.StorageDriver.VolumeSnapshot(
+		ctx, volumeID, snapshotName, opts)
+	finish(err)
+	return snap, err
+}
+
+// SnapshotGroupCreate snapshots the volumes with the driver if it supports
+// snapshot groups. Otherwise, or if the driver cannot snapshot the volumes
+// together and returns ErrNotImplemented, the volumes are snapshotted one
+// after another, and if a volume cannot be snapshotted the group's other
+// snapshots are removed.
+func (d *sdm) SnapshotGroupCreate(
+	ctx types.Context,
+	volumeIDs []string,
+	snapshotName string,
+	opts types.Store) (*types.SnapshotGroup, error) {
+
+	ctx, finish := d.startSpan(ctx, "SnapshotGroupCreate")
+
+	var (
+		group *types.SnapshotGroup
+		err   = types.ErrNotImplemented
+	)
+	if sd, ok := d.StorageDriver.(types.ProvidesSnapshotGroups); ok {
+		group, err = sd.SnapshotGroupCreate(
+			ctx, volumeIDs, snapshotName, opts)
+	}
+	if err == types.ErrNotImplemented {
+		group, err = d.snapshotVolumes(ctx, volumeIDs, snapshotName, opts)
+	}
+	finish(err)
+	return group, err
+}
+
+// VolumeRecycle moves the volume to the recycle bin if the driver supports
+// recycling volumes. Otherwise types.ErrNotImplemented is returned.
+func (d *sdm) VolumeRecycle(
+	ctx types.Context,
+	volumeID string,
+	opts types.Store) error {
+
+	sd, ok := d.StorageDriver.(types.ProvidesVolumeRecycling)
+	if !ok {
+		return types.ErrNotImplemented
+	}
+	ctx, finish := d.startSpan(ctx, "VolumeRecycle")
+	err := sd.VolumeRecycle(ctx, volumeID, opts)
+	finish(err)
+	return err
+}
+
+// RecycledVolumes returns the volumes in the recycle bin if the driver
+// supports recycling volumes. Otherwise types.ErrNotImplemented is returned.
+func (d *sdm) RecycledVolumes(
+	ctx types.Context,
+	opts types.Store) ([]*types.Volume, error) {
+
+	sd, ok := d.StorageDriver.(types.ProvidesVolumeRecycling)
+	if !ok {
+		return nil, types.ErrNotImplemented
+	}
+	ctx, finish := d.startSpan(ctx, "RecycledVolumes")
+	vols, err := sd.RecycledVolumes(ctx, opts)
+	finish(err)
+	return vols, err
+}
+
+// RecycledVolumeRestore restores the volume from the recycle bin if the
+// driver supports recycling volumes. Otherwise types.ErrNotImplemented is
+// returned.
+func (d *sdm) RecycledVolumeRestore(
+	ctx types.Context,
+	volumeID string,
+	opts types.Store) (*types.Volume, error) {
+
+	sd, ok := d.StorageDriver.(types.ProvidesVolumeRecycling)
+	if !ok {
+		return nil, types.ErrNotImplemented
+	}
+	ctx, finish := d.startSpan(ctx, "RecycledVolumeRestore")
+	vol, err := sd.RecycledVolumeRestore(ctx, volumeID, opts)
+	finish(err)
+	return vol, err
+}
+
+// RecycledVolumePurge permanently removes the volume from the recycle bin if
+// the driver supports recycling volumes. Otherwise types.ErrNotImplemented
+// is returned.
+func (d *sdm) RecycledVolumePurge(
+	ctx types.Context,
+	volumeID string,
+	opts types.Store) error {
+
+	sd, ok := d.StorageDriver.(types.ProvidesVolumeRecycling)
+	if !ok {
+		return types.ErrNotImplemented
+	}
+	ctx, finish := d.startSpan(ctx, "RecycledVolumePurge")
+	err := sd.RecycledVolumePurge(ctx, volumeID, opts)
+	finish(err)
+	return err
+}
+
+// VolumeImport imports the volume with the backend-native identifier if the
+// driver supports importing volumes. Otherwise types.ErrNotImplemented is
+// returned.
+func (d *sdm) VolumeImport(
+	ctx types.Context,
+	nativeID, volumeName string,
+	opts types.Store) (*types.Volume, error) {
+
+	sd, ok := d.StorageDriver.(types.ProvidesVolumeImport)
+	if !ok {
+		return nil, types.ErrNotImplemented
+	}
+	ctx, finish := d.startSpan(ctx, "VolumeImport")
+	v, err := sd.VolumeImport(ctx, nativeID, volumeName, opts)
+	finish(err)
+	return v, err
+}
+
+// VolumeUnmanage releases the volume from libStorage management if the
+// driver supports it. Otherwise types.ErrNotImplemented is returned.
+func (d *sdm) VolumeUnmanage(
+	ctx types.Context,
+	volumeID string,
+	opts types.Store) error {
+
+	sd, ok := d.StorageDriver.(types.ProvidesVolumeUnmanage)
+	if !ok {
+		return types.ErrNotImplemented
+	}
+	ctx, finish := d.startSpan(ctx, "VolumeUnmanage")
+	err := sd.VolumeUnmanage(ctx, volumeID, opts)
+	finish(err)
+	return err
+}
+
+// StampsVolumeMetadata returns a flag indicating whether or not the driver
+// stamps the volumes it creates with metadata. A false value is returned if
+// the driver does not support stamping volumes.
+func (d *sdm) StampsVolumeMetadata(ctx types.Context) bool {
+	sd, ok := d.StorageDriver.(types.ProvidesVolumeMetadata)
+	return ok && sd.StampsVolumeMetadata(ctx)
+}
+
+// VolumeResize changes the size of the volume if the driver supports it.
+// Otherwise types.ErrNotImplemented is returned.
+func (d *sdm) VolumeResize(
+	ctx types.Context,
+	volumeID string,
+	size int64,
+	opts types.Store) (*types.Volume, error) {
+
+	sd, ok := d.StorageDriver.(types.ProvidesVolumeResize)
+	if !ok {
+		return nil, types.ErrNotImplemented
+	}
+	ctx, finish := d.startSpan(ctx, "VolumeResize")
+	v, err := sd.VolumeResize(ctx, volumeID, size, opts)
+	finish(err)
+	return v, err
+}
+
+// VolumeRetype changes the type of the volume if the driver supports it.
+// Otherwise types.ErrNotImplemented is returned.
+func (d *sdm) VolumeRetype(
+	ctx types.Context,
+	volumeID, volumeType string,
+	opts types.Store) (*types.Volume, error) {
+
+	sd, ok := d.StorageDriver.(types.ProvidesVolumeRetype)
+	if !ok {
+		return nil, types.ErrNotImplemented
+	}
+	ctx, finish := d.startSpan(ctx, "VolumeRetype")
+	v, err := sd.VolumeRetype(ctx, volumeID, volumeType, opts)
+	finish(err)
+	return v, err
+}
+
+// VolumeStats returns the volume's IO statistics if the driver provides
+// them. Otherwise types.ErrNotImplemented is returned.
+func (d *sdm) VolumeStats(
+	ctx types.Context,
+	volumeID string,
+	opts types.Store) (*types.VolumeIOStats, error) {
+
+	sd, ok := d.StorageDriver.(types.ProvidesVolumeStats)
+	if !ok {
+		return nil, types.ErrNotImplemented
+	}
+	ctx, finish := d.startSpan(ctx, "VolumeStats")
+	stats, err := sd.VolumeStats(ctx, volumeID, opts)
+	finish(err)
+	return stats, err
+}
+
+// Faults returns the faults the driver injects if the driver supports
+// injecting faults. Otherwise types.ErrNotImplemented is returned.
+func (d *sdm) Faults(ctx types.Context) (*types.FaultInjection, error) {
+	sd, ok := d.StorageDriver.(types.ProvidesFaultInjection)
+	if !ok {
+		return nil, types.ErrNotImplemented
+	}
+	return sd.Faults(ctx.Join(d.Context))
+}
+
+// SetFaults replaces the faults the driver injects if the driver supports
+// injecting faults. Otherwise types.ErrNotImplemented is returned.
+func (d *sdm) SetFaults(
+	ctx types.Context, faults *types.FaultInjection) error {
+
+	sd, ok := d.StorageDriver.(types.ProvidesFaultInjection)
+	if !ok {
+		return types.ErrNotImplemented
+	}
+	return sd.SetFaults(ctx.Join(d.Context), faults)
+}
+
+func (d *sdm) snapshotVolumes(
+	ctx types.Context,
+	volumeIDs []string,
+	snapshotName string,
+	opts types.Store) (*types.SnapshotGroup, error) {
+
+	ctx.WithField("snapshotName", snapshotName).Warn(
+		"snapshotting volumes one after another; snapshot group " +
+			"is not crash-consistent")
+
+	// the volumes are not snapshotted at the same point in time
+	group := &types.SnapshotGroup{
+		Name:       snapshotName,
+		Consistent: false,
+		Snapshots:  map[string]*types.Snapshot{},
+	}
+
+	for _, volumeID := range volumeIDs {
+		snap, err := d.StorageDriver.VolumeSnapshot(
+			ctx, volumeID, snapshotName, opts)
+		if err != nil {
+			for _, s := range group.Snapshots {
+				if err := d.StorageDriver.SnapshotRemove(
+					ctx, s.ID, opts); err != nil {
+					ctx.WithError(err).WithField("snapshotID", s.ID).Warn(
+						"error removing snapshot of failed snapshot group")
+				}
+			}
+			return nil, err
+		}
+		group.Snapshots[volumeID] = snap
+	}
+
+	return group, nil
 }
 
 func (d *sdm) VolumeRemove(
@@ -115,8 +482,10 @@ func (d *sdm) VolumeRemove(
 	volumeID string,
 	opts *types.VolumeRemoveOpts) error {
 
-	return d.StorageDriver.VolumeRemove(
-		ctx.Join(d.Context), volumeID, opts)
+	ctx, finish := d.startSpan(ctx, "VolumeRemove")
+	err := d.StorageDriver.VolumeRemove(ctx, volumeID, opts)
+	finish(err)
+	return err
 }
 
 func (d *sdm) VolumeAttach(
@@ -124,8 +493,10 @@ func (d *sdm) VolumeAttach(
 	volumeID string,
 	opts *types.VolumeAttachOpts) (*types.Volume, string, error) {
 
-	return d.StorageDriver.VolumeAttach(
-		ctx.Join(d.Context), volumeID, opts)
+	ctx, finish := d.startSpan(ctx, "VolumeAttach")
+	vol, token, err := d.StorageDriver.VolumeAttach(ctx, volumeID, opts)
+	finish(err)
+	return vol, token, err
 }
 
 func (d *sdm) VolumeDetach(
@@ -133,15 +504,20 @@ func (d *sdm) VolumeDetach(
 	volumeID string,
 	opts *types.VolumeDetachOpts) (*types.Volume, error) {
 
-	return d.StorageDriver.VolumeDetach(
-		ctx.Join(d.Context), volumeID, opts)
+	ctx, finish := d.startSpan(ctx, "VolumeDetach")
+	vol, err := d.StorageDriver.VolumeDetach(ctx, volumeID, opts)
+	finish(err)
+	return vol, err
 }
 
 func (d *sdm) Snapshots(
 	ctx types.Context,
 	opts types.Store) ([]*types.Snapshot, error) {
 
-	return d.StorageDriver.Snapshots(ctx.Join(d.Context), opts)
+	ctx, finish := d.startSpan(ctx, "Snapshots")
+	snaps, err := d.StorageDriver.Snapshots(ctx, opts)
+	finish(err)
+	return snaps, err
 }
 
 func (d *sdm) SnapshotInspect(
@@ -149,8 +525,10 @@ func (d *sdm) SnapshotInspect(
 	snapshotID string,
 	opts types.Store) (*types.Snapshot, error) {
 
-	return d.StorageDriver.SnapshotInspect(
-		ctx.Join(d.Context), snapshotID, opts)
+	ctx, finish := d.startSpan(ctx, "SnapshotInspect")
+	snap, err := d.StorageDriver.SnapshotInspect(ctx, snapshotID, opts)
+	finish(err)
+	return snap, err
 }
 
 func (d *sdm) SnapshotCopy(
@@ -160,8 +538,11 @@ func (d *sdm) SnapshotCopy(
 	destinationID string,
 	opts types.Store) (*types.Snapshot, error) {
 
-	return d.StorageDriver.SnapshotCopy(
-		ctx.Join(d.Context), snapshotID, snapshotName, destinationID, opts)
+	ctx, finish := d.startSpan(ctx, "SnapshotCopy")
+	snap, err := d.StorageDriver.SnapshotCopy(
+		ctx, snapshotID, snapshotName, destinationID, opts)
+	finish(err)
+	return snap, err
 }
 
 func (d *sdm) SnapshotRemove(
@@ -169,11 +550,17 @@ func (d *sdm) SnapshotRemove(
 	snapshotID string,
 	opts types.Store) error {
 
-	return d.StorageDriver.SnapshotRemove(ctx.Join(d.Context), snapshotID, opts)
+	ctx, finish := d.startSpan(ctx, "SnapshotRemove")
+	err := d.StorageDriver.SnapshotRemove(ctx, snapshotID, opts)
+	finish(err)
+	return err
 }
 
 func (d *sdmWithLogin) Login(
 	ctx types.Context) (interface{}, error) {
 
-	return d.StorageDriverWithLogin.Login(ctx.Join(d.Context))
+	ctx, finish := d.startSpan(ctx, "Login")
+	sess, err := d.login.Login(ctx)
+	finish(err)
+	return sess, err
 }
@@ -3,6 +3,7 @@ package executors
 import (
 	"encoding/json"
 	"regexp"
+	"strings"
 
 	// depend upon this tool with a nil import in order to preserve it
 	// in the dependency list
@@ -17,8 +18,18 @@ var (
 	pathRX    = regexp.MustCompile(`^lsx-(.+?)(?:.exe)?$`)
 )
 
+// signatureExt is the extension of the files embedded alongside the
+// executors that hold the executors' signatures. The signatures are created
+// when the executors are built so that the key with which they are signed
+// is never present on the server.
+const signatureExt = ".sig"
+
 func init() {
 	for path, bdFunc := range _bindata {
+		if strings.HasSuffix(path, signatureExt) {
+			continue
+		}
+
 		bd, err := bdFunc()
 
 		if err != nil {
@@ -31,11 +42,26 @@ func init() {
 				MD5Checksum:  bd.info.MD5Checksum(),
 				Size:         bd.info.Size(),
 				LastModified: bd.info.ModTime().Unix(),
+				Signature:    signature(path),
 			},
 		}
 	}
 }
 
+// signature returns the signature embedded alongside the executor, or an
+// empty string if the executor was not signed.
+func signature(path string) string {
+	sigFunc, ok := _bindata[path+signatureExt]
+	if !ok {
+		return ""
+	}
+	sig, err := sigFunc()
+	if err != nil {
+		panic(err)
+	}
+	return strings.TrimSpace(string(sig.bytes))
+}
+
 // ExecutorInfos returns a channel on which all executor information can be
 // received.
 func ExecutorInfos() <-chan *ExecutorInfoEx {
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	gofig "github.com/akutz/gofig/types"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/server/services"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// bodyHandler is a global HTTP filter that limits the size of request bodies
+// and compresses response bodies.
+type bodyHandler struct {
+	handler types.APIFunc
+	config  gofig.Config
+}
+
+// NewBodyHandler returns a new global HTTP filter that rejects requests whose
+// bodies exceed the maximum size and compresses the responses sent to clients
+// that accept gzip encoding. The limit and compression are configured for the
+// server and may be overridden for each storage service.
+func NewBodyHandler(config gofig.Config) types.Middleware {
+	return &bodyHandler{config: config}
+}
+
+func (h *bodyHandler) Name() string {
+	return "body-handler"
+}
+
+func (h *bodyHandler) Handler(m types.APIFunc) types.APIFunc {
+	return (&bodyHandler{m, h.config}).Handle
+}
+
+// Handle is the type's Handler function.
+func (h *bodyHandler) Handle(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	config := h.serviceConfig(ctx)
+
+	maxSize := int64(config.GetInt(types.ConfigServerMaxRequestBodySize))
+	if maxSize > 0 && req.Body != nil {
+		if req.ContentLength > maxSize {
+			return utils.NewRequestTooLargeError(maxSize)
+		}
+		buf, err := ioutil.ReadAll(io.LimitReader(req.Body, maxSize+1))
+		if err != nil {
+			return err
+		}
+		if int64(len(buf)) > maxSize {
+			return utils.NewRequestTooLargeError(maxSize)
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(buf))
+	}
+
+	if !config.GetBool(types.ConfigServerCompressionEnabled) ||
+		req.Method == http.MethodHead ||
+		!acceptsGzip(req.Header.Get("Accept-Encoding")) {
+		return h.handler(ctx, w, req, store)
+	}
+
+	gw := &gzipResponseWriter{
+		ResponseWriter: w,
+		minSize:        config.GetInt(types.ConfigServerCompressionMinSize),
+	}
+	err := h.handler(ctx, gw, req, store)
+	if cerr := gw.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// serviceConfig returns the configuration of the storage service named by
+// the request's route or the server's configuration if the route does not
+// name a service.
+func (h *bodyHandler) serviceConfig(ctx types.Context) gofig.Config {
+	vars, _ := ctx.Value(context.RouteVarsKey).(map[string]string)
+	if name := vars["service"]; name != "" {
+		if svc := services.GetStorageService(ctx, name); svc != nil {
+			if config := services.StorageServiceConfig(svc); config != nil {
+				return config
+			}
+		}
+	}
+	return h.config
+}
+
+func acceptsGzip(acceptEncoding string) bool {
+	for _, enc := range strings.Split(acceptEncoding, ",") {
+		parts := strings.Split(enc, ";")
+		if strings.TrimSpace(parts[0]) != "gzip" {
+			continue
+		}
+		for _, p := range parts[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				if q, err := strconv.ParseFloat(p[2:], 64); err == nil &&
+					q == 0 {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// gzipResponseWriter compresses a response once its body reaches the minimum
+// size. Smaller responses are written uncompressed when the writer is closed.
+// Responses that are already encoded or that are partial content are never
+// compressed.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	minSize int
+	status  int
+	buf     []byte
+	gz      *gzip.Writer
+	plain   bool
+}
+
+func (w *gzipResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *gzipResponseWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.gz != nil {
+		return w.gz.Write(p)
+	}
+	if w.plain {
+		return w.ResponseWriter.Write(p)
+	}
+
+	if !w.compressible() {
+		w.plain = true
+		w.ResponseWriter.WriteHeader(w.status)
+		return w.ResponseWriter.Write(p)
+	}
+
+	w.buf = append(w.buf, p...)
+	if len(w.buf) < w.minSize {
+		return len(p), nil
+	}
+
+	hdr := w.Header()
+	hdr.Set("Content-Encoding", "gzip")
+	hdr.Add("Vary", "Accept-Encoding")
+	hdr.Del("Content-Length")
+	w.ResponseWriter.WriteHeader(w.status)
+
+	w.gz = gzip.NewWriter(w.ResponseWriter)
+	buf := w.buf
+	w.buf = nil
+	if _, err := w.gz.Write(buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *gzipResponseWriter) compressible() bool {
+	hdr := w.Header()
+	return hdr.Get("Content-Encoding") == "" &&
+		hdr.Get("Content-Range") == "" &&
+		w.status != http.StatusPartialContent &&
+		w.status != http.StatusNoContent &&
+		w.status != http.StatusNotModified
+}
+
+// Close flushes the compressed response or writes the buffered, uncompressed
+// response.
+func (w *gzipResponseWriter) Close() error {
+	if w.gz != nil {
+		return w.gz.Close()
+	}
+	if w.plain {
+		return nil
+	}
+	if w.status == 0 && len(w.buf) == 0 {
+		return nil
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.plain = true
+	w.ResponseWriter.WriteHeader(w.status)
+	_, err := w.ResponseWriter.Write(w.buf)
+	return err
+}
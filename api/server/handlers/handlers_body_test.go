@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gofigCore "github.com/akutz/gofig"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := []struct {
+		acceptEncoding string
+		accepts        bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"deflate, gzip", true},
+		{"gzip;q=0.5", true},
+		{"gzip;q=0", false},
+		{"gzip; q=0.0, deflate", false},
+		{"identity", false},
+		{"x-gzip", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.accepts, acceptsGzip(tt.acceptEncoding),
+			"%q", tt.acceptEncoding)
+	}
+}
+
+func TestBodyHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		maxSize        int
+		reqBody        string
+		compression    bool
+		acceptEncoding string
+		resBody        string
+		tooLarge       bool
+		gzipped        bool
+	}{
+		{"within limit", 8, "12345678", false, "", "ok", false, false},
+		{"over limit", 8, "123456789", false, "", "ok", true, false},
+		{"no limit", 0, "123456789", false, "", "ok", false, false},
+		{"compressed", 0, "", true, "gzip", "0123456789", false, true},
+		{"below min size", 0, "", true, "gzip", "01234", false, false},
+		{"not accepted", 0, "", true, "identity", "0123456789",
+			false, false},
+		{"disabled", 0, "", false, "gzip", "0123456789", false, false},
+	}
+
+	for _, tt := range tests {
+		config := gofigCore.New()
+		config.Set(types.ConfigServerMaxRequestBodySize, tt.maxSize)
+		config.Set(types.ConfigServerCompressionEnabled, tt.compression)
+		config.Set(types.ConfigServerCompressionMinSize, 10)
+
+		var received string
+		next := func(
+			ctx types.Context,
+			w http.ResponseWriter,
+			req *http.Request,
+			store types.Store) error {
+
+			if req.Body != nil {
+				buf, _ := ioutil.ReadAll(req.Body)
+				received = string(buf)
+			}
+			w.WriteHeader(http.StatusOK)
+			_, err := w.Write([]byte(tt.resBody))
+			return err
+		}
+		h := NewBodyHandler(config).Handler(next)
+
+		req := httptest.NewRequest(
+			http.MethodPost, "/volumes", strings.NewReader(tt.reqBody))
+		req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+		w := httptest.NewRecorder()
+
+		err := h(context.Background(), w, req, utils.NewStore())
+		if tt.tooLarge {
+			assert.IsType(t, &types.ErrRequestTooLarge{}, err, tt.name)
+			continue
+		}
+		if !assert.NoError(t, err, tt.name) {
+			continue
+		}
+		assert.Equal(t, tt.reqBody, received, tt.name)
+
+		body := w.Body.String()
+		if tt.gzipped {
+			assert.Equal(t, "gzip",
+				w.Header().Get("Content-Encoding"), tt.name)
+			gr, err := gzip.NewReader(w.Body)
+			if !assert.NoError(t, err, tt.name) {
+				continue
+			}
+			buf, _ := ioutil.ReadAll(gr)
+			body = string(buf)
+		} else {
+			assert.Empty(t, w.Header().Get("Content-Encoding"), tt.name)
+		}
+		assert.Equal(t, tt.resBody, body, tt.name)
+	}
+}
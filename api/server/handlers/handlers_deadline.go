@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// deadlineHandler is a global HTTP filter for deriving a request's deadline
+// from the configured request timeout and the timeout header.
+type deadlineHandler struct {
+	handler types.APIFunc
+	timeout time.Duration
+}
+
+// NewDeadlineHandler returns a new global HTTP filter for deriving a
+// request's deadline from the configured request timeout and the timeout
+// header. A timeout of zero means requests have no deadline unless one is
+// sent by the client.
+func NewDeadlineHandler(timeout time.Duration) types.Middleware {
+	return &deadlineHandler{timeout: timeout}
+}
+
+func (h *deadlineHandler) Name() string {
+	return "deadline-handler"
+}
+
+func (h *deadlineHandler) Handler(m types.APIFunc) types.APIFunc {
+	return (&deadlineHandler{m, h.timeout}).Handle
+}
+
+// Handle is the type's Handler function.
+func (h *deadlineHandler) Handle(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	timeout := h.timeout
+
+	if v := req.Header.Get(types.TimeoutHeader); v != "" {
+		ctx.WithField(types.TimeoutHeader, v).Debug("http header")
+		dur, err := time.ParseDuration(v)
+		if err != nil || dur <= 0 {
+			return utils.NewInvalidRequestError(
+				types.TimeoutHeader, v, "invalid timeout")
+		}
+		// the client may shorten the configured timeout but not extend it
+		if timeout <= 0 || dur < timeout {
+			timeout = dur
+		}
+	}
+
+	if timeout <= 0 {
+		return h.handler(ctx, w, req, store)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+
+	// asynchronous tasks outlive the request, so their context is released
+	// when the deadline is reached instead of when the handler returns
+	defer func() {
+		if !store.GetBool("async") {
+			cancel()
+		}
+	}()
+
+	ctx.WithField("timeout", timeout).Debug("set request deadline")
+	return h.handler(ctx, w, req, store)
+}
@@ -1,10 +1,15 @@
 package handlers
 
 import (
+	"encoding/json"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/akutz/goof"
 
+	"github.com/codedellemc/libstorage/api/context"
 	"github.com/codedellemc/libstorage/api/server/httputils"
 	"github.com/codedellemc/libstorage/api/types"
 )
@@ -42,17 +47,175 @@ func (h *errorHandler) Handle(
 	ctx.Error(err)
 
 	httpErr := goof.NewHTTPError(err, getStatus(err))
-	httputils.WriteJSON(w, httpErr.Status(), httpErr)
+
+	if d := getRetryAfter(err); d > 0 {
+		secs := int(math.Ceil(d.Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(secs))
+	}
+
+	code := getCode(err)
+	if code == "" {
+		code = types.ErrCodeInternal
+	}
+
+	if context.APIVersion(ctx) >= types.APIVersion2 {
+		httputils.WriteJSON(
+			w, httpErr.Status(), &structuredError{httpErr, code})
+		return nil
+	}
+
+	httputils.WriteJSON(w, httpErr.Status(), &errorEnvelope{httpErr, code})
 	return nil
 }
 
+// errorEnvelope adds an error's machine-readable code to the JSON
+// representation of an HTTP error.
+type errorEnvelope struct {
+	goof.HTTPError
+	code types.ErrorCode
+}
+
+func (e *errorEnvelope) MarshalJSON() ([]byte, error) {
+	buf, err := json.Marshal(e.HTTPError)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(buf, &m); err != nil {
+		return nil, err
+	}
+	m["code"] = e.code
+	return json.Marshal(m)
+}
+
+// structuredError is the JSON representation of an HTTP error in version 2
+// of the API. The error's code, message, status, and fields are nested in an
+// object so that a response's body may be identified as an error.
+type structuredError struct {
+	goof.HTTPError
+	code types.ErrorCode
+}
+
+func (e *structuredError) MarshalJSON() ([]byte, error) {
+	buf, err := json.Marshal(e.HTTPError)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(buf, &m); err != nil {
+		return nil, err
+	}
+	obj := map[string]interface{}{
+		"code":    e.code,
+		"message": m["message"],
+		"status":  m["status"],
+	}
+	if details, ok := m["error"]; ok {
+		obj["details"] = details
+	}
+	return json.Marshal(map[string]interface{}{"error": obj})
+}
+
 func getStatus(err error) int {
+	if err == types.ErrTimedOut {
+		return http.StatusGatewayTimeout
+	}
 	switch err.(type) {
 	case *types.ErrBadAdminToken:
 		return http.StatusUnauthorized
 	case *types.ErrNotFound:
 		return http.StatusNotFound
+	case *types.ErrWrongZone:
+		return http.StatusBadRequest
+	case *types.ErrInvalidRequest:
+		return http.StatusBadRequest
+	case *types.ErrBadFilter:
+		return http.StatusBadRequest
+	case *types.ErrMissingInstanceID:
+		return http.StatusBadRequest
+	case *types.ErrVolumeAttached:
+		return http.StatusConflict
+	case *types.ErrVolumeInUse:
+		return http.StatusConflict
+	case *types.ErrQuotaExceeded:
+		return http.StatusForbidden
+	case *types.ErrDriverTimeout:
+		return http.StatusGatewayTimeout
+	case *types.ErrDeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case *types.ErrInstanceIDBinding:
+		return http.StatusForbidden
+	case *types.ErrServiceUnavailable:
+		return http.StatusServiceUnavailable
+	case *types.ErrThrottled:
+		return http.StatusTooManyRequests
+	case *types.ErrRequestTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case *types.ErrPolicyDenied:
+		return http.StatusForbidden
 	default:
 		return http.StatusInternalServerError
 	}
 }
+
+// getRetryAfter returns how long until a request that failed with the error
+// may be sent again, or zero if the request should not be retried or the
+// time is not known.
+func getRetryAfter(err error) time.Duration {
+	switch e := err.(type) {
+	case *types.ErrThrottled:
+		return e.RetryAfter
+	case *types.ErrServiceUnavailable:
+		return e.RetryAfter
+	}
+	return 0
+}
+
+func getCode(err error) types.ErrorCode {
+	if err == types.ErrTimedOut {
+		return types.ErrCodeDriverTimeout
+	}
+	switch e := err.(type) {
+	case *types.ErrNotFound:
+		switch e.ResourceType {
+		case types.ResourceTypeVolume:
+			return types.ErrCodeVolumeNotFound
+		case types.ResourceTypeSnapshot:
+			return types.ErrCodeSnapshotNotFound
+		}
+		return types.ErrCodeResourceNotFound
+	case *types.ErrVolumeAttached:
+		return types.ErrCodeVolumeAttached
+	case *types.ErrVolumeInUse:
+		return types.ErrCodeVolumeInUse
+	case *types.ErrQuotaExceeded:
+		return types.ErrCodeQuotaExceeded
+	case *types.ErrDriverTimeout:
+		return types.ErrCodeDriverTimeout
+	case *types.ErrDeadlineExceeded:
+		return types.ErrCodeDeadlineExceeded
+	case *types.ErrInstanceIDBinding:
+		return types.ErrCodeInstanceIDBinding
+	case *types.ErrWrongZone:
+		return types.ErrCodeWrongZone
+	case *types.ErrInvalidRequest:
+		return types.ErrCodeInvalidRequest
+	case *types.ErrBadAdminToken:
+		return types.ErrCodeBadAdminToken
+	case *types.ErrMissingInstanceID:
+		return types.ErrCodeMissingInstanceID
+	case *types.ErrBadFilter:
+		return types.ErrCodeBadFilter
+	case *types.ErrUnsupportedForClientType:
+		return types.ErrCodeUnsupportedForClientType
+	case *types.ErrServiceUnavailable:
+		return types.ErrCodeServiceUnavailable
+	case *types.ErrThrottled:
+		return types.ErrCodeThrottled
+	case *types.ErrRequestTooLarge:
+		return types.ErrCodeRequestTooLarge
+	case *types.ErrPolicyDenied:
+		return types.ErrCodePolicyDenied
+	}
+	return ""
+}
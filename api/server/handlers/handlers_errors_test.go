@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/akutz/goof"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+func TestGetStatusAndCode(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   types.ErrorCode
+	}{
+		{
+			utils.NewVolumeNotFoundError("vol-000"),
+			http.StatusNotFound,
+			types.ErrCodeVolumeNotFound,
+		},
+		{
+			utils.NewSnapshotNotFoundError("snap-000"),
+			http.StatusNotFound,
+			types.ErrCodeSnapshotNotFound,
+		},
+		{
+			utils.NewNotFoundError("vfs"),
+			http.StatusNotFound,
+			types.ErrCodeResourceNotFound,
+		},
+		{
+			utils.NewVolumeInUseError("vol-000", "i-000"),
+			http.StatusConflict,
+			types.ErrCodeVolumeInUse,
+		},
+		{
+			utils.NewBadFilterErr("(name=", goof.New("invalid filter")),
+			http.StatusBadRequest,
+			types.ErrCodeBadFilter,
+		},
+		{
+			utils.NewMissingInstanceIDError("vfs"),
+			http.StatusBadRequest,
+			types.ErrCodeMissingInstanceID,
+		},
+		{
+			utils.NewThrottledError(goof.New("RequestLimitExceeded"), 0),
+			http.StatusTooManyRequests,
+			types.ErrCodeThrottled,
+		},
+		{
+			goof.New("error"),
+			http.StatusInternalServerError,
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.status, getStatus(tt.err), tt.err.Error())
+		assert.Equal(t, tt.code, getCode(tt.err), tt.err.Error())
+	}
+}
+
+func TestGetRetryAfter(t *testing.T) {
+	assert.Equal(t, 2*time.Second, getRetryAfter(
+		utils.NewThrottledError(goof.New("throttled"), 2*time.Second)))
+	assert.Equal(t, 5*time.Second, getRetryAfter(
+		utils.NewServiceUnavailableError("vfs", 5*time.Second)))
+	assert.Equal(t, time.Duration(0), getRetryAfter(
+		utils.NewTaskQueueFullError("vfs", 10)))
+	assert.Equal(t, time.Duration(0), getRetryAfter(goof.New("error")))
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// etagHandler is an HTTP filter that tags responses with a hash of their
+// content.
+type etagHandler struct {
+	handler types.APIFunc
+}
+
+// NewETagHandler returns a new filter that sets the ETag header of a
+// successful response to a hash of the response's body. A request whose
+// If-None-Match header matches the hash receives a 304 status without a body
+// so that polling clients do not transfer and parse unchanged listings.
+func NewETagHandler() types.Middleware {
+	return &etagHandler{}
+}
+
+func (h *etagHandler) Name() string {
+	return "etag-handler"
+}
+
+func (h *etagHandler) Handler(m types.APIFunc) types.APIFunc {
+	return (&etagHandler{m}).Handle
+}
+
+// Handle is the type's Handler function.
+func (h *etagHandler) Handle(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	if req.Method != http.MethodGet {
+		return h.handler(ctx, w, req, store)
+	}
+
+	rec := httptest.NewRecorder()
+	if err := h.handler(ctx, rec, req, store); err != nil {
+		return err
+	}
+
+	if rec.Code != http.StatusOK {
+		return writeRecorded(w, rec)
+	}
+
+	sum := sha256.Sum256(rec.Body.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	rec.HeaderMap.Set("ETag", etag)
+
+	if !etagMatches(req.Header.Get("If-None-Match"), etag) {
+		return writeRecorded(w, rec)
+	}
+
+	for k, v := range rec.HeaderMap {
+		if k != "Content-Length" && k != "Content-Type" {
+			w.Header()[k] = v
+		}
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return nil
+}
+
+// etagMatches returns a flag indicating whether or not the value of an
+// If-None-Match header matches the ETag. Weak comparison is used.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, v := range strings.Split(ifNoneMatch, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.TrimPrefix(v, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
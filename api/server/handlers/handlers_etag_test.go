@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+func TestETagMatches(t *testing.T) {
+	const etag = `"abc"`
+	tests := []struct {
+		ifNoneMatch string
+		matches     bool
+	}{
+		{"", false},
+		{`"abc"`, true},
+		{`W/"abc"`, true},
+		{`"xyz", "abc"`, true},
+		{"*", true},
+		{`"xyz"`, false},
+		{`abc`, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.matches, etagMatches(tt.ifNoneMatch, etag),
+			"%q", tt.ifNoneMatch)
+	}
+}
+
+func TestETagHandler(t *testing.T) {
+	body := `{"vfs-000":{"id":"vfs-000"}}`
+	next := func(
+		ctx types.Context,
+		w http.ResponseWriter,
+		req *http.Request,
+		store types.Store) error {
+
+		if req.URL.Path == "/volumes/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return nil
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(body))
+		return err
+	}
+	h := NewETagHandler().Handler(next)
+
+	serve := func(
+		method, path, ifNoneMatch string) *httptest.ResponseRecorder {
+
+		req := httptest.NewRequest(method, path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		assert.NoError(t, h(context.Background(), w, req, utils.NewStore()))
+		return w
+	}
+
+	etag := serve(http.MethodGet, "/volumes", "").Header().Get("ETag")
+	if !assert.NotEmpty(t, etag) {
+		t.FailNow()
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		ifNoneMatch string
+		status      int
+		tagged      bool
+		body        string
+	}{
+		{"untagged request", http.MethodGet, "/volumes", "",
+			http.StatusOK, true, body},
+		{"matching tag", http.MethodGet, "/volumes", etag,
+			http.StatusNotModified, true, ""},
+		{"weak matching tag", http.MethodGet, "/volumes", "W/" + etag,
+			http.StatusNotModified, true, ""},
+		{"stale tag", http.MethodGet, "/volumes", `"stale"`,
+			http.StatusOK, true, body},
+		{"error response", http.MethodGet, "/volumes/missing", etag,
+			http.StatusNotFound, false, ""},
+		{"not a get", http.MethodPost, "/volumes", etag,
+			http.StatusOK, false, body},
+	}
+
+	for _, tt := range tests {
+		w := serve(tt.method, tt.path, tt.ifNoneMatch)
+		assert.Equal(t, tt.status, w.Code, tt.name)
+		assert.Equal(t, tt.body, w.Body.String(), tt.name)
+		if tt.tagged {
+			assert.Equal(t, etag, w.Header().Get("ETag"), tt.name)
+		} else {
+			assert.Empty(t, w.Header().Get("ETag"), tt.name)
+		}
+		if tt.status == http.StatusNotModified {
+			assert.Empty(t, w.Header().Get("Content-Type"), tt.name)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/server/services"
 	"github.com/codedellemc/libstorage/api/types"
 )
 
@@ -12,19 +13,12 @@ import (
 // from the headers
 type instanceIDHandler struct {
 	handler types.APIFunc
-	s2d     map[string]string
 }
 
 // NewInstanceIDHandler returns a new global HTTP filter for grokking the
 // InstanceIDs from the headers
-func NewInstanceIDHandler(svcs <-chan types.StorageService) types.Middleware {
-	iidh := &instanceIDHandler{
-		s2d: map[string]string{},
-	}
-	for s := range svcs {
-		iidh.s2d[strings.ToLower(s.Name())] = strings.ToLower(s.Driver().Name())
-	}
-	return iidh
+func NewInstanceIDHandler() types.Middleware {
+	return &instanceIDHandler{}
 }
 
 func (h *instanceIDHandler) Name() string {
@@ -32,7 +26,7 @@ func (h *instanceIDHandler) Name() string {
 }
 
 func (h *instanceIDHandler) Handler(m types.APIFunc) types.APIFunc {
-	return (&instanceIDHandler{m, h.s2d}).Handle
+	return (&instanceIDHandler{m}).Handle
 }
 
 // Handle is the type's Handler function.
@@ -76,7 +70,11 @@ func (h *instanceIDHandler) Handle(
 		}
 	}
 
-	for s, d := range h.s2d {
+	// the server's services are read for each request since they may
+	// change when the server's configuration is reloaded
+	for svc := range services.StorageServices(ctx) {
+		s := strings.ToLower(svc.Name())
+		d := strings.ToLower(svc.Driver().Name())
 		if iid, ok := s2i[s]; ok {
 			valMap[s] = iid
 		} else if iid, ok := d2i[d]; ok {
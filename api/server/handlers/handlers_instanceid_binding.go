@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// instanceIDBindingHandler is a global HTTP filter for binding the instance
+// IDs claimed by a request to the fingerprint of the request's TLS client
+// certificate
+type instanceIDBindingHandler struct {
+	handler  types.APIFunc
+	bindings *instanceIDBindings
+}
+
+// NewInstanceIDBindingHandler returns a new global HTTP filter for binding
+// the instance IDs claimed by a request to the fingerprint of the request's
+// TLS client certificate. An instance ID is bound the first time it is
+// claimed, and subsequent requests that claim the instance ID with a
+// different certificate are rejected. The bindings are persisted to the
+// provided file.
+func NewInstanceIDBindingHandler(path string) (types.Middleware, error) {
+	bindings := &instanceIDBindings{
+		path:         path,
+		fingerprints: map[string]string{},
+	}
+	if err := bindings.load(); err != nil {
+		return nil, err
+	}
+	return &instanceIDBindingHandler{bindings: bindings}, nil
+}
+
+func (h *instanceIDBindingHandler) Name() string {
+	return "instanceIDBinding-handler"
+}
+
+func (h *instanceIDBindingHandler) Handler(m types.APIFunc) types.APIFunc {
+	return (&instanceIDBindingHandler{m, h.bindings}).Handle
+}
+
+// Handle is the type's Handler function.
+func (h *instanceIDBindingHandler) Handle(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	iidm, ok := ctx.Value(context.AllInstanceIDsKey).(types.InstanceIDMap)
+	if !ok || len(iidm) == 0 {
+		return h.handler(ctx, w, req, store)
+	}
+
+	var fingerprint string
+	if req.TLS != nil && len(req.TLS.PeerCertificates) > 0 {
+		sum := sha256.Sum256(req.TLS.PeerCertificates[0].Raw)
+		fingerprint = hex.EncodeToString(sum[:])
+	}
+
+	for service, iid := range iidm {
+		if iid.ID == "" {
+			continue
+		}
+		if fingerprint == "" {
+			return utils.NewInstanceIDBindingError(service, iid.ID)
+		}
+		bound, err := h.bindings.bind(service, iid.ID, fingerprint)
+		if err != nil {
+			return err
+		}
+		if !bound {
+			ctx.WithFields(log.Fields{
+				"service":     service,
+				"instanceID":  iid.ID,
+				"fingerprint": fingerprint,
+			}).Warn("instance id claimed with different client certificate")
+			return utils.NewInstanceIDBindingError(service, iid.ID)
+		}
+	}
+
+	return h.handler(ctx, w, req, store)
+}
+
+// instanceIDBindings maps instance IDs to the fingerprints of the client
+// certificates to which they are bound.
+type instanceIDBindings struct {
+	sync.Mutex
+	path         string
+	fingerprints map[string]string
+}
+
+func (b *instanceIDBindings) load() error {
+	buf, err := ioutil.ReadFile(b.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err := json.Unmarshal(buf, &b.fingerprints); err != nil {
+		return goof.WithFieldE(
+			"path", b.path, "error reading instance id bindings", err)
+	}
+	return nil
+}
+
+func (b *instanceIDBindings) save() error {
+	buf, err := json.MarshalIndent(b.fingerprints, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(b.path), 0755); err != nil {
+		return err
+	}
+	// write to a temporary file first so that a failed write does not
+	// corrupt the existing bindings
+	tmp := b.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, b.path)
+}
+
+// bind binds the instance ID to the fingerprint if the instance ID is not
+// already bound. A flag is returned indicating whether or not the instance
+// ID is bound to the fingerprint.
+func (b *instanceIDBindings) bind(
+	service, instanceID, fingerprint string) (bool, error) {
+
+	key := fmt.Sprintf("%s/%s", service, instanceID)
+
+	b.Lock()
+	defer b.Unlock()
+
+	if v, ok := b.fingerprints[key]; ok {
+		return v == fingerprint, nil
+	}
+
+	b.fingerprints[key] = fingerprint
+	if err := b.save(); err != nil {
+		delete(b.fingerprints, key)
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+func newInstanceIDBindingTestHandler(
+	t *testing.T, path string) (types.APIFunc, *int) {
+
+	m, err := NewInstanceIDBindingHandler(path)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	calls := 0
+	return m.Handler(func(
+		ctx types.Context,
+		w http.ResponseWriter,
+		req *http.Request,
+		store types.Store) error {
+		calls++
+		return nil
+	}), &calls
+}
+
+func newInstanceIDBindingTestRequest(
+	instanceID string, cert []byte) (types.Context, *http.Request) {
+
+	ctx := context.Background().WithValue(
+		context.AllInstanceIDsKey,
+		types.InstanceIDMap{
+			"vfs": &types.InstanceID{ID: instanceID, Driver: "vfs"},
+		})
+	req, _ := http.NewRequest(http.MethodGet, "/volumes", nil)
+	if cert != nil {
+		req.TLS = &tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{{Raw: cert}},
+		}
+	}
+	return ctx, req
+}
+
+func TestInstanceIDBinding(t *testing.T) {
+	d, err := ioutil.TempDir("", "")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(d)
+	p := path.Join(d, "instance-id-bindings.json")
+
+	h, calls := newInstanceIDBindingTestHandler(t, p)
+	w := httptest.NewRecorder()
+	store := utils.NewStore()
+
+	// the instance ID is bound to the certificate that first claims it
+	ctx, req := newInstanceIDBindingTestRequest("iid-1", []byte("node1"))
+	assert.NoError(t, h(ctx, w, req, store))
+	ctx, req = newInstanceIDBindingTestRequest("iid-1", []byte("node1"))
+	assert.NoError(t, h(ctx, w, req, store))
+	assert.Equal(t, 2, *calls)
+
+	// another certificate cannot claim the bound instance ID
+	ctx, req = newInstanceIDBindingTestRequest("iid-1", []byte("node2"))
+	assert.IsType(t,
+		&types.ErrInstanceIDBinding{}, h(ctx, w, req, store))
+	assert.Equal(t, 2, *calls)
+
+	// an instance ID cannot be claimed without a certificate
+	ctx, req = newInstanceIDBindingTestRequest("iid-2", nil)
+	assert.IsType(t,
+		&types.ErrInstanceIDBinding{}, h(ctx, w, req, store))
+	assert.Equal(t, 2, *calls)
+
+	ctx, req = newInstanceIDBindingTestRequest("iid-2", []byte("node2"))
+	assert.NoError(t, h(ctx, w, req, store))
+	assert.Equal(t, 3, *calls)
+
+	// the bindings are persisted
+	h, calls = newInstanceIDBindingTestHandler(t, p)
+	ctx, req = newInstanceIDBindingTestRequest("iid-1", []byte("node2"))
+	assert.IsType(t,
+		&types.ErrInstanceIDBinding{}, h(ctx, w, req, store))
+	ctx, req = newInstanceIDBindingTestRequest("iid-2", []byte("node2"))
+	assert.NoError(t, h(ctx, w, req, store))
+	assert.Equal(t, 1, *calls)
+}
+
+func TestInstanceIDBindingWithoutInstanceID(t *testing.T) {
+	d, err := ioutil.TempDir("", "")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(d)
+
+	h, calls := newInstanceIDBindingTestHandler(
+		t, path.Join(d, "instance-id-bindings.json"))
+	req, _ := http.NewRequest(http.MethodGet, "/volumes", nil)
+	assert.NoError(t, h(
+		context.Background(), httptest.NewRecorder(), req, utils.NewStore()))
+	assert.Equal(t, 1, *calls)
+}
@@ -0,0 +1,329 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/server/httputils"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// listHandler is an HTTP filter that sorts and paginates the objects of a
+// list and selects them by their labels. Lists are paginated and selected
+// only in version 2 of the API.
+type listHandler struct {
+	handler types.APIFunc
+}
+
+// NewListHandler returns a new filter for paginating a list of objects, such
+// as a map of volumes or snapshots keyed by their IDs, and selecting the
+// objects by their labels. The objects are ordered by their keys.
+//
+// The limit query parameter is the maximum number of objects returned, and
+// the next query parameter is the token returned with the previous page in
+// the Libstorage-Next header. The labels query parameter is a comma-separated
+// list of key=value pairs, all of which must be among an object's fields for
+// the object to be selected.
+//
+// The sort query parameter orders the objects by their name, size, or
+// createdAt values, in descending order if the key is prefixed with a hyphen,
+// and the members of the returned object are written in that order.
+func NewListHandler() types.Middleware {
+	return &listHandler{}
+}
+
+func (h *listHandler) Name() string {
+	return "list-handler"
+}
+
+func (h *listHandler) Handler(m types.APIFunc) types.APIFunc {
+	return (&listHandler{m}).Handle
+}
+
+// Handle is the type's Handler function.
+func (h *listHandler) Handle(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	query := req.URL.Query()
+
+	sortKey, desc, err := parseSort(query.Get("sort"))
+	if err != nil {
+		return err
+	}
+
+	paginate := context.APIVersion(ctx) >= types.APIVersion2
+	if !paginate && sortKey == "" {
+		return h.handler(ctx, w, req, store)
+	}
+
+	var (
+		limit  int
+		next   string
+		labels map[string]string
+	)
+
+	if paginate {
+		if v := query.Get("limit"); v != "" {
+			i, err := strconv.Atoi(v)
+			if err != nil || i <= 0 {
+				return utils.NewInvalidRequestError(
+					"limit", v, "limit must be a positive integer")
+			}
+			limit = i
+		}
+		next = query.Get("next")
+
+		if labels, err = parseLabels(query.Get("labels")); err != nil {
+			return err
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	if err := h.handler(ctx, rec, req, store); err != nil {
+		return err
+	}
+
+	var objs map[string]json.RawMessage
+	if rec.Code != http.StatusOK ||
+		json.Unmarshal(rec.Body.Bytes(), &objs) != nil {
+		return writeRecorded(w, rec)
+	}
+
+	keys := make([]string, 0, len(objs))
+	for k, v := range objs {
+		if len(labels) > 0 && !hasLabels(v, labels) {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if sortKey != "" {
+		sortObjects(keys, objs, sortKey, desc)
+	}
+
+	if next != "" {
+		if sortKey == "" {
+			keys = keys[sort.SearchStrings(keys, next):]
+		} else {
+			i := indexOf(keys, next)
+			if i < 0 {
+				return utils.NewInvalidRequestError(
+					"next", next, "next object no longer exists")
+			}
+			keys = keys[i:]
+		}
+	}
+	if limit > 0 && len(keys) > limit {
+		w.Header().Set(types.NextHeader, keys[limit])
+		keys = keys[:limit]
+	}
+
+	for k, v := range rec.HeaderMap {
+		if k != "Content-Length" {
+			w.Header()[k] = v
+		}
+	}
+
+	if sortKey == "" {
+		page := make(map[string]json.RawMessage, len(keys))
+		for _, k := range keys {
+			page[k] = objs[k]
+		}
+		httputils.WriteJSON(w, rec.Code, page)
+		return nil
+	}
+
+	return writeOrderedJSON(w, rec.Code, keys, objs)
+}
+
+// listSortKeys are the keys by which a list may be sorted.
+var listSortKeys = map[string]bool{
+	"name":      true,
+	"size":      true,
+	"createdAt": true,
+}
+
+// parseSort parses the sort query parameter. A key prefixed with a hyphen
+// sorts the list in descending order.
+func parseSort(text string) (string, bool, error) {
+	if text == "" {
+		return "", false, nil
+	}
+	key, desc := strings.TrimPrefix(text, "-"), strings.HasPrefix(text, "-")
+	if !listSortKeys[key] {
+		return "", false, utils.NewInvalidRequestError(
+			"sort", text, "sort must be name, size, or createdAt")
+	}
+	return key, desc, nil
+}
+
+// sortValues are the values of a volume or snapshot by which it is sorted.
+type sortValues struct {
+	Name       string            `json:"name"`
+	Size       int64             `json:"size"`
+	VolumeSize int64             `json:"volumeSize"`
+	StartTime  int64             `json:"startTime"`
+	Fields     map[string]string `json:"fields"`
+}
+
+func (v *sortValues) size() int64 {
+	if v.Size > 0 {
+		return v.Size
+	}
+	return v.VolumeSize
+}
+
+func (v *sortValues) createdAt() int64 {
+	if v.StartTime > 0 {
+		return v.StartTime
+	}
+	i, _ := strconv.ParseInt(v.Fields["createdAt"], 10, 64)
+	return i
+}
+
+// sortObjects orders the keys, already in lexical order, by the sort key of
+// the objects they identify. Objects with equal values remain ordered by
+// their keys.
+func sortObjects(
+	keys []string,
+	objs map[string]json.RawMessage,
+	sortKey string,
+	desc bool) {
+
+	s := &objectSorter{keys: keys, key: sortKey, desc: desc}
+	s.vals = make([]*sortValues, len(keys))
+	for i, k := range keys {
+		s.vals[i] = &sortValues{}
+		json.Unmarshal(objs[k], s.vals[i])
+	}
+	sort.Stable(s)
+}
+
+type objectSorter struct {
+	keys []string
+	vals []*sortValues
+	key  string
+	desc bool
+}
+
+func (s *objectSorter) Len() int {
+	return len(s.keys)
+}
+
+func (s *objectSorter) Swap(i, j int) {
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+	s.vals[i], s.vals[j] = s.vals[j], s.vals[i]
+}
+
+func (s *objectSorter) Less(i, j int) bool {
+	a, b := s.vals[i], s.vals[j]
+	if s.desc {
+		a, b = b, a
+	}
+	switch s.key {
+	case "size":
+		return a.size() < b.size()
+	case "createdAt":
+		return a.createdAt() < b.createdAt()
+	}
+	return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+}
+
+func indexOf(keys []string, key string) int {
+	for i, k := range keys {
+		if k == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// writeOrderedJSON writes a JSON object whose members are in the order of
+// the keys. Encoding a map would order the members by their keys instead.
+func writeOrderedJSON(
+	w http.ResponseWriter,
+	status int,
+	keys []string,
+	objs map[string]json.RawMessage) error {
+
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		kbuf, err := json.Marshal(k)
+		if err != nil {
+			return err
+		}
+		buf.Write(kbuf)
+		buf.WriteByte(':')
+		buf.Write(objs[k])
+	}
+	buf.WriteByte('}')
+
+	out := &bytes.Buffer{}
+	if err := json.Indent(out, buf.Bytes(), "", "  "); err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// parseLabels parses a comma-separated list of key=value pairs.
+func parseLabels(text string) (map[string]string, error) {
+	if text == "" {
+		return nil, nil
+	}
+	labels := map[string]string{}
+	for _, p := range strings.Split(text, ",") {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, utils.NewInvalidRequestError(
+				"labels", text, "labels must be key=value pairs")
+		}
+		labels[kv[0]] = kv[1]
+	}
+	return labels, nil
+}
+
+// hasLabels returns a flag indicating whether or not all of the labels are
+// among the fields of the JSON object.
+func hasLabels(obj json.RawMessage, labels map[string]string) bool {
+	var v struct {
+		Fields map[string]string `json:"fields"`
+	}
+	if err := json.Unmarshal(obj, &v); err != nil {
+		return false
+	}
+	for k, lv := range labels {
+		if fv, ok := v.Fields[k]; !ok || fv != lv {
+			return false
+		}
+	}
+	return true
+}
+
+func writeRecorded(
+	w http.ResponseWriter, rec *httptest.ResponseRecorder) error {
+
+	for k, v := range rec.HeaderMap {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(rec.Code)
+	_, err := w.Write(rec.Body.Bytes())
+	return err
+}
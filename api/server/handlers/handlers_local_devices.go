@@ -47,6 +47,18 @@ func (h *localDevicesHandler) Handle(
 		valMap[strings.ToLower(val.Driver)] = val
 	}
 
+	// the information about the local devices is sent in its own headers
+	// so that the local devices headers are unchanged for older servers
+	for _, h := range req.Header[types.LocalDeviceInfoHeader] {
+		info := &types.LocalDevices{}
+		if err := info.UnmarshalDevicesText([]byte(h)); err != nil {
+			return err
+		}
+		if val, ok := valMap[strings.ToLower(info.Driver)]; ok {
+			val.Devices = info.Devices
+		}
+	}
+
 	ctx = ctx.WithValue(context.AllLocalDevicesKey, valMap)
 	return h.handler(ctx, w, req, store)
 }
@@ -17,6 +17,7 @@ import (
 	"github.com/akutz/gotil"
 
 	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
 )
 
 const (
@@ -117,7 +118,7 @@ func logRequest(
 	fmt.Fprint(w, "    -------------------------- ")
 	fmt.Fprint(w, "HTTP REQUEST (SERVER)")
 	fmt.Fprintln(w, " --------------------------")
-	gotil.WriteIndented(w, reqDump)
+	gotil.WriteIndented(w, utils.RedactHTTP(reqDump))
 }
 
 func logResponse(
@@ -134,7 +135,7 @@ func logResponse(
 	}
 	fmt.Fprintln(w, "")
 	if !isBinaryContent(rec.HeaderMap) {
-		gotil.WriteIndented(w, rec.Body.Bytes())
+		gotil.WriteIndented(w, utils.RedactHTTP(rec.Body.Bytes()))
 	}
 }
 
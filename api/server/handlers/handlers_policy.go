@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// policyHandler is a route filter that asks an OPA policy whether to admit
+// a mutating request.
+type policyHandler struct {
+	handler  types.APIFunc
+	url      string
+	failOpen bool
+	client   *http.Client
+}
+
+// policyInput is the document that describes a request to the policy.
+type policyInput struct {
+	Principal  string                 `json:"principal,omitempty"`
+	Tenant     string                 `json:"tenant,omitempty"`
+	Operation  string                 `json:"operation"`
+	Method     string                 `json:"method"`
+	Path       string                 `json:"path"`
+	Service    string                 `json:"service,omitempty"`
+	VolumeID   string                 `json:"volumeID,omitempty"`
+	SnapshotID string                 `json:"snapshotID,omitempty"`
+	InstanceID string                 `json:"instanceID,omitempty"`
+	Size       *int64                 `json:"size,omitempty"`
+	Labels     map[string]interface{} `json:"labels,omitempty"`
+	Args       map[string]interface{} `json:"args,omitempty"`
+}
+
+// policyDecision is the policy's response. The result is either a boolean
+// or an object with an allow flag and the reasons a request was denied.
+type policyDecision struct {
+	Result json.RawMessage `json:"result"`
+}
+
+type policyResult struct {
+	Allow   bool     `json:"allow"`
+	Reasons []string `json:"reasons"`
+}
+
+// NewPolicyHandler returns a new route filter that asks the OPA policy at
+// the configured URL whether to admit a mutating request. A nil value is
+// returned if no policy is configured.
+func NewPolicyHandler(config gofig.Config) types.Middleware {
+	url := config.GetString(types.ConfigServerPolicyURL)
+	if url == "" {
+		return nil
+	}
+	timeout, err := time.ParseDuration(
+		config.GetString(types.ConfigServerPolicyTimeout))
+	if err != nil {
+		timeout = 5 * time.Second
+	}
+	return &policyHandler{
+		url:      url,
+		failOpen: config.GetBool(types.ConfigServerPolicyFailOpen),
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+func (h *policyHandler) Name() string {
+	return "policy-handler"
+}
+
+func (h *policyHandler) Handler(m types.APIFunc) types.APIFunc {
+	return (&policyHandler{m, h.url, h.failOpen, h.client}).Handle
+}
+
+// Handle is the type's Handler function.
+func (h *policyHandler) Handle(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	input := newPolicyInput(ctx, req, store)
+	lf := log.Fields{
+		"policy":    h.url,
+		"operation": input.Operation,
+		"principal": input.Principal,
+	}
+
+	allow, reasons, err := h.decide(input)
+	if err != nil {
+		if !h.failOpen {
+			ctx.WithFields(lf).WithError(err).Error(
+				"error getting policy decision")
+			return utils.NewPolicyDeniedError(
+				input.Operation, []string{"policy decision unavailable"})
+		}
+		ctx.WithFields(lf).WithError(err).Warn(
+			"admitting request without policy decision")
+		return h.handler(ctx, w, req, store)
+	}
+
+	if !allow {
+		lf["reasons"] = reasons
+		ctx.WithFields(lf).Warn("request denied by policy")
+		return utils.NewPolicyDeniedError(input.Operation, reasons)
+	}
+
+	ctx.WithFields(lf).Debug("request admitted by policy")
+	return h.handler(ctx, w, req, store)
+}
+
+// decide posts the input to the policy and returns its decision.
+func (h *policyHandler) decide(
+	input *policyInput) (bool, []string, error) {
+
+	buf, err := json.Marshal(map[string]interface{}{"input": input})
+	if err != nil {
+		return false, nil, err
+	}
+
+	res, err := h.client.Post(h.url, "application/json", bytes.NewReader(buf))
+	if err != nil {
+		return false, nil, err
+	}
+	defer res.Body.Close()
+
+	if buf, err = ioutil.ReadAll(res.Body); err != nil {
+		return false, nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return false, nil, goof.WithFields(goof.Fields{
+			"status": res.StatusCode,
+			"body":   string(buf),
+		}, "error querying policy")
+	}
+
+	return parsePolicyDecision(buf)
+}
+
+// parsePolicyDecision parses the policy's response. An undefined result,
+// which OPA returns when no rule of the policy matches, denies the request.
+func parsePolicyDecision(buf []byte) (bool, []string, error) {
+	d := &policyDecision{}
+	if err := json.Unmarshal(buf, d); err != nil {
+		return false, nil, err
+	}
+	if len(d.Result) == 0 || string(d.Result) == "null" {
+		return false, []string{"policy decision undefined"}, nil
+	}
+
+	var allow bool
+	if err := json.Unmarshal(d.Result, &allow); err == nil {
+		return allow, nil, nil
+	}
+
+	r := &policyResult{}
+	if err := json.Unmarshal(d.Result, r); err != nil {
+		return false, nil, goof.WithFieldE(
+			"result", string(d.Result), "invalid policy decision", err)
+	}
+	if r.Allow {
+		return true, nil, nil
+	}
+	return false, r.Reasons, nil
+}
+
+// newPolicyInput returns the document that describes the request to the
+// policy. The admin token and the values of secrets, such as passwords,
+// encryption keys, and the credentials of a service registration, are
+// omitted at any depth so they are never sent to the policy.
+func newPolicyInput(
+	ctx types.Context,
+	req *http.Request,
+	store types.Store) *policyInput {
+
+	input := &policyInput{
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		Service:    store.GetString("service"),
+		VolumeID:   store.GetString("volumeID"),
+		SnapshotID: store.GetString("snapshotID"),
+		Size:       store.GetInt64Ptr("size"),
+		Args:       map[string]interface{}{},
+	}
+
+	if route, ok := ctx.Value(context.RouteKey).(types.Route); ok {
+		input.Operation = route.GetName()
+	} else {
+		input.Operation = fmt.Sprintf("%s %s", req.Method, req.URL.Path)
+	}
+	if v, ok := ctx.Value(context.UserKey).(string); ok {
+		input.Principal = v
+	}
+	if v, ok := context.Tenant(ctx); ok {
+		input.Tenant = v
+	}
+	if iid, ok := context.InstanceID(ctx); ok && iid != nil {
+		input.InstanceID = iid.ID
+	}
+
+	for _, k := range store.Keys() {
+		// the admin token authorizes the request and is not an argument
+		if k == "admin" || utils.IsSecretName(k) {
+			continue
+		}
+		input.Args[k] = policyArg(store.Get(k))
+	}
+
+	if opts := store.GetStore("opts"); opts != nil {
+		for _, k := range []string{"labels", "tags"} {
+			if labels := opts.GetMap(k); labels != nil {
+				input.Labels = labels
+				break
+			}
+		}
+	}
+
+	return input
+}
+
+// policyArg returns a copy of an argument of the request without the values
+// of secrets.
+func policyArg(v interface{}) interface{} {
+	switch tv := v.(type) {
+	case types.Store:
+		return policyArgs(tv.Map())
+	case map[string]interface{}:
+		return policyArgs(tv)
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(tv))
+		for k, v := range tv {
+			m[fmt.Sprintf("%v", k)] = v
+		}
+		return policyArgs(m)
+	case []interface{}:
+		l := make([]interface{}, len(tv))
+		for i, v := range tv {
+			l[i] = policyArg(v)
+		}
+		return l
+	}
+	return v
+}
+
+func policyArgs(m map[string]interface{}) map[string]interface{} {
+	args := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if utils.IsSecretName(k) {
+			continue
+		}
+		args[k] = policyArg(v)
+	}
+	return args
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+func TestNewPolicyInputRedaction(t *testing.T) {
+	req, err := http.NewRequest("POST", "/volumes/gce", nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	store := utils.NewStoreWithData(map[string]interface{}{
+		"service":       "gce",
+		"name":          "data",
+		"admin":         "admin-token",
+		"encryptionKey": "abc123",
+		"opts": utils.NewStoreWithData(map[string]interface{}{
+			"kmsKeyName":      "projects/p/keys/k",
+			"encryptionKey":   "abc123",
+			"rsaEncryptedKey": "def456",
+			"labels":          map[string]interface{}{"app": "db"},
+		}),
+		"config": map[interface{}]interface{}{
+			"ebs": map[interface{}]interface{}{
+				"region":    "us-east-1",
+				"accessKey": "AKIA",
+				"secretKey": "abc123",
+			},
+		},
+		"volumes": []interface{}{
+			map[string]interface{}{"id": "vol-1", "password": "abc123"},
+		},
+	})
+
+	input := newPolicyInput(context.Background(), req, store)
+	assert.Equal(t, "gce", input.Service)
+	assert.Equal(t, map[string]interface{}{
+		"service": "gce",
+		"name":    "data",
+		"opts": map[string]interface{}{
+			"kmskeyname": "projects/p/keys/k",
+			"labels":     map[string]interface{}{"app": "db"},
+		},
+		"config": map[string]interface{}{
+			"ebs": map[string]interface{}{"region": "us-east-1"},
+		},
+		"volumes": []interface{}{
+			map[string]interface{}{"id": "vol-1"},
+		},
+	}, input.Args)
+	assert.Equal(t, map[string]interface{}{"app": "db"}, input.Labels)
+}
@@ -40,6 +40,19 @@ func NewSchemaValidator(
 	}
 }
 
+// SchemaValidatorSchemas returns the request and response schemas of a
+// middleware created with NewSchemaValidator. The final return value is false
+// if the middleware is not a schema validator.
+func SchemaValidatorSchemas(
+	m types.Middleware) (reqSchema, resSchema []byte, ok bool) {
+
+	h, ok := m.(*schemaValidator)
+	if !ok {
+		return nil, nil, false
+	}
+	return h.reqSchema, h.resSchema, true
+}
+
 func (h *schemaValidator) Name() string {
 	return "schema-validator"
 }
@@ -81,9 +94,10 @@ func (h *schemaValidator) Handle(
 		ctx = ctx.WithValue("reqObj", reqObj)
 	}
 
-	// if there's not response schema then just return the result of the next
-	// handler
-	if DisableResponseValidation || h.resSchema == nil {
+	// if there's not response schema, or the request is being submitted in
+	// validate mode, then just return the result of the next handler
+	if DisableResponseValidation || h.resSchema == nil ||
+		store.GetBool("validate") {
 		return h.handler(ctx, w, req, store)
 	}
 
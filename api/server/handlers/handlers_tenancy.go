@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/server/services"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// tenancyHandler is a global HTTP filter that scopes a request to the
+// tenant of its authenticated user.
+type tenancyHandler struct {
+	handler types.APIFunc
+	pattern *regexp.Regexp
+	admins  map[string]bool
+}
+
+// NewTenancyHandler returns a new global HTTP filter that scopes a request
+// to the tenant of its authenticated user. The tenant is the first group
+// captured from the user's identity by the configured pattern, or the
+// identity itself if no pattern is configured. The requests of the
+// configured administrators and the requests that include the server's
+// admin token are not scoped. A nil value is returned if tenancy is
+// disabled.
+func NewTenancyHandler(config gofig.Config) (types.Middleware, error) {
+	if !config.GetBool(types.ConfigServerTenancyEnabled) {
+		return nil, nil
+	}
+
+	h := &tenancyHandler{admins: map[string]bool{}}
+
+	if p := config.GetString(types.ConfigServerTenancyPattern); p != "" {
+		rx, err := regexp.Compile(p)
+		if err != nil {
+			return nil, goof.WithFieldE(
+				"pattern", p, "invalid tenancy pattern", err)
+		}
+		if rx.NumSubexp() == 0 {
+			return nil, goof.WithField(
+				"pattern", p, "tenancy pattern has no group")
+		}
+		h.pattern = rx
+	}
+
+	for _, a := range config.GetStringSlice(types.ConfigServerTenancyAdmins) {
+		h.admins[a] = true
+	}
+
+	return h, nil
+}
+
+func (h *tenancyHandler) Name() string {
+	return "tenancy-handler"
+}
+
+func (h *tenancyHandler) Handler(m types.APIFunc) types.APIFunc {
+	return (&tenancyHandler{m, h.pattern, h.admins}).Handle
+}
+
+// Handle is the type's Handler function.
+func (h *tenancyHandler) Handle(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	user, _ := ctx.Value(context.UserKey).(string)
+
+	if h.isAdmin(ctx, user, store) {
+		ctx.WithField("user", user).Debug("request not scoped to a tenant")
+		if user != "" && h.admins[user] {
+			ctx = ctx.WithValue(context.AdminKey, true)
+		}
+		return h.handler(ctx, w, req, store)
+	}
+
+	tenant := h.tenant(user)
+	if tenant == "" {
+		operation := req.Method + " " + req.URL.Path
+		if route, ok := ctx.Value(context.RouteKey).(types.Route); ok {
+			operation = route.GetName()
+		}
+		ctx.WithFields(log.Fields{
+			"user":      user,
+			"operation": operation,
+		}).Warn("request has no tenant")
+		return utils.NewPolicyDeniedError(
+			operation, []string{"request has no tenant"})
+	}
+
+	ctx = ctx.WithValue(context.TenantKey, tenant)
+	return h.handler(ctx, w, req, store)
+}
+
+// isAdmin returns a flag indicating whether or not the user is one of the
+// configured administrators or the request includes the server's admin
+// token.
+func (h *tenancyHandler) isAdmin(
+	ctx types.Context, user string, store types.Store) bool {
+
+	if user != "" && h.admins[user] {
+		return true
+	}
+	token, ok := ctx.Value(context.AdminTokenKey).(string)
+	return ok && token != "" && store.GetString("admin") == token
+}
+
+// tenant returns the tenant of the user, or an empty string if the user's
+// identity is unknown or does not match the pattern.
+func (h *tenancyHandler) tenant(user string) string {
+	if user == "" || h.pattern == nil {
+		return user
+	}
+	m := h.pattern.FindStringSubmatch(user)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+// volumeOwnerValidator is an HTTP filter for validating that the volume
+// specified as part of the path belongs to the request's tenant.
+type volumeOwnerValidator struct {
+	handler types.APIFunc
+}
+
+// NewVolumeOwnerValidator returns a new filter for validating that the
+// volume specified as part of the path belongs to the request's tenant.
+// The volume of another tenant, as well as a volume that cannot be
+// inspected, is reported as not found so that its existence is not
+// disclosed.
+func NewVolumeOwnerValidator() types.Middleware {
+	return &volumeOwnerValidator{}
+}
+
+func (h *volumeOwnerValidator) Name() string {
+	return "volume-owner-validator"
+}
+
+func (h *volumeOwnerValidator) Handler(m types.APIFunc) types.APIFunc {
+	return (&volumeOwnerValidator{m}).Handle
+}
+
+// Handle is the type's Handler function.
+func (h *volumeOwnerValidator) Handle(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	if _, ok := context.Tenant(ctx); !ok {
+		return h.handler(ctx, w, req, store)
+	}
+
+	svc, ok := context.Service(ctx)
+	if !ok || !store.IsSet("volumeID") {
+		return h.handler(ctx, w, req, store)
+	}
+
+	volumeID := store.GetString("volumeID")
+
+	var (
+		v   *types.Volume
+		err error
+	)
+
+	// only the inspect route looks volumes up by name; every other route
+	// treats the path's volume ID as an ID whether or not the byName query
+	// parameter is present
+	if route, ok := context.Route(ctx); ok &&
+		route.GetName() == "volumeInspect" && store.IsSet("byName") {
+		v, err = tenantVolumeByName(ctx, svc, volumeID)
+	} else {
+		v, err = tenantVolumeByID(ctx, svc, volumeID)
+	}
+	if err != nil {
+		ctx.WithError(err).WithField("volumeID", volumeID).Warn(
+			"error validating volume owner")
+		return utils.NewVolumeNotFoundError(volumeID)
+	}
+
+	if v == nil || !services.IsTenantVolume(ctx, svc, v) {
+		ctx.WithField("volumeID", volumeID).Warn(
+			"volume belongs to another tenant")
+		return utils.NewVolumeNotFoundError(volumeID)
+	}
+
+	return h.handler(ctx, w, req, store)
+}
+
+// tenantVolumeByID returns the volume with the provided ID. The volumes in
+// the service's recycle bin are searched if the volume cannot be inspected
+// so that the owner of a recycled volume may restore or purge it.
+func tenantVolumeByID(
+	ctx types.Context,
+	svc types.StorageService,
+	volumeID string) (*types.Volume, error) {
+
+	v, err := svc.Driver().VolumeInspect(
+		ctx, volumeID, &types.VolumeInspectOpts{Opts: utils.NewStore()})
+	if err == nil {
+		return v, nil
+	}
+
+	d, ok := svc.Driver().(types.ProvidesVolumeRecycling)
+	if !ok {
+		return nil, err
+	}
+	vols, rerr := d.RecycledVolumes(ctx, utils.NewStore())
+	if rerr != nil {
+		return nil, err
+	}
+	for _, rv := range vols {
+		if rv.ID == volumeID {
+			return rv, nil
+		}
+	}
+	return nil, err
+}
+
+// tenantVolumeByName returns the request tenant's volume with the provided
+// name, or nil if the tenant has no such volume.
+func tenantVolumeByName(
+	ctx types.Context,
+	svc types.StorageService,
+	name string) (*types.Volume, error) {
+
+	vols, err := svc.Driver().Volumes(
+		ctx, &types.VolumesOpts{Opts: utils.NewStore()})
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range vols {
+		services.UnmapVolumeName(svc, v)
+		if strings.EqualFold(v.Name, name) &&
+			services.IsTenantVolume(ctx, svc, v) {
+			return v, nil
+		}
+	}
+	return nil, nil
+}
+
+// snapshotOwnerValidator is an HTTP filter for validating that the snapshot
+// specified as part of the path belongs to the request's tenant.
+type snapshotOwnerValidator struct {
+	handler types.APIFunc
+}
+
+// NewSnapshotOwnerValidator returns a new filter for validating that the
+// snapshot specified as part of the path belongs to the request's tenant.
+// The snapshot of another tenant, as well as a snapshot that cannot be
+// inspected, is reported as not found so that its existence is not
+// disclosed.
+func NewSnapshotOwnerValidator() types.Middleware {
+	return &snapshotOwnerValidator{}
+}
+
+func (h *snapshotOwnerValidator) Name() string {
+	return "snapshot-owner-validator"
+}
+
+func (h *snapshotOwnerValidator) Handler(m types.APIFunc) types.APIFunc {
+	return (&snapshotOwnerValidator{m}).Handle
+}
+
+// Handle is the type's Handler function.
+func (h *snapshotOwnerValidator) Handle(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	if _, ok := context.Tenant(ctx); !ok {
+		return h.handler(ctx, w, req, store)
+	}
+
+	svc, ok := context.Service(ctx)
+	if !ok || !store.IsSet("snapshotID") {
+		return h.handler(ctx, w, req, store)
+	}
+
+	snapshotID := store.GetString("snapshotID")
+	s, err := svc.Driver().SnapshotInspect(ctx, snapshotID, utils.NewStore())
+	if err != nil {
+		ctx.WithError(err).WithField("snapshotID", snapshotID).Warn(
+			"error validating snapshot owner")
+		return utils.NewSnapshotNotFoundError(snapshotID)
+	}
+
+	if !services.IsTenantSnapshot(ctx, svc, s) {
+		ctx.WithField("snapshotID", snapshotID).Warn(
+			"snapshot belongs to another tenant")
+		return utils.NewSnapshotNotFoundError(snapshotID)
+	}
+
+	return h.handler(ctx, w, req, store)
+}
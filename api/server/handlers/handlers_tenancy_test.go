@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/server/httputils"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+type tenantDriver struct {
+	types.StorageDriver
+	volumes  []*types.Volume
+	recycled []*types.Volume
+}
+
+func (d *tenantDriver) Name() string {
+	return "ebs"
+}
+
+func (d *tenantDriver) StampsVolumeMetadata(ctx types.Context) bool {
+	return true
+}
+
+func (d *tenantDriver) Volumes(
+	ctx types.Context,
+	opts *types.VolumesOpts) ([]*types.Volume, error) {
+
+	return d.volumes, nil
+}
+
+func (d *tenantDriver) VolumeInspect(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeInspectOpts) (*types.Volume, error) {
+
+	for _, v := range d.volumes {
+		if v.ID == volumeID {
+			return v, nil
+		}
+	}
+	return nil, utils.NewNotFoundError(volumeID)
+}
+
+func (d *tenantDriver) VolumeRecycle(
+	ctx types.Context, volumeID string, opts types.Store) error {
+	return nil
+}
+
+func (d *tenantDriver) RecycledVolumes(
+	ctx types.Context, opts types.Store) ([]*types.Volume, error) {
+	return d.recycled, nil
+}
+
+func (d *tenantDriver) RecycledVolumeRestore(
+	ctx types.Context,
+	volumeID string,
+	opts types.Store) (*types.Volume, error) {
+	return nil, nil
+}
+
+func (d *tenantDriver) RecycledVolumePurge(
+	ctx types.Context, volumeID string, opts types.Store) error {
+	return nil
+}
+
+type tenantService struct {
+	types.StorageService
+	driver types.StorageDriver
+}
+
+func (s *tenantService) Name() string {
+	return "ebs"
+}
+
+func (s *tenantService) Driver() types.StorageDriver {
+	return s.driver
+}
+
+func TestVolumeOwnerValidator(t *testing.T) {
+	owned := func(id, name, tenant string) *types.Volume {
+		return &types.Volume{
+			ID:     id,
+			Name:   name,
+			Fields: map[string]string{types.VolumeMetadataTenant: tenant},
+		}
+	}
+	svc := &tenantService{driver: &tenantDriver{
+		volumes: []*types.Volume{
+			owned("vol-1", "data", "team1"),
+			owned("vol-2", "logs", "team2"),
+		},
+		recycled: []*types.Volume{
+			owned("vol-3", "old", "team1"),
+			owned("vol-4", "older", "team2"),
+		},
+	}}
+
+	tests := []struct {
+		route    string
+		volumeID string
+		byName   bool
+		allowed  bool
+	}{
+		{"volumeAttach", "vol-1", false, true},
+		{"volumeAttach", "vol-2", false, false},
+		{"volumeAttach", "vol-9", false, false},
+		{"volumeAttach", "vol-2", true, false},
+		{"volumeRemove", "vol-2", true, false},
+		{"volumeUndelete", "vol-3", false, true},
+		{"volumeUndelete", "vol-4", false, false},
+		{"volumeInspect", "data", true, true},
+		{"volumeInspect", "logs", true, false},
+		{"volumeInspect", "vol-2", false, false},
+	}
+
+	for _, tt := range tests {
+		called := false
+		next := func(
+			ctx types.Context,
+			w http.ResponseWriter,
+			req *http.Request,
+			store types.Store) error {
+
+			called = true
+			return nil
+		}
+		h := NewVolumeOwnerValidator().Handler(next)
+
+		route := httputils.NewPostRoute(
+			tt.route, "/volumes/{service}/{volumeID}", next)
+		ctx := context.WithStorageService(context.Background(), svc)
+		ctx = ctx.WithValue(context.RouteKey, route)
+		ctx = ctx.WithValue(context.TenantKey, "team1")
+
+		store := utils.NewStore()
+		store.Set("volumeID", tt.volumeID)
+		if tt.byName {
+			store.Set("byName", "")
+		}
+
+		err := h(ctx, nil, nil, store)
+		assert.Equal(t, tt.allowed, called, "%+v", tt)
+		if tt.allowed {
+			assert.NoError(t, err, "%+v", tt)
+		} else {
+			assert.Error(t, err, "%+v", tt)
+		}
+	}
+}
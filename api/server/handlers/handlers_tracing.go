@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils/tracing"
+)
+
+// tracingHandler is a global HTTP filter for tracing requests.
+type tracingHandler struct {
+	handler types.APIFunc
+}
+
+// NewTracingHandler returns a new global HTTP filter for tracing requests.
+// The span for a request is a child of the span propagated by the client,
+// if any.
+func NewTracingHandler() types.Middleware {
+	return &tracingHandler{}
+}
+
+func (h *tracingHandler) Name() string {
+	return "tracing-handler"
+}
+
+func (h *tracingHandler) Handler(m types.APIFunc) types.APIFunc {
+	return (&tracingHandler{m}).Handle
+}
+
+// Handle is the type's Handler function.
+func (h *tracingHandler) Handle(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	opName := req.Method + " " + req.URL.Path
+	if route, ok := ctx.Value(context.RouteKey).(types.Route); ok {
+		opName = route.GetName()
+	}
+
+	ctx, finish := tracing.StartServerSpan(ctx, opName, req)
+	err := h.handler(ctx, w, req, store)
+	finish(err)
+	return err
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/codedellemc/libstorage/api/server/services"
 	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
 )
 
 // WriteJSON writes the value v to the http response stream as json with
@@ -75,7 +76,12 @@ func WriteTask(
 		}
 		WriteJSON(w, okStatus, task.Result)
 	case <-exeTimeout.C:
-		WriteJSON(w, http.StatusRequestTimeout, task)
+		return utils.NewDriverTimeoutError(exeTimeoutDur, task)
+	case <-ctx.Done():
+		if deadline, ok := ctx.Deadline(); ok {
+			return utils.NewDeadlineExceededError(deadline, task)
+		}
+		return ctx.Err()
 	}
 
 	return nil
@@ -0,0 +1,35 @@
+package events
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/server/httputils"
+	"github.com/codedellemc/libstorage/api/server/services"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// maxWait is the maximum number of seconds a client may wait for an event.
+const maxWait = 60
+
+func (r *router) events(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	wait := store.GetInt("wait")
+	if wait > maxWait {
+		wait = maxWait
+	}
+
+	events := services.Events(
+		ctx,
+		context.MustService(ctx).Name(),
+		store.GetInt64("since"),
+		time.Duration(wait)*time.Second)
+
+	httputils.WriteJSON(w, http.StatusOK, events)
+	return nil
+}
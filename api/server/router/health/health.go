@@ -0,0 +1,60 @@
+package health
+
+import (
+	"time"
+
+	gofig "github.com/akutz/gofig/types"
+
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/server/httputils"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+func init() {
+	registry.RegisterRouter(&router{})
+}
+
+type router struct {
+	routes  []types.Route
+	timeout time.Duration
+}
+
+func (r *router) Name() string {
+	return "health-router"
+}
+
+func (r *router) Init(config gofig.Config) {
+	r.timeout = defaultTimeout
+	if dur, err := time.ParseDuration(
+		config.GetString(types.ConfigServerHealthTimeout)); err == nil {
+		r.timeout = dur
+	}
+	r.initRoutes()
+}
+
+// Routes returns the available routes.
+func (r *router) Routes() []types.Route {
+	return r.routes
+}
+
+func (r *router) initRoutes() {
+
+	r.routes = []types.Route{
+
+		// GET
+		httputils.NewGetRoute(
+			"health",
+			"/health",
+			r.health),
+
+		httputils.NewGetRoute(
+			"live",
+			"/live",
+			r.live),
+
+		httputils.NewGetRoute(
+			"ready",
+			"/ready",
+			r.ready),
+	}
+}
@@ -0,0 +1,153 @@
+package health
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/server/httputils"
+	"github.com/codedellemc/libstorage/api/server/services"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// defaultTimeout is how long a storage service's health check may run when
+// no timeout is configured.
+const defaultTimeout = 5 * time.Second
+
+// health reports the health of the server's storage services. The response
+// status is always 200 so that operators can inspect a degraded server.
+// Since the services' drivers are checked, the resource should not be used
+// as a liveness probe.
+func (r *router) health(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	httputils.WriteJSON(w, http.StatusOK, r.checkServices(ctx))
+	return nil
+}
+
+// live reports that the server is able to serve requests. The storage
+// services are not checked so that an outage of a storage platform does not
+// cause an orchestrator to restart a server that is otherwise healthy.
+func (r *router) live(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	httputils.WriteJSON(
+		w, http.StatusOK, &types.Health{Status: types.HealthStatusOK})
+	return nil
+}
+
+// ready reports the health of the server's storage services. The response
+// status is 503 unless all of the services are healthy so that load
+// balancers can stop routing requests to a degraded server.
+func (r *router) ready(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	health := r.checkServices(ctx)
+	status := http.StatusOK
+	if health.Status != types.HealthStatusOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	httputils.WriteJSON(w, status, health)
+	return nil
+}
+
+func (r *router) checkServices(ctx types.Context) *types.Health {
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		health = &types.Health{
+			Status:   types.HealthStatusOK,
+			Services: map[string]*types.ServiceHealth{},
+		}
+	)
+
+	for service := range services.StorageServices(ctx) {
+		wg.Add(1)
+		go func(service types.StorageService) {
+			defer wg.Done()
+			sh := r.checkService(ctx, service)
+			mu.Lock()
+			defer mu.Unlock()
+			health.Services[service.Name()] = sh
+			if sh.Status != types.HealthStatusOK {
+				health.Status = types.HealthStatusDegraded
+			}
+		}(service)
+	}
+
+	wg.Wait()
+	return health
+}
+
+func (r *router) checkService(
+	ctx types.Context,
+	service types.StorageService) *types.ServiceHealth {
+
+	var (
+		start = time.Now()
+		sh    = &types.ServiceHealth{
+			Status: types.HealthStatusOK,
+			Driver: service.Driver().Name(),
+		}
+	)
+
+	defer func() {
+		sh.Duration = int64(time.Since(start) / time.Millisecond)
+	}()
+
+	ctx, cancel := context.WithTimeout(
+		context.WithStorageService(ctx, service), r.timeout)
+	defer cancel()
+
+	// the check runs in its own goroutine so that a driver that does not
+	// honor the context's deadline cannot block the response
+	errC := make(chan error, 1)
+	go func() {
+		ctx, err := context.WithStorageSession(ctx)
+		if err != nil {
+			errC <- err
+			return
+		}
+		if d, ok := service.Driver().(types.ProvidesHealthCheck); ok {
+			errC <- d.HealthCheck(ctx)
+			return
+		}
+		errC <- nil
+	}()
+
+	var err error
+	select {
+	case err = <-errC:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		ctx.WithError(err).Warn("storage service is unhealthy")
+		sh.Status = types.HealthStatusUnavailable
+		sh.Error = err.Error()
+	}
+
+	sh.CircuitBreaker = services.CircuitBreakerState(service)
+	if sh.CircuitBreaker != nil &&
+		sh.CircuitBreaker.Status == types.CircuitBreakerOpen {
+		sh.Status = types.HealthStatusUnavailable
+		if sh.Error == "" {
+			sh.Error = "circuit breaker open"
+		}
+	}
+
+	return sh
+}
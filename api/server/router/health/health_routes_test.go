@@ -0,0 +1,30 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+func TestLive(t *testing.T) {
+	r := &router{}
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/live", nil)
+
+	// the context has no server, so checking the storage services would
+	// panic
+	assert.NoError(t, r.live(context.Background(), w, req, utils.NewStore()))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	health := &types.Health{}
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(health))
+	assert.Equal(t, types.HealthStatusOK, health.Status)
+	assert.Empty(t, health.Services)
+}
@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils/metrics"
+)
+
+// metrics writes the durations and failures of the storage and OS driver
+// operations performed by the process in the Prometheus text format.
+func (r *router) metrics(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	buf := &bytes.Buffer{}
+	if err := metrics.WriteText(buf); err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+	return nil
+}
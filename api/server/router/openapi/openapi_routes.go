@@ -0,0 +1,33 @@
+package openapi
+
+import (
+	"net/http"
+
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/server/httputils"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// openAPIInspect returns the OpenAPI document that describes the server's
+// routes. The document is built the first time it is requested since the
+// routes of all of the routers are known only after they are initialized.
+func (r *router) openAPIInspect(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	r.specOnce.Do(func() {
+		routers := []types.Router{}
+		for rr := range registry.Routers() {
+			routers = append(routers, rr)
+		}
+		r.spec, r.specErr = BuildSpec(routers)
+	})
+	if r.specErr != nil {
+		return r.specErr
+	}
+
+	httputils.WriteJSON(w, http.StatusOK, r.spec)
+	return nil
+}
@@ -0,0 +1,247 @@
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/codedellemc/libstorage/api"
+	"github.com/codedellemc/libstorage/api/server/handlers"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils/schema"
+)
+
+const (
+	// Version is the version of the OpenAPI specification to which the
+	// generated documents adhere.
+	Version = "3.0.0"
+
+	definitionsPrefix = "#/definitions/"
+	componentsPrefix  = "#/components/schemas/"
+)
+
+var pathParamRX = regexp.MustCompile(`\{([^}:]+)(?::[^}]+)?\}`)
+
+// operation is the set of routes that share a path and a method. the routes
+// are distinguished from one another by their queries.
+type operation struct {
+	path   string
+	method string
+	routes []types.Route
+}
+
+// BuildSpec returns an OpenAPI document that describes the routes of the
+// provided routers. The request and response bodies are described by the
+// schemas the routes' schema validators use, so the document and the server's
+// validation of requests are driven by the same libStorage JSON schema.
+func BuildSpec(routers []types.Router) (map[string]interface{}, error) {
+
+	defs, err := schema.Definitions()
+	if err != nil {
+		return nil, err
+	}
+	components := map[string]interface{}{}
+	for k, v := range defs {
+		components[k] = rewriteRefs(v)
+	}
+
+	ops := map[string]*operation{}
+	for _, r := range routers {
+		for _, route := range r.Routes() {
+			method := strings.ToLower(route.GetMethod())
+			key := route.GetPath() + " " + method
+			op, ok := ops[key]
+			if !ok {
+				op = &operation{path: route.GetPath(), method: method}
+				ops[key] = op
+			}
+			op.routes = append(op.routes, route)
+		}
+	}
+
+	keys := make([]string, 0, len(ops))
+	for k := range ops {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	paths := map[string]interface{}{}
+	opIDs := map[string]int{}
+	for _, k := range keys {
+		op := ops[k]
+		path := pathParamRX.ReplaceAllString(op.path, "{$1}")
+		item, ok := paths[path].(map[string]interface{})
+		if !ok {
+			item = map[string]interface{}{}
+			paths[path] = item
+		}
+		item[op.method] = op.build(opIDs)
+	}
+
+	return map[string]interface{}{
+		"openapi": Version,
+		"info": map[string]interface{}{
+			"title":       "libStorage",
+			"description": "The libStorage API",
+			"version":     apiVersion(),
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": components,
+		},
+	}, nil
+}
+
+func apiVersion() string {
+	if api.Version == nil || api.Version.SemVer == "" {
+		return "0.0.0"
+	}
+	return api.Version.SemVer
+}
+
+func (o *operation) build(opIDs map[string]int) map[string]interface{} {
+
+	var (
+		names     []string
+		reqRefs   []string
+		resRefs   []string
+		params    []interface{}
+		seenQuery = map[string]bool{}
+	)
+
+	for _, m := range pathParamRX.FindAllStringSubmatch(o.path, -1) {
+		params = append(params, map[string]interface{}{
+			"name":     m[1],
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]interface{}{"type": "string"},
+		})
+	}
+
+	for _, r := range o.routes {
+		names = append(names, r.GetName())
+
+		q := r.GetQueries()
+		for i := 0; i+1 < len(q); i += 2 {
+			if seenQuery[q[i]] {
+				continue
+			}
+			seenQuery[q[i]] = true
+			params = append(params, map[string]interface{}{
+				"name": q[i],
+				"in":   "query",
+				"description": fmt.Sprintf(
+					"Selects the %s operation.", r.GetName()),
+				"allowEmptyValue": true,
+				"schema":          map[string]interface{}{"type": "string"},
+			})
+		}
+
+		for _, m := range r.GetMiddlewares() {
+			reqSchema, resSchema, ok := handlers.SchemaValidatorSchemas(m)
+			if !ok {
+				continue
+			}
+			reqRefs = appendRef(reqRefs, reqSchema)
+			resRefs = appendRef(resRefs, resSchema)
+		}
+	}
+
+	opID := names[0]
+	if n := opIDs[opID]; n > 0 {
+		opID = fmt.Sprintf("%s%d", opID, n+1)
+	}
+	opIDs[names[0]]++
+
+	res := map[string]interface{}{
+		"description": "The operation succeeded.",
+	}
+	if s := refsSchema(resRefs); s != nil {
+		res["content"] = jsonContent(s)
+	}
+
+	spec := map[string]interface{}{
+		"operationId": opID,
+		"summary":     strings.Join(names, ", "),
+		"responses": map[string]interface{}{
+			"200": res,
+			"default": map[string]interface{}{
+				"description": "The operation failed.",
+				"content": jsonContent(map[string]interface{}{
+					"$ref": componentsPrefix + "error",
+				}),
+			},
+		},
+	}
+	if len(params) > 0 {
+		spec["parameters"] = params
+	}
+	if s := refsSchema(reqRefs); s != nil {
+		spec["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content":  jsonContent(s),
+		}
+	}
+	return spec
+}
+
+func appendRef(refs []string, s []byte) []string {
+	name := schema.DefinitionName(s)
+	if name == "" {
+		return refs
+	}
+	for _, r := range refs {
+		if r == name {
+			return refs
+		}
+	}
+	return append(refs, name)
+}
+
+// refsSchema returns a schema that refers to the named components. A oneOf
+// schema is returned if the routes of an operation use different schemas.
+func refsSchema(refs []string) map[string]interface{} {
+	switch len(refs) {
+	case 0:
+		return nil
+	case 1:
+		return map[string]interface{}{"$ref": componentsPrefix + refs[0]}
+	}
+	oneOf := make([]interface{}, len(refs))
+	for i, r := range refs {
+		oneOf[i] = map[string]interface{}{"$ref": componentsPrefix + r}
+	}
+	return map[string]interface{}{"oneOf": oneOf}
+}
+
+func jsonContent(s interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"application/json": map[string]interface{}{"schema": s},
+	}
+}
+
+// rewriteRefs returns a copy of a JSON schema definition with its references
+// to other definitions rewritten to refer to the OpenAPI components.
+func rewriteRefs(v interface{}) interface{} {
+	switch tv := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(tv))
+		for k, vv := range tv {
+			if s, ok := vv.(string); ok && k == "$ref" {
+				m[k] = strings.Replace(
+					s, definitionsPrefix, componentsPrefix, 1)
+				continue
+			}
+			m[k] = rewriteRefs(vv)
+		}
+		return m
+	case []interface{}:
+		a := make([]interface{}, len(tv))
+		for i, vv := range tv {
+			a[i] = rewriteRefs(vv)
+		}
+		return a
+	}
+	return v
+}
@@ -40,6 +40,7 @@ func (r *router) initRoutes() {
 			"services",
 			"/services",
 			r.servicesList,
+			handlers.NewETagHandler(),
 			handlers.NewSchemaValidator(nil, schema.ServiceInfoMapSchema, nil)),
 
 		httputils.NewGetRoute(
@@ -48,5 +49,46 @@ func (r *router) initRoutes() {
 			r.serviceInspect,
 			handlers.NewServiceValidator(),
 			handlers.NewSchemaValidator(nil, schema.ServiceInfoSchema, nil)),
+
+		httputils.NewGetRoute(
+			"serviceCapabilities",
+			"/services/{service}/capabilities",
+			r.serviceCapabilities,
+			handlers.NewServiceValidator(),
+			handlers.NewSchemaValidator(
+				nil, schema.StorageCapabilitiesSchema, nil)),
+
+		httputils.NewGetRoute(
+			"serviceFaults",
+			"/services/{service}/faults",
+			r.serviceFaults,
+			handlers.NewServiceValidator(),
+			handlers.NewSchemaValidator(nil, schema.FaultInjectionSchema, nil)),
+
+		// POST
+		httputils.NewPostRoute(
+			"serviceFaultsSet",
+			"/services/{service}/faults",
+			r.serviceFaultsSet,
+			handlers.NewServiceValidator(),
+			handlers.NewSchemaValidator(
+				schema.FaultInjectionSchema,
+				schema.FaultInjectionSchema,
+				func() interface{} { return &types.FaultInjection{} })),
+
+		httputils.NewPostRoute(
+			"serviceRegister",
+			"/admin/services",
+			r.serviceRegister,
+			handlers.NewSchemaValidator(
+				schema.ServiceRegistrationSchema,
+				schema.ServiceInfoSchema,
+				func() interface{} { return &types.ServiceRegistration{} })),
+
+		// DELETE
+		httputils.NewDeleteRoute(
+			"serviceDeregister",
+			"/admin/services/{service}",
+			r.serviceDeregister),
 	}
 }
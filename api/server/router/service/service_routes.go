@@ -45,6 +45,147 @@ func (r *router) serviceInspect(
 	return nil
 }
 
+func (r *router) serviceCapabilities(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	service := context.MustService(ctx)
+
+	caps := &types.StorageCapabilities{}
+	if d, ok := service.Driver().(types.ProvidesStorageCapabilities); ok {
+		var err error
+		if caps, err = d.Capabilities(ctx); err != nil {
+			return err
+		}
+	}
+
+	httputils.WriteJSON(w, http.StatusOK, caps)
+	return nil
+}
+
+func (r *router) serviceFaults(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	if err := checkAdminToken(ctx, store); err != nil {
+		return err
+	}
+
+	service := context.MustService(ctx)
+	d, ok := service.Driver().(types.ProvidesFaultInjection)
+	if !ok {
+		return types.ErrNotImplemented
+	}
+
+	faults, err := d.Faults(ctx)
+	if err != nil {
+		return err
+	}
+	if faults == nil {
+		faults = &types.FaultInjection{}
+	}
+
+	httputils.WriteJSON(w, http.StatusOK, faults)
+	return nil
+}
+
+func (r *router) serviceFaultsSet(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	if err := checkAdminToken(ctx, store); err != nil {
+		return err
+	}
+
+	faults, ok := ctx.Value("reqObj").(*types.FaultInjection)
+	if !ok {
+		return utils.NewInvalidRequestError(
+			"faults", nil, "missing fault injection")
+	}
+
+	service := context.MustService(ctx)
+	d, ok := service.Driver().(types.ProvidesFaultInjection)
+	if !ok {
+		return types.ErrNotImplemented
+	}
+
+	if err := d.SetFaults(ctx, faults); err != nil {
+		return err
+	}
+	ctx.WithField("faults", faults).Warn("storage driver faults changed")
+
+	httputils.WriteJSON(w, http.StatusOK, faults)
+	return nil
+}
+
+func (r *router) serviceRegister(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	if err := checkAdminToken(ctx, store); err != nil {
+		return err
+	}
+
+	reg, ok := ctx.Value("reqObj").(*types.ServiceRegistration)
+	if !ok {
+		return utils.NewInvalidRequestError(
+			"service", nil, "missing service registration")
+	}
+
+	service, err := services.RegisterStorageService(ctx, reg)
+	if err != nil {
+		return err
+	}
+
+	ctx = context.WithStorageService(ctx, service)
+	si, err := toServiceInfo(ctx, service, store)
+	if err != nil {
+		return err
+	}
+	httputils.WriteJSON(w, http.StatusCreated, si)
+	return nil
+}
+
+func (r *router) serviceDeregister(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	if err := checkAdminToken(ctx, store); err != nil {
+		return err
+	}
+
+	err := services.DeregisterStorageService(ctx, store.GetString("service"))
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusResetContent)
+	return nil
+}
+
+// checkAdminToken returns an error if the request's admin token does not
+// match the server's admin token.
+func checkAdminToken(ctx types.Context, store types.Store) error {
+	expectedToken, ok := ctx.Value(context.AdminTokenKey).(string)
+	if !ok {
+		return utils.NewBadAdminTokenError("missing")
+	}
+	actualToken := store.GetString("admin")
+	if expectedToken != actualToken {
+		return utils.NewBadAdminTokenError(actualToken)
+	}
+	return nil
+}
+
 func toServiceInfo(
 	ctx types.Context,
 	service types.StorageService,
@@ -60,7 +201,7 @@ func toServiceInfo(
 		}
 
 		var err error
-		instance, err = d.InstanceInspect(ctx, store)
+		instance, err = services.InstanceInspect(ctx, service, store)
 		if err != nil {
 			return nil, err
 		}
@@ -75,7 +216,7 @@ func toServiceInfo(
 		return nil, err
 	}
 
-	return &types.ServiceInfo{
+	si := &types.ServiceInfo{
 		Name:     service.Name(),
 		Instance: instance,
 		Driver: &types.DriverInfo{
@@ -83,5 +224,14 @@ func toServiceInfo(
 			Type:       st,
 			NextDevice: nd,
 		},
-	}, nil
+	}
+
+	// the service's default mount options are sent to the client since
+	// they are configured on the server but applied by the client's
+	// integration driver
+	if config := services.StorageServiceConfig(service); config != nil {
+		si.MountOptions = config.GetString(types.ConfigIgVolOpsMountOptions)
+	}
+
+	return si, nil
 }
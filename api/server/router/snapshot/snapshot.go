@@ -43,6 +43,7 @@ func (r *router) initRoutes() {
 			"snapshots",
 			"/snapshots",
 			r.snapshots,
+			handlers.NewETagHandler(),
 			handlers.NewSchemaValidator(
 				nil, schema.ServiceSnapshotMapSchema, nil),
 		),
@@ -52,8 +53,10 @@ func (r *router) initRoutes() {
 			"snapshotsForService",
 			"/snapshots/{service}",
 			r.snapshotsForService,
+			handlers.NewETagHandler(),
 			handlers.NewServiceValidator(),
 			handlers.NewStorageSessionHandler(),
+			handlers.NewListHandler(),
 			handlers.NewSchemaValidator(
 				nil, schema.SnapshotMapSchema, nil),
 		),
@@ -99,6 +102,36 @@ func (r *router) initRoutes() {
 				}),
 			handlers.NewPostArgsHandler(r.config),
 		).Queries("copy"),
+		httputils.NewPostRoute(
+			"snapshotCopyPath",
+			"/snapshots/{service}/{snapshotID}/copy",
+			r.snapshotCopy,
+			handlers.NewServiceValidator(),
+			handlers.NewStorageSessionHandler(),
+			handlers.NewSchemaValidator(
+				schema.SnapshotCopyRequestSchema,
+				schema.SnapshotSchema,
+				func() interface{} {
+					return &types.SnapshotCopyRequest{}
+				}),
+			handlers.NewPostArgsHandler(r.config),
+		),
+
+		// snapshot a group of volumes
+		httputils.NewPostRoute(
+			"snapshotGroupCreate",
+			"/snapshot-groups/{service}",
+			r.snapshotGroupCreate,
+			handlers.NewServiceValidator(),
+			handlers.NewStorageSessionHandler(),
+			handlers.NewSchemaValidator(
+				schema.SnapshotGroupCreateRequestSchema,
+				schema.SnapshotGroupSchema,
+				func() interface{} {
+					return &types.SnapshotGroupCreateRequest{}
+				}),
+			handlers.NewPostArgsHandler(r.config),
+		),
 
 		// DELETE
 		httputils.NewDeleteRoute(
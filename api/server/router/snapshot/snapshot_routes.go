@@ -10,6 +10,7 @@ import (
 	"github.com/codedellemc/libstorage/api/server/services"
 	"github.com/codedellemc/libstorage/api/types"
 	"github.com/codedellemc/libstorage/api/utils"
+	"github.com/codedellemc/libstorage/api/utils/filters"
 	"github.com/codedellemc/libstorage/api/utils/schema"
 )
 
@@ -19,6 +20,11 @@ func (r *router) snapshots(
 	req *http.Request,
 	store types.Store) error {
 
+	filter, err := volume.ParseFilter(store)
+	if err != nil {
+		return err
+	}
+
 	var (
 		tasks   = map[string]*types.Task{}
 		taskIDs []int
@@ -42,11 +48,8 @@ func (r *router) snapshots(
 				return nil, err
 			}
 
-			objMap := map[string]*types.Snapshot{}
-			for _, obj := range objs {
-				objMap[obj.ID] = obj
-			}
-			return objMap, nil
+			objs = services.TenantSnapshots(ctx, svc, objs)
+			return filteredSnapshots(objs, filter), nil
 		}
 
 		task := service.TaskExecute(ctx, run, schema.SnapshotMapSchema)
@@ -89,23 +92,23 @@ func (r *router) snapshotsForService(
 	req *http.Request,
 	store types.Store) error {
 
+	filter, err := volume.ParseFilter(store)
+	if err != nil {
+		return err
+	}
+
 	service := context.MustService(ctx)
 
 	run := func(
 		ctx types.Context,
 		svc types.StorageService) (interface{}, error) {
 
-		var reply types.SnapshotMap = map[string]*types.Snapshot{}
-
 		objs, err := svc.Driver().Snapshots(ctx, store)
 		if err != nil {
 			return nil, err
 		}
-
-		for _, obj := range objs {
-			reply[obj.ID] = obj
-		}
-		return reply, nil
+		objs = services.TenantSnapshots(ctx, svc, objs)
+		return types.SnapshotMap(filteredSnapshots(objs, filter)), nil
 	}
 
 	return httputils.WriteTask(
@@ -156,10 +159,20 @@ func (r *router) snapshotRemove(
 		ctx types.Context,
 		svc types.StorageService) (interface{}, error) {
 
-		return nil, svc.Driver().SnapshotRemove(
+		if err := svc.Driver().SnapshotRemove(
 			ctx,
 			store.GetString("snapshotID"),
-			store)
+			store); err != nil {
+			return nil, err
+		}
+
+		services.PublishEvent(ctx, &types.Event{
+			Type:       types.EventSnapshotRemoved,
+			Service:    svc.Name(),
+			SnapshotID: store.GetString("snapshotID"),
+		})
+
+		return nil, nil
 	}
 
 	return httputils.WriteTask(
@@ -183,10 +196,16 @@ func (r *router) volumeCreate(
 		ctx types.Context,
 		svc types.StorageService) (interface{}, error) {
 
+		name, err := services.VolumeName(
+			ctx, svc, store.GetString("name"), store)
+		if err != nil {
+			return nil, err
+		}
+
 		v, err := svc.Driver().VolumeCreateFromSnapshot(
-			ctx,
+			services.WithVolumeMetadata(ctx, svc),
 			store.GetString("snapshotID"),
-			store.GetString("name"),
+			name,
 			&types.VolumeCreateOpts{
 				AvailabilityZone: store.GetStringPtr("availabilityZone"),
 				IOPS:             store.GetInt64Ptr("iops"),
@@ -199,16 +218,24 @@ func (r *router) volumeCreate(
 			return nil, err
 		}
 
+		services.UnmapVolumeName(svc, v)
+
 		if volume.OnVolume != nil {
 			ok, err := volume.OnVolume(ctx, req, store, v)
 			if err != nil {
 				return nil, err
 			}
 			if !ok {
-				return nil, utils.NewNotFoundError(v.ID)
+				return nil, utils.NewVolumeNotFoundError(v.ID)
 			}
 		}
 
+		services.PublishEvent(ctx, &types.Event{
+			Type:     types.EventVolumeCreated,
+			Service:  svc.Name(),
+			VolumeID: v.ID,
+		})
+
 		return v, nil
 	}
 
@@ -233,12 +260,27 @@ func (r *router) snapshotCopy(
 		ctx types.Context,
 		svc types.StorageService) (interface{}, error) {
 
-		return svc.Driver().SnapshotCopy(
+		s, err := svc.Driver().SnapshotCopy(
 			ctx,
 			store.GetString("snapshotID"),
 			store.GetString("snapshotName"),
 			store.GetString("destinationID"),
 			store)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if s != nil {
+			services.PublishEvent(ctx, &types.Event{
+				Type:       types.EventSnapshotCreated,
+				Service:    svc.Name(),
+				VolumeID:   s.VolumeID,
+				SnapshotID: s.ID,
+			})
+		}
+
+		return s, nil
 	}
 
 	return httputils.WriteTask(
@@ -249,3 +291,96 @@ func (r *router) snapshotCopy(
 		service.TaskExecute(ctx, run, schema.SnapshotSchema),
 		http.StatusCreated)
 }
+
+func (r *router) snapshotGroupCreate(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	service := context.MustService(ctx)
+
+	run := func(
+		ctx types.Context,
+		svc types.StorageService) (interface{}, error) {
+
+		d, ok := svc.Driver().(types.ProvidesSnapshotGroups)
+		if !ok {
+			return nil, types.ErrNotImplemented
+		}
+
+		volumeIDs := store.GetStringSlice("volumeIDs")
+		if err := validateTenantVolumes(ctx, svc, volumeIDs); err != nil {
+			return nil, err
+		}
+
+		group, err := d.SnapshotGroupCreate(
+			ctx,
+			volumeIDs,
+			store.GetString("snapshotName"),
+			store)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range group.Snapshots {
+			services.PublishEvent(ctx, &types.Event{
+				Type:       types.EventSnapshotCreated,
+				Service:    svc.Name(),
+				VolumeID:   s.VolumeID,
+				SnapshotID: s.ID,
+			})
+		}
+
+		return group, nil
+	}
+
+	return httputils.WriteTask(
+		ctx,
+		r.config,
+		w,
+		store,
+		service.TaskExecute(ctx, run, schema.SnapshotGroupSchema),
+		http.StatusCreated)
+}
+
+// validateTenantVolumes returns a not found error for the first of the
+// volumes that does not belong to the request's tenant.
+func validateTenantVolumes(
+	ctx types.Context,
+	svc types.StorageService,
+	volumeIDs []string) error {
+
+	if _, ok := context.Tenant(ctx); !ok {
+		return nil
+	}
+	for _, volumeID := range volumeIDs {
+		v, err := svc.Driver().VolumeInspect(
+			ctx, volumeID, &types.VolumeInspectOpts{Opts: utils.NewStore()})
+		if err != nil {
+			return err
+		}
+		if !services.IsTenantVolume(ctx, svc, v) {
+			ctx.WithField("volumeID", volumeID).Warn(
+				"volume belongs to another tenant")
+			return utils.NewVolumeNotFoundError(volumeID)
+		}
+	}
+	return nil
+}
+
+// filteredSnapshots returns the snapshots that match the filter keyed by
+// their IDs.
+func filteredSnapshots(
+	objs []*types.Snapshot,
+	filter *types.Filter) map[string]*types.Snapshot {
+
+	objMap := map[string]*types.Snapshot{}
+	for _, obj := range objs {
+		if filter != nil && !filters.Match(filter, filters.SnapshotField(obj)) {
+			continue
+		}
+		objMap[obj.ID] = obj
+	}
+	return objMap
+}
@@ -24,6 +24,22 @@ func (r *router) tasks(
 	return nil
 }
 
+// tasksInterrupted returns the tasks that had not completed when the server
+// was previously shut down.
+func (r *router) tasksInterrupted(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	tasks := services.InterruptedTasks(ctx)
+	if tasks == nil {
+		tasks = []*types.InterruptedTask{}
+	}
+	httputils.WriteJSON(w, http.StatusOK, tasks)
+	return nil
+}
+
 func (r *router) taskInspect(
 	ctx types.Context,
 	w http.ResponseWriter,
@@ -33,6 +33,13 @@ func (r *router) initRoutes() {
 
 	r.routes = []types.Route{
 
+		// GET
+		httputils.NewGetRoute(
+			"tasksInterrupted",
+			"/tasks",
+			r.tasksInterrupted,
+		).Queries("interrupted"),
+
 		// GET
 		httputils.NewGetRoute(
 			"tasks",
@@ -56,6 +56,7 @@ func (r *router) initRoutes() {
 			"volumes",
 			"/volumes",
 			r.volumes,
+			handlers.NewETagHandler(),
 			handlers.NewSchemaValidator(nil, schema.ServiceVolumeMapSchema, nil),
 		),
 
@@ -64,8 +65,10 @@ func (r *router) initRoutes() {
 			"volumesForService",
 			"/volumes/{service}",
 			r.volumesForService,
+			handlers.NewETagHandler(),
 			handlers.NewServiceValidator(),
 			handlers.NewStorageSessionHandler(),
+			handlers.NewListHandler(),
 			handlers.NewSchemaValidator(nil, schema.VolumeMapSchema, nil),
 		),
 
@@ -79,6 +82,24 @@ func (r *router) initRoutes() {
 			handlers.NewSchemaValidator(nil, schema.VolumeSchema, nil),
 		),
 
+		// get the usage and IO statistics of a specific volume
+		httputils.NewGetRoute(
+			"volumeStats",
+			"/volumes/{service}/{volumeID}/stats",
+			r.volumeStats,
+			handlers.NewServiceValidator(),
+			handlers.NewStorageSessionHandler(),
+			handlers.NewSchemaValidator(nil, schema.VolumeStatsSchema, nil),
+		),
+
+		// get the history of a specific volume's events
+		httputils.NewGetRoute(
+			"volumeEvents",
+			"/volumes/{service}/{volumeID}/events",
+			r.volumeEvents,
+			handlers.NewServiceValidator(),
+		),
+
 		// POST
 
 		// detach all volumes for a service
@@ -95,6 +116,20 @@ func (r *router) initRoutes() {
 			handlers.NewPostArgsHandler(r.config),
 		).Queries("detach"),
 
+		// import a volume created outside of libStorage
+		httputils.NewPostRoute(
+			"volumeImport",
+			"/volumes/{service}",
+			r.volumeImport,
+			handlers.NewServiceValidator(),
+			handlers.NewStorageSessionHandler(),
+			handlers.NewSchemaValidator(
+				schema.VolumeImportRequestSchema,
+				schema.VolumeSchema,
+				func() interface{} { return &types.VolumeImportRequest{} }),
+			handlers.NewPostArgsHandler(r.config),
+		).Queries("import"),
+
 		// create a new volume
 		httputils.NewPostRoute(
 			"volumeCreate",
@@ -151,6 +186,58 @@ func (r *router) initRoutes() {
 			handlers.NewPostArgsHandler(r.config),
 		).Queries("attach"),
 
+		// report whether or not an attached volume was mounted
+		httputils.NewPostRoute(
+			"volumeMountReport",
+			"/volumes/{service}/{volumeID}",
+			r.volumeMountReport,
+			handlers.NewServiceValidator(),
+			handlers.NewSchemaValidator(
+				schema.VolumeMountReportRequestSchema,
+				nil,
+				func() interface{} { return &types.VolumeMountReportRequest{} }),
+			handlers.NewPostArgsHandler(r.config),
+		).Queries("mountReport"),
+
+		// restore a volume from the recycle bin
+		httputils.NewPostRoute(
+			"volumeUndelete",
+			"/volumes/{service}/{volumeID}",
+			r.volumeUndelete,
+			handlers.NewServiceValidator(),
+			handlers.NewStorageSessionHandler(),
+			handlers.NewSchemaValidator(nil, schema.VolumeSchema, nil),
+		).Queries("undelete"),
+
+		// change the size of a volume
+		httputils.NewPostRoute(
+			"volumeResize",
+			"/volumes/{service}/{volumeID}",
+			r.volumeResize,
+			handlers.NewServiceValidator(),
+			handlers.NewStorageSessionHandler(),
+			handlers.NewSchemaValidator(nil, schema.VolumeSchema, nil),
+		).Queries("resize"),
+
+		// change the type of a volume
+		httputils.NewPostRoute(
+			"volumeRetype",
+			"/volumes/{service}/{volumeID}",
+			r.volumeRetype,
+			handlers.NewServiceValidator(),
+			handlers.NewStorageSessionHandler(),
+			handlers.NewSchemaValidator(nil, schema.VolumeSchema, nil),
+		).Queries("retype"),
+
+		// release a volume from management without removing it
+		httputils.NewPostRoute(
+			"volumeUnmanage",
+			"/volumes/{service}/{volumeID}",
+			r.volumeUnmanage,
+			handlers.NewServiceValidator(),
+			handlers.NewStorageSessionHandler(),
+		).Queries("unmanage"),
+
 		// detach all volumes for all services
 		httputils.NewPostRoute(
 			"volumesDetachAll",
@@ -185,5 +272,14 @@ func (r *router) initRoutes() {
 			handlers.NewServiceValidator(),
 			handlers.NewStorageSessionHandler(),
 		),
+
+		// forcefully detach a volume from a failed instance
+		httputils.NewDeleteRoute(
+			"volumeForceDetach",
+			"/volumes/{service}/{volumeID}/attachments",
+			r.volumeForceDetach,
+			handlers.NewServiceValidator(),
+			handlers.NewStorageSessionHandler(),
+		),
 	}
 }
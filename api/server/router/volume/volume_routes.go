@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/akutz/goof"
@@ -23,7 +24,7 @@ func (r *router) volumes(
 	req *http.Request,
 	store types.Store) error {
 
-	filter, err := parseFilter(store)
+	filter, err := ParseFilter(store)
 	if err != nil {
 		return err
 	}
@@ -97,7 +98,7 @@ func (r *router) volumesForService(
 	req *http.Request,
 	store types.Store) error {
 
-	filter, err := parseFilter(store)
+	filter, err := ParseFilter(store)
 	if err != nil {
 		return err
 	}
@@ -140,6 +141,10 @@ func handleVolAttachments(
 		return true
 	}
 
+	if attachments.Devices() {
+		describeVolAttachments(ctx, iid, vol)
+	}
+
 	if lf == nil {
 		lf = log.Fields{}
 	}
@@ -208,6 +213,28 @@ func handleVolAttachments(
 	return f(vol.AttachmentState)
 }
 
+// describeVolAttachments sets the device metadata of the volume's
+// attachments to the instance from the descriptions of the instance's local
+// devices, which are present if the executor was configured to describe
+// them.
+func describeVolAttachments(
+	ctx types.Context,
+	iid *types.InstanceID,
+	vol *types.Volume) {
+
+	ld, ok := context.LocalDevices(ctx)
+	if !ok || len(ld.Devices) == 0 || iid == nil {
+		return
+	}
+	for _, a := range vol.Attachments {
+		if a.DeviceName == "" || a.InstanceID == nil ||
+			!strings.EqualFold(iid.ID, a.InstanceID.ID) {
+			continue
+		}
+		a.Describe(ld.Devices[a.DeviceName])
+	}
+}
+
 func getFilteredVolumes(
 	ctx types.Context,
 	req *http.Request,
@@ -216,12 +243,7 @@ func getFilteredVolumes(
 	opts *types.VolumesOpts,
 	filter *types.Filter) (types.VolumeMap, error) {
 
-	var (
-		filterOp    types.FilterOperator
-		filterLeft  string
-		filterRight string
-		objMap      = types.VolumeMap{}
-	)
+	objMap := types.VolumeMap{}
 
 	iid, iidOK := context.InstanceID(ctx)
 	if opts.Attachments.RequiresInstanceID() && !iidOK {
@@ -230,31 +252,46 @@ func getFilteredVolumes(
 
 	ctx.WithField("attachments", opts.Attachments).Debug("querying volumes")
 
-	objs, err := storSvc.Driver().Volumes(ctx, opts)
+	managedOnly := services.ManagedVolumesOnly(ctx, storSvc, store)
+
+	var (
+		objs []*types.Volume
+		err  error
+	)
+	if store.GetBool("deleted") {
+		objs, err = recycledVolumes(ctx, storSvc, store)
+	} else {
+		objs, err = storSvc.Driver().Volumes(ctx, opts)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	if filter != nil {
-		filterOp = filter.Op
-		filterLeft = strings.ToLower(filter.Left)
-		filterRight = strings.ToLower(filter.Right)
-	}
-
 	for _, obj := range objs {
 
+		services.UnmapVolumeName(storSvc, obj)
+
 		lf := log.Fields{
 			"attachments": opts.Attachments,
 			"volumeID":    obj.ID,
 			"volumeName":  obj.Name,
 		}
 
-		if filterOp == types.FilterEqualityMatch && filterLeft == "name" {
-			ctx.WithFields(lf).Debug("checking name filter")
-			if !strings.EqualFold(obj.Name, filterRight) {
-				ctx.WithFields(lf).Debug("omitted volume due to name filter")
-				continue
-			}
+		if managedOnly && !services.IsManagedVolume(obj) {
+			ctx.WithFields(lf).Debug("omitted unmanaged volume")
+			continue
+		}
+
+		if !services.IsTenantVolume(ctx, storSvc, obj) {
+			ctx.WithFields(lf).Debug("omitted volume of another tenant")
+			continue
+		}
+
+		// the filter is applied before the attachments are inspected so
+		// that volumes that do not match are not processed further
+		if filter != nil && !filters.Match(filter, filters.VolumeField(obj)) {
+			ctx.WithFields(lf).Debug("omitted volume due to filter")
+			continue
 		}
 
 		if !handleVolAttachments(ctx, lf, iid, obj, opts.Attachments) {
@@ -316,9 +353,13 @@ func (r *router) volumeInspect(
 
 			volID := store.GetString("volumeID")
 			for _, v := range vols {
+				services.UnmapVolumeName(svc, v)
+				if !services.IsTenantVolume(ctx, svc, v) {
+					continue
+				}
 				if strings.EqualFold(v.Name, volID) {
 					if !handleVolAttachments(ctx, nil, iid, v, attachments) {
-						return nil, utils.NewNotFoundError(volID)
+						return nil, utils.NewVolumeNotFoundError(volID)
 					}
 					if OnVolume != nil {
 						ok, err := OnVolume(ctx, req, store, v)
@@ -326,7 +367,7 @@ func (r *router) volumeInspect(
 							return nil, err
 						}
 						if !ok {
-							return nil, utils.NewNotFoundError(volID)
+							return nil, utils.NewVolumeNotFoundError(volID)
 						}
 					}
 
@@ -334,7 +375,7 @@ func (r *router) volumeInspect(
 				}
 			}
 
-			return nil, utils.NewNotFoundError(volID)
+			return nil, utils.NewVolumeNotFoundError(volID)
 		}
 
 	} else {
@@ -350,8 +391,10 @@ func (r *router) volumeInspect(
 				return nil, err
 			}
 
+			services.UnmapVolumeName(svc, v)
+
 			if !handleVolAttachments(ctx, nil, iid, v, attachments) {
-				return nil, utils.NewNotFoundError(v.ID)
+				return nil, utils.NewVolumeNotFoundError(v.ID)
 			}
 
 			if OnVolume != nil {
@@ -360,7 +403,7 @@ func (r *router) volumeInspect(
 					return nil, err
 				}
 				if !ok {
-					return nil, utils.NewNotFoundError(v.ID)
+					return nil, utils.NewVolumeNotFoundError(v.ID)
 				}
 			}
 
@@ -385,40 +428,81 @@ func (r *router) volumeCreate(
 
 	service := context.MustService(ctx)
 
+	opts := &types.VolumeCreateOpts{
+		AvailabilityZone: store.GetStringPtr("availabilityZone"),
+		IOPS:             store.GetInt64Ptr("iops"),
+		Size:             store.GetInt64Ptr("size"),
+		Type:             store.GetStringPtr("type"),
+		Encrypted:        store.GetBoolPtr("encrypted"),
+		EncryptionKey:    store.GetStringPtr("encryptionKey"),
+		Thin:             store.GetBoolPtr("thin"),
+		Opts:             store,
+	}
+
+	if store.GetBool("validate") {
+		run := func(
+			ctx types.Context,
+			svc types.StorageService) (interface{}, error) {
+
+			return validateVolumeCreate(
+				ctx, svc, store.GetString("name"), opts)
+		}
+
+		return httputils.WriteTask(
+			ctx,
+			r.config,
+			w,
+			store,
+			service.TaskExecute(ctx, run, schema.ValidateResponseSchema),
+			http.StatusOK)
+	}
+
 	run := func(
 		ctx types.Context,
 		svc types.StorageService) (interface{}, error) {
 
+		// the request is validated the same way as when it is only
+		// validated
+		if _, err := validateVolumeCreate(
+			ctx, svc, store.GetString("name"), opts); err != nil {
+			return nil, err
+		}
+
+		name, err := services.VolumeName(
+			ctx, svc, store.GetString("name"), store)
+		if err != nil {
+			return nil, err
+		}
+
 		v, err := svc.Driver().VolumeCreate(
-			ctx,
-			store.GetString("name"),
-			&types.VolumeCreateOpts{
-				AvailabilityZone: store.GetStringPtr("availabilityZone"),
-				IOPS:             store.GetInt64Ptr("iops"),
-				Size:             store.GetInt64Ptr("size"),
-				Type:             store.GetStringPtr("type"),
-				Encrypted:        store.GetBoolPtr("encrypted"),
-				EncryptionKey:    store.GetStringPtr("encryptionKey"),
-				Opts:             store,
-			})
+			services.WithVolumeMetadata(ctx, svc), name, opts)
 
 		if err != nil {
 			return nil, err
 		}
 
+		services.UnmapVolumeName(svc, v)
+
 		if OnVolume != nil {
 			ok, err := OnVolume(ctx, req, store, v)
 			if err != nil {
 				return nil, err
 			}
 			if !ok {
-				return nil, utils.NewNotFoundError(v.ID)
+				return nil, utils.NewVolumeNotFoundError(v.ID)
 			}
 		}
 
 		if v.AttachmentState == 0 {
 			v.AttachmentState = types.VolumeAvailable
 		}
+
+		services.PublishEvent(ctx, &types.Event{
+			Type:     types.EventVolumeCreated,
+			Service:  svc.Name(),
+			VolumeID: v.ID,
+		})
+
 		return v, nil
 	}
 
@@ -443,9 +527,76 @@ func (r *router) volumeCopy(
 		ctx types.Context,
 		svc types.StorageService) (interface{}, error) {
 
+		name, err := services.VolumeName(
+			ctx, svc, store.GetString("volumeName"), store)
+		if err != nil {
+			return nil, err
+		}
+
 		v, err := svc.Driver().VolumeCopy(
-			ctx,
+			services.WithVolumeMetadata(ctx, svc),
 			store.GetString("volumeID"),
+			name,
+			store)
+
+		if err != nil {
+			return nil, err
+		}
+
+		services.UnmapVolumeName(svc, v)
+
+		if OnVolume != nil {
+			ok, err := OnVolume(ctx, req, store, v)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return nil, utils.NewVolumeNotFoundError(v.ID)
+			}
+		}
+
+		if v.AttachmentState == 0 {
+			v.AttachmentState = types.VolumeAvailable
+		}
+
+		services.PublishEvent(ctx, &types.Event{
+			Type:     types.EventVolumeCreated,
+			Service:  svc.Name(),
+			VolumeID: v.ID,
+		})
+
+		return v, nil
+	}
+
+	return httputils.WriteTask(
+		ctx,
+		r.config,
+		w,
+		store,
+		service.TaskExecute(ctx, run, schema.VolumeSchema),
+		http.StatusCreated)
+}
+
+func (r *router) volumeImport(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	service := context.MustService(ctx)
+
+	run := func(
+		ctx types.Context,
+		svc types.StorageService) (interface{}, error) {
+
+		d, ok := svc.Driver().(types.ProvidesVolumeImport)
+		if !ok {
+			return nil, types.ErrNotImplemented
+		}
+
+		v, err := d.VolumeImport(
+			services.WithVolumeMetadata(ctx, svc),
+			store.GetString("nativeID"),
 			store.GetString("volumeName"),
 			store)
 
@@ -459,13 +610,20 @@ func (r *router) volumeCopy(
 				return nil, err
 			}
 			if !ok {
-				return nil, utils.NewNotFoundError(v.ID)
+				return nil, utils.NewVolumeNotFoundError(v.ID)
 			}
 		}
 
 		if v.AttachmentState == 0 {
 			v.AttachmentState = types.VolumeAvailable
 		}
+
+		services.PublishEvent(ctx, &types.Event{
+			Type:     types.EventVolumeImported,
+			Service:  svc.Name(),
+			VolumeID: v.ID,
+		})
+
 		return v, nil
 	}
 
@@ -490,11 +648,26 @@ func (r *router) volumeSnapshot(
 		ctx types.Context,
 		svc types.StorageService) (interface{}, error) {
 
-		return svc.Driver().VolumeSnapshot(
-			ctx,
+		s, err := svc.Driver().VolumeSnapshot(
+			services.WithVolumeMetadata(ctx, svc),
 			store.GetString("volumeID"),
 			store.GetString("snapshotName"),
 			store)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if s != nil {
+			services.PublishEvent(ctx, &types.Event{
+				Type:       types.EventSnapshotCreated,
+				Service:    svc.Name(),
+				VolumeID:   s.VolumeID,
+				SnapshotID: s.ID,
+			})
+		}
+
+		return s, nil
 	}
 
 	return httputils.WriteTask(
@@ -517,20 +690,80 @@ func (r *router) volumeAttach(
 		return utils.NewMissingInstanceIDError(service.Name())
 	}
 
+	validateTopology := r.config.GetBool(types.ConfigServerTopologyValidate)
+
+	accessMode, ok := types.ParseAttachAccessMode(
+		store.GetString("accessMode"))
+	if !ok {
+		return utils.NewInvalidRequestError(
+			"accessMode", store.GetString("accessMode"),
+			"invalid access mode")
+	}
+
+	opts := &types.VolumeAttachOpts{
+		NextDevice: store.GetStringPtr("nextDeviceName"),
+		Force:      store.GetBool("force"),
+		AccessMode: accessMode,
+		Opts:       store,
+	}
+
+	wait := store.GetBool("wait")
+	waitTimeout, err := attachWaitTimeout(store)
+	if err != nil {
+		return err
+	}
+
+	if store.GetBool("validate") {
+		run := func(
+			ctx types.Context,
+			svc types.StorageService) (interface{}, error) {
+
+			return validateVolumeAttach(
+				ctx, svc, store.GetString("volumeID"), validateTopology, opts)
+		}
+
+		return httputils.WriteTask(
+			ctx,
+			r.config,
+			w,
+			store,
+			service.TaskExecute(ctx, run, schema.ValidateResponseSchema),
+			http.StatusOK)
+	}
+
 	run := func(
 		ctx types.Context,
 		svc types.StorageService) (interface{}, error) {
 
-		v, attTokn, err := svc.Driver().VolumeAttach(
-			ctx,
-			store.GetString("volumeID"),
-			&types.VolumeAttachOpts{
-				NextDevice: store.GetStringPtr("nextDeviceName"),
-				Force:      store.GetBool("force"),
-				Opts:       store,
-			})
+		volumeID := store.GetString("volumeID")
+
+		// the request is validated the same way as when it is only
+		// validated
+		if _, err := validateVolumeAttach(
+			ctx, svc, volumeID, validateTopology, opts); err != nil {
+			return nil, err
+		}
+
+		// reserve the next device so that a concurrent attachment to the
+		// same instance is not given the same device
+		if opts.NextDevice != nil {
+			info, err := svc.Driver().NextDeviceInfo(ctx)
+			if err != nil {
+				return nil, err
+			}
+			nextDevice, err := services.ReserveNextDevice(
+				ctx, volumeID, *opts.NextDevice, info)
+			if err != nil {
+				return nil, err
+			}
+			opts.NextDevice = &nextDevice
+		}
+
+		v, attTokn, err := svc.Driver().VolumeAttach(ctx, volumeID, opts)
 
 		if err != nil {
+			services.ReleaseNextDevice(ctx, volumeID)
+			services.InvalidateInstance(ctx, svc)
 			return nil, err
 		}
 
@@ -540,7 +773,7 @@ func (r *router) volumeAttach(
 				return nil, err
 			}
 			if !ok {
-				return nil, utils.NewNotFoundError(v.ID)
+				return nil, utils.NewVolumeNotFoundError(v.ID)
 			}
 		}
 
@@ -548,18 +781,50 @@ func (r *router) volumeAttach(
 			v.AttachmentState = types.VolumeAttached
 		}
 
+		publishAuditedEvent(ctx, store, &types.Event{
+			Type:     types.EventVolumeAttached,
+			Service:  svc.Name(),
+			VolumeID: v.ID,
+		})
+
+		// the instance's client reports whether or not the volume was
+		// mounted with the mount token
+		mountToken, err := services.IssueMountToken(ctx, v.ID)
+		if err != nil {
+			ctx.WithError(err).Warn("error issuing mount token")
+		} else if mountToken != "" {
+			if v.Fields == nil {
+				v.Fields = map[string]string{}
+			}
+			v.Fields[types.VolumeFieldMountToken] = mountToken
+		}
+
 		return &types.VolumeAttachResponse{
 			Volume:      v,
 			AttachToken: attTokn,
 		}, nil
 	}
 
+	task := service.TaskExecute(ctx, run, schema.VolumeAttachResponseSchema)
+
+	// the request may wait for the storage platform to complete the
+	// attachment so that the returned volume includes the final device; the
+	// wait is a task of its own so that it does not occupy one of the
+	// service's task workers
+	if wait {
+		waitRun := func(ctx types.Context) (interface{}, error) {
+			return waitForAttachment(ctx, service, task, waitTimeout, store)
+		}
+		task = services.TaskExecute(
+			ctx, waitRun, schema.VolumeAttachResponseSchema)
+	}
+
 	return httputils.WriteTask(
 		ctx,
 		r.config,
 		w,
 		store,
-		service.TaskExecute(ctx, run, schema.VolumeAttachResponseSchema),
+		task,
 		http.StatusOK)
 }
 
@@ -570,7 +835,8 @@ func (r *router) volumeDetach(
 	store types.Store) error {
 
 	service := context.MustService(ctx)
-	if _, ok := context.InstanceID(ctx); !ok {
+	iid, ok := context.InstanceID(ctx)
+	if !ok {
 		return utils.NewMissingInstanceIDError(service.Name())
 	}
 
@@ -578,6 +844,25 @@ func (r *router) volumeDetach(
 		ctx types.Context,
 		svc types.StorageService) (interface{}, error) {
 
+		// the instance from which the volume is detached is the request's
+		// own, so a volume attached only to other instances may not be
+		// detached by the request
+		if checkAdmin(ctx, store) != nil {
+			v, err := svc.Driver().VolumeInspect(
+				ctx, store.GetString("volumeID"), &types.VolumeInspectOpts{
+					Attachments: types.VolAttReq,
+					Opts:        store,
+				})
+			if err != nil {
+				return nil, err
+			}
+			if attachedElsewhere(v, iid.ID) {
+				ctx.WithField("volumeID", v.ID).Warn(
+					"volume attached to another instance")
+				return nil, utils.NewVolumeAttachedError(v.ID)
+			}
+		}
+
 		v, err := svc.Driver().VolumeDetach(
 			ctx,
 			store.GetString("volumeID"),
@@ -587,9 +872,18 @@ func (r *router) volumeDetach(
 			})
 
 		if err != nil {
+			services.InvalidateInstance(ctx, svc)
 			return nil, err
 		}
 
+		services.ReleaseNextDevice(ctx, store.GetString("volumeID"))
+
+		publishAuditedEvent(ctx, store, &types.Event{
+			Type:     types.EventVolumeDetached,
+			Service:  svc.Name(),
+			VolumeID: store.GetString("volumeID"),
+		})
+
 		if v == nil {
 			return nil, nil
 		}
@@ -600,7 +894,7 @@ func (r *router) volumeDetach(
 				return nil, err
 			}
 			if !ok {
-				return nil, utils.NewNotFoundError(v.ID)
+				return nil, utils.NewVolumeNotFoundError(v.ID)
 			}
 		}
 
@@ -632,8 +926,15 @@ func (r *router) volumeDetachAll(
 		opts                            = &types.VolumesOpts{Opts: store}
 		reply    types.ServiceVolumeMap = map[string]types.VolumeMap{}
 		replyRWL                        = &sync.Mutex{}
+		admin                           = checkAdmin(ctx, store) == nil
 	)
 
+	// the volumes' attachments are required in order to skip the volumes
+	// attached only to other instances
+	if !admin {
+		opts.Attachments = types.VolAttReq
+	}
+
 	for service := range services.StorageServices(ctx) {
 
 		run := func(
@@ -642,7 +943,8 @@ func (r *router) volumeDetachAll(
 
 			ctx = context.WithStorageService(ctx, svc)
 
-			if _, ok := context.InstanceID(ctx); !ok {
+			iid, ok := context.InstanceID(ctx)
+			if !ok {
 				return nil, utils.NewMissingInstanceIDError(service.Name())
 			}
 
@@ -669,6 +971,12 @@ func (r *router) volumeDetachAll(
 			}()
 
 			for _, volume := range volumes {
+				if !services.IsTenantVolume(ctx, svc, volume) {
+					continue
+				}
+				if !admin && attachedElsewhere(volume, iid.ID) {
+					continue
+				}
 				v, err := driver.VolumeDetach(
 					ctx,
 					volume.ID,
@@ -677,12 +985,17 @@ func (r *router) volumeDetachAll(
 						Opts:  store,
 					})
 				if err != nil {
+					services.InvalidateInstance(ctx, svc)
 					return nil, err
 				}
 
-				if err != nil {
-					return nil, err
-				}
+				services.ReleaseNextDevice(ctx, volume.ID)
+
+				publishAuditedEvent(ctx, store, &types.Event{
+					Type:     types.EventVolumeDetached,
+					Service:  svc.Name(),
+					VolumeID: volume.ID,
+				})
 
 				if v == nil {
 					continue
@@ -694,7 +1007,7 @@ func (r *router) volumeDetachAll(
 						return nil, err
 					}
 					if !ok {
-						return nil, utils.NewNotFoundError(v.ID)
+						return nil, utils.NewVolumeNotFoundError(v.ID)
 					}
 				}
 
@@ -739,7 +1052,8 @@ func (r *router) volumeDetachAllForService(
 	store types.Store) error {
 
 	service := context.MustService(ctx)
-	if _, ok := context.InstanceID(ctx); !ok {
+	iid, ok := context.InstanceID(ctx)
+	if !ok {
 		return utils.NewMissingInstanceIDError(service.Name())
 	}
 
@@ -751,12 +1065,26 @@ func (r *router) volumeDetachAllForService(
 
 		driver := svc.Driver()
 
-		volumes, err := driver.Volumes(ctx, &types.VolumesOpts{Opts: store})
+		// the volumes' attachments are required in order to skip the
+		// volumes attached only to other instances
+		admin := checkAdmin(ctx, store) == nil
+		opts := &types.VolumesOpts{Opts: store}
+		if !admin {
+			opts.Attachments = types.VolAttReq
+		}
+
+		volumes, err := driver.Volumes(ctx, opts)
 		if err != nil {
 			return nil, err
 		}
 
 		for _, volume := range volumes {
+			if !services.IsTenantVolume(ctx, svc, volume) {
+				continue
+			}
+			if !admin && attachedElsewhere(volume, iid.ID) {
+				continue
+			}
 			v, err := driver.VolumeDetach(
 				ctx,
 				volume.ID,
@@ -765,12 +1093,17 @@ func (r *router) volumeDetachAllForService(
 					Opts:  store,
 				})
 			if err != nil {
+				services.InvalidateInstance(ctx, svc)
 				return nil, utils.NewBatchProcessErr(reply, err)
 			}
 
-			if err != nil {
-				return nil, err
-			}
+			services.ReleaseNextDevice(ctx, volume.ID)
+
+			publishAuditedEvent(ctx, store, &types.Event{
+				Type:     types.EventVolumeDetached,
+				Service:  svc.Name(),
+				VolumeID: volume.ID,
+			})
 
 			if v == nil {
 				continue
@@ -782,7 +1115,7 @@ func (r *router) volumeDetachAllForService(
 					return nil, err
 				}
 				if !ok {
-					return nil, utils.NewNotFoundError(v.ID)
+					return nil, utils.NewVolumeNotFoundError(v.ID)
 				}
 			}
 
@@ -813,17 +1146,74 @@ func (r *router) volumeRemove(
 
 	service := context.MustService(ctx)
 
+	opts := &types.VolumeRemoveOpts{
+		Force: store.GetBool("force"),
+		Opts:  store,
+	}
+
+	if store.GetBool("validate") {
+		run := func(
+			ctx types.Context,
+			svc types.StorageService) (interface{}, error) {
+
+			return validateVolumeRemove(
+				ctx, svc, store.GetString("volumeID"), opts)
+		}
+
+		return httputils.WriteTask(
+			ctx,
+			r.config,
+			w,
+			store,
+			service.TaskExecute(ctx, run, schema.ValidateResponseSchema),
+			http.StatusOK)
+	}
+
 	run := func(
 		ctx types.Context,
 		svc types.StorageService) (interface{}, error) {
 
-		return nil, svc.Driver().VolumeRemove(
-			ctx,
-			store.GetString("volumeID"),
-			&types.VolumeRemoveOpts{
-				Force: store.GetBool("force"),
-				Opts:  store,
-			})
+		volumeID := store.GetString("volumeID")
+		purge := store.GetBool("purge")
+
+		// a recycled volume may only be purged
+		if purge {
+			purged, err := purgeRecycledVolume(ctx, svc, volumeID, store)
+			if err != nil {
+				return nil, err
+			}
+			if purged {
+				return nil, nil
+			}
+		}
+
+		if err := validateVolumeNotInUse(
+			ctx, svc, volumeID, opts); err != nil {
+			return nil, err
+		}
+
+		if !purge && services.VolumeRecycleRetention(svc) > 0 {
+			recycled, err := recycleVolume(ctx, svc, volumeID, store)
+			if err != nil {
+				return nil, err
+			}
+			if recycled {
+				return nil, nil
+			}
+		}
+
+		if err := svc.Driver().VolumeRemove(
+			ctx, volumeID, opts); err != nil {
+			return nil, err
+		}
+
+		publishAuditedEvent(ctx, store, &types.Event{
+			Type:     types.EventVolumeRemoved,
+			Service:  svc.Name(),
+			VolumeID: volumeID,
+		})
+
+		return nil, nil
 	}
 
 	return httputils.WriteTask(
@@ -835,14 +1225,727 @@ func (r *router) volumeRemove(
 		http.StatusNoContent)
 }
 
-func parseFilter(store types.Store) (*types.Filter, error) {
-	if !store.IsSet("filter") {
-		return nil, nil
+// volumeMountReport records whether or not the client of the instance to
+// which a volume was attached mounted the volume. The client presents the
+// mount token the server issued when the volume was attached.
+func (r *router) volumeMountReport(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	service := context.MustService(ctx)
+	if _, ok := context.InstanceID(ctx); !ok {
+		return utils.NewMissingInstanceIDError(service.Name())
 	}
-	fsz := store.GetString("filter")
-	filter, err := filters.CompileFilter(fsz)
-	if err != nil {
-		return nil, utils.NewBadFilterErr(fsz, err)
+
+	volumeID := store.GetString("volumeID")
+	if err := services.RedeemMountToken(
+		ctx, volumeID, store.GetString("token")); err != nil {
+		return err
 	}
-	return filter, nil
+
+	ev := &types.Event{
+		Type:     types.EventVolumeMounted,
+		Service:  service.Name(),
+		VolumeID: volumeID,
+		Fields:   map[string]string{},
+	}
+	if mp := store.GetString("mountPoint"); mp != "" {
+		ev.Fields[types.EventFieldMountPoint] = mp
+	}
+	if msg := store.GetString("error"); msg != "" {
+		ev.Type = types.EventVolumeMountFailed
+		ev.Fields[types.EventFieldError] = msg
+	}
+	publishAuditedEvent(ctx, store, ev)
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// volumeUndelete restores a volume from the service's recycle bin.
+func (r *router) volumeUndelete(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	service := context.MustService(ctx)
+
+	run := func(
+		ctx types.Context,
+		svc types.StorageService) (interface{}, error) {
+
+		d, ok := svc.Driver().(types.ProvidesVolumeRecycling)
+		if !ok {
+			return nil, types.ErrNotImplemented
+		}
+
+		v, err := d.RecycledVolumeRestore(
+			ctx, store.GetString("volumeID"), store)
+		if err != nil {
+			return nil, err
+		}
+
+		services.PublishEvent(ctx, &types.Event{
+			Type:     types.EventVolumeRestored,
+			Service:  svc.Name(),
+			VolumeID: v.ID,
+		})
+
+		return v, nil
+	}
+
+	return httputils.WriteTask(
+		ctx,
+		r.config,
+		w,
+		store,
+		service.TaskExecute(ctx, run, schema.VolumeSchema),
+		http.StatusOK)
+}
+
+// volumeEvents returns the history of the volume's events, such as when it
+// was created, attached to and detached from instances, snapshotted, and
+// resized. The history is kept by the server, so it is returned even if the
+// volume has been removed.
+func (r *router) volumeEvents(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	service := context.MustService(ctx)
+	events := services.VolumeEvents(
+		ctx, service.Name(), store.GetString("volumeID"))
+	httputils.WriteJSON(w, http.StatusOK, events)
+	return nil
+}
+
+// volumeStats returns the IO statistics of a volume as reported by the
+// storage platform. The statistics omit the IO statistics if the storage
+// driver does not provide them. The usage of the volume's file system is
+// added by the client on whose instance the volume is mounted.
+func (r *router) volumeStats(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	service := context.MustService(ctx)
+
+	run := func(
+		ctx types.Context,
+		svc types.StorageService) (interface{}, error) {
+
+		volumeID := store.GetString("volumeID")
+		v, err := svc.Driver().VolumeInspect(
+			ctx, volumeID, &types.VolumeInspectOpts{
+				Attachments: types.VolAttNone,
+				Opts:        store,
+			})
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			return nil, utils.NewVolumeNotFoundError(volumeID)
+		}
+
+		stats := &types.VolumeStats{
+			VolumeID: volumeID,
+			Time:     time.Now().Unix(),
+		}
+
+		if d, ok := svc.Driver().(types.ProvidesVolumeStats); ok {
+			ioStats, err := d.VolumeStats(ctx, volumeID, store)
+			if err != nil && err != types.ErrNotImplemented {
+				return nil, err
+			}
+			stats.IO = ioStats
+		}
+
+		return stats, nil
+	}
+
+	return httputils.WriteTask(
+		ctx,
+		r.config,
+		w,
+		store,
+		service.TaskExecute(ctx, run, schema.VolumeStatsSchema),
+		http.StatusOK)
+}
+
+// volumeResize changes the size of a volume to the size in GiB specified by
+// the size query parameter.
+func (r *router) volumeResize(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	service := context.MustService(ctx)
+
+	size := store.GetInt64("size")
+	if size <= 0 {
+		return utils.NewInvalidRequestError(
+			"size", store.GetString("size"), "size must be greater than zero")
+	}
+
+	run := func(
+		ctx types.Context,
+		svc types.StorageService) (interface{}, error) {
+
+		d, ok := svc.Driver().(types.ProvidesVolumeResize)
+		if !ok {
+			return nil, types.ErrNotImplemented
+		}
+
+		volumeID := store.GetString("volumeID")
+		if err := validateVolumeQuota(ctx, svc, volumeID, size); err != nil {
+			return nil, err
+		}
+
+		v, err := d.VolumeResize(ctx, volumeID, size, store)
+		if err != nil {
+			return nil, err
+		}
+
+		services.PublishEvent(ctx, &types.Event{
+			Type:     types.EventVolumeResized,
+			Service:  svc.Name(),
+			VolumeID: volumeID,
+		})
+
+		return v, nil
+	}
+
+	return httputils.WriteTask(
+		ctx,
+		r.config,
+		w,
+		store,
+		service.TaskExecute(ctx, run, schema.VolumeSchema),
+		http.StatusOK)
+}
+
+// volumeRetype changes the type of a volume to the type specified by the
+// type query parameter.
+func (r *router) volumeRetype(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	service := context.MustService(ctx)
+
+	volumeType := store.GetString("type")
+	if volumeType == "" {
+		return utils.NewInvalidRequestError(
+			"type", volumeType, "type is required")
+	}
+
+	run := func(
+		ctx types.Context,
+		svc types.StorageService) (interface{}, error) {
+
+		d, ok := svc.Driver().(types.ProvidesVolumeRetype)
+		if !ok {
+			return nil, types.ErrNotImplemented
+		}
+
+		volumeID := store.GetString("volumeID")
+		v, err := d.VolumeRetype(ctx, volumeID, volumeType, store)
+		if err != nil {
+			return nil, err
+		}
+
+		services.PublishEvent(ctx, &types.Event{
+			Type:     types.EventVolumeRetyped,
+			Service:  svc.Name(),
+			VolumeID: volumeID,
+			Fields:   map[string]string{"type": volumeType},
+		})
+
+		return v, nil
+	}
+
+	return httputils.WriteTask(
+		ctx,
+		r.config,
+		w,
+		store,
+		service.TaskExecute(ctx, run, schema.VolumeSchema),
+		http.StatusOK)
+}
+
+// volumeUnmanage releases a volume from libStorage management without
+// removing it from the storage platform. A volume that is attached to an
+// instance is not released unless the force query parameter is set.
+func (r *router) volumeUnmanage(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	service := context.MustService(ctx)
+
+	run := func(
+		ctx types.Context,
+		svc types.StorageService) (interface{}, error) {
+
+		d, ok := svc.Driver().(types.ProvidesVolumeUnmanage)
+		if !ok {
+			return nil, types.ErrNotImplemented
+		}
+
+		volumeID := store.GetString("volumeID")
+		if err := validateVolumeNotInUse(
+			ctx, svc, volumeID, &types.VolumeRemoveOpts{
+				Force: store.GetBool("force"),
+				Opts:  store,
+			}); err != nil {
+			return nil, err
+		}
+
+		if err := d.VolumeUnmanage(ctx, volumeID, store); err != nil {
+			return nil, err
+		}
+
+		services.PublishEvent(ctx, &types.Event{
+			Type:     types.EventVolumeUnmanaged,
+			Service:  svc.Name(),
+			VolumeID: volumeID,
+		})
+
+		return nil, nil
+	}
+
+	return httputils.WriteTask(
+		ctx,
+		r.config,
+		w,
+		store,
+		service.TaskExecute(ctx, run, nil),
+		http.StatusNoContent)
+}
+
+// volumeForceDetach forcefully detaches a volume from the instance specified
+// by the instanceID query parameter, or from all of the instances to which
+// the volume is attached, such as when the instances have failed. The
+// detachment is published as an EventVolumeForceDetached event for each
+// instance so that the instance's client, if it recovers, removes the
+// volume's mounts and mapping. Because the instance is not the caller's own,
+// only administrators may forcefully detach a volume.
+func (r *router) volumeForceDetach(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	if err := checkAdmin(ctx, store); err != nil {
+		return err
+	}
+
+	service := context.MustService(ctx)
+
+	if !store.GetBool("force") {
+		return utils.NewInvalidRequestError(
+			"force", store.GetString("force"),
+			"attachments may only be removed forcefully")
+	}
+
+	run := func(
+		ctx types.Context,
+		svc types.StorageService) (interface{}, error) {
+
+		volumeID := store.GetString("volumeID")
+		instanceID := store.GetString("instanceID")
+
+		v, err := svc.Driver().VolumeInspect(
+			ctx, volumeID, &types.VolumeInspectOpts{
+				Attachments: types.VolAttReqTrue,
+				Opts:        store,
+			})
+		if err != nil {
+			return nil, err
+		}
+
+		if OnVolume != nil {
+			ok, err := OnVolume(ctx, req, store, v)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return nil, utils.NewVolumeNotFoundError(v.ID)
+			}
+		}
+
+		// storage platforms that are unable to report the instances to
+		// which a volume is attached, such as Ceph, still detach the volume
+		// when no attachments are listed
+		var atts []*types.VolumeAttachment
+		for _, a := range v.Attachments {
+			if a.InstanceID == nil {
+				continue
+			}
+			if instanceID == "" || a.InstanceID.ID == instanceID {
+				atts = append(atts, a)
+			}
+		}
+		if len(atts) == 0 {
+			a := &types.VolumeAttachment{VolumeID: volumeID}
+			if instanceID != "" {
+				a.InstanceID = &types.InstanceID{
+					ID:     instanceID,
+					Driver: svc.Driver().Name(),
+				}
+			}
+			atts = append(atts, a)
+		}
+
+		for _, a := range atts {
+			actx := ctx
+			if a.InstanceID != nil {
+				actx = ctx.WithValue(context.InstanceIDKey, a.InstanceID)
+			}
+
+			if v, err = svc.Driver().VolumeDetach(
+				actx, volumeID, &types.VolumeDetachOpts{
+					Force: true,
+					Opts:  store,
+				}); err != nil {
+				services.InvalidateInstance(actx, svc)
+				return nil, err
+			}
+
+			fields := map[string]string{}
+			if a.InstanceID != nil {
+				services.ReleaseNextDevice(actx, volumeID)
+				fields[types.EventFieldInstanceID] = a.InstanceID.ID
+			}
+			if a.DeviceName != "" {
+				fields[types.EventFieldDeviceName] = a.DeviceName
+			}
+
+			actx.WithFields(log.Fields{
+				"volumeID":   volumeID,
+				"attachment": fields,
+			}).Warn("forcefully detached volume")
+
+			publishAuditedEvent(ctx, store, &types.Event{
+				Type:     types.EventVolumeForceDetached,
+				Service:  svc.Name(),
+				VolumeID: volumeID,
+				Fields:   fields,
+			})
+		}
+
+		publishAuditedEvent(ctx, store, &types.Event{
+			Type:     types.EventVolumeDetached,
+			Service:  svc.Name(),
+			VolumeID: volumeID,
+		})
+
+		return v, nil
+	}
+
+	return httputils.WriteTask(
+		ctx,
+		r.config,
+		w,
+		store,
+		service.TaskExecute(ctx, run, nil),
+		http.StatusResetContent)
+}
+
+// publishAuditedEvent records the reason and requester a client gave for
+// attaching, detaching, or removing a volume, as well as the client's
+// authenticated identity, in the event's fields and in the audit log before
+// publishing the event.
+func publishAuditedEvent(
+	ctx types.Context,
+	store types.Store,
+	ev *types.Event) {
+
+	if ev.Fields == nil {
+		ev.Fields = map[string]string{}
+	}
+	if v := store.GetString("reason"); v != "" {
+		ev.Fields[types.EventFieldReason] = v
+	}
+	if v := store.GetString("requestedBy"); v != "" {
+		ev.Fields[types.EventFieldRequestedBy] = v
+	}
+	if v, ok := ctx.Value(context.UserKey).(string); ok && v != "" {
+		ev.Fields[types.EventFieldUser] = v
+	}
+	if iid, ok := context.InstanceID(ctx); ok && iid != nil &&
+		ev.Fields[types.EventFieldInstanceID] == "" {
+		ev.Fields[types.EventFieldInstanceID] = iid.ID
+	}
+
+	fields := log.Fields{
+		"audit":    true,
+		"event":    ev.Type,
+		"service":  ev.Service,
+		"volumeID": ev.VolumeID,
+	}
+	for k, v := range ev.Fields {
+		fields[k] = v
+	}
+	ctx.WithFields(fields).Info("volume audit")
+
+	services.PublishEvent(ctx, ev)
+}
+
+// ParseFilter compiles the request's filter query parameter, if present.
+func ParseFilter(store types.Store) (*types.Filter, error) {
+	if !store.IsSet("filter") {
+		return nil, nil
+	}
+	fsz := store.GetString("filter")
+	filter, err := filters.CompileFilter(fsz)
+	if err != nil {
+		return nil, utils.NewBadFilterErr(fsz, err)
+	}
+	return filter, nil
+}
+
+// validateVolumeTopology ensures a volume and the instance to which it is
+// being attached reside in the same availability zone, or the same region
+// if the driver's volumes are regional, and in the same rack if the driver
+// reports rack topology. The check is skipped when the volume does not
+// report its location or the driver reports no topology constraints, but
+// the attach is rejected when the instance's location cannot be determined.
+func validateVolumeTopology(
+	ctx types.Context,
+	svc types.StorageService,
+	volumeID string,
+	store types.Store) error {
+
+	// the capabilities are checked first so that the volumes of drivers
+	// without topology constraints are not inspected before each attach
+	topology := []string{"zone"}
+	if d, ok := svc.Driver().(types.ProvidesStorageCapabilities); ok {
+		caps, err := d.Capabilities(ctx)
+		if err != nil {
+			return err
+		}
+		if len(caps.Topology) == 0 {
+			return nil
+		}
+		topology = caps.Topology
+	}
+
+	vol, err := svc.Driver().VolumeInspect(
+		ctx, volumeID, &types.VolumeInspectOpts{Opts: store})
+	if err != nil {
+		return err
+	}
+	if vol == nil || (vol.AvailabilityZone == "" && vol.Rack == "") {
+		return nil
+	}
+
+	inst, err := services.InstanceInspect(ctx, svc, store)
+	if err != nil {
+		return err
+	}
+
+	if err := checkVolumeZone(volumeID, topology, inst, vol); err != nil {
+		ctx.WithFields(log.Fields{
+			"volumeID":   volumeID,
+			"volumeZone": vol.AvailabilityZone,
+			"topology":   topology,
+		}).Warn("volume attach rejected due to zone mismatch")
+		return err
+	}
+	if err := checkVolumeRack(volumeID, topology, inst, vol); err != nil {
+		ctx.WithFields(log.Fields{
+			"volumeID":   volumeID,
+			"volumeRack": vol.Rack,
+			"topology":   topology,
+		}).Warn("volume attach rejected due to rack mismatch")
+		return err
+	}
+	return nil
+}
+
+// checkVolumeZone returns an ErrWrongZone error if the volume's availability
+// zone differs from the instance's zone, or from the instance's region if
+// the region is the only topology constraint. The check is skipped if the
+// volume does not report its zone, but fails if the instance does not report
+// its location.
+func checkVolumeZone(
+	volumeID string,
+	topology []string,
+	inst *types.Instance,
+	vol *types.Volume) error {
+
+	if vol == nil || vol.AvailabilityZone == "" {
+		return nil
+	}
+	var instZone string
+	if inst != nil {
+		instZone = inst.Zone
+		if len(topology) == 1 && topology[0] == "region" {
+			instZone = inst.Region
+		}
+	}
+	if instZone != "" && strings.EqualFold(vol.AvailabilityZone, instZone) {
+		return nil
+	}
+	return utils.NewWrongZoneError(volumeID, vol.AvailabilityZone, instZone)
+}
+
+// checkVolumeRack returns an ErrWrongZone error if rack is a topology
+// constraint and the volume's rack differs from the instance's rack. The
+// check is skipped if the volume does not report its rack, but fails if the
+// instance does not report its rack.
+func checkVolumeRack(
+	volumeID string,
+	topology []string,
+	inst *types.Instance,
+	vol *types.Volume) error {
+
+	if vol == nil || vol.Rack == "" {
+		return nil
+	}
+	constrained := false
+	for _, t := range topology {
+		if t == "rack" {
+			constrained = true
+			break
+		}
+	}
+	if !constrained {
+		return nil
+	}
+	var instRack string
+	if inst != nil {
+		instRack = inst.Rack
+	}
+	if instRack != "" && strings.EqualFold(vol.Rack, instRack) {
+		return nil
+	}
+	return utils.NewWrongRackError(volumeID, vol.Rack, instRack)
+}
+
+// recycledVolumes returns the volumes in the service's recycle bin. No
+// volumes are returned if the service's driver does not support recycling
+// volumes.
+func recycledVolumes(
+	ctx types.Context,
+	svc types.StorageService,
+	store types.Store) ([]*types.Volume, error) {
+
+	d, ok := svc.Driver().(types.ProvidesVolumeRecycling)
+	if !ok {
+		return nil, nil
+	}
+	vols, err := d.RecycledVolumes(ctx, store)
+	if err == types.ErrNotImplemented {
+		return nil, nil
+	}
+	return utils.SortVolumeByID(vols), err
+}
+
+// recycleVolume moves the volume to the service's recycle bin. A false
+// value is returned if the service's driver does not support recycling
+// volumes, in which case the volume should be removed.
+func recycleVolume(
+	ctx types.Context,
+	svc types.StorageService,
+	volumeID string,
+	store types.Store) (bool, error) {
+
+	d, ok := svc.Driver().(types.ProvidesVolumeRecycling)
+	if !ok {
+		return false, nil
+	}
+	if err := d.VolumeRecycle(ctx, volumeID, store); err != nil {
+		if err == types.ErrNotImplemented {
+			ctx.WithField("volumeID", volumeID).Debug(
+				"driver does not support recycling volumes")
+			return false, nil
+		}
+		return false, err
+	}
+
+	ctx.WithField("volumeID", volumeID).Info("recycled volume")
+	publishAuditedEvent(ctx, store, &types.Event{
+		Type:     types.EventVolumeRecycled,
+		Service:  svc.Name(),
+		VolumeID: volumeID,
+	})
+	return true, nil
+}
+
+// purgeRecycledVolume permanently removes the volume from the service's
+// recycle bin. A false value is returned if the volume is not in the
+// recycle bin.
+func purgeRecycledVolume(
+	ctx types.Context,
+	svc types.StorageService,
+	volumeID string,
+	store types.Store) (bool, error) {
+
+	vols, err := recycledVolumes(ctx, svc, store)
+	if err != nil {
+		return false, err
+	}
+
+	for _, v := range vols {
+		if v.ID != volumeID {
+			continue
+		}
+		d := svc.Driver().(types.ProvidesVolumeRecycling)
+		if err := d.RecycledVolumePurge(ctx, volumeID, store); err != nil {
+			return false, err
+		}
+		publishAuditedEvent(ctx, store, &types.Event{
+			Type:     types.EventVolumeRemoved,
+			Service:  svc.Name(),
+			VolumeID: volumeID,
+		})
+		return true, nil
+	}
+	return false, nil
+}
+
+// attachedElsewhere returns a flag indicating whether or not the volume is
+// attached only to instances other than the one with the provided ID. A
+// volume whose driver does not report the instances to which it is
+// attached, such as a Ceph volume, is not attached elsewhere.
+func attachedElsewhere(v *types.Volume, instanceID string) bool {
+	var elsewhere bool
+	for _, a := range v.Attachments {
+		if a.InstanceID == nil {
+			continue
+		}
+		if a.InstanceID.ID == instanceID {
+			return false
+		}
+		elsewhere = true
+	}
+	return elsewhere
+}
+
+// checkAdmin returns an error unless the request's authenticated user is a
+// configured administrator or the request's admin token matches the
+// server's admin token.
+func checkAdmin(ctx types.Context, store types.Store) error {
+	if context.Admin(ctx) {
+		return nil
+	}
+	expectedToken, ok := ctx.Value(context.AdminTokenKey).(string)
+	if !ok {
+		return utils.NewBadAdminTokenError("missing")
+	}
+	actualToken := store.GetString("admin")
+	if expectedToken != actualToken {
+		return utils.NewBadAdminTokenError(actualToken)
+	}
+	return nil
 }
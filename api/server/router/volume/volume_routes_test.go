@@ -0,0 +1,170 @@
+package volume
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+func TestCheckVolumeZone(t *testing.T) {
+	zone := []string{"zone"}
+	region := []string{"region"}
+	inst := &types.Instance{Region: "nyc3", Zone: "us-east-1a"}
+
+	tests := []struct {
+		topology []string
+		inst     *types.Instance
+		volZone  string
+		wrong    bool
+	}{
+		{zone, inst, "US-EAST-1A", false},
+		{zone, inst, "us-east-1b", true},
+		{region, inst, "nyc3", false},
+		{region, inst, "us-east-1a", true},
+		// the check is skipped if the volume's zone is unknown
+		{zone, inst, "", false},
+		{zone, &types.Instance{}, "", false},
+		// but fails if the instance's location is unknown
+		{zone, &types.Instance{}, "us-east-1b", true},
+		{zone, nil, "us-east-1b", true},
+		{region, &types.Instance{Zone: "nyc3"}, "nyc3", true},
+	}
+
+	for _, tt := range tests {
+		err := checkVolumeZone("vol-1", tt.topology, tt.inst,
+			&types.Volume{AvailabilityZone: tt.volZone})
+		if tt.wrong {
+			assert.IsType(t, &types.ErrWrongZone{}, err, "%+v", tt)
+		} else {
+			assert.NoError(t, err, "%+v", tt)
+		}
+	}
+}
+
+func TestCheckVolumeRack(t *testing.T) {
+	rack := []string{"rack"}
+	zone := []string{"zone"}
+	inst := &types.Instance{Rack: "rack1"}
+
+	tests := []struct {
+		topology []string
+		inst     *types.Instance
+		volRack  string
+		wrong    bool
+	}{
+		{rack, inst, "RACK1", false},
+		{rack, inst, "rack2", true},
+		{[]string{"zone", "rack"}, inst, "rack2", true},
+		// the check is skipped if rack is not a constraint
+		{zone, inst, "rack2", false},
+		// or if the volume's rack is unknown
+		{rack, inst, "", false},
+		// but fails if the instance's rack is unknown
+		{rack, &types.Instance{}, "rack1", true},
+		{rack, nil, "rack1", true},
+	}
+
+	for _, tt := range tests {
+		err := checkVolumeRack("vol-1", tt.topology, tt.inst,
+			&types.Volume{Rack: tt.volRack})
+		if tt.wrong {
+			assert.IsType(t, &types.ErrWrongZone{}, err, "%+v", tt)
+		} else {
+			assert.NoError(t, err, "%+v", tt)
+		}
+	}
+}
+
+func TestAttachedElsewhere(t *testing.T) {
+	att := func(id string) *types.VolumeAttachment {
+		a := &types.VolumeAttachment{VolumeID: "vol-1"}
+		if id != "" {
+			a.InstanceID = &types.InstanceID{ID: id, Driver: "vfs"}
+		}
+		return a
+	}
+
+	tests := []struct {
+		atts      []*types.VolumeAttachment
+		elsewhere bool
+	}{
+		{nil, false},
+		{[]*types.VolumeAttachment{att("i-1")}, false},
+		{[]*types.VolumeAttachment{att("i-2")}, true},
+		{[]*types.VolumeAttachment{att("i-2"), att("i-1")}, false},
+		// attachments without instances cannot be checked
+		{[]*types.VolumeAttachment{att("")}, false},
+		{[]*types.VolumeAttachment{att(""), att("i-2")}, true},
+	}
+
+	for _, tt := range tests {
+		v := &types.Volume{ID: "vol-1", Attachments: tt.atts}
+		assert.Equal(t, tt.elsewhere, attachedElsewhere(v, "i-1"), "%+v", tt)
+	}
+}
+
+func TestCheckVolumeFields(t *testing.T) {
+	custom := utils.NewStoreWithData(map[string]interface{}{
+		types.VolumeFieldFSType: "xfs",
+	})
+
+	assert.NoError(t, checkVolumeFields(
+		&types.StorageCapabilities{VolumeFields: true}, custom))
+
+	err := checkVolumeFields(&types.StorageCapabilities{}, custom)
+	assert.IsType(t, &types.ErrInvalidRequest{}, err)
+
+	// a raw volume would be formatted when mounted if its access mode were
+	// dropped by the driver
+	err = checkVolumeFields(
+		&types.StorageCapabilities{},
+		utils.NewStoreWithData(map[string]interface{}{
+			types.VolumeFieldAccessMode: types.VolumeAccessModeRaw,
+		}))
+	assert.IsType(t, &types.ErrInvalidRequest{}, err)
+
+	// other custom options are not checked
+	assert.NoError(t, checkVolumeFields(
+		&types.StorageCapabilities{},
+		utils.NewStoreWithData(map[string]interface{}{"tier": "gold"})))
+}
+
+func TestCheckVolumeQuota(t *testing.T) {
+	used := []*types.Volume{{ID: "vol-1", Size: 10}, {ID: "vol-2", Size: 20}}
+
+	assert.NoError(t, checkVolumeQuota("ebs", 40, used, 10))
+	assert.NoError(t, checkVolumeQuota("ebs", 40, nil, 40))
+
+	err := checkVolumeQuota("ebs", 40, used, 11)
+	assert.IsType(t, &types.ErrQuotaExceeded{}, err)
+}
+
+func TestNormalizeAttachAccessMode(t *testing.T) {
+	ro := &types.StorageCapabilities{ReadOnlyAttach: true}
+	rw := &types.StorageCapabilities{}
+	roMode := types.AttachAccessModeReadOnly
+	rwMode := types.AttachAccessModeReadWrite
+
+	tests := []struct {
+		caps  *types.StorageCapabilities
+		mode  types.AttachAccessMode
+		want  types.AttachAccessMode
+		mount bool
+	}{
+		{rw, "", rwMode, false},
+		{ro, rwMode, rwMode, false},
+		{ro, roMode, roMode, false},
+		// drivers that cannot attach read-only have the volume mounted
+		// read-only instead
+		{rw, roMode, rwMode, true},
+	}
+
+	for _, tt := range tests {
+		mode, mount := normalizeAttachAccessMode(tt.caps, tt.mode)
+		assert.Equal(t, tt.want, mode, "%+v", tt)
+		assert.Equal(t, tt.mount, mount, "%+v", tt)
+	}
+}
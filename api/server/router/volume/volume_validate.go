@@ -0,0 +1,347 @@
+package volume
+
+import (
+	"github.com/codedellemc/libstorage/api/server/services"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// validateVolumeCreate performs the server-side and driver-side validation
+// of a volume creation request without creating the volume.
+func validateVolumeCreate(
+	ctx types.Context,
+	svc types.StorageService,
+	name string,
+	opts *types.VolumeCreateOpts) (*types.ValidateResponse, error) {
+
+	if name == "" {
+		return nil, utils.NewInvalidRequestError(
+			"name", name, "volume name is required")
+	}
+
+	backendName, err := services.VolumeName(ctx, svc, name, opts.Opts)
+	if err != nil {
+		return nil, err
+	}
+
+	req := map[string]interface{}{"name": name}
+	if backendName != name {
+		req[types.VolumeFieldBackendName] = backendName
+	}
+
+	if opts.Size != nil {
+		size := *opts.Size
+		if size < 0 {
+			return nil, utils.NewInvalidRequestError(
+				"size", size, "volume size cannot be negative")
+		}
+		if d, ok := svc.Driver().(types.ProvidesStorageCapabilities); ok {
+			caps, err := d.Capabilities(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if caps.MaxVolumeSize > 0 && size > caps.MaxVolumeSize {
+				return nil, utils.NewInvalidRequestError(
+					"size", size, "volume size exceeds driver maximum")
+			}
+		}
+		if err := validateVolumeQuota(ctx, svc, "", size); err != nil {
+			return nil, err
+		}
+		req["size"] = size
+	}
+	if opts.IOPS != nil {
+		req["iops"] = *opts.IOPS
+	}
+	if opts.AvailabilityZone != nil {
+		req["availabilityZone"] = *opts.AvailabilityZone
+	}
+	if opts.Type != nil {
+		req["type"] = *opts.Type
+	}
+	if opts.Encrypted != nil {
+		req["encrypted"] = *opts.Encrypted
+	}
+	if opts.Thin != nil {
+		if err := validateVolumeProvisioning(ctx, svc, opts); err != nil {
+			return nil, err
+		}
+		req["thin"] = *opts.Thin
+	}
+	if err := validateVolumeFields(ctx, svc, opts); err != nil {
+		return nil, err
+	}
+
+	if d, ok := svc.Driver().(types.ProvidesValidation); ok {
+		if err := d.VolumeCreateValidate(
+			ctx, backendName, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	return &types.ValidateResponse{
+		Operation: "volumeCreate",
+		Request:   req,
+	}, nil
+}
+
+// validateVolumeQuota returns an ErrQuotaExceeded error if the service's
+// volume quota would be exceeded once the volume with the provided ID, or a
+// new volume if the ID is empty, has the provided size in GiB. Only the
+// volumes visible to the request's tenant count against the quota.
+func validateVolumeQuota(
+	ctx types.Context,
+	svc types.StorageService,
+	volumeID string,
+	size int64) error {
+
+	quota := services.VolumeQuota(svc)
+	if quota <= 0 {
+		return nil
+	}
+	vols, err := svc.Driver().Volumes(
+		ctx, &types.VolumesOpts{Opts: utils.NewStore()})
+	if err != nil {
+		return err
+	}
+	used := []*types.Volume{}
+	for _, v := range vols {
+		if v.ID != volumeID && services.IsTenantVolume(ctx, svc, v) {
+			used = append(used, v)
+		}
+	}
+	return checkVolumeQuota(svc.Name(), quota, used, size)
+}
+
+// checkVolumeQuota returns an ErrQuotaExceeded error if the size in GiB
+// added to the sizes of the used volumes exceeds the quota.
+func checkVolumeQuota(
+	name string, quota int64, used []*types.Volume, size int64) error {
+
+	total := size
+	for _, v := range used {
+		total += v.Size
+	}
+	if total > quota {
+		return utils.NewQuotaExceededError(name, quota, total)
+	}
+	return nil
+}
+
+// validateVolumeProvisioning returns an invalid request error if a thin or
+// thick volume is requested from a driver whose capabilities do not include
+// the requested provisioning. The request is not checked if the driver does
+// not report its capabilities.
+func validateVolumeProvisioning(
+	ctx types.Context,
+	svc types.StorageService,
+	opts *types.VolumeCreateOpts) error {
+
+	if opts.Thin == nil {
+		return nil
+	}
+	d, ok := svc.Driver().(types.ProvidesStorageCapabilities)
+	if !ok {
+		return nil
+	}
+	caps, err := d.Capabilities(ctx)
+	if err != nil {
+		return err
+	}
+	if !caps.SupportsProvisioning(*opts.Thin) {
+		return utils.NewInvalidRequestError(
+			"thin", *opts.Thin, "provisioning not supported by driver")
+	}
+	return nil
+}
+
+// validateVolumeFields returns an invalid request error if the request's
+// custom options include the fields an integration driver records with a
+// volume and the driver does not record them, since the volume would later
+// be formatted or mounted without them.
+func validateVolumeFields(
+	ctx types.Context,
+	svc types.StorageService,
+	opts *types.VolumeCreateOpts) error {
+
+	custom := opts.Opts.GetStore("opts")
+	if custom == nil {
+		return nil
+	}
+	caps := &types.StorageCapabilities{}
+	if d, ok := svc.Driver().(types.ProvidesStorageCapabilities); ok {
+		var err error
+		if caps, err = d.Capabilities(ctx); err != nil {
+			return err
+		}
+	}
+	return checkVolumeFields(caps, custom)
+}
+
+// checkVolumeFields returns an invalid request error if the custom options
+// include the fields an integration driver records with a volume and the
+// capabilities do not include VolumeFields.
+func checkVolumeFields(
+	caps *types.StorageCapabilities, custom types.Store) error {
+
+	if caps.VolumeFields {
+		return nil
+	}
+	for _, k := range types.IntegrationVolumeFields {
+		if custom.IsSet(k) {
+			return utils.NewInvalidRequestError(
+				k, custom.Get(k), "volume field not recorded by driver")
+		}
+	}
+	return nil
+}
+
+// validateVolumeAttach performs the server-side and driver-side validation
+// of a volume attach request without attaching the volume.
+func validateVolumeAttach(
+	ctx types.Context,
+	svc types.StorageService,
+	volumeID string,
+	topology bool,
+	opts *types.VolumeAttachOpts) (*types.ValidateResponse, error) {
+
+	if _, err := svc.Driver().VolumeInspect(
+		ctx, volumeID, &types.VolumeInspectOpts{Opts: opts.Opts}); err != nil {
+		return nil, err
+	}
+
+	accessMode, readOnlyMount, err := attachAccessMode(
+		ctx, svc, opts.AccessMode)
+	if err != nil {
+		return nil, err
+	}
+
+	if topology {
+		if err := validateVolumeTopology(
+			ctx, svc, volumeID, opts.Opts); err != nil {
+			return nil, err
+		}
+	}
+
+	if d, ok := svc.Driver().(types.ProvidesValidation); ok {
+		if err := d.VolumeAttachValidate(ctx, volumeID, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	req := map[string]interface{}{
+		"volumeID": volumeID,
+		"force":    opts.Force,
+	}
+	req["accessMode"] = accessMode
+	if readOnlyMount {
+		req["readOnlyMount"] = true
+	}
+	if opts.NextDevice != nil {
+		req["nextDeviceName"] = *opts.NextDevice
+	}
+
+	return &types.ValidateResponse{
+		Operation: "volumeAttach",
+		Request:   req,
+	}, nil
+}
+
+// attachAccessMode returns the normalized access mode of an attach request
+// and whether or not the volume is mounted read-only by the integration
+// driver because the service's driver cannot attach it read-only.
+func attachAccessMode(
+	ctx types.Context,
+	svc types.StorageService,
+	mode types.AttachAccessMode) (types.AttachAccessMode, bool, error) {
+
+	caps := &types.StorageCapabilities{}
+	if mode.ReadOnly() {
+		if d, ok := svc.Driver().(types.ProvidesStorageCapabilities); ok {
+			var err error
+			if caps, err = d.Capabilities(ctx); err != nil {
+				return "", false, err
+			}
+		}
+	}
+	m, ro := normalizeAttachAccessMode(caps, mode)
+	return m, ro, nil
+}
+
+// normalizeAttachAccessMode returns the access mode with which a volume is
+// attached given the requested mode and the driver's capabilities, and
+// whether or not the volume is mounted read-only instead.
+func normalizeAttachAccessMode(
+	caps *types.StorageCapabilities,
+	mode types.AttachAccessMode) (types.AttachAccessMode, bool) {
+
+	if !mode.ReadOnly() {
+		return types.AttachAccessModeReadWrite, false
+	}
+	if caps.ReadOnlyAttach {
+		return types.AttachAccessModeReadOnly, false
+	}
+	return types.AttachAccessModeReadWrite, true
+}
+
+// validateVolumeRemove performs the server-side and driver-side validation
+// of a volume removal request without removing the volume.
+func validateVolumeRemove(
+	ctx types.Context,
+	svc types.StorageService,
+	volumeID string,
+	opts *types.VolumeRemoveOpts) (*types.ValidateResponse, error) {
+
+	if err := validateVolumeNotInUse(ctx, svc, volumeID, opts); err != nil {
+		return nil, err
+	}
+
+	if d, ok := svc.Driver().(types.ProvidesValidation); ok {
+		if err := d.VolumeRemoveValidate(ctx, volumeID, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	return &types.ValidateResponse{
+		Operation: "volumeRemove",
+		Request: map[string]interface{}{
+			"volumeID": volumeID,
+			"force":    opts.Force,
+		},
+	}, nil
+}
+
+// validateVolumeNotInUse returns an ErrVolumeInUse error if the volume is
+// attached to any instance and the removal is not forced. The check is made
+// by the server so that removing an attached volume fails the same way no
+// matter the storage platform.
+func validateVolumeNotInUse(
+	ctx types.Context,
+	svc types.StorageService,
+	volumeID string,
+	opts *types.VolumeRemoveOpts) error {
+
+	vol, err := svc.Driver().VolumeInspect(
+		ctx, volumeID, &types.VolumeInspectOpts{
+			Attachments: types.VolAttReq,
+			Opts:        opts.Opts,
+		})
+	if err != nil {
+		return err
+	}
+
+	if opts.Force || len(vol.Attachments) == 0 {
+		return nil
+	}
+
+	instanceIDs := []string{}
+	seen := map[string]bool{}
+	for _, a := range vol.Attachments {
+		if a.InstanceID == nil || seen[a.InstanceID.ID] {
+			continue
+		}
+		seen[a.InstanceID.ID] = true
+		instanceIDs = append(instanceIDs, a.InstanceID.ID)
+	}
+	return utils.NewVolumeInUseError(volumeID, instanceIDs...)
+}
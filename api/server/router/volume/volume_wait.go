@@ -0,0 +1,195 @@
+package volume
+
+import (
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/server/services"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+	"github.com/codedellemc/libstorage/api/utils/schema"
+)
+
+const (
+	// defaultAttachWaitTimeout is how long an attach request that waits for
+	// the attachment to complete waits if the request has no timeout.
+	defaultAttachWaitTimeout = 60 * time.Second
+
+	// maxAttachWaitTimeout is the longest an attach request may wait for the
+	// attachment to complete.
+	maxAttachWaitTimeout = 5 * time.Minute
+)
+
+// attachWaitInterval is how often the volume is inspected while waiting for
+// its attachment to complete.
+var attachWaitInterval = time.Second
+
+// attachWaitTimeout returns how long an attach request waits for the
+// attachment to complete, which is the request's timeout query parameter
+// if it has one. The timeout is capped at maxAttachWaitTimeout.
+func attachWaitTimeout(store types.Store) (time.Duration, error) {
+	v := store.GetString("timeout")
+	if v == "" {
+		return defaultAttachWaitTimeout, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, utils.NewInvalidRequestError(
+			"timeout", v, "invalid timeout")
+	}
+	if d > maxAttachWaitTimeout {
+		d = maxAttachWaitTimeout
+	}
+	return d, nil
+}
+
+// waitForAttachment waits for the attach task to complete and then inspects
+// the volume until the storage platform reports that the volume's attachment
+// to the instance in the context is complete and includes the attachment's
+// device. The attach task's response is returned with the inspected volume.
+//
+// The wait is not performed by one of the service's task workers. Each
+// inspection is a task of its own, so the service's workers are free to
+// execute other requests between the inspections.
+func waitForAttachment(
+	ctx types.Context,
+	svc types.StorageService,
+	attachTask *types.Task,
+	timeout time.Duration,
+	store types.Store) (*types.VolumeAttachResponse, error) {
+
+	if err := waitForTask(ctx, attachTask); err != nil {
+		return nil, err
+	}
+	res, ok := attachTask.Result.(*types.VolumeAttachResponse)
+	if !ok || res.Volume == nil {
+		return nil, goof.New("error casting to *types.VolumeAttachResponse")
+	}
+
+	iid, _ := context.InstanceID(ctx)
+	attachments := types.VolAttReqWithDevMapForInstance
+	opts := &types.VolumeInspectOpts{
+		Attachments: attachments,
+		Opts:        store,
+	}
+	volumeID := res.Volume.ID
+
+	inspect := func() (*types.Volume, error) {
+		run := func(
+			ctx types.Context,
+			svc types.StorageService) (interface{}, error) {
+
+			v, err := svc.Driver().VolumeInspect(ctx, volumeID, opts)
+			if err != nil {
+				return nil, err
+			}
+			handleVolAttachments(ctx, nil, iid, v, attachments)
+			return v, nil
+		}
+		t := svc.TaskExecute(ctx, run, schema.VolumeSchema)
+		if err := waitForTask(ctx, t); err != nil {
+			return nil, err
+		}
+		v, ok := t.Result.(*types.Volume)
+		if !ok {
+			return nil, goof.New("error casting to *types.Volume")
+		}
+		return v, nil
+	}
+
+	v, err := pollAttachment(ctx, iid, volumeID, timeout, inspect)
+	if err != nil {
+		return nil, err
+	}
+
+	// the mount token issued by the attach task is kept
+	if token, ok := res.Volume.Fields[types.VolumeFieldMountToken]; ok {
+		if v.Fields == nil {
+			v.Fields = map[string]string{}
+		}
+		v.Fields[types.VolumeFieldMountToken] = token
+	}
+	res.Volume = v
+	return res, nil
+}
+
+// waitForTask waits for the task to complete and returns its error. An
+// error is also returned if the request's deadline elapses first.
+func waitForTask(ctx types.Context, t *types.Task) error {
+	select {
+	case <-services.TaskWaitC(ctx, t.ID):
+		return t.Error
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// pollAttachment inspects the volume at the attachWaitInterval until its
+// attachment to the instance is complete and includes the attachment's
+// device, and then returns the volume. An error is returned if the
+// attachment is not complete before the timeout or the request's deadline.
+func pollAttachment(
+	ctx types.Context,
+	iid *types.InstanceID,
+	volumeID string,
+	timeout time.Duration,
+	inspect func() (*types.Volume, error)) (*types.Volume, error) {
+
+	lf := log.Fields{
+		"volumeID": volumeID,
+		"timeout":  timeout,
+	}
+
+	expired := time.NewTimer(timeout)
+	defer expired.Stop()
+	ticker := time.NewTicker(attachWaitInterval)
+	defer ticker.Stop()
+
+	for {
+		v, err := inspect()
+		if err != nil {
+			return nil, err
+		}
+		if a := completeAttachment(iid, v); a != nil {
+			lf["deviceName"] = a.DeviceName
+			ctx.WithFields(lf).Debug("attachment complete")
+			return v, nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-expired.C:
+			ctx.WithFields(lf).Warn("timed out waiting for attachment")
+			return nil, types.ErrTimedOut
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// completeAttachment returns the volume's attachment to the instance if the
+// attachment is complete and includes its device; otherwise a nil value is
+// returned.
+func completeAttachment(
+	iid *types.InstanceID, v *types.Volume) *types.VolumeAttachment {
+
+	if iid == nil || v.AttachmentState != types.VolumeAttached {
+		return nil
+	}
+	for _, a := range v.Attachments {
+		if a.InstanceID == nil || !strings.EqualFold(iid.ID, a.InstanceID.ID) {
+			continue
+		}
+		// attachments that are in progress are reported by some storage
+		// platforms, ex. EBS, with the attaching status
+		if a.DeviceName == "" || strings.EqualFold(a.Status, "attaching") {
+			return nil
+		}
+		return a
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package volume
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+func TestAttachWaitTimeout(t *testing.T) {
+	store := utils.NewStore()
+	d, err := attachWaitTimeout(store)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultAttachWaitTimeout, d)
+
+	store.Set("timeout", "2m")
+	d, err = attachWaitTimeout(store)
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, d)
+
+	// the timeout is capped
+	store.Set("timeout", "24h")
+	d, err = attachWaitTimeout(store)
+	assert.NoError(t, err)
+	assert.Equal(t, maxAttachWaitTimeout, d)
+
+	store.Set("timeout", "-1s")
+	_, err = attachWaitTimeout(store)
+	assert.Error(t, err)
+}
+
+func TestCompleteAttachment(t *testing.T) {
+	iid := &types.InstanceID{ID: "i-1234", Driver: "ebs"}
+	v := &types.Volume{
+		AttachmentState: types.VolumeAttached,
+		Attachments: []*types.VolumeAttachment{
+			{
+				InstanceID: &types.InstanceID{ID: "i-5678", Driver: "ebs"},
+				DeviceName: "/dev/xvdf",
+			},
+			{
+				InstanceID: &types.InstanceID{ID: "I-1234", Driver: "ebs"},
+				DeviceName: "/dev/xvdg",
+				Status:     "attaching",
+			},
+		},
+	}
+
+	// the attachment to the instance is in progress
+	assert.Nil(t, completeAttachment(iid, v))
+	assert.Nil(t, completeAttachment(nil, v))
+
+	v.Attachments[1].Status = "attached"
+	if a := completeAttachment(iid, v); assert.NotNil(t, a) {
+		assert.Equal(t, "/dev/xvdg", a.DeviceName)
+	}
+
+	v.AttachmentState = types.VolumeUnavailable
+	assert.Nil(t, completeAttachment(iid, v))
+}
+
+func TestPollAttachment(t *testing.T) {
+	defer func(v time.Duration) { attachWaitInterval = v }(attachWaitInterval)
+	attachWaitInterval = time.Millisecond
+
+	ctx := context.Background()
+	iid := &types.InstanceID{ID: "i-1234", Driver: "ebs"}
+
+	// the device is reported by the third inspection
+	polls := 0
+	inspect := func() (*types.Volume, error) {
+		polls++
+		v := &types.Volume{
+			ID:              "vol-1",
+			AttachmentState: types.VolumeAttached,
+			Attachments: []*types.VolumeAttachment{
+				{InstanceID: iid, Status: "attaching"},
+			},
+		}
+		if polls == 3 {
+			v.Attachments[0].DeviceName = "/dev/xvdf"
+			v.Attachments[0].Status = "attached"
+		}
+		return v, nil
+	}
+	v, err := pollAttachment(ctx, iid, "vol-1", time.Minute, inspect)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "/dev/xvdf", v.Attachments[0].DeviceName)
+	}
+	assert.Equal(t, 3, polls)
+
+	// the attachment does not complete before the timeout
+	polls = 0
+	inspect = func() (*types.Volume, error) {
+		polls++
+		return &types.Volume{ID: "vol-1"}, nil
+	}
+	_, err = pollAttachment(ctx, iid, "vol-1", 20*time.Millisecond, inspect)
+	assert.Equal(t, types.ErrTimedOut, err)
+	assert.True(t, polls > 1)
+
+	// an inspection error ends the wait
+	errInspect := errors.New("error inspecting volume")
+	_, err = pollAttachment(ctx, iid, "vol-1", time.Minute,
+		func() (*types.Volume, error) { return nil, errInspect })
+	assert.Equal(t, errInspect, err)
+}
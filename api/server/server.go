@@ -7,6 +7,7 @@ import (
 	"os/signal"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -23,12 +24,17 @@ import (
 	"github.com/codedellemc/libstorage/api/types"
 	"github.com/codedellemc/libstorage/api/utils"
 	apicnfg "github.com/codedellemc/libstorage/api/utils/config"
+	"github.com/codedellemc/libstorage/api/utils/metrics"
+	"github.com/codedellemc/libstorage/api/utils/tracing"
 
 	// imported to load routers
 	_ "github.com/codedellemc/libstorage/imports/routers"
 
 	// imported to load remote storage drivers
 	_ "github.com/codedellemc/libstorage/imports/remote"
+
+	// imported to load secrets providers
+	_ "github.com/codedellemc/libstorage/imports/secrets"
 )
 
 var (
@@ -45,6 +51,7 @@ type server struct {
 	closeSignal  chan int
 	closedSignal chan int
 	closeOnce    *sync.Once
+	reloadLock   sync.Mutex
 
 	routers        []types.Router
 	routeHandlers  map[string][]types.Middleware
@@ -54,6 +61,11 @@ type server struct {
 	logHTTPRequests  bool
 	logHTTPResponses bool
 
+	requestTimeout  time.Duration
+	shutdownTimeout time.Duration
+	draining        int32
+	tracingCloser   io.Closer
+
 	stdOut io.WriteCloser
 	stdErr io.WriteCloser
 }
@@ -115,6 +127,13 @@ func newServer(goCtx gocontext.Context, config gofig.Config) (*server, error) {
 	}
 	config = config.Scope(types.ConfigServer)
 
+	// a configured admin token replaces the generated one so that it may
+	// be provided to the clients that make admin requests
+	if t := config.GetString(types.ConfigServerAdminToken); t != "" {
+		adminToken = t
+		ctx = ctx.WithValue(context.AdminTokenKey, adminToken)
+	}
+
 	s := &server{
 		ctx:          ctx,
 		name:         serverName,
@@ -145,10 +164,35 @@ func newServer(goCtx gocontext.Context, config gofig.Config) (*server, error) {
 
 	// always update the server context's log level
 	context.SetLogLevel(s.ctx, logConfig.Level)
+	context.SetLogFormatter(s.ctx, logConfig.Formatter())
+	s.ctx = context.WithComponent(
+		s.ctx.WithValue(context.LogLevelsKey, logConfig.Levels), "server")
 	s.ctx.WithFields(logFields).Info("configured logging")
 
+	if s.tracingCloser, err = tracing.Init(
+		s.ctx, config, "libstorage-server"); err != nil {
+		return nil, err
+	}
+	metrics.Init(config)
+
+	if err := utils.InitExec(config); err != nil {
+		return nil, err
+	}
+
 	s.ctx.Info("initializing server")
 
+	// an invalid config only fails the server's start when validation is
+	// enabled so that configs with keys the server does not recognize keep
+	// working after an upgrade
+	if err := apicnfg.Validate(config); err != nil {
+		if config.GetBool(types.ConfigServerValidateConfig) {
+			return nil, err
+		}
+		s.ctx.WithError(err).Warn("invalid config")
+	} else {
+		s.ctx.Info("validated config")
+	}
+
 	if err := s.initEndpoints(s.ctx); err != nil {
 		return nil, err
 	}
@@ -167,7 +211,21 @@ func newServer(goCtx gocontext.Context, config gofig.Config) (*server, error) {
 		s.stdErr = getLogIO(logConfig.Stderr, types.ConfigLogStderr)
 	}
 
-	s.initGlobalMiddleware()
+	if dur, err := time.ParseDuration(
+		config.GetString(types.ConfigServerRequestTimeout)); err == nil {
+		s.requestTimeout = dur
+	}
+	s.ctx.WithField(
+		"requestTimeout", s.requestTimeout).Info("configured request timeout")
+
+	if dur, err := time.ParseDuration(
+		config.GetString(types.ConfigServerShutdownTimeout)); err == nil {
+		s.shutdownTimeout = dur
+	}
+
+	if err := s.initGlobalMiddleware(); err != nil {
+		return nil, err
+	}
 
 	if err := s.initRouters(); err != nil {
 		return nil, err
@@ -195,7 +253,7 @@ func Serve(
 	srvErrs := make(chan error, len(s.servers))
 
 	for _, srv := range s.servers {
-		srv.srv.Handler = s.createMux(srv.ctx)
+		srv.srv.Handler = srv.verifyPeer(s.createMux(srv.ctx))
 		go func(srv *HTTPServer) {
 			srv.ctx.Info("api listening")
 			if err := srv.Serve(); err != nil {
@@ -262,6 +320,10 @@ func (s *server) Close() (err error) {
 func (s *server) close() error {
 	s.ctx.Info("shutting down server")
 
+	// requests that arrive on open connections are rejected while the
+	// in-flight tasks are drained
+	atomic.StoreInt32(&s.draining, 1)
+
 	for _, srv := range s.servers {
 		srv.ctx.Info("shutting down endpoint")
 		if err := srv.Close(); err != nil {
@@ -276,6 +338,14 @@ func (s *server) close() error {
 		srv.ctx.Debug("shutdown endpoint complete")
 	}
 
+	if err := services.Drain(s.ctx, s.shutdownTimeout); err != nil {
+		s.ctx.WithError(err).Error("error draining in-flight tasks")
+	}
+
+	if err := s.tracingCloser.Close(); err != nil {
+		log.Error(err)
+	}
+
 	if s.stdOut != nil {
 		if err := s.stdOut.Close(); err != nil {
 			log.Error(err)
@@ -294,13 +364,13 @@ func (s *server) close() error {
 }
 
 // CloseOnAbort is a helper function that can be called by programs, such as
-// tests or a command line or service application.
+// tests or a command line or service application. A SIGHUP signal does not
+// close the servers; see ReloadOnHangup.
 func CloseOnAbort() {
 	// make sure all servers get closed even if the test is abrubptly aborted
 	sigc := make(chan os.Signal, 1)
 	signal.Notify(sigc,
 		syscall.SIGKILL,
-		syscall.SIGHUP,
 		syscall.SIGINT,
 		syscall.SIGTERM,
 		syscall.SIGQUIT)
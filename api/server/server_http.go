@@ -9,7 +9,9 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"strconv"
 	"sync"
+	"sync/atomic"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/akutz/goof"
@@ -18,8 +20,10 @@ import (
 
 	"github.com/codedellemc/libstorage/api/context"
 	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/server/httputils"
 	"github.com/codedellemc/libstorage/api/types"
 	"github.com/codedellemc/libstorage/api/utils"
+	"github.com/codedellemc/libstorage/api/utils/spiffe"
 )
 
 const defaultEndpointConfig = `
@@ -135,17 +139,77 @@ func (s *server) initEndpoints(ctx types.Context) error {
 			return err
 		}
 
+		// an instance ID can only be bound to a verified client certificate
+		if s.config.GetBool(types.ConfigServerBindInstanceIDs) &&
+			!requiresClientCert(tlsConfig) {
+			return goof.WithField(
+				"endpoint", endpoint,
+				"binding instance ids requires client certificates")
+		}
+
 		ctx.WithFields(logFields).Info("configured endpoint")
 
 		srv, err := s.newHTTPServer(proto, addr, tlsConfig)
 		if err != nil {
 			return err
 		}
+		srv.endpoint = endpoint
 
 		ctx.Info("server created")
 		s.servers = append(s.servers, srv)
 	}
 
+	return s.initLocalSocket(ctx)
+}
+
+// initLocalSocket creates an additional, non-TLS endpoint on the configured
+// local UNIX socket. Co-located clients use this socket in place of a
+// loopback TCP address. Since the endpoint does not use TLS, access to it is
+// limited by the socket's file mode.
+func (s *server) initLocalSocket(ctx types.Context) error {
+
+	sock := s.config.GetString(types.ConfigHTTPLocalSocket)
+	if sock == "" {
+		return nil
+	}
+
+	if s.config.GetBool(types.ConfigServerBindInstanceIDs) {
+		return goof.WithField(
+			"localSocket", sock,
+			"binding instance ids requires client certificates")
+	}
+
+	szMode := s.config.GetString(types.ConfigHTTPLocalSocketMode)
+	mode, err := strconv.ParseUint(szMode, 8, 32)
+	if err != nil || os.FileMode(mode)&^os.ModePerm != 0 {
+		return goof.WithField(
+			"localSocketMode", szMode, "invalid local socket mode")
+	}
+
+	for _, addr := range s.addrs {
+		if addr == fmt.Sprintf("unix://%s", sock) {
+			return nil
+		}
+	}
+
+	// remove a socket file left behind by a previous server, but not one on
+	// which a running server is still listening
+	if err := utils.RemoveStaleSocket(sock); err != nil {
+		return err
+	}
+
+	l, err := utils.ListenUnix(sock, os.FileMode(mode))
+	if err != nil {
+		return err
+	}
+	srv := s.newListenerServer(
+		"unix", sock, l, nil, &certificates{}, make(chan struct{}))
+
+	ctx.WithFields(log.Fields{
+		"localSocket":     sock,
+		"localSocketMode": szMode,
+	}).Info("local socket server created")
+	s.servers = append(s.servers, srv)
 	return nil
 }
 
@@ -169,19 +233,55 @@ func (s *server) addRouter(r types.Router) {
 	s.routers = append(s.routers, r)
 }
 
+// makeHTTPHandler returns the handler for a route. A version of zero
+// indicates the route is unversioned and the version of the API is
+// negotiated with the request's Accept header, defaulting to version 1.
 func (s *server) makeHTTPHandler(
 	ctx types.Context,
-	route types.Route) http.HandlerFunc {
+	route types.Route,
+	version types.APIVersion) http.HandlerFunc {
 
 	return func(w http.ResponseWriter, req *http.Request) {
 
 		w.Header().Set(types.ServerNameHeader, s.name)
 
+		ver := version
+		if ver == 0 {
+			ver = types.ParseAcceptAPIVersion(req.Header.Get("Accept"))
+			if ver == 0 {
+				ver = types.APIVersion1
+			}
+		}
+		if !ver.Supported() {
+			httputils.WriteJSON(w, http.StatusNotAcceptable,
+				goof.NewHTTPError(
+					goof.WithField(
+						"apiVersion", ver.String(),
+						"unsupported api version"),
+					http.StatusNotAcceptable))
+			return
+		}
+		w.Header().Set(types.APIVersionHeader, ver.String())
+
+		if atomic.LoadInt32(&s.draining) == 1 {
+			w.Header().Set("Connection", "close")
+			httputils.WriteJSON(w, http.StatusServiceUnavailable,
+				goof.NewHTTPError(
+					goof.New("server shutting down"),
+					http.StatusServiceUnavailable))
+			return
+		}
+
 		ctx := context.WithRequestRoute(ctx, req, route)
+		ctx = ctx.WithValue(context.APIVersionKey, ver)
 
 		if req.TLS != nil {
 			if len(req.TLS.PeerCertificates) > 0 {
 				userName := req.TLS.PeerCertificates[0].Subject.CommonName
+				if userName == "" {
+					// SPIFFE SVIDs identify a workload by its URI SAN
+					userName, _ = utils.SPIFFEID(req.TLS.PeerCertificates[0])
+				}
 				ctx = ctx.WithValue(context.UserKey, userName)
 			}
 		}
@@ -193,6 +293,7 @@ func (s *server) makeHTTPHandler(
 			vars = map[string]string{}
 		}
 		store := utils.NewStoreWithVars(vars)
+		ctx = ctx.WithValue(context.RouteVarsKey, vars)
 
 		handlerFunc := s.handleWithMiddleware(ctx, route)
 		if err := handlerFunc(ctx, w, req, store); err != nil {
@@ -209,12 +310,15 @@ func (s *server) createMux(ctx types.Context) *mux.Router {
 
 			ctx := ctx.WithValue(context.RouteKey, r)
 
-			f := s.makeHTTPHandler(ctx, r)
-			mr := m.Path(r.GetPath())
-			mr = mr.Name(r.GetName())
-			mr = mr.Methods(r.GetMethod())
-			mr = mr.Queries(r.GetQueries()...)
-			mr.Handler(f)
+			// each route is served at its unversioned path, where the
+			// version of the API is negotiated, as well as at a path
+			// prefixed with each of the supported versions
+			s.addMuxRoute(m, r, r.GetPath(), s.makeHTTPHandler(ctx, r, 0))
+			for _, v := range types.APIVersions {
+				s.addMuxRoute(
+					m, r, "/"+v.String()+r.GetPath(),
+					s.makeHTTPHandler(ctx, r, v))
+			}
 
 			if l, ok := context.GetLogLevel(ctx); ok && l >= log.DebugLevel {
 				ctx.WithFields(log.Fields{
@@ -231,6 +335,16 @@ func (s *server) createMux(ctx types.Context) *mux.Router {
 	return m
 }
 
+func (s *server) addMuxRoute(
+	m *mux.Router, r types.Route, path string, f http.HandlerFunc) {
+
+	mr := m.Path(path)
+	mr = mr.Name(r.GetName())
+	mr = mr.Methods(r.GetMethod())
+	mr = mr.Queries(r.GetQueries()...)
+	mr.Handler(f)
+}
+
 func (s *server) newHTTPServer(
 	proto, laddr string, tlsConfig *types.TLSConfig) (*HTTPServer, error) {
 
@@ -239,16 +353,79 @@ func (s *server) newHTTPServer(
 		err error
 	)
 
+	certs := &certificates{}
+	closed := make(chan struct{})
+
+	// fetch the server's SVID from the SPIFFE Workload API
+	var svids *spiffe.Source
+	if tlsConfig != nil && tlsConfig.SPIFFEWorkloadAPI != "" {
+		svids, err = spiffe.NewSource(
+			s.ctx, tlsConfig.SPIFFEWorkloadAPI, closed,
+			func(cert *tls.Certificate) {
+				certs.set([]tls.Certificate{*cert})
+			})
+		if err != nil {
+			close(closed)
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{*svids.Certificate()}
+	}
+
 	if tlsConfig != nil {
+		// serve the certificates from a holder that may be updated when the
+		// server's configuration is reloaded or the cert files change
+		certs.set(tlsConfig.Certificates)
+		tlsConfig.Certificates = nil
+		tlsConfig.GetCertificate = certs.get
 		l, err = tls.Listen(proto, laddr, &tlsConfig.Config)
 	} else {
 		l, err = net.Listen(proto, laddr)
 	}
 
 	if err != nil {
+		close(closed)
 		return nil, err
 	}
 
+	if tlsConfig != nil {
+		if err := watchCertificates(
+			s.ctx, tlsConfig, certs, closed); err != nil {
+			l.Close()
+			return nil, err
+		}
+	}
+
+	srv := s.newListenerServer(proto, laddr, l, tlsConfig, certs, closed)
+	srv.svids = svids
+	return srv, nil
+}
+
+// requiresClientCert returns a flag indicating whether or not the clients of
+// an endpoint with the provided TLS config must present verified
+// certificates.
+func requiresClientCert(tlsConfig *types.TLSConfig) bool {
+	if tlsConfig == nil {
+		return false
+	}
+	switch tlsConfig.ClientAuth {
+	case tls.RequireAndVerifyClientCert:
+		return true
+	case tls.RequireAnyClientCert:
+		// the peers' SVIDs are verified after the handshake
+		return tlsConfig.SPIFFEWorkloadAPI != ""
+	}
+	return false
+}
+
+// newListenerServer returns a server that serves requests accepted by the
+// provided listener.
+func (s *server) newListenerServer(
+	proto, laddr string,
+	l net.Listener,
+	tlsConfig *types.TLSConfig,
+	certs *certificates,
+	closed chan struct{}) *HTTPServer {
+
 	host := fmt.Sprintf("%s://%s", proto, laddr)
 	ctx := s.ctx.WithValue(context.HostKey, host)
 	ctx = ctx.WithValue(context.TLSKey, tlsConfig != nil)
@@ -260,10 +437,70 @@ func (s *server) newHTTPServer(
 	srv.ErrorLog = golog.New(errLogger, "", 0)
 
 	return &HTTPServer{
-		srv: srv,
-		l:   l,
-		ctx: ctx,
-	}, nil
+		srv:       srv,
+		l:         l,
+		ctx:       ctx,
+		tlsConfig: tlsConfig,
+		certs:     certs,
+		closed:    closed,
+		closeOnce: &sync.Once{},
+	}
+}
+
+// watchCertificates updates the provided certificates each time the
+// configured cert and key files change.
+func watchCertificates(
+	ctx types.Context,
+	tlsConfig *types.TLSConfig,
+	certs *certificates,
+	stop <-chan struct{}) error {
+
+	if tlsConfig.ReloadInterval <= 0 || tlsConfig.CertFile == "" {
+		return nil
+	}
+
+	kp, err := utils.NewTLSKeyPair(tlsConfig.CertFile, tlsConfig.KeyFile)
+	if err != nil {
+		return err
+	}
+
+	go kp.Watch(ctx, tlsConfig.ReloadInterval, stop,
+		func(cert *tls.Certificate) {
+			certs.set([]tls.Certificate{*cert})
+		})
+	return nil
+}
+
+// verifyPeer wraps the provided handler in order to reject requests from
+// peers that do not present a SPIFFE ID allowed by the server's TLS config.
+func (s *HTTPServer) verifyPeer(h http.Handler) http.Handler {
+
+	if s.tlsConfig == nil ||
+		(s.svids == nil && !s.tlsConfig.RequiresSPIFFEID()) {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.TLS == nil {
+			http.Error(w, "tls required", http.StatusUnauthorized)
+			return
+		}
+		if s.svids != nil && len(req.TLS.PeerCertificates) > 0 {
+			err := s.svids.VerifyPeer(req.TLS.PeerCertificates)
+			if err != nil {
+				s.ctx.WithError(err).Warn("rejected peer")
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+		}
+		if _, err := utils.VerifySPIFFEID(
+			s.tlsConfig, req.TLS.PeerCertificates); err != nil {
+			s.ctx.WithError(err).Warn("rejected peer")
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		h.ServeHTTP(w, req)
+	})
 }
 
 // HTTPServer contains an instance of http server and the listener.
@@ -274,9 +511,15 @@ func (s *server) newHTTPServer(
 // l   net.Listener, is a TCP or Socket listener that dispatches incoming
 // request to the router.
 type HTTPServer struct {
-	srv *http.Server
-	l   net.Listener
-	ctx types.Context
+	srv       *http.Server
+	l         net.Listener
+	ctx       types.Context
+	endpoint  string
+	tlsConfig *types.TLSConfig
+	certs     *certificates
+	svids     *spiffe.Source
+	closed    chan struct{}
+	closeOnce *sync.Once
 }
 
 // Serve starts listening for inbound requests.
@@ -286,6 +529,7 @@ func (s *HTTPServer) Serve() error {
 
 // Close closes the HTTPServer from listening for the inbound requests.
 func (s *HTTPServer) Close() error {
+	s.closeOnce.Do(func() { close(s.closed) })
 	return s.l.Close()
 }
 
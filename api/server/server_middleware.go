@@ -1,12 +1,14 @@
 package server
 
 import (
+	"net/http"
+	"strings"
+
 	"github.com/codedellemc/libstorage/api/server/handlers"
-	"github.com/codedellemc/libstorage/api/server/services"
 	"github.com/codedellemc/libstorage/api/types"
 )
 
-func (s *server) initGlobalMiddleware() {
+func (s *server) initGlobalMiddleware() error {
 
 	s.addGlobalMiddleware(handlers.NewQueryParamsHandler())
 
@@ -19,10 +21,35 @@ func (s *server) initGlobalMiddleware() {
 
 	s.addGlobalMiddleware(handlers.NewTransactionHandler())
 	s.addGlobalMiddleware(handlers.NewErrorHandler())
-	s.addGlobalMiddleware(
-		handlers.NewInstanceIDHandler(services.StorageServices(s.ctx)))
+	s.addGlobalMiddleware(handlers.NewBodyHandler(s.config))
+	s.addGlobalMiddleware(handlers.NewDeadlineHandler(s.requestTimeout))
+	s.addGlobalMiddleware(handlers.NewTracingHandler())
+	s.addGlobalMiddleware(handlers.NewInstanceIDHandler())
+
+	if s.config.GetBool(types.ConfigServerBindInstanceIDs) {
+		path := s.config.GetString(types.ConfigServerInstanceIDBindingsFile)
+		h, err := handlers.NewInstanceIDBindingHandler(path)
+		if err != nil {
+			return err
+		}
+		s.addGlobalMiddleware(h)
+		s.ctx.WithField("path", path).Info("binding instance ids")
+	}
+
+	tenancy, err := handlers.NewTenancyHandler(s.config)
+	if err != nil {
+		return err
+	}
+	if tenancy != nil {
+		s.addGlobalMiddleware(tenancy)
+		s.ctx.WithField(
+			"pattern", s.config.GetString(types.ConfigServerTenancyPattern)).Info(
+			"scoping volumes to tenants")
+	}
+
 	s.addGlobalMiddleware(handlers.NewLocalDevicesHandler())
 	s.addGlobalMiddleware(handlers.NewOnRequestHandler())
+	return nil
 }
 
 func (s *server) initRouteMiddleware() {
@@ -30,13 +57,45 @@ func (s *server) initRouteMiddleware() {
 	// also possible to add route-specific middleware that is not defined as
 	// part of a route's Middlewares collection.
 	s.routeHandlers = map[string][]types.Middleware{}
+
+	// the admission policy is consulted for mutating requests after the
+	// route-specific middleware has parsed the requests' bodies
+	policy := handlers.NewPolicyHandler(s.config)
+	if policy != nil {
+		s.ctx.WithField(
+			"policy", s.config.GetString(types.ConfigServerPolicyURL)).Info(
+			"enforcing admission policy")
+	}
+
+	// the ownership of the volume or snapshot specified as part of a route's
+	// path is validated after the route-specific middleware has resolved the
+	// service
+	tenancy := s.config.GetBool(types.ConfigServerTenancyEnabled)
+
 	for _, router := range s.routers {
 		for _, r := range router.Routes() {
 			s.addRouterMiddleware(r, r.GetMiddlewares()...)
+			if tenancy && strings.Contains(r.GetPath(), "{volumeID}") {
+				s.addRouterMiddleware(r, handlers.NewVolumeOwnerValidator())
+			}
+			if tenancy && strings.Contains(r.GetPath(), "{snapshotID}") {
+				s.addRouterMiddleware(r, handlers.NewSnapshotOwnerValidator())
+			}
+			if policy != nil && isMutatingMethod(r.GetMethod()) {
+				s.addRouterMiddleware(r, policy)
+			}
 		}
 	}
 }
 
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
 func (s *server) addRouterMiddleware(
 	r types.Route, middlewares ...types.Middleware) {
 
@@ -0,0 +1,237 @@
+package server
+
+import (
+	"crypto/tls"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/server/services"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+	apicnfg "github.com/codedellemc/libstorage/api/utils/config"
+)
+
+// ConfigLoader is a function that loads the configuration used to reload
+// the servers.
+type ConfigLoader func() (gofig.Config, error)
+
+// certificates holds an endpoint's TLS certificates so they may be replaced
+// without closing the endpoint's listener.
+type certificates struct {
+	sync.RWMutex
+	certs []tls.Certificate
+}
+
+func (c *certificates) set(certs []tls.Certificate) {
+	c.Lock()
+	defer c.Unlock()
+	c.certs = certs
+}
+
+func (c *certificates) get(
+	hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.RLock()
+	defer c.RUnlock()
+	if len(c.certs) == 0 {
+		return nil, goof.New("no certificates configured")
+	}
+	return &c.certs[0], nil
+}
+
+// Reload updates the server from the provided configuration. If the config
+// is nil then the default configuration files are read again.
+//
+// Storage services that were added or changed are started and services that
+// were removed are stopped. Services whose configuration did not change, as
+// well as their in-flight requests, are not affected. The certificates of
+// the server's TLS endpoints are rotated. The server's endpoint addresses
+// cannot be changed without a restart.
+func (s *server) Reload(config gofig.Config) error {
+
+	s.reloadLock.Lock()
+	defer s.reloadLock.Unlock()
+
+	if config == nil {
+		var err error
+		if config, err = apicnfg.NewConfig(); err != nil {
+			return err
+		}
+	}
+	config = config.Scope(types.ConfigServer)
+
+	s.ctx.Info("reloading server")
+
+	// parse all of the endpoints' certificates before reloading the
+	// services so an invalid TLS config does not leave the server only
+	// partially reloaded
+	certs, err := s.parseCertificates(config)
+	if err != nil {
+		return err
+	}
+
+	if err := services.Reload(s.ctx, config); err != nil {
+		return err
+	}
+
+	for srv, c := range certs {
+		srv.certs.set(c)
+		srv.ctx.Info("rotated tls certificates")
+	}
+
+	s.config = config
+	s.ctx.Info("reloaded server")
+	return nil
+}
+
+func (s *server) parseCertificates(
+	config gofig.Config) (map[*HTTPServer][]tls.Certificate, error) {
+
+	certs := map[*HTTPServer][]tls.Certificate{}
+
+	for _, srv := range s.servers {
+		if srv.tlsConfig == nil {
+			continue
+		}
+
+		if config.Get(srv.endpoint) == nil {
+			srv.ctx.WithField("endpoint", srv.endpoint).Warn(
+				"endpoint removed from config; restart required")
+			continue
+		}
+
+		tlsConfig, err := utils.ParseTLSConfig(
+			srv.ctx,
+			config.Scope(srv.endpoint),
+			nil,
+			types.ConfigServer,
+			srv.endpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		if tlsConfig == nil {
+			srv.ctx.WithField("endpoint", srv.endpoint).Warn(
+				"tls disabled in config; restart required")
+			continue
+		}
+
+		if len(tlsConfig.Certificates) > 0 {
+			certs[srv] = tlsConfig.Certificates
+		}
+	}
+
+	return certs, nil
+}
+
+// Reload reloads all servers with the configuration returned by the
+// provided loader. If the loader is nil then the default configuration files
+// are read again.
+func Reload(load ConfigLoader) <-chan error {
+	errs := make(chan error)
+	go func() {
+		defer close(errs)
+		var config gofig.Config
+		if load != nil {
+			var err error
+			if config, err = load(); err != nil {
+				errs <- err
+				return
+			}
+		}
+		for _, server := range servers {
+			if err := server.Reload(config); err != nil {
+				errs <- err
+			}
+		}
+		log.Info("all servers reloaded")
+	}()
+	return errs
+}
+
+// ReloadOnHangup reloads all servers with the configuration returned by the
+// provided loader each time the program receives a SIGHUP signal. If the
+// loader is nil then the default configuration files are read again.
+func ReloadOnHangup(load ConfigLoader) {
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGHUP)
+	go func() {
+		for range sigc {
+			log.Info("received hangup signal")
+			for err := range Reload(load) {
+				log.WithError(err).Error("error reloading server")
+			}
+		}
+	}()
+}
+
+// ReloadOnChange reloads all servers with the configuration returned by the
+// provided loader when the modification time of any of the provided files
+// changes. The files are checked at the specified interval. If the loader is
+// nil then the default configuration files are read again, and if no files
+// are provided then the default configuration files are watched. The returned
+// function stops watching the files.
+func ReloadOnChange(
+	interval time.Duration,
+	load ConfigLoader,
+	files ...string) func() {
+
+	if len(files) == 0 {
+		files = apicnfg.Files()
+	}
+
+	modTimes := func() map[string]time.Time {
+		m := map[string]time.Time{}
+		for _, f := range files {
+			if fi, err := os.Stat(f); err == nil {
+				m[f] = fi.ModTime()
+			}
+		}
+		return m
+	}
+
+	stop := make(chan int)
+	stopOnce := &sync.Once{}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		last := modTimes()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				next := modTimes()
+				if modTimesEqual(last, next) {
+					continue
+				}
+				last = next
+				log.WithField("files", files).Info("config files changed")
+				for err := range Reload(load) {
+					log.WithError(err).Error("error reloading server")
+				}
+			}
+		}
+	}()
+
+	return func() { stopOnce.Do(func() { close(stop) }) }
+}
+
+func modTimesEqual(a, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || !w.Equal(v) {
+			return false
+		}
+	}
+	return true
+}
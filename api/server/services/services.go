@@ -2,6 +2,7 @@ package services
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 	"sync"
 
@@ -20,7 +21,23 @@ var (
 type serviceContainer struct {
 	config          gofig.Config
 	storageServices map[string]types.StorageService
+	serviceConfigs  map[string]interface{}
 	taskService     *globalTaskService
+	eventService    *globalEventService
+	deviceService   *globalDeviceService
+
+	mountTokenService *globalMountTokenService
+
+	// registered are the services registered while the server is running
+	// keyed by the services' names. The services are registered and
+	// deregistered, and the container is reloaded, while holding the
+	// register lock.
+	registered   map[string]*types.ServiceRegistration
+	registerLock sync.Mutex
+
+	// interruptedTasks are the tasks that were interrupted when the server
+	// was previously shut down
+	interruptedTasks []*types.InterruptedTask
 }
 
 // Init initializes the types.
@@ -34,8 +51,14 @@ func Init(ctx types.Context, config gofig.Config) error {
 	ctx.Info("initializing server services")
 
 	sc := &serviceContainer{
-		taskService:     &globalTaskService{name: "global-task-service"},
+		taskService:   &globalTaskService{name: "global-task-service"},
+		eventService:  &globalEventService{name: "global-event-service"},
+		deviceService: &globalDeviceService{name: "global-device-service"},
+		mountTokenService: &globalMountTokenService{
+			name: "global-mount-token-service",
+		},
 		storageServices: map[string]types.StorageService{},
+		serviceConfigs:  map[string]interface{}{},
 	}
 
 	if err := sc.Init(ctx, config); err != nil {
@@ -43,8 +66,13 @@ func Init(ctx types.Context, config gofig.Config) error {
 	}
 
 	servicesByServerRWL.Lock()
-	defer servicesByServerRWL.Unlock()
 	servicesByServer[serverName] = sc
+	servicesByServerRWL.Unlock()
+
+	// the mount tokens outstanding when the server was stopped expire only
+	// once the services are registered, as expiring a token publishes an
+	// event
+	sc.mountTokenService.resume(ctx)
 
 	return nil
 }
@@ -56,10 +84,32 @@ func (sc *serviceContainer) Init(ctx types.Context, config gofig.Config) error {
 		return err
 	}
 
+	if err := sc.eventService.Init(ctx, config); err != nil {
+		return err
+	}
+
+	if err := sc.deviceService.Init(ctx, config); err != nil {
+		return err
+	}
+
+	if err := sc.mountTokenService.Init(ctx, config); err != nil {
+		return err
+	}
+
+	registered, err := loadRegisteredServices(config)
+	if err != nil {
+		return err
+	}
+	sc.registered = registered
+
 	if err := sc.initStorageServices(ctx); err != nil {
 		return err
 	}
 
+	if err := sc.loadInterruptedTasks(ctx); err != nil {
+		ctx.WithError(err).Error("error loading interrupted tasks")
+	}
+
 	return nil
 }
 
@@ -85,12 +135,30 @@ func GetStorageService(
 	return getStorageServices(ctx)[name]
 }
 
+// StorageServiceConfig returns the configuration of the storage service. The
+// properties set for the service override those set for the server. A nil
+// value is returned if the service was not created by this package.
+func StorageServiceConfig(svc types.StorageService) gofig.Config {
+	s, ok := svc.(*storageService)
+	if !ok {
+		return nil
+	}
+	return s.Config()
+}
+
 // StorageServices returns a channel on which all the storage services are
 // received.
 func StorageServices(ctx types.Context) <-chan types.StorageService {
+	servicesByServerRWL.RLock()
+	svcs := []types.StorageService{}
+	for _, v := range getStorageServices(ctx) {
+		svcs = append(svcs, v)
+	}
+	servicesByServerRWL.RUnlock()
+
 	c := make(chan types.StorageService)
 	go func() {
-		for _, v := range getStorageServices(ctx) {
+		for _, v := range svcs {
 			c <- v
 		}
 		close(c)
@@ -98,6 +166,83 @@ func StorageServices(ctx types.Context) <-chan types.StorageService {
 	return c
 }
 
+// Reload updates the server's storage services from the provided config.
+// Services that were added or whose configuration changed are initialized,
+// and services that were removed no longer receive requests. Services whose
+// configuration is unchanged are left as-is so their in-flight requests are
+// unaffected. The existing services remain in place if any of the new or
+// changed services fails to initialize.
+func Reload(ctx types.Context, config gofig.Config) error {
+
+	serverName, ok := context.Server(ctx)
+	if !ok {
+		panic("ctx is missing ServerName")
+	}
+
+	servicesByServerRWL.RLock()
+	sc := servicesByServer[serverName]
+	servicesByServerRWL.RUnlock()
+
+	return sc.Reload(ctx, config)
+}
+
+func (sc *serviceContainer) Reload(
+	ctx types.Context, config gofig.Config) error {
+
+	ctx.Info("reloading server services")
+
+	sc.registerLock.Lock()
+	defer sc.registerLock.Unlock()
+
+	cfgSvcsMap, err := sc.servicesConfig(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	storageServices := map[string]types.StorageService{}
+	serviceConfigs := map[string]interface{}{}
+
+	for serviceName, serviceConfig := range cfgSvcsMap {
+		serviceConfigs[serviceName] = serviceConfig
+
+		if storSvc, ok := sc.storageServices[serviceName]; ok &&
+			reflect.DeepEqual(serviceConfig, sc.serviceConfigs[serviceName]) {
+			ctx.WithField("service", serviceName).Debug("service unchanged")
+			storageServices[serviceName] = storSvc
+			continue
+		}
+
+		storSvc, err := newServiceFromConfig(
+			ctx, config, serviceName, serviceConfig)
+		if err != nil {
+			return err
+		}
+		storageServices[serviceName] = storSvc
+	}
+
+	servicesByServerRWL.Lock()
+	prevServices := sc.storageServices
+	sc.config = config
+	sc.storageServices = storageServices
+	sc.serviceConfigs = serviceConfigs
+	servicesByServerRWL.Unlock()
+
+	// stop the services that were removed or replaced
+	for serviceName, storSvc := range prevServices {
+		if storageServices[serviceName] == storSvc {
+			continue
+		}
+		if s, ok := storSvc.(*storageService); ok {
+			s.close()
+		}
+		if _, ok := storageServices[serviceName]; !ok {
+			ctx.WithField("service", serviceName).Info("removed service")
+		}
+	}
+
+	return nil
+}
+
 func (sc *serviceContainer) initStorageServices(ctx types.Context) error {
 	if ctx == nil {
 		panic("ctx is nil")
@@ -105,16 +250,39 @@ func (sc *serviceContainer) initStorageServices(ctx types.Context) error {
 	if sc.config == nil {
 		panic("sc.config is nil")
 	}
-	cfgSvcs := sc.config.Get(types.ConfigServices)
+	cfgSvcsMap, err := sc.servicesConfig(ctx, sc.config)
+	if err != nil {
+		return err
+	}
+	ctx.WithField("count", len(cfgSvcsMap)).Debug("got services map")
+
+	for serviceName, serviceConfig := range cfgSvcsMap {
+		storSvc, err := newServiceFromConfig(
+			ctx, sc.config, serviceName, serviceConfig)
+		if err != nil {
+			return err
+		}
+
+		sc.storageServices[serviceName] = storSvc
+		sc.serviceConfigs[serviceName] = serviceConfig
+	}
+
+	return nil
+}
+
+func getServicesConfig(
+	config gofig.Config) (map[string]interface{}, error) {
+
+	cfgSvcs := config.Get(types.ConfigServices)
 	cfgSvcsMap, ok := cfgSvcs.(map[string]interface{})
 	if !ok {
-		driverName := sc.config.GetString("libstorage.driver")
+		driverName := config.GetString("libstorage.driver")
 		if driverName == "" {
 			err := goof.WithFields(goof.Fields{
 				"configKey": types.ConfigServices,
 				"obj":       cfgSvcs,
 			}, "invalid format")
-			return err
+			return nil, err
 		}
 
 		cfgSvcsMap = map[string]interface{}{
@@ -123,30 +291,33 @@ func (sc *serviceContainer) initStorageServices(ctx types.Context) error {
 			},
 		}
 	}
-	ctx.WithField("count", len(cfgSvcsMap)).Debug("got services map")
-
-	for serviceName := range cfgSvcsMap {
-		serviceName = strings.ToLower(serviceName)
-
-		storSvc := &storageService{name: serviceName}
+	return cfgSvcsMap, nil
+}
 
-		ctx := ctx.WithValue(context.StorageServiceKey, storSvc)
-		ctx.Debug("processing service config")
+func newStorageService(
+	ctx types.Context,
+	config gofig.Config,
+	serviceName string) (*storageService, error) {
+
+	scope := fmt.Sprintf("libstorage.server.services.%s", serviceName)
+	storSvc := &storageService{
+		name:       serviceName,
+		scope:      scope,
+		baseConfig: config,
+	}
 
-		scope := fmt.Sprintf("libstorage.server.services.%s", serviceName)
-		ctx.WithField("scope", scope).Debug(
-			"getting scoped config for service")
-		config := sc.config.Scope(scope)
+	ctx = ctx.WithValue(context.StorageServiceKey, storSvc)
+	ctx.Debug("processing service config")
 
-		if err := storSvc.Init(ctx, config); err != nil {
-			return err
-		}
+	ctx.WithField("scope", scope).Debug(
+		"getting scoped config for service")
 
-		ctx.Info("created new service")
-		sc.storageServices[serviceName] = storSvc
+	if err := storSvc.Init(ctx, config.Scope(scope)); err != nil {
+		return nil, err
 	}
 
-	return nil
+	ctx.Info("created new service")
+	return storSvc, nil
 }
 
 func getTaskService(ctx types.Context) *globalTaskService {
@@ -163,9 +334,10 @@ func getTaskService(ctx types.Context) *globalTaskService {
 	return servicesByServer[serverName].taskService
 }
 
-// Tasks returns a channel on which all tasks are received.
+// Tasks returns a channel on which all tasks visible to the request's tenant
+// are received.
 func Tasks(ctx types.Context) <-chan *types.Task {
-	return getTaskService(ctx).Tasks()
+	return getTaskService(ctx).tenantTasks(ctx)
 }
 
 // TaskTrack creates a new, trackable task.
@@ -181,9 +353,10 @@ func TaskExecute(
 	return getTaskService(ctx).TaskExecute(ctx, run, schema)
 }
 
-// TaskInspect returns the task with the specified ID.
+// TaskInspect returns the task with the specified ID. A nil value is
+// returned if the task is not visible to the request's tenant.
 func TaskInspect(ctx types.Context, taskID int) *types.Task {
-	return getTaskService(ctx).TaskInspect(taskID)
+	return getTaskService(ctx).tenantTaskInspect(ctx, taskID)
 }
 
 // TaskWait blocks until the specified task is completed.
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// circuitBreaker fails a storage service's tasks fast once the service's
+// driver has failed a number of consecutive times so that requests are not
+// queued against a backend that is down. After the cool-down period a single
+// task is allowed to run; the breaker closes if the task succeeds and opens
+// for another cool-down period if it does not.
+type circuitBreaker struct {
+	sync.Mutex
+	service   string
+	threshold int
+	cooldown  time.Duration
+	state     types.CircuitBreakerStatus
+	failures  int
+	openedAt  time.Time
+	trial     bool
+}
+
+// CircuitBreakerState returns the state of the service's circuit breaker or
+// nil if the service's circuit breaker is disabled.
+func CircuitBreakerState(
+	svc types.StorageService) *types.CircuitBreakerState {
+
+	s, ok := svc.(*storageService)
+	if !ok || s.breaker == nil {
+		return nil
+	}
+	return s.breaker.State()
+}
+
+func (s *storageService) initCircuitBreaker(ctx types.Context) {
+	threshold := s.config.GetInt(types.ConfigServerCircuitBreakerThreshold)
+	if threshold <= 0 {
+		return
+	}
+	cooldown, err := time.ParseDuration(
+		s.config.GetString(types.ConfigServerCircuitBreakerCooldown))
+	if err != nil || cooldown <= 0 {
+		cooldown = 30 * time.Second
+	}
+	s.breaker = &circuitBreaker{
+		service:   s.name,
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     types.CircuitBreakerClosed,
+	}
+	ctx.WithFields(log.Fields{
+		"threshold": threshold,
+		"cooldown":  cooldown,
+	}).Debug("configured circuit breaker")
+}
+
+// check returns an error if the breaker is open and its cool-down period
+// has not elapsed. Unlike allow, check does not change the breaker's state.
+func (b *circuitBreaker) check() error {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.state != types.CircuitBreakerOpen {
+		return nil
+	}
+	if remaining := b.cooldown - time.Since(b.openedAt); remaining > 0 {
+		return utils.NewServiceUnavailableError(b.service, remaining)
+	}
+	return nil
+}
+
+// allow returns an error if the breaker is open and a task should not be
+// run.
+func (b *circuitBreaker) allow() error {
+	b.Lock()
+	defer b.Unlock()
+
+	switch b.state {
+	case types.CircuitBreakerOpen:
+		remaining := b.cooldown - time.Since(b.openedAt)
+		if remaining > 0 {
+			return utils.NewServiceUnavailableError(b.service, remaining)
+		}
+		b.state = types.CircuitBreakerHalfOpen
+		b.trial = true
+		return nil
+	case types.CircuitBreakerHalfOpen:
+		if b.trial {
+			return utils.NewServiceUnavailableError(b.service, b.cooldown)
+		}
+		b.trial = true
+	}
+	return nil
+}
+
+// record records the result of a task that was allowed to run.
+func (b *circuitBreaker) record(ctx types.Context, err error) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.trial = false
+
+	if !isBackendFailure(err) {
+		if b.state != types.CircuitBreakerClosed {
+			ctx.Info("circuit breaker closed")
+		}
+		b.state = types.CircuitBreakerClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == types.CircuitBreakerHalfOpen ||
+		b.failures >= b.threshold {
+		if b.state != types.CircuitBreakerOpen {
+			ctx.WithFields(log.Fields{
+				"failures": b.failures,
+				"cooldown": b.cooldown,
+			}).WithError(err).Warn("circuit breaker opened")
+		}
+		b.state = types.CircuitBreakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// State returns the breaker's state.
+func (b *circuitBreaker) State() *types.CircuitBreakerState {
+	b.Lock()
+	defer b.Unlock()
+
+	state := &types.CircuitBreakerState{
+		Status:   b.state,
+		Failures: b.failures,
+	}
+	if b.state == types.CircuitBreakerOpen {
+		state.OpenedAt = b.openedAt.Unix()
+		if remaining := b.cooldown - time.Since(b.openedAt); remaining > 0 {
+			state.RetryAfter = int64(remaining / time.Second)
+		}
+	}
+	return state
+}
+
+// wrap returns a function that runs the task if the breaker allows it and
+// records the task's result.
+func (b *circuitBreaker) wrap(
+	run types.StorageTaskRunFunc) types.StorageTaskRunFunc {
+
+	return func(
+		ctx types.Context,
+		svc types.StorageService) (interface{}, error) {
+
+		if err := b.allow(); err != nil {
+			return nil, err
+		}
+		result, err := run(ctx, svc)
+		b.record(ctx, err)
+		return result, err
+	}
+}
+
+// isBackendFailure returns a flag indicating whether or not the error is a
+// failure of the storage backend rather than an error caused by the request.
+// Only timeouts, network errors such as refused connections, and the errors
+// drivers classify as ErrBackend or ErrThrottled, such as throttling and
+// authentication errors, are backend failures, so the errors caused by one client's bad
+// requests do not open the breaker for all of the clients. The inner errors
+// of wrapped errors are checked as well.
+func isBackendFailure(err error) bool {
+	for err != nil {
+		if err == types.ErrTimedOut {
+			return true
+		}
+		switch err.(type) {
+		case *types.ErrBackend, *types.ErrThrottled, net.Error:
+			return true
+		}
+		f, ok := err.(fieldsError)
+		if !ok {
+			return false
+		}
+		err, _ = f.Fields()["inner"].(error)
+	}
+	return false
+}
+
+// fieldsError is an error with fields, such as a goof error, which holds
+// the error it wraps in its "inner" field.
+type fieldsError interface {
+	Fields() map[string]interface{}
+}
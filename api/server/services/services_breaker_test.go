@@ -0,0 +1,133 @@
+package services
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/akutz/goof"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	b := &circuitBreaker{
+		service:   "ebs",
+		threshold: 2,
+		cooldown:  50 * time.Millisecond,
+		state:     types.CircuitBreakerClosed,
+	}
+
+	failed := utils.NewBackendError(errors.New("auth failure"))
+	fail := func(types.Context, types.StorageService) (interface{}, error) {
+		return nil, failed
+	}
+	succeed := func(types.Context, types.StorageService) (interface{}, error) {
+		return "ok", nil
+	}
+	notFound := func(types.Context, types.StorageService) (interface{}, error) {
+		return nil, utils.NewNotFoundError("vol-1")
+	}
+
+	// errors caused by the request do not count as failures
+	_, err := b.wrap(notFound)(ctx, nil)
+	assert.IsType(t, &types.ErrNotFound{}, err)
+	_, err = b.wrap(fail)(ctx, nil)
+	assert.Equal(t, failed, err)
+	_, err = b.wrap(notFound)(ctx, nil)
+	assert.IsType(t, &types.ErrNotFound{}, err)
+	assert.Equal(t, types.CircuitBreakerClosed, b.State().Status)
+
+	_, err = b.wrap(fail)(ctx, nil)
+	assert.Equal(t, failed, err)
+	state := b.State()
+	assert.Equal(t, types.CircuitBreakerOpen, state.Status)
+	assert.Equal(t, 2, state.Failures)
+
+	// the breaker fails fast while it is open
+	assert.IsType(t, &types.ErrServiceUnavailable{}, b.check())
+	_, err = b.wrap(succeed)(ctx, nil)
+	assert.IsType(t, &types.ErrServiceUnavailable{}, err)
+
+	// a failed trial opens the breaker again
+	time.Sleep(60 * time.Millisecond)
+	assert.NoError(t, b.check())
+	_, err = b.wrap(fail)(ctx, nil)
+	assert.Equal(t, failed, err)
+	assert.Equal(t, types.CircuitBreakerOpen, b.State().Status)
+
+	// only one trial is allowed while the breaker is half-open
+	time.Sleep(60 * time.Millisecond)
+	assert.NoError(t, b.allow())
+	assert.Equal(t, types.CircuitBreakerHalfOpen, b.State().Status)
+	assert.IsType(t, &types.ErrServiceUnavailable{}, b.allow())
+
+	// a successful trial closes the breaker
+	b.record(ctx, nil)
+	state = b.State()
+	assert.Equal(t, types.CircuitBreakerClosed, state.Status)
+	assert.Equal(t, 0, state.Failures)
+	_, err = b.wrap(succeed)(ctx, nil)
+	assert.NoError(t, err)
+}
+
+func TestIsBackendFailure(t *testing.T) {
+	// errors caused by requests, including untyped driver errors
+	for _, err := range []error{
+		nil,
+		types.ErrNotImplemented,
+		goof.New("volume name already exists"),
+		goof.WithError("error detaching volume", goof.New("Volume not attached")),
+		utils.NewNotFoundError("vol-1"),
+		utils.NewInvalidRequestError("size", 0, "invalid size"),
+		utils.NewServiceUnavailableError("ebs", time.Second),
+	} {
+		assert.False(t, isBackendFailure(err), "%v", err)
+	}
+
+	// timeouts, network errors, and errors classified by drivers, including
+	// when they are wrapped
+	backendErr := utils.NewBackendError(errors.New("RequestLimitExceeded"))
+	netErr := &net.OpError{
+		Op:  "dial",
+		Net: "tcp",
+		Err: errors.New("connection refused"),
+	}
+	for _, err := range []error{
+		types.ErrTimedOut,
+		backendErr,
+		utils.NewThrottledError(errors.New("Throttling"), time.Second),
+		netErr,
+		goof.WithError("error creating volume", backendErr),
+		goof.WithFieldE("volumeID", "vol-1", "error getting volume", netErr),
+	} {
+		assert.True(t, isBackendFailure(err), "%v", err)
+	}
+}
+
+func TestCircuitBreakerClientErrors(t *testing.T) {
+	ctx := context.Background()
+	b := &circuitBreaker{
+		service:   "ebs",
+		threshold: 2,
+		cooldown:  time.Minute,
+		state:     types.CircuitBreakerClosed,
+	}
+
+	// a client's bad requests do not open the breaker for other clients
+	exists := func(types.Context, types.StorageService) (interface{}, error) {
+		return nil, goof.New("volume name already exists")
+	}
+	for i := 0; i < 5; i++ {
+		_, err := b.wrap(exists)(ctx, nil)
+		assert.EqualError(t, err, "volume name already exists")
+	}
+	state := b.State()
+	assert.Equal(t, types.CircuitBreakerClosed, state.Status)
+	assert.Equal(t, 0, state.Failures)
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// nextDeviceChars are the characters that may follow a next device info's
+// prefix, in the order in which device names are allocated.
+const nextDeviceChars = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// globalDeviceService reserves the device names chosen for volumes being
+// attached to an instance so that concurrent attachments to the same
+// instance are not given the same device name.
+type globalDeviceService struct {
+	sync.Mutex
+	name  string
+	lease time.Duration
+
+	// reservations are keyed by driver and instance ID and then by device
+	// name
+	reservations map[string]map[string]*deviceReservation
+}
+
+type deviceReservation struct {
+	volumeID string
+	expires  time.Time
+}
+
+// Init initializes the service.
+func (s *globalDeviceService) Init(
+	ctx types.Context, config gofig.Config) error {
+
+	s.lease = 2 * time.Minute
+	if d, err := time.ParseDuration(
+		config.GetString(types.ConfigServerNextDeviceLease)); err == nil {
+		s.lease = d
+	}
+	s.reservations = map[string]map[string]*deviceReservation{}
+	ctx.WithField("lease", s.lease).Debug("configured device service")
+	return nil
+}
+
+func (s *globalDeviceService) Name() string {
+	return s.name
+}
+
+// Reserve reserves a device name for the volume. The candidate, which is
+// the device name chosen by the instance's executor, is reserved if it is
+// not reserved for another volume. Otherwise the first of the candidates
+// that follow it that is neither reserved nor in use is reserved.
+func (s *globalDeviceService) Reserve(
+	key, volumeID, candidate string,
+	candidates []string,
+	inUse map[string]bool) (string, error) {
+
+	s.Lock()
+	defer s.Unlock()
+
+	now := time.Now()
+	devices, ok := s.reservations[key]
+	if !ok {
+		devices = map[string]*deviceReservation{}
+		s.reservations[key] = devices
+	}
+	for device, r := range devices {
+		if now.After(r.expires) {
+			delete(devices, device)
+		}
+	}
+
+	isFree := func(device string) bool {
+		r, ok := devices[device]
+		return !ok || r.volumeID == volumeID
+	}
+
+	device := ""
+	if isFree(candidate) {
+		device = candidate
+	} else {
+		start := 0
+		for i, c := range candidates {
+			if c == candidate {
+				start = i + 1
+				break
+			}
+		}
+		for _, c := range candidates[start:] {
+			if isFree(c) && !inUse[c] {
+				device = c
+				break
+			}
+		}
+	}
+	if device == "" {
+		return "", goof.WithField(
+			"candidate", candidate, "no available device")
+	}
+
+	for d, r := range devices {
+		if r.volumeID == volumeID {
+			delete(devices, d)
+		}
+	}
+	devices[device] = &deviceReservation{
+		volumeID: volumeID,
+		expires:  now.Add(s.lease),
+	}
+	return device, nil
+}
+
+// Release releases the device name reserved for the volume.
+func (s *globalDeviceService) Release(key, volumeID string) {
+	s.Lock()
+	defer s.Unlock()
+
+	devices := s.reservations[key]
+	for d, r := range devices {
+		if r.volumeID == volumeID {
+			delete(devices, d)
+		}
+	}
+	if len(devices) == 0 {
+		delete(s.reservations, key)
+	}
+}
+
+func getDeviceService(ctx types.Context) *globalDeviceService {
+
+	serverName, ok := context.Server(ctx)
+	if !ok {
+		panic("ctx is missing ServerName")
+	}
+
+	servicesByServerRWL.RLock()
+	defer servicesByServerRWL.RUnlock()
+
+	return servicesByServer[serverName].deviceService
+}
+
+// deviceReservationKey returns the key of the device reservations for the
+// instance in the context. Instances are identified by driver rather than
+// by service since the services of a driver share an instance's device
+// names.
+func deviceReservationKey(ctx types.Context) (string, bool) {
+	svc, ok := context.Service(ctx)
+	if !ok {
+		return "", false
+	}
+	iid, ok := context.InstanceID(ctx)
+	if !ok || iid.ID == "" {
+		return "", false
+	}
+	return strings.ToLower(svc.Driver().Name()) + "/" + iid.ID, true
+}
+
+// ReserveNextDevice reserves a device name for the volume being attached to
+// the instance in the context. The provided device name, which is chosen by
+// the instance's executor, is returned unless it is reserved for another
+// volume, in which case the next available device name is reserved and
+// returned. The device name is reserved until the volume is detached, the
+// attachment fails, or the configured lease expires, whichever is first.
+func ReserveNextDevice(
+	ctx types.Context,
+	volumeID, nextDevice string,
+	info *types.NextDeviceInfo) (string, error) {
+
+	key, ok := deviceReservationKey(ctx)
+	if !ok || nextDevice == "" {
+		return nextDevice, nil
+	}
+
+	inUse := map[string]bool{}
+	if ld, ok := context.LocalDevices(ctx); ok {
+		for k, v := range ld.DeviceMap {
+			inUse[k] = true
+			inUse[v] = true
+		}
+	}
+
+	device, err := getDeviceService(ctx).Reserve(
+		key, volumeID, nextDevice, nextDeviceCandidates(info), inUse)
+	if err != nil {
+		return "", err
+	}
+	if device != nextDevice {
+		ctx.WithFields(log.Fields{
+			"volumeID":   volumeID,
+			"nextDevice": nextDevice,
+			"reserved":   device,
+		}).Info("next device reserved for another volume")
+	}
+	return device, nil
+}
+
+// ReleaseNextDevice releases the device name reserved for the volume being
+// attached to the instance in the context.
+func ReleaseNextDevice(ctx types.Context, volumeID string) {
+	if key, ok := deviceReservationKey(ctx); ok {
+		getDeviceService(ctx).Release(key, volumeID)
+	}
+}
+
+// nextDeviceCandidates returns the device names described by the next
+// device info, ex. /dev/xvdf through /dev/xvdp for the prefix "xvd" and the
+// pattern "[f-p]". Only patterns that match a single character are
+// supported.
+func nextDeviceCandidates(info *types.NextDeviceInfo) []string {
+	if info == nil || info.Ignore || info.Pattern == "" {
+		return nil
+	}
+	rx, err := regexp.Compile("^(?:" + info.Pattern + ")$")
+	if err != nil {
+		return nil
+	}
+	var candidates []string
+	for _, c := range nextDeviceChars {
+		if rx.MatchString(string(c)) {
+			candidates = append(candidates, "/dev/"+info.Prefix+string(c))
+		}
+	}
+	return candidates
+}
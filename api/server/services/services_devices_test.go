@@ -0,0 +1,66 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+func TestNextDeviceCandidates(t *testing.T) {
+	assert.Equal(t,
+		[]string{"/dev/xvdf", "/dev/xvdg", "/dev/xvdh", "/dev/xvdi"},
+		nextDeviceCandidates(
+			&types.NextDeviceInfo{Prefix: "xvd", Pattern: "[f-i]"}))
+	assert.Nil(t, nextDeviceCandidates(nil))
+	assert.Nil(t, nextDeviceCandidates(
+		&types.NextDeviceInfo{Prefix: "xvd", Pattern: "[f-i]", Ignore: true}))
+}
+
+func TestDeviceServiceReserve(t *testing.T) {
+	s := &globalDeviceService{
+		lease:        time.Minute,
+		reservations: map[string]map[string]*deviceReservation{},
+	}
+	candidates := nextDeviceCandidates(
+		&types.NextDeviceInfo{Prefix: "xvd", Pattern: "[f-i]"})
+	inUse := map[string]bool{"/dev/xvdg": true}
+
+	// concurrent attachments are given the same device by their executors
+	d1, err := s.Reserve("ebs/i-1", "vol-1", "/dev/xvdf", candidates, inUse)
+	assert.NoError(t, err)
+	assert.Equal(t, "/dev/xvdf", d1)
+	d2, err := s.Reserve("ebs/i-1", "vol-2", "/dev/xvdf", candidates, inUse)
+	assert.NoError(t, err)
+	assert.Equal(t, "/dev/xvdh", d2)
+
+	// the same volume keeps its reservation
+	d1, err = s.Reserve("ebs/i-1", "vol-1", "/dev/xvdf", candidates, inUse)
+	assert.NoError(t, err)
+	assert.Equal(t, "/dev/xvdf", d1)
+
+	// reservations are per instance
+	d3, err := s.Reserve("ebs/i-2", "vol-3", "/dev/xvdf", candidates, inUse)
+	assert.NoError(t, err)
+	assert.Equal(t, "/dev/xvdf", d3)
+
+	d4, err := s.Reserve("ebs/i-1", "vol-4", "/dev/xvdf", candidates, inUse)
+	assert.NoError(t, err)
+	assert.Equal(t, "/dev/xvdi", d4)
+
+	_, err = s.Reserve("ebs/i-1", "vol-5", "/dev/xvdf", candidates, inUse)
+	assert.Error(t, err)
+
+	s.Release("ebs/i-1", "vol-1")
+	d5, err := s.Reserve("ebs/i-1", "vol-5", "/dev/xvdf", candidates, inUse)
+	assert.NoError(t, err)
+	assert.Equal(t, "/dev/xvdf", d5)
+
+	// expired reservations are released
+	s.reservations["ebs/i-1"]["/dev/xvdf"].expires = time.Now().Add(-time.Second)
+	d6, err := s.Reserve("ebs/i-1", "vol-6", "/dev/xvdf", candidates, inUse)
+	assert.NoError(t, err)
+	assert.Equal(t, "/dev/xvdf", d6)
+}
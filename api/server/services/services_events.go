@@ -0,0 +1,206 @@
+package services
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	gofig "github.com/akutz/gofig/types"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+type globalEventService struct {
+	sync.RWMutex
+	name    string
+	epoch   string
+	events  []*types.Event
+	lastID  int64
+	max     int
+	publish chan int
+
+	// history are the histories of the volumes' events keyed by the
+	// volumes' services and IDs
+	history     map[string][]*types.Event
+	historyMax  int
+	historyVols int
+	historyFile string
+	historyLock sync.Mutex
+
+	// historySave is signaled when the histories have changed so that they
+	// are written to the history file off of the request path
+	historySave chan struct{}
+}
+
+// Init initializes the service.
+func (s *globalEventService) Init(
+	ctx types.Context, config gofig.Config) error {
+
+	s.max = config.GetInt(types.ConfigServerEventsMax)
+	if s.max <= 0 {
+		s.max = 1000
+	}
+	s.publish = make(chan int)
+
+	epoch, err := types.NewUUID()
+	if err != nil {
+		return err
+	}
+	s.epoch = epoch.String()
+	ctx.WithFields(log.Fields{
+		"max":   s.max,
+		"epoch": s.epoch,
+	}).Debug("configured event service")
+
+	s.historyMax = config.GetInt(types.ConfigServerEventsVolumeHistoryMax)
+	s.historyVols = config.GetInt(types.ConfigServerEventsVolumeHistoryVolumes)
+	s.historyFile = config.GetString(types.ConfigServerEventsVolumeHistoryFile)
+	if err := s.loadVolumeHistory(); err != nil {
+		ctx.WithError(err).Error("error loading volume event history")
+	}
+	s.initVolumeHistorySaver(ctx)
+	return nil
+}
+
+func (s *globalEventService) Name() string {
+	return s.name
+}
+
+// Publish records an event and wakes any waiting subscribers.
+func (s *globalEventService) Publish(ev *types.Event) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.lastID++
+	ev.ID = s.lastID
+	ev.Epoch = s.epoch
+	ev.Time = time.Now().Unix()
+
+	s.events = append(s.events, ev)
+	if len(s.events) > s.max {
+		s.events = s.events[len(s.events)-s.max:]
+	}
+	s.recordVolumeEvent(ev)
+
+	close(s.publish)
+	s.publish = make(chan int)
+}
+
+// Since returns the events with an ID greater than the provided ID as well as
+// a channel that is closed when the next event is published. An ID greater
+// than that of the last event published was received in a previous epoch,
+// so all of the events are returned in order for the client to observe the
+// new epoch.
+func (s *globalEventService) Since(id int64) ([]*types.Event, <-chan int) {
+	s.RLock()
+	defer s.RUnlock()
+
+	if id > s.lastID {
+		id = 0
+	}
+
+	events := []*types.Event{}
+	for _, ev := range s.events {
+		if ev.ID > id {
+			events = append(events, ev)
+		}
+	}
+	return events, s.publish
+}
+
+func getEventService(ctx types.Context) *globalEventService {
+
+	serverName, ok := context.Server(ctx)
+	if !ok {
+		panic("ctx is missing ServerName")
+	}
+
+	servicesByServerRWL.RLock()
+	defer servicesByServerRWL.RUnlock()
+
+	return servicesByServer[serverName].eventService
+}
+
+// PublishEvent publishes an event for the storage service in the context.
+func PublishEvent(ctx types.Context, ev *types.Event) {
+	if ev.Service == "" {
+		if svc, ok := context.Service(ctx); ok {
+			ev.Service = svc.Name()
+		}
+	}
+	if ev.VolumeID != "" {
+		if _, ok := ev.Fields[types.EventFieldInstanceID]; !ok {
+			if iid, ok := context.InstanceID(ctx); ok && iid != nil {
+				if ev.Fields == nil {
+					ev.Fields = map[string]string{}
+				}
+				ev.Fields[types.EventFieldInstanceID] = iid.ID
+			}
+		}
+	}
+
+	if _, ok := ev.Fields[types.EventFieldTenant]; !ok {
+		if tenant, ok := context.Tenant(ctx); ok {
+			if ev.Fields == nil {
+				ev.Fields = map[string]string{}
+			}
+			ev.Fields[types.EventFieldTenant] = tenant
+		}
+	}
+
+	s := getEventService(ctx)
+	s.Publish(ev)
+	ctx.WithField("event", ev.Type).Debug("published event")
+
+	if ev.VolumeID != "" {
+		s.scheduleVolumeHistorySave()
+	}
+}
+
+// Events returns the service's events with an ID greater than the provided
+// ID. If there are no such events then Events waits up to the specified
+// duration for one to be published. A request scoped to a tenant receives
+// only the events published by the tenant's requests.
+func Events(
+	ctx types.Context,
+	service string,
+	since int64,
+	wait time.Duration) []*types.Event {
+
+	s := getEventService(ctx)
+	timeout := time.After(wait)
+
+	for {
+		events, c := s.Since(since)
+		events = serviceEvents(ctx, events, service)
+		if len(events) > 0 || wait <= 0 {
+			return events
+		}
+
+		select {
+		case <-c:
+		case <-timeout:
+			return events
+		case <-ctx.Done():
+			return events
+		}
+	}
+}
+
+// serviceEvents returns the events for the service that are visible to the
+// request's tenant.
+func serviceEvents(
+	ctx types.Context,
+	events []*types.Event,
+	service string) []*types.Event {
+
+	svcEvents := []*types.Event{}
+	for _, ev := range events {
+		if strings.EqualFold(ev.Service, service) && isTenantEvent(ctx, ev) {
+			svcEvents = append(svcEvents, ev)
+		}
+	}
+	return svcEvents
+}
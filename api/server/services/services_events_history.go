@@ -0,0 +1,207 @@
+package services
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+func volumeHistoryKey(service, volumeID string) string {
+	return strings.ToLower(service) + "/" + volumeID
+}
+
+// recordVolumeEvent appends the event to the history of the event's volume,
+// dropping the volume's oldest events once the history is full. The
+// histories of the volumes whose last events are the oldest are dropped
+// once there are too many volumes. The service must be locked.
+func (s *globalEventService) recordVolumeEvent(ev *types.Event) {
+	if ev.VolumeID == "" || s.historyMax <= 0 {
+		return
+	}
+	if s.history == nil {
+		s.history = map[string][]*types.Event{}
+	}
+	k := volumeHistoryKey(ev.Service, ev.VolumeID)
+	if _, ok := s.history[k]; !ok && s.historyVols > 0 {
+		for len(s.history) >= s.historyVols {
+			dropOldestVolumeHistory(s.history)
+		}
+	}
+	h := append(s.history[k], ev)
+	if len(h) > s.historyMax {
+		h = h[len(h)-s.historyMax:]
+	}
+	s.history[k] = h
+}
+
+// dropOldestVolumeHistory removes the history whose last event is the
+// oldest.
+func dropOldestVolumeHistory(history map[string][]*types.Event) {
+	var (
+		oldest string
+		last   *types.Event
+	)
+	for k, h := range history {
+		if len(h) == 0 {
+			oldest = k
+			break
+		}
+		ev := h[len(h)-1]
+		if last == nil || ev.Time < last.Time ||
+			(ev.Time == last.Time && ev.ID < last.ID) {
+			oldest, last = k, ev
+		}
+	}
+	delete(history, oldest)
+}
+
+// VolumeHistory returns the history of the volume's events, oldest first.
+func (s *globalEventService) VolumeHistory(
+	service, volumeID string) []*types.Event {
+
+	s.RLock()
+	defer s.RUnlock()
+
+	h := s.history[volumeHistoryKey(service, volumeID)]
+	events := make([]*types.Event, len(h))
+	copy(events, h)
+	return events
+}
+
+// loadVolumeHistory reads the volumes' histories from the history file so
+// that the histories survive a restart of the server.
+func (s *globalEventService) loadVolumeHistory() error {
+	if s.historyFile == "" || s.historyMax <= 0 {
+		return nil
+	}
+
+	buf, err := ioutil.ReadFile(s.historyFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	history := map[string][]*types.Event{}
+	if err := json.Unmarshal(buf, &history); err != nil {
+		return goof.WithFieldE(
+			"path", s.historyFile, "error reading volume event history", err)
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	for k, h := range history {
+		if len(h) > s.historyMax {
+			h = h[len(h)-s.historyMax:]
+		}
+		history[k] = h
+
+		// the IDs of the events published by this server follow those of
+		// the restored events so that an ID identifies a single event
+		for _, ev := range h {
+			if ev.ID > s.lastID {
+				s.lastID = ev.ID
+			}
+		}
+	}
+	for s.historyVols > 0 && len(history) > s.historyVols {
+		dropOldestVolumeHistory(history)
+	}
+	s.history = history
+	return nil
+}
+
+// initVolumeHistorySaver starts writing the volumes' histories to the
+// history file whenever they change. The histories are written by a single
+// goroutine so that publishing an event does not wait on the file, and the
+// changes made while the file is being written are saved together by the
+// next write.
+func (s *globalEventService) initVolumeHistorySaver(ctx types.Context) {
+	if s.historyFile == "" || s.historyMax <= 0 {
+		return
+	}
+	s.historySave = make(chan struct{}, 1)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.historySave:
+				if err := s.saveVolumeHistory(); err != nil {
+					ctx.WithError(err).Warn(
+						"error saving volume event history")
+				}
+			}
+		}
+	}()
+}
+
+// scheduleVolumeHistorySave signals that the volumes' histories have changed
+// and should be written to the history file.
+func (s *globalEventService) scheduleVolumeHistorySave() {
+	if s.historySave == nil {
+		return
+	}
+	select {
+	case s.historySave <- struct{}{}:
+	default:
+	}
+}
+
+// saveVolumeHistory writes the volumes' histories to the history file.
+func (s *globalEventService) saveVolumeHistory() error {
+	if s.historyFile == "" || s.historyMax <= 0 {
+		return nil
+	}
+
+	// the histories are written one at a time so that an older history
+	// does not overwrite a newer one
+	s.historyLock.Lock()
+	defer s.historyLock.Unlock()
+
+	s.RLock()
+	buf, err := json.MarshalIndent(s.history, "", "  ")
+	s.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.historyFile), 0755); err != nil {
+		return err
+	}
+	// write to a temporary file first so that a failed write does not leave
+	// a partial record
+	tmp := s.historyFile + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.historyFile)
+}
+
+// VolumeEvents returns the history of the events of the volume with the
+// provided service and ID, oldest first. The history of a removed volume is
+// retained so that its removal may be investigated. A request scoped to a
+// tenant receives only the events published by the tenant's requests.
+func VolumeEvents(
+	ctx types.Context, service, volumeID string) []*types.Event {
+
+	events := getEventService(ctx).VolumeHistory(service, volumeID)
+	if _, ok := context.Tenant(ctx); !ok {
+		return events
+	}
+	tenantEvents := []*types.Event{}
+	for _, ev := range events {
+		if isTenantEvent(ctx, ev) {
+			tenantEvents = append(tenantEvents, ev)
+		}
+	}
+	return tenantEvents
+}
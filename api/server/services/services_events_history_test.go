@@ -0,0 +1,138 @@
+package services
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gofigCore "github.com/akutz/gofig"
+	"github.com/stretchr/testify/assert"
+	gocontext "golang.org/x/net/context"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+func TestVolumeHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "volume-events.json")
+	config := gofigCore.New()
+	config.Set(types.ConfigServerEventsVolumeHistoryMax, 2)
+	config.Set(types.ConfigServerEventsVolumeHistoryFile, path)
+
+	ctx := context.Background()
+	s := &globalEventService{}
+	assert.NoError(t, s.Init(ctx, config))
+
+	for _, et := range []types.EventType{
+		types.EventVolumeCreated,
+		types.EventVolumeAttached,
+		types.EventVolumeDetached,
+	} {
+		s.Publish(&types.Event{
+			Type:     et,
+			Service:  "vfs",
+			VolumeID: "vfs-000",
+		})
+	}
+	s.Publish(&types.Event{
+		Type:     types.EventVolumeCreated,
+		Service:  "vfs",
+		VolumeID: "vfs-001",
+	})
+	s.Publish(&types.Event{Type: types.EventTaskInterrupted})
+
+	// the history is bounded and the oldest events are dropped
+	h := s.VolumeHistory("VFS", "vfs-000")
+	if assert.Len(t, h, 2) {
+		assert.Equal(t, types.EventVolumeAttached, h[0].Type)
+		assert.Equal(t, types.EventVolumeDetached, h[1].Type)
+	}
+	assert.Len(t, s.VolumeHistory("vfs", "vfs-001"), 1)
+	assert.Empty(t, s.VolumeHistory("vfs", "vfs-002"))
+
+	// the history is read by the next server
+	assert.NoError(t, s.saveVolumeHistory())
+	s2 := &globalEventService{}
+	assert.NoError(t, s2.Init(ctx, config))
+	h = s2.VolumeHistory("vfs", "vfs-000")
+	if assert.Len(t, h, 2) {
+		assert.Equal(t, types.EventVolumeDetached, h[1].Type)
+	}
+
+	// and the IDs of its events follow those of the restored events
+	ev := &types.Event{
+		Type:     types.EventVolumeRemoved,
+		Service:  "vfs",
+		VolumeID: "vfs-000",
+	}
+	s2.Publish(ev)
+	h = s2.VolumeHistory("vfs", "vfs-000")
+	if assert.Len(t, h, 2) {
+		assert.True(t, h[1].ID > h[0].ID)
+		assert.NotEqual(t, h[0].Epoch, h[1].Epoch)
+	}
+}
+
+func TestVolumeHistoryVolumes(t *testing.T) {
+	config := gofigCore.New()
+	config.Set(types.ConfigServerEventsVolumeHistoryMax, 2)
+	config.Set(types.ConfigServerEventsVolumeHistoryVolumes, 2)
+	config.Set(types.ConfigServerEventsVolumeHistoryFile, "")
+
+	s := &globalEventService{}
+	assert.NoError(t, s.Init(context.Background(), config))
+
+	for _, id := range []string{"vfs-000", "vfs-001", "vfs-000", "vfs-002"} {
+		s.Publish(&types.Event{
+			Type:     types.EventVolumeAttached,
+			Service:  "vfs",
+			VolumeID: id,
+		})
+	}
+
+	// the history of the volume whose last event is the oldest is dropped
+	assert.Len(t, s.VolumeHistory("vfs", "vfs-000"), 2)
+	assert.Empty(t, s.VolumeHistory("vfs", "vfs-001"))
+	assert.Len(t, s.VolumeHistory("vfs", "vfs-002"), 1)
+}
+
+func TestVolumeHistorySaver(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "volume-events.json")
+	config := gofigCore.New()
+	config.Set(types.ConfigServerEventsVolumeHistoryFile, path)
+
+	gctx, cancel := gocontext.WithCancel(gocontext.Background())
+	defer cancel()
+	s := &globalEventService{}
+	assert.NoError(t, s.Init(context.New(gctx), config))
+
+	s.Publish(&types.Event{
+		Type:     types.EventVolumeCreated,
+		Service:  "vfs",
+		VolumeID: "vfs-000",
+	})
+	s.scheduleVolumeHistorySave()
+
+	// the history is written in the background
+	for i := 0; i < 100; i++ {
+		if _, err = os.Stat(path); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.NoError(t, err)
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"time"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// initInstanceCache initializes the cache of the instances returned by the
+// service's driver if the cache is enabled.
+func (s *storageService) initInstanceCache(ctx types.Context) {
+	dur, err := time.ParseDuration(
+		s.config.GetString(types.ConfigServerCacheInstance))
+	if err != nil || dur <= 0 {
+		return
+	}
+	s.instances = utils.NewTTLStore(dur, true)
+	ctx.WithField("ttl", dur).Debug("configured instance cache")
+}
+
+// InstanceInspect returns the instance for the instance ID in the context.
+// The instances returned by a service's driver are cached for the
+// configured duration so that repeated requests for the same instance do
+// not each call the storage platform's metadata service. An instance ID's
+// cached instance is removed if an inspection of the instance fails, and
+// InvalidateInstance removes it when an attach or detach operation fails.
+func InstanceInspect(
+	ctx types.Context,
+	svc types.StorageService,
+	opts types.Store) (*types.Instance, error) {
+
+	s, ok := svc.(*storageService)
+	if !ok || s.instances == nil {
+		return svc.Driver().InstanceInspect(ctx, opts)
+	}
+
+	iid, ok := context.InstanceID(ctx)
+	if !ok {
+		return svc.Driver().InstanceInspect(ctx, opts)
+	}
+	key := iid.String()
+
+	if i, ok := s.instances.Get(key).(*types.Instance); ok {
+		ctx.WithField("instanceID", key).Debug("found cached instance")
+		return i, nil
+	}
+
+	i, err := svc.Driver().InstanceInspect(ctx, opts)
+	if err != nil {
+		s.instances.Delete(key)
+		return nil, err
+	}
+	if i != nil {
+		s.instances.Set(key, i)
+	}
+	return i, nil
+}
+
+// InvalidateInstance removes the cached instance for the instance ID in the
+// context so that the instance is inspected again by the next operation that
+// requires it. The cached instance may be inaccurate once an attach or detach
+// operation fails.
+func InvalidateInstance(ctx types.Context, svc types.StorageService) {
+	s, ok := svc.(*storageService)
+	if !ok || s.instances == nil {
+		return
+	}
+	iid, ok := context.InstanceID(ctx)
+	if !ok {
+		return
+	}
+	s.instances.Delete(iid.String())
+	ctx.WithField("instanceID", iid.String()).Debug("removed cached instance")
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// instancesTestDriver is a storage driver that counts the inspections of
+// its instance. Only the methods used by the test are implemented.
+type instancesTestDriver struct {
+	types.StorageDriver
+	inspections int
+}
+
+func (d *instancesTestDriver) InstanceInspect(
+	ctx types.Context, opts types.Store) (*types.Instance, error) {
+
+	d.inspections++
+	iid := context.MustInstanceID(ctx)
+	return &types.Instance{InstanceID: iid}, nil
+}
+
+func TestInvalidateInstance(t *testing.T) {
+	d := &instancesTestDriver{}
+	s := &storageService{
+		driver:    d,
+		instances: utils.NewTTLStore(time.Minute, true),
+	}
+
+	ctx := context.Background().WithValue(
+		context.InstanceIDKey,
+		&types.InstanceID{ID: "iid-1", Driver: "vfs"})
+	store := utils.NewStore()
+
+	_, err := InstanceInspect(ctx, s, store)
+	assert.NoError(t, err)
+	_, err = InstanceInspect(ctx, s, store)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, d.inspections)
+
+	// the instance is inspected again once a failed attach or detach
+	// invalidates the cached instance
+	InvalidateInstance(ctx, s)
+	_, err = InstanceInspect(ctx, s, store)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, d.inspections)
+
+	// the cached instances of other instance IDs are not removed
+	InvalidateInstance(ctx.WithValue(
+		context.InstanceIDKey,
+		&types.InstanceID{ID: "iid-2", Driver: "vfs"}), s)
+	_, err = InstanceInspect(ctx, s, store)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, d.inspections)
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// WithVolumeMetadata returns a context with the metadata with which the
+// service's driver stamps the volume or snapshot it creates: the service's
+// name, the client's authenticated identity, tenant, and instance ID, the
+// time the object is created, and the version of the metadata's schema.
+func WithVolumeMetadata(
+	ctx types.Context, svc types.StorageService) types.Context {
+
+	md := map[string]string{
+		types.VolumeMetadataService: svc.Name(),
+		types.VolumeMetadataCreated: strconv.FormatInt(time.Now().Unix(), 10),
+		types.VolumeMetadataSchema:  types.VolumeMetadataSchemaVersion,
+	}
+	if v, ok := ctx.Value(context.UserKey).(string); ok && v != "" {
+		md[types.VolumeMetadataCreator] = v
+	}
+	if iid, ok := context.InstanceID(ctx); ok && iid != nil && iid.ID != "" {
+		md[types.VolumeMetadataInstance] = iid.ID
+	}
+	if v, ok := context.Tenant(ctx); ok && v != "" {
+		md[types.VolumeMetadataTenant] = v
+	}
+	return ctx.WithValue(context.VolumeMetadataKey, md)
+}
+
+// VolumeQuota returns the maximum total size, in GiB, of the service's
+// volumes that are visible to a request's tenant, or of all the service's
+// volumes if the request is not scoped to a tenant. A quota of zero
+// indicates the service's volumes are not limited.
+func VolumeQuota(svc types.StorageService) int64 {
+	s, ok := svc.(*storageService)
+	if !ok {
+		return 0
+	}
+	return int64(s.Config().GetInt(types.ConfigServerVolumeQuota))
+}
+
+// ManagedVolumesOnly returns a flag indicating whether or not the service's
+// volume listing includes only the volumes stamped with libStorage metadata.
+// The request's managed query parameter, if present, overrides the service's
+// configuration. Volumes are not filtered if the service's driver does not
+// stamp the volumes it creates.
+func ManagedVolumesOnly(
+	ctx types.Context,
+	svc types.StorageService,
+	store types.Store) bool {
+
+	s, ok := svc.(*storageService)
+	if !ok {
+		return false
+	}
+	managed := s.Config().GetBool(types.ConfigServerVolumeManagedOnly)
+	if store != nil && store.IsSet("managed") {
+		managed = store.GetBool("managed")
+	}
+	if !managed {
+		return false
+	}
+	d, ok := svc.Driver().(types.ProvidesVolumeMetadata)
+	return ok && d.StampsVolumeMetadata(ctx)
+}
+
+// IsManagedVolume returns a flag indicating whether or not the volume is
+// stamped with libStorage metadata.
+func IsManagedVolume(v *types.Volume) bool {
+	return v.Fields[types.VolumeMetadataService] != ""
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"testing"
+
+	gofigCore "github.com/akutz/gofig"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+func TestWithVolumeMetadata(t *testing.T) {
+	ctx := context.Background()
+	ctx = ctx.WithValue(context.UserKey, "node1.example.com")
+	ctx = ctx.WithValue(
+		context.InstanceIDKey, &types.InstanceID{ID: "i-1234", Driver: "ebs"})
+
+	ctx = WithVolumeMetadata(ctx, &storageService{name: "ebs"})
+	md, ok := context.VolumeMetadata(ctx)
+	if !assert.True(t, ok) {
+		t.FailNow()
+	}
+	assert.Equal(t, "ebs", md[types.VolumeMetadataService])
+	assert.Equal(t, "node1.example.com", md[types.VolumeMetadataCreator])
+	assert.Equal(t, "i-1234", md[types.VolumeMetadataInstance])
+	assert.Equal(t,
+		types.VolumeMetadataSchemaVersion, md[types.VolumeMetadataSchema])
+	assert.NotEmpty(t, md[types.VolumeMetadataCreated])
+
+	assert.True(t, IsManagedVolume(&types.Volume{Fields: md}))
+	assert.False(t, IsManagedVolume(&types.Volume{}))
+}
+
+type stampingDriver struct {
+	types.StorageDriver
+}
+
+func (d *stampingDriver) StampsVolumeMetadata(ctx types.Context) bool {
+	return true
+}
+
+func TestManagedVolumesOnly(t *testing.T) {
+	config := gofigCore.New()
+	config.Set(types.ConfigServerVolumeManagedOnly, true)
+
+	ctx := context.Background()
+	s := &storageService{name: "ebs", config: config}
+
+	// volumes are not filtered if the driver does not stamp them
+	assert.False(t, ManagedVolumesOnly(ctx, s, nil))
+
+	s.driver = &stampingDriver{}
+	assert.True(t, ManagedVolumesOnly(ctx, s, nil))
+
+	// the managed query parameter overrides the configuration
+	store := utils.NewStore()
+	store.Set("managed", "false")
+	assert.False(t, ManagedVolumesOnly(ctx, s, store))
+
+	config.Set(types.ConfigServerVolumeManagedOnly, false)
+	assert.False(t, ManagedVolumesOnly(ctx, s, utils.NewStore()))
+	store.Set("managed", "true")
+	assert.True(t, ManagedVolumesOnly(ctx, s, store))
+}
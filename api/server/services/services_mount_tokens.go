@@ -0,0 +1,277 @@
+package services
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// globalMountTokenService issues the short-lived tokens with which the
+// clients of the instances to which volumes are attached report whether or
+// not the volumes were mounted. The outstanding tokens are recorded in a
+// file so that a client may still redeem its token after the server is
+// restarted.
+type globalMountTokenService struct {
+	sync.Mutex
+	name   string
+	ttl    time.Duration
+	path   string
+	tokens map[string]*mountToken
+}
+
+type mountToken struct {
+	Service    string `json:"service"`
+	VolumeID   string `json:"volumeID"`
+	InstanceID string `json:"instanceID"`
+	Expires    int64  `json:"expires"`
+	timer      *time.Timer
+}
+
+// Init initializes the service and loads the tokens that were outstanding
+// when the server was last stopped. The loaded tokens do not expire until
+// resume is invoked.
+func (s *globalMountTokenService) Init(
+	ctx types.Context, config gofig.Config) error {
+
+	s.ttl = 5 * time.Minute
+	if d, err := time.ParseDuration(
+		config.GetString(types.ConfigServerMountTokenTTL)); err == nil {
+		s.ttl = d
+	}
+	s.path = config.GetString(types.ConfigServerMountTokenFile)
+	s.tokens = map[string]*mountToken{}
+	if err := s.load(); err != nil {
+		ctx.WithField("path", s.path).WithError(err).Error(
+			"error loading mount tokens")
+	}
+	ctx.WithFields(log.Fields{
+		"ttl":    s.ttl,
+		"path":   s.path,
+		"tokens": len(s.tokens),
+	}).Debug("configured mount token service")
+	return nil
+}
+
+// resume schedules the expiry of the tokens loaded when the service was
+// initialized. A token that expired while the server was stopped expires
+// immediately. Expiring a token publishes an event, so resume is invoked
+// once the server's services are registered.
+func (s *globalMountTokenService) resume(ctx types.Context) {
+	s.Lock()
+	defer s.Unlock()
+
+	now := time.Now()
+	for token, t := range s.tokens {
+		if t.timer != nil {
+			continue
+		}
+		d := time.Unix(t.Expires, 0).Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		t.timer = s.schedule(ctx, token, d)
+	}
+}
+
+func (s *globalMountTokenService) schedule(
+	ctx types.Context, token string, d time.Duration) *time.Timer {
+
+	return time.AfterFunc(d, func() {
+		s.expire(ctx, token)
+	})
+}
+
+func (s *globalMountTokenService) load() error {
+	if s.path == "" {
+		return nil
+	}
+	buf, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err := json.Unmarshal(buf, &s.tokens); err != nil {
+		return goof.WithFieldE(
+			"path", s.path, "error reading mount tokens", err)
+	}
+	return nil
+}
+
+// save writes the outstanding tokens. A token that cannot be recorded is
+// still valid until the server is restarted, so errors are logged rather
+// than returned. The caller must hold the service's lock.
+func (s *globalMountTokenService) save(ctx types.Context) {
+	if s.path == "" {
+		return
+	}
+	err := func() error {
+		buf, err := json.MarshalIndent(s.tokens, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+			return err
+		}
+		// write to a temporary file first so that a failed write does not
+		// lose the tokens already recorded
+		tmp := s.path + ".tmp"
+		if err := ioutil.WriteFile(tmp, buf, 0600); err != nil {
+			return err
+		}
+		return os.Rename(tmp, s.path)
+	}()
+	if err != nil {
+		ctx.WithField("path", s.path).WithError(err).Error(
+			"error saving mount tokens")
+	}
+}
+
+func (s *globalMountTokenService) Name() string {
+	return s.name
+}
+
+// Issue issues a token for the attachment of the volume to the instance.
+// An empty token is returned if mount tokens are disabled. The
+// volumeMountUnreported event is published if the token expires before it
+// is redeemed.
+func (s *globalMountTokenService) Issue(
+	ctx types.Context,
+	service, volumeID, instanceID string) (string, error) {
+
+	if s.ttl <= 0 {
+		return "", nil
+	}
+
+	uuid, err := types.NewUUID()
+	if err != nil {
+		return "", err
+	}
+	token := uuid.String()
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.tokens[token] = &mountToken{
+		Service:    service,
+		VolumeID:   volumeID,
+		InstanceID: instanceID,
+		Expires:    time.Now().Add(s.ttl).Unix(),
+		timer:      s.schedule(ctx, token, s.ttl),
+	}
+	s.save(ctx)
+	return token, nil
+}
+
+// Redeem redeems the token issued for the attachment of the volume to the
+// instance. A token may be redeemed only once.
+func (s *globalMountTokenService) Redeem(
+	ctx types.Context,
+	service, volumeID, instanceID, token string) bool {
+
+	s.Lock()
+	defer s.Unlock()
+
+	t, ok := s.tokens[token]
+	if !ok ||
+		t.Service != service ||
+		t.VolumeID != volumeID ||
+		t.InstanceID != instanceID {
+		return false
+	}
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+	delete(s.tokens, token)
+	s.save(ctx)
+	return true
+}
+
+func (s *globalMountTokenService) expire(ctx types.Context, token string) {
+	s.Lock()
+	t, ok := s.tokens[token]
+	if ok {
+		delete(s.tokens, token)
+		s.save(ctx)
+	}
+	s.Unlock()
+
+	if !ok {
+		return
+	}
+
+	ctx.WithFields(log.Fields{
+		"service":    t.Service,
+		"volumeID":   t.VolumeID,
+		"instanceID": t.InstanceID,
+	}).Warn("volume mount not reported")
+
+	PublishEvent(ctx, &types.Event{
+		Type:     types.EventVolumeMountUnreported,
+		Service:  t.Service,
+		VolumeID: t.VolumeID,
+		Fields: map[string]string{
+			types.EventFieldInstanceID: t.InstanceID,
+		},
+	})
+}
+
+func getMountTokenService(ctx types.Context) *globalMountTokenService {
+
+	serverName, ok := context.Server(ctx)
+	if !ok {
+		panic("ctx is missing ServerName")
+	}
+
+	servicesByServerRWL.RLock()
+	defer servicesByServerRWL.RUnlock()
+
+	return servicesByServer[serverName].mountTokenService
+}
+
+// IssueMountToken issues a token with which the client of the instance in
+// the context reports whether or not the volume attached to the instance
+// was mounted. The token expires after the configured duration. An empty
+// token is returned if mount tokens are disabled.
+func IssueMountToken(ctx types.Context, volumeID string) (string, error) {
+	svc, ok := context.Service(ctx)
+	if !ok {
+		return "", nil
+	}
+	iid, ok := context.InstanceID(ctx)
+	if !ok || iid.ID == "" {
+		return "", nil
+	}
+	return getMountTokenService(ctx).Issue(
+		ctx, svc.Name(), volumeID, iid.ID)
+}
+
+// RedeemMountToken redeems the token issued when the volume was attached to
+// the instance in the context. An error is returned if the token was not
+// issued for the volume and instance, has already been redeemed, or has
+// expired.
+func RedeemMountToken(ctx types.Context, volumeID, token string) error {
+	svc := context.MustService(ctx)
+	var instanceID string
+	if iid, ok := context.InstanceID(ctx); ok {
+		instanceID = iid.ID
+	}
+	if !getMountTokenService(ctx).Redeem(
+		ctx, svc.Name(), volumeID, instanceID, token) {
+		return utils.NewInvalidRequestError(
+			"token", "", "invalid or expired mount token")
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/context"
+)
+
+func TestMountTokenServiceRedeem(t *testing.T) {
+	s := &globalMountTokenService{
+		ttl:    time.Hour,
+		tokens: map[string]*mountToken{},
+	}
+	ctx := context.Background()
+
+	token, err := s.Issue(ctx, "ebs", "vol-1", "i-1")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+
+	// the token is valid only for its volume and instance
+	assert.False(t, s.Redeem(ctx, "ebs", "vol-2", "i-1", token))
+	assert.False(t, s.Redeem(ctx, "ebs", "vol-1", "i-2", token))
+	assert.False(t, s.Redeem(ctx, "gce", "vol-1", "i-1", token))
+
+	// the token may be redeemed only once
+	assert.True(t, s.Redeem(ctx, "ebs", "vol-1", "i-1", token))
+	assert.False(t, s.Redeem(ctx, "ebs", "vol-1", "i-1", token))
+	assert.Empty(t, s.tokens)
+}
+
+func TestMountTokenServiceDisabled(t *testing.T) {
+	s := &globalMountTokenService{tokens: map[string]*mountToken{}}
+	token, err := s.Issue(context.Background(), "ebs", "vol-1", "i-1")
+	assert.NoError(t, err)
+	assert.Empty(t, token)
+}
+
+func TestMountTokenServiceRestart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mounttokens")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	p := path.Join(dir, "mount-tokens.json")
+	s := &globalMountTokenService{
+		ttl:    time.Hour,
+		path:   p,
+		tokens: map[string]*mountToken{},
+	}
+
+	token1, err := s.Issue(ctx, "ebs", "vol-1", "i-1")
+	assert.NoError(t, err)
+	token2, err := s.Issue(ctx, "ebs", "vol-2", "i-1")
+	assert.NoError(t, err)
+	assert.True(t, s.Redeem(ctx, "ebs", "vol-1", "i-1", token1))
+	s.tokens[token2].timer.Stop()
+
+	// a restarted server loads the tokens that were not redeemed
+	s = &globalMountTokenService{
+		ttl:    time.Hour,
+		path:   p,
+		tokens: map[string]*mountToken{},
+	}
+	assert.NoError(t, s.load())
+	if !assert.Len(t, s.tokens, 1) {
+		t.FailNow()
+	}
+	tok := s.tokens[token2]
+	if !assert.NotNil(t, tok) {
+		t.FailNow()
+	}
+	assert.Equal(t, "ebs", tok.Service)
+	assert.Equal(t, "vol-2", tok.VolumeID)
+	assert.Equal(t, "i-1", tok.InstanceID)
+	assert.Nil(t, tok.timer)
+
+	// the loaded tokens expire once the service is resumed
+	s.resume(ctx)
+	assert.NotNil(t, tok.timer)
+
+	// and may still be redeemed
+	assert.True(t, s.Redeem(ctx, "ebs", "vol-2", "i-1", token2))
+
+	s = &globalMountTokenService{path: p, tokens: map[string]*mountToken{}}
+	assert.NoError(t, s.load())
+	assert.Empty(t, s.tokens)
+}
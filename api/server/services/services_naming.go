@@ -0,0 +1,196 @@
+package services
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// namingTemplateVarRX matches the variables of a naming template, ex.
+// {tenant}.
+var namingTemplateVarRX = regexp.MustCompile(`\{([A-Za-z0-9_.]+)\}`)
+
+// initVolumeNaming compiles the service's volume name pattern and the
+// expression that reverses the service's naming template.
+func (s *storageService) initVolumeNaming(ctx types.Context) error {
+	pattern := s.config.GetString(types.ConfigServerVolumeNamingPattern)
+	template := s.config.GetString(types.ConfigServerVolumeNamingTemplate)
+	if pattern == "" && template == "" {
+		return nil
+	}
+
+	if pattern != "" {
+		rx, err := regexp.Compile(pattern)
+		if err != nil {
+			return goof.WithFieldE(
+				"pattern", pattern, "invalid volume name pattern", err)
+		}
+		s.namePattern = rx
+	}
+
+	if template != "" {
+		rx, err := namingTemplateRX(s.name, template)
+		if err != nil {
+			return goof.WithFieldE(
+				"template", template, "invalid volume naming template", err)
+		}
+		s.nameTemplate = template
+		s.nameTemplateRX = rx
+	}
+
+	ctx.WithFields(log.Fields{
+		"pattern":  pattern,
+		"template": template,
+	}).Debug("configured volume naming")
+	return nil
+}
+
+// VolumeName validates the name a client requested for a new volume of the
+// service against the service's volume name pattern and returns the name
+// with which the volume is created, which is the requested name expanded
+// with the service's naming template, if any.
+//
+// The template's {name} variable is the requested name, {service} is the
+// service's name, and {user} is the client's authenticated identity. The
+// {tenant} variable is the client's tenant if tenancy is enabled. Any other
+// variable is read from the request, ex. {env} is the value of the env query
+// parameter or request option.
+func VolumeName(
+	ctx types.Context,
+	svc types.StorageService,
+	name string,
+	store types.Store) (string, error) {
+
+	s, ok := svc.(*storageService)
+	if !ok {
+		return name, nil
+	}
+
+	if name == "" {
+		return "", utils.NewInvalidRequestError(
+			"name", name, "volume name is required")
+	}
+	if s.namePattern != nil && !s.namePattern.MatchString(name) {
+		return "", utils.NewInvalidRequestError(
+			"name", name, "volume name does not match "+
+				s.namePattern.String())
+	}
+	if s.nameTemplate == "" {
+		return name, nil
+	}
+
+	var err error
+	backendName := namingTemplateVarRX.ReplaceAllStringFunc(
+		s.nameTemplate, func(v string) string {
+			k := v[1 : len(v)-1]
+			val := namingTemplateValue(ctx, svc, name, store, k)
+			if val == "" && err == nil {
+				err = utils.NewInvalidRequestError(
+					k, val, "volume naming template value is required")
+			}
+			return val
+		})
+	if err != nil {
+		return "", err
+	}
+	return backendName, nil
+}
+
+func namingTemplateValue(
+	ctx types.Context,
+	svc types.StorageService,
+	name string,
+	store types.Store,
+	k string) string {
+
+	switch k {
+	case "name":
+		return name
+	case "service":
+		return svc.Name()
+	case "user":
+		v, _ := ctx.Value(context.UserKey).(string)
+		return v
+	case "tenant":
+		if v, ok := context.Tenant(ctx); ok {
+			return v
+		}
+	}
+	if v := store.GetString(k); v != "" {
+		return v
+	}
+	if opts := store.GetStore("opts"); opts != nil {
+		return opts.GetString(k)
+	}
+	return ""
+}
+
+// UnmapVolumeName reverses the service's naming template by setting the
+// name of a volume whose name matches the template to the name the client
+// requested when the volume was created. The volume's original name is
+// retained in the volume's fields. The names of volumes that were not
+// created with the template are not changed.
+func UnmapVolumeName(svc types.StorageService, v *types.Volume) {
+
+	s, ok := svc.(*storageService)
+	if !ok || v == nil || s.nameTemplateRX == nil {
+		return
+	}
+	m := s.nameTemplateRX.FindStringSubmatch(v.Name)
+	if m == nil || m[1] == "" {
+		return
+	}
+	if v.Fields == nil {
+		v.Fields = map[string]string{}
+	}
+	v.Fields[types.VolumeFieldBackendName] = v.Name
+	v.Name = m[1]
+}
+
+// namingTemplateRX returns a regular expression that matches the names
+// expanded with the template and captures their {name} variable. The
+// {service} variable matches only the service's name. A nil value is
+// returned if the template has no {name} variable.
+func namingTemplateRX(service, template string) (*regexp.Regexp, error) {
+	if !strings.Contains(template, "{name}") {
+		return nil, nil
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("^")
+	var (
+		i     int
+		named bool
+	)
+	for _, loc := range namingTemplateVarRX.FindAllStringSubmatchIndex(
+		template, -1) {
+
+		buf.WriteString(regexp.QuoteMeta(template[i:loc[0]]))
+		i = loc[1]
+
+		switch template[loc[2]:loc[3]] {
+		case "name":
+			if named {
+				buf.WriteString(".+?")
+				continue
+			}
+			named = true
+			buf.WriteString("(.+)")
+		case "service":
+			buf.WriteString(regexp.QuoteMeta(service))
+		default:
+			buf.WriteString(".+?")
+		}
+	}
+	buf.WriteString(regexp.QuoteMeta(template[i:]))
+	buf.WriteString("$")
+
+	return regexp.Compile(buf.String())
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"testing"
+
+	gofigCore "github.com/akutz/gofig"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+func TestVolumeNaming(t *testing.T) {
+	config := gofigCore.New()
+	config.Set(types.ConfigServerVolumeNamingPattern, "^[a-z][a-z0-9-]*$")
+	config.Set(types.ConfigServerVolumeNamingTemplate, "{tenant}-{name}")
+
+	ctx := context.Background()
+	s := &storageService{name: "ebs", config: config}
+	if !assert.NoError(t, s.initVolumeNaming(ctx)) {
+		t.FailNow()
+	}
+
+	store := utils.NewStore()
+	store.Set("tenant", "team1")
+
+	name, err := VolumeName(ctx, s, "data-01", store)
+	assert.NoError(t, err)
+	assert.Equal(t, "team1-data-01", name)
+
+	// the requested name must match the pattern
+	_, err = VolumeName(ctx, s, "Data_01", store)
+	assert.IsType(t, &types.ErrInvalidRequest{}, err)
+
+	// the template's variables must have values
+	_, err = VolumeName(ctx, s, "data-01", utils.NewStore())
+	assert.IsType(t, &types.ErrInvalidRequest{}, err)
+
+	v := &types.Volume{Name: name}
+	UnmapVolumeName(s, v)
+	assert.Equal(t, "data-01", v.Name)
+	assert.Equal(t, name, v.Fields[types.VolumeFieldBackendName])
+
+	// volumes not created with the template keep their names
+	v = &types.Volume{Name: "legacy"}
+	UnmapVolumeName(s, v)
+	assert.Equal(t, "legacy", v.Name)
+	assert.Nil(t, v.Fields)
+}
+
+func TestNamingTemplateRX(t *testing.T) {
+	rx, err := namingTemplateRX("ebs", "{service}.{name}.{env}")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, []string{"ebs.data.prod", "data"},
+		rx.FindStringSubmatch("ebs.data.prod"))
+	assert.Nil(t, rx.FindStringSubmatch("gce.data.prod"))
+
+	rx, err = namingTemplateRX("ebs", "{tenant}-vol")
+	assert.NoError(t, err)
+	assert.Nil(t, rx)
+}
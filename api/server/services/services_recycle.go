@@ -0,0 +1,117 @@
+package services
+
+import (
+	"strconv"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// VolumeRecycleRetention returns how long the volumes removed from the
+// service are kept in the recycle bin before they are purged. A duration of
+// zero indicates removed volumes are not recycled.
+func VolumeRecycleRetention(svc types.StorageService) time.Duration {
+	s, ok := svc.(*storageService)
+	if !ok {
+		return 0
+	}
+	return s.recycleRetention()
+}
+
+func (s *storageService) recycleRetention() time.Duration {
+	dur, err := time.ParseDuration(
+		s.Config().GetString(types.ConfigServerVolumeRecycleRetention))
+	if err != nil || dur < 0 {
+		return 0
+	}
+	return dur
+}
+
+// initRecycleBin starts purging the volumes whose retention has elapsed from
+// the service's recycle bin if removed volumes are recycled.
+func (s *storageService) initRecycleBin(ctx types.Context) {
+	dur := s.recycleRetention()
+	if dur <= 0 {
+		return
+	}
+
+	interval := time.Minute
+	if dur < interval {
+		interval = dur
+	}
+	ctx.WithField("retention", dur).Debug("configured volume recycle bin")
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.closed:
+				return
+			case <-ticker.C:
+				if err := s.purgeRecycledVolumes(ctx, dur); err != nil {
+					if err == types.ErrNotImplemented {
+						ctx.Warn("driver does not support recycling volumes")
+						return
+					}
+					ctx.WithError(err).Error(
+						"error purging recycled volumes")
+				}
+			}
+		}
+	}()
+}
+
+// purgeRecycledVolumes permanently removes the volumes that have been in the
+// recycle bin for longer than the retention.
+func (s *storageService) purgeRecycledVolumes(
+	ctx types.Context, retention time.Duration) error {
+
+	ctx = context.WithStorageService(ctx, s)
+	ctx, err := context.WithStorageSession(ctx)
+	if err != nil {
+		return err
+	}
+
+	d, ok := s.Driver().(types.ProvidesVolumeRecycling)
+	if !ok {
+		return types.ErrNotImplemented
+	}
+
+	vols, err := d.RecycledVolumes(ctx, utils.NewStore())
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, v := range vols {
+		recycled, err := strconv.ParseInt(
+			v.Fields[types.VolumeFieldRecycledTime], 10, 64)
+		if err != nil {
+			continue
+		}
+		if now.Sub(time.Unix(recycled, 0)) < retention {
+			continue
+		}
+
+		fields := log.Fields{"volumeID": v.ID, "volumeName": v.Name}
+		if err := d.RecycledVolumePurge(
+			ctx, v.ID, utils.NewStore()); err != nil {
+			ctx.WithFields(fields).WithError(err).Error(
+				"error purging recycled volume")
+			continue
+		}
+		ctx.WithFields(fields).Info("purged recycled volume")
+
+		PublishEvent(ctx, &types.Event{
+			Type:     types.EventVolumeRemoved,
+			Service:  s.name,
+			VolumeID: v.ID,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,296 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// RegisterStorageService creates a storage service while the server is
+// running. The service is persisted to the registered services file so it
+// is created again when the server is restarted or reloaded. An error is
+// returned if a service with the same name already exists.
+func RegisterStorageService(
+	ctx types.Context,
+	reg *types.ServiceRegistration) (types.StorageService, error) {
+
+	return getServiceContainer(ctx).register(ctx, reg)
+}
+
+// DeregisterStorageService removes a storage service that was created by
+// RegisterStorageService. Services defined by the server's config cannot be
+// removed.
+func DeregisterStorageService(ctx types.Context, name string) error {
+	return getServiceContainer(ctx).deregister(ctx, name)
+}
+
+func getServiceContainer(ctx types.Context) *serviceContainer {
+
+	serverName, ok := context.Server(ctx)
+	if !ok {
+		panic("ctx is missing ServerName")
+	}
+
+	servicesByServerRWL.RLock()
+	defer servicesByServerRWL.RUnlock()
+	return servicesByServer[serverName]
+}
+
+func (sc *serviceContainer) register(
+	ctx types.Context,
+	reg *types.ServiceRegistration) (types.StorageService, error) {
+
+	sc.registerLock.Lock()
+	defer sc.registerLock.Unlock()
+
+	name := strings.ToLower(reg.Name)
+
+	servicesByServerRWL.RLock()
+	_, exists := sc.storageServices[name]
+	config := sc.config
+	servicesByServerRWL.RUnlock()
+
+	if exists {
+		return nil, utils.NewInvalidRequestError(
+			"name", reg.Name, "service already exists")
+	}
+	if _, err := registry.NewStorageDriver(reg.Driver); err != nil {
+		return nil, utils.NewInvalidRequestError(
+			"driver", reg.Driver, "unknown storage driver")
+	}
+
+	storSvc, err := newRegisteredStorageService(ctx, config, reg)
+	if err != nil {
+		return nil, err
+	}
+
+	registered := map[string]*types.ServiceRegistration{name: reg}
+	for k, v := range sc.registered {
+		registered[k] = v
+	}
+	if err := saveRegisteredServices(config, registered); err != nil {
+		storSvc.close()
+		return nil, err
+	}
+
+	servicesByServerRWL.Lock()
+	sc.registered = registered
+	sc.storageServices[name] = storSvc
+	sc.serviceConfigs[name] = reg
+	servicesByServerRWL.Unlock()
+
+	ctx.WithFields(log.Fields{
+		"service": name,
+		"driver":  reg.Driver,
+	}).Info("registered service")
+	return storSvc, nil
+}
+
+func (sc *serviceContainer) deregister(ctx types.Context, name string) error {
+
+	sc.registerLock.Lock()
+	defer sc.registerLock.Unlock()
+
+	name = strings.ToLower(name)
+
+	reg, ok := sc.registered[name]
+	if !ok {
+		servicesByServerRWL.RLock()
+		_, exists := sc.storageServices[name]
+		servicesByServerRWL.RUnlock()
+		if exists {
+			return utils.NewInvalidRequestError(
+				"service", name, "service is defined by the config")
+		}
+		return utils.NewNotFoundError(name)
+	}
+
+	registered := map[string]*types.ServiceRegistration{}
+	for k, v := range sc.registered {
+		if k != name {
+			registered[k] = v
+		}
+	}
+	if err := saveRegisteredServices(sc.config, registered); err != nil {
+		return err
+	}
+
+	// the registered service is not removed if a service with the same
+	// name is defined by the config
+	var storSvc types.StorageService
+	servicesByServerRWL.Lock()
+	sc.registered = registered
+	if sc.serviceConfigs[name] == interface{}(reg) {
+		storSvc = sc.storageServices[name]
+		delete(sc.storageServices, name)
+		delete(sc.serviceConfigs, name)
+	}
+	servicesByServerRWL.Unlock()
+
+	if s, ok := storSvc.(*storageService); ok {
+		s.close()
+	}
+
+	ctx.WithField("service", name).Info("deregistered service")
+	return nil
+}
+
+// servicesConfig returns the configurations of the services defined by the
+// config and of the registered services keyed by the services' names. A
+// registered service is ignored if the config defines a service with the
+// same name.
+func (sc *serviceContainer) servicesConfig(
+	ctx types.Context,
+	config gofig.Config) (map[string]interface{}, error) {
+
+	cfgSvcsMap, err := getServicesConfig(config)
+	if err != nil {
+		if len(sc.registered) == 0 {
+			return nil, err
+		}
+		cfgSvcsMap = map[string]interface{}{}
+	}
+
+	svcsMap := map[string]interface{}{}
+	for name, serviceConfig := range cfgSvcsMap {
+		svcsMap[strings.ToLower(name)] = serviceConfig
+	}
+	for name, reg := range sc.registered {
+		if _, ok := svcsMap[name]; ok {
+			ctx.WithField("service", name).Warn(
+				"registered service ignored for configured service")
+			continue
+		}
+		svcsMap[name] = reg
+	}
+	return svcsMap, nil
+}
+
+// newServiceFromConfig creates a service from one of the configurations
+// returned by servicesConfig.
+func newServiceFromConfig(
+	ctx types.Context,
+	config gofig.Config,
+	serviceName string,
+	serviceConfig interface{}) (*storageService, error) {
+
+	if reg, ok := serviceConfig.(*types.ServiceRegistration); ok {
+		return newRegisteredStorageService(ctx, config, reg)
+	}
+	return newStorageService(ctx, config, serviceName)
+}
+
+// newRegisteredStorageService creates a registered service from a copy of
+// the server's config to which the service's settings are added as if the
+// service were defined by the config.
+func newRegisteredStorageService(
+	ctx types.Context,
+	config gofig.Config,
+	reg *types.ServiceRegistration) (*storageService, error) {
+
+	name := strings.ToLower(reg.Name)
+
+	settings := map[string]interface{}{}
+	for k, v := range reg.Config {
+		settings[k] = v
+	}
+	settings["driver"] = reg.Driver
+
+	buf, err := json.Marshal(map[string]interface{}{
+		"libstorage": map[string]interface{}{
+			"server": map[string]interface{}{
+				"services": map[string]interface{}{name: settings},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	svcConfig, err := config.Copy()
+	if err != nil {
+		return nil, err
+	}
+	if err := svcConfig.ReadConfig(bytes.NewReader(buf)); err != nil {
+		return nil, err
+	}
+
+	return newStorageService(ctx, svcConfig, name)
+}
+
+func loadRegisteredServices(
+	config gofig.Config) (map[string]*types.ServiceRegistration, error) {
+
+	registered := map[string]*types.ServiceRegistration{}
+
+	path := config.GetString(types.ConfigServerRegisteredServicesFile)
+	if path == "" {
+		return registered, nil
+	}
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return registered, nil
+		}
+		return nil, err
+	}
+
+	var regs []*types.ServiceRegistration
+	if err := json.Unmarshal(buf, &regs); err != nil {
+		return nil, goof.WithFieldE(
+			"path", path, "error reading registered services", err)
+	}
+	for _, reg := range regs {
+		registered[strings.ToLower(reg.Name)] = reg
+	}
+	return registered, nil
+}
+
+func saveRegisteredServices(
+	config gofig.Config,
+	registered map[string]*types.ServiceRegistration) error {
+
+	path := config.GetString(types.ConfigServerRegisteredServicesFile)
+	if path == "" {
+		return nil
+	}
+
+	names := make([]string, 0, len(registered))
+	for name := range registered {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	regs := make([]*types.ServiceRegistration, len(names))
+	for i, name := range names {
+		regs[i] = registered[name]
+	}
+
+	buf, err := json.MarshalIndent(regs, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// write to a temporary file first so that a failed write does not leave
+	// a partial record; the file is only readable by its owner since the
+	// services' settings may include credentials
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
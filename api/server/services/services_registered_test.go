@@ -0,0 +1,80 @@
+package services
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gofigCore "github.com/akutz/gofig"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+func TestRegisteredServicesPersisted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registered")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "registered-services.json")
+	config := gofigCore.New()
+	config.Set(types.ConfigServerRegisteredServicesFile, path)
+
+	// no services are registered until the file exists
+	registered, err := loadRegisteredServices(config)
+	assert.NoError(t, err)
+	assert.Empty(t, registered)
+
+	pool := &types.ServiceRegistration{
+		Name:   "Ceph-Pool2",
+		Driver: "rbd",
+		Config: map[string]interface{}{
+			"rbd": map[string]interface{}{"defaultPool": "pool2"},
+		},
+	}
+	assert.NoError(t, saveRegisteredServices(
+		config, map[string]*types.ServiceRegistration{"ceph-pool2": pool}))
+
+	fi, err := os.Stat(path)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	// the services are keyed by their lower-case names
+	registered, err = loadRegisteredServices(config)
+	assert.NoError(t, err)
+	assert.Len(t, registered, 1)
+	if assert.Contains(t, registered, "ceph-pool2") {
+		assert.Equal(t, "rbd", registered["ceph-pool2"].Driver)
+		assert.Equal(t, pool.Config, registered["ceph-pool2"].Config)
+	}
+}
+
+func TestServicesConfigIncludesRegistered(t *testing.T) {
+	config := gofigCore.New()
+	config.Set(types.ConfigServices, map[string]interface{}{
+		"VFS": map[string]interface{}{"driver": "vfs"},
+	})
+
+	registered := &types.ServiceRegistration{Name: "pool2", Driver: "rbd"}
+	shadowed := &types.ServiceRegistration{Name: "vfs", Driver: "rbd"}
+	sc := &serviceContainer{
+		registered: map[string]*types.ServiceRegistration{
+			"pool2": registered,
+			"vfs":   shadowed,
+		},
+	}
+
+	// a configured service takes precedence over a registered service with
+	// the same name
+	svcs, err := sc.servicesConfig(context.Background(), config)
+	assert.NoError(t, err)
+	assert.Len(t, svcs, 2)
+	assert.Equal(t, registered, svcs["pool2"])
+	assert.NotEqual(t, shadowed, svcs["vfs"])
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"strings"
+
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// findSecretRefs returns a map of the config keys whose values reference
+// secrets to the references. The keys of services other than the one with
+// the specified scope are ignored, as are the integration driver's keys since
+// the integration driver resolves its own references.
+func findSecretRefs(config gofig.Config, scope string) map[string]string {
+
+	scope = strings.ToLower(scope) + "."
+	services := strings.ToLower(string(types.ConfigServices)) + "."
+	integration := strings.ToLower(types.ConfigIg) + "."
+
+	refs := map[string]string{}
+
+	var walk func(prefix string, m map[string]interface{})
+	walk = func(prefix string, m map[string]interface{}) {
+		for k, v := range m {
+			k = prefix + strings.ToLower(k)
+			if strings.HasPrefix(k, integration) {
+				continue
+			}
+			if strings.HasPrefix(k, services) &&
+				!strings.HasPrefix(k, scope) &&
+				!strings.HasPrefix(scope, k+".") {
+				continue
+			}
+			switch tv := v.(type) {
+			case map[string]interface{}:
+				walk(k+".", tv)
+			case string:
+				if _, _, _, ok := types.ParseSecretRef(tv); ok {
+					refs[k] = tv
+				}
+			}
+		}
+	}
+
+	walk("", config.AllSettings())
+	return refs
+}
+
+// resolveSecrets returns a map of the config keys whose values reference
+// secrets to the secrets' values.
+func resolveSecrets(
+	ctx types.Context,
+	config gofig.Config,
+	refs map[string]string) (map[string]string, error) {
+
+	secrets := map[string]string{}
+
+	for k, ref := range refs {
+		val, err := registry.ResolveSecret(ctx, config, ref)
+		if err != nil {
+			return nil, goof.WithFieldE(
+				"configKey", k, "error resolving secret", err)
+		}
+		secrets[k] = val
+	}
+
+	ctx.WithField("count", len(secrets)).Debug("resolved secrets")
+	return secrets, nil
+}
+
+// applySecrets replaces the config values that reference secrets with the
+// secrets' values.
+func applySecrets(config gofig.Config, secrets map[string]string) {
+	for k, v := range secrets {
+		config.Set(k, v)
+	}
+}
+
+// secretsEqual returns a flag indicating whether or not two maps of
+// resolved secrets are equal.
+func secretsEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
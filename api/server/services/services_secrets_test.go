@@ -0,0 +1,140 @@
+package services
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	gofigCore "github.com/akutz/gofig"
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+const secretsTestName = "secretstest"
+
+var (
+	secretsTestValue    string
+	secretsTestValueRWL sync.RWMutex
+)
+
+func setSecretsTestValue(v string) {
+	secretsTestValueRWL.Lock()
+	defer secretsTestValueRWL.Unlock()
+	secretsTestValue = v
+}
+
+type secretsTestProvider struct{}
+
+func (p *secretsTestProvider) Name() string {
+	return secretsTestName
+}
+
+func (p *secretsTestProvider) Init(
+	ctx types.Context, config gofig.Config) error {
+	return nil
+}
+
+func (p *secretsTestProvider) Secret(
+	ctx types.Context, path, key string) (string, error) {
+	secretsTestValueRWL.RLock()
+	defer secretsTestValueRWL.RUnlock()
+	return secretsTestValue, nil
+}
+
+// secretsTestDriver is a storage driver that is initialized with the
+// password in its config. Only the methods used by the test are implemented.
+type secretsTestDriver struct {
+	types.StorageDriver
+	password string
+	closed   chan struct{}
+}
+
+func (d *secretsTestDriver) Name() string {
+	return secretsTestName
+}
+
+func (d *secretsTestDriver) Init(
+	ctx types.Context, config gofig.Config) error {
+	d.password = config.GetString(secretsTestName + ".password")
+	if d.password == "invalid" {
+		return goof.New("invalid password")
+	}
+	return nil
+}
+
+func (d *secretsTestDriver) Close() error {
+	close(d.closed)
+	return nil
+}
+
+func init() {
+	registry.RegisterSecretsProvider(secretsTestName,
+		func() types.SecretsProvider { return &secretsTestProvider{} })
+	registry.RegisterStorageDriver(secretsTestName,
+		func() types.StorageDriver {
+			return &secretsTestDriver{closed: make(chan struct{})}
+		})
+}
+
+func TestRotateSecrets(t *testing.T) {
+	setSecretsTestValue("password1")
+
+	ref := "secret://" + secretsTestName + "/password"
+	config := gofigCore.New()
+	config.Set(types.ConfigServices, map[string]interface{}{
+		"secrets": map[string]interface{}{
+			"driver":        secretsTestName,
+			secretsTestName: map[string]interface{}{"password": ref},
+		},
+	})
+
+	ctx := context.Background()
+	s, err := newStorageService(ctx, config, "secrets")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer s.close()
+
+	d1 := s.Driver().(*secretsTestDriver)
+	assert.Equal(t, "password1", d1.password)
+
+	// the server's config, which is shared by its services, is not modified
+	assert.Equal(t, ref, config.GetString(
+		types.ConfigServices+".secrets."+secretsTestName+".password"))
+
+	// the driver is not replaced if the secrets are unchanged
+	assert.NoError(t, s.rotateSecrets(ctx, 0))
+	assert.True(t, d1 == s.Driver())
+
+	// the service is unchanged if the new driver cannot be initialized
+	setSecretsTestValue("invalid")
+	assert.Error(t, s.rotateSecrets(ctx, 0))
+	assert.True(t, d1 == s.Driver())
+	assert.Equal(t, "password1",
+		s.Config().GetString(secretsTestName+".password"))
+
+	setSecretsTestValue("password2")
+	assert.NoError(t, s.rotateSecrets(ctx, 0))
+	d2 := s.Driver().(*secretsTestDriver)
+	assert.False(t, d1 == d2)
+	assert.Equal(t, "password2", d2.password)
+	assert.Equal(t, "password2",
+		s.Config().GetString(secretsTestName+".password"))
+
+	// the previous driver is closed once the grace period elapses
+	select {
+	case <-d1.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("previous driver was not closed")
+	}
+	select {
+	case <-d2.closed:
+		t.Fatal("current driver was closed")
+	default:
+	}
+}
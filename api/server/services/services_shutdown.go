@@ -0,0 +1,227 @@
+package services
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// Drain waits for the storage services' queued and running tasks to
+// complete. If the timeout elapses first, the tasks that have not completed
+// are recorded to the interrupted tasks file so that they are reported when
+// the server is started again. A timeout of zero records the incomplete
+// tasks without waiting.
+func Drain(ctx types.Context, timeout time.Duration) error {
+
+	serverName, ok := context.Server(ctx)
+	if !ok {
+		panic("ctx is missing ServerName")
+	}
+
+	servicesByServerRWL.RLock()
+	sc := servicesByServer[serverName]
+	servicesByServerRWL.RUnlock()
+
+	if sc == nil {
+		return nil
+	}
+	return sc.drain(ctx, timeout)
+}
+
+func (sc *serviceContainer) drain(
+	ctx types.Context, timeout time.Duration) error {
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	// the tasks are listed again after the listed tasks complete in case
+	// more tasks were created while waiting
+	for waited := false; ; waited = true {
+		pending := sc.taskService.pendingStorageTasks()
+		if len(pending) == 0 {
+			if waited {
+				ctx.Info("in-flight tasks completed")
+			}
+			return nil
+		}
+		ctx.WithFields(log.Fields{
+			"tasks":   len(pending),
+			"timeout": timeout,
+		}).Info("waiting for in-flight tasks")
+
+		for _, t := range pending {
+			select {
+			case <-t.done:
+			case <-deadline.C:
+				return sc.recordInterruptedTasks(ctx)
+			}
+		}
+	}
+}
+
+// pendingStorageTasks returns the storage service tasks that have not
+// completed.
+func (s *globalTaskService) pendingStorageTasks() []*task {
+	s.RLock()
+	defer s.RUnlock()
+
+	var pending []*task
+	for _, t := range s.tasks {
+		if t.storService == nil {
+			continue
+		}
+		select {
+		case <-t.done:
+		default:
+			pending = append(pending, t)
+		}
+	}
+	return pending
+}
+
+func (sc *serviceContainer) recordInterruptedTasks(ctx types.Context) error {
+
+	pending := sc.taskService.pendingStorageTasks()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	now := time.Now().Unix()
+	tasks := make([]*types.InterruptedTask, len(pending))
+	for i, t := range pending {
+		tasks[i] = newInterruptedTask(t, now)
+		ctx.WithFields(log.Fields{
+			"taskID":   tasks[i].ID,
+			"service":  tasks[i].Service,
+			"route":    tasks[i].Route,
+			"volumeID": tasks[i].VolumeID,
+		}).Warn("interrupted task")
+	}
+
+	path := sc.config.GetString(types.ConfigServerTasksInterruptedFile)
+	buf, err := json.MarshalIndent(tasks, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// write to a temporary file first so that a failed write does not leave
+	// a partial record
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+
+	ctx.WithFields(log.Fields{
+		"tasks": len(tasks),
+		"path":  path,
+	}).Warn("recorded interrupted tasks")
+	return nil
+}
+
+func newInterruptedTask(t *task, now int64) *types.InterruptedTask {
+	it := &types.InterruptedTask{
+		ID:            t.ID,
+		Service:       t.storService.Name(),
+		State:         t.State,
+		QueueTime:     t.QueueTime,
+		InterruptTime: now,
+	}
+	if it.State == "" {
+		it.State = types.TaskStateQueued
+	}
+	if route, ok := context.Route(t.ctx); ok {
+		it.Route = route.GetName()
+	}
+	if vars, ok := t.ctx.Value(context.RouteVarsKey).(map[string]string); ok {
+		it.VolumeID = vars["volumeID"]
+		it.SnapshotID = vars["snapshotID"]
+	}
+	if iid, ok := context.InstanceID(t.ctx); ok && iid != nil {
+		it.InstanceID = iid.ID
+	}
+	return it
+}
+
+// loadInterruptedTasks publishes an event for each of the tasks that were
+// interrupted when the server was previously shut down so that clients may
+// resume or reconcile the tasks' operations. The interrupted tasks file is
+// removed once the events are published.
+func (sc *serviceContainer) loadInterruptedTasks(ctx types.Context) error {
+
+	path := sc.config.GetString(types.ConfigServerTasksInterruptedFile)
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	var tasks []*types.InterruptedTask
+	if err := json.Unmarshal(buf, &tasks); err != nil {
+		return goof.WithFieldE(
+			"path", path, "error reading interrupted tasks", err)
+	}
+
+	for _, t := range tasks {
+		ctx.WithFields(log.Fields{
+			"taskID":        t.ID,
+			"service":       t.Service,
+			"route":         t.Route,
+			"volumeID":      t.VolumeID,
+			"instanceID":    t.InstanceID,
+			"interruptTime": t.InterruptTime,
+		}).Warn("task interrupted by previous shutdown")
+
+		fields := map[string]string{
+			types.EventFieldTaskID: strconv.Itoa(t.ID),
+			types.EventFieldRoute:  t.Route,
+		}
+		if t.InstanceID != "" {
+			fields[types.EventFieldInstanceID] = t.InstanceID
+		}
+		sc.eventService.Publish(&types.Event{
+			Type:       types.EventTaskInterrupted,
+			Service:    t.Service,
+			VolumeID:   t.VolumeID,
+			SnapshotID: t.SnapshotID,
+			Fields:     fields,
+		})
+	}
+
+	sc.interruptedTasks = tasks
+	return os.Remove(path)
+}
+
+// InterruptedTasks returns the tasks that were interrupted when the server
+// was previously shut down. The tenants of the interrupted tasks are not
+// recorded, so no tasks are returned to a request scoped to a tenant.
+func InterruptedTasks(ctx types.Context) []*types.InterruptedTask {
+
+	serverName, ok := context.Server(ctx)
+	if !ok {
+		panic("ctx is missing ServerName")
+	}
+
+	if _, ok := context.Tenant(ctx); ok {
+		return nil
+	}
+
+	servicesByServerRWL.RLock()
+	defer servicesByServerRWL.RUnlock()
+	return servicesByServer[serverName].interruptedTasks
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gofigCore "github.com/akutz/gofig"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+func TestDrainRecordsInterruptedTasks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "drain")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "interrupted-tasks.json")
+	config := gofigCore.New()
+	config.Set(types.ConfigServerTasksInterruptedFile, path)
+
+	ctx := context.Background()
+	sc := &serviceContainer{
+		config:       config,
+		taskService:  &globalTaskService{tasks: map[int]*task{}},
+		eventService: &globalEventService{},
+	}
+	assert.NoError(t, sc.eventService.Init(ctx, config))
+
+	svc := &storageService{name: "vfs"}
+	completed := &task{
+		Task:        types.Task{ID: 0},
+		ctx:         ctx,
+		storService: svc,
+		done:        make(chan int),
+	}
+	close(completed.done)
+	running := &task{
+		Task: types.Task{ID: 1, State: types.TaskStateRunning},
+		ctx: ctx.WithValue(
+			context.RouteVarsKey,
+			map[string]string{"service": "vfs", "volumeID": "vfs-000"}),
+		storService: svc,
+		done:        make(chan int),
+	}
+	sc.taskService.tasks[0] = completed
+	sc.taskService.tasks[1] = running
+
+	// the running task is recorded once the timeout elapses
+	assert.NoError(t, sc.drain(ctx, 10*time.Millisecond))
+	_, err = os.Stat(path)
+	assert.NoError(t, err)
+
+	// the interrupted tasks are published as events when the server starts
+	assert.NoError(t, sc.loadInterruptedTasks(ctx))
+	if assert.Len(t, sc.interruptedTasks, 1) {
+		it := sc.interruptedTasks[0]
+		assert.Equal(t, 1, it.ID)
+		assert.Equal(t, "vfs", it.Service)
+		assert.Equal(t, "vfs-000", it.VolumeID)
+		assert.Equal(t, types.TaskState(types.TaskStateRunning), it.State)
+	}
+	events, _ := sc.eventService.Since(0)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, types.EventTaskInterrupted, events[0].Type)
+		assert.Equal(t, "vfs-000", events[0].VolumeID)
+		assert.Equal(t, "1", events[0].Fields[types.EventFieldTaskID])
+	}
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	// nothing is recorded if the tasks complete before the timeout
+	close(running.done)
+	assert.NoError(t, sc.drain(ctx, time.Second))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
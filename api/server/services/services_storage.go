@@ -1,45 +1,216 @@
 package services
 
 import (
+	"io"
+	"regexp"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
 	gofig "github.com/akutz/gofig/types"
 	"github.com/akutz/goof"
 
 	"github.com/codedellemc/libstorage/api/context"
 	"github.com/codedellemc/libstorage/api/registry"
 	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+	"github.com/codedellemc/libstorage/api/utils/metrics"
 )
 
 type storageService struct {
 	name          string
 	driver        types.StorageDriver
+	driverRWL     sync.RWMutex
 	config        gofig.Config
+	baseConfig    gofig.Config
+	scope         string
+	secretRefs    map[string]string
+	secrets       map[string]string
 	taskExecQueue chan *task
+	instances     types.Store
+	closed        chan struct{}
+	closeOnce     sync.Once
+	breaker       *circuitBreaker
+
+	namePattern    *regexp.Regexp
+	nameTemplate   string
+	nameTemplateRX *regexp.Regexp
 }
 
 func (s *storageService) Init(ctx types.Context, config gofig.Config) error {
 	s.config = config
+	s.closed = make(chan struct{})
+
+	// the references are retained since the config values are replaced
+	// with the secrets' values
+	s.secretRefs = findSecretRefs(config, s.scope)
+	secrets, err := resolveSecrets(ctx, config, s.secretRefs)
+	if err != nil {
+		return err
+	}
+	if len(secrets) > 0 {
+		if s.config, err = s.newSecretsConfig(secrets); err != nil {
+			return err
+		}
+	}
+	s.secrets = secrets
+
+	driver, err := s.initStorageDriver(ctx, s.config)
+	if err != nil {
+		return err
+	}
+	s.driver = driver
 
-	if err := s.initStorageDriver(ctx); err != nil {
+	if err := s.validateTenancy(ctx); err != nil {
 		return err
 	}
 
-	s.taskExecQueue = make(chan *task)
-	go func() {
-		for t := range s.taskExecQueue {
-			execTask(t)
+	if err := s.initVolumeNaming(ctx); err != nil {
+		return err
+	}
+
+	s.initInstanceCache(ctx)
+	s.initRecycleBin(ctx)
+	s.initCircuitBreaker(ctx)
+
+	if len(s.secrets) > 0 {
+		if dur, err := time.ParseDuration(s.config.GetString(
+			types.ConfigSecretsRefreshInterval)); err == nil && dur > 0 {
+			go s.refreshSecrets(ctx, dur)
+		}
+	}
+
+	s.initTaskWorkers(ctx)
+	return nil
+}
+
+// initTaskWorkers starts the workers that execute the service's tasks. The
+// number of workers bounds how many of the service's tasks, and thus driver
+// operations, run at once. Tasks beyond the queue's size are rejected rather
+// than queued.
+func (s *storageService) initTaskWorkers(ctx types.Context) {
+	workers := s.config.GetInt(types.ConfigServerTasksConcurrency)
+	if workers <= 0 {
+		workers = 1
+	}
+	queueSize := s.config.GetInt(types.ConfigServerTasksQueueSize)
+	if queueSize < 0 {
+		queueSize = 0
+	}
+	ctx.WithFields(log.Fields{
+		"workers":   workers,
+		"queueSize": queueSize,
+	}).Debug("configured task workers")
+
+	s.taskExecQueue = make(chan *task, queueSize)
+	for i := 0; i < workers; i++ {
+		go func() {
+			for t := range s.taskExecQueue {
+				metrics.TaskStarted(s.name)
+				execTask(t)
+				metrics.TaskCompleted(s.name)
+			}
+		}()
+	}
+}
+
+// close stops refreshing the service's secrets. The service's in-flight
+// tasks are not affected.
+func (s *storageService) close() {
+	s.closeOnce.Do(func() { close(s.closed) })
+}
+
+// refreshSecrets resolves the service's secrets at the specified interval
+// and re-initializes the service's driver when the secrets are rotated.
+func (s *storageService) refreshSecrets(
+	ctx types.Context, interval time.Duration) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.closed:
+			return
+		case <-ticker.C:
+			if err := s.rotateSecrets(ctx, interval); err != nil {
+				ctx.WithError(err).Error("error rotating secrets")
+			}
 		}
-	}()
+	}
+}
+
+// rotateSecrets resolves the service's secrets and, if they changed,
+// initializes a new driver with a new config to which the secrets are
+// applied. The service's config and driver are replaced only if the new
+// driver is initialized. Requests that are in-flight when the secrets are
+// rotated continue to use the previous driver, which is closed once the
+// provided grace period elapses.
+func (s *storageService) rotateSecrets(
+	ctx types.Context, grace time.Duration) error {
+
+	secrets, err := resolveSecrets(ctx, s.Config(), s.secretRefs)
+	if err != nil {
+		return err
+	}
+	if secretsEqual(s.secrets, secrets) {
+		return nil
+	}
+
+	config, err := s.newSecretsConfig(secrets)
+	if err != nil {
+		return err
+	}
+	driver, err := s.initStorageDriver(ctx, config)
+	if err != nil {
+		return goof.WithError(
+			"error initializing driver with rotated secrets", err)
+	}
+
+	s.driverRWL.Lock()
+	prevDriver := s.driver
+	s.driver = driver
+	s.config = config
+	s.driverRWL.Unlock()
+	s.secrets = secrets
+	ctx.Info("rotated secrets")
+
+	if c, ok := prevDriver.(io.Closer); ok {
+		time.AfterFunc(grace, func() {
+			if err := c.Close(); err != nil {
+				ctx.WithError(err).Warn("error closing previous driver")
+			}
+		})
+	}
 	return nil
 }
 
-func (s *storageService) initStorageDriver(ctx types.Context) error {
-	driverName := s.config.GetString("driver")
+// newSecretsConfig returns a copy of the service's config to which the
+// provided secrets are applied. The config from which the service's config
+// is scoped is shared with the server's other services, so it is not
+// modified.
+func (s *storageService) newSecretsConfig(
+	secrets map[string]string) (gofig.Config, error) {
+
+	config, err := s.baseConfig.Copy()
+	if err != nil {
+		return nil, err
+	}
+	config = config.Scope(s.scope)
+	applySecrets(config, secrets)
+	return config, nil
+}
+
+func (s *storageService) initStorageDriver(
+	ctx types.Context, config gofig.Config) (types.StorageDriver, error) {
+
+	driverName := config.GetString("driver")
 	if driverName == "" {
-		driverName = s.config.GetString("libstorage.driver")
+		driverName = config.GetString("libstorage.driver")
 		if driverName == "" {
-			driverName = s.config.GetString("libstorage.storage.driver")
+			driverName = config.GetString("libstorage.storage.driver")
 			if driverName == "" {
-				return goof.WithField(
+				return nil, goof.WithField(
 					"service", s.name, "error getting driver name")
 			}
 		}
@@ -48,24 +219,27 @@ func (s *storageService) initStorageDriver(ctx types.Context) error {
 	ctx.WithField("driverName", driverName).Debug("got driver name")
 	driver, err := registry.NewStorageDriver(driverName)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	ctx = ctx.WithValue(context.DriverKey, driver)
 
-	if err := driver.Init(ctx, s.config); err != nil {
-		return err
+	if err := driver.Init(ctx, config); err != nil {
+		return nil, err
 	}
 
-	s.driver = driver
-	return nil
+	return driver, nil
 }
 
 func (s *storageService) Config() gofig.Config {
+	s.driverRWL.RLock()
+	defer s.driverRWL.RUnlock()
 	return s.config
 }
 
 func (s *storageService) Driver() types.StorageDriver {
+	s.driverRWL.RLock()
+	defer s.driverRWL.RUnlock()
 	return s.driver
 }
 
@@ -74,8 +248,34 @@ func (s *storageService) TaskExecute(
 	run types.StorageTaskRunFunc,
 	schema []byte) *types.Task {
 
+	if s.breaker != nil {
+		// tasks fail fast rather than being queued while the breaker is open
+		if err := s.breaker.check(); err != nil {
+			t := newStorageServiceTask(
+				ctx,
+				func(types.Context, types.StorageService) (interface{}, error) {
+					return nil, err
+				},
+				s, schema)
+			go execTask(t)
+			return &t.Task
+		}
+		run = s.breaker.wrap(run)
+	}
+
 	t := newStorageServiceTask(ctx, run, s, schema)
-	go func() { s.taskExecQueue <- t }()
+	metrics.TaskQueued(s.name)
+	select {
+	case s.taskExecQueue <- t:
+	default:
+		metrics.TaskRejected(s.name)
+		err := utils.NewTaskQueueFullError(s.name, cap(s.taskExecQueue))
+		t.storRunFunc = func(
+			types.Context, types.StorageService) (interface{}, error) {
+			return nil, err
+		}
+		go execTask(t)
+	}
 	return &t.Task
 }
 
@@ -56,6 +56,20 @@ func newStorageServiceTask(
 	return t
 }
 
+// setProgress sets the task's progress. Reports outside of the range 0-100
+// are clamped, and a report less than the current progress is ignored.
+func (t *task) setProgress(percent int) {
+	if percent < 0 {
+		percent = 0
+	} else if percent > 100 {
+		percent = 100
+	}
+	if percent > t.Progress {
+		t.Progress = percent
+		t.ctx.WithField("progress", percent).Debug("task progress")
+	}
+}
+
 func execTask(t *task) {
 	defer func() {
 		t.CompleteTime = time.Now().Unix()
@@ -64,6 +78,9 @@ func execTask(t *task) {
 			t.State = types.TaskStateError
 		} else {
 			t.State = types.TaskStateSuccess
+			if t.Progress > 0 {
+				t.Progress = 100
+			}
 		}
 		close(t.done)
 		t.ctx.Debug("task completed")
@@ -139,10 +156,26 @@ func (s *globalTaskService) Name() string {
 
 // Tasks returns a channel on which all tasks are received.
 func (s *globalTaskService) Tasks() <-chan *types.Task {
+	return s.tasksFunc(func(t *task) bool { return true })
+}
+
+// tenantTasks returns a channel on which the tasks visible to the request's
+// tenant are received.
+func (s *globalTaskService) tenantTasks(ctx types.Context) <-chan *types.Task {
+	return s.tasksFunc(func(t *task) bool { return isTenantTask(ctx, t) })
+}
+
+// tasksFunc returns a channel on which the tasks for which the provided
+// function returns true are received.
+func (s *globalTaskService) tasksFunc(
+	include func(t *task) bool) <-chan *types.Task {
+
 	tasks := []*types.Task{}
 	s.RLock()
 	for _, v := range s.tasks {
-		tasks = append(tasks, &v.Task)
+		if include(v) {
+			tasks = append(tasks, &v.Task)
+		}
 	}
 	s.RUnlock()
 
@@ -173,8 +206,10 @@ func (s *globalTaskService) taskTrack(ctx types.Context) *task {
 			QueueTime: now,
 		},
 		resultSchemaValidationEnabled: s.resultSchemaValidationEnabled,
-		ctx: ctx.WithValue(context.TaskKey, fmt.Sprintf("%d", taskID)),
 	}
+	t.ctx = ctx.WithValue(
+		context.TaskKey, fmt.Sprintf("%d", taskID)).WithValue(
+		context.TaskProgressKey, t.setProgress)
 
 	s.Lock()
 	s.tasks[taskID] = t
@@ -204,6 +239,19 @@ func (s *globalTaskService) TaskInspect(taskID int) *types.Task {
 	return nil
 }
 
+// tenantTaskInspect returns the task with the specified ID if it is visible
+// to the request's tenant.
+func (s *globalTaskService) tenantTaskInspect(
+	ctx types.Context, taskID int) *types.Task {
+
+	s.RLock()
+	defer s.RUnlock()
+	if t, ok := s.tasks[taskID]; ok && isTenantTask(ctx, t) {
+		return &t.Task
+	}
+	return nil
+}
+
 // TaskWait blocks until the specified task is completed.
 func (s *globalTaskService) TaskWait(taskID int) {
 	<-s.TaskWaitC(taskID)
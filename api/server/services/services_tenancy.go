@@ -0,0 +1,145 @@
+package services
+
+import (
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// validateTenancy returns an error if tenancy is enabled and the service's
+// driver does not stamp the volumes it creates, since the volumes of such a
+// driver cannot be scoped to tenants.
+func (s *storageService) validateTenancy(ctx types.Context) error {
+	if !s.config.GetBool(types.ConfigServerTenancyEnabled) {
+		return nil
+	}
+	if stampsVolumeMetadata(ctx, s) {
+		return nil
+	}
+	return goof.WithField(
+		"service", s.name,
+		"tenancy requires a driver that stamps volume metadata")
+}
+
+// stampsVolumeMetadata returns a flag indicating whether or not the service's
+// driver stamps the volumes it creates with metadata.
+func stampsVolumeMetadata(ctx types.Context, svc types.StorageService) bool {
+	d, ok := svc.Driver().(types.ProvidesVolumeMetadata)
+	return ok && d.StampsVolumeMetadata(ctx)
+}
+
+// IsTenantVolume returns a flag indicating whether or not the volume is
+// visible to and mutable by the request's tenant. All volumes are visible if
+// the request is not scoped to a tenant, which is the case when tenancy is
+// disabled or the client is an administrator. Otherwise only the volumes
+// stamped with the request's tenant are visible. No volumes are visible if
+// the service's driver does not stamp the volumes it creates.
+func IsTenantVolume(
+	ctx types.Context,
+	svc types.StorageService,
+	v *types.Volume) bool {
+
+	tenant, ok := context.Tenant(ctx)
+	if !ok {
+		return true
+	}
+	if !stampsVolumeMetadata(ctx, svc) {
+		return false
+	}
+	return v.Fields[types.VolumeMetadataTenant] == tenant
+}
+
+// IsTenantSnapshot returns a flag indicating whether or not the snapshot is
+// visible to and mutable by the request's tenant. A snapshot stamped with a
+// tenant is visible only to that tenant. Otherwise the snapshot is visible
+// only if the volume from which it was taken is visible.
+func IsTenantSnapshot(
+	ctx types.Context,
+	svc types.StorageService,
+	s *types.Snapshot) bool {
+
+	return newSnapshotTenancy(ctx, svc).isTenantSnapshot(s)
+}
+
+// TenantSnapshots returns the snapshots that are visible to the request's
+// tenant.
+func TenantSnapshots(
+	ctx types.Context,
+	svc types.StorageService,
+	snapshots []*types.Snapshot) []*types.Snapshot {
+
+	if _, ok := context.Tenant(ctx); !ok {
+		return snapshots
+	}
+	st := newSnapshotTenancy(ctx, svc)
+	visible := []*types.Snapshot{}
+	for _, s := range snapshots {
+		if st.isTenantSnapshot(s) {
+			visible = append(visible, s)
+		}
+	}
+	return visible
+}
+
+// snapshotTenancy determines the visibility of snapshots, caching the
+// visibility of the volumes from which they were taken.
+type snapshotTenancy struct {
+	ctx     types.Context
+	svc     types.StorageService
+	volumes map[string]bool
+}
+
+func newSnapshotTenancy(
+	ctx types.Context, svc types.StorageService) *snapshotTenancy {
+
+	return &snapshotTenancy{ctx: ctx, svc: svc, volumes: map[string]bool{}}
+}
+
+func (st *snapshotTenancy) isTenantSnapshot(s *types.Snapshot) bool {
+	tenant, ok := context.Tenant(st.ctx)
+	if !ok {
+		return true
+	}
+	if !stampsVolumeMetadata(st.ctx, st.svc) {
+		return false
+	}
+	if v, ok := s.Fields[types.VolumeMetadataTenant]; ok {
+		return v == tenant
+	}
+	if s.VolumeID == "" {
+		return false
+	}
+	if visible, ok := st.volumes[s.VolumeID]; ok {
+		return visible
+	}
+	v, err := st.svc.Driver().VolumeInspect(
+		st.ctx, s.VolumeID, &types.VolumeInspectOpts{Opts: utils.NewStore()})
+	visible := err == nil && v != nil && IsTenantVolume(st.ctx, st.svc, v)
+	st.volumes[s.VolumeID] = visible
+	return visible
+}
+
+// isTenantTask returns a flag indicating whether or not the task is visible
+// to the request's tenant. A task is visible only to the tenant of the
+// request that created it.
+func isTenantTask(ctx types.Context, t *task) bool {
+	tenant, ok := context.Tenant(ctx)
+	if !ok {
+		return true
+	}
+	taskTenant, ok := context.Tenant(t.ctx)
+	return ok && taskTenant == tenant
+}
+
+// isTenantEvent returns a flag indicating whether or not the event is
+// visible to the request's tenant. An event is visible only to the tenant of
+// the request that published it.
+func isTenantEvent(ctx types.Context, ev *types.Event) bool {
+	tenant, ok := context.Tenant(ctx)
+	if !ok {
+		return true
+	}
+	return ev.Fields[types.EventFieldTenant] == tenant
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"testing"
+
+	gofigCore "github.com/akutz/gofig"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+type tenantDriver struct {
+	stampingDriver
+	volumes map[string]*types.Volume
+}
+
+func (d *tenantDriver) VolumeInspect(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeInspectOpts) (*types.Volume, error) {
+
+	if v, ok := d.volumes[volumeID]; ok {
+		return v, nil
+	}
+	return nil, utils.NewNotFoundError(volumeID)
+}
+
+func TestIsTenantVolume(t *testing.T) {
+	s := &storageService{name: "ebs", driver: &stampingDriver{}}
+	team1 := &types.Volume{
+		Fields: map[string]string{types.VolumeMetadataTenant: "team1"},
+	}
+	team2 := &types.Volume{
+		Fields: map[string]string{types.VolumeMetadataTenant: "team2"},
+	}
+	unowned := &types.Volume{}
+
+	// requests not scoped to a tenant see all volumes
+	ctx := context.Background()
+	assert.True(t, IsTenantVolume(ctx, s, team1))
+	assert.True(t, IsTenantVolume(ctx, s, team2))
+	assert.True(t, IsTenantVolume(ctx, s, unowned))
+
+	ctx = ctx.WithValue(context.TenantKey, "team1")
+	assert.True(t, IsTenantVolume(ctx, s, team1))
+	assert.False(t, IsTenantVolume(ctx, s, team2))
+	assert.False(t, IsTenantVolume(ctx, s, unowned))
+
+	md, _ := context.VolumeMetadata(WithVolumeMetadata(ctx, s))
+	assert.Equal(t, "team1", md[types.VolumeMetadataTenant])
+
+	// no volumes are visible to a tenant if the driver does not stamp them
+	s.driver = nil
+	assert.False(t, IsTenantVolume(ctx, s, team1))
+	assert.True(t, IsTenantVolume(context.Background(), s, team1))
+}
+
+func TestValidateTenancy(t *testing.T) {
+	config := gofigCore.New()
+	ctx := context.Background()
+	s := &storageService{name: "ebs", config: config}
+
+	assert.NoError(t, s.validateTenancy(ctx))
+
+	// tenancy cannot be enabled for a driver that does not stamp volumes
+	config.Set(types.ConfigServerTenancyEnabled, true)
+	assert.Error(t, s.validateTenancy(ctx))
+
+	s.driver = &stampingDriver{}
+	assert.NoError(t, s.validateTenancy(ctx))
+}
+
+func TestTenantSnapshots(t *testing.T) {
+	s := &storageService{name: "ebs", driver: &tenantDriver{
+		volumes: map[string]*types.Volume{
+			"vol-1": {
+				ID: "vol-1",
+				Fields: map[string]string{
+					types.VolumeMetadataTenant: "team1",
+				},
+			},
+			"vol-2": {
+				ID: "vol-2",
+				Fields: map[string]string{
+					types.VolumeMetadataTenant: "team2",
+				},
+			},
+		},
+	}}
+
+	snaps := []*types.Snapshot{
+		// stamped with a tenant
+		{
+			ID:       "snap-1",
+			VolumeID: "vol-2",
+			Fields: map[string]string{
+				types.VolumeMetadataTenant: "team1",
+			},
+		},
+		// scoped by the volume from which it was taken
+		{ID: "snap-2", VolumeID: "vol-1"},
+		{ID: "snap-3", VolumeID: "vol-2"},
+		// taken from a volume that no longer exists
+		{ID: "snap-4", VolumeID: "vol-3"},
+		{ID: "snap-5"},
+	}
+
+	ctx := context.Background()
+	assert.Len(t, TenantSnapshots(ctx, s, snaps), len(snaps))
+	assert.True(t, IsTenantSnapshot(ctx, s, snaps[4]))
+
+	ctx = ctx.WithValue(context.TenantKey, "team1")
+	visible := TenantSnapshots(ctx, s, snaps)
+	if assert.Len(t, visible, 2) {
+		assert.Equal(t, "snap-1", visible[0].ID)
+		assert.Equal(t, "snap-2", visible[1].ID)
+	}
+	assert.True(t, IsTenantSnapshot(ctx, s, snaps[1]))
+	assert.False(t, IsTenantSnapshot(ctx, s, snaps[2]))
+
+	ctx = ctx.WithValue(context.TenantKey, "team2")
+	visible = TenantSnapshots(ctx, s, snaps)
+	if assert.Len(t, visible, 1) {
+		assert.Equal(t, "snap-3", visible[0].ID)
+	}
+}
+
+func TestTenantTasksAndEvents(t *testing.T) {
+	admin := context.Background()
+	team1 := admin.WithValue(context.TenantKey, "team1")
+	team2 := admin.WithValue(context.TenantKey, "team2")
+
+	task1 := &task{ctx: team1}
+	task2 := &task{ctx: admin}
+	assert.True(t, isTenantTask(admin, task1))
+	assert.True(t, isTenantTask(admin, task2))
+	assert.True(t, isTenantTask(team1, task1))
+	assert.False(t, isTenantTask(team2, task1))
+	assert.False(t, isTenantTask(team1, task2))
+
+	ev1 := &types.Event{
+		Service: "vfs",
+		Fields:  map[string]string{types.EventFieldTenant: "team1"},
+	}
+	ev2 := &types.Event{Service: "vfs"}
+	events := []*types.Event{ev1, ev2}
+	assert.Len(t, serviceEvents(admin, events, "vfs"), 2)
+	assert.Equal(t, []*types.Event{ev1}, serviceEvents(team1, events, "vfs"))
+	assert.Empty(t, serviceEvents(team2, events, "vfs"))
+}
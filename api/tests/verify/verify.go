@@ -0,0 +1,329 @@
+/*
+Package verify exercises the lifecycle of a volume against a storage service
+backed by a live storage platform and reports the conformance of the
+service's driver.
+
+The lifecycle is create, list, inspect, attach, mount, snapshot, unmount,
+detach, and remove. Each step is recorded in a report along with the driver's
+capabilities so that driver authors can see which operations their driver
+supports and catch regressions in the operations it used to support.
+*/
+package verify
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// StepStatus is the outcome of a step of the lifecycle.
+type StepStatus string
+
+const (
+	// StepPassed indicates the step succeeded.
+	StepPassed StepStatus = "passed"
+
+	// StepFailed indicates the step failed.
+	StepFailed StepStatus = "failed"
+
+	// StepSkipped indicates the step was not executed, either because it
+	// was disabled or because a step on which it depends failed.
+	StepSkipped StepStatus = "skipped"
+
+	// StepUnsupported indicates the driver reports that it does not support
+	// the step.
+	StepUnsupported StepStatus = "unsupported"
+)
+
+// Step is the result of a step of the lifecycle.
+type Step struct {
+	Name     string     `json:"name"`
+	Status   StepStatus `json:"status"`
+	Duration string     `json:"duration,omitempty"`
+	Error    string     `json:"error,omitempty"`
+}
+
+// Report is the conformance report of a storage service's driver.
+type Report struct {
+	Service      string                     `json:"service"`
+	Driver       string                     `json:"driver,omitempty"`
+	Time         int64                      `json:"time"`
+	Capabilities *types.StorageCapabilities `json:"capabilities,omitempty"`
+	Steps        []*Step                    `json:"steps"`
+}
+
+// Failed returns a flag indicating whether or not any step failed.
+func (r *Report) Failed() bool {
+	for _, s := range r.Steps {
+		if s.Status == StepFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteJSON writes the report to the writer as JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	buf, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", buf)
+	return err
+}
+
+// Options are the options with which the lifecycle is exercised.
+type Options struct {
+
+	// Service is the name of the storage service.
+	Service string
+
+	// VolumeName is the name of the volume that is created. A name with the
+	// current time is used if the name is empty.
+	VolumeName string
+
+	// VolumeSize is the size of the volume that is created in GiB. The
+	// driver's default size is used if the size is zero.
+	VolumeSize int64
+
+	// Mount indicates whether or not the volume is mounted and unmounted.
+	// Mounting the volume requires the client to run with the privileges to
+	// format and mount file systems.
+	Mount bool
+}
+
+type verifier struct {
+	ctx    types.Context
+	client types.Client
+	opts   *Options
+	report *Report
+	failed bool
+}
+
+// Run exercises the lifecycle of a volume against the storage service with
+// the client, which must be an integration client for the attach, mount,
+// unmount, and detach steps to succeed. The volume is removed even if a step
+// fails.
+func Run(ctx types.Context, client types.Client, opts *Options) *Report {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx = ctx.WithValue(context.ServiceKey, opts.Service)
+
+	v := &verifier{
+		ctx:    ctx,
+		client: client,
+		opts:   opts,
+		report: &Report{
+			Service: opts.Service,
+			Time:    time.Now().Unix(),
+		},
+	}
+	v.run()
+	return v.report
+}
+
+// step executes the step unless a previous step failed. A step function
+// returns types.ErrNotImplemented if the driver does not support the step.
+func (v *verifier) step(name string, f func() error) bool {
+	s := &Step{Name: name}
+	v.report.Steps = append(v.report.Steps, s)
+
+	if v.failed {
+		s.Status = StepSkipped
+		return false
+	}
+
+	start := time.Now()
+	err := f()
+	s.Duration = time.Since(start).String()
+
+	switch {
+	case err == nil:
+		s.Status = StepPassed
+		return true
+	case err == types.ErrNotImplemented:
+		s.Status = StepUnsupported
+	default:
+		s.Status = StepFailed
+		s.Error = err.Error()
+		v.failed = true
+	}
+	return false
+}
+
+func (v *verifier) skip(name string) {
+	v.report.Steps = append(
+		v.report.Steps, &Step{Name: name, Status: StepSkipped})
+}
+
+func (v *verifier) unsupported(name string) {
+	v.report.Steps = append(
+		v.report.Steps, &Step{Name: name, Status: StepUnsupported})
+}
+
+func (v *verifier) run() {
+
+	var (
+		ctx        = v.ctx
+		api        = v.client.API()
+		service    = v.opts.Service
+		vol        *types.Volume
+		snap       *types.Snapshot
+		attached   bool
+		mounted    bool
+		detached   bool
+		volumeName = v.opts.VolumeName
+	)
+
+	if volumeName == "" {
+		volumeName = fmt.Sprintf("verify-%d", time.Now().Unix())
+	}
+
+	v.step("inspectService", func() error {
+		si, err := api.ServiceInspect(ctx, service)
+		if err != nil {
+			return err
+		}
+		if si.Driver != nil {
+			v.report.Driver = si.Driver.Name
+		}
+		return nil
+	})
+
+	v.step("capabilities", func() error {
+		caps, err := api.ServiceCapabilities(ctx, service)
+		if err != nil {
+			return err
+		}
+		v.report.Capabilities = caps
+		return nil
+	})
+
+	v.step("create", func() error {
+		req := &types.VolumeCreateRequest{Name: volumeName}
+		if v.opts.VolumeSize > 0 {
+			req.Size = &v.opts.VolumeSize
+		}
+		var err error
+		vol, err = api.VolumeCreate(ctx, service, req)
+		return err
+	})
+
+	// the volume is removed even if a step after its creation fails
+	defer func() {
+		if vol == nil {
+			v.skip("remove")
+			return
+		}
+		if mounted && !detached {
+			v.client.Integration().Unmount(ctx, vol.ID, "", utils.NewStore())
+		} else if attached && !detached {
+			v.client.Storage().VolumeDetach(
+				ctx, vol.ID, &types.VolumeDetachOpts{Opts: utils.NewStore()})
+		}
+		v.failed = false
+		v.step("remove", func() error {
+			return api.VolumeRemove(ctx, service, vol.ID, true)
+		})
+	}()
+
+	v.step("list", func() error {
+		vols, err := api.VolumesByService(ctx, service, 0)
+		if err != nil {
+			return err
+		}
+		if _, ok := vols[vol.ID]; !ok {
+			return utils.NewVolumeNotFoundError(vol.ID)
+		}
+		return nil
+	})
+
+	v.step("inspect", func() error {
+		iv, err := api.VolumeInspect(ctx, service, vol.ID, 0)
+		if err != nil {
+			return err
+		}
+		if iv.ID != vol.ID || iv.Name != volumeName {
+			return fmt.Errorf(
+				"inspected volume %s (%s), expected %s (%s)",
+				iv.ID, iv.Name, vol.ID, volumeName)
+		}
+		return nil
+	})
+
+	attached = v.step("attach", func() error {
+		_, _, err := v.client.Storage().VolumeAttach(
+			ctx, vol.ID, &types.VolumeAttachOpts{Opts: utils.NewStore()})
+		return err
+	})
+
+	if v.opts.Mount {
+		mounted = v.step("mount", func() error {
+			_, _, err := v.client.Integration().Mount(
+				ctx, vol.ID, "",
+				&types.VolumeMountOpts{Opts: utils.NewStore()})
+			return err
+		})
+	} else {
+		v.skip("mount")
+	}
+
+	if v.report.Capabilities != nil && !v.report.Capabilities.Snapshots {
+		v.unsupported("snapshot")
+		v.unsupported("snapshotRemove")
+	} else {
+		v.step("snapshot", func() error {
+			var err error
+			snap, err = api.VolumeSnapshot(
+				ctx, service, vol.ID,
+				&types.VolumeSnapshotRequest{
+					SnapshotName: volumeName + "-snap",
+				})
+			return err
+		})
+		if snap != nil {
+			v.step("snapshotRemove", func() error {
+				return api.SnapshotRemove(ctx, service, snap.ID)
+			})
+		} else {
+			v.skip("snapshotRemove")
+		}
+	}
+
+	if mounted {
+		// the integration driver detaches the volume it unmounts
+		v.step("unmount", func() error {
+			_, err := v.client.Integration().Unmount(
+				ctx, vol.ID, "", utils.NewStore())
+			return err
+		})
+	} else {
+		v.skip("unmount")
+	}
+
+	if attached {
+		detached = v.step("detach", func() error {
+			iv, err := api.VolumeInspect(
+				ctx, service, vol.ID,
+				types.VolAttReqForInstance)
+			if err != nil {
+				return err
+			}
+			if len(iv.Attachments) > 0 {
+				_, err = v.client.Storage().VolumeDetach(
+					ctx, vol.ID,
+					&types.VolumeDetachOpts{Opts: utils.NewStore()})
+			}
+			return err
+		})
+	} else {
+		v.skip("detach")
+	}
+}
@@ -0,0 +1,71 @@
+// +build driver_verify
+
+package verify
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/codedellemc/libstorage/libstoragetest"
+)
+
+// TestDriver exercises the lifecycle of a volume against the live storage
+// platform of the driver named by LIBSTORAGE_VERIFY_DRIVER:
+//
+//  LIBSTORAGE_VERIFY_DRIVER - the name of the storage driver
+//  LIBSTORAGE_VERIFY_CONFIG - the path to a YAML file that configures the
+//                             driver
+//  LIBSTORAGE_VERIFY_SIZE   - the size of the volume in GiB
+//  LIBSTORAGE_VERIFY_MOUNT  - whether or not to mount the volume; mounting
+//                             requires root privileges
+//  LIBSTORAGE_VERIFY_REPORT - the path to which the JSON conformance report
+//                             is written
+func TestDriver(t *testing.T) {
+
+	driver := os.Getenv("LIBSTORAGE_VERIFY_DRIVER")
+	if driver == "" {
+		t.Skip("LIBSTORAGE_VERIFY_DRIVER is not set")
+	}
+
+	opts := &libstoragetest.Options{Driver: driver}
+	if p := os.Getenv("LIBSTORAGE_VERIFY_CONFIG"); p != "" {
+		buf, err := ioutil.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts.Config = buf
+	}
+
+	s := libstoragetest.New(t, opts)
+	defer s.Close()
+
+	size, _ := strconv.ParseInt(os.Getenv("LIBSTORAGE_VERIFY_SIZE"), 10, 64)
+	mount, _ := strconv.ParseBool(os.Getenv("LIBSTORAGE_VERIFY_MOUNT"))
+
+	report := Run(nil, s.Client, &Options{
+		Service:    driver,
+		VolumeSize: size,
+		Mount:      mount,
+	})
+
+	for _, step := range report.Steps {
+		t.Logf("%-16s %-12s %s", step.Name, step.Status, step.Error)
+	}
+
+	if p := os.Getenv("LIBSTORAGE_VERIFY_REPORT"); p != "" {
+		f, err := os.Create(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if err := report.WriteJSON(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if report.Failed() {
+		t.Fail()
+	}
+}
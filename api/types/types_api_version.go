@@ -0,0 +1,82 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// APIVersion is a version of the libStorage API.
+type APIVersion int
+
+const (
+	// APIVersion1 is the original version of the API. Requests made to the
+	// unversioned routes are handled with version 1 unless the client
+	// negotiates another version with the Accept header.
+	APIVersion1 APIVersion = 1
+
+	// APIVersion2 is the version of the API that replaces the flat error
+	// objects with a structured error model and adds pagination and label
+	// selectors to the volume and snapshot lists.
+	APIVersion2 APIVersion = 2
+
+	// LatestAPIVersion is the latest version of the API.
+	LatestAPIVersion = APIVersion2
+)
+
+// APIVersions are the supported versions of the API.
+var APIVersions = []APIVersion{APIVersion1, APIVersion2}
+
+const mediaTypePrefix = "application/vnd.libstorage."
+
+// String returns the version's string representation, ex. v2.
+func (v APIVersion) String() string {
+	return fmt.Sprintf("v%d", int(v))
+}
+
+// MediaType returns the media type with which a client requests the version
+// with the Accept header, ex. application/vnd.libstorage.v2+json.
+func (v APIVersion) MediaType() string {
+	return fmt.Sprintf("%s%s+json", mediaTypePrefix, v)
+}
+
+// Supported returns a flag indicating whether or not the version is
+// supported.
+func (v APIVersion) Supported() bool {
+	for _, sv := range APIVersions {
+		if v == sv {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseAPIVersion parses a version such as v2 or 2. Zero is returned if the
+// text is not a valid version.
+func ParseAPIVersion(text string) APIVersion {
+	text = strings.TrimPrefix(strings.ToLower(text), "v")
+	i, err := strconv.Atoi(text)
+	if err != nil || i <= 0 {
+		return 0
+	}
+	return APIVersion(i)
+}
+
+// ParseAcceptAPIVersion returns the version requested by the value of an
+// Accept header. Zero is returned if the header does not request a libStorage
+// media type.
+func ParseAcceptAPIVersion(accept string) APIVersion {
+	for _, mt := range strings.Split(accept, ",") {
+		if i := strings.Index(mt, ";"); i >= 0 {
+			mt = mt[:i]
+		}
+		mt = strings.ToLower(strings.TrimSpace(mt))
+		if !strings.HasPrefix(mt, mediaTypePrefix) {
+			continue
+		}
+		mt = strings.TrimPrefix(mt, mediaTypePrefix)
+		mt = strings.TrimSuffix(mt, "+json")
+		return ParseAPIVersion(mt)
+	}
+	return 0
+}
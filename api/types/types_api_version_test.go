@@ -0,0 +1,28 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAPIVersion(t *testing.T) {
+	assert.Equal(t, APIVersion2, ParseAPIVersion("v2"))
+	assert.Equal(t, APIVersion1, ParseAPIVersion("1"))
+	assert.Equal(t, APIVersion(0), ParseAPIVersion("v0"))
+	assert.Equal(t, APIVersion(0), ParseAPIVersion("latest"))
+	assert.Equal(t, "v2", APIVersion2.String())
+	assert.True(t, APIVersion1.Supported())
+	assert.False(t, APIVersion(3).Supported())
+}
+
+func TestParseAcceptAPIVersion(t *testing.T) {
+	assert.Equal(t, APIVersion2, ParseAcceptAPIVersion(
+		APIVersion2.MediaType()))
+	assert.Equal(t, APIVersion2, ParseAcceptAPIVersion(
+		"text/plain, application/vnd.libstorage.v2+json; q=0.9"))
+	assert.Equal(t, APIVersion(3), ParseAcceptAPIVersion(
+		"application/vnd.libstorage.v3+json"))
+	assert.Equal(t, APIVersion(0), ParseAcceptAPIVersion("application/json"))
+	assert.Equal(t, APIVersion(0), ParseAcceptAPIVersion(""))
+}
@@ -3,6 +3,7 @@ package types
 import (
 	"io"
 	"strings"
+	"time"
 )
 
 // ClientType is a client's type.
@@ -103,11 +104,25 @@ type APIClient interface {
 	// ServiceInspect returns information about a service.
 	ServiceInspect(ctx Context, name string) (*ServiceInfo, error)
 
+	// ServiceCapabilities returns the capabilities of a service's driver.
+	ServiceCapabilities(
+		ctx Context, name string) (*StorageCapabilities, error)
+
 	// Volumes returns a list of all Volumes for all Services.
 	Volumes(
 		ctx Context,
 		attachments VolumeAttachmentsTypes) (ServiceVolumeMap, error)
 
+	// AllVolumes returns a list of all Volumes for all Services, including
+	// the volumes that are not stamped with libStorage metadata.
+	AllVolumes(
+		ctx Context,
+		attachments VolumeAttachmentsTypes) (ServiceVolumeMap, error)
+
+	// RecycledVolumes returns the volumes in the recycle bins of all
+	// Services.
+	RecycledVolumes(ctx Context) (ServiceVolumeMap, error)
+
 	// VolumesByService returns a list of all Volumes for a service.
 	VolumesByService(
 		ctx Context,
@@ -120,6 +135,17 @@ type APIClient interface {
 		service, volumeID string,
 		attachments VolumeAttachmentsTypes) (*Volume, error)
 
+	// VolumeStats gets the usage and IO statistics of a single volume.
+	VolumeStats(
+		ctx Context,
+		service, volumeID string) (*VolumeStats, error)
+
+	// VolumeEvents gets the history of a single volume's events, oldest
+	// first.
+	VolumeEvents(
+		ctx Context,
+		service, volumeID string) ([]*Event, error)
+
 	// VolumeCreate creates a single volume.
 	VolumeCreate(
 		ctx Context,
@@ -138,12 +164,58 @@ type APIClient interface {
 		service, volumeID string,
 		request *VolumeCopyRequest) (*Volume, error)
 
+	// VolumeImport imports a single volume that was created outside of
+	// libStorage.
+	VolumeImport(
+		ctx Context,
+		service string,
+		request *VolumeImportRequest) (*Volume, error)
+
+	// VolumeUnmanage releases a single volume from libStorage management
+	// without removing it.
+	VolumeUnmanage(
+		ctx Context,
+		service, volumeID string,
+		force bool) error
+
+	// VolumeResize changes the size of a single volume to the new size in
+	// GiB.
+	VolumeResize(
+		ctx Context,
+		service, volumeID string,
+		size int64) (*Volume, error)
+
+	// VolumeRetype changes the type of a single volume. The migration
+	// policy is passed to the storage platform if it is not empty.
+	VolumeRetype(
+		ctx Context,
+		service, volumeID, volumeType, migrationPolicy string) (*Volume, error)
+
 	// VolumeRemove removes a single volume.
 	VolumeRemove(
 		ctx Context,
 		service, volumeID string,
 		force bool) error
 
+	// VolumePurge removes a single volume without moving it to the recycle
+	// bin, or permanently removes a volume from the recycle bin.
+	VolumePurge(
+		ctx Context,
+		service, volumeID string) error
+
+	// VolumeUndelete restores a single volume from the recycle bin.
+	VolumeUndelete(
+		ctx Context,
+		service, volumeID string) (*Volume, error)
+
+	// VolumeForceDetach forcefully detaches a single volume from the
+	// specified instance, or from all of the instances to which it is
+	// attached if the instance ID is empty. Only administrators may
+	// forcefully detach volumes.
+	VolumeForceDetach(
+		ctx Context,
+		service, volumeID, instanceID string) (*Volume, error)
+
 	// VolumeAttach attaches a single volume.
 	VolumeAttach(
 		ctx Context,
@@ -158,6 +230,14 @@ type APIClient interface {
 		volumeID string,
 		request *VolumeDetachRequest) (*Volume, error)
 
+	// VolumeMountReport reports whether or not a volume attached to the
+	// client's instance was mounted.
+	VolumeMountReport(
+		ctx Context,
+		service string,
+		volumeID string,
+		request *VolumeMountReportRequest) error
+
 	// VolumeDetachAll attaches all volumes from all
 	VolumeDetachAll(
 		ctx Context,
@@ -176,6 +256,12 @@ type APIClient interface {
 		volumeID string,
 		request *VolumeSnapshotRequest) (*Snapshot, error)
 
+	// SnapshotGroupCreate snapshots a group of volumes.
+	SnapshotGroupCreate(
+		ctx Context,
+		service string,
+		request *SnapshotGroupCreateRequest) (*SnapshotGroup, error)
+
 	// Snapshots returns a list of all Snapshots for all
 	Snapshots(ctx Context) (ServiceSnapshotMap, error)
 
@@ -199,6 +285,24 @@ type APIClient interface {
 		service, snapshotID string,
 		request *SnapshotCopyRequest) (*Snapshot, error)
 
+	// Events returns the service's events with an ID greater than the
+	// provided ID, waiting up to the specified duration for an event to
+	// occur if there are no such events.
+	Events(
+		ctx Context,
+		service string,
+		since int64,
+		wait time.Duration) ([]*Event, error)
+
+	// Health returns the health of the server and its storage services.
+	Health(ctx Context) (*Health, error)
+
+	// Tasks returns the server's tasks keyed by their IDs.
+	Tasks(ctx Context) (map[string]*Task, error)
+
+	// TaskInspect returns information about a task.
+	TaskInspect(ctx Context, taskID int) (*Task, error)
+
 	// Executors returns information about the executors.
 	Executors(
 		ctx Context) (map[string]*ExecutorInfo, error)
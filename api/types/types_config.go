@@ -57,9 +57,36 @@ const (
 	// ConfigLogHTTPResponses is a config key.
 	ConfigLogHTTPResponses = ConfigLogging + ".httpResponses"
 
+	// ConfigLogFormat is a config key.
+	ConfigLogFormat = ConfigLogging + ".format"
+
+	// ConfigLogLevels is a config key.
+	ConfigLogLevels = ConfigLogging + ".levels"
+
+	// ConfigLogSlowOpThreshold is a config key.
+	ConfigLogSlowOpThreshold = ConfigLogging + ".slowOpThreshold"
+
 	// ConfigHTTPDisableKeepAlive is a config key.
 	ConfigHTTPDisableKeepAlive = ConfigRoot + ".http.disableKeepAlive"
 
+	// ConfigHTTPMaxIdleConns is a config key.
+	ConfigHTTPMaxIdleConns = ConfigRoot + ".http.maxIdleConns"
+
+	// ConfigHTTPMaxIdleConnsPerHost is a config key.
+	ConfigHTTPMaxIdleConnsPerHost = ConfigRoot + ".http.maxIdleConnsPerHost"
+
+	// ConfigHTTPIdleConnTimeout is a config key.
+	ConfigHTTPIdleConnTimeout = ConfigRoot + ".http.idleConnTimeout"
+
+	// ConfigHTTPKeepAlive is a config key.
+	ConfigHTTPKeepAlive = ConfigRoot + ".http.keepAlive"
+
+	// ConfigHTTPLocalSocket is a config key.
+	ConfigHTTPLocalSocket = ConfigRoot + ".http.localSocket"
+
+	// ConfigHTTPLocalSocketMode is a config key.
+	ConfigHTTPLocalSocketMode = ConfigRoot + ".http.localSocketMode"
+
 	// ConfigHTTPWriteTimeout is a config key.
 	ConfigHTTPWriteTimeout = ConfigRoot + ".http.writeTimeout"
 
@@ -84,9 +111,99 @@ const (
 	// ConfigExecutorNoDownload is a config key.
 	ConfigExecutorNoDownload = ConfigRoot + ".executor.disableDownload"
 
+	// ConfigExecutorEndpoint is a config key.
+	ConfigExecutorEndpoint = ConfigRoot + ".executor.endpoint"
+
+	// ConfigExecutorVerifyKey is a config key.
+	ConfigExecutorVerifyKey = ConfigRoot + ".executor.verifyKey"
+
+	// ConfigExecutorVerifyRequired is a config key.
+	ConfigExecutorVerifyRequired = ConfigRoot + ".executor.verifyRequired"
+
+	// ConfigExecutorDescribeDevices is a config key.
+	ConfigExecutorDescribeDevices = ConfigRoot + ".executor.describeDevices"
+
+	// ConfigExecutorFreezeTimeout is a config key.
+	ConfigExecutorFreezeTimeout = ConfigRoot + ".executor.freezeTimeout"
+
+	// ConfigExecutorInstanceID is a config key.
+	ConfigExecutorInstanceID = ConfigRoot + ".executor.instanceID"
+
+	// ConfigExecutorInstanceIDResolvers is a config key.
+	ConfigExecutorInstanceIDResolvers = ConfigExecutorInstanceID + ".resolvers"
+
+	// ConfigExecutorInstanceIDStatic is a config key.
+	ConfigExecutorInstanceIDStatic = ConfigExecutorInstanceID + ".static"
+
+	// ConfigExecutorInstanceIDCommand is a config key.
+	ConfigExecutorInstanceIDCommand = ConfigExecutorInstanceID + ".command"
+
+	// ConfigExecutorMultipath is a config key.
+	ConfigExecutorMultipath = ConfigRoot + ".executor.multipath"
+
+	// ConfigExecutorMultipathEnabled is a config key.
+	ConfigExecutorMultipathEnabled = ConfigExecutorMultipath + ".enabled"
+
+	// ConfigExecutorMultipathTimeout is a config key.
+	ConfigExecutorMultipathTimeout = ConfigExecutorMultipath + ".timeout"
+
 	// ConfigClientCacheInstanceID is a config key.
 	ConfigClientCacheInstanceID = ConfigClient + ".cache.instanceID"
 
+	// ConfigClientCacheInstance is a config key.
+	ConfigClientCacheInstance = ConfigClient + ".cache.instance"
+
+	// ConfigClientCacheVolumes is a config key.
+	ConfigClientCacheVolumes = ConfigClient + ".cache.volumes"
+
+	// ConfigClientCacheVolumesTTL is a config key.
+	ConfigClientCacheVolumesTTL = ConfigClient + ".cache.volumesTTL"
+
+	// ConfigClientCacheETags is a config key.
+	ConfigClientCacheETags = ConfigClient + ".cache.etags"
+
+	// ConfigClientAdminToken is a config key.
+	ConfigClientAdminToken = ConfigClient + ".adminToken"
+
+	// ConfigClientForceDetachCleanup is a config key.
+	ConfigClientForceDetachCleanup = ConfigClient + ".forceDetach.cleanup"
+
+	// ConfigClientRetry is a config key.
+	ConfigClientRetry = ConfigClient + ".retry"
+
+	// ConfigClientRetryMaxAttempts is a config key.
+	ConfigClientRetryMaxAttempts = ConfigClientRetry + ".maxAttempts"
+
+	// ConfigClientRetryInitialBackoff is a config key.
+	ConfigClientRetryInitialBackoff = ConfigClientRetry + ".initialBackoff"
+
+	// ConfigClientRetryMaxBackoff is a config key.
+	ConfigClientRetryMaxBackoff = ConfigClientRetry + ".maxBackoff"
+
+	// ConfigClientRetryBudget is a config key.
+	ConfigClientRetryBudget = ConfigClientRetry + ".budget"
+
+	// ConfigTracing is a config key.
+	ConfigTracing = ConfigRoot + ".tracing"
+
+	// ConfigTracingExporter is a config key.
+	ConfigTracingExporter = ConfigTracing + ".exporter"
+
+	// ConfigTracingJaegerAgent is a config key.
+	ConfigTracingJaegerAgent = ConfigTracing + ".jaeger.agent"
+
+	// ConfigTracingSamplerType is a config key.
+	ConfigTracingSamplerType = ConfigTracing + ".sampler.type"
+
+	// ConfigTracingSamplerParam is a config key.
+	ConfigTracingSamplerParam = ConfigTracing + ".sampler.param"
+
+	// ConfigSecrets is a config key.
+	ConfigSecrets = ConfigRoot + ".secrets"
+
+	// ConfigSecretsRefreshInterval is a config key.
+	ConfigSecretsRefreshInterval = ConfigSecrets + ".refreshInterval"
+
 	// ConfigTLS is a config key.
 	ConfigTLS = ConfigRoot + ".tls"
 
@@ -111,12 +228,51 @@ const (
 	// ConfigTLSKeyFile is a config key.
 	ConfigTLSKeyFile = ConfigTLS + ".keyFile"
 
+	// ConfigTLSReloadInterval is a config key.
+	ConfigTLSReloadInterval = ConfigTLS + ".reloadInterval"
+
+	// ConfigTLSSPIFFE is a config key.
+	ConfigTLSSPIFFE = ConfigTLS + ".spiffe"
+
+	// ConfigTLSSPIFFETrustDomain is a config key.
+	ConfigTLSSPIFFETrustDomain = ConfigTLSSPIFFE + ".trustDomain"
+
+	// ConfigTLSSPIFFEIDs is a config key.
+	ConfigTLSSPIFFEIDs = ConfigTLSSPIFFE + ".ids"
+
+	// ConfigTLSSPIFFEWorkloadAPI is a config key.
+	ConfigTLSSPIFFEWorkloadAPI = ConfigTLSSPIFFE + ".workloadAPI"
+
+	// ConfigExec is a config key.
+	ConfigExec = ConfigRoot + ".exec"
+
+	// ConfigExecPaths is a config key.
+	ConfigExecPaths = ConfigExec + ".paths"
+
+	// ConfigExecSearchPath is a config key.
+	ConfigExecSearchPath = ConfigExec + ".searchPath"
+
+	// ConfigExecEnv is a config key.
+	ConfigExecEnv = ConfigExec + ".env"
+
+	// ConfigExecAllow is a config key.
+	ConfigExecAllow = ConfigExec + ".allow"
+
+	// ConfigExecChroot is a config key.
+	ConfigExecChroot = ConfigExec + ".chroot"
+
+	// ConfigExecNamespaces is a config key.
+	ConfigExecNamespaces = ConfigExec + ".namespaces"
+
 	// ConfigDeviceAttachTimeout is a config key.
 	ConfigDeviceAttachTimeout = ConfigRoot + ".device.attachTimeout"
 
 	// ConfigDeviceScanType is a config key.
 	ConfigDeviceScanType = ConfigRoot + ".device.scanType"
 
+	// ConfigDeviceStableNames is a config key.
+	ConfigDeviceStableNames = ConfigRoot + ".device.stableNames"
+
 	// ConfigSchemaResponseValidationEnabled is a config key.
 	ConfigSchemaResponseValidationEnabled = ConfigRoot +
 		".schema.responseValidationEnabled"
@@ -129,4 +285,144 @@ const (
 
 	// ConfigServerTasksLogTimeout is a config key.
 	ConfigServerTasksLogTimeout = ConfigServerTasks + ".logTimeout"
+
+	// ConfigServerTasksConcurrency is a config key.
+	ConfigServerTasksConcurrency = ConfigServerTasks + ".concurrency"
+
+	// ConfigServerTasksQueueSize is a config key.
+	ConfigServerTasksQueueSize = ConfigServerTasks + ".queueSize"
+
+	// ConfigServerTasksInterruptedFile is a config key.
+	ConfigServerTasksInterruptedFile = ConfigServerTasks + ".interruptedFile"
+
+	// ConfigServerHealthTimeout is a config key.
+	ConfigServerHealthTimeout = ConfigServer + ".healthTimeout"
+
+	// ConfigServerRequestTimeout is a config key.
+	ConfigServerRequestTimeout = ConfigServer + ".requestTimeout"
+
+	// ConfigServerReloadInterval is a config key.
+	ConfigServerReloadInterval = ConfigServer + ".reloadInterval"
+
+	// ConfigServerShutdownTimeout is a config key.
+	ConfigServerShutdownTimeout = ConfigServer + ".shutdownTimeout"
+
+	// ConfigServerCircuitBreakerThreshold is a config key.
+	ConfigServerCircuitBreakerThreshold = ConfigServer +
+		".circuitBreaker.threshold"
+
+	// ConfigServerCircuitBreakerCooldown is a config key.
+	ConfigServerCircuitBreakerCooldown = ConfigServer +
+		".circuitBreaker.cooldown"
+
+	// ConfigServerAdminToken is a config key.
+	ConfigServerAdminToken = ConfigServer + ".adminToken"
+
+	// ConfigServerBindInstanceIDs is a config key.
+	ConfigServerBindInstanceIDs = ConfigServer + ".bindInstanceIDs"
+
+	// ConfigServerRegisteredServicesFile is a config key.
+	ConfigServerRegisteredServicesFile = ConfigServer +
+		".registeredServicesFile"
+
+	// ConfigServerInstanceIDBindingsFile is a config key.
+	ConfigServerInstanceIDBindingsFile = ConfigServer +
+		".instanceIDBindingsFile"
+
+	// ConfigServerEvents is a config key.
+	ConfigServerEvents = ConfigServer + ".events"
+
+	// ConfigServerEventsMax is a config key.
+	ConfigServerEventsMax = ConfigServerEvents + ".max"
+
+	// ConfigServerEventsVolumeHistoryMax is a config key.
+	ConfigServerEventsVolumeHistoryMax = ConfigServerEvents +
+		".volumeHistoryMax"
+
+	// ConfigServerEventsVolumeHistoryVolumes is a config key.
+	ConfigServerEventsVolumeHistoryVolumes = ConfigServerEvents +
+		".volumeHistoryVolumes"
+
+	// ConfigServerEventsVolumeHistoryFile is a config key.
+	ConfigServerEventsVolumeHistoryFile = ConfigServerEvents +
+		".volumeHistoryFile"
+
+	// ConfigServerPolicy is a config key.
+	ConfigServerPolicy = ConfigServer + ".policy"
+
+	// ConfigServerPolicyURL is a config key.
+	ConfigServerPolicyURL = ConfigServerPolicy + ".url"
+
+	// ConfigServerPolicyTimeout is a config key.
+	ConfigServerPolicyTimeout = ConfigServerPolicy + ".timeout"
+
+	// ConfigServerPolicyFailOpen is a config key.
+	ConfigServerPolicyFailOpen = ConfigServerPolicy + ".failOpen"
+
+	// ConfigServerTenancy is a config key.
+	ConfigServerTenancy = ConfigServer + ".tenancy"
+
+	// ConfigServerTenancyEnabled is a config key.
+	ConfigServerTenancyEnabled = ConfigServerTenancy + ".enabled"
+
+	// ConfigServerTenancyPattern is a config key.
+	ConfigServerTenancyPattern = ConfigServerTenancy + ".pattern"
+
+	// ConfigServerTenancyAdmins is a config key.
+	ConfigServerTenancyAdmins = ConfigServerTenancy + ".admins"
+
+	// ConfigServerMountTokenTTL is a config key.
+	ConfigServerMountTokenTTL = ConfigServer + ".mountToken.ttl"
+
+	// ConfigServerMountTokenFile is a config key.
+	ConfigServerMountTokenFile = ConfigServer + ".mountToken.file"
+
+	// ConfigServerCacheInstance is a config key.
+	ConfigServerCacheInstance = ConfigServer + ".cache.instance"
+
+	// ConfigServerNextDeviceLease is a config key.
+	ConfigServerNextDeviceLease = ConfigServer + ".nextDevice.lease"
+
+	// ConfigServerVolumeRecycle is a config key.
+	ConfigServerVolumeRecycle = ConfigServer + ".volume.recycle"
+
+	// ConfigServerVolumeRecycleRetention is a config key.
+	ConfigServerVolumeRecycleRetention = ConfigServerVolumeRecycle +
+		".retention"
+
+	// ConfigServerVolumeQuota is a config key.
+	ConfigServerVolumeQuota = ConfigServer + ".volume.quota"
+
+	// ConfigServerVolumeManagedOnly is a config key.
+	ConfigServerVolumeManagedOnly = ConfigServer + ".volume.managedOnly"
+
+	// ConfigServerVolumeNaming is a config key.
+	ConfigServerVolumeNaming = ConfigServer + ".volume.naming"
+
+	// ConfigServerVolumeNamingPattern is a config key.
+	ConfigServerVolumeNamingPattern = ConfigServerVolumeNaming + ".pattern"
+
+	// ConfigServerVolumeNamingTemplate is a config key.
+	ConfigServerVolumeNamingTemplate = ConfigServerVolumeNaming + ".template"
+
+	// ConfigServerTopology is a config key.
+	ConfigServerTopology = ConfigServer + ".topology"
+
+	// ConfigServerTopologyValidate is a config key.
+	ConfigServerTopologyValidate = ConfigServerTopology + ".validate"
+
+	// ConfigServerValidateConfig is a config key.
+	ConfigServerValidateConfig = ConfigServer + ".validateConfig"
+
+	// ConfigServerMaxRequestBodySize is a config key.
+	ConfigServerMaxRequestBodySize = ConfigServer + ".maxRequestBodySize"
+
+	// ConfigServerCompression is a config key.
+	ConfigServerCompression = ConfigServer + ".compression"
+
+	// ConfigServerCompressionEnabled is a config key.
+	ConfigServerCompressionEnabled = ConfigServerCompression + ".enabled"
+
+	// ConfigServerCompressionMinSize is a config key.
+	ConfigServerCompressionMinSize = ConfigServerCompression + ".minSize"
 )
@@ -22,6 +22,33 @@ const (
 	//ConfigIgVolOpsMountRootPath is a config key.
 	ConfigIgVolOpsMountRootPath = ConfigIgVolOpsMount + ".rootPath"
 
+	//ConfigIgVolOpsMountEncrypt is a config key.
+	ConfigIgVolOpsMountEncrypt = ConfigIgVolOpsMount + ".encrypt"
+
+	//ConfigIgVolOpsMountEncryptionKey is a config key.
+	ConfigIgVolOpsMountEncryptionKey = ConfigIgVolOpsMount + ".encryptionKey"
+
+	//ConfigIgVolOpsMountOptions is a config key.
+	ConfigIgVolOpsMountOptions = ConfigIgVolOpsMount + ".options"
+
+	//ConfigIgVolOpsMountSELinuxLabel is a config key.
+	ConfigIgVolOpsMountSELinuxLabel = ConfigIgVolOpsMount + ".selinuxLabel"
+
+	//ConfigIgVolOpsMountFsck is a config key.
+	ConfigIgVolOpsMountFsck = ConfigIgVolOpsMount + ".fsck"
+
+	//ConfigIgVolOpsMountFsckPolicy is a config key.
+	ConfigIgVolOpsMountFsckPolicy = ConfigIgVolOpsMountFsck + ".policy"
+
+	//ConfigIgVolOpsMountFsckAction is a config key.
+	ConfigIgVolOpsMountFsckAction = ConfigIgVolOpsMountFsck + ".action"
+
+	//ConfigIgVolOpsMountGrowFS is a config key.
+	ConfigIgVolOpsMountGrowFS = ConfigIgVolOpsMount + ".growFS"
+
+	//ConfigIgVolOpsMountJournal is a config key.
+	ConfigIgVolOpsMountJournal = ConfigIgVolOpsMount + ".journal"
+
 	//ConfigIgVolOpsUnmount is a config key.
 	ConfigIgVolOpsUnmount = ConfigIgVolOps + ".unmount"
 
@@ -40,6 +67,15 @@ const (
 	// ConfigIgVolOpsPathCacheAsync is a config key.
 	ConfigIgVolOpsPathCacheAsync = ConfigIgVolOpsPathCache + ".async"
 
+	// ConfigIgVolOpsReconcile is a config key.
+	ConfigIgVolOpsReconcile = ConfigIgVolOps + ".reconcile"
+
+	// ConfigIgVolOpsReconcileInterval is a config key.
+	ConfigIgVolOpsReconcileInterval = ConfigIgVolOpsReconcile + ".interval"
+
+	// ConfigIgVolOpsReconcileDryRun is a config key.
+	ConfigIgVolOpsReconcileDryRun = ConfigIgVolOpsReconcile + ".dryRun"
+
 	// ConfigIgVolOpsCreate is a config key.
 	ConfigIgVolOpsCreate = ConfigIgVolOps + ".create"
 
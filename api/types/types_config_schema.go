@@ -0,0 +1,56 @@
+package types
+
+// ConfigKeyType is the type of a config key's value.
+type ConfigKeyType int
+
+const (
+	// ConfigKeyString is a key with a scalar value that is read as a string.
+	ConfigKeyString ConfigKeyType = iota
+
+	// ConfigKeyInt is a key with an integer value.
+	ConfigKeyInt
+
+	// ConfigKeyBool is a key with a boolean value.
+	ConfigKeyBool
+
+	// ConfigKeyAny is a key with a value of any type. The keys nested under
+	// the key are not validated.
+	ConfigKeyAny
+)
+
+// String returns the string-representation of the ConfigKeyType.
+func (t ConfigKeyType) String() string {
+	switch t {
+	case ConfigKeyString:
+		return "string"
+	case ConfigKeyInt:
+		return "integer"
+	case ConfigKeyBool:
+		return "boolean"
+	}
+	return "any"
+}
+
+// ConfigSchema describes the config keys of libStorage or a driver so that a
+// configuration may be validated before it is used.
+type ConfigSchema struct {
+
+	// Name is the name of the schema.
+	Name string
+
+	// Namespaces are the keys, such as rbd, under which every key must be
+	// described by the schema. Keys under a namespace that are not described
+	// by the schema are unknown.
+	Namespaces []string
+
+	// Keys are the described config keys and the types of their values.
+	Keys map[string]ConfigKeyType
+
+	// StorageDriver is the name of the storage driver whose keys the schema
+	// describes, if any.
+	StorageDriver string
+
+	// Required are the keys that must have values in the config of each
+	// storage service that uses the storage driver.
+	Required []string
+}
@@ -11,11 +11,29 @@ import (
 type DeviceScanType int
 
 const (
+	// LSXExitCodeError is the exit code the executor binary uses to indicate
+	// a function failed with an error that has no more specific exit code.
+	LSXExitCodeError = 1
+
 	// LSXExitCodeNotImplemented is the exit code the executor binary uses to
 	// indicate a function is not implemented for a given storage driver on the
 	// current system.
 	LSXExitCodeNotImplemented = 2
 
+	// LSXExitCodeNotFound is the exit code the executor binary uses to
+	// indicate a function failed because a resource, such as a device or a
+	// mount path, does not exist.
+	LSXExitCodeNotFound = 3
+
+	// LSXExitCodePermissionDenied is the exit code the executor binary uses
+	// to indicate a function failed because the executor lacks the
+	// privileges the function requires.
+	LSXExitCodePermissionDenied = 4
+
+	// LSXExitCodeUsage is the exit code the executor binary uses to indicate
+	// the command or its arguments are invalid.
+	LSXExitCodeUsage = 64
+
 	// LSXExitCodeTimedOut is the exit code the executor binary uses to indicate
 	// a function timed out.
 	LSXExitCodeTimedOut = 255
@@ -27,6 +45,11 @@ const (
 	// map.
 	LSXCmdLocalDevices = "localDevices"
 
+	// LSXArgDescribeDevices is the optional argument to the LocalDevices
+	// command that causes the executor to describe the mapped devices, such
+	// as their sizes and file system types.
+	LSXArgDescribeDevices = "describe"
+
 	// LSXCmdNextDevice is the command to execute to get the next device.
 	LSXCmdNextDevice = "nextDevice"
 
@@ -46,6 +69,16 @@ const (
 
 	// LSXCmdMounts is the command for getting a list of mount info objects.
 	LSXCmdMounts = "mounts"
+
+	// LSXCmdFreeze is the command for freezing a mounted file system.
+	LSXCmdFreeze = "freeze"
+
+	// LSXCmdThaw is the command for thawing a frozen file system.
+	LSXCmdThaw = "thaw"
+
+	// LSXCmdFileSystemUsage is the command for getting the capacity usage of
+	// a mounted file system.
+	LSXCmdFileSystemUsage = "fsUsage"
 )
 
 const (
@@ -221,6 +254,29 @@ type StorageExecutorCLI interface {
 	Supported(
 		ctx Context,
 		opts Store) (LSXSupportedOp, error)
+
+	// FreezeFileSystem freezes the file system mounted at the provided path
+	// until it is thawed. If the timeout is greater than zero the file system
+	// is thawed by the executor once the timeout elapses should the caller
+	// fail to thaw it.
+	FreezeFileSystem(
+		ctx Context,
+		mountPoint string,
+		timeout time.Duration,
+		opts Store) error
+
+	// ThawFileSystem thaws the file system mounted at the provided path.
+	ThawFileSystem(
+		ctx Context,
+		mountPoint string,
+		opts Store) error
+
+	// FileSystemUsage returns the capacity usage of the file system mounted
+	// at the provided path.
+	FileSystemUsage(
+		ctx Context,
+		mountPoint string,
+		opts Store) (*VolumeUsage, error)
 }
 
 // LSXSupportedOp is a bit for the mask returned from an executor's Supported
@@ -1,5 +1,90 @@
 package types
 
+const (
+	// VolumeFieldFSType is the name of the volume field in which an
+	// integration driver records the type of file system with which a
+	// volume should be formatted.
+	VolumeFieldFSType = "fsType"
+
+	// VolumeFieldMkfsOpts is the name of the volume field in which an
+	// integration driver records the options passed to mkfs when a volume
+	// is formatted.
+	VolumeFieldMkfsOpts = "mkfsOpts"
+
+	// VolumeFieldMountOpts is the name of the volume field in which an
+	// integration driver records the options with which a volume should be
+	// mounted.
+	VolumeFieldMountOpts = "mountOpts"
+
+	// VolumeFieldUID is the name of the volume field in which an
+	// integration driver records the ID of the user that owns the root of
+	// the volume's file system.
+	VolumeFieldUID = "uid"
+
+	// VolumeFieldGID is the name of the volume field in which an
+	// integration driver records the ID of the group that owns the root of
+	// the volume's file system.
+	VolumeFieldGID = "gid"
+
+	// VolumeFieldMode is the name of the volume field in which an
+	// integration driver records the octal permissions of the root of the
+	// volume's file system.
+	VolumeFieldMode = "mode"
+
+	// VolumeFieldSELinuxLabel is the name of the volume field in which an
+	// integration driver records the SELinux context with which a volume is
+	// mounted.
+	VolumeFieldSELinuxLabel = "selinuxLabel"
+
+	// VolumeFieldAccessMode is the name of the volume field in which an
+	// integration driver records how a volume is accessed, either
+	// VolumeAccessModeFileSystem or VolumeAccessModeRaw.
+	VolumeFieldAccessMode = "accessMode"
+
+	// VolumeAccessModeFileSystem indicates a volume is formatted and mounted
+	// and accessed through its file system.
+	VolumeAccessModeFileSystem = "filesystem"
+
+	// VolumeAccessModeRaw indicates a volume is not formatted or mounted and
+	// is accessed directly as a block device.
+	VolumeAccessModeRaw = "raw"
+
+	// VolumeFieldMirror is the name of the volume field in which a storage
+	// driver records the name of the local mirror into which the devices of
+	// a mirrored volume are assembled.
+	VolumeFieldMirror = "mirror"
+
+	// AttachmentFieldMirrorDevices is the name of the attachment field in
+	// which a storage driver records the comma-separated names of the
+	// devices of a mirrored volume's attachment.
+	AttachmentFieldMirrorDevices = "mirrorDevices"
+
+	// AttachmentFieldFsType is the name of the attachment field in which a
+	// storage driver records the type of file system with which the
+	// attachment's device is mounted, ex. efs. The device is mounted by the
+	// file system's mount helper and is neither checked nor formatted.
+	AttachmentFieldFsType = "fsType"
+
+	// AttachmentFieldMountOptions is the name of the attachment field in
+	// which a storage driver records the options with which the attachment's
+	// device is mounted by the mount helper of its file system.
+	AttachmentFieldMountOptions = "mountOptions"
+)
+
+// IntegrationVolumeFields are the names of the fields that an integration
+// driver records with the volumes it creates and uses when the volumes are
+// mounted. The fields may only be recorded with the volumes of a storage
+// driver whose capabilities include VolumeFields.
+var IntegrationVolumeFields = []string{
+	VolumeFieldFSType,
+	VolumeFieldMkfsOpts,
+	VolumeFieldMountOpts,
+	VolumeFieldAccessMode,
+	VolumeFieldUID,
+	VolumeFieldGID,
+	VolumeFieldMode,
+}
+
 // NewIntegrationDriver is a function that constructs a new IntegrationDriver.
 type NewIntegrationDriver func() IntegrationDriver
 
@@ -8,9 +93,67 @@ type VolumeMountOpts struct {
 	OverwriteFS bool
 	NewFSType   string
 	Preempt     bool
-	Opts        Store
+
+	// NewFSOpts are additional options passed to mkfs when the volume is
+	// formatted.
+	NewFSOpts []string
+
+	// MountOptions is a comma-separated list of options with which the
+	// volume is mounted, ex. "noatime,discard". The options are merged with
+	// the configured defaults and the options recorded with the volume.
+	MountOptions string
+
+	// MountLabel is the SELinux context with which the volume is mounted,
+	// such as the private label of the container that uses the volume.
+	MountLabel string
+
+	// Encrypted requests that the integration driver encrypt the volume
+	// with dm-crypt, even if the volume is encrypted natively by its storage
+	// platform.
+	Encrypted bool
+
+	// ReadOnly requests that the volume be attached and mounted read-only.
+	// A volume mounted read-only is neither formatted nor checked.
+	ReadOnly bool
+
+	// AccessMode is VolumeAccessModeRaw to access the volume as a block
+	// device without formatting or mounting it, even if the volume's access
+	// mode is not recorded with the volume.
+	AccessMode string
+
+	// UID, GID, and Mode are the owner and octal permissions applied to the
+	// root of the volume's file system after it is mounted. They override
+	// the owner and permissions recorded with the volume.
+	UID  string
+	GID  string
+	Mode string
+
+	Opts Store
 }
 
+// MountStep is a step of the workflow that mounts a volume. An integration
+// driver records each step in the mount journal before executing it so that
+// a workflow interrupted by a crash can be rolled back.
+type MountStep string
+
+const (
+	// MountStepAttach attaches the volume to the instance. The step's value
+	// is the volume's ID.
+	MountStepAttach MountStep = "attach"
+
+	// MountStepWaitForDevice waits for the attached volume's device to
+	// appear.
+	MountStepWaitForDevice MountStep = "waitForDevice"
+
+	// MountStepFormat formats the volume's device. The step's value is the
+	// path of the device.
+	MountStepFormat MountStep = "format"
+
+	// MountStepMount mounts the volume's device. The step's value is the
+	// mount point.
+	MountStepMount MountStep = "mount"
+)
+
 // VolumeMapping is a volume's name and the path to which it is mounted.
 type VolumeMapping interface {
 	// VolumeName returns the volume's name.
@@ -23,6 +166,81 @@ type VolumeMapping interface {
 	Status() map[string]interface{}
 }
 
+// OrphanType is the type of a resource that is no longer in use by any of
+// the consumers of an integration driver.
+type OrphanType string
+
+const (
+	// OrphanAttachment is a volume that is attached to the instance but is
+	// neither mounted nor in use.
+	OrphanAttachment OrphanType = "attachment"
+
+	// OrphanMount is a volume mount for a volume that is no longer attached
+	// to the instance.
+	OrphanMount OrphanType = "mount"
+
+	// OrphanDevice is a local device that is mapped to a volume for which
+	// the storage driver reports no attachment to the instance.
+	OrphanDevice OrphanType = "device"
+)
+
+// Orphan is a resource found by an integration driver's reconciler that is
+// no longer in use.
+type Orphan struct {
+	// Type is the type of the orphaned resource.
+	Type OrphanType `json:"type" yaml:"type"`
+
+	// VolumeID is the ID of the volume to which the resource belongs.
+	VolumeID string `json:"volumeID,omitempty" yaml:"volumeID,omitempty"`
+
+	// VolumeName is the name of the volume to which the resource belongs.
+	VolumeName string `json:"volumeName,omitempty" yaml:"volumeName,omitempty"`
+
+	// DeviceName is the local device of the resource.
+	DeviceName string `json:"deviceName,omitempty" yaml:"deviceName,omitempty"`
+
+	// MountPoint is the mount point of the resource.
+	MountPoint string `json:"mountPoint,omitempty" yaml:"mountPoint,omitempty"`
+
+	// Reconciled is a flag indicating whether or not the resource was
+	// detached or unmounted.
+	Reconciled bool `json:"reconciled,omitempty" yaml:"reconciled,omitempty"`
+
+	// Error is the error that occurred reconciling the resource.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// ReconcileReport is the result of reconciling the volumes attached to an
+// instance with their use by an integration driver's consumers.
+type ReconcileReport struct {
+	// Time is the time stamp (epoch) at which the reconciliation began.
+	Time int64 `json:"time" yaml:"time"`
+
+	// DryRun is a flag indicating whether or not the orphans were only
+	// reported.
+	DryRun bool `json:"dryRun,omitempty" yaml:"dryRun,omitempty"`
+
+	// Orphans are the orphaned resources.
+	Orphans []*Orphan `json:"orphans,omitempty" yaml:"orphans,omitempty"`
+}
+
+// IntegrationDriverReconciler is implemented by integration drivers that
+// detach and unmount the volumes that are no longer in use by the driver's
+// consumers.
+type IntegrationDriverReconciler interface {
+	// Reconcile finds the orphaned attachments, mounts, and devices on the
+	// instance and reconciles them unless dryRun is true.
+	Reconcile(ctx Context, dryRun bool) (*ReconcileReport, error)
+}
+
+// IntegrationDriverFileSystemGrower is implemented by integration drivers
+// that grow the file systems of the volumes they mount.
+type IntegrationDriverFileSystemGrower interface {
+	// GrowFileSystem grows the file system of the volume to fill its device
+	// if the volume is mounted on the instance.
+	GrowFileSystem(ctx Context, volumeID string) error
+}
+
 // IntegrationDriverManager is the management wrapper for an IntegrationDriver.
 type IntegrationDriverManager interface {
 	IntegrationDriver
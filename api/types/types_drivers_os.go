@@ -7,14 +7,27 @@ type NewOSDriver func() OSDriver
 type DeviceMountOpts struct {
 	MountOptions string
 	MountLabel   string
-	Opts         Store
+
+	// ReadOnly mounts the device read-only regardless of the mount options.
+	ReadOnly bool
+
+	// FsType is the type of the device's file system. The device is mounted
+	// with the file system's mount helper when set, ex. mount.efs.
+	FsType string
+
+	Opts Store
 }
 
 // DeviceFormatOpts are options when formatting a device.
 type DeviceFormatOpts struct {
 	NewFSType   string
 	OverwriteFS bool
-	Opts        Store
+
+	// NewFSOpts are additional options passed to mkfs when creating the
+	// file system, ex. "-I 512" to set the inode size of an ext4 file system.
+	NewFSOpts []string
+
+	Opts Store
 }
 
 // OSDriverManager is the management wrapper for an OSDriver.
@@ -60,3 +73,25 @@ type OSDriver interface {
 		deviceName string,
 		opts *DeviceFormatOpts) error
 }
+
+// ProvidesDeviceMirroring is a type that is able to assemble local devices
+// into a software mirror, ex. a Linux md RAID1 device.
+type ProvidesDeviceMirroring interface {
+
+	// MirrorAssemble assembles the devices into the mirror with the name and
+	// returns the path of the mirror's device. A mirror that is already
+	// assembled is returned unchanged, and a mirror whose devices have never
+	// been mirrored is created.
+	MirrorAssemble(
+		ctx Context,
+		name string,
+		devices []string,
+		opts Store) (string, error)
+
+	// MirrorStop stops the mirror with the name, releasing its devices. It
+	// is not an error to stop a mirror that is not assembled.
+	MirrorStop(
+		ctx Context,
+		name string,
+		opts Store) error
+}
@@ -0,0 +1,37 @@
+package types
+
+import "strings"
+
+// NewSecretsProvider is a function that constructs a new SecretsProvider.
+type NewSecretsProvider func() SecretsProvider
+
+// SecretScheme is the URL scheme used to reference a secret from a config
+// value, ex. secret://vault/secret/aws#accessKey.
+const SecretScheme = "secret"
+
+// SecretsProvider is the interface implemented by types that provide
+// secrets, such as the credentials used by storage drivers.
+type SecretsProvider interface {
+	Driver
+
+	// Secret returns the value of the secret at the specified path. If a
+	// key is specified then the value of the key in the secret is returned.
+	Secret(ctx Context, path, key string) (string, error)
+}
+
+// ParseSecretRef parses a reference to a secret in the format
+// secret://provider/path#key. The key is optional.
+func ParseSecretRef(v string) (provider, path, key string, ok bool) {
+	prefix := SecretScheme + "://"
+	if !strings.HasPrefix(v, prefix) {
+		return "", "", "", false
+	}
+	v = v[len(prefix):]
+	if i := strings.LastIndex(v, "#"); i >= 0 {
+		v, key = v[:i], v[i+1:]
+	}
+	if i := strings.Index(v, "/"); i >= 0 {
+		v, path = v[:i], v[i+1:]
+	}
+	return v, path, key, v != ""
+}
@@ -0,0 +1,38 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSecretRef(t *testing.T) {
+	tests := []struct {
+		ref                 string
+		provider, path, key string
+		ok                  bool
+	}{
+		{"secret://vault/secret/aws#accessKey",
+			"vault", "secret/aws", "accessKey", true},
+		{"secret://env/AWS_SECRET_ACCESS_KEY",
+			"env", "AWS_SECRET_ACCESS_KEY", "", true},
+		{"secret://file/etc/ceph/keyring",
+			"file", "etc/ceph/keyring", "", true},
+		{"secret://file/etc/libstorage/creds.json#password",
+			"file", "etc/libstorage/creds.json", "password", true},
+		{"secret://", "", "", "", false},
+		{"https://vault/secret/aws#accessKey", "", "", "", false},
+		{"plaintext", "", "", "", false},
+	}
+
+	for _, tt := range tests {
+		provider, path, key, ok := ParseSecretRef(tt.ref)
+		assert.Equal(t, tt.ok, ok, tt.ref)
+		if !tt.ok {
+			continue
+		}
+		assert.Equal(t, tt.provider, provider, tt.ref)
+		assert.Equal(t, tt.path, path, tt.ref)
+		assert.Equal(t, tt.key, key, tt.ref)
+	}
+}
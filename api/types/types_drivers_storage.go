@@ -1,6 +1,9 @@
 package types
 
-import "strconv"
+import (
+	"strconv"
+	"strings"
+)
 
 // LibStorageDriverName is the name of the libStorage storage driver.
 const LibStorageDriverName = "libstorage"
@@ -229,14 +232,56 @@ type VolumeCreateOpts struct {
 	Type             *string
 	Encrypted        *bool
 	EncryptionKey    *string
-	Opts             Store
+
+	// Thin requests a thin-provisioned volume when true and a thick
+	// volume when false. The driver's default is used when nil.
+	Thin *bool
+
+	Opts Store
+}
+
+// AttachAccessMode is the mode in which a volume is attached.
+type AttachAccessMode string
+
+const (
+	// AttachAccessModeReadWrite attaches a volume for reading and writing.
+	// It is the default access mode.
+	AttachAccessModeReadWrite AttachAccessMode = "rw"
+
+	// AttachAccessModeReadOnly attaches a volume for reading only.
+	AttachAccessModeReadOnly AttachAccessMode = "ro"
+)
+
+// ParseAttachAccessMode parses an access mode. An empty string is parsed as
+// AttachAccessModeReadWrite, and a flag indicating whether or not the string
+// is a valid access mode is returned.
+func ParseAttachAccessMode(s string) (AttachAccessMode, bool) {
+	switch AttachAccessMode(strings.ToLower(s)) {
+	case "", AttachAccessModeReadWrite:
+		return AttachAccessModeReadWrite, true
+	case AttachAccessModeReadOnly:
+		return AttachAccessModeReadOnly, true
+	}
+	return "", false
+}
+
+// ReadOnly returns a flag indicating whether or not the access mode is
+// read-only.
+func (m AttachAccessMode) ReadOnly() bool {
+	return m == AttachAccessModeReadOnly
 }
 
 // VolumeAttachOpts are options for attaching a volume.
 type VolumeAttachOpts struct {
 	NextDevice *string
 	Force      bool
-	Opts       Store
+
+	// AccessMode is the mode in which the volume is attached. Drivers that
+	// cannot attach volumes read-only attach them for reading and writing,
+	// and the volume is mounted read-only by the integration driver instead.
+	AccessMode AttachAccessMode
+
+	Opts Store
 }
 
 // VolumeDetachOpts are options for detaching a volume.
@@ -366,6 +411,264 @@ type StorageDriver interface {
 		opts Store) error
 }
 
+// ProvidesStorageCapabilities is a type that reports the optional features
+// supported by a storage driver.
+type ProvidesStorageCapabilities interface {
+
+	// Capabilities returns the driver's capabilities.
+	Capabilities(
+		ctx Context) (*StorageCapabilities, error)
+}
+
+// ProvidesValidation is a type that is able to validate mutating operations
+// without performing them.
+type ProvidesValidation interface {
+
+	// VolumeCreateValidate validates a volume creation request.
+	VolumeCreateValidate(
+		ctx Context,
+		name string,
+		opts *VolumeCreateOpts) error
+
+	// VolumeAttachValidate validates a volume attach request.
+	VolumeAttachValidate(
+		ctx Context,
+		volumeID string,
+		opts *VolumeAttachOpts) error
+
+	// VolumeRemoveValidate validates a volume removal request.
+	VolumeRemoveValidate(
+		ctx Context,
+		volumeID string,
+		opts *VolumeRemoveOpts) error
+}
+
+// ProvidesSnapshotGroups is a type that is able to snapshot a group of
+// volumes at the same point in time.
+type ProvidesSnapshotGroups interface {
+
+	// SnapshotGroupCreate snapshots the volumes. The returned group is
+	// marked as consistent if the storage platform snapshotted the volumes
+	// at the same point in time. ErrNotImplemented is returned if the
+	// storage platform cannot snapshot the volumes together, in which case
+	// the volumes are snapshotted one after another.
+	SnapshotGroupCreate(
+		ctx Context,
+		volumeIDs []string,
+		snapshotName string,
+		opts Store) (*SnapshotGroup, error)
+}
+
+// VolumeFieldRecycledTime is the name of the volume field in which a
+// recycled volume's recycle time is stored as the number of seconds since
+// the epoch.
+const VolumeFieldRecycledTime = "recycledTime"
+
+const (
+	// VolumeMetadataService is the key of the metadata that holds the name
+	// of the service with which a volume was created.
+	VolumeMetadataService = "libstorage-service"
+
+	// VolumeMetadataCreator is the key of the metadata that holds the
+	// authenticated identity of the client that created a volume.
+	VolumeMetadataCreator = "libstorage-creator"
+
+	// VolumeMetadataTenant is the key of the metadata that holds the tenant
+	// of the client that created a volume.
+	VolumeMetadataTenant = "libstorage-tenant"
+
+	// VolumeMetadataInstance is the key of the metadata that holds the ID of
+	// the instance from which a volume was created.
+	VolumeMetadataInstance = "libstorage-instance"
+
+	// VolumeMetadataCreated is the key of the metadata that holds the time
+	// at which a volume was created as the number of seconds since the
+	// epoch.
+	VolumeMetadataCreated = "libstorage-created"
+
+	// VolumeMetadataSchema is the key of the metadata that holds the version
+	// of the metadata's schema.
+	VolumeMetadataSchema = "libstorage-schema"
+
+	// VolumeMetadataSchemaVersion is the version of the metadata's schema.
+	VolumeMetadataSchemaVersion = "1"
+)
+
+// ProvidesVolumeMetadata is a type that stamps the volumes it creates with
+// the metadata in the context, using the storage platform's tag mechanism,
+// and returns the metadata in the fields of the volumes it lists.
+type ProvidesVolumeMetadata interface {
+
+	// StampsVolumeMetadata returns a flag indicating whether or not the
+	// driver stamps the volumes it creates with metadata.
+	StampsVolumeMetadata(ctx Context) bool
+}
+
+// VolumeFieldBackendName is the name of the volume field in which the name
+// of a volume created with a service's naming template is stored when the
+// volume's name is reversed to the name requested by the client.
+const VolumeFieldBackendName = "backendName"
+
+// VolumeFieldMountToken is the name of the field of an attached volume in
+// which the server returns the token with which the instance's client
+// reports whether or not the volume was mounted.
+const VolumeFieldMountToken = "mountToken"
+
+// ProvidesVolumeRecycling is a type that is able to move volumes to a
+// recycle bin, from which they may be restored until they are purged,
+// rather than removing them.
+type ProvidesVolumeRecycling interface {
+
+	// VolumeRecycle moves the volume to the recycle bin.
+	VolumeRecycle(
+		ctx Context,
+		volumeID string,
+		opts Store) error
+
+	// RecycledVolumes returns the volumes in the recycle bin. Each volume's
+	// VolumeFieldRecycledTime field is the time at which it was recycled.
+	RecycledVolumes(
+		ctx Context,
+		opts Store) ([]*Volume, error)
+
+	// RecycledVolumeRestore restores the volume from the recycle bin.
+	RecycledVolumeRestore(
+		ctx Context,
+		volumeID string,
+		opts Store) (*Volume, error)
+
+	// RecycledVolumePurge removes the volume from the recycle bin
+	// permanently.
+	RecycledVolumePurge(
+		ctx Context,
+		volumeID string,
+		opts Store) error
+}
+
+// VolumeFieldNativeID is the name of the volume field in which an imported
+// volume's backend-native identifier is stored.
+const VolumeFieldNativeID = "nativeID"
+
+// ProvidesVolumeImport is a type that is able to bring a volume that was
+// created outside of libStorage under its management.
+type ProvidesVolumeImport interface {
+
+	// VolumeImport registers the volume with the backend-native identifier
+	// as a volume managed by libStorage. If volumeName is not empty the
+	// volume is given the name. The volume's VolumeFieldNativeID field is the
+	// backend-native identifier.
+	VolumeImport(
+		ctx Context,
+		nativeID, volumeName string,
+		opts Store) (*Volume, error)
+}
+
+// ProvidesVolumeUnmanage is a type that is able to release a volume from
+// libStorage management without removing the volume from the storage
+// platform.
+type ProvidesVolumeUnmanage interface {
+
+	// VolumeUnmanage clears the tags or metadata libStorage uses to manage
+	// the volume. The volume's data is left intact, and the volume may be
+	// imported again.
+	VolumeUnmanage(
+		ctx Context,
+		volumeID string,
+		opts Store) error
+}
+
+// ProvidesVolumeResize is a type that is able to change the size of a
+// volume.
+type ProvidesVolumeResize interface {
+
+	// VolumeResize changes the size of the volume to the new size in GiB.
+	VolumeResize(
+		ctx Context,
+		volumeID string,
+		size int64,
+		opts Store) (*Volume, error)
+}
+
+// VolumeRetypeOptMigrationPolicy is the name of the volume retype option
+// that indicates whether or not a storage platform may migrate a volume's
+// data in order to change the volume's type. The value is platform
+// specific, ex. "never" or "on-demand".
+const VolumeRetypeOptMigrationPolicy = "migrationPolicy"
+
+// ProvidesVolumeRetype is a type that is able to change the type of a
+// volume, such as to move the volume to a replicated tier of storage.
+type ProvidesVolumeRetype interface {
+
+	// VolumeRetype changes the type of the volume.
+	VolumeRetype(
+		ctx Context,
+		volumeID, volumeType string,
+		opts Store) (*Volume, error)
+}
+
+// ProvidesVolumeStats is a type that is able to report the IO statistics the
+// storage platform collects for a volume.
+type ProvidesVolumeStats interface {
+
+	// VolumeStats returns the volume's IO statistics.
+	VolumeStats(
+		ctx Context,
+		volumeID string,
+		opts Store) (*VolumeIOStats, error)
+}
+
+// ProvidesHealthCheck is a type that is able to check whether the storage
+// platform is reachable and the driver's credentials are valid.
+type ProvidesHealthCheck interface {
+
+	// HealthCheck returns an error if the storage platform is unhealthy.
+	HealthCheck(ctx Context) error
+}
+
+// FaultInjectionAllOps is the key of the faults injected into the operations
+// that do not have faults of their own.
+const FaultInjectionAllOps = "*"
+
+// FaultInjection describes the faults a storage driver injects into its
+// operations in order to test how its callers behave when storage fails.
+type FaultInjection struct {
+
+	// Seed seeds the generator that decides which operations fail so that
+	// a sequence of injected faults is reproducible.
+	Seed int64 `json:"seed" yaml:"seed"`
+
+	// Ops are the faults injected into the driver's operations keyed by the
+	// names of the operations, ex. VolumeAttach.
+	Ops map[string]*OperationFault `json:"ops,omitempty" yaml:"ops,omitempty"`
+
+	// FlakyAttachRate is the probability, from 0 to 1, that a volume attach
+	// reports success without attaching the volume.
+	FlakyAttachRate float64 `json:"flakyAttachRate,omitempty" yaml:"flakyAttachRate,omitempty"`
+}
+
+// OperationFault describes the faults injected into an operation.
+type OperationFault struct {
+
+	// Latency is the duration, ex. 500ms, by which the operation is delayed.
+	Latency string `json:"latency,omitempty" yaml:"latency,omitempty"`
+
+	// ErrorRate is the probability, from 0 to 1, that the operation fails.
+	ErrorRate float64 `json:"errorRate,omitempty" yaml:"errorRate,omitempty"`
+}
+
+// ProvidesFaultInjection is a type that is able to inject faults into its
+// operations. It is implemented by drivers used for testing, such as the
+// mock driver.
+type ProvidesFaultInjection interface {
+
+	// Faults returns the faults the driver injects.
+	Faults(ctx Context) (*FaultInjection, error)
+
+	// SetFaults replaces the faults the driver injects. A nil value stops
+	// the injection of faults.
+	SetFaults(ctx Context, faults *FaultInjection) error
+}
+
 // StorageDriverWithLogin is a StorageDriver with a Login function.
 type StorageDriverWithLogin interface {
 	StorageDriver
@@ -83,3 +83,17 @@ func TestParseVolumeAttachmentsTypes(t *testing.T) {
 	assert.True(t, a.Devices())
 	assert.True(t, a.Attached())
 }
+
+func TestParseAttachAccessMode(t *testing.T) {
+	m, ok := ParseAttachAccessMode("")
+	assert.True(t, ok)
+	assert.Equal(t, AttachAccessModeReadWrite, m)
+	assert.False(t, m.ReadOnly())
+
+	m, ok = ParseAttachAccessMode("RO")
+	assert.True(t, ok)
+	assert.True(t, m.ReadOnly())
+
+	_, ok = ParseAttachAccessMode("wo")
+	assert.False(t, ok)
+}
@@ -1,6 +1,8 @@
 package types
 
 import (
+	"time"
+
 	"github.com/akutz/goof"
 )
 
@@ -20,7 +22,24 @@ type ErrBadAdminToken struct{ goof.Goof }
 
 // ErrNotFound occurs when a Driver inspects or sends an operation to a
 // resource that cannot be found.
-type ErrNotFound struct{ goof.Goof }
+type ErrNotFound struct {
+	goof.Goof
+
+	// ResourceType is the type of the resource that cannot be found. It is
+	// empty if the resource is not a volume or snapshot.
+	ResourceType ResourceType
+}
+
+// ResourceType is the type of a resource that cannot be found.
+type ResourceType string
+
+const (
+	// ResourceTypeVolume indicates a volume.
+	ResourceTypeVolume ResourceType = "volume"
+
+	// ResourceTypeSnapshot indicates a snapshot.
+	ResourceTypeSnapshot ResourceType = "snapshot"
+)
 
 // ErrMissingInstanceID occurs when an operation requires the instance ID for
 // the configured service to be avaialble.
@@ -47,3 +66,160 @@ type ErrBatchProcess struct{ goof.Goof }
 // ErrBadFilter occurs when a bad filter is supplied via the filter query
 // string.
 type ErrBadFilter struct{ goof.Goof }
+
+// ErrWrongZone occurs when an operation, such as an attach, is requested
+// for a volume that resides in a different availability zone or rack than
+// the instance for which the operation was requested.
+type ErrWrongZone struct{ goof.Goof }
+
+// ErrInvalidRequest occurs when a request fails server-side or driver-side
+// validation.
+type ErrInvalidRequest struct{ goof.Goof }
+
+// ErrVolumeAttached occurs when an operation, such as an attach or a detach,
+// conflicts with the instances to which a volume is attached.
+type ErrVolumeAttached struct{ goof.Goof }
+
+// ErrVolumeInUse occurs when a volume that is attached to one or more
+// instances is removed without being forced. The error includes the IDs of
+// the instances to which the volume is attached if they are known.
+type ErrVolumeInUse struct{ goof.Goof }
+
+// ErrQuotaExceeded occurs when an operation would exceed a storage quota.
+type ErrQuotaExceeded struct{ goof.Goof }
+
+// ErrDriverTimeout occurs when a storage driver operation does not complete
+// within the server's task execution timeout. The error includes the ID of
+// the operation's task, which may still complete.
+type ErrDriverTimeout struct{ goof.Goof }
+
+// ErrDeadlineExceeded occurs when a request does not complete before its
+// deadline. The error includes the state of the request's task at the time
+// the deadline was exceeded.
+type ErrDeadlineExceeded struct{ goof.Goof }
+
+// ErrInstanceIDBinding occurs when a request claims an instance ID that is
+// bound to a client certificate other than the one presented with the
+// request.
+type ErrInstanceIDBinding struct{ goof.Goof }
+
+// ErrServiceUnavailable occurs when a request is made to a storage service
+// whose circuit breaker is open because the service's driver has failed
+// repeatedly or whose task queue is full.
+type ErrServiceUnavailable struct {
+	goof.Goof
+
+	// RetryAfter is how long until the service is tried again. It is zero
+	// if the time is not known.
+	RetryAfter time.Duration
+}
+
+// ErrRequestTooLarge occurs when the body of a request exceeds the maximum
+// size.
+type ErrRequestTooLarge struct{ goof.Goof }
+
+// ErrPolicyDenied occurs when the server's admission policy denies a
+// request.
+type ErrPolicyDenied struct{ goof.Goof }
+
+// ErrBackend occurs when a storage driver's backend fails, such as when it
+// cannot be reached, throttles requests, or rejects the driver's
+// credentials, rather than because of the request. Drivers return it so that
+// the failure counts against the service's circuit breaker.
+type ErrBackend struct{ goof.Goof }
+
+// ErrThrottled occurs when a storage driver's backend throttles requests. It
+// is a backend failure, but the request may be sent again once the backend
+// stops throttling them.
+type ErrThrottled struct {
+	goof.Goof
+
+	// RetryAfter is how long until the request may be sent again.
+	RetryAfter time.Duration
+}
+
+// ErrorCode is a stable, machine-readable code that identifies the type of
+// an error returned by the API.
+type ErrorCode string
+
+const (
+	// ErrCodeVolumeNotFound indicates a volume could not be found.
+	ErrCodeVolumeNotFound ErrorCode = "VOLUME_NOT_FOUND"
+
+	// ErrCodeSnapshotNotFound indicates a snapshot could not be found.
+	ErrCodeSnapshotNotFound ErrorCode = "SNAPSHOT_NOT_FOUND"
+
+	// ErrCodeResourceNotFound indicates a resource other than a volume or
+	// snapshot could not be found.
+	ErrCodeResourceNotFound ErrorCode = "RESOURCE_NOT_FOUND"
+
+	// ErrCodeVolumeAttached indicates a volume is attached.
+	ErrCodeVolumeAttached ErrorCode = "VOLUME_ATTACHED"
+
+	// ErrCodeVolumeInUse indicates a volume cannot be removed because it is
+	// attached to one or more instances.
+	ErrCodeVolumeInUse ErrorCode = "VOLUME_IN_USE"
+
+	// ErrCodeQuotaExceeded indicates a storage quota was exceeded.
+	ErrCodeQuotaExceeded ErrorCode = "QUOTA_EXCEEDED"
+
+	// ErrCodeDriverTimeout indicates a storage driver operation timed out.
+	ErrCodeDriverTimeout ErrorCode = "DRIVER_TIMEOUT"
+
+	// ErrCodeDeadlineExceeded indicates a request did not complete before
+	// its deadline.
+	ErrCodeDeadlineExceeded ErrorCode = "DEADLINE_EXCEEDED"
+
+	// ErrCodeWrongZone indicates a volume and instance reside in different
+	// availability zones.
+	ErrCodeWrongZone ErrorCode = "WRONG_ZONE"
+
+	// ErrCodeInvalidRequest indicates a request failed validation.
+	ErrCodeInvalidRequest ErrorCode = "INVALID_REQUEST"
+
+	// ErrCodeBadAdminToken indicates an invalid admin token was provided.
+	ErrCodeBadAdminToken ErrorCode = "BAD_ADMIN_TOKEN"
+
+	// ErrCodeMissingInstanceID indicates an operation required an instance
+	// ID that was not provided.
+	ErrCodeMissingInstanceID ErrorCode = "MISSING_INSTANCE_ID"
+
+	// ErrCodeBadFilter indicates an invalid filter was provided.
+	ErrCodeBadFilter ErrorCode = "BAD_FILTER"
+
+	// ErrCodeUnsupportedForClientType indicates an operation is not
+	// supported for the client's type.
+	ErrCodeUnsupportedForClientType ErrorCode = "UNSUPPORTED_FOR_CLIENT_TYPE"
+
+	// ErrCodeInstanceIDBinding indicates a request claimed an instance ID
+	// that is bound to a different client certificate.
+	ErrCodeInstanceIDBinding ErrorCode = "INSTANCE_ID_BINDING"
+
+	// ErrCodeServiceUnavailable indicates a storage service's circuit
+	// breaker is open or its task queue is full.
+	ErrCodeServiceUnavailable ErrorCode = "SERVICE_UNAVAILABLE"
+
+	// ErrCodeThrottled indicates a storage driver's backend throttled a
+	// request.
+	ErrCodeThrottled ErrorCode = "THROTTLED"
+
+	// ErrCodeRequestTooLarge indicates the body of a request exceeded the
+	// maximum size.
+	ErrCodeRequestTooLarge ErrorCode = "REQUEST_TOO_LARGE"
+
+	// ErrCodePolicyDenied indicates the server's admission policy denied a
+	// request.
+	ErrCodePolicyDenied ErrorCode = "POLICY_DENIED"
+
+	// ErrCodeInternal indicates an error without a more specific code.
+	ErrCodeInternal ErrorCode = "INTERNAL_ERROR"
+)
+
+// ErrHTTP is an error returned by the API client that includes the stable,
+// machine-readable code for the error that occurred on the server.
+type ErrHTTP struct {
+	goof.HTTPError
+
+	// Code is the error's machine-readable code.
+	Code ErrorCode
+}
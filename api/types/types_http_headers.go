@@ -9,6 +9,11 @@ const (
 	// LocalDevicesHeader is the HTTP header that contains a local device pair.
 	LocalDevicesHeader = "Libstorage-Localdevices"
 
+	// LocalDeviceInfoHeader is the HTTP header that contains the information
+	// about the local devices of a driver, such as their sizes and file
+	// system types.
+	LocalDeviceInfoHeader = "Libstorage-Localdeviceinfo"
+
 	// TransactionHeader is the HTTP header that contains the transaction
 	// sent from the client.
 	TransactionHeader = "Libstorage-Tx"
@@ -18,4 +23,20 @@ const (
 	// for the first time. This header is provided with every response sent
 	// from the server.
 	ServerNameHeader = "Libstorage-Servername"
+
+	// TimeoutHeader is the HTTP header that contains the duration, in Go
+	// duration format, within which the client expects the server to complete
+	// the request. The server may reduce this value but will never extend it
+	// beyond the configured request timeout.
+	TimeoutHeader = "Libstorage-Timeout"
+
+	// APIVersionHeader is the HTTP header that contains the version of the
+	// API with which the server handled the request. This header is provided
+	// with every response sent from the server.
+	APIVersionHeader = "Libstorage-Apiversion"
+
+	// NextHeader is the HTTP header that contains the token with which the
+	// next page of a paginated list is requested. The header is omitted from
+	// the response for the last page.
+	NextHeader = "Libstorage-Next"
 )
@@ -12,6 +12,7 @@ type VolumeCreateRequest struct {
 	EncryptionKey    *string                `json:"encryptionKey,omitempty"`
 	IOPS             *int64                 `json:"iops,omitempty"`
 	Size             *int64                 `json:"size,omitempty"`
+	Thin             *bool                  `json:"thin,omitempty"`
 	Type             *string                `json:"type,omitempty"`
 	Opts             map[string]interface{} `json:"opts,omitempty"`
 }
@@ -22,9 +23,26 @@ type VolumeCopyRequest struct {
 	Opts       map[string]interface{} `json:"opts,omitempty"`
 }
 
+// VolumeImportRequest is the JSON body for importing a volume.
+type VolumeImportRequest struct {
+	NativeID   string                 `json:"nativeID"`
+	VolumeName string                 `json:"volumeName,omitempty"`
+	Opts       map[string]interface{} `json:"opts,omitempty"`
+}
+
 // VolumeSnapshotRequest is the JSON body for snapshotting a volume.
 type VolumeSnapshotRequest struct {
 	SnapshotName string                 `json:"snapshotName"`
+	Quiesce      bool                   `json:"quiesce,omitempty"`
+	Opts         map[string]interface{} `json:"opts,omitempty"`
+}
+
+// SnapshotGroupCreateRequest is the JSON body for snapshotting a group of
+// volumes.
+type SnapshotGroupCreateRequest struct {
+	SnapshotName string                 `json:"snapshotName"`
+	VolumeIDs    []string               `json:"volumeIDs"`
+	Quiesce      bool                   `json:"quiesce,omitempty"`
 	Opts         map[string]interface{} `json:"opts,omitempty"`
 }
 
@@ -32,19 +50,34 @@ type VolumeSnapshotRequest struct {
 type VolumeAttachRequest struct {
 	Force          bool                   `json:"force,omitempty"`
 	NextDeviceName *string                `json:"nextDeviceName,omitempty"`
+	AccessMode     AttachAccessMode       `json:"accessMode,omitempty"`
+	Reason         string                 `json:"reason,omitempty"`
+	RequestedBy    string                 `json:"requestedBy,omitempty"`
 	Opts           map[string]interface{} `json:"opts,omitempty"`
 }
 
 // VolumeDetachRequest is the JSON body for detaching a volume from an instance.
 type VolumeDetachRequest struct {
-	Force bool                   `json:"force,omitempty"`
-	Opts  map[string]interface{} `json:"opts,omitempty"`
+	Force       bool                   `json:"force,omitempty"`
+	Reason      string                 `json:"reason,omitempty"`
+	RequestedBy string                 `json:"requestedBy,omitempty"`
+	Opts        map[string]interface{} `json:"opts,omitempty"`
+}
+
+// VolumeMountReportRequest is the JSON body for reporting whether or not a
+// volume attached to an instance was mounted.
+type VolumeMountReportRequest struct {
+	Token      string                 `json:"token"`
+	MountPoint string                 `json:"mountPoint,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+	Opts       map[string]interface{} `json:"opts,omitempty"`
 }
 
 // SnapshotCopyRequest is the JSON body for copying a snapshot.
 type SnapshotCopyRequest struct {
 	SnapshotName  string                 `json:"snapshotName"`
 	DestinationID string                 `json:"destinationID"`
+	Destination   string                 `json:"destination,omitempty"`
 	Opts          map[string]interface{} `json:"opts,omitempty"`
 }
 
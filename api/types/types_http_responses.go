@@ -6,3 +6,11 @@ type VolumeAttachResponse struct {
 	Volume      *Volume `json:"volume"`
 	AttachToken string  `json:"attachToken"`
 }
+
+// ValidateResponse is the JSON response for a mutating request submitted in
+// validate mode. The request is validated and normalized, but the operation
+// is not performed.
+type ValidateResponse struct {
+	Operation string                 `json:"operation"`
+	Request   map[string]interface{} `json:"request"`
+}
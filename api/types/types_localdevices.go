@@ -23,6 +23,51 @@ type LocalDevices struct {
 
 	// DeviceMap is voluem to device mappings.
 	DeviceMap map[string]string `json:"deviceMap,omitempty" yaml:"deviceMap,omitempty"`
+
+	// Devices is information about the mapped devices, keyed by the devices'
+	// paths. It is only present if the executor was asked to describe the
+	// devices.
+	Devices map[string]*LocalDevice `json:"devices,omitempty" yaml:"devices,omitempty"`
+}
+
+// LocalDevice is information about a device attached to the host. Fields
+// that cannot be determined on the host are empty.
+type LocalDevice struct {
+
+	// Size is the size of the device in bytes.
+	Size int64 `json:"size,omitempty" yaml:"size,omitempty"`
+
+	// Serial is the device's serial number.
+	Serial string `json:"serial,omitempty" yaml:"serial,omitempty"`
+
+	// WWN is the device's world wide name.
+	WWN string `json:"wwn,omitempty" yaml:"wwn,omitempty"`
+
+	// BusType is the type of the bus over which the device is attached, ex.
+	// scsi, ata, or nvme.
+	BusType string `json:"busType,omitempty" yaml:"busType,omitempty"`
+
+	// LUN is the logical unit number of the device on its bus.
+	LUN string `json:"lun,omitempty" yaml:"lun,omitempty"`
+
+	// ByID is the device's persistent symlink in /dev/disk/by-id.
+	ByID string `json:"byID,omitempty" yaml:"byID,omitempty"`
+
+	// ByPath is the device's persistent symlink in /dev/disk/by-path.
+	ByPath string `json:"byPath,omitempty" yaml:"byPath,omitempty"`
+
+	// FSType is the type of the device's file system. It is empty if the
+	// device does not have a file system.
+	FSType string `json:"fsType,omitempty" yaml:"fsType,omitempty"`
+
+	// MountPoint is where the device is mounted. It is empty if the device
+	// is not mounted.
+	MountPoint string `json:"mountPoint,omitempty" yaml:"mountPoint,omitempty"`
+}
+
+// Mounted returns a flag indicating whether or not the device is mounted.
+func (d *LocalDevice) Mounted() bool {
+	return d.MountPoint != ""
 }
 
 // String returns the string representation of a LocalDevices object.
@@ -91,20 +136,52 @@ func (l *LocalDevices) UnmarshalText(value []byte) error {
 	return nil
 }
 
+// MarshalDevicesText marshals the LocalDevices' device information to a
+// text string that adheres to the format `DRIVER=DEVICES`, where DEVICES is
+// the JSON representation of the Devices field.
+func (l *LocalDevices) MarshalDevicesText() ([]byte, error) {
+	buf, err := json.Marshal(l.Devices)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(l.Driver+"="), buf...), nil
+}
+
+// UnmarshalDevicesText unmarshals the data into the LocalDevices' driver and
+// device information provided the data adheres to the format described in
+// the MarshalDevicesText function.
+func (l *LocalDevices) UnmarshalDevicesText(value []byte) error {
+	i := bytes.IndexByte(value, '=')
+	if i < 1 {
+		return goof.WithField(
+			"value", string(value), "invalid LocalDevices devices")
+	}
+	devices := map[string]*LocalDevice{}
+	if err := json.Unmarshal(value[i+1:], &devices); err != nil {
+		return goof.WithFieldE(
+			"value", string(value), "invalid LocalDevices devices", err)
+	}
+	l.Driver = string(value[:i])
+	l.Devices = devices
+	return nil
+}
+
 // MarshalJSON marshals the InstanceID to JSON.
 func (l *LocalDevices) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&struct {
-		Driver    string            `json:"driver"`
-		DeviceMap map[string]string `json:"deviceMap"`
-	}{l.Driver, l.DeviceMap})
+		Driver    string                  `json:"driver"`
+		DeviceMap map[string]string       `json:"deviceMap"`
+		Devices   map[string]*LocalDevice `json:"devices,omitempty"`
+	}{l.Driver, l.DeviceMap, l.Devices})
 }
 
 // UnmarshalJSON marshals the InstanceID to JSON.
 func (l *LocalDevices) UnmarshalJSON(data []byte) error {
 
 	ldm := &struct {
-		Driver    string            `json:"driver"`
-		DeviceMap map[string]string `json:"deviceMap"`
+		Driver    string                  `json:"driver"`
+		DeviceMap map[string]string       `json:"deviceMap"`
+		Devices   map[string]*LocalDevice `json:"devices"`
 	}{}
 
 	if err := json.Unmarshal(data, ldm); err != nil {
@@ -113,6 +190,7 @@ func (l *LocalDevices) UnmarshalJSON(data []byte) error {
 
 	l.Driver = ldm.Driver
 	l.DeviceMap = ldm.DeviceMap
+	l.Devices = ldm.Devices
 
 	return nil
 }
@@ -121,9 +199,10 @@ func (l *LocalDevices) UnmarshalJSON(data []byte) error {
 // LocalDevices.
 func (l *LocalDevices) MarshalYAML() (interface{}, error) {
 	return &struct {
-		Driver    string            `json:"driver" yaml:"driver"`
-		DeviceMap map[string]string `json:"deviceMap,omitempty" yaml:"deviceMap,omitempty"`
-	}{l.Driver, l.DeviceMap}, nil
+		Driver    string                  `json:"driver" yaml:"driver"`
+		DeviceMap map[string]string       `json:"deviceMap,omitempty" yaml:"deviceMap,omitempty"`
+		Devices   map[string]*LocalDevice `json:"devices,omitempty" yaml:"devices,omitempty"`
+	}{l.Driver, l.DeviceMap, l.Devices}, nil
 }
 
 // byString  implements sort.Interface for []string.
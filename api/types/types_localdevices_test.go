@@ -61,3 +61,35 @@ func TestLocalDevicesMarshalToYAML(t *testing.T) {
 	}
 	fmt.Println(string(out))
 }
+
+func TestLocalDevicesMarshalDevices(t *testing.T) {
+
+	ld1 := newLocalDevicesObj()
+	ld1.Devices = map[string]*LocalDevice{
+		"/dev/xvda": {
+			Size:       8589934592,
+			Serial:     "vol-000",
+			FSType:     "ext4",
+			MountPoint: "/",
+		},
+		"/dev/xvdb": {Size: 1073741824, WWN: "0x5000c500a1b2c3d4"},
+	}
+	assert.True(t, ld1.Devices["/dev/xvda"].Mounted())
+	assert.False(t, ld1.Devices["/dev/xvdb"].Mounted())
+
+	buf, err := ld1.MarshalJSON()
+	assert.NoError(t, err)
+	ld2 := &LocalDevices{}
+	assert.NoError(t, ld2.UnmarshalJSON(buf))
+	assert.EqualValues(t, ld1, ld2)
+
+	buf, err = ld1.MarshalDevicesText()
+	assert.NoError(t, err)
+	ld3 := &LocalDevices{}
+	assert.NoError(t, ld3.UnmarshalDevicesText(buf))
+	assert.Equal(t, "vfs", ld3.Driver)
+	assert.EqualValues(t, ld1.Devices, ld3.Devices)
+
+	assert.Error(t, ld3.UnmarshalDevicesText([]byte("vfs")))
+	assert.Error(t, ld3.UnmarshalDevicesText([]byte("vfs={")))
+}
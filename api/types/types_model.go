@@ -1,6 +1,10 @@
 package types
 
-import "strconv"
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+)
 
 // StorageType is the type of storage a driver provides.
 type StorageType string
@@ -49,6 +53,13 @@ type Instance struct {
 	// The region from which the object originates.
 	Region string `json:"region,omitempty" yaml:",omitempty"`
 
+	// Zone is the availability zone in which the instance resides.
+	Zone string `json:"zone,omitempty" yaml:",omitempty"`
+
+	// Rack is the rack, or other failure domain within a zone, in which the
+	// instance resides, ex. a Ceph CRUSH rack.
+	Rack string `json:"rack,omitempty" yaml:",omitempty"`
+
 	// Fields are additional properties that can be defined for this type.
 	Fields map[string]string `json:"fields,omitempty" yaml:",omitempty"`
 }
@@ -126,6 +137,30 @@ type Snapshot struct {
 	Fields map[string]string `json:"fields,omitempty" yaml:",omitempty"`
 }
 
+// SnapshotGroup is a set of snapshots of multiple volumes that are taken
+// together, such as the volumes of a database.
+type SnapshotGroup struct {
+	// The snapshot group's ID if the storage platform tracks the snapshots
+	// as a group.
+	ID string `json:"id,omitempty" yaml:"id,omitempty"`
+
+	// The name of the snapshot group.
+	Name string `json:"name" yaml:"name"`
+
+	// A flag indicating whether or not the storage platform snapshotted the
+	// volumes at the same point in time. The volumes are otherwise
+	// snapshotted one after another.
+	Consistent bool `json:"consistent" yaml:"consistent"`
+
+	// A flag indicating whether or not the volumes' file systems were frozen
+	// while the volumes were snapshotted.
+	Quiesced bool `json:"quiesced,omitempty" yaml:"quiesced,omitempty"`
+
+	// The group's snapshots keyed by the IDs of the volumes to which they
+	// belong.
+	Snapshots map[string]*Snapshot `json:"snapshots" yaml:"snapshots"`
+}
+
 // VolumeAttachmentStates is the volume's attachment state possibilities.
 type VolumeAttachmentStates int
 
@@ -181,6 +216,10 @@ type Volume struct {
 	// The availability zone for which the volume is available.
 	AvailabilityZone string `json:"availabilityZone,omitempty" yaml:"availabilityZone,omitempty"`
 
+	// Rack is the rack, or other failure domain within a zone, to which the
+	// volume's storage is confined, ex. the CRUSH rack of a Ceph pool.
+	Rack string `json:"rack,omitempty" yaml:"rack,omitempty"`
+
 	// A flag indicating whether or not the volume is encrypted.
 	Encrypted bool `json:"encrypted,omitempty" yaml:"encrypted,omitempty"`
 
@@ -236,6 +275,11 @@ type VolumeAttachment struct {
 	// volume is retrieved via an integration driver.
 	MountPoint string `json:"mountPoint,omitempty" yaml:"mountPoint,omitempty"`
 
+	// DevicePath is the path of the device through which a raw volume is
+	// accessed as a block device. This field is set when a raw volume is
+	// mounted via an integration driver.
+	DevicePath string `json:"devicePath,omitempty" yaml:"devicePath,omitempty"`
+
 	// The ID of the instance on which the volume to which the attachment
 	// belongs is mounted.
 	InstanceID *InstanceID `json:"instanceID" yaml:"instanceID,omitempty"`
@@ -246,10 +290,73 @@ type VolumeAttachment struct {
 	// The ID of the volume to which the attachment belongs.
 	VolumeID string `json:"volumeID" yaml:"volumeID,omitempty"`
 
+	// BusType is the type of the bus over which the device is attached, ex.
+	// scsi, ata, nvme, or virtio.
+	BusType string `json:"busType,omitempty" yaml:"busType,omitempty"`
+
+	// LUN is the logical unit number of the device on its bus.
+	LUN string `json:"lun,omitempty" yaml:"lun,omitempty"`
+
+	// WWN is the device's world wide name.
+	WWN string `json:"wwn,omitempty" yaml:"wwn,omitempty"`
+
+	// Serial is the device's serial number.
+	Serial string `json:"serial,omitempty" yaml:"serial,omitempty"`
+
+	// ByID is the device's persistent symlink in /dev/disk/by-id, which
+	// names the device by its bus and serial number or world wide name.
+	ByID string `json:"byID,omitempty" yaml:"byID,omitempty"`
+
+	// ByPath is the device's persistent symlink in /dev/disk/by-path, which
+	// names the device by the path of the hardware through which it is
+	// attached.
+	ByPath string `json:"byPath,omitempty" yaml:"byPath,omitempty"`
+
 	// Fields are additional properties that can be defined for this type.
 	Fields map[string]string `json:"fields,omitempty" yaml:",omitempty"`
 }
 
+// Describe sets the attachment's device metadata that has not been set by
+// its storage driver from the information about its local device.
+func (a *VolumeAttachment) Describe(d *LocalDevice) {
+	if d == nil {
+		return
+	}
+	if a.BusType == "" {
+		a.BusType = d.BusType
+	}
+	if a.LUN == "" {
+		a.LUN = d.LUN
+	}
+	if a.WWN == "" {
+		a.WWN = d.WWN
+	}
+	if a.Serial == "" {
+		a.Serial = d.Serial
+	}
+	if a.ByID == "" {
+		a.ByID = d.ByID
+	}
+	if a.ByPath == "" {
+		a.ByPath = d.ByPath
+	}
+}
+
+// StableDeviceName returns the attachment's persistent device symlink,
+// which does not change when the kernel names the device differently, such
+// as after a reboot. The /dev/disk/by-id symlink is preferred to the
+// /dev/disk/by-path symlink. The device's name is returned if neither
+// symlink is known.
+func (a *VolumeAttachment) StableDeviceName() string {
+	if a.ByID != "" {
+		return a.ByID
+	}
+	if a.ByPath != "" {
+		return a.ByPath
+	}
+	return a.DeviceName
+}
+
 // VolumeDevice provides information about a volume's backing storage
 // device. This might be a block device, NAS device, object device, etc.
 type VolumeDevice struct {
@@ -278,6 +385,66 @@ type VolumeDevice struct {
 	Fields map[string]string `json:"fields,omitempty" yaml:",omitempty"`
 }
 
+// VolumeStats are a volume's capacity usage and IO statistics.
+type VolumeStats struct {
+	// The ID of the volume to which the statistics belong.
+	VolumeID string `json:"volumeID" yaml:"volumeID"`
+
+	// Time is the time stamp (epoch) at which the statistics were collected.
+	Time int64 `json:"time" yaml:"time"`
+
+	// Usage is the usage of the volume's file system. It is nil unless the
+	// volume is mounted on the client's instance.
+	Usage *VolumeUsage `json:"usage,omitempty" yaml:"usage,omitempty"`
+
+	// IO is the volume's IO statistics as reported by the storage platform.
+	// It is nil if the storage driver does not provide IO statistics.
+	IO *VolumeIOStats `json:"io,omitempty" yaml:"io,omitempty"`
+}
+
+// VolumeUsage is the capacity usage of a mounted file system.
+type VolumeUsage struct {
+	// MountPoint is where the file system is mounted.
+	MountPoint string `json:"mountPoint" yaml:"mountPoint"`
+
+	// TotalBytes is the size of the file system in bytes.
+	TotalBytes uint64 `json:"totalBytes" yaml:"totalBytes"`
+
+	// UsedBytes is the number of bytes in use.
+	UsedBytes uint64 `json:"usedBytes" yaml:"usedBytes"`
+
+	// AvailableBytes is the number of bytes available to unprivileged users.
+	AvailableBytes uint64 `json:"availableBytes" yaml:"availableBytes"`
+
+	// TotalInodes is the number of inodes in the file system.
+	TotalInodes uint64 `json:"totalInodes,omitempty" yaml:"totalInodes,omitempty"`
+
+	// UsedInodes is the number of inodes in use.
+	UsedInodes uint64 `json:"usedInodes,omitempty" yaml:"usedInodes,omitempty"`
+}
+
+// VolumeIOStats are a volume's IO rates averaged over a period.
+type VolumeIOStats struct {
+	// Period is the number of seconds over which the rates are averaged. It
+	// is zero if the storage platform does not report the period.
+	Period int64 `json:"period,omitempty" yaml:"period,omitempty"`
+
+	// ReadOps is the number of read operations per second.
+	ReadOps float64 `json:"readOps" yaml:"readOps"`
+
+	// WriteOps is the number of write operations per second.
+	WriteOps float64 `json:"writeOps" yaml:"writeOps"`
+
+	// ReadBytes is the number of bytes read per second.
+	ReadBytes float64 `json:"readBytes" yaml:"readBytes"`
+
+	// WriteBytes is the number of bytes written per second.
+	WriteBytes float64 `json:"writeBytes" yaml:"writeBytes"`
+
+	// Fields are additional statistics specific to the storage platform.
+	Fields map[string]string `json:"fields,omitempty" yaml:",omitempty"`
+}
+
 // ExecutorInfo contains information about a client-side executor, such as
 // its name and MD5 checksum.
 type ExecutorInfo struct {
@@ -294,6 +461,10 @@ type ExecutorInfo struct {
 
 	// LastModified is the time the executor was last modified as an epoch.
 	LastModified int64 `json:"lastModified" yaml:"lastModified"`
+
+	// Signature is the base64 encoded Ed25519 signature of the executor. It
+	// is empty if the executor was not signed when the server was built.
+	Signature string `json:"signature,omitempty" yaml:",omitempty"`
 }
 
 // ServiceInfo is information about a service.
@@ -306,6 +477,26 @@ type ServiceInfo struct {
 
 	// Driver is the name of the driver registered for the service.
 	Driver *DriverInfo `json:"driver"`
+
+	// MountOptions is the comma-separated list of options with which the
+	// integration driver mounts the service's volumes by default, as
+	// configured for the service on the server.
+	MountOptions string `json:"mountOptions,omitempty" yaml:"mountOptions,omitempty"`
+}
+
+// ServiceRegistration describes a storage service registered while the
+// server is running rather than defined in the server's configuration.
+type ServiceRegistration struct {
+	// Name is the service's name.
+	Name string `json:"name" yaml:"name"`
+
+	// Driver is the name of the service's storage driver.
+	Driver string `json:"driver" yaml:"driver"`
+
+	// Config is the service's configuration. Its keys are the same as those
+	// of a service defined in a configuration file, ex. the settings of the
+	// service's storage driver.
+	Config map[string]interface{} `json:"config,omitempty" yaml:"config,omitempty"`
 }
 
 // DriverInfo is information about a driver.
@@ -320,6 +511,150 @@ type DriverInfo struct {
 	NextDevice *NextDeviceInfo `json:"nextDevice,omitempty" yaml:"nextDevice,omitempty"`
 }
 
+// StorageCapabilities describes the optional features supported by a
+// storage driver.
+type StorageCapabilities struct {
+	// Snapshots indicates whether the driver supports volume snapshots.
+	Snapshots bool `json:"snapshots"`
+
+	// Clone indicates whether the driver supports copying a volume.
+	Clone bool `json:"clone"`
+
+	// Resize indicates whether the driver supports expanding a volume.
+	Resize bool `json:"resize"`
+
+	// MultiAttach indicates whether a volume may be attached to more than
+	// one instance at a time.
+	MultiAttach bool `json:"multiAttach"`
+
+	// ReadOnlyAttach indicates whether the driver supports attaching a
+	// volume read-only.
+	ReadOnlyAttach bool `json:"readOnlyAttach,omitempty" yaml:"readOnlyAttach,omitempty"`
+
+	// MaxVolumeSize is the maximum size of a volume, in GiB. A value of zero
+	// indicates there is no known limit.
+	MaxVolumeSize int64 `json:"maxVolumeSize,omitempty" yaml:"maxVolumeSize,omitempty"`
+
+	// Topology is a list of the topology constraints that apply to volume
+	// placement, ex. "zone" or "region".
+	Topology []string `json:"topology,omitempty" yaml:",omitempty"`
+
+	// Provisioning is a list of the ways in which the driver can provision
+	// volumes, "thin" and/or "thick". A driver that lists both provisions
+	// volumes as requested by the thin volume create option.
+	Provisioning []string `json:"provisioning,omitempty" yaml:",omitempty"`
+
+	// VolumeFields indicates whether the driver records the custom options
+	// of a volume creation request as the created volume's fields, ex. the
+	// type of file system with which an integration driver formats the
+	// volume.
+	VolumeFields bool `json:"volumeFields,omitempty" yaml:"volumeFields,omitempty"`
+
+	// Fields are additional properties that can be defined for this type.
+	Fields map[string]string `json:"fields,omitempty" yaml:",omitempty"`
+}
+
+const (
+	// ProvisioningThin indicates a driver can create volumes whose storage
+	// is allocated as it is written.
+	ProvisioningThin = "thin"
+
+	// ProvisioningThick indicates a driver can create volumes whose storage
+	// is allocated when they are created.
+	ProvisioningThick = "thick"
+)
+
+// SupportsProvisioning returns a flag indicating whether or not the driver
+// can create thin-provisioned volumes if thin is true, or thick volumes if
+// thin is false.
+func (c *StorageCapabilities) SupportsProvisioning(thin bool) bool {
+	want := ProvisioningThick
+	if thin {
+		want = ProvisioningThin
+	}
+	for _, p := range c.Provisioning {
+		if p == want {
+			return true
+		}
+	}
+	return false
+}
+
+// HealthStatus is the health status of a server or storage service.
+type HealthStatus string
+
+const (
+	// HealthStatusOK indicates the server or service is healthy.
+	HealthStatusOK HealthStatus = "ok"
+
+	// HealthStatusDegraded indicates one or more of the server's services
+	// are unhealthy.
+	HealthStatusDegraded HealthStatus = "degraded"
+
+	// HealthStatusUnavailable indicates a service is unhealthy.
+	HealthStatusUnavailable HealthStatus = "unavailable"
+)
+
+// Health is the health of a server and its storage services.
+type Health struct {
+	// Status is the server's health status.
+	Status HealthStatus `json:"status"`
+
+	// Services maps the names of the server's storage services to their
+	// health.
+	Services map[string]*ServiceHealth `json:"services,omitempty" yaml:",omitempty"`
+}
+
+// ServiceHealth is the health of a storage service.
+type ServiceHealth struct {
+	// Status is the service's health status.
+	Status HealthStatus `json:"status"`
+
+	// Driver is the name of the service's storage driver.
+	Driver string `json:"driver"`
+
+	// Duration is the number of milliseconds the health check took.
+	Duration int64 `json:"duration"`
+
+	// Error is the reason the service is unhealthy.
+	Error string `json:"error,omitempty" yaml:",omitempty"`
+
+	// CircuitBreaker is the state of the service's circuit breaker. The
+	// field is nil if the service's circuit breaker is disabled.
+	CircuitBreaker *CircuitBreakerState `json:"circuitBreaker,omitempty" yaml:",omitempty"`
+}
+
+// CircuitBreakerStatus is the status of a storage service's circuit breaker.
+type CircuitBreakerStatus string
+
+const (
+	// CircuitBreakerClosed indicates the service's requests are executed.
+	CircuitBreakerClosed CircuitBreakerStatus = "closed"
+
+	// CircuitBreakerOpen indicates the service's requests fail fast.
+	CircuitBreakerOpen CircuitBreakerStatus = "open"
+
+	// CircuitBreakerHalfOpen indicates a single request is being executed
+	// to determine whether or not the service has recovered.
+	CircuitBreakerHalfOpen CircuitBreakerStatus = "halfOpen"
+)
+
+// CircuitBreakerState is the state of a storage service's circuit breaker.
+type CircuitBreakerState struct {
+	// Status is the breaker's status.
+	Status CircuitBreakerStatus `json:"status"`
+
+	// Failures is the number of consecutive driver failures.
+	Failures int `json:"failures"`
+
+	// OpenedAt is the epoch time at which the breaker opened.
+	OpenedAt int64 `json:"openedAt,omitempty" yaml:",omitempty"`
+
+	// RetryAfter is the number of seconds until the breaker allows a
+	// request to determine whether or not the service has recovered.
+	RetryAfter int64 `json:"retryAfter,omitempty" yaml:",omitempty"`
+}
+
 // NextDeviceInfo assists the libStorage client in determining the
 // next available device name by providing the driver's device prefix and
 // optional pattern.
@@ -385,9 +720,233 @@ type Task struct {
 	// State is the current state of the task.
 	State TaskState `json:"state"`
 
+	// Progress is the percentage of the task that has been completed, if
+	// the operation executed by the task reports its progress.
+	Progress int `json:"progress,omitempty" yaml:",omitempty"`
+
 	// Result holds the result of the task.
 	Result interface{} `json:"result,omitempty" yaml:",omitempty"`
 
 	// Error contains the error if the task was unsuccessful.
 	Error error `json:"error,omitempty" yaml:",omitempty"`
 }
+
+// UnmarshalJSON unmarshals the task from JSON. The task's error is
+// unmarshaled from either a string or an object with a message, as errors
+// are marshaled by the server.
+func (t *Task) UnmarshalJSON(data []byte) error {
+
+	type task Task
+	tj := &struct {
+		*task
+		Error json.RawMessage `json:"error,omitempty"`
+	}{task: (*task)(t)}
+
+	if err := json.Unmarshal(data, tj); err != nil {
+		return err
+	}
+
+	t.Error = nil
+	if len(tj.Error) == 0 || string(tj.Error) == "null" {
+		return nil
+	}
+
+	var msg string
+	if err := json.Unmarshal(tj.Error, &msg); err == nil {
+		t.Error = errors.New(msg)
+		return nil
+	}
+
+	obj := &struct {
+		Message string `json:"message"`
+		Msg     string `json:"msg"`
+	}{}
+	if err := json.Unmarshal(tj.Error, obj); err == nil {
+		if obj.Message != "" {
+			t.Error = errors.New(obj.Message)
+			return nil
+		}
+		if obj.Msg != "" {
+			t.Error = errors.New(obj.Msg)
+			return nil
+		}
+	}
+
+	t.Error = errors.New(string(tj.Error))
+	return nil
+}
+
+// InterruptedTask describes a task that had not completed when the server
+// was shut down, such as an attachment that may have been left incomplete.
+type InterruptedTask struct {
+	// ID is the task's ID.
+	ID int `json:"id" yaml:"id"`
+
+	// Service is the name of the service that executed the task.
+	Service string `json:"service" yaml:"service"`
+
+	// Route is the name of the route that created the task, ex.
+	// volumeAttach.
+	Route string `json:"route,omitempty" yaml:",omitempty"`
+
+	// VolumeID is the ID of the volume on which the task operated.
+	VolumeID string `json:"volumeID,omitempty" yaml:"volumeID,omitempty"`
+
+	// SnapshotID is the ID of the snapshot on which the task operated.
+	SnapshotID string `json:"snapshotID,omitempty" yaml:"snapshotID,omitempty"`
+
+	// InstanceID is the ID of the instance on whose behalf the task was
+	// executed.
+	InstanceID string `json:"instanceID,omitempty" yaml:"instanceID,omitempty"`
+
+	// State is the state of the task when it was interrupted.
+	State TaskState `json:"state"`
+
+	// QueueTime is the time stamp when the task was created.
+	QueueTime int64 `json:"queueTime" yaml:"queueTime"`
+
+	// InterruptTime is the time stamp when the task was interrupted.
+	InterruptTime int64 `json:"interruptTime" yaml:"interruptTime"`
+}
+
+// EventType is the type of an event.
+type EventType string
+
+const (
+	// EventVolumeCreated occurs when a volume is created.
+	EventVolumeCreated EventType = "volumeCreated"
+
+	// EventVolumeRemoved occurs when a volume is removed.
+	EventVolumeRemoved EventType = "volumeRemoved"
+
+	// EventVolumeRecycled occurs when a volume is moved to the recycle bin
+	// rather than removed.
+	EventVolumeRecycled EventType = "volumeRecycled"
+
+	// EventVolumeRestored occurs when a volume is restored from the recycle
+	// bin.
+	EventVolumeRestored EventType = "volumeRestored"
+
+	// EventVolumeImported occurs when a volume that was created outside of
+	// libStorage is imported.
+	EventVolumeImported EventType = "volumeImported"
+
+	// EventVolumeUnmanaged occurs when a volume is released from libStorage
+	// management without being removed.
+	EventVolumeUnmanaged EventType = "volumeUnmanaged"
+
+	// EventVolumeResized occurs when a volume's size is changed.
+	EventVolumeResized EventType = "volumeResized"
+
+	// EventVolumeRetyped occurs when a volume's type is changed.
+	EventVolumeRetyped EventType = "volumeRetyped"
+
+	// EventVolumeAttached occurs when a volume is attached.
+	EventVolumeAttached EventType = "volumeAttached"
+
+	// EventVolumeDetached occurs when a volume is detached.
+	EventVolumeDetached EventType = "volumeDetached"
+
+	// EventVolumeForceDetached occurs when a volume is forcefully detached
+	// from an instance, such as an instance that has failed. The event's
+	// fields include the instance's ID and the volume's device on the
+	// instance if they are known so that the instance's client can remove
+	// the device's mounts and mapping.
+	EventVolumeForceDetached EventType = "volumeForceDetached"
+
+	// EventVolumeMounted occurs when the client of the instance to which a
+	// volume is attached reports that the volume was mounted.
+	EventVolumeMounted EventType = "volumeMounted"
+
+	// EventVolumeMountFailed occurs when the client of the instance to which
+	// a volume is attached reports that the volume could not be mounted.
+	EventVolumeMountFailed EventType = "volumeMountFailed"
+
+	// EventVolumeMountUnreported occurs when the mount token issued for an
+	// attachment expires before the instance's client reports whether or
+	// not the volume was mounted.
+	EventVolumeMountUnreported EventType = "volumeMountUnreported"
+
+	// EventSnapshotCreated occurs when a snapshot is created.
+	EventSnapshotCreated EventType = "snapshotCreated"
+
+	// EventSnapshotRemoved occurs when a snapshot is removed.
+	EventSnapshotRemoved EventType = "snapshotRemoved"
+
+	// EventTaskInterrupted occurs when a server starts and finds a task
+	// that did not complete before the server was previously shut down.
+	EventTaskInterrupted EventType = "taskInterrupted"
+)
+
+const (
+	// EventFieldInstanceID is the name of the event field that holds the ID
+	// of the instance to which the event applies.
+	EventFieldInstanceID = "instanceID"
+
+	// EventFieldDeviceName is the name of the event field that holds the
+	// name of the device to which the event applies.
+	EventFieldDeviceName = "deviceName"
+
+	// EventFieldTenant is the name of the event field that holds the tenant
+	// of the request that caused the event.
+	EventFieldTenant = "tenant"
+
+	// EventFieldTaskID is the name of the event field that holds the ID of
+	// the task to which the event applies.
+	EventFieldTaskID = "taskID"
+
+	// EventFieldRoute is the name of the event field that holds the name of
+	// the route that created the task to which the event applies.
+	EventFieldRoute = "route"
+
+	// EventFieldReason is the name of the event field that holds the reason
+	// a client gave for the request that caused the event.
+	EventFieldReason = "reason"
+
+	// EventFieldRequestedBy is the name of the event field that holds who a
+	// client said requested the change that caused the event.
+	EventFieldRequestedBy = "requestedBy"
+
+	// EventFieldUser is the name of the event field that holds the
+	// authenticated identity of the client whose request caused the event.
+	EventFieldUser = "user"
+
+	// EventFieldMountPoint is the name of the event field that holds the
+	// path at which a volume was mounted.
+	EventFieldMountPoint = "mountPoint"
+
+	// EventFieldError is the name of the event field that holds the error
+	// that caused an operation to fail.
+	EventFieldError = "error"
+)
+
+// Event describes a change to a storage resource.
+type Event struct {
+	// ID is the event's ID. Event IDs increase monotonically within an
+	// epoch.
+	ID int64 `json:"id" yaml:"id"`
+
+	// Epoch identifies the server process that published the event. A
+	// server begins a new epoch, and its event IDs begin again at 1, each
+	// time it starts.
+	Epoch string `json:"epoch,omitempty" yaml:"epoch,omitempty"`
+
+	// Time is the time stamp when the event occurred.
+	Time int64 `json:"time" yaml:"time"`
+
+	// Type is the event's type.
+	Type EventType `json:"type" yaml:"type"`
+
+	// Service is the name of the service to which the event's resource
+	// belongs.
+	Service string `json:"service" yaml:"service"`
+
+	// VolumeID is the ID of the volume to which the event applies.
+	VolumeID string `json:"volumeID,omitempty" yaml:"volumeID,omitempty"`
+
+	// SnapshotID is the ID of the snapshot to which the event applies.
+	SnapshotID string `json:"snapshotID,omitempty" yaml:"snapshotID,omitempty"`
+
+	// Fields are additional properties that can be defined for this type.
+	Fields map[string]string `json:"fields,omitempty" yaml:",omitempty"`
+}
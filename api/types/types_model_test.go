@@ -1,9 +1,11 @@
 package types
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v1"
 )
 
@@ -85,3 +87,64 @@ func TestInstanceWithOnlyInstanceIDMarshalToYAML(t *testing.T) {
 
 	fmt.Println(string(out))
 }
+
+func TestVolumeAttachmentDescribe(t *testing.T) {
+	a := &VolumeAttachment{DeviceName: "/dev/sdb", LUN: "1"}
+	a.Describe(nil)
+	assert.Equal(t, "", a.BusType)
+
+	a.Describe(&LocalDevice{
+		BusType: "scsi",
+		LUN:     "3",
+		WWN:     "0x5000c500a1b2c3d4",
+		Serial:  "disk-0123",
+		ByPath:  "/dev/disk/by-path/pci-0000:00:04.0-scsi-0:0:1:3",
+	})
+	assert.Equal(t, "scsi", a.BusType)
+	assert.Equal(t, "1", a.LUN)
+	assert.Equal(t, "0x5000c500a1b2c3d4", a.WWN)
+	assert.Equal(t, "disk-0123", a.Serial)
+	assert.Equal(t,
+		"/dev/disk/by-path/pci-0000:00:04.0-scsi-0:0:1:3", a.ByPath)
+}
+
+func TestStorageCapabilitiesSupportsProvisioning(t *testing.T) {
+	caps := &StorageCapabilities{}
+	assert.False(t, caps.SupportsProvisioning(true))
+	assert.False(t, caps.SupportsProvisioning(false))
+
+	caps.Provisioning = []string{ProvisioningThin}
+	assert.True(t, caps.SupportsProvisioning(true))
+	assert.False(t, caps.SupportsProvisioning(false))
+
+	caps.Provisioning = append(caps.Provisioning, ProvisioningThick)
+	assert.True(t, caps.SupportsProvisioning(false))
+}
+
+func TestTaskUnmarshalJSON(t *testing.T) {
+
+	task := &Task{}
+	assert.NoError(t, json.Unmarshal(
+		[]byte(`{"id":1,"state":"success","result":"hi"}`), task))
+	assert.Equal(t, 1, task.ID)
+	assert.Equal(t, TaskState(TaskStateSuccess), task.State)
+	assert.Equal(t, "hi", task.Result)
+	assert.NoError(t, task.Error)
+
+	task = &Task{}
+	assert.NoError(t, json.Unmarshal(
+		[]byte(`{"id":2,"state":"error","error":"failed"}`), task))
+	assert.Equal(t, 2, task.ID)
+	assert.EqualError(t, task.Error, "failed")
+
+	task = &Task{}
+	assert.NoError(t, json.Unmarshal(
+		[]byte(`{"id":3,"state":"error","error":{"message":"failed"}}`),
+		task))
+	assert.EqualError(t, task.Error, "failed")
+
+	task = &Task{}
+	assert.NoError(t, json.Unmarshal(
+		[]byte(`{"id":4,"state":"error","error":{"msg":"failed"}}`), task))
+	assert.EqualError(t, task.Error, "failed")
+}
@@ -3,6 +3,8 @@ package types
 import (
 	"io"
 	"strings"
+
+	gofig "github.com/akutz/gofig/types"
 )
 
 // EndpointType is a type of endpoint.
@@ -52,4 +54,9 @@ type Server interface {
 
 	// Addrs returns the server's configured endpoint addresses.
 	Addrs() []string
+
+	// Reload updates the server's storage services and TLS certificates
+	// from the provided config. If the config is nil then the default
+	// configuration files are read again.
+	Reload(config gofig.Config) error
 }
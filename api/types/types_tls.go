@@ -1,6 +1,9 @@
 package types
 
-import "crypto/tls"
+import (
+	"crypto/tls"
+	"time"
+)
 
 // TLSConfig is a custom TLS configuration that includes the concept of a
 // peer certificate's fingerprint.
@@ -9,4 +12,32 @@ type TLSConfig struct {
 
 	// PeerFingerprint is the expected SHA256 fingerprint of a peer certificate.
 	PeerFingerprint []byte
+
+	// CertFile is the path to the file from which the certificate was loaded.
+	CertFile string
+
+	// KeyFile is the path to the file from which the key was loaded.
+	KeyFile string
+
+	// ReloadInterval is how often the cert and key files are checked for
+	// changes. A value of zero disables reloading the files.
+	ReloadInterval time.Duration
+
+	// SPIFFETrustDomain is the trust domain to which a peer's SPIFFE ID must
+	// belong.
+	SPIFFETrustDomain string
+
+	// SPIFFEIDs are the SPIFFE IDs a peer is allowed to present.
+	SPIFFEIDs []string
+
+	// SPIFFEWorkloadAPI is the address of the SPIFFE Workload API from which
+	// the X.509-SVID and trust bundle are fetched in place of the cert, key,
+	// and trusted certs files.
+	SPIFFEWorkloadAPI string
+}
+
+// RequiresSPIFFEID returns a flag indicating whether or not a peer must
+// present a SPIFFE ID.
+func (c *TLSConfig) RequiresSPIFFEID() bool {
+	return c.SPIFFETrustDomain != "" || len(c.SPIFFEIDs) > 0
 }
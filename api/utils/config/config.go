@@ -17,31 +17,38 @@ import (
 func NewConfig() (gofig.Config, error) {
 	config := registry.NewConfig()
 
-	etcYML := types.Etc.Join("config.yml")
-	etcYAML := types.Etc.Join("config.yaml")
-
-	userHomeDir := gotil.HomeDir()
-	usrYML := path.Join(userHomeDir, "config.yml")
-	usrYAML := path.Join(userHomeDir, "config.yaml")
-
-	if err := readConfigFile(config, etcYML); err != nil {
-		return nil, err
-	}
-	if err := readConfigFile(config, etcYAML); err != nil {
-		return nil, err
-	}
-	if err := readConfigFile(config, usrYML); err != nil {
-		return nil, err
-	}
-	if err := readConfigFile(config, usrYAML); err != nil {
+	if err := ReadFiles(config); err != nil {
 		return nil, err
 	}
 
 	types.BackCompat(config)
+	BindEnv(config)
 
 	return config, nil
 }
 
+// ReadFiles reads the configuration files returned by Files into a config.
+func ReadFiles(config gofig.Config) error {
+	for _, f := range Files() {
+		if err := readConfigFile(config, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Files returns the paths of the configuration files read by NewConfig in
+// the order in which they are read. The files may not exist.
+func Files() []string {
+	userHomeDir := gotil.HomeDir()
+	return []string{
+		types.Etc.Join("config.yml"),
+		types.Etc.Join("config.yaml"),
+		path.Join(userHomeDir, "config.yml"),
+		path.Join(userHomeDir, "config.yaml"),
+	}
+}
+
 // UpdateLogLevel updates the log level based on the config.
 func UpdateLogLevel(config gofig.Config) {
 	ll, err := log.ParseLevel(config.GetString(types.ConfigLogLevel))
@@ -68,3 +75,9 @@ func DeviceAttachTimeout(config gofig.Config) time.Duration {
 func DeviceScanType(config gofig.Config) types.DeviceScanType {
 	return types.ParseDeviceScanType(config.GetInt(types.ConfigDeviceScanType))
 }
+
+// DeviceStableNames gets a flag indicating whether or not the persistent
+// symlinks of devices are returned in place of their names.
+func DeviceStableNames(config gofig.Config) bool {
+	return config.GetBool(types.ConfigDeviceStableNames)
+}
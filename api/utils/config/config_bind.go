@@ -0,0 +1,99 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"sort"
+	"strings"
+
+	gofig "github.com/akutz/gofig/types"
+	flag "github.com/spf13/pflag"
+
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// EnvVarName returns the name of the environment variable that overrides a
+// config key, ex. LIBSTORAGE_TLS_CERTFILE for libstorage.tls.certFile.
+func EnvVarName(key string) string {
+	return strings.ToUpper(strings.Replace(key, ".", "_", -1))
+}
+
+// FlagName returns the name of the command line flag that overrides a config
+// key, ex. libstorageTlsCertFile for libstorage.tls.certFile.
+func FlagName(key string) string {
+	parts := strings.Split(key, ".")
+	buf := &bytes.Buffer{}
+	for i, p := range parts {
+		if p == "" {
+			continue
+		}
+		if i == 0 {
+			buf.WriteString(strings.ToLower(p[:1]))
+		} else {
+			buf.WriteString(strings.ToUpper(p[:1]))
+		}
+		buf.WriteString(p[1:])
+	}
+	return buf.String()
+}
+
+// unboundKeys returns the keys described by the registered config schemas
+// that are not bound to environment variables and flags by the config. Keys
+// with values of any type are omitted since they cannot be set from a
+// single string.
+func unboundKeys(config gofig.Config) []string {
+	bound := map[string]bool{}
+	for _, fs := range config.FlagSets() {
+		fs.VisitAll(func(f *flag.Flag) {
+			bound[f.Name] = true
+		})
+	}
+
+	uniq := map[string]bool{}
+	for _, s := range registry.ConfigSchemas() {
+		for k, t := range s.Keys {
+			if t == types.ConfigKeyAny || bound[FlagName(k)] {
+				continue
+			}
+			uniq[k] = true
+		}
+	}
+
+	keys := make([]string, 0, len(uniq))
+	for k := range uniq {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// BindEnv sets the keys described by the registered config schemas that the
+// config does not bind itself to the values of their environment variables.
+func BindEnv(config gofig.Config) {
+	for _, k := range unboundKeys(config) {
+		if v, ok := os.LookupEnv(EnvVarName(k)); ok {
+			config.Set(k, v)
+		}
+	}
+}
+
+// FlagSet returns a flag set with a flag for each key described by the
+// registered config schemas that the config does not bind itself.
+func FlagSet(config gofig.Config) *flag.FlagSet {
+	fs := flag.NewFlagSet("libStorage Options", flag.ContinueOnError)
+	for _, k := range unboundKeys(config) {
+		fs.String(FlagName(k), "", "Sets "+k)
+	}
+	return fs
+}
+
+// BindFlags sets the config keys of the flags in the flag set, returned by
+// FlagSet, that were set on the command line.
+func BindFlags(config gofig.Config, fs *flag.FlagSet) {
+	for _, k := range unboundKeys(config) {
+		if f := fs.Lookup(FlagName(k)); f != nil && f.Changed {
+			config.Set(k, f.Value.String())
+		}
+	}
+}
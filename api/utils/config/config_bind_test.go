@@ -0,0 +1,71 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	gofigCore "github.com/akutz/gofig"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+func TestEnvVarAndFlagNames(t *testing.T) {
+	tests := []struct {
+		key  string
+		env  string
+		flag string
+	}{
+		{"libstorage.tls.certFile",
+			"LIBSTORAGE_TLS_CERTFILE", "libstorageTlsCertFile"},
+		{"rbd.defaultPool", "RBD_DEFAULTPOOL", "rbdDefaultPool"},
+		{"Isilon.endpoint", "ISILON_ENDPOINT", "isilonEndpoint"},
+		{"scaleio.pools..name", "SCALEIO_POOLS__NAME", "scaleioPoolsName"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.env, EnvVarName(tt.key), tt.key)
+		assert.Equal(t, tt.flag, FlagName(tt.key), tt.key)
+	}
+}
+
+func TestBindEnvAndFlags(t *testing.T) {
+	registry.RegisterConfigSchema(&types.ConfigSchema{
+		Name:       "bindtest",
+		Namespaces: []string{"bindtest"},
+		Keys: map[string]types.ConfigKeyType{
+			"bindtest.endpoint":  types.ConfigKeyString,
+			"bindtest.pool.name": types.ConfigKeyString,
+			"bindtest.insecure":  types.ConfigKeyBool,
+			"bindtest.opts":      types.ConfigKeyAny,
+		},
+	})
+	config := gofigCore.New()
+
+	os.Setenv("BINDTEST_ENDPOINT", "https://10.0.0.1")
+	defer os.Unsetenv("BINDTEST_ENDPOINT")
+	BindEnv(config)
+	assert.Equal(t, "https://10.0.0.1", config.GetString("bindtest.endpoint"))
+	assert.Equal(t, "", config.GetString("bindtest.pool.name"))
+
+	fs := FlagSet(config)
+	for _, name := range []string{
+		"bindtestEndpoint", "bindtestPoolName", "bindtestInsecure",
+	} {
+		assert.NotNil(t, fs.Lookup(name), name)
+	}
+	// keys of any type cannot be set from a single string
+	assert.Nil(t, fs.Lookup("bindtestOpts"))
+
+	if !assert.NoError(t, fs.Parse([]string{
+		"--bindtestPoolName=gold", "--bindtestInsecure=true",
+	})) {
+		t.FailNow()
+	}
+	BindFlags(config, fs)
+	assert.Equal(t, "gold", config.GetString("bindtest.pool.name"))
+	assert.True(t, config.GetBool("bindtest.insecure"))
+
+	// flags that are not set do not override the environment
+	assert.Equal(t, "https://10.0.0.1", config.GetString("bindtest.endpoint"))
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	gofig "github.com/akutz/gofig/types"
+)
+
+// RedactedValue replaces the values of secret keys in the effective config.
+const RedactedValue = "******"
+
+// secretKeyParts are the parts of the names of keys with secret values.
+var secretKeyParts = []string{
+	"password",
+	"secret",
+	"token",
+	"accesskey",
+	"signingkey",
+	"credential",
+}
+
+// Effective returns the merged settings of a config, with the values of keys
+// that may hold secrets, such as passwords and access keys, redacted.
+func Effective(config gofig.Config) map[string]interface{} {
+	return redactMap(config.AllSettings())
+}
+
+func redactMap(m map[string]interface{}) map[string]interface{} {
+	rm := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if isSecretKey(k) && v != nil && v != "" {
+			rm[k] = RedactedValue
+			continue
+		}
+		rm[k] = redactValue(v)
+	}
+	return rm
+}
+
+func redactValue(v interface{}) interface{} {
+	switch tv := v.(type) {
+	case map[string]interface{}:
+		return redactMap(tv)
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(tv))
+		for k, v := range tv {
+			m[fmt.Sprintf("%v", k)] = v
+		}
+		return redactMap(m)
+	case []interface{}:
+		l := make([]interface{}, len(tv))
+		for i, v := range tv {
+			l[i] = redactValue(v)
+		}
+		return l
+	}
+	return v
+}
+
+func isSecretKey(key string) bool {
+	lk := strings.ToLower(key)
+	for _, p := range secretKeyParts {
+		if strings.Contains(lk, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,297 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// Validate validates a configuration against the registered config schemas.
+// Keys under a schema's namespaces that are not described by the schema,
+// values whose types do not match their keys' types, and keys that are
+// required by the storage drivers of the configured services but have no
+// values are all reported by the returned error.
+func Validate(config gofig.Config) error {
+	v := newConfigValidator(registry.ConfigSchemas())
+	v.walk("", "", config.AllSettings())
+	v.validateServices(config)
+
+	if len(v.problems) == 0 {
+		return nil
+	}
+	sort.Strings(v.problems)
+	return goof.WithField(
+		"problems", v.problems,
+		"invalid config: "+strings.Join(v.problems, "; "))
+}
+
+type configValidator struct {
+	schemas    []*types.ConfigSchema
+	keys       map[string]string
+	keyTypes   map[string]types.ConfigKeyType
+	parents    map[string]bool
+	namespaces []string
+	problems   []string
+}
+
+func newConfigValidator(schemas []*types.ConfigSchema) *configValidator {
+	v := &configValidator{
+		schemas:  schemas,
+		keys:     map[string]string{},
+		keyTypes: map[string]types.ConfigKeyType{},
+		parents:  map[string]bool{},
+	}
+	for _, s := range schemas {
+		for k, t := range s.Keys {
+			lk := strings.ToLower(k)
+			v.keys[lk] = k
+			v.keyTypes[lk] = t
+			parts := strings.Split(lk, ".")
+			for i := 1; i < len(parts); i++ {
+				v.parents[strings.Join(parts[:i], ".")] = true
+			}
+		}
+		for _, ns := range s.Namespaces {
+			v.namespaces = append(v.namespaces, strings.ToLower(ns))
+		}
+	}
+	return v
+}
+
+func (v *configValidator) problem(key, format string, args ...interface{}) {
+	v.problems = append(
+		v.problems, fmt.Sprintf("%s: %s", key, fmt.Sprintf(format, args...)))
+}
+
+// walk validates the settings nested under a path. The path is the settings'
+// location with respect to the schemas, and the name is their location in
+// the configuration, which differs for the settings of a service.
+func (v *configValidator) walk(
+	path, name string, settings map[string]interface{}) {
+
+	for k, val := range settings {
+		p, n := joinKey(path, strings.ToLower(k)), joinKey(name, k)
+		if p == strings.ToLower(types.ConfigServices) {
+			continue
+		}
+		v.validate(p, n, val)
+	}
+}
+
+func (v *configValidator) validate(path, name string, val interface{}) {
+	m, isMap := val.(map[string]interface{})
+
+	if t, ok := v.keyTypes[path]; ok && !(isMap && v.parents[path]) {
+		if !valueIsType(val, t) {
+			// the settings' keys are lower-case, so the described key is
+			// reported if the setting is not nested under a service
+			if name == path {
+				name = v.keys[path]
+			}
+			v.problem(name, "expected %s value, got %s",
+				article(t), describe(val))
+		}
+		return
+	}
+
+	inNamespace := v.inNamespace(path)
+
+	if isMap && (v.parents[path] || !inNamespace) {
+		v.walk(path, name, m)
+		return
+	}
+
+	if !inNamespace {
+		return
+	}
+
+	if s := v.suggest(path); s != "" {
+		v.problem(name, "unknown key, did you mean %s?", s)
+		return
+	}
+	v.problem(name, "unknown key")
+}
+
+// validateServices validates the settings of each configured storage service
+// and ensures the keys required by the services' storage drivers are set.
+func (v *configValidator) validateServices(config gofig.Config) {
+	servicesKey := types.ConfigServices
+
+	// a single service is created for the configured driver if no services
+	// are configured
+	services, ok := config.Get(servicesKey).(map[string]interface{})
+	if !ok {
+		driverName := config.GetString("libstorage.driver")
+		if driverName == "" {
+			if val := config.Get(servicesKey); val != nil {
+				v.problem(servicesKey, "expected a map of services, got %s",
+					describe(val))
+			}
+			return
+		}
+		services = map[string]interface{}{
+			driverName: map[string]interface{}{"driver": driverName},
+		}
+	}
+
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		prefix := joinKey(servicesKey, name)
+		settings, ok := services[name].(map[string]interface{})
+		if !ok && services[name] != nil {
+			v.problem(prefix, "expected a map of service settings, got %s",
+				describe(services[name]))
+			continue
+		}
+		for k, val := range settings {
+			if strings.EqualFold(k, "driver") {
+				continue
+			}
+			v.validate(strings.ToLower(k), joinKey(prefix, k), val)
+		}
+
+		scoped := config.Scope(prefix)
+		driverName := scoped.GetString("driver")
+		if driverName == "" {
+			driverName = scoped.GetString("libstorage.driver")
+		}
+		if driverName == "" {
+			driverName = scoped.GetString(types.ConfigStorageDriver)
+		}
+
+		for _, s := range v.schemas {
+			if s.StorageDriver == "" ||
+				!strings.EqualFold(s.StorageDriver, driverName) {
+				continue
+			}
+			for _, k := range s.Required {
+				if scoped.GetString(k) == "" {
+					v.problem(k,
+						"required by the %s storage driver of service %s",
+						s.StorageDriver, name)
+				}
+			}
+		}
+	}
+}
+
+func (v *configValidator) inNamespace(path string) bool {
+	for _, ns := range v.namespaces {
+		if path == ns || strings.HasPrefix(path, ns+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// suggest returns the described key most similar to an unknown key if the
+// keys differ by no more than two characters.
+func (v *configValidator) suggest(path string) string {
+	var (
+		best     string
+		bestDist = 3
+	)
+	for lk, k := range v.keys {
+		if d := editDistance(path, lk); d < bestDist ||
+			(d == bestDist && k < best) {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func valueIsType(val interface{}, t types.ConfigKeyType) bool {
+	if val == nil || t == types.ConfigKeyAny {
+		return true
+	}
+	switch tv := val.(type) {
+	case map[string]interface{}, map[interface{}]interface{}, []interface{}:
+		return false
+	case string:
+		switch t {
+		case types.ConfigKeyInt:
+			_, err := strconv.ParseInt(strings.TrimSpace(tv), 10, 64)
+			return err == nil
+		case types.ConfigKeyBool:
+			_, err := strconv.ParseBool(strings.TrimSpace(tv))
+			return err == nil
+		}
+		return true
+	case bool:
+		return t != types.ConfigKeyInt
+	case float32:
+		return t != types.ConfigKeyBool &&
+			(t != types.ConfigKeyInt || tv == float32(int64(tv)))
+	case float64:
+		return t != types.ConfigKeyBool &&
+			(t != types.ConfigKeyInt || tv == float64(int64(tv)))
+	}
+	return t != types.ConfigKeyBool
+}
+
+func article(t types.ConfigKeyType) string {
+	if t == types.ConfigKeyInt {
+		return "an integer"
+	}
+	return "a " + t.String()
+}
+
+func describe(val interface{}) string {
+	switch tv := val.(type) {
+	case map[string]interface{}, map[interface{}]interface{}:
+		return "a map"
+	case []interface{}:
+		return "a list"
+	case string:
+		return strconv.Quote(tv)
+	}
+	return fmt.Sprintf("%v", val)
+}
+
+// editDistance returns the Levenshtein distance between two strings.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(
+				prev[j]+1, minInt(curr[j-1]+1, prev[j-1]+cost))
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+var testSchemas = []*types.ConfigSchema{
+	{
+		Name:       "libstorage",
+		Namespaces: []string{"libstorage.server"},
+		Keys: map[string]types.ConfigKeyType{
+			"libstorage.server.maxRequestBodySize":  types.ConfigKeyInt,
+			"libstorage.server.compression.enabled": types.ConfigKeyBool,
+			"libstorage.server.endpoints":           types.ConfigKeyAny,
+		},
+	},
+	{
+		Name:          "rbd",
+		Namespaces:    []string{"rbd"},
+		StorageDriver: "rbd",
+		Keys: map[string]types.ConfigKeyType{
+			"rbd.defaultPool": types.ConfigKeyString,
+			"rbd.testModule":  types.ConfigKeyBool,
+		},
+	},
+}
+
+func TestConfigValidatorWalk(t *testing.T) {
+	server := func(settings map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"libstorage": map[string]interface{}{"server": settings},
+		}
+	}
+	rbd := func(settings map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"rbd": settings}
+	}
+
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		problems []string
+	}{
+		{
+			"valid",
+			server(map[string]interface{}{
+				"maxRequestBodySize": 1024,
+				"compression":        map[string]interface{}{"enabled": true},
+				"endpoints": map[string]interface{}{
+					"public": map[string]interface{}{"address": ":7979"},
+				},
+			}),
+			nil,
+		},
+		{
+			"integer string",
+			server(map[string]interface{}{"maxRequestBodySize": "1024"}),
+			nil,
+		},
+		{
+			"not an integer",
+			server(map[string]interface{}{"maxRequestBodySize": "1k"}),
+			[]string{`libstorage.server.maxRequestBodySize: ` +
+				`expected an integer value, got "1k"`},
+		},
+		{
+			"fractional integer",
+			server(map[string]interface{}{"maxRequestBodySize": 1.5}),
+			[]string{`libstorage.server.maxRequestBodySize: ` +
+				`expected an integer value, got 1.5`},
+		},
+		{
+			"not a boolean",
+			rbd(map[string]interface{}{"testModule": 5}),
+			[]string{`rbd.testModule: expected a boolean value, got 5`},
+		},
+		{
+			"map for scalar",
+			rbd(map[string]interface{}{
+				"defaultPool": map[string]interface{}{"name": "rbd"},
+			}),
+			[]string{`rbd.defaultPool: expected a string value, got a map`},
+		},
+		{
+			"misspelled key",
+			rbd(map[string]interface{}{"defaultPol": "rbd"}),
+			[]string{`rbd.defaultPol: unknown key, ` +
+				`did you mean rbd.defaultPool?`},
+		},
+		{
+			"unknown key",
+			rbd(map[string]interface{}{"monitors": "10.0.0.1"}),
+			[]string{`rbd.monitors: unknown key`},
+		},
+		{
+			"outside namespaces",
+			map[string]interface{}{
+				"ebs": map[string]interface{}{"anything": 1},
+			},
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		v := newConfigValidator(testSchemas)
+		v.walk("", "", tt.settings)
+		assert.Equal(t, tt.problems, v.problems, tt.name)
+	}
+}
+
+func TestValueIsType(t *testing.T) {
+	tests := []struct {
+		val interface{}
+		typ types.ConfigKeyType
+		ok  bool
+	}{
+		{nil, types.ConfigKeyInt, true},
+		{"abc", types.ConfigKeyString, true},
+		{"10", types.ConfigKeyInt, true},
+		{"ten", types.ConfigKeyInt, false},
+		{"true", types.ConfigKeyBool, true},
+		{"yes", types.ConfigKeyBool, false},
+		{true, types.ConfigKeyString, true},
+		{true, types.ConfigKeyInt, false},
+		{10, types.ConfigKeyInt, true},
+		{10, types.ConfigKeyBool, false},
+		{10.0, types.ConfigKeyInt, true},
+		{10.5, types.ConfigKeyInt, false},
+		{[]interface{}{"a"}, types.ConfigKeyString, false},
+		{[]interface{}{"a"}, types.ConfigKeyAny, true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.ok, valueIsType(tt.val, tt.typ),
+			"%v %s", tt.val, tt.typ)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		d    int
+	}{
+		{"", "", 0},
+		{"rbd", "", 3},
+		{"rbd.defaultpool", "rbd.defaultpool", 0},
+		{"rbd.defaultpol", "rbd.defaultpool", 1},
+		{"rbd.dfaultpoll", "rbd.defaultpool", 2},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.d, editDistance(tt.a, tt.b), "%s %s", tt.a, tt.b)
+	}
+}
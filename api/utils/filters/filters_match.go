@@ -0,0 +1,153 @@
+package filters
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// FieldFunc returns the value of an object's field and a flag indicating
+// whether or not the object has the field.
+type FieldFunc func(key string) (string, bool)
+
+// Match returns a flag indicating whether or not an object matches a filter.
+//
+// String comparisons are case-insensitive. The >= and <= operators compare
+// numerically when both operands are numbers, and the ~= operator matches
+// values that begin with the right operand.
+func Match(f *types.Filter, field FieldFunc) bool {
+	if f == nil {
+		return true
+	}
+
+	switch f.Op {
+	case filterAnd:
+		for _, c := range f.Children {
+			if !Match(c, field) {
+				return false
+			}
+		}
+		return true
+	case filterOr:
+		for _, c := range f.Children {
+			if Match(c, field) {
+				return true
+			}
+		}
+		return false
+	case filterNot:
+		return len(f.Children) == 1 && !Match(f.Children[0], field)
+	}
+
+	v, ok := field(f.Left)
+	if !ok {
+		return false
+	}
+
+	var (
+		lv = strings.ToLower(v)
+		rv = strings.ToLower(f.Right)
+	)
+
+	switch f.Op {
+	case filterPresent:
+		return true
+	case filterEqualityMatch:
+		return lv == rv
+	case filterSubstrings:
+		return strings.Contains(lv, rv)
+	case filterSubstringsPrefix:
+		return strings.HasSuffix(lv, rv)
+	case filterSubstringsPostfix, filterApproxMatch:
+		return strings.HasPrefix(lv, rv)
+	case filterGreaterOrEqual:
+		return compare(lv, rv) >= 0
+	case filterLessOrEqual:
+		return compare(lv, rv) <= 0
+	}
+	return false
+}
+
+// compare compares two values numerically if both are numbers, otherwise
+// lexically.
+func compare(l, r string) int {
+	lf, lerr := strconv.ParseFloat(l, 64)
+	rf, rerr := strconv.ParseFloat(r, 64)
+	if lerr != nil || rerr != nil {
+		return strings.Compare(l, r)
+	}
+	switch {
+	case lf < rf:
+		return -1
+	case lf > rf:
+		return 1
+	}
+	return 0
+}
+
+// VolumeField returns a FieldFunc for a volume. A key that is not one of the
+// volume's properties is looked up in the volume's fields.
+func VolumeField(v *types.Volume) FieldFunc {
+	return func(key string) (string, bool) {
+		switch strings.ToLower(key) {
+		case "id":
+			return v.ID, true
+		case "name":
+			return v.Name, true
+		case "size":
+			return strconv.FormatInt(v.Size, 10), true
+		case "iops":
+			return strconv.FormatInt(v.IOPS, 10), true
+		case "type":
+			return v.Type, true
+		case "status":
+			return v.Status, true
+		case "availabilityzone":
+			return v.AvailabilityZone, true
+		case "encrypted":
+			return strconv.FormatBool(v.Encrypted), true
+		}
+		return fieldValue(v.Fields, key)
+	}
+}
+
+// SnapshotField returns a FieldFunc for a snapshot. The size of a snapshot
+// is the size of its volume, and its creation time is its start time. A key
+// that is not one of the snapshot's properties is looked up in the
+// snapshot's fields.
+func SnapshotField(s *types.Snapshot) FieldFunc {
+	return func(key string) (string, bool) {
+		switch strings.ToLower(key) {
+		case "id":
+			return s.ID, true
+		case "name":
+			return s.Name, true
+		case "size", "volumesize":
+			return strconv.FormatInt(s.VolumeSize, 10), true
+		case "createdat", "starttime":
+			return strconv.FormatInt(s.StartTime, 10), true
+		case "volumeid":
+			return s.VolumeID, true
+		case "status":
+			return s.Status, true
+		case "description":
+			return s.Description, true
+		case "encrypted":
+			return strconv.FormatBool(s.Encrypted), true
+		}
+		return fieldValue(s.Fields, key)
+	}
+}
+
+func fieldValue(fields map[string]string, key string) (string, bool) {
+	if v, ok := fields[key]; ok {
+		return v, true
+	}
+	for k, v := range fields {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return "", false
+}
@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/types"
 )
 
 func TestCompilePresent(t *testing.T) {
@@ -145,3 +147,65 @@ func TestCompileInequalityMatch(t *testing.T) {
 	assert.EqualValues(t, "department", f.Children[1].Left)
 	assert.EqualValues(t, "finance", f.Children[1].Right)
 }
+
+func TestMatchVolume(t *testing.T) {
+	vol := &types.Volume{
+		ID:     "vol-000",
+		Name:   "Database",
+		Size:   250,
+		Fields: map[string]string{"env": "prod"},
+	}
+
+	tests := map[string]bool{
+		`(size>=100)`:                      true,
+		`(size>=1000)`:                     false,
+		`(size<=250)`:                      true,
+		`(name~=data)`:                     true,
+		`(name~=base)`:                     false,
+		`(name=database)`:                  true,
+		`(name=*base)`:                     true,
+		`(name=Data*)`:                     true,
+		`(name=*tab*)`:                     true,
+		`(env=prod)`:                       true,
+		`(owner=*)`:                        false,
+		`(&(size>=100)(!(env=dev)))`:       true,
+		`(|(size>=1000)(name~=db))`:        false,
+		`(|(size>=1000)(env=prod))`:        true,
+		`(&(name~=data)(size<=100))`:       false,
+		`(!(&(name~=data)(size<=100)))`:    true,
+		`(%26(name~=data)(size%3E%3D100))`: true,
+	}
+
+	for sf, expected := range tests {
+		f, err := CompileFilter(sf)
+		if err != nil {
+			t.Fatalf("%s: %v", sf, err)
+		}
+		assert.Equal(t, expected, Match(f, VolumeField(vol)), sf)
+	}
+}
+
+func TestMatchSnapshot(t *testing.T) {
+	snap := &types.Snapshot{
+		ID:         "snap-000",
+		Name:       "nightly",
+		VolumeSize: 10,
+		StartTime:  1500000000,
+	}
+
+	tests := map[string]bool{
+		`(size>=9)`:                  true,
+		`(size>=100)`:                false,
+		`(createdAt>=1400000000)`:    true,
+		`(createdAt<=1400000000)`:    false,
+		`(&(name~=night)(size<=10))`: true,
+	}
+
+	for sf, expected := range tests {
+		f, err := CompileFilter(sf)
+		if err != nil {
+			t.Fatalf("%s: %v", sf, err)
+		}
+		assert.Equal(t, expected, Match(f, SnapshotField(snap)), sf)
+	}
+}
@@ -0,0 +1,282 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	gofig "github.com/akutz/gofig/types"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+const (
+	// KindStorage is the kind of the operations of storage drivers.
+	KindStorage = "storage"
+
+	// KindOS is the kind of the operations of OS drivers.
+	KindOS = "os"
+)
+
+// buckets are the upper bounds, in seconds, of the driver operation duration
+// histograms.
+var buckets = []float64{
+	.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120,
+}
+
+type opKey struct {
+	kind   string
+	driver string
+	op     string
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+	errors uint64
+}
+
+type serviceTasks struct {
+	queued   int64
+	running  int64
+	rejected uint64
+}
+
+var (
+	opsLock sync.Mutex
+	ops     = map[opKey]*histogram{}
+
+	tasksLock sync.Mutex
+	tasks     = map[string]*serviceTasks{}
+
+	thresholdLock sync.RWMutex
+	threshold     time.Duration
+)
+
+// Init configures the duration above which driver operations are logged as
+// slow. A threshold of zero disables the logging of slow operations.
+func Init(config gofig.Config) {
+	dur, err := time.ParseDuration(
+		config.GetString(types.ConfigLogSlowOpThreshold))
+	if err != nil || dur < 0 {
+		dur = 0
+	}
+	thresholdLock.Lock()
+	defer thresholdLock.Unlock()
+	threshold = dur
+}
+
+// SlowOpThreshold returns the duration above which driver operations are
+// logged as slow.
+func SlowOpThreshold() time.Duration {
+	thresholdLock.RLock()
+	defer thresholdLock.RUnlock()
+	return threshold
+}
+
+// StartDriverOp starts timing a driver operation. The returned function
+// records the operation's duration and whether or not it failed, and it logs
+// a warning if the operation took longer than the slow operation threshold.
+func StartDriverOp(
+	ctx types.Context, kind, driver, op string) func(error) {
+
+	start := time.Now()
+	return func(err error) {
+		dur := time.Since(start)
+		ObserveDriverOp(kind, driver, op, dur, err)
+
+		if t := SlowOpThreshold(); t > 0 && dur > t {
+			ctx.WithFields(log.Fields{
+				"kind":      kind,
+				"driver":    driver,
+				"op":        op,
+				"duration":  dur,
+				"threshold": t,
+			}).Warn("slow driver operation")
+		}
+	}
+}
+
+// ObserveDriverOp records the duration of a driver operation.
+func ObserveDriverOp(
+	kind, driver, op string, dur time.Duration, err error) {
+
+	key := opKey{kind: kind, driver: driver, op: op}
+	secs := dur.Seconds()
+
+	opsLock.Lock()
+	defer opsLock.Unlock()
+
+	h, ok := ops[key]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(buckets))}
+		ops[key] = h
+	}
+	for i, b := range buckets {
+		if secs <= b {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += secs
+	if err != nil {
+		h.errors++
+	}
+}
+
+func getServiceTasks(service string) *serviceTasks {
+	st, ok := tasks[service]
+	if !ok {
+		st = &serviceTasks{}
+		tasks[service] = st
+	}
+	return st
+}
+
+// TaskQueued records that a task was added to a service's task queue.
+func TaskQueued(service string) {
+	tasksLock.Lock()
+	defer tasksLock.Unlock()
+	getServiceTasks(service).queued++
+}
+
+// TaskRejected records that a task recorded as queued could not be added to
+// a service's task queue because the queue was full.
+func TaskRejected(service string) {
+	tasksLock.Lock()
+	defer tasksLock.Unlock()
+	st := getServiceTasks(service)
+	st.queued--
+	st.rejected++
+}
+
+// TaskStarted records that a service's worker removed a task from the
+// service's task queue and started executing it.
+func TaskStarted(service string) {
+	tasksLock.Lock()
+	defer tasksLock.Unlock()
+	st := getServiceTasks(service)
+	st.queued--
+	st.running++
+}
+
+// TaskCompleted records that a service's worker completed a task.
+func TaskCompleted(service string) {
+	tasksLock.Lock()
+	defer tasksLock.Unlock()
+	getServiceTasks(service).running--
+}
+
+// WriteText writes the recorded driver operation and task queue metrics to
+// the writer in the Prometheus text exposition format.
+func WriteText(w io.Writer) error {
+
+	opsLock.Lock()
+	keys := make([]opKey, 0, len(ops))
+	snap := make(map[opKey]histogram, len(ops))
+	for k, h := range ops {
+		keys = append(keys, k)
+		c := *h
+		c.counts = append([]uint64(nil), h.counts...)
+		snap[k] = c
+	}
+	opsLock.Unlock()
+
+	tasksLock.Lock()
+	services := make([]string, 0, len(tasks))
+	taskSnap := make(map[string]serviceTasks, len(tasks))
+	for k, st := range tasks {
+		services = append(services, k)
+		taskSnap[k] = *st
+	}
+	tasksLock.Unlock()
+
+	sort.Sort(byKey(keys))
+	sort.Strings(services)
+
+	const (
+		durName      = "libstorage_driver_op_duration_seconds"
+		errName      = "libstorage_driver_op_errors_total"
+		queuedName   = "libstorage_service_tasks_queued"
+		runningName  = "libstorage_service_tasks_running"
+		rejectedName = "libstorage_service_tasks_rejected_total"
+	)
+
+	var err error
+	printf := func(format string, a ...interface{}) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, format, a...)
+		}
+	}
+
+	printf("# HELP %s The duration of driver operations.\n", durName)
+	printf("# TYPE %s histogram\n", durName)
+	for _, k := range keys {
+		h := snap[k]
+		labels := k.labels()
+		for i, b := range buckets {
+			printf("%s_bucket{%s,le=\"%s\"} %d\n",
+				durName, labels,
+				strconv.FormatFloat(b, 'g', -1, 64), h.counts[i])
+		}
+		printf("%s_bucket{%s,le=\"+Inf\"} %d\n", durName, labels, h.count)
+		printf("%s_sum{%s} %s\n",
+			durName, labels, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		printf("%s_count{%s} %d\n", durName, labels, h.count)
+	}
+
+	printf("# HELP %s The number of driver operations that failed.\n",
+		errName)
+	printf("# TYPE %s counter\n", errName)
+	for _, k := range keys {
+		printf("%s{%s} %d\n", errName, k.labels(), snap[k].errors)
+	}
+
+	printf("# HELP %s The number of tasks in a service's queue.\n",
+		queuedName)
+	printf("# TYPE %s gauge\n", queuedName)
+	for _, svc := range services {
+		printf("%s{service=%q} %d\n", queuedName, svc, taskSnap[svc].queued)
+	}
+
+	printf("# HELP %s The number of a service's tasks being executed.\n",
+		runningName)
+	printf("# TYPE %s gauge\n", runningName)
+	for _, svc := range services {
+		printf("%s{service=%q} %d\n",
+			runningName, svc, taskSnap[svc].running)
+	}
+
+	printf("# HELP %s The number of tasks rejected by a full queue.\n",
+		rejectedName)
+	printf("# TYPE %s counter\n", rejectedName)
+	for _, svc := range services {
+		printf("%s{service=%q} %d\n",
+			rejectedName, svc, taskSnap[svc].rejected)
+	}
+
+	return err
+}
+
+func (k opKey) labels() string {
+	return fmt.Sprintf("kind=%q,driver=%q,op=%q", k.kind, k.driver, k.op)
+}
+
+type byKey []opKey
+
+func (s byKey) Len() int      { return len(s) }
+func (s byKey) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byKey) Less(i, j int) bool {
+	if s[i].kind != s[j].kind {
+		return s[i].kind < s[j].kind
+	}
+	if s[i].driver != s[j].driver {
+		return s[i].driver < s[j].driver
+	}
+	return s[i].op < s[j].op
+}
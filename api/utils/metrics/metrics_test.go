@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	gofigCore "github.com/akutz/gofig"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+func TestInit(t *testing.T) {
+	config := gofigCore.New()
+
+	config.Set(types.ConfigLogSlowOpThreshold, "250ms")
+	Init(config)
+	assert.Equal(t, 250*time.Millisecond, SlowOpThreshold())
+
+	config.Set(types.ConfigLogSlowOpThreshold, "invalid")
+	Init(config)
+	assert.Equal(t, time.Duration(0), SlowOpThreshold())
+}
+
+func TestWriteText(t *testing.T) {
+	ObserveDriverOp(KindStorage, "vfs", "VolumeCreate",
+		20*time.Millisecond, nil)
+	ObserveDriverOp(KindStorage, "vfs", "VolumeCreate",
+		3*time.Second, errors.New("failed"))
+	ObserveDriverOp(KindOS, "linux", "Mount", time.Millisecond, nil)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, WriteText(buf))
+	text := buf.String()
+	t.Log(text)
+
+	for _, line := range []string{
+		`libstorage_driver_op_duration_seconds_bucket{` +
+			`kind="storage",driver="vfs",op="VolumeCreate",le="0.01"} 0`,
+		`libstorage_driver_op_duration_seconds_bucket{` +
+			`kind="storage",driver="vfs",op="VolumeCreate",le="0.025"} 1`,
+		`libstorage_driver_op_duration_seconds_bucket{` +
+			`kind="storage",driver="vfs",op="VolumeCreate",le="5"} 2`,
+		`libstorage_driver_op_duration_seconds_count{` +
+			`kind="storage",driver="vfs",op="VolumeCreate"} 2`,
+		`libstorage_driver_op_errors_total{` +
+			`kind="storage",driver="vfs",op="VolumeCreate"} 1`,
+		`libstorage_driver_op_errors_total{` +
+			`kind="os",driver="linux",op="Mount"} 0`,
+	} {
+		assert.Contains(t, text, line+"\n")
+	}
+
+	assert.True(t,
+		strings.Index(text, `kind="os"`) < strings.Index(text, `kind="storage"`))
+}
+
+func TestTaskMetrics(t *testing.T) {
+	TaskQueued("ebs")
+	TaskQueued("ebs")
+	TaskQueued("ebs")
+	TaskRejected("ebs")
+	TaskStarted("ebs")
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, WriteText(buf))
+	text := buf.String()
+
+	assert.Contains(t, text, `libstorage_service_tasks_queued{service="ebs"} 1`)
+	assert.Contains(t, text, `libstorage_service_tasks_running{service="ebs"} 1`)
+	assert.Contains(t, text,
+		`libstorage_service_tasks_rejected_total{service="ebs"} 1`)
+
+	TaskCompleted("ebs")
+	buf.Reset()
+	assert.NoError(t, WriteText(buf))
+	assert.Contains(t, buf.String(),
+		`libstorage_service_tasks_running{service="ebs"} 0`)
+}
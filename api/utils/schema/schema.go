@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/cesanta/ucl"
@@ -26,6 +27,9 @@ var (
 	// resource.
 	VolumeAttachmentSchema = buildSchemaVar("volumeAttachment")
 
+	// VolumeStatsSchema is the JSON schema for the VolumeStats resource.
+	VolumeStatsSchema = buildSchemaVar("volumeStats")
+
 	// ServiceVolumeMapSchema is the JSON schema for the ServiceVolumeMap
 	// resource.
 	ServiceVolumeMapSchema = buildSchemaVar("serviceVolumeMap")
@@ -43,12 +47,31 @@ var (
 	// SnapshotSchema is the JSON schema for the Snapshot resource.
 	SnapshotSchema = buildSchemaVar("snapshot")
 
+	// SnapshotGroupSchema is the JSON schema for the SnapshotGroup resource.
+	SnapshotGroupSchema = buildSchemaVar("snapshotGroup")
+
 	// ServiceInfoSchema is the JSON schema for the ServiceInfo resource.
 	ServiceInfoSchema = buildSchemaVar("serviceInfo")
 
 	// ServiceInfoMapSchema is the JSON schemea for a map[string]*ServiceInfo.
 	ServiceInfoMapSchema = buildSchemaVar("serviceInfoMap")
 
+	// ServiceRegistrationSchema is the JSON schema for the
+	// ServiceRegistration resource.
+	ServiceRegistrationSchema = buildSchemaVar("serviceRegistration")
+
+	// StorageCapabilitiesSchema is the JSON schema for the
+	// StorageCapabilities resource.
+	StorageCapabilitiesSchema = buildSchemaVar("storageCapabilities")
+
+	// FaultInjectionSchema is the JSON schema for the FaultInjection
+	// resource.
+	FaultInjectionSchema = buildSchemaVar("faultInjection")
+
+	// ValidateResponseSchema is the JSON schema for a response to a request
+	// submitted in validate mode.
+	ValidateResponseSchema = buildSchemaVar("validateResponse")
+
 	// DriverInfoSchema is the JSON schema for the DriverInfo resource.
 	DriverInfoSchema = buildSchemaVar("driverInfo")
 
@@ -63,6 +86,10 @@ var (
 	// request.
 	VolumeCopyRequestSchema = buildSchemaVar("volumeCopyRequest")
 
+	// VolumeImportRequestSchema is the JSON schema for a Volume import
+	// request.
+	VolumeImportRequestSchema = buildSchemaVar("volumeImportRequest")
+
 	// VolumeSnapshotRequestSchema is the JSON schema for a Volume snapshot
 	// request.
 	VolumeSnapshotRequestSchema = buildSchemaVar("volumeSnapshotRequest")
@@ -75,6 +102,11 @@ var (
 	// response.
 	VolumeAttachResponseSchema = buildSchemaVar("volumeAttachResponse")
 
+	// VolumeMountReportRequestSchema is the JSON schema for a Volume mount
+	// report request.
+	VolumeMountReportRequestSchema = buildSchemaVar(
+		"volumeMountReportRequest")
+
 	// VolumeDetachRequestSchema is the JSON schema for a Volume detach
 	// request.
 	VolumeDetachRequestSchema = buildSchemaVar("volumeDetachRequest")
@@ -83,6 +115,11 @@ var (
 	// request.
 	SnapshotCopyRequestSchema = buildSchemaVar("snapshotCopyRequest")
 
+	// SnapshotGroupCreateRequestSchema is the JSON schema for a Snapshot
+	// group create request.
+	SnapshotGroupCreateRequestSchema = buildSchemaVar(
+		"snapshotGroupCreateRequest")
+
 	// VolumeCreateFromSnapshotRequestSchema is the JSON schema for a
 	// Volume create from Snapshot request.
 	VolumeCreateFromSnapshotRequestSchema = buildSchemaVar(
@@ -96,6 +133,35 @@ func buildSchemaVar(name string) []byte {
 }`, jsonSchemaID, name))
 }
 
+// DefinitionName returns the name of the definition in the libStorage JSON
+// schema to which a schema built by this package refers. An empty string is
+// returned if the schema does not refer to such a definition.
+func DefinitionName(s []byte) string {
+	var v struct {
+		Ref string `json:"$ref"`
+	}
+	if err := json.Unmarshal(s, &v); err != nil {
+		return ""
+	}
+	prefix := jsonSchemaID + "#/definitions/"
+	if !strings.HasPrefix(v.Ref, prefix) {
+		return ""
+	}
+	return strings.TrimPrefix(v.Ref, prefix)
+}
+
+// Definitions returns the definitions of the libStorage JSON schema keyed by
+// their names.
+func Definitions() (map[string]interface{}, error) {
+	var v struct {
+		Definitions map[string]interface{} `json:"definitions"`
+	}
+	if err := json.Unmarshal(jsonSchema, &v); err != nil {
+		return nil, err
+	}
+	return v.Definitions, nil
+}
+
 // ValidateVolume validates a Volume object using the JSON schema. If the
 // object is valid no error is returned. The first return value, the object
 // marshaled to JSON, is returned whether or not the validation is successful.
@@ -90,6 +90,34 @@ const (
                     "type": "string",
                     "description": "The file system path to which the volume is mounted."
                 },
+                "devicePath": {
+                    "type": "string",
+                    "description": "The path of the device through which a raw volume is accessed."
+                },
+                "busType": {
+                    "type": "string",
+                    "description": "The type of the bus over which the device is attached, ex. scsi, ata, nvme, or virtio."
+                },
+                "lun": {
+                    "type": "string",
+                    "description": "The logical unit number of the device on its bus."
+                },
+                "wwn": {
+                    "type": "string",
+                    "description": "The device's world wide name."
+                },
+                "serial": {
+                    "type": "string",
+                    "description": "The device's serial number."
+                },
+                "byID": {
+                    "type": "string",
+                    "description": "The device's persistent symlink in /dev/disk/by-id."
+                },
+                "byPath": {
+                    "type": "string",
+                    "description": "The device's persistent symlink in /dev/disk/by-path."
+                },
                 "fields": { "$ref": "#/definitions/fields" }
             },
             "required": [ "instanceID", "deviceName", "volumeID" ],
@@ -97,6 +125,86 @@ const (
         },
 
 
+        "volumeStats": {
+            "title": "VolumeStats",
+            "description": "VolumeStats are a volume's capacity usage and IO statistics.",
+            "type": "object",
+            "properties": {
+                "volumeID": {
+                    "type": "string",
+                    "description": "The ID of the volume to which the statistics belong."
+                },
+                "time": {
+                    "type": "number",
+                    "description": "The time stamp (epoch) at which the statistics were collected."
+                },
+                "usage": {
+                    "type": "object",
+                    "description": "The usage of the volume's file system when the volume is mounted on the client's instance.",
+                    "properties": {
+                        "mountPoint": {
+                            "type": "string",
+                            "description": "The file system path to which the volume is mounted."
+                        },
+                        "totalBytes": {
+                            "type": "number",
+                            "description": "The size of the file system in bytes."
+                        },
+                        "usedBytes": {
+                            "type": "number",
+                            "description": "The number of bytes in use."
+                        },
+                        "availableBytes": {
+                            "type": "number",
+                            "description": "The number of bytes available to unprivileged users."
+                        },
+                        "totalInodes": {
+                            "type": "number",
+                            "description": "The number of inodes in the file system."
+                        },
+                        "usedInodes": {
+                            "type": "number",
+                            "description": "The number of inodes in use."
+                        }
+                    },
+                    "required": [ "mountPoint", "totalBytes", "usedBytes", "availableBytes" ],
+                    "additionalProperties": false
+                },
+                "io": {
+                    "type": "object",
+                    "description": "The volume's IO statistics as reported by the storage platform.",
+                    "properties": {
+                        "period": {
+                            "type": "number",
+                            "description": "The number of seconds over which the rates are averaged."
+                        },
+                        "readOps": {
+                            "type": "number",
+                            "description": "The number of read operations per second."
+                        },
+                        "writeOps": {
+                            "type": "number",
+                            "description": "The number of write operations per second."
+                        },
+                        "readBytes": {
+                            "type": "number",
+                            "description": "The number of bytes read per second."
+                        },
+                        "writeBytes": {
+                            "type": "number",
+                            "description": "The number of bytes written per second."
+                        },
+                        "fields": { "$ref": "#/definitions/fields" }
+                    },
+                    "required": [ "readOps", "writeOps", "readBytes", "writeBytes" ],
+                    "additionalProperties": false
+                }
+            },
+            "required": [ "volumeID", "time" ],
+            "additionalProperties": false
+        },
+
+
         "instanceID": {
             "title": "InstanceID",
             "description": "InstanceID identifies a host to a remote storage platform.",
@@ -146,6 +254,10 @@ const (
                     "type": "string",
                     "description": "The region from which the object originates."
                 },
+                "zone": {
+                    "type": "string",
+                    "description": "The availability zone in which the instance resides."
+                },
                 "fields": { "$ref": "#/definitions/fields" }
             },
             "required": [ "id" ],
@@ -197,6 +309,34 @@ const (
         },
 
 
+        "snapshotGroup": {
+            "title": "SnapshotGroup",
+            "description": "SnapshotGroup is a set of snapshots of multiple volumes that are taken together.",
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "description": "The snapshot group's ID if the storage platform tracks the snapshots as a group."
+                },
+                "name": {
+                    "type": "string",
+                    "description": "The name of the snapshot group."
+                },
+                "consistent": {
+                    "type": "boolean",
+                    "description": "A flag indicating whether or not the storage platform snapshotted the volumes at the same point in time."
+                },
+                "quiesced": {
+                    "type": "boolean",
+                    "description": "A flag indicating whether or not the volumes' file systems were frozen while the volumes were snapshotted."
+                },
+                "snapshots": { "$ref": "#/definitions/snapshotMap" }
+            },
+            "required": [ "name", "consistent", "snapshots" ],
+            "additionalProperties": false
+        },
+
+
         "task": {
             "type": "object",
             "properties": {
@@ -224,6 +364,12 @@ const (
                     "type": "number",
                     "description": "The time stamp (epoch) when the task started running."
                 },
+                "progress": {
+                    "type": "number",
+                    "minimum": 0,
+                    "maximum": 100,
+                    "description": "The percentage of the task that has been completed."
+                },
                 "result": {
                     "type": "object",
                     "description": "The result of the operation."
@@ -247,7 +393,36 @@ const (
                     "description": "Name is the service's name."
                 },
                 "instance": { "$ref": "#/definitions/instance" },
-                "driver": { "$ref": "#/definitions/driverInfo" }
+                "driver": { "$ref": "#/definitions/driverInfo" },
+                "mountOptions": {
+                    "type": "string",
+                    "description": "MountOptions is the comma-separated list of options with which the integration driver mounts the service's volumes by default."
+                }
+            },
+            "required": [ "name", "driver" ],
+            "additionalProperties": false
+        },
+
+
+        "serviceRegistration": {
+            "title": "ServiceRegistration",
+            "description": "ServiceRegistration describes a storage service registered while the server is running.",
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "pattern": "^[A-Za-z0-9_-]+$",
+                    "description": "Name is the service's name."
+                },
+                "driver": {
+                    "type": "string",
+                    "minLength": 1,
+                    "description": "Driver is the name of the service's storage driver."
+                },
+                "config": {
+                    "type": "object",
+                    "description": "Config is the service's configuration with the same keys as a service defined in a configuration file, ex. the settings of its storage driver."
+                }
             },
             "required": [ "name", "driver" ],
             "additionalProperties": false
@@ -272,6 +447,94 @@ const (
         },
 
 
+        "storageCapabilities": {
+            "type": "object",
+            "properties": {
+                "snapshots": {
+                    "type": "boolean",
+                    "description": "Snapshots indicates whether the driver supports volume snapshots."
+                },
+                "clone": {
+                    "type": "boolean",
+                    "description": "Clone indicates whether the driver supports copying a volume."
+                },
+                "resize": {
+                    "type": "boolean",
+                    "description": "Resize indicates whether the driver supports expanding a volume."
+                },
+                "multiAttach": {
+                    "type": "boolean",
+                    "description": "MultiAttach indicates whether a volume may be attached to more than one instance at a time."
+                },
+                "readOnlyAttach": {
+                    "type": "boolean",
+                    "description": "ReadOnlyAttach indicates whether the driver supports attaching a volume read-only."
+                },
+                "maxVolumeSize": {
+                    "type": "number",
+                    "description": "MaxVolumeSize is the maximum size of a volume, in GiB."
+                },
+                "topology": {
+                    "type": "array",
+                    "description": "Topology is a list of the topology constraints that apply to volume placement.",
+                    "items": { "type": "string" }
+                },
+                "provisioning": {
+                    "type": "array",
+                    "description": "Provisioning is a list of the ways in which the driver can provision volumes.",
+                    "items": { "type": "string", "enum": [ "thin", "thick" ] }
+                },
+                "volumeFields": {
+                    "type": "boolean",
+                    "description": "VolumeFields indicates whether the driver records the custom options of a volume creation request as the created volume's fields."
+                },
+                "fields": { "$ref": "#/definitions/fields" }
+            },
+            "required": [ "snapshots", "clone", "resize", "multiAttach" ],
+            "additionalProperties": false
+        },
+
+
+        "faultInjection": {
+            "title": "FaultInjection",
+            "description": "FaultInjection describes the faults a storage driver injects into its operations.",
+            "type": "object",
+            "properties": {
+                "seed": {
+                    "type": "number",
+                    "description": "The seed of the generator that decides which operations fail."
+                },
+                "ops": {
+                    "type": "object",
+                    "description": "The faults injected into the driver's operations keyed by the names of the operations. The faults keyed by * are injected into the operations without faults of their own.",
+                    "additionalProperties": {
+                        "type": "object",
+                        "properties": {
+                            "latency": {
+                                "type": "string",
+                                "description": "The duration, ex. 500ms, by which the operation is delayed."
+                            },
+                            "errorRate": {
+                                "type": "number",
+                                "minimum": 0,
+                                "maximum": 1,
+                                "description": "The probability that the operation fails."
+                            }
+                        },
+                        "additionalProperties": false
+                    }
+                },
+                "flakyAttachRate": {
+                    "type": "number",
+                    "minimum": 0,
+                    "maximum": 1,
+                    "description": "The probability that a volume attach reports success without attaching the volume."
+                }
+            },
+            "additionalProperties": false
+        },
+
+
         "executorInfo": {
             "type": "object",
             "properties": {
@@ -449,6 +712,9 @@ const (
                 "size": {
                     "type": "number"
                 },
+                "thin": {
+                    "type": "boolean"
+                },
                 "type": {
                     "type": "string"
                 },
@@ -472,12 +738,31 @@ const (
         },
 
 
+        "volumeImportRequest": {
+            "type": "object",
+            "properties": {
+                "nativeID": {
+                    "type": "string"
+                },
+                "volumeName": {
+                    "type": "string"
+                },
+                "opts": { "$ref" : "#/definitions/opts" }
+            },
+            "required": [ "nativeID" ],
+            "additionalProperties": false
+        },
+
+
         "volumeSnapshotRequest": {
             "type": "object",
             "properties": {
                 "snapshotName": {
                     "type": "string"
                 },
+                "quiesce": {
+                    "type": "boolean"
+                },
                 "opts": { "$ref" : "#/definitions/opts" }
             },
             "required": [ "snapshotName" ],
@@ -485,6 +770,28 @@ const (
         },
 
 
+        "snapshotGroupCreateRequest": {
+            "type": "object",
+            "properties": {
+                "snapshotName": {
+                    "type": "string"
+                },
+                "volumeIDs": {
+                    "type": "array",
+                    "items": { "type": "string" },
+                    "minItems": 1,
+                    "uniqueItems": true
+                },
+                "quiesce": {
+                    "type": "boolean"
+                },
+                "opts": { "$ref" : "#/definitions/opts" }
+            },
+            "required": [ "snapshotName", "volumeIDs" ],
+            "additionalProperties": false
+        },
+
+
         "volumeAttachRequest": {
             "type": "object",
             "properties": {
@@ -494,6 +801,18 @@ const (
                 "force": {
                     "type": "boolean"
                 },
+                "accessMode": {
+                    "type": "string",
+                    "enum": [ "rw", "ro" ]
+                },
+                "reason": {
+                    "type": "string",
+                    "description": "Why the volume is attached. The reason is recorded in the volume's event history."
+                },
+                "requestedBy": {
+                    "type": "string",
+                    "description": "Who requested the attachment, ex. a team or a user. The requester is recorded in the volume's event history."
+                },
                 "opts": { "$ref" : "#/definitions/opts" }
             },
             "additionalProperties": false
@@ -511,18 +830,65 @@ const (
         },
 
 
+        "validateResponse": {
+            "type": "object",
+            "properties": {
+                "operation": {
+                    "type": "string",
+                    "description": "The operation that was validated."
+                },
+                "request": {
+                    "type": "object",
+                    "description": "The normalized request."
+                }
+            },
+            "required": [ "operation", "request" ],
+            "additionalProperties": false
+        },
+
+
         "volumeDetachRequest": {
             "type": "object",
             "properties": {
                 "force": {
                     "type": "boolean"
                 },
+                "reason": {
+                    "type": "string",
+                    "description": "Why the volume is detached. The reason is recorded in the volume's event history."
+                },
+                "requestedBy": {
+                    "type": "string",
+                    "description": "Who requested the detachment, ex. a team or a user. The requester is recorded in the volume's event history."
+                },
                 "opts": { "$ref" : "#/definitions/opts" }
             },
             "additionalProperties": false
         },
 
 
+        "volumeMountReportRequest": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string",
+                    "description": "The mount token issued by the server when the volume was attached."
+                },
+                "mountPoint": {
+                    "type": "string",
+                    "description": "The path at which the volume is mounted."
+                },
+                "error": {
+                    "type": "string",
+                    "description": "Why the volume could not be mounted, or empty if the volume was mounted."
+                },
+                "opts": { "$ref" : "#/definitions/opts" }
+            },
+            "required": [ "token" ],
+            "additionalProperties": false
+        },
+
+
         "snapshotCopyRequest": {
             "type": "object",
             "properties": {
@@ -532,6 +898,10 @@ const (
                 "destinationID": {
                     "type": "string"
                 },
+                "destination": {
+                    "type": "string",
+                    "description": "The location to which the snapshot is copied, ex. a region or a cluster/pool. The snapshot is copied within its own location if omitted."
+                },
                 "opts": { "$ref" : "#/definitions/opts" }
             },
             "required": [ "snapshotName", "destinationID" ],
@@ -567,6 +937,40 @@ const (
             },
             "required": [ "message", "status" ],
             "additionalProperties": false
+        },
+
+
+        "structuredError": {
+            "type": "object",
+            "description": "StructuredError is the representation of an error in version 2 of the API.",
+            "properties": {
+                "error": {
+                    "type": "object",
+                    "properties": {
+                        "code": {
+                            "type": "string",
+                            "description": "The error's machine-readable code."
+                        },
+                        "message": {
+                            "type": "string",
+                            "description": "The error's message."
+                        },
+                        "status": {
+                            "type": "number",
+                            "minimum": 400,
+                            "maximum": 599
+                        },
+                        "details": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "required": [ "code", "message", "status" ],
+                    "additionalProperties": false
+                }
+            },
+            "required": [ "error" ],
+            "additionalProperties": false
         }
     }
 }
@@ -282,3 +282,16 @@ func TestVolumeSnapshotRequestObject(t *testing.T) {
 		assert.NoError(t, err)
 	}
 }
+
+func TestDefinitions(t *testing.T) {
+	assert.Equal(t, "volume", DefinitionName(VolumeSchema))
+	assert.Equal(t, "faultInjection", DefinitionName(FaultInjectionSchema))
+	assert.Empty(t, DefinitionName([]byte(`{"type":"object"}`)))
+	assert.Empty(t, DefinitionName(nil))
+
+	defs, err := Definitions()
+	assert.NoError(t, err)
+	for _, name := range []string{"volume", "snapshot", "error"} {
+		assert.Contains(t, defs, name)
+	}
+}
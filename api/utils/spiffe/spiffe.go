@@ -0,0 +1,327 @@
+// Package spiffe provides the X.509-SVIDs and trust bundles that the SPIFFE
+// Workload API issues to a workload.
+//
+// The Workload API's messages are declared here rather than generated so
+// that the package does not require the protocol buffer compiler.
+package spiffe
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/akutz/goof"
+	"github.com/akutz/gotil"
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+const (
+	// WorkloadAPIHeader is the metadata header the Workload API requires
+	// with each request.
+	WorkloadAPIHeader = "workload.spiffe.io"
+
+	// FetchX509SVIDMethod is the Workload API method that streams the
+	// workload's X.509-SVIDs.
+	FetchX509SVIDMethod = "/SpiffeWorkloadAPI/FetchX509SVID"
+)
+
+var (
+	// dialTimeout is how long to wait for the Workload API to be available
+	// when the SVID is first fetched.
+	dialTimeout = 10 * time.Second
+
+	// retryInterval is how long to wait before the stream of SVIDs is
+	// opened again after it is broken.
+	retryInterval = 5 * time.Second
+)
+
+// FetchX509SVIDStream describes the FetchX509SVID stream.
+var FetchX509SVIDStream = &grpc.StreamDesc{
+	StreamName:    "FetchX509SVID",
+	ServerStreams: true,
+}
+
+// X509SVIDRequest is the request sent to the FetchX509SVID method.
+type X509SVIDRequest struct{}
+
+// Reset resets the message.
+func (m *X509SVIDRequest) Reset() { *m = X509SVIDRequest{} }
+
+// String returns the message as text.
+func (m *X509SVIDRequest) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage marks the type as a protocol buffer message.
+func (*X509SVIDRequest) ProtoMessage() {}
+
+// X509SVIDResponse is a message streamed by the FetchX509SVID method each
+// time the workload's SVIDs are rotated.
+type X509SVIDResponse struct {
+	// SVIDs are the workload's SVIDs. The first is the default SVID.
+	SVIDs []*X509SVID `protobuf:"bytes,1,rep,name=svids"`
+}
+
+// Reset resets the message.
+func (m *X509SVIDResponse) Reset() { *m = X509SVIDResponse{} }
+
+// String returns the message as text.
+func (m *X509SVIDResponse) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage marks the type as a protocol buffer message.
+func (*X509SVIDResponse) ProtoMessage() {}
+
+// X509SVID is an X.509-SVID, its key, and the bundle of its trust domain.
+type X509SVID struct {
+	// SPIFFEID is the SVID's SPIFFE ID.
+	SPIFFEID string `protobuf:"bytes,1,opt,name=spiffe_id,proto3"`
+
+	// Certificates are the DER-encoded certificates of the SVID's chain,
+	// the SVID first.
+	Certificates []byte `protobuf:"bytes,2,opt,name=x509_svid,proto3"`
+
+	// Key is the SVID's DER-encoded PKCS#8 private key.
+	Key []byte `protobuf:"bytes,3,opt,name=x509_svid_key,proto3"`
+
+	// Bundle are the DER-encoded CA certificates of the trust domain.
+	Bundle []byte `protobuf:"bytes,4,opt,name=bundle,proto3"`
+}
+
+// Reset resets the message.
+func (m *X509SVID) Reset() { *m = X509SVID{} }
+
+// String returns the message as text.
+func (m *X509SVID) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage marks the type as a protocol buffer message.
+func (*X509SVID) ProtoMessage() {}
+
+// Source is the workload's X.509-SVID and trust bundle as issued by the
+// Workload API.
+type Source struct {
+	sync.RWMutex
+	id    string
+	cert  *tls.Certificate
+	roots *x509.CertPool
+}
+
+// NewSource fetches the workload's X.509-SVID and trust bundle from the
+// Workload API listening at the provided address, ex.
+// unix:///tmp/spire-agent/public/api.sock. The SVID and bundle are updated
+// each time the Workload API rotates them until the stop channel is closed.
+// The onUpdate function, if not nil, is invoked with each rotated SVID.
+func NewSource(
+	ctx types.Context,
+	addr string,
+	stop <-chan struct{},
+	onUpdate func(*tls.Certificate)) (*Source, error) {
+
+	network, laddr, err := gotil.ParseAddress(addr)
+	if err != nil {
+		return nil, goof.WithFieldE(
+			"address", addr, "invalid workload api address", err)
+	}
+
+	dialer := func(_ string, timeout time.Duration) (net.Conn, error) {
+		return net.DialTimeout(network, laddr, timeout)
+	}
+
+	conn, err := grpc.Dial(
+		laddr,
+		grpc.WithInsecure(),
+		grpc.WithDialer(dialer),
+		grpc.WithBlock(),
+		grpc.WithTimeout(dialTimeout))
+	if err != nil {
+		return nil, goof.WithFieldE(
+			"address", addr, "error dialing workload api", err)
+	}
+
+	streamCtx, cancel := context.WithCancel(metadata.NewContext(
+		context.Background(), metadata.Pairs(WorkloadAPIHeader, "true")))
+
+	s := &Source{}
+	stream, err := s.open(streamCtx, conn)
+	if err != nil {
+		cancel()
+		conn.Close()
+		return nil, goof.WithFieldE(
+			"address", addr, "error fetching spiffe svid", err)
+	}
+
+	ctx.WithFields(map[string]interface{}{
+		"address":  addr,
+		"spiffeID": s.ID(),
+	}).Info("fetched spiffe svid")
+
+	go func() {
+		<-stop
+		cancel()
+		conn.Close()
+	}()
+	go s.watch(ctx, streamCtx, conn, stream, onUpdate)
+
+	return s, nil
+}
+
+// ID returns the SPIFFE ID of the workload's SVID.
+func (s *Source) ID() string {
+	s.RLock()
+	defer s.RUnlock()
+	return s.id
+}
+
+// Certificate returns the workload's SVID.
+func (s *Source) Certificate() *tls.Certificate {
+	s.RLock()
+	defer s.RUnlock()
+	return s.cert
+}
+
+// Roots returns the CA certificates of the workload's trust domain.
+func (s *Source) Roots() *x509.CertPool {
+	s.RLock()
+	defer s.RUnlock()
+	return s.roots
+}
+
+// VerifyPeer verifies that the first of the provided peer certificates is
+// signed by the workload's trust bundle. Peers are verified after the TLS
+// handshake since the bundle may be rotated, and SVIDs do not have the DNS
+// names with which a TLS client verifies its server.
+func (s *Source) VerifyPeer(peerCerts []*x509.Certificate) error {
+	if len(peerCerts) == 0 {
+		return goof.New("peer missing svid")
+	}
+	opts := x509.VerifyOptions{
+		Roots:         s.Roots(),
+		Intermediates: x509.NewCertPool(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+	for _, cert := range peerCerts[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	if _, err := peerCerts[0].Verify(opts); err != nil {
+		return goof.WithError("invalid peer svid", err)
+	}
+	return nil
+}
+
+// open opens the stream of SVIDs and receives the first of them.
+func (s *Source) open(
+	ctx context.Context, conn *grpc.ClientConn) (grpc.ClientStream, error) {
+
+	stream, err := grpc.NewClientStream(
+		ctx, FetchX509SVIDStream, conn, FetchX509SVIDMethod)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(&X509SVIDRequest{}); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	if err := s.recv(stream); err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+// recv receives the next SVID from the stream.
+func (s *Source) recv(stream grpc.ClientStream) error {
+	res := &X509SVIDResponse{}
+	if err := stream.RecvMsg(res); err != nil {
+		return err
+	}
+	if len(res.SVIDs) == 0 {
+		return goof.New("workload api returned no svids")
+	}
+	svid := res.SVIDs[0]
+
+	certs, err := x509.ParseCertificates(svid.Certificates)
+	if err != nil {
+		return err
+	}
+	if len(certs) == 0 {
+		return goof.New("workload api returned empty svid")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(svid.Key)
+	if err != nil {
+		return err
+	}
+	bundle, err := x509.ParseCertificates(svid.Bundle)
+	if err != nil {
+		return err
+	}
+
+	cert := &tls.Certificate{PrivateKey: key, Leaf: certs[0]}
+	for _, c := range certs {
+		cert.Certificate = append(cert.Certificate, c.Raw)
+	}
+	roots := x509.NewCertPool()
+	for _, c := range bundle {
+		roots.AddCert(c)
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	s.id = svid.SPIFFEID
+	s.cert = cert
+	s.roots = roots
+	return nil
+}
+
+// watch receives the SVIDs rotated by the Workload API until the stream's
+// context is canceled. A broken stream is opened again. The previous SVID
+// remains in use while the Workload API is unavailable.
+func (s *Source) watch(
+	ctx types.Context,
+	streamCtx context.Context,
+	conn *grpc.ClientConn,
+	stream grpc.ClientStream,
+	onUpdate func(*tls.Certificate)) {
+
+	for {
+		var err error
+		if stream != nil {
+			if err = s.recv(stream); err == nil {
+				ctx.WithField("spiffeID", s.ID()).Info("rotated spiffe svid")
+				if onUpdate != nil {
+					onUpdate(s.Certificate())
+				}
+				continue
+			}
+		}
+
+		select {
+		case <-streamCtx.Done():
+			return
+		default:
+		}
+		if err != nil {
+			ctx.WithError(err).Warn("error receiving spiffe svid")
+		}
+
+		select {
+		case <-streamCtx.Done():
+			return
+		case <-time.After(retryInterval):
+		}
+
+		if stream, err = s.open(streamCtx, conn); err != nil {
+			ctx.WithError(err).Warn("error fetching spiffe svid")
+			stream = nil
+			continue
+		}
+		ctx.WithField("spiffeID", s.ID()).Info("fetched spiffe svid")
+		if onUpdate != nil {
+			onUpdate(s.Certificate())
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package spiffe
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+var (
+	oidPublicKeyECDSA = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidNamedCurveP256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+)
+
+// marshalPKCS8 encodes the key as the Workload API does.
+func marshalPKCS8(t *testing.T, key *ecdsa.PrivateKey) []byte {
+	ecKey, err := x509.MarshalECPrivateKey(key)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	params, _ := asn1.Marshal(oidNamedCurveP256)
+	der, err := asn1.Marshal(struct {
+		Version    int
+		Algo       pkix.AlgorithmIdentifier
+		PrivateKey []byte
+	}{
+		Algo: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPublicKeyECDSA,
+			Parameters: asn1.RawValue{FullBytes: params},
+		},
+		PrivateKey: ecKey,
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return der
+}
+
+func newTestCert(
+	t *testing.T,
+	tmpl *x509.Certificate,
+	parent *x509.Certificate,
+	parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	tmpl.NotBefore = time.Now().Add(-time.Minute)
+	tmpl.NotAfter = time.Now().Add(time.Hour)
+	der, err := x509.CreateCertificate(
+		rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	cert, err := x509.ParseCertificate(der)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return cert, key
+}
+
+func newTestCA(t *testing.T) (*x509.Certificate, *ecdsa.PrivateKey) {
+	return newTestCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "example.org"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil, nil)
+}
+
+func newTestSVID(
+	t *testing.T,
+	id string,
+	ca *x509.Certificate,
+	caKey *ecdsa.PrivateKey) *X509SVID {
+
+	cert, key := newTestCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: id},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}, ca, caKey)
+	return &X509SVID{
+		SPIFFEID:     id,
+		Certificates: cert.Raw,
+		Key:          marshalPKCS8(t, key),
+		Bundle:       ca.Raw,
+	}
+}
+
+// newTestWorkloadAPI serves a Workload API that streams the SVIDs sent on
+// the returned channel.
+func newTestWorkloadAPI(t *testing.T) (string, chan *X509SVID, func()) {
+	sock := utils.GetTempSockFile()
+	l, err := net.Listen("unix", sock)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	svids := make(chan *X509SVID, 1)
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		md, _ := metadata.FromContext(stream.Context())
+		if len(md[WorkloadAPIHeader]) == 0 {
+			return errors.New("missing workload api header")
+		}
+		if err := stream.RecvMsg(&X509SVIDRequest{}); err != nil {
+			return err
+		}
+		for {
+			select {
+			case <-stream.Context().Done():
+				return nil
+			case svid := <-svids:
+				res := &X509SVIDResponse{SVIDs: []*X509SVID{svid}}
+				if err := stream.SendMsg(res); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	s := grpc.NewServer()
+	s.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "SpiffeWorkloadAPI",
+		HandlerType: (*interface{})(nil),
+		Streams: []grpc.StreamDesc{
+			{
+				StreamName:    FetchX509SVIDStream.StreamName,
+				Handler:       handler,
+				ServerStreams: true,
+			},
+		},
+	}, struct{}{})
+	go s.Serve(l)
+
+	return "unix://" + sock, svids, func() {
+		s.Stop()
+		os.RemoveAll(sock)
+	}
+}
+
+func TestSource(t *testing.T) {
+	addr, svids, stopServer := newTestWorkloadAPI(t)
+	defer stopServer()
+
+	ca, caKey := newTestCA(t)
+	svids <- newTestSVID(t, "spiffe://example.org/server", ca, caKey)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	updates := make(chan *tls.Certificate, 1)
+	s, err := NewSource(context.Background(), addr, stop,
+		func(cert *tls.Certificate) { updates <- cert })
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "spiffe://example.org/server", s.ID())
+	assert.Equal(t, "spiffe://example.org/server",
+		s.Certificate().Leaf.Subject.CommonName)
+
+	peer, _ := newTestCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "client"},
+	}, ca, caKey)
+	assert.NoError(t, s.VerifyPeer([]*x509.Certificate{peer}))
+
+	otherCA, otherCAKey := newTestCA(t)
+	other, _ := newTestCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "client"},
+	}, otherCA, otherCAKey)
+	assert.Error(t, s.VerifyPeer([]*x509.Certificate{other}))
+	assert.Error(t, s.VerifyPeer(nil))
+
+	// the SVID and bundle are rotated
+	svids <- newTestSVID(t, "spiffe://example.org/server2", otherCA, otherCAKey)
+	select {
+	case cert := <-updates:
+		assert.Equal(t, "spiffe://example.org/server2",
+			cert.Leaf.Subject.CommonName)
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for rotated svid")
+	}
+	assert.Equal(t, "spiffe://example.org/server2", s.ID())
+	assert.NoError(t, s.VerifyPeer([]*x509.Certificate{other}))
+	assert.Error(t, s.VerifyPeer([]*x509.Certificate{peer}))
+}
+
+func TestSourceUnavailable(t *testing.T) {
+	dialTimeout = time.Second
+	defer func() { dialTimeout = 10 * time.Second }()
+
+	stop := make(chan struct{})
+	defer close(stop)
+	_, err := NewSource(context.Background(),
+		"unix://"+utils.GetTempSockFile(), stop, nil)
+	assert.Error(t, err)
+}
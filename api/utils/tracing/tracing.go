@@ -0,0 +1,182 @@
+package tracing
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	otlog "github.com/opentracing/opentracing-go/log"
+	jaegercfg "github.com/uber/jaeger-client-go/config"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+const (
+	// ExporterNone disables tracing.
+	ExporterNone = ""
+
+	// ExporterJaeger exports spans to a Jaeger agent.
+	ExporterJaeger = "jaeger"
+)
+
+type nopCloser struct{}
+
+func (c *nopCloser) Close() error { return nil }
+
+// Init configures the global tracer using the exporter specified by the
+// configuration. Init does not replace a global tracer that was already
+// configured, such as by a program that embeds libStorage. The returned
+// closer flushes and releases the tracer.
+func Init(
+	ctx types.Context,
+	config gofig.Config,
+	serviceName string) (io.Closer, error) {
+
+	exporter := config.GetString(types.ConfigTracingExporter)
+	if exporter == ExporterNone {
+		return &nopCloser{}, nil
+	}
+
+	if _, ok := opentracing.GlobalTracer().(opentracing.NoopTracer); !ok {
+		ctx.Debug("global tracer already configured")
+		return &nopCloser{}, nil
+	}
+
+	switch exporter {
+	case ExporterJaeger:
+		return initJaeger(ctx, config, serviceName)
+	}
+
+	return nil, goof.WithField(
+		"exporter", exporter, "unsupported tracing exporter")
+}
+
+func initJaeger(
+	ctx types.Context,
+	config gofig.Config,
+	serviceName string) (io.Closer, error) {
+
+	samplerParam, err := strconv.ParseFloat(
+		config.GetString(types.ConfigTracingSamplerParam), 64)
+	if err != nil {
+		return nil, goof.WithError("invalid tracing sampler param", err)
+	}
+
+	cfg := &jaegercfg.Configuration{
+		Sampler: &jaegercfg.SamplerConfig{
+			Type:  config.GetString(types.ConfigTracingSamplerType),
+			Param: samplerParam,
+		},
+		Reporter: &jaegercfg.ReporterConfig{
+			LocalAgentHostPort: config.GetString(
+				types.ConfigTracingJaegerAgent),
+		},
+	}
+
+	tracer, closer, err := cfg.New(serviceName)
+	if err != nil {
+		return nil, err
+	}
+	opentracing.SetGlobalTracer(tracer)
+
+	ctx.WithFields(map[string]interface{}{
+		"exporter":     ExporterJaeger,
+		"agent":        cfg.Reporter.LocalAgentHostPort,
+		"samplerType":  cfg.Sampler.Type,
+		"samplerParam": cfg.Sampler.Param,
+	}).Info("configured tracing")
+
+	return closer, nil
+}
+
+// SpanFromContext returns the span stored in the context, if any.
+func SpanFromContext(ctx types.Context) opentracing.Span {
+	if span, ok := ctx.Value(context.SpanKey).(opentracing.Span); ok {
+		return span
+	}
+	return nil
+}
+
+// StartSpan starts a span that is a child of the context's span, if any. The
+// returned context contains the new span, and the returned function finishes
+// the span, marking it as failed if the error provided to the function is not
+// nil.
+func StartSpan(
+	ctx types.Context,
+	operationName string,
+	tags map[string]interface{},
+	opts ...opentracing.StartSpanOption) (types.Context, func(error)) {
+
+	if parent := SpanFromContext(ctx); parent != nil {
+		opts = append(opts, opentracing.ChildOf(parent.Context()))
+	}
+	if len(tags) > 0 {
+		opts = append(opts, opentracing.Tags(tags))
+	}
+
+	span := opentracing.GlobalTracer().StartSpan(operationName, opts...)
+	ctx = ctx.WithValue(context.SpanKey, span)
+
+	return ctx, func(err error) {
+		if err != nil {
+			ext.Error.Set(span, true)
+			span.LogFields(otlog.Error(err))
+		}
+		span.Finish()
+	}
+}
+
+// StartServerSpan starts a span for an incoming HTTP request. The span is a
+// child of the span context propagated in the request's headers, if any.
+func StartServerSpan(
+	ctx types.Context,
+	operationName string,
+	req *http.Request) (types.Context, func(error)) {
+
+	var opts []opentracing.StartSpanOption
+	if spanCtx, err := opentracing.GlobalTracer().Extract(
+		opentracing.HTTPHeaders,
+		opentracing.HTTPHeadersCarrier(req.Header)); err == nil {
+		opts = append(opts, ext.RPCServerOption(spanCtx))
+	} else {
+		opts = append(opts, ext.SpanKindRPCServer)
+	}
+
+	return StartSpan(ctx, operationName, map[string]interface{}{
+		string(ext.HTTPMethod): req.Method,
+		string(ext.HTTPUrl):    req.URL.String(),
+	}, opts...)
+}
+
+// StartClientSpan starts a span for an outgoing HTTP request and injects
+// the span's context into the request's headers.
+func StartClientSpan(
+	ctx types.Context,
+	req *http.Request) (types.Context, func(*http.Response, error)) {
+
+	ctx, finish := StartSpan(
+		ctx, req.Method+" "+req.URL.Path, map[string]interface{}{
+			string(ext.HTTPMethod): req.Method,
+			string(ext.HTTPUrl):    req.URL.String(),
+		}, ext.SpanKindRPCClient)
+
+	span := SpanFromContext(ctx)
+	if err := span.Tracer().Inject(
+		span.Context(),
+		opentracing.HTTPHeaders,
+		opentracing.HTTPHeadersCarrier(req.Header)); err != nil {
+		ctx.WithError(err).Debug("error injecting span context")
+	}
+
+	return ctx, func(res *http.Response, err error) {
+		if res != nil {
+			ext.HTTPStatusCode.Set(span, uint16(res.StatusCode))
+		}
+		finish(err)
+	}
+}
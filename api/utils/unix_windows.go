@@ -2,7 +2,22 @@
 
 package utils
 
+import (
+	"net"
+	"os"
+)
+
 // HostName returns then host name.
 func HostName() (string, error) {
 	return "windows", nil
 }
+
+// ListenUnix listens on the UNIX socket. The mode is ignored.
+func ListenUnix(sock string, mode os.FileMode) (net.Listener, error) {
+	return net.Listen("unix", sock)
+}
+
+// RemoveStaleSocket does nothing because UNIX sockets are not left behind.
+func RemoveStaleSocket(sock string) error {
+	return nil
+}
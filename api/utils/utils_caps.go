@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"sort"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// CommonCapabilities returns the capabilities shared by all of the storage
+// drivers whose capabilities are provided, such as the backends of a driver
+// that places volumes on other services. A feature is supported only if all
+// of the drivers support it, the maximum volume size is the smallest of the
+// drivers' limits, and the topology constraints of every driver apply.
+func CommonCapabilities(
+	caps ...*types.StorageCapabilities) *types.StorageCapabilities {
+
+	if len(caps) == 0 {
+		return &types.StorageCapabilities{}
+	}
+
+	cc := &types.StorageCapabilities{
+		Snapshots:      true,
+		Clone:          true,
+		Resize:         true,
+		MultiAttach:    true,
+		ReadOnlyAttach: true,
+		VolumeFields:   true,
+	}
+
+	topology := map[string]bool{}
+	provisioning := map[string]int{}
+	for _, c := range caps {
+		if c == nil {
+			c = &types.StorageCapabilities{}
+		}
+		cc.Snapshots = cc.Snapshots && c.Snapshots
+		cc.Clone = cc.Clone && c.Clone
+		cc.Resize = cc.Resize && c.Resize
+		cc.MultiAttach = cc.MultiAttach && c.MultiAttach
+		cc.ReadOnlyAttach = cc.ReadOnlyAttach && c.ReadOnlyAttach
+		cc.VolumeFields = cc.VolumeFields && c.VolumeFields
+		if c.MaxVolumeSize > 0 &&
+			(cc.MaxVolumeSize == 0 || c.MaxVolumeSize < cc.MaxVolumeSize) {
+			cc.MaxVolumeSize = c.MaxVolumeSize
+		}
+		for _, t := range c.Topology {
+			topology[t] = true
+		}
+		for _, p := range c.Provisioning {
+			provisioning[p]++
+		}
+	}
+
+	for t := range topology {
+		cc.Topology = append(cc.Topology, t)
+	}
+	sort.Strings(cc.Topology)
+	for _, p := range []string{
+		types.ProvisioningThin, types.ProvisioningThick} {
+		if provisioning[p] == len(caps) {
+			cc.Provisioning = append(cc.Provisioning, p)
+		}
+	}
+	return cc
+}
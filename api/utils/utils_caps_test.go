@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+func TestCommonCapabilities(t *testing.T) {
+	tests := []struct {
+		name string
+		caps []*types.StorageCapabilities
+		want *types.StorageCapabilities
+	}{
+		{
+			name: "none",
+			want: &types.StorageCapabilities{},
+		},
+		{
+			name: "one",
+			caps: []*types.StorageCapabilities{
+				{
+					Snapshots:     true,
+					Resize:        true,
+					MaxVolumeSize: 16384,
+					Topology:      []string{"zone"},
+					Provisioning:  []string{types.ProvisioningThin},
+				},
+			},
+			want: &types.StorageCapabilities{
+				Snapshots:     true,
+				Resize:        true,
+				MaxVolumeSize: 16384,
+				Topology:      []string{"zone"},
+				Provisioning:  []string{types.ProvisioningThin},
+			},
+		},
+		{
+			name: "intersection",
+			caps: []*types.StorageCapabilities{
+				{
+					Snapshots:    true,
+					Clone:        true,
+					MultiAttach:  true,
+					Topology:     []string{"zone"},
+					Provisioning: []string{types.ProvisioningThin},
+				},
+				{
+					Snapshots:     true,
+					MaxVolumeSize: 1024,
+					Topology:      []string{"region", "zone"},
+					Provisioning: []string{
+						types.ProvisioningThin, types.ProvisioningThick},
+				},
+				{
+					Snapshots:     true,
+					MaxVolumeSize: 512,
+					Provisioning: []string{
+						types.ProvisioningThick, types.ProvisioningThin},
+				},
+			},
+			want: &types.StorageCapabilities{
+				Snapshots:     true,
+				MaxVolumeSize: 512,
+				Topology:      []string{"region", "zone"},
+				Provisioning:  []string{types.ProvisioningThin},
+			},
+		},
+		{
+			name: "nil",
+			caps: []*types.StorageCapabilities{
+				{Snapshots: true, Provisioning: []string{"thin"}},
+				nil,
+			},
+			want: &types.StorageCapabilities{},
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, CommonCapabilities(tt.caps...), tt.name)
+	}
+}
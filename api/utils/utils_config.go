@@ -66,3 +66,46 @@ func getBool(
 
 	return false
 }
+
+func get(
+	config gofig.Config,
+	key string,
+	roots ...string) interface{} {
+
+	for _, r := range roots {
+		rk := strings.Replace(key, "libstorage.", fmt.Sprintf("%s.", r), 1)
+		if config.IsSet(rk) {
+			return config.Get(rk)
+		}
+	}
+
+	return config.Get(key)
+}
+
+// getStringSlice returns the value of a key that is either a list or a
+// comma-separated string.
+func getStringSlice(
+	config gofig.Config,
+	key string,
+	roots ...string) []string {
+
+	var vals []string
+	switch tv := get(config, key, roots...).(type) {
+	case []string:
+		vals = tv
+	case []interface{}:
+		for _, v := range tv {
+			vals = append(vals, fmt.Sprintf("%v", v))
+		}
+	case string:
+		vals = strings.Split(tv, ",")
+	}
+
+	var trimmed []string
+	for _, v := range vals {
+		if v = strings.TrimSpace(v); v != "" {
+			trimmed = append(trimmed, v)
+		}
+	}
+	return trimmed
+}
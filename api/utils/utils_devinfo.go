@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+var (
+	udevDataDir = "/run/udev/data"
+	mountInfo   = "/proc/self/mountinfo"
+	devByPath   = "/dev/disk/by-path"
+)
+
+const (
+	// udevByIDLink and udevByPathLink are the prefixes of the symlinks to a
+	// device, relative to /dev, that are recorded in the udev database
+	udevByIDLink   = "disk/by-id/"
+	udevByPathLink = "disk/by-path/"
+)
+
+// DescribeLocalDevices sets the information about each of the mapped
+// devices, such as its size, serial number, bus, persistent symlinks, file
+// system type, and mount point. The information is read from sysfs, the udev
+// database, and the mount table, so fields that cannot be determined on the
+// host, such as on hosts that are not Linux, are left empty.
+func DescribeLocalDevices(ld *types.LocalDevices) {
+	if ld == nil {
+		return
+	}
+
+	mounts := parseMountInfo(mountInfo)
+	ld.Devices = map[string]*types.LocalDevice{}
+	for _, device := range ld.DeviceMap {
+		if _, ok := ld.Devices[device]; ok {
+			continue
+		}
+		ld.Devices[device] = describeDevice(device, mounts)
+	}
+}
+
+// describeDevice returns the information about the device. The provided map
+// is the mount points of the mounted devices keyed by their device numbers.
+func describeDevice(device string, mounts map[string]string) *types.LocalDevice {
+	if p, err := filepath.EvalSymlinks(device); err == nil {
+		device = p
+	}
+	devDir := filepath.Join(sysBlockDir, filepath.Base(device))
+
+	info := &types.LocalDevice{}
+	if sectors, err := strconv.ParseInt(
+		readSysFile(filepath.Join(devDir, "size")), 10, 64); err == nil {
+		// sysfs reports the size in 512 byte sectors regardless of the
+		// device's logical block size
+		info.Size = sectors * 512
+	}
+
+	devNum := readSysFile(filepath.Join(devDir, "dev"))
+	if devNum != "" {
+		props, links := parseUdevData(
+			filepath.Join(udevDataDir, "b"+devNum))
+		info.Serial = props["ID_SERIAL_SHORT"]
+		if info.Serial == "" {
+			info.Serial = props["ID_SERIAL"]
+		}
+		info.WWN = props["ID_WWN"]
+		info.BusType = props["ID_BUS"]
+		if idPath := props["ID_PATH"]; idPath != "" {
+			info.ByPath = filepath.Join(devByPath, idPath)
+		} else {
+			info.ByPath = udevLink(links, udevByPathLink)
+		}
+		info.ByID = udevLink(links, udevByIDLink)
+		info.FSType = props["ID_FS_TYPE"]
+		info.MountPoint = mounts[devNum]
+	}
+
+	if info.Serial == "" {
+		info.Serial = readSysFile(filepath.Join(devDir, "device", "serial"))
+	}
+	if info.WWN == "" {
+		info.WWN = readSysFile(filepath.Join(devDir, "device", "wwid"))
+	}
+	if info.BusType == "" {
+		info.BusType = deviceBusType(filepath.Base(device))
+	}
+	info.LUN = deviceLUN(devDir)
+	return info
+}
+
+// deviceBusType returns the type of the bus of the device with the name for
+// the devices whose buses udev does not report.
+func deviceBusType(name string) string {
+	switch {
+	case strings.HasPrefix(name, "nvme"):
+		return "nvme"
+	case strings.HasPrefix(name, "vd"):
+		return "virtio"
+	case strings.HasPrefix(name, "xvd"):
+		return "xen"
+	}
+	return ""
+}
+
+// deviceLUN returns the LUN of a SCSI device, the last element of the
+// host:channel:target:lun address to which the device's sysfs device link
+// points. An empty string is returned for devices that are not SCSI devices.
+func deviceLUN(devDir string) string {
+	p, err := filepath.EvalSymlinks(filepath.Join(devDir, "device"))
+	if err != nil {
+		return ""
+	}
+	addr := strings.Split(filepath.Base(p), ":")
+	if len(addr) != 4 {
+		return ""
+	}
+	if _, err := strconv.Atoi(addr[3]); err != nil {
+		return ""
+	}
+	return addr[3]
+}
+
+func readSysFile(path string) string {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(buf))
+}
+
+// parseUdevData returns the properties, the "E:" lines, and the symlinks,
+// the "S:" lines, of a device's entry in the udev database. The symlinks are
+// relative to /dev and are in the order in which udev recorded them.
+func parseUdevData(path string) (map[string]string, []string) {
+	props := map[string]string{}
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return props, nil
+	}
+	var links []string
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "S:"):
+			links = append(links, line[2:])
+		case strings.HasPrefix(line, "E:"):
+			kv := strings.SplitN(line[2:], "=", 2)
+			if len(kv) == 2 {
+				props[kv[0]] = kv[1]
+			}
+		}
+	}
+	return props, links
+}
+
+// udevLink returns the absolute path of the first of the device's symlinks
+// that has the prefix, or an empty string if the device has no such
+// symlink.
+func udevLink(links []string, prefix string) string {
+	for _, l := range links {
+		if strings.HasPrefix(l, prefix) {
+			return filepath.Join("/dev", l)
+		}
+	}
+	return ""
+}
+
+// parseMountInfo returns the mount points of the mounted devices keyed by
+// their device numbers, ex. 8:16. A device mounted more than once is
+// mapped to its first mount point.
+func parseMountInfo(path string) map[string]string {
+	mounts := map[string]string{}
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return mounts
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		// mount ID, parent ID, major:minor, root, mount point, ...
+		f := strings.Fields(scanner.Text())
+		if len(f) < 5 {
+			continue
+		}
+		if _, ok := mounts[f[2]]; !ok {
+			mounts[f[2]] = f[4]
+		}
+	}
+	return mounts
+}
@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+func TestDescribeLocalDevices(t *testing.T) {
+	dir, err := ioutil.TempDir("", "devinfo")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	mkfile := func(path, data string) {
+		path = filepath.Join(dir, path)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, ioutil.WriteFile(path, []byte(data), 0644))
+	}
+
+	mkfile("sys/xvdf/size", "2097152\n")
+	mkfile("sys/xvdf/dev", "202:80\n")
+	mkfile("sys/xvdg/size", "4194304\n")
+	mkfile("sys/xvdg/dev", "202:96\n")
+	mkfile("sys/xvdg/device/serial", "vol-0123\n")
+	mkfile("udev/b202:80", "S:disk/by-id/nvme-vol0456\n"+
+		"E:ID_SERIAL_SHORT=vol0456\n"+
+		"E:ID_WWN=0x5000c500a1b2c3d4\n"+
+		"E:ID_BUS=scsi\n"+
+		"E:ID_PATH=pci-0000:00:04.0-scsi-0:0:1:3\n"+
+		"E:ID_FS_TYPE=ext4\n")
+	mkfile("sys/devices/0:0:1:3/model", "Disk\n")
+	assert.NoError(t, os.Symlink(
+		filepath.Join(dir, "sys/devices/0:0:1:3"),
+		filepath.Join(dir, "sys/xvdf/device")))
+	mkfile("mountinfo",
+		"22 1 202:1 / / rw,relatime shared:1 - ext4 /dev/xvda1 rw\n"+
+			"40 22 202:80 / /var/lib/libstorage/volumes/vol0456/data "+
+			"rw,relatime shared:20 - ext4 /dev/xvdf rw\n")
+
+	oldSysBlockDir, oldUdevDataDir, oldMountInfo :=
+		sysBlockDir, udevDataDir, mountInfo
+	sysBlockDir = filepath.Join(dir, "sys")
+	udevDataDir = filepath.Join(dir, "udev")
+	mountInfo = filepath.Join(dir, "mountinfo")
+	defer func() {
+		sysBlockDir, udevDataDir, mountInfo =
+			oldSysBlockDir, oldUdevDataDir, oldMountInfo
+	}()
+
+	ld := &types.LocalDevices{
+		Driver: "ebs",
+		DeviceMap: map[string]string{
+			"vol-0456": "/dev/xvdf",
+			"vol-0123": "/dev/xvdg",
+			"vol-0789": "/dev/xvdh",
+		},
+	}
+	DescribeLocalDevices(ld)
+
+	assert.Len(t, ld.Devices, 3)
+	assert.Equal(t, &types.LocalDevice{
+		Size:       1073741824,
+		Serial:     "vol0456",
+		WWN:        "0x5000c500a1b2c3d4",
+		BusType:    "scsi",
+		LUN:        "3",
+		ByID:       "/dev/disk/by-id/nvme-vol0456",
+		ByPath:     "/dev/disk/by-path/pci-0000:00:04.0-scsi-0:0:1:3",
+		FSType:     "ext4",
+		MountPoint: "/var/lib/libstorage/volumes/vol0456/data",
+	}, ld.Devices["/dev/xvdf"])
+	assert.Equal(t, &types.LocalDevice{
+		Size:    2147483648,
+		Serial:  "vol-0123",
+		BusType: "xen",
+	}, ld.Devices["/dev/xvdg"])
+	assert.Equal(t, &types.LocalDevice{BusType: "xen"}, ld.Devices["/dev/xvdh"])
+}
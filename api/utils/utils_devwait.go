@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"time"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+const (
+	// deviceEventPollInterval is how often devices are checked when device
+	// events are available, in case an event is missed.
+	deviceEventPollInterval = 5 * time.Second
+
+	// devicePollInterval is how often devices are checked when device
+	// events are not available.
+	devicePollInterval = 500 * time.Millisecond
+)
+
+// WaitForDevice invokes the check function each time a device is added or
+// changed until the function returns true or an error, or until the timeout
+// elapses. A flag is returned indicating whether or not the check function
+// returned true before the timeout elapsed.
+//
+// Device events are received from the kernel and udev over netlink. If
+// netlink is not available then the /dev directory is watched with inotify,
+// and if that is not available either then the check function is invoked at
+// a short, regular interval.
+func WaitForDevice(
+	ctx types.Context,
+	timeout time.Duration,
+	check func() (bool, error)) (bool, error) {
+
+	if ok, err := check(); ok || err != nil {
+		return ok, err
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+
+	interval := deviceEventPollInterval
+	events, err := deviceEvents(stop)
+	if err != nil {
+		ctx.WithError(err).Debug("device events unavailable; polling")
+		interval = devicePollInterval
+	}
+
+	timeoutC := time.After(timeout)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-timeoutC:
+			return false, nil
+		case <-events:
+		case <-ticker.C:
+		}
+		if ok, err := check(); ok || err != nil {
+			return ok, err
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// +build linux
+
+package utils
+
+import (
+	"bytes"
+	"syscall"
+
+	"github.com/akutz/goof"
+)
+
+const (
+	// netlinkGroupKernel is the netlink multicast group of the kernel's
+	// uevents.
+	netlinkGroupKernel = 1
+
+	// netlinkGroupUdev is the netlink multicast group of udev's events,
+	// which are sent once udev has created a device's symlinks, such as
+	// those in /dev/disk/by-id.
+	netlinkGroupUdev = 2
+
+	// deviceEventWaitMillis is how long to wait for an event before
+	// checking whether or not to stop.
+	deviceEventWaitMillis = 250
+)
+
+var (
+	blockSubsystem = []byte("SUBSYSTEM=block")
+
+	inotifyDirs = []string{"/dev", "/dev/disk/by-id", "/dev/disk/by-path"}
+)
+
+// deviceEvents returns a channel that receives a value each time a device
+// is added or changed, until the stop channel is closed.
+func deviceEvents(stop <-chan struct{}) (<-chan struct{}, error) {
+	if fd, err := netlinkSocket(); err == nil {
+		return watchDeviceEvents(fd, stop, isBlockUevent)
+	}
+	fd, err := inotifyDevDirs()
+	if err != nil {
+		return nil, err
+	}
+	return watchDeviceEvents(fd, stop, nil)
+}
+
+// netlinkSocket returns a netlink socket that receives the kernel's and
+// udev's uevents.
+func netlinkSocket() (int, error) {
+	fd, err := syscall.Socket(
+		syscall.AF_NETLINK,
+		syscall.SOCK_RAW|syscall.SOCK_CLOEXEC,
+		syscall.NETLINK_KOBJECT_UEVENT)
+	if err != nil {
+		return -1, err
+	}
+	if err := syscall.Bind(fd, &syscall.SockaddrNetlink{
+		Family: syscall.AF_NETLINK,
+		Groups: netlinkGroupKernel | netlinkGroupUdev,
+	}); err != nil {
+		syscall.Close(fd)
+		return -1, err
+	}
+	return fd, nil
+}
+
+// inotifyDevDirs returns an inotify instance that watches for the creation
+// of files in the device directories.
+func inotifyDevDirs() (int, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC)
+	if err != nil {
+		return -1, err
+	}
+	watched := false
+	for _, dir := range inotifyDirs {
+		if _, err := syscall.InotifyAddWatch(
+			fd, dir, syscall.IN_CREATE|syscall.IN_MOVED_TO); err == nil {
+			watched = true
+		}
+	}
+	if !watched {
+		syscall.Close(fd)
+		return -1, goof.New("error watching device directories")
+	}
+	return fd, nil
+}
+
+// isBlockUevent returns a flag indicating whether or not the uevent is for
+// a block device.
+func isBlockUevent(buf []byte) bool {
+	return bytes.Contains(buf, blockSubsystem)
+}
+
+// watchDeviceEvents reads the events from the file descriptor, sending a
+// value to the returned channel for each event that matches the provided
+// function, or for every event if the function is nil. The file descriptor
+// is closed once the stop channel is closed.
+func watchDeviceEvents(
+	fd int,
+	stop <-chan struct{},
+	match func([]byte) bool) (<-chan struct{}, error) {
+
+	epfd, err := syscall.EpollCreate1(syscall.EPOLL_CLOEXEC)
+	if err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	if err := syscall.EpollCtl(
+		epfd, syscall.EPOLL_CTL_ADD, fd,
+		&syscall.EpollEvent{Events: syscall.EPOLLIN, Fd: int32(fd)}); err != nil {
+		syscall.Close(epfd)
+		syscall.Close(fd)
+		return nil, err
+	}
+
+	events := make(chan struct{}, 1)
+
+	go func() {
+		defer syscall.Close(fd)
+		defer syscall.Close(epfd)
+
+		var (
+			buf = make([]byte, 8192)
+			eps = make([]syscall.EpollEvent, 1)
+		)
+
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			n, err := syscall.EpollWait(epfd, eps, deviceEventWaitMillis)
+			if err != nil && err != syscall.EINTR {
+				return
+			}
+			if n <= 0 {
+				continue
+			}
+
+			n, err = syscall.Read(fd, buf)
+			if err != nil || n <= 0 {
+				continue
+			}
+			if match != nil && !match(buf[:n]) {
+				continue
+			}
+
+			// the receiver only needs to know that something changed, so
+			// events that arrive while one is pending are dropped
+			select {
+			case events <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	return events, nil
+}
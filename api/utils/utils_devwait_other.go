@@ -0,0 +1,9 @@
+// +build !linux
+
+package utils
+
+import "github.com/akutz/goof"
+
+func deviceEvents(stop <-chan struct{}) (<-chan struct{}, error) {
+	return nil, goof.New("device events unsupported")
+}
@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/akutz/goof"
+)
+
+// diskPathPrefix is the prefix of the path of a Windows physical disk.
+const diskPathPrefix = `\\.\PHYSICALDRIVE`
+
+// Disk is a disk attached to a Windows host.
+type Disk struct {
+	// Number is the disk's number.
+	Number int `json:"Number"`
+
+	// Location is the disk's location, ex.
+	// "Integrated : Adapter 3 : Port 0 : Target 0 : LUN 2".
+	Location string `json:"Location"`
+
+	// SerialNumber is the disk's serial number.
+	SerialNumber string `json:"SerialNumber"`
+
+	// IsBoot is a flag indicating whether or not the disk is the boot disk.
+	IsBoot bool `json:"IsBoot"`
+
+	// IsSystem is a flag indicating whether or not the disk is the system
+	// disk.
+	IsSystem bool `json:"IsSystem"`
+}
+
+// Path returns the disk's path, ex. \\.\PHYSICALDRIVE2.
+func (d *Disk) Path() string {
+	return DiskPath(d.Number)
+}
+
+var (
+	diskAdapterRX = regexp.MustCompile(`(?i)\bAdapter (\d+)`)
+	diskTargetRX  = regexp.MustCompile(`(?i)\bTarget (\d+)`)
+	diskLUNRX     = regexp.MustCompile(`(?i)\bLUN (\d+)`)
+)
+
+// Adapter returns the number of the disk's storage adapter from the disk's
+// location, or -1 if the location does not include one.
+func (d *Disk) Adapter() int {
+	return locationID(diskAdapterRX, d.Location)
+}
+
+// Target returns the SCSI target ID from the disk's location, or -1 if the
+// location does not include one.
+func (d *Disk) Target() int {
+	return locationID(diskTargetRX, d.Location)
+}
+
+// LUN returns the SCSI logical unit number from the disk's location, or -1
+// if the location does not include one.
+func (d *Disk) LUN() int {
+	return locationID(diskLUNRX, d.Location)
+}
+
+func locationID(rx *regexp.Regexp, location string) int {
+	m := rx.FindStringSubmatch(location)
+	if m == nil {
+		return -1
+	}
+	i, err := strconv.Atoi(m[1])
+	if err != nil {
+		return -1
+	}
+	return i
+}
+
+// DiskPath returns the path of the Windows physical disk with the provided
+// number.
+func DiskPath(number int) string {
+	return fmt.Sprintf("%s%d", diskPathPrefix, number)
+}
+
+// DiskNumber returns the number of the Windows physical disk with the
+// provided path. The path may also be the disk's number.
+func DiskNumber(path string) (int, error) {
+	n := path
+	if len(n) > len(diskPathPrefix) &&
+		strings.EqualFold(n[:len(diskPathPrefix)], diskPathPrefix) {
+		n = n[len(diskPathPrefix):]
+	}
+	i, err := strconv.Atoi(n)
+	if err != nil || i < 0 {
+		return 0, goof.WithField("path", path, "invalid disk path")
+	}
+	return i, nil
+}
+
+// ParseDisks parses the JSON output of the PowerShell Get-Disk cmdlet. The
+// cmdlet emits an object rather than an array when there is only one disk.
+func ParseDisks(buf []byte) ([]*Disk, error) {
+	buf = []byte(strings.TrimSpace(string(buf)))
+	if len(buf) == 0 {
+		return nil, nil
+	}
+	if buf[0] == '{' {
+		disk := &Disk{}
+		if err := json.Unmarshal(buf, disk); err != nil {
+			return nil, err
+		}
+		return []*Disk{disk}, nil
+	}
+	var disks []*Disk
+	if err := json.Unmarshal(buf, &disks); err != nil {
+		return nil, err
+	}
+	return disks, nil
+}
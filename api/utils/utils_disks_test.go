@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiskNumber(t *testing.T) {
+	n, err := DiskNumber(`\\.\PHYSICALDRIVE2`)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	n, err = DiskNumber(`\\.\physicaldrive12`)
+	assert.NoError(t, err)
+	assert.Equal(t, 12, n)
+
+	n, err = DiskNumber("3")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, `\\.\PHYSICALDRIVE3`, DiskPath(n))
+
+	_, err = DiskNumber("/dev/xvdf")
+	assert.Error(t, err)
+}
+
+func TestParseDisks(t *testing.T) {
+	disks, err := ParseDisks([]byte(`{"Number":0,"IsBoot":true}`))
+	assert.NoError(t, err)
+	assert.Len(t, disks, 1)
+	assert.True(t, disks[0].IsBoot)
+
+	disks, err = ParseDisks([]byte(`[
+		{"Number":0,"Location":"PCI Slot 3 : Adapter 0 : Port 0 : ` +
+		`Target 0 : LUN 0","IsBoot":true,"IsSystem":true},
+		{"Number":1,"Location":"Integrated : Adapter 3 : Port 0 : ` +
+		`Target 5 : LUN 2","SerialNumber":"vol0123"}]`))
+	assert.NoError(t, err)
+	assert.Len(t, disks, 2)
+	assert.Equal(t, `\\.\PHYSICALDRIVE1`, disks[1].Path())
+	assert.Equal(t, 0, disks[0].Adapter())
+	assert.Equal(t, 3, disks[1].Adapter())
+	assert.Equal(t, 5, disks[1].Target())
+	assert.Equal(t, 2, disks[1].LUN())
+	assert.Equal(t, "vol0123", disks[1].SerialNumber)
+
+	disks, err = ParseDisks([]byte(`{"Number":4}`))
+	assert.NoError(t, err)
+	assert.Equal(t, -1, disks[0].Target())
+	assert.Equal(t, -1, disks[0].LUN())
+
+	disks, err = ParseDisks(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, disks)
+}
@@ -0,0 +1,48 @@
+// +build windows
+
+package utils
+
+import (
+	"strings"
+
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// PowerShell runs the provided PowerShell script and returns its output.
+func PowerShell(ctx types.Context, script string) ([]byte, error) {
+	out, err := CommandContext(
+		ctx,
+		"powershell",
+		"-NoProfile",
+		"-NonInteractive",
+		"-Command",
+		"$ErrorActionPreference = 'Stop'; "+script).CombinedOutput()
+	if err != nil {
+		return nil, goof.WithFieldsE(goof.Fields{
+			"script": script,
+			"output": strings.TrimSpace(string(out)),
+		}, "error running powershell", err)
+	}
+	return out, nil
+}
+
+// PowerShellQuote returns the provided string as a single-quoted PowerShell
+// string literal.
+func PowerShellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+// Disks returns the disks attached to the host.
+func Disks(ctx types.Context) ([]*Disk, error) {
+	out, err := PowerShell(
+		ctx,
+		"@(Get-Disk | Select-Object "+
+			"Number,Location,SerialNumber,IsBoot,IsSystem) | "+
+			"ConvertTo-Json -Compress")
+	if err != nil {
+		return nil, err
+	}
+	return ParseDisks(out)
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"time"
+
 	"github.com/akutz/goof"
 
 	"github.com/codedellemc/libstorage/api/types"
@@ -24,13 +26,32 @@ func NewBadAdminTokenError(token string) error {
 	}
 }
 
-// NewNotFoundError returns a new ErrNotFound error.
+// NewNotFoundError returns a new ErrNotFound error for a resource that is
+// not a volume or snapshot.
 func NewNotFoundError(resourceID string) error {
 	return &types.ErrNotFound{
 		Goof: goof.WithField("resourceID", resourceID, "resource not found"),
 	}
 }
 
+// NewVolumeNotFoundError returns a new ErrNotFound error for a volume.
+func NewVolumeNotFoundError(volumeID string) error {
+	return &types.ErrNotFound{
+		Goof: goof.WithField(
+			"resourceID", volumeID, "resource not found"),
+		ResourceType: types.ResourceTypeVolume,
+	}
+}
+
+// NewSnapshotNotFoundError returns a new ErrNotFound error for a snapshot.
+func NewSnapshotNotFoundError(snapshotID string) error {
+	return &types.ErrNotFound{
+		Goof: goof.WithField(
+			"resourceID", snapshotID, "resource not found"),
+		ResourceType: types.ResourceTypeSnapshot,
+	}
+}
+
 // NewMissingInstanceIDError returns a new ErrMissingInstanceID error.
 func NewMissingInstanceIDError(service string) error {
 	return &types.ErrMissingInstanceID{
@@ -64,3 +85,153 @@ func NewBadFilterErr(filter string, err error) error {
 	return &types.ErrBadFilter{Goof: goof.WithFieldE(
 		"filter", filter, "bad filter", err)}
 }
+
+// NewWrongZoneError returns a new ErrWrongZone error.
+func NewWrongZoneError(volumeID, volumeZone, instanceZone string) error {
+	return &types.ErrWrongZone{Goof: goof.WithFields(goof.Fields{
+		"volumeID":     volumeID,
+		"volumeZone":   volumeZone,
+		"instanceZone": instanceZone,
+	}, "volume and instance are in different zones")}
+}
+
+// NewWrongRackError returns a new ErrWrongZone error for a volume and an
+// instance that reside in different racks.
+func NewWrongRackError(volumeID, volumeRack, instanceRack string) error {
+	return &types.ErrWrongZone{Goof: goof.WithFields(goof.Fields{
+		"volumeID":     volumeID,
+		"volumeRack":   volumeRack,
+		"instanceRack": instanceRack,
+	}, "volume and instance are in different racks")}
+}
+
+// NewInvalidRequestError returns a new ErrInvalidRequest error.
+func NewInvalidRequestError(field string, value interface{}, msg string) error {
+	return &types.ErrInvalidRequest{Goof: goof.WithFields(goof.Fields{
+		"field": field,
+		"value": value,
+	}, msg)}
+}
+
+// NewVolumeAttachedError returns a new ErrVolumeAttached error.
+func NewVolumeAttachedError(volumeID string) error {
+	return &types.ErrVolumeAttached{
+		Goof: goof.WithField("volumeID", volumeID, "volume is attached"),
+	}
+}
+
+// NewVolumeInUseError returns a new ErrVolumeInUse error. The IDs of the
+// instances to which the volume is attached are included if provided.
+func NewVolumeInUseError(volumeID string, instanceIDs ...string) error {
+	fields := goof.Fields{"volumeID": volumeID}
+	if len(instanceIDs) > 0 {
+		fields["instanceIDs"] = instanceIDs
+	}
+	return &types.ErrVolumeInUse{
+		Goof: goof.WithFields(fields, "volume is in use"),
+	}
+}
+
+// NewQuotaExceededError returns a new ErrQuotaExceeded error.
+func NewQuotaExceededError(quota string, limit, requested int64) error {
+	return &types.ErrQuotaExceeded{Goof: goof.WithFields(goof.Fields{
+		"quota":     quota,
+		"limit":     limit,
+		"requested": requested,
+	}, "quota exceeded")}
+}
+
+// NewDriverTimeoutError returns a new ErrDriverTimeout error.
+func NewDriverTimeoutError(timeout time.Duration, task *types.Task) error {
+
+	fields := goof.Fields{"timeout": timeout.String()}
+	if task != nil {
+		fields["taskID"] = task.ID
+		fields["taskState"] = task.State
+	}
+	return &types.ErrDriverTimeout{
+		Goof: goof.WithFields(fields, "driver operation timed out"),
+	}
+}
+
+// NewInstanceIDBindingError returns a new ErrInstanceIDBinding error.
+func NewInstanceIDBindingError(service, instanceID string) error {
+	return &types.ErrInstanceIDBinding{Goof: goof.WithFields(goof.Fields{
+		"service":    service,
+		"instanceID": instanceID,
+	}, "instance id bound to different client certificate")}
+}
+
+// NewServiceUnavailableError returns a new ErrServiceUnavailable error.
+func NewServiceUnavailableError(
+	service string, retryAfter time.Duration) error {
+
+	return &types.ErrServiceUnavailable{
+		Goof: goof.WithFields(goof.Fields{
+			"service":    service,
+			"retryAfter": retryAfter.String(),
+		}, "service unavailable"),
+		RetryAfter: retryAfter,
+	}
+}
+
+// NewRequestTooLargeError returns a new ErrRequestTooLarge error.
+func NewRequestTooLargeError(maxSize int64) error {
+	return &types.ErrRequestTooLarge{Goof: goof.WithField(
+		"maxSize", maxSize, "request body too large")}
+}
+
+// NewPolicyDeniedError returns a new ErrPolicyDenied error.
+func NewPolicyDeniedError(operation string, reasons []string) error {
+	fields := goof.Fields{"operation": operation}
+	if len(reasons) > 0 {
+		fields["reasons"] = reasons
+	}
+	return &types.ErrPolicyDenied{
+		Goof: goof.WithFields(fields, "request denied by policy"),
+	}
+}
+
+// NewBackendError returns a new ErrBackend error with the same message as
+// the backend's error.
+func NewBackendError(inner error) error {
+	return &types.ErrBackend{Goof: goof.WithError(inner.Error(), inner)}
+}
+
+// NewThrottledError returns a new ErrThrottled error with the same message
+// as the backend's error.
+func NewThrottledError(inner error, retryAfter time.Duration) error {
+	return &types.ErrThrottled{
+		Goof: goof.WithFieldE(
+			"retryAfter", retryAfter.String(), inner.Error(), inner),
+		RetryAfter: retryAfter,
+	}
+}
+
+// NewTaskQueueFullError returns a new ErrServiceUnavailable error that
+// indicates a service's task queue is full.
+func NewTaskQueueFullError(service string, queueSize int) error {
+	return &types.ErrServiceUnavailable{Goof: goof.WithFields(goof.Fields{
+		"service":   service,
+		"queueSize": queueSize,
+	}, "task queue full")}
+}
+
+// NewDeadlineExceededError returns a new ErrDeadlineExceeded error.
+func NewDeadlineExceededError(deadline time.Time, task *types.Task) error {
+
+	fields := goof.Fields{
+		"deadline": deadline.UTC().Format(time.RFC3339Nano),
+	}
+	if task != nil {
+		fields["taskID"] = task.ID
+		fields["taskState"] = task.State
+		if task.StartTime > 0 {
+			fields["elapsed"] = time.Since(
+				time.Unix(task.StartTime, 0)).String()
+		}
+	}
+	return &types.ErrDeadlineExceeded{
+		Goof: goof.WithFields(fields, "deadline exceeded"),
+	}
+}
@@ -0,0 +1,223 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// execSettings are the settings with which the executables run by drivers
+// are found and run.
+type execSettings struct {
+	// paths are the absolute paths of executables, keyed by their names
+	paths map[string]string
+
+	// searchPath is the PATH in which executables are found
+	searchPath []string
+
+	// env are the variables added to the environment of executables
+	env []string
+
+	// allow are the names of the only executables that may be run
+	allow map[string]bool
+
+	// chroot is the root directory of executables
+	chroot string
+
+	// namespaces are the Linux namespaces in which executables are run
+	namespaces []string
+}
+
+var (
+	execLock sync.RWMutex
+	execCfg  = &execSettings{}
+)
+
+// InitExec configures how the executables run by drivers, ex. rbd or mount,
+// are found and run. Executables may be given absolute paths, found in a
+// fixed search path rather than the process's PATH, limited to an
+// allow-list, given additional environment variables, and run in a chroot
+// or in new Linux namespaces.
+func InitExec(config gofig.Config) error {
+	s := &execSettings{
+		paths:  map[string]string{},
+		env:    getStringSlice(config, types.ConfigExecEnv),
+		chroot: config.GetString(types.ConfigExecChroot),
+	}
+
+	paths, _ := get(config, types.ConfigExecPaths).(map[string]interface{})
+	for name, v := range paths {
+		p := fmt.Sprintf("%v", v)
+		if !filepath.IsAbs(p) {
+			return goof.WithFields(goof.Fields{
+				"name": name,
+				"path": p,
+			}, "executable path must be absolute")
+		}
+		s.paths[name] = p
+	}
+
+	// a relative directory in the search path would find executables
+	// relative to the working directory
+	if sp := config.GetString(types.ConfigExecSearchPath); sp != "" {
+		for _, dir := range filepath.SplitList(sp) {
+			if !filepath.IsAbs(dir) {
+				return goof.WithField(
+					"dir", dir, "search path directory must be absolute")
+			}
+			s.searchPath = append(s.searchPath, dir)
+		}
+	}
+
+	for _, v := range s.env {
+		if !strings.Contains(v, "=") {
+			return goof.WithField(
+				"env", v, "environment variable must be KEY=VALUE")
+		}
+	}
+
+	if allow := getStringSlice(config, types.ConfigExecAllow); len(allow) > 0 {
+		s.allow = map[string]bool{}
+		for _, name := range allow {
+			s.allow[name] = true
+		}
+	}
+
+	if s.chroot != "" && !filepath.IsAbs(s.chroot) {
+		return goof.WithField(
+			"chroot", s.chroot, "chroot directory must be absolute")
+	}
+
+	s.namespaces = getStringSlice(config, types.ConfigExecNamespaces)
+	if err := validateExecIsolation(s.chroot, s.namespaces); err != nil {
+		return err
+	}
+
+	execLock.Lock()
+	defer execLock.Unlock()
+	execCfg = s
+	return nil
+}
+
+func getExecSettings() *execSettings {
+	execLock.RLock()
+	defer execLock.RUnlock()
+	return execCfg
+}
+
+// Command returns a command that runs the named executable with the
+// configured execution settings. It should be used in place of
+// exec.Command by drivers that do not have a context.
+func Command(name string, args ...string) *exec.Cmd {
+	return newCommand(nil, name, args...)
+}
+
+// LookPath returns the path of the named executable as it is found when run
+// with the configured execution settings, or an error if the executable is
+// not allowed or cannot be found.
+func LookPath(name string) (string, error) {
+	s := getExecSettings()
+
+	path, err := s.resolve(name)
+	if err != nil {
+		return "", err
+	}
+	if !strings.ContainsRune(path, os.PathSeparator) {
+		return exec.LookPath(path)
+	}
+	if _, err := os.Stat(filepath.Join("/", s.chroot, path)); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// newCommand returns a command that runs the named executable with the
+// configured execution settings. An executable that is not allowed or is not
+// found in the configured search path is replaced with a path that cannot be
+// run, so the command fails when it is started.
+func newCommand(ctx types.Context, name string, args ...string) *exec.Cmd {
+	s := getExecSettings()
+
+	path, err := s.resolve(name)
+	if err != nil {
+		fields := log.Fields{"name": name}
+		if ctx != nil {
+			ctx.WithFields(fields).WithError(err).Error(
+				"cannot run executable")
+		} else {
+			log.WithFields(fields).WithError(err).Error(
+				"cannot run executable")
+		}
+		path = filepath.Join(os.DevNull, filepath.Base(name))
+	}
+
+	cmd := newExecCmd(ctx, path, args...)
+	s.apply(cmd)
+	return cmd
+}
+
+// resolve returns the path of the named executable.
+func (s *execSettings) resolve(name string) (string, error) {
+	base := filepath.Base(name)
+	if s.allow != nil && !s.allow[base] {
+		return "", goof.WithField(
+			"name", base, "executable is not allowed")
+	}
+	if p, ok := s.paths[base]; ok {
+		return p, nil
+	}
+	if len(s.searchPath) == 0 ||
+		strings.ContainsRune(name, os.PathSeparator) {
+		return name, nil
+	}
+
+	// the executable is found relative to the root directory in which it
+	// is run
+	for _, dir := range s.searchPath {
+		p := filepath.Join(dir, name)
+		if fi, err := os.Stat(filepath.Join("/", s.chroot, p)); err == nil &&
+			!fi.IsDir() && fi.Mode()&0111 != 0 {
+			return p, nil
+		}
+	}
+	return "", goof.WithField(
+		"name", name, "executable not found in search path")
+}
+
+// apply sets the command's environment and isolation.
+func (s *execSettings) apply(cmd *exec.Cmd) {
+	if len(s.env) > 0 || len(s.searchPath) > 0 {
+		var vars []string
+		if len(s.searchPath) > 0 {
+			vars = append(vars, "PATH="+strings.Join(
+				s.searchPath, string(os.PathListSeparator)))
+		}
+		cmd.Env = mergeEnv(os.Environ(), append(vars, s.env...))
+	}
+	setExecIsolation(cmd, s.chroot, s.namespaces)
+}
+
+// mergeEnv returns the environment with the variables, replacing any of the
+// environment's variables with the same names.
+func mergeEnv(env, vars []string) []string {
+	names := map[string]bool{}
+	for _, v := range vars {
+		names[strings.SplitN(v, "=", 2)[0]] = true
+	}
+	merged := make([]string, 0, len(env)+len(vars))
+	for _, v := range env {
+		if !names[strings.SplitN(v, "=", 2)[0]] {
+			merged = append(merged, v)
+		}
+	}
+	return append(merged, vars...)
+}
@@ -0,0 +1,24 @@
+// +build go1.7
+
+package utils
+
+import (
+	"os/exec"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// CommandContext returns a command that is killed if the context's deadline
+// is exceeded or the context is canceled before the command completes. The
+// command runs the named executable with the configured execution settings.
+func CommandContext(
+	ctx types.Context, name string, args ...string) *exec.Cmd {
+	return newCommand(ctx, name, args...)
+}
+
+func newExecCmd(ctx types.Context, path string, args ...string) *exec.Cmd {
+	if ctx == nil {
+		return exec.Command(path, args...)
+	}
+	return exec.CommandContext(ctx, path, args...)
+}
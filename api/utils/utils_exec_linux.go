@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"os/exec"
+	"syscall"
+
+	"github.com/akutz/goof"
+)
+
+// execNamespaces are the clone flags of the Linux namespaces in which
+// executables may be run. A new mount or PID namespace is not supported,
+// since the mounts made by executables such as mount must be visible to the
+// host and the devices they map must be found by the host's processes.
+var execNamespaces = map[string]uintptr{
+	"ipc": syscall.CLONE_NEWIPC,
+	"net": syscall.CLONE_NEWNET,
+	"uts": syscall.CLONE_NEWUTS,
+}
+
+func validateExecIsolation(chroot string, namespaces []string) error {
+	for _, ns := range namespaces {
+		if _, ok := execNamespaces[ns]; !ok {
+			return goof.WithField(
+				"namespace", ns, "unsupported exec namespace")
+		}
+	}
+	return nil
+}
+
+// setExecIsolation sets the command's root directory and the namespaces in
+// which it is run.
+func setExecIsolation(cmd *exec.Cmd, chroot string, namespaces []string) {
+	if chroot == "" && len(namespaces) == 0 {
+		return
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Chroot = chroot
+	for _, ns := range namespaces {
+		cmd.SysProcAttr.Cloneflags |= execNamespaces[ns]
+	}
+}
@@ -0,0 +1,21 @@
+// +build !linux
+
+package utils
+
+import (
+	"os/exec"
+
+	"github.com/akutz/goof"
+)
+
+// validateExecIsolation returns an error if a chroot or namespaces are
+// configured, since running executables in them is only supported on Linux.
+func validateExecIsolation(chroot string, namespaces []string) error {
+	if chroot != "" || len(namespaces) > 0 {
+		return goof.New("exec chroot and namespaces require linux")
+	}
+	return nil
+}
+
+func setExecIsolation(cmd *exec.Cmd, chroot string, namespaces []string) {
+}
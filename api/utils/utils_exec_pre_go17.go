@@ -0,0 +1,21 @@
+// +build !go1.7
+
+package utils
+
+import (
+	"os/exec"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// CommandContext returns a command. Prior to Go 1.7 the context's deadline
+// is not honored by the command. The command runs the named executable with
+// the configured execution settings.
+func CommandContext(
+	ctx types.Context, name string, args ...string) *exec.Cmd {
+	return newCommand(ctx, name, args...)
+}
+
+func newExecCmd(ctx types.Context, path string, args ...string) *exec.Cmd {
+	return exec.Command(path, args...)
+}
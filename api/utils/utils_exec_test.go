@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecResolve(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exec")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	rbd := filepath.Join(dir, "rbd")
+	if !assert.NoError(t, ioutil.WriteFile(rbd, nil, 0755)) {
+		t.FailNow()
+	}
+
+	s := &execSettings{
+		paths:      map[string]string{"mount": "/bin/mount"},
+		searchPath: []string{dir},
+		allow:      map[string]bool{"rbd": true, "mount": true, "rados": true},
+	}
+
+	p, err := s.resolve("rbd")
+	assert.NoError(t, err)
+	assert.Equal(t, rbd, p)
+
+	p, err = s.resolve("mount")
+	assert.NoError(t, err)
+	assert.Equal(t, "/bin/mount", p)
+
+	// executables must be found in the search path
+	_, err = s.resolve("rados")
+	assert.Error(t, err)
+
+	// executables must be allowed
+	_, err = s.resolve("/tmp/rbd2")
+	assert.Error(t, err)
+}
+
+func TestLookPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exec")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	mkfs := filepath.Join(dir, "mkfs.xfs")
+	if !assert.NoError(t, ioutil.WriteFile(mkfs, nil, 0755)) {
+		t.FailNow()
+	}
+
+	execLock.Lock()
+	og := execCfg
+	execCfg = &execSettings{searchPath: []string{dir}}
+	execLock.Unlock()
+	defer func() {
+		execLock.Lock()
+		execCfg = og
+		execLock.Unlock()
+	}()
+
+	p, err := LookPath("mkfs.xfs")
+	assert.NoError(t, err)
+	assert.Equal(t, mkfs, p)
+
+	_, err = LookPath("mkfs.btrfs")
+	assert.Error(t, err)
+}
+
+func TestMergeEnv(t *testing.T) {
+	assert.EqualValues(t,
+		[]string{"HOME=/root", "PATH=/sbin", "CEPH_ARGS=--id admin"},
+		mergeEnv(
+			[]string{"PATH=/usr/bin", "HOME=/root"},
+			[]string{"PATH=/sbin", "CEPH_ARGS=--id admin"}))
+}
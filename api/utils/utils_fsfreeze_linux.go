@@ -0,0 +1,56 @@
+// +build linux
+
+package utils
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/akutz/goof"
+)
+
+const (
+	// fiFreeze is the FIFREEZE ioctl, _IOWR('X', 119, int).
+	fiFreeze = 0xC0045877
+
+	// fiThaw is the FITHAW ioctl, _IOWR('X', 120, int).
+	fiThaw = 0xC0045878
+)
+
+// FreezeFileSystem freezes the file system mounted at the provided path so
+// that it is consistent on disk. Writes to the file system block until it
+// is thawed.
+func FreezeFileSystem(mountPoint string) error {
+	if err := fsIoctl(mountPoint, fiFreeze); err != nil {
+		return goof.WithFieldE(
+			"mountPoint", mountPoint, "error freezing file system", err)
+	}
+	return nil
+}
+
+// ThawFileSystem thaws the file system mounted at the provided path. Thawing
+// a file system that is not frozen is not an error.
+func ThawFileSystem(mountPoint string) error {
+	err := fsIoctl(mountPoint, fiThaw)
+	if err == syscall.EINVAL {
+		return nil
+	}
+	if err != nil {
+		return goof.WithFieldE(
+			"mountPoint", mountPoint, "error thawing file system", err)
+	}
+	return nil
+}
+
+func fsIoctl(mountPoint string, req uintptr) error {
+	f, err := os.Open(mountPoint)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, _, errno := syscall.Syscall(
+		syscall.SYS_IOCTL, f.Fd(), req, 0); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+// +build !linux
+
+package utils
+
+import "github.com/codedellemc/libstorage/api/types"
+
+// FreezeFileSystem freezes the file system mounted at the provided path.
+// Freezing file systems is only supported on Linux.
+func FreezeFileSystem(mountPoint string) error {
+	return types.ErrNotImplemented
+}
+
+// ThawFileSystem thaws the file system mounted at the provided path.
+// Thawing file systems is only supported on Linux.
+func ThawFileSystem(mountPoint string) error {
+	return types.ErrNotImplemented
+}
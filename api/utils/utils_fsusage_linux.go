@@ -0,0 +1,30 @@
+// +build linux
+
+package utils
+
+import (
+	"syscall"
+
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// FileSystemUsage returns the capacity usage of the file system mounted at
+// the provided path.
+func FileSystemUsage(mountPoint string) (*types.VolumeUsage, error) {
+	st := syscall.Statfs_t{}
+	if err := syscall.Statfs(mountPoint, &st); err != nil {
+		return nil, goof.WithFieldE(
+			"mountPoint", mountPoint, "error getting file system usage", err)
+	}
+	bsize := uint64(st.Bsize)
+	return &types.VolumeUsage{
+		MountPoint:     mountPoint,
+		TotalBytes:     st.Blocks * bsize,
+		UsedBytes:      (st.Blocks - st.Bfree) * bsize,
+		AvailableBytes: st.Bavail * bsize,
+		TotalInodes:    st.Files,
+		UsedInodes:     st.Files - st.Ffree,
+	}, nil
+}
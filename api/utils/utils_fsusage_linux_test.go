@@ -0,0 +1,24 @@
+// +build linux
+
+package utils
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSystemUsage(t *testing.T) {
+	usage, err := FileSystemUsage(os.TempDir())
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, os.TempDir(), usage.MountPoint)
+	assert.NotZero(t, usage.TotalBytes)
+	assert.True(t, usage.UsedBytes <= usage.TotalBytes)
+	assert.True(t, usage.AvailableBytes <= usage.TotalBytes)
+
+	_, err = FileSystemUsage("/no/such/mount/point")
+	assert.Error(t, err)
+}
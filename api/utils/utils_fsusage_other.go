@@ -0,0 +1,11 @@
+// +build !linux
+
+package utils
+
+import "github.com/codedellemc/libstorage/api/types"
+
+// FileSystemUsage returns the capacity usage of the file system mounted at
+// the provided path. Getting file system usage is only supported on Linux.
+func FileSystemUsage(mountPoint string) (*types.VolumeUsage, error) {
+	return nil, types.ErrNotImplemented
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// GeomDisk is a disk attached to a FreeBSD host as reported by the GEOM
+// disk class.
+type GeomDisk struct {
+	// Name is the disk's name, ex. "xbd5".
+	Name string
+
+	// MediaSize is the size of the disk in bytes.
+	MediaSize int64
+
+	// Descr is the disk's description, ex. "Xen Virtual Block Device".
+	Descr string
+
+	// Ident is the disk's identity, usually its serial number.
+	Ident string
+}
+
+// Path returns the disk's path, ex. /dev/xbd5.
+func (d *GeomDisk) Path() string {
+	return "/dev/" + d.Name
+}
+
+// ParseGeomDisks parses the output of "geom disk list".
+func ParseGeomDisks(buf []byte) []*GeomDisk {
+	var (
+		disks []*GeomDisk
+		disk  *GeomDisk
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "Geom name:") {
+			disk = &GeomDisk{
+				Name: strings.TrimSpace(
+					strings.TrimPrefix(line, "Geom name:")),
+			}
+			disks = append(disks, disk)
+			continue
+		}
+		if disk == nil {
+			continue
+		}
+		kv := strings.SplitN(line, ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		v := strings.TrimSpace(kv[1])
+		if v == "(null)" {
+			v = ""
+		}
+		switch kv[0] {
+		case "Mediasize":
+			// ex. "10737418240 (10G)"
+			if f := strings.Fields(v); len(f) > 0 {
+				disk.MediaSize, _ = strconv.ParseInt(f[0], 10, 64)
+			}
+		case "descr":
+			disk.Descr = v
+		case "ident":
+			disk.Ident = v
+		}
+	}
+	return disks
+}
@@ -0,0 +1,18 @@
+// +build freebsd
+
+package utils
+
+import (
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// GeomDisks returns the disks attached to the host.
+func GeomDisks(ctx types.Context) ([]*GeomDisk, error) {
+	out, err := CommandContext(ctx, "geom", "disk", "list").Output()
+	if err != nil {
+		return nil, goof.WithError("error listing geom disks", err)
+	}
+	return ParseGeomDisks(out), nil
+}
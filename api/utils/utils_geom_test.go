@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const geomDiskList = `Geom name: xbd0
+Providers:
+1. Name: xbd0
+   Mediasize: 10737418240 (10G)
+   Sectorsize: 512
+   Mode: r1w1e2
+   descr: (null)
+   ident: (null)
+   rotationrate: unknown
+   fwsectors: 0
+   fwheads: 0
+
+Geom name: xbd5
+Providers:
+1. Name: xbd5
+   Mediasize: 1073741824 (1.0G)
+   Sectorsize: 512
+   Mode: r0w0e0
+   descr: Xen Virtual Block Device
+   ident: vol-0123
+   rotationrate: unknown
+`
+
+func TestParseGeomDisks(t *testing.T) {
+	disks := ParseGeomDisks([]byte(geomDiskList))
+	if !assert.Len(t, disks, 2) {
+		t.FailNow()
+	}
+
+	assert.Equal(t, "xbd0", disks[0].Name)
+	assert.Equal(t, int64(10737418240), disks[0].MediaSize)
+	assert.Empty(t, disks[0].Descr)
+	assert.Empty(t, disks[0].Ident)
+
+	assert.Equal(t, "/dev/xbd5", disks[1].Path())
+	assert.Equal(t, int64(1073741824), disks[1].MediaSize)
+	assert.Equal(t, "Xen Virtual Block Device", disks[1].Descr)
+	assert.Equal(t, "vol-0123", disks[1].Ident)
+
+	assert.Empty(t, ParseGeomDisks(nil))
+}
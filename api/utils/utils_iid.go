@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+const (
+	// InstanceIDResolverStatic is the name of the resolver that returns the
+	// configured instance ID.
+	InstanceIDResolverStatic = "static"
+
+	// InstanceIDResolverMachineID is the name of the resolver that returns
+	// the host's machine ID, ex. the contents of /etc/machine-id.
+	InstanceIDResolverMachineID = "machineid"
+
+	// InstanceIDResolverDMIUUID is the name of the resolver that returns the
+	// host's DMI (SMBIOS) system UUID.
+	InstanceIDResolverDMIUUID = "dmiuuid"
+
+	// InstanceIDResolverCommand is the name of the resolver that returns the
+	// output of the configured command.
+	InstanceIDResolverCommand = "command"
+)
+
+var (
+	machineIDFiles = []string{"/etc/machine-id", "/var/lib/dbus/machine-id"}
+	dmiUUIDFile    = "/sys/class/dmi/id/product_uuid"
+)
+
+// ResolveInstanceID returns the instance ID derived by the first of the
+// configured instance ID resolvers that succeeds. Executors for platforms
+// without a metadata service use the resolvers so that an instance's
+// identity is stable across reboots and changes to its IP addresses.
+//
+// An empty string and a nil error are returned if no resolvers are
+// configured, in which case the executor should derive the instance ID as
+// it otherwise would. An error is returned if resolvers are configured but
+// none of them succeed.
+func ResolveInstanceID(
+	ctx types.Context, config gofig.Config) (string, error) {
+
+	resolvers := getStringSlice(config, types.ConfigExecutorInstanceIDResolvers)
+	if len(resolvers) == 0 {
+		return "", nil
+	}
+
+	for _, r := range resolvers {
+		id, err := resolveInstanceID(ctx, config, strings.ToLower(r))
+		if err != nil {
+			if ctx != nil {
+				ctx.WithError(err).WithField(
+					"resolver", r).Debug("instance ID resolver failed")
+			}
+			continue
+		}
+		if id != "" {
+			return id, nil
+		}
+	}
+
+	return "", goof.WithField(
+		"resolvers", resolvers, "no instance ID resolver succeeded")
+}
+
+func resolveInstanceID(
+	ctx types.Context, config gofig.Config, resolver string) (string, error) {
+
+	switch resolver {
+	case InstanceIDResolverStatic:
+		return getString(config, types.ConfigExecutorInstanceIDStatic), nil
+	case InstanceIDResolverMachineID:
+		for _, f := range machineIDFiles {
+			if id := readSysFile(f); id != "" {
+				return id, nil
+			}
+		}
+		return "", goof.New("machine ID not found")
+	case InstanceIDResolverDMIUUID:
+		buf, err := ioutil.ReadFile(dmiUUIDFile)
+		if err != nil {
+			return "", goof.WithError("error reading DMI UUID", err)
+		}
+		return strings.ToLower(strings.TrimSpace(string(buf))), nil
+	case InstanceIDResolverCommand:
+		return resolveInstanceIDCommand(
+			ctx, getString(config, types.ConfigExecutorInstanceIDCommand))
+	}
+	return "", goof.WithField(
+		"resolver", resolver, "invalid instance ID resolver")
+}
+
+// resolveInstanceIDCommand returns the trimmed output of the command, which
+// is run with the shell so that it may include arguments and pipes.
+func resolveInstanceIDCommand(
+	ctx types.Context, command string) (string, error) {
+
+	if command == "" {
+		return "", goof.New("instance ID command not configured")
+	}
+
+	var cmd *exec.Cmd
+	if ctx != nil {
+		cmd = CommandContext(ctx, "sh", "-c", command)
+	} else {
+		cmd = Command("sh", "-c", command)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return "", goof.WithFieldsE(log.Fields{
+			"command": command,
+		}, "error running instance ID command", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
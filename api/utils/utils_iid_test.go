@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gofigCore "github.com/akutz/gofig"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+func TestResolveInstanceID(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iid")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	machineIDFiles = []string{filepath.Join(dir, "machine-id")}
+	dmiUUIDFile = filepath.Join(dir, "product_uuid")
+	assert.NoError(t, ioutil.WriteFile(
+		dmiUUIDFile, []byte("EC2A1B2C-0000-1111-2222-333344445555\n"), 0644))
+
+	config := gofigCore.New()
+
+	id, err := ResolveInstanceID(nil, config)
+	assert.NoError(t, err)
+	assert.Empty(t, id)
+
+	config.Set(types.ConfigExecutorInstanceIDResolvers, "machineID,dmiUUID")
+	id, err = ResolveInstanceID(nil, config)
+	assert.NoError(t, err)
+	assert.Equal(t, "ec2a1b2c-0000-1111-2222-333344445555", id)
+
+	assert.NoError(t, ioutil.WriteFile(
+		machineIDFiles[0], []byte("0123456789abcdef\n"), 0644))
+	id, err = ResolveInstanceID(nil, config)
+	assert.NoError(t, err)
+	assert.Equal(t, "0123456789abcdef", id)
+
+	config.Set(types.ConfigExecutorInstanceIDResolvers, "static,command")
+	config.Set(types.ConfigExecutorInstanceIDCommand, "echo host-1 | tr h H")
+	id, err = ResolveInstanceID(nil, config)
+	assert.NoError(t, err)
+	assert.Equal(t, "Host-1", id)
+
+	config.Set(types.ConfigExecutorInstanceIDStatic, "rack1-node4")
+	id, err = ResolveInstanceID(nil, config)
+	assert.NoError(t, err)
+	assert.Equal(t, "rack1-node4", id)
+
+	config.Set(types.ConfigExecutorInstanceIDResolvers, "command,invalid")
+	config.Set(types.ConfigExecutorInstanceIDCommand, "exit 1")
+	_, err = ResolveInstanceID(nil, config)
+	assert.Error(t, err)
+}
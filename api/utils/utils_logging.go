@@ -1,12 +1,24 @@
 package utils
 
 import (
+	"fmt"
+	"strings"
+
 	log "github.com/Sirupsen/logrus"
 	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
 
 	"github.com/codedellemc/libstorage/api/types"
 )
 
+const (
+	// LogFormatText is the log format for human-readable text.
+	LogFormatText = "text"
+
+	// LogFormatJSON is the log format for structured JSON.
+	LogFormatJSON = "json"
+)
+
 // LoggingConfig is the logging configuration.
 type LoggingConfig struct {
 
@@ -24,6 +36,21 @@ type LoggingConfig struct {
 
 	// HTTPResponses is a flag indicating whether or not to log HTTP responses.
 	HTTPResponses bool
+
+	// Format is the log format.
+	Format string
+
+	// Levels maps component names, such as "server" or "driver.rbd", to
+	// the log levels that override Level for those components.
+	Levels map[string]log.Level
+}
+
+// Formatter returns the log formatter for the configured format.
+func (c *LoggingConfig) Formatter() log.Formatter {
+	if c.Format == LogFormatJSON {
+		return &log.JSONFormatter{}
+	}
+	return &log.TextFormatter{}
 }
 
 // ParseLoggingConfig returns a new LoggingConfig instance.
@@ -73,5 +100,103 @@ func ParseLoggingConfig(
 		f(types.ConfigLogHTTPResponses, logConfig.HTTPResponses)
 	}
 
+	switch format := strings.ToLower(
+		getString(config, types.ConfigLogFormat, roots...)); format {
+	case "", LogFormatText:
+		logConfig.Format = LogFormatText
+	case LogFormatJSON:
+		logConfig.Format = LogFormatJSON
+		f(types.ConfigLogFormat, format)
+	default:
+		return nil, goof.WithField("format", format, "invalid log format")
+	}
+
+	levels, err := parseLogLevels(
+		get(config, types.ConfigLogLevels, roots...))
+	if err != nil {
+		return nil, err
+	}
+	if len(levels) > 0 {
+		logConfig.Levels = levels
+		f(types.ConfigLogLevels, levels)
+	}
+
 	return logConfig, nil
 }
+
+// parseLogLevels parses component log levels from a map, which may be
+// nested, or from a string of comma-separated component=level pairs.
+func parseLogLevels(v interface{}) (map[string]log.Level, error) {
+
+	levels := map[string]log.Level{}
+
+	add := func(name string, val interface{}) error {
+		szVal := fmt.Sprintf("%v", val)
+		lvl, err := log.ParseLevel(szVal)
+		if err != nil {
+			return goof.WithFieldsE(goof.Fields{
+				"component": name,
+				"level":     szVal,
+			}, "invalid component log level", err)
+		}
+		levels[strings.ToLower(name)] = lvl
+		return nil
+	}
+
+	var walk func(prefix string, v interface{}) error
+	walk = func(prefix string, v interface{}) error {
+		switch tv := v.(type) {
+		case nil:
+			return nil
+		case string:
+			if prefix != "" {
+				return add(prefix, tv)
+			}
+			for _, p := range strings.Split(tv, ",") {
+				if p = strings.TrimSpace(p); p == "" {
+					continue
+				}
+				kv := strings.SplitN(p, "=", 2)
+				if len(kv) != 2 {
+					return goof.WithField(
+						"levels", tv, "invalid component log levels")
+				}
+				if err := add(
+					strings.TrimSpace(kv[0]),
+					strings.TrimSpace(kv[1])); err != nil {
+					return err
+				}
+			}
+			return nil
+		case map[string]interface{}:
+			for k, v := range tv {
+				if err := walk(joinComponent(prefix, k), v); err != nil {
+					return err
+				}
+			}
+			return nil
+		case map[interface{}]interface{}:
+			for k, v := range tv {
+				name := joinComponent(prefix, fmt.Sprintf("%v", k))
+				if err := walk(name, v); err != nil {
+					return err
+				}
+			}
+			return nil
+		default:
+			return add(prefix, tv)
+		}
+	}
+
+	if err := walk("", v); err != nil {
+		return nil, err
+	}
+	return levels, nil
+}
+
+func joinComponent(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+var (
+	sysBlockDir  = "/sys/block"
+	devMapperDir = "/dev/mapper"
+)
+
+// multipathUUIDPrefix is the prefix of the device-mapper UUIDs of the
+// devices created by dm-multipath.
+const multipathUUIDPrefix = "mpath-"
+
+// MultipathDevice returns the path of the dm-multipath device for which the
+// provided device is one of the paths. A flag is returned indicating whether
+// or not the device is a path of a dm-multipath device.
+func MultipathDevice(device string) (string, bool) {
+	if p, err := filepath.EvalSymlinks(device); err == nil {
+		device = p
+	}
+
+	holdersDir := filepath.Join(sysBlockDir, filepath.Base(device), "holders")
+	holders, err := ioutil.ReadDir(holdersDir)
+	if err != nil {
+		return "", false
+	}
+
+	for _, h := range holders {
+		dmDir := filepath.Join(sysBlockDir, h.Name(), "dm")
+		uuid, err := ioutil.ReadFile(filepath.Join(dmDir, "uuid"))
+		if err != nil {
+			continue
+		}
+		if !strings.HasPrefix(
+			strings.TrimSpace(string(uuid)), multipathUUIDPrefix) {
+			continue
+		}
+		name, err := ioutil.ReadFile(filepath.Join(dmDir, "name"))
+		if err != nil {
+			continue
+		}
+		return filepath.Join(devMapperDir, strings.TrimSpace(string(name))), true
+	}
+
+	return "", false
+}
+
+// WaitForMultipathDevice waits for the provided device to become one of the
+// paths of a dm-multipath device, as happens once multipathd has coalesced
+// the paths of a newly attached LUN. A flag is returned indicating whether
+// or not the device became a path of a dm-multipath device before the
+// timeout elapsed.
+func WaitForMultipathDevice(
+	ctx types.Context,
+	device string,
+	timeout time.Duration) (string, bool) {
+
+	var mpath string
+	ok, _ := WaitForDevice(ctx, timeout, func() (bool, error) {
+		var ok bool
+		mpath, ok = MultipathDevice(device)
+		return ok, nil
+	})
+	return mpath, ok
+}
+
+// ResolveMultipathDevices replaces the devices in the map that are paths of
+// dm-multipath devices with the dm-multipath devices.
+func ResolveMultipathDevices(ld *types.LocalDevices) {
+	if ld == nil {
+		return
+	}
+	for k, v := range ld.DeviceMap {
+		if mpath, ok := MultipathDevice(v); ok {
+			ld.DeviceMap[k] = mpath
+		}
+	}
+}
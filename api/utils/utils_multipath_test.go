@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+func newTestSysBlock(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "sysblock")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	mkfile := func(path, data string) {
+		path = filepath.Join(dir, path)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, ioutil.WriteFile(path, []byte(data), 0644))
+	}
+
+	// sdb and sdc are paths of the multipath device dm-0, and sdd is held
+	// by dm-1, which is not a multipath device
+	mkfile("sdb/holders/dm-0", "")
+	mkfile("sdc/holders/dm-0", "")
+	mkfile("sdd/holders/dm-1", "")
+	mkfile("sde/size", "")
+	mkfile("dm-0/dm/uuid", "mpath-3600a098038303053453f463045715a41\n")
+	mkfile("dm-0/dm/name", "mpatha\n")
+	mkfile("dm-1/dm/uuid", "CRYPT-LUKS1-abc\n")
+	mkfile("dm-1/dm/name", "crypt\n")
+
+	oldSysBlockDir := sysBlockDir
+	sysBlockDir = dir
+	return func() {
+		sysBlockDir = oldSysBlockDir
+		os.RemoveAll(dir)
+	}
+}
+
+func TestMultipathDevice(t *testing.T) {
+	defer newTestSysBlock(t)()
+
+	mpath, ok := MultipathDevice("/dev/sdb")
+	assert.True(t, ok)
+	assert.Equal(t, "/dev/mapper/mpatha", mpath)
+
+	_, ok = MultipathDevice("/dev/sdd")
+	assert.False(t, ok)
+
+	_, ok = MultipathDevice("/dev/sde")
+	assert.False(t, ok)
+
+	_, ok = WaitForMultipathDevice(
+		context.Background(), "/dev/sde", time.Millisecond)
+	assert.False(t, ok)
+}
+
+func TestResolveMultipathDevices(t *testing.T) {
+	defer newTestSysBlock(t)()
+
+	ld := &types.LocalDevices{
+		DeviceMap: map[string]string{
+			"vol-1": "/dev/sdc",
+			"vol-2": "/dev/sde",
+		},
+	}
+	ResolveMultipathDevices(ld)
+	assert.Equal(t, "/dev/mapper/mpatha", ld.DeviceMap["vol-1"])
+	assert.Equal(t, "/dev/sde", ld.DeviceMap["vol-2"])
+}
@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+const redacted = "******"
+
+var (
+	// secretNameRX matches the names of flags, headers, and fields whose
+	// values are secrets.
+	secretNameRX = regexp.MustCompile(
+		`(?i)(password|passwd|secret|token|apikey|api_key|accesskey|` +
+			`access_key|privatekey|private_key|credential|authorization)`)
+
+	// keyNameRX matches the names of flags and options whose values are
+	// keys, such as the "--key" flag of the rbd command, the "key" option
+	// of a Ceph mount, or the "rsaEncryptedKey" option of a GCE volume. The
+	// names of key files are not matched.
+	keyNameRX = regexp.MustCompile(`(?i)(^-*|[-_.]|encryption|encrypted)key$`)
+
+	// jsonStringMemberRX matches JSON members whose values are strings. The
+	// member's name is captured so that it may be checked with IsSecretName.
+	jsonStringMemberRX = regexp.MustCompile(
+		`("((?:[^"\\]|\\.)*)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+	// secretHeaderRX matches HTTP headers whose values are secrets.
+	secretHeaderRX = regexp.MustCompile(
+		`(?im)^((?:authorization|proxy-authorization|x-auth-token|` +
+			`cookie|set-cookie):[ \t]*)[^\r\n]*`)
+)
+
+// RedactArgs returns a copy of the command arguments with the values of
+// secrets replaced. A value is a secret if it follows a flag with a secret
+// name, such as "--password" or "--key", or if it is part of a name=value
+// argument with a secret name, such as "secret=abc". The options of a
+// comma-separated list, such as "name=admin,secret=abc", are redacted
+// individually.
+func RedactArgs(args []string) []string {
+	out := make([]string, len(args))
+	for i, a := range args {
+		if i > 0 && isSecretFlag(args[i-1]) {
+			out[i] = redacted
+			continue
+		}
+		if !strings.Contains(a, "=") {
+			out[i] = a
+			continue
+		}
+		opts := strings.Split(a, ",")
+		for j, o := range opts {
+			if kv := strings.SplitN(o, "=", 2); len(kv) == 2 &&
+				IsSecretName(kv[0]) {
+				opts[j] = kv[0] + "=" + redacted
+			}
+		}
+		out[i] = strings.Join(opts, ",")
+	}
+	return out
+}
+
+// IsSecretName returns a flag indicating whether or not the name of a flag,
+// option, or field indicates that its value is a secret, such as a password
+// or a key.
+func IsSecretName(name string) bool {
+	return secretNameRX.MatchString(name) || keyNameRX.MatchString(name)
+}
+
+func isSecretFlag(arg string) bool {
+	return strings.HasPrefix(arg, "-") &&
+		!strings.Contains(arg, "=") &&
+		IsSecretName(arg)
+}
+
+// RedactHTTP returns a copy of a dumped HTTP request or response with the
+// values of secret headers and JSON members replaced. A JSON member is a
+// secret if IsSecretName reports its name is.
+func RedactHTTP(buf []byte) []byte {
+	buf = secretHeaderRX.ReplaceAll(buf, []byte("${1}"+redacted))
+	return jsonStringMemberRX.ReplaceAllFunc(buf, func(m []byte) []byte {
+		sm := jsonStringMemberRX.FindSubmatch(m)
+		if !IsSecretName(string(sm[2])) {
+			return m
+		}
+		out := append([]byte{}, sm[1]...)
+		return append(out, `"`+redacted+`"`...)
+	})
+}
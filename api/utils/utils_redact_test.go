@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactArgs(t *testing.T) {
+	args := RedactArgs([]string{
+		"s3fs", "bucket", "/mnt",
+		"-o", "passwd_file=/etc/passwd-s3fs",
+		"--secret-key", "abc123",
+		"--pool", "rbd",
+	})
+	assert.EqualValues(t, []string{
+		"s3fs", "bucket", "/mnt",
+		"-o", "passwd_file=******",
+		"--secret-key", "******",
+		"--pool", "rbd",
+	}, args)
+}
+
+func TestRedactArgsKeys(t *testing.T) {
+	args := RedactArgs([]string{
+		"rbd", "map", "rbd/vol",
+		"--id", "admin",
+		"--key", "AQBz+1hYAAAAABAAHRMRQoq2dwrY5gqPvuA0QQ==",
+		"--keyring", "/etc/ceph/ceph.client.admin.keyring",
+		"-o", "name=admin,secret=AQBz+1hY==,noshare",
+		"encryption-key=abc123",
+		"monkey=business",
+	})
+	assert.EqualValues(t, []string{
+		"rbd", "map", "rbd/vol",
+		"--id", "admin",
+		"--key", "******",
+		"--keyring", "/etc/ceph/ceph.client.admin.keyring",
+		"-o", "name=admin,secret=******,noshare",
+		"encryption-key=******",
+		"monkey=business",
+	}, args)
+}
+
+func TestRedactHTTP(t *testing.T) {
+	buf := RedactHTTP([]byte("POST /volumes HTTP/1.1\r\n" +
+		"Authorization: Bearer abc123\r\n" +
+		"Content-Type: application/json\r\n\r\n" +
+		`{"name":"vol","opts":{"secretKey":"abc123","token": "x\"y"}}`))
+	assert.Equal(t, "POST /volumes HTTP/1.1\r\n"+
+		"Authorization: ******\r\n"+
+		"Content-Type: application/json\r\n\r\n"+
+		`{"name":"vol","opts":{"secretKey":"******","token": "******"}}`,
+		string(buf))
+}
+
+func TestRedactHTTPKeys(t *testing.T) {
+	buf := RedactHTTP([]byte(
+		`{"encryptionKey":"hunter2","opts":{"key":"AQBx==",` +
+			`"keyring":"/etc/ceph/keyring","monkey":"business"}}`))
+	assert.Equal(t,
+		`{"encryptionKey":"******","opts":{"key":"******",`+
+			`"keyring":"/etc/ceph/keyring","monkey":"business"}}`,
+		string(buf))
+}
+
+func TestIsSecretName(t *testing.T) {
+	for _, name := range []string{
+		"password", "secretKey", "encryptionKey", "rsaEncryptedKey",
+		"accessKey", "token", "--key",
+	} {
+		assert.True(t, IsSecretName(name), name)
+	}
+	for _, name := range []string{
+		"name", "size", "kmsKeyName", "encryptionKeySha256", "monkey",
+		"keyring",
+	} {
+		assert.False(t, IsSecretName(name), name)
+	}
+}
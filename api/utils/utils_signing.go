@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"strings"
+
+	"github.com/akutz/goof"
+	"golang.org/x/crypto/ed25519"
+)
+
+var oidEd25519 = asn1.ObjectIdentifier{1, 3, 101, 112}
+
+type pkcs8Key struct {
+	Version    int
+	Algo       pkix.AlgorithmIdentifier
+	PrivateKey []byte
+}
+
+type spkiKey struct {
+	Algo      pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// ParseSigningKey parses an Ed25519 private key. The key may be a PEM
+// encoded PKCS #8 key, such as one created with
+// "openssl genpkey -algorithm ed25519", or a base64 encoded 32 byte seed or
+// 64 byte private key.
+func ParseSigningKey(buf []byte) (ed25519.PrivateKey, error) {
+	if block, _ := pem.Decode(buf); block != nil {
+		var k pkcs8Key
+		if _, err := asn1.Unmarshal(block.Bytes, &k); err != nil {
+			return nil, goof.WithError("invalid signing key", err)
+		}
+		if !k.Algo.Algorithm.Equal(oidEd25519) {
+			return nil, goof.New("signing key is not an ed25519 key")
+		}
+		var seed []byte
+		if _, err := asn1.Unmarshal(k.PrivateKey, &seed); err != nil {
+			return nil, goof.WithError("invalid signing key", err)
+		}
+		return newSigningKey(seed)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(
+		strings.TrimSpace(string(buf)))
+	if err != nil {
+		return nil, goof.WithError("invalid signing key", err)
+	}
+	if len(key) == ed25519.PrivateKeySize {
+		return ed25519.PrivateKey(key), nil
+	}
+	return newSigningKey(key)
+}
+
+// newSigningKey returns the private key for the provided seed.
+func newSigningKey(seed []byte) (ed25519.PrivateKey, error) {
+	if len(seed) != 32 {
+		return nil, goof.WithField(
+			"size", len(seed), "invalid signing key size")
+	}
+	_, key, err := ed25519.GenerateKey(bytes.NewReader(seed))
+	if err != nil {
+		return nil, goof.WithError("invalid signing key", err)
+	}
+	return key, nil
+}
+
+// ParseVerifyKey parses an Ed25519 public key. The key may be a PEM encoded
+// public key, such as one created with "openssl pkey -pubout", or a base64
+// encoded 32 byte public key.
+func ParseVerifyKey(buf []byte) (ed25519.PublicKey, error) {
+	var key []byte
+	if block, _ := pem.Decode(buf); block != nil {
+		var k spkiKey
+		if _, err := asn1.Unmarshal(block.Bytes, &k); err != nil {
+			return nil, goof.WithError("invalid verify key", err)
+		}
+		if !k.Algo.Algorithm.Equal(oidEd25519) {
+			return nil, goof.New("verify key is not an ed25519 key")
+		}
+		key = k.PublicKey.RightAlign()
+	} else {
+		var err error
+		if key, err = base64.StdEncoding.DecodeString(
+			strings.TrimSpace(string(buf))); err != nil {
+			return nil, goof.WithError("invalid verify key", err)
+		}
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, goof.WithField(
+			"size", len(key), "invalid verify key size")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// Sign returns the base64 encoded signature of the data.
+func Sign(key ed25519.PrivateKey, data []byte) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(key, data))
+}
+
+// VerifySignature verifies the base64 encoded signature of the data.
+func VerifySignature(
+	key ed25519.PublicKey, data []byte, signature string) error {
+
+	if signature == "" {
+		return goof.New("missing signature")
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return goof.WithError("invalid signature", err)
+	}
+	if !ed25519.Verify(key, data, sig) {
+		return goof.New("invalid signature")
+	}
+	return nil
+}
@@ -20,6 +20,20 @@ func SortVolumeByID(volumes []*types.Volume) []*types.Volume {
 	return volumes
 }
 
+// BySnapshotID implements sort.Interface for []*types.Snapshot based on the
+// ID field.
+type BySnapshotID []*types.Snapshot
+
+func (a BySnapshotID) Len() int           { return len(a) }
+func (a BySnapshotID) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a BySnapshotID) Less(i, j int) bool { return a[i].ID < a[j].ID }
+
+// SortSnapshotByID sorts the snapshots by their IDs.
+func SortSnapshotByID(snapshots []*types.Snapshot) []*types.Snapshot {
+	sort.Sort(BySnapshotID(snapshots))
+	return snapshots
+}
+
 // ByString  implements sort.Interface for []string.
 type ByString []string
 
@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"net/url"
+	"strings"
+
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+var oidSubjectAltName = asn1.ObjectIdentifier{2, 5, 29, 17}
+
+const (
+	asn1ClassContextSpecific = 2
+	asn1TagSequence          = 16
+	sanTagURI                = 6
+	spiffeScheme             = "spiffe"
+)
+
+// SPIFFEID returns the SPIFFE ID from the URI subject alternative names of
+// the provided certificate. An empty string is returned if the certificate
+// does not have a SPIFFE ID.
+func SPIFFEID(cert *x509.Certificate) (string, error) {
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(oidSubjectAltName) {
+			continue
+		}
+
+		var seq asn1.RawValue
+		if _, err := asn1.Unmarshal(ext.Value, &seq); err != nil {
+			return "", err
+		}
+		if !seq.IsCompound || seq.Tag != asn1TagSequence {
+			return "", goof.New("invalid subject alternative name")
+		}
+
+		for rest := seq.Bytes; len(rest) > 0; {
+			var v asn1.RawValue
+			var err error
+			if rest, err = asn1.Unmarshal(rest, &v); err != nil {
+				return "", err
+			}
+			if v.Class != asn1ClassContextSpecific || v.Tag != sanTagURI {
+				continue
+			}
+			if u, err := url.Parse(string(v.Bytes)); err == nil &&
+				strings.EqualFold(u.Scheme, spiffeScheme) {
+				return u.String(), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// VerifySPIFFEID verifies that the first of the provided peer certificates
+// has a SPIFFE ID that is allowed by the TLS config. The peer's SPIFFE ID is
+// returned, and may be empty if the TLS config does not require one.
+func VerifySPIFFEID(
+	tlsConfig *types.TLSConfig,
+	peerCerts []*x509.Certificate) (string, error) {
+
+	var id string
+	if len(peerCerts) > 0 {
+		var err error
+		if id, err = SPIFFEID(peerCerts[0]); err != nil {
+			return "", err
+		}
+	}
+
+	if !tlsConfig.RequiresSPIFFEID() {
+		return id, nil
+	}
+
+	if id == "" {
+		return "", goof.New("peer missing spiffe id")
+	}
+
+	for _, allowedID := range tlsConfig.SPIFFEIDs {
+		if id == allowedID {
+			return id, nil
+		}
+	}
+
+	if td := tlsConfig.SPIFFETrustDomain; td != "" {
+		if u, err := url.Parse(id); err == nil && strings.EqualFold(u.Host, td) {
+			return id, nil
+		}
+	}
+
+	return "", goof.WithField("spiffeID", id, "peer spiffe id not allowed")
+}
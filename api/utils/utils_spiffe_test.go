@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+func newTestCert(t *testing.T, uris ...string) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "libstorage"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	if len(uris) > 0 {
+		names := []asn1.RawValue{}
+		for _, u := range uris {
+			names = append(names, asn1.RawValue{
+				Class: asn1ClassContextSpecific,
+				Tag:   sanTagURI,
+				Bytes: []byte(u),
+			})
+		}
+		san, err := asn1.Marshal(names)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		tmpl.ExtraExtensions = []pkix.Extension{
+			{Id: oidSubjectAltName, Value: san},
+		}
+	}
+
+	der, err := x509.CreateCertificate(
+		rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	cert, err := x509.ParseCertificate(der)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return cert
+}
+
+func TestSPIFFEID(t *testing.T) {
+	id, err := SPIFFEID(newTestCert(t))
+	assert.NoError(t, err)
+	assert.Empty(t, id)
+
+	id, err = SPIFFEID(newTestCert(
+		t, "https://example.org", "spiffe://example.org/lsx"))
+	assert.NoError(t, err)
+	assert.Equal(t, "spiffe://example.org/lsx", id)
+}
+
+func TestVerifySPIFFEID(t *testing.T) {
+	certs := []*x509.Certificate{
+		newTestCert(t, "spiffe://example.org/lsx"),
+	}
+
+	id, err := VerifySPIFFEID(&types.TLSConfig{}, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, id)
+
+	id, err = VerifySPIFFEID(&types.TLSConfig{
+		SPIFFEIDs: []string{"spiffe://example.org/lsx"},
+	}, certs)
+	assert.NoError(t, err)
+	assert.Equal(t, "spiffe://example.org/lsx", id)
+
+	id, err = VerifySPIFFEID(&types.TLSConfig{
+		SPIFFETrustDomain: "example.org",
+	}, certs)
+	assert.NoError(t, err)
+	assert.Equal(t, "spiffe://example.org/lsx", id)
+
+	_, err = VerifySPIFFEID(&types.TLSConfig{
+		SPIFFETrustDomain: "example.com",
+	}, certs)
+	assert.Error(t, err)
+
+	_, err = VerifySPIFFEID(&types.TLSConfig{
+		SPIFFEIDs: []string{"spiffe://example.org/lsx"},
+	}, []*x509.Certificate{newTestCert(t)})
+	assert.Error(t, err)
+}
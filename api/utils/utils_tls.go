@@ -8,6 +8,7 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
 	gofig "github.com/akutz/gofig/types"
@@ -105,6 +106,19 @@ func ParseTLSConfig(
 			return nil, err
 		}
 		tlsConfig.Certificates = []tls.Certificate{cer}
+		tlsConfig.CertFile = certFile
+		tlsConfig.KeyFile = keyFile
+
+		if v := getString(
+			config, types.ConfigTLSReloadInterval, roots...); v != "" {
+			dur, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, goof.WithFieldE(
+					"reloadInterval", v, "invalid tls reload interval", err)
+			}
+			tlsConfig.ReloadInterval = dur
+			f(types.ConfigTLSReloadInterval, dur)
+		}
 	}
 
 	if isSet(config, types.ConfigTLSServerName, roots...) {
@@ -155,5 +169,65 @@ func ParseTLSConfig(
 		tlsConfig.ClientCAs = certPool
 	}
 
+	if isSet(config, types.ConfigTLSSPIFFETrustDomain, roots...) {
+		trustDomain := getString(
+			config, types.ConfigTLSSPIFFETrustDomain, roots...)
+		tlsConfig.SPIFFETrustDomain = trustDomain
+		f(types.ConfigTLSSPIFFETrustDomain, trustDomain)
+	}
+
+	if isSet(config, types.ConfigTLSSPIFFEIDs, roots...) {
+		ids := getStringSlice(config, types.ConfigTLSSPIFFEIDs, roots...)
+		tlsConfig.SPIFFEIDs = ids
+		f(types.ConfigTLSSPIFFEIDs, ids)
+	}
+
+	if isSet(config, types.ConfigTLSSPIFFEWorkloadAPI, roots...) {
+		addr := getString(config, types.ConfigTLSSPIFFEWorkloadAPI, roots...)
+		tlsConfig.SPIFFEWorkloadAPI = addr
+		f(types.ConfigTLSSPIFFEWorkloadAPI, addr)
+	}
+
+	// a peer can only present a SPIFFE ID with a certificate
+	if tlsConfig.RequiresSPIFFEID() {
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	// the certificates of peers are verified with the Workload API's trust
+	// bundle after the handshake since the bundle may be rotated
+	if tlsConfig.SPIFFEWorkloadAPI != "" {
+		if tlsConfig.ClientAuth == tls.RequireAndVerifyClientCert {
+			tlsConfig.ClientAuth = tls.RequireAnyClientCert
+		} else {
+			tlsConfig.ClientAuth = tls.RequestClientCert
+		}
+	}
+
 	return tlsConfig, nil
 }
+
+// CloneTLSConfig returns a copy of the provided TLS config. A tls.Config may
+// not be modified once it is used, so a copy is modified instead.
+func CloneTLSConfig(c *tls.Config) *tls.Config {
+	return &tls.Config{
+		Rand:                     c.Rand,
+		Time:                     c.Time,
+		Certificates:             c.Certificates,
+		NameToCertificate:        c.NameToCertificate,
+		GetCertificate:           c.GetCertificate,
+		RootCAs:                  c.RootCAs,
+		NextProtos:               c.NextProtos,
+		ServerName:               c.ServerName,
+		ClientAuth:               c.ClientAuth,
+		ClientCAs:                c.ClientCAs,
+		InsecureSkipVerify:       c.InsecureSkipVerify,
+		CipherSuites:             c.CipherSuites,
+		PreferServerCipherSuites: c.PreferServerCipherSuites,
+		SessionTicketsDisabled:   c.SessionTicketsDisabled,
+		SessionTicketKey:         c.SessionTicketKey,
+		ClientSessionCache:       c.ClientSessionCache,
+		MinVersion:               c.MinVersion,
+		MaxVersion:               c.MaxVersion,
+		CurvePreferences:         c.CurvePreferences,
+	}
+}
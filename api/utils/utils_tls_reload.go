@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// TLSKeyPair is a certificate and key loaded from files that may be reloaded
+// when the files change.
+type TLSKeyPair struct {
+	sync.RWMutex
+	certFile string
+	keyFile  string
+	cert     *tls.Certificate
+	modTimes [2]time.Time
+}
+
+// NewTLSKeyPair loads a certificate and key from the provided files.
+func NewTLSKeyPair(certFile, keyFile string) (*TLSKeyPair, error) {
+	kp := &TLSKeyPair{certFile: certFile, keyFile: keyFile}
+	if _, err := kp.Reload(); err != nil {
+		return nil, err
+	}
+	return kp, nil
+}
+
+// Certificate returns the most recently loaded certificate.
+func (kp *TLSKeyPair) Certificate() *tls.Certificate {
+	kp.RLock()
+	defer kp.RUnlock()
+	return kp.cert
+}
+
+// GetCertificate returns the most recently loaded certificate. This function
+// may be used as the GetCertificate function of a tls.Config.
+func (kp *TLSKeyPair) GetCertificate(
+	*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return kp.Certificate(), nil
+}
+
+// Reload loads the certificate and key if either file was modified since
+// they were last loaded. A flag is returned indicating whether or not the
+// certificate was reloaded. The previous certificate is retained if the
+// files cannot be loaded, such as when only one of them has been replaced.
+func (kp *TLSKeyPair) Reload() (bool, error) {
+
+	var modTimes [2]time.Time
+	for i, f := range []string{kp.certFile, kp.keyFile} {
+		fi, err := os.Stat(f)
+		if err != nil {
+			return false, err
+		}
+		modTimes[i] = fi.ModTime()
+	}
+
+	kp.RLock()
+	unchanged := kp.cert != nil && modTimes == kp.modTimes
+	kp.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(kp.certFile, kp.keyFile)
+	if err != nil {
+		return false, err
+	}
+
+	kp.Lock()
+	defer kp.Unlock()
+	kp.cert = &cert
+	kp.modTimes = modTimes
+	return true, nil
+}
+
+// Watch reloads the certificate and key at the specified interval until the
+// stop channel is closed. The onReload function, if not nil, is invoked each
+// time the certificate is reloaded.
+func (kp *TLSKeyPair) Watch(
+	ctx types.Context,
+	interval time.Duration,
+	stop <-chan struct{},
+	onReload func(*tls.Certificate)) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			ok, err := kp.Reload()
+			if err != nil {
+				ctx.WithError(err).Warn("error reloading tls certificate")
+				continue
+			}
+			if !ok {
+				continue
+			}
+			ctx.WithFields(log.Fields{
+				"certFile": kp.certFile,
+				"keyFile":  kp.keyFile,
+			}).Info("reloaded tls certificate")
+			if onReload != nil {
+				onReload(kp.Certificate())
+			}
+		}
+	}
+}
@@ -2,7 +2,17 @@
 
 package utils
 
-import "os/exec"
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/akutz/goof"
+)
 
 const (
 	newline = 10
@@ -19,3 +29,82 @@ func HostName() (string, error) {
 	}
 	return string(buf), nil
 }
+
+// ListenUnix listens on the UNIX socket with the provided mode. The socket is
+// created in a private directory beside the socket's path and is linked to
+// the path only once its mode is set, so that it cannot be connected to
+// before then. The process's umask is not changed, and an existing file at
+// the path is not replaced.
+func ListenUnix(sock string, mode os.FileMode) (net.Listener, error) {
+	dir, err := ioutil.TempDir(filepath.Dir(sock), ".sock")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	tmp := filepath.Join(dir, "s")
+	l, err := net.Listen("unix", tmp)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(tmp, mode); err != nil {
+		l.Close()
+		return nil, err
+	}
+	if err := os.Link(tmp, sock); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return &unixListener{Listener: l, sock: sock}, nil
+}
+
+// unixListener is a UNIX socket listener that reports and removes the path
+// to which its socket was linked rather than the one at which it was
+// created.
+type unixListener struct {
+	net.Listener
+	sock string
+}
+
+func (l *unixListener) Addr() net.Addr {
+	return &net.UnixAddr{Name: l.sock, Net: "unix"}
+}
+
+func (l *unixListener) Close() error {
+	err := l.Listener.Close()
+	os.Remove(l.sock)
+	return err
+}
+
+// RemoveStaleSocket removes the UNIX socket at the path if it was left
+// behind by a process that is no longer listening on it. A socket is stale
+// only if connecting to it is refused, so an error is returned if a process
+// is still listening on the socket or if connecting to it fails for another
+// reason. Nothing is removed if the path does not exist or is not a socket.
+func RemoveStaleSocket(sock string) error {
+	fi, err := os.Stat(sock)
+	if err != nil || fi.Mode()&os.ModeSocket == 0 {
+		return nil
+	}
+	conn, err := net.DialTimeout("unix", sock, time.Second)
+	if err == nil {
+		conn.Close()
+		return goof.WithField("path", sock, "socket in use")
+	}
+	if !isConnRefused(err) {
+		return goof.WithFieldE("path", sock, "error checking socket", err)
+	}
+	return os.Remove(sock)
+}
+
+// isConnRefused returns a flag indicating whether or not the error occurred
+// because a connection was refused.
+func isConnRefused(err error) bool {
+	if oe, ok := err.(*net.OpError); ok {
+		err = oe.Err
+	}
+	if se, ok := err.(*os.SyscallError); ok {
+		err = se.Err
+	}
+	return err == syscall.ECONNREFUSED
+}
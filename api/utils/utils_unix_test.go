@@ -0,0 +1,86 @@
+// +build !windows
+
+package utils
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListenUnix(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lssock")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	sock := filepath.Join(dir, "libstorage.sock")
+	l, err := ListenUnix(sock, 0600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, sock, l.Addr().String())
+
+	fi, err := os.Stat(sock)
+	if assert.NoError(t, err) {
+		assert.True(t, fi.Mode()&os.ModeSocket != 0)
+		assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+	}
+
+	c, err := net.Dial("unix", sock)
+	if assert.NoError(t, err) {
+		c.Close()
+	}
+
+	// the private directory in which the socket was created is removed
+	files, _ := ioutil.ReadDir(dir)
+	assert.Len(t, files, 1)
+
+	// an existing file is not replaced
+	_, err = ListenUnix(sock, 0600)
+	assert.Error(t, err)
+
+	assert.NoError(t, l.Close())
+	_, err = os.Stat(sock)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRemoveStaleSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lssock")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	// nothing is removed if the path does not exist or is not a socket
+	sock := filepath.Join(dir, "libstorage.sock")
+	assert.NoError(t, RemoveStaleSocket(sock))
+	if !assert.NoError(t, ioutil.WriteFile(sock, []byte("data"), 0644)) {
+		t.FailNow()
+	}
+	assert.NoError(t, RemoveStaleSocket(sock))
+	_, err = os.Stat(sock)
+	assert.NoError(t, err)
+	os.Remove(sock)
+
+	// a socket that is being listened on is not removed
+	l, err := net.ListenUnix("unix", &net.UnixAddr{Name: sock, Net: "unix"})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	l.SetUnlinkOnClose(false)
+	assert.Error(t, RemoveStaleSocket(sock))
+	_, err = os.Stat(sock)
+	assert.NoError(t, err)
+
+	// but one that is no longer listened on is
+	l.Close()
+	assert.NoError(t, RemoveStaleSocket(sock))
+	_, err = os.Stat(sock)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,265 @@
+// Package v2 provides the driver-facing types of version 2 of the Go API.
+// Unlike version 1, the functions take a context.Context as their first
+// argument, plain values in place of pointers, and option structs whose zero
+// values are the defaults. Adapters convert existing storage drivers to and
+// from the version 2 interface.
+package v2
+
+import (
+	"golang.org/x/net/context"
+
+	apictx "github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// Options are driver-specific options.
+type Options map[string]interface{}
+
+// VolumesOptions are options for listing volumes.
+type VolumesOptions struct {
+	Attachments types.VolumeAttachmentsTypes
+	Opts        Options
+}
+
+// VolumeInspectOptions are options for inspecting a volume.
+type VolumeInspectOptions struct {
+	Attachments types.VolumeAttachmentsTypes
+	Opts        Options
+}
+
+// VolumeCreateOptions are options for creating a volume. Fields with zero
+// values are omitted so that the driver's defaults are used.
+type VolumeCreateOptions struct {
+	AvailabilityZone string
+	IOPS             int64
+	Size             int64
+	Type             string
+	Encrypted        bool
+	EncryptionKey    string
+	Opts             Options
+}
+
+// VolumeAttachOptions are options for attaching a volume.
+type VolumeAttachOptions struct {
+	NextDevice string
+	Force      bool
+	AccessMode types.AttachAccessMode
+	Opts       Options
+}
+
+// VolumeDetachOptions are options for detaching a volume.
+type VolumeDetachOptions struct {
+	Force bool
+	Opts  Options
+}
+
+// VolumeRemoveOptions are options for removing a volume.
+type VolumeRemoveOptions struct {
+	Force bool
+	Opts  Options
+}
+
+// VolumeSnapshotOptions are options for snapshotting a volume.
+type VolumeSnapshotOptions struct {
+	Quiesce bool
+	Opts    Options
+}
+
+// VolumeMountOptions are options for mounting a volume.
+type VolumeMountOptions struct {
+	FSType       string
+	FSOpts       []string
+	OverwriteFS  bool
+	Preempt      bool
+	MountOptions string
+	MountLabel   string
+	Encrypted    bool
+	ReadOnly     bool
+	Opts         Options
+}
+
+// Context returns the libStorage context for a context. The context is
+// returned as is if it is already a libStorage context.
+func Context(ctx context.Context) types.Context {
+	if ctx == nil {
+		return apictx.Background()
+	}
+	if c, ok := ctx.(types.Context); ok {
+		return c
+	}
+	return apictx.New(ctx)
+}
+
+// Store returns a store with the options' data.
+func (o Options) Store() types.Store {
+	if o == nil {
+		return utils.NewStore()
+	}
+	return utils.NewStoreWithData(o)
+}
+
+// StoreOptions returns the data of a store as options.
+func StoreOptions(s types.Store) Options {
+	if s == nil {
+		return nil
+	}
+	return Options(s.Map())
+}
+
+// V1 returns the version 1 representation of the options.
+func (o *VolumesOptions) V1() *types.VolumesOpts {
+	return &types.VolumesOpts{
+		Attachments: o.Attachments,
+		Opts:        o.Opts.Store(),
+	}
+}
+
+// V1 returns the version 1 representation of the options.
+func (o *VolumeInspectOptions) V1() *types.VolumeInspectOpts {
+	return &types.VolumeInspectOpts{
+		Attachments: o.Attachments,
+		Opts:        o.Opts.Store(),
+	}
+}
+
+// V1 returns the version 1 representation of the options.
+func (o *VolumeCreateOptions) V1() *types.VolumeCreateOpts {
+	return &types.VolumeCreateOpts{
+		AvailabilityZone: stringPtr(o.AvailabilityZone),
+		IOPS:             int64Ptr(o.IOPS),
+		Size:             int64Ptr(o.Size),
+		Type:             stringPtr(o.Type),
+		Encrypted:        boolPtr(o.Encrypted),
+		EncryptionKey:    stringPtr(o.EncryptionKey),
+		Opts:             o.Opts.Store(),
+	}
+}
+
+// V1Request returns the version 1 API request for the options.
+func (o *VolumeCreateOptions) V1Request(
+	name string) *types.VolumeCreateRequest {
+
+	return &types.VolumeCreateRequest{
+		Name:             name,
+		AvailabilityZone: stringPtr(o.AvailabilityZone),
+		IOPS:             int64Ptr(o.IOPS),
+		Size:             int64Ptr(o.Size),
+		Type:             stringPtr(o.Type),
+		Encrypted:        boolPtr(o.Encrypted),
+		EncryptionKey:    stringPtr(o.EncryptionKey),
+		Opts:             o.Opts,
+	}
+}
+
+// V1 returns the version 1 representation of the options.
+func (o *VolumeAttachOptions) V1() *types.VolumeAttachOpts {
+	return &types.VolumeAttachOpts{
+		NextDevice: stringPtr(o.NextDevice),
+		Force:      o.Force,
+		AccessMode: o.AccessMode,
+		Opts:       o.Opts.Store(),
+	}
+}
+
+// V1Request returns the version 1 API request for the options.
+func (o *VolumeAttachOptions) V1Request() *types.VolumeAttachRequest {
+	return &types.VolumeAttachRequest{
+		NextDeviceName: stringPtr(o.NextDevice),
+		Force:          o.Force,
+		AccessMode:     o.AccessMode,
+		Opts:           o.Opts,
+	}
+}
+
+// V1 returns the version 1 representation of the options.
+func (o *VolumeDetachOptions) V1() *types.VolumeDetachOpts {
+	return &types.VolumeDetachOpts{
+		Force: o.Force,
+		Opts:  o.Opts.Store(),
+	}
+}
+
+// V1Request returns the version 1 API request for the options.
+func (o *VolumeDetachOptions) V1Request() *types.VolumeDetachRequest {
+	return &types.VolumeDetachRequest{
+		Force: o.Force,
+		Opts:  o.Opts,
+	}
+}
+
+// V1 returns the version 1 representation of the options.
+func (o *VolumeRemoveOptions) V1() *types.VolumeRemoveOpts {
+	return &types.VolumeRemoveOpts{
+		Force: o.Force,
+		Opts:  o.Opts.Store(),
+	}
+}
+
+// V1Request returns the version 1 API request for the options.
+func (o *VolumeSnapshotOptions) V1Request(
+	snapshotName string) *types.VolumeSnapshotRequest {
+
+	return &types.VolumeSnapshotRequest{
+		SnapshotName: snapshotName,
+		Quiesce:      o.Quiesce,
+		Opts:         o.Opts,
+	}
+}
+
+// V1 returns the version 1 representation of the options.
+func (o *VolumeMountOptions) V1() *types.VolumeMountOpts {
+	return &types.VolumeMountOpts{
+		NewFSType:    o.FSType,
+		NewFSOpts:    o.FSOpts,
+		OverwriteFS:  o.OverwriteFS,
+		Preempt:      o.Preempt,
+		MountOptions: o.MountOptions,
+		MountLabel:   o.MountLabel,
+		Encrypted:    o.Encrypted,
+		ReadOnly:     o.ReadOnly,
+		Opts:         o.Opts.Store(),
+	}
+}
+
+func stringPtr(v string) *string {
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+func int64Ptr(v int64) *int64 {
+	if v == 0 {
+		return nil
+	}
+	return &v
+}
+
+func boolPtr(v bool) *bool {
+	if !v {
+		return nil
+	}
+	return &v
+}
+
+func stringVal(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
+
+func int64Val(p *int64) int64 {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
+func boolVal(p *bool) bool {
+	if p == nil {
+		return false
+	}
+	return *p
+}
@@ -0,0 +1,432 @@
+package v2
+
+import (
+	gofig "github.com/akutz/gofig/types"
+	"golang.org/x/net/context"
+
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// NewStorageDriver is a function that constructs a new StorageDriver.
+type NewStorageDriver func() StorageDriver
+
+// StorageDriver is the version 2 interface of a libStorage storage driver.
+// The contexts passed to the driver are libStorage contexts, so the driver
+// may use the Context function to access the logger and the values the
+// server injects, such as the storage service.
+type StorageDriver interface {
+
+	// Name returns the name of the driver.
+	Name() string
+
+	// Init initializes the driver.
+	Init(ctx context.Context, config gofig.Config) error
+
+	// NextDeviceInfo returns the information about the driver's next
+	// available device workflow.
+	NextDeviceInfo(ctx context.Context) (*types.NextDeviceInfo, error)
+
+	// Type returns the type of storage the driver provides.
+	Type(ctx context.Context) (types.StorageType, error)
+
+	// InstanceInspect returns an instance.
+	InstanceInspect(
+		ctx context.Context, opts Options) (*types.Instance, error)
+
+	// Volumes returns all volumes or a filtered list of volumes.
+	Volumes(
+		ctx context.Context, opts VolumesOptions) ([]*types.Volume, error)
+
+	// VolumeInspect inspects a single volume.
+	VolumeInspect(
+		ctx context.Context,
+		volumeID string,
+		opts VolumeInspectOptions) (*types.Volume, error)
+
+	// VolumeCreate creates a new volume.
+	VolumeCreate(
+		ctx context.Context,
+		name string,
+		opts VolumeCreateOptions) (*types.Volume, error)
+
+	// VolumeCreateFromSnapshot creates a new volume from an existing
+	// snapshot.
+	VolumeCreateFromSnapshot(
+		ctx context.Context,
+		snapshotID, volumeName string,
+		opts VolumeCreateOptions) (*types.Volume, error)
+
+	// VolumeCopy copies an existing volume.
+	VolumeCopy(
+		ctx context.Context,
+		volumeID, volumeName string,
+		opts Options) (*types.Volume, error)
+
+	// VolumeSnapshot snapshots a volume.
+	VolumeSnapshot(
+		ctx context.Context,
+		volumeID, snapshotName string,
+		opts Options) (*types.Snapshot, error)
+
+	// VolumeRemove removes a volume.
+	VolumeRemove(
+		ctx context.Context,
+		volumeID string,
+		opts VolumeRemoveOptions) error
+
+	// VolumeAttach attaches a volume and provides a token clients can use
+	// to validate that device has appeared locally.
+	VolumeAttach(
+		ctx context.Context,
+		volumeID string,
+		opts VolumeAttachOptions) (*types.Volume, string, error)
+
+	// VolumeDetach detaches a volume.
+	VolumeDetach(
+		ctx context.Context,
+		volumeID string,
+		opts VolumeDetachOptions) (*types.Volume, error)
+
+	// Snapshots returns all snapshots.
+	Snapshots(
+		ctx context.Context, opts Options) ([]*types.Snapshot, error)
+
+	// SnapshotInspect inspects a single snapshot.
+	SnapshotInspect(
+		ctx context.Context,
+		snapshotID string,
+		opts Options) (*types.Snapshot, error)
+
+	// SnapshotCopy copies an existing snapshot.
+	SnapshotCopy(
+		ctx context.Context,
+		snapshotID, snapshotName, destinationID string,
+		opts Options) (*types.Snapshot, error)
+
+	// SnapshotRemove removes a snapshot.
+	SnapshotRemove(
+		ctx context.Context,
+		snapshotID string,
+		opts Options) error
+}
+
+// RegisterStorageDriver registers a version 2 storage driver with the
+// libStorage registry. The driver is adapted to the version 1 interface.
+func RegisterStorageDriver(name string, ctor NewStorageDriver) {
+	registry.RegisterStorageDriver(name, func() types.StorageDriver {
+		return ToV1(ctor())
+	})
+}
+
+// FromV1 adapts a version 1 storage driver to the version 2 interface.
+func FromV1(d types.StorageDriver) StorageDriver {
+	if a, ok := d.(*v1Adapter); ok {
+		return a.d
+	}
+	return &v2Adapter{d}
+}
+
+// ToV1 adapts a version 2 storage driver to the version 1 interface. The
+// optional version 1 interfaces, such as ProvidesStorageCapabilities, are not
+// provided by the adapted driver.
+func ToV1(d StorageDriver) types.StorageDriver {
+	if a, ok := d.(*v2Adapter); ok {
+		return a.d
+	}
+	return &v1Adapter{d}
+}
+
+// v2Adapter adapts a version 1 driver to the version 2 interface.
+type v2Adapter struct {
+	d types.StorageDriver
+}
+
+func (a *v2Adapter) Name() string {
+	return a.d.Name()
+}
+
+func (a *v2Adapter) Init(ctx context.Context, config gofig.Config) error {
+	return a.d.Init(Context(ctx), config)
+}
+
+func (a *v2Adapter) NextDeviceInfo(
+	ctx context.Context) (*types.NextDeviceInfo, error) {
+
+	return a.d.NextDeviceInfo(Context(ctx))
+}
+
+func (a *v2Adapter) Type(ctx context.Context) (types.StorageType, error) {
+	return a.d.Type(Context(ctx))
+}
+
+func (a *v2Adapter) InstanceInspect(
+	ctx context.Context, opts Options) (*types.Instance, error) {
+
+	return a.d.InstanceInspect(Context(ctx), opts.Store())
+}
+
+func (a *v2Adapter) Volumes(
+	ctx context.Context, opts VolumesOptions) ([]*types.Volume, error) {
+
+	return a.d.Volumes(Context(ctx), opts.V1())
+}
+
+func (a *v2Adapter) VolumeInspect(
+	ctx context.Context,
+	volumeID string,
+	opts VolumeInspectOptions) (*types.Volume, error) {
+
+	return a.d.VolumeInspect(Context(ctx), volumeID, opts.V1())
+}
+
+func (a *v2Adapter) VolumeCreate(
+	ctx context.Context,
+	name string,
+	opts VolumeCreateOptions) (*types.Volume, error) {
+
+	return a.d.VolumeCreate(Context(ctx), name, opts.V1())
+}
+
+func (a *v2Adapter) VolumeCreateFromSnapshot(
+	ctx context.Context,
+	snapshotID, volumeName string,
+	opts VolumeCreateOptions) (*types.Volume, error) {
+
+	return a.d.VolumeCreateFromSnapshot(
+		Context(ctx), snapshotID, volumeName, opts.V1())
+}
+
+func (a *v2Adapter) VolumeCopy(
+	ctx context.Context,
+	volumeID, volumeName string,
+	opts Options) (*types.Volume, error) {
+
+	return a.d.VolumeCopy(Context(ctx), volumeID, volumeName, opts.Store())
+}
+
+func (a *v2Adapter) VolumeSnapshot(
+	ctx context.Context,
+	volumeID, snapshotName string,
+	opts Options) (*types.Snapshot, error) {
+
+	return a.d.VolumeSnapshot(
+		Context(ctx), volumeID, snapshotName, opts.Store())
+}
+
+func (a *v2Adapter) VolumeRemove(
+	ctx context.Context,
+	volumeID string,
+	opts VolumeRemoveOptions) error {
+
+	return a.d.VolumeRemove(Context(ctx), volumeID, opts.V1())
+}
+
+func (a *v2Adapter) VolumeAttach(
+	ctx context.Context,
+	volumeID string,
+	opts VolumeAttachOptions) (*types.Volume, string, error) {
+
+	return a.d.VolumeAttach(Context(ctx), volumeID, opts.V1())
+}
+
+func (a *v2Adapter) VolumeDetach(
+	ctx context.Context,
+	volumeID string,
+	opts VolumeDetachOptions) (*types.Volume, error) {
+
+	return a.d.VolumeDetach(Context(ctx), volumeID, opts.V1())
+}
+
+func (a *v2Adapter) Snapshots(
+	ctx context.Context, opts Options) ([]*types.Snapshot, error) {
+
+	return a.d.Snapshots(Context(ctx), opts.Store())
+}
+
+func (a *v2Adapter) SnapshotInspect(
+	ctx context.Context,
+	snapshotID string,
+	opts Options) (*types.Snapshot, error) {
+
+	return a.d.SnapshotInspect(Context(ctx), snapshotID, opts.Store())
+}
+
+func (a *v2Adapter) SnapshotCopy(
+	ctx context.Context,
+	snapshotID, snapshotName, destinationID string,
+	opts Options) (*types.Snapshot, error) {
+
+	return a.d.SnapshotCopy(
+		Context(ctx), snapshotID, snapshotName, destinationID, opts.Store())
+}
+
+func (a *v2Adapter) SnapshotRemove(
+	ctx context.Context,
+	snapshotID string,
+	opts Options) error {
+
+	return a.d.SnapshotRemove(Context(ctx), snapshotID, opts.Store())
+}
+
+// v1Adapter adapts a version 2 driver to the version 1 interface.
+type v1Adapter struct {
+	d StorageDriver
+}
+
+func (a *v1Adapter) Name() string {
+	return a.d.Name()
+}
+
+func (a *v1Adapter) Init(ctx types.Context, config gofig.Config) error {
+	return a.d.Init(ctx, config)
+}
+
+func (a *v1Adapter) NextDeviceInfo(
+	ctx types.Context) (*types.NextDeviceInfo, error) {
+
+	return a.d.NextDeviceInfo(ctx)
+}
+
+func (a *v1Adapter) Type(ctx types.Context) (types.StorageType, error) {
+	return a.d.Type(ctx)
+}
+
+func (a *v1Adapter) InstanceInspect(
+	ctx types.Context, opts types.Store) (*types.Instance, error) {
+
+	return a.d.InstanceInspect(ctx, StoreOptions(opts))
+}
+
+func (a *v1Adapter) Volumes(
+	ctx types.Context,
+	opts *types.VolumesOpts) ([]*types.Volume, error) {
+
+	return a.d.Volumes(ctx, VolumesOptions{
+		Attachments: opts.Attachments,
+		Opts:        StoreOptions(opts.Opts),
+	})
+}
+
+func (a *v1Adapter) VolumeInspect(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeInspectOpts) (*types.Volume, error) {
+
+	return a.d.VolumeInspect(ctx, volumeID, VolumeInspectOptions{
+		Attachments: opts.Attachments,
+		Opts:        StoreOptions(opts.Opts),
+	})
+}
+
+func (a *v1Adapter) VolumeCreate(
+	ctx types.Context,
+	name string,
+	opts *types.VolumeCreateOpts) (*types.Volume, error) {
+
+	return a.d.VolumeCreate(ctx, name, createOptions(opts))
+}
+
+func (a *v1Adapter) VolumeCreateFromSnapshot(
+	ctx types.Context,
+	snapshotID, volumeName string,
+	opts *types.VolumeCreateOpts) (*types.Volume, error) {
+
+	return a.d.VolumeCreateFromSnapshot(
+		ctx, snapshotID, volumeName, createOptions(opts))
+}
+
+func (a *v1Adapter) VolumeCopy(
+	ctx types.Context,
+	volumeID, volumeName string,
+	opts types.Store) (*types.Volume, error) {
+
+	return a.d.VolumeCopy(ctx, volumeID, volumeName, StoreOptions(opts))
+}
+
+func (a *v1Adapter) VolumeSnapshot(
+	ctx types.Context,
+	volumeID, snapshotName string,
+	opts types.Store) (*types.Snapshot, error) {
+
+	return a.d.VolumeSnapshot(
+		ctx, volumeID, snapshotName, StoreOptions(opts))
+}
+
+func (a *v1Adapter) VolumeRemove(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeRemoveOpts) error {
+
+	return a.d.VolumeRemove(ctx, volumeID, VolumeRemoveOptions{
+		Force: opts.Force,
+		Opts:  StoreOptions(opts.Opts),
+	})
+}
+
+func (a *v1Adapter) VolumeAttach(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeAttachOpts) (*types.Volume, string, error) {
+
+	return a.d.VolumeAttach(ctx, volumeID, VolumeAttachOptions{
+		NextDevice: stringVal(opts.NextDevice),
+		Force:      opts.Force,
+		AccessMode: opts.AccessMode,
+		Opts:       StoreOptions(opts.Opts),
+	})
+}
+
+func (a *v1Adapter) VolumeDetach(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeDetachOpts) (*types.Volume, error) {
+
+	return a.d.VolumeDetach(ctx, volumeID, VolumeDetachOptions{
+		Force: opts.Force,
+		Opts:  StoreOptions(opts.Opts),
+	})
+}
+
+func (a *v1Adapter) Snapshots(
+	ctx types.Context, opts types.Store) ([]*types.Snapshot, error) {
+
+	return a.d.Snapshots(ctx, StoreOptions(opts))
+}
+
+func (a *v1Adapter) SnapshotInspect(
+	ctx types.Context,
+	snapshotID string,
+	opts types.Store) (*types.Snapshot, error) {
+
+	return a.d.SnapshotInspect(ctx, snapshotID, StoreOptions(opts))
+}
+
+func (a *v1Adapter) SnapshotCopy(
+	ctx types.Context,
+	snapshotID, snapshotName, destinationID string,
+	opts types.Store) (*types.Snapshot, error) {
+
+	return a.d.SnapshotCopy(
+		ctx, snapshotID, snapshotName, destinationID, StoreOptions(opts))
+}
+
+func (a *v1Adapter) SnapshotRemove(
+	ctx types.Context,
+	snapshotID string,
+	opts types.Store) error {
+
+	return a.d.SnapshotRemove(ctx, snapshotID, StoreOptions(opts))
+}
+
+func createOptions(opts *types.VolumeCreateOpts) VolumeCreateOptions {
+	return VolumeCreateOptions{
+		AvailabilityZone: stringVal(opts.AvailabilityZone),
+		IOPS:             int64Val(opts.IOPS),
+		Size:             int64Val(opts.Size),
+		Type:             stringVal(opts.Type),
+		Encrypted:        boolVal(opts.Encrypted),
+		EncryptionKey:    stringVal(opts.EncryptionKey),
+		Opts:             StoreOptions(opts.Opts),
+	}
+}
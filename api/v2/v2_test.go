@@ -0,0 +1,57 @@
+package v2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+
+	apictx "github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+func TestVolumeCreateOptions(t *testing.T) {
+	opts := &VolumeCreateOptions{
+		Size: 10,
+		Type: "gp2",
+		Opts: Options{"priority": 2},
+	}
+
+	v1 := opts.V1()
+	assert.Nil(t, v1.AvailabilityZone)
+	assert.Nil(t, v1.IOPS)
+	assert.Nil(t, v1.Encrypted)
+	assert.Equal(t, int64(10), *v1.Size)
+	assert.Equal(t, "gp2", *v1.Type)
+	assert.Equal(t, 2, v1.Opts.GetInt("priority"))
+
+	assert.Equal(t, *opts, createOptions(v1))
+
+	req := opts.V1Request("vol-000")
+	assert.Equal(t, "vol-000", req.Name)
+	assert.Equal(t, int64(10), *req.Size)
+	assert.Nil(t, req.EncryptionKey)
+}
+
+func TestVolumeAttachOptions(t *testing.T) {
+	v1 := (&VolumeAttachOptions{Force: true}).V1()
+	assert.True(t, v1.Force)
+	assert.Nil(t, v1.NextDevice)
+	assert.NotNil(t, v1.Opts)
+
+	v1 = (&VolumeAttachOptions{NextDevice: "/dev/xvdb"}).V1()
+	assert.Equal(t, "/dev/xvdb", *v1.NextDevice)
+
+	req := (&VolumeAttachOptions{
+		AccessMode: types.AttachAccessModeReadOnly}).V1Request()
+	assert.Equal(t, types.AttachAccessModeReadOnly, req.AccessMode)
+}
+
+func TestContext(t *testing.T) {
+	assert.NotNil(t, Context(nil))
+
+	ctx := apictx.Background()
+	assert.Equal(t, ctx, Context(ctx))
+
+	assert.NotNil(t, Context(context.Background()))
+}
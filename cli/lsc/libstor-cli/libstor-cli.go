@@ -0,0 +1,9 @@
+package main
+
+import (
+	"github.com/codedellemc/libstorage/cli/lsc"
+)
+
+func main() {
+	lsc.Run()
+}
@@ -0,0 +1,630 @@
+// +build gofig pflag
+
+package lsc
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+	"github.com/akutz/gotil"
+	flag "github.com/spf13/pflag"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/codedellemc/libstorage/api"
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/registry"
+	apitypes "github.com/codedellemc/libstorage/api/types"
+	apiconfig "github.com/codedellemc/libstorage/api/utils/config"
+	"github.com/codedellemc/libstorage/client"
+
+	// load the config and the client's drivers
+	_ "github.com/codedellemc/libstorage/imports/config"
+	_ "github.com/codedellemc/libstorage/imports/local"
+)
+
+const (
+	// EnvProfiles is the environment variable that overrides the path of the
+	// profiles file.
+	EnvProfiles = "LIBSTORAGE_PROFILES"
+
+	// EnvProfile is the environment variable that selects the profile when
+	// the --profile flag is not specified.
+	EnvProfile = "LIBSTORAGE_PROFILE"
+)
+
+var (
+	cliFlags        *flag.FlagSet
+	flagConfig      *string
+	flagHost        *string
+	flagService     *string
+	flagProfile     *string
+	flagProfiles    *string
+	flagOutput      *string
+	flagLogLvl      *string
+	flagSize        *int64
+	flagType        *string
+	flagIOPS        *int64
+	flagZone        *string
+	flagForce       *bool
+	flagAttachments *bool
+	flagAll         *bool
+	flagFSType      *string
+	flagOverwriteFS *bool
+	flagReason      *string
+	flagRequestedBy *string
+	flagHelp        *bool
+	flagVersion     *bool
+)
+
+func init() {
+	cliFlags = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	flagConfig = cliFlags.StringP("config", "c", "", "path")
+	flagHost = cliFlags.StringP("host", "h", "", "<proto>://<addr>")
+	flagService = cliFlags.StringP("service", "s", "", "service name")
+	flagProfile = cliFlags.StringP("profile", "p", "", "profile name")
+	flagProfiles = cliFlags.String("profiles", "", "profiles file path")
+	flagOutput = cliFlags.StringP("output", "o", outputTable, "table|json")
+	flagLogLvl = cliFlags.StringP("log", "l", "warn", "error|warn|info|debug")
+	flagSize = cliFlags.Int64("size", 0, "volume size in GiB")
+	flagType = cliFlags.String("type", "", "volume type")
+	flagIOPS = cliFlags.Int64("iops", 0, "volume IOPS")
+	flagZone = cliFlags.String("availabilityZone", "", "availability zone")
+	flagForce = cliFlags.BoolP("force", "f", false, "force the operation")
+	flagAttachments = cliFlags.BoolP(
+		"attachments", "a", false, "include volume attachments")
+	flagAll = cliFlags.Bool(
+		"all", false, "include volumes not managed by libStorage")
+	flagFSType = cliFlags.String("fsType", "", "file system type")
+	flagOverwriteFS = cliFlags.Bool(
+		"overwriteFS", false, "format the volume if it has no file system")
+	flagReason = cliFlags.String(
+		"reason", "", "why the volume is attached or detached")
+	flagRequestedBy = cliFlags.String(
+		"requestedBy", "", "who requested the attachment or detachment")
+	flagHelp = cliFlags.BoolP("help", "?", false, "print usage")
+	flagVersion = cliFlags.Bool("version", false, "print version info")
+	flag.CommandLine.AddFlagSet(cliFlags)
+}
+
+// Run runs the admin CLI.
+func Run() {
+	flag.Usage = printUsage
+	flag.Parse()
+
+	if *flagVersion {
+		fmt.Fprint(os.Stdout, api.Version.String())
+		os.Exit(0)
+	}
+
+	args := flag.Args()
+	if *flagHelp || len(args) == 0 {
+		printUsage()
+	}
+
+	out, err := newOutput(*flagOutput)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	config, err := newConfig()
+	if err != nil {
+		exitWithError(err)
+	}
+
+	ctx := context.Background()
+	if service := config.GetString(apitypes.ConfigService); service != "" {
+		ctx = ctx.WithValue(context.ServiceKey, service)
+	}
+
+	c, err := client.New(ctx, config)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	cmd := &command{
+		ctx:     ctx,
+		config:  config,
+		client:  c,
+		out:     out,
+		service: config.GetString(apitypes.ConfigService),
+	}
+
+	if err := cmd.run(args); err != nil {
+		if err == errUsage {
+			printUsage()
+		}
+		exitWithError(err)
+	}
+}
+
+// newConfig returns the config from which the client is created. The config
+// is read from the default config files, the file specified with the
+// --config flag, and the selected profile, in that order, and the keys set
+// with command line flags take precedence over all of them.
+func newConfig() (gofig.Config, error) {
+	config := registry.NewConfig()
+	if err := apiconfig.ReadFiles(config); err != nil {
+		return nil, err
+	}
+
+	if *flagConfig != "" {
+		if err := config.ReadConfigFile(*flagConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := readProfile(config); err != nil {
+		return nil, err
+	}
+
+	apitypes.BackCompat(config)
+	apiconfig.BindEnv(config)
+
+	if *flagHost != "" {
+		config.Set(apitypes.ConfigHost, *flagHost)
+	}
+	if *flagService != "" {
+		config.Set(apitypes.ConfigService, *flagService)
+	}
+	config.Set(apitypes.ConfigLogLevel, *flagLogLvl)
+	apiconfig.UpdateLogLevel(config)
+
+	return config, nil
+}
+
+// profilesFile returns the path of the profiles file.
+func profilesFile() string {
+	if *flagProfiles != "" {
+		return *flagProfiles
+	}
+	if v := os.Getenv(EnvProfiles); v != "" {
+		return v
+	}
+	return path.Join(gotil.HomeDir(), ".libstorage", "profiles.yml")
+}
+
+// profiles is the content of a profiles file. Each profile is a libStorage
+// config, and the default profile is used when no profile is selected:
+//
+//     default: prod
+//     profiles:
+//       prod:
+//         libstorage:
+//           host: tcp://libstorage.example.com:7979
+//           service: ebs
+//       lab:
+//         libstorage:
+//           host: unix:///var/run/libstorage/localhost.sock
+//           service: vfs
+type profiles struct {
+	Default  string                 `yaml:"default"`
+	Profiles map[string]interface{} `yaml:"profiles"`
+}
+
+// readProfile reads the selected profile into the config. It is not an
+// error for the profiles file to be missing unless a profile is selected.
+func readProfile(config gofig.Config) error {
+	name := *flagProfile
+	if name == "" {
+		name = os.Getenv(EnvProfile)
+	}
+
+	file := profilesFile()
+	if !gotil.FileExists(file) {
+		if name != "" {
+			return goof.WithField("path", file, "profiles file not found")
+		}
+		return nil
+	}
+
+	buf, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	p := &profiles{}
+	if err := yaml.Unmarshal(buf, p); err != nil {
+		return goof.WithFieldE("path", file, "invalid profiles file", err)
+	}
+
+	if name == "" {
+		name = p.Default
+	}
+	if name == "" {
+		return nil
+	}
+
+	profile, ok := p.Profiles[name]
+	if !ok {
+		return goof.WithFields(goof.Fields{
+			"path":    file,
+			"profile": name,
+		}, "profile not found")
+	}
+	if buf, err = yaml.Marshal(profile); err != nil {
+		return err
+	}
+	return config.ReadConfig(bytes.NewReader(buf))
+}
+
+// command executes a command with the client.
+type command struct {
+	ctx     apitypes.Context
+	config  gofig.Config
+	client  apitypes.Client
+	out     output
+	service string
+}
+
+func (c *command) run(args []string) error {
+	switch strings.ToLower(args[0]) {
+	case "services", "service":
+		return c.services(args[1:])
+	case "volumes", "volume":
+		return c.volumes(args[1:])
+	case "snapshots", "snapshot":
+		return c.snapshots(args[1:])
+	case "tasks", "task":
+		return c.tasks(args[1:])
+	case "instances", "instance":
+		return c.instances(args[1:])
+	case "mount":
+		return c.mount(args[1:])
+	case "unmount", "umount":
+		return c.unmount(args[1:])
+	}
+	return errUsage
+}
+
+func (c *command) services(args []string) error {
+	if len(args) == 0 || args[0] == "ls" {
+		svcs, err := c.client.API().Services(c.ctx)
+		if err != nil {
+			return err
+		}
+		return c.out.services(svcs)
+	}
+	if len(args) != 2 {
+		return errUsage
+	}
+	switch args[0] {
+	case "inspect":
+		svc, err := c.client.API().ServiceInspect(c.ctx, args[1])
+		if err != nil {
+			return err
+		}
+		return c.out.services(map[string]*apitypes.ServiceInfo{args[1]: svc})
+	case "caps":
+		caps, err := c.client.API().ServiceCapabilities(c.ctx, args[1])
+		if err != nil {
+			return err
+		}
+		return c.out.object(caps)
+	}
+	return errUsage
+}
+
+func (c *command) volumes(args []string) error {
+	attachments := apitypes.VolAttNone
+	if *flagAttachments {
+		attachments = apitypes.VolAttReq
+	}
+
+	if len(args) == 0 || args[0] == "ls" {
+		if *flagAll {
+			vols, err := c.client.API().AllVolumes(c.ctx, attachments)
+			if err != nil {
+				return err
+			}
+			if c.service != "" {
+				vols = apitypes.ServiceVolumeMap{c.service: vols[c.service]}
+			}
+			return c.out.volumes(vols)
+		}
+		if c.service == "" {
+			vols, err := c.client.API().Volumes(c.ctx, attachments)
+			if err != nil {
+				return err
+			}
+			return c.out.volumes(vols)
+		}
+		vols, err := c.client.API().VolumesByService(
+			c.ctx, c.service, attachments)
+		if err != nil {
+			return err
+		}
+		return c.out.volumes(apitypes.ServiceVolumeMap{c.service: vols})
+	}
+
+	if len(args) < 2 {
+		return errUsage
+	}
+	if err := c.requireService(); err != nil {
+		return err
+	}
+
+	var (
+		vol *apitypes.Volume
+		err error
+	)
+
+	ac := c.client.API()
+	switch args[0] {
+	case "inspect":
+		vol, err = ac.VolumeInspect(c.ctx, c.service, args[1], attachments)
+	case "create":
+		vol, err = ac.VolumeCreate(c.ctx, c.service, c.createRequest(args[1]))
+	case "remove", "rm":
+		if err := ac.VolumeRemove(
+			c.ctx, c.service, args[1], *flagForce); err != nil {
+			return err
+		}
+		return c.out.id(args[1])
+	case "attach":
+		var token string
+		vol, token, err = ac.VolumeAttach(
+			c.ctx, c.service, args[1],
+			&apitypes.VolumeAttachRequest{
+				Force:       *flagForce,
+				Reason:      *flagReason,
+				RequestedBy: *flagRequestedBy,
+			})
+		if err == nil && token != "" {
+			vol.Fields = setField(vol.Fields, "attachToken", token)
+		}
+	case "detach":
+		vol, err = ac.VolumeDetach(
+			c.ctx, c.service, args[1],
+			&apitypes.VolumeDetachRequest{
+				Force:       *flagForce,
+				Reason:      *flagReason,
+				RequestedBy: *flagRequestedBy,
+			})
+	case "events":
+		events, err := ac.VolumeEvents(c.ctx, c.service, args[1])
+		if err != nil {
+			return err
+		}
+		return c.out.object(events)
+	case "snapshot":
+		if len(args) != 3 {
+			return errUsage
+		}
+		snap, err := ac.VolumeSnapshot(
+			c.ctx, c.service, args[1],
+			&apitypes.VolumeSnapshotRequest{SnapshotName: args[2]})
+		if err != nil {
+			return err
+		}
+		return c.out.snapshots(apitypes.ServiceSnapshotMap{
+			c.service: apitypes.SnapshotMap{snap.ID: snap},
+		})
+	default:
+		return errUsage
+	}
+
+	if err != nil {
+		return err
+	}
+	return c.out.volumes(apitypes.ServiceVolumeMap{
+		c.service: apitypes.VolumeMap{vol.ID: vol},
+	})
+}
+
+func (c *command) createRequest(name string) *apitypes.VolumeCreateRequest {
+	req := &apitypes.VolumeCreateRequest{Name: name}
+	if *flagSize > 0 {
+		req.Size = flagSize
+	}
+	if *flagType != "" {
+		req.Type = flagType
+	}
+	if *flagIOPS > 0 {
+		req.IOPS = flagIOPS
+	}
+	if *flagZone != "" {
+		req.AvailabilityZone = flagZone
+	}
+	return req
+}
+
+func (c *command) snapshots(args []string) error {
+	ac := c.client.API()
+
+	if len(args) == 0 || args[0] == "ls" {
+		if c.service == "" {
+			snaps, err := ac.Snapshots(c.ctx)
+			if err != nil {
+				return err
+			}
+			return c.out.snapshots(snaps)
+		}
+		snaps, err := ac.SnapshotsByService(c.ctx, c.service)
+		if err != nil {
+			return err
+		}
+		return c.out.snapshots(apitypes.ServiceSnapshotMap{c.service: snaps})
+	}
+
+	if len(args) != 2 {
+		return errUsage
+	}
+	if err := c.requireService(); err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "inspect":
+		snap, err := ac.SnapshotInspect(c.ctx, c.service, args[1])
+		if err != nil {
+			return err
+		}
+		return c.out.snapshots(apitypes.ServiceSnapshotMap{
+			c.service: apitypes.SnapshotMap{snap.ID: snap},
+		})
+	case "remove", "rm":
+		if err := ac.SnapshotRemove(c.ctx, c.service, args[1]); err != nil {
+			return err
+		}
+		return c.out.id(args[1])
+	}
+	return errUsage
+}
+
+func (c *command) tasks(args []string) error {
+	if len(args) == 0 || args[0] == "ls" {
+		tasks, err := c.client.API().Tasks(c.ctx)
+		if err != nil {
+			return err
+		}
+		return c.out.tasks(tasks)
+	}
+	if len(args) != 2 || args[0] != "inspect" {
+		return errUsage
+	}
+	id, err := strconv.Atoi(args[1])
+	if err != nil {
+		return goof.WithFieldE("taskID", args[1], "invalid task id", err)
+	}
+	task, err := c.client.API().TaskInspect(c.ctx, id)
+	if err != nil {
+		return err
+	}
+	return c.out.tasks(map[string]*apitypes.Task{args[1]: task})
+}
+
+func (c *command) instances(args []string) error {
+	if len(args) > 0 && args[0] != "ls" {
+		return errUsage
+	}
+	instances, err := c.client.API().Instances(c.ctx)
+	if err != nil {
+		return err
+	}
+	return c.out.instances(instances)
+}
+
+// mount attaches and mounts the volume on the local host with the client's
+// integration driver.
+func (c *command) mount(args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	if err := c.requireService(); err != nil {
+		return err
+	}
+	mountPath, vol, err := c.client.Integration().Mount(
+		c.ctx, "", args[0], &apitypes.VolumeMountOpts{
+			NewFSType:   *flagFSType,
+			OverwriteFS: *flagOverwriteFS,
+			Preempt:     *flagForce,
+		})
+	if err != nil {
+		return err
+	}
+	vol.Fields = setField(vol.Fields, "mountPath", mountPath)
+	return c.out.volumes(apitypes.ServiceVolumeMap{
+		c.service: apitypes.VolumeMap{vol.ID: vol},
+	})
+}
+
+// unmount unmounts and detaches the volume from the local host with the
+// client's integration driver.
+func (c *command) unmount(args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	if err := c.requireService(); err != nil {
+		return err
+	}
+	vol, err := c.client.Integration().Unmount(c.ctx, "", args[0], nil)
+	if err != nil {
+		return err
+	}
+	if vol == nil {
+		return c.out.id(args[0])
+	}
+	return c.out.volumes(apitypes.ServiceVolumeMap{
+		c.service: apitypes.VolumeMap{vol.ID: vol},
+	})
+}
+
+func (c *command) requireService() error {
+	if c.service == "" {
+		return goof.New("service is required")
+	}
+	return nil
+}
+
+func setField(fields map[string]string, k, v string) map[string]string {
+	if fields == nil {
+		fields = map[string]string{}
+	}
+	fields[k] = v
+	return fields
+}
+
+func exitWithError(err error) {
+	fmt.Fprintf(os.Stderr, "%s: error: %v\n", os.Args[0], err)
+	os.Exit(1)
+}
+
+func printUsage() {
+	fmt.Fprintf(os.Stderr, usage, os.Args[0])
+	fmt.Fprintln(os.Stderr, cliFlags.FlagUsages())
+	fmt.Fprintf(os.Stderr, profilesUsage, os.Args[0])
+	os.Exit(1)
+}
+
+const (
+	usage = `usage: %s [-options] <command> [<args>]
+
+  Commands
+
+    services  [ls]
+    services  inspect|caps <service>
+    volumes   [ls] [-a] [--all]
+    volumes   inspect|create|remove|attach|detach <volume>
+    volumes   snapshot <volumeID> <snapshotName>
+    volumes   events <volumeID>
+    snapshots [ls]
+    snapshots inspect|remove <snapshotID>
+    tasks     [ls]
+    tasks     inspect <taskID>
+    instances [ls]
+    mount     <volumeName>
+    unmount   <volumeName>
+
+  The volumes and snapshots commands that operate on a single volume or
+  snapshot require a service, which is specified with the -s flag or with
+  the libstorage.service config key. The mount and unmount commands attach
+  and mount, and unmount and detach, a volume on the local host with the
+  client's integration driver.
+
+`
+
+	profilesUsage = `  Profiles
+
+    A profile is a named libStorage config read from the profiles file,
+    which is $HOME/.libstorage/profiles.yml unless the --profiles flag or
+    the LIBSTORAGE_PROFILES environment variable specify another path.
+    The -p flag or the LIBSTORAGE_PROFILE environment variable select the
+    profile, otherwise the file's default profile is used:
+
+      default: prod
+      profiles:
+        prod:
+          libstorage:
+            host: tcp://libstorage.example.com:7979
+            service: ebs
+
+    For example, "%[1]s -p prod volumes ls" lists the volumes of the ebs
+    service of the server at libstorage.example.com.
+`
+)
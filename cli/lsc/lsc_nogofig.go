@@ -0,0 +1,14 @@
+// +build !gofig !pflag
+
+package lsc
+
+import (
+	"fmt"
+	"os"
+)
+
+// Run runs the admin CLI.
+func Run() {
+	fmt.Fprintf(os.Stderr, "%s was built without gofig\n", os.Args[0])
+	os.Exit(1)
+}
@@ -0,0 +1,228 @@
+// +build gofig pflag
+
+package lsc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/akutz/goof"
+
+	apitypes "github.com/codedellemc/libstorage/api/types"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// errUsage is returned when a command's arguments are invalid.
+var errUsage = errors.New("invalid usage")
+
+// output writes the results of the commands to stdout.
+type output interface {
+	id(id string) error
+	object(v interface{}) error
+	services(svcs map[string]*apitypes.ServiceInfo) error
+	volumes(vols apitypes.ServiceVolumeMap) error
+	snapshots(snaps apitypes.ServiceSnapshotMap) error
+	tasks(tasks map[string]*apitypes.Task) error
+	instances(instances map[string]*apitypes.Instance) error
+}
+
+func newOutput(format string) (output, error) {
+	switch strings.ToLower(format) {
+	case outputTable:
+		return &tableOutput{w: os.Stdout}, nil
+	case outputJSON:
+		return &jsonOutput{w: os.Stdout}, nil
+	}
+	return nil, goof.WithField("output", format, "invalid output format")
+}
+
+// jsonOutput writes the results as indented JSON.
+type jsonOutput struct {
+	w io.Writer
+}
+
+func (o *jsonOutput) write(v interface{}) error {
+	buf, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(o.w, string(buf))
+	return err
+}
+
+func (o *jsonOutput) id(id string) error {
+	return o.write(map[string]string{"id": id})
+}
+
+func (o *jsonOutput) object(v interface{}) error {
+	return o.write(v)
+}
+
+func (o *jsonOutput) services(svcs map[string]*apitypes.ServiceInfo) error {
+	return o.write(svcs)
+}
+
+func (o *jsonOutput) volumes(vols apitypes.ServiceVolumeMap) error {
+	return o.write(vols)
+}
+
+func (o *jsonOutput) snapshots(snaps apitypes.ServiceSnapshotMap) error {
+	return o.write(snaps)
+}
+
+func (o *jsonOutput) tasks(tasks map[string]*apitypes.Task) error {
+	return o.write(tasks)
+}
+
+func (o *jsonOutput) instances(
+	instances map[string]*apitypes.Instance) error {
+	return o.write(instances)
+}
+
+// tableOutput writes the results as tab-aligned columns sorted by their
+// first columns.
+type tableOutput struct {
+	w io.Writer
+}
+
+func (o *tableOutput) write(header []string, rows [][]string) error {
+	sort.Sort(byColumns(rows))
+	tw := tabwriter.NewWriter(o.w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func (o *tableOutput) id(id string) error {
+	_, err := fmt.Fprintln(o.w, id)
+	return err
+}
+
+// object writes the object as JSON since its fields are not known.
+func (o *tableOutput) object(v interface{}) error {
+	return (&jsonOutput{w: o.w}).write(v)
+}
+
+func (o *tableOutput) services(svcs map[string]*apitypes.ServiceInfo) error {
+	var rows [][]string
+	for name, svc := range svcs {
+		var driver, storType string
+		if svc.Driver != nil {
+			driver = svc.Driver.Name
+			storType = string(svc.Driver.Type)
+		}
+		rows = append(rows, []string{name, driver, storType})
+	}
+	return o.write([]string{"SERVICE", "DRIVER", "TYPE"}, rows)
+}
+
+func (o *tableOutput) volumes(vols apitypes.ServiceVolumeMap) error {
+	var rows [][]string
+	for service, vm := range vols {
+		for _, v := range vm {
+			var instances []string
+			for _, a := range v.Attachments {
+				if a.InstanceID != nil {
+					instances = append(instances, a.InstanceID.ID)
+				}
+			}
+			rows = append(rows, []string{
+				service,
+				v.ID,
+				v.Name,
+				fmt.Sprintf("%d", v.Size),
+				v.Status,
+				strings.Join(instances, ","),
+				v.MountPoint(),
+			})
+		}
+	}
+	return o.write([]string{
+		"SERVICE", "ID", "NAME", "SIZE", "STATUS", "ATTACHED TO", "MOUNT",
+	}, rows)
+}
+
+func (o *tableOutput) snapshots(snaps apitypes.ServiceSnapshotMap) error {
+	var rows [][]string
+	for service, sm := range snaps {
+		for _, s := range sm {
+			rows = append(rows, []string{
+				service,
+				s.ID,
+				s.Name,
+				s.VolumeID,
+				fmt.Sprintf("%d", s.VolumeSize),
+				s.Status,
+			})
+		}
+	}
+	return o.write([]string{
+		"SERVICE", "ID", "NAME", "VOLUME ID", "SIZE", "STATUS",
+	}, rows)
+}
+
+func (o *tableOutput) tasks(tasks map[string]*apitypes.Task) error {
+	var rows [][]string
+	for id, t := range tasks {
+		var errMsg string
+		if t.Error != nil {
+			errMsg = t.Error.Error()
+		}
+		rows = append(rows, []string{
+			fmt.Sprintf("%8s", id),
+			string(t.State),
+			t.User,
+			fmt.Sprintf("%d", t.Progress),
+			errMsg,
+		})
+	}
+	return o.write([]string{"ID", "STATE", "USER", "PROGRESS", "ERROR"}, rows)
+}
+
+func (o *tableOutput) instances(
+	instances map[string]*apitypes.Instance) error {
+
+	var rows [][]string
+	for service, i := range instances {
+		if i == nil {
+			rows = append(rows, []string{service, "", "", "", ""})
+			continue
+		}
+		var id string
+		if i.InstanceID != nil {
+			id = i.InstanceID.ID
+		}
+		rows = append(rows, []string{
+			service, id, i.Name, i.ProviderName, i.Region,
+		})
+	}
+	return o.write([]string{
+		"SERVICE", "INSTANCE ID", "NAME", "PROVIDER", "REGION",
+	}, rows)
+}
+
+// byColumns implements sort.Interface for table rows.
+type byColumns [][]string
+
+func (a byColumns) Len() int      { return len(a) }
+func (a byColumns) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byColumns) Less(i, j int) bool {
+	for c := 0; c < len(a[i]) && c < len(a[j]); c++ {
+		if a[i][c] != a[j][c] {
+			return a[i][c] < a[j][c]
+		}
+	}
+	return false
+}
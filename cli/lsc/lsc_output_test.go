@@ -0,0 +1,206 @@
+// +build gofig pflag
+
+package lsc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apitypes "github.com/codedellemc/libstorage/api/types"
+)
+
+func TestNewOutput(t *testing.T) {
+	tests := []struct {
+		format string
+		want   output
+	}{
+		{"table", &tableOutput{}},
+		{"TABLE", &tableOutput{}},
+		{"json", &jsonOutput{}},
+		{"Json", &jsonOutput{}},
+		{"yaml", nil},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		out, err := newOutput(tt.format)
+		if tt.want == nil {
+			assert.Error(t, err, tt.format)
+			assert.Nil(t, out, tt.format)
+			continue
+		}
+		assert.NoError(t, err, tt.format)
+		assert.IsType(t, tt.want, out, tt.format)
+	}
+}
+
+var (
+	testVols = apitypes.ServiceVolumeMap{
+		"vfs": apitypes.VolumeMap{
+			"vfs-001": &apitypes.Volume{
+				ID:     "vfs-001",
+				Name:   "data",
+				Size:   10,
+				Status: "attached",
+				Attachments: []*apitypes.VolumeAttachment{
+					{
+						InstanceID: &apitypes.InstanceID{ID: "i-001"},
+						MountPoint: "/var/lib/libstorage/volumes/data",
+					},
+				},
+			},
+			"vfs-000": &apitypes.Volume{
+				ID:   "vfs-000",
+				Name: "logs",
+				Size: 1,
+			},
+		},
+	}
+
+	testSnaps = apitypes.ServiceSnapshotMap{
+		"vfs": apitypes.SnapshotMap{
+			"snap-000": &apitypes.Snapshot{
+				ID:         "snap-000",
+				Name:       "backup",
+				VolumeID:   "vfs-001",
+				VolumeSize: 10,
+				Status:     "completed",
+			},
+		},
+	}
+)
+
+func TestJSONOutput(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(output) error
+		want  string
+	}{
+		{
+			"id",
+			func(o output) error { return o.id("vfs-000") },
+			`{"id": "vfs-000"}`,
+		},
+		{
+			"object",
+			func(o output) error {
+				return o.object(map[string]bool{"snapshots": true})
+			},
+			`{"snapshots": true}`,
+		},
+		{
+			"volumes",
+			func(o output) error { return o.volumes(testVols) },
+			`{"vfs": {
+				"vfs-000": {"id": "vfs-000", "name": "logs", "size": 1,
+					"type": ""},
+				"vfs-001": {"id": "vfs-001", "name": "data", "size": 10,
+					"status": "attached", "type": "", "attachments": [{
+						"deviceName": "",
+						"mountPoint": "/var/lib/libstorage/volumes/data",
+						"instanceID": {
+							"id": "i-001", "driver": "", "service": ""},
+						"status": "", "volumeID": ""}]}}}`,
+		},
+		{
+			"snapshots",
+			func(o output) error { return o.snapshots(testSnaps) },
+			`{"vfs": {"snap-000": {"id": "snap-000", "name": "backup",
+				"volumeID": "vfs-001", "volumeSize": 10,
+				"status": "completed"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		assert.NoError(t, tt.write(&jsonOutput{w: buf}), tt.name)
+		assert.JSONEq(t, tt.want, buf.String(), tt.name)
+	}
+}
+
+func TestTableOutput(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(output) error
+		want  string
+	}{
+		{
+			"id",
+			func(o output) error { return o.id("vfs-000") },
+			"vfs-000\n",
+		},
+		{
+			"services",
+			func(o output) error {
+				return o.services(map[string]*apitypes.ServiceInfo{
+					"vfs": {
+						Name: "vfs",
+						Driver: &apitypes.DriverInfo{
+							Name: "vfs",
+							Type: apitypes.NAS,
+						},
+					},
+					"ebs": {Name: "ebs"},
+				})
+			},
+			"SERVICE  DRIVER  TYPE\n" +
+				"ebs              \n" +
+				"vfs      vfs     nas\n",
+		},
+		{
+			"volumes",
+			func(o output) error { return o.volumes(testVols) },
+			"SERVICE  ID       NAME  SIZE  STATUS    ATTACHED TO  MOUNT\n" +
+				"vfs      vfs-000  logs  1                            \n" +
+				"vfs      vfs-001  data  10    attached  i-001        " +
+				"/var/lib/libstorage/volumes/data\n",
+		},
+		{
+			"snapshots",
+			func(o output) error { return o.snapshots(testSnaps) },
+			"SERVICE  ID        NAME    VOLUME ID  SIZE  STATUS\n" +
+				"vfs      snap-000  backup  vfs-001    10    completed\n",
+		},
+		{
+			"tasks",
+			func(o output) error {
+				return o.tasks(map[string]*apitypes.Task{
+					"10": {ID: 10, State: apitypes.TaskStateRunning},
+					"9": {
+						ID:       9,
+						State:    apitypes.TaskStateSuccess,
+						User:     "admin",
+						Progress: 100,
+					},
+				})
+			},
+			"ID        STATE    USER   PROGRESS  ERROR\n" +
+				"       9  success  admin  100       \n" +
+				"      10  running         0         \n",
+		},
+		{
+			"instances",
+			func(o output) error {
+				return o.instances(map[string]*apitypes.Instance{
+					"vfs": {
+						InstanceID:   &apitypes.InstanceID{ID: "i-001"},
+						Name:         "host",
+						ProviderName: "vfs",
+					},
+					"ebs": nil,
+				})
+			},
+			"SERVICE  INSTANCE ID  NAME  PROVIDER  REGION\n" +
+				"ebs                                   \n" +
+				"vfs      i-001        host  vfs       \n",
+		},
+	}
+
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		assert.NoError(t, tt.write(&tableOutput{w: buf}), tt.name)
+		assert.Equal(t, tt.want, buf.String(), tt.name)
+	}
+}
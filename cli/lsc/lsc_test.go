@@ -0,0 +1,185 @@
+// +build gofig pflag
+
+package lsc
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	gofigCore "github.com/akutz/gofig"
+	"github.com/akutz/gotil"
+	"github.com/stretchr/testify/assert"
+
+	apitypes "github.com/codedellemc/libstorage/api/types"
+)
+
+// setProfileFlags sets the --profile and --profiles flags and the profile
+// environment variables and returns a func that restores them.
+func setProfileFlags(
+	t *testing.T, profile, profiles, envProfile, envProfiles string) func() {
+
+	oldProfile, oldProfiles := *flagProfile, *flagProfiles
+	oldEnvProfile := os.Getenv(EnvProfile)
+	oldEnvProfiles := os.Getenv(EnvProfiles)
+
+	*flagProfile, *flagProfiles = profile, profiles
+	assert.NoError(t, os.Setenv(EnvProfile, envProfile))
+	assert.NoError(t, os.Setenv(EnvProfiles, envProfiles))
+
+	return func() {
+		*flagProfile, *flagProfiles = oldProfile, oldProfiles
+		os.Setenv(EnvProfile, oldEnvProfile)
+		os.Setenv(EnvProfiles, oldEnvProfiles)
+	}
+}
+
+func TestProfilesFile(t *testing.T) {
+	tests := []struct {
+		name string
+		flag string
+		env  string
+		want string
+	}{
+		{
+			"default",
+			"",
+			"",
+			path.Join(gotil.HomeDir(), ".libstorage", "profiles.yml"),
+		},
+		{
+			"env",
+			"",
+			"/etc/libstorage/profiles.yml",
+			"/etc/libstorage/profiles.yml",
+		},
+		{
+			"flag",
+			"/tmp/profiles.yml",
+			"/etc/libstorage/profiles.yml",
+			"/tmp/profiles.yml",
+		},
+	}
+
+	for _, tt := range tests {
+		restore := setProfileFlags(t, "", tt.flag, "", tt.env)
+		assert.Equal(t, tt.want, profilesFile(), tt.name)
+		restore()
+	}
+}
+
+const testProfiles = `default: prod
+profiles:
+  prod:
+    libstorage:
+      host: tcp://libstorage.example.com:7979
+      service: ebs
+  lab:
+    libstorage:
+      host: unix:///var/run/libstorage/localhost.sock
+      service: vfs
+`
+
+func TestReadProfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "profiles")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	profilesPath := path.Join(dir, "profiles.yml")
+	noDefaultPath := path.Join(dir, "nodefault.yml")
+	invalidPath := path.Join(dir, "invalid.yml")
+	missingPath := path.Join(dir, "missing.yml")
+	for p, s := range map[string]string{
+		profilesPath:  testProfiles,
+		noDefaultPath: testProfiles[len("default: prod\n"):],
+		invalidPath:   "profiles: [",
+	} {
+		if !assert.NoError(t, ioutil.WriteFile(p, []byte(s), 0600)) {
+			t.FailNow()
+		}
+	}
+
+	tests := []struct {
+		name       string
+		profile    string
+		envProfile string
+		file       string
+		host       string
+		service    string
+		err        bool
+	}{
+		{
+			name:    "default profile",
+			file:    profilesPath,
+			host:    "tcp://libstorage.example.com:7979",
+			service: "ebs",
+		},
+		{
+			name:    "flag profile",
+			profile: "lab",
+			file:    profilesPath,
+			host:    "unix:///var/run/libstorage/localhost.sock",
+			service: "vfs",
+		},
+		{
+			name:       "env profile",
+			envProfile: "lab",
+			file:       profilesPath,
+			host:       "unix:///var/run/libstorage/localhost.sock",
+			service:    "vfs",
+		},
+		{
+			name:       "flag overrides env",
+			profile:    "prod",
+			envProfile: "lab",
+			file:       profilesPath,
+			host:       "tcp://libstorage.example.com:7979",
+			service:    "ebs",
+		},
+		{
+			name: "no default profile",
+			file: noDefaultPath,
+		},
+		{
+			name: "missing file",
+			file: missingPath,
+		},
+		{
+			name:    "missing file with profile",
+			profile: "prod",
+			file:    missingPath,
+			err:     true,
+		},
+		{
+			name:    "missing profile",
+			profile: "qa",
+			file:    profilesPath,
+			err:     true,
+		},
+		{
+			name: "invalid file",
+			file: invalidPath,
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		restore := setProfileFlags(t, tt.profile, tt.file, tt.envProfile, "")
+		config := gofigCore.New()
+		err := readProfile(config)
+		restore()
+
+		if tt.err {
+			assert.Error(t, err, tt.name)
+			continue
+		}
+		assert.NoError(t, err, tt.name)
+		assert.Equal(
+			t, tt.host, config.GetString(apitypes.ConfigHost), tt.name)
+		assert.Equal(
+			t, tt.service, config.GetString(apitypes.ConfigService), tt.name)
+	}
+}
@@ -4,9 +4,11 @@ package lss
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
 
@@ -17,6 +19,7 @@ import (
 	flag "github.com/spf13/pflag"
 
 	"github.com/codedellemc/libstorage/api"
+	"github.com/codedellemc/libstorage/api/registry"
 	"github.com/codedellemc/libstorage/api/server"
 	apitypes "github.com/codedellemc/libstorage/api/types"
 	apiconfig "github.com/codedellemc/libstorage/api/utils/config"
@@ -37,6 +40,8 @@ var (
 	flagVersion     *bool
 	flagEnv         *bool
 	flagPrintConfig *bool
+	flagPrintEff    *bool
+	flagBindings    *flag.FlagSet
 	config          gofig.Config
 )
 
@@ -49,6 +54,8 @@ func init() {
 	flagVersion = cliFlags.Bool("version", false, "print version info")
 	flagEnv = cliFlags.Bool("env", false, "print env info")
 	flagPrintConfig = cliFlags.Bool("printConfig", false, "print config info")
+	flagPrintEff = cliFlags.Bool(
+		"print-effective-config", false, "print merged, redacted config")
 	flagVerbose = cliFlags.BoolP("verbose", "v", false, "print verbose usage")
 	flag.CommandLine.AddFlagSet(cliFlags)
 }
@@ -57,6 +64,15 @@ func init() {
 func Run() {
 	server.CloseOnAbort()
 
+	// the config is created before the command line is parsed so that the
+	// flags of the config's keys are parsed as well
+	config = registry.NewConfig()
+	for _, fs := range config.FlagSets() {
+		flag.CommandLine.AddFlagSet(fs)
+	}
+	flagBindings = apiconfig.FlagSet(config)
+	flag.CommandLine.AddFlagSet(flagBindings)
+
 	flag.Usage = printUsage
 	flag.Parse()
 
@@ -77,12 +93,11 @@ func Run() {
 	// if a config is specified then do not care about any other options
 	if flagConfig != nil && gotil.FileExists(*flagConfig) {
 
-		config = gofigCore.New()
-
 		if err := config.ReadConfigFile(*flagConfig); err != nil {
 			fmt.Fprintf(os.Stderr, "%s: error: %v\n", os.Args[0], err)
 			os.Exit(1)
 		}
+		bindConfig(config)
 
 		if flagPrintConfig != nil && *flagPrintConfig {
 			jstr, err := config.ToJSON()
@@ -94,12 +109,24 @@ func Run() {
 			os.Exit(0)
 		}
 
+		if flagPrintEff != nil && *flagPrintEff {
+			printEffectiveConfig()
+		}
+
 		s, errs, err := server.Serve(nil, config)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "%s: error: %v\n", os.Args[0], err)
 			os.Exit(1)
 		}
 
+		reloadOnChange(func() (gofig.Config, error) {
+			config := gofigCore.New()
+			if err := config.ReadConfigFile(*flagConfig); err != nil {
+				return nil, err
+			}
+			return bindConfig(config), nil
+		}, *flagConfig)
+
 		err = <-errs
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "%s: error: %v\n", os.Args[0], err)
@@ -110,22 +137,19 @@ func Run() {
 		os.Exit(0)
 	}
 
-	cfg, err := apiconfig.NewConfig()
-	if err != nil {
+	if err := apiconfig.ReadFiles(config); err != nil {
 		fmt.Fprintf(os.Stderr, "%s: error: %v\n", os.Args[0], err)
 		os.Exit(1)
 	}
-
-	config = cfg
-	for _, fs := range config.FlagSets() {
-		flag.CommandLine.AddFlagSet(fs)
-	}
+	apitypes.BackCompat(config)
+	bindConfig(config)
 
 	if flagHelp != nil && *flagHelp {
 		flag.Usage()
 	}
 
-	if len(flag.Args()) == 0 {
+	printEff := flagPrintEff != nil && *flagPrintEff
+	if len(flag.Args()) == 0 && !printEff {
 		flag.Usage()
 	}
 
@@ -163,9 +187,16 @@ func Run() {
 		}
 		fmt.Fprintf(buf, "      %s:\n        driver: %s\n", sn, dn)
 	}
-	if err := config.ReadConfig(buf); err != nil {
-		fmt.Fprintf(os.Stderr, "%s: error: %v\n", os.Args[0], err)
-		os.Exit(1)
+	svcsConfig := buf.Bytes()
+	if len(flag.Args()) > 0 {
+		if err := config.ReadConfig(bytes.NewReader(svcsConfig)); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: error: %v\n", os.Args[0], err)
+			os.Exit(1)
+		}
+	}
+
+	if printEff {
+		printEffectiveConfig()
 	}
 
 	server.CloseOnAbort()
@@ -176,14 +207,61 @@ func Run() {
 		os.Exit(1)
 	}
 
+	reloadOnChange(func() (gofig.Config, error) {
+		config, err := apiconfig.NewConfig()
+		if err != nil {
+			return nil, err
+		}
+		if err := config.ReadConfig(bytes.NewReader(svcsConfig)); err != nil {
+			return nil, err
+		}
+		apiconfig.BindFlags(config, flagBindings)
+		return config, nil
+	})
+
 	<-errs
 }
 
+// bindConfig sets the config's keys that are not bound by the config itself
+// from their environment variables and command line flags.
+func bindConfig(config gofig.Config) gofig.Config {
+	apiconfig.BindEnv(config)
+	apiconfig.BindFlags(config, flagBindings)
+	return config
+}
+
+// printEffectiveConfig prints the merged config, with the values of secret
+// keys redacted, and exits.
+func printEffectiveConfig() {
+	buf, err := json.MarshalIndent(apiconfig.Effective(config), "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: error: %v\n", os.Args[0], err)
+		os.Exit(1)
+	}
+	fmt.Fprintln(os.Stdout, string(buf))
+	os.Exit(0)
+}
+
+// reloadOnChange reloads the server when the process receives a SIGHUP
+// signal and, if a reload interval is configured, when the provided config
+// files change. If no files are provided then the default config files are
+// watched.
+func reloadOnChange(load server.ConfigLoader, files ...string) {
+	server.ReloadOnHangup(load)
+	interval, err := time.ParseDuration(
+		config.GetString(apitypes.ConfigServerReloadInterval))
+	if err != nil || interval <= 0 {
+		return
+	}
+	server.ReloadOnChange(interval, load, files...)
+}
+
 func printUsage() {
 	firstLine := fmt.Sprintf("usage: %s", os.Args[0])
 	fmt.Fprintf(os.Stderr, "%s\n", firstLine)
 	padFmt := fmt.Sprintf("%%%ds\n", len(firstLine))
 	fmt.Fprintf(os.Stderr, padFmt, "-c,--config <configFilePath> [--printConfig]")
+	fmt.Fprintf(os.Stderr, padFmt, "--print-effective-config")
 	fmt.Fprintf(os.Stderr, padFmt, "--version")
 	fmt.Fprintf(os.Stderr, padFmt, "--env")
 	fmt.Fprintf(os.Stderr, padFmt, "[-options] <driver>[:<service>] [<driver>[:<service>]...]")
@@ -199,6 +277,8 @@ func printUsage() {
 			fmt.Fprintln(os.Stderr, fsn)
 			fmt.Fprintln(os.Stderr, fs.FlagUsages())
 		}
+		fmt.Fprintln(os.Stderr, "libStorage Options")
+		fmt.Fprintln(os.Stderr, flagBindings.FlagUsages())
 		fmt.Fprintln(os.Stderr)
 	}
 
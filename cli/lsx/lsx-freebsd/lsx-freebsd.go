@@ -0,0 +1,11 @@
+// +build freebsd
+
+package main
+
+import (
+	"github.com/codedellemc/libstorage/cli/lsx"
+)
+
+func main() {
+	lsx.Run()
+}
@@ -0,0 +1,49 @@
+// lsx-sign signs executors when they are built so that the key with which
+// they are signed never needs to be present on a server. The base64 encoded
+// Ed25519 signature of each executor is written to a file with the
+// executor's path and a ".sig" extension, which is embedded in the server
+// alongside the executor.
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+func main() {
+	if len(os.Args) < 3 {
+		fmt.Fprintf(os.Stderr, "usage: %s KEY_FILE EXECUTOR...\n",
+			filepath.Base(os.Args[0]))
+		os.Exit(1)
+	}
+
+	buf, err := ioutil.ReadFile(os.Args[1])
+	if err != nil {
+		fail(err)
+	}
+	key, err := utils.ParseSigningKey(buf)
+	if err != nil {
+		fail(err)
+	}
+
+	for _, path := range os.Args[2:] {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			fail(err)
+		}
+		sig := utils.Sign(key, data)
+		if err := ioutil.WriteFile(
+			path+".sig", []byte(sig+"\n"), 0644); err != nil {
+			fail(err)
+		}
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	os.Exit(1)
+}
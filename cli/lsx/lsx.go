@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -11,6 +12,8 @@ import (
 	"strings"
 	"time"
 
+	gofig "github.com/akutz/gofig/types"
+
 	"github.com/codedellemc/libstorage/api/context"
 	"github.com/codedellemc/libstorage/api/registry"
 	apitypes "github.com/codedellemc/libstorage/api/types"
@@ -23,41 +26,166 @@ import (
 )
 
 var cmdRx = regexp.MustCompile(
-	`(?i)^((?:un?)?mounts?|supported|instanceid|nextdevice|localdevices|wait)$`)
+	`(?i)^((?:un?)?mounts?|supported|instanceid|nextdevice|localdevices|wait|` +
+		`freeze|thaw|fsusage)$`)
+
+// errUsage is returned when a command's arguments are invalid.
+var errUsage = errors.New("invalid usage")
 
 // Run runs the executor CLI.
 func Run() {
 
-	args := os.Args
+	args, help := parseFlags(os.Args)
+	if help {
+		printHelpAndExit(args[1:])
+	}
+
+	if len(args) >= 2 {
+		switch strings.ToLower(args[1]) {
+		case cmdServe:
+			serve(args[2:])
+			return
+		case cmdHelp:
+			printHelpAndExit(args[2:])
+		case cmdCompletion:
+			printCompletionAndExit(args[2:])
+		}
+	}
+
 	if len(args) < 3 {
 		printUsageAndExit()
 	}
 
 	d, err := registry.NewStorageExecutor(args[1])
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
+		exitWithError("", err, apitypes.LSXExitCodeUsage)
 	}
 
-	driverName := strings.ToLower(d.Name())
-
 	config, err := apiconfig.NewConfig()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
+		exitWithError("", err, apitypes.LSXExitCodeError)
 	}
 
 	apiconfig.UpdateLogLevel(config)
 	ctx := context.Background()
 
+	if err := utils.InitExec(config); err != nil {
+		exitWithError("", err, apitypes.LSXExitCodeError)
+	}
+
+	if err := initChaos(); err != nil {
+		exitWithError("", err, apitypes.LSXExitCodeError)
+	}
+
 	if err := d.Init(ctx, config); err != nil {
+		exitWithError("", err, exitCodeForError(err))
+	}
+
+	op, result, exitCode, err := execute(ctx, config, d, args[2:])
+	if err == errUsage && !jsonOutput {
+		printUsageAndExit()
+	}
+
+	if err != nil {
+		if err != errUsage {
+			err = fmt.Errorf("error getting %s: %v", op, err)
+		}
+		exitWithError(op, err, exitCode)
+	}
+
+	encode := encodeResult
+	if jsonOutput {
+		encode = encodeResultJSON
+	}
+	buf, err := encode(result)
+	if err != nil {
+		exitWithError(op, fmt.Errorf(
+			"error encoding %s: %v", op, err), apitypes.LSXExitCodeError)
+	}
+	os.Stdout.Write(buf)
+
+	os.Exit(exitCode)
+}
+
+// jsonOutput is a flag indicating whether or not the results and errors of
+// the commands are written as JSON.
+var jsonOutput bool
+
+// parseFlags removes the global flags from the provided arguments, which may
+// be specified anywhere on the command line. The remaining arguments are
+// returned along with a flag indicating whether or not help was requested.
+func parseFlags(args []string) ([]string, bool) {
+	var (
+		help bool
+		rem  = []string{args[0]}
+	)
+	for _, a := range args[1:] {
+		switch a {
+		case "--json":
+			jsonOutput = true
+		case "-h", "-?", "--help":
+			help = true
+		default:
+			rem = append(rem, a)
+		}
+	}
+	return rem, help
+}
+
+// exitWithError writes the error to stderr, as a JSON object if the JSON
+// output mode is enabled, and exits with the provided exit code.
+func exitWithError(op string, err error, exitCode int) {
+	if jsonOutput {
+		buf, _ := json.Marshal(&struct {
+			Op       string `json:"op,omitempty"`
+			Error    string `json:"error"`
+			ExitCode int    `json:"exitCode"`
+		}{op, err.Error(), exitCode})
+		fmt.Fprintln(os.Stderr, string(buf))
+	} else {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
 	}
+	os.Exit(exitCode)
+}
+
+// exitCodeForError returns the exit code for the class of the error.
+func exitCodeForError(err error) int {
+	switch err.(type) {
+	case *apitypes.ErrNotFound:
+		return apitypes.LSXExitCodeNotFound
+	case *apitypes.ErrDeadlineExceeded:
+		return apitypes.LSXExitCodeTimedOut
+	}
+	switch {
+	case err == errUsage:
+		return apitypes.LSXExitCodeUsage
+	case err == apitypes.ErrTimedOut:
+		return apitypes.LSXExitCodeTimedOut
+	case strings.EqualFold(err.Error(), apitypes.ErrNotImplemented.Error()):
+		return apitypes.LSXExitCodeNotImplemented
+	case os.IsNotExist(err):
+		return apitypes.LSXExitCodeNotFound
+	case os.IsPermission(err):
+		return apitypes.LSXExitCodePermissionDenied
+	}
+	return apitypes.LSXExitCodeError
+}
+
+// execute runs the command, the first of the provided arguments, with the
+// executor. The name of the operation, its result, and the code with which
+// the executor CLI exits are returned. errUsage is returned if the command
+// or its arguments are invalid.
+func execute(
+	ctx apitypes.Context,
+	config gofig.Config,
+	d apitypes.StorageExecutor,
+	args []string) (string, interface{}, int, error) {
 
-	cmd := cmdRx.FindString(args[2])
+	driverName := strings.ToLower(d.Name())
+
+	cmd := cmdRx.FindString(args[0])
 	if cmd == "" {
-		printUsageAndExit()
+		return "", nil, apitypes.LSXExitCodeUsage, errUsage
 	}
 	store := utils.NewStore()
 
@@ -65,6 +193,7 @@ func Run() {
 		result   interface{}
 		op       string
 		exitCode int
+		err      error
 	)
 
 	if strings.EqualFold(cmd, apitypes.LSXCmdSupported) {
@@ -117,9 +246,9 @@ func Run() {
 				mountPath  string
 				mountOpts  = &apitypes.DeviceMountOpts{Opts: store}
 			)
-			mountArgs := args[3:]
+			mountArgs := args[1:]
 			if len(mountArgs) == 0 {
-				printUsageAndExit()
+				return op, nil, apitypes.LSXExitCodeUsage, errUsage
 			}
 
 			remArgs := []string{}
@@ -135,6 +264,10 @@ func Run() {
 						mountOpts.MountOptions = mountArgs[x+1]
 						x = x + 2
 						continue
+					case "-t":
+						mountOpts.FsType = mountArgs[x+1]
+						x = x + 2
+						continue
 					}
 				}
 				remArgs = append(remArgs, a)
@@ -142,7 +275,7 @@ func Run() {
 			}
 
 			if len(remArgs) != 2 {
-				printUsageAndExit()
+				return op, nil, apitypes.LSXExitCodeUsage, errUsage
 			}
 
 			deviceName = remArgs[0]
@@ -162,10 +295,10 @@ func Run() {
 		if !ok {
 			err = apitypes.ErrNotImplemented
 		} else {
-			if len(args) < 4 {
-				printUsageAndExit()
+			if len(args) < 2 {
+				return op, nil, apitypes.LSXExitCodeUsage, errUsage
 			}
-			mountPath := args[3]
+			mountPath := args[1]
 			opErr := dd.Unmount(ctx, mountPath, store)
 			if opErr != nil {
 				err = opErr
@@ -191,73 +324,104 @@ func Run() {
 			result = opResult
 		}
 	} else if strings.EqualFold(cmd, apitypes.LSXCmdLocalDevices) {
-		if len(args) < 4 {
-			printUsageAndExit()
+		if len(args) < 2 {
+			return apitypes.LSXCmdLocalDevices, nil, apitypes.LSXExitCodeUsage, errUsage
 		}
 		op = apitypes.LSXCmdLocalDevices
-		opResult, opErr := d.LocalDevices(ctx, &apitypes.LocalDevicesOpts{
-			ScanType: apitypes.ParseDeviceScanType(args[3]),
+		opResult, opErr := localDevices(ctx, d, &apitypes.LocalDevicesOpts{
+			ScanType: apitypes.ParseDeviceScanType(args[1]),
 			Opts:     store,
 		})
 		if opErr != nil {
 			err = opErr
 		} else {
+			if multipathEnabled(config) {
+				utils.ResolveMultipathDevices(opResult)
+			}
 			opResult.Driver = driverName
 			result = opResult
+
+			// described devices are written as JSON since the text
+			// format only includes the device map
+			if len(args) > 2 &&
+				strings.EqualFold(args[2], apitypes.LSXArgDescribeDevices) {
+				utils.DescribeLocalDevices(opResult)
+				buf, err := opResult.MarshalJSON()
+				if err != nil {
+					return op, nil, 1, err
+				}
+				result = json.RawMessage(buf)
+			}
+		}
+	} else if strings.EqualFold(cmd, apitypes.LSXCmdFreeze) ||
+		strings.EqualFold(cmd, apitypes.LSXCmdThaw) {
+		op = strings.ToLower(cmd)
+		if len(args) < 2 {
+			return op, nil, apitypes.LSXExitCodeUsage, errUsage
+		}
+		mountPath := args[1]
+
+		// the timeout of a freeze is when a safety thaw is performed and
+		// the timeout of a thaw is how long to wait before thawing
+		var timeout time.Duration
+		if len(args) > 2 {
+			d, perr := time.ParseDuration(args[2])
+			if perr != nil || d <= 0 {
+				return op, nil, apitypes.LSXExitCodeUsage, errUsage
+			}
+			timeout = d
+		}
+
+		// the token of a thaw identifies the freeze whose safety thaw it is
+		var token string
+		if len(args) > 3 {
+			token = args[3]
+		}
+
+		var opErr error
+		if op == apitypes.LSXCmdFreeze {
+			opErr = freezeFileSystem(driverName, mountPath, timeout)
+		} else {
+			opErr = thawFileSystem(mountPath, timeout, token)
+		}
+		if opErr != nil {
+			err = opErr
+		} else {
+			result = mountPath
+		}
+	} else if strings.EqualFold(cmd, apitypes.LSXCmdFileSystemUsage) {
+		op = apitypes.LSXCmdFileSystemUsage
+		if len(args) < 2 {
+			return op, nil, apitypes.LSXExitCodeUsage, errUsage
+		}
+		opResult, opErr := utils.FileSystemUsage(args[1])
+		if opErr != nil {
+			err = opErr
+		} else {
+			result = opResult
 		}
 	} else if strings.EqualFold(cmd, apitypes.LSXCmdWaitForDevice) {
-		if len(args) < 6 {
-			printUsageAndExit()
+		if len(args) < 4 {
+			return apitypes.LSXCmdWaitForDevice, nil, apitypes.LSXExitCodeUsage, errUsage
 		}
 		op = apitypes.LSXCmdWaitForDevice
 		opts := &apitypes.WaitForDeviceOpts{
 			LocalDevicesOpts: apitypes.LocalDevicesOpts{
-				ScanType: apitypes.ParseDeviceScanType(args[3]),
+				ScanType: apitypes.ParseDeviceScanType(args[1]),
 				Opts:     store,
 			},
-			Token:   strings.ToLower(args[4]),
-			Timeout: utils.DeviceAttachTimeout(args[5]),
-		}
-
-		ldl := func() (bool, *apitypes.LocalDevices, error) {
-			ldm, err := d.LocalDevices(ctx, &opts.LocalDevicesOpts)
-			if err != nil {
-				return false, nil, err
-			}
-			for k := range ldm.DeviceMap {
-				if strings.ToLower(k) == opts.Token {
-					return true, ldm, nil
-				}
-			}
-			return false, ldm, nil
+			Token:   strings.ToLower(args[2]),
+			Timeout: utils.DeviceAttachTimeout(args[3]),
 		}
 
-		var (
-			found    bool
-			opErr    error
-			opResult *apitypes.LocalDevices
-			timeoutC = time.After(opts.Timeout)
-			tick     = time.Tick(500 * time.Millisecond)
-		)
-
-	TimeoutLoop:
-
-		for {
-			select {
-			case <-timeoutC:
-				exitCode = apitypes.LSXExitCodeTimedOut
-				break TimeoutLoop
-			case <-tick:
-				if found, opResult, opErr = ldl(); found || opErr != nil {
-					break TimeoutLoop
-				}
-			}
+		found, opResult, opErr := waitForDevice(ctx, config, d, opts, nil)
+		if !found && opErr == nil {
+			exitCode = apitypes.LSXExitCodeTimedOut
 		}
 
 		if opErr != nil {
 			err = opErr
 		} else {
-			opResult.Driver = driverName
 			result = opResult
 		}
 	}
@@ -266,41 +430,127 @@ func Run() {
 		// if the function is not implemented then exit with
 		// apitypes.LSXExitCodeNotImplemented to let callers
 		// know that the function is unsupported on this system
-		exitCode = 1
-		if strings.EqualFold(err.Error(), apitypes.ErrNotImplemented.Error()) {
-			exitCode = apitypes.LSXExitCodeNotImplemented
+		exitCode = exitCodeForError(err)
+	}
+
+	return op, result, exitCode, err
+}
+
+// waitForDevice waits for the device with the provided token to be presented
+// to the host. The provided function, which may be nil, is called with the
+// devices found by each scan of the host's devices. A flag indicating
+// whether or not the device was found before the timeout elapsed is
+// returned along with the devices found by the last scan.
+func waitForDevice(
+	ctx apitypes.Context,
+	config gofig.Config,
+	d apitypes.StorageExecutor,
+	opts *apitypes.WaitForDeviceOpts,
+	progress func(ld *apitypes.LocalDevices)) (
+	bool, *apitypes.LocalDevices, error) {
+
+	driverName := strings.ToLower(d.Name())
+
+	ldl := func() (bool, *apitypes.LocalDevices, error) {
+		ldm, err := localDevices(ctx, d, &opts.LocalDevicesOpts)
+		if err != nil {
+			return false, nil, err
 		}
-		fmt.Fprintf(os.Stderr,
-			"error: error getting %s: %v\n", op, err)
-		os.Exit(exitCode)
+		ldm.Driver = driverName
+		if progress != nil {
+			progress(ldm)
+		}
+		for k := range ldm.DeviceMap {
+			if strings.ToLower(k) == opts.Token {
+				return true, ldm, nil
+			}
+		}
+		return false, ldm, nil
 	}
 
+	var ld *apitypes.LocalDevices
+	found, err := utils.WaitForDevice(
+		ctx, opts.Timeout, func() (bool, error) {
+			var (
+				ok  bool
+				err error
+			)
+			ok, ld, err = ldl()
+			return ok, err
+		})
+	if err != nil {
+		return false, nil, err
+	}
+
+	if found && multipathEnabled(config) {
+		waitForMultipathDevice(ctx, config, ld, opts.Token)
+		utils.ResolveMultipathDevices(ld)
+	}
+	return found, ld, nil
+}
+
+// encodeResultJSON encodes the result of a command as JSON, which is how it
+// is written to stdout when the JSON output mode is enabled.
+func encodeResultJSON(result interface{}) ([]byte, error) {
+	buf, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	if isNullBuf(buf) {
+		buf = emptyJSONBuff
+	}
+	return append(buf, newline), nil
+}
+
+// encodeResult encodes the result of a command as it is written to stdout.
+func encodeResult(result interface{}) ([]byte, error) {
 	switch tr := result.(type) {
 	case bool:
-		fmt.Fprintf(os.Stdout, "%v", result)
+		return []byte(fmt.Sprintf("%v", result)), nil
 	case string:
-		fmt.Fprintln(os.Stdout, result)
+		return []byte(fmt.Sprintln(result)), nil
 	case encoding.TextMarshaler:
-		buf, err := tr.MarshalText()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "error: error encoding %s: %v\n", op, err)
-			os.Exit(1)
-		}
-		os.Stdout.Write(buf)
+		return tr.MarshalText()
 	default:
 		buf, err := json.Marshal(result)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "error: error encoding %s: %v\n", op, err)
-			os.Exit(1)
+			return nil, err
 		}
 		if isNullBuf(buf) {
-			os.Stdout.Write(emptyJSONBuff)
-		} else {
-			os.Stdout.Write(buf)
+			return emptyJSONBuff, nil
 		}
+		return buf, nil
 	}
+}
 
-	os.Exit(exitCode)
+// multipathEnabled returns a flag indicating whether or not devices that are
+// paths of dm-multipath devices are resolved to the dm-multipath devices.
+func multipathEnabled(config gofig.Config) bool {
+	return config.GetBool(apitypes.ConfigExecutorMultipathEnabled)
+}
+
+// waitForMultipathDevice waits for the device with the provided token to be
+// coalesced into a dm-multipath device. Devices that are not coalesced before
+// the configured timeout are used as they are, since a device may be
+// attached with a single path.
+func waitForMultipathDevice(
+	ctx apitypes.Context,
+	config gofig.Config,
+	ld *apitypes.LocalDevices,
+	token string) {
+
+	timeout, err := time.ParseDuration(
+		config.GetString(apitypes.ConfigExecutorMultipathTimeout))
+	if err != nil {
+		timeout = 10 * time.Second
+	}
+
+	for k, v := range ld.DeviceMap {
+		if strings.ToLower(k) == token {
+			utils.WaitForMultipathDevice(ctx, v, timeout)
+			return
+		}
+	}
 }
 
 const (
@@ -340,12 +590,22 @@ func printUsage() {
 	fmt.Fprintf(w, "supported\n")
 	printUsageLeftPadded(w, lpad2, "instanceID\n")
 	printUsageLeftPadded(w, lpad2, "nextDevice\n")
-	printUsageLeftPadded(w, lpad2, "localDevices <scanType>\n")
+	printUsageLeftPadded(w, lpad2, "localDevices <scanType> [describe]\n")
 	printUsageLeftPadded(w, lpad2, "wait <scanType> <attachToken> <timeout>\n")
 	printUsageLeftPadded(w, lpad2, "mounts\n")
-	printUsageLeftPadded(w, lpad2, "mount [-l label] [-o options] device path\n")
+	printUsageLeftPadded(w, lpad2,
+		"mount [-l label] [-o options] [-t fstype] device path\n")
 	printUsageLeftPadded(w, lpad2, "umount path\n")
+	printUsageLeftPadded(w, lpad2, "freeze path [timeout]\n")
+	printUsageLeftPadded(w, lpad2, "thaw path [delay [token]]\n")
+	printUsageLeftPadded(w, lpad2, "fsUsage path\n")
+	printUsageLeftPadded(w, lpad1, "%s serve [endpoint]\n", os.Args[0])
+	printUsageLeftPadded(w, lpad1, "%s help [command]\n", os.Args[0])
+	printUsageLeftPadded(w, lpad1, "%s completion bash\n", os.Args[0])
 	fmt.Fprintln(w)
+	printUsageLeftPadded(w, lpad1,
+		"--json:      write results and errors as JSON\n\n")
+	printUsageLeftPadded(w, lpad1, "--help:      print a command's usage\n\n")
 	executorVar := "executor:    "
 	printUsageLeftPadded(w, lpad1, executorVar)
 	lpad3 := lpad1 + len(executorVar)
@@ -383,5 +643,5 @@ func printUsageLeftPadded(
 
 func printUsageAndExit() {
 	printUsage()
-	os.Exit(1)
+	os.Exit(apitypes.LSXExitCodeUsage)
 }
@@ -0,0 +1,167 @@
+package lsx
+
+import (
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/akutz/goof"
+
+	apitypes "github.com/codedellemc/libstorage/api/types"
+)
+
+// chaosEnvVar is the environment variable that enables the executor's chaos
+// mode, a test-only mode in which artificial delays and failures are
+// injected into the scans of the host's devices in order to validate how
+// clients and servers retry and time out. The value is a comma-separated
+// list of settings, ex.
+//
+//	LIBSTORAGE_LSX_CHAOS=delay=2s,missing=0.5,error=0.1,seed=1
+//
+//	delay   - the duration by which each scan is delayed
+//	missing - the probability, from 0 to 1, that a device is omitted from a
+//	          scan's results
+//	error   - the probability, from 0 to 1, that a scan fails
+//	seed    - the seed of the generator that decides which devices are
+//	          omitted and which scans fail; the current time by default
+const chaosEnvVar = "LIBSTORAGE_LSX_CHAOS"
+
+// chaos describes the faults injected into the scans of the host's devices.
+type chaos struct {
+	sync.Mutex
+	delay       time.Duration
+	missingRate float64
+	errorRate   float64
+	rand        *rand.Rand
+}
+
+// parseChaos parses the chaos mode settings. A nil value is returned if the
+// settings are empty.
+func parseChaos(settings string) (*chaos, error) {
+	settings = strings.TrimSpace(settings)
+	if settings == "" {
+		return nil, nil
+	}
+
+	c := &chaos{}
+	seed := time.Now().UnixNano()
+
+	for _, s := range strings.Split(settings, ",") {
+		kv := strings.SplitN(strings.TrimSpace(s), "=", 2)
+		if len(kv) != 2 {
+			return nil, goof.WithField("setting", s, "invalid chaos setting")
+		}
+		k, v := strings.ToLower(kv[0]), kv[1]
+
+		var err error
+		switch k {
+		case "delay":
+			c.delay, err = time.ParseDuration(v)
+		case "missing":
+			c.missingRate, err = parseProbability(v)
+		case "error":
+			c.errorRate, err = parseProbability(v)
+		case "seed":
+			seed, err = strconv.ParseInt(v, 10, 64)
+		default:
+			return nil, goof.WithField("setting", s, "invalid chaos setting")
+		}
+		if err != nil {
+			return nil, goof.WithFieldE(
+				"setting", s, "invalid chaos setting", err)
+		}
+	}
+
+	c.rand = rand.New(rand.NewSource(seed))
+	return c, nil
+}
+
+func parseProbability(v string) (float64, error) {
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, err
+	}
+	if f < 0 || f > 1 {
+		return 0, goof.WithField("probability", f, "invalid probability")
+	}
+	return f, nil
+}
+
+// executorChaos is the chaos mode of the executor, or nil if chaos mode is
+// disabled.
+var executorChaos *chaos
+
+// initChaos enables the executor's chaos mode if the chaos mode environment
+// variable is set.
+func initChaos() error {
+	c, err := parseChaos(os.Getenv(chaosEnvVar))
+	if err != nil || c == nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"delay":   c.delay,
+		"missing": c.missingRate,
+		"error":   c.errorRate,
+	}).Warn("executor chaos mode enabled")
+
+	executorChaos = c
+	return nil
+}
+
+// localDevices scans the host's devices with the executor. If chaos mode is
+// enabled then the scan is delayed, may fail, and may omit devices. Waiting
+// for a device scans the host's devices, so chaos mode also delays the wait
+// or causes it to time out.
+func localDevices(
+	ctx apitypes.Context,
+	d apitypes.StorageExecutor,
+	opts *apitypes.LocalDevicesOpts) (*apitypes.LocalDevices, error) {
+
+	c := executorChaos
+	if c == nil {
+		return d.LocalDevices(ctx, opts)
+	}
+
+	if c.delay > 0 {
+		timer := time.NewTimer(c.delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if c.chance(c.errorRate) {
+		ctx.Debug("chaos mode failed device scan")
+		return nil, goof.New("chaos mode device scan failure")
+	}
+
+	ld, err := d.LocalDevices(ctx, opts)
+	if err != nil || ld == nil {
+		return ld, err
+	}
+
+	for k := range ld.DeviceMap {
+		if c.chance(c.missingRate) {
+			ctx.WithField("device", k).Debug("chaos mode omitted device")
+			delete(ld.DeviceMap, k)
+		}
+	}
+	return ld, nil
+}
+
+// chance returns true with the provided probability.
+func (c *chaos) chance(p float64) bool {
+	if p <= 0 {
+		return false
+	}
+	c.Lock()
+	defer c.Unlock()
+	return c.rand.Float64() < p
+}
@@ -0,0 +1,133 @@
+package lsx
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/akutz/goof"
+
+	apitypes "github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+var (
+	// freezeTokenDir returns the directory that holds the tokens of the
+	// frozen file systems.
+	freezeTokenDir = func() string { return apitypes.Run.Join("freeze") }
+
+	// thaw thaws a file system. It is replaced by the tests.
+	thaw = utils.ThawFileSystem
+)
+
+// freezeFileSystem freezes the file system mounted at the provided path. If
+// a timeout is provided then a safety thaw is scheduled so the file system
+// is thawed once the timeout elapses even if the client that froze it fails
+// to thaw it. The safety thaw is performed by a detached executor process
+// since the executor CLI exits once the file system is frozen.
+//
+// Each freeze records a new token for the file system, and the safety thaw
+// only thaws the file system if the token is unchanged when the timeout
+// elapses. An explicit thaw removes the token, and freezing the file system
+// again replaces it, so a safety thaw never thaws a later freeze.
+func freezeFileSystem(
+	driverName, mountPath string, timeout time.Duration) error {
+
+	if err := utils.FreezeFileSystem(mountPath); err != nil {
+		return err
+	}
+	if timeout <= 0 {
+		removeFreezeToken(mountPath)
+		return nil
+	}
+
+	token, err := writeFreezeToken(mountPath)
+	if err != nil {
+		thaw(mountPath)
+		return goof.WithError("error scheduling safety thaw", err)
+	}
+
+	cmd := exec.Command(
+		os.Args[0],
+		driverName,
+		apitypes.LSXCmdThaw,
+		mountPath,
+		timeout.String(),
+		token)
+	if err := cmd.Start(); err != nil {
+		removeFreezeToken(mountPath)
+		thaw(mountPath)
+		return goof.WithError("error scheduling safety thaw", err)
+	}
+
+	// reap the safety thaw when the executor is running as a daemon
+	go cmd.Wait()
+	return nil
+}
+
+// thawFileSystem thaws the file system mounted at the provided path. An
+// explicit thaw, one without a token, cancels any pending safety thaw. A
+// safety thaw waits for the delay and then thaws the file system only if the
+// freeze that scheduled it, identified by the token, is still current.
+func thawFileSystem(
+	mountPath string, delay time.Duration, token string) error {
+
+	time.Sleep(delay)
+	if token == "" {
+		removeFreezeToken(mountPath)
+	} else if !claimFreezeToken(mountPath, token) {
+		return nil
+	}
+	return thaw(mountPath)
+}
+
+// freezeTokenPath returns the path of the file that holds the token of the
+// current freeze of the file system mounted at the provided path.
+func freezeTokenPath(mountPath string) string {
+	return path.Join(
+		freezeTokenDir(), fmt.Sprintf("%x", sha1.Sum([]byte(mountPath))))
+}
+
+// writeFreezeToken records a new token for the file system mounted at the
+// provided path.
+func writeFreezeToken(mountPath string) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := fmt.Sprintf("%x", buf)
+
+	p := freezeTokenPath(mountPath)
+	if err := os.MkdirAll(path.Dir(p), 0700); err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(p, []byte(token), 0600); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// claimFreezeToken removes the token of the file system mounted at the
+// provided path if it is the provided token. False is returned if the token
+// was removed or replaced.
+func claimFreezeToken(mountPath, token string) bool {
+	p := freezeTokenPath(mountPath)
+	buf, err := ioutil.ReadFile(p)
+	if err != nil || strings.TrimSpace(string(buf)) != token {
+		return false
+	}
+	os.Remove(p)
+	return true
+}
+
+// removeFreezeToken removes the token of the file system mounted at the
+// provided path, cancelling its pending safety thaw.
+func removeFreezeToken(mountPath string) {
+	os.Remove(freezeTokenPath(mountPath))
+}
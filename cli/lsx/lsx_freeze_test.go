@@ -0,0 +1,50 @@
+package lsx
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSafetyThaw(t *testing.T) {
+	d, err := ioutil.TempDir("", "")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(d)
+
+	defer func(f func() string, g func(string) error) {
+		freezeTokenDir, thaw = f, g
+	}(freezeTokenDir, thaw)
+	freezeTokenDir = func() string { return d }
+	thaws := 0
+	thaw = func(string) error {
+		thaws++
+		return nil
+	}
+
+	const mountPath = "/var/lib/libstorage/volumes/data"
+
+	// a safety thaw thaws the file system if it was not thawed
+	token, err := writeFreezeToken(mountPath)
+	assert.NoError(t, err)
+	assert.NoError(t, thawFileSystem(mountPath, 0, token))
+	assert.Equal(t, 1, thaws)
+
+	// but not if the file system was frozen again
+	token, err = writeFreezeToken(mountPath)
+	assert.NoError(t, err)
+	token2, err := writeFreezeToken(mountPath)
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, token2)
+	assert.NoError(t, thawFileSystem(mountPath, 0, token))
+	assert.Equal(t, 1, thaws)
+
+	// and an explicit thaw cancels the pending safety thaw
+	assert.NoError(t, thawFileSystem(mountPath, 0, ""))
+	assert.Equal(t, 2, thaws)
+	assert.NoError(t, thawFileSystem(mountPath, 0, token2))
+	assert.Equal(t, 2, thaws)
+}
@@ -0,0 +1,215 @@
+package lsx
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	apitypes "github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+const (
+	// cmdHelp is the command that prints the usage of the executor CLI or
+	// of one of its commands.
+	cmdHelp = "help"
+
+	// cmdCompletion is the command that prints a shell completion script.
+	cmdCompletion = "completion"
+)
+
+// commandHelp is the usage and description of a command.
+type commandHelp struct {
+	name  string
+	usage string
+	desc  string
+}
+
+// commands are the executor CLI's commands in the order in which their
+// usage is printed.
+var commands = []*commandHelp{
+	{
+		name:  apitypes.LSXCmdSupported,
+		usage: "<executor> supported",
+		desc: `Prints the operations the executor supports on the host as a bit
+mask, or 0 if the executor is not supported on the host.`,
+	},
+	{
+		name:  apitypes.LSXCmdInstanceID,
+		usage: "<executor> instanceID",
+		desc:  `Prints the host's instance ID.`,
+	},
+	{
+		name:  apitypes.LSXCmdNextDevice,
+		usage: "<executor> nextDevice",
+		desc: `Prints the name of the next available device, if the executor
+reserves device names.`,
+	},
+	{
+		name:  apitypes.LSXCmdLocalDevices,
+		usage: "<executor> localDevices <scanType> [describe]",
+		desc: `Prints the map of the host's devices. The scan type is 0 or quick,
+or 1 or deep. If describe is specified then the devices' sizes,
+serial numbers, and partitions are printed as well.`,
+	},
+	{
+		name:  apitypes.LSXCmdWaitForDevice,
+		usage: "<executor> wait <scanType> <attachToken> <timeout>",
+		desc: `Waits for the device with the attach token to be presented to the
+host and prints the map of the host's devices. Exits with 255 if
+the device is not presented before the timeout elapses.`,
+	},
+	{
+		name:  apitypes.LSXCmdMounts,
+		usage: "<executor> mounts",
+		desc:  `Prints the host's mounts.`,
+	},
+	{
+		name:  apitypes.LSXCmdMount,
+		usage: "<executor> mount [-l label] [-o options] [-t fstype] device path",
+		desc: `Mounts the device at the path with the SELinux label, mount
+options, and file system type, if specified.`,
+	},
+	{
+		name:  apitypes.LSXCmdUmount,
+		usage: "<executor> umount path",
+		desc:  `Unmounts the file system mounted at the path.`,
+	},
+	{
+		name:  apitypes.LSXCmdFreeze,
+		usage: "<executor> freeze path [timeout]",
+		desc: `Freezes the file system mounted at the path. The file system is
+thawed when the timeout elapses if it has not been thawed or frozen again.`,
+	},
+	{
+		name:  apitypes.LSXCmdThaw,
+		usage: "<executor> thaw path [delay [token]]",
+		desc: `Thaws the file system mounted at the path after the delay, if
+specified, elapses. A thaw with a token is the safety thaw of the freeze that
+recorded the token, and it does not thaw the file system if the file system
+was thawed or frozen again in the meantime. A thaw without a token cancels the
+pending safety thaw.`,
+	},
+	{
+		name:  apitypes.LSXCmdFileSystemUsage,
+		usage: "<executor> fsUsage path",
+		desc:  `Prints the usage of the file system mounted at the path.`,
+	},
+	{
+		name:  cmdServe,
+		usage: "serve [endpoint]",
+		desc: `Runs the executor as a daemon that serves the executor gRPC
+service at the endpoint, or at the libstorage.executor.endpoint
+property's address.`,
+	},
+	{
+		name:  cmdHelp,
+		usage: "help [command]",
+		desc:  `Prints the usage of the executor CLI or of the command.`,
+	},
+	{
+		name:  cmdCompletion,
+		usage: "completion bash",
+		desc: `Prints a bash completion script, ex.:
+
+    source <(%s completion bash)`,
+	},
+}
+
+// findCommand returns the help of the first of the arguments that is a
+// command.
+func findCommand(args []string) *commandHelp {
+	for _, a := range args {
+		if strings.EqualFold(a, "unmount") {
+			a = apitypes.LSXCmdUmount
+		}
+		for _, c := range commands {
+			if strings.EqualFold(a, c.name) {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// printHelpAndExit prints the usage of the first of the arguments that is a
+// command, or the usage of the executor CLI if there is no such command.
+func printHelpAndExit(args []string) {
+	c := findCommand(args)
+	if c == nil {
+		printUsage()
+		os.Exit(0)
+	}
+	fmt.Fprintf(os.Stdout, "usage: %s %s\n\n", os.Args[0], c.usage)
+	desc := strings.Replace(c.desc, "%s", os.Args[0], -1)
+	for _, line := range strings.Split(desc, "\n") {
+		fmt.Fprintf(os.Stdout, "  %s\n", line)
+	}
+	fmt.Fprintf(os.Stdout, "\n%s\n", exitCodesUsage)
+	os.Exit(0)
+}
+
+// printCompletionAndExit prints the shell completion script.
+func printCompletionAndExit(args []string) {
+	if len(args) != 1 || !strings.EqualFold(args[0], "bash") {
+		printUsageAndExit()
+	}
+
+	execNames := []string{}
+	for en := range executorNames() {
+		execNames = append(execNames, en)
+	}
+	cmdNames := []string{}
+	for _, c := range commands {
+		if c.name != cmdServe && c.name != cmdHelp && c.name != cmdCompletion {
+			cmdNames = append(cmdNames, c.name)
+		}
+	}
+
+	fmt.Fprintf(os.Stdout, bashCompletion,
+		strings.Join(utils.SortByString(execNames), " "),
+		strings.Join(cmdNames, " "),
+		filepath.Base(os.Args[0]))
+	os.Exit(0)
+}
+
+const (
+	exitCodesUsage = `exit codes: 0 success, 1 error, 2 not implemented,
+            3 not found, 4 permission denied, 64 invalid usage,
+            255 timed out`
+
+	bashCompletion = `_lsx_complete() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local cmds="%[2]s"
+    local words=""
+    COMPREPLY=()
+    if [ "$COMP_CWORD" -eq 1 ]; then
+        words="%[1]s serve help completion --json --help"
+    elif [ "$COMP_CWORD" -eq 2 ]; then
+        case "${COMP_WORDS[1]}" in
+        help) words="$cmds serve completion" ;;
+        completion) words="bash" ;;
+        serve) ;;
+        *) words="$cmds" ;;
+        esac
+    else
+        case "${COMP_WORDS[2]}" in
+        localDevices)
+            [ "$COMP_CWORD" -eq 3 ] && words="quick deep"
+            [ "$COMP_CWORD" -eq 4 ] && words="describe"
+            ;;
+        wait)
+            [ "$COMP_CWORD" -eq 3 ] && words="quick deep"
+            ;;
+        mount|umount|unmount|freeze|thaw|fsUsage)
+            COMPREPLY=( $(compgen -f -- "$cur") )
+            return 0
+            ;;
+        esac
+    fi
+    COMPREPLY=( $(compgen -W "$words" -- "$cur") )
+}
+complete -F _lsx_complete %[3]s
+`
+)
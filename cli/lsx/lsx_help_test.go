@@ -0,0 +1,88 @@
+package lsx
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apitypes "github.com/codedellemc/libstorage/api/types"
+)
+
+func TestFindCommand(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, ""},
+		{[]string{"vfs"}, ""},
+		{[]string{"vfs", "mount"}, apitypes.LSXCmdMount},
+		{[]string{"vfs", "MOUNTS"}, apitypes.LSXCmdMounts},
+		{[]string{"vfs", "unmount"}, apitypes.LSXCmdUmount},
+		{
+			[]string{"vfs", "localdevices", "quick"},
+			apitypes.LSXCmdLocalDevices,
+		},
+		{[]string{"serve"}, cmdServe},
+		{[]string{"help", "thaw"}, cmdHelp},
+		{[]string{"completion"}, cmdCompletion},
+	}
+
+	for _, tt := range tests {
+		c := findCommand(tt.args)
+		if tt.want == "" {
+			assert.Nil(t, c, "%v", tt.args)
+			continue
+		}
+		if assert.NotNil(t, c, "%v", tt.args) {
+			assert.Equal(t, tt.want, c.name, "%v", tt.args)
+		}
+	}
+}
+
+func TestCommandsHelp(t *testing.T) {
+	names := map[string]bool{}
+	for _, c := range commands {
+		assert.False(t, names[c.name], "duplicate command %s", c.name)
+		names[c.name] = true
+
+		assert.NotEmpty(t, c.desc, c.name)
+		assert.True(t,
+			strings.HasPrefix(c.usage, c.name) ||
+				strings.HasPrefix(c.usage, "<executor> "+c.name),
+			"usage of %s: %s", c.name, c.usage)
+
+		// the executor's commands are the ones the executor CLI runs
+		switch c.name {
+		case cmdServe, cmdHelp, cmdCompletion:
+			assert.False(t, cmdRx.MatchString(c.name), c.name)
+		default:
+			assert.True(t, cmdRx.MatchString(c.name), c.name)
+		}
+	}
+
+	for _, name := range []string{
+		apitypes.LSXCmdSupported,
+		apitypes.LSXCmdInstanceID,
+		apitypes.LSXCmdNextDevice,
+		apitypes.LSXCmdLocalDevices,
+		apitypes.LSXCmdWaitForDevice,
+		apitypes.LSXCmdMounts,
+		apitypes.LSXCmdMount,
+		apitypes.LSXCmdUmount,
+		apitypes.LSXCmdFreeze,
+		apitypes.LSXCmdThaw,
+		apitypes.LSXCmdFileSystemUsage,
+	} {
+		assert.True(t, names[name], "no help for %s", name)
+	}
+}
+
+func TestBashCompletion(t *testing.T) {
+	s := fmt.Sprintf(bashCompletion, "vfs", "mount umount", "lsx-linux")
+	assert.NotContains(t, s, "%!")
+	assert.Contains(t, s, `words="vfs serve help completion --json --help"`)
+	assert.Contains(t, s, `local cmds="mount umount"`)
+	assert.Contains(t, s, "complete -F _lsx_complete lsx-linux\n")
+}
@@ -0,0 +1,204 @@
+package lsx
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+	gofig "github.com/akutz/gofig/types"
+	gocontext "golang.org/x/net/context"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/lsxrpc"
+	"github.com/codedellemc/libstorage/api/registry"
+	apitypes "github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+	apiconfig "github.com/codedellemc/libstorage/api/utils/config"
+)
+
+// cmdServe is the command that runs the executor as a daemon.
+const cmdServe = "serve"
+
+// serve runs the executor as a daemon that serves the executor gRPC service
+// at the provided endpoint or, if no endpoint is provided, the configured
+// one.
+func serve(args []string) {
+
+	config, err := apiconfig.NewConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	apiconfig.UpdateLogLevel(config)
+
+	if err := initChaos(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	endpoint := config.GetString(apitypes.ConfigExecutorEndpoint)
+	if len(args) > 0 {
+		endpoint = args[0]
+	}
+	if endpoint == "" {
+		printUsageAndExit()
+	}
+
+	l, err := lsxrpc.Listen(endpoint)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	d := &daemon{
+		ctx:       context.Background(),
+		config:    config,
+		executors: map[string]apitypes.StorageExecutor{},
+	}
+
+	log.WithField("endpoint", endpoint).Info("serving executor")
+	if err := lsxrpc.NewServer(d).Serve(l); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// daemon serves the executor gRPC service. Executors are initialized the
+// first time they are used and are then reused for subsequent requests.
+// Commands are run one at a time, as they are when the executor binary is
+// invoked for each operation.
+type daemon struct {
+	sync.Mutex
+	ctx       apitypes.Context
+	config    gofig.Config
+	executors map[string]apitypes.StorageExecutor
+}
+
+// executor returns the initialized executor with the provided name. The
+// daemon must be locked.
+func (d *daemon) executor(name string) (apitypes.StorageExecutor, error) {
+	name = strings.ToLower(name)
+	if se, ok := d.executors[name]; ok {
+		return se, nil
+	}
+	se, err := registry.NewStorageExecutor(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := se.Init(d.ctx, d.config); err != nil {
+		return nil, err
+	}
+	d.executors[name] = se
+	return se, nil
+}
+
+func (d *daemon) Exec(
+	ctx gocontext.Context,
+	req *lsxrpc.ExecRequest) (*lsxrpc.ExecResponse, error) {
+
+	if len(req.Args) < 2 {
+		return &lsxrpc.ExecResponse{
+			ExitCode: apitypes.LSXExitCodeUsage,
+			Error:    errUsage.Error(),
+		}, nil
+	}
+
+	d.Lock()
+	defer d.Unlock()
+
+	se, err := d.executor(req.Args[0])
+	if err != nil {
+		return &lsxrpc.ExecResponse{
+			ExitCode: exitCodeForError(err),
+			Error:    err.Error(),
+		}, nil
+	}
+
+	op, result, exitCode, err := execute(
+		context.New(ctx), d.config, se, req.Args[1:])
+	if err != nil {
+		if err != errUsage {
+			err = fmt.Errorf("error getting %s: %v", op, err)
+		}
+		return &lsxrpc.ExecResponse{
+			ExitCode: exitCode,
+			Error:    err.Error(),
+		}, nil
+	}
+
+	buf, err := encodeResult(result)
+	if err != nil {
+		return &lsxrpc.ExecResponse{
+			ExitCode: 1,
+			Error:    fmt.Sprintf("error encoding %s: %v", op, err),
+		}, nil
+	}
+	return &lsxrpc.ExecResponse{Output: buf, ExitCode: exitCode}, nil
+}
+
+// Wait waits for a device, sending the devices found by each scan of the
+// host's devices to the client. The daemon is locked only while the devices
+// are scanned so that other commands may run during the wait.
+func (d *daemon) Wait(
+	req *lsxrpc.WaitRequest, stream lsxrpc.WaitServer) error {
+
+	d.Lock()
+	se, err := d.executor(req.Driver)
+	d.Unlock()
+	if err != nil {
+		return stream.Send(&lsxrpc.WaitEvent{
+			Done:     true,
+			ExitCode: exitCodeForError(err),
+			Error:    err.Error(),
+		})
+	}
+
+	opts := &apitypes.WaitForDeviceOpts{
+		LocalDevicesOpts: apitypes.LocalDevicesOpts{
+			ScanType: req.ScanType,
+			Opts:     utils.NewStore(),
+		},
+		Token:   strings.ToLower(req.Token),
+		Timeout: utils.DeviceAttachTimeout(req.Timeout),
+	}
+
+	locked := &lockedExecutor{StorageExecutor: se, lock: &d.Mutex}
+	found, ld, err := waitForDevice(
+		context.New(stream.Context()), d.config, locked, opts,
+		func(ld *apitypes.LocalDevices) {
+			if err := stream.Send(
+				&lsxrpc.WaitEvent{LocalDevices: ld}); err != nil {
+				d.ctx.WithError(err).Debug("error sending wait event")
+			}
+		})
+
+	event := &lsxrpc.WaitEvent{
+		Done:         true,
+		Matched:      found,
+		LocalDevices: ld,
+	}
+	if err != nil {
+		event.ExitCode = exitCodeForError(err)
+		event.Error = err.Error()
+	} else if !found {
+		event.ExitCode = apitypes.LSXExitCodeTimedOut
+	}
+	return stream.Send(event)
+}
+
+// lockedExecutor locks the daemon while it scans the host's devices.
+type lockedExecutor struct {
+	apitypes.StorageExecutor
+	lock sync.Locker
+}
+
+func (e *lockedExecutor) LocalDevices(
+	ctx apitypes.Context,
+	opts *apitypes.LocalDevicesOpts) (*apitypes.LocalDevices, error) {
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.StorageExecutor.LocalDevices(ctx, opts)
+}
@@ -0,0 +1,44 @@
+package lsx
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/akutz/goof"
+	"github.com/stretchr/testify/assert"
+
+	apitypes "github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+func TestExitCodeForError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{goof.New("error"), apitypes.LSXExitCodeError},
+		{errUsage, apitypes.LSXExitCodeUsage},
+		{apitypes.ErrNotImplemented, apitypes.LSXExitCodeNotImplemented},
+		{goof.New("Not Implemented"), apitypes.LSXExitCodeNotImplemented},
+		{utils.NewNotFoundError("vfs-000"), apitypes.LSXExitCodeNotFound},
+		{utils.NewVolumeNotFoundError("vfs-000"), apitypes.LSXExitCodeNotFound},
+		{
+			&os.PathError{Op: "open", Path: "/dev/xvdb", Err: os.ErrNotExist},
+			apitypes.LSXExitCodeNotFound,
+		},
+		{
+			&os.PathError{Op: "open", Path: "/dev/xvdb", Err: os.ErrPermission},
+			apitypes.LSXExitCodePermissionDenied,
+		},
+		{apitypes.ErrTimedOut, apitypes.LSXExitCodeTimedOut},
+		{
+			utils.NewDeadlineExceededError(time.Now(), nil),
+			apitypes.LSXExitCodeTimedOut,
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, exitCodeForError(tt.err), tt.err.Error())
+	}
+}
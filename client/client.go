@@ -55,6 +55,9 @@ func New(goCtx gocontext.Context, config gofig.Config) (types.Client, error) {
 
 	// always update the server context's log level
 	context.SetLogLevel(c.ctx, logConfig.Level)
+	context.SetLogFormatter(c.ctx, logConfig.Formatter())
+	c.ctx = context.WithComponent(
+		c.ctx.WithValue(context.LogLevelsKey, logConfig.Levels), "client")
 	c.ctx.WithFields(logFields).Info("configured logging")
 
 	if config.IsSet(types.ConfigService) {
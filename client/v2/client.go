@@ -0,0 +1,198 @@
+// Package client provides version 2 of the libStorage Go client. Its
+// functions take a context.Context as their first argument, plain values in
+// place of pointers, and option structs whose zero values are the defaults.
+package client
+
+import (
+	gofig "github.com/akutz/gofig/types"
+	"golang.org/x/net/context"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+	"github.com/codedellemc/libstorage/api/v2"
+	v1 "github.com/codedellemc/libstorage/client"
+)
+
+// Client is a version 2 libStorage client.
+type Client struct {
+	c types.Client
+}
+
+// New returns a new version 2 libStorage client.
+func New(ctx context.Context, config gofig.Config) (*Client, error) {
+	c, err := v1.New(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	return Wrap(c), nil
+}
+
+// Wrap returns a version 2 client that wraps a version 1 client.
+func Wrap(c types.Client) *Client {
+	return &Client{c: c}
+}
+
+// V1 returns the wrapped version 1 client.
+func (c *Client) V1() types.Client {
+	return c.c
+}
+
+// Storage returns the client's storage driver adapted to the version 2
+// driver interface.
+func (c *Client) Storage() v2.StorageDriver {
+	return v2.FromV1(c.c.Storage())
+}
+
+// Services returns information about the configured storage services.
+func (c *Client) Services(
+	ctx context.Context) (map[string]*types.ServiceInfo, error) {
+
+	return c.c.API().Services(v2.Context(ctx))
+}
+
+// ServiceInspect returns information about a storage service.
+func (c *Client) ServiceInspect(
+	ctx context.Context, service string) (*types.ServiceInfo, error) {
+
+	return c.c.API().ServiceInspect(v2.Context(ctx), service)
+}
+
+// Volumes returns the volumes of a storage service ordered by their IDs.
+func (c *Client) Volumes(
+	ctx context.Context,
+	service string,
+	opts v2.VolumesOptions) ([]*types.Volume, error) {
+
+	vols, err := c.c.API().VolumesByService(
+		v2.Context(ctx), service, opts.Attachments)
+	if err != nil {
+		return nil, err
+	}
+	objs := make([]*types.Volume, 0, len(vols))
+	for _, v := range vols {
+		objs = append(objs, v)
+	}
+	return utils.SortVolumeByID(objs), nil
+}
+
+// VolumeInspect returns a volume.
+func (c *Client) VolumeInspect(
+	ctx context.Context,
+	service, volumeID string,
+	opts v2.VolumeInspectOptions) (*types.Volume, error) {
+
+	return c.c.API().VolumeInspect(
+		v2.Context(ctx), service, volumeID, opts.Attachments)
+}
+
+// VolumeCreate creates a volume.
+func (c *Client) VolumeCreate(
+	ctx context.Context,
+	service, name string,
+	opts v2.VolumeCreateOptions) (*types.Volume, error) {
+
+	return c.c.API().VolumeCreate(
+		v2.Context(ctx), service, opts.V1Request(name))
+}
+
+// VolumeCreateFromSnapshot creates a volume from a snapshot.
+func (c *Client) VolumeCreateFromSnapshot(
+	ctx context.Context,
+	service, snapshotID, name string,
+	opts v2.VolumeCreateOptions) (*types.Volume, error) {
+
+	return c.c.API().VolumeCreateFromSnapshot(
+		v2.Context(ctx), service, snapshotID, opts.V1Request(name))
+}
+
+// VolumeRemove removes a volume.
+func (c *Client) VolumeRemove(
+	ctx context.Context,
+	service, volumeID string,
+	opts v2.VolumeRemoveOptions) error {
+
+	return c.c.API().VolumeRemove(
+		v2.Context(ctx), service, volumeID, opts.Force)
+}
+
+// VolumeAttach attaches a volume to the client's instance. The token with
+// which the volume's device is found is returned with the volume.
+func (c *Client) VolumeAttach(
+	ctx context.Context,
+	service, volumeID string,
+	opts v2.VolumeAttachOptions) (*types.Volume, string, error) {
+
+	return c.c.API().VolumeAttach(
+		v2.Context(ctx), service, volumeID, opts.V1Request())
+}
+
+// VolumeDetach detaches a volume from the client's instance.
+func (c *Client) VolumeDetach(
+	ctx context.Context,
+	service, volumeID string,
+	opts v2.VolumeDetachOptions) (*types.Volume, error) {
+
+	return c.c.API().VolumeDetach(
+		v2.Context(ctx), service, volumeID, opts.V1Request())
+}
+
+// VolumeSnapshot snapshots a volume.
+func (c *Client) VolumeSnapshot(
+	ctx context.Context,
+	service, volumeID, snapshotName string,
+	opts v2.VolumeSnapshotOptions) (*types.Snapshot, error) {
+
+	return c.c.API().VolumeSnapshot(
+		v2.Context(ctx), service, volumeID, opts.V1Request(snapshotName))
+}
+
+// VolumeMount attaches, formats if necessary, and mounts a volume by its
+// name. The path at which the volume is mounted is returned with the volume.
+func (c *Client) VolumeMount(
+	ctx context.Context,
+	volumeName string,
+	opts v2.VolumeMountOptions) (string, *types.Volume, error) {
+
+	return c.c.Integration().Mount(
+		v2.Context(ctx), "", volumeName, opts.V1())
+}
+
+// VolumeUnmount unmounts a volume by its name.
+func (c *Client) VolumeUnmount(
+	ctx context.Context,
+	volumeName string,
+	opts v2.Options) (*types.Volume, error) {
+
+	return c.c.Integration().Unmount(
+		v2.Context(ctx), "", volumeName, opts.Store())
+}
+
+// Snapshots returns the snapshots of a storage service ordered by their IDs.
+func (c *Client) Snapshots(
+	ctx context.Context, service string) ([]*types.Snapshot, error) {
+
+	snaps, err := c.c.API().SnapshotsByService(v2.Context(ctx), service)
+	if err != nil {
+		return nil, err
+	}
+	objs := make([]*types.Snapshot, 0, len(snaps))
+	for _, s := range snaps {
+		objs = append(objs, s)
+	}
+	return utils.SortSnapshotByID(objs), nil
+}
+
+// SnapshotInspect returns a snapshot.
+func (c *Client) SnapshotInspect(
+	ctx context.Context,
+	service, snapshotID string) (*types.Snapshot, error) {
+
+	return c.c.API().SnapshotInspect(v2.Context(ctx), service, snapshotID)
+}
+
+// SnapshotRemove removes a snapshot.
+func (c *Client) SnapshotRemove(
+	ctx context.Context, service, snapshotID string) error {
+
+	return c.c.API().SnapshotRemove(v2.Context(ctx), service, snapshotID)
+}
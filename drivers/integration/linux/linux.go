@@ -3,6 +3,7 @@ package linux
 import (
 	"os"
 	"strings"
+	"sync"
 
 	"fmt"
 
@@ -10,6 +11,7 @@ import (
 
 	gofig "github.com/akutz/gofig/types"
 	"github.com/akutz/goof"
+	"github.com/akutz/gotil"
 
 	"github.com/codedellemc/libstorage/api/context"
 	"github.com/codedellemc/libstorage/api/registry"
@@ -21,10 +23,26 @@ import (
 const (
 	providerName            = "linux"
 	defaultVolumeSize int64 = 16
+
+	// selinuxLabelShared is the Docker-style option to label a volume with
+	// the label shared by all containers.
+	selinuxLabelShared = "z"
+
+	// selinuxLabelPrivate is the Docker-style option to label a volume with
+	// the private label of the container that uses the volume.
+	selinuxLabelPrivate = "Z"
+
+	// selinuxSharedLabel is the SELinux context shared by all containers.
+	selinuxSharedLabel = "system_u:object_r:svirt_sandbox_file_t:s0"
 )
 
 type driver struct {
 	config gofig.Config
+
+	// deviceSizes are the sizes of the devices whose file systems were
+	// grown to fill them
+	deviceSizes     map[string]int64
+	deviceSizesLock sync.Mutex
 }
 
 type volumeMapping struct {
@@ -50,12 +68,16 @@ func init() {
 }
 
 func newDriver() types.IntegrationDriver {
-	return &driver{}
+	return &driver{deviceSizes: map[string]int64{}}
 }
 
 func (d *driver) Init(ctx types.Context, config gofig.Config) error {
 	d.config = config
 
+	if err := d.validateFsckConfig(); err != nil {
+		return err
+	}
+
 	ctx.WithFields(log.Fields{
 		types.ConfigIgVolOpsMountRootPath:       d.volumeRootPath(),
 		types.ConfigIgVolOpsCreateDefaultType:   d.volumeType(),
@@ -65,6 +87,10 @@ func (d *driver) Init(ctx types.Context, config gofig.Config) error {
 		types.ConfigIgVolOpsCreateDefaultFsType: d.fsType(),
 		types.ConfigIgVolOpsMountPath:           d.mountDirPath(),
 		types.ConfigIgVolOpsCreateImplicit:      d.volumeCreateImplicit(),
+		types.ConfigIgVolOpsMountEncrypt:        d.encrypt(),
+		types.ConfigIgVolOpsMountFsckPolicy:     d.fsckPolicy(),
+		types.ConfigIgVolOpsMountFsckAction:     d.fsckAction(),
+		types.ConfigIgVolOpsMountGrowFS:         d.growFS(),
 	}).Info("linux integration driver successfully initialized")
 
 	return nil
@@ -105,6 +131,7 @@ func (d *driver) List(
 		vs["type"] = v.Type
 		vs["availabilityZone"] = v.AvailabilityZone
 		vs["fields"] = v.Fields
+		vs["accessMode"] = volumeAccessMode(v)
 		vs["service"] = serviceName
 		vs["server"] = serviceName
 		volMaps = append(volMaps, &volumeMapping{
@@ -143,7 +170,7 @@ func (d *driver) Inspect(
 	}
 
 	if obj == nil {
-		return nil, utils.NewNotFoundError(volumeName)
+		return nil, utils.NewVolumeNotFoundError(volumeName)
 	}
 
 	fields = log.Fields{
@@ -167,6 +194,16 @@ func (d *driver) Mount(
 		"volumeID":   volumeID,
 		"opts":       opts}).Info("mounting volume")
 
+	switch opts.AccessMode {
+	case "", types.VolumeAccessModeFileSystem, types.VolumeAccessModeRaw:
+	default:
+		return "", nil, goof.WithField(
+			"accessMode", opts.AccessMode, "invalid access mode")
+	}
+	if _, err := volumeOwnership(&types.Volume{}, opts); err != nil {
+		return "", nil, err
+	}
+
 	lsAtt := types.VolAttReqWithDevMapOnlyVolsAttachedToInstanceOrUnattachedVols
 	if opts.Preempt {
 		lsAtt = types.VolAttReqWithDevMapForInstance
@@ -201,17 +238,28 @@ func (d *driver) Mount(
 		ctx.Debug("performing precautionary unmount")
 		_ = client.OS().Unmount(ctx, mp, opts.Opts)
 
+		context.RecordMountStep(ctx, types.MountStepAttach, vol.ID)
+
+		accessMode := types.AttachAccessModeReadWrite
+		if opts.ReadOnly {
+			accessMode = types.AttachAccessModeReadOnly
+		}
+
 		var token string
 		vol, token, err = client.Storage().VolumeAttach(
 			ctx, vol.ID, &types.VolumeAttachOpts{
-				Force: opts.Preempt,
-				Opts:  utils.NewStore(),
+				Force:      opts.Preempt,
+				AccessMode: accessMode,
+				Opts:       utils.NewStore(),
 			})
 		if err != nil {
 			return "", nil, err
 		}
 
-		if token != "" {
+		context.RecordMountToken(
+			ctx, vol.ID, vol.Fields[types.VolumeFieldMountToken])
+
+		for _, token := range attachTokens(vol, token) {
 			opts := &types.WaitForDeviceOpts{
 				LocalDevicesOpts: types.LocalDevicesOpts{
 					ScanType: apiconfig.DeviceScanType(d.config),
@@ -221,6 +269,7 @@ func (d *driver) Mount(
 				Timeout: apiconfig.DeviceAttachTimeout(d.config),
 			}
 
+			context.RecordMountStep(ctx, types.MountStepWaitForDevice, "")
 			_, _, err = client.Executor().WaitForDevice(ctx, opts)
 			if err != nil {
 				return "", nil, goof.WithError(
@@ -260,27 +309,95 @@ func (d *driver) Mount(
 		return "", nil, goof.New("no device name returned")
 	}
 
+	if isHelperMounted(ma) {
+		return d.helperMount(ctx, vol, ma, opts)
+	}
+
+	// the devices of a mirrored volume are assembled into a local mirror
+	// whose device is used in place of the attachment's device
+	attachedDevice := ma.DeviceName
+	if isMirroredVolume(vol) {
+		if attachedDevice, err = d.assembleMirror(
+			ctx, vol, ma, opts.Opts); err != nil {
+			return "", nil, err
+		}
+	}
+
+	device, err := d.openEncryptedDevice(ctx, vol, attachedDevice, opts)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// a raw volume is neither formatted nor mounted, and the path of its
+	// device is returned in place of a mount point. A volume is raw if its
+	// access mode is recorded as raw or if the mount request specifies it.
+	if isRawVolume(vol) || opts.AccessMode == types.VolumeAccessModeRaw {
+		ctx.WithFields(log.Fields{
+			"vol":    vol,
+			"device": device,
+		}).Info("raw volume attached")
+		device = d.stableDevicePath(ma, device)
+		ma.DevicePath = device
+		d.stableDeviceNames(vol)
+		return device, vol, nil
+	}
+
 	mounts, err := client.OS().Mounts(
-		ctx, ma.DeviceName, "", opts.Opts)
+		ctx, device, "", opts.Opts)
 	if err != nil {
 		return "", nil, err
 	}
 
 	if len(mounts) > 0 {
+		if !opts.ReadOnly && d.growFS() {
+			d.growMountedFileSystem(ctx, vol, attachedDevice, mounts[0])
+		}
+		d.stableDeviceNames(vol)
 		return d.volumeMountPath(mounts[0].MountPoint), vol, nil
 	}
 
+	// the file system recorded when the volume was created is used unless
+	// the mount request specifies one
+	if opts.NewFSType == "" {
+		opts.NewFSType = vol.Fields[types.VolumeFieldFSType]
+	}
 	if opts.NewFSType == "" {
 		opts.NewFSType = d.fsType()
 	}
-	if err := client.OS().Format(
-		ctx,
-		ma.DeviceName,
-		&types.DeviceFormatOpts{
-			NewFSType:   opts.NewFSType,
-			OverwriteFS: opts.OverwriteFS,
-		}); err != nil {
-		return "", nil, err
+	if opts.NewFSOpts == nil {
+		opts.NewFSOpts = strings.Fields(vol.Fields[types.VolumeFieldMkfsOpts])
+	}
+
+	// a read-only volume is neither checked nor formatted, since either
+	// could write to its device
+	if opts.ReadOnly {
+		ok, err := hasFileSystem(ctx, device)
+		if err != nil {
+			return "", nil, err
+		}
+		if !ok {
+			return "", nil, goof.WithField("device", device,
+				"cannot mount read-only volume without file system")
+		}
+	} else {
+		// a device that will be overwritten does not need to be checked
+		if !opts.OverwriteFS {
+			if err := d.checkFileSystem(ctx, device); err != nil {
+				return "", nil, err
+			}
+		}
+
+		context.RecordMountStep(ctx, types.MountStepFormat, device)
+		if err := client.OS().Format(
+			ctx,
+			device,
+			&types.DeviceFormatOpts{
+				NewFSType:   opts.NewFSType,
+				NewFSOpts:   opts.NewFSOpts,
+				OverwriteFS: opts.OverwriteFS,
+			}); err != nil {
+			return "", nil, err
+		}
 	}
 
 	mountPath, err := d.getVolumeMountPath(vol.Name)
@@ -292,22 +409,63 @@ func (d *driver) Mount(
 		return "", nil, err
 	}
 
+	svcMountOpts, err := d.serviceMountOptions(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	mountOpts := mergeMountOptions(
+		d.mountOptions(),
+		svcMountOpts,
+		vol.Fields[types.VolumeFieldMountOpts],
+		opts.MountOptions)
+
+	mountLabel, err := d.mountLabel(vol, opts)
+	if err != nil {
+		return "", nil, err
+	}
+
+	context.RecordMountStep(ctx, types.MountStepMount, mountPath)
 	if err := client.OS().Mount(
 		ctx,
-		ma.DeviceName,
+		device,
 		mountPath,
-		&types.DeviceMountOpts{}); err != nil {
+		&types.DeviceMountOpts{
+			MountOptions: mountOpts,
+			MountLabel:   mountLabel,
+			ReadOnly:     opts.ReadOnly,
+		}); err != nil {
 		return "", nil, err
 	}
 
 	mntPath := d.volumeMountPath(mountPath)
 
+	if !opts.ReadOnly {
+		if d.growFS() {
+			mounts, err := client.OS().Mounts(ctx, "", mountPath, opts.Opts)
+			if err != nil {
+				return "", nil, err
+			}
+			if len(mounts) > 0 {
+				d.growMountedFileSystem(ctx, vol, attachedDevice, mounts[0])
+			}
+		}
+
+		ownership, err := volumeOwnership(vol, opts)
+		if err != nil {
+			return "", nil, err
+		}
+		if err := applyOwnership(ctx, ownership, mntPath); err != nil {
+			return "", nil, err
+		}
+	}
+
 	fields := log.Fields{
 		"vol":     vol,
 		"mntPath": mntPath,
 	}
 	ctx.WithFields(fields).Info("volume mounted")
 
+	d.stableDeviceNames(vol)
 	return mntPath, vol, nil
 }
 
@@ -359,8 +517,28 @@ func (d *driver) Unmount(
 		return nil, goof.New("no device name found for attachment")
 	}
 
+	// an encrypted volume is mounted from its dm-crypt mapping, and a
+	// mirrored volume from its local mirror
+	device := ma.DeviceName
+	if isMirroredVolume(vol) {
+		device = mirrorDevicePath(vol)
+	}
+	if gotil.FileExists(cryptDevicePath(vol)) {
+		device = cryptDevicePath(vol)
+	}
+
+	// a volume mounted by a mount helper is found by its mount point since
+	// the helper may have mounted a device other than the attachment's
+	mountPath := ""
+	if isHelperMounted(ma) {
+		device = ""
+		if mountPath, err = d.getVolumeMountPath(vol.Name); err != nil {
+			return nil, err
+		}
+	}
+
 	mounts, err := client.OS().Mounts(
-		ctx, ma.DeviceName, "", opts)
+		ctx, device, mountPath, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -379,6 +557,16 @@ func (d *driver) Unmount(
 		}
 	}
 
+	if err := d.closeEncryptedDevice(ctx, vol); err != nil {
+		return nil, err
+	}
+
+	if isMirroredVolume(vol) {
+		if err := d.stopMirror(ctx, vol, opts); err != nil {
+			return nil, err
+		}
+	}
+
 	vol, err = client.Storage().VolumeDetach(ctx, vol.ID,
 		&types.VolumeDetachOpts{
 			Force: opts.GetBool("force"),
@@ -410,7 +598,7 @@ func (d *driver) Path(
 	if err != nil {
 		return "", err
 	} else if vol == nil {
-		return "", utils.NewNotFoundError(
+		return "", utils.NewVolumeNotFoundError(
 			fmt.Sprintf("volumeID=%s,volumeName=%s", volumeID, volumeName))
 	}
 
@@ -420,8 +608,17 @@ func (d *driver) Path(
 
 	client := context.MustClient(ctx)
 
-	mounts, err := client.OS().Mounts(
-		ctx, vol.Attachments[0].DeviceName, "", opts)
+	// an encrypted volume is mounted from its dm-crypt mapping
+	device := vol.Attachments[0].DeviceName
+	if gotil.FileExists(cryptDevicePath(vol)) {
+		device = cryptDevicePath(vol)
+	}
+
+	if isRawVolume(vol) {
+		return d.stableDevicePath(vol.Attachments[0], device), nil
+	}
+
+	mounts, err := client.OS().Mounts(ctx, device, "", opts)
 	if err != nil {
 		return "", err
 	}
@@ -460,6 +657,7 @@ func (d *driver) Create(
 	optsNew.IOPS = &iops
 	optsNew.Encrypted = opts.Encrypted
 	optsNew.EncryptionKey = opts.EncryptionKey
+	optsNew.Thin = opts.Thin
 
 	if opts.Opts.IsSet("availabilityZone") {
 		az = opts.Opts.GetString("availabilityZone")
@@ -476,6 +674,40 @@ func (d *driver) Create(
 	if opts.Opts.IsSet("iops") {
 		iops = opts.Opts.GetInt64("iops")
 	}
+	if opts.Opts.IsSet("thin") {
+		thin := opts.Opts.GetBool("thin")
+		optsNew.Thin = &thin
+	}
+
+	// the access mode, file system, and mkfs options are sent with the
+	// request to be recorded with the volume, and the server rejects the
+	// request if the storage driver cannot record them. The defaults are not
+	// recorded so that volumes may be created by any storage driver.
+	accessMode := types.VolumeAccessModeFileSystem
+	if opts.Opts.IsSet(types.VolumeFieldAccessMode) {
+		accessMode = opts.Opts.GetString(types.VolumeFieldAccessMode)
+	}
+	switch accessMode {
+	case types.VolumeAccessModeFileSystem:
+		opts.Opts = withoutKey(opts.Opts, types.VolumeFieldAccessMode)
+	case types.VolumeAccessModeRaw:
+	default:
+		return nil, goof.WithField(
+			"accessMode", accessMode, "invalid access mode")
+	}
+
+	fsType := d.fsType()
+	if opts.Opts.IsSet(types.VolumeFieldFSType) {
+		fsType = opts.Opts.GetString(types.VolumeFieldFSType)
+		if !isSupportedFSType(fsType) {
+			return nil, goof.WithField(
+				"fsType", fsType, "unsupported file system")
+		}
+	}
+
+	if err := normalizeOwnershipOpts(opts.Opts); err != nil {
+		return nil, err
+	}
 
 	optsNew.Opts = opts.Opts
 
@@ -485,8 +717,11 @@ func (d *driver) Create(
 		"size":             size,
 		"volumeType":       volumeType,
 		"IOPS":             iops,
+		"accessMode":       accessMode,
+		"fsType":           fsType,
 		"encrypted":        optsNew.Encrypted,
 		"encryptionKey":    optsNew.EncryptionKey,
+		"thin":             optsNew.Thin,
 		"opts":             opts}).Info("creating volume")
 
 	client := context.MustClient(ctx)
@@ -586,3 +821,76 @@ func (d *driver) mountDirPath() string {
 func (d *driver) volumeCreateImplicit() bool {
 	return d.config.GetBool(types.ConfigIgVolOpsCreateImplicit)
 }
+
+func (d *driver) mountOptions() string {
+	return d.config.GetString(types.ConfigIgVolOpsMountOptions)
+}
+
+// serviceMountOptions returns the default mount options configured on the
+// server for the service in the context, if any.
+func (d *driver) serviceMountOptions(ctx types.Context) (string, error) {
+	serviceName, ok := context.ServiceName(ctx)
+	if !ok {
+		return "", nil
+	}
+	si, err := context.MustClient(ctx).API().ServiceInspect(ctx, serviceName)
+	if err != nil {
+		return "", goof.WithFieldE(
+			"service", serviceName, "error getting service mount options", err)
+	}
+	return si.MountOptions, nil
+}
+
+// mountLabel returns the SELinux context with which to mount the volume. The
+// label in the mount request takes precedence over the label recorded with
+// the volume, which takes precedence over the configured label. The label
+// "z" is replaced with the label shared by all containers, and the label "Z"
+// requires the mount request to provide a container's private label.
+func (d *driver) mountLabel(
+	vol *types.Volume, opts *types.VolumeMountOpts) (string, error) {
+
+	if opts.MountLabel != "" {
+		return opts.MountLabel, nil
+	}
+
+	label := vol.Fields[types.VolumeFieldSELinuxLabel]
+	if label == "" {
+		label = d.config.GetString(types.ConfigIgVolOpsMountSELinuxLabel)
+	}
+
+	switch label {
+	case selinuxLabelShared:
+		return selinuxSharedLabel, nil
+	case selinuxLabelPrivate:
+		return "", goof.New("private selinux label requires a mount label")
+	}
+	return label, nil
+}
+
+func (d *driver) fsckPolicy() string {
+	v := d.config.GetString(types.ConfigIgVolOpsMountFsckPolicy)
+	if v == "" {
+		return fsckPolicyNever
+	}
+	return strings.ToLower(v)
+}
+
+func (d *driver) fsckAction() string {
+	v := d.config.GetString(types.ConfigIgVolOpsMountFsckAction)
+	if v == "" {
+		return fsckActionFail
+	}
+	return strings.ToLower(v)
+}
+
+func (d *driver) growFS() bool {
+	return d.config.GetBool(types.ConfigIgVolOpsMountGrowFS)
+}
+
+func (d *driver) encrypt() bool {
+	return d.config.GetBool(types.ConfigIgVolOpsMountEncrypt)
+}
+
+func (d *driver) encryptionKeyRef() string {
+	return d.config.GetString(types.ConfigIgVolOpsMountEncryptionKey)
+}
@@ -0,0 +1,184 @@
+package linux
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/akutz/goof"
+	"github.com/akutz/gotil"
+
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+const (
+	cryptsetupCmd   = "cryptsetup"
+	cryptNamePrefix = "libstorage-"
+	cryptMapperDir  = "/dev/mapper"
+)
+
+var invalidCryptNameRX = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+// cryptName returns the name of the dm-crypt mapping for a volume.
+func cryptName(vol *types.Volume) string {
+	return cryptNamePrefix + invalidCryptNameRX.ReplaceAllString(vol.ID, "_")
+}
+
+// cryptDevicePath returns the path of the device for a dm-crypt mapping.
+func cryptDevicePath(vol *types.Volume) string {
+	return path.Join(cryptMapperDir, cryptName(vol))
+}
+
+// isLUKSDevice returns a flag indicating whether or not the device has
+// been formatted with LUKS.
+func isLUKSDevice(ctx types.Context, device string) bool {
+	return utils.CommandContext(
+		ctx, cryptsetupCmd, "isLuks", device).Run() == nil
+}
+
+// hasFileSystem returns a flag indicating whether or not the device has a
+// file system or other recognized signature.
+func hasFileSystem(ctx types.Context, device string) (bool, error) {
+	fsType, err := deviceFSType(ctx, device)
+	if err != nil {
+		return false, err
+	}
+	return fsType != "", nil
+}
+
+// encryptionRequested returns a flag indicating whether or not a volume
+// should be encrypted by the driver. Volumes that are encrypted natively by
+// their storage platform are only encrypted when explicitly requested.
+func (d *driver) encryptionRequested(
+	vol *types.Volume, opts *types.VolumeMountOpts) bool {
+	return opts.Encrypted || (d.encrypt() && !vol.Encrypted)
+}
+
+// encryptionKey returns the key used to encrypt a volume. The key is
+// fetched from the secrets backend using the configured reference, in which
+// the {id} and {name} placeholders are replaced with the volume's ID and
+// name.
+func (d *driver) encryptionKey(
+	ctx types.Context, vol *types.Volume) (string, error) {
+
+	ref := d.encryptionKeyRef()
+	if ref == "" {
+		return "", goof.WithField(
+			"configKey", types.ConfigIgVolOpsMountEncryptionKey,
+			"encryption key not configured")
+	}
+	ref = strings.NewReplacer("{id}", vol.ID, "{name}", vol.Name).Replace(ref)
+
+	key, err := registry.ResolveSecret(ctx, d.config, ref)
+	if err != nil {
+		return "", goof.WithError("error fetching encryption key", err)
+	}
+	if key == "" {
+		return "", goof.New("encryption key is empty")
+	}
+	return key, nil
+}
+
+// openEncryptedDevice returns the path of the device that should be
+// formatted and mounted for a volume. If the volume's device is formatted
+// with LUKS, or if encryption is requested, then the device's dm-crypt
+// mapping is opened and its path is returned. A device that is not yet
+// formatted with LUKS is formatted first. Otherwise the volume's device is
+// returned unchanged.
+func (d *driver) openEncryptedDevice(
+	ctx types.Context,
+	vol *types.Volume,
+	device string,
+	opts *types.VolumeMountOpts) (string, error) {
+
+	mappedDevice := cryptDevicePath(vol)
+	if gotil.FileExists(mappedDevice) {
+		return mappedDevice, nil
+	}
+
+	isLUKS := isLUKSDevice(ctx, device)
+	if !isLUKS && !d.encryptionRequested(vol, opts) {
+		return device, nil
+	}
+
+	key, err := d.encryptionKey(ctx, vol)
+	if err != nil {
+		return "", err
+	}
+
+	fields := log.Fields{
+		"device":       device,
+		"mappedDevice": mappedDevice,
+	}
+
+	if !isLUKS && opts.ReadOnly {
+		return "", goof.WithField(
+			"device", device, "cannot encrypt read-only volume")
+	}
+
+	if !isLUKS {
+		// never encrypt a device with existing data unless the caller has
+		// asked for the device's file system to be overwritten
+		ok, err := hasFileSystem(ctx, device)
+		if err != nil {
+			return "", err
+		}
+		if ok && !opts.OverwriteFS {
+			return "", goof.WithField("device", device,
+				"cannot encrypt device with existing file system")
+		}
+
+		ctx.WithFields(fields).Info("formatting device with luks")
+		if err := runCryptsetup(
+			ctx, key, "-q", "luksFormat", "--key-file", "-",
+			device); err != nil {
+			return "", err
+		}
+	}
+
+	args := []string{"luksOpen", "--key-file", "-"}
+	if opts.ReadOnly {
+		args = append(args, "--readonly")
+	}
+	args = append(args, device, cryptName(vol))
+
+	ctx.WithFields(fields).Info("opening encrypted device")
+	if err := runCryptsetup(ctx, key, args...); err != nil {
+		return "", err
+	}
+
+	return mappedDevice, nil
+}
+
+// closeEncryptedDevice closes a volume's dm-crypt mapping if it is open.
+func (d *driver) closeEncryptedDevice(
+	ctx types.Context, vol *types.Volume) error {
+
+	if !gotil.FileExists(cryptDevicePath(vol)) {
+		return nil
+	}
+
+	ctx.WithField(
+		"mappedDevice", cryptDevicePath(vol)).Info("closing encrypted device")
+	return runCryptsetup(ctx, "", "luksClose", cryptName(vol))
+}
+
+// runCryptsetup runs cryptsetup with the specified arguments. The key, if
+// any, is written to the command's stdin so it never appears in the
+// process list.
+func runCryptsetup(ctx types.Context, key string, args ...string) error {
+	cmd := utils.CommandContext(ctx, cryptsetupCmd, args...)
+	if key != "" {
+		cmd.Stdin = strings.NewReader(key)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return goof.WithFieldsE(goof.Fields{
+			"args":   args,
+			"output": strings.TrimSpace(string(out)),
+		}, "error running cryptsetup", err)
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package linux
+
+import (
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+const (
+	// fsckPolicyNever never checks a volume's file system before it is
+	// mounted.
+	fsckPolicyNever = "never"
+
+	// fsckPolicyAuto checks a volume's file system before it is mounted if
+	// the file system was not cleanly unmounted.
+	fsckPolicyAuto = "auto"
+
+	// fsckPolicyAlways always checks a volume's file system before it is
+	// mounted.
+	fsckPolicyAlways = "always"
+
+	// fsckActionRepair repairs a file system with errors.
+	fsckActionRepair = "repair"
+
+	// fsckActionWarn logs a warning and mounts a file system with errors.
+	fsckActionWarn = "warn"
+
+	// fsckActionFail fails the mount of a file system with errors.
+	fsckActionFail = "fail"
+)
+
+// fsckResult is the result of checking a file system.
+type fsckResult int
+
+const (
+	fsckClean fsckResult = iota
+	fsckRepaired
+	fsckCorrupt
+)
+
+// validateFsckConfig returns an error if the configured file system check
+// policy or action is invalid.
+func (d *driver) validateFsckConfig() error {
+	switch d.fsckPolicy() {
+	case fsckPolicyNever, fsckPolicyAuto, fsckPolicyAlways:
+	default:
+		return goof.WithFields(goof.Fields{
+			"configKey": types.ConfigIgVolOpsMountFsckPolicy,
+			"value":     d.fsckPolicy(),
+		}, "invalid fsck policy")
+	}
+	switch d.fsckAction() {
+	case fsckActionRepair, fsckActionWarn, fsckActionFail:
+	default:
+		return goof.WithFields(goof.Fields{
+			"configKey": types.ConfigIgVolOpsMountFsckAction,
+			"value":     d.fsckAction(),
+		}, "invalid fsck action")
+	}
+	return nil
+}
+
+// checkFileSystem checks the file system on a volume's device according to
+// the configured policy before the device is mounted. A file system with
+// errors is repaired, logged, or causes an error to be returned according
+// to the configured action. Devices without a file system are not checked.
+func (d *driver) checkFileSystem(ctx types.Context, device string) error {
+
+	policy := d.fsckPolicy()
+	if policy == fsckPolicyNever {
+		return nil
+	}
+
+	fsType, err := deviceFSType(ctx, device)
+	if err != nil {
+		return err
+	}
+	if fsType == "" {
+		return nil
+	}
+
+	fields := log.Fields{
+		"device": device,
+		"fsType": fsType,
+		"policy": policy,
+	}
+
+	if policy == fsckPolicyAuto {
+		dirty, err := isFileSystemDirty(ctx, device, fsType)
+		if err != nil {
+			return err
+		}
+		if !dirty {
+			ctx.WithFields(fields).Debug("file system is clean")
+			return nil
+		}
+	}
+
+	action := d.fsckAction()
+	fields["action"] = action
+
+	ctx.WithFields(fields).Info("checking file system")
+	result, err := runFsck(ctx, device, fsType, action == fsckActionRepair)
+	if err != nil {
+		return err
+	}
+
+	switch result {
+	case fsckRepaired:
+		ctx.WithFields(fields).Warn("repaired file system")
+	case fsckCorrupt:
+		if action == fsckActionWarn {
+			ctx.WithFields(fields).Warn("file system has errors")
+			return nil
+		}
+		return goof.WithFields(goof.Fields{
+			"device": device,
+			"fsType": fsType,
+		}, "file system has errors")
+	}
+	return nil
+}
+
+// isFileSystemDirty returns a flag indicating whether or not the file system
+// on the device was not cleanly unmounted. Only the ext family of file
+// systems records this; the journals of other file systems are replayed when
+// they are mounted.
+func isFileSystemDirty(
+	ctx types.Context, device, fsType string) (bool, error) {
+
+	if !strings.HasPrefix(fsType, "ext") {
+		return false, nil
+	}
+
+	out, err := utils.CommandContext(ctx, "dumpe2fs", "-h", device).Output()
+	if err != nil {
+		return false, goof.WithFieldE(
+			"device", device, "error reading file system state", err)
+	}
+	return isDumpe2fsDirty(string(out)), nil
+}
+
+// isDumpe2fsDirty returns a flag indicating whether or not the superblock
+// printed by dumpe2fs -h is of a file system that was not cleanly
+// unmounted. The state of a journaled file system remains clean when it is
+// detached without being unmounted, but its needs_recovery feature is set
+// until its journal is replayed.
+func isDumpe2fsDirty(out string) bool {
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case strings.HasPrefix(line, "Filesystem state:"):
+			state := strings.TrimSpace(
+				strings.TrimPrefix(line, "Filesystem state:"))
+			if state != "clean" {
+				return true
+			}
+		case strings.HasPrefix(line, "Filesystem features:"):
+			features := strings.Fields(
+				strings.TrimPrefix(line, "Filesystem features:"))
+			for _, f := range features {
+				if f == "needs_recovery" {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// runFsck checks, and optionally repairs, the file system on the device.
+func runFsck(
+	ctx types.Context,
+	device, fsType string,
+	repair bool) (fsckResult, error) {
+
+	var (
+		name string
+		args []string
+	)
+
+	switch {
+	case strings.HasPrefix(fsType, "ext"):
+		name, args = "e2fsck", []string{"-n"}
+		if repair {
+			args = []string{"-p"}
+		}
+	case fsType == "xfs":
+		name, args = "xfs_repair", []string{"-n"}
+		if repair {
+			args = nil
+		}
+	case fsType == "btrfs":
+		// btrfs' repair mode is not safe to run unattended, so btrfs
+		// file systems are only ever checked
+		name, args = "btrfs", []string{"check", "--readonly"}
+	default:
+		ctx.WithField("fsType", fsType).Warn(
+			"cannot check unsupported file system")
+		return fsckClean, nil
+	}
+
+	args = append(args, device)
+	out, err := utils.CommandContext(ctx, name, args...).CombinedOutput()
+	status := exitStatus(err)
+	if err != nil && status < 0 {
+		return 0, goof.WithFieldsE(goof.Fields{
+			"command": name,
+			"device":  device,
+		}, "error checking file system", err)
+	}
+
+	if status != 0 {
+		ctx.WithFields(log.Fields{
+			"command": name,
+			"status":  status,
+			"output":  strings.TrimSpace(string(out)),
+		}).Debug("file system check output")
+	}
+
+	switch name {
+	case "e2fsck":
+		// e2fsck's exit status is a bit mask: 1 and 2 indicate errors that
+		// were corrected, 4 errors that were not corrected, and 8 or more
+		// an operational error
+		switch {
+		case status == 0:
+			return fsckClean, nil
+		case status >= 8:
+			return 0, goof.WithFields(goof.Fields{
+				"device": device,
+				"status": status,
+			}, "error checking file system")
+		case status&4 != 0:
+			return fsckCorrupt, nil
+		default:
+			return fsckRepaired, nil
+		}
+	case "xfs_repair":
+		// xfs_repair exits with 2 when the log must be replayed by mounting
+		// the file system, which is done when the device is mounted
+		if status == 0 || status == 2 {
+			return fsckClean, nil
+		}
+		return fsckCorrupt, nil
+	}
+
+	if status != 0 {
+		return fsckCorrupt, nil
+	}
+	return fsckClean, nil
+}
@@ -0,0 +1,61 @@
+package linux
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsDumpe2fsDirty(t *testing.T) {
+	const (
+		features = "has_journal ext_attr resize_inode dir_index filetype " +
+			"extent 64bit flex_bg sparse_super large_file huge_file " +
+			"dir_nlink extra_isize metadata_csum"
+		recovery = "has_journal ext_attr resize_inode dir_index filetype " +
+			"needs_recovery extent 64bit flex_bg sparse_super large_file"
+	)
+
+	// a cleanly unmounted file system
+	assert.False(t, isDumpe2fsDirty(
+		fmt.Sprintf(dumpe2fsFormat, features, "clean")))
+
+	// a journaled file system detached without being unmounted
+	assert.True(t, isDumpe2fsDirty(
+		fmt.Sprintf(dumpe2fsFormat, recovery, "clean")))
+
+	// a file system without a journal that was not cleanly unmounted
+	assert.True(t, isDumpe2fsDirty(
+		fmt.Sprintf(dumpe2fsFormat, "ext_attr filetype", "not clean")))
+	assert.True(t, isDumpe2fsDirty(
+		fmt.Sprintf(dumpe2fsFormat, features, "clean with errors")))
+
+	assert.False(t, isDumpe2fsDirty(""))
+}
+
+const dumpe2fsFormat = `dumpe2fs 1.43.4 (31-Jan-2017)
+Filesystem volume name:   <none>
+Last mounted on:          /var/lib/libstorage/volumes/data
+Filesystem UUID:          3a1d6e1c-6d3f-4f45-9c8e-0f4a1b2c3d4e
+Filesystem magic number:  0xEF53
+Filesystem revision #:    1 (dynamic)
+Filesystem features:      %s
+Filesystem flags:         signed_directory_hash
+Default mount options:    user_xattr acl
+Filesystem state:         %s
+Errors behavior:          Continue
+Filesystem OS type:       Linux
+Inode count:              65536
+Block count:              262144
+Reserved block count:     13107
+Free blocks:              249189
+Free inodes:              65525
+First block:              0
+Block size:               4096
+Journal backup:           inode blocks
+Journal features:         journal_incompat_revoke journal_64bit
+Journal size:             8M
+Journal length:           2048
+Journal sequence:         0x00000007
+Journal start:            1
+`
@@ -21,5 +21,12 @@ func init() {
 	r.Key(gofig.String, "", "/data", "", types.ConfigIgVolOpsMountRootPath)
 	r.Key(gofig.Bool, "", true, "", types.ConfigIgVolOpsCreateImplicit)
 	r.Key(gofig.Bool, "", false, "", types.ConfigIgVolOpsMountPreempt)
+	r.Key(gofig.Bool, "", false, "", types.ConfigIgVolOpsMountEncrypt)
+	r.Key(gofig.Bool, "", false, "", types.ConfigIgVolOpsMountGrowFS)
+	r.Key(gofig.String, "", "", "", types.ConfigIgVolOpsMountOptions)
+	r.Key(gofig.String, "", "", "", types.ConfigIgVolOpsMountSELinuxLabel)
+	r.Key(gofig.String, "", "never", "", types.ConfigIgVolOpsMountFsckPolicy)
+	r.Key(gofig.String, "", "fail", "", types.ConfigIgVolOpsMountFsckAction)
+	r.Key(gofig.String, "", "", "", types.ConfigIgVolOpsMountEncryptionKey)
 	gofigCore.Register(r)
 }
@@ -0,0 +1,172 @@
+package linux
+
+import (
+	"strconv"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// deviceSize returns the size of the device in bytes.
+func deviceSize(ctx types.Context, device string) (int64, error) {
+	out, err := utils.CommandContext(
+		ctx, "blockdev", "--getsize64", device).Output()
+	if err != nil {
+		return 0, goof.WithFieldE(
+			"device", device, "error getting device size", err)
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+}
+
+// growFileSystem grows the mounted file system to fill its device if the
+// size of the volume's device has changed since the mount point was last
+// seen, such as after the volume was expanded by its storage platform. The
+// size of the first device seen at a mount point is always treated as a
+// change, as the volume may have been expanded while it was detached. The
+// file system is grown regardless of the device's size if force is true.
+func (d *driver) growFileSystem(
+	ctx types.Context,
+	vol *types.Volume,
+	device string,
+	mount *types.MountInfo,
+	force bool) error {
+
+	fields := log.Fields{
+		"device":     device,
+		"mountPoint": mount.MountPoint,
+		"fsType":     mount.FSType,
+	}
+
+	size, err := deviceSize(ctx, device)
+	if err != nil {
+		return err
+	}
+	fields["size"] = size
+
+	d.deviceSizesLock.Lock()
+	defer d.deviceSizesLock.Unlock()
+	if !force && d.deviceSizes[mount.MountPoint] == size {
+		return nil
+	}
+
+	if err := resizeFileSystem(ctx, vol, mount); err != nil {
+		return err
+	}
+
+	d.deviceSizes[mount.MountPoint] = size
+	ctx.WithFields(fields).Info("grew file system to device size")
+	return nil
+}
+
+// growMountedFileSystem grows the file system of a volume that is mounted
+// by the driver. Errors are logged rather than returned since the volume
+// remains usable at its previous size.
+func (d *driver) growMountedFileSystem(
+	ctx types.Context,
+	vol *types.Volume,
+	device string,
+	mount *types.MountInfo) {
+
+	if err := d.growFileSystem(ctx, vol, device, mount, false); err != nil {
+		ctx.WithFields(log.Fields{
+			"device":     device,
+			"mountPoint": mount.MountPoint,
+		}).WithError(err).Warn("error growing file system")
+	}
+}
+
+// resizeFileSystem grows the mounted file system to fill its device. A
+// volume encrypted with dm-crypt has its mapping resized first.
+func resizeFileSystem(
+	ctx types.Context, vol *types.Volume, mount *types.MountInfo) error {
+
+	if mount.Source == cryptDevicePath(vol) {
+		if err := runCryptsetup(
+			ctx, "", "resize", cryptName(vol)); err != nil {
+			return err
+		}
+	}
+
+	name, args, err := growFileSystemCommand(mount)
+	if err != nil {
+		return err
+	}
+
+	if out, err := utils.CommandContext(
+		ctx, name, args...).CombinedOutput(); err != nil {
+		return goof.WithFieldsE(goof.Fields{
+			"command": name,
+			"output":  strings.TrimSpace(string(out)),
+		}, "error growing file system", err)
+	}
+	return nil
+}
+
+// growFileSystemCommand returns the command that grows the mounted file
+// system, which is chosen by the type with which the file system is
+// mounted.
+func growFileSystemCommand(
+	mount *types.MountInfo) (string, []string, error) {
+
+	switch fsType := mount.FSType; {
+	case strings.HasPrefix(fsType, "ext"):
+		return "resize2fs", []string{mount.Source}, nil
+	case fsType == "xfs":
+		return "xfs_growfs", []string{mount.MountPoint}, nil
+	case fsType == "btrfs":
+		return "btrfs", []string{
+			"filesystem", "resize", "max", mount.MountPoint}, nil
+	}
+	return "", nil, goof.WithField(
+		"fsType", mount.FSType, "cannot grow unsupported file system")
+}
+
+// GrowFileSystem grows the file system of the volume to fill its device if
+// the volume is mounted on the instance. It is invoked after the volume is
+// resized so that its new capacity is available without remounting it.
+func (d *driver) GrowFileSystem(ctx types.Context, volumeID string) error {
+
+	vol, err := d.volumeInspectByIDOrName(
+		ctx, volumeID, "", types.VolAttReqWithDevMapForInstance, nil)
+	if err != nil {
+		return err
+	}
+	if isRawVolume(vol) {
+		return nil
+	}
+
+	mountPath, err := d.getVolumeMountPath(vol.Name)
+	if err != nil {
+		return err
+	}
+	client := context.MustClient(ctx)
+	mounts, err := client.OS().Mounts(ctx, "", mountPath, utils.NewStore())
+	if err != nil {
+		return err
+	}
+	if len(mounts) == 0 {
+		return nil
+	}
+
+	device := mounts[0].Source
+	if isMirroredVolume(vol) {
+		device = mirrorDevicePath(vol)
+	} else if mounts[0].Source == cryptDevicePath(vol) {
+		inst, err := client.Storage().InstanceInspect(ctx, utils.NewStore())
+		if err != nil {
+			return err
+		}
+		for _, a := range vol.Attachments {
+			if a.InstanceID.ID == inst.InstanceID.ID {
+				device = a.DeviceName
+			}
+		}
+	}
+
+	return d.growFileSystem(ctx, vol, device, mounts[0], true)
+}
@@ -0,0 +1,48 @@
+package linux
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+func TestGrowFileSystemCommand(t *testing.T) {
+	name, args, err := growFileSystemCommand(&types.MountInfo{
+		Source:     "/dev/mapper/libstorage-vol-1",
+		MountPoint: "/var/lib/libstorage/volumes/data",
+		FSType:     "ext4",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "resize2fs", name)
+	assert.Equal(t, []string{"/dev/mapper/libstorage-vol-1"}, args)
+
+	// xfs and btrfs are grown through their mount points
+	name, args, err = growFileSystemCommand(&types.MountInfo{
+		Source:     "/dev/xvdf",
+		MountPoint: "/var/lib/libstorage/volumes/data",
+		FSType:     "xfs",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "xfs_growfs", name)
+	assert.Equal(t, []string{"/var/lib/libstorage/volumes/data"}, args)
+
+	name, args, err = growFileSystemCommand(&types.MountInfo{
+		Source:     "/dev/xvdf",
+		MountPoint: "/var/lib/libstorage/volumes/data",
+		FSType:     "btrfs",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "btrfs", name)
+	assert.Equal(t, []string{
+		"filesystem", "resize", "max", "/var/lib/libstorage/volumes/data"},
+		args)
+
+	_, _, err = growFileSystemCommand(&types.MountInfo{
+		Source:     "/dev/xvdf",
+		MountPoint: "/var/lib/libstorage/volumes/data",
+		FSType:     "vfat",
+	})
+	assert.Error(t, err)
+}
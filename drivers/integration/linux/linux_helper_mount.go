@@ -0,0 +1,73 @@
+package linux
+
+import (
+	"os"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// isHelperMounted returns a flag indicating whether or not an attachment's
+// device is mounted with the mount helper of the file system recorded by
+// the storage driver, such as an EFS access point.
+func isHelperMounted(ma *types.VolumeAttachment) bool {
+	return ma.Fields[types.AttachmentFieldFsType] != ""
+}
+
+// helperMount mounts a volume whose attachment's device is mounted with the
+// mount helper of its file system. The device is neither checked nor
+// formatted. Since a mount helper may mount a device other than the
+// attachment's, ex. a local TLS tunnel, the volume's mount is found by its
+// mount point rather than by its device.
+func (d *driver) helperMount(
+	ctx types.Context,
+	vol *types.Volume,
+	ma *types.VolumeAttachment,
+	opts *types.VolumeMountOpts) (string, *types.Volume, error) {
+
+	client := context.MustClient(ctx)
+
+	mountPath, err := d.getVolumeMountPath(vol.Name)
+	if err != nil {
+		return "", nil, err
+	}
+
+	mounts, err := client.OS().Mounts(ctx, "", mountPath, opts.Opts)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(mounts) > 0 {
+		return d.volumeMountPath(mounts[0].MountPoint), vol, nil
+	}
+
+	if err := os.MkdirAll(mountPath, 0755); err != nil {
+		return "", nil, err
+	}
+
+	fsType := ma.Fields[types.AttachmentFieldFsType]
+	mountOpts := mergeMountOptions(
+		ma.Fields[types.AttachmentFieldMountOptions], opts.MountOptions)
+
+	ctx.WithFields(log.Fields{
+		"device":       ma.DeviceName,
+		"fsType":       fsType,
+		"mountOptions": mountOpts,
+	}).Info("mounting volume with mount helper")
+
+	context.RecordMountStep(ctx, types.MountStepMount, mountPath)
+	if err := client.OS().Mount(
+		ctx,
+		ma.DeviceName,
+		mountPath,
+		&types.DeviceMountOpts{
+			MountOptions: mountOpts,
+			ReadOnly:     opts.ReadOnly,
+			FsType:       fsType,
+		}); err != nil {
+		return "", nil, err
+	}
+
+	return d.volumeMountPath(mountPath), vol, nil
+}
@@ -0,0 +1,77 @@
+package linux
+
+import (
+	"path"
+	"strings"
+
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+const (
+	mirrorDeviceDir = "/dev/md"
+
+	// mirrorTokenSeparator separates the tokens of the devices of a
+	// mirrored volume returned when the volume is attached.
+	mirrorTokenSeparator = ","
+)
+
+// isMirroredVolume returns a flag indicating whether or not a volume's
+// devices are assembled into a local mirror.
+func isMirroredVolume(vol *types.Volume) bool {
+	return vol.Fields[types.VolumeFieldMirror] != ""
+}
+
+// mirrorDevicePath returns the path of the device of a mirrored volume's
+// local mirror.
+func mirrorDevicePath(vol *types.Volume) string {
+	return path.Join(mirrorDeviceDir, vol.Fields[types.VolumeFieldMirror])
+}
+
+// attachTokens returns the tokens of the devices for which to wait after a
+// volume is attached. A mirrored volume has a token for each of its devices.
+func attachTokens(vol *types.Volume, token string) []string {
+	if token == "" {
+		return nil
+	}
+	if vol != nil && isMirroredVolume(vol) {
+		return strings.Split(token, mirrorTokenSeparator)
+	}
+	return []string{token}
+}
+
+// assembleMirror assembles the devices of a mirrored volume's attachment
+// into a local mirror with the client's OS driver and returns the path of
+// the mirror's device.
+func (d *driver) assembleMirror(
+	ctx types.Context,
+	vol *types.Volume,
+	ma *types.VolumeAttachment,
+	opts types.Store) (string, error) {
+
+	od, ok := context.MustClient(ctx).OS().(types.ProvidesDeviceMirroring)
+	if !ok {
+		return "", goof.New("os driver does not support mirrored volumes")
+	}
+	devices := strings.Split(
+		ma.Fields[types.AttachmentFieldMirrorDevices], mirrorTokenSeparator)
+	if len(devices) != 2 || devices[0] == "" || devices[1] == "" {
+		return "", goof.WithField(
+			"devices", devices, "mirrored volume is missing devices")
+	}
+	return od.MirrorAssemble(
+		ctx, vol.Fields[types.VolumeFieldMirror], devices, opts)
+}
+
+// stopMirror stops a mirrored volume's local mirror.
+func (d *driver) stopMirror(
+	ctx types.Context, vol *types.Volume, opts types.Store) error {
+
+	od, ok := context.MustClient(ctx).OS().(types.ProvidesDeviceMirroring)
+	if !ok {
+		return nil
+	}
+	return od.MirrorStop(ctx, vol.Fields[types.VolumeFieldMirror], opts)
+}
@@ -2,15 +2,49 @@ package linux
 
 import (
 	"fmt"
+	"os"
+	"os/exec"
 	"path"
+	"regexp"
+	"runtime"
+	"strconv"
 	"strings"
+	"syscall"
 
+	log "github.com/Sirupsen/logrus"
 	"github.com/akutz/goof"
+
 	"github.com/codedellemc/libstorage/api/context"
 	"github.com/codedellemc/libstorage/api/types"
 	"github.com/codedellemc/libstorage/api/utils"
+	apiconfig "github.com/codedellemc/libstorage/api/utils/config"
 )
 
+// stableDeviceNames sets the device names of the volume's attachments to
+// the devices' persistent symlinks, if known, so that the names returned to
+// the caller do not change when the devices are renamed across reboots. The
+// devices' names are used internally since they are the sources of the
+// instance's mounts.
+func (d *driver) stableDeviceNames(vol *types.Volume) {
+	if !apiconfig.DeviceStableNames(d.config) {
+		return
+	}
+	for _, a := range vol.Attachments {
+		a.DeviceName = a.StableDeviceName()
+	}
+}
+
+// stableDevicePath returns the persistent symlink of the attachment's
+// device if the device is the one at the path, otherwise the path.
+func (d *driver) stableDevicePath(
+	a *types.VolumeAttachment, device string) string {
+
+	if device != a.DeviceName || !apiconfig.DeviceStableNames(d.config) {
+		return device
+	}
+	return a.StableDeviceName()
+}
+
 func (d *driver) getVolumeMountPath(volumeName string) (string, error) {
 	if volumeName == "" {
 		return "", goof.New("missing volume name")
@@ -71,7 +105,7 @@ func (d *driver) volumeInspectByIDOrName(
 	}
 
 	if obj == nil {
-		return nil, utils.NewNotFoundError(
+		return nil, utils.NewVolumeNotFoundError(
 			fmt.Sprintf("volumeID=%s,volumeName=%s", volumeID, volumeName))
 	}
 	return obj, nil
@@ -89,3 +123,227 @@ func isErrNotFound(err error) bool {
 func (d *driver) volumeMountPath(target string) string {
 	return path.Join(target, d.volumeRootPath())
 }
+
+// fsTypeRX matches the name of a type of file system, ex. ext4.
+var fsTypeRX = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// isSupportedFSType returns a flag indicating whether or not volumes may be
+// formatted with the provided type of file system by the host's OS driver.
+// A type is supported on Linux if its mkfs.<type> executable can be run.
+func isSupportedFSType(fsType string) bool {
+	if !fsTypeRX.MatchString(fsType) {
+		return false
+	}
+	switch runtime.GOOS {
+	case "windows":
+		return fsType == "ntfs"
+	case "freebsd", "solaris":
+		return fsType == "ufs" || fsType == "zfs"
+	}
+	_, err := utils.LookPath("mkfs." + fsType)
+	return err == nil
+}
+
+// mergeMountOptions merges comma-separated lists of mount options. Options
+// in later lists take precedence over the same or opposing options in
+// earlier lists, ex. "ro" overrides "rw" and "nodiscard" overrides "discard".
+func mergeMountOptions(options ...string) string {
+	var (
+		merged []string
+		index  = map[string]int{}
+	)
+	for _, opts := range options {
+		for _, o := range strings.Split(opts, ",") {
+			if o = strings.TrimSpace(o); o == "" {
+				continue
+			}
+			k := mountOptionKey(o)
+			if i, ok := index[k]; ok {
+				merged[i] = o
+				continue
+			}
+			index[k] = len(merged)
+			merged = append(merged, o)
+		}
+	}
+	return strings.Join(merged, ",")
+}
+
+// mountOptionKey returns the key that identifies a mount option and its
+// opposing option.
+func mountOptionKey(o string) string {
+	k := strings.SplitN(o, "=", 2)[0]
+	switch {
+	case k == "ro":
+		return "rw"
+	case strings.HasPrefix(k, "no"):
+		return k[2:]
+	}
+	return k
+}
+
+// deviceFSType returns the type of the device's file system or other
+// recognized signature. An empty string is returned if the device has no
+// recognized signature.
+func deviceFSType(ctx types.Context, device string) (string, error) {
+	out, err := utils.CommandContext(
+		ctx, "blkid", "-p", "-o", "value", "-s", "TYPE", device).Output()
+	if err != nil {
+		// blkid exits with 2 when no signature is found
+		if exitStatus(err) == 2 {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// exitStatus returns the exit status of the command that returned the
+// provided error, or -1 if the error is not from a command that exited.
+func exitStatus(err error) int {
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+			return ws.ExitStatus()
+		}
+	}
+	return -1
+}
+
+// volumeAccessMode returns the access mode recorded with the volume, or
+// types.VolumeAccessModeFileSystem if none was recorded.
+func volumeAccessMode(vol *types.Volume) string {
+	if v, ok := vol.Fields[types.VolumeFieldAccessMode]; ok && v != "" {
+		return v
+	}
+	return types.VolumeAccessModeFileSystem
+}
+
+// isRawVolume returns a flag indicating whether or not the volume is
+// accessed directly as a block device.
+func isRawVolume(vol *types.Volume) bool {
+	return volumeAccessMode(vol) == types.VolumeAccessModeRaw
+}
+
+// withoutKey returns a copy of the store without the key.
+func withoutKey(opts types.Store, key string) types.Store {
+	if !opts.IsSet(key) {
+		return opts
+	}
+	data := map[string]interface{}{}
+	for _, k := range opts.Keys() {
+		if k != key {
+			data[k] = opts.Get(k)
+		}
+	}
+	return utils.NewStoreWithData(data)
+}
+
+// normalizeOwnershipOpts validates the uid, gid, and mode volume options,
+// if set, and stores them as strings so they are recorded consistently as
+// volume fields.
+func normalizeOwnershipOpts(opts types.Store) error {
+	for _, k := range []string{types.VolumeFieldUID, types.VolumeFieldGID} {
+		if !opts.IsSet(k) {
+			continue
+		}
+		v := fmt.Sprintf("%v", opts.Get(k))
+		if id, err := strconv.Atoi(v); err != nil || id < 0 {
+			return goof.WithField(k, v, "invalid volume owner")
+		}
+		opts.Set(k, v)
+	}
+	if opts.IsSet(types.VolumeFieldMode) {
+		v := fmt.Sprintf("%v", opts.Get(types.VolumeFieldMode))
+		if _, err := parseFileMode(v); err != nil {
+			return err
+		}
+		opts.Set(types.VolumeFieldMode, v)
+	}
+	return nil
+}
+
+// parseFileMode parses octal file permissions, ex. 0750.
+func parseFileMode(v string) (os.FileMode, error) {
+	m, err := strconv.ParseUint(v, 8, 32)
+	if err != nil || m > 07777 {
+		return 0, goof.WithField("mode", v, "invalid volume mode")
+	}
+	mode := os.FileMode(m & 0777)
+	if m&04000 != 0 {
+		mode |= os.ModeSetuid
+	}
+	if m&02000 != 0 {
+		mode |= os.ModeSetgid
+	}
+	if m&01000 != 0 {
+		mode |= os.ModeSticky
+	}
+	return mode, nil
+}
+
+// volumeOwnership returns the owner and permissions that are applied to the
+// mounted volume. The values specified by the mount request override those
+// recorded with the volume.
+func volumeOwnership(
+	vol *types.Volume, opts *types.VolumeMountOpts) (types.Store, error) {
+
+	ownership := utils.NewStore()
+	for k, v := range map[string]string{
+		types.VolumeFieldUID:  opts.UID,
+		types.VolumeFieldGID:  opts.GID,
+		types.VolumeFieldMode: opts.Mode,
+	} {
+		if v == "" {
+			v = vol.Fields[k]
+		}
+		if v != "" {
+			ownership.Set(k, v)
+		}
+	}
+	if err := normalizeOwnershipOpts(ownership); err != nil {
+		return nil, err
+	}
+	return ownership, nil
+}
+
+// applyOwnership applies the owner and permissions returned by
+// volumeOwnership to the path returned for the mounted volume.
+func applyOwnership(
+	ctx types.Context, ownership types.Store, path string) error {
+
+	uid, gid := -1, -1
+	if ownership.IsSet(types.VolumeFieldUID) {
+		uid = ownership.GetInt(types.VolumeFieldUID)
+	}
+	if ownership.IsSet(types.VolumeFieldGID) {
+		gid = ownership.GetInt(types.VolumeFieldGID)
+	}
+	if uid >= 0 || gid >= 0 {
+		ctx.WithFields(log.Fields{
+			"path": path,
+			"uid":  uid,
+			"gid":  gid,
+		}).Debug("changing volume owner")
+		if err := os.Chown(path, uid, gid); err != nil {
+			return goof.WithFieldE("path", path,
+				"error changing volume owner", err)
+		}
+	}
+
+	if v := ownership.GetString(types.VolumeFieldMode); v != "" {
+		mode, err := parseFileMode(v)
+		if err != nil {
+			return err
+		}
+		ctx.WithFields(log.Fields{
+			"path": path,
+			"mode": v,
+		}).Debug("changing volume mode")
+		if err := os.Chmod(path, mode); err != nil {
+			return goof.WithFieldE("path", path,
+				"error changing volume mode", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package linux
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+func TestVolumeOwnership(t *testing.T) {
+	vol := &types.Volume{
+		Fields: map[string]string{
+			types.VolumeFieldUID:  "1000",
+			types.VolumeFieldMode: "0750",
+		},
+	}
+
+	ownership, err := volumeOwnership(vol, &types.VolumeMountOpts{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1000, ownership.GetInt(types.VolumeFieldUID))
+	assert.False(t, ownership.IsSet(types.VolumeFieldGID))
+	assert.Equal(t, "0750", ownership.GetString(types.VolumeFieldMode))
+
+	// the mount request overrides the recorded ownership, and the ownership
+	// of a volume whose driver records nothing is taken from the request
+	opts := &types.VolumeMountOpts{UID: "1001", GID: "1001"}
+	ownership, err = volumeOwnership(vol, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 1001, ownership.GetInt(types.VolumeFieldUID))
+	assert.Equal(t, 1001, ownership.GetInt(types.VolumeFieldGID))
+	assert.Equal(t, "0750", ownership.GetString(types.VolumeFieldMode))
+
+	ownership, err = volumeOwnership(&types.Volume{}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 1001, ownership.GetInt(types.VolumeFieldUID))
+	assert.False(t, ownership.IsSet(types.VolumeFieldMode))
+
+	_, err = volumeOwnership(vol, &types.VolumeMountOpts{UID: "-1"})
+	assert.Error(t, err)
+	_, err = volumeOwnership(vol, &types.VolumeMountOpts{Mode: "0999"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,305 @@
+// +build freebsd
+
+package freebsd
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+const (
+	driverName = "freebsd"
+
+	fsTypeUFS = "ufs"
+	fsTypeZFS = "zfs"
+)
+
+var (
+	errUnknownOS             = goof.New("unknown OS")
+	errUnsupportedFileSystem = goof.New("unsupported file system")
+)
+
+func init() {
+	registry.RegisterOSDriver(driverName, newDriver)
+}
+
+type driver struct {
+	config gofig.Config
+}
+
+func newDriver() types.OSDriver {
+	return &driver{}
+}
+
+func (d *driver) Init(ctx types.Context, config gofig.Config) error {
+	if runtime.GOOS != "freebsd" {
+		return errUnknownOS
+	}
+	d.config = config
+	return nil
+}
+
+func (d *driver) Name() string {
+	return driverName
+}
+
+func (d *driver) Mounts(
+	ctx types.Context,
+	deviceName, mountPoint string,
+	opts types.Store) ([]*types.MountInfo, error) {
+
+	if mountPoint != "" && deviceName != "" {
+		return nil, goof.New("cannot specify mountPoint and deviceName")
+	}
+
+	// "mount -p" prints the mounted file systems in fstab(5) format
+	out, err := utils.CommandContext(ctx, "mount", "-p").Output()
+	if err != nil {
+		return nil, goof.WithError("error listing mounts", err)
+	}
+
+	mounts := []*types.MountInfo{}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		f := strings.Fields(scanner.Text())
+		if len(f) < 4 {
+			continue
+		}
+		m := &types.MountInfo{
+			Source:     f[0],
+			MountPoint: f[1],
+			FSType:     f[2],
+			Opts:       f[3],
+		}
+		if (mountPoint == "" && deviceName == "") ||
+			m.MountPoint == mountPoint || m.Source == deviceName {
+			mounts = append(mounts, m)
+		}
+	}
+	return mounts, nil
+}
+
+// Mount mounts the device's file system at the mount point. The ZFS pool on
+// a device formatted with ZFS is imported before its root data set is
+// mounted.
+func (d *driver) Mount(
+	ctx types.Context,
+	deviceName, mountPoint string,
+	opts *types.DeviceMountOpts) error {
+
+	fsType, label, err := probeFsType(ctx, deviceName)
+	if err != nil {
+		return err
+	}
+
+	source := deviceName
+	switch fsType {
+	case fsTypeUFS:
+	case fsTypeZFS:
+		if err := importPool(ctx, label); err != nil {
+			return err
+		}
+		source = label
+	default:
+		return goof.WithFieldE(
+			"fsType", fsType, "error mounting device",
+			errUnsupportedFileSystem)
+	}
+
+	args := []string{"-t", fsType}
+	if opts.MountOptions != "" {
+		args = append(args, "-o", opts.MountOptions)
+	}
+	args = append(args, source, mountPoint)
+
+	if out, err := utils.CommandContext(
+		ctx, "mount", args...).CombinedOutput(); err != nil {
+		return goof.WithFieldsE(goof.Fields{
+			"deviceName": deviceName,
+			"mountPoint": mountPoint,
+			"output":     strings.TrimSpace(string(out)),
+		}, "error mounting device", err)
+	}
+	return nil
+}
+
+// Unmount unmounts the mount point. The ZFS pool of a mounted ZFS data set
+// is exported so that its device may be detached.
+func (d *driver) Unmount(
+	ctx types.Context,
+	mountPoint string,
+	opts types.Store) error {
+
+	mounts, err := d.Mounts(ctx, "", mountPoint, opts)
+	if err != nil {
+		return err
+	}
+
+	if out, err := utils.CommandContext(
+		ctx, "umount", mountPoint).CombinedOutput(); err != nil {
+		return goof.WithFieldsE(goof.Fields{
+			"mountPoint": mountPoint,
+			"output":     strings.TrimSpace(string(out)),
+		}, "error unmounting mount point", err)
+	}
+
+	for _, m := range mounts {
+		if m.FSType != fsTypeZFS {
+			continue
+		}
+		pool := strings.SplitN(m.Source, "/", 2)[0]
+		if err := runCommand(ctx, "zpool", "export", pool); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *driver) IsMounted(
+	ctx types.Context,
+	mountPoint string,
+	opts types.Store) (bool, error) {
+
+	mounts, err := d.Mounts(ctx, "", mountPoint, opts)
+	if err != nil {
+		return false, err
+	}
+	return len(mounts) > 0, nil
+}
+
+// Format creates a UFS file system or a ZFS pool on the device if the
+// device does not have a file system or if OverwriteFS is set. UFS is used
+// if a file system type is not specified. A ZFS pool is given a unique name
+// so that it does not conflict with the pools on other hosts to which the
+// device may be attached, and its root data set is mounted like a UFS file
+// system rather than by ZFS.
+func (d *driver) Format(
+	ctx types.Context,
+	deviceName string,
+	opts *types.DeviceFormatOpts) error {
+
+	fsType, _, err := probeFsType(ctx, deviceName)
+	if err != nil {
+		return err
+	}
+	fsDetected := fsType != ""
+
+	ctx.WithFields(log.Fields{
+		"fsDetected":  fsDetected,
+		"fsType":      fsType,
+		"deviceName":  deviceName,
+		"overwriteFs": opts.OverwriteFS,
+		"driverName":  driverName}).Info("probe information")
+
+	if fsDetected && !opts.OverwriteFS {
+		return nil
+	}
+
+	for _, o := range opts.NewFSOpts {
+		// a path could cause the device to be formatted with another
+		// device
+		if strings.HasPrefix(o, "/") {
+			return goof.WithField(
+				"option", o, "invalid file system option")
+		}
+	}
+
+	switch opts.NewFSType {
+	case "", fsTypeUFS:
+		args := append([]string{"-U"}, opts.NewFSOpts...)
+		return runCommand(ctx, "newfs", append(args, deviceName)...)
+	case fsTypeZFS:
+		pool, err := newPoolName()
+		if err != nil {
+			return err
+		}
+		args := append(
+			[]string{"create", "-f", "-O", "mountpoint=legacy"},
+			opts.NewFSOpts...)
+		args = append(args, pool, deviceName)
+		if err := runCommand(ctx, "zpool", args...); err != nil {
+			return err
+		}
+		return runCommand(ctx, "zpool", "export", pool)
+	}
+
+	return goof.WithFieldE(
+		"fsType", opts.NewFSType, "error formatting device",
+		errUnsupportedFileSystem)
+}
+
+// probeFsType returns the type of the device's file system and its label,
+// which is the name of the pool for ZFS. An empty type is returned if the
+// device does not have a file system.
+func probeFsType(
+	ctx types.Context, deviceName string) (string, string, error) {
+
+	out, err := utils.CommandContext(
+		ctx, "fstyp", "-l", deviceName).Output()
+	if err != nil {
+		// fstyp exits with a non-zero status when it does not recognize
+		// the device's file system
+		if _, ok := err.(*exec.ExitError); ok {
+			return "", "", nil
+		}
+		return "", "", goof.WithFieldE(
+			"deviceName", deviceName, "error probing file system", err)
+	}
+
+	f := strings.Fields(string(out))
+	if len(f) == 0 {
+		return "", "", nil
+	}
+	if len(f) == 1 {
+		return f[0], "", nil
+	}
+	return f[0], f[1], nil
+}
+
+// importPool imports the ZFS pool without mounting its data sets if it is
+// not already imported.
+func importPool(ctx types.Context, pool string) error {
+	if pool == "" {
+		return goof.New("zfs pool has no name")
+	}
+	if err := utils.CommandContext(
+		ctx, "zpool", "list", "-H", "-o", "name", pool).Run(); err == nil {
+		return nil
+	}
+	return runCommand(ctx, "zpool", "import", "-N", "-d", "/dev", pool)
+}
+
+// newPoolName returns a unique name for a ZFS pool.
+func newPoolName() (string, error) {
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "libstorage-" + hex.EncodeToString(buf), nil
+}
+
+func runCommand(ctx types.Context, name string, args ...string) error {
+	out, err := utils.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil {
+		return goof.WithFieldsE(goof.Fields{
+			"command": name,
+			"args":    args,
+			"output":  strings.TrimSpace(string(out)),
+		}, "error running command", err)
+	}
+	return nil
+}
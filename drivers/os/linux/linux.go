@@ -6,9 +6,9 @@ import (
 	"bytes"
 	"fmt"
 	"os"
-	"os/exec"
 	"runtime"
 	"strings"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
 
@@ -18,6 +18,7 @@ import (
 	"github.com/codedellemc/libstorage/api/context"
 	"github.com/codedellemc/libstorage/api/registry"
 	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
 )
 
 const driverName = "linux"
@@ -104,6 +105,14 @@ func (d *driver) Mount(
 	deviceName, mountPoint string,
 	opts *types.DeviceMountOpts) error {
 
+	// a read-only mount is enforced with the ro option so that it applies
+	// whether the device is mounted locally or by the executor
+	if opts.ReadOnly {
+		roOpts := *opts
+		roOpts.MountOptions = joinMountOptions(opts.MountOptions, "ro")
+		opts = &roOpts
+	}
+
 	// see if we should use the executor?
 	if client, ok := context.Client(ctx); ok {
 		if _, ok := context.ServiceName(ctx); ok {
@@ -127,8 +136,19 @@ func (d *driver) Mount(
 		}
 	}
 
+	if opts.FsType != "" {
+		if err := d.helperMount(
+			deviceName, mountPoint, opts.FsType, opts.MountOptions); err != nil {
+			return err
+		}
+		os.MkdirAll(d.volumeMountPath(mountPoint), d.fileModeMountPath())
+		os.Chmod(d.volumeMountPath(mountPoint), d.fileModeMountPath())
+		return nil
+	}
+
 	if d.isNfsDevice(deviceName) {
-		if err := d.nfsMount(deviceName, mountPoint); err != nil {
+		if err := d.nfsMount(
+			deviceName, mountPoint, opts.ReadOnly); err != nil {
 			return err
 		}
 		os.MkdirAll(d.volumeMountPath(mountPoint), d.fileModeMountPath())
@@ -141,10 +161,12 @@ func (d *driver) Mount(
 		return err
 	}
 
-	options := formatMountLabel("", opts.MountLabel)
-	options = fmt.Sprintf("%s,%s", opts.MountOptions, opts.MountLabel)
+	options := formatMountLabel(opts.MountOptions, opts.MountLabel)
 	if fsType == "xfs" {
-		options = fmt.Sprintf("%s,nouuid", opts.MountLabel)
+		options = joinMountOptions(options, "nouuid")
+	}
+	if err := validateMountOptions(fsType, options); err != nil {
+		return err
 	}
 
 	if err := mount(deviceName, mountPoint, fsType, options); err != nil {
@@ -180,7 +202,7 @@ func (d *driver) Unmount(
 		}
 	}
 
-	return unmount(mountPoint)
+	return d.unmount(ctx, mountPoint)
 }
 
 func (d *driver) IsMounted(
@@ -221,37 +243,59 @@ func (d *driver) Format(
 		"driverName":  driverName}).Info("probe information")
 
 	if opts.OverwriteFS || !fsDetected {
-		switch opts.NewFSType {
-		case "ext4":
-			if err := exec.Command(
-				"mkfs.ext4", "-F", deviceName).Run(); err != nil {
-				return goof.WithFieldE(
-					"deviceName", deviceName,
-					"error creating filesystem",
-					err)
-			}
-		case "xfs":
-			if err := exec.Command(
-				"mkfs.xfs", "-f", deviceName).Run(); err != nil {
-				return goof.WithFieldE(
-					"deviceName", deviceName,
-					"error creating filesystem",
-					err)
-			}
-		default:
-			return errUnsupportedFileSystem
+		args, err := mkfsArgs(opts.NewFSType, opts.NewFSOpts)
+		if err != nil {
+			return err
+		}
+		args = append(args, deviceName)
+		if err := utils.Command(
+			"mkfs."+opts.NewFSType, args...).Run(); err != nil {
+			return goof.WithFieldE(
+				"deviceName", deviceName,
+				"error creating filesystem",
+				err)
 		}
 	}
 
 	return nil
 }
 
+// mkfsArgs returns the arguments used to create a file system of the given
+// type, not including the device. The file system is created by the type's
+// mkfs.<type> executable, which is forced to overwrite an existing file
+// system if the type is known to require it.
+func mkfsArgs(fsType string, fsOpts []string) ([]string, error) {
+	var args []string
+	switch fsType {
+	case "":
+		return nil, errUnsupportedFileSystem
+	case "ext2", "ext3", "ext4":
+		args = []string{"-F"}
+	case "xfs", "btrfs":
+		args = []string{"-f"}
+	}
+	for _, o := range fsOpts {
+		// a path could cause mkfs to format a device other than the
+		// volume's device
+		if strings.HasPrefix(o, "/") {
+			return nil, goof.WithField(
+				"option", o, "invalid file system option")
+		}
+		args = append(args, o)
+	}
+	return args, nil
+}
+
 func (d *driver) isNfsDevice(device string) bool {
 	return strings.Contains(device, ":")
 }
 
-func (d *driver) nfsMount(device, target string) error {
-	command := exec.Command("mount", device, target)
+func (d *driver) nfsMount(device, target string, readOnly bool) error {
+	args := []string{device, target}
+	if readOnly {
+		args = append([]string{"-o", "ro"}, args...)
+	}
+	command := utils.Command("mount", args...)
 	output, err := command.CombinedOutput()
 	if err != nil {
 		return goof.WithError(fmt.Sprintf("failed mounting: %s", output), err)
@@ -260,6 +304,23 @@ func (d *driver) nfsMount(device, target string) error {
 	return nil
 }
 
+// helperMount mounts a device with the mount helper of its file system, ex.
+// mount.efs, which receives the mount options unchanged.
+func (d *driver) helperMount(device, target, fsType, options string) error {
+	args := []string{"-t", fsType}
+	if options != "" {
+		args = append(args, "-o", options)
+	}
+	args = append(args, device, target)
+	command := utils.Command("mount", args...)
+	output, err := command.CombinedOutput()
+	if err != nil {
+		return goof.WithFieldE("fsType", fsType,
+			fmt.Sprintf("failed mounting: %s", output), err)
+	}
+	return nil
+}
+
 func (d *driver) fileModeMountPath() (fileMode os.FileMode) {
 	return os.FileMode(d.volumeFileMode())
 }
@@ -326,3 +387,19 @@ func (d *driver) volumeFileMode() int {
 func (d *driver) volumeRootPath() string {
 	return d.config.GetString("linux.volume.rootpath")
 }
+
+func (d *driver) unmountPolicy() string {
+	v := d.config.GetString("linux.unmount.policy")
+	if v == "" {
+		return unmountPolicyFail
+	}
+	return strings.ToLower(v)
+}
+
+func (d *driver) unmountTimeout() time.Duration {
+	v, err := time.ParseDuration(d.config.GetString("linux.unmount.timeout"))
+	if err != nil || v <= 0 {
+		return defaultUnmountTimeout
+	}
+	return v
+}
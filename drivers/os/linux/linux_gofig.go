@@ -11,5 +11,7 @@ func init() {
 	r := gofigCore.NewRegistration("Linux")
 	r.Key(gofig.Int, "", 0700, "", "linux.volume.filemode")
 	r.Key(gofig.String, "", "/data", "", "linux.volume.rootpath")
+	r.Key(gofig.String, "", "fail", "", "linux.unmount.policy")
+	r.Key(gofig.String, "", "10s", "", "linux.unmount.timeout")
 	gofigCore.Register(r)
 }
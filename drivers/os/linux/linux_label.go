@@ -4,6 +4,7 @@ package linux
 
 import (
 	"fmt"
+	"os"
 )
 
 /*
@@ -17,7 +18,7 @@ the first parameter.  Second parameter is the label that you wish to apply
 to all content in the mount point.
 */
 func formatMountLabel(src, mountLabel string) string {
-	if mountLabel != "" {
+	if mountLabel != "" && selinuxEnabled() {
 		switch src {
 		case "":
 			src = fmt.Sprintf("context=%q", mountLabel)
@@ -27,3 +28,20 @@ func formatMountLabel(src, mountLabel string) string {
 	}
 	return src
 }
+
+var selinuxEnforceFiles = []string{
+	"/sys/fs/selinux/enforce",
+	"/selinux/enforce",
+}
+
+// selinuxEnabled returns a flag indicating whether or not SELinux is enabled
+// on the host. Mount labels are ignored when it is not, since the kernel
+// rejects the context mount option.
+func selinuxEnabled() bool {
+	for _, f := range selinuxEnforceFiles {
+		if _, err := os.Stat(f); err == nil {
+			return true
+		}
+	}
+	return false
+}
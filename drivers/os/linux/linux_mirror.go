@@ -0,0 +1,142 @@
+// +build linux
+
+package linux
+
+import (
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/akutz/goof"
+	"github.com/akutz/gotil"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+const (
+	mdadmCmd = "mdadm"
+	mdDir    = "/dev/md"
+)
+
+// mirrorDevicePath returns the path of the md device for a mirror.
+func mirrorDevicePath(name string) string {
+	return path.Join(mdDir, name)
+}
+
+// hasMDSuperblock returns a flag indicating whether or not the device is a
+// member of an md array.
+func hasMDSuperblock(ctx types.Context, device string) bool {
+	return utils.CommandContext(
+		ctx, mdadmCmd, "--examine", device).Run() == nil
+}
+
+// checkNoFileSystem returns an error if the device has a file system, which
+// would be destroyed if the device were mirrored.
+func checkNoFileSystem(device string) error {
+	if fsType, _ := probeFsType(device); fsType != "" {
+		return goof.WithFields(goof.Fields{
+			"device": device,
+			"fsType": fsType,
+		}, "cannot mirror device with existing file system")
+	}
+	return nil
+}
+
+// MirrorAssemble assembles the devices into an md RAID1 device. Devices that
+// have been mirrored before are assembled even if one of them is missing so
+// that the mirror's data remains available while it is degraded. Devices that
+// have never been mirrored, including those that replace a missing member,
+// are only mirrored if they do not have a file system.
+func (d *driver) MirrorAssemble(
+	ctx types.Context,
+	name string,
+	devices []string,
+	opts types.Store) (string, error) {
+
+	device := mirrorDevicePath(name)
+	if gotil.FileExists(device) {
+		return device, nil
+	}
+
+	fields := log.Fields{
+		"mirror":  device,
+		"devices": devices,
+	}
+
+	var members, replaced []string
+	for _, dev := range devices {
+		if hasMDSuperblock(ctx, dev) {
+			members = append(members, dev)
+		} else {
+			replaced = append(replaced, dev)
+		}
+	}
+
+	for _, dev := range replaced {
+		if err := checkNoFileSystem(dev); err != nil {
+			return "", err
+		}
+	}
+
+	if len(members) > 0 {
+		ctx.WithFields(fields).Info("assembling mirror")
+		args := append([]string{"--assemble", "--run", device}, members...)
+		if err := runMdadm(ctx, args...); err != nil {
+			return "", err
+		}
+		// a device that was replaced while the mirror was degraded is added
+		// back so that the mirror is rebuilt onto it
+		for _, dev := range replaced {
+			ctx.WithField("device", dev).Warn("rebuilding mirror device")
+			if err := runMdadm(
+				ctx, "--manage", device, "--add", dev); err != nil {
+				return "", err
+			}
+		}
+		return device, nil
+	}
+
+	if err := os.MkdirAll(mdDir, 0755); err != nil {
+		return "", err
+	}
+
+	ctx.WithFields(fields).Info("creating mirror")
+	args := append([]string{
+		"--create", device, "--run", "--level=1", "--metadata=1.2",
+		"--raid-devices=" + strconv.Itoa(len(devices)),
+	}, devices...)
+	if err := runMdadm(ctx, args...); err != nil {
+		return "", err
+	}
+	return device, nil
+}
+
+// MirrorStop stops the md device for a mirror if it is assembled.
+func (d *driver) MirrorStop(
+	ctx types.Context,
+	name string,
+	opts types.Store) error {
+
+	device := mirrorDevicePath(name)
+	if !gotil.FileExists(device) {
+		return nil
+	}
+	ctx.WithField("mirror", device).Info("stopping mirror")
+	return runMdadm(ctx, "--stop", device)
+}
+
+// runMdadm runs mdadm with the specified arguments.
+func runMdadm(ctx types.Context, args ...string) error {
+	out, err := utils.CommandContext(
+		ctx, mdadmCmd, args...).CombinedOutput()
+	if err != nil {
+		return goof.WithFieldsE(goof.Fields{
+			"args":   args,
+			"output": strings.TrimSpace(string(out)),
+		}, "error running mdadm", err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// +build linux
+
+package linux
+
+import (
+	"strings"
+
+	"github.com/akutz/goof"
+)
+
+// fsMountOpts are the file system specific mount options that are supported
+// for each type of file system. Options that are not file system specific,
+// such as "ro" or "noatime", are always supported.
+var fsMountOpts = map[string][]string{
+	"ext4": {
+		"acl", "noacl", "auto_da_alloc", "noauto_da_alloc", "barrier",
+		"nobarrier", "commit", "data", "delalloc", "nodelalloc",
+		"dioread_lock", "dioread_nolock", "errors", "grpquota",
+		"init_itable", "noinit_itable", "journal_checksum",
+		"nojournal_checksum", "noload", "prjquota", "quota", "noquota",
+		"resgid", "resuid", "stripe", "user_xattr", "nouser_xattr",
+		"usrquota",
+	},
+	"xfs": {
+		"allocsize", "attr2", "noattr2", "filestreams", "gquota",
+		"grpquota", "ikeep", "noikeep", "inode32", "inode64", "largeio",
+		"nolargeio", "logbsize", "logbufs", "nouuid", "pquota", "prjquota",
+		"quota", "noquota", "sunit", "swalloc", "swidth", "uquota",
+		"usrquota", "wsync",
+	},
+	"btrfs": {
+		"acl", "noacl", "autodefrag", "noautodefrag", "barrier",
+		"nobarrier", "commit", "compress", "compress-force", "datacow",
+		"nodatacow", "datasum", "nodatasum", "degraded", "device",
+		"space_cache", "nospace_cache", "ssd", "nossd", "ssd_spread",
+		"subvol", "subvolid", "thread_pool", "user_subvol_rm_allowed",
+	},
+}
+
+// commonMountOpts are the mount options passed to the file system that all
+// of the file systems in fsMountOpts support.
+var commonMountOpts = []string{
+	"context", "fscontext", "defcontext", "rootcontext",
+	"discard", "nodiscard", "lazytime", "nolazytime",
+}
+
+// validateMountOptions returns an error if the provided, comma-separated
+// mount options include an option that is not supported by the file system.
+// The options for file systems other than those in fsMountOpts are not
+// validated.
+func validateMountOptions(fsType, options string) error {
+	supported, ok := fsMountOpts[fsType]
+	if !ok {
+		return nil
+	}
+	_, data := parseOptions(options)
+	for _, o := range strings.Split(data, ",") {
+		if o == "" {
+			continue
+		}
+		name := strings.SplitN(o, "=", 2)[0]
+		if !containsString(supported, name) &&
+			!containsString(commonMountOpts, name) {
+			return goof.WithFields(goof.Fields{
+				"fsType": fsType,
+				"option": o,
+			}, "unsupported mount option")
+		}
+	}
+	return nil
+}
+
+// joinMountOptions joins the non-empty, comma-separated mount options.
+func joinMountOptions(options ...string) string {
+	var opts []string
+	for _, o := range options {
+		if o != "" {
+			opts = append(opts, o)
+		}
+	}
+	return strings.Join(opts, ",")
+}
+
+func containsString(a []string, s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
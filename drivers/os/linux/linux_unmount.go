@@ -0,0 +1,200 @@
+// +build linux
+
+package linux
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+const (
+	// unmountPolicyFail fails an unmount of a busy mount point.
+	unmountPolicyFail = "fail"
+
+	// unmountPolicyRetry retries an unmount of a busy mount point with
+	// backoff until the unmount timeout elapses.
+	unmountPolicyRetry = "retry"
+
+	// unmountPolicyLazy retries an unmount of a busy mount point and then
+	// lazily unmounts it, detaching it now and cleaning it up once it is no
+	// longer busy.
+	unmountPolicyLazy = "lazy"
+
+	// unmountPolicyForce retries an unmount of a busy mount point and then
+	// forcefully unmounts it, falling back to a lazy unmount.
+	unmountPolicyForce = "force"
+
+	unmountBackoffMin = 100 * time.Millisecond
+	unmountBackoffMax = 2 * time.Second
+
+	defaultUnmountTimeout = 10 * time.Second
+)
+
+// mountHolder is a process that holds a mount point open.
+type mountHolder struct {
+	pid  int
+	name string
+}
+
+func (h *mountHolder) String() string {
+	return fmt.Sprintf("%d (%s)", h.pid, h.name)
+}
+
+// unmount unmounts the mount point according to the configured unmount
+// policy if the mount point is busy.
+func (d *driver) unmount(ctx types.Context, mountPoint string) error {
+
+	if ok, err := mounted(mountPoint); err != nil || !ok {
+		return err
+	}
+
+	err := forceUnmount(mountPoint)
+	if err == nil || err != syscall.EBUSY {
+		return err
+	}
+
+	policy := d.unmountPolicy()
+	fields := log.Fields{
+		"mountPoint": mountPoint,
+		"policy":     policy,
+	}
+
+	switch policy {
+	case unmountPolicyRetry, unmountPolicyLazy, unmountPolicyForce:
+		ctx.WithFields(fields).Info("mount point busy; retrying unmount")
+		if err = unmountWithBackoff(
+			mountPoint, d.unmountTimeout()); err != syscall.EBUSY {
+			return err
+		}
+	}
+
+	holders := mountHolders(mountPoint)
+
+	switch policy {
+	case unmountPolicyForce:
+		ctx.WithFields(fields).WithField("holders", holders).Warn(
+			"forcefully unmounting busy mount point")
+		if err = sysUnmount(mountPoint, syscall.MNT_FORCE); err == nil {
+			return nil
+		}
+		fallthrough
+	case unmountPolicyLazy:
+		ctx.WithFields(fields).WithField("holders", holders).Warn(
+			"lazily unmounting busy mount point")
+		if err = sysUnmount(mountPoint, syscall.MNT_DETACH); err == nil {
+			return nil
+		}
+	}
+
+	return goof.WithFieldsE(goof.Fields{
+		"mountPoint": mountPoint,
+		"holders":    holders,
+	}, "mount point is busy", err)
+}
+
+// unmountWithBackoff retries the unmount of a busy mount point with
+// exponential backoff until the timeout elapses.
+func unmountWithBackoff(mountPoint string, timeout time.Duration) error {
+	var (
+		err      error
+		backoff  = unmountBackoffMin
+		deadline = time.Now().Add(timeout)
+	)
+	for {
+		if err = sysUnmount(mountPoint, 0); err != syscall.EBUSY {
+			return err
+		}
+		if time.Now().Add(backoff).After(deadline) {
+			return err
+		}
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > unmountBackoffMax {
+			backoff = unmountBackoffMax
+		}
+	}
+}
+
+// mountHolders returns the processes that have a working directory, root
+// directory, executable, open file, or memory-mapped file beneath the mount
+// point.
+func mountHolders(mountPoint string) []*mountHolder {
+	dirs, err := ioutil.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+
+	var holders []*mountHolder
+	for _, dir := range dirs {
+		pid, err := strconv.Atoi(dir.Name())
+		if err != nil {
+			continue
+		}
+		procDir := filepath.Join("/proc", dir.Name())
+		if !isHoldingMount(procDir, mountPoint) {
+			continue
+		}
+		comm, _ := ioutil.ReadFile(filepath.Join(procDir, "comm"))
+		holders = append(holders, &mountHolder{
+			pid:  pid,
+			name: strings.TrimSpace(string(comm)),
+		})
+	}
+	return holders
+}
+
+// isHoldingMount returns a flag indicating whether or not the process with
+// the provided /proc directory holds the mount point open.
+func isHoldingMount(procDir, mountPoint string) bool {
+	for _, l := range []string{"cwd", "root", "exe"} {
+		if p, err := os.Readlink(
+			filepath.Join(procDir, l)); err == nil &&
+			isBeneath(p, mountPoint) {
+			return true
+		}
+	}
+
+	fdDir := filepath.Join(procDir, "fd")
+	if fds, err := ioutil.ReadDir(fdDir); err == nil {
+		for _, fd := range fds {
+			if p, err := os.Readlink(
+				filepath.Join(fdDir, fd.Name())); err == nil &&
+				isBeneath(p, mountPoint) {
+				return true
+			}
+		}
+	}
+
+	f, err := os.Open(filepath.Join(procDir, "maps"))
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// the path of a mapped file is the sixth field
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 6 && isBeneath(fields[5], mountPoint) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBeneath returns a flag indicating whether or not the path is the
+// directory or is beneath it.
+func isBeneath(path, dir string) bool {
+	dir = strings.TrimSuffix(dir, "/")
+	return path == dir || strings.HasPrefix(path, dir+"/")
+}
@@ -0,0 +1,296 @@
+// +build windows
+
+package windows
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+const (
+	driverName = "windows"
+
+	// fsTypeNTFS is the only file system with which the driver formats
+	// devices.
+	fsTypeNTFS = "ntfs"
+)
+
+var (
+	errUnknownOS             = goof.New("unknown OS")
+	errUnsupportedFileSystem = goof.New("unsupported file system")
+	errUnsupportedFSOpts     = goof.New("unsupported file system options")
+)
+
+func init() {
+	registry.RegisterOSDriver(driverName, newDriver)
+}
+
+type driver struct {
+	config gofig.Config
+}
+
+func newDriver() types.OSDriver {
+	return &driver{}
+}
+
+func (d *driver) Init(ctx types.Context, config gofig.Config) error {
+	if runtime.GOOS != "windows" {
+		return errUnknownOS
+	}
+	d.config = config
+	return nil
+}
+
+func (d *driver) Name() string {
+	return driverName
+}
+
+// partition is a partition as emitted by the PowerShell Get-Partition cmdlet.
+type partition struct {
+	DiskNumber      int      `json:"DiskNumber"`
+	PartitionNumber int      `json:"PartitionNumber"`
+	AccessPaths     []string `json:"AccessPaths"`
+	FileSystem      string   `json:"FileSystem"`
+}
+
+func (d *driver) Mounts(
+	ctx types.Context,
+	deviceName, mountPoint string,
+	opts types.Store) ([]*types.MountInfo, error) {
+
+	if mountPoint != "" && deviceName != "" {
+		return nil, goof.New("cannot specify mountPoint and deviceName")
+	}
+
+	diskNumber := -1
+	if deviceName != "" {
+		n, err := utils.DiskNumber(deviceName)
+		if err != nil {
+			return nil, err
+		}
+		diskNumber = n
+	}
+
+	out, err := utils.PowerShell(
+		ctx,
+		"@(Get-Partition | Select-Object "+
+			"DiskNumber,PartitionNumber,AccessPaths,"+
+			"@{n='FileSystem';e={"+
+			"($_ | Get-Volume -ErrorAction SilentlyContinue).FileSystem}}) | "+
+			"ConvertTo-Json -Compress")
+	if err != nil {
+		return nil, err
+	}
+
+	parts, err := parsePartitions(out)
+	if err != nil {
+		return nil, goof.WithError("error parsing partitions", err)
+	}
+
+	mounts := []*types.MountInfo{}
+	for _, p := range parts {
+		if diskNumber >= 0 && p.DiskNumber != diskNumber {
+			continue
+		}
+		for _, ap := range p.AccessPaths {
+			// skip the volume GUID paths every volume has
+			if strings.HasPrefix(ap, `\\?\`) {
+				continue
+			}
+			mp := cleanMountPoint(ap)
+			if mountPoint != "" &&
+				!strings.EqualFold(mp, cleanMountPoint(mountPoint)) {
+				continue
+			}
+			mounts = append(mounts, &types.MountInfo{
+				Source:     utils.DiskPath(p.DiskNumber),
+				MountPoint: mp,
+				FSType:     strings.ToLower(p.FileSystem),
+			})
+		}
+	}
+	return mounts, nil
+}
+
+// Mount mounts the data partition of the disk at the mount point, which may
+// be either a drive letter, ex. "F:", or an empty directory on an NTFS
+// volume. The directory is created if it does not exist.
+func (d *driver) Mount(
+	ctx types.Context,
+	deviceName, mountPoint string,
+	opts *types.DeviceMountOpts) error {
+
+	n, err := utils.DiskNumber(deviceName)
+	if err != nil {
+		return err
+	}
+
+	if !isDriveLetter(mountPoint) {
+		if err := os.MkdirAll(mountPoint, 0755); err != nil {
+			return err
+		}
+	}
+
+	ap := utils.PowerShellQuote(accessPath(mountPoint))
+	script := onlineDiskScript(n) + dataPartitionScript(n) + fmt.Sprintf(
+		"if ($p -eq $null) { throw 'disk %[1]d has no data partition' }; "+
+			"if ($p.AccessPaths -notcontains %[2]s) { "+
+			"Add-PartitionAccessPath -DiskNumber %[1]d "+
+			"-PartitionNumber $p.PartitionNumber -AccessPath %[2]s }",
+		n, ap)
+
+	_, err = utils.PowerShell(ctx, script)
+	return err
+}
+
+func (d *driver) Unmount(
+	ctx types.Context,
+	mountPoint string,
+	opts types.Store) error {
+
+	ap := utils.PowerShellQuote(accessPath(mountPoint))
+	_, err := utils.PowerShell(ctx, fmt.Sprintf(
+		"$p = Get-Partition | "+
+			"Where-Object { $_.AccessPaths -contains %[1]s } | "+
+			"Select-Object -First 1; "+
+			"if ($p -ne $null) { Remove-PartitionAccessPath "+
+			"-DiskNumber $p.DiskNumber -PartitionNumber $p.PartitionNumber "+
+			"-AccessPath %[1]s }",
+		ap))
+	return err
+}
+
+func (d *driver) IsMounted(
+	ctx types.Context,
+	mountPoint string,
+	opts types.Store) (bool, error) {
+
+	mounts, err := d.Mounts(ctx, "", mountPoint, opts)
+	if err != nil {
+		return false, err
+	}
+	return len(mounts) > 0, nil
+}
+
+// Format brings the disk online, initializes it with a GPT partition table
+// if it is not initialized, creates a data partition that fills the disk if
+// one does not exist, and formats the partition with NTFS if it does not
+// have a file system or if OverwriteFS is set.
+func (d *driver) Format(
+	ctx types.Context,
+	deviceName string,
+	opts *types.DeviceFormatOpts) error {
+
+	if fsType := strings.ToLower(opts.NewFSType); fsType != "" &&
+		fsType != fsTypeNTFS {
+		return goof.WithFieldE(
+			"fsType", opts.NewFSType, "error formatting device",
+			errUnsupportedFileSystem)
+	}
+	if len(opts.NewFSOpts) > 0 {
+		return goof.WithFieldE(
+			"fsOpts", opts.NewFSOpts, "error formatting device",
+			errUnsupportedFSOpts)
+	}
+
+	n, err := utils.DiskNumber(deviceName)
+	if err != nil {
+		return err
+	}
+
+	force := "$false"
+	if opts.OverwriteFS {
+		force = "$true"
+	}
+
+	script := onlineDiskScript(n) + fmt.Sprintf(
+		"if ((Get-Disk -Number %[1]d).PartitionStyle -eq 'RAW') { "+
+			"Initialize-Disk -Number %[1]d -PartitionStyle GPT }; ",
+		n) + dataPartitionScript(n) + fmt.Sprintf(
+		"if ($p -eq $null) { "+
+			"$p = New-Partition -DiskNumber %[1]d -UseMaximumSize }; "+
+			"$v = $p | Get-Volume -ErrorAction SilentlyContinue; "+
+			"if (%[2]s -or $v -eq $null -or -not $v.FileSystem) { "+
+			"Format-Volume -Partition $p -FileSystem NTFS "+
+			"-Confirm:$false -Force | Out-Null }",
+		n, force)
+
+	_, err = utils.PowerShell(ctx, script)
+	return err
+}
+
+// onlineDiskScript returns a script that brings the disk online and makes it
+// writable. Windows leaves newly attached disks offline by default on
+// server editions.
+func onlineDiskScript(n int) string {
+	return fmt.Sprintf(
+		"$d = Get-Disk -Number %[1]d; "+
+			"if ($d.IsOffline) { Set-Disk -Number %[1]d -IsOffline $false }; "+
+			"if ($d.IsReadOnly) { "+
+			"Set-Disk -Number %[1]d -IsReadOnly $false }; ",
+		n)
+}
+
+// dataPartitionScript returns a script that assigns the disk's largest data
+// partition to $p. The data partitions of GPT disks are of type Basic and
+// those of MBR disks are of type IFS.
+func dataPartitionScript(n int) string {
+	return fmt.Sprintf(
+		"$p = Get-Partition -DiskNumber %d -ErrorAction SilentlyContinue | "+
+			"Where-Object { $_.Type -eq 'Basic' -or $_.Type -eq 'IFS' } | "+
+			"Sort-Object Size -Descending | Select-Object -First 1; ",
+		n)
+}
+
+// parsePartitions parses the JSON output of the PowerShell Get-Partition
+// cmdlet.
+func parsePartitions(buf []byte) ([]*partition, error) {
+	buf = []byte(strings.TrimSpace(string(buf)))
+	if len(buf) == 0 {
+		return nil, nil
+	}
+	if buf[0] == '{' {
+		p := &partition{}
+		if err := json.Unmarshal(buf, p); err != nil {
+			return nil, err
+		}
+		return []*partition{p}, nil
+	}
+	var parts []*partition
+	if err := json.Unmarshal(buf, &parts); err != nil {
+		return nil, err
+	}
+	return parts, nil
+}
+
+var driveLetterRX = regexp.MustCompile(`^[a-zA-Z]:\\?$`)
+
+// isDriveLetter returns a flag indicating whether or not the mount point is
+// a drive letter, ex. "F:".
+func isDriveLetter(mountPoint string) bool {
+	return driveLetterRX.MatchString(mountPoint)
+}
+
+// cleanMountPoint returns the mount point without a trailing separator, ex.
+// "F:" or "C:\mnt\vol".
+func cleanMountPoint(mountPoint string) string {
+	return strings.TrimSuffix(filepath.Clean(mountPoint), `\`)
+}
+
+// accessPath returns the mount point as a partition access path, which
+// always has a trailing separator, ex. "F:\" or "C:\mnt\vol\".
+func accessPath(mountPoint string) string {
+	return cleanMountPoint(mountPoint) + `\`
+}
@@ -0,0 +1,47 @@
+package env
+
+import (
+	"os"
+
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+const (
+	// Name is the name of the provider.
+	Name = "env"
+)
+
+type provider struct{}
+
+func init() {
+	registry.RegisterSecretsProvider(Name, newProvider)
+}
+
+func newProvider() types.SecretsProvider {
+	return &provider{}
+}
+
+func (p *provider) Name() string {
+	return Name
+}
+
+func (p *provider) Init(ctx types.Context, config gofig.Config) error {
+	return nil
+}
+
+// Secret returns the value of the environment variable with the name
+// specified by the path, ex. secret://env/AWS_SECRET_ACCESS_KEY. The key is
+// ignored.
+func (p *provider) Secret(
+	ctx types.Context, path, key string) (string, error) {
+
+	v, ok := os.LookupEnv(path)
+	if !ok {
+		return "", goof.WithField("name", path, "env var not set")
+	}
+	return v, nil
+}
@@ -0,0 +1,74 @@
+package file
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+const (
+	// Name is the name of the provider.
+	Name = "file"
+)
+
+type provider struct{}
+
+func init() {
+	registry.RegisterSecretsProvider(Name, newProvider)
+}
+
+func newProvider() types.SecretsProvider {
+	return &provider{}
+}
+
+func (p *provider) Name() string {
+	return Name
+}
+
+func (p *provider) Init(ctx types.Context, config gofig.Config) error {
+	return nil
+}
+
+// Secret returns the contents of the file specified by the path, ex.
+// secret://file/etc/ceph/client.admin.key, without any trailing whitespace.
+// If a key is specified then the file must contain a JSON object, and the
+// value of the key in the object is returned.
+func (p *provider) Secret(
+	ctx types.Context, path, key string) (string, error) {
+
+	if !filepath.IsAbs(path) {
+		path = "/" + path
+	}
+
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	if key == "" {
+		return strings.TrimRight(string(buf), "\r\n\t "), nil
+	}
+
+	data := map[string]interface{}{}
+	if err := json.Unmarshal(buf, &data); err != nil {
+		return "", goof.WithFieldE(
+			"path", path, "error reading secret file", err)
+	}
+
+	v, ok := data[key]
+	if !ok {
+		return "", goof.WithFields(goof.Fields{
+			"path": path,
+			"key":  key,
+		}, "secret key not found")
+	}
+	return fmt.Sprintf("%v", v), nil
+}
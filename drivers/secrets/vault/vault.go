@@ -0,0 +1,167 @@
+package vault
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	gofigCore "github.com/akutz/gofig"
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+const (
+	// Name is the name of the provider.
+	Name = "vault"
+
+	// ConfigAddress is the config key for the address of the Vault server.
+	ConfigAddress = "libstorage.secrets.vault.address"
+
+	// ConfigToken is the config key for the Vault token.
+	ConfigToken = "libstorage.secrets.vault.token"
+
+	// ConfigTokenFile is the config key for the path to a file that
+	// contains the Vault token.
+	ConfigTokenFile = "libstorage.secrets.vault.tokenFile"
+
+	// ConfigTimeout is the config key for the timeout of requests to the
+	// Vault server.
+	ConfigTimeout = "libstorage.secrets.vault.timeout"
+
+	defaultAddress = "https://127.0.0.1:8200"
+)
+
+type provider struct {
+	address   string
+	token     string
+	tokenFile string
+	client    *http.Client
+}
+
+func init() {
+	registry.RegisterSecretsProvider(Name, newProvider)
+
+	r := gofigCore.NewRegistration("Vault")
+	r.Key(gofig.String, "", "", "", ConfigAddress)
+	r.Key(gofig.String, "", "", "", ConfigToken)
+	r.Key(gofig.String, "", "", "", ConfigTokenFile)
+	r.Key(gofig.String, "", "10s", "", ConfigTimeout)
+	gofigCore.Register(r)
+}
+
+func newProvider() types.SecretsProvider {
+	return &provider{}
+}
+
+func (p *provider) Name() string {
+	return Name
+}
+
+func (p *provider) Init(ctx types.Context, config gofig.Config) error {
+
+	// the address and token fall back to the environment variables used by
+	// the vault cli
+	if p.address = config.GetString(ConfigAddress); p.address == "" {
+		if p.address = os.Getenv("VAULT_ADDR"); p.address == "" {
+			p.address = defaultAddress
+		}
+	}
+	p.address = strings.TrimSuffix(p.address, "/")
+
+	if p.token = config.GetString(ConfigToken); p.token == "" {
+		p.token = os.Getenv("VAULT_TOKEN")
+	}
+	p.tokenFile = config.GetString(ConfigTokenFile)
+
+	timeout, err := time.ParseDuration(config.GetString(ConfigTimeout))
+	if err != nil {
+		timeout = 10 * time.Second
+	}
+	p.client = &http.Client{Timeout: timeout}
+
+	ctx.WithField("address", p.address).Info("vault secrets provider initialized")
+	return nil
+}
+
+// getToken returns the vault token. A token file is read each time it is
+// needed so that a token renewed by an agent is used.
+func (p *provider) getToken() (string, error) {
+	if p.tokenFile == "" {
+		return p.token, nil
+	}
+	buf, err := ioutil.ReadFile(p.tokenFile)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(buf)), nil
+}
+
+// Secret returns the value of the key in the secret at the specified path,
+// ex. secret://vault/secret/libstorage/aws#secretKey. Both version 1 and
+// version 2 of the vault key/value secrets engine are supported. For
+// version 2 the path must include the data prefix, ex.
+// secret://vault/secret/data/libstorage/aws#secretKey.
+func (p *provider) Secret(
+	ctx types.Context, path, key string) (string, error) {
+
+	if key == "" {
+		return "", goof.WithField("path", path, "vault secret key required")
+	}
+
+	token, err := p.getToken()
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/v1/%s", p.address, strings.TrimPrefix(path, "/"))
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", goof.WithFields(goof.Fields{
+			"path":   path,
+			"status": res.StatusCode,
+		}, "error reading vault secret")
+	}
+
+	reply := struct {
+		Data map[string]interface{} `json:"data"`
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&reply); err != nil {
+		return "", err
+	}
+
+	data := reply.Data
+
+	// version 2 of the key/value engine nests the secret's data
+	if _, ok := data["metadata"]; ok {
+		if v2, ok := data["data"].(map[string]interface{}); ok {
+			data = v2
+		}
+	}
+
+	v, ok := data[key]
+	if !ok {
+		return "", goof.WithFields(goof.Fields{
+			"path": path,
+			"key":  key,
+		}, "secret key not found")
+	}
+	return fmt.Sprintf("%v", v), nil
+}
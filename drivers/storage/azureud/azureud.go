@@ -54,6 +54,19 @@ const (
 
 	// TagKey is a tag key
 	TagKey = "tag"
+
+	// UseManagedIdentityKey is a flag about authenticating with the
+	// managed identity of the virtual machine on which the driver runs
+	UseManagedIdentityKey = "useManagedIdentity"
+
+	// ManagedIdentityEndpointKey is the URL of the endpoint from which
+	// tokens for a managed identity are requested
+	ManagedIdentityEndpointKey = "managedIdentityEndpoint"
+
+	// DefaultManagedIdentityEndpoint is the Azure Instance Metadata
+	// Service's managed identity token endpoint
+	DefaultManagedIdentityEndpoint = "http://169.254.169.254" +
+		"/metadata/identity/oauth2/token"
 )
 
 const (
@@ -92,6 +105,14 @@ const (
 
 	// ConfigAzureTagKey is a config key
 	ConfigAzureTagKey = ConfigAzure + "." + TagKey
+
+	// ConfigAzureUseManagedIdentityKey is a config key
+	ConfigAzureUseManagedIdentityKey = ConfigAzure + "." +
+		UseManagedIdentityKey
+
+	// ConfigAzureManagedIdentityEndpointKey is a config key
+	ConfigAzureManagedIdentityEndpointKey = ConfigAzure + "." +
+		ManagedIdentityEndpointKey
 )
 
 func init() {
@@ -108,6 +129,12 @@ func init() {
 	r.Key(gofig.Bool, "", DefaultUseHTTPS, "", ConfigAzureUseHTTPSKey)
 	r.Key(gofig.String, "", "",
 		"Tag prefix for Azure naming", ConfigAzureTagKey)
+	r.Key(gofig.Bool, "", false,
+		"Authenticate with the VM's managed identity",
+		ConfigAzureUseManagedIdentityKey)
+	r.Key(gofig.String, "", DefaultManagedIdentityEndpoint,
+		"Managed identity token endpoint",
+		ConfigAzureManagedIdentityEndpointKey)
 
 	gofigCore.Register(r)
 }
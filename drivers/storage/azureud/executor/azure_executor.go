@@ -3,18 +3,11 @@
 package executor
 
 import (
-	"bufio"
-	"bytes"
 	"fmt"
-	"os/exec"
 	"regexp"
-	"strings"
-
-	log "github.com/Sirupsen/logrus"
 
 	gofig "github.com/akutz/gofig/types"
 	"github.com/akutz/goof"
-	"github.com/akutz/gotil"
 
 	"github.com/codedellemc/libstorage/api/registry"
 	"github.com/codedellemc/libstorage/api/types"
@@ -52,8 +45,7 @@ func (d *driver) Supported(
 	ctx types.Context,
 	opts types.Store) (bool, error) {
 
-	if !gotil.FileExistsInPath("lsscsi") {
-		ctx.Error("lsscsi executable not found in PATH")
+	if !supported(ctx) {
 		return false, nil
 	}
 
@@ -108,65 +100,3 @@ func (d *driver) NextDevice(
 	}
 	return "", errNoAvaiDevice
 }
-
-var (
-	devRX  = regexp.MustCompile(`^/dev/sd[c-z]$`)
-	scsiRx = regexp.MustCompile(`^\[\d+:\d+:\d+:(\d+)\]$`)
-)
-
-// Retrieve device paths currently attached and/or mounted
-func (d *driver) LocalDevices(
-	ctx types.Context,
-	opts *types.LocalDevicesOpts) (*types.LocalDevices, error) {
-
-	// Read all of the attached devices
-	scsiDevs, err := getSCSIDevs()
-	if err != nil {
-		return nil, err
-	}
-
-	devMap := map[string]string{}
-
-	scanner := bufio.NewScanner(bytes.NewReader(scsiDevs))
-	for scanner.Scan() {
-		fields := strings.Fields(scanner.Text())
-		device := fields[len(fields)-1]
-		if !devRX.MatchString(device) {
-			continue
-		}
-
-		matches := scsiRx.FindStringSubmatch(fields[0])
-		if matches == nil {
-			continue
-		}
-
-		lun := matches[1]
-		devMap[device] = lun
-	}
-
-	ld := &types.LocalDevices{Driver: d.Name()}
-	if len(devMap) > 0 {
-		ld.DeviceMap = devMap
-	}
-
-	ctx.WithField("devicemap", ld.DeviceMap).Debug("local devices")
-
-	return ld, nil
-}
-
-func getSCSIDevs() ([]byte, error) {
-
-	out, err := exec.Command("lsscsi").Output()
-	if err != nil {
-		if exiterr, ok := err.(*exec.ExitError); ok {
-			stderr := string(exiterr.Stderr)
-			log.Errorf("Unable to get scsi devices: %s", stderr)
-			return nil,
-				goof.Newf("Unable to get scsi devices: %s",
-					stderr)
-		}
-		return nil, goof.WithError("Unable to get scsci devices", err)
-	}
-
-	return out, nil
-}
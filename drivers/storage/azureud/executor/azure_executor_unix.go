@@ -0,0 +1,92 @@
+// +build !windows
+// +build !libstorage_storage_executor libstorage_storage_executor_azureud
+
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/akutz/goof"
+	"github.com/akutz/gotil"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// supported returns a flag indicating whether or not the tools the executor
+// requires are installed.
+func supported(ctx types.Context) bool {
+	if !gotil.FileExistsInPath("lsscsi") {
+		ctx.Error("lsscsi executable not found in PATH")
+		return false
+	}
+	return true
+}
+
+var (
+	devRX  = regexp.MustCompile(`^/dev/sd[c-z]$`)
+	scsiRx = regexp.MustCompile(`^\[\d+:\d+:\d+:(\d+)\]$`)
+)
+
+// Retrieve device paths currently attached and/or mounted
+func (d *driver) LocalDevices(
+	ctx types.Context,
+	opts *types.LocalDevicesOpts) (*types.LocalDevices, error) {
+
+	// Read all of the attached devices
+	scsiDevs, err := getSCSIDevs()
+	if err != nil {
+		return nil, err
+	}
+
+	devMap := map[string]string{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(scsiDevs))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		device := fields[len(fields)-1]
+		if !devRX.MatchString(device) {
+			continue
+		}
+
+		matches := scsiRx.FindStringSubmatch(fields[0])
+		if matches == nil {
+			continue
+		}
+
+		lun := matches[1]
+		devMap[device] = lun
+	}
+
+	ld := &types.LocalDevices{Driver: d.Name()}
+	if len(devMap) > 0 {
+		ld.DeviceMap = devMap
+	}
+
+	ctx.WithField("devicemap", ld.DeviceMap).Debug("local devices")
+
+	return ld, nil
+}
+
+func getSCSIDevs() ([]byte, error) {
+
+	out, err := utils.Command("lsscsi").Output()
+	if err != nil {
+		if exiterr, ok := err.(*exec.ExitError); ok {
+			stderr := string(exiterr.Stderr)
+			log.Errorf("Unable to get scsi devices: %s", stderr)
+			return nil,
+				goof.Newf("Unable to get scsi devices: %s",
+					stderr)
+		}
+		return nil, goof.WithError("Unable to get scsci devices", err)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,62 @@
+// +build windows
+// +build !libstorage_storage_executor libstorage_storage_executor_azureud
+
+package executor
+
+import (
+	"strconv"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// supported returns a flag indicating whether or not the tools the executor
+// requires are installed.
+func supported(ctx types.Context) bool {
+	return true
+}
+
+// Retrieve device paths currently attached and/or mounted. Azure attaches
+// data disks to a different storage adapter than the OS and temporary
+// disks, so disks that share the boot disk's adapter are ignored. The
+// returned map's keys are the paths of the disks, ex. \\.\PHYSICALDRIVE2,
+// and its values the disks' LUNs.
+func (d *driver) LocalDevices(
+	ctx types.Context,
+	opts *types.LocalDevicesOpts) (*types.LocalDevices, error) {
+
+	disks, err := utils.Disks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bootAdapter := -1
+	for _, disk := range disks {
+		if disk.IsBoot {
+			bootAdapter = disk.Adapter()
+			break
+		}
+	}
+
+	devMap := map[string]string{}
+
+	for _, disk := range disks {
+		if disk.IsBoot || disk.IsSystem || disk.Adapter() == bootAdapter {
+			continue
+		}
+		lun := disk.LUN()
+		if lun < 0 {
+			continue
+		}
+		devMap[disk.Path()] = strconv.Itoa(lun)
+	}
+
+	ld := &types.LocalDevices{Driver: d.Name()}
+	if len(devMap) > 0 {
+		ld.DeviceMap = devMap
+	}
+
+	ctx.WithField("devicemap", ld.DeviceMap).Debug("local devices")
+
+	return ld, nil
+}
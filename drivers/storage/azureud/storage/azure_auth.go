@@ -0,0 +1,240 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_azureud
+
+package storage
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akutz/goof"
+
+	armStorage "github.com/Azure/azure-sdk-for-go/arm/storage"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/drivers/storage/azureud"
+)
+
+const (
+	// tokenRefreshWindow is how long before a token expires that the token
+	// is refreshed so that requests are not sent with a token that expires
+	// in flight.
+	tokenRefreshWindow = 5 * time.Minute
+
+	managedIdentityAPIVersion = "2018-02-01"
+)
+
+// authorizer returns the authorizer of requests to Azure Resource Manager.
+// The driver authenticates as a service principal with a client secret or
+// certificate, or as the managed identity of the VM on which it runs. The
+// tokens of both are refreshed automatically before they expire.
+func (d *driver) authorizer(ctx types.Context) (autorest.Authorizer, error) {
+
+	if d.useManagedIdentity {
+		ctx.Info("Authenticating via managed identity")
+		t := &managedIdentityToken{
+			endpoint: d.managedIdentityEndpoint,
+			clientID: d.clientID,
+			resource: azure.PublicCloud.ResourceManagerEndpoint,
+		}
+		// a VM without a managed identity is detected at login rather
+		// than by the first request
+		if _, err := t.ensureFresh(); err != nil {
+			return nil, err
+		}
+		return t, nil
+	}
+
+	var certData []byte
+	if d.clientSecret != "" {
+		ctx.Info("Authenticating via clientSecret")
+	} else {
+		ctx.Info("Authenticating via client certificate")
+		var err error
+		certData, err = ioutil.ReadFile(d.certPath)
+		if err != nil {
+			return nil, goof.WithError(
+				"Failed to read provided certificate file",
+				err)
+		}
+	}
+
+	oauthConfig, err := azure.PublicCloud.OAuthConfigForTenant(
+		d.tenantID)
+	if err != nil {
+		return nil, goof.WithError(
+			"Failed to create OAuthConfig for tenant", err)
+	}
+
+	var spt *azure.ServicePrincipalToken
+	if d.clientSecret != "" {
+		spt, err = azure.NewServicePrincipalToken(
+			*oauthConfig, d.clientID, d.clientSecret,
+			azure.PublicCloud.ResourceManagerEndpoint)
+		if err != nil {
+			return nil, goof.WithError(
+				"Failed to create Service Principal Token"+
+					" with client ID and secret", err)
+		}
+	} else {
+		certificate, rsaPrivateKey, err := decodePkcs12(certData, "")
+		if err != nil {
+			return nil, goof.WithError(
+				"Failed to decode certificate data", err)
+		}
+
+		spt, err = azure.NewServicePrincipalTokenFromCertificate(
+			*oauthConfig, d.clientID, certificate,
+			rsaPrivateKey,
+			azure.PublicCloud.ResourceManagerEndpoint)
+		if err != nil {
+			return nil, goof.WithError(
+				"Failed to create Service Principal Token"+
+					" with certificate ", err)
+		}
+	}
+
+	spt.SetAutoRefresh(true)
+	spt.SetRefreshWithin(tokenRefreshWindow)
+	return spt, nil
+}
+
+// listStorageAccessKey returns an access key of the storage account listed
+// with Azure Resource Manager, which allows the driver to access page blobs
+// without a configured storage access key.
+func (d *driver) listStorageAccessKey(
+	authorizer autorest.Authorizer) (string, error) {
+
+	ac := armStorage.NewAccountsClient(d.subscriptionID)
+	ac.Authorizer = authorizer
+
+	res, err := ac.ListKeys(d.resourceGroup, d.storageAccount)
+	if err != nil {
+		return "", goof.WithFieldE(
+			"storageAccount", d.storageAccount,
+			"Failed to list storage account keys", err)
+	}
+	if res.Keys != nil {
+		for _, k := range *res.Keys {
+			if k.Value != nil && *k.Value != "" &&
+				strings.EqualFold(string(k.Permissions), "full") {
+				return *k.Value, nil
+			}
+		}
+	}
+	return "", goof.WithField(
+		"storageAccount", d.storageAccount,
+		"Storage account has no access keys with full permissions")
+}
+
+// managedIdentityToken is an authorizer that requests tokens for the
+// managed identity of the VM on which the driver runs from the Azure
+// Instance Metadata Service. The token is refreshed when it expires within
+// the refresh window.
+type managedIdentityToken struct {
+	sync.Mutex
+	endpoint  string
+	clientID  string
+	resource  string
+	token     string
+	expiresOn time.Time
+}
+
+// WithAuthorization returns a PrepareDecorator that adds the bearer token
+// of the managed identity to requests.
+func (t *managedIdentityToken) WithAuthorization() autorest.PrepareDecorator {
+	return func(p autorest.Preparer) autorest.Preparer {
+		return autorest.PreparerFunc(
+			func(r *http.Request) (*http.Request, error) {
+				r, err := p.Prepare(r)
+				if err != nil {
+					return r, err
+				}
+				token, err := t.ensureFresh()
+				if err != nil {
+					return r, err
+				}
+				return autorest.Prepare(
+					r, autorest.WithBearerAuthorization(token))
+			})
+	}
+}
+
+// ensureFresh returns the token, which is refreshed first if it expires
+// within the refresh window.
+func (t *managedIdentityToken) ensureFresh() (string, error) {
+	t.Lock()
+	defer t.Unlock()
+
+	if t.token != "" &&
+		time.Now().Add(tokenRefreshWindow).Before(t.expiresOn) {
+		return t.token, nil
+	}
+	if err := t.refresh(); err != nil {
+		return "", err
+	}
+	return t.token, nil
+}
+
+func (t *managedIdentityToken) refresh() error {
+
+	q := url.Values{}
+	q.Set("api-version", managedIdentityAPIVersion)
+	q.Set("resource", t.resource)
+	// the client ID selects one of the VM's user-assigned identities
+	if t.clientID != "" {
+		q.Set("client_id", t.clientID)
+	}
+
+	req, err := http.NewRequest("GET", t.endpoint+"?"+q.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Metadata", "true")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return goof.WithError(
+			"Failed to request managed identity token", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return goof.WithField(
+			"status", res.Status,
+			"Failed to request managed identity token")
+	}
+
+	var reply struct {
+		AccessToken string `json:"access_token"`
+		ExpiresOn   string `json:"expires_on"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&reply); err != nil {
+		return goof.WithError(
+			"Failed to decode managed identity token", err)
+	}
+	expiresOn, err := strconv.ParseInt(reply.ExpiresOn, 10, 64)
+	if err != nil {
+		return goof.WithError(
+			"Failed to parse managed identity token expiry", err)
+	}
+
+	t.token = reply.AccessToken
+	t.expiresOn = time.Unix(expiresOn, 0)
+	return nil
+}
+
+func (d *driver) getUseManagedIdentity() bool {
+	return d.config.GetBool(azureud.ConfigAzureUseManagedIdentityKey)
+}
+
+func (d *driver) getManagedIdentityEndpoint() string {
+	return d.config.GetString(azureud.ConfigAzureManagedIdentityEndpointKey)
+}
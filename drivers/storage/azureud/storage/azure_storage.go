@@ -10,7 +10,6 @@ import (
 	"encoding/binary"
 	"fmt"
 	"hash"
-	"io/ioutil"
 	"strconv"
 	"strings"
 	"sync"
@@ -60,6 +59,9 @@ type driver struct {
 	clientSecret     string
 	certPath         string
 	useHTTPS         bool
+
+	useManagedIdentity      bool
+	managedIdentityEndpoint string
 }
 
 func init() {
@@ -79,23 +81,34 @@ func (d *driver) Init(context types.Context, config gofig.Config) error {
 	d.config = config
 
 	d.tenantID = d.getTenantID()
-	if d.tenantID == "" {
-		return goof.New("tenantID is a required config item")
-	}
-
 	d.clientID = d.getClientID()
-	if d.clientID == "" {
-		return goof.New("clientID is a required config item")
-	}
-
 	d.clientSecret = d.getClientSecret()
 	d.certPath = d.getCertPath()
-	if d.clientSecret == "" && d.certPath == "" {
-		return goof.New(
-			"clientSecret or certPath must be set for login.")
-	}
-	if d.clientSecret != "" && d.certPath != "" {
-		context.Warn("certPath will be ignored since clientSecret is set")
+	d.useManagedIdentity = d.getUseManagedIdentity()
+	d.managedIdentityEndpoint = d.getManagedIdentityEndpoint()
+
+	// a managed identity needs no credentials, and the client ID, if set,
+	// selects one of the VM's user-assigned identities
+	if d.useManagedIdentity {
+		if d.clientSecret != "" || d.certPath != "" {
+			context.Warn("clientSecret and certPath will be ignored " +
+				"since useManagedIdentity is set")
+		}
+	} else {
+		if d.tenantID == "" {
+			return goof.New("tenantID is a required config item")
+		}
+		if d.clientID == "" {
+			return goof.New("clientID is a required config item")
+		}
+		if d.clientSecret == "" && d.certPath == "" {
+			return goof.New(
+				"clientSecret or certPath must be set for login.")
+		}
+		if d.clientSecret != "" && d.certPath != "" {
+			context.Warn(
+				"certPath will be ignored since clientSecret is set")
+		}
 	}
 
 	d.storageAccount = d.getStorageAccount()
@@ -103,10 +116,8 @@ func (d *driver) Init(context types.Context, config gofig.Config) error {
 		return goof.New("storageAccount is a required config item")
 	}
 
+	// the storage access key is listed at login when it is not set
 	d.storageAccessKey = d.getStorageAccessKey()
-	if d.storageAccessKey == "" {
-		return goof.New("storageAccessKey is a required config item")
-	}
 
 	d.container = d.getContainer()
 
@@ -184,17 +195,16 @@ func (d *driver) Login(ctx types.Context) (interface{}, error) {
 
 	ctx.Debug("login to azure storage driver")
 	var (
-		hkey     = md5.New()
-		ckey     string
-		certData []byte
-		spt      *autorest.ServicePrincipalToken
-		err      error
+		hkey = md5.New()
+		ckey string
 	)
 
 	writeHkey(hkey, &d.subscriptionID)
 	writeHkey(hkey, &d.tenantID)
 	writeHkey(hkey, &d.storageAccount)
 	writeHkey(hkey, &d.clientID)
+	writeHkey(hkey, &d.managedIdentityEndpoint)
+	writeHkeyB(hkey, []byte(strconv.FormatBool(d.useManagedIdentity)))
 	ckey = fmt.Sprintf("%x", hkey.Sum(nil))
 
 	if session, ok := sessions[ckey]; ok {
@@ -203,54 +213,13 @@ func (d *driver) Login(ctx types.Context) (interface{}, error) {
 		return session, nil
 	}
 
-	if d.clientSecret != "" {
-		ctx.Info("Authenticating via clientSecret")
-	} else {
-		ctx.Info("Authenticating via client certificate")
-		certData, err = ioutil.ReadFile(d.certPath)
-		if err != nil {
-			return nil, goof.WithError(
-				"Failed to read provided certificate file",
-				err)
-		}
-	}
-
-	oauthConfig, err := autorest.PublicCloud.OAuthConfigForTenant(
-		d.tenantID)
+	authorizer, err := d.authorizer(ctx)
 	if err != nil {
-		return nil, goof.WithError(
-			"Failed to create OAuthConfig for tenant", err)
-	}
-
-	if d.clientSecret != "" {
-		spt, err = autorest.NewServicePrincipalToken(
-			*oauthConfig, d.clientID, d.clientSecret,
-			autorest.PublicCloud.ResourceManagerEndpoint)
-		if err != nil {
-			return nil, goof.WithError(
-				"Failed to create Service Principal Token"+
-					" with client ID and secret", err)
-		}
-	} else {
-		certificate, rsaPrivateKey, err := decodePkcs12(certData, "")
-		if err != nil {
-			return nil, goof.WithError(
-				"Failed to decode certificate data", err)
-		}
-
-		spt, err = autorest.NewServicePrincipalTokenFromCertificate(
-			*oauthConfig, d.clientID, certificate,
-			rsaPrivateKey,
-			autorest.PublicCloud.ResourceManagerEndpoint)
-		if err != nil {
-			return nil, goof.WithError(
-				"Failed to create Service Principal Token"+
-					" with certificate ", err)
-		}
+		return nil, err
 	}
 
 	newVMC := armCompute.NewVirtualMachinesClient(d.subscriptionID)
-	newVMC.Authorizer = spt
+	newVMC.Authorizer = authorizer
 	newVMC.PollingDelay = 5 * time.Second
 
 	// Verify login is working by listing VMs
@@ -260,9 +229,17 @@ func (d *driver) Login(ctx types.Context) (interface{}, error) {
 			err)
 	}
 
+	storageAccessKey := d.storageAccessKey
+	if storageAccessKey == "" {
+		storageAccessKey, err = d.listStorageAccessKey(authorizer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	bc, err := blobStorage.NewBasicClient(
 		d.storageAccount,
-		d.storageAccessKey)
+		storageAccessKey)
 	if err != nil {
 		return nil, goof.WithError(
 			"Failed to create BlobStorage client", err)
@@ -287,6 +264,15 @@ func (d *driver) NextDeviceInfo(
 	return utils.NextDeviceInfo, nil
 }
 
+// Capabilities returns the capabilities of the driver's storage platform.
+func (d *driver) Capabilities(
+	ctx types.Context) (*types.StorageCapabilities, error) {
+
+	return &types.StorageCapabilities{
+		MaxVolumeSize: 1023,
+	}, nil
+}
+
 // Type returns the type of storage the driver provides.
 func (d *driver) Type(ctx types.Context) (types.StorageType, error) {
 	//Example: Block storage
@@ -691,10 +677,12 @@ func (d *driver) toTypesVolume(
 				}
 				if attachments.Devices() {
 					if iid.ID == attVM {
-						att.DeviceName = getDevice(
+						att.DeviceName, att.LUN = getDevice(
 							ctx, vmDisks, &bName,
 							ld.DeviceMap,
 						)
+						// data disks are attached to the SCSI controller
+						att.BusType = "scsi"
 					}
 				}
 				attachedVols = append(attachedVols, att)
@@ -707,11 +695,13 @@ func (d *driver) toTypesVolume(
 	return volumes, nil
 }
 
+// getDevice returns the device and LUN of the disk with the name. The LUN
+// is returned even if the disk's device is not found.
 func getDevice(
 	ctx types.Context,
 	vmDisks []armCompute.DataDisk,
 	bName *string,
-	devMap map[string]string) string {
+	devMap map[string]string) (string, string) {
 
 	for _, disk := range vmDisks {
 		name := strings.TrimSuffix(*disk.Name, vhdExtension)
@@ -722,12 +712,13 @@ func getDevice(
 				name, strLun, devMap)
 			for dev, lun := range devMap {
 				if lun == strLun {
-					return dev
+					return dev, strLun
 				}
 			}
+			return "", strLun
 		}
 	}
-	return ""
+	return "", ""
 }
 
 func (d *driver) diskURI(name string) string {
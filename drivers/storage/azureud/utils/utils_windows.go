@@ -0,0 +1,18 @@
+// +build windows
+// +build !libstorage_storage_driver libstorage_storage_driver_azureud
+
+package utils
+
+import (
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// NextDeviceInfo is the NextDeviceInfo object for Azure.
+//
+// On Azure Windows instances the device name is only used to attach a
+// volume; the executor maps the volume's LUN to the local disk.
+var NextDeviceInfo = &types.NextDeviceInfo{
+	Prefix:  "sd",
+	Pattern: "[c-z]",
+	Ignore:  false,
+}
@@ -0,0 +1,152 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_composite
+
+package composite
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	gofigCore "github.com/akutz/gofig"
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+const (
+	// Name is the name of the storage driver
+	Name = "composite"
+
+	// ConfigRoutes is the config key of the map of the patterns of the
+	// routes to the names of the services to which they route.
+	ConfigRoutes = Name + ".routes"
+
+	// ConfigDefault is the config key of the name of the service to which
+	// volumes that match no route are routed.
+	ConfigDefault = Name + ".default"
+
+	// IDSeparator separates the name of a backend service from the ID of a
+	// volume or snapshot of the service in the IDs returned by the driver.
+	IDSeparator = ":"
+)
+
+func init() {
+	registerConfig()
+}
+
+func registerConfig() {
+	r := gofigCore.NewRegistration("Composite")
+	r.Key(gofig.String, "", "", "", ConfigDefault)
+	gofigCore.Register(r)
+
+	registry.RegisterConfigSchema(&types.ConfigSchema{
+		Name:       "Composite",
+		Namespaces: []string{Name},
+		Keys: map[string]types.ConfigKeyType{
+			ConfigRoutes:  types.ConfigKeyAny,
+			ConfigDefault: types.ConfigKeyString,
+		},
+		StorageDriver: Name,
+	})
+}
+
+// Route routes volumes to a backend service. A route with a prefix matches
+// the volumes whose names begin with the prefix, and a route with a label
+// matches the volumes created with the label's key and value among their
+// options.
+type Route struct {
+	Prefix     string
+	LabelKey   string
+	LabelValue string
+	Service    string
+}
+
+// String returns the route's pattern.
+func (r *Route) String() string {
+	if r.LabelKey != "" {
+		return fmt.Sprintf("%s=%s", r.LabelKey, r.LabelValue)
+	}
+	return r.Prefix + "*"
+}
+
+// Match returns a flag indicating whether or not the route matches a volume
+// name or a volume's labels. Names and labels are matched without regard to
+// case.
+func (r *Route) Match(name string, label func(key string) string) bool {
+	if r.LabelKey != "" {
+		return label != nil &&
+			strings.EqualFold(label(r.LabelKey), r.LabelValue)
+	}
+	return strings.HasPrefix(strings.ToLower(name), r.Prefix)
+}
+
+// ParseRoutes parses the value of the composite.routes key, a map of
+// patterns to the names of services. A pattern that ends with * routes the
+// volumes whose names begin with the rest of the pattern, and a pattern of
+// the form key=value routes the volumes created with the label. The routes
+// are returned in the order in which they are matched: prefixes from longest
+// to shortest followed by labels.
+func ParseRoutes(routes interface{}) ([]*Route, error) {
+
+	patterns := map[string]interface{}{}
+	switch tr := routes.(type) {
+	case nil:
+	case map[string]interface{}:
+		patterns = tr
+	case map[interface{}]interface{}:
+		for k, v := range tr {
+			patterns[fmt.Sprintf("%v", k)] = v
+		}
+	default:
+		return nil, goof.WithField(
+			"routes", routes, "composite routes must be a map")
+	}
+
+	parsed := make([]*Route, 0, len(patterns))
+	for pattern, service := range patterns {
+		svc, ok := service.(string)
+		if !ok || svc == "" {
+			return nil, goof.WithField(
+				"pattern", pattern, "composite route has no service")
+		}
+		r := &Route{Service: strings.ToLower(svc)}
+		switch {
+		case strings.HasSuffix(pattern, "*"):
+			r.Prefix = strings.ToLower(strings.TrimSuffix(pattern, "*"))
+		case strings.Contains(pattern, "="):
+			kv := strings.SplitN(pattern, "=", 2)
+			if kv[0] == "" {
+				return nil, goof.WithField(
+					"pattern", pattern, "composite route has no label key")
+			}
+			r.LabelKey, r.LabelValue = kv[0], kv[1]
+		default:
+			return nil, goof.WithField(
+				"pattern", pattern, "invalid composite route pattern")
+		}
+		parsed = append(parsed, r)
+	}
+
+	sort.Sort(byMatchOrder(parsed))
+	return parsed, nil
+}
+
+type byMatchOrder []*Route
+
+func (r byMatchOrder) Len() int      { return len(r) }
+func (r byMatchOrder) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r byMatchOrder) Less(i, j int) bool {
+	a, b := r[i], r[j]
+	if (a.LabelKey == "") != (b.LabelKey == "") {
+		return a.LabelKey == ""
+	}
+	if a.LabelKey == "" {
+		if len(a.Prefix) != len(b.Prefix) {
+			return len(a.Prefix) > len(b.Prefix)
+		}
+		return a.Prefix < b.Prefix
+	}
+	return a.String() < b.String()
+}
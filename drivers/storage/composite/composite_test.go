@@ -0,0 +1,56 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_composite
+
+package composite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRoutes(t *testing.T) {
+	routes, err := ParseRoutes(map[string]interface{}{
+		"bulk/*":      "s3fs",
+		"fast/*":      "EBS-gp3",
+		"fast/db/*":   "ebs-io2",
+		"tier=backup": "s3fs",
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	// the longest prefixes are matched first and labels last
+	patterns := make([]string, len(routes))
+	for i, r := range routes {
+		patterns[i] = r.String()
+	}
+	assert.Equal(t, []string{
+		"fast/db/*", "bulk/*", "fast/*", "tier=backup"}, patterns)
+	assert.Equal(t, "ebs-gp3", routes[2].Service)
+
+	labels := func(key string) string {
+		if key == "tier" {
+			return "Backup"
+		}
+		return ""
+	}
+	assert.True(t, routes[0].Match("FAST/db/orders", nil))
+	assert.False(t, routes[0].Match("fast/web", nil))
+	assert.True(t, routes[3].Match("logs", labels))
+	assert.False(t, routes[3].Match("logs", nil))
+}
+
+func TestParseRoutesInvalid(t *testing.T) {
+	_, err := ParseRoutes([]interface{}{"fast/*"})
+	assert.Error(t, err)
+	_, err = ParseRoutes(map[string]interface{}{"fast": "ebs"})
+	assert.Error(t, err)
+	_, err = ParseRoutes(map[string]interface{}{"=fast": "ebs"})
+	assert.Error(t, err)
+	_, err = ParseRoutes(map[interface{}]interface{}{"fast/*": ""})
+	assert.Error(t, err)
+
+	routes, err := ParseRoutes(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, routes)
+}
@@ -0,0 +1,545 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_composite
+
+package storage
+
+import (
+	"sort"
+	"strings"
+
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/server/services"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+	"github.com/codedellemc/libstorage/drivers/storage/composite"
+)
+
+// driver fans a single service out to the drivers of backend services. The
+// IDs of the volumes and snapshots it returns are prefixed with the names of
+// their backend services so that operations on existing volumes are routed
+// to the services that own them.
+type driver struct {
+	config         gofig.Config
+	routes         []*composite.Route
+	defaultService string
+
+	// backends are the names of the services to which the routes route
+	backends []string
+
+	// namePrefixes are the prefixes of the routes that route to each
+	// backend service. A prefix is removed from the name of a volume created
+	// by the backend service and added to the names of the service's
+	// volumes.
+	namePrefixes map[string]string
+}
+
+func init() {
+	registry.RegisterStorageDriver(composite.Name, newDriver)
+}
+
+func newDriver() types.StorageDriver {
+	return &driver{}
+}
+
+func (d *driver) Name() string {
+	return composite.Name
+}
+
+func (d *driver) Init(ctx types.Context, config gofig.Config) error {
+	d.config = config
+
+	routes, err := composite.ParseRoutes(config.Get(composite.ConfigRoutes))
+	if err != nil {
+		return err
+	}
+	d.routes = routes
+	d.defaultService = strings.ToLower(
+		config.GetString(composite.ConfigDefault))
+
+	if len(d.routes) == 0 && d.defaultService == "" {
+		return goof.New("composite driver has no routes or default service")
+	}
+
+	d.namePrefixes = map[string]string{}
+	backends := map[string]bool{}
+	if d.defaultService != "" {
+		backends[d.defaultService] = true
+	}
+	for _, r := range d.routes {
+		backends[r.Service] = true
+		// the routes are sorted from the longest prefix to the shortest, so
+		// a service's volumes are named with its most specific prefix
+		if _, ok := d.namePrefixes[r.Service]; !ok && r.LabelKey == "" {
+			d.namePrefixes[r.Service] = r.Prefix
+		}
+	}
+	for name := range backends {
+		d.backends = append(d.backends, name)
+	}
+	sort.Strings(d.backends)
+
+	if svc, ok := context.Service(ctx); ok {
+		if backends[strings.ToLower(svc.Name())] {
+			return goof.WithField(
+				"service", svc.Name(), "composite service routes to itself")
+		}
+	}
+
+	ctx.WithField("backends", d.backends).Info("composite storage driver")
+	return nil
+}
+
+// backend returns a context for, and the driver of, a backend service. The
+// context is logged into the service's storage platform.
+func (d *driver) backend(
+	ctx types.Context,
+	name string) (types.Context, types.StorageDriver, error) {
+
+	svc := services.GetStorageService(ctx, name)
+	if svc == nil {
+		return nil, nil, goof.WithField(
+			"service", name, "composite backend service not found")
+	}
+	bctx, err := context.WithStorageSession(
+		context.WithStorageService(ctx, svc))
+	if err != nil {
+		return nil, nil, err
+	}
+	return bctx, svc.Driver(), nil
+}
+
+// route returns the name of the backend service to which a new volume is
+// routed and the name of the volume on the backend service.
+func (d *driver) route(
+	name string, opts types.Store) (string, string, error) {
+
+	var label func(string) string
+	if opts != nil {
+		label = opts.GetString
+	}
+	for _, r := range d.routes {
+		if r.Match(name, label) {
+			return r.Service, d.backendName(r.Service, name), nil
+		}
+	}
+	if d.defaultService != "" {
+		return d.defaultService, d.backendName(d.defaultService, name), nil
+	}
+	return "", "", utils.NewInvalidRequestError(
+		"name", name, "volume matches no composite route")
+}
+
+// backendName removes the name prefix of a backend service from a name.
+func (d *driver) backendName(backend, name string) string {
+	prefix := d.namePrefixes[backend]
+	if prefix != "" && strings.HasPrefix(strings.ToLower(name), prefix) {
+		return name[len(prefix):]
+	}
+	return name
+}
+
+// splitID splits an ID returned by the driver into the name of a backend
+// service and the ID of the backend service's volume or snapshot.
+func (d *driver) splitID(id string) (string, string, error) {
+	parts := strings.SplitN(id, composite.IDSeparator, 2)
+	if len(parts) == 2 {
+		backend := strings.ToLower(parts[0])
+		i := sort.SearchStrings(d.backends, backend)
+		if i < len(d.backends) && d.backends[i] == backend {
+			return backend, parts[1], nil
+		}
+	}
+	return "", "", utils.NewNotFoundError(id)
+}
+
+func (d *driver) joinID(backend, id string) string {
+	if id == "" {
+		return ""
+	}
+	return backend + composite.IDSeparator + id
+}
+
+func (d *driver) toVolume(backend string, v *types.Volume) *types.Volume {
+	if v == nil {
+		return nil
+	}
+	cv := *v
+	cv.ID = d.joinID(backend, v.ID)
+	cv.Name = d.namePrefixes[backend] + v.Name
+	if v.Attachments != nil {
+		cv.Attachments = make([]*types.VolumeAttachment, len(v.Attachments))
+		for i, a := range v.Attachments {
+			ca := *a
+			ca.VolumeID = d.joinID(backend, a.VolumeID)
+			cv.Attachments[i] = &ca
+		}
+	}
+	return &cv
+}
+
+func (d *driver) toSnapshot(
+	backend string, s *types.Snapshot) *types.Snapshot {
+
+	if s == nil {
+		return nil
+	}
+	cs := *s
+	cs.ID = d.joinID(backend, s.ID)
+	cs.VolumeID = d.joinID(backend, s.VolumeID)
+	return &cs
+}
+
+// primary returns the backend service that describes the driver's type,
+// instance, and devices: the default service if there is one, otherwise the
+// first of the backend services.
+func (d *driver) primary() string {
+	if d.defaultService != "" {
+		return d.defaultService
+	}
+	return d.backends[0]
+}
+
+func (d *driver) Type(ctx types.Context) (types.StorageType, error) {
+	bctx, bd, err := d.backend(ctx, d.primary())
+	if err != nil {
+		return "", err
+	}
+	return bd.Type(bctx)
+}
+
+func (d *driver) NextDeviceInfo(
+	ctx types.Context) (*types.NextDeviceInfo, error) {
+
+	bctx, bd, err := d.backend(ctx, d.primary())
+	if err != nil {
+		return nil, err
+	}
+	return bd.NextDeviceInfo(bctx)
+}
+
+func (d *driver) InstanceInspect(
+	ctx types.Context,
+	opts types.Store) (*types.Instance, error) {
+
+	bctx, bd, err := d.backend(ctx, d.primary())
+	if err != nil {
+		return nil, err
+	}
+	return bd.InstanceInspect(bctx, opts)
+}
+
+// HealthCheck returns an error if a backend service does not exist or its
+// storage platform is unhealthy.
+func (d *driver) HealthCheck(ctx types.Context) error {
+	for _, name := range d.backends {
+		bctx, bd, err := d.backend(ctx, name)
+		if err != nil {
+			return err
+		}
+		if hc, ok := bd.(types.ProvidesHealthCheck); ok {
+			if err := hc.HealthCheck(bctx); err != nil {
+				return goof.WithFieldE(
+					"service", name, "composite backend unhealthy", err)
+			}
+		}
+	}
+	return nil
+}
+
+// Capabilities returns the capabilities shared by the backend services, so
+// that a feature is reported only if it is supported wherever a volume may
+// be routed. Volumes cannot be resized through the composite service.
+func (d *driver) Capabilities(
+	ctx types.Context) (*types.StorageCapabilities, error) {
+
+	var caps []*types.StorageCapabilities
+	for _, name := range d.backends {
+		bctx, bd, err := d.backend(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		c, err := backendCapabilities(bctx, bd)
+		if err != nil {
+			return nil, err
+		}
+		caps = append(caps, c)
+	}
+	cc := utils.CommonCapabilities(caps...)
+	cc.Resize = false
+	return cc, nil
+}
+
+// backendCapabilities returns the capabilities of a backend service's
+// driver.
+func backendCapabilities(
+	ctx types.Context,
+	sd types.StorageDriver) (*types.StorageCapabilities, error) {
+
+	if pc, ok := sd.(types.ProvidesStorageCapabilities); ok {
+		return pc.Capabilities(ctx)
+	}
+	return &types.StorageCapabilities{}, nil
+}
+
+func (d *driver) Volumes(
+	ctx types.Context,
+	opts *types.VolumesOpts) ([]*types.Volume, error) {
+
+	var vols []*types.Volume
+	for _, name := range d.backends {
+		bctx, bd, err := d.backend(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		bvols, err := bd.Volumes(bctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range bvols {
+			vols = append(vols, d.toVolume(name, v))
+		}
+	}
+	return vols, nil
+}
+
+func (d *driver) VolumeInspect(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeInspectOpts) (*types.Volume, error) {
+
+	name, id, err := d.splitID(volumeID)
+	if err != nil {
+		return nil, err
+	}
+	bctx, bd, err := d.backend(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	v, err := bd.VolumeInspect(bctx, id, opts)
+	if err != nil {
+		return nil, err
+	}
+	return d.toVolume(name, v), nil
+}
+
+func (d *driver) VolumeCreate(
+	ctx types.Context,
+	volumeName string,
+	opts *types.VolumeCreateOpts) (*types.Volume, error) {
+
+	var labels types.Store
+	if opts != nil {
+		labels = opts.Opts
+	}
+	name, backendName, err := d.route(volumeName, labels)
+	if err != nil {
+		return nil, err
+	}
+	bctx, bd, err := d.backend(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	ctx.WithField("service", name).Debug("routed volume create")
+	v, err := bd.VolumeCreate(bctx, backendName, opts)
+	if err != nil {
+		return nil, err
+	}
+	return d.toVolume(name, v), nil
+}
+
+func (d *driver) VolumeCreateFromSnapshot(
+	ctx types.Context,
+	snapshotID, volumeName string,
+	opts *types.VolumeCreateOpts) (*types.Volume, error) {
+
+	name, id, err := d.splitID(snapshotID)
+	if err != nil {
+		return nil, err
+	}
+	bctx, bd, err := d.backend(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	v, err := bd.VolumeCreateFromSnapshot(
+		bctx, id, d.backendName(name, volumeName), opts)
+	if err != nil {
+		return nil, err
+	}
+	return d.toVolume(name, v), nil
+}
+
+func (d *driver) VolumeCopy(
+	ctx types.Context,
+	volumeID, volumeName string,
+	opts types.Store) (*types.Volume, error) {
+
+	name, id, err := d.splitID(volumeID)
+	if err != nil {
+		return nil, err
+	}
+	bctx, bd, err := d.backend(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	v, err := bd.VolumeCopy(bctx, id, d.backendName(name, volumeName), opts)
+	if err != nil {
+		return nil, err
+	}
+	return d.toVolume(name, v), nil
+}
+
+func (d *driver) VolumeSnapshot(
+	ctx types.Context,
+	volumeID, snapshotName string,
+	opts types.Store) (*types.Snapshot, error) {
+
+	name, id, err := d.splitID(volumeID)
+	if err != nil {
+		return nil, err
+	}
+	bctx, bd, err := d.backend(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	s, err := bd.VolumeSnapshot(bctx, id, snapshotName, opts)
+	if err != nil {
+		return nil, err
+	}
+	return d.toSnapshot(name, s), nil
+}
+
+func (d *driver) VolumeRemove(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeRemoveOpts) error {
+
+	name, id, err := d.splitID(volumeID)
+	if err != nil {
+		return err
+	}
+	bctx, bd, err := d.backend(ctx, name)
+	if err != nil {
+		return err
+	}
+	return bd.VolumeRemove(bctx, id, opts)
+}
+
+func (d *driver) VolumeAttach(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeAttachOpts) (*types.Volume, string, error) {
+
+	name, id, err := d.splitID(volumeID)
+	if err != nil {
+		return nil, "", err
+	}
+	bctx, bd, err := d.backend(ctx, name)
+	if err != nil {
+		return nil, "", err
+	}
+	v, token, err := bd.VolumeAttach(bctx, id, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return d.toVolume(name, v), token, nil
+}
+
+func (d *driver) VolumeDetach(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeDetachOpts) (*types.Volume, error) {
+
+	name, id, err := d.splitID(volumeID)
+	if err != nil {
+		return nil, err
+	}
+	bctx, bd, err := d.backend(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	v, err := bd.VolumeDetach(bctx, id, opts)
+	if err != nil {
+		return nil, err
+	}
+	return d.toVolume(name, v), nil
+}
+
+func (d *driver) Snapshots(
+	ctx types.Context,
+	opts types.Store) ([]*types.Snapshot, error) {
+
+	var snaps []*types.Snapshot
+	for _, name := range d.backends {
+		bctx, bd, err := d.backend(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		bsnaps, err := bd.Snapshots(bctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range bsnaps {
+			snaps = append(snaps, d.toSnapshot(name, s))
+		}
+	}
+	return snaps, nil
+}
+
+func (d *driver) SnapshotInspect(
+	ctx types.Context,
+	snapshotID string,
+	opts types.Store) (*types.Snapshot, error) {
+
+	name, id, err := d.splitID(snapshotID)
+	if err != nil {
+		return nil, err
+	}
+	bctx, bd, err := d.backend(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	s, err := bd.SnapshotInspect(bctx, id, opts)
+	if err != nil {
+		return nil, err
+	}
+	return d.toSnapshot(name, s), nil
+}
+
+func (d *driver) SnapshotCopy(
+	ctx types.Context,
+	snapshotID, snapshotName, destinationID string,
+	opts types.Store) (*types.Snapshot, error) {
+
+	name, id, err := d.splitID(snapshotID)
+	if err != nil {
+		return nil, err
+	}
+	bctx, bd, err := d.backend(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	s, err := bd.SnapshotCopy(bctx, id, snapshotName, destinationID, opts)
+	if err != nil {
+		return nil, err
+	}
+	return d.toSnapshot(name, s), nil
+}
+
+func (d *driver) SnapshotRemove(
+	ctx types.Context,
+	snapshotID string,
+	opts types.Store) error {
+
+	name, id, err := d.splitID(snapshotID)
+	if err != nil {
+		return err
+	}
+	bctx, bd, err := d.backend(ctx, name)
+	if err != nil {
+		return err
+	}
+	return bd.SnapshotRemove(bctx, id, opts)
+}
@@ -32,6 +32,41 @@ const (
 
 	// ConfigDORegion is the key for the region in the config file
 	ConfigDORegion = Name + ".region"
+
+	// ConfigDOTag is the key for the tag that identifies the volumes
+	// managed by the driver in the config file
+	ConfigDOTag = Name + ".tag"
+
+	// ConfigDOTags is the key for the tags applied to every volume created
+	// by the driver in the config file
+	ConfigDOTags = Name + ".tags"
+
+	// ConfigDODropletCacheTTL is the key for how long the droplets looked up
+	// by InstanceInspect are cached in the config file
+	ConfigDODropletCacheTTL = Name + ".dropletCacheTTL"
+
+	// DefaultDropletCacheTTL is the default value of ConfigDODropletCacheTTL
+	DefaultDropletCacheTTL = "5m"
+
+	// VolumeOptTags is the volume create option that specifies additional,
+	// comma-separated tags to apply to the volume
+	VolumeOptTags = "tags"
+
+	// VolumesOptTag is the volumes option that lists only the volumes with
+	// the tag
+	VolumesOptTag = "tag"
+
+	// VolumeFieldTags is the key of the volume field that contains the
+	// volume's comma-separated tags
+	VolumeFieldTags = "tags"
+
+	// InstanceFieldSize is the key of the instance field that contains the
+	// droplet's size slug
+	InstanceFieldSize = "size"
+
+	// InstanceFieldTags is the key of the instance field that contains the
+	// droplet's comma-separated tags
+	InstanceFieldTags = "tags"
 )
 
 func init() {
@@ -52,5 +87,23 @@ func registerConfig() {
 		"",
 		"The DigitalOcean region",
 		ConfigDORegion)
+	r.Key(
+		gofig.String,
+		"",
+		"",
+		"The tag that identifies the volumes managed by the driver",
+		ConfigDOTag)
+	r.Key(
+		gofig.String,
+		"",
+		"",
+		"The tags applied to every volume created by the driver",
+		ConfigDOTags)
+	r.Key(
+		gofig.String,
+		"",
+		DefaultDropletCacheTTL,
+		"How long droplets looked up by InstanceInspect are cached",
+		ConfigDODropletCacheTTL)
 	gofigCore.Register(r)
 }
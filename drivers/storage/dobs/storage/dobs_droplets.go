@@ -0,0 +1,95 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_dobs
+
+package storage
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/akutz/goof"
+	"github.com/digitalocean/godo"
+
+	do "github.com/codedellemc/libstorage/drivers/storage/dobs"
+)
+
+// droplets is shared by the instances of the driver so that the services
+// that use the driver do not look up the same droplet separately.
+var droplets = &dropletCache{entries: map[int]*dropletEntry{}}
+
+// dropletCache caches the droplets looked up by InstanceInspect. Volume
+// operations inspect the instance of the request, so caching the droplets
+// keeps bursts of operations within the DigitalOcean API's rate limits.
+type dropletCache struct {
+	sync.Mutex
+	entries map[int]*dropletEntry
+}
+
+type dropletEntry struct {
+	droplet *godo.Droplet
+	expires time.Time
+}
+
+// get returns the cached droplet with the ID. If there is none, or it has
+// expired, fn is called to look up the droplet, which is cached for the
+// ttl. Errors are not cached.
+func (c *dropletCache) get(
+	id int,
+	ttl time.Duration,
+	fn func() (*godo.Droplet, error)) (*godo.Droplet, error) {
+
+	if ttl <= 0 {
+		return fn()
+	}
+
+	c.Lock()
+	e, ok := c.entries[id]
+	c.Unlock()
+	if ok && time.Now().Before(e.expires) {
+		return e.droplet, nil
+	}
+
+	droplet, err := fn()
+	if err != nil {
+		return nil, err
+	}
+
+	c.Lock()
+	c.entries[id] = &dropletEntry{
+		droplet: droplet,
+		expires: time.Now().Add(ttl),
+	}
+	c.Unlock()
+	return droplet, nil
+}
+
+// getDroplet returns the droplet with the ID, which is looked up with the
+// DigitalOcean API if it is not cached.
+func (d *driver) getDroplet(dropletID string) (*godo.Droplet, error) {
+
+	id, err := strconv.Atoi(dropletID)
+	if err != nil {
+		return nil, goof.WithFieldE(
+			"dropletID", dropletID, "invalid droplet id", err)
+	}
+
+	ttl, err := time.ParseDuration(d.dropletCacheTTL())
+	if err != nil {
+		return nil, goof.WithFieldE(
+			"dropletCacheTTL", d.dropletCacheTTL(),
+			"invalid droplet cache ttl", err)
+	}
+
+	return droplets.get(id, ttl, func() (*godo.Droplet, error) {
+		droplet, _, err := d.client.Droplets.Get(id)
+		if err != nil {
+			return nil, goof.WithFieldE(
+				"dropletID", dropletID, "error getting droplet", err)
+		}
+		return droplet, nil
+	})
+}
+
+func (d *driver) dropletCacheTTL() string {
+	return d.config.GetString(do.ConfigDODropletCacheTTL)
+}
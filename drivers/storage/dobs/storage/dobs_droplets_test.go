@@ -0,0 +1,60 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_dobs
+
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDropletCache(t *testing.T) {
+	var (
+		calls int
+		fail  bool
+	)
+	fn := func() (*godo.Droplet, error) {
+		calls++
+		if fail {
+			return nil, errors.New("rate limited")
+		}
+		return &godo.Droplet{ID: calls}, nil
+	}
+
+	tests := []struct {
+		name  string
+		id    int
+		ttl   time.Duration
+		fail  bool
+		sleep time.Duration
+		calls int
+		err   bool
+	}{
+		{"miss", 1, time.Minute, false, 0, 1, false},
+		{"hit", 1, time.Minute, false, 0, 1, false},
+		{"other droplet", 2, time.Minute, false, 0, 2, false},
+		{"disabled", 1, 0, false, 0, 3, false},
+		{"short ttl", 3, time.Millisecond, false, 0, 4, false},
+		{"expired", 3, time.Minute, false, 5 * time.Millisecond, 5, false},
+		{"error", 4, time.Minute, true, 0, 6, true},
+		{"error not cached", 4, time.Minute, false, 0, 7, false},
+	}
+
+	c := &dropletCache{entries: map[int]*dropletEntry{}}
+	for _, tt := range tests {
+		fail = tt.fail
+		time.Sleep(tt.sleep)
+		droplet, err := c.get(tt.id, tt.ttl, fn)
+		assert.Equal(t, tt.calls, calls, tt.name)
+		if tt.err {
+			assert.Error(t, err, tt.name)
+			assert.Nil(t, droplet, tt.name)
+		} else {
+			assert.NoError(t, err, tt.name)
+			assert.NotNil(t, droplet, tt.name)
+		}
+	}
+}
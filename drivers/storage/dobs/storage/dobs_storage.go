@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	log "github.com/Sirupsen/logrus"
@@ -17,6 +18,7 @@ import (
 	"github.com/codedellemc/libstorage/api/context"
 	"github.com/codedellemc/libstorage/api/registry"
 	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
 
 	do "github.com/codedellemc/libstorage/drivers/storage/dobs"
 	doUtils "github.com/codedellemc/libstorage/drivers/storage/dobs/utils"
@@ -79,27 +81,67 @@ func (d *driver) NextDeviceInfo(
 	return nil, nil
 }
 
+// Capabilities returns the capabilities of the driver's storage platform.
+func (d *driver) Capabilities(
+	ctx types.Context) (*types.StorageCapabilities, error) {
+
+	return &types.StorageCapabilities{
+		MaxVolumeSize: 16384,
+		Topology:      []string{"region"},
+	}, nil
+}
+
 func (d *driver) InstanceInspect(
 	ctx types.Context, opts types.Store) (*types.Instance, error) {
 	iid := context.MustInstanceID(ctx)
-	return &types.Instance{
+	instance := &types.Instance{
 		InstanceID:   iid,
 		Region:       iid.Fields[do.InstanceIDFieldRegion],
 		Name:         iid.Fields[do.InstanceIDFieldName],
 		ProviderName: iid.Driver,
-	}, nil
+	}
+
+	droplet, err := d.getDroplet(iid.ID)
+	if err != nil {
+		// the instance ID's fields describe the droplet well enough for
+		// volume operations if the droplet cannot be looked up
+		if instance.Region == "" {
+			return nil, err
+		}
+		ctx.WithError(err).Warn("error getting droplet")
+		return instance, nil
+	}
+
+	instance.Name = droplet.Name
+	if droplet.Region != nil && droplet.Region.Slug != "" {
+		instance.Region = droplet.Region.Slug
+	}
+	instance.Fields = map[string]string{}
+	if droplet.SizeSlug != "" {
+		instance.Fields[do.InstanceFieldSize] = droplet.SizeSlug
+	}
+	if len(droplet.Tags) > 0 {
+		instance.Fields[do.InstanceFieldTags] = strings.Join(droplet.Tags, ",")
+	}
+
+	return instance, nil
 }
 
 func (d *driver) Volumes(
 	ctx types.Context, opts *types.VolumesOpts) ([]*types.Volume, error) {
-	doVolumes, _, err := d.client.Storage.ListVolumes(nil)
+	doVolumes, err := d.listVolumes()
 	if err != nil {
 		return nil, err
 	}
 
+	tags := d.listTags(opts.Opts)
+
 	var volumes []*types.Volume
 	for _, vol := range doVolumes {
-		volumes = append(volumes, d.toTypesVolume(ctx, &vol, opts.Attachments))
+		if !hasTags(vol, tags) {
+			continue
+		}
+		volumes = append(volumes, d.toTypesVolume(ctx, vol, opts.Attachments))
 	}
 
 	return volumes, nil
@@ -107,7 +149,7 @@ func (d *driver) Volumes(
 
 func (d *driver) VolumeInspect(
 	ctx types.Context, volumeID string, opts *types.VolumeInspectOpts) (*types.Volume, error) {
-	doVolume, _, err := d.client.Storage.GetVolume(volumeID)
+	doVolume, err := d.getVolume(volumeID)
 	if err != nil {
 		return nil, err
 	}
@@ -125,13 +167,14 @@ func (d *driver) VolumeCreate(
 		}
 		opts.AvailabilityZone = &instance.Region
 	}
-	volumeReq := &godo.VolumeCreateRequest{
+	volumeReq := &volumeCreateRequest{
 		Region:        *opts.AvailabilityZone,
 		Name:          name,
 		SizeGigaBytes: *opts.Size,
+		Tags:          d.createTags(opts.Opts),
 	}
 
-	volume, _, err := d.client.Storage.CreateVolume(volumeReq)
+	volume, err := d.createVolume(volumeReq)
 	if err != nil {
 		return nil, err
 	}
@@ -170,7 +213,7 @@ func (d *driver) VolumeRemove(
 
 	if len(volume.DropletIDs) > 0 {
 		if !opts.Force {
-			return goof.New("volume already attached")
+			return utils.NewVolumeInUseError(volumeID)
 		}
 
 		err := d.volumeDetach(volumeID)
@@ -301,7 +344,7 @@ func mustInstanceIDID(ctx types.Context) *string {
 }
 
 func (d *driver) toTypesVolume(
-	ctx types.Context, volume *godo.Volume,
+	ctx types.Context, volume *doVolume,
 	attachments types.VolumeAttachmentsTypes) *types.Volume {
 	// Collect attachment info for the volume
 	var atts []*types.VolumeAttachment
@@ -340,6 +383,12 @@ func (d *driver) toTypesVolume(
 		Status:           status,
 	}
 
+	if len(volume.Tags) > 0 {
+		vol.Fields = map[string]string{
+			do.VolumeFieldTags: strings.Join(volume.Tags, ","),
+		}
+	}
+
 	return vol
 }
 
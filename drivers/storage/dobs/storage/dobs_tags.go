@@ -0,0 +1,168 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_dobs
+
+package storage
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/digitalocean/godo"
+
+	"github.com/codedellemc/libstorage/api/types"
+
+	do "github.com/codedellemc/libstorage/drivers/storage/dobs"
+)
+
+const (
+	volumesPath = "v2/volumes"
+
+	// volumesPerPage is the maximum page size of the DigitalOcean API,
+	// which keeps the number of requests needed to list volumes low.
+	volumesPerPage = "200"
+)
+
+// doVolume is a DigitalOcean volume and its tags. The volume API is
+// requested directly rather than with the godo storage service, since the
+// service's volumes do not include their tags.
+type doVolume struct {
+	godo.Volume
+	Tags []string `json:"tags,omitempty"`
+}
+
+type volumeRoot struct {
+	Volume *doVolume `json:"volume"`
+}
+
+type volumesRoot struct {
+	Volumes []*doVolume `json:"volumes"`
+	Links   struct {
+		Pages struct {
+			Next string `json:"next"`
+		} `json:"pages"`
+	} `json:"links"`
+}
+
+type volumeCreateRequest struct {
+	Region        string   `json:"region"`
+	Name          string   `json:"name"`
+	SizeGigaBytes int64    `json:"size_gigabytes"`
+	Tags          []string `json:"tags,omitempty"`
+}
+
+// listVolumes returns all of the account's volumes, following the pages of
+// the listing.
+func (d *driver) listVolumes() ([]*doVolume, error) {
+	var vols []*doVolume
+	path := volumesPath + "?per_page=" + volumesPerPage
+	for path != "" {
+		req, err := d.client.NewRequest(http.MethodGet, path, nil)
+		if err != nil {
+			return nil, err
+		}
+		root := &volumesRoot{}
+		if _, err := d.client.Do(req, root); err != nil {
+			return nil, err
+		}
+		vols = append(vols, root.Volumes...)
+		path = root.Links.Pages.Next
+	}
+	return vols, nil
+}
+
+func (d *driver) getVolume(volumeID string) (*doVolume, error) {
+	req, err := d.client.NewRequest(
+		http.MethodGet, volumesPath+"/"+volumeID, nil)
+	if err != nil {
+		return nil, err
+	}
+	root := &volumeRoot{}
+	if _, err := d.client.Do(req, root); err != nil {
+		return nil, err
+	}
+	return root.Volume, nil
+}
+
+func (d *driver) createVolume(
+	createReq *volumeCreateRequest) (*doVolume, error) {
+
+	req, err := d.client.NewRequest(http.MethodPost, volumesPath, createReq)
+	if err != nil {
+		return nil, err
+	}
+	root := &volumeRoot{}
+	if _, err := d.client.Do(req, root); err != nil {
+		return nil, err
+	}
+	return root.Volume, nil
+}
+
+// createTags returns the tags of a new volume: the tag that identifies the
+// driver's volumes, the tags applied to every volume, and the tags of the
+// volume create options.
+func (d *driver) createTags(opts types.Store) []string {
+	var tags []string
+	add := func(tag string) {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			return
+		}
+		for _, t := range tags {
+			if t == tag {
+				return
+			}
+		}
+		tags = append(tags, tag)
+	}
+
+	add(d.tag())
+	for _, t := range d.tags() {
+		add(t)
+	}
+	if opts != nil {
+		for _, t := range strings.Split(opts.GetString(do.VolumeOptTags), ",") {
+			add(t)
+		}
+	}
+	return tags
+}
+
+// listTags returns the tags a volume must have to be listed: the tag that
+// identifies the driver's volumes and the tag of the volumes options.
+func (d *driver) listTags(opts types.Store) []string {
+	var tags []string
+	if v := d.tag(); v != "" {
+		tags = append(tags, v)
+	}
+	if opts != nil {
+		if v := opts.GetString(do.VolumesOptTag); v != "" {
+			tags = append(tags, v)
+		}
+	}
+	return tags
+}
+
+// hasTags returns a flag indicating whether or not the volume has all of
+// the tags.
+func hasTags(vol *doVolume, tags []string) bool {
+	for _, tag := range tags {
+		found := false
+		for _, t := range vol.Tags {
+			if t == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *driver) tag() string {
+	return d.config.GetString(do.ConfigDOTag)
+}
+
+func (d *driver) tags() []string {
+	return d.config.GetStringSlice(do.ConfigDOTags)
+}
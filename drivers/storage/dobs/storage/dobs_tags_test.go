@@ -0,0 +1,91 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_dobs
+
+package storage
+
+import (
+	"testing"
+
+	gofigCore "github.com/akutz/gofig"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+
+	do "github.com/codedellemc/libstorage/drivers/storage/dobs"
+)
+
+func newTagsTestDriver(tag string, tags ...string) *driver {
+	config := gofigCore.New()
+	config.Set(do.ConfigDOTag, tag)
+	config.Set(do.ConfigDOTags, tags)
+	return &driver{config: config}
+}
+
+func tagOpts(key, val string) types.Store {
+	return utils.NewStoreWithData(map[string]interface{}{key: val})
+}
+
+func TestCreateTags(t *testing.T) {
+	tests := []struct {
+		name string
+		d    *driver
+		opts types.Store
+		tags []string
+	}{
+		{"none", newTagsTestDriver(""), nil, nil},
+		{"driver tag", newTagsTestDriver("libstorage"), nil,
+			[]string{"libstorage"}},
+		{"configured tags", newTagsTestDriver("libstorage", "prod", "db"),
+			nil, []string{"libstorage", "prod", "db"}},
+		{"option tags",
+			newTagsTestDriver("libstorage", "prod"),
+			tagOpts(do.VolumeOptTags, " app , prod,,libstorage"),
+			[]string{"libstorage", "prod", "app"}},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.tags, tt.d.createTags(tt.opts), tt.name)
+	}
+}
+
+func TestListTags(t *testing.T) {
+	tests := []struct {
+		name string
+		d    *driver
+		opts types.Store
+		tags []string
+	}{
+		{"none", newTagsTestDriver(""), nil, nil},
+		{"driver tag", newTagsTestDriver("libstorage", "prod"), nil,
+			[]string{"libstorage"}},
+		{"option tag", newTagsTestDriver(""),
+			tagOpts(do.VolumesOptTag, "app"), []string{"app"}},
+		{"both", newTagsTestDriver("libstorage"),
+			tagOpts(do.VolumesOptTag, "app"),
+			[]string{"libstorage", "app"}},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.tags, tt.d.listTags(tt.opts), tt.name)
+	}
+}
+
+func TestHasTags(t *testing.T) {
+	vol := &doVolume{Tags: []string{"libstorage", "app"}}
+
+	tests := []struct {
+		tags []string
+		has  bool
+	}{
+		{nil, true},
+		{[]string{"libstorage"}, true},
+		{[]string{"libstorage", "app"}, true},
+		{[]string{"libstorage", "db"}, false},
+		{[]string{"LIBSTORAGE"}, false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.has, hasTags(vol, tt.tags), "%v", tt.tags)
+	}
+	assert.False(t, hasTags(&doVolume{}, []string{"libstorage"}))
+}
@@ -62,6 +62,35 @@ const (
 	// If a KmsKeyID is specified, all volumes will be created with their
 	// Encrypted flag set to true.
 	KmsKeyID = "kmsKeyID"
+
+	// RoleARN is the ARN of an IAM role the driver assumes with the
+	// credentials it otherwise uses. The role may belong to another
+	// account, and the temporary credentials obtained from STS are
+	// refreshed automatically before they expire.
+	RoleARN = "roleARN"
+
+	// RoleExternalID is a key constant.
+	RoleExternalID = "roleExternalID"
+
+	// RoleSessionName is a key constant.
+	RoleSessionName = "roleSessionName"
+
+	// FastSnapshotRestoreZones is a comma-separated list of the
+	// availability zones in which fast snapshot restore is enabled for new
+	// snapshots. Volumes created from such snapshots in those zones are
+	// fully initialized when they are created.
+	FastSnapshotRestoreZones = "fastSnapshotRestoreZones"
+
+	// DescribeCacheTTL is how long the responses of the EC2 operations that
+	// describe volumes and instances are cached. Zero disables the cache.
+	DescribeCacheTTL = "describeCacheTTL"
+
+	// DefaultDescribeCacheTTL is the default value of DescribeCacheTTL.
+	DefaultDescribeCacheTTL = "5s"
+
+	// DefaultRoleSessionName is the name of the sessions of an assumed role
+	// when no name is configured.
+	DefaultRoleSessionName = "libstorage"
 )
 
 func init() {
@@ -73,6 +102,13 @@ func init() {
 	r.Key(gofig.Int, "", DefaultMaxRetries, "", Name+"."+MaxRetries)
 	r.Key(gofig.String, "", "", "Tag prefix for EBS naming", Name+"."+Tag)
 	r.Key(gofig.String, "", "", "", Name+"."+KmsKeyID)
+	r.Key(gofig.String, "", "", "", Name+"."+RoleARN)
+	r.Key(gofig.String, "", "", "", Name+"."+RoleExternalID)
+	r.Key(gofig.String, "", DefaultRoleSessionName, "",
+		Name+"."+RoleSessionName)
+	r.Key(gofig.String, "", "", "", Name+"."+FastSnapshotRestoreZones)
+	r.Key(gofig.String, "", DefaultDescribeCacheTTL, "",
+		Name+"."+DescribeCacheTTL)
 
 	r.Key(gofig.String, "", "", "", NameEC2+"."+AccessKey)
 	r.Key(gofig.String, "", "", "", NameEC2+"."+SecretKey)
@@ -81,6 +117,13 @@ func init() {
 	r.Key(gofig.Int, "", DefaultMaxRetries, "", NameEC2+"."+MaxRetries)
 	r.Key(gofig.String, "", "", "Tag prefix for EBS naming", NameEC2+"."+Tag)
 	r.Key(gofig.String, "", "", "", NameEC2+"."+KmsKeyID)
+	r.Key(gofig.String, "", "", "", NameEC2+"."+RoleARN)
+	r.Key(gofig.String, "", "", "", NameEC2+"."+RoleExternalID)
+	r.Key(gofig.String, "", DefaultRoleSessionName, "",
+		NameEC2+"."+RoleSessionName)
+	r.Key(gofig.String, "", "", "", NameEC2+"."+FastSnapshotRestoreZones)
+	r.Key(gofig.String, "", DefaultDescribeCacheTTL, "",
+		NameEC2+"."+DescribeCacheTTL)
 
 	r.Key(gofig.String, "", "", "", NameAWS+"."+AccessKey)
 	r.Key(gofig.String, "", "", "", NameAWS+"."+SecretKey)
@@ -89,6 +132,13 @@ func init() {
 	r.Key(gofig.Int, "", DefaultMaxRetries, "", NameAWS+"."+MaxRetries)
 	r.Key(gofig.String, "", "", "Tag prefix for EBS naming", NameAWS+"."+Tag)
 	r.Key(gofig.String, "", "", "", NameAWS+"."+KmsKeyID)
+	r.Key(gofig.String, "", "", "", NameAWS+"."+RoleARN)
+	r.Key(gofig.String, "", "", "", NameAWS+"."+RoleExternalID)
+	r.Key(gofig.String, "", DefaultRoleSessionName, "",
+		NameAWS+"."+RoleSessionName)
+	r.Key(gofig.String, "", "", "", NameAWS+"."+FastSnapshotRestoreZones)
+	r.Key(gofig.String, "", DefaultDescribeCacheTTL, "",
+		NameAWS+"."+DescribeCacheTTL)
 
 	gofigCore.Register(r)
 }
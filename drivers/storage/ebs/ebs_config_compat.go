@@ -35,6 +35,22 @@ const (
 	// ConfigEBSKmsKeyID is a config key.
 	ConfigEBSKmsKeyID = ConfigEBS + "." + KmsKeyID
 
+	// ConfigEBSRoleARN is a config key.
+	ConfigEBSRoleARN = ConfigEBS + "." + RoleARN
+
+	// ConfigEBSRoleExternalID is a config key.
+	ConfigEBSRoleExternalID = ConfigEBS + "." + RoleExternalID
+
+	// ConfigEBSRoleSessionName is a config key.
+	ConfigEBSRoleSessionName = ConfigEBS + "." + RoleSessionName
+
+	// ConfigEBSFastSnapshotRestoreZones is a config key.
+	ConfigEBSFastSnapshotRestoreZones = ConfigEBS + "." +
+		FastSnapshotRestoreZones
+
+	// ConfigEBSDescribeCacheTTL is a config key.
+	ConfigEBSDescribeCacheTTL = ConfigEBS + "." + DescribeCacheTTL
+
 	// ConfigEC2 is a config key.
 	ConfigEC2 = "ec2"
 
@@ -62,6 +78,22 @@ const (
 	// ConfigEC2KmsKeyID is a config key.
 	ConfigEC2KmsKeyID = ConfigEC2 + "." + KmsKeyID
 
+	// ConfigEC2RoleARN is a config key.
+	ConfigEC2RoleARN = ConfigEC2 + "." + RoleARN
+
+	// ConfigEC2RoleExternalID is a config key.
+	ConfigEC2RoleExternalID = ConfigEC2 + "." + RoleExternalID
+
+	// ConfigEC2RoleSessionName is a config key.
+	ConfigEC2RoleSessionName = ConfigEC2 + "." + RoleSessionName
+
+	// ConfigEC2FastSnapshotRestoreZones is a config key.
+	ConfigEC2FastSnapshotRestoreZones = ConfigEC2 + "." +
+		FastSnapshotRestoreZones
+
+	// ConfigEC2DescribeCacheTTL is a config key.
+	ConfigEC2DescribeCacheTTL = ConfigEC2 + "." + DescribeCacheTTL
+
 	// ConfigAWS is a config key.
 	ConfigAWS = "aws"
 
@@ -88,6 +120,22 @@ const (
 
 	// ConfigAWSKmsKeyID is a config key.
 	ConfigAWSKmsKeyID = ConfigAWS + "." + KmsKeyID
+
+	// ConfigAWSRoleARN is a config key.
+	ConfigAWSRoleARN = ConfigAWS + "." + RoleARN
+
+	// ConfigAWSRoleExternalID is a config key.
+	ConfigAWSRoleExternalID = ConfigAWS + "." + RoleExternalID
+
+	// ConfigAWSRoleSessionName is a config key.
+	ConfigAWSRoleSessionName = ConfigAWS + "." + RoleSessionName
+
+	// ConfigAWSFastSnapshotRestoreZones is a config key.
+	ConfigAWSFastSnapshotRestoreZones = ConfigAWS + "." +
+		FastSnapshotRestoreZones
+
+	// ConfigAWSDescribeCacheTTL is a config key.
+	ConfigAWSDescribeCacheTTL = ConfigAWS + "." + DescribeCacheTTL
 )
 
 // BackCompat ensures keys can be used from old configurations.
@@ -101,6 +149,12 @@ func BackCompat(config gofig.Config) {
 		{ConfigEBSTag, ConfigEC2Tag},
 		{ConfigEBSRexrayTag, ConfigEC2RexrayTag},
 		{ConfigEBSKmsKeyID, ConfigEC2KmsKeyID},
+		{ConfigEBSRoleARN, ConfigEC2RoleARN},
+		{ConfigEBSRoleExternalID, ConfigEC2RoleExternalID},
+		{ConfigEBSRoleSessionName, ConfigEC2RoleSessionName},
+		{ConfigEBSFastSnapshotRestoreZones,
+			ConfigEC2FastSnapshotRestoreZones},
+		{ConfigEBSDescribeCacheTTL, ConfigEC2DescribeCacheTTL},
 	}
 	for _, check := range ec2Checks {
 		if !config.IsSet(check[0]) && config.IsSet(check[1]) {
@@ -118,6 +172,12 @@ func BackCompat(config gofig.Config) {
 		{ConfigEBSTag, ConfigAWSTag},
 		{ConfigEBSRexrayTag, ConfigAWSRexrayTag},
 		{ConfigEBSKmsKeyID, ConfigAWSKmsKeyID},
+		{ConfigEBSRoleARN, ConfigAWSRoleARN},
+		{ConfigEBSRoleExternalID, ConfigAWSRoleExternalID},
+		{ConfigEBSRoleSessionName, ConfigAWSRoleSessionName},
+		{ConfigEBSFastSnapshotRestoreZones,
+			ConfigAWSFastSnapshotRestoreZones},
+		{ConfigEBSDescribeCacheTTL, ConfigAWSDescribeCacheTTL},
 	}
 	for _, check := range awsChecks {
 		if !config.IsSet(check[0]) && config.IsSet(check[1]) {
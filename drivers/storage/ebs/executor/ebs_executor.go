@@ -6,8 +6,6 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
-	"os"
-	"path"
 	"regexp"
 	"strings"
 
@@ -125,45 +123,6 @@ func (d *driver) NextDevice(
 	return "", errNoAvaiDevice
 }
 
-const procPartitions = "/proc/partitions"
-
-var xvdRX = regexp.MustCompile(`^xvd[a-z]$`)
-
-// Retrieve device paths currently attached and/or mounted
-func (d *driver) LocalDevices(
-	ctx types.Context,
-	opts *types.LocalDevicesOpts) (*types.LocalDevices, error) {
-
-	f, err := os.Open(procPartitions)
-	if err != nil {
-		return nil, goof.WithError("error reading "+procPartitions, err)
-	}
-	defer f.Close()
-
-	devMap := map[string]string{}
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		fields := strings.Fields(scanner.Text())
-		if len(fields) != 4 {
-			continue
-		}
-		devName := fields[3]
-		if !xvdRX.MatchString(devName) {
-			continue
-		}
-		devPath := path.Join("/dev/", devName)
-		devMap[devPath] = devPath
-	}
-
-	ld := &types.LocalDevices{Driver: d.Name()}
-	if len(devMap) > 0 {
-		ld.DeviceMap = devMap
-	}
-
-	return ld, nil
-}
-
 var ephemDevRX = regexp.MustCompile(`ephemeral([0-9]|1[0-9]|2[0-3])$`)
 
 // Find ephemeral devices from metadata
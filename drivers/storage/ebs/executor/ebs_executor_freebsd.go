@@ -0,0 +1,54 @@
+// +build freebsd
+// +build !libstorage_storage_executor libstorage_storage_executor_ebs
+
+package executor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+	ebsUtils "github.com/codedellemc/libstorage/drivers/storage/ebs/utils"
+)
+
+var xbdRX = regexp.MustCompile(`^xbd(\d+)$`)
+
+// Retrieve device paths currently attached and/or mounted. FreeBSD's Xen
+// block front driver numbers each attached volume by the index of the
+// letter of the volume's device name, so a volume attached as /dev/xvdf
+// appears as /dev/xbd5. The returned map's keys are the device names and
+// its values the paths of the disks.
+func (d *driver) LocalDevices(
+	ctx types.Context,
+	opts *types.LocalDevicesOpts) (*types.LocalDevices, error) {
+
+	disks, err := utils.GeomDisks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	devMap := map[string]string{}
+
+	for _, disk := range disks {
+		m := xbdRX.FindStringSubmatch(disk.Name)
+		if m == nil {
+			continue
+		}
+		unit, _ := strconv.Atoi(m[1])
+		if unit > 25 {
+			continue
+		}
+		devName := fmt.Sprintf(
+			"/dev/%s%c", ebsUtils.NextDeviceInfo.Prefix, 'a'+unit)
+		devMap[devName] = disk.Path()
+	}
+
+	ld := &types.LocalDevices{Driver: d.Name()}
+	if len(devMap) > 0 {
+		ld.DeviceMap = devMap
+	}
+
+	return ld, nil
+}
@@ -0,0 +1,55 @@
+// +build !windows,!freebsd
+// +build !libstorage_storage_executor libstorage_storage_executor_ebs
+
+package executor
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+const procPartitions = "/proc/partitions"
+
+var xvdRX = regexp.MustCompile(`^xvd[a-z]$`)
+
+// Retrieve device paths currently attached and/or mounted
+func (d *driver) LocalDevices(
+	ctx types.Context,
+	opts *types.LocalDevicesOpts) (*types.LocalDevices, error) {
+
+	f, err := os.Open(procPartitions)
+	if err != nil {
+		return nil, goof.WithError("error reading "+procPartitions, err)
+	}
+	defer f.Close()
+
+	devMap := map[string]string{}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 4 {
+			continue
+		}
+		devName := fields[3]
+		if !xvdRX.MatchString(devName) {
+			continue
+		}
+		devPath := path.Join("/dev/", devName)
+		devMap[devPath] = devPath
+	}
+
+	ld := &types.LocalDevices{Driver: d.Name()}
+	if len(devMap) > 0 {
+		ld.DeviceMap = devMap
+	}
+
+	return ld, nil
+}
@@ -0,0 +1,50 @@
+// +build windows
+// +build !libstorage_storage_executor libstorage_storage_executor_ebs
+
+package executor
+
+import (
+	"fmt"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+	ebsUtils "github.com/codedellemc/libstorage/drivers/storage/ebs/utils"
+)
+
+// Retrieve device paths currently attached and/or mounted. The AWS PV
+// storage driver presents each attached volume with a SCSI target ID that
+// is the index of the letter of the volume's device name, so a volume
+// attached as /dev/xvdf has target ID 5. The returned map's keys are the
+// device names and its values the paths of the disks, ex.
+// \\.\PHYSICALDRIVE1.
+func (d *driver) LocalDevices(
+	ctx types.Context,
+	opts *types.LocalDevicesOpts) (*types.LocalDevices, error) {
+
+	disks, err := utils.Disks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	devMap := map[string]string{}
+
+	for _, disk := range disks {
+		if disk.IsBoot || disk.IsSystem {
+			continue
+		}
+		target := disk.Target()
+		if target < 1 || target > 25 {
+			continue
+		}
+		devName := fmt.Sprintf(
+			"/dev/%s%c", ebsUtils.NextDeviceInfo.Prefix, 'a'+target)
+		devMap[devName] = disk.Path()
+	}
+
+	ld := &types.LocalDevices{Driver: d.Name()}
+	if len(devMap) > 0 {
+		ld.DeviceMap = devMap
+	}
+
+	return ld, nil
+}
@@ -0,0 +1,103 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_ebs
+
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+	awsec2 "github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// describeResponses is shared by the sessions of the driver, since the
+// sessions are also shared by the services that use the driver.
+var describeResponses = newDescribeCache()
+
+// describeCache caches the responses of EC2 describe operations for a short
+// time. Concurrent requests for the same response share a single call to
+// EC2, so a burst of requests, such as when many containers are restarted at
+// once, results in one call instead of one per request.
+type describeCache struct {
+	sync.Mutex
+	entries map[string]*describeEntry
+}
+
+type describeEntry struct {
+	done    chan struct{}
+	val     interface{}
+	err     error
+	expires time.Time
+}
+
+func newDescribeCache() *describeCache {
+	return &describeCache{entries: map[string]*describeEntry{}}
+}
+
+// do returns the cached response for the key. If there is none, or it has
+// expired, fn is called to get the response, which is cached for the ttl.
+// Callers that request the key while fn is running wait for and share its
+// response. Errors are returned to the waiting callers but are not cached.
+func (c *describeCache) do(
+	key string,
+	ttl time.Duration,
+	fn func() (interface{}, error)) (interface{}, error) {
+
+	if c == nil || ttl <= 0 {
+		return fn()
+	}
+
+	c.Lock()
+	if e, ok := c.entries[key]; ok {
+		select {
+		case <-e.done:
+			if e.err == nil && time.Now().Before(e.expires) {
+				c.Unlock()
+				return e.val, nil
+			}
+		default:
+			c.Unlock()
+			<-e.done
+			return e.val, e.err
+		}
+	}
+	e := &describeEntry{done: make(chan struct{})}
+	c.entries[key] = e
+	c.Unlock()
+
+	e.val, e.err = fn()
+	e.expires = time.Now().Add(ttl)
+	close(e.done)
+	return e.val, e.err
+}
+
+// invalidate removes all of the cached responses. Calls that are in flight
+// still return their responses to the callers waiting on them.
+func (c *describeCache) invalidate() {
+	if c == nil {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	c.entries = map[string]*describeEntry{}
+}
+
+// invalidateOnChange is a handler that invalidates the cache after any EC2
+// operation other than a describe operation is sent, since the operation
+// may have changed the volumes or instances described by the cached
+// responses.
+func (c *describeCache) invalidateOnChange(r *request.Request) {
+	if !strings.HasPrefix(r.Operation.Name, "Describe") {
+		c.invalidate()
+	}
+}
+
+// describeCacheKey returns the key of a describe operation's response. The
+// key includes the session so that the responses from different regions
+// are not mixed.
+func describeCacheKey(
+	svc *awsec2.EC2, op string, input fmt.Stringer) string {
+	return fmt.Sprintf("%p-%s-%s", svc, op, input.String())
+}
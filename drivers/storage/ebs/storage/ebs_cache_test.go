@@ -0,0 +1,92 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_ebs
+
+package storage
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribeCache(t *testing.T) {
+	c := newDescribeCache()
+	calls := 0
+	fn := func() (interface{}, error) {
+		calls++
+		return calls, nil
+	}
+
+	v, err := c.do("key", time.Minute, fn)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+	v, _ = c.do("key", time.Minute, fn)
+	assert.Equal(t, 1, v)
+	v, _ = c.do("other", time.Minute, fn)
+	assert.Equal(t, 2, v)
+
+	c.invalidate()
+	v, _ = c.do("key", time.Minute, fn)
+	assert.Equal(t, 3, v)
+
+	// a zero ttl disables the cache
+	v, _ = c.do("key", 0, fn)
+	assert.Equal(t, 4, v)
+}
+
+func TestDescribeCacheErrorNotCached(t *testing.T) {
+	c := newDescribeCache()
+	_, err := c.do("key", time.Minute, func() (interface{}, error) {
+		return nil, errors.New("throttled")
+	})
+	assert.Error(t, err)
+	v, err := c.do("key", time.Minute, func() (interface{}, error) {
+		return "ok", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", v)
+}
+
+func TestDescribeCacheShared(t *testing.T) {
+	var (
+		c       = newDescribeCache()
+		calls   = 0
+		release = make(chan struct{})
+		started = make(chan struct{})
+		wg      sync.WaitGroup
+	)
+	fn := func() (interface{}, error) {
+		calls++
+		close(started)
+		<-release
+		return "ok", nil
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.do("key", time.Minute, fn)
+	}()
+	<-started
+
+	results := make(chan interface{}, 3)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, _ := c.do("key", time.Minute, fn)
+			results <- v
+		}()
+	}
+
+	close(release)
+	wg.Wait()
+	close(results)
+
+	assert.Equal(t, 1, calls)
+	for v := range results {
+		assert.Equal(t, "ok", v)
+	}
+}
@@ -0,0 +1,78 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_ebs
+
+package storage
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/drivers/storage/ebs"
+)
+
+// opEnableFastSnapshotRestores is the name of the EC2 operation that enables
+// fast snapshot restore. The vendored SDK predates the operation, so it is
+// sent with the input and output types below. EC2 accepts it with the API
+// version the SDK already uses.
+const opEnableFastSnapshotRestores = "EnableFastSnapshotRestores"
+
+type enableFastSnapshotRestoresInput struct {
+	_ struct{} `type:"structure"`
+
+	AvailabilityZones []*string `locationName:"AvailabilityZone" locationNameList:"AvailabilityZone" type:"list" required:"true"`
+
+	SourceSnapshotIds []*string `locationName:"SourceSnapshotId" locationNameList:"SnapshotId" type:"list" required:"true"`
+}
+
+type enableFastSnapshotRestoresOutput struct {
+	_ struct{} `type:"structure"`
+}
+
+// enableFastSnapshotRestores enables fast snapshot restore for a snapshot in
+// the availability zones.
+func enableFastSnapshotRestores(
+	ctx types.Context, snapshotID string, zones []string) error {
+
+	input := &enableFastSnapshotRestoresInput{
+		SourceSnapshotIds: []*string{&snapshotID},
+	}
+	for i := range zones {
+		input.AvailabilityZones = append(input.AvailabilityZones, &zones[i])
+	}
+
+	req := mustSession(ctx).NewRequest(
+		&request.Operation{
+			Name:       opEnableFastSnapshotRestores,
+			HTTPMethod: "POST",
+			HTTPPath:   "/",
+		},
+		input,
+		&enableFastSnapshotRestoresOutput{})
+	return req.Send()
+}
+
+// fastSnapshotRestoreZones returns the availability zones in which to enable
+// fast snapshot restore for a new snapshot. The zones may be specified with
+// the snapshot request's options, otherwise the zones in the driver's config
+// are used.
+func (d *driver) fastSnapshotRestoreZones(opts types.Store) []string {
+	var zones []string
+	if opts != nil && opts.IsSet(ebs.FastSnapshotRestoreZones) {
+		zones = opts.GetStringSlice(ebs.FastSnapshotRestoreZones)
+		if len(zones) == 0 {
+			if v := opts.GetString(ebs.FastSnapshotRestoreZones); v != "" {
+				zones = strings.Split(v, ",")
+			}
+		}
+	} else {
+		zones = d.getFastSnapshotRestoreZones()
+	}
+	var trimmed []string
+	for _, z := range zones {
+		if z = strings.TrimSpace(z); z != "" {
+			trimmed = append(trimmed, z)
+		}
+	}
+	return trimmed
+}
@@ -0,0 +1,171 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_ebs
+
+package storage
+
+import (
+	"github.com/akutz/goof"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	awsec2 "github.com/aws/aws-sdk-go/service/ec2"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// opCreateSnapshots is the name of the EC2 operation that creates
+// crash-consistent snapshots of the volumes attached to an instance. The
+// vendored SDK predates the operation, so it is sent with the input and
+// output types below. EC2 accepts it with the API version the SDK already
+// uses.
+const opCreateSnapshots = "CreateSnapshots"
+
+type createSnapshotsInput struct {
+	_ struct{} `type:"structure"`
+
+	Description *string `type:"string"`
+
+	InstanceSpecification *instanceSpecification `type:"structure" required:"true"`
+}
+
+type instanceSpecification struct {
+	_ struct{} `type:"structure"`
+
+	ExcludeBootVolume *bool `type:"boolean"`
+
+	InstanceId *string `type:"string"`
+}
+
+type createSnapshotsOutput struct {
+	_ struct{} `type:"structure"`
+
+	Snapshots []*snapshotInfo `locationName:"snapshotSet" locationNameList:"item" type:"list"`
+}
+
+type snapshotInfo struct {
+	_ struct{} `type:"structure"`
+
+	SnapshotId *string `locationName:"snapshotId" type:"string"`
+
+	VolumeId *string `locationName:"volumeId" type:"string"`
+}
+
+// SnapshotGroupCreate snapshots the volumes at the same point in time with
+// the CreateSnapshots operation, which snapshots all of the volumes attached
+// to an instance. The snapshots of the instance's other volumes are removed.
+// ErrNotImplemented is returned if the volumes are not all attached to the
+// same instance.
+func (d *driver) SnapshotGroupCreate(
+	ctx types.Context,
+	volumeIDs []string,
+	snapshotName string,
+	opts types.Store) (*types.SnapshotGroup, error) {
+
+	fields := map[string]interface{}{
+		"volumeIDs":    volumeIDs,
+		"snapshotName": snapshotName,
+	}
+
+	vols := map[string]*awsec2.Volume{}
+	instanceID := ""
+	for _, volumeID := range volumeIDs {
+		ec2vols, err := d.getVolume(ctx, volumeID, "")
+		if err != nil {
+			return nil, goof.WithFieldsE(fields, "error getting volume", err)
+		}
+		if len(ec2vols) == 0 {
+			return nil, utils.NewVolumeNotFoundError(volumeID)
+		}
+		iid := attachedInstanceID(ec2vols[0])
+		if iid == "" || (instanceID != "" && iid != instanceID) {
+			return nil, types.ErrNotImplemented
+		}
+		instanceID = iid
+		vols[volumeID] = ec2vols[0]
+	}
+	fields["instanceID"] = instanceID
+
+	output := &createSnapshotsOutput{}
+	req := mustSession(ctx).NewRequest(
+		&request.Operation{
+			Name:       opCreateSnapshots,
+			HTTPMethod: "POST",
+			HTTPPath:   "/",
+		},
+		&createSnapshotsInput{
+			Description: aws.String(d.getFullName(snapshotName)),
+			InstanceSpecification: &instanceSpecification{
+				ExcludeBootVolume: aws.Bool(false),
+				InstanceId:        &instanceID,
+			},
+		},
+		output)
+	if err := req.Send(); err != nil {
+		return nil, goof.WithFieldsE(fields, "error creating snapshots", err)
+	}
+
+	group := &types.SnapshotGroup{
+		Name:       snapshotName,
+		Consistent: true,
+		Snapshots:  map[string]*types.Snapshot{},
+	}
+
+	var snapshotIDs []string
+	for _, si := range output.Snapshots {
+		snapshotID := aws.StringValue(si.SnapshotId)
+		vol, ok := vols[aws.StringValue(si.VolumeId)]
+		if !ok {
+			d.removeGroupSnapshots(ctx, snapshotID)
+			continue
+		}
+		snapshotIDs = append(snapshotIDs, snapshotID)
+		group.Snapshots[*vol.VolumeId] = &types.Snapshot{ID: snapshotID}
+	}
+
+	err := func() error {
+		for volumeID, s := range group.Snapshots {
+			if err := d.createSnapshotTags(
+				ctx, s.ID, snapshotName, vols[volumeID].Tags); err != nil {
+				return goof.WithFieldsE(fields, "error creating tags", err)
+			}
+			snap, err := d.SnapshotInspect(ctx, s.ID, opts)
+			if err != nil {
+				return err
+			}
+			group.Snapshots[volumeID] = snap
+		}
+		if len(group.Snapshots) != len(vols) {
+			return goof.WithFields(fields, "volumes not snapshotted")
+		}
+		return nil
+	}()
+	if err != nil {
+		d.removeGroupSnapshots(ctx, snapshotIDs...)
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// removeGroupSnapshots removes snapshots created by CreateSnapshots that do
+// not belong to a snapshot group.
+func (d *driver) removeGroupSnapshots(
+	ctx types.Context, snapshotIDs ...string) {
+
+	for _, snapshotID := range snapshotIDs {
+		if err := d.SnapshotRemove(ctx, snapshotID, nil); err != nil {
+			ctx.WithError(err).WithField("snapshotID", snapshotID).Warn(
+				"error removing snapshot of snapshot group")
+		}
+	}
+}
+
+// attachedInstanceID returns the ID of the instance to which the volume is
+// attached.
+func attachedInstanceID(vol *awsec2.Volume) string {
+	for _, att := range vol.Attachments {
+		if aws.StringValue(att.State) == awsec2.VolumeAttachmentStateAttached {
+			return aws.StringValue(att.InstanceId)
+		}
+	}
+	return ""
+}
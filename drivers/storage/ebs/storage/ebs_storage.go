@@ -6,6 +6,7 @@ import (
 	"crypto/md5"
 	"fmt"
 	"hash"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -16,15 +17,20 @@ import (
 	"github.com/akutz/goof"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/credentials/ec2rolecreds"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/ec2metadata"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	awsec2 "github.com/aws/aws-sdk-go/service/ec2"
 
 	"github.com/codedellemc/libstorage/api/context"
 	"github.com/codedellemc/libstorage/api/registry"
 	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
 	"github.com/codedellemc/libstorage/drivers/storage/ebs"
 	ebsUtils "github.com/codedellemc/libstorage/drivers/storage/ebs/utils"
 )
@@ -36,6 +42,18 @@ const (
 	waitVolumeAttach = "attach"
 	// waitVolumeDetach signifies to wait for volume detachment to complete
 	waitVolumeDetach = "detach"
+
+	// recycledTagKey is the key of the tag whose value is the time at which
+	// a volume was recycled
+	recycledTagKey = "libstorage.recycled"
+
+	// metadataTagPrefix is the prefix of the keys of the tags with which
+	// the server stamps the objects it creates
+	metadataTagPrefix = "libstorage-"
+
+	// metricsPeriod is the number of seconds over which CloudWatch
+	// aggregates the metrics of EBS volumes with basic monitoring
+	metricsPeriod = 300
 )
 
 type driver struct {
@@ -46,6 +64,9 @@ type driver struct {
 	maxRetries *int
 	accessKey  string
 	kmsKeyID   string
+	roleARN    string
+
+	describeCacheTTL time.Duration
 }
 
 func init() {
@@ -76,6 +97,8 @@ func (d *driver) Init(context types.Context, config gofig.Config) error {
 	d.accessKey = d.getAccessKey()
 	if v := d.getRegion(); v != "" {
 		d.region = &v
+	} else if v := detectRegion(); v != "" {
+		d.region = &v
 	}
 	if v := d.getEndpoint(); v != "" {
 		d.endpoint = &v
@@ -83,10 +106,31 @@ func (d *driver) Init(context types.Context, config gofig.Config) error {
 	maxRetries := d.getMaxRetries()
 	d.maxRetries = &maxRetries
 	d.kmsKeyID = d.getKmsKeyID()
+	d.roleARN = d.getRoleARN()
+	ttl, err := time.ParseDuration(d.getDescribeCacheTTL())
+	if err != nil {
+		return goof.WithError("invalid describe cache ttl", err)
+	}
+	d.describeCacheTTL = ttl
 	log.Info("storage driver initialized")
 	return nil
 }
 
+// detectRegion returns the region of the EC2 instance on which the driver is
+// running according to the instance metadata service. An empty string is
+// returned if the driver is not running on an EC2 instance. The request is
+// not retried so that initialization is not delayed off of EC2.
+func detectRegion() string {
+	region, err := ec2metadata.New(
+		session.New(), &aws.Config{MaxRetries: aws.Int(0)}).Region()
+	if err != nil {
+		log.WithError(err).Debug("error detecting region from metadata")
+		return ""
+	}
+	log.WithField(ebs.Region, region).Info("detected region from metadata")
+	return region
+}
+
 const cacheKeyC = "cacheKey"
 
 var (
@@ -110,6 +154,7 @@ func (d *driver) Login(ctx types.Context) (interface{}, error) {
 		ckey     string
 		hkey     = md5.New()
 		akey     = d.accessKey
+		role     = d.roleARN
 		region   = d.mustRegion(ctx)
 	)
 
@@ -123,6 +168,7 @@ func (d *driver) Login(ctx types.Context) (interface{}, error) {
 	writeHkey(hkey, region)
 	writeHkey(hkey, endpoint)
 	writeHkey(hkey, &akey)
+	writeHkey(hkey, &role)
 	ckey = fmt.Sprintf("%x", hkey.Sum(nil))
 
 	// if the session is cached then return it
@@ -151,44 +197,99 @@ func (d *driver) Login(ctx types.Context) (interface{}, error) {
 	if endpoint != nil {
 		fields[ebs.Endpoint] = *endpoint
 	}
+	if role != "" {
+		fields[ebs.RoleARN] = role
+	}
 
 	log.WithFields(fields).Debug("ebs service connetion attempt")
 	sess := session.New()
 
+	creds := credentials.NewChainCredentials(
+		[]credentials.Provider{
+			&credentials.StaticProvider{
+				Value: credentials.Value{
+					AccessKeyID:     akey,
+					SecretAccessKey: skey,
+				},
+			},
+			&credentials.EnvProvider{},
+			&credentials.SharedCredentialsProvider{},
+			&ec2rolecreds.EC2RoleProvider{
+				Client: ec2metadata.New(sess),
+			},
+		},
+	)
+
+	// the role is assumed with the credentials from the chain above, and
+	// the credentials of the role are refreshed by the STS provider before
+	// they expire
+	if role != "" {
+		creds = stscreds.NewCredentials(
+			session.New(&aws.Config{
+				Region:      region,
+				MaxRetries:  d.maxRetries,
+				Credentials: creds,
+			}),
+			role,
+			d.assumeRoleOptions,
+		)
+	}
+
 	svc := awsec2.New(
 		sess,
 		&aws.Config{
-			Region:     region,
-			Endpoint:   endpoint,
-			MaxRetries: d.maxRetries,
-			Credentials: credentials.NewChainCredentials(
-				[]credentials.Provider{
-					&credentials.StaticProvider{
-						Value: credentials.Value{
-							AccessKeyID:     akey,
-							SecretAccessKey: skey,
-						},
-					},
-					&credentials.EnvProvider{},
-					&credentials.SharedCredentialsProvider{},
-					&ec2rolecreds.EC2RoleProvider{
-						Client: ec2metadata.New(sess),
-					},
-				},
-			),
+			Region:      region,
+			Endpoint:    endpoint,
+			MaxRetries:  d.maxRetries,
+			Credentials: creds,
 		},
 	)
 
+	// throttled requests back off together, and the describe cache is
+	// invalidated by any operation that may change what it describes
+	svc.Retryer = throttleRetryer{
+		DefaultRetryer: client.DefaultRetryer{NumMaxRetries: *d.maxRetries},
+	}
+	svc.Handlers.Send.PushFront(throttle.wait)
+	svc.Handlers.Send.PushBack(describeResponses.invalidateOnChange)
+	svc.Handlers.AfterRetry.PushBack(classifyBackendError)
+
 	sessions[ckey] = svc
 	log.WithFields(fields).Info("ebs service connetion created & cached")
 
 	return svc, nil
 }
 
+// assumeRoleOptions configures the provider of an assumed role's
+// credentials.
+func (d *driver) assumeRoleOptions(p *stscreds.AssumeRoleProvider) {
+	p.RoleSessionName = d.getRoleSessionName()
+	if v := d.getRoleExternalID(); v != "" {
+		p.ExternalID = &v
+	}
+	// refresh the credentials a minute before they expire so requests are
+	// not signed with credentials that expire while in flight
+	p.ExpiryWindow = time.Minute
+}
+
 func mustSession(ctx types.Context) *awsec2.EC2 {
 	return context.MustSession(ctx).(*awsec2.EC2)
 }
 
+// regionContext returns a context whose session is a client for the
+// provided region that uses the credentials of the context's session.
+func regionContext(ctx types.Context, region string) types.Context {
+	svc := mustSession(ctx)
+	rsvc := awsec2.New(session.New(), svc.Config.Copy(&aws.Config{
+		Region:   &region,
+		Endpoint: aws.String(fmt.Sprintf("ec2.%s.amazonaws.com", region)),
+	}))
+	rsvc.Retryer = svc.Retryer
+	rsvc.Handlers.Send.PushFront(throttle.wait)
+	rsvc.Handlers.AfterRetry.PushBack(classifyBackendError)
+	return ctx.WithValue(context.SessionKey, rsvc)
+}
+
 func mustInstanceIDID(ctx types.Context) *string {
 	return &context.MustInstanceID(ctx).ID
 }
@@ -213,6 +314,16 @@ func (d *driver) mustAvailabilityZone(ctx types.Context) *string {
 	return nil
 }
 
+func (d *driver) HealthCheck(ctx types.Context) error {
+	// describing the availability zones verifies that the endpoint is
+	// reachable and that the credentials are valid
+	if _, err := mustSession(ctx).DescribeAvailabilityZones(
+		&awsec2.DescribeAvailabilityZonesInput{}); err != nil {
+		return goof.WithError("error describing availability zones", err)
+	}
+	return nil
+}
+
 // NextDeviceInfo returns the information about the driver's next available
 // device workflow.
 func (d *driver) NextDeviceInfo(
@@ -220,6 +331,17 @@ func (d *driver) NextDeviceInfo(
 	return ebsUtils.NextDeviceInfo, nil
 }
 
+// Capabilities returns the capabilities of the driver's storage platform.
+func (d *driver) Capabilities(
+	ctx types.Context) (*types.StorageCapabilities, error) {
+
+	return &types.StorageCapabilities{
+		Snapshots:     true,
+		MaxVolumeSize: 16384,
+		Topology:      []string{"zone"},
+	}, nil
+}
+
 // Type returns the type of storage the driver provides.
 func (d *driver) Type(ctx types.Context) (types.StorageType, error) {
 	//Example: Block storage
@@ -235,6 +357,7 @@ func (d *driver) InstanceInspect(
 	return &types.Instance{
 		Name:         iid.ID,
 		Region:       iid.Fields[ebs.InstanceIDFieldRegion],
+		Zone:         iid.Fields[ebs.InstanceIDFieldAvailabilityZone],
 		InstanceID:   iid,
 		ProviderName: iid.Driver,
 	}, nil
@@ -252,6 +375,7 @@ func (d *driver) Volumes(
 	if len(ec2vols) == 0 {
 		return nil, errNoVolReturned
 	}
+	ec2vols = omitRecycled(ec2vols)
 	// Convert retrieved volumes to libStorage types.Volume
 	vols, convErr := d.toTypesVolume(ctx, ec2vols, opts.Attachments)
 	if convErr != nil {
@@ -273,6 +397,9 @@ func (d *driver) VolumeInspect(
 	if len(ec2vols) == 0 {
 		return nil, errNoVolReturned
 	}
+	if ec2vols = omitRecycled(ec2vols); len(ec2vols) == 0 {
+		return nil, utils.NewVolumeNotFoundError(volumeID)
+	}
 	vols, convErr := d.toTypesVolume(ctx, ec2vols, opts.Attachments)
 	if convErr != nil {
 		return nil, goof.WithError("error converting to types.Volume", convErr)
@@ -326,72 +453,38 @@ func (d *driver) VolumeCreateFromSnapshot(
 	ctx types.Context,
 	snapshotID, volumeName string,
 	opts *types.VolumeCreateOpts) (*types.Volume, error) {
-	// TODO Snapshots are not implemented yet
-	return nil, types.ErrNotImplemented
-	/*
-		// Initialize for logging
-		fields := map[string]interface{}{
-			"driverName": d.Name(),
-			"snapshotID": snapshotID,
-			"volumeName": volumeName,
-			"opts":       opts,
-		}
-
-		log.WithFields(fields).Debug("creating volume from snapshot")
-
-		// Check if volume with same name exists
-		ec2vols, err := d.getVolume(ctx, "", volumeName)
-		if err != nil {
-			return nil, goof.WithFieldsE(fields, "Error getting volume", err)
-		}
-		volumes, convErr := d.toTypesVolume(ctx, ec2vols, false)
-		if convErr != nil {
-			return nil, goof.WithFieldsE(fields,
-				"Error converting to types.Volume", convErr)
-		}
+	// Initialize for logging
+	fields := map[string]interface{}{
+		"driverName": d.Name(),
+		"snapshotID": snapshotID,
+		"volumeName": volumeName,
+		"opts":       opts,
+	}
 
-		if len(volumes) > 0 {
-			return nil, goof.WithFields(fields, "volume name already exists")
-		}
+	log.WithFields(fields).Debug("creating volume from snapshot")
 
-		volume := &types.Volume{}
+	// Check if volume with same name exists
+	ec2vols, err := d.getVolume(ctx, "", volumeName)
+	if err != nil {
+		return nil, goof.WithFieldsE(fields, "error getting volume", err)
+	}
+	if len(ec2vols) > 0 {
+		return nil, goof.WithFields(fields, "volume name already exists")
+	}
 
-		// Pass arguments into libStorage types.Volume
-		if opts.AvailabilityZone != nil {
-			volume.AvailabilityZone = *opts.AvailabilityZone
-		}
-		if opts.Type != nil {
-			volume.Type = *opts.Type
-		}
-		if opts.Size != nil {
-			volume.Size = *opts.Size
-		}
-		if opts.IOPS != nil {
-			volume.IOPS = *opts.IOPS
-		}
-		if *opts.Encrypted == false {
-			// Volume must be encrypted if snapshot is encrypted
-			snapshot, err := d.SnapshotInspect(ctx, snapshotID, nil)
-			if err != nil {
-				return &types.Volume{}, goof.WithFieldsE(fields,
-					"Error getting snapshot", err)
-			}
-			volume.Encrypted = snapshot.Encrypted
-		} else {
-			volume.Encrypted = *opts.Encrypted
-		}
+	// the volume is as large as the snapshot unless a size is requested
+	if opts.Size != nil && *opts.Size == 0 {
+		opts.Size = nil
+	}
 
-		// Pass libStorage types.Volume to helper function which calls EC2 API
-		vol, err := d.createVolume(ctx, volumeName, snapshotID, volume)
-		if err != nil {
-			return &types.Volume{}, goof.WithFieldsE(fields,
-				"error creating volume", err)
-		}
-		// Return the volume created
-		return d.VolumeInspect(ctx, *vol.VolumeId, &types.VolumeInspectOpts{
-			Attachments: true,
-		})
-	*/
+	vol, err := d.createVolume(ctx, volumeName, snapshotID, opts)
+	if err != nil {
+		return nil, goof.WithFieldsE(fields, "error creating volume", err)
+	}
+	// Return the volume created
+	return d.VolumeInspect(ctx, *vol.VolumeId, &types.VolumeInspectOpts{
+		Attachments: types.VolAttReqTrue,
+	})
 }
 
 // VolumeCopy copies an existing volume.
@@ -399,7 +492,7 @@ func (d *driver) VolumeCopy(
 	ctx types.Context,
 	volumeID, volumeName string,
 	opts types.Store) (*types.Volume, error) {
-	// TODO Snapshots are not implemented yet
+	// TODO Copying volumes is not implemented yet
 	return nil, types.ErrNotImplemented
 	/*
 		// Creates a temp snapshot of an existing volume,
@@ -488,42 +581,53 @@ func (d *driver) VolumeSnapshot(
 	ctx types.Context,
 	volumeID, snapshotName string,
 	opts types.Store) (*types.Snapshot, error) {
-	// TODO Snapshots are not implemented yet
-	return nil, types.ErrNotImplemented
-	/*
-		// Create snapshot with EC2 API call
-		csInput := &awsec2.CreateSnapshotInput{
-			VolumeId: &volumeID,
-		}
 
-		resp, err := d.ec2Instance.CreateSnapshot(csInput)
-		if err != nil {
-			return nil, goof.WithError("Error creating snapshot", err)
-		}
+	fields := map[string]interface{}{
+		"volumeID":     volumeID,
+		"snapshotName": snapshotName,
+	}
 
-		// Add tags to EC2 snapshot
-		if err = d.createTags(*resp.SnapshotId, snapshotName); err != nil {
-			return &types.Snapshot{}, goof.WithError(
-				"Error creating tags", err)
-		}
+	ec2vols, err := d.getVolume(ctx, volumeID, "")
+	if err != nil {
+		return nil, goof.WithFieldsE(fields, "error getting volume", err)
+	}
+	if len(ec2vols) == 0 {
+		return nil, utils.NewVolumeNotFoundError(volumeID)
+	}
 
-		log.Println("Waiting for snapshot to complete")
-		err = d.waitSnapshotComplete(ctx, *resp.SnapshotId)
-		if err != nil {
-			return &types.Snapshot{}, goof.WithError(
-				"Error waiting for snapshot creation", err)
-		}
+	resp, err := mustSession(ctx).CreateSnapshot(&awsec2.CreateSnapshotInput{
+		VolumeId:    &volumeID,
+		Description: aws.String(d.getFullName(snapshotName)),
+	})
+	if err != nil {
+		return nil, goof.WithFieldsE(fields, "error creating snapshot", err)
+	}
+	fields["snapshotID"] = *resp.SnapshotId
 
-		// Check if successful snapshot
-		snapshot, err := d.SnapshotInspect(ctx, *resp.SnapshotId, nil)
-		if err != nil {
-			return &types.Snapshot{}, goof.WithError(
-				"Error getting snapshot", err)
+	// the snapshot is named and tagged like its volume
+	if err := d.createSnapshotTags(
+		ctx, *resp.SnapshotId, snapshotName, ec2vols[0].Tags); err != nil {
+		return nil, goof.WithFieldsE(fields, "error creating tags", err)
+	}
+
+	if zones := d.fastSnapshotRestoreZones(opts); len(zones) > 0 {
+		fields["zones"] = zones
+		// fast snapshot restore cannot be enabled until the snapshot is
+		// complete
+		ctx.WithFields(fields).Info("waiting for snapshot to complete")
+		if err := d.waitSnapshotComplete(ctx, *resp.SnapshotId); err != nil {
+			return nil, goof.WithFieldsE(
+				fields, "error waiting for snapshot creation", err)
+		}
+		if err := enableFastSnapshotRestores(
+			ctx, *resp.SnapshotId, zones); err != nil {
+			return nil, goof.WithFieldsE(
+				fields, "error enabling fast snapshot restore", err)
 		}
+		ctx.WithFields(fields).Info("enabled fast snapshot restore")
+	}
 
-		log.Println("Created Snapshot: " + snapshot.ID)
-		return snapshot, nil
-	*/
+	return d.SnapshotInspect(ctx, *resp.SnapshotId, opts)
 }
 
 // VolumeRemove removes a volume.
@@ -537,8 +641,6 @@ func (d *driver) VolumeRemove(
 		"volumeID": volumeID,
 	}
 
-	//TODO check if volume is attached? if so fail
-
 	// Delete volume via EC2 API call
 	dvInput := &awsec2.DeleteVolumeInput{
 		VolumeId: &volumeID,
@@ -551,6 +653,285 @@ func (d *driver) VolumeRemove(
 	return nil
 }
 
+// VolumeRecycle detaches the volume if it is attached and tags it as
+// recycled. Recycled volumes are omitted from the driver's volumes.
+func (d *driver) VolumeRecycle(
+	ctx types.Context,
+	volumeID string,
+	opts types.Store) error {
+
+	ec2vols, err := d.getVolume(ctx, volumeID, "")
+	if err != nil {
+		return goof.WithError("error getting volume", err)
+	}
+	if len(ec2vols) == 0 || isRecycled(ec2vols[0]) {
+		return errNoVolReturned
+	}
+
+	if len(ec2vols[0].Attachments) > 0 {
+		if _, err := d.VolumeDetach(ctx, volumeID, &types.VolumeDetachOpts{
+			Force: true,
+			Opts:  opts,
+		}); err != nil {
+			return goof.WithError("error detaching volume", err)
+		}
+	}
+
+	ctInput := &awsec2.CreateTagsInput{
+		Resources: []*string{&volumeID},
+		Tags: []*awsec2.Tag{
+			{
+				Key: aws.String(recycledTagKey),
+				Value: aws.String(
+					strconv.FormatInt(time.Now().Unix(), 10)),
+			},
+		},
+	}
+	if _, err := mustSession(ctx).CreateTags(ctInput); err != nil {
+		return goof.WithError("error tagging volume", err)
+	}
+
+	return nil
+}
+
+// RecycledVolumes returns the volumes tagged as recycled.
+func (d *driver) RecycledVolumes(
+	ctx types.Context,
+	opts types.Store) ([]*types.Volume, error) {
+
+	ec2vols, err := d.getVolume(ctx, "", "")
+	if err != nil {
+		return nil, goof.WithError("error getting volume", err)
+	}
+
+	var recycled []*awsec2.Volume
+	for _, v := range ec2vols {
+		if isRecycled(v) {
+			recycled = append(recycled, v)
+		}
+	}
+
+	vols, err := d.toTypesVolume(ctx, recycled, types.VolAttNone)
+	if err != nil {
+		return nil, goof.WithError("error converting to types.Volume", err)
+	}
+	for i, v := range vols {
+		if v.Fields == nil {
+			v.Fields = map[string]string{}
+		}
+		v.Fields[types.VolumeFieldRecycledTime] = getTag(
+			recycled[i].Tags, recycledTagKey)
+	}
+	return vols, nil
+}
+
+// RecycledVolumeRestore removes the volume's recycled tag.
+func (d *driver) RecycledVolumeRestore(
+	ctx types.Context,
+	volumeID string,
+	opts types.Store) (*types.Volume, error) {
+
+	if err := d.mustBeRecycled(ctx, volumeID); err != nil {
+		return nil, err
+	}
+
+	dtInput := &awsec2.DeleteTagsInput{
+		Resources: []*string{&volumeID},
+		Tags: []*awsec2.Tag{
+			{Key: aws.String(recycledTagKey)},
+		},
+	}
+	if _, err := mustSession(ctx).DeleteTags(dtInput); err != nil {
+		return nil, goof.WithError("error removing volume tag", err)
+	}
+
+	return d.VolumeInspect(ctx, volumeID, &types.VolumeInspectOpts{
+		Attachments: types.VolAttNone,
+		Opts:        opts,
+	})
+}
+
+// RecycledVolumePurge deletes the recycled volume.
+func (d *driver) RecycledVolumePurge(
+	ctx types.Context,
+	volumeID string,
+	opts types.Store) error {
+
+	if err := d.mustBeRecycled(ctx, volumeID); err != nil {
+		return err
+	}
+	return d.VolumeRemove(ctx, volumeID, &types.VolumeRemoveOpts{Opts: opts})
+}
+
+func (d *driver) mustBeRecycled(ctx types.Context, volumeID string) error {
+	ec2vols, err := d.getVolume(ctx, volumeID, "")
+	if err != nil {
+		return goof.WithError("error getting volume", err)
+	}
+	if len(ec2vols) == 0 || !isRecycled(ec2vols[0]) {
+		return utils.NewVolumeNotFoundError(volumeID)
+	}
+	return nil
+}
+
+// VolumeImport tags the EBS volume with the volume ID nativeID with the
+// volume name. If no volume name is provided the volume keeps its existing
+// name or, if it has none, is named after its volume ID.
+func (d *driver) VolumeImport(
+	ctx types.Context,
+	nativeID, volumeName string,
+	opts types.Store) (*types.Volume, error) {
+
+	fields := map[string]interface{}{
+		"driverName": d.Name(),
+		"nativeID":   nativeID,
+		"volumeName": volumeName,
+	}
+
+	ec2vols, err := d.getVolume(ctx, nativeID, "")
+	if err != nil {
+		return nil, goof.WithFieldsE(fields, "error getting volume", err)
+	}
+	if len(ec2vols) == 0 || isRecycled(ec2vols[0]) {
+		return nil, utils.NewVolumeNotFoundError(nativeID)
+	}
+
+	if volumeName == "" {
+		volumeName = d.getPrintableName(d.getName(ec2vols[0].Tags))
+	}
+	if volumeName == "" {
+		volumeName = nativeID
+	}
+
+	ec2VolsToCheck, err := d.getVolume(ctx, "", volumeName)
+	if err != nil {
+		return nil, goof.WithFieldsE(fields, "error getting volume", err)
+	}
+	for _, v := range ec2VolsToCheck {
+		if *v.VolumeId != nativeID {
+			return nil, goof.WithFields(fields, "volume name already exists")
+		}
+	}
+
+	if err := d.createTags(ctx, nativeID, volumeName); err != nil {
+		return nil, goof.WithFieldsE(fields, "error tagging volume", err)
+	}
+
+	vol, err := d.VolumeInspect(ctx, nativeID, &types.VolumeInspectOpts{
+		Attachments: types.VolAttReqTrue,
+		Opts:        opts,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if vol.Fields == nil {
+		vol.Fields = map[string]string{}
+	}
+	vol.Fields[types.VolumeFieldNativeID] = nativeID
+	return vol, nil
+}
+
+// VolumeUnmanage removes the Name tag with which libStorage names the EBS
+// volume, as well as the volume's recycled and metadata tags, leaving the
+// volume as it would be had it been created outside of libStorage.
+func (d *driver) VolumeUnmanage(
+	ctx types.Context,
+	volumeID string,
+	opts types.Store) error {
+
+	ec2vols, err := d.getVolume(ctx, volumeID, "")
+	if err != nil {
+		return goof.WithError("error getting volume", err)
+	}
+	if len(ec2vols) == 0 || isRecycled(ec2vols[0]) {
+		return utils.NewVolumeNotFoundError(volumeID)
+	}
+
+	dtInput := &awsec2.DeleteTagsInput{
+		Resources: []*string{&volumeID},
+		Tags: []*awsec2.Tag{
+			{Key: aws.String("Name")},
+			{Key: aws.String(recycledTagKey)},
+		},
+	}
+	for _, tag := range ec2vols[0].Tags {
+		if strings.HasPrefix(aws.StringValue(tag.Key), metadataTagPrefix) {
+			dtInput.Tags = append(dtInput.Tags, &awsec2.Tag{Key: tag.Key})
+		}
+	}
+	if _, err := mustSession(ctx).DeleteTags(dtInput); err != nil {
+		return goof.WithError("error removing volume tags", err)
+	}
+
+	return nil
+}
+
+// StampsVolumeMetadata returns true since the driver tags the volumes and
+// snapshots it creates with the server's metadata.
+func (d *driver) StampsVolumeMetadata(ctx types.Context) bool {
+	return true
+}
+
+// VolumeStats returns the volume's IO rates averaged over the most recent
+// period for which CloudWatch has metrics for the volume. The rates are zero
+// if CloudWatch has no recent metrics for the volume.
+func (d *driver) VolumeStats(
+	ctx types.Context,
+	volumeID string,
+	opts types.Store) (*types.VolumeIOStats, error) {
+
+	// CloudWatch is reached with the EC2 service's region and credentials
+	// but not its endpoint
+	config := mustSession(ctx).Client.Config.Copy()
+	config.Endpoint = nil
+	svc := cloudwatch.New(session.New(), config)
+
+	stats := &types.VolumeIOStats{Period: metricsPeriod}
+	rates := map[string]*float64{
+		"VolumeReadOps":    &stats.ReadOps,
+		"VolumeWriteOps":   &stats.WriteOps,
+		"VolumeReadBytes":  &stats.ReadBytes,
+		"VolumeWriteBytes": &stats.WriteBytes,
+	}
+
+	now := time.Now()
+	for metricName, rate := range rates {
+		out, err := svc.GetMetricStatistics(
+			&cloudwatch.GetMetricStatisticsInput{
+				Namespace:  aws.String("AWS/EBS"),
+				MetricName: aws.String(metricName),
+				Dimensions: []*cloudwatch.Dimension{
+					{
+						Name:  aws.String("VolumeId"),
+						Value: &volumeID,
+					},
+				},
+				StartTime: aws.Time(now.Add(-3 * metricsPeriod * time.Second)),
+				EndTime:   aws.Time(now),
+				Period:    aws.Int64(metricsPeriod),
+				Statistics: []*string{
+					aws.String(cloudwatch.StatisticSum),
+				},
+			})
+		if err != nil {
+			return nil, goof.WithFieldE(
+				"metricName", metricName, "error getting volume metric", err)
+		}
+
+		var latest *cloudwatch.Datapoint
+		for _, dp := range out.Datapoints {
+			if latest == nil || dp.Timestamp.After(*latest.Timestamp) {
+				latest = dp
+			}
+		}
+		if latest != nil && latest.Sum != nil {
+			*rate = *latest.Sum / metricsPeriod
+		}
+	}
+
+	return stats, nil
+}
+
 var (
 	errMissingNextDevice  = goof.New("missing next device")
 	errVolAlreadyAttached = goof.New("volume already attached to a host")
@@ -696,21 +1077,12 @@ func (d *driver) VolumeDetach(
 func (d *driver) Snapshots(
 	ctx types.Context,
 	opts types.Store) ([]*types.Snapshot, error) {
-	// TODO Snapshots are not implemented yet
-	return nil, types.ErrNotImplemented
-	/*
-		// Get all snapshots
-		ec2snapshots, err := d.getSnapshot(ctx, "", "", "")
-		if err != nil {
-			return nil, goof.WithError("error getting snapshot", err)
-		}
-		if len(ec2snapshots) == 0 {
-			return nil, goof.New("no snapshots returned")
-		}
-		// Convert to libStorage types.Snapshot
-		snapshots := d.toTypesSnapshot(ec2snapshots)
-		return snapshots, nil
-	*/
+
+	ec2snapshots, err := d.getSnapshot(ctx, "", "", "")
+	if err != nil {
+		return nil, goof.WithError("error getting snapshots", err)
+	}
+	return d.toTypesSnapshot(ec2snapshots), nil
 }
 
 // SnapshotInspect inspects a single snapshot.
@@ -718,99 +1090,77 @@ func (d *driver) SnapshotInspect(
 	ctx types.Context,
 	snapshotID string,
 	opts types.Store) (*types.Snapshot, error) {
-	// TODO Snapshots are not implemented yet
-	return nil, types.ErrNotImplemented
-	/*
-		// Get snapshot corresponding to snapshot ID
-		ec2snapshots, err := d.getSnapshot(ctx, "", snapshotID, "")
-		if err != nil {
-			return nil, goof.WithError("error getting snapshot", err)
-		}
-		if len(ec2snapshots) == 0 {
-			return nil, goof.New("no snapshots returned")
-		}
-		// Convert to libStorage types.Snapshot
-		snapshots := d.toTypesSnapshot(ec2snapshots)
 
-		// Because getSnapshot returns an array
-		// and we only expect the 1st element to be a match, return 1st element
-		return snapshots[0], nil
-	*/
+	ec2snapshots, err := d.getSnapshot(ctx, "", snapshotID, "")
+	if err != nil {
+		return nil, goof.WithFieldE(
+			"snapshotID", snapshotID, "error getting snapshot", err)
+	}
+	if len(ec2snapshots) == 0 {
+		return nil, utils.NewSnapshotNotFoundError(snapshotID)
+	}
+	return d.toTypesSnapshot(ec2snapshots)[0], nil
 }
 
-// SnapshotCopy copies an existing snapshot.
+// SnapshotCopy copies an existing snapshot. The snapshot is copied to the
+// region specified by the destination option, or within its own region if
+// there is none. The copy is named with the snapshot name, or the name of the
+// source snapshot if none is specified, and gets the source snapshot's tags.
 func (d *driver) SnapshotCopy(
 	ctx types.Context,
 	snapshotID, snapshotName, destinationID string,
 	opts types.Store) (*types.Snapshot, error) {
-	// TODO Snapshots are not implemented yet
-	return nil, types.ErrNotImplemented
-	/*
-		// no snapshot id inputted
-		if snapshotID == "" {
-			return &types.Snapshot{}, goof.New("Missing snapshotID")
-		}
 
-		// Get snapshot to copy
-		origSnapshots, err := d.getSnapshot(ctx, "", snapshotID, "")
-		if err != nil {
-			return &types.Snapshot{},
-				goof.WithError("Error getting snapshot", err)
-		}
-
-		if len(origSnapshots) > 1 {
-			return &types.Snapshot{},
-				goof.New("multiple snapshots returned")
-		} else if len(origSnapshots) == 0 {
-			return &types.Snapshot{}, goof.New("no snapshots returned")
-		}
-
-		// Copy snapshot with EC2 API call
-		snapshotID = *(origSnapshots[0]).SnapshotId
-		snapshotName = d.getName(origSnapshots[0].Tags)
+	if snapshotID == "" {
+		return nil, goof.New("missing snapshot id")
+	}
 
-		options := &awsec2.CopySnapshotInput{
-			SourceSnapshotId: &snapshotID,
-			SourceRegion:     &d.instanceDocument.Region,
-			Description:      aws.String(fmt.Sprintf("Copy of %s", snapshotID)),
-		}
-		resp := &awsec2.CopySnapshotOutput{}
+	fields := map[string]interface{}{
+		"snapshotID":   snapshotID,
+		"snapshotName": snapshotName,
+	}
 
-		resp, err = d.ec2Instance.CopySnapshot(options)
-		if err != nil {
-			return nil, goof.WithError("error copying snapshot", err)
-		}
+	ec2snapshots, err := d.getSnapshot(ctx, "", snapshotID, "")
+	if err != nil {
+		return nil, goof.WithFieldsE(fields, "error getting snapshot", err)
+	}
+	if len(ec2snapshots) == 0 {
+		return nil, utils.NewSnapshotNotFoundError(snapshotID)
+	}
+	if snapshotName == "" {
+		snapshotName = d.getName(ec2snapshots[0].Tags)
+	}
 
-		// Add tags to copied snapshot
-		if err = d.createTags(*resp.SnapshotId, snapshotName); err != nil {
-			return &types.Snapshot{}, goof.WithError(
-				"Error creating tags", err)
-		}
+	srcRegion := aws.StringValue(mustSession(ctx).Config.Region)
+	destCtx := ctx
+	if dest := opts.GetString("destination"); dest != "" && dest != srcRegion {
+		fields["destination"] = dest
+		destCtx = regionContext(ctx, dest)
+	}
 
-		log.WithFields(log.Fields{
-			"moduleName":      d.Name(),
-			"driverName":      d.Name(),
-			"snapshotName":    snapshotName,
-			"resp.SnapshotId": *resp.SnapshotId}).Info("waiting for snapshot to complete")
+	resp, err := mustSession(destCtx).CopySnapshot(&awsec2.CopySnapshotInput{
+		SourceSnapshotId: &snapshotID,
+		SourceRegion:     &srcRegion,
+		Description:      aws.String(d.getFullName(snapshotName)),
+	})
+	if err != nil {
+		return nil, goof.WithFieldsE(fields, "error copying snapshot", err)
+	}
+	fields["copyID"] = *resp.SnapshotId
 
-		// Wait for snapshot status to update
-		err = d.waitSnapshotComplete(ctx, *resp.SnapshotId)
-		if err != nil {
-			return &types.Snapshot{}, goof.WithError(
-				"Error waiting for snapshot creation", err)
-		}
+	if err := d.createSnapshotTags(
+		destCtx, *resp.SnapshotId, snapshotName,
+		ec2snapshots[0].Tags); err != nil {
+		return nil, goof.WithFieldsE(fields, "error creating tags", err)
+	}
 
-		// Check if successful snapshot
-		snapshotCopy, err := d.SnapshotInspect(ctx, *resp.SnapshotId, nil)
-		if err != nil {
-			return &types.Snapshot{}, goof.WithError(
-				"Error getting snapshot copy", err)
-		}
-		destinationID = snapshotCopy.ID
+	ctx.WithFields(fields).Info("waiting for snapshot copy to complete")
+	if err := d.waitSnapshotComplete(destCtx, *resp.SnapshotId); err != nil {
+		return nil, goof.WithFieldsE(
+			fields, "error waiting for snapshot copy", err)
+	}
 
-		log.Println("Copied Snapshot: " + destinationID)
-		return snapshotCopy, nil
-	*/
+	return d.SnapshotInspect(destCtx, *resp.SnapshotId, opts)
 }
 
 // SnapshotRemove removes a snapshot.
@@ -818,31 +1168,23 @@ func (d *driver) SnapshotRemove(
 	ctx types.Context,
 	snapshotID string,
 	opts types.Store) error {
-	// TODO Snapshots are not implemented yet
-	return types.ErrNotImplemented
-	/*
-		// Initialize for logging
-		fields := map[string]interface{}{
-			"provider":   d.Name(),
-			"snapshotID": snapshotID,
-		}
 
-		// no snapshot ID inputted
-		if snapshotID == "" {
-			return goof.New("missing snapshot id")
-		}
+	if snapshotID == "" {
+		return goof.New("missing snapshot id")
+	}
 
-		// Delete snapshot using EC2 API call
-		dsInput := &awsec2.DeleteSnapshotInput{
-			SnapshotId: &snapshotID,
-		}
-		_, err := d.ec2Instance.DeleteSnapshot(dsInput)
-		if err != nil {
-			return goof.WithFieldsE(fields, "error deleting snapshot", err)
+	_, err := mustSession(ctx).DeleteSnapshot(&awsec2.DeleteSnapshotInput{
+		SnapshotId: &snapshotID,
+	})
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok &&
+			awsErr.Code() == "InvalidSnapshot.NotFound" {
+			return utils.NewSnapshotNotFoundError(snapshotID)
 		}
-
-		return nil
-	*/
+		return goof.WithFieldE(
+			"snapshotID", snapshotID, "error deleting snapshot", err)
+	}
+	return nil
 }
 
 ///////////////////////////////////////////////////////////////////////
@@ -893,12 +1235,18 @@ func (d *driver) getVolume(
 	}
 
 	// Retrieve filtered volumes through EC2 API call
-	resp, err := mustSession(ctx).DescribeVolumes(dvInput)
+	svc := mustSession(ctx)
+	resp, err := describeResponses.do(
+		describeCacheKey(svc, "DescribeVolumes", dvInput),
+		d.describeCacheTTL,
+		func() (interface{}, error) {
+			return svc.DescribeVolumes(dvInput)
+		})
 	if err != nil {
 		return []*awsec2.Volume{}, err
 	}
 
-	return resp.Volumes, nil
+	return resp.(*awsec2.DescribeVolumesOutput).Volumes, nil
 }
 
 var errGetLocDevs = goof.New("error getting local devices from context")
@@ -935,8 +1283,11 @@ func (d *driver) toTypesVolume(
 					deviceName = strings.Replace(
 						*attachment.Device, "sd",
 						ebsUtils.NextDeviceInfo.Prefix, 1)
-					// Keep device name if it is found in local devices
-					if _, ok := ld.DeviceMap[deviceName]; !ok {
+					// Use the local path of the device if it is found in
+					// local devices, ex. \\.\PHYSICALDRIVE1 on Windows
+					if dev, ok := ld.DeviceMap[deviceName]; ok {
+						deviceName = dev
+					} else {
 						deviceName = ""
 					}
 				}
@@ -963,6 +1314,7 @@ func (d *driver) toTypesVolume(
 			Type:             *volume.VolumeType,
 			Size:             *volume.Size,
 			Attachments:      attachmentsSD,
+			Fields:           metadataFields(volume.Tags),
 		}
 
 		// Some volume types have no IOPS, so we get nil in volume.Iops
@@ -975,46 +1327,33 @@ func (d *driver) toTypesVolume(
 }
 
 // getSnapshot searches for and returns snapshots matching criteria
-// TODO Snapshots are not implemented yet
-/*
 func (d *driver) getSnapshot(
 	ctx types.Context,
 	volumeID, snapshotID, snapshotName string) ([]*awsec2.Snapshot, error) {
-	// Prepare filters
-	filters := []*awsec2.Filter{}
+
+	// only the account's own snapshots are returned rather than every
+	// public snapshot in the region
+	dsInput := &awsec2.DescribeSnapshotsInput{
+		OwnerIds: []*string{aws.String("self")},
+	}
+
 	if snapshotName != "" {
-		filters = append(filters, &awsec2.Filter{
+		dsInput.Filters = append(dsInput.Filters, &awsec2.Filter{
 			Name: aws.String("tag:Name"), Values: []*string{&snapshotName}})
 	}
 
 	if volumeID != "" {
-		filters = append(filters, &awsec2.Filter{
+		dsInput.Filters = append(dsInput.Filters, &awsec2.Filter{
 			Name: aws.String("volume-id"), Values: []*string{&volumeID}})
 	}
 
 	if snapshotID != "" {
-		//using SnapshotIds in request is returning stale data
-		filters = append(filters, &awsec2.Filter{
+		// using SnapshotIds in the request returns stale data
+		dsInput.Filters = append(dsInput.Filters, &awsec2.Filter{
 			Name: aws.String("snapshot-id"), Values: []*string{&snapshotID}})
 	}
 
-	// TODO rexrayTag?
-	//	if d.ec2Tag != "" {
-	//	filters = append(filters, &ec2.Filter{
-	//		Name:   aws.String(fmt.Sprintf("tag:%s", rexrayTag)),
-	//		Values: []*string{&d.ec2Tag}})
-	//}
-
-	// Prepare input
-	dsInput := &awsec2.DescribeSnapshotsInput{}
-
-	// Apply filters if arguments are specified
-	if len(filters) > 0 {
-		dsInput.Filters = filters
-	}
-
-	// Retrieve filtered volumes through EC2 API call
-	resp, err := d.ec2Instance.DescribeSnapshots(dsInput)
+	resp, err := mustSession(ctx).DescribeSnapshots(dsInput)
 	if err != nil {
 		return nil, err
 	}
@@ -1022,29 +1361,29 @@ func (d *driver) getSnapshot(
 	return resp.Snapshots, nil
 }
 
-
 // Converts EC2 API snapshots to libStorage types.Snapshot
 func (d *driver) toTypesSnapshot(
 	ec2snapshots []*awsec2.Snapshot) []*types.Snapshot {
-	var snapshotsInt []*types.Snapshot
+
+	var snapshotsSD []*types.Snapshot
 	for _, snapshot := range ec2snapshots {
-		name := d.getName(snapshot.Tags)
 		snapshotSD := &types.Snapshot{
-			Name:        name,
-			VolumeID:    *snapshot.VolumeId,
-			ID:          *snapshot.SnapshotId,
-			Encrypted:   *snapshot.Encrypted,
-			VolumeSize:  *snapshot.VolumeSize,
-			StartTime:   (*snapshot.StartTime).Unix(),
-			Description: *snapshot.Description,
-			Status:      *snapshot.State,
+			Name:        d.getName(snapshot.Tags),
+			VolumeID:    aws.StringValue(snapshot.VolumeId),
+			ID:          aws.StringValue(snapshot.SnapshotId),
+			Encrypted:   aws.BoolValue(snapshot.Encrypted),
+			VolumeSize:  aws.Int64Value(snapshot.VolumeSize),
+			Description: aws.StringValue(snapshot.Description),
+			Status:      aws.StringValue(snapshot.State),
+		}
+		if snapshot.StartTime != nil {
+			snapshotSD.StartTime = snapshot.StartTime.Unix()
 		}
-		snapshotsInt = append(snapshotsInt, snapshotSD)
+		snapshotsSD = append(snapshotsSD, snapshotSD)
 	}
 
-	return snapshotsInt
+	return snapshotsSD
 }
-*/
 
 var (
 	errNoVolReturned       = goof.New("no volume returned")
@@ -1182,6 +1521,15 @@ func (d *driver) createTags(ctx types.Context, id, name string) (err error) {
 			Value: &inputName,
 		})
 
+	// the metadata with which the server stamps the objects it creates
+	if md, ok := context.VolumeMetadata(ctx); ok {
+		for k, v := range md {
+			ctInput.Tags = append(
+				ctInput.Tags,
+				&awsec2.Tag{Key: aws.String(k), Value: aws.String(v)})
+		}
+	}
+
 	// TODO rexrayTag
 	/*	if d.ec2Tag != "" {
 			initCTInput()
@@ -1248,60 +1596,140 @@ func (d *driver) waitVolumeComplete(
 }
 
 // Wait for snapshot action to complete
-// TODO Snapshots are not implemented yet
-/*
 func (d *driver) waitSnapshotComplete(
 	ctx types.Context, snapshotID string) error {
+
 	if snapshotID == "" {
-		return goof.New("Missing snapshot ID")
+		return goof.New("missing snapshot ID")
 	}
 
 	for {
-		// update snapshot
 		snapshots, err := d.getSnapshot(ctx, "", snapshotID, "")
 		if err != nil {
-			return goof.WithError(
-				"Error getting snapshot", err)
+			return goof.WithError("error getting snapshot", err)
 		}
-
-		// check retrieved snapshot
 		if len(snapshots) == 0 {
-			return goof.New("No snapshots found")
+			return utils.NewSnapshotNotFoundError(snapshotID)
+		}
+		if v := aws.StringValue(snapshots[0].Progress); v != "" {
+			if p, err := strconv.Atoi(strings.TrimSuffix(v, "%")); err == nil {
+				context.SetTaskProgress(ctx, p)
+			}
 		}
-		snapshot := snapshots[0]
-		if *snapshot.State == awsec2.SnapshotStateCompleted {
-			break
+		switch *snapshots[0].State {
+		case awsec2.SnapshotStateCompleted:
+			return nil
+		case awsec2.SnapshotStateError:
+			return goof.WithField(
+				"stateMessage", aws.StringValue(snapshots[0].StateMessage),
+				"snapshot state error")
 		}
-		if *snapshot.State == awsec2.SnapshotStateError {
-			return goof.Newf("Snapshot state error: %s", *snapshot.StateMessage)
+		time.Sleep(5 * time.Second)
+	}
+}
+
+// createSnapshotTags names a snapshot and copies the tags of its volume to
+// it. The volume's name and the tags reserved by AWS and libStorage are not
+// copied.
+func (d *driver) createSnapshotTags(
+	ctx types.Context,
+	snapshotID, snapshotName string,
+	volTags []*awsec2.Tag) error {
+
+	if err := d.createTags(ctx, snapshotID, snapshotName); err != nil {
+		return err
+	}
+
+	var tags []*awsec2.Tag
+	for _, tag := range volTags {
+		key := aws.StringValue(tag.Key)
+		if key == "Name" || key == recycledTagKey ||
+			strings.HasPrefix(key, "aws:") ||
+			strings.HasPrefix(key, metadataTagPrefix) {
+			continue
 		}
-		time.Sleep(1 * time.Second)
+		tags = append(tags, tag)
+	}
+	if len(tags) == 0 {
+		return nil
 	}
 
+	_, err := mustSession(ctx).CreateTags(&awsec2.CreateTagsInput{
+		Resources: []*string{&snapshotID},
+		Tags:      tags,
+	})
+	if err != nil {
+		return goof.WithError("error copying volume tags", err)
+	}
 	return nil
 }
-*/
 
-// Retrieve volume or snapshot name
-func (d *driver) getName(tags []*awsec2.Tag) string {
+// getTag returns the value of the tag with the specified key
+func getTag(tags []*awsec2.Tag, key string) string {
 	for _, tag := range tags {
-		if *tag.Key == "Name" {
+		if *tag.Key == key {
 			return *tag.Value
 		}
 	}
 	return ""
 }
 
+// isRecycled returns a flag indicating whether the volume is tagged as
+// recycled
+func isRecycled(volume *awsec2.Volume) bool {
+	return getTag(volume.Tags, recycledTagKey) != ""
+}
+
+// omitRecycled returns the volumes that are not tagged as recycled
+func omitRecycled(volumes []*awsec2.Volume) []*awsec2.Volume {
+	var vols []*awsec2.Volume
+	for _, v := range volumes {
+		if !isRecycled(v) {
+			vols = append(vols, v)
+		}
+	}
+	return vols
+}
+
+// Retrieve volume or snapshot name
+func (d *driver) getName(tags []*awsec2.Tag) string {
+	return getTag(tags, "Name")
+}
+
+// metadataFields returns the metadata tags with which the server stamped a
+// volume, or nil if the volume has none.
+func metadataFields(tags []*awsec2.Tag) map[string]string {
+	var fields map[string]string
+	for _, tag := range tags {
+		key := aws.StringValue(tag.Key)
+		if !strings.HasPrefix(key, metadataTagPrefix) {
+			continue
+		}
+		if fields == nil {
+			fields = map[string]string{}
+		}
+		fields[key] = aws.StringValue(tag.Value)
+	}
+	return fields
+}
+
 // Retrieve current instance using EC2 API call
 func (d *driver) getInstance(ctx types.Context) (awsec2.Instance, error) {
 	diInput := &awsec2.DescribeInstancesInput{
 		InstanceIds: []*string{mustInstanceIDID(ctx)},
 	}
-	resp, err := mustSession(ctx).DescribeInstances(diInput)
+	svc := mustSession(ctx)
+	v, err := describeResponses.do(
+		describeCacheKey(svc, "DescribeInstances", diInput),
+		d.describeCacheTTL,
+		func() (interface{}, error) {
+			return svc.DescribeInstances(diInput)
+		})
 	if err != nil {
 		return awsec2.Instance{}, goof.WithError(
 			"error retrieving instance with EC2 API call", err)
 	}
+	resp := v.(*awsec2.DescribeInstancesOutput)
 	return *resp.Reservations[0].Instances[0], nil
 }
 
@@ -1393,6 +1821,66 @@ func (d *driver) getKmsKeyID() string {
 	return d.config.GetString(ebs.ConfigEC2KmsKeyID)
 }
 
+func (d *driver) getRoleARN() string {
+	if v := d.config.GetString(ebs.ConfigEBSRoleARN); v != "" {
+		return v
+	}
+	if v := d.config.GetString(ebs.ConfigAWSRoleARN); v != "" {
+		return v
+	}
+	return d.config.GetString(ebs.ConfigEC2RoleARN)
+}
+
+func (d *driver) getRoleExternalID() string {
+	if v := d.config.GetString(ebs.ConfigEBSRoleExternalID); v != "" {
+		return v
+	}
+	if v := d.config.GetString(ebs.ConfigAWSRoleExternalID); v != "" {
+		return v
+	}
+	return d.config.GetString(ebs.ConfigEC2RoleExternalID)
+}
+
+func (d *driver) getRoleSessionName() string {
+	if v := d.config.GetString(ebs.ConfigEBSRoleSessionName); v != "" {
+		return v
+	}
+	if v := d.config.GetString(ebs.ConfigAWSRoleSessionName); v != "" {
+		return v
+	}
+	if v := d.config.GetString(ebs.ConfigEC2RoleSessionName); v != "" {
+		return v
+	}
+	return ebs.DefaultRoleSessionName
+}
+
+func (d *driver) getFastSnapshotRestoreZones() []string {
+	v := d.config.GetString(ebs.ConfigEBSFastSnapshotRestoreZones)
+	if v == "" {
+		v = d.config.GetString(ebs.ConfigAWSFastSnapshotRestoreZones)
+	}
+	if v == "" {
+		v = d.config.GetString(ebs.ConfigEC2FastSnapshotRestoreZones)
+	}
+	if v == "" {
+		return nil
+	}
+	return strings.Split(v, ",")
+}
+
+func (d *driver) getDescribeCacheTTL() string {
+	if v := d.config.GetString(ebs.ConfigEBSDescribeCacheTTL); v != "" {
+		return v
+	}
+	if v := d.config.GetString(ebs.ConfigAWSDescribeCacheTTL); v != "" {
+		return v
+	}
+	if v := d.config.GetString(ebs.ConfigEC2DescribeCacheTTL); v != "" {
+		return v
+	}
+	return ebs.DefaultDescribeCacheTTL
+}
+
 // TODO rexrayTag
 /*func (d *driver) rexrayTag() string {
 	if rexrayTag := d.config.GetString("ebs.rexrayTag"); rexrayTag != "" {
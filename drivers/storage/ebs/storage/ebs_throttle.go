@@ -0,0 +1,123 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_ebs
+
+package storage
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/request"
+
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+const (
+	// throttleMinDelay is the delay before the first retry of a throttled
+	// request.
+	throttleMinDelay = time.Second
+
+	// throttleMaxDelay is the longest delay before retrying a throttled
+	// request.
+	throttleMaxDelay = time.Minute
+)
+
+// throttle is shared by the sessions of the driver so that when EC2
+// throttles one request all of the driver's requests back off, rather than
+// each request exhausting its retries against the same request limit.
+var throttle = &throttleGate{}
+
+type throttleGate struct {
+	sync.Mutex
+	until time.Time
+}
+
+// backoff delays all requests until the delay has elapsed.
+func (g *throttleGate) backoff(delay time.Duration) {
+	g.Lock()
+	defer g.Unlock()
+	if until := time.Now().Add(delay); until.After(g.until) {
+		g.until = until
+	}
+}
+
+// remaining returns how long until the gate opens, or the minimum delay if
+// it is open.
+func (g *throttleGate) remaining() time.Duration {
+	g.Lock()
+	defer g.Unlock()
+	if d := g.until.Sub(time.Now()); d > throttleMinDelay {
+		return d
+	}
+	return throttleMinDelay
+}
+
+// wait is a handler that blocks a request until the gate is open.
+func (g *throttleGate) wait(r *request.Request) {
+	g.Lock()
+	d := g.until.Sub(time.Now())
+	g.Unlock()
+	if d > 0 {
+		time.Sleep(d)
+	}
+}
+
+// throttleRetryer retries throttled requests with a longer, exponential
+// delay than the SDK's default retryer and closes the throttle gate for the
+// length of the delay. Other errors are retried by the default retryer.
+type throttleRetryer struct {
+	client.DefaultRetryer
+}
+
+// RetryRules returns the delay before retrying a request.
+func (r throttleRetryer) RetryRules(req *request.Request) time.Duration {
+	if !req.IsErrorThrottle() {
+		return r.DefaultRetryer.RetryRules(req)
+	}
+	delay := throttleMinDelay << uint(req.RetryCount)
+	if delay <= 0 || delay > throttleMaxDelay {
+		delay = throttleMaxDelay
+	}
+	// jitter keeps the retries of concurrent requests from arriving at EC2
+	// at the same time
+	delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)))
+	throttle.backoff(delay)
+	return delay
+}
+
+// authErrorCodes are the codes of the errors EC2 returns when it rejects the
+// driver's credentials.
+var authErrorCodes = map[string]bool{
+	"AuthFailure":           true,
+	"ExpiredToken":          true,
+	"InvalidClientTokenId":  true,
+	"RequestExpired":        true,
+	"SignatureDoesNotMatch": true,
+}
+
+// classifyBackendError is a handler that replaces the error of a request
+// that will not be retried with an ErrThrottled error if EC2 throttled the
+// request, so that the client may retry it once the throttle gate opens, or
+// with an ErrBackend error if EC2 rejected the driver's credentials, failed
+// with a server error, or could not be reached. Both are counted by the
+// server's circuit breaker. Errors caused by the request are left as they
+// are.
+func classifyBackendError(r *request.Request) {
+	if r.Error == nil {
+		return
+	}
+	if r.IsErrorThrottle() {
+		r.Error = utils.NewThrottledError(r.Error, throttle.remaining())
+		return
+	}
+	if r.IsErrorRetryable() ||
+		(r.HTTPResponse != nil && r.HTTPResponse.StatusCode >= 500) {
+		r.Error = utils.NewBackendError(r.Error)
+		return
+	}
+	if awsErr, ok := r.Error.(awserr.Error); ok && authErrorCodes[awsErr.Code()] {
+		r.Error = utils.NewBackendError(r.Error)
+	}
+}
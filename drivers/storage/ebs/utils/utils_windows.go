@@ -0,0 +1,18 @@
+// +build windows
+// +build !libstorage_storage_driver libstorage_storage_driver_ebs
+
+package utils
+
+import (
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// NextDeviceInfo is the NextDeviceInfo object for EBS.
+//
+// On Windows EC2 instances the device name is only used to attach a volume;
+// the executor maps the name to the local disk by the disk's SCSI target ID.
+var NextDeviceInfo = &types.NextDeviceInfo{
+	Prefix:  "xvd",
+	Pattern: "[f-p]",
+	Ignore:  false,
+}
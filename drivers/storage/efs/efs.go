@@ -55,6 +55,73 @@ const (
 
 	// DisableSessionCache is a key constant.
 	DisableSessionCache = "disableSessionCache"
+
+	// TLS is a key constant.
+	TLS = "tls"
+
+	// MountHelperOptions is a key constant.
+	MountHelperOptions = "mountHelperOptions"
+
+	// FileSystemID is the ID of a file system on which volumes are created
+	// as access points rather than as file systems of their own.
+	FileSystemID = "fileSystemID"
+
+	// AccessPointUID is a key constant.
+	AccessPointUID = "accessPoint.uid"
+
+	// AccessPointGID is a key constant.
+	AccessPointGID = "accessPoint.gid"
+
+	// AccessPointRootDir is a key constant.
+	AccessPointRootDir = "accessPoint.rootDir"
+
+	// AccessPointPermissions is a key constant.
+	AccessPointPermissions = "accessPoint.permissions"
+
+	// DefaultAccessPointRootDir is the format of the directory exposed by a
+	// volume's access point. The format's only argument is the name of the
+	// volume.
+	DefaultAccessPointRootDir = "/%s"
+
+	// DefaultAccessPointPermissions is the mode with which the directory of
+	// a volume's access point is created.
+	DefaultAccessPointPermissions = "0755"
+
+	// VolumeOptUID is the name of the volume create option that overrides
+	// the user ID of the volume's access point.
+	VolumeOptUID = "uid"
+
+	// VolumeOptGID is the name of the volume create option that overrides
+	// the group ID of the volume's access point.
+	VolumeOptGID = "gid"
+
+	// VolumeOptRootDir is the name of the volume create option that
+	// overrides the directory exposed by the volume's access point.
+	VolumeOptRootDir = "rootDir"
+
+	// VolumeOptPermissions is the name of the volume create option that
+	// overrides the mode of the directory of the volume's access point.
+	VolumeOptPermissions = "permissions"
+
+	// VolumeFieldFileSystemID is the name of the field of an access point's
+	// volume that records the ID of its file system.
+	VolumeFieldFileSystemID = "fileSystemID"
+
+	// VolumeFieldRootDir is the name of the field of an access point's
+	// volume that records the directory it exposes.
+	VolumeFieldRootDir = "rootDir"
+
+	// VolumeFieldUID is the name of the field of an access point's volume
+	// that records the user ID of its clients.
+	VolumeFieldUID = "uid"
+
+	// VolumeFieldGID is the name of the field of an access point's volume
+	// that records the group ID of its clients.
+	VolumeFieldGID = "gid"
+
+	// FsType is the type of file system with which access points are
+	// mounted by the EFS mount helper from amazon-efs-utils.
+	FsType = "efs"
 )
 
 const (
@@ -87,6 +154,27 @@ const (
 
 	// ConfigEFSDisableSessionCache is a config key.
 	ConfigEFSDisableSessionCache = ConfigEFS + "." + DisableSessionCache
+
+	// ConfigEFSTLS is a config key.
+	ConfigEFSTLS = ConfigEFS + "." + TLS
+
+	// ConfigEFSMountHelperOptions is a config key.
+	ConfigEFSMountHelperOptions = ConfigEFS + "." + MountHelperOptions
+
+	// ConfigEFSFileSystemID is a config key.
+	ConfigEFSFileSystemID = ConfigEFS + "." + FileSystemID
+
+	// ConfigEFSAccessPointUID is a config key.
+	ConfigEFSAccessPointUID = ConfigEFS + "." + AccessPointUID
+
+	// ConfigEFSAccessPointGID is a config key.
+	ConfigEFSAccessPointGID = ConfigEFS + "." + AccessPointGID
+
+	// ConfigEFSAccessPointRootDir is a config key.
+	ConfigEFSAccessPointRootDir = ConfigEFS + "." + AccessPointRootDir
+
+	// ConfigEFSAccessPointPermissions is a config key.
+	ConfigEFSAccessPointPermissions = ConfigEFS + "." + AccessPointPermissions
 )
 
 func init() {
@@ -101,5 +189,17 @@ func init() {
 	r.Key(gofig.String, "", "", "Tag prefix for EFS naming", ConfigEFSTag)
 	r.Key(gofig.Bool, "", false,
 		"A flag that disables the session cache", ConfigEFSDisableSessionCache)
+	r.Key(gofig.Bool, "", false,
+		"Mount with the EFS mount helper and TLS", ConfigEFSTLS)
+	r.Key(gofig.String, "", "",
+		"Additional EFS mount helper options", ConfigEFSMountHelperOptions)
+	r.Key(gofig.String, "", "",
+		"File system on which volumes are access points", ConfigEFSFileSystemID)
+	r.Key(gofig.Int, "", 0, "Access point user ID", ConfigEFSAccessPointUID)
+	r.Key(gofig.Int, "", 0, "Access point group ID", ConfigEFSAccessPointGID)
+	r.Key(gofig.String, "", DefaultAccessPointRootDir,
+		"Access point directory format", ConfigEFSAccessPointRootDir)
+	r.Key(gofig.String, "", DefaultAccessPointPermissions,
+		"Access point directory mode", ConfigEFSAccessPointPermissions)
 	gofigCore.Register(r)
 }
@@ -0,0 +1,402 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_efs
+
+package storage
+
+import (
+	"crypto/md5"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/akutz/goof"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	awsefs "github.com/aws/aws-sdk-go/service/efs"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+	"github.com/codedellemc/libstorage/drivers/storage/efs"
+)
+
+// The vendored SDK predates EFS access points, so the access point
+// operations are sent with the input and output types below, which mirror
+// the types of the EFS API.
+const (
+	opCreateAccessPoint    = "CreateAccessPoint"
+	opDescribeAccessPoints = "DescribeAccessPoints"
+	opDeleteAccessPoint    = "DeleteAccessPoint"
+
+	accessPointsPath = "/2015-02-01/access-points"
+
+	// accessPointIDPrefix prefixes the IDs of access points, which tells
+	// the IDs of access point volumes from the IDs of file system volumes.
+	accessPointIDPrefix = "fsap-"
+
+	accessPointLifeCycleStateAvailable = "available"
+)
+
+type posixUser struct {
+	_ struct{} `type:"structure"`
+
+	Gid *int64 `type:"long" required:"true"`
+
+	Uid *int64 `type:"long" required:"true"`
+}
+
+type creationInfo struct {
+	_ struct{} `type:"structure"`
+
+	OwnerGid *int64 `type:"long" required:"true"`
+
+	OwnerUid *int64 `type:"long" required:"true"`
+
+	Permissions *string `type:"string" required:"true"`
+}
+
+type rootDirectory struct {
+	_ struct{} `type:"structure"`
+
+	CreationInfo *creationInfo `type:"structure"`
+
+	Path *string `min:"1" type:"string"`
+}
+
+type accessPointDescription struct {
+	_ struct{} `type:"structure"`
+
+	AccessPointId *string `type:"string"`
+
+	FileSystemId *string `type:"string"`
+
+	LifeCycleState *string `type:"string"`
+
+	Name *string `type:"string"`
+
+	PosixUser *posixUser `type:"structure"`
+
+	RootDirectory *rootDirectory `type:"structure"`
+
+	Tags []*awsefs.Tag `type:"list"`
+}
+
+type createAccessPointInput struct {
+	_ struct{} `type:"structure"`
+
+	ClientToken *string `min:"1" type:"string" required:"true"`
+
+	FileSystemId *string `type:"string" required:"true"`
+
+	PosixUser *posixUser `type:"structure"`
+
+	RootDirectory *rootDirectory `type:"structure"`
+
+	Tags []*awsefs.Tag `type:"list"`
+}
+
+type describeAccessPointsInput struct {
+	_ struct{} `type:"structure"`
+
+	AccessPointId *string `location:"querystring" locationName:"AccessPointId" type:"string"`
+
+	FileSystemId *string `location:"querystring" locationName:"FileSystemId" type:"string"`
+
+	NextToken *string `location:"querystring" locationName:"NextToken" type:"string"`
+}
+
+type describeAccessPointsOutput struct {
+	_ struct{} `type:"structure"`
+
+	AccessPoints []*accessPointDescription `type:"list"`
+
+	NextToken *string `type:"string"`
+}
+
+type deleteAccessPointInput struct {
+	_ struct{} `type:"structure"`
+
+	AccessPointId *string `location:"uri" locationName:"AccessPointId" type:"string" required:"true"`
+}
+
+type deleteAccessPointOutput struct {
+	_ struct{} `type:"structure"`
+}
+
+func createAccessPoint(
+	svc *awsefs.EFS,
+	input *createAccessPointInput) (*accessPointDescription, error) {
+
+	output := &accessPointDescription{}
+	req := svc.NewRequest(
+		&request.Operation{
+			Name:       opCreateAccessPoint,
+			HTTPMethod: "POST",
+			HTTPPath:   accessPointsPath,
+		},
+		input,
+		output)
+	return output, req.Send()
+}
+
+// describeAccessPoints returns the access point with the ID, or if the ID
+// is empty, all of the access points of the file system.
+func describeAccessPoints(
+	svc *awsefs.EFS,
+	accessPointID, fileSystemID string) ([]*accessPointDescription, error) {
+
+	input := &describeAccessPointsInput{}
+	if accessPointID != "" {
+		input.AccessPointId = &accessPointID
+	} else {
+		input.FileSystemId = &fileSystemID
+	}
+
+	var accessPoints []*accessPointDescription
+	for {
+		output := &describeAccessPointsOutput{}
+		req := svc.NewRequest(
+			&request.Operation{
+				Name:       opDescribeAccessPoints,
+				HTTPMethod: "GET",
+				HTTPPath:   accessPointsPath,
+			},
+			input,
+			output)
+		if err := req.Send(); err != nil {
+			return nil, err
+		}
+		accessPoints = append(accessPoints, output.AccessPoints...)
+		if output.NextToken == nil || *output.NextToken == "" {
+			return accessPoints, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
+
+func deleteAccessPoint(svc *awsefs.EFS, accessPointID string) error {
+	req := svc.NewRequest(
+		&request.Operation{
+			Name:       opDeleteAccessPoint,
+			HTTPMethod: "DELETE",
+			HTTPPath:   accessPointsPath + "/{AccessPointId}",
+		},
+		&deleteAccessPointInput{AccessPointId: &accessPointID},
+		&deleteAccessPointOutput{})
+	return req.Send()
+}
+
+// isAccessPointID returns a flag indicating whether or not a volume ID is the
+// ID of an access point.
+func isAccessPointID(volumeID string) bool {
+	return strings.HasPrefix(volumeID, accessPointIDPrefix)
+}
+
+// accessPointVolumes returns the volumes of the access points of the
+// driver's file system.
+func (d *driver) accessPointVolumes(
+	ctx types.Context,
+	opts *types.VolumesOpts) ([]*types.Volume, error) {
+
+	accessPoints, err := describeAccessPoints(
+		mustSession(ctx), "", d.fileSystemID)
+	if err != nil {
+		return nil, err
+	}
+
+	var volumesSD []*types.Volume
+	for _, ap := range accessPoints {
+		// Only volumes with partition prefix
+		if ap.Name == nil ||
+			!strings.HasPrefix(*ap.Name, d.tag+tagDelimiter) {
+			continue
+		}
+		if aws.StringValue(ap.LifeCycleState) !=
+			accessPointLifeCycleStateAvailable {
+			continue
+		}
+		volumeSD, err := d.toTypesVolumeFromAccessPoint(
+			ctx, ap, opts.Attachments)
+		if err != nil {
+			return nil, err
+		}
+		volumesSD = append(volumesSD, volumeSD)
+	}
+	return volumesSD, nil
+}
+
+// accessPointInspect returns the volume of an access point.
+func (d *driver) accessPointInspect(
+	ctx types.Context,
+	accessPointID string,
+	opts *types.VolumeInspectOpts) (*types.Volume, error) {
+
+	accessPoints, err := describeAccessPoints(
+		mustSession(ctx), accessPointID, "")
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok &&
+			awsErr.Code() == "AccessPointNotFound" {
+			return nil, utils.NewVolumeNotFoundError(accessPointID)
+		}
+		return nil, err
+	}
+	if len(accessPoints) == 0 {
+		return nil, utils.NewVolumeNotFoundError(accessPointID)
+	}
+	return d.toTypesVolumeFromAccessPoint(
+		ctx, accessPoints[0], opts.Attachments)
+}
+
+// accessPointCreate creates a volume as an access point of the driver's
+// file system. The access point exposes a directory of its own, which EFS
+// creates with the configured owner and mode, and the clients of the access
+// point act as the configured user and group.
+func (d *driver) accessPointCreate(
+	ctx types.Context,
+	name string,
+	opts *types.VolumeCreateOpts) (*types.Volume, error) {
+
+	var (
+		svc      = mustSession(ctx)
+		fullName = d.getFullVolumeName(name)
+		uid      = d.getAccessPointUID()
+		gid      = d.getAccessPointGID()
+		rootDir  = d.accessPointRootDir(name)
+		perms    = d.getAccessPointPermissions()
+	)
+
+	if opts.Opts != nil {
+		if opts.Opts.IsSet(efs.VolumeOptUID) {
+			uid = opts.Opts.GetInt64(efs.VolumeOptUID)
+		}
+		if opts.Opts.IsSet(efs.VolumeOptGID) {
+			gid = opts.Opts.GetInt64(efs.VolumeOptGID)
+		}
+		if v := opts.Opts.GetString(efs.VolumeOptRootDir); v != "" {
+			rootDir = v
+		}
+		if v := opts.Opts.GetString(efs.VolumeOptPermissions); v != "" {
+			perms = v
+		}
+	}
+
+	fields := log.Fields{
+		"fileSystemID": d.fileSystemID,
+		"volumeName":   name,
+		"rootDir":      rootDir,
+		"uid":          uid,
+		"gid":          gid,
+		"permissions":  perms,
+	}
+
+	// the token is limited to 64 characters and makes retried requests
+	// return the same access point
+	ap, err := createAccessPoint(svc, &createAccessPointInput{
+		ClientToken:  aws.String(fmt.Sprintf("%x", md5.Sum([]byte(fullName)))),
+		FileSystemId: aws.String(d.fileSystemID),
+		PosixUser: &posixUser{
+			Uid: aws.Int64(uid),
+			Gid: aws.Int64(gid),
+		},
+		RootDirectory: &rootDirectory{
+			Path: aws.String(rootDir),
+			CreationInfo: &creationInfo{
+				OwnerUid:    aws.Int64(uid),
+				OwnerGid:    aws.Int64(gid),
+				Permissions: aws.String(perms),
+			},
+		},
+		Tags: []*awsefs.Tag{
+			{
+				Key:   aws.String("Name"),
+				Value: aws.String(fullName),
+			},
+		},
+	})
+	if err != nil {
+		return nil, goof.WithFieldsE(
+			fields, "error creating access point", err)
+	}
+	fields["accessPointID"] = *ap.AccessPointId
+	ctx.WithFields(fields).Info("created access point")
+
+	// Wait until the access point is in "available" state
+	for aws.StringValue(ap.LifeCycleState) !=
+		accessPointLifeCycleStateAvailable {
+
+		<-time.After(2 * time.Second)
+		aps, err := describeAccessPoints(svc, *ap.AccessPointId, "")
+		if err != nil {
+			return nil, goof.WithFieldsE(
+				fields, "error describing access point", err)
+		}
+		if len(aps) == 0 {
+			return nil, goof.WithFields(fields, "access point disappeared")
+		}
+		ap = aps[0]
+		if aws.StringValue(ap.LifeCycleState) == "error" {
+			return nil, goof.WithFields(
+				fields, "access point creation failed")
+		}
+	}
+
+	return d.toTypesVolumeFromAccessPoint(ctx, ap, 0)
+}
+
+// toTypesVolumeFromAccessPoint returns the volume of an access point. The
+// attachments of the volume are those of the access point's file system,
+// and they are mounted by the EFS mount helper through the access point.
+func (d *driver) toTypesVolumeFromAccessPoint(
+	ctx types.Context,
+	ap *accessPointDescription,
+	attachments types.VolumeAttachmentsTypes) (*types.Volume, error) {
+
+	var (
+		apID = aws.StringValue(ap.AccessPointId)
+		fsID = aws.StringValue(ap.FileSystemId)
+	)
+
+	volume := &types.Volume{
+		Name: d.getPrintableName(aws.StringValue(ap.Name)),
+		ID:   apID,
+		Fields: map[string]string{
+			efs.VolumeFieldFileSystemID: fsID,
+		},
+	}
+	if ap.RootDirectory != nil && ap.RootDirectory.Path != nil {
+		volume.Fields[efs.VolumeFieldRootDir] = *ap.RootDirectory.Path
+	}
+	if ap.PosixUser != nil {
+		volume.Fields[efs.VolumeFieldUID] = strconv.FormatInt(
+			aws.Int64Value(ap.PosixUser.Uid), 10)
+		volume.Fields[efs.VolumeFieldGID] = strconv.FormatInt(
+			aws.Int64Value(ap.PosixUser.Gid), 10)
+	}
+
+	atts, err := d.getVolumeAttachments(ctx, fsID, attachments)
+	if err != nil {
+		return nil, err
+	}
+	for _, att := range atts {
+		att.VolumeID = apID
+		att.DeviceName = fsID + ":/"
+		att.Fields = d.helperMountFields(apID)
+	}
+	if len(atts) > 0 {
+		volume.Attachments = atts
+	}
+	return volume, nil
+}
+
+// accessPointRootDir returns the directory exposed by the access point of
+// the volume with the name.
+func (d *driver) accessPointRootDir(name string) string {
+	format := d.getAccessPointRootDir()
+	if strings.Contains(format, "%s") {
+		return fmt.Sprintf(format, name)
+	}
+	return path.Join(format, name)
+}
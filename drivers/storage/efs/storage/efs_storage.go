@@ -25,6 +25,7 @@ import (
 	"github.com/codedellemc/libstorage/api/context"
 	"github.com/codedellemc/libstorage/api/registry"
 	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
 	"github.com/codedellemc/libstorage/drivers/storage/efs"
 )
 
@@ -43,6 +44,9 @@ type driver struct {
 	accessKey           string
 	secGroups           []string
 	disableSessionCache bool
+	fileSystemID        string
+	tls                 bool
+	mountHelperOptions  string
 }
 
 func init() {
@@ -76,6 +80,15 @@ func (d *driver) Init(ctx types.Context, config gofig.Config) error {
 	d.disableSessionCache = d.getDisableSessionCache()
 	fields["disableSessionCache"] = d.disableSessionCache
 
+	d.fileSystemID = d.getFileSystemID()
+	fields["fileSystemID"] = d.fileSystemID
+
+	d.tls = d.getTLS()
+	fields["tls"] = d.tls
+
+	d.mountHelperOptions = d.getMountHelperOptions()
+	fields["mountHelperOptions"] = d.mountHelperOptions
+
 	if v := d.getRegion(); v != "" {
 		d.region = &v
 		fields["region"] = v
@@ -263,6 +276,7 @@ func (d *driver) InstanceInspect(
 	return &types.Instance{
 		Name:         iid.ID,
 		Region:       iid.Fields[efs.InstanceIDFieldRegion],
+		Zone:         iid.Fields[efs.InstanceIDFieldAvailabilityZone],
 		InstanceID:   iid,
 		ProviderName: iid.Driver,
 	}, nil
@@ -273,6 +287,16 @@ func (d *driver) Type(ctx types.Context) (types.StorageType, error) {
 	return types.NAS, nil
 }
 
+func (d *driver) HealthCheck(ctx types.Context) error {
+	// describing a file system verifies that the endpoint is reachable and
+	// that the credentials are valid
+	if _, err := mustSession(ctx).DescribeFileSystems(
+		&awsefs.DescribeFileSystemsInput{MaxItems: aws.Int64(1)}); err != nil {
+		return goof.WithError("error describing file systems", err)
+	}
+	return nil
+}
+
 // NextDeviceInfo returns the information about the driver's next available
 // device workflow.
 func (d *driver) NextDeviceInfo(
@@ -280,11 +304,26 @@ func (d *driver) NextDeviceInfo(
 	return nil, nil
 }
 
+// Capabilities returns the capabilities of the driver's storage platform.
+func (d *driver) Capabilities(
+	ctx types.Context) (*types.StorageCapabilities, error) {
+
+	return &types.StorageCapabilities{
+		MultiAttach: true,
+		Topology:    []string{"region"},
+	}, nil
+}
+
 // Volumes returns all volumes or a filtered list of volumes.
 func (d *driver) Volumes(
 	ctx types.Context,
 	opts *types.VolumesOpts) ([]*types.Volume, error) {
 
+	// the volumes of a service with a file system are its access points
+	if d.fileSystemID != "" {
+		return d.accessPointVolumes(ctx, opts)
+	}
+
 	svc := mustSession(ctx)
 
 	fileSystems, err := d.getAllFileSystems(svc)
@@ -342,6 +381,10 @@ func (d *driver) VolumeInspect(
 	volumeID string,
 	opts *types.VolumeInspectOpts) (*types.Volume, error) {
 
+	if isAccessPointID(volumeID) {
+		return d.accessPointInspect(ctx, volumeID, opts)
+	}
+
 	resp, err := mustSession(ctx).DescribeFileSystems(
 		&awsefs.DescribeFileSystemsInput{FileSystemId: aws.String(volumeID)})
 	if err != nil {
@@ -349,7 +392,7 @@ func (d *driver) VolumeInspect(
 	}
 
 	if len(resp.FileSystems) == 0 {
-		return nil, types.ErrNotFound{}
+		return nil, utils.NewVolumeNotFoundError(volumeID)
 	}
 
 	fileSystem := resp.FileSystems[0]
@@ -397,6 +440,10 @@ func (d *driver) VolumeCreate(
 	name string,
 	opts *types.VolumeCreateOpts) (*types.Volume, error) {
 
+	if d.fileSystemID != "" {
+		return d.accessPointCreate(ctx, name, opts)
+	}
+
 	// Token is limited to 64 ASCII characters so just create MD5 hash from full
 	// tag/name identifier
 	creationToken := fmt.Sprintf("%x", md5.Sum([]byte(d.getFullVolumeName(name))))
@@ -475,6 +522,19 @@ func (d *driver) VolumeRemove(
 
 	svc := mustSession(ctx)
 
+	// removing an access point leaves its directory and the file system's
+	// mount targets in place for the file system's other access points
+	if isAccessPointID(volumeID) {
+		if err := deleteAccessPoint(svc, volumeID); err != nil {
+			if awsErr, ok := err.(awserr.Error); ok &&
+				awsErr.Code() == "AccessPointNotFound" {
+				return utils.NewVolumeNotFoundError(volumeID)
+			}
+			return err
+		}
+		return nil
+	}
+
 	// Remove MountTarget(s)
 	resp, err := svc.DescribeMountTargets(
 		&awsefs.DescribeMountTargetsInput{
@@ -597,8 +657,15 @@ func (d *driver) VolumeAttach(
 			return nil, "", errInvalidSecGroups
 		}
 
+		// the mount targets of an access point's volume are those of its
+		// file system
+		fileSystemID := vol.ID
+		if v := vol.Fields[efs.VolumeFieldFileSystemID]; v != "" {
+			fileSystemID = v
+		}
+
 		request := &awsefs.CreateMountTargetInput{
-			FileSystemId:   aws.String(vol.ID),
+			FileSystemId:   aws.String(fileSystemID),
 			SubnetId:       aws.String(iid.ID),
 			SecurityGroups: aws.StringSlice(secGrpIDs),
 		}
@@ -788,12 +855,35 @@ func (d *driver) getVolumeAttachments(
 			DeviceName: dev,
 			Status:     status,
 		}
+		// a file system mounted with TLS is mounted by the EFS mount helper,
+		// which connects to the mount target through a local TLS tunnel
+		if d.tls && dev != "" {
+			attachmentSD.DeviceName = *mountTarget.FileSystemId + ":/"
+			attachmentSD.Fields = d.helperMountFields("")
+		}
 		atts = append(atts, attachmentSD)
 	}
 
 	return atts, nil
 }
 
+// helperMountFields returns the attachment fields that have a volume mounted
+// by the EFS mount helper. Access points require TLS, so it is used for them
+// regardless of the driver's config.
+func (d *driver) helperMountFields(accessPointID string) map[string]string {
+	options := []string{efs.TLS}
+	if accessPointID != "" {
+		options = append(options, "accesspoint="+accessPointID)
+	}
+	if d.mountHelperOptions != "" {
+		options = append(options, d.mountHelperOptions)
+	}
+	return map[string]string{
+		types.AttachmentFieldFsType:       efs.FsType,
+		types.AttachmentFieldMountOptions: strings.Join(options, ","),
+	}
+}
+
 // Retrieve config arguments
 func (d *driver) getAccessKey() string {
 	return d.config.GetString(efs.ConfigEFSAccessKey)
@@ -830,3 +920,37 @@ func (d *driver) getSecurityGroups() []string {
 func (d *driver) getDisableSessionCache() bool {
 	return d.config.GetBool(efs.ConfigEFSDisableSessionCache)
 }
+
+func (d *driver) getTLS() bool {
+	return d.config.GetBool(efs.ConfigEFSTLS)
+}
+
+func (d *driver) getMountHelperOptions() string {
+	return d.config.GetString(efs.ConfigEFSMountHelperOptions)
+}
+
+func (d *driver) getFileSystemID() string {
+	return d.config.GetString(efs.ConfigEFSFileSystemID)
+}
+
+func (d *driver) getAccessPointUID() int64 {
+	return int64(d.config.GetInt(efs.ConfigEFSAccessPointUID))
+}
+
+func (d *driver) getAccessPointGID() int64 {
+	return int64(d.config.GetInt(efs.ConfigEFSAccessPointGID))
+}
+
+func (d *driver) getAccessPointRootDir() string {
+	if v := d.config.GetString(efs.ConfigEFSAccessPointRootDir); v != "" {
+		return v
+	}
+	return efs.DefaultAccessPointRootDir
+}
+
+func (d *driver) getAccessPointPermissions() string {
+	if v := d.config.GetString(efs.ConfigEFSAccessPointPermissions); v != "" {
+		return v
+	}
+	return efs.DefaultAccessPointPermissions
+}
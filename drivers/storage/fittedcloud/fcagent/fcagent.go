@@ -4,11 +4,12 @@ package fcagent
 
 import (
 	"errors"
-	"os/exec"
 	"strconv"
 	"strings"
 
 	log "github.com/Sirupsen/logrus"
+
+	"github.com/codedellemc/libstorage/api/utils"
 )
 
 const (
@@ -26,12 +27,12 @@ func IsRunning() (string, error) {
 	cmd := "fcagent"
 	args := []string{"echo"}
 
-	if path, err = exec.LookPath(cmd); err != nil {
+	if path, err = utils.LookPath(cmd); err != nil {
 		log.Debug(err)
 	}
 	log.Debug(path, args[0])
 
-	if cmdOut, err = exec.Command(cmd, args...).Output(); err != nil {
+	if cmdOut, err = utils.Command(cmd, args...).Output(); err != nil {
 		log.Debug(err)
 	}
 	return string(cmdOut), err
@@ -74,7 +75,7 @@ func CreateVol(tagName string, sizeGb int, encryption bool, kmsKeyID string) (st
 
 	log.Debug(cmd, args)
 
-	if cmdOut, err = exec.Command(cmd, args...).Output(); err != nil {
+	if cmdOut, err = utils.Command(cmd, args...).Output(); err != nil {
 		log.Debug(err)
 		return string(cmdOut), err
 	}
@@ -102,7 +103,7 @@ func DelVol(fcVolName string) (string, error) {
 
 	log.Debug(cmd, args)
 
-	if cmdOut, err = exec.Command(cmd, args...).Output(); err != nil {
+	if cmdOut, err = utils.Command(cmd, args...).Output(); err != nil {
 		log.Debug(err)
 	}
 
@@ -122,7 +123,7 @@ func getFcVolName(devName string) (string, error) {
 
 	log.Debug(cmd, args)
 
-	if cmdOut, err = exec.Command(cmd, args...).Output(); err != nil {
+	if cmdOut, err = utils.Command(cmd, args...).Output(); err != nil {
 		log.Debug(err)
 	}
 
@@ -223,6 +223,18 @@ func (d *driver) NextDeviceInfo(
 	return fcUtils.NextDeviceInfo, nil
 }
 
+// Capabilities returns the capabilities of the driver's storage platform.
+func (d *driver) Capabilities(
+	ctx types.Context) (*types.StorageCapabilities, error) {
+
+	return &types.StorageCapabilities{
+		MaxVolumeSize: 16384,
+		Topology:      []string{"zone"},
+		// the agent thin-provisions volumes on EBS
+		Provisioning: []string{types.ProvisioningThin},
+	}, nil
+}
+
 // Type returns the type of storage the driver provides.
 func (d *driver) Type(ctx types.Context) (types.StorageType, error) {
 	//Example: Block storage
@@ -238,6 +250,7 @@ func (d *driver) InstanceInspect(
 	return &types.Instance{
 		Name:         iid.ID,
 		Region:       iid.Fields[fittedcloud.InstanceIDFieldRegion],
+		Zone:         iid.Fields[fittedcloud.InstanceIDFieldAvailabilityZone],
 		InstanceID:   iid,
 		ProviderName: iid.Driver,
 	}, nil
@@ -27,6 +27,35 @@ const (
 
 	// DefaultDiskType indicates what type of disk to create by default
 	DefaultDiskType = DiskTypeSSD
+
+	// KmsKeyNamePrefix is the prefix of the resource name of a Cloud KMS
+	// key. A volume's encryption key with the prefix is a Cloud KMS key,
+	// otherwise it is a customer-supplied encryption key.
+	KmsKeyNamePrefix = "projects/"
+
+	// VolumeOptKmsKeyName is the name of the volume create option that
+	// specifies the resource name of the Cloud KMS key that protects the
+	// disk.
+	VolumeOptKmsKeyName = "kmsKeyName"
+
+	// VolumeOptEncryptionKey is the name of the volume create and attach
+	// option that specifies a base64 encoded, 256-bit customer-supplied
+	// encryption key.
+	VolumeOptEncryptionKey = "encryptionKey"
+
+	// VolumeOptRsaEncryptedKey is the name of the volume create and attach
+	// option that specifies a base64 encoded customer-supplied encryption
+	// key wrapped with Google's public RSA certificate.
+	VolumeOptRsaEncryptedKey = "rsaEncryptedKey"
+
+	// VolumeFieldKmsKeyName is the name of the volume field that contains
+	// the resource name of the Cloud KMS key that protects the disk.
+	VolumeFieldKmsKeyName = "kmsKeyName"
+
+	// VolumeFieldEncryptionKeySha256 is the name of the volume field that
+	// contains the SHA-256 hash of the customer-supplied encryption key
+	// that protects the disk.
+	VolumeFieldEncryptionKeySha256 = "encryptionKeySha256"
 )
 
 func init() {
@@ -40,6 +69,15 @@ func init() {
 		"gcepd.defaultDiskType")
 	r.Key(gofig.String, "", "", "Tag to apply and filter disks",
 		"gcepd.tag")
+	r.Key(gofig.String, "", "",
+		"Resource name of the Cloud KMS key that protects new disks",
+		"gcepd.kmsKeyName")
+	r.Key(gofig.String, "", "",
+		"Base64 encoded customer-supplied encryption key for disks",
+		"gcepd.encryptionKey")
+	r.Key(gofig.String, "", "",
+		"Base64 encoded RSA-wrapped customer-supplied encryption key",
+		"gcepd.rsaEncryptedKey")
 
 	gofigCore.Register(r)
 }
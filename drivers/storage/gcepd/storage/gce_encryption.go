@@ -0,0 +1,172 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_gcepd
+
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+
+	goof "github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/drivers/storage/gcepd"
+
+	compute "google.golang.org/api/compute/v0.beta"
+)
+
+// createEncryptionKey returns the key with which to protect a new disk, or
+// nil if the disk is protected by a Google-managed key. The key is a Cloud
+// KMS key or a customer-supplied encryption key (CSEK). The volume create
+// options take precedence over the volume's encryption key, which takes
+// precedence over the driver's configured keys.
+func (d *driver) createEncryptionKey(
+	opts *types.VolumeCreateOpts) (*compute.CustomerEncryptionKey, error) {
+
+	var kmsKeyName, rawKey, rsaKey string
+	if opts.Opts != nil {
+		kmsKeyName = opts.Opts.GetString(gcepd.VolumeOptKmsKeyName)
+		rawKey = opts.Opts.GetString(gcepd.VolumeOptEncryptionKey)
+		rsaKey = opts.Opts.GetString(gcepd.VolumeOptRsaEncryptedKey)
+	}
+	if kmsKeyName == "" && rawKey == "" && rsaKey == "" &&
+		opts.EncryptionKey != nil && *opts.EncryptionKey != "" {
+		if strings.HasPrefix(*opts.EncryptionKey, gcepd.KmsKeyNamePrefix) {
+			kmsKeyName = *opts.EncryptionKey
+		} else {
+			rawKey = *opts.EncryptionKey
+		}
+	}
+	if kmsKeyName == "" && rawKey == "" && rsaKey == "" {
+		kmsKeyName = d.kmsKeyName()
+		rawKey = d.encryptionKey()
+		rsaKey = d.rsaEncryptedKey()
+	}
+
+	n := 0
+	for _, v := range []string{kmsKeyName, rawKey, rsaKey} {
+		if v != "" {
+			n++
+		}
+	}
+	switch n {
+	case 0:
+		if opts.Encrypted != nil && *opts.Encrypted {
+			return nil, goof.New(
+				"encrypted volume requires a kms or encryption key")
+		}
+		return nil, nil
+	case 1:
+		// noop
+	default:
+		return nil, goof.New(
+			"only one of a kms key, encryption key, or rsa encrypted key " +
+				"may protect a volume")
+	}
+
+	if rawKey != "" {
+		if _, err := decodeRawKey(rawKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return &compute.CustomerEncryptionKey{
+		KmsKeyName:      kmsKeyName,
+		RawKey:          rawKey,
+		RsaEncryptedKey: rsaKey,
+	}, nil
+}
+
+// attachEncryptionKey returns the customer-supplied encryption key that
+// must accompany a request to attach the disk, or nil if the disk is not
+// protected by a customer-supplied encryption key. Disks protected by a
+// Cloud KMS key are attached without a key. The volume attach options take
+// precedence over the driver's configured keys.
+func (d *driver) attachEncryptionKey(
+	disk *compute.Disk,
+	opts types.Store) (*compute.CustomerEncryptionKey, error) {
+
+	if disk.DiskEncryptionKey == nil ||
+		disk.DiskEncryptionKey.KmsKeyName != "" {
+		return nil, nil
+	}
+
+	var rawKey, rsaKey string
+	if opts != nil {
+		rawKey = opts.GetString(gcepd.VolumeOptEncryptionKey)
+		rsaKey = opts.GetString(gcepd.VolumeOptRsaEncryptedKey)
+	}
+	if rawKey == "" && rsaKey == "" {
+		rawKey = d.encryptionKey()
+		rsaKey = d.rsaEncryptedKey()
+	}
+
+	switch {
+	case rawKey != "":
+		key, err := decodeRawKey(rawKey)
+		if err != nil {
+			return nil, err
+		}
+		// the disk reports the hash of its key, so a key that cannot
+		// unlock the disk is rejected before the attach is requested
+		if sha := disk.DiskEncryptionKey.Sha256; sha != "" {
+			sum := sha256.Sum256(key)
+			if base64.StdEncoding.EncodeToString(sum[:]) != sha {
+				return nil, goof.WithField(
+					"volumeID", disk.Name,
+					"encryption key does not match volume")
+			}
+		}
+		return &compute.CustomerEncryptionKey{RawKey: rawKey}, nil
+	case rsaKey != "":
+		return &compute.CustomerEncryptionKey{RsaEncryptedKey: rsaKey}, nil
+	}
+
+	return nil, goof.WithField(
+		"volumeID", disk.Name,
+		"volume protected by customer-supplied encryption key requires key")
+}
+
+// decodeRawKey decodes a base64 encoded, 256-bit customer-supplied
+// encryption key. The key is never included in the returned error.
+func decodeRawKey(rawKey string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(rawKey)
+	if err != nil {
+		return nil, goof.New("encryption key is not base64 encoded")
+	}
+	if len(key) != 32 {
+		return nil, goof.WithField(
+			"length", len(key), "encryption key must be 256 bits")
+	}
+	return key, nil
+}
+
+// setEncryptionFields marks the volume as encrypted if its disk is protected
+// by a Cloud KMS key or a customer-supplied encryption key.
+func setEncryptionFields(volume *types.Volume, disk *compute.Disk) {
+	if disk.DiskEncryptionKey == nil {
+		return
+	}
+	volume.Encrypted = true
+	if volume.Fields == nil {
+		volume.Fields = map[string]string{}
+	}
+	if v := disk.DiskEncryptionKey.KmsKeyName; v != "" {
+		volume.Fields[gcepd.VolumeFieldKmsKeyName] = v
+	}
+	if v := disk.DiskEncryptionKey.Sha256; v != "" {
+		volume.Fields[gcepd.VolumeFieldEncryptionKeySha256] = v
+	}
+}
+
+func (d *driver) kmsKeyName() string {
+	return d.config.GetString("gcepd.kmsKeyName")
+}
+
+func (d *driver) encryptionKey() string {
+	return d.config.GetString("gcepd.encryptionKey")
+}
+
+func (d *driver) rsaEncryptedKey() string {
+	return d.config.GetString("gcepd.rsaEncryptedKey")
+}
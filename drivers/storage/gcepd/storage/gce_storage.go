@@ -36,6 +36,10 @@ const (
 	cacheKeyC     = "cacheKey"
 	tagKey        = "libstoragetag"
 	minDiskSizeGB = 10
+
+	// metadataLabelPrefix is the prefix of the keys of the labels with
+	// which the server stamps the disks it creates
+	metadataLabelPrefix = "libstorage-"
 )
 
 var (
@@ -43,6 +47,10 @@ var (
 	// with a lowercase letter or numeral. In between can be lowercase
 	// letters, numbers or dashes
 	tagRegex = regexp.MustCompile(`^[a-z](?:[a-z0-9\-]*[a-z0-9])?$`)
+
+	// labelValueRegex matches the characters that are not allowed in label
+	// values
+	labelValueRegex = regexp.MustCompile(`[^a-z0-9_\-]`)
 )
 
 type driver struct {
@@ -220,6 +228,17 @@ func (d *driver) NextDeviceInfo(
 	return nil, nil
 }
 
+// Capabilities returns the capabilities of the driver's storage platform.
+func (d *driver) Capabilities(
+	ctx types.Context) (*types.StorageCapabilities, error) {
+
+	return &types.StorageCapabilities{
+		ReadOnlyAttach: true,
+		MaxVolumeSize:  65536,
+		Topology:       []string{"zone"},
+	}, nil
+}
+
 // Type returns the type of storage the driver provides.
 func (d *driver) Type(ctx types.Context) (types.StorageType, error) {
 	return types.Block, nil
@@ -233,6 +252,7 @@ func (d *driver) InstanceInspect(
 	iid := context.MustInstanceID(ctx)
 	return &types.Instance{
 		InstanceID: iid,
+		Zone:       iid.Fields[gcepd.InstanceIDFieldZone],
 	}, nil
 }
 
@@ -384,6 +404,12 @@ func (d *driver) VolumeCreate(
 	)
 }
 
+// StampsVolumeMetadata returns true since the driver labels the disks it
+// creates with the server's metadata.
+func (d *driver) StampsVolumeMetadata(ctx types.Context) bool {
+	return true
+}
+
 // VolumeCreateFromSnapshot creates a new volume from an existing snapshot.
 func (d *driver) VolumeCreateFromSnapshot(
 	ctx types.Context,
@@ -428,7 +454,7 @@ func (d *driver) VolumeRemove(
 
 	// TODO: check if disk is still attached first
 	asyncOp, err := mustSession(ctx).Disks.Delete(
-		*d.projectID, *zone, volumeID).Do()
+		*d.projectID, *zone, volumeID).Context(ctx).Do()
 	if err != nil {
 		return goof.WithError("Failed to initiate disk deletion", err)
 	}
@@ -476,7 +502,9 @@ func (d *driver) VolumeAttach(
 		return nil, "", goof.New("Volume not found")
 	}
 
-	if len(gceDisk.Users) > 0 {
+	// a disk may be attached read-only to any number of instances as long
+	// as none of them have attached it for reading and writing
+	if len(gceDisk.Users) > 0 && !opts.AccessMode.ReadOnly() {
 		if !opts.Force {
 			return nil, "", goof.New(
 				"Volume already attached to different host")
@@ -490,7 +518,13 @@ func (d *driver) VolumeAttach(
 		}
 	}
 
-	err = d.attachVolume(ctx, &instanceName, zone, &volumeID)
+	key, err := d.attachEncryptionKey(gceDisk, opts.Opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = d.attachVolume(
+		ctx, &instanceName, zone, &volumeID, opts.AccessMode.ReadOnly(), key)
 	if err != nil {
 		return nil, "", err
 	}
@@ -658,7 +692,7 @@ func (d *driver) getDisks(
 		diskListQ.Filter(filter)
 	}
 
-	diskList, err := diskListQ.Do()
+	diskList, err := diskListQ.Context(ctx).Do()
 	if err != nil {
 		ctx.Errorf("Error listing disks: %s", err)
 		return nil, err
@@ -677,7 +711,7 @@ func (d *driver) getAggregatedDisks(
 		aggListQ.Filter(filter)
 	}
 
-	aggList, err := aggListQ.Do()
+	aggList, err := aggListQ.Context(ctx).Do()
 	if err != nil {
 		ctx.Errorf("Error listing aggregated disks: %s", err)
 		return nil, err
@@ -699,7 +733,8 @@ func (d *driver) getDisk(
 	zone *string,
 	name *string) (*compute.Disk, error) {
 
-	disk, err := mustSession(ctx).Disks.Get(*d.projectID, *zone, *name).Do()
+	disk, err := mustSession(ctx).Disks.Get(
+		*d.projectID, *zone, *name).Context(ctx).Do()
 	if err != nil {
 		if apiE, ok := err.(*googleapi.Error); ok {
 			if apiE.Code == 404 {
@@ -718,7 +753,8 @@ func (d *driver) getInstance(
 	zone *string,
 	name *string) (*compute.Instance, error) {
 
-	inst, err := mustSession(ctx).Instances.Get(*d.projectID, *zone, *name).Do()
+	inst, err := mustSession(ctx).Instances.Get(
+		*d.projectID, *zone, *name).Context(ctx).Do()
 	if err != nil {
 		if apiE, ok := err.(*googleapi.Error); ok {
 			if apiE.Code == 404 {
@@ -763,7 +799,9 @@ func (d *driver) toTypeVolume(
 			Status:           disk.Status,
 			Type:             utils.GetIndex(disk.Type),
 			Size:             disk.SizeGb,
+			Fields:           metadataFields(disk.Labels),
 		}
+		setEncryptionFields(volume, disk)
 
 		if attachments.Requested() {
 			attachment := getAttachment(disk, attachments, ld)
@@ -817,6 +855,11 @@ func getAttachment(
 		if attachments.Devices() {
 			if dev, ok := ld.DeviceMap[disk.Name]; ok {
 				att.DeviceName = dev
+				// persistent disks are attached as SCSI disks whose
+				// serial numbers are the names with which they were
+				// attached, the names of the disks
+				att.BusType = "scsi"
+				att.Serial = disk.Name
 				// TODO: Do we need to enforce that the zone
 				// found in link matches the zone for the volume?
 			}
@@ -846,14 +889,20 @@ func (d *driver) createVolume(
 	diskTypeURI := fmt.Sprintf("zones/%s/diskTypes/%s",
 		*opts.AvailabilityZone, diskType)
 
+	key, err := d.createEncryptionKey(opts)
+	if err != nil {
+		return err
+	}
+
 	createDisk := &compute.Disk{
-		Name:   *volumeName,
-		SizeGb: *opts.Size,
-		Type:   diskTypeURI,
+		Name:              *volumeName,
+		SizeGb:            *opts.Size,
+		Type:              diskTypeURI,
+		DiskEncryptionKey: key,
 	}
 
 	asyncOp, err := mustSession(ctx).Disks.Insert(
-		*d.projectID, *opts.AvailabilityZone, createDisk).Do()
+		*d.projectID, *opts.AvailabilityZone, createDisk).Context(ctx).Do()
 	if err != nil {
 		return goof.WithError("Failed to initiate disk creation", err)
 	}
@@ -864,7 +913,7 @@ func (d *driver) createVolume(
 		return err
 	}
 
-	if d.tag != "" {
+	if labels := d.getDiskLabels(ctx); len(labels) > 0 {
 		/* In order to set the labels on a disk, we have to query the
 		   disk first in order to get the generated label fingerprint
 		*/
@@ -874,13 +923,12 @@ func (d *driver) createVolume(
 				"Unable to query disk for labeling")
 			return nil
 		}
-		labels := getLabels(&d.tag)
 		_, err = mustSession(ctx).Disks.SetLabels(
 			*d.projectID, *opts.AvailabilityZone, *volumeName,
 			&compute.ZoneSetLabelsRequest{
 				Labels:           labels,
 				LabelFingerprint: disk.LabelFingerprint,
-			}).Do()
+			}).Context(ctx).Do()
 		if err != nil {
 			ctx.WithError(err).Warn("Unable to label disk")
 		}
@@ -899,7 +947,7 @@ OpLoop:
 	for {
 		time.Sleep(100 * time.Millisecond)
 		op, err := mustSession(ctx).ZoneOperations.Get(
-			*d.projectID, *zone, opName).Do()
+			*d.projectID, *zone, opName).Context(ctx).Do()
 		if err != nil {
 			return err
 		}
@@ -925,17 +973,24 @@ func (d *driver) attachVolume(
 	ctx types.Context,
 	instanceID *string,
 	zone *string,
-	volumeName *string) error {
+	volumeName *string,
+	readOnly bool,
+	key *compute.CustomerEncryptionKey) error {
 
 	disk := &compute.AttachedDisk{
-		AutoDelete: false,
-		Boot:       false,
-		Source:     fmt.Sprintf("zones/%s/disks/%s", *zone, *volumeName),
-		DeviceName: *volumeName,
+		AutoDelete:        false,
+		Boot:              false,
+		Source:            fmt.Sprintf("zones/%s/disks/%s", *zone, *volumeName),
+		DeviceName:        *volumeName,
+		Mode:              "READ_WRITE",
+		DiskEncryptionKey: key,
+	}
+	if readOnly {
+		disk.Mode = "READ_ONLY"
 	}
 
 	asyncOp, err := mustSession(ctx).Instances.AttachDisk(
-		*d.projectID, *zone, *instanceID, disk).Do()
+		*d.projectID, *zone, *instanceID, disk).Context(ctx).Do()
 	if err != nil {
 		return err
 	}
@@ -990,7 +1045,7 @@ func (d *driver) detachVolume(
 				"Unable to get device name from instance", err)
 		}
 		asyncOp, err := mustSession(ctx).Instances.DetachDisk(
-			*d.projectID, zone, instanceName, devName).Do()
+			*d.projectID, zone, instanceName, devName).Context(ctx).Do()
 		if err != nil {
 			asyncErr = goof.WithError("Error detaching disk", err)
 			continue
@@ -1018,3 +1073,45 @@ func getLabels(tag *string) map[string]string {
 
 	return labels
 }
+
+// getDiskLabels returns the labels of a new disk, which are the configured
+// tag and the metadata with which the server stamps the disks it creates.
+// The metadata's values are converted to valid label values.
+func (d *driver) getDiskLabels(ctx types.Context) map[string]string {
+	labels := map[string]string{}
+	if d.tag != "" {
+		labels = getLabels(&d.tag)
+	}
+	if md, ok := context.VolumeMetadata(ctx); ok {
+		for k, v := range md {
+			labels[k] = toLabelValue(v)
+		}
+	}
+	return labels
+}
+
+// toLabelValue converts the value to a valid label value, which has at most
+// 63 lowercase letters, numerals, dashes, and underscores.
+func toLabelValue(v string) string {
+	v = labelValueRegex.ReplaceAllString(strings.ToLower(v), "-")
+	if len(v) > 63 {
+		v = v[:63]
+	}
+	return v
+}
+
+// metadataFields returns the metadata labels with which the server stamped
+// a disk, or nil if the disk has none.
+func metadataFields(labels map[string]string) map[string]string {
+	var fields map[string]string
+	for k, v := range labels {
+		if !strings.HasPrefix(k, metadataLabelPrefix) {
+			continue
+		}
+		if fields == nil {
+			fields = map[string]string{}
+		}
+		fields[k] = v
+	}
+	return fields
+}
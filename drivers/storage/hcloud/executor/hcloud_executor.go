@@ -0,0 +1,88 @@
+// +build !libstorage_storage_executor libstorage_storage_executor_hcloud
+
+package executor
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+
+	gofig "github.com/akutz/gofig/types"
+
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/drivers/storage/hcloud"
+	hcUtils "github.com/codedellemc/libstorage/drivers/storage/hcloud/utils"
+)
+
+const diskIDPath = "/dev/disk/by-id"
+
+var (
+	diskPrefix = regexp.MustCompile(`^` + hcloud.VolumePrefix + `(\d+)$`)
+)
+
+type driver struct {
+	config gofig.Config
+}
+
+func init() {
+	registry.RegisterStorageExecutor(hcloud.Name, newDriver)
+}
+
+func newDriver() types.StorageExecutor {
+	return &driver{}
+}
+
+func (d *driver) Name() string {
+	return hcloud.Name
+}
+
+func (d *driver) Init(ctx types.Context, config gofig.Config) error {
+	d.config = config
+	return nil
+}
+
+func (d *driver) InstanceID(
+	ctx types.Context, opts types.Store) (*types.InstanceID, error) {
+	return hcUtils.InstanceID(ctx)
+}
+
+func (d *driver) NextDevice(
+	ctx types.Context, opts types.Store) (string, error) {
+	return "", types.ErrNotImplemented
+}
+
+// LocalDevices maps the IDs of the attached volumes to their devices, which
+// are resolved from the Linux device IDs assigned by the hypervisor rather
+// than from the order in which the volumes were attached.
+func (d *driver) LocalDevices(
+	ctx types.Context,
+	opts *types.LocalDevicesOpts) (*types.LocalDevices, error) {
+
+	deviceMap := map[string]string{}
+
+	dir, _ := ioutil.ReadDir(diskIDPath)
+	for _, device := range dir {
+		m := diskPrefix.FindStringSubmatch(device.Name())
+		if m == nil {
+			continue
+		}
+		devPath, err := filepath.EvalSymlinks(
+			filepath.Join(diskIDPath, device.Name()))
+		if err != nil {
+			return nil, err
+		}
+		deviceMap[m[1]] = devPath
+	}
+
+	ld := &types.LocalDevices{Driver: d.Name()}
+	if len(deviceMap) > 0 {
+		ld.DeviceMap = deviceMap
+	}
+
+	return ld, nil
+}
+
+func (d *driver) Supported(ctx types.Context, opts types.Store) (bool, error) {
+	return hcUtils.IsServer(ctx)
+}
@@ -0,0 +1,89 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_hcloud
+
+package hcloud
+
+import (
+	gofigCore "github.com/akutz/gofig"
+	gofig "github.com/akutz/gofig/types"
+)
+
+const (
+	// Name is the name of the driver
+	Name = "hcloud"
+
+	// InstanceIDFieldLocation is the key used to retrieve the location from
+	// the instance id map
+	InstanceIDFieldLocation = "location"
+
+	// InstanceIDFieldName is the key used to retrieve the name from the
+	// instance id map
+	InstanceIDFieldName = "name"
+
+	// VolumePrefix is the prefix of the Linux device IDs of Hetzner Cloud
+	// volumes, which are followed by the volume's ID, for example:
+	//
+	//     /dev/disk/by-id/scsi-0HC_Volume_1234567
+	VolumePrefix = "scsi-0HC_Volume_"
+
+	// DefaultEndpoint is the default URL of the Hetzner Cloud API
+	DefaultEndpoint = "https://api.hetzner.cloud/v1"
+
+	// DefaultActionTimeout is the default value of ConfigHCloudActionTimeout
+	DefaultActionTimeout = "2m"
+
+	// MinVolumeSize is the minimum size of a volume in GiB
+	MinVolumeSize = 10
+
+	// MaxVolumeSize is the maximum size of a volume in GiB
+	MaxVolumeSize = 10240
+
+	// VolumeFieldLinuxDevice is the key of the volume field that contains
+	// the path of the volume's Linux device
+	VolumeFieldLinuxDevice = "linuxDevice"
+
+	// ConfigHCloudToken is the key for the API token in the config file
+	ConfigHCloudToken = Name + ".token"
+
+	// ConfigHCloudLocation is the key for the location in the config file
+	ConfigHCloudLocation = Name + ".location"
+
+	// ConfigHCloudEndpoint is the key for the API endpoint in the config file
+	ConfigHCloudEndpoint = Name + ".endpoint"
+
+	// ConfigHCloudActionTimeout is the key for how long the driver waits for
+	// an action, such as attaching a volume, to complete in the config file
+	ConfigHCloudActionTimeout = Name + ".actionTimeout"
+)
+
+func init() {
+	registerConfig()
+}
+
+func registerConfig() {
+	r := gofigCore.NewRegistration("Hetzner Cloud Volumes")
+	r.Key(
+		gofig.String,
+		"",
+		"",
+		"The Hetzner Cloud API token",
+		ConfigHCloudToken)
+	r.Key(
+		gofig.String,
+		"",
+		"",
+		"The Hetzner Cloud location",
+		ConfigHCloudLocation)
+	r.Key(
+		gofig.String,
+		"",
+		DefaultEndpoint,
+		"The Hetzner Cloud API endpoint",
+		ConfigHCloudEndpoint)
+	r.Key(
+		gofig.String,
+		"",
+		DefaultActionTimeout,
+		"How long to wait for an action to complete",
+		ConfigHCloudActionTimeout)
+	gofigCore.Register(r)
+}
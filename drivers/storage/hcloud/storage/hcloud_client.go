@@ -0,0 +1,277 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_hcloud
+
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api"
+	"github.com/codedellemc/libstorage/api/types"
+
+	hcUtils "github.com/codedellemc/libstorage/drivers/storage/hcloud/utils"
+)
+
+const (
+	actionStatusRunning = "running"
+	actionStatusError   = "error"
+
+	// volumesPerPage is the maximum page size of the Hetzner Cloud API.
+	volumesPerPage = 50
+
+	actionPollInterval = time.Second
+)
+
+// client is a client of the parts of the Hetzner Cloud API used by the
+// driver.
+type client struct {
+	endpoint string
+	token    string
+}
+
+type hcVolume struct {
+	ID       int               `json:"id"`
+	Name     string            `json:"name"`
+	Size     int64             `json:"size"`
+	Server   *int              `json:"server"`
+	Status   string            `json:"status"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Location struct {
+		Name string `json:"name"`
+	} `json:"location"`
+	LinuxDevice string `json:"linux_device"`
+}
+
+type hcAction struct {
+	ID     int    `json:"id"`
+	Status string `json:"status"`
+	Error  *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type hcServer struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	Datacenter struct {
+		Name     string `json:"name"`
+		Location struct {
+			Name string `json:"name"`
+		} `json:"location"`
+	} `json:"datacenter"`
+}
+
+type hcVolumeCreateRequest struct {
+	Name     string `json:"name"`
+	Size     int64  `json:"size"`
+	Location string `json:"location"`
+}
+
+// apiError is an error returned by the Hetzner Cloud API.
+type apiError struct {
+	status  int
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("hcloud: %s (%s)", e.Message, e.Code)
+}
+
+func isNotFound(err error) bool {
+	aerr, ok := err.(*apiError)
+	return ok && aerr.status == http.StatusNotFound
+}
+
+// do sends the request to the API and decodes the reply into v.
+func (c *client) do(
+	ctx types.Context,
+	method, path string,
+	body, v interface{}) error {
+
+	var r io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(buf)
+	}
+
+	req, err := http.NewRequest(
+		method, strings.TrimSuffix(c.endpoint, "/")+path, r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("User-Agent", "libstorage/"+api.Version.SemVer)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := hcUtils.DoRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		var reply struct {
+			Error *apiError `json:"error"`
+		}
+		json.NewDecoder(res.Body).Decode(&reply)
+		if reply.Error == nil {
+			reply.Error = &apiError{Message: res.Status}
+		}
+		reply.Error.status = res.StatusCode
+		return reply.Error
+	}
+
+	if v == nil || res.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+func (c *client) listVolumes(ctx types.Context) ([]*hcVolume, error) {
+	var vols []*hcVolume
+	for page := 1; page > 0; {
+		var reply struct {
+			Volumes []*hcVolume `json:"volumes"`
+			Meta    struct {
+				Pagination struct {
+					NextPage *int `json:"next_page"`
+				} `json:"pagination"`
+			} `json:"meta"`
+		}
+		path := fmt.Sprintf(
+			"/volumes?page=%d&per_page=%d", page, volumesPerPage)
+		if err := c.do(ctx, http.MethodGet, path, nil, &reply); err != nil {
+			return nil, err
+		}
+		vols = append(vols, reply.Volumes...)
+		page = 0
+		if reply.Meta.Pagination.NextPage != nil {
+			page = *reply.Meta.Pagination.NextPage
+		}
+	}
+	return vols, nil
+}
+
+func (c *client) getVolume(
+	ctx types.Context, volumeID string) (*hcVolume, error) {
+
+	var reply struct {
+		Volume *hcVolume `json:"volume"`
+	}
+	if err := c.do(
+		ctx, http.MethodGet, "/volumes/"+volumeID, nil, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Volume, nil
+}
+
+func (c *client) createVolume(
+	ctx types.Context,
+	createReq *hcVolumeCreateRequest) (*hcVolume, *hcAction, error) {
+
+	var reply struct {
+		Volume *hcVolume `json:"volume"`
+		Action *hcAction `json:"action"`
+	}
+	if err := c.do(
+		ctx, http.MethodPost, "/volumes", createReq, &reply); err != nil {
+		return nil, nil, err
+	}
+	return reply.Volume, reply.Action, nil
+}
+
+func (c *client) deleteVolume(ctx types.Context, volumeID string) error {
+	return c.do(ctx, http.MethodDelete, "/volumes/"+volumeID, nil, nil)
+}
+
+// volumeAction requests the volume action, ex. "attach", and returns the
+// action that is run by the API.
+func (c *client) volumeAction(
+	ctx types.Context,
+	volumeID, action string,
+	body interface{}) (*hcAction, error) {
+
+	if body == nil {
+		body = struct{}{}
+	}
+	var reply struct {
+		Action *hcAction `json:"action"`
+	}
+	path := "/volumes/" + volumeID + "/actions/" + action
+	if err := c.do(ctx, http.MethodPost, path, body, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Action, nil
+}
+
+func (c *client) getServer(
+	ctx types.Context, serverID string) (*hcServer, error) {
+
+	var reply struct {
+		Server *hcServer `json:"server"`
+	}
+	if err := c.do(
+		ctx, http.MethodGet, "/servers/"+serverID, nil, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Server, nil
+}
+
+// waitForAction polls the action until it is no longer running or the
+// timeout elapses. An error is returned if the action failed.
+func (c *client) waitForAction(
+	ctx types.Context,
+	action *hcAction,
+	timeout time.Duration) error {
+
+	if action == nil {
+		return nil
+	}
+
+	deadline := time.Now().Add(timeout)
+	for action.Status == actionStatusRunning {
+		if time.Now().After(deadline) {
+			return goof.WithFields(goof.Fields{
+				"actionID": action.ID,
+				"timeout":  timeout,
+			}, "timed out waiting for action")
+		}
+		time.Sleep(actionPollInterval)
+
+		var reply struct {
+			Action *hcAction `json:"action"`
+		}
+		path := fmt.Sprintf("/actions/%d", action.ID)
+		if err := c.do(ctx, http.MethodGet, path, nil, &reply); err != nil {
+			return err
+		}
+		if reply.Action == nil {
+			return nil
+		}
+		action = reply.Action
+	}
+
+	if action.Status == actionStatusError {
+		fields := goof.Fields{"actionID": action.ID}
+		if action.Error != nil {
+			fields["code"] = action.Error.Code
+			fields["message"] = action.Error.Message
+		}
+		return goof.WithFields(fields, "action failed")
+	}
+	return nil
+}
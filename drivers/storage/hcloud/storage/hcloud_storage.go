@@ -0,0 +1,493 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_hcloud
+
+package storage
+
+import (
+	"strconv"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+
+	"github.com/codedellemc/libstorage/drivers/storage/hcloud"
+)
+
+type driver struct {
+	config gofig.Config
+	client *client
+}
+
+func init() {
+	registry.RegisterStorageDriver(hcloud.Name, newDriver)
+}
+
+func newDriver() types.StorageDriver {
+	return &driver{}
+}
+
+func (d *driver) Name() string {
+	return hcloud.Name
+}
+
+func (d *driver) Init(ctx types.Context, config gofig.Config) error {
+	d.config = config
+
+	if d.token() == "" {
+		return goof.New("hcloud.token is required")
+	}
+	if _, err := time.ParseDuration(d.actionTimeout()); err != nil {
+		return goof.WithFieldE(
+			"actionTimeout", d.actionTimeout(),
+			"invalid action timeout", err)
+	}
+
+	d.client = &client{
+		endpoint: d.endpoint(),
+		token:    d.token(),
+	}
+
+	ctx.WithFields(log.Fields{
+		"token":    "******",
+		"location": d.location(),
+		"endpoint": d.endpoint(),
+	}).Info("storage driver initialized")
+
+	return nil
+}
+
+func (d *driver) Type(ctx types.Context) (types.StorageType, error) {
+	return types.Block, nil
+}
+
+// Volumes are bound to a location and may be attached to the servers in
+// any of the location's data centers.
+func (d *driver) Capabilities(
+	ctx types.Context) (*types.StorageCapabilities, error) {
+
+	return &types.StorageCapabilities{
+		Resize:        true,
+		MaxVolumeSize: hcloud.MaxVolumeSize,
+		Topology:      []string{"zone"},
+	}, nil
+}
+
+func (d *driver) NextDeviceInfo(
+	ctx types.Context) (*types.NextDeviceInfo, error) {
+	return nil, nil
+}
+
+func (d *driver) InstanceInspect(
+	ctx types.Context, opts types.Store) (*types.Instance, error) {
+
+	iid := context.MustInstanceID(ctx)
+	instance := &types.Instance{
+		InstanceID:   iid,
+		Region:       iid.Fields[hcloud.InstanceIDFieldLocation],
+		Zone:         iid.Fields[hcloud.InstanceIDFieldLocation],
+		Name:         iid.Fields[hcloud.InstanceIDFieldName],
+		ProviderName: iid.Driver,
+	}
+
+	// an instance ID without fields is resolved with the API
+	if instance.Zone == "" {
+		server, err := d.client.getServer(ctx, iid.ID)
+		if err != nil {
+			return nil, goof.WithFieldE(
+				"serverID", iid.ID, "error getting server", err)
+		}
+		instance.Name = server.Name
+		instance.Region = server.Datacenter.Location.Name
+		instance.Zone = server.Datacenter.Location.Name
+	}
+
+	return instance, nil
+}
+
+func (d *driver) Volumes(
+	ctx types.Context, opts *types.VolumesOpts) ([]*types.Volume, error) {
+
+	hcVolumes, err := d.client.listVolumes(ctx)
+	if err != nil {
+		return nil, goof.WithError("error listing volumes", err)
+	}
+
+	var volumes []*types.Volume
+	for _, vol := range hcVolumes {
+		v, err := d.toTypesVolume(ctx, vol, opts.Attachments)
+		if err != nil {
+			return nil, err
+		}
+		volumes = append(volumes, v)
+	}
+
+	return volumes, nil
+}
+
+func (d *driver) VolumeInspect(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeInspectOpts) (*types.Volume, error) {
+
+	vol, err := d.getVolume(ctx, volumeID)
+	if err != nil {
+		return nil, err
+	}
+	return d.toTypesVolume(ctx, vol, opts.Attachments)
+}
+
+func (d *driver) VolumeCreate(
+	ctx types.Context,
+	name string,
+	opts *types.VolumeCreateOpts) (*types.Volume, error) {
+
+	size := int64(hcloud.MinVolumeSize)
+	if opts.Size != nil && *opts.Size > size {
+		size = *opts.Size
+	}
+	if size > hcloud.MaxVolumeSize {
+		return nil, utils.NewInvalidRequestError(
+			"size", size, "size exceeds the maximum volume size")
+	}
+
+	location := d.location()
+	if opts.AvailabilityZone != nil && *opts.AvailabilityZone != "" {
+		location = *opts.AvailabilityZone
+	}
+	if location == "" {
+		instance, err := d.InstanceInspect(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		location = instance.Zone
+	}
+
+	fields := log.Fields{
+		"volumeName": name,
+		"size":       size,
+		"location":   location,
+	}
+	ctx.WithFields(fields).Debug("creating volume")
+
+	vol, action, err := d.client.createVolume(ctx, &hcVolumeCreateRequest{
+		Name:     name,
+		Size:     size,
+		Location: location,
+	})
+	if err != nil {
+		return nil, goof.WithFieldsE(fields, "error creating volume", err)
+	}
+	if err := d.waitForAction(ctx, action); err != nil {
+		return nil, goof.WithFieldsE(fields, "error creating volume", err)
+	}
+
+	return d.VolumeInspect(ctx, strconv.Itoa(vol.ID), &types.VolumeInspectOpts{
+		Attachments: types.VolAttReqTrue,
+	})
+}
+
+func (d *driver) VolumeCreateFromSnapshot(
+	ctx types.Context,
+	snapshotID, volumeName string,
+	opts *types.VolumeCreateOpts) (*types.Volume, error) {
+	return nil, types.ErrNotImplemented
+}
+
+func (d *driver) VolumeCopy(
+	ctx types.Context,
+	volumeID, volumeName string,
+	opts types.Store) (*types.Volume, error) {
+	return nil, types.ErrNotImplemented
+}
+
+func (d *driver) VolumeSnapshot(
+	ctx types.Context,
+	volumeID, snapshotName string,
+	opts types.Store) (*types.Snapshot, error) {
+	return nil, types.ErrNotImplemented
+}
+
+func (d *driver) VolumeRemove(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeRemoveOpts) error {
+
+	vol, err := d.getVolume(ctx, volumeID)
+	if err != nil {
+		return err
+	}
+
+	if vol.Server != nil {
+		if !opts.Force {
+			return utils.NewVolumeInUseError(volumeID)
+		}
+		if err := d.volumeDetach(ctx, volumeID); err != nil {
+			return err
+		}
+	}
+
+	if err := d.client.deleteVolume(ctx, volumeID); err != nil {
+		return goof.WithFieldE(
+			"volumeID", volumeID, "error removing volume", err)
+	}
+	return nil
+}
+
+func (d *driver) VolumeAttach(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeAttachOpts) (*types.Volume, string, error) {
+
+	vol, err := d.getVolume(ctx, volumeID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	serverID, err := strconv.Atoi(context.MustInstanceID(ctx).ID)
+	if err != nil {
+		return nil, "", goof.WithError("invalid server id", err)
+	}
+
+	if vol.Server != nil {
+		if *vol.Server == serverID {
+			return nil, "", goof.New("volume already attached to instance")
+		}
+		if !opts.Force {
+			return nil, "", goof.New("volume already attached")
+		}
+		if err := d.volumeDetach(ctx, volumeID); err != nil {
+			return nil, "", err
+		}
+	}
+
+	fields := log.Fields{
+		"volumeID": volumeID,
+		"serverID": serverID,
+	}
+	ctx.WithFields(fields).Debug("attaching volume")
+
+	// the volume is attached without being mounted by the server so that
+	// it is formatted and mounted by the integration driver
+	action, err := d.client.volumeAction(
+		ctx, volumeID, "attach", map[string]interface{}{
+			"server":    serverID,
+			"automount": false,
+		})
+	if err != nil {
+		return nil, "", goof.WithFieldsE(fields, "error attaching volume", err)
+	}
+	if err := d.waitForAction(ctx, action); err != nil {
+		return nil, "", goof.WithFieldsE(fields, "error attaching volume", err)
+	}
+
+	attachedVol, err := d.VolumeInspect(
+		ctx, volumeID, &types.VolumeInspectOpts{
+			Attachments: types.VolAttReqTrue,
+			Opts:        opts.Opts,
+		})
+	if err != nil {
+		return nil, "", goof.WithError("error getting volume", err)
+	}
+
+	// the executor maps the IDs of the attached volumes to their devices
+	return attachedVol, volumeID, nil
+}
+
+func (d *driver) VolumeDetach(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeDetachOpts) (*types.Volume, error) {
+
+	vol, err := d.getVolume(ctx, volumeID)
+	if err != nil {
+		return nil, err
+	}
+	if vol.Server == nil {
+		return nil, goof.New("volume already detached")
+	}
+
+	if err := d.volumeDetach(ctx, volumeID); err != nil {
+		return nil, err
+	}
+
+	return d.VolumeInspect(ctx, volumeID, &types.VolumeInspectOpts{
+		Attachments: types.VolAttReqTrue,
+		Opts:        opts.Opts,
+	})
+}
+
+// VolumeResize expands the volume. Hetzner Cloud volumes cannot be shrunk.
+func (d *driver) VolumeResize(
+	ctx types.Context,
+	volumeID string,
+	size int64,
+	opts types.Store) (*types.Volume, error) {
+
+	vol, err := d.getVolume(ctx, volumeID)
+	if err != nil {
+		return nil, err
+	}
+	if size <= vol.Size {
+		return nil, utils.NewInvalidRequestError(
+			"size", size, "size must be greater than the volume's size")
+	}
+	if size > hcloud.MaxVolumeSize {
+		return nil, utils.NewInvalidRequestError(
+			"size", size, "size exceeds the maximum volume size")
+	}
+
+	fields := log.Fields{
+		"volumeID": volumeID,
+		"size":     size,
+	}
+	ctx.WithFields(fields).Debug("resizing volume")
+
+	action, err := d.client.volumeAction(
+		ctx, volumeID, "resize", map[string]interface{}{"size": size})
+	if err != nil {
+		return nil, goof.WithFieldsE(fields, "error resizing volume", err)
+	}
+	if err := d.waitForAction(ctx, action); err != nil {
+		return nil, goof.WithFieldsE(fields, "error resizing volume", err)
+	}
+
+	return d.VolumeInspect(ctx, volumeID, &types.VolumeInspectOpts{
+		Attachments: types.VolAttReqTrue,
+		Opts:        opts,
+	})
+}
+
+func (d *driver) Snapshots(
+	ctx types.Context, opts types.Store) ([]*types.Snapshot, error) {
+	return nil, types.ErrNotImplemented
+}
+
+func (d *driver) SnapshotInspect(
+	ctx types.Context,
+	snapshotID string,
+	opts types.Store) (*types.Snapshot, error) {
+	return nil, types.ErrNotImplemented
+}
+
+func (d *driver) SnapshotCopy(
+	ctx types.Context,
+	snapshotID, snapshotName, destinationID string,
+	opts types.Store) (*types.Snapshot, error) {
+	return nil, types.ErrNotImplemented
+}
+
+func (d *driver) SnapshotRemove(
+	ctx types.Context, snapshotID string, opts types.Store) error {
+	return types.ErrNotImplemented
+}
+
+func (d *driver) getVolume(
+	ctx types.Context, volumeID string) (*hcVolume, error) {
+
+	vol, err := d.client.getVolume(ctx, volumeID)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, utils.NewVolumeNotFoundError(volumeID)
+		}
+		return nil, goof.WithFieldE(
+			"volumeID", volumeID, "error getting volume", err)
+	}
+	return vol, nil
+}
+
+func (d *driver) volumeDetach(ctx types.Context, volumeID string) error {
+	ctx.WithField("volumeID", volumeID).Debug("detaching volume")
+
+	action, err := d.client.volumeAction(ctx, volumeID, "detach", nil)
+	if err != nil {
+		return goof.WithFieldE(
+			"volumeID", volumeID, "error detaching volume", err)
+	}
+	if err := d.waitForAction(ctx, action); err != nil {
+		return goof.WithFieldE(
+			"volumeID", volumeID, "error detaching volume", err)
+	}
+	return nil
+}
+
+func (d *driver) waitForAction(ctx types.Context, action *hcAction) error {
+	timeout, err := time.ParseDuration(d.actionTimeout())
+	if err != nil {
+		return err
+	}
+	return d.client.waitForAction(ctx, action, timeout)
+}
+
+func (d *driver) toTypesVolume(
+	ctx types.Context,
+	vol *hcVolume,
+	attachments types.VolumeAttachmentsTypes) (*types.Volume, error) {
+
+	volumeID := strconv.Itoa(vol.ID)
+
+	status := "detached"
+	if vol.Server != nil {
+		status = "attached"
+	}
+
+	volume := &types.Volume{
+		Name:             vol.Name,
+		ID:               volumeID,
+		Size:             vol.Size,
+		AvailabilityZone: vol.Location.Name,
+		Status:           status,
+		Fields:           map[string]string{},
+	}
+	if vol.LinuxDevice != "" {
+		volume.Fields[hcloud.VolumeFieldLinuxDevice] = vol.LinuxDevice
+	}
+	for k, v := range vol.Labels {
+		volume.Fields[k] = v
+	}
+
+	if !attachments.Requested() || vol.Server == nil {
+		return volume, nil
+	}
+
+	att := &types.VolumeAttachment{
+		VolumeID: volumeID,
+		InstanceID: &types.InstanceID{
+			ID:     strconv.Itoa(*vol.Server),
+			Driver: hcloud.Name,
+		},
+	}
+	if attachments.Devices() {
+		ld, ok := context.LocalDevices(ctx)
+		if !ok {
+			return nil, goof.New("error getting local devices from context")
+		}
+		if dev, ok := ld.DeviceMap[volumeID]; ok {
+			att.DeviceName = dev
+			att.BusType = "scsi"
+		}
+	}
+	volume.Attachments = []*types.VolumeAttachment{att}
+
+	return volume, nil
+}
+
+func (d *driver) token() string {
+	return d.config.GetString(hcloud.ConfigHCloudToken)
+}
+
+func (d *driver) location() string {
+	return d.config.GetString(hcloud.ConfigHCloudLocation)
+}
+
+func (d *driver) endpoint() string {
+	return d.config.GetString(hcloud.ConfigHCloudEndpoint)
+}
+
+func (d *driver) actionTimeout() string {
+	return d.config.GetString(hcloud.ConfigHCloudActionTimeout)
+}
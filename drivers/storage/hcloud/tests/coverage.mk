@@ -0,0 +1,2 @@
+HCLOUD_COVERPKG := $(ROOT_IMPORT_PATH)/drivers/storage/hcloud
+TEST_COVERPKG_./drivers/storage/hcloud/tests := $(HCLOUD_COVERPKG),$(HCLOUD_COVERPKG)/executor
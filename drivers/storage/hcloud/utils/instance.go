@@ -0,0 +1,97 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_hcloud
+
+package utils
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/drivers/storage/hcloud"
+)
+
+const (
+	metadataBase = "169.254.169.254"
+	metadataURL  = "http://" + metadataBase + "/hetzner/v1/metadata"
+	metadataID   = metadataURL + "/instance-id"
+	metadataName = metadataURL + "/hostname"
+	metadataAZ   = metadataURL + "/availability-zone"
+)
+
+// InstanceID gets the instance information from the server's metadata
+// service.
+func InstanceID(ctx types.Context) (*types.InstanceID, error) {
+
+	id, err := getURL(ctx, metadataID)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := getURL(ctx, metadataName)
+	if err != nil {
+		return nil, err
+	}
+
+	az, err := getURL(ctx, metadataAZ)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.InstanceID{
+		ID:     id,
+		Driver: hcloud.Name,
+		Fields: map[string]string{
+			hcloud.InstanceIDFieldLocation: Location(az),
+			hcloud.InstanceIDFieldName:     name,
+		},
+	}, nil
+}
+
+// Location returns the location of the availability zone, ex. "fsn1" for
+// the availability zone "fsn1-dc14". Volumes are created in a location and
+// may be attached to the servers in any of its data centers.
+func Location(az string) string {
+	if i := strings.Index(az, "-"); i > 0 {
+		return az[:i]
+	}
+	return az
+}
+
+// IsServer is a simple check to see if code is being executed on a Hetzner
+// Cloud server or not.
+func IsServer(ctx types.Context) (bool, error) {
+	if _, err := getURL(ctx, metadataID); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
+
+func getURL(ctx types.Context, url string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := DoRequest(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", goof.WithFields(goof.Fields{
+			"url":    url,
+			"status": resp.Status,
+		}, "error getting metadata")
+	}
+
+	buf, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(buf)), nil
+}
@@ -0,0 +1,93 @@
+// +build !libstorage_storage_executor libstorage_storage_executor_iscsi
+
+package executor
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	gofig "github.com/akutz/gofig/types"
+
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/drivers/storage/iscsi"
+	iscsiUtils "github.com/codedellemc/libstorage/drivers/storage/iscsi/utils"
+)
+
+const diskByPath = "/dev/disk/by-path"
+
+var iscsiDevice = regexp.MustCompile(`^ip-.+:\d+-iscsi-(.+)-lun-\d+$`)
+
+type driver struct {
+	sync.Mutex
+	config   gofig.Config
+	lastSync time.Time
+}
+
+func init() {
+	registry.RegisterStorageExecutor(iscsi.Name, newDriver)
+}
+
+func newDriver() types.StorageExecutor {
+	return &driver{}
+}
+
+func (d *driver) Name() string {
+	return iscsi.Name
+}
+
+func (d *driver) Init(ctx types.Context, config gofig.Config) error {
+	d.config = config
+	return nil
+}
+
+func (d *driver) InstanceID(
+	ctx types.Context, opts types.Store) (*types.InstanceID, error) {
+	return iscsiUtils.InstanceID(ctx, d.config)
+}
+
+func (d *driver) NextDevice(
+	ctx types.Context, opts types.Store) (string, error) {
+	return "", types.ErrNotImplemented
+}
+
+// LocalDevices maps the IQNs of the targets to which the host is logged in
+// to their devices. The host's sessions are synchronized with the targets
+// exported to the host first.
+func (d *driver) LocalDevices(
+	ctx types.Context,
+	opts *types.LocalDevicesOpts) (*types.LocalDevices, error) {
+
+	d.syncSessions(ctx)
+
+	deviceMap := map[string]string{}
+
+	dir, _ := ioutil.ReadDir(diskByPath)
+	for _, device := range dir {
+		m := iscsiDevice.FindStringSubmatch(device.Name())
+		if m == nil {
+			continue
+		}
+		devPath, err := filepath.EvalSymlinks(
+			filepath.Join(diskByPath, device.Name()))
+		if err != nil {
+			return nil, err
+		}
+		deviceMap[strings.ToLower(m[1])] = devPath
+	}
+
+	ld := &types.LocalDevices{Driver: d.Name()}
+	if len(deviceMap) > 0 {
+		ld.DeviceMap = deviceMap
+	}
+
+	return ld, nil
+}
+
+func (d *driver) Supported(ctx types.Context, opts types.Store) (bool, error) {
+	return iscsiUtils.IsInitiator(ctx, d.config)
+}
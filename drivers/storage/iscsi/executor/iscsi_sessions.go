@@ -0,0 +1,243 @@
+// +build !libstorage_storage_executor libstorage_storage_executor_iscsi
+
+package executor
+
+import (
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+	"github.com/codedellemc/libstorage/drivers/storage/iscsi"
+	iscsiUtils "github.com/codedellemc/libstorage/drivers/storage/iscsi/utils"
+)
+
+const (
+	iscsiadm = "iscsiadm"
+
+	sessionStateLoggedIn = "LOGGED_IN"
+)
+
+// session is an iSCSI session of the host.
+type session struct {
+	target string
+	portal string
+	state  string
+}
+
+// syncSessions logs in to the targets exported to the host, logs out of the
+// block service's targets that are no longer exported to the host, and
+// recovers the sessions that have failed or whose targets have moved to
+// another portal, ex. when the service fails a volume over to another
+// replica. The exported targets are listed with the block service's API,
+// which requires the executor to be configured with the API's endpoint.
+// The sessions are synchronized at most once per sync interval.
+func (d *driver) syncSessions(ctx types.Context) {
+	d.Lock()
+	defer d.Unlock()
+
+	interval, err := time.ParseDuration(
+		d.config.GetString(iscsi.ConfigISCSISyncInterval))
+	if err != nil {
+		interval, _ = time.ParseDuration(iscsi.DefaultSyncInterval)
+	}
+	if time.Since(d.lastSync) < interval {
+		return
+	}
+	d.lastSync = time.Now()
+
+	client, err := iscsiUtils.NewClient(d.config)
+	if err != nil {
+		ctx.WithError(err).Debug("iscsi sessions not synchronized")
+		return
+	}
+	initiator, err := iscsiUtils.InitiatorName(d.config)
+	if err != nil {
+		ctx.WithError(err).Warn("error getting initiator name")
+		return
+	}
+	vols, err := client.ListVolumes(ctx)
+	if err != nil {
+		ctx.WithError(err).Warn("error listing volumes")
+		return
+	}
+	sessions, err := iscsiSessions(ctx)
+	if err != nil {
+		ctx.WithError(err).Warn("error listing iscsi sessions")
+		return
+	}
+
+	exported := map[string]bool{}
+	for _, vol := range vols {
+		if vol.TargetIQN == "" || !vol.IsExportedTo(initiator) {
+			continue
+		}
+		target := strings.ToLower(vol.TargetIQN)
+		exported[target] = true
+
+		if s, ok := sessions[target]; ok {
+			if s.state == sessionStateLoggedIn &&
+				hasPortal(vol.Portals, s.portal) {
+				continue
+			}
+			ctx.WithFields(log.Fields{
+				"iqn":    vol.TargetIQN,
+				"portal": s.portal,
+				"state":  s.state,
+			}).Warn("recovering iscsi session")
+			if err := iscsiLogout(ctx, vol.TargetIQN, s.portal); err != nil {
+				ctx.WithError(err).Error("error logging out of iscsi target")
+				continue
+			}
+		}
+
+		if err := iscsiLoginAny(ctx, vol.TargetIQN, vol.Portals); err != nil {
+			ctx.WithField("iqn", vol.TargetIQN).WithError(err).Error(
+				"error logging in to iscsi target")
+		}
+	}
+
+	// only the sessions of the block service's targets are logged out
+	for _, vol := range vols {
+		target := strings.ToLower(vol.TargetIQN)
+		s, ok := sessions[target]
+		if !ok || exported[target] {
+			continue
+		}
+		if err := iscsiLogout(ctx, vol.TargetIQN, s.portal); err != nil {
+			ctx.WithFields(log.Fields{
+				"iqn":    vol.TargetIQN,
+				"portal": s.portal,
+			}).WithError(err).Error("error logging out of iscsi target")
+		}
+	}
+}
+
+// iscsiSessions returns the host's iSCSI sessions keyed by the lower-cased
+// IQNs of their targets.
+func iscsiSessions(ctx types.Context) (map[string]*session, error) {
+	out, err := utils.CommandContext(
+		ctx, iscsiadm, "-m", "session", "-P", "1").CombinedOutput()
+	if err != nil {
+		// iscsiadm exits with an error when there are no sessions
+		if strings.Contains(string(out), "No active sessions") {
+			return map[string]*session{}, nil
+		}
+		return nil, goof.WithFieldE(
+			"output", string(out), "error listing iscsi sessions", err)
+	}
+	return parseSessions(string(out)), nil
+}
+
+// parseSessions parses the output of "iscsiadm -m session -P 1":
+//
+//     Target: iqn.2017-01.io.example:vol1 (non-flash)
+//         Current Portal: 10.0.0.5:3260,1
+//         Persistent Portal: 10.0.0.5:3260,1
+//             ...
+//             iSCSI Session State: LOGGED_IN
+func parseSessions(out string) map[string]*session {
+	sessions := map[string]*session{}
+
+	var target string
+	var s *session
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "Target: "):
+			f := strings.Fields(line)
+			target = f[1]
+		case strings.HasPrefix(line, "Current Portal: ") && target != "":
+			portal := strings.TrimPrefix(line, "Current Portal: ")
+			s = &session{
+				target: target,
+				portal: strings.SplitN(portal, ",", 2)[0],
+			}
+			sessions[strings.ToLower(target)] = s
+		case strings.HasPrefix(line, "iSCSI Session State: ") && s != nil:
+			s.state = strings.TrimPrefix(line, "iSCSI Session State: ")
+		}
+	}
+	return sessions
+}
+
+func hasPortal(portals []string, portal string) bool {
+	for _, p := range portals {
+		if p == portal {
+			return true
+		}
+	}
+	return false
+}
+
+// iscsiLoginAny logs in to the target at the first of its portals at which
+// the login succeeds.
+func iscsiLoginAny(ctx types.Context, iqn string, portals []string) error {
+	if len(portals) == 0 {
+		return goof.WithField("iqn", iqn, "target has no portals")
+	}
+	var err error
+	for _, portal := range portals {
+		if err = iscsiLogin(ctx, iqn, portal); err == nil {
+			return nil
+		}
+		ctx.WithFields(log.Fields{
+			"iqn":    iqn,
+			"portal": portal,
+		}).WithError(err).Warn("error logging in to iscsi portal")
+	}
+	return err
+}
+
+// iscsiLogin logs in to the target, which is logged in to automatically
+// when the host boots.
+func iscsiLogin(ctx types.Context, iqn, portal string) error {
+	ctx.WithFields(log.Fields{
+		"iqn":    iqn,
+		"portal": portal,
+	}).Info("logging in to iscsi target")
+
+	for _, args := range [][]string{
+		{"-m", "node", "-o", "new", "-T", iqn, "-p", portal},
+		{"-m", "node", "-o", "update", "-T", iqn, "-p", portal,
+			"-n", "node.startup", "-v", "automatic"},
+		{"-m", "node", "-T", iqn, "-p", portal, "-l"},
+	} {
+		if err := runISCSIAdm(ctx, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// iscsiLogout logs out of the target and removes its node record.
+func iscsiLogout(ctx types.Context, iqn, portal string) error {
+	ctx.WithFields(log.Fields{
+		"iqn":    iqn,
+		"portal": portal,
+	}).Info("logging out of iscsi target")
+
+	for _, args := range [][]string{
+		{"-m", "node", "-T", iqn, "-p", portal, "-u"},
+		{"-m", "node", "-o", "delete", "-T", iqn, "-p", portal},
+	} {
+		if err := runISCSIAdm(ctx, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runISCSIAdm(ctx types.Context, args ...string) error {
+	out, err := utils.CommandContext(ctx, iscsiadm, args...).CombinedOutput()
+	if err != nil {
+		return goof.WithFieldsE(log.Fields{
+			"args":   strings.Join(args, " "),
+			"output": string(out),
+		}, "error running iscsiadm", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// +build !libstorage_storage_executor libstorage_storage_executor_iscsi
+
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sessionsOutput = `Target: iqn.2017-01.io.example:vol1 (non-flash)
+	Current Portal: 10.0.0.5:3260,1
+	Persistent Portal: 10.0.0.5:3260,1
+		**********
+		Interface:
+		**********
+		Iface Name: default
+		SID: 1
+		iSCSI Connection State: LOGGED IN
+		iSCSI Session State: LOGGED_IN
+		Internal iscsid Session State: NO CHANGE
+Target: iqn.2017-01.io.example:VOL2 (non-flash)
+	Current Portal: 10.0.0.6:3260,1
+	Persistent Portal: 10.0.0.6:3260,1
+		**********
+		Interface:
+		**********
+		Iface Name: default
+		SID: 2
+		iSCSI Connection State: TRANSPORT WAIT
+		iSCSI Session State: FAILED
+		Internal iscsid Session State: REOPEN
+`
+
+func TestParseSessions(t *testing.T) {
+	sessions := parseSessions(sessionsOutput)
+	assert.Len(t, sessions, 2)
+
+	s := sessions["iqn.2017-01.io.example:vol1"]
+	if assert.NotNil(t, s) {
+		assert.Equal(t, "10.0.0.5:3260", s.portal)
+		assert.Equal(t, sessionStateLoggedIn, s.state)
+	}
+
+	s = sessions["iqn.2017-01.io.example:vol2"]
+	if assert.NotNil(t, s) {
+		assert.Equal(t, "iqn.2017-01.io.example:VOL2", s.target)
+		assert.Equal(t, "10.0.0.6:3260", s.portal)
+		assert.Equal(t, "FAILED", s.state)
+	}
+}
+
+func TestDeviceName(t *testing.T) {
+	m := iscsiDevice.FindStringSubmatch(
+		"ip-10.0.0.5:3260-iscsi-iqn.2017-01.io.example:vol1-lun-0")
+	if assert.Len(t, m, 2) {
+		assert.Equal(t, "iqn.2017-01.io.example:vol1", m[1])
+	}
+	assert.Nil(t, iscsiDevice.FindStringSubmatch(
+		"ip-10.0.0.5:3260-iscsi-iqn.2017-01.io.example:vol1-lun-0-part1"))
+}
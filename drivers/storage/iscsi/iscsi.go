@@ -0,0 +1,115 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_iscsi
+
+package iscsi
+
+import (
+	gofigCore "github.com/akutz/gofig"
+	gofig "github.com/akutz/gofig/types"
+)
+
+const (
+	// Name is the name of the driver
+	Name = "iscsi"
+
+	// InstanceIDFieldHostname is the key used to retrieve the hostname from
+	// the instance id map
+	InstanceIDFieldHostname = "hostname"
+
+	// InstanceIDFieldInitiator is the key used to retrieve the initiator IQN
+	// from the instance id map when the instance ID is derived by the
+	// configured instance ID resolvers rather than being the IQN
+	InstanceIDFieldInitiator = "initiator"
+
+	// DefaultReplicas is the default value of ConfigISCSIReplicas
+	DefaultReplicas = 3
+
+	// DefaultSyncInterval is the default value of ConfigISCSISyncInterval
+	DefaultSyncInterval = "5s"
+
+	// VolumeOptReplicas is the volume create option that specifies the
+	// number of replicas of the volume
+	VolumeOptReplicas = "replicas"
+
+	// VolumeFieldTargetIQN is the key of the volume field that contains the
+	// IQN of the volume's iSCSI target
+	VolumeFieldTargetIQN = "targetIQN"
+
+	// VolumeFieldPortals is the key of the volume field that contains the
+	// comma-separated portals at which the volume's target is exported
+	VolumeFieldPortals = "portals"
+
+	// VolumeFieldReplicas is the key of the volume field that contains the
+	// number of replicas of the volume
+	VolumeFieldReplicas = "replicas"
+
+	// ConfigISCSIEndpoint is the key for the URL of the block service's API
+	// in the config file
+	ConfigISCSIEndpoint = Name + ".endpoint"
+
+	// ConfigISCSIToken is the key for the bearer token with which requests
+	// to the block service's API are authenticated in the config file
+	ConfigISCSIToken = Name + ".token"
+
+	// ConfigISCSIInsecure is the key for whether or not the API's
+	// certificate is verified in the config file
+	ConfigISCSIInsecure = Name + ".insecure"
+
+	// ConfigISCSIReplicas is the key for the default number of replicas of
+	// new volumes in the config file
+	ConfigISCSIReplicas = Name + ".replicas"
+
+	// ConfigISCSIInitiatorName is the key for the initiator IQN of the host
+	// in the config file, which is otherwise read from the host's
+	// initiatorname.iscsi file
+	ConfigISCSIInitiatorName = Name + ".initiatorName"
+
+	// ConfigISCSISyncInterval is the key for how often the executor
+	// synchronizes the host's iSCSI sessions with the targets exported to
+	// the host in the config file
+	ConfigISCSISyncInterval = Name + ".syncInterval"
+)
+
+func init() {
+	registerConfig()
+}
+
+func registerConfig() {
+	r := gofigCore.NewRegistration("iSCSI")
+	r.Key(
+		gofig.String,
+		"",
+		"",
+		"The URL of the block service's API",
+		ConfigISCSIEndpoint)
+	r.Key(
+		gofig.String,
+		"",
+		"",
+		"The bearer token used to authenticate with the block service",
+		ConfigISCSIToken)
+	r.Key(
+		gofig.Bool,
+		"",
+		false,
+		"A flag that indicates whether or not to skip certificate checks",
+		ConfigISCSIInsecure)
+	r.Key(
+		gofig.Int,
+		"",
+		DefaultReplicas,
+		"The default number of replicas of new volumes",
+		ConfigISCSIReplicas)
+	r.Key(
+		gofig.String,
+		"",
+		"",
+		"The initiator IQN of the host",
+		ConfigISCSIInitiatorName)
+	r.Key(
+		gofig.String,
+		"",
+		DefaultSyncInterval,
+		"How often the host's iSCSI sessions are synchronized",
+		ConfigISCSISyncInterval)
+	gofigCore.Register(r)
+}
@@ -0,0 +1,399 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_iscsi
+
+package storage
+
+import (
+	"strconv"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+
+	"github.com/codedellemc/libstorage/drivers/storage/iscsi"
+	iscsiUtils "github.com/codedellemc/libstorage/drivers/storage/iscsi/utils"
+)
+
+const defaultVolumeSize = 16
+
+type driver struct {
+	config gofig.Config
+	client *iscsiUtils.Client
+}
+
+func init() {
+	registry.RegisterStorageDriver(iscsi.Name, newDriver)
+}
+
+func newDriver() types.StorageDriver {
+	return &driver{}
+}
+
+func (d *driver) Name() string {
+	return iscsi.Name
+}
+
+func (d *driver) Init(ctx types.Context, config gofig.Config) error {
+	d.config = config
+
+	client, err := iscsiUtils.NewClient(config)
+	if err != nil {
+		return err
+	}
+	d.client = client
+
+	ctx.WithFields(log.Fields{
+		"endpoint": d.config.GetString(iscsi.ConfigISCSIEndpoint),
+		"token":    "******",
+		"insecure": d.config.GetBool(iscsi.ConfigISCSIInsecure),
+		"replicas": d.replicas(nil),
+	}).Info("storage driver initialized")
+
+	return nil
+}
+
+func (d *driver) Type(ctx types.Context) (types.StorageType, error) {
+	return types.Block, nil
+}
+
+func (d *driver) Capabilities(
+	ctx types.Context) (*types.StorageCapabilities, error) {
+	return &types.StorageCapabilities{}, nil
+}
+
+func (d *driver) NextDeviceInfo(
+	ctx types.Context) (*types.NextDeviceInfo, error) {
+	return nil, nil
+}
+
+func (d *driver) InstanceInspect(
+	ctx types.Context, opts types.Store) (*types.Instance, error) {
+
+	iid := context.MustInstanceID(ctx)
+	return &types.Instance{
+		InstanceID:   iid,
+		Name:         iid.Fields[iscsi.InstanceIDFieldHostname],
+		ProviderName: iid.Driver,
+	}, nil
+}
+
+func (d *driver) Volumes(
+	ctx types.Context, opts *types.VolumesOpts) ([]*types.Volume, error) {
+
+	vols, err := d.client.ListVolumes(ctx)
+	if err != nil {
+		return nil, goof.WithError("error listing volumes", err)
+	}
+
+	var volumes []*types.Volume
+	for _, vol := range vols {
+		v, err := d.toTypesVolume(ctx, vol, opts.Attachments)
+		if err != nil {
+			return nil, err
+		}
+		volumes = append(volumes, v)
+	}
+
+	return volumes, nil
+}
+
+func (d *driver) VolumeInspect(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeInspectOpts) (*types.Volume, error) {
+
+	vol, err := d.getVolume(ctx, volumeID)
+	if err != nil {
+		return nil, err
+	}
+	return d.toTypesVolume(ctx, vol, opts.Attachments)
+}
+
+func (d *driver) VolumeCreate(
+	ctx types.Context,
+	name string,
+	opts *types.VolumeCreateOpts) (*types.Volume, error) {
+
+	size := int64(defaultVolumeSize)
+	if opts.Size != nil && *opts.Size != 0 {
+		size = *opts.Size
+	}
+
+	replicas := d.replicas(opts.Opts)
+	if replicas < 1 {
+		return nil, utils.NewInvalidRequestError(
+			iscsi.VolumeOptReplicas, replicas, "replicas must be at least 1")
+	}
+
+	fields := log.Fields{
+		"volumeName": name,
+		"size":       size,
+		"replicas":   replicas,
+	}
+	ctx.WithFields(fields).Debug("creating volume")
+
+	vol, err := d.client.CreateVolume(ctx, &iscsiUtils.VolumeCreateRequest{
+		Name:     name,
+		Size:     size,
+		Replicas: replicas,
+	})
+	if err != nil {
+		return nil, goof.WithFieldsE(fields, "error creating volume", err)
+	}
+
+	return d.toTypesVolume(ctx, vol, types.VolAttReqTrue)
+}
+
+func (d *driver) VolumeCreateFromSnapshot(
+	ctx types.Context,
+	snapshotID, volumeName string,
+	opts *types.VolumeCreateOpts) (*types.Volume, error) {
+	return nil, types.ErrNotImplemented
+}
+
+func (d *driver) VolumeCopy(
+	ctx types.Context,
+	volumeID, volumeName string,
+	opts types.Store) (*types.Volume, error) {
+	return nil, types.ErrNotImplemented
+}
+
+func (d *driver) VolumeSnapshot(
+	ctx types.Context,
+	volumeID, snapshotName string,
+	opts types.Store) (*types.Snapshot, error) {
+	return nil, types.ErrNotImplemented
+}
+
+func (d *driver) VolumeRemove(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeRemoveOpts) error {
+
+	vol, err := d.getVolume(ctx, volumeID)
+	if err != nil {
+		return err
+	}
+
+	if len(vol.Initiators) > 0 {
+		if !opts.Force {
+			return utils.NewVolumeInUseError(volumeID)
+		}
+		for _, initiator := range vol.Initiators {
+			if err := d.unexport(ctx, volumeID, initiator); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := d.client.DeleteVolume(ctx, volumeID); err != nil {
+		return goof.WithFieldE(
+			"volumeID", volumeID, "error removing volume", err)
+	}
+	return nil
+}
+
+// VolumeAttach exports the volume's target to the initiator that requests
+// the volume. The executor logs in to the target while it waits for the
+// volume's device.
+func (d *driver) VolumeAttach(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeAttachOpts) (*types.Volume, string, error) {
+
+	vol, err := d.getVolume(ctx, volumeID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	initiator := iscsiUtils.Initiator(context.MustInstanceID(ctx))
+	if vol.IsExportedTo(initiator) {
+		return nil, "", goof.New("volume already attached to instance")
+	}
+	if len(vol.Initiators) > 0 {
+		if !opts.Force {
+			return nil, "", goof.New("volume already attached")
+		}
+		for _, i := range vol.Initiators {
+			if err := d.unexport(ctx, volumeID, i); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+
+	fields := log.Fields{
+		"volumeID":  volumeID,
+		"initiator": initiator,
+	}
+	ctx.WithFields(fields).Debug("attaching volume")
+
+	vol, err = d.client.ExportVolume(ctx, volumeID, initiator)
+	if err != nil {
+		return nil, "", goof.WithFieldsE(fields, "error attaching volume", err)
+	}
+	if vol.TargetIQN == "" {
+		return nil, "", goof.WithFields(fields, "volume has no target iqn")
+	}
+
+	attachedVol, err := d.toTypesVolume(ctx, vol, types.VolAttReqTrue)
+	if err != nil {
+		return nil, "", goof.WithError("error getting volume", err)
+	}
+
+	// the executor maps the IQNs of the targets to their devices
+	return attachedVol, strings.ToLower(vol.TargetIQN), nil
+}
+
+func (d *driver) VolumeDetach(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeDetachOpts) (*types.Volume, error) {
+
+	vol, err := d.getVolume(ctx, volumeID)
+	if err != nil {
+		return nil, err
+	}
+
+	initiator := iscsiUtils.Initiator(context.MustInstanceID(ctx))
+	if !vol.IsExportedTo(initiator) {
+		return nil, goof.New("volume already detached")
+	}
+	if err := d.unexport(ctx, volumeID, initiator); err != nil {
+		return nil, err
+	}
+
+	return d.VolumeInspect(ctx, volumeID, &types.VolumeInspectOpts{
+		Attachments: types.VolAttReqTrue,
+		Opts:        opts.Opts,
+	})
+}
+
+func (d *driver) Snapshots(
+	ctx types.Context, opts types.Store) ([]*types.Snapshot, error) {
+	return nil, types.ErrNotImplemented
+}
+
+func (d *driver) SnapshotInspect(
+	ctx types.Context,
+	snapshotID string,
+	opts types.Store) (*types.Snapshot, error) {
+	return nil, types.ErrNotImplemented
+}
+
+func (d *driver) SnapshotCopy(
+	ctx types.Context,
+	snapshotID, snapshotName, destinationID string,
+	opts types.Store) (*types.Snapshot, error) {
+	return nil, types.ErrNotImplemented
+}
+
+func (d *driver) SnapshotRemove(
+	ctx types.Context, snapshotID string, opts types.Store) error {
+	return types.ErrNotImplemented
+}
+
+func (d *driver) getVolume(
+	ctx types.Context, volumeID string) (*iscsiUtils.Volume, error) {
+
+	vol, err := d.client.GetVolume(ctx, volumeID)
+	if err != nil {
+		if iscsiUtils.IsNotFound(err) {
+			return nil, utils.NewVolumeNotFoundError(volumeID)
+		}
+		return nil, goof.WithFieldE(
+			"volumeID", volumeID, "error getting volume", err)
+	}
+	return vol, nil
+}
+
+func (d *driver) unexport(
+	ctx types.Context, volumeID, initiator string) error {
+
+	fields := log.Fields{
+		"volumeID":  volumeID,
+		"initiator": initiator,
+	}
+	ctx.WithFields(fields).Debug("detaching volume")
+
+	if _, err := d.client.UnexportVolume(
+		ctx, volumeID, initiator); err != nil {
+		return goof.WithFieldsE(fields, "error detaching volume", err)
+	}
+	return nil
+}
+
+func (d *driver) toTypesVolume(
+	ctx types.Context,
+	vol *iscsiUtils.Volume,
+	attachments types.VolumeAttachmentsTypes) (*types.Volume, error) {
+
+	status := vol.Status
+	if status == "" {
+		status = "detached"
+		if len(vol.Initiators) > 0 {
+			status = "attached"
+		}
+	}
+
+	volume := &types.Volume{
+		Name:   vol.Name,
+		ID:     vol.ID,
+		Size:   vol.Size,
+		Status: status,
+		Fields: map[string]string{
+			iscsi.VolumeFieldTargetIQN: vol.TargetIQN,
+			iscsi.VolumeFieldPortals:   strings.Join(vol.Portals, ","),
+			iscsi.VolumeFieldReplicas:  strconv.Itoa(vol.Replicas),
+		},
+	}
+
+	if !attachments.Requested() {
+		return volume, nil
+	}
+
+	// the attachment to the instance in the context is reported with its
+	// instance ID, which may be a resolved ID rather than its IQN
+	iid, iidOK := context.InstanceID(ctx)
+	for _, initiator := range vol.Initiators {
+		att := &types.VolumeAttachment{
+			VolumeID: vol.ID,
+			InstanceID: &types.InstanceID{
+				ID:     initiator,
+				Driver: iscsi.Name,
+			},
+		}
+		if iidOK && iscsiUtils.Initiator(iid) == initiator {
+			att.InstanceID = &types.InstanceID{
+				ID:     iid.ID,
+				Driver: iscsi.Name,
+			}
+		}
+		if attachments.Devices() {
+			ld, ok := context.LocalDevices(ctx)
+			if !ok {
+				return nil, goof.New(
+					"error getting local devices from context")
+			}
+			iqn := strings.ToLower(vol.TargetIQN)
+			if dev, ok := ld.DeviceMap[iqn]; ok {
+				att.DeviceName = dev
+				att.BusType = "scsi"
+			}
+		}
+		volume.Attachments = append(volume.Attachments, att)
+	}
+
+	return volume, nil
+}
+
+func (d *driver) replicas(opts types.Store) int {
+	if opts != nil && opts.IsSet(iscsi.VolumeOptReplicas) {
+		return opts.GetInt(iscsi.VolumeOptReplicas)
+	}
+	return d.config.GetInt(iscsi.ConfigISCSIReplicas)
+}
@@ -0,0 +1,2 @@
+ISCSI_COVERPKG := $(ROOT_IMPORT_PATH)/drivers/storage/iscsi
+TEST_COVERPKG_./drivers/storage/iscsi/tests := $(ISCSI_COVERPKG),$(ISCSI_COVERPKG)/executor
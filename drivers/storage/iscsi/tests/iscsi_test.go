@@ -0,0 +1,212 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_iscsi
+
+package iscsi
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	log "github.com/Sirupsen/logrus"
+	gofigCore "github.com/akutz/gofig"
+	gofig "github.com/akutz/gofig/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/server"
+	apitests "github.com/codedellemc/libstorage/api/tests"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+	"github.com/codedellemc/libstorage/drivers/storage/iscsi"
+	iscsiUtils "github.com/codedellemc/libstorage/drivers/storage/iscsi/utils"
+)
+
+var (
+	configYAML = []byte(`
+iscsi:
+  endpoint: http://127.0.0.1:9500
+  replicas: 2`)
+)
+
+func skipTests() bool {
+	travis, _ := strconv.ParseBool(os.Getenv("TRAVIS"))
+	noTest, _ := strconv.ParseBool(os.Getenv("TEST_SKIP_ISCSI"))
+	return travis || noTest
+}
+
+var volumeName string
+
+func init() {
+	uuid, _ := types.NewUUID()
+	volumeName = "ls-" + strings.Split(uuid.String(), "-")[0]
+}
+
+func TestMain(m *testing.M) {
+	server.CloseOnAbort()
+	ec := m.Run()
+	os.Exit(ec)
+}
+
+func TestConfig(t *testing.T) {
+	if skipTests() {
+		t.SkipNow()
+	}
+
+	tf := func(config gofig.Config, client types.Client, t *testing.T) {
+		assert.NotEqual(t, config.GetString(iscsi.ConfigISCSIEndpoint), "")
+		assert.Equal(t, 2, config.GetInt(iscsi.ConfigISCSIReplicas))
+		assert.Equal(t, iscsi.DefaultSyncInterval,
+			config.GetString(iscsi.ConfigISCSISyncInterval))
+	}
+
+	apitests.Run(t, iscsi.Name, configYAML, tf)
+}
+
+func TestInstanceID(t *testing.T) {
+	if skipTests() {
+		t.SkipNow()
+	}
+
+	sd, err := registry.NewStorageDriver(iscsi.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	config := gofigCore.New()
+	if err := config.ReadConfig(bytes.NewReader(configYAML)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sd.Init(ctx, config); err != nil {
+		t.Fatal(err)
+	}
+
+	iid, err := iscsiUtils.InstanceID(ctx, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx = ctx.WithValue(context.InstanceIDKey, iid)
+	i, err := sd.InstanceInspect(ctx, utils.NewStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iid = i.InstanceID
+	apitests.Run(
+		t, iscsi.Name, nil,
+		(&apitests.InstanceIDTest{
+			Driver:   iscsi.Name,
+			Expected: iid,
+		}).Test)
+}
+
+func TestServices(t *testing.T) {
+	if skipTests() {
+		t.SkipNow()
+	}
+
+	tf := func(config gofig.Config, client types.Client, t *testing.T) {
+		reply, err := client.API().Services(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, len(reply), 1)
+
+		_, ok := reply[iscsi.Name]
+		assert.True(t, ok)
+	}
+
+	apitests.Run(t, iscsi.Name, configYAML, tf)
+}
+
+func TestVolumeCreateRemove(t *testing.T) {
+	if skipTests() {
+		t.SkipNow()
+	}
+
+	tf := func(config gofig.Config, client types.Client, t *testing.T) {
+		vol := volumeCreate(t, client, volumeName)
+		volumeRemove(t, client, vol.ID)
+	}
+
+	apitests.Run(t, iscsi.Name, configYAML, tf)
+}
+
+func TestVolumeAttach(t *testing.T) {
+	if skipTests() {
+		t.SkipNow()
+	}
+
+	tf := func(config gofig.Config, client types.Client, t *testing.T) {
+		vol := volumeCreate(t, client, volumeName)
+		_ = volumeAttach(t, client, vol.ID)
+		_ = volumeDetach(t, client, vol.ID)
+		volumeRemove(t, client, vol.ID)
+	}
+
+	apitests.Run(t, iscsi.Name, configYAML, tf)
+}
+
+func volumeCreate(
+	t *testing.T, client types.Client, volumeName string) *types.Volume {
+	log.WithField("volumeName", volumeName).Info("creating volume")
+
+	size := int64(1)
+	reply, err := client.API().VolumeCreate(
+		nil, iscsi.Name, &types.VolumeCreateRequest{
+			Name: volumeName,
+			Size: &size,
+		})
+	assert.NoError(t, err)
+	if err != nil {
+		t.FailNow()
+	}
+	apitests.LogAsJSON(reply, t)
+
+	assert.Equal(t, volumeName, reply.Name)
+	assert.Equal(t, size, reply.Size)
+	return reply
+}
+
+func volumeRemove(t *testing.T, client types.Client, volumeID string) {
+	log.WithField("volumeID", volumeID).Info("removing volume")
+	err := client.API().VolumeRemove(nil, iscsi.Name, volumeID, false)
+	assert.NoError(t, err)
+	if err != nil {
+		t.FailNow()
+	}
+}
+
+func volumeAttach(
+	t *testing.T, client types.Client, volumeID string) *types.Volume {
+	log.WithField("volumeID", volumeID).Info("attaching volume")
+
+	reply, token, err := client.API().VolumeAttach(
+		nil, iscsi.Name, volumeID, &types.VolumeAttachRequest{})
+	assert.NoError(t, err)
+	if err != nil {
+		t.FailNow()
+	}
+	apitests.LogAsJSON(reply, t)
+	assert.Equal(t,
+		strings.ToLower(reply.Fields[iscsi.VolumeFieldTargetIQN]), token)
+	assert.Len(t, reply.Attachments, 1)
+	return reply
+}
+
+func volumeDetach(
+	t *testing.T, client types.Client, volumeID string) *types.Volume {
+	log.WithField("volumeID", volumeID).Info("detaching volume")
+
+	reply, err := client.API().VolumeDetach(
+		nil, iscsi.Name, volumeID, &types.VolumeDetachRequest{})
+	assert.NoError(t, err)
+	if err != nil {
+		t.FailNow()
+	}
+	apitests.LogAsJSON(reply, t)
+	assert.Len(t, reply.Attachments, 0)
+	return reply
+}
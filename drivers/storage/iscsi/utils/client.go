@@ -0,0 +1,248 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_iscsi
+
+package utils
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/drivers/storage/iscsi"
+)
+
+// Client is a client of the block service's API. The API is a small REST
+// API that provisions replicated volumes and exports each volume as an
+// iSCSI target to the initiators to which it is attached:
+//
+//     GET    /volumes
+//     POST   /volumes
+//     GET    /volumes/{id}
+//     DELETE /volumes/{id}
+//     POST   /volumes/{id}/export
+//     POST   /volumes/{id}/unexport
+type Client struct {
+	endpoint string
+	token    string
+	client   *http.Client
+}
+
+// Volume is a volume of the block service.
+type Volume struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Size     int64  `json:"size"`
+	Replicas int    `json:"replicas"`
+	Status   string `json:"status"`
+
+	// TargetIQN is the IQN of the volume's target.
+	TargetIQN string `json:"targetIQN"`
+
+	// Portals are the addresses, ex. "10.0.0.5:3260", at which the target
+	// is exported, in order of preference. The portals of a replicated
+	// volume may change when the service fails the volume over to another
+	// replica.
+	Portals []string `json:"portals"`
+
+	// Initiators are the IQNs of the initiators to which the target is
+	// exported.
+	Initiators []string `json:"initiators"`
+}
+
+// IsExportedTo returns a flag indicating whether or not the volume's target
+// is exported to the initiator.
+func (v *Volume) IsExportedTo(initiator string) bool {
+	for _, i := range v.Initiators {
+		if strings.EqualFold(i, initiator) {
+			return true
+		}
+	}
+	return false
+}
+
+// VolumeCreateRequest is the body of a request to create a volume.
+type VolumeCreateRequest struct {
+	Name     string `json:"name"`
+	Size     int64  `json:"size"`
+	Replicas int    `json:"replicas,omitempty"`
+}
+
+type exportRequest struct {
+	Initiator string `json:"initiator"`
+}
+
+// apiError is an error returned by the block service's API.
+type apiError struct {
+	status  int
+	Message string `json:"message"`
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("iscsi: %s", e.Message)
+}
+
+// IsNotFound returns a flag indicating whether or not the error is the
+// API's reply to a request for a resource that does not exist.
+func IsNotFound(err error) bool {
+	aerr, ok := err.(*apiError)
+	return ok && aerr.status == http.StatusNotFound
+}
+
+// NewClient returns a client of the API at the configured endpoint.
+func NewClient(config gofig.Config) (*Client, error) {
+	endpoint := config.GetString(iscsi.ConfigISCSIEndpoint)
+	if endpoint == "" {
+		return nil, goof.New("iscsi.endpoint is required")
+	}
+	if _, err := url.Parse(endpoint); err != nil {
+		return nil, goof.WithFieldE(
+			"endpoint", endpoint, "invalid endpoint", err)
+	}
+
+	client := http.DefaultClient
+	if config.GetBool(iscsi.ConfigISCSIInsecure) {
+		client = &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+		}
+	}
+
+	return &Client{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		token:    config.GetString(iscsi.ConfigISCSIToken),
+		client:   client,
+	}, nil
+}
+
+// do sends the request to the API and decodes the reply into v.
+func (c *Client) do(
+	ctx types.Context,
+	method, path string,
+	body, v interface{}) error {
+
+	var r io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(buf)
+	}
+
+	req, err := http.NewRequest(method, c.endpoint+path, r)
+	if err != nil {
+		return err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	req.Header.Set("User-Agent", "libstorage/"+api.Version.SemVer)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := DoRequestWithClient(ctx, c.client, req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		aerr := &apiError{}
+		json.NewDecoder(res.Body).Decode(aerr)
+		if aerr.Message == "" {
+			aerr.Message = res.Status
+		}
+		aerr.status = res.StatusCode
+		return aerr
+	}
+
+	if v == nil || res.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// ListVolumes returns the service's volumes.
+func (c *Client) ListVolumes(ctx types.Context) ([]*Volume, error) {
+	var vols []*Volume
+	if err := c.do(ctx, http.MethodGet, "/volumes", nil, &vols); err != nil {
+		return nil, err
+	}
+	return vols, nil
+}
+
+// GetVolume returns the volume with the given ID.
+func (c *Client) GetVolume(
+	ctx types.Context, volumeID string) (*Volume, error) {
+
+	vol := &Volume{}
+	if err := c.do(
+		ctx, http.MethodGet, volumePath(volumeID), nil, vol); err != nil {
+		return nil, err
+	}
+	return vol, nil
+}
+
+// CreateVolume creates a volume.
+func (c *Client) CreateVolume(
+	ctx types.Context, createReq *VolumeCreateRequest) (*Volume, error) {
+
+	vol := &Volume{}
+	if err := c.do(
+		ctx, http.MethodPost, "/volumes", createReq, vol); err != nil {
+		return nil, err
+	}
+	return vol, nil
+}
+
+// DeleteVolume deletes the volume with the given ID.
+func (c *Client) DeleteVolume(ctx types.Context, volumeID string) error {
+	return c.do(ctx, http.MethodDelete, volumePath(volumeID), nil, nil)
+}
+
+// ExportVolume exports the volume's target to the initiator.
+func (c *Client) ExportVolume(
+	ctx types.Context, volumeID, initiator string) (*Volume, error) {
+
+	vol := &Volume{}
+	if err := c.do(
+		ctx,
+		http.MethodPost,
+		volumePath(volumeID)+"/export",
+		&exportRequest{Initiator: initiator},
+		vol); err != nil {
+		return nil, err
+	}
+	return vol, nil
+}
+
+// UnexportVolume stops exporting the volume's target to the initiator.
+func (c *Client) UnexportVolume(
+	ctx types.Context, volumeID, initiator string) (*Volume, error) {
+
+	vol := &Volume{}
+	if err := c.do(
+		ctx,
+		http.MethodPost,
+		volumePath(volumeID)+"/unexport",
+		&exportRequest{Initiator: initiator},
+		vol); err != nil {
+		return nil, err
+	}
+	return vol, nil
+}
+
+func volumePath(volumeID string) string {
+	return "/volumes/" + volumeID
+}
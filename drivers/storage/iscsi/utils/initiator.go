@@ -0,0 +1,108 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_iscsi
+
+package utils
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"strings"
+
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/types"
+	apiutils "github.com/codedellemc/libstorage/api/utils"
+	"github.com/codedellemc/libstorage/drivers/storage/iscsi"
+)
+
+const (
+	initiatorNameFile = "/etc/iscsi/initiatorname.iscsi"
+	initiatorNameKey  = "InitiatorName="
+)
+
+// InitiatorName returns the initiator IQN of the host, which is the
+// configured initiator name or else the name in the host's
+// initiatorname.iscsi file.
+func InitiatorName(config gofig.Config) (string, error) {
+	if config != nil {
+		if v := config.GetString(iscsi.ConfigISCSIInitiatorName); v != "" {
+			return v, nil
+		}
+	}
+
+	f, err := os.Open(initiatorNameFile)
+	if err != nil {
+		return "", goof.WithError("error reading initiator name", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, initiatorNameKey) {
+			return strings.TrimPrefix(line, initiatorNameKey), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", goof.WithError("error reading initiator name", err)
+	}
+	return "", goof.WithField(
+		"path", initiatorNameFile, "initiator name not found")
+}
+
+// InstanceID returns the host's instance ID, which is its initiator IQN,
+// since the block service exports targets to initiators. If instance ID
+// resolvers are configured then the instance ID is the resolved ID instead,
+// and the IQN is recorded in the instance ID's initiator field.
+func InstanceID(
+	ctx types.Context, config gofig.Config) (*types.InstanceID, error) {
+
+	iqn, err := InitiatorName(config)
+	if err != nil {
+		return nil, err
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+	iid := &types.InstanceID{
+		ID:     iqn,
+		Driver: iscsi.Name,
+		Fields: map[string]string{
+			iscsi.InstanceIDFieldHostname: hostname,
+		},
+	}
+	if config == nil {
+		return iid, nil
+	}
+	id, err := apiutils.ResolveInstanceID(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	if id != "" {
+		iid.ID = id
+		iid.Fields[iscsi.InstanceIDFieldInitiator] = iqn
+	}
+	return iid, nil
+}
+
+// Initiator returns the initiator IQN of the instance.
+func Initiator(iid *types.InstanceID) string {
+	if v := iid.Fields[iscsi.InstanceIDFieldInitiator]; v != "" {
+		return v
+	}
+	return iid.ID
+}
+
+// IsInitiator is a simple check to see if code is being executed on a host
+// with the iSCSI initiator utilities installed or not.
+func IsInitiator(ctx types.Context, config gofig.Config) (bool, error) {
+	if _, err := exec.LookPath("iscsiadm"); err != nil {
+		return false, nil
+	}
+	if _, err := InitiatorName(config); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,19 @@
+// +build go1.7
+// +build !libstorage_storage_driver libstorage_storage_driver_iscsi
+
+package utils
+
+import (
+	"net/http"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// DoRequestWithClient sends the request with the context and client.
+func DoRequestWithClient(
+	ctx types.Context,
+	client *http.Client,
+	req *http.Request) (*http.Response, error) {
+	req = req.WithContext(ctx)
+	return client.Do(req)
+}
@@ -0,0 +1,20 @@
+// +build !go1.7
+// +build !libstorage_storage_driver libstorage_storage_driver_iscsi
+
+package utils
+
+import (
+	"net/http"
+
+	"golang.org/x/net/context/ctxhttp"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// DoRequestWithClient sends the request with the context and client.
+func DoRequestWithClient(
+	ctx types.Context,
+	client *http.Client,
+	req *http.Request) (*http.Response, error) {
+	return ctxhttp.Do(ctx, client, req)
+}
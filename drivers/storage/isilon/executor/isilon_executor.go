@@ -16,6 +16,7 @@ import (
 
 	"github.com/codedellemc/libstorage/api/registry"
 	"github.com/codedellemc/libstorage/api/types"
+	apiutils "github.com/codedellemc/libstorage/api/utils"
 	"github.com/codedellemc/libstorage/drivers/storage/isilon"
 )
 
@@ -82,6 +83,17 @@ func (d *driver) InstanceID(
 		return nil, err
 	}
 
+	// volumes are exported to the instance's IP addresses, which remain in
+	// the metadata if the instance's ID is derived by the instance ID
+	// resolvers
+	if d.config != nil {
+		id, err := apiutils.ResolveInstanceID(ctx, d.config)
+		if err != nil {
+			return nil, err
+		}
+		iid.ID = id
+	}
+
 	return iid, nil
 }
 
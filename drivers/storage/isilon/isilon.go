@@ -5,11 +5,24 @@ package isilon
 import (
 	gofigCore "github.com/akutz/gofig"
 	gofig "github.com/akutz/gofig/types"
+
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
 )
 
 const (
 	// Name is the provider's name.
 	Name = "isilon"
+
+	// VolumeFieldUsage is the name of the volume field in which the number
+	// of bytes of data in a volume's directory, as counted by the volume's
+	// SmartQuota, is reported.
+	VolumeFieldUsage = "usage"
+
+	// VolumeFieldPhysicalUsage is the name of the volume field in which the
+	// number of bytes a volume's directory consumes on disk, including
+	// protection overhead, is reported.
+	VolumeFieldPhysicalUsage = "physicalUsage"
 )
 
 func init() {
@@ -25,4 +38,28 @@ func init() {
 	r.Key(gofig.Bool, "", false, "", "isilon.quotas")
 	r.Key(gofig.Bool, "", false, "", "isilon.sharedMounts")
 	gofigCore.Register(r)
+
+	registry.RegisterConfigSchema(&types.ConfigSchema{
+		Name:       "Isilon",
+		Namespaces: []string{Name},
+		Keys: map[string]types.ConfigKeyType{
+			"isilon.endpoint":     types.ConfigKeyString,
+			"isilon.insecure":     types.ConfigKeyBool,
+			"isilon.userName":     types.ConfigKeyString,
+			"isilon.group":        types.ConfigKeyString,
+			"isilon.password":     types.ConfigKeyString,
+			"isilon.volumePath":   types.ConfigKeyString,
+			"isilon.nfsHost":      types.ConfigKeyString,
+			"isilon.dataSubnet":   types.ConfigKeyString,
+			"isilon.quotas":       types.ConfigKeyBool,
+			"isilon.sharedMounts": types.ConfigKeyBool,
+		},
+		StorageDriver: Name,
+		Required: []string{
+			"isilon.endpoint",
+			"isilon.userName",
+			"isilon.password",
+			"isilon.volumePath",
+		},
+	})
 }
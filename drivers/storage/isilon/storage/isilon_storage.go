@@ -5,6 +5,7 @@ package storage
 import (
 	"fmt"
 	"net"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/codedellemc/libstorage/api/context"
 	"github.com/codedellemc/libstorage/api/registry"
 	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
 	"github.com/codedellemc/libstorage/drivers/storage/isilon"
 )
 
@@ -115,6 +117,18 @@ func (d *driver) getInstanceID(ctx types.Context) (string, error) {
 	return createInstanceID(idList), nil
 }
 
+// exportClient returns the NFS client to which volumes are exported for the
+// instance in the context. The client is derived from the instance's IP
+// addresses on the data subnet, even if the instance's ID is resolved by the
+// executor's instance ID resolvers.
+func (d *driver) exportClient(ctx types.Context) (string, error) {
+	iid := context.MustInstanceID(ctx)
+	if iid.HasMetadata() {
+		return d.getInstanceID(ctx)
+	}
+	return iid.ID, nil
+}
+
 // Create an instance ID from a list of client IP addresses
 func createInstanceID(clients []string) string {
 	return strings.Join(clients, idDelimiter)
@@ -169,6 +183,16 @@ func (d *driver) NextDeviceInfo(
 	return nil, nil
 }
 
+// Capabilities returns the capabilities of the driver's storage platform.
+func (d *driver) Capabilities(
+	ctx types.Context) (*types.StorageCapabilities, error) {
+
+	return &types.StorageCapabilities{
+		Resize:      d.quotas(),
+		MultiAttach: true,
+	}, nil
+}
+
 func (d *driver) getVolumeAttachments(
 	ctx types.Context,
 	attachments types.VolumeAttachmentsTypes) (
@@ -183,11 +207,18 @@ func (d *driver) getVolumeAttachments(
 		return nil, err
 	}
 
-	iid, iidOK := context.InstanceID(ctx)
 	var (
-		ld   *types.LocalDevices
-		ldOK bool
+		iid    *types.InstanceID
+		iidOK  bool
+		client string
+		ld     *types.LocalDevices
+		ldOK   bool
 	)
+	if iid, iidOK = context.InstanceID(ctx); iidOK {
+		if client, err = d.exportClient(ctx); err != nil {
+			ctx.WithError(err).Debug("error getting export client")
+		}
+	}
 	if attachments.Devices() {
 		ld, ldOK = context.LocalDevices(ctx)
 	}
@@ -199,7 +230,8 @@ func (d *driver) getVolumeAttachments(
 			status string
 		)
 		for _, c := range export.Clients {
-			if iidOK && ldOK && strings.EqualFold(c, iid.ID) {
+			local := iidOK && strings.EqualFold(c, client)
+			if local && ldOK {
 				dev = d.nfsMountPath(export.ExportPath)
 				if _, ok := ld.DeviceMap[dev]; ok {
 					status = "Exported and Mounted"
@@ -209,9 +241,15 @@ func (d *driver) getVolumeAttachments(
 			} else {
 				status = "Exported"
 			}
+			// the export to the instance in the context is reported with
+			// the instance's ID, which may be a resolved ID
+			id := c
+			if local && iid.ID != "" {
+				id = iid.ID
+			}
 			attachmentSD := &types.VolumeAttachment{
 				VolumeID:   export.Volume.Name,
-				InstanceID: &types.InstanceID{ID: c, Driver: d.Name()},
+				InstanceID: &types.InstanceID{ID: id, Driver: d.Name()},
 				DeviceName: dev,
 				Status:     status,
 			}
@@ -271,28 +309,16 @@ func (d *driver) VolumeCreate(ctx types.Context, volumeName string,
 			"volumeName", volumeName, "Error creating volume", err)
 	}
 
-	// Set or update the quota for volume
-	if d.quotas() {
-		quota, err := d.client.GetQuota(ctx, volumeName)
-		if quota == nil {
-			// PAPI uses bytes for it's size units, but REX-Ray uses gigs
-			err = d.client.SetQuotaSize(ctx, volumeName, *opts.Size*bytesPerGb)
-			if err != nil {
-				// TODO: not sure how to handle this situation. Delete created
-				// volume and return an error?  Ignore and continue?
-				return nil, goof.WithFieldE("volumeName", volumeName,
-					"Error creating volume", err)
-			}
-		} else {
-			// PAPI uses bytes for it's size units, but REX-Ray uses gigs
-			err = d.client.UpdateQuotaSize(
-				ctx, volumeName, *opts.Size*bytesPerGb)
-			if err != nil {
-				// TODO: not sure how to handle this situation. Delete created
-				// volume and return an error?  Ignore and continue?
-				return nil, goof.WithFieldE("volumeName", volumeName,
-					"Error creating volume", err)
+	// Set or update the quota for volume. A volume whose quota cannot be
+	// set is removed so that it is not left without size enforcement.
+	if d.quotas() && opts.Size != nil && *opts.Size > 0 {
+		if err := d.setQuotaSize(ctx, volumeName, *opts.Size); err != nil {
+			if err := d.client.ForceDeleteVolume(ctx, volumeName); err != nil {
+				ctx.WithField("volumeName", volumeName).WithError(err).Error(
+					"error removing volume without quota")
 			}
+			return nil, goof.WithFieldE("volumeName", volumeName,
+				"Error creating volume", err)
 		}
 	}
 
@@ -300,6 +326,57 @@ func (d *driver) VolumeCreate(ctx types.Context, volumeName string,
 		&types.VolumeInspectOpts{Attachments: 0})
 }
 
+// VolumeResize changes the size of the volume's SmartQuota. A volume cannot
+// be shrunk below the amount of data in its directory.
+func (d *driver) VolumeResize(
+	ctx types.Context,
+	volumeID string,
+	size int64,
+	opts types.Store) (*types.Volume, error) {
+
+	if !d.quotas() {
+		return nil, types.ErrNotImplemented
+	}
+
+	vols, err := d.getVolume(ctx, volumeID, "", types.VolAttNone)
+	if err != nil {
+		return nil, err
+	}
+	if vols == nil {
+		return nil, utils.NewVolumeNotFoundError(volumeID)
+	}
+
+	if quota, _ := d.client.GetQuota(ctx, volumeID); quota != nil &&
+		quota.Usage.Logical > size*bytesPerGb {
+		return nil, utils.NewQuotaExceededError(
+			volumeID, size*bytesPerGb, quota.Usage.Logical)
+	}
+
+	fields := log.Fields{
+		"volumeID": volumeID,
+		"size":     size,
+	}
+	ctx.WithFields(fields).Debug("resizing volume quota")
+	if err := d.setQuotaSize(ctx, volumeID, size); err != nil {
+		return nil, goof.WithFieldsE(fields, "error resizing volume", err)
+	}
+
+	return d.VolumeInspect(ctx, volumeID,
+		&types.VolumeInspectOpts{Attachments: types.VolAttNone})
+}
+
+// setQuotaSize creates or updates the SmartQuota of the volume's directory
+// with a hard threshold of the size in GiB.
+func (d *driver) setQuotaSize(
+	ctx types.Context, volumeName string, size int64) error {
+
+	// PAPI uses bytes for it's size units, but REX-Ray uses gigs
+	if quota, _ := d.client.GetQuota(ctx, volumeName); quota == nil {
+		return d.client.SetQuotaSize(ctx, volumeName, size*bytesPerGb)
+	}
+	return d.client.UpdateQuotaSize(ctx, volumeName, size*bytesPerGb)
+}
+
 // VolumeRemove removes a volume.
 func (d *driver) VolumeRemove(
 	ctx types.Context,
@@ -338,7 +415,7 @@ func (d *driver) VolumeAttach(
 	d.Lock()
 	defer d.Unlock()
 
-	instanceID, err := d.InstanceInspect(ctx, nil)
+	client, err := d.exportClient(ctx)
 	if err != nil {
 		return nil, "", err
 	}
@@ -367,7 +444,7 @@ func (d *driver) VolumeAttach(
 	// we have existing clients, we need to exit.
 	if len(clients) > 0 && !d.sharedMounts() && opts.Force == false {
 		for _, c := range clients {
-			if c == instanceID.InstanceID.ID {
+			if c == client {
 				return nil, "", goof.New("volume already attached to instance")
 			}
 		}
@@ -376,9 +453,9 @@ func (d *driver) VolumeAttach(
 	}
 
 	if d.sharedMounts() {
-		clients = append(clients, instanceID.InstanceID.ID)
+		clients = append(clients, client)
 	} else {
-		clients = []string{instanceID.InstanceID.ID}
+		clients = []string{client}
 	}
 
 	log.WithField("clients", clients).Info("setting exports")
@@ -433,7 +510,7 @@ func (d *driver) VolumeDetach(
 		return nil, goof.New("no volumes returned")
 	}
 
-	instanceID, err := d.InstanceInspect(ctx, nil)
+	client, err := d.exportClient(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -450,7 +527,7 @@ func (d *driver) VolumeDetach(
 
 	var newClients []string
 	for _, c := range clients {
-		if c != instanceID.InstanceID.ID {
+		if c != client {
 			newClients = append(newClients, c)
 		}
 	}
@@ -579,15 +656,11 @@ func (d *driver) getVolume(
 
 	var volumesSD []*types.Volume
 	for _, volume := range volumes {
-		volSize, err := d.getSize(ctx, volume.Name, volume.Name)
-		if err != nil {
-			return nil, err
-		}
 		volumeSD := &types.Volume{
 			Name: volume.Name,
 			ID:   volume.Name,
-			Size: volSize,
 		}
+		d.setQuotaFields(ctx, volumeSD)
 		if attachments.Requested() {
 			if vatts, ok := attMap[volume.Name]; ok {
 				volumeSD.Attachments = vatts
@@ -599,31 +672,31 @@ func (d *driver) getVolume(
 	return volumesSD, nil
 }
 
-func (d *driver) getSize(
-	ctx types.Context, volumeID, volumeName string) (int64, error) {
+// setQuotaFields sets the volume's size to the hard threshold of its
+// SmartQuota and reports the quota's usage in the volume's fields. A volume
+// without a quota is left unchanged.
+func (d *driver) setQuotaFields(ctx types.Context, vol *types.Volume) {
 
-	if d.quotas() == false {
-		return 0, nil
+	if !d.quotas() {
+		return
 	}
 
-	if volumeID != "" {
-		volumeName = volumeID
-	}
-	if volumeName == "" {
-		return 0, goof.New("volume name or ID not set")
+	quota, err := d.client.GetQuota(ctx, vol.Name)
+	if err != nil || quota == nil {
+		return
 	}
 
-	quota, err := d.client.GetQuota(ctx, volumeName)
-	if err != nil {
-		return 0, nil
-	}
 	// PAPI returns the size in bytes, REX-Ray uses gigs
 	if quota.Thresholds.Hard != 0 {
-		return quota.Thresholds.Hard / bytesPerGb, nil
+		vol.Size = quota.Thresholds.Hard / bytesPerGb
 	}
 
-	return 0, nil
-
+	vol.Fields = map[string]string{
+		isilon.VolumeFieldUsage: strconv.FormatInt(
+			quota.Usage.Logical, 10),
+		isilon.VolumeFieldPhysicalUsage: strconv.FormatInt(
+			quota.Usage.Physical, 10),
+	}
 }
 
 type isiVolExport struct {
@@ -313,3 +313,25 @@ func TestVolumeAttach(t *testing.T) {
 	}
 	apitests.Run(t, isilon.Name, configYAML, tf)
 }
+
+func TestVolumeResize(t *testing.T) {
+	if skipTests() {
+		t.SkipNow()
+	}
+
+	tf := func(config gofig.Config, client types.Client, t *testing.T) {
+		vol := volumeCreate(t, client, volumeName)
+		defer volumeRemove(t, client, vol.ID)
+
+		reply, err := client.API().VolumeResize(nil, isilon.Name, vol.ID, 2)
+		assert.NoError(t, err)
+		if err != nil {
+			t.Error("failed volumeResize")
+			t.FailNow()
+		}
+		apitests.LogAsJSON(reply, t)
+		assert.Equal(t, int64(2), reply.Size)
+		assert.Contains(t, reply.Fields, isilon.VolumeFieldUsage)
+	}
+	apitests.Run(t, isilon.Name, configYAML, tf)
+}
@@ -0,0 +1,58 @@
+package libstorage
+
+import (
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// attachWaitRequested returns a flag indicating whether or not the attach
+// request asks to wait for the attachment to complete.
+func attachWaitRequested(request *types.VolumeAttachRequest) bool {
+	wait, _ := request.Opts["wait"].(bool)
+	return wait
+}
+
+// waitForLocalDevice waits for the executor to find the device of the
+// volume attached to the client's instance and then inspects the volume
+// with the local devices the executor read once the device appeared, so
+// that the volume's attachment includes the device's local path. The wait
+// is skipped if the server did not return an attach token, and the volume
+// is inspected with the local devices as they are now.
+func (c *client) waitForLocalDevice(
+	ctx types.Context,
+	service string,
+	vol *types.Volume,
+	token string) (*types.Volume, error) {
+
+	if token != "" {
+		if _, _, err := c.WaitForDevice(ctx, &types.WaitForDeviceOpts{
+			LocalDevicesOpts: types.LocalDevicesOpts{
+				ScanType: types.ParseDeviceScanType(
+					c.config.GetInt(types.ConfigDeviceScanType)),
+				Opts: utils.NewStore(),
+			},
+			Token: token,
+			Timeout: utils.DeviceAttachTimeout(
+				c.config.GetString(types.ConfigDeviceAttachTimeout)),
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	c.volumeCache.invalidate(service)
+	v, err := c.VolumeInspect(
+		ctx, service, vol.ID, types.VolAttReqWithDevMapForInstance)
+	if err != nil {
+		return nil, err
+	}
+
+	// the mount token issued with the attachment is not returned when the
+	// volume is inspected
+	if token, ok := vol.Fields[types.VolumeFieldMountToken]; ok {
+		if v.Fields == nil {
+			v.Fields = map[string]string{}
+		}
+		v.Fields[types.VolumeFieldMountToken] = token
+	}
+	return v, nil
+}
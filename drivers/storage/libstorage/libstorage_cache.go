@@ -0,0 +1,244 @@
+package libstorage
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+const (
+	// eventsWait is how long the client waits for the server to publish an
+	// event before polling again.
+	eventsWait = 30 * time.Second
+
+	// eventsRetryWait is how long the client waits before polling again
+	// after a failed attempt to receive events.
+	eventsRetryWait = 5 * time.Second
+)
+
+// volumeCache caches volume and instance lookups by service. A service's
+// entries are invalidated when the client performs a mutating operation on
+// the service or when the server publishes an event for the service. The
+// server publishes only the events of the client's tenant, so the changes
+// made by an administrator or another tenant are not observed; the entries
+// therefore also expire after the cache's TTL. The entries are stored
+// encoded so that the values returned to callers are copies that may be
+// modified without corrupting the cache.
+type volumeCache struct {
+	sync.RWMutex
+	ttl      time.Duration
+	services map[string]map[string]*volumeCacheEntry
+}
+
+type volumeCacheEntry struct {
+	buf     []byte
+	expires time.Time
+}
+
+func newVolumeCache(ttl time.Duration) *volumeCache {
+	return &volumeCache{
+		ttl:      ttl,
+		services: map[string]map[string]*volumeCacheEntry{},
+	}
+}
+
+// get decodes the cached entry into val and returns a flag indicating
+// whether or not the entry exists and has not expired.
+func (c *volumeCache) get(service, key string, val interface{}) bool {
+	if c == nil {
+		return false
+	}
+	c.RLock()
+	e, ok := c.services[service][key]
+	c.RUnlock()
+	if !ok || time.Now().After(e.expires) {
+		return false
+	}
+	return json.Unmarshal(e.buf, val) == nil
+}
+
+func (c *volumeCache) set(service, key string, val interface{}) {
+	if c == nil {
+		return
+	}
+	buf, err := json.Marshal(val)
+	if err != nil {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	if _, ok := c.services[service]; !ok {
+		c.services[service] = map[string]*volumeCacheEntry{}
+	}
+	c.services[service][key] = &volumeCacheEntry{
+		buf:     buf,
+		expires: time.Now().Add(c.ttl),
+	}
+}
+
+func (c *volumeCache) invalidate(service string) {
+	if c == nil {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	delete(c.services, service)
+}
+
+func (c *volumeCache) invalidateAll() {
+	if c == nil {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	c.services = map[string]map[string]*volumeCacheEntry{}
+}
+
+// headersCacheKey returns a key for the instance ID and local devices in the
+// context. The server's responses depend on the headers in which they are
+// sent, such as the devices and mount states used to determine the volumes'
+// attachments, so a response may be used only for requests with the same
+// headers.
+func headersCacheKey(ctx types.Context) string {
+	h := sha1.New()
+	if iid, ok := context.InstanceID(ctx); ok && iid != nil {
+		fmt.Fprintln(h, iid.String())
+	}
+	if lds, ok := context.LocalDevices(ctx); ok {
+		json.NewEncoder(h).Encode(lds)
+	} else if ldm, ok := ctx.Value(
+		context.AllLocalDevicesKey).(types.LocalDevicesMap); ok {
+		json.NewEncoder(h).Encode(ldm)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func volumesCacheKey(
+	ctx types.Context, attachments types.VolumeAttachmentsTypes) string {
+	return fmt.Sprintf("volumes-%d-%s", attachments, headersCacheKey(ctx))
+}
+
+func volumeCacheKey(
+	ctx types.Context,
+	volumeID string,
+	attachments types.VolumeAttachmentsTypes) string {
+	return fmt.Sprintf(
+		"volume-%s-%d-%s", volumeID, attachments, headersCacheKey(ctx))
+}
+
+func instanceCacheKey(ctx types.Context) string {
+	return fmt.Sprintf("instance-%s", headersCacheKey(ctx))
+}
+
+// watchEvents receives the events the server publishes for the service and
+// invalidates the service's cached entries. Volumes forcefully detached from
+// the instance are cleaned up if enabled, including those detached before
+// the client started. The ID of the last event received is retained when
+// receiving events fails so that the events already handled are not
+// received again. When the server restarts it begins a new epoch whose
+// event IDs begin again at 1, so the events are received again from the
+// start of the new epoch.
+func (c *client) watchEvents(ctx types.Context, service string) {
+
+	var (
+		since    int64
+		epoch    string
+		baseline = true
+	)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		wait := eventsWait
+		if baseline {
+			wait = 0
+		}
+
+		events, err := c.APIClient.Events(ctx, service, since, wait)
+		if err != nil {
+			// events may have been missed, so the service's entries are
+			// suspect
+			c.volumeCache.invalidate(service)
+			ctx.WithError(err).Warn("error receiving events")
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(eventsRetryWait):
+			}
+			continue
+		}
+
+		if len(events) > 0 && events[0].Epoch != epoch {
+			if epoch != "" {
+				// the events of the new epoch that precede these were not
+				// received, so the events are received again from the start
+				// of the epoch
+				ctx.WithField("epoch", events[0].Epoch).Info(
+					"server began new event epoch")
+				c.volumeCache.invalidate(service)
+				since = 0
+				baseline = true
+				epoch = events[0].Epoch
+				continue
+			}
+			epoch = events[0].Epoch
+		}
+
+		if c.forceDetachCleanup {
+			for _, ev := range forceDetached(events) {
+				go c.cleanupForceDetached(ctx, ev)
+			}
+		}
+
+		for _, ev := range events {
+			if !baseline {
+				ctx.WithField("event", ev.Type).Debug("invalidating cache")
+				c.volumeCache.invalidate(service)
+			}
+			if ev.ID > since {
+				since = ev.ID
+			}
+		}
+
+		baseline = false
+	}
+}
+
+// forceDetached returns the events that indicate a volume was forcefully
+// detached from an instance, except for those followed by an event that
+// indicates the volume was attached to the instance again. The volume of
+// such an event is in use and must not be cleaned up, such as when the
+// client restarts and receives the server's buffered events.
+func forceDetached(events []*types.Event) []*types.Event {
+	var detached []*types.Event
+	for i, ev := range events {
+		if ev.Type != types.EventVolumeForceDetached {
+			continue
+		}
+		iid := ev.Fields[types.EventFieldInstanceID]
+		reattached := false
+		for _, later := range events[i+1:] {
+			if later.Type == types.EventVolumeAttached &&
+				later.Service == ev.Service &&
+				later.VolumeID == ev.VolumeID &&
+				later.Fields[types.EventFieldInstanceID] == iid {
+				reattached = true
+				break
+			}
+		}
+		if !reattached {
+			detached = append(detached, ev)
+		}
+	}
+	return detached
+}
@@ -0,0 +1,151 @@
+package libstorage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+func TestVolumeCacheReturnsCopies(t *testing.T) {
+	c := newVolumeCache(time.Minute)
+	c.set("vfs", "volume", &types.Volume{
+		ID:     "vfs-000",
+		Fields: map[string]string{"owner": "root"},
+		Attachments: []*types.VolumeAttachment{
+			{VolumeID: "vfs-000", DeviceName: "/dev/xvda"},
+		},
+	})
+
+	v := &types.Volume{}
+	if !assert.True(t, c.get("vfs", "volume", v)) {
+		t.FailNow()
+	}
+	v.Fields["owner"] = "nobody"
+	v.Attachments[0].DeviceName = "/dev/xvdb"
+
+	v = &types.Volume{}
+	assert.True(t, c.get("vfs", "volume", v))
+	assert.Equal(t, "root", v.Fields["owner"])
+	assert.Equal(t, "/dev/xvda", v.Attachments[0].DeviceName)
+
+	vols := types.VolumeMap{}
+	c.set("vfs", "volumes", vols)
+	vols["vfs-000"] = v
+	vols = types.VolumeMap{}
+	assert.True(t, c.get("vfs", "volumes", &vols))
+	assert.Len(t, vols, 0)
+}
+
+func TestVolumeCacheInvalidate(t *testing.T) {
+	c := newVolumeCache(time.Minute)
+	c.set("vfs", "volume", &types.Volume{ID: "vfs-000"})
+	c.set("ebs", "volume", &types.Volume{ID: "vol-000"})
+
+	c.invalidate("vfs")
+	assert.False(t, c.get("vfs", "volume", &types.Volume{}))
+	assert.True(t, c.get("ebs", "volume", &types.Volume{}))
+
+	c.invalidateAll()
+	assert.False(t, c.get("ebs", "volume", &types.Volume{}))
+
+	var nilCache *volumeCache
+	nilCache.set("vfs", "volume", &types.Volume{ID: "vfs-000"})
+	assert.False(t, nilCache.get("vfs", "volume", &types.Volume{}))
+}
+
+func TestVolumeCacheExpires(t *testing.T) {
+	c := newVolumeCache(time.Millisecond)
+	c.set("vfs", "volume", &types.Volume{ID: "vfs-000"})
+	time.Sleep(5 * time.Millisecond)
+	assert.False(t, c.get("vfs", "volume", &types.Volume{}))
+}
+
+func TestForceDetached(t *testing.T) {
+	ev := func(
+		id int64, typ types.EventType, vol, iid string) *types.Event {
+		return &types.Event{
+			ID:       id,
+			Type:     typ,
+			Service:  "rbd",
+			VolumeID: vol,
+			Fields:   map[string]string{types.EventFieldInstanceID: iid},
+		}
+	}
+	fd := types.EventVolumeForceDetached
+	att := types.EventVolumeAttached
+
+	tests := []struct {
+		events   []*types.Event
+		detached []int64
+	}{
+		{[]*types.Event{ev(1, fd, "rbd.vol", "i-000")}, []int64{1}},
+		// the volume was attached to the instance again
+		{[]*types.Event{
+			ev(1, fd, "rbd.vol", "i-000"),
+			ev(2, att, "rbd.vol", "i-000"),
+		}, nil},
+		// and then forcefully detached again
+		{[]*types.Event{
+			ev(1, fd, "rbd.vol", "i-000"),
+			ev(2, att, "rbd.vol", "i-000"),
+			ev(3, fd, "rbd.vol", "i-000"),
+		}, []int64{3}},
+		// the volume was attached to another instance
+		{[]*types.Event{
+			ev(1, fd, "rbd.vol", "i-000"),
+			ev(2, att, "rbd.vol", "i-001"),
+		}, []int64{1}},
+		// another volume was attached to the instance
+		{[]*types.Event{
+			ev(1, fd, "rbd.vol", "i-000"),
+			ev(2, att, "rbd.other", "i-000"),
+		}, []int64{1}},
+		// the attachment preceded the force detach
+		{[]*types.Event{
+			ev(1, att, "rbd.vol", "i-000"),
+			ev(2, fd, "rbd.vol", "i-000"),
+		}, []int64{2}},
+	}
+
+	for i, tt := range tests {
+		var ids []int64
+		for _, ev := range forceDetached(tt.events) {
+			ids = append(ids, ev.ID)
+		}
+		assert.Equal(t, tt.detached, ids, "test %d", i)
+	}
+}
+
+func TestVolumeCacheKeyHeaders(t *testing.T) {
+	ctx := context.Background()
+	base := volumesCacheKey(ctx, types.VolAttReqTrue)
+	assert.Equal(t, base, volumesCacheKey(ctx, types.VolAttReqTrue))
+	assert.NotEqual(t, base, volumesCacheKey(ctx, types.VolAttReq))
+
+	iid := &types.InstanceID{ID: "i-000", Driver: "vfs"}
+	ctxIID := ctx.WithValue(context.InstanceIDKey, iid)
+	assert.NotEqual(t, base, volumesCacheKey(ctxIID, types.VolAttReqTrue))
+
+	newCtx := func(dev string) types.Context {
+		return ctxIID.WithValue(
+			context.AllLocalDevicesKey,
+			types.LocalDevicesMap{
+				"vfs": &types.LocalDevices{
+					Driver:    "vfs",
+					DeviceMap: map[string]string{"vfs-000": dev},
+				},
+			})
+	}
+	assert.Equal(t,
+		volumeCacheKey(newCtx("/dev/xvda"), "vfs-000", types.VolAttReqTrue),
+		volumeCacheKey(newCtx("/dev/xvda"), "vfs-000", types.VolAttReqTrue))
+	assert.NotEqual(t,
+		volumeCacheKey(newCtx("/dev/xvda"), "vfs-000", types.VolAttReqTrue),
+		volumeCacheKey(newCtx("/dev/xvdb"), "vfs-000", types.VolAttReqTrue))
+	assert.NotEqual(t,
+		instanceCacheKey(ctx), instanceCacheKey(ctxIID))
+}
@@ -5,14 +5,18 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"strings"
 
 	log "github.com/Sirupsen/logrus"
 
 	gofig "github.com/akutz/gofig/types"
 	"github.com/akutz/goof"
+	"golang.org/x/crypto/ed25519"
 
 	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/lsxrpc"
 	"github.com/codedellemc/libstorage/api/types"
 	"github.com/codedellemc/libstorage/api/utils"
 )
@@ -26,6 +30,13 @@ type client struct {
 	serviceCache    *lss
 	supportedCache  *lss
 	instanceIDCache types.Store
+	instanceCache   types.Store
+	volumeCache     *volumeCache
+	lsxClient       *lsxrpc.Client
+
+	// forceDetachCleanup indicates whether or not the mounts and mappings
+	// of volumes forcefully detached from the instance are removed
+	forceDetachCleanup bool
 }
 
 var errExecutorNotSupported = errors.New("executor not supported")
@@ -51,14 +62,21 @@ func (c *client) dial(ctx types.Context) error {
 	store := utils.NewStore()
 	c.ctx = c.ctx.WithValue(context.ServerKey, c.ServerName())
 
-	if !c.config.GetBool(types.ConfigExecutorNoDownload) {
+	if ep := c.config.GetString(types.ConfigExecutorEndpoint); ep != "" {
+		// an executor daemon is called in place of the executor binary, so
+		// the binary is not downloaded
+		ctx.WithField("endpoint", ep).Info("using executor daemon")
+		if c.lsxClient, err = lsxrpc.Dial(ep); err != nil {
+			return err
+		}
+	} else if !c.config.GetBool(types.ConfigExecutorNoDownload) {
 
 		ctx.Info("initializing executors cache")
 		if _, err := c.Executors(ctx); err != nil {
 			return err
 		}
 
-		if err := c.updateExecutor(ctx); err != nil {
+		if err := c.updateExecutor(ctx, svcInfos); err != nil {
 			return err
 		}
 	}
@@ -107,7 +125,8 @@ func (c *client) getServiceInfo(service string) (*types.ServiceInfo, error) {
 	return nil, goof.WithField("name", service, "unknown service")
 }
 
-func (c *client) updateExecutor(ctx types.Context) error {
+func (c *client) updateExecutor(
+	ctx types.Context, svcInfos map[string]*types.ServiceInfo) error {
 
 	if c.isController() {
 		return utils.NewUnsupportedForClientTypeError(
@@ -134,7 +153,7 @@ func (c *client) updateExecutor(ctx types.Context) error {
 
 	if !types.LSX.Exists() {
 		ctx.Debug("executor does not exist, download executor")
-		return c.downloadExecutor(ctx)
+		return c.downloadExecutor(ctx, lsxi, svcInfos)
 	}
 
 	ctx.Debug("executor exists, getting local checksum")
@@ -149,7 +168,7 @@ func (c *client) updateExecutor(ctx types.Context) error {
 			"remoteChecksum": lsxi.MD5Checksum,
 			"localChecksum":  checksum,
 		}).Debug("executor checksums do not match, download executor")
-		return c.downloadExecutor(ctx)
+		return c.downloadExecutor(ctx, lsxi, svcInfos)
 	}
 
 	return nil
@@ -190,7 +209,14 @@ func (c *client) getExecutorChecksum(ctx types.Context) (string, error) {
 	return sum, nil
 }
 
-func (c *client) downloadExecutor(ctx types.Context) error {
+// downloadExecutor downloads the executor to a temporary file and verifies
+// its checksum and, if a verify key is configured, its signature. The
+// verified executor then replaces the local executor, which is restored if
+// the new executor fails its self-test.
+func (c *client) downloadExecutor(
+	ctx types.Context,
+	lsxi *types.ExecutorInfo,
+	svcInfos map[string]*types.ServiceInfo) error {
 
 	if c.isController() {
 		return utils.NewUnsupportedForClientTypeError(
@@ -199,30 +225,161 @@ func (c *client) downloadExecutor(ctx types.Context) error {
 
 	ctx.Debug("downloading executor")
 
-	f, err := os.OpenFile(
-		types.LSX.String(),
-		os.O_CREATE|os.O_RDWR|os.O_TRUNC,
-		0755)
+	verifyKey, err := executorVerifyKey(ctx, c.config)
 	if err != nil {
 		return err
 	}
 
-	defer f.Close()
-
 	rdr, err := c.APIClient.ExecutorGet(ctx, types.LSX.Name())
 	if err != nil {
 		return err
 	}
+	defer rdr.Close()
 
-	n, err := io.Copy(f, rdr)
+	buf, err := ioutil.ReadAll(rdr)
 	if err != nil {
 		return err
 	}
+	ctx.WithField("bytes", len(buf)).Debug("downloaded executor")
+
+	if sum := fmt.Sprintf("%x", md5.Sum(buf)); sum != lsxi.MD5Checksum {
+		return goof.WithFields(goof.Fields{
+			"remoteChecksum":     lsxi.MD5Checksum,
+			"downloadedChecksum": sum,
+		}, "downloaded executor checksum does not match")
+	}
 
-	if err := f.Sync(); err != nil {
+	if verifyKey != nil {
+		if err := utils.VerifySignature(
+			verifyKey, buf, lsxi.Signature); err != nil {
+			return goof.WithError("error verifying executor", err)
+		}
+		ctx.Debug("verified executor signature")
+	}
+
+	lsxBin := types.LSX.String()
+	newBin := lsxBin + ".new"
+	if err := writeExecutor(newBin, buf); err != nil {
+		os.Remove(newBin)
 		return err
 	}
 
-	ctx.WithField("bytes", n).Debug("downloaded executor")
+	return c.installExecutor(ctx, newBin, svcInfos)
+}
+
+// executorVerifyKey returns the key with which a downloaded executor's
+// signature is verified. If no key is configured then the executor is not
+// verified, unless verification is required, in which case an error is
+// returned.
+func executorVerifyKey(
+	ctx types.Context, config gofig.Config) (ed25519.PublicKey, error) {
+
+	vk := config.GetString(types.ConfigExecutorVerifyKey)
+	if vk != "" {
+		return utils.ParseVerifyKey([]byte(vk))
+	}
+	if config.GetBool(types.ConfigExecutorVerifyRequired) {
+		return nil, goof.New(
+			"executor verification required but no verify key configured")
+	}
+	ctx.Warn("executor signature not verified; no verify key configured")
+	return nil, nil
+}
+
+// installExecutor replaces the local executor with the new one. The local
+// executor, if there is one, is kept until the new executor passes its
+// self-test and is restored if it does not.
+func (c *client) installExecutor(
+	ctx types.Context,
+	newBin string,
+	svcInfos map[string]*types.ServiceInfo) error {
+
+	lsxBin := types.LSX.String()
+	oldBin := lsxBin + ".old"
+
+	hasOld := types.LSX.Exists()
+	if hasOld {
+		os.Remove(oldBin)
+		if err := os.Link(lsxBin, oldBin); err != nil {
+			if err := copyExecutor(lsxBin, oldBin); err != nil {
+				os.Remove(newBin)
+				return goof.WithError("error backing up executor", err)
+			}
+		}
+	}
+
+	if err := os.Rename(newBin, lsxBin); err != nil {
+		os.Remove(newBin)
+		return goof.WithError("error replacing executor", err)
+	}
+
+	testErr := c.testExecutor(ctx, svcInfos)
+	if testErr == nil {
+		if hasOld {
+			os.Remove(oldBin)
+		}
+		ctx.Info("updated executor")
+		return nil
+	}
+
+	if !hasOld {
+		os.Remove(lsxBin)
+		return goof.WithError("new executor failed self-test", testErr)
+	}
+	if err := os.Rename(oldBin, lsxBin); err != nil {
+		return goof.WithError("error restoring executor", err)
+	}
+
+	// the previous executor is still usable, so the client is not prevented
+	// from starting because the new executor is broken
+	ctx.WithError(testErr).Warn(
+		"new executor failed self-test, restored previous executor")
+	return nil
+}
+
+// testExecutor runs the executor's supported command for each service's
+// driver. A driver that does not implement the command passes the test.
+func (c *client) testExecutor(
+	ctx types.Context, svcInfos map[string]*types.ServiceInfo) error {
+
+	lsxBin := types.LSX.String()
+	tested := map[string]bool{}
+	for _, si := range svcInfos {
+		if si.Driver == nil {
+			continue
+		}
+		driverName := strings.ToLower(si.Driver.Name)
+		if tested[driverName] {
+			continue
+		}
+		tested[driverName] = true
+
+		if _, err := c.execExecutor(
+			ctx, lsxBin, driverName, types.LSXCmdSupported); err != nil &&
+			err != types.ErrNotImplemented {
+			return goof.WithFieldE(
+				"driver", driverName, "executor self-test failed", err)
+		}
+	}
 	return nil
 }
+
+func writeExecutor(path string, buf []byte) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Write(buf); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+func copyExecutor(src, dst string) error {
+	buf, err := ioutil.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return writeExecutor(dst, buf)
+}
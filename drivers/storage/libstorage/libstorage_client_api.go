@@ -3,6 +3,9 @@ package libstorage
 import (
 	"io"
 
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/context"
 	"github.com/codedellemc/libstorage/api/registry"
 	"github.com/codedellemc/libstorage/api/types"
 	"github.com/codedellemc/libstorage/api/utils"
@@ -29,10 +32,27 @@ func (c *client) InstanceInspect(
 	}
 
 	ctx = c.withInstanceID(c.requireCtx(ctx), service)
+
+	key := instanceCacheKey(ctx)
+	cached := &types.Instance{}
+	if c.volumeCache.get(service, key, cached) {
+		return cached, nil
+	}
+	if c.instanceCache != nil {
+		if i, ok := c.instanceCache.Get(service).(*types.Instance); ok {
+			return i, nil
+		}
+	}
+
 	i, err := c.APIClient.InstanceInspect(ctx, service)
 	if err != nil {
+		c.bustInstanceCache(ctx, service)
 		return nil, err
 	}
+	c.volumeCache.set(service, key, i)
+	if c.instanceCache != nil {
+		c.instanceCache.Set(service, i)
+	}
 	return i, nil
 }
 
@@ -64,6 +84,12 @@ func (c *client) ServiceInspect(
 	return c.APIClient.ServiceInspect(ctx, service)
 }
 
+func (c *client) ServiceCapabilities(
+	ctx types.Context, service string) (*types.StorageCapabilities, error) {
+
+	return c.APIClient.ServiceCapabilities(c.requireCtx(ctx), service)
+}
+
 func (c *client) Volumes(
 	ctx types.Context,
 	attachments types.VolumeAttachmentsTypes) (types.ServiceVolumeMap, error) {
@@ -91,7 +117,18 @@ func (c *client) VolumesByService(
 	}
 	ctx = ctxA
 
-	return c.APIClient.VolumesByService(ctx, service, attachments)
+	key := volumesCacheKey(ctx, attachments)
+	cached := types.VolumeMap{}
+	if c.volumeCache.get(service, key, &cached) {
+		return cached, nil
+	}
+
+	vols, err := c.APIClient.VolumesByService(ctx, service, attachments)
+	if err != nil {
+		return nil, err
+	}
+	c.volumeCache.set(service, key, vols)
+	return vols, nil
 }
 
 func (c *client) VolumeInspect(
@@ -106,7 +143,57 @@ func (c *client) VolumeInspect(
 	}
 	ctx = ctxA
 
-	return c.APIClient.VolumeInspect(ctx, service, volumeID, attachments)
+	key := volumeCacheKey(ctx, volumeID, attachments)
+	cached := &types.Volume{}
+	if c.volumeCache.get(service, key, cached) {
+		return cached, nil
+	}
+
+	vol, err := c.APIClient.VolumeInspect(ctx, service, volumeID, attachments)
+	if err != nil {
+		return nil, err
+	}
+	c.volumeCache.set(service, key, vol)
+	return vol, nil
+}
+
+// VolumeStats gets the volume's IO statistics from the server and, if the
+// volume is mounted on this instance, adds the usage of its file system as
+// collected by the executor.
+func (c *client) VolumeStats(
+	ctx types.Context,
+	service, volumeID string) (*types.VolumeStats, error) {
+
+	ctx = c.withInstanceID(c.requireCtx(ctx), service)
+
+	stats, err := c.APIClient.VolumeStats(ctx, service, volumeID)
+	if err != nil {
+		return nil, err
+	}
+	if c.isController() {
+		return stats, nil
+	}
+
+	fields := map[string]interface{}{
+		"service":  service,
+		"volumeID": volumeID,
+	}
+
+	mountPoints, err := c.volumeMountPoints(ctx, service, []string{volumeID})
+	if err != nil {
+		ctx.WithFields(fields).WithError(err).Debug(
+			"not getting file system usage of volume")
+		return stats, nil
+	}
+
+	usage, err := c.FileSystemUsage(ctx, mountPoints[0], utils.NewStore())
+	if err != nil {
+		ctx.WithFields(fields).WithError(err).Warn(
+			"error getting file system usage of volume")
+		return stats, nil
+	}
+	stats.Usage = usage
+	return stats, nil
 }
 
 func (c *client) VolumeCreate(
@@ -114,6 +201,8 @@ func (c *client) VolumeCreate(
 	service string,
 	request *types.VolumeCreateRequest) (*types.Volume, error) {
 
+	defer c.volumeCache.invalidate(service)
+
 	ctx = c.withInstanceID(c.requireCtx(ctx), service)
 	ctxA, err := c.withAllLocalDevices(ctx)
 	if err != nil {
@@ -150,6 +239,8 @@ func (c *client) VolumeCreateFromSnapshot(
 	service, snapshotID string,
 	request *types.VolumeCreateRequest) (*types.Volume, error) {
 
+	defer c.volumeCache.invalidate(service)
+
 	ctx = c.withInstanceID(c.requireCtx(ctx), service)
 
 	lsd, _ := registry.NewClientDriver(service)
@@ -182,6 +273,8 @@ func (c *client) VolumeCopy(
 	service, volumeID string,
 	request *types.VolumeCopyRequest) (*types.Volume, error) {
 
+	defer c.volumeCache.invalidate(service)
+
 	ctx = c.withInstanceID(c.requireCtx(ctx), service)
 
 	lsd, _ := registry.NewClientDriver(service)
@@ -208,11 +301,80 @@ func (c *client) VolumeCopy(
 	return vol, nil
 }
 
+func (c *client) VolumeImport(
+	ctx types.Context,
+	service string,
+	request *types.VolumeImportRequest) (*types.Volume, error) {
+
+	defer c.volumeCache.invalidate(service)
+
+	ctx = c.withInstanceID(c.requireCtx(ctx), service)
+	return c.APIClient.VolumeImport(ctx, service, request)
+}
+
+func (c *client) VolumeUnmanage(
+	ctx types.Context,
+	service, volumeID string,
+	force bool) error {
+
+	defer c.volumeCache.invalidate(service)
+
+	ctx = c.withInstanceID(c.requireCtx(ctx), service)
+	return c.APIClient.VolumeUnmanage(ctx, service, volumeID, force)
+}
+
+func (c *client) VolumeResize(
+	ctx types.Context,
+	service, volumeID string,
+	size int64) (*types.Volume, error) {
+
+	defer c.volumeCache.invalidate(service)
+
+	ctx = c.withInstanceID(c.requireCtx(ctx), service)
+	vol, err := c.APIClient.VolumeResize(ctx, service, volumeID, size)
+	if err != nil {
+		return nil, err
+	}
+
+	// the file system of a volume mounted on the instance is grown to fill
+	// the volume's new size
+	if c.isController() {
+		return vol, nil
+	}
+	lsc, ok := context.Client(ctx)
+	if !ok || lsc.Integration() == nil {
+		return vol, nil
+	}
+	g, ok := lsc.Integration().(types.IntegrationDriverFileSystemGrower)
+	if !ok {
+		return vol, nil
+	}
+	if err := g.GrowFileSystem(ctx, volumeID); err != nil {
+		return nil, goof.WithFieldE(
+			"volumeID", volumeID, "error growing file system", err)
+	}
+	return vol, nil
+}
+
+func (c *client) VolumeRetype(
+	ctx types.Context,
+	service, volumeID, volumeType, migrationPolicy string) (
+	*types.Volume, error) {
+
+	defer c.volumeCache.invalidate(service)
+
+	ctx = c.withInstanceID(c.requireCtx(ctx), service)
+	return c.APIClient.VolumeRetype(
+		ctx, service, volumeID, volumeType, migrationPolicy)
+}
+
 func (c *client) VolumeRemove(
 	ctx types.Context,
 	service, volumeID string,
 	force bool) error {
 
+	defer c.volumeCache.invalidate(service)
+
 	ctx = c.withInstanceID(c.requireCtx(ctx), service)
 
 	lsd, _ := registry.NewClientDriver(service)
@@ -250,6 +412,8 @@ func (c *client) VolumeAttach(
 			c.clientType, "VolumeAttach")
 	}
 
+	defer c.volumeCache.invalidate(service)
+
 	ctx = c.withInstanceID(c.requireCtx(ctx), service)
 	ctxA, err := c.withAllLocalDevices(ctx)
 	if err != nil {
@@ -257,7 +421,21 @@ func (c *client) VolumeAttach(
 	}
 	ctx = ctxA
 
-	return c.APIClient.VolumeAttach(ctx, service, volumeID, request)
+	v, attTokn, err := c.APIClient.VolumeAttach(
+		ctx, service, volumeID, request)
+	if err != nil {
+		c.bustInstanceCache(ctx, service)
+		return v, attTokn, err
+	}
+
+	// the server waits for the storage platform to complete the attachment
+	// and the executor waits for its device to appear on this instance
+	if attachWaitRequested(request) {
+		if v, err = c.waitForLocalDevice(ctx, service, v, attTokn); err != nil {
+			return nil, "", err
+		}
+	}
+	return v, attTokn, nil
 }
 
 func (c *client) VolumeDetach(
@@ -271,6 +449,8 @@ func (c *client) VolumeDetach(
 			c.clientType, "VolumeDetach")
 	}
 
+	defer c.volumeCache.invalidate(service)
+
 	ctx = c.withInstanceID(c.requireCtx(ctx), service)
 	ctxA, err := c.withAllLocalDevices(ctx)
 	if err != nil {
@@ -278,7 +458,49 @@ func (c *client) VolumeDetach(
 	}
 	ctx = ctxA
 
-	return c.APIClient.VolumeDetach(ctx, service, volumeID, request)
+	v, err := c.APIClient.VolumeDetach(ctx, service, volumeID, request)
+	if err != nil {
+		c.bustInstanceCache(ctx, service)
+	}
+	return v, err
+}
+
+func (c *client) VolumeMountReport(
+	ctx types.Context,
+	service string,
+	volumeID string,
+	request *types.VolumeMountReportRequest) error {
+
+	ctx = c.withInstanceID(c.requireCtx(ctx), service)
+	return c.APIClient.VolumeMountReport(ctx, service, volumeID, request)
+}
+
+func (c *client) VolumePurge(
+	ctx types.Context,
+	service, volumeID string) error {
+
+	defer c.volumeCache.invalidate(service)
+
+	return c.APIClient.VolumePurge(c.requireCtx(ctx), service, volumeID)
+}
+
+func (c *client) VolumeUndelete(
+	ctx types.Context,
+	service, volumeID string) (*types.Volume, error) {
+
+	defer c.volumeCache.invalidate(service)
+
+	return c.APIClient.VolumeUndelete(c.requireCtx(ctx), service, volumeID)
+}
+
+func (c *client) VolumeForceDetach(
+	ctx types.Context,
+	service, volumeID, instanceID string) (*types.Volume, error) {
+
+	defer c.volumeCache.invalidate(service)
+
+	return c.APIClient.VolumeForceDetach(
+		c.requireCtx(ctx), service, volumeID, instanceID)
 }
 
 func (c *client) VolumeDetachAll(
@@ -290,6 +512,8 @@ func (c *client) VolumeDetachAll(
 			c.clientType, "VolumeDetachAll")
 	}
 
+	defer c.volumeCache.invalidateAll()
+
 	ctx = c.withAllInstanceIDs(c.requireCtx(ctx))
 	ctxA, err := c.withAllLocalDevices(ctx)
 	if err != nil {
@@ -310,6 +534,8 @@ func (c *client) VolumeDetachAllForService(
 			c.clientType, "VolumeDetachAllForService")
 	}
 
+	defer c.volumeCache.invalidate(service)
+
 	ctx = c.withInstanceID(c.requireCtx(ctx), service)
 	ctxA, err := c.withAllLocalDevices(ctx)
 	if err != nil {
@@ -326,8 +552,55 @@ func (c *client) VolumeSnapshot(
 	volumeID string,
 	request *types.VolumeSnapshotRequest) (*types.Snapshot, error) {
 
+	defer c.volumeCache.invalidate(service)
+
+	ctx = c.withInstanceID(c.requireCtx(ctx), service)
+	if !request.Quiesce {
+		return c.APIClient.VolumeSnapshot(ctx, service, volumeID, request)
+	}
+
+	if c.isController() {
+		return nil, utils.NewUnsupportedForClientTypeError(
+			c.clientType, "VolumeSnapshot")
+	}
+
+	fctx, thaw, err := c.freezeVolumes(ctx, service, []string{volumeID})
+	if err != nil {
+		return nil, err
+	}
+	snap, err := c.APIClient.VolumeSnapshot(fctx, service, volumeID, request)
+	thaw()
+	return snap, err
+}
+
+func (c *client) SnapshotGroupCreate(
+	ctx types.Context,
+	service string,
+	request *types.SnapshotGroupCreateRequest) (*types.SnapshotGroup, error) {
+
+	defer c.volumeCache.invalidate(service)
+
 	ctx = c.withInstanceID(c.requireCtx(ctx), service)
-	return c.APIClient.VolumeSnapshot(ctx, service, volumeID, request)
+	if !request.Quiesce {
+		return c.APIClient.SnapshotGroupCreate(ctx, service, request)
+	}
+
+	if c.isController() {
+		return nil, utils.NewUnsupportedForClientTypeError(
+			c.clientType, "SnapshotGroupCreate")
+	}
+
+	fctx, thaw, err := c.freezeVolumes(ctx, service, request.VolumeIDs)
+	if err != nil {
+		return nil, err
+	}
+	group, err := c.APIClient.SnapshotGroupCreate(fctx, service, request)
+	thaw()
+	if err != nil {
+		return nil, err
+	}
+	group.Quiesced = true
+	return group, nil
 }
 
 func (c *client) Snapshots(
@@ -356,6 +629,8 @@ func (c *client) SnapshotRemove(
 	ctx types.Context,
 	service, snapshotID string) error {
 
+	defer c.volumeCache.invalidate(service)
+
 	ctx = c.withInstanceID(c.requireCtx(ctx), service)
 	return c.APIClient.SnapshotRemove(ctx, service, snapshotID)
 }
@@ -365,6 +640,8 @@ func (c *client) SnapshotCopy(
 	service, snapshotID string,
 	request *types.SnapshotCopyRequest) (*types.Snapshot, error) {
 
+	defer c.volumeCache.invalidate(service)
+
 	ctx = c.withInstanceID(c.requireCtx(ctx), service)
 	return c.APIClient.SnapshotCopy(ctx, service, snapshotID, request)
 }
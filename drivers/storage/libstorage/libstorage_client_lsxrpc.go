@@ -0,0 +1,91 @@
+package libstorage
+
+import (
+	"errors"
+
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/lsxrpc"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// runExecutorRPC runs an executor command with the executor daemon. The
+// daemon's exit codes are mapped to errors as are those of the executor
+// binary.
+func (c *client) runExecutorRPC(
+	ctx types.Context, args ...string) ([]byte, error) {
+
+	ctx.WithField("args", args).Debug("invoking executor daemon")
+
+	res, err := c.lsxClient.Exec(ctx, &lsxrpc.ExecRequest{Args: args})
+	if err != nil {
+		return nil, goof.WithFieldE(
+			"args", args, "error calling executor daemon", err)
+	}
+
+	switch err := executorError(res.ExitCode, res.Error); err {
+	case nil:
+		return res.Output, nil
+	case types.ErrNotImplemented, types.ErrTimedOut:
+		return nil, err
+	default:
+		return nil, goof.WithFieldE(
+			"args", args, "error executing xcli", err)
+	}
+}
+
+// waitForDeviceRPC waits for a device with the executor daemon, which
+// streams the devices found by each scan of the host's devices rather than
+// only the result of the last scan.
+func (c *client) waitForDeviceRPC(
+	ctx types.Context,
+	driverName string,
+	opts *types.WaitForDeviceOpts) (bool, *types.LocalDevices, error) {
+
+	event, err := c.lsxClient.Wait(
+		ctx,
+		&lsxrpc.WaitRequest{
+			Driver:   driverName,
+			ScanType: opts.ScanType,
+			Token:    opts.Token,
+			Timeout:  opts.Timeout.String(),
+		},
+		func(event *lsxrpc.WaitEvent) {
+			if !event.Done && event.LocalDevices != nil {
+				ctx.WithField("localDevices", event.LocalDevices).Debug(
+					"executor daemon scanned devices")
+			}
+		})
+	if err != nil {
+		return false, nil, goof.WithError(
+			"error calling executor daemon", err)
+	}
+
+	if event.ExitCode != types.LSXExitCodeTimedOut {
+		if err := executorError(event.ExitCode, event.Error); err != nil {
+			return false, nil, err
+		}
+	}
+
+	ld := event.LocalDevices
+	if ld == nil {
+		ld = &types.LocalDevices{Driver: driverName}
+	}
+	removeInvalidLocalDevices(ctx, ld)
+
+	ctx.Debug("xli waitfordevice success")
+	return event.Matched, ld, nil
+}
+
+// executorError returns the error indicated by an executor's exit code.
+func executorError(exitCode int, msg string) error {
+	switch exitCode {
+	case 0:
+		return nil
+	case types.LSXExitCodeNotImplemented:
+		return types.ErrNotImplemented
+	case types.LSXExitCodeTimedOut:
+		return types.ErrTimedOut
+	}
+	return errors.New(msg)
+}
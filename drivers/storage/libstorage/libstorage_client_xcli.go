@@ -215,8 +215,13 @@ func (c *client) LocalDevices(
 	}
 	driverName := si.Driver.Name
 
-	out, err := c.runExecutor(
-		ctx, driverName, types.LSXCmdLocalDevices, opts.ScanType.String())
+	args := []string{
+		driverName, types.LSXCmdLocalDevices, opts.ScanType.String()}
+	if c.config.GetBool(types.ConfigExecutorDescribeDevices) {
+		args = append(args, types.LSXArgDescribeDevices)
+	}
+
+	out, err := c.runExecutor(ctx, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -256,6 +261,10 @@ func (c *client) WaitForDevice(
 	}
 	driverName := si.Driver.Name
 
+	if c.lsxClient != nil {
+		return c.waitForDeviceRPC(ctx, driverName, opts)
+	}
+
 	out, err := c.runExecutor(
 		ctx, driverName, types.LSXCmdWaitForDevice,
 		opts.ScanType.String(), opts.Token, opts.Timeout.String())
@@ -315,6 +324,9 @@ func (c *client) Mount(
 	if len(opts.MountOptions) > 0 {
 		args = append(args, "-o", opts.MountOptions)
 	}
+	if len(opts.FsType) > 0 {
+		args = append(args, "-t", opts.FsType)
+	}
 
 	if _, err = c.runExecutor(ctx, args...); err != nil {
 		return err
@@ -404,15 +416,120 @@ func (c *client) Unmount(
 	return nil
 }
 
+// FreezeFileSystem freezes the file system mounted at the provided path.
+func (c *client) FreezeFileSystem(
+	ctx types.Context,
+	mountPoint string,
+	timeout time.Duration,
+	opts types.Store) error {
+
+	args := []string{mountPoint}
+	if timeout > 0 {
+		args = append(args, timeout.String())
+	}
+	return c.fsFreezeOp(ctx, types.LSXCmdFreeze, args...)
+}
+
+// ThawFileSystem thaws the file system mounted at the provided path.
+func (c *client) ThawFileSystem(
+	ctx types.Context,
+	mountPoint string,
+	opts types.Store) error {
+
+	return c.fsFreezeOp(ctx, types.LSXCmdThaw, mountPoint)
+}
+
+func (c *client) fsFreezeOp(
+	ctx types.Context, cmd string, args ...string) error {
+
+	if c.isController() {
+		return utils.NewUnsupportedForClientTypeError(c.clientType, cmd)
+	}
+
+	ctx = context.RequireTX(ctx.Join(c.ctx))
+
+	serviceName, ok := context.ServiceName(ctx)
+	if !ok {
+		return goof.New("missing service name")
+	}
+
+	si, err := c.getServiceInfo(serviceName)
+	if err != nil {
+		return err
+	}
+	driverName := si.Driver.Name
+
+	args = append([]string{driverName, cmd}, args...)
+	if _, err = c.runExecutor(ctx, args...); err != nil {
+		return err
+	}
+
+	ctx.WithField("mountPoint", args[2]).Debugf("xli %s success", cmd)
+	return nil
+}
+
+// FileSystemUsage returns the capacity usage of the file system mounted at
+// the provided path.
+func (c *client) FileSystemUsage(
+	ctx types.Context,
+	mountPoint string,
+	opts types.Store) (*types.VolumeUsage, error) {
+
+	if c.isController() {
+		return nil, utils.NewUnsupportedForClientTypeError(
+			c.clientType, "FileSystemUsage")
+	}
+
+	ctx = context.RequireTX(ctx.Join(c.ctx))
+
+	serviceName, ok := context.ServiceName(ctx)
+	if !ok {
+		return nil, goof.New("missing service name")
+	}
+
+	si, err := c.getServiceInfo(serviceName)
+	if err != nil {
+		return nil, err
+	}
+	driverName := si.Driver.Name
+
+	out, err := c.runExecutor(
+		ctx, driverName, types.LSXCmdFileSystemUsage, mountPoint)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &types.VolumeUsage{}
+	if err := json.Unmarshal(out, usage); err != nil {
+		return nil, err
+	}
+
+	ctx.WithField("mountPoint", mountPoint).Debug("xli fsUsage success")
+	return usage, nil
+}
+
 func unmarshalLocalDevices(
 	ctx types.Context, out []byte) (*types.LocalDevices, error) {
 
 	ld := &types.LocalDevices{}
-	if err := ld.UnmarshalText(out); err != nil {
+
+	// described devices are written as JSON, but an executor that predates
+	// the describe argument ignores it and writes the text format
+	if len(out) > 0 && out[0] == '{' {
+		if err := ld.UnmarshalJSON(out); err != nil {
+			return nil, err
+		}
+	} else if err := ld.UnmarshalText(out); err != nil {
 		return nil, err
 	}
 
-	// remove any local devices that has no mapped volume information
+	removeInvalidLocalDevices(ctx, ld)
+	return ld, nil
+}
+
+// removeInvalidLocalDevices removes any local devices that have no mapped
+// volume information.
+func removeInvalidLocalDevices(ctx types.Context, ld *types.LocalDevices) {
 	for k, v := range ld.DeviceMap {
 		if len(v) == 0 {
 			ctx.WithField("deviceID", k).Warn(
@@ -420,8 +537,6 @@ func unmarshalLocalDevices(
 			delete(ld.DeviceMap, k)
 		}
 	}
-
-	return ld, nil
 }
 
 func (c *client) runExecutor(
@@ -432,6 +547,10 @@ func (c *client) runExecutor(
 			c.clientType, "runExecutor")
 	}
 
+	if c.lsxClient != nil {
+		return c.runExecutorRPC(ctx, args...)
+	}
+
 	ctx.Debug("waiting on executor lock")
 	if err := c.lsxMutexWait(); err != nil {
 		return nil, err
@@ -444,7 +563,14 @@ func (c *client) runExecutor(
 		}
 	}()
 
-	lsxBin := types.LSX.String()
+	return c.execExecutor(ctx, types.LSX.String(), args...)
+}
+
+// execExecutor invokes the executor binary at the provided path. The
+// executor lock must be held.
+func (c *client) execExecutor(
+	ctx types.Context, lsxBin string, args ...string) ([]byte, error) {
+
 	cmd := exec.Command(lsxBin, args...)
 	cmd.Env = os.Environ()
 
@@ -0,0 +1,115 @@
+package libstorage
+
+import (
+	"path/filepath"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// cleanupForceDetached removes the mounts and the mapping of a volume that
+// was forcefully detached from the client's instance, such as after the
+// instance was thought to have failed. The event applies to the instance if
+// it names the instance or, when the instance is unknown to the server, if
+// the volume is still mapped on the instance.
+func (c *client) cleanupForceDetached(ctx types.Context, ev *types.Event) {
+
+	ctx = ctx.WithValue(context.ServiceKey, ev.Service)
+	eventIID := ev.Fields[types.EventFieldInstanceID]
+	fields := log.Fields{
+		"volumeID":   ev.VolumeID,
+		"instanceID": eventIID,
+	}
+
+	store := utils.NewStore()
+	iid, err := c.InstanceID(ctx, store)
+	if err != nil {
+		ctx.WithFields(fields).WithError(err).Debug(
+			"error getting instance ID for forced detach")
+		return
+	}
+	if eventIID != "" && eventIID != iid.ID {
+		return
+	}
+
+	ld, err := c.LocalDevices(ctx, &types.LocalDevicesOpts{
+		ScanType: types.DeviceScanQuick,
+		Opts:     store,
+	})
+	if err != nil {
+		ctx.WithFields(fields).WithError(err).Warn(
+			"error getting local devices for forced detach")
+		return
+	}
+
+	device, mapped := ld.DeviceMap[ev.VolumeID]
+	if eventIID == "" && !mapped {
+		return
+	}
+
+	// the device named by the event is used only if it has not since been
+	// mapped to another volume
+	if !mapped {
+		device = ev.Fields[types.EventFieldDeviceName]
+		for _, d := range ld.DeviceMap {
+			if d == device {
+				device = ""
+				break
+			}
+		}
+	}
+
+	if device != "" {
+		if err := c.unmountDevice(ctx, device, store); err != nil {
+			ctx.WithFields(fields).WithError(err).Error(
+				"error unmounting forcefully detached volume")
+			return
+		}
+	}
+
+	// a volume that is still mapped, such as an RBD image, is unmapped by
+	// detaching it from the instance. the detach is not forced so that the
+	// volume is not detached from another instance to which it may now be
+	// attached.
+	if mapped {
+		if _, err := c.VolumeDetach(
+			ctx, ev.Service, ev.VolumeID,
+			&types.VolumeDetachRequest{}); err != nil {
+			ctx.WithFields(fields).WithError(err).Error(
+				"error unmapping forcefully detached volume")
+			return
+		}
+	}
+
+	ctx.WithFields(fields).Info("cleaned up forcefully detached volume")
+}
+
+// unmountDevice unmounts all of the mounts of the device.
+func (c *client) unmountDevice(
+	ctx types.Context, device string, store types.Store) error {
+
+	mounts, err := c.Mounts(ctx, store)
+	if err != nil {
+		return err
+	}
+
+	devices := map[string]bool{device: true}
+	if p, err := filepath.EvalSymlinks(device); err == nil {
+		devices[p] = true
+	}
+
+	for _, m := range mounts {
+		if !devices[m.Source] {
+			continue
+		}
+		ctx.WithField("mountPoint", m.MountPoint).Info(
+			"unmounting forcefully detached volume")
+		if err := c.Unmount(ctx, m.MountPoint, store); err != nil {
+			return err
+		}
+	}
+	return nil
+}
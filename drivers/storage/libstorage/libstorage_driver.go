@@ -8,6 +8,8 @@ import (
 	"errors"
 	"net"
 	"net/http"
+	"os"
+	"sync"
 	"time"
 
 	log "github.com/Sirupsen/logrus"
@@ -18,6 +20,9 @@ import (
 	"github.com/codedellemc/libstorage/api/context"
 	"github.com/codedellemc/libstorage/api/types"
 	"github.com/codedellemc/libstorage/api/utils"
+	"github.com/codedellemc/libstorage/api/utils/metrics"
+	"github.com/codedellemc/libstorage/api/utils/spiffe"
+	"github.com/codedellemc/libstorage/api/utils/tracing"
 )
 
 var (
@@ -34,6 +39,11 @@ var (
 
 type driver struct {
 	client
+
+	// closed is closed when the driver is closed in order to stop updating
+	// the client's certificate
+	closed    chan struct{}
+	closeOnce sync.Once
 }
 
 func newDriver() types.StorageDriver {
@@ -42,6 +52,38 @@ func newDriver() types.StorageDriver {
 
 var errServerFingerprint = errors.New("invalid server fingerprint")
 
+// dialTLSConfig returns the TLS config used to dial the server. A tls.Config
+// may not be modified once it is used, so a copy with the most recent
+// certificate is returned when the client's certificate is reloaded from its
+// files or fetched from the SPIFFE Workload API.
+func dialTLSConfig(
+	tlsConfig *types.TLSConfig,
+	keyPair *utils.TLSKeyPair,
+	svids *spiffe.Source) *tls.Config {
+
+	if keyPair == nil && svids == nil {
+		return &tlsConfig.Config
+	}
+
+	c := utils.CloneTLSConfig(&tlsConfig.Config)
+	if keyPair != nil {
+		c.Certificates = []tls.Certificate{*keyPair.Certificate()}
+	}
+	if svids != nil {
+		// the server's SVID is verified with the Workload API's trust bundle
+		// once the connection is established
+		c.Certificates = []tls.Certificate{*svids.Certificate()}
+		c.InsecureSkipVerify = true
+	}
+	return c
+}
+
+// Close stops updating the client's certificate.
+func (d *driver) Close() error {
+	d.closeOnce.Do(func() { close(d.closed) })
+	return nil
+}
+
 func (d *driver) Init(ctx types.Context, config gofig.Config) error {
 	logFields := log.Fields{}
 
@@ -60,6 +102,46 @@ func (d *driver) Init(ctx types.Context, config gofig.Config) error {
 		return err
 	}
 
+	// the driver is closed when its context is canceled
+	d.closed = make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			d.Close()
+		case <-d.closed:
+		}
+	}()
+
+	// reload the client's certificate when its files change
+	var keyPair *utils.TLSKeyPair
+	if tlsConfig != nil &&
+		tlsConfig.ReloadInterval > 0 && tlsConfig.CertFile != "" {
+		keyPair, err = utils.NewTLSKeyPair(
+			tlsConfig.CertFile, tlsConfig.KeyFile)
+		if err != nil {
+			return err
+		}
+		go keyPair.Watch(d.ctx, tlsConfig.ReloadInterval, d.closed, nil)
+	}
+
+	// fetch the client's SVID from the SPIFFE Workload API
+	var svids *spiffe.Source
+	if tlsConfig != nil && tlsConfig.SPIFFEWorkloadAPI != "" {
+		svids, err = spiffe.NewSource(
+			d.ctx, tlsConfig.SPIFFEWorkloadAPI, d.closed, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	// use the local socket fast path if the server is co-located
+	if tlsConfig == nil {
+		if sock := getLocalSocket(config, proto, lAddr); sock != "" {
+			logFields["localSocket"] = sock
+			proto, lAddr = "unix", sock
+		}
+	}
+
 	host := getHost(proto, lAddr, tlsConfig)
 	lsxPath := config.GetString(types.ConfigExecutorPath)
 	cliType := types.ParseClientType(config.GetString(types.ConfigClientType))
@@ -70,17 +152,33 @@ func (d *driver) Init(ctx types.Context, config gofig.Config) error {
 	logFields["clientType"] = cliType
 	logFields["disableKeepAlive"] = disableKeepAlive
 
+	keepAlive, err := time.ParseDuration(
+		config.GetString(types.ConfigHTTPKeepAlive))
+	if err != nil {
+		keepAlive = time.Duration(30 * time.Second)
+	}
+	dialer := &net.Dialer{KeepAlive: keepAlive}
+
 	httpTransport := &http.Transport{
 		Dial: func(string, string) (net.Conn, error) {
 			if tlsConfig == nil {
-				return net.Dial(proto, lAddr)
+				return dialer.Dial(proto, lAddr)
 			}
 
-			conn, err := tls.Dial(proto, lAddr, &tlsConfig.Config)
+			conn, err := tls.DialWithDialer(
+				dialer, proto, lAddr, dialTLSConfig(tlsConfig, keyPair, svids))
 			if err != nil {
 				return nil, err
 			}
 
+			if svids != nil {
+				err := svids.VerifyPeer(conn.ConnectionState().PeerCertificates)
+				if err != nil {
+					conn.Close()
+					return nil, err
+				}
+			}
+
 			if len(tlsConfig.PeerFingerprint) > 0 {
 				peerCerts := conn.ConnectionState().PeerCertificates
 				matchedFingerprint := false
@@ -108,12 +206,32 @@ func (d *driver) Init(ctx types.Context, config gofig.Config) error {
 				}
 			}
 
+			if _, err := utils.VerifySPIFFEID(
+				tlsConfig, conn.ConnectionState().PeerCertificates); err != nil {
+				conn.Close()
+				return nil, err
+			}
+
 			return conn, nil
 		},
-		DisableKeepAlives: disableKeepAlive,
+		DisableKeepAlives:   disableKeepAlive,
+		MaxIdleConnsPerHost: config.GetInt(types.ConfigHTTPMaxIdleConnsPerHost),
+	}
+	tuneTransport(httpTransport, config, logFields)
+
+	retryPolicy := getRetryPolicy(config)
+	logFields["retryMaxAttempts"] = retryPolicy.MaxAttempts
+
+	apiClientOpts := []apiclient.Option{apiclient.WithRetryPolicy(retryPolicy)}
+	if config.GetBool(types.ConfigClientCacheETags) {
+		apiClientOpts = append(apiClientOpts, apiclient.WithETagCache())
+	}
+	logFields["cacheETags"] = config.GetBool(types.ConfigClientCacheETags)
+	if t := config.GetString(types.ConfigClientAdminToken); t != "" {
+		apiClientOpts = append(apiClientOpts, apiclient.WithAdminToken(t))
 	}
 
-	apiClient := apiclient.New(host, httpTransport)
+	apiClient := apiclient.New(host, httpTransport, apiClientOpts...)
 	logReq := config.GetBool(types.ConfigLogHTTPRequests)
 	logRes := config.GetBool(types.ConfigLogHTTPResponses)
 	apiClient.LogRequests(logReq)
@@ -137,7 +255,7 @@ func (d *driver) Init(ctx types.Context, config gofig.Config) error {
 		newIIDCache := utils.NewStore
 		dur, err := time.ParseDuration(
 			config.GetString(types.ConfigClientCacheInstanceID))
-		if err != nil {
+		if err == nil && dur > 0 {
 			logFields["iidCacheDuration"] = dur.String()
 			newIIDCache = func() types.Store {
 				return utils.NewTTLStore(dur, true)
@@ -149,6 +267,41 @@ func (d *driver) Init(ctx types.Context, config gofig.Config) error {
 		d.instanceIDCache = newIIDCache()
 	}
 
+	if dur, err := time.ParseDuration(
+		config.GetString(types.ConfigClientCacheInstance)); err == nil &&
+		dur > 0 {
+		logFields["instanceCacheDuration"] = dur.String()
+		d.instanceCache = utils.NewTTLStore(dur, true)
+	}
+
+	if config.GetBool(types.ConfigClientCacheVolumes) {
+		ttl, err := time.ParseDuration(
+			config.GetString(types.ConfigClientCacheVolumesTTL))
+		if err != nil || ttl <= 0 {
+			ttl = time.Minute
+		}
+		logFields["cacheVolumes"] = true
+		logFields["cacheVolumesTTL"] = ttl.String()
+		d.volumeCache = newVolumeCache(ttl)
+	}
+
+	if config.GetBool(types.ConfigClientForceDetachCleanup) &&
+		!d.isController() {
+		logFields["forceDetachCleanup"] = true
+		d.forceDetachCleanup = true
+	}
+
+	// the client's tracer is used for the life of the process, so the closer
+	// returned by the tracing package is not retained
+	if _, err := tracing.Init(d.ctx, config, "libstorage-client"); err != nil {
+		return err
+	}
+	metrics.Init(config)
+
+	if err := utils.InitExec(config); err != nil {
+		return err
+	}
+
 	d.ctx.WithFields(logFields).Info("created libStorage client")
 
 	if err := d.dial(ctx); err != nil {
@@ -156,5 +309,55 @@ func (d *driver) Init(ctx types.Context, config gofig.Config) error {
 	}
 
 	d.ctx.Info("successefully dialed libStorage server")
+
+	if d.volumeCache != nil || d.forceDetachCleanup {
+		for _, service := range d.serviceCache.Keys() {
+			go d.watchEvents(d.ctx, service)
+		}
+	}
+
 	return nil
 }
+
+func getRetryPolicy(config gofig.Config) *apiclient.RetryPolicy {
+	initialBackoff, err := time.ParseDuration(
+		config.GetString(types.ConfigClientRetryInitialBackoff))
+	if err != nil {
+		initialBackoff = time.Duration(100 * time.Millisecond)
+	}
+	maxBackoff, err := time.ParseDuration(
+		config.GetString(types.ConfigClientRetryMaxBackoff))
+	if err != nil {
+		maxBackoff = time.Duration(5 * time.Second)
+	}
+	return &apiclient.RetryPolicy{
+		MaxAttempts:    config.GetInt(types.ConfigClientRetryMaxAttempts),
+		InitialBackoff: initialBackoff,
+		MaxBackoff:     maxBackoff,
+		Budget:         config.GetInt(types.ConfigClientRetryBudget),
+	}
+}
+
+// getLocalSocket returns the path to the server's local UNIX socket if the
+// client is configured to connect to a loopback TCP address and the socket
+// exists.
+func getLocalSocket(config gofig.Config, proto, lAddr string) string {
+	sock := config.GetString(types.ConfigHTTPLocalSocket)
+	if sock == "" || proto != "tcp" {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(lAddr)
+	if err != nil {
+		return ""
+	}
+	if host != "localhost" {
+		if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+			return ""
+		}
+	}
+	fi, err := os.Stat(sock)
+	if err != nil || fi.Mode()&os.ModeSocket == 0 {
+		return ""
+	}
+	return sock
+}
@@ -19,6 +19,17 @@ func (d *driver) XCLI() types.StorageExecutorCLI {
 	return &d.client
 }
 
+func (d *driver) Capabilities(
+	ctx types.Context) (*types.StorageCapabilities, error) {
+
+	serviceName, ok := context.ServiceName(ctx)
+	if !ok {
+		return nil, goof.New("missing service name")
+	}
+
+	return d.client.ServiceCapabilities(ctx, serviceName)
+}
+
 func (d *driver) NextDeviceInfo(
 	ctx types.Context) (*types.NextDeviceInfo, error) {
 
@@ -120,6 +131,7 @@ func (d *driver) VolumeCreate(
 		EncryptionKey:    opts.EncryptionKey,
 		IOPS:             opts.IOPS,
 		Size:             opts.Size,
+		Thin:             opts.Thin,
 		Type:             opts.Type,
 		Opts:             opts.Opts.Map(),
 	}
@@ -169,6 +181,26 @@ func (d *driver) VolumeCopy(
 	return d.client.VolumeCopy(ctx, serviceName, volumeID, req)
 }
 
+func (d *driver) VolumeImport(
+	ctx types.Context,
+	nativeID, volumeName string,
+	opts types.Store) (*types.Volume, error) {
+
+	ctx = d.requireCtx(ctx)
+	serviceName, ok := context.ServiceName(ctx)
+	if !ok {
+		return nil, goof.New("missing service name")
+	}
+
+	req := &types.VolumeImportRequest{
+		NativeID:   nativeID,
+		VolumeName: volumeName,
+		Opts:       opts.Map(),
+	}
+
+	return d.client.VolumeImport(ctx, serviceName, req)
+}
+
 func (d *driver) VolumeSnapshot(
 	ctx types.Context,
 	volumeID, snapshotName string,
@@ -182,12 +214,35 @@ func (d *driver) VolumeSnapshot(
 
 	req := &types.VolumeSnapshotRequest{
 		SnapshotName: snapshotName,
+		Quiesce:      opts.GetBool("quiesce"),
 		Opts:         opts.Map(),
 	}
 
 	return d.client.VolumeSnapshot(ctx, serviceName, volumeID, req)
 }
 
+func (d *driver) SnapshotGroupCreate(
+	ctx types.Context,
+	volumeIDs []string,
+	snapshotName string,
+	opts types.Store) (*types.SnapshotGroup, error) {
+
+	ctx = d.requireCtx(ctx)
+	serviceName, ok := context.ServiceName(ctx)
+	if !ok {
+		return nil, goof.New("missing service name")
+	}
+
+	req := &types.SnapshotGroupCreateRequest{
+		SnapshotName: snapshotName,
+		VolumeIDs:    volumeIDs,
+		Quiesce:      opts.GetBool("quiesce"),
+		Opts:         opts.Map(),
+	}
+
+	return d.client.SnapshotGroupCreate(ctx, serviceName, req)
+}
+
 func (d *driver) VolumeRemove(
 	ctx types.Context,
 	volumeID string,
@@ -231,6 +286,7 @@ func (d *driver) VolumeAttach(
 	req := &types.VolumeAttachRequest{
 		NextDeviceName: nextDevicePtr,
 		Force:          opts.Force,
+		AccessMode:     opts.AccessMode,
 		Opts:           opts.Opts.Map(),
 	}
 
@@ -313,6 +369,7 @@ func (d *driver) SnapshotCopy(
 	req := &types.SnapshotCopyRequest{
 		SnapshotName:  snapshotName,
 		DestinationID: destinationID,
+		Destination:   opts.GetString("destination"),
 		Opts:          opts.Map(),
 	}
 
@@ -337,6 +394,10 @@ func (d *driver) assertProvidesAPIClient() types.ProvidesAPIClient {
 	return d
 }
 
+func (d *driver) assertProvidesStorageCapabilities() types.ProvidesStorageCapabilities {
+	return d
+}
+
 func (d *driver) assertProvidesStorageExecutorCLI() types.ProvidesStorageExecutorCLI {
 	return d
 }
@@ -0,0 +1,27 @@
+// +build go1.7
+
+package libstorage
+
+import (
+	"net/http"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	gofig "github.com/akutz/gofig/types"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+func tuneTransport(
+	t *http.Transport, config gofig.Config, logFields log.Fields) {
+
+	t.MaxIdleConns = config.GetInt(types.ConfigHTTPMaxIdleConns)
+	if dur, err := time.ParseDuration(
+		config.GetString(types.ConfigHTTPIdleConnTimeout)); err == nil {
+		t.IdleConnTimeout = dur
+	}
+
+	logFields["maxIdleConns"] = t.MaxIdleConns
+	logFields["maxIdleConnsPerHost"] = t.MaxIdleConnsPerHost
+	logFields["idleConnTimeout"] = t.IdleConnTimeout
+}
@@ -0,0 +1,16 @@
+// +build !go1.7
+
+package libstorage
+
+import (
+	"net/http"
+
+	log "github.com/Sirupsen/logrus"
+	gofig "github.com/akutz/gofig/types"
+)
+
+func tuneTransport(
+	t *http.Transport, config gofig.Config, logFields log.Fields) {
+
+	logFields["maxIdleConnsPerHost"] = t.MaxIdleConnsPerHost
+}
@@ -0,0 +1,116 @@
+package libstorage
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	gofigCore "github.com/akutz/gofig"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+func newTestKeyPair(t *testing.T) *utils.TLSKeyPair {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "libstorage-client"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(
+		rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	d, err := ioutil.TempDir("", "")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(d)
+
+	certFile := path.Join(d, "client.crt")
+	keyFile := path.Join(d, "client.key")
+	assert.NoError(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(
+		&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(
+		&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+
+	kp, err := utils.NewTLSKeyPair(certFile, keyFile)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return kp
+}
+
+func TestDialTLSConfig(t *testing.T) {
+	tlsConfig := &types.TLSConfig{
+		Config: tls.Config{
+			ServerName: "libstorage-server",
+			MinVersion: tls.VersionTLS12,
+			RootCAs:    x509.NewCertPool(),
+		},
+	}
+
+	assert.True(t, &tlsConfig.Config == dialTLSConfig(tlsConfig, nil, nil))
+
+	kp := newTestKeyPair(t)
+	c := dialTLSConfig(tlsConfig, kp, nil)
+	assert.False(t, &tlsConfig.Config == c)
+	assert.Equal(t, "libstorage-server", c.ServerName)
+	assert.Equal(t, uint16(tls.VersionTLS12), c.MinVersion)
+	assert.True(t, tlsConfig.RootCAs == c.RootCAs)
+	assert.False(t, c.InsecureSkipVerify)
+	if assert.Len(t, c.Certificates, 1) {
+		assert.Equal(t, kp.Certificate().Certificate, c.Certificates[0].Certificate)
+	}
+	assert.Empty(t, tlsConfig.Certificates)
+}
+
+func TestExecutorVerifyKey(t *testing.T) {
+	ctx := context.Background()
+
+	// executors are not verified by default if no key is configured
+	config := gofigCore.New()
+	key, err := executorVerifyKey(ctx, config)
+	assert.NoError(t, err)
+	assert.Nil(t, key)
+
+	// and are rejected if verification is required
+	config.Set(types.ConfigExecutorVerifyRequired, true)
+	_, err = executorVerifyKey(ctx, config)
+	assert.Error(t, err)
+
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	config.Set(types.ConfigExecutorVerifyKey,
+		base64.StdEncoding.EncodeToString(pub))
+	key, err = executorVerifyKey(ctx, config)
+	assert.NoError(t, err)
+	assert.Equal(t, pub, key)
+}
@@ -2,9 +2,13 @@ package libstorage
 
 import (
 	"strings"
+	"time"
+
+	"github.com/akutz/goof"
 
 	"github.com/codedellemc/libstorage/api/context"
 	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
 )
 
 func (c *client) requireCtx(ctx types.Context) types.Context {
@@ -32,8 +36,10 @@ func (c *client) withAllInstanceIDs(ctx types.Context) types.Context {
 	}
 
 	iidm := types.InstanceIDMap{}
-	for _, k := range c.instanceIDCache.Keys() {
-		iidm[k] = c.instanceIDCache.GetInstanceID(k)
+	for _, k := range c.serviceCache.Keys() {
+		if iid := c.instanceID(ctx, k); iid != nil {
+			iidm[k] = iid
+		}
 	}
 
 	if len(iidm) == 0 {
@@ -57,14 +63,56 @@ func (c *client) withInstanceID(
 		return ctx
 	}
 
-	if !c.instanceIDCache.IsSet(si.Name) {
+	// the instance ID being resolved is already in the context when the
+	// server is asked to inspect the instance during its resolution
+	if _, ok := context.InstanceID(ctx); ok &&
+		!c.instanceIDCache.IsSet(si.Name) {
 		return ctx
 	}
 
-	iid := c.instanceIDCache.GetInstanceID(si.Name)
+	iid := c.instanceID(ctx, si.Name)
+	if iid == nil {
+		return ctx
+	}
 	return ctx.WithValue(context.InstanceIDKey, iid)
 }
 
+// instanceID returns the service's cached instance ID. The instance ID is
+// retrieved again if it expired from the cache or was busted.
+func (c *client) instanceID(
+	ctx types.Context, service string) *types.InstanceID {
+
+	if iid := c.instanceIDCache.GetInstanceID(service); iid != nil {
+		return iid
+	}
+
+	iid, err := c.InstanceID(
+		ctx.WithValue(context.ServiceKey, service), utils.NewStore())
+	if err != nil {
+		if err != errExecutorNotSupported {
+			ctx.WithError(err).WithField("service", service).Warn(
+				"error getting instance ID")
+		}
+		return nil
+	}
+	return iid
+}
+
+// bustInstanceCache removes the service's cached instance ID and instance
+// after an operation that depends upon them fails, since the failure may be
+// because they are stale, ex. after the host is moved to another instance.
+// They are retrieved again by the next operation that requires them.
+func (c *client) bustInstanceCache(ctx types.Context, service string) {
+	if c.instanceCache != nil {
+		c.instanceCache.Delete(service)
+	}
+	if c.isController() {
+		return
+	}
+	c.instanceIDCache.Delete(service)
+	ctx.WithField("service", service).Debug("busted instance cache")
+}
+
 func (c *client) withAllLocalDevices(ctx types.Context) (types.Context, error) {
 
 	if c.isController() {
@@ -96,3 +144,93 @@ func (c *client) withAllLocalDevices(ctx types.Context) (types.Context, error) {
 
 	return ctx.WithValue(context.AllLocalDevicesKey, ldm), nil
 }
+
+// freezeVolumes freezes the file systems of the volumes, which must be
+// attached to this instance and mounted, so that they are consistent on disk
+// while the volumes are snapshotted. The returned context's deadline is when
+// the executor thaws the file systems should they not be thawed by the
+// returned function, so the server stops snapshotting the volumes once
+// their file systems may no longer be frozen. If a file system cannot be
+// frozen the file systems that were frozen are thawed and an error is
+// returned.
+func (c *client) freezeVolumes(
+	ctx types.Context,
+	service string,
+	volumeIDs []string) (types.Context, func(), error) {
+
+	mountPoints, err := c.volumeMountPoints(ctx, service, volumeIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	timeout, err := time.ParseDuration(
+		c.config.GetString(types.ConfigExecutorFreezeTimeout))
+	if err != nil || timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	dctx, cancel := context.WithTimeout(ctx, timeout)
+
+	var frozen []string
+	thaw := func() {
+		cancel()
+		for _, mp := range frozen {
+			if err := c.ThawFileSystem(ctx, mp, utils.NewStore()); err != nil {
+				ctx.WithError(err).WithField(
+					"mountPoint", mp).Error("error thawing file system")
+			}
+		}
+	}
+
+	for _, mp := range mountPoints {
+		if err := c.FreezeFileSystem(
+			ctx, mp, timeout, utils.NewStore()); err != nil {
+			thaw()
+			return nil, nil, err
+		}
+		frozen = append(frozen, mp)
+	}
+	return dctx, thaw, nil
+}
+
+// volumeMountPoints returns the paths at which the volumes are mounted on
+// this instance. The mount points are those reported by the executor when
+// it describes the local devices.
+func (c *client) volumeMountPoints(
+	ctx types.Context,
+	service string,
+	volumeIDs []string) ([]string, error) {
+
+	ld, err := c.LocalDevices(ctx, &types.LocalDevicesOpts{
+		ScanType: types.DeviceScanQuick,
+		Opts:     utils.NewStore(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(ld.Devices) == 0 {
+		return nil, goof.WithField(
+			"service", service, "local devices are not described")
+	}
+
+	var mountPoints []string
+	for _, volumeID := range volumeIDs {
+		vol, err := c.VolumeInspect(ctx, service, volumeID,
+			types.VolAttReqWithDevMapOnlyVolsAttachedToInstance)
+		if err != nil {
+			return nil, err
+		}
+		mountPoint := ""
+		for _, a := range vol.Attachments {
+			if d, ok := ld.Devices[a.DeviceName]; ok && d.Mounted() {
+				mountPoint = d.MountPoint
+				break
+			}
+		}
+		if mountPoint == "" {
+			return nil, goof.WithField(
+				"volumeID", volumeID, "volume is not mounted on this instance")
+		}
+		mountPoints = append(mountPoints, mountPoint)
+	}
+	return mountPoints, nil
+}
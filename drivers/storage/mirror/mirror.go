@@ -0,0 +1,128 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_mirror
+
+package mirror
+
+import (
+	"regexp"
+	"strings"
+
+	gofigCore "github.com/akutz/gofig"
+	gofig "github.com/akutz/gofig/types"
+
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+const (
+	// Name is the name of the storage driver
+	Name = "mirror"
+
+	// ConfigPrimary is the config key of the name of the service that
+	// provides the first volume of each mirrored pair.
+	ConfigPrimary = Name + ".primary"
+
+	// ConfigSecondary is the config key of the name of the service that
+	// provides the second volume of each mirrored pair.
+	ConfigSecondary = Name + ".secondary"
+
+	// IDSeparator separates the IDs of the primary and secondary volumes in
+	// the ID of a mirrored volume.
+	IDSeparator = ","
+
+	// mirrorNamePrefix prefixes the names of local mirrors.
+	mirrorNamePrefix = "libstorage-"
+)
+
+func init() {
+	registerConfig()
+}
+
+func registerConfig() {
+	r := gofigCore.NewRegistration("Mirror")
+	r.Key(gofig.String, "", "", "", ConfigPrimary)
+	r.Key(gofig.String, "", "", "", ConfigSecondary)
+	gofigCore.Register(r)
+
+	registry.RegisterConfigSchema(&types.ConfigSchema{
+		Name:       "Mirror",
+		Namespaces: []string{Name},
+		Keys: map[string]types.ConfigKeyType{
+			ConfigPrimary:   types.ConfigKeyString,
+			ConfigSecondary: types.ConfigKeyString,
+		},
+		StorageDriver: Name,
+	})
+}
+
+// JoinID returns the ID of the mirrored volume made up of the primary and
+// secondary volumes with the IDs.
+func JoinID(primaryID, secondaryID string) string {
+	return primaryID + IDSeparator + secondaryID
+}
+
+// SplitID returns the IDs of the primary and secondary volumes of the
+// mirrored volume with the ID. A flag indicating whether or not the ID is
+// the ID of a mirrored volume is also returned.
+func SplitID(id string) (string, string, bool) {
+	parts := strings.SplitN(id, IDSeparator, 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+var invalidMirrorNameRX = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+// MirrorName returns the name of the local mirror into which the devices of
+// the mirrored volume with the name are assembled.
+func MirrorName(volumeName string) string {
+	return mirrorNamePrefix +
+		invalidMirrorNameRX.ReplaceAllString(volumeName, "_")
+}
+
+// PairAttachments returns the attachments of a mirrored volume. Each of the
+// primary volume's attachments is paired with the secondary volume's
+// attachment to the same instance, and the names of both of their devices
+// are recorded in the AttachmentFieldMirrorDevices field. The instances are
+// matched by comparing the instance IDs of the backend services, which
+// differ when the services use different drivers. A primary attachment
+// without a matching secondary attachment is omitted, since the mirror
+// cannot be assembled on its instance.
+func PairAttachments(
+	primary, secondary []*types.VolumeAttachment,
+	primaryIID, secondaryIID *types.InstanceID) []*types.VolumeAttachment {
+
+	if primary == nil {
+		return nil
+	}
+
+	sameInstance := func(p, s *types.VolumeAttachment) bool {
+		if p.InstanceID == nil || s.InstanceID == nil {
+			return false
+		}
+		if primaryIID != nil && secondaryIID != nil &&
+			p.InstanceID.ID == primaryIID.ID {
+			return s.InstanceID.ID == secondaryIID.ID
+		}
+		return p.InstanceID.ID == s.InstanceID.ID
+	}
+
+	paired := []*types.VolumeAttachment{}
+	for _, p := range primary {
+		for _, s := range secondary {
+			if !sameInstance(p, s) {
+				continue
+			}
+			pa := *p
+			pa.Fields = map[string]string{}
+			for k, v := range p.Fields {
+				pa.Fields[k] = v
+			}
+			pa.Fields[types.AttachmentFieldMirrorDevices] =
+				p.DeviceName + IDSeparator + s.DeviceName
+			paired = append(paired, &pa)
+			break
+		}
+	}
+	return paired
+}
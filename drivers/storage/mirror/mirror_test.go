@@ -0,0 +1,55 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_mirror
+
+package mirror
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+func TestSplitID(t *testing.T) {
+	id := JoinID("vol-0123", "pd-4567")
+	assert.Equal(t, "vol-0123,pd-4567", id)
+
+	p, s, ok := SplitID(id)
+	assert.True(t, ok)
+	assert.Equal(t, "vol-0123", p)
+	assert.Equal(t, "pd-4567", s)
+
+	_, _, ok = SplitID("vol-0123")
+	assert.False(t, ok)
+	_, _, ok = SplitID(",pd-4567")
+	assert.False(t, ok)
+}
+
+func TestMirrorName(t *testing.T) {
+	assert.Equal(t, "libstorage-db_orders", MirrorName("db/orders"))
+}
+
+func TestPairAttachments(t *testing.T) {
+	primary := []*types.VolumeAttachment{
+		{DeviceName: "/dev/xvdf", InstanceID: &types.InstanceID{ID: "i-1"}},
+		{DeviceName: "/dev/xvdg", InstanceID: &types.InstanceID{ID: "i-2"}},
+	}
+	secondary := []*types.VolumeAttachment{
+		{DeviceName: "/dev/sdb", InstanceID: &types.InstanceID{ID: "gce-1"}},
+	}
+
+	paired := PairAttachments(primary, secondary,
+		&types.InstanceID{ID: "i-1"}, &types.InstanceID{ID: "gce-1"})
+	if !assert.Len(t, paired, 1) {
+		t.FailNow()
+	}
+	assert.Equal(t, "/dev/xvdf", paired[0].DeviceName)
+	assert.Equal(t, "/dev/xvdf,/dev/sdb",
+		paired[0].Fields[types.AttachmentFieldMirrorDevices])
+	assert.Nil(t, primary[0].Fields)
+
+	// without the local instance IDs the attachments of the services are
+	// paired by their own instance IDs
+	assert.Empty(t, PairAttachments(primary, secondary, nil, nil))
+	assert.Nil(t, PairAttachments(nil, secondary, nil, nil))
+}
@@ -0,0 +1,475 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_mirror
+
+package storage
+
+import (
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/server/services"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+	"github.com/codedellemc/libstorage/drivers/storage/mirror"
+)
+
+// driver mirrors each of its volumes across the volumes with the same name
+// on two backend services. The devices of a mirrored volume's pair of
+// volumes are assembled into a local mirror by the client's OS driver.
+type driver struct {
+	config    gofig.Config
+	primary   string
+	secondary string
+}
+
+// leg is a backend service's volume of a mirrored pair.
+type leg struct {
+	ctx types.Context
+	sd  types.StorageDriver
+	iid *types.InstanceID
+	vol *types.Volume
+}
+
+func init() {
+	registry.RegisterStorageDriver(mirror.Name, newDriver)
+}
+
+func newDriver() types.StorageDriver {
+	return &driver{}
+}
+
+func (d *driver) Name() string {
+	return mirror.Name
+}
+
+func (d *driver) Init(ctx types.Context, config gofig.Config) error {
+	d.config = config
+	d.primary = strings.ToLower(config.GetString(mirror.ConfigPrimary))
+	d.secondary = strings.ToLower(config.GetString(mirror.ConfigSecondary))
+
+	if d.primary == "" || d.secondary == "" {
+		return goof.New(
+			"mirror driver requires primary and secondary services")
+	}
+	if d.primary == d.secondary {
+		return goof.WithField(
+			"service", d.primary, "mirror services must be different")
+	}
+	if svc, ok := context.Service(ctx); ok {
+		name := strings.ToLower(svc.Name())
+		if name == d.primary || name == d.secondary {
+			return goof.WithField(
+				"service", svc.Name(), "mirror service mirrors itself")
+		}
+	}
+
+	ctx.WithFields(log.Fields{
+		"primary":   d.primary,
+		"secondary": d.secondary,
+	}).Warn("mirror storage driver is experimental")
+	return nil
+}
+
+// backend returns a context for, and the driver of, a backend service. The
+// context is logged into the service's storage platform.
+func (d *driver) backend(
+	ctx types.Context,
+	name string) (*leg, error) {
+
+	svc := services.GetStorageService(ctx, name)
+	if svc == nil {
+		return nil, goof.WithField(
+			"service", name, "mirror backend service not found")
+	}
+	bctx, err := context.WithStorageSession(
+		context.WithStorageService(ctx, svc))
+	if err != nil {
+		return nil, err
+	}
+	iid, _ := context.InstanceID(bctx)
+	return &leg{ctx: bctx, sd: svc.Driver(), iid: iid}, nil
+}
+
+// backends returns the primary and secondary backends.
+func (d *driver) backends(ctx types.Context) (*leg, *leg, error) {
+	p, err := d.backend(ctx, d.primary)
+	if err != nil {
+		return nil, nil, err
+	}
+	s, err := d.backend(ctx, d.secondary)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, s, nil
+}
+
+// inspect returns the primary and secondary backends with the volumes of
+// the mirrored volume with the ID.
+func (d *driver) inspect(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeInspectOpts) (*leg, *leg, error) {
+
+	pid, sid, ok := mirror.SplitID(volumeID)
+	if !ok {
+		return nil, nil, utils.NewVolumeNotFoundError(volumeID)
+	}
+	p, s, err := d.backends(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if p.vol, err = p.sd.VolumeInspect(p.ctx, pid, opts); err != nil {
+		return nil, nil, err
+	}
+	if s.vol, err = s.sd.VolumeInspect(s.ctx, sid, opts); err != nil {
+		return nil, nil, err
+	}
+	return p, s, nil
+}
+
+// toVolume returns the mirrored volume made up of the primary and secondary
+// volumes. The mirrored volume is as large, as fast, and as encrypted as the
+// lesser of its volumes.
+func (d *driver) toVolume(p, s *leg) *types.Volume {
+	mv := *p.vol
+	mv.ID = mirror.JoinID(p.vol.ID, s.vol.ID)
+	if s.vol.Size < mv.Size {
+		mv.Size = s.vol.Size
+	}
+	if s.vol.IOPS < mv.IOPS {
+		mv.IOPS = s.vol.IOPS
+	}
+	mv.Encrypted = p.vol.Encrypted && s.vol.Encrypted
+	mv.AttachmentState = attachmentState(
+		p.vol.AttachmentState, s.vol.AttachmentState)
+
+	mv.Fields = map[string]string{}
+	for k, v := range p.vol.Fields {
+		mv.Fields[k] = v
+	}
+	mv.Fields[types.VolumeFieldMirror] = mirror.MirrorName(p.vol.Name)
+
+	mv.Attachments = mirror.PairAttachments(
+		p.vol.Attachments, s.vol.Attachments, p.iid, s.iid)
+	for _, a := range mv.Attachments {
+		a.VolumeID = mv.ID
+	}
+	return &mv
+}
+
+// attachmentState returns the attachment state of a mirrored volume. A
+// volume with one attached and one available volume is reported as
+// available so that attaching it attaches the missing volume.
+func attachmentState(
+	p, s types.VolumeAttachmentStates) types.VolumeAttachmentStates {
+
+	switch {
+	case p == s:
+		return p
+	case p == types.VolumeAttachmentStateUnknown ||
+		s == types.VolumeAttachmentStateUnknown:
+		return types.VolumeAttachmentStateUnknown
+	case p == types.VolumeAvailable || s == types.VolumeAvailable:
+		return types.VolumeAvailable
+	}
+	return types.VolumeUnavailable
+}
+
+func (d *driver) Type(ctx types.Context) (types.StorageType, error) {
+	return types.Block, nil
+}
+
+func (d *driver) NextDeviceInfo(
+	ctx types.Context) (*types.NextDeviceInfo, error) {
+
+	p, err := d.backend(ctx, d.primary)
+	if err != nil {
+		return nil, err
+	}
+	return p.sd.NextDeviceInfo(p.ctx)
+}
+
+func (d *driver) InstanceInspect(
+	ctx types.Context,
+	opts types.Store) (*types.Instance, error) {
+
+	p, err := d.backend(ctx, d.primary)
+	if err != nil {
+		return nil, err
+	}
+	return p.sd.InstanceInspect(p.ctx, opts)
+}
+
+// HealthCheck returns an error if either backend service does not exist or
+// its storage platform is unhealthy.
+func (d *driver) HealthCheck(ctx types.Context) error {
+	for _, name := range []string{d.primary, d.secondary} {
+		b, err := d.backend(ctx, name)
+		if err != nil {
+			return err
+		}
+		if hc, ok := b.sd.(types.ProvidesHealthCheck); ok {
+			if err := hc.HealthCheck(b.ctx); err != nil {
+				return goof.WithFieldE(
+					"service", name, "mirror backend unhealthy", err)
+			}
+		}
+	}
+	return nil
+}
+
+// Capabilities returns the capabilities shared by the backend services.
+// Mirrored volumes cannot be snapshotted, copied, or resized.
+func (d *driver) Capabilities(
+	ctx types.Context) (*types.StorageCapabilities, error) {
+
+	p, s, err := d.backends(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var caps []*types.StorageCapabilities
+	for _, b := range []*leg{p, s} {
+		c := &types.StorageCapabilities{}
+		if pc, ok := b.sd.(types.ProvidesStorageCapabilities); ok {
+			if c, err = pc.Capabilities(b.ctx); err != nil {
+				return nil, err
+			}
+		}
+		caps = append(caps, c)
+	}
+	cc := utils.CommonCapabilities(caps...)
+	cc.Snapshots = false
+	cc.Clone = false
+	cc.Resize = false
+	return cc, nil
+}
+
+// Volumes returns the mirrored volumes. The volumes of the backend services
+// are paired by name, and a volume without a counterpart on the other
+// service is omitted.
+func (d *driver) Volumes(
+	ctx types.Context,
+	opts *types.VolumesOpts) ([]*types.Volume, error) {
+
+	p, s, err := d.backends(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pvols, err := p.sd.Volumes(p.ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	svols, err := s.sd.Volumes(s.ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := map[string]*types.Volume{}
+	for _, v := range svols {
+		byName[v.Name] = v
+	}
+
+	vols := []*types.Volume{}
+	for _, v := range pvols {
+		sv, ok := byName[v.Name]
+		if !ok {
+			ctx.WithField("volumeName", v.Name).Warn(
+				"mirror volume has no secondary volume")
+			continue
+		}
+		pl, sl := *p, *s
+		pl.vol, sl.vol = v, sv
+		vols = append(vols, d.toVolume(&pl, &sl))
+	}
+	return vols, nil
+}
+
+func (d *driver) VolumeInspect(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeInspectOpts) (*types.Volume, error) {
+
+	p, s, err := d.inspect(ctx, volumeID, opts)
+	if err != nil {
+		return nil, err
+	}
+	return d.toVolume(p, s), nil
+}
+
+// VolumeCreate creates a volume with the name on each backend service. The
+// primary volume is removed if the secondary volume cannot be created.
+func (d *driver) VolumeCreate(
+	ctx types.Context,
+	volumeName string,
+	opts *types.VolumeCreateOpts) (*types.Volume, error) {
+
+	p, s, err := d.backends(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if p.vol, err = p.sd.VolumeCreate(p.ctx, volumeName, opts); err != nil {
+		return nil, err
+	}
+	if s.vol, err = s.sd.VolumeCreate(s.ctx, volumeName, opts); err != nil {
+		if rerr := p.sd.VolumeRemove(
+			p.ctx, p.vol.ID, &types.VolumeRemoveOpts{
+				Opts: utils.NewStore(),
+			}); rerr != nil {
+			ctx.WithError(rerr).WithField("volumeID", p.vol.ID).Error(
+				"error removing primary volume of failed mirror")
+		}
+		return nil, err
+	}
+	return d.toVolume(p, s), nil
+}
+
+func (d *driver) VolumeCreateFromSnapshot(
+	ctx types.Context,
+	snapshotID, volumeName string,
+	opts *types.VolumeCreateOpts) (*types.Volume, error) {
+	return nil, types.ErrNotImplemented
+}
+
+func (d *driver) VolumeCopy(
+	ctx types.Context,
+	volumeID, volumeName string,
+	opts types.Store) (*types.Volume, error) {
+	return nil, types.ErrNotImplemented
+}
+
+func (d *driver) VolumeSnapshot(
+	ctx types.Context,
+	volumeID, snapshotName string,
+	opts types.Store) (*types.Snapshot, error) {
+	return nil, types.ErrNotImplemented
+}
+
+// VolumeRemove removes both of a mirrored volume's volumes. The secondary
+// volume is removed even if the primary volume cannot be.
+func (d *driver) VolumeRemove(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeRemoveOpts) error {
+
+	pid, sid, ok := mirror.SplitID(volumeID)
+	if !ok {
+		return utils.NewVolumeNotFoundError(volumeID)
+	}
+	p, s, err := d.backends(ctx)
+	if err != nil {
+		return err
+	}
+	perr := p.sd.VolumeRemove(p.ctx, pid, opts)
+	if err := s.sd.VolumeRemove(s.ctx, sid, opts); err != nil {
+		return err
+	}
+	return perr
+}
+
+// VolumeAttach attaches the volumes of a mirrored volume that are not
+// already attached. The volumes attached by the call are detached if either
+// volume cannot be attached. The tokens of the attached volumes' devices are
+// returned separated by commas.
+func (d *driver) VolumeAttach(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeAttachOpts) (*types.Volume, string, error) {
+
+	p, s, err := d.inspect(ctx, volumeID, &types.VolumeInspectOpts{
+		Attachments: types.VolAttReqForInstance,
+		Opts:        utils.NewStore(),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	var (
+		tokens   []string
+		attached []*leg
+	)
+	for _, l := range []*leg{p, s} {
+		if l.vol.AttachmentState == types.VolumeAttached && !opts.Force {
+			continue
+		}
+		vol, token, err := l.sd.VolumeAttach(l.ctx, l.vol.ID, opts)
+		if err != nil {
+			for _, a := range attached {
+				if _, derr := a.sd.VolumeDetach(
+					a.ctx, a.vol.ID, &types.VolumeDetachOpts{
+						Opts: utils.NewStore(),
+					}); derr != nil {
+					ctx.WithError(derr).WithField("volumeID", a.vol.ID).Error(
+						"error detaching volume of failed mirror attach")
+				}
+			}
+			return nil, "", err
+		}
+		l.vol = vol
+		attached = append(attached, l)
+		if token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+
+	return d.toVolume(p, s), strings.Join(tokens, mirror.IDSeparator), nil
+}
+
+// VolumeDetach detaches both of a mirrored volume's volumes. The secondary
+// volume is detached even if the primary volume cannot be.
+func (d *driver) VolumeDetach(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeDetachOpts) (*types.Volume, error) {
+
+	pid, sid, ok := mirror.SplitID(volumeID)
+	if !ok {
+		return nil, utils.NewVolumeNotFoundError(volumeID)
+	}
+	p, s, err := d.backends(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var perr error
+	p.vol, perr = p.sd.VolumeDetach(p.ctx, pid, opts)
+	if s.vol, err = s.sd.VolumeDetach(s.ctx, sid, opts); err != nil {
+		return nil, err
+	}
+	if perr != nil {
+		return nil, perr
+	}
+	if p.vol == nil || s.vol == nil {
+		return nil, nil
+	}
+	return d.toVolume(p, s), nil
+}
+
+func (d *driver) Snapshots(
+	ctx types.Context,
+	opts types.Store) ([]*types.Snapshot, error) {
+	return nil, nil
+}
+
+func (d *driver) SnapshotInspect(
+	ctx types.Context,
+	snapshotID string,
+	opts types.Store) (*types.Snapshot, error) {
+	return nil, types.ErrNotImplemented
+}
+
+func (d *driver) SnapshotCopy(
+	ctx types.Context,
+	snapshotID, snapshotName, destinationID string,
+	opts types.Store) (*types.Snapshot, error) {
+	return nil, types.ErrNotImplemented
+}
+
+func (d *driver) SnapshotRemove(
+	ctx types.Context,
+	snapshotID string,
+	opts types.Store) error {
+	return types.ErrNotImplemented
+}
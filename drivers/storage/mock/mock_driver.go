@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	log "github.com/Sirupsen/logrus"
+	gofig "github.com/akutz/gofig/types"
 	"github.com/akutz/goof"
 
 	"github.com/codedellemc/libstorage/api/context"
@@ -27,6 +28,7 @@ type driver struct {
 	volumes        []*types.Volume
 	snapshots      []*types.Snapshot
 	storageType    types.StorageType
+	faults         faultInjector
 }
 
 func init() {
@@ -91,10 +93,41 @@ func newDriver() types.StorageDriver {
 	return d
 }
 
+func (d *driver) Init(ctx types.Context, config gofig.Config) error {
+	if err := d.Executor.Init(ctx, config); err != nil {
+		return err
+	}
+	faults, err := faultsFromConfig(config)
+	if err != nil {
+		return err
+	}
+	return d.faults.set(faults)
+}
+
+// Faults returns the faults the driver injects.
+func (d *driver) Faults(ctx types.Context) (*types.FaultInjection, error) {
+	return d.faults.get(), nil
+}
+
+// SetFaults replaces the faults the driver injects.
+func (d *driver) SetFaults(
+	ctx types.Context, faults *types.FaultInjection) error {
+	return d.faults.set(faults)
+}
+
 func (d *driver) Type(ctx types.Context) (types.StorageType, error) {
 	return types.Block, nil
 }
 
+func (d *driver) Capabilities(
+	ctx types.Context) (*types.StorageCapabilities, error) {
+
+	return &types.StorageCapabilities{
+		Snapshots: true,
+		Clone:     true,
+	}, nil
+}
+
 func (d *driver) NextDeviceInfo(
 	ctx types.Context) (*types.NextDeviceInfo, error) {
 	return d.nextDeviceInfo, nil
@@ -103,6 +136,11 @@ func (d *driver) NextDeviceInfo(
 func (d *driver) InstanceInspect(
 	ctx types.Context,
 	opts types.Store) (*types.Instance, error) {
+
+	if err := d.faults.inject(ctx, "InstanceInspect"); err != nil {
+		return nil, err
+	}
+
 	iid, _ := d.InstanceID(ctx, opts)
 	return &types.Instance{Name: "mockInstance", InstanceID: iid}, nil
 }
@@ -111,6 +149,10 @@ func (d *driver) Volumes(
 	ctx types.Context,
 	opts *types.VolumesOpts) ([]*types.Volume, error) {
 
+	if err := d.faults.inject(ctx, "Volumes"); err != nil {
+		return nil, err
+	}
+
 	xiid := executor.GetInstanceID()
 
 	if serviceName, ok := context.ServiceName(ctx); ok && serviceName == Name {
@@ -159,12 +201,16 @@ func (d *driver) VolumeInspect(
 	volumeID string,
 	opts *types.VolumeInspectOpts) (*types.Volume, error) {
 
+	if err := d.faults.inject(ctx, "VolumeInspect"); err != nil {
+		return nil, err
+	}
+
 	for _, v := range d.volumes {
 		if strings.ToLower(v.ID) == strings.ToLower(volumeID) {
 			return v, nil
 		}
 	}
-	return nil, utils.NewNotFoundError(volumeID)
+	return nil, utils.NewVolumeNotFoundError(volumeID)
 }
 
 func (d *driver) VolumeCreate(
@@ -172,6 +218,10 @@ func (d *driver) VolumeCreate(
 	name string,
 	opts *types.VolumeCreateOpts) (*types.Volume, error) {
 
+	if err := d.faults.inject(ctx, "VolumeCreate"); err != nil {
+		return nil, err
+	}
+
 	if name == "Volume 010" {
 		return nil, goof.WithFieldE(
 			"iops", opts.IOPS,
@@ -223,6 +273,10 @@ func (d *driver) VolumeCreateFromSnapshot(
 	snapshotID, volumeName string,
 	opts *types.VolumeCreateOpts) (*types.Volume, error) {
 
+	if err := d.faults.inject(ctx, "VolumeCreateFromSnapshot"); err != nil {
+		return nil, err
+	}
+
 	s, err := d.SnapshotInspect(ctx, snapshotID, nil)
 	if err != nil {
 		return nil, err
@@ -268,6 +322,10 @@ func (d *driver) VolumeCopy(
 	volumeID, volumeName string,
 	opts types.Store) (*types.Volume, error) {
 
+	if err := d.faults.inject(ctx, "VolumeCopy"); err != nil {
+		return nil, err
+	}
+
 	ctx.WithFields(log.Fields{
 		"volumeID":   volumeID,
 		"volumeName": volumeName,
@@ -307,6 +365,10 @@ func (d *driver) VolumeSnapshot(
 	volumeID, snapshotName string,
 	opts types.Store) (*types.Snapshot, error) {
 
+	if err := d.faults.inject(ctx, "VolumeSnapshot"); err != nil {
+		return nil, err
+	}
+
 	ctx.WithFields(log.Fields{
 		"volumeID":     volumeID,
 		"snapshotName": snapshotName,
@@ -331,6 +393,10 @@ func (d *driver) VolumeRemove(
 	volumeID string,
 	opts *types.VolumeRemoveOpts) error {
 
+	if err := d.faults.inject(ctx, "VolumeRemove"); err != nil {
+		return err
+	}
+
 	ctx.WithFields(log.Fields{
 		"volumeID": volumeID,
 	}).Debug("mockDriver.VolumeRemove")
@@ -346,7 +412,7 @@ func (d *driver) VolumeRemove(
 	}
 
 	if volume == nil {
-		return utils.NewNotFoundError(volumeID)
+		return utils.NewVolumeNotFoundError(volumeID)
 	}
 
 	d.volumes = append(d.volumes[:xToRemove], d.volumes[xToRemove+1:]...)
@@ -359,6 +425,10 @@ func (d *driver) VolumeAttach(
 	volumeID string,
 	opts *types.VolumeAttachOpts) (*types.Volume, string, error) {
 
+	if err := d.faults.inject(ctx, "VolumeAttach"); err != nil {
+		return nil, "", err
+	}
+
 	var modVol *types.Volume
 	for _, vol := range d.volumes {
 		if vol.ID == volumeID {
@@ -367,6 +437,14 @@ func (d *driver) VolumeAttach(
 		}
 	}
 
+	// a flaky attach reports success without attaching the volume, as if
+	// the volume's device never appeared
+	if d.faults.flakyAttach() {
+		ctx.WithField("volumeID", volumeID).Debug(
+			"mockDriver injected flaky attach")
+		return modVol, "1234", nil
+	}
+
 	modVol.Attachments = []*types.VolumeAttachment{
 		&types.VolumeAttachment{
 			DeviceName: *opts.NextDevice,
@@ -385,6 +463,10 @@ func (d *driver) VolumeDetach(
 	volumeID string,
 	opts *types.VolumeDetachOpts) (*types.Volume, error) {
 
+	if err := d.faults.inject(ctx, "VolumeDetach"); err != nil {
+		return nil, err
+	}
+
 	var modVol *types.Volume
 	for _, vol := range d.volumes {
 		if vol.ID == volumeID {
@@ -403,6 +485,10 @@ func (d *driver) VolumeDetachAll(
 	volumeID string,
 	opts types.Store) error {
 
+	if err := d.faults.inject(ctx, "VolumeDetachAll"); err != nil {
+		return err
+	}
+
 	for _, vol := range d.volumes {
 		vol.Attachments = nil
 	}
@@ -414,6 +500,10 @@ func (d *driver) Snapshots(
 	ctx types.Context,
 	opts types.Store) ([]*types.Snapshot, error) {
 
+	if err := d.faults.inject(ctx, "Snapshots"); err != nil {
+		return nil, err
+	}
+
 	return d.snapshots, nil
 }
 
@@ -422,6 +512,10 @@ func (d *driver) SnapshotInspect(
 	snapshotID string,
 	opts types.Store) (*types.Snapshot, error) {
 
+	if err := d.faults.inject(ctx, "SnapshotInspect"); err != nil {
+		return nil, err
+	}
+
 	for _, v := range d.snapshots {
 		if strings.ToLower(v.ID) == strings.ToLower(snapshotID) {
 			return v, nil
@@ -435,6 +529,10 @@ func (d *driver) SnapshotCopy(
 	snapshotID, snapshotName, destinationID string,
 	opts types.Store) (*types.Snapshot, error) {
 
+	if err := d.faults.inject(ctx, "SnapshotCopy"); err != nil {
+		return nil, err
+	}
+
 	ctx.WithFields(log.Fields{
 		"snapshotID":    snapshotID,
 		"snapshotName":  snapshotName,
@@ -472,6 +570,10 @@ func (d *driver) SnapshotRemove(
 	snapshotID string,
 	opts types.Store) error {
 
+	if err := d.faults.inject(ctx, "SnapshotRemove"); err != nil {
+		return err
+	}
+
 	ctx.WithFields(log.Fields{
 		"snapshotID": snapshotID,
 	}).Debug("mockDriver.SnapshotRemove")
@@ -487,7 +589,7 @@ func (d *driver) SnapshotRemove(
 	}
 
 	if snapshot == nil {
-		return utils.NewNotFoundError(snapshotID)
+		return utils.NewSnapshotNotFoundError(snapshotID)
 	}
 
 	d.snapshots = append(d.snapshots[:xToRemove], d.snapshots[xToRemove+1:]...)
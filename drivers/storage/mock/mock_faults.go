@@ -0,0 +1,204 @@
+// +build mock
+
+package mock
+
+import (
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+const (
+	// ConfigFaultsSeed is the config key for the seed of the generator that
+	// decides which operations fail.
+	ConfigFaultsSeed = Name + ".faults.seed"
+
+	// ConfigFaultsOps is the config key for the names of the operations
+	// into which the configured latency and errors are injected. The faults
+	// are injected into all operations if no operations are named.
+	ConfigFaultsOps = Name + ".faults.ops"
+
+	// ConfigFaultsLatency is the config key for the duration by which
+	// operations are delayed.
+	ConfigFaultsLatency = Name + ".faults.latency"
+
+	// ConfigFaultsErrorRate is the config key for the probability, from 0
+	// to 1, that an operation fails.
+	ConfigFaultsErrorRate = Name + ".faults.errorRate"
+
+	// ConfigFaultsFlakyAttachRate is the config key for the probability,
+	// from 0 to 1, that a volume attach reports success without attaching
+	// the volume.
+	ConfigFaultsFlakyAttachRate = Name + ".faults.flakyAttachRate"
+)
+
+// faultInjector injects the faults with which the driver is configured into
+// the driver's operations.
+type faultInjector struct {
+	sync.Mutex
+	faults *types.FaultInjection
+	rand   *rand.Rand
+}
+
+// faultsFromConfig returns the faults with which the driver is configured or
+// nil if no faults are configured.
+func faultsFromConfig(config gofig.Config) (*types.FaultInjection, error) {
+
+	latency := config.GetString(ConfigFaultsLatency)
+	errorRate, err := parseRate(config, ConfigFaultsErrorRate)
+	if err != nil {
+		return nil, err
+	}
+	flakyAttachRate, err := parseRate(config, ConfigFaultsFlakyAttachRate)
+	if err != nil {
+		return nil, err
+	}
+	if latency == "" && errorRate == 0 && flakyAttachRate == 0 {
+		return nil, nil
+	}
+
+	faults := &types.FaultInjection{
+		Seed:            int64(config.GetInt(ConfigFaultsSeed)),
+		Ops:             map[string]*types.OperationFault{},
+		FlakyAttachRate: flakyAttachRate,
+	}
+	ops := config.GetStringSlice(ConfigFaultsOps)
+	if len(ops) == 0 {
+		ops = []string{types.FaultInjectionAllOps}
+	}
+	for _, op := range ops {
+		faults.Ops[op] = &types.OperationFault{
+			Latency:   latency,
+			ErrorRate: errorRate,
+		}
+	}
+	return faults, nil
+}
+
+func parseRate(config gofig.Config, key string) (float64, error) {
+	v := config.GetString(key)
+	if v == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, goof.WithFieldE(key, v, "invalid fault rate", err)
+	}
+	return f, nil
+}
+
+func validateFaults(faults *types.FaultInjection) error {
+	if faults == nil {
+		return nil
+	}
+	if faults.FlakyAttachRate < 0 || faults.FlakyAttachRate > 1 {
+		return goof.WithField(
+			"flakyAttachRate", faults.FlakyAttachRate, "invalid fault rate")
+	}
+	for op, f := range faults.Ops {
+		if f == nil {
+			continue
+		}
+		if f.ErrorRate < 0 || f.ErrorRate > 1 {
+			return goof.WithFields(goof.Fields{
+				"op":        op,
+				"errorRate": f.ErrorRate,
+			}, "invalid fault rate")
+		}
+		if f.Latency == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(f.Latency); err != nil {
+			return goof.WithFieldsE(goof.Fields{
+				"op":      op,
+				"latency": f.Latency,
+			}, "invalid fault latency", err)
+		}
+	}
+	return nil
+}
+
+func (fi *faultInjector) get() *types.FaultInjection {
+	fi.Lock()
+	defer fi.Unlock()
+	return fi.faults
+}
+
+// set replaces the injected faults and reseeds the generator so that the
+// same faults fail the same sequence of operations.
+func (fi *faultInjector) set(faults *types.FaultInjection) error {
+	if err := validateFaults(faults); err != nil {
+		return err
+	}
+	fi.Lock()
+	defer fi.Unlock()
+	fi.faults = faults
+	fi.rand = nil
+	if faults != nil {
+		fi.rand = rand.New(rand.NewSource(faults.Seed))
+	}
+	return nil
+}
+
+// opFault returns the faults injected into the operation.
+func (fi *faultInjector) opFault(op string) (time.Duration, bool) {
+	fi.Lock()
+	defer fi.Unlock()
+
+	if fi.faults == nil {
+		return 0, false
+	}
+	f, ok := fi.faults.Ops[op]
+	if !ok {
+		f = fi.faults.Ops[types.FaultInjectionAllOps]
+	}
+	if f == nil {
+		return 0, false
+	}
+
+	latency, _ := time.ParseDuration(f.Latency)
+	fail := f.ErrorRate > 0 && fi.rand.Float64() < f.ErrorRate
+	return latency, fail
+}
+
+// flakyAttach returns a flag indicating whether or not a volume attach
+// should report success without attaching the volume.
+func (fi *faultInjector) flakyAttach() bool {
+	fi.Lock()
+	defer fi.Unlock()
+	if fi.faults == nil || fi.faults.FlakyAttachRate <= 0 {
+		return false
+	}
+	return fi.rand.Float64() < fi.faults.FlakyAttachRate
+}
+
+// inject delays the operation and returns an error if the operation should
+// fail.
+func (fi *faultInjector) inject(ctx types.Context, op string) error {
+	latency, fail := fi.opFault(op)
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if fail {
+		ctx.WithField("op", op).Debug("mockDriver injected fault")
+		return goof.WithFields(goof.Fields{
+			"driver": Name,
+			"op":     op,
+		}, "injected fault")
+	}
+	return nil
+}
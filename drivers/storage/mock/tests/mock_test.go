@@ -8,11 +8,13 @@ import (
 	"strconv"
 	"testing"
 
+	gofigCore "github.com/akutz/gofig"
 	gofig "github.com/akutz/gofig/types"
 	"github.com/akutz/goof"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/registry"
 	"github.com/codedellemc/libstorage/api/server"
 	"github.com/codedellemc/libstorage/api/server/executors"
 	apitests "github.com/codedellemc/libstorage/api/tests"
@@ -67,6 +69,70 @@ func TestStorageDriverVolumes(t *testing.T) {
 		})
 }
 
+func TestFaultInjection(t *testing.T) {
+	d, err := registry.NewStorageDriver(mock.Name)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	ctx := context.Background()
+	config := gofigCore.New()
+	config.Set(mock.ConfigFaultsErrorRate, "1")
+	config.Set(mock.ConfigFaultsOps, []string{"Snapshots"})
+	if !assert.NoError(t, d.Init(ctx, config)) {
+		t.FailNow()
+	}
+
+	_, err = d.Snapshots(ctx, utils.NewStore())
+	assert.Error(t, err)
+	_, err = d.VolumeInspect(ctx, "vol-000", nil)
+	assert.NoError(t, err)
+
+	fd, ok := d.(types.ProvidesFaultInjection)
+	if !assert.True(t, ok) {
+		t.FailNow()
+	}
+
+	faults := &types.FaultInjection{
+		Seed: 42,
+		Ops: map[string]*types.OperationFault{
+			types.FaultInjectionAllOps: &types.OperationFault{
+				ErrorRate: 0.5,
+			},
+		},
+	}
+	inspect := func() []bool {
+		var failed []bool
+		for i := 0; i < 20; i++ {
+			_, err := d.VolumeInspect(ctx, "vol-000", nil)
+			failed = append(failed, err != nil)
+		}
+		return failed
+	}
+
+	// the same seed fails the same sequence of operations
+	assert.NoError(t, fd.SetFaults(ctx, faults))
+	failed := inspect()
+	assert.Contains(t, failed, true)
+	assert.Contains(t, failed, false)
+	assert.NoError(t, fd.SetFaults(ctx, faults))
+	assert.Equal(t, failed, inspect())
+
+	actual, err := fd.Faults(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, faults, actual)
+
+	assert.Error(t, fd.SetFaults(ctx, &types.FaultInjection{
+		Ops: map[string]*types.OperationFault{
+			"VolumeAttach": &types.OperationFault{Latency: "soon"},
+		},
+	}))
+
+	assert.NoError(t, fd.SetFaults(ctx, nil))
+	_, err = d.Snapshots(ctx, utils.NewStore())
+	assert.NoError(t, err)
+}
+
 func TestClient(t *testing.T) {
 	apitests.Run(t, mock.Name, configYAML,
 		func(config gofig.Config, client types.Client, t *testing.T) {
@@ -0,0 +1,105 @@
+// +build !libstorage_storage_executor libstorage_storage_executor_oci
+
+package executor
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	gofig "github.com/akutz/gofig/types"
+
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/drivers/storage/oci"
+	ociUtils "github.com/codedellemc/libstorage/drivers/storage/oci/utils"
+)
+
+const diskByPath = "/dev/disk/by-path"
+
+var (
+	consistentDevice = regexp.MustCompile(`^oraclevd[a-z]+$`)
+	iscsiDevice      = regexp.MustCompile(`^ip-.+:\d+-iscsi-(.+)-lun-\d+$`)
+)
+
+type driver struct {
+	config gofig.Config
+}
+
+func init() {
+	registry.RegisterStorageExecutor(oci.Name, newDriver)
+}
+
+func newDriver() types.StorageExecutor {
+	return &driver{}
+}
+
+func (d *driver) Name() string {
+	return oci.Name
+}
+
+func (d *driver) Init(ctx types.Context, config gofig.Config) error {
+	d.config = config
+	return nil
+}
+
+func (d *driver) InstanceID(
+	ctx types.Context, opts types.Store) (*types.InstanceID, error) {
+	return ociUtils.InstanceID(ctx)
+}
+
+func (d *driver) NextDevice(
+	ctx types.Context, opts types.Store) (string, error) {
+	return "", types.ErrNotImplemented
+}
+
+// LocalDevices maps the consistent device paths of the paravirtualized
+// volumes and the IQNs of the iSCSI volumes to their devices. The iSCSI
+// targets of the volumes attached to the instance are logged in to first.
+func (d *driver) LocalDevices(
+	ctx types.Context,
+	opts *types.LocalDevicesOpts) (*types.LocalDevices, error) {
+
+	d.syncISCSISessions(ctx)
+
+	deviceMap := map[string]string{}
+
+	dir, _ := ioutil.ReadDir(oci.ConsistentDevicePrefix)
+	for _, device := range dir {
+		if !consistentDevice.MatchString(device.Name()) {
+			continue
+		}
+		path := oci.ConsistentDevicePrefix + device.Name()
+		devPath, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			return nil, err
+		}
+		deviceMap[path] = devPath
+	}
+
+	dir, _ = ioutil.ReadDir(diskByPath)
+	for _, device := range dir {
+		m := iscsiDevice.FindStringSubmatch(device.Name())
+		if m == nil {
+			continue
+		}
+		devPath, err := filepath.EvalSymlinks(
+			filepath.Join(diskByPath, device.Name()))
+		if err != nil {
+			return nil, err
+		}
+		deviceMap[strings.ToLower(m[1])] = devPath
+	}
+
+	ld := &types.LocalDevices{Driver: d.Name()}
+	if len(deviceMap) > 0 {
+		ld.DeviceMap = deviceMap
+	}
+
+	return ld, nil
+}
+
+func (d *driver) Supported(ctx types.Context, opts types.Store) (bool, error) {
+	return ociUtils.IsInstance(ctx)
+}
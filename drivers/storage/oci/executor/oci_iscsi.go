@@ -0,0 +1,181 @@
+// +build !libstorage_storage_executor libstorage_storage_executor_oci
+
+package executor
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+	"github.com/codedellemc/libstorage/drivers/storage/oci"
+	ociUtils "github.com/codedellemc/libstorage/drivers/storage/oci/utils"
+)
+
+const (
+	iscsiadm = "iscsiadm"
+
+	// ociIQNPrefix is the prefix of the IQNs of the iSCSI targets of OCI
+	// block volumes.
+	ociIQNPrefix = "iqn.2015-12.com.oracleiaas:"
+
+	// syncInterval is how often the sessions are synchronized while the
+	// executor waits for a device, since each synchronization lists the
+	// instance's attachments with the OCI API.
+	syncInterval = 5 * time.Second
+)
+
+var lastSync time.Time
+
+// syncISCSISessions logs in to the iSCSI targets of the volumes attached to
+// the instance, and logs out of the targets of the volumes that have been
+// detached. The attachments are listed with the OCI API, which requires the
+// executor to be configured with credentials, ex. to use instance
+// principals. Hosts without iscsiadm or credentials are not synchronized.
+// The sessions are synchronized at most once per sync interval.
+func (d *driver) syncISCSISessions(ctx types.Context) {
+
+	if time.Since(lastSync) < syncInterval {
+		return
+	}
+	lastSync = time.Now()
+
+	if _, err := exec.LookPath(iscsiadm); err != nil {
+		return
+	}
+
+	md, err := ociUtils.GetInstanceMetadata(ctx)
+	if err != nil {
+		ctx.WithError(err).Debug("error getting instance metadata")
+		return
+	}
+	client, err := ociUtils.NewClient(ctx, d.config)
+	if err != nil {
+		ctx.WithError(err).Debug("iscsi sessions not synchronized")
+		return
+	}
+	atts, err := client.ListVolumeAttachments(
+		ctx, md.CompartmentID, "", md.ID)
+	if err != nil {
+		ctx.WithError(err).Warn("error listing volume attachments")
+		return
+	}
+
+	sessions, err := iscsiSessions(ctx)
+	if err != nil {
+		ctx.WithError(err).Warn("error listing iscsi sessions")
+		return
+	}
+
+	attached := map[string]bool{}
+	for _, att := range atts {
+		if att.AttachmentType != oci.AttachmentTypeISCSI ||
+			att.LifecycleState != ociUtils.LifecycleStateAttached {
+			continue
+		}
+		attached[att.IQN] = true
+		if _, ok := sessions[att.IQN]; ok {
+			continue
+		}
+		portal := fmt.Sprintf("%s:%d", att.IPv4, att.Port)
+		if err := iscsiLogin(ctx, att.IQN, portal); err != nil {
+			ctx.WithFields(log.Fields{
+				"iqn":    att.IQN,
+				"portal": portal,
+			}).WithError(err).Error("error logging in to iscsi target")
+		}
+	}
+
+	for iqn, portal := range sessions {
+		if attached[iqn] || !strings.HasPrefix(iqn, ociIQNPrefix) {
+			continue
+		}
+		if err := iscsiLogout(ctx, iqn, portal); err != nil {
+			ctx.WithFields(log.Fields{
+				"iqn":    iqn,
+				"portal": portal,
+			}).WithError(err).Error("error logging out of iscsi target")
+		}
+	}
+}
+
+// iscsiSessions returns the portals of the host's iSCSI sessions keyed by
+// their targets' IQNs.
+func iscsiSessions(ctx types.Context) (map[string]string, error) {
+	out, err := utils.CommandContext(
+		ctx, iscsiadm, "-m", "session").CombinedOutput()
+	if err != nil {
+		// iscsiadm exits with an error when there are no sessions
+		if strings.Contains(string(out), "No active sessions") {
+			return map[string]string{}, nil
+		}
+		return nil, goof.WithFieldE(
+			"output", string(out), "error listing iscsi sessions", err)
+	}
+
+	// tcp: [1] 169.254.2.2:3260,1 iqn.2015-12.com.oracleiaas:... (non-flash)
+	sessions := map[string]string{}
+	for _, line := range strings.Split(string(out), "\n") {
+		f := strings.Fields(line)
+		if len(f) < 4 {
+			continue
+		}
+		sessions[f[3]] = strings.SplitN(f[2], ",", 2)[0]
+	}
+	return sessions, nil
+}
+
+// iscsiLogin logs in to the target, which is logged in to automatically
+// when the host boots.
+func iscsiLogin(ctx types.Context, iqn, portal string) error {
+	ctx.WithFields(log.Fields{
+		"iqn":    iqn,
+		"portal": portal,
+	}).Info("logging in to iscsi target")
+
+	for _, args := range [][]string{
+		{"-m", "node", "-o", "new", "-T", iqn, "-p", portal},
+		{"-m", "node", "-o", "update", "-T", iqn,
+			"-n", "node.startup", "-v", "automatic"},
+		{"-m", "node", "-T", iqn, "-p", portal, "-l"},
+	} {
+		if err := runISCSIAdm(ctx, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// iscsiLogout logs out of the target and removes its node record.
+func iscsiLogout(ctx types.Context, iqn, portal string) error {
+	ctx.WithFields(log.Fields{
+		"iqn":    iqn,
+		"portal": portal,
+	}).Info("logging out of iscsi target")
+
+	for _, args := range [][]string{
+		{"-m", "node", "-T", iqn, "-p", portal, "-u"},
+		{"-m", "node", "-o", "delete", "-T", iqn, "-p", portal},
+	} {
+		if err := runISCSIAdm(ctx, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runISCSIAdm(ctx types.Context, args ...string) error {
+	out, err := utils.CommandContext(ctx, iscsiadm, args...).CombinedOutput()
+	if err != nil {
+		return goof.WithFieldsE(log.Fields{
+			"args":   strings.Join(args, " "),
+			"output": string(out),
+		}, "error running iscsiadm", err)
+	}
+	return nil
+}
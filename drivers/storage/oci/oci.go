@@ -0,0 +1,191 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_oci
+
+package oci
+
+import (
+	gofigCore "github.com/akutz/gofig"
+	gofig "github.com/akutz/gofig/types"
+)
+
+const (
+	// Name is the name of the driver
+	Name = "oci"
+
+	// InstanceIDFieldRegion is the key used to retrieve the region from the
+	// instance id map
+	InstanceIDFieldRegion = "region"
+
+	// InstanceIDFieldAvailabilityDomain is the key used to retrieve the
+	// availability domain from the instance id map
+	InstanceIDFieldAvailabilityDomain = "availabilityDomain"
+
+	// InstanceIDFieldCompartmentID is the key used to retrieve the
+	// compartment ID from the instance id map
+	InstanceIDFieldCompartmentID = "compartmentID"
+
+	// AttachmentTypeParavirtualized attaches a volume as a paravirtualized
+	// device, which requires no configuration of the instance
+	AttachmentTypeParavirtualized = "paravirtualized"
+
+	// AttachmentTypeISCSI attaches a volume as an iSCSI target, which the
+	// executor logs in to
+	AttachmentTypeISCSI = "iscsi"
+
+	// BackupPolicyOCIDPrefix is the prefix of the OCIDs of volume backup
+	// policies
+	BackupPolicyOCIDPrefix = "ocid1.volumebackuppolicy."
+
+	// ConsistentDevicePrefix is the prefix of the consistent device paths
+	// with which paravirtualized volumes are attached, ex.
+	// /dev/oracleoci/oraclevdb
+	ConsistentDevicePrefix = "/dev/oracleoci/"
+
+	// DefaultAttachmentType is the default value of ConfigOCIAttachmentType
+	DefaultAttachmentType = AttachmentTypeParavirtualized
+
+	// DefaultActionTimeout is the default value of ConfigOCIActionTimeout
+	DefaultActionTimeout = "5m"
+
+	// MinVolumeSize is the minimum size of a volume in GiB
+	MinVolumeSize = 50
+
+	// MaxVolumeSize is the maximum size of a volume in GiB
+	MaxVolumeSize = 32768
+
+	// VolumeOptAttachmentType is the volume attach option that specifies
+	// the attachment type, "paravirtualized" or "iscsi"
+	VolumeOptAttachmentType = "attachmentType"
+
+	// VolumeOptBackupPolicy is the volume create option that specifies the
+	// name or OCID of the backup policy assigned to the volume
+	VolumeOptBackupPolicy = "backupPolicy"
+
+	// VolumeFieldBackupPolicyID is the key of the volume field that contains
+	// the OCID of the volume's backup policy
+	VolumeFieldBackupPolicyID = "backupPolicyID"
+
+	// AttachmentFieldType is the key of the attachment field that contains
+	// the attachment type
+	AttachmentFieldType = "attachmentType"
+
+	// AttachmentFieldIQN is the key of the attachment field that contains
+	// the IQN of an iSCSI attachment's target
+	AttachmentFieldIQN = "iqn"
+
+	// ConfigOCIRegion is the key for the region in the config file
+	ConfigOCIRegion = Name + ".region"
+
+	// ConfigOCICompartmentID is the key for the compartment ID in the config
+	// file
+	ConfigOCICompartmentID = Name + ".compartmentID"
+
+	// ConfigOCIAvailabilityDomain is the key for the availability domain in
+	// the config file
+	ConfigOCIAvailabilityDomain = Name + ".availabilityDomain"
+
+	// ConfigOCIUseInstancePrincipals is the key for whether or not the
+	// driver authenticates as the instance on which it runs in the config
+	// file
+	ConfigOCIUseInstancePrincipals = Name + ".useInstancePrincipals"
+
+	// ConfigOCITenancyID is the key for the tenancy ID in the config file
+	ConfigOCITenancyID = Name + ".tenancyID"
+
+	// ConfigOCIUserID is the key for the user ID in the config file
+	ConfigOCIUserID = Name + ".userID"
+
+	// ConfigOCIFingerprint is the key for the fingerprint of the user's API
+	// signing key in the config file
+	ConfigOCIFingerprint = Name + ".fingerprint"
+
+	// ConfigOCIKeyFile is the key for the path of the user's API signing key
+	// in the config file
+	ConfigOCIKeyFile = Name + ".keyFile"
+
+	// ConfigOCIAttachmentType is the key for the default attachment type in
+	// the config file
+	ConfigOCIAttachmentType = Name + ".attachmentType"
+
+	// ConfigOCIBackupPolicy is the key for the name or OCID of the backup
+	// policy assigned to new volumes in the config file
+	ConfigOCIBackupPolicy = Name + ".backupPolicy"
+
+	// ConfigOCIActionTimeout is the key for how long the driver waits for a
+	// volume or attachment to change state in the config file
+	ConfigOCIActionTimeout = Name + ".actionTimeout"
+)
+
+func init() {
+	registerConfig()
+}
+
+func registerConfig() {
+	r := gofigCore.NewRegistration("Oracle Cloud Infrastructure Block Volumes")
+	r.Key(
+		gofig.String,
+		"",
+		"",
+		"The OCI region",
+		ConfigOCIRegion)
+	r.Key(
+		gofig.String,
+		"",
+		"",
+		"The OCID of the compartment of the volumes",
+		ConfigOCICompartmentID)
+	r.Key(
+		gofig.String,
+		"",
+		"",
+		"The availability domain in which volumes are created",
+		ConfigOCIAvailabilityDomain)
+	r.Key(
+		gofig.Bool,
+		"",
+		false,
+		"Authenticate as the instance on which the driver runs",
+		ConfigOCIUseInstancePrincipals)
+	r.Key(
+		gofig.String,
+		"",
+		"",
+		"The OCID of the tenancy",
+		ConfigOCITenancyID)
+	r.Key(
+		gofig.String,
+		"",
+		"",
+		"The OCID of the user",
+		ConfigOCIUserID)
+	r.Key(
+		gofig.String,
+		"",
+		"",
+		"The fingerprint of the user's API signing key",
+		ConfigOCIFingerprint)
+	r.Key(
+		gofig.String,
+		"",
+		"",
+		"The path of the user's API signing key",
+		ConfigOCIKeyFile)
+	r.Key(
+		gofig.String,
+		"",
+		DefaultAttachmentType,
+		"The attachment type, paravirtualized or iscsi",
+		ConfigOCIAttachmentType)
+	r.Key(
+		gofig.String,
+		"",
+		"",
+		"The name or OCID of the backup policy assigned to new volumes",
+		ConfigOCIBackupPolicy)
+	r.Key(
+		gofig.String,
+		"",
+		DefaultActionTimeout,
+		"How long to wait for a volume or attachment to change state",
+		ConfigOCIActionTimeout)
+	gofigCore.Register(r)
+}
@@ -0,0 +1,689 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_oci
+
+package storage
+
+import (
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+
+	"github.com/codedellemc/libstorage/drivers/storage/oci"
+	ociUtils "github.com/codedellemc/libstorage/drivers/storage/oci/utils"
+)
+
+const statePollInterval = 2 * time.Second
+
+type driver struct {
+	config        gofig.Config
+	client        *ociUtils.Client
+	compartmentID string
+}
+
+func init() {
+	registry.RegisterStorageDriver(oci.Name, newDriver)
+}
+
+func newDriver() types.StorageDriver {
+	return &driver{}
+}
+
+func (d *driver) Name() string {
+	return oci.Name
+}
+
+func (d *driver) Init(ctx types.Context, config gofig.Config) error {
+	d.config = config
+
+	client, err := ociUtils.NewClient(ctx, config)
+	if err != nil {
+		return err
+	}
+	d.client = client
+
+	// the compartment of the instance on which the driver runs is used if
+	// one is not configured
+	d.compartmentID = config.GetString(oci.ConfigOCICompartmentID)
+	if d.compartmentID == "" {
+		md, err := ociUtils.GetInstanceMetadata(ctx)
+		if err != nil {
+			return goof.WithError(
+				"compartmentID is required when not running on an instance",
+				err)
+		}
+		d.compartmentID = md.CompartmentID
+	}
+
+	switch d.attachmentType(nil) {
+	case oci.AttachmentTypeParavirtualized, oci.AttachmentTypeISCSI:
+	default:
+		return goof.WithField(
+			"attachmentType", d.attachmentType(nil),
+			"invalid attachment type")
+	}
+	if _, err := time.ParseDuration(d.actionTimeout()); err != nil {
+		return goof.WithFieldE(
+			"actionTimeout", d.actionTimeout(),
+			"invalid action timeout", err)
+	}
+
+	ctx.WithFields(log.Fields{
+		"region":                client.Region,
+		"compartmentID":         d.compartmentID,
+		"useInstancePrincipals": d.useInstancePrincipals(),
+		"attachmentType":        d.attachmentType(nil),
+		"backupPolicy":          d.backupPolicy(nil),
+	}).Info("storage driver initialized")
+
+	return nil
+}
+
+func (d *driver) Type(ctx types.Context) (types.StorageType, error) {
+	return types.Block, nil
+}
+
+// Volumes are bound to an availability domain and may be attached to the
+// instances in the availability domain.
+func (d *driver) Capabilities(
+	ctx types.Context) (*types.StorageCapabilities, error) {
+
+	return &types.StorageCapabilities{
+		MaxVolumeSize: oci.MaxVolumeSize,
+		Topology:      []string{"zone"},
+	}, nil
+}
+
+func (d *driver) NextDeviceInfo(
+	ctx types.Context) (*types.NextDeviceInfo, error) {
+	return nil, nil
+}
+
+func (d *driver) InstanceInspect(
+	ctx types.Context, opts types.Store) (*types.Instance, error) {
+
+	iid := context.MustInstanceID(ctx)
+	return &types.Instance{
+		InstanceID:   iid,
+		Region:       iid.Fields[oci.InstanceIDFieldRegion],
+		Zone:         iid.Fields[oci.InstanceIDFieldAvailabilityDomain],
+		ProviderName: iid.Driver,
+	}, nil
+}
+
+func (d *driver) Volumes(
+	ctx types.Context, opts *types.VolumesOpts) ([]*types.Volume, error) {
+
+	vols, err := d.client.ListVolumes(
+		ctx, d.compartmentID, d.availabilityDomain())
+	if err != nil {
+		return nil, goof.WithError("error listing volumes", err)
+	}
+
+	// the attachments of all of the volumes are listed with one request
+	var atts []*ociUtils.VolumeAttachment
+	if opts.Attachments.Requested() {
+		atts, err = d.client.ListVolumeAttachments(
+			ctx, d.compartmentID, "", "")
+		if err != nil {
+			return nil, goof.WithError("error listing attachments", err)
+		}
+	}
+
+	var volumes []*types.Volume
+	for _, vol := range vols {
+		if isTerminated(vol) {
+			continue
+		}
+		v, err := d.toTypesVolume(ctx, vol, atts, opts.Attachments)
+		if err != nil {
+			return nil, err
+		}
+		volumes = append(volumes, v)
+	}
+	return volumes, nil
+}
+
+func (d *driver) VolumeInspect(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeInspectOpts) (*types.Volume, error) {
+
+	vol, err := d.getVolume(ctx, volumeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var atts []*ociUtils.VolumeAttachment
+	if opts.Attachments.Requested() {
+		if atts, err = d.volumeAttachments(ctx, volumeID); err != nil {
+			return nil, err
+		}
+	}
+
+	volume, err := d.toTypesVolume(ctx, vol, atts, opts.Attachments)
+	if err != nil {
+		return nil, err
+	}
+
+	asg, err := d.client.GetVolumeBackupPolicyAssignment(ctx, volumeID)
+	if err != nil {
+		ctx.WithError(err).Warn("error getting backup policy assignment")
+	} else if asg != nil {
+		volume.Fields[oci.VolumeFieldBackupPolicyID] = asg.PolicyID
+	}
+
+	return volume, nil
+}
+
+func (d *driver) VolumeCreate(
+	ctx types.Context,
+	name string,
+	opts *types.VolumeCreateOpts) (*types.Volume, error) {
+
+	size := int64(oci.MinVolumeSize)
+	if opts.Size != nil && *opts.Size > size {
+		size = *opts.Size
+	}
+	if size > oci.MaxVolumeSize {
+		return nil, utils.NewInvalidRequestError(
+			"size", size, "size exceeds the maximum volume size")
+	}
+
+	ad := d.availabilityDomain()
+	if opts.AvailabilityZone != nil && *opts.AvailabilityZone != "" {
+		ad = *opts.AvailabilityZone
+	}
+	if ad == "" {
+		instance, err := d.InstanceInspect(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		ad = instance.Zone
+	}
+
+	// the backup policy is resolved before the volume is created so that a
+	// volume is not created with a policy that does not exist
+	policyID, err := d.backupPolicyID(ctx, d.backupPolicy(opts.Opts))
+	if err != nil {
+		return nil, err
+	}
+
+	fields := log.Fields{
+		"volumeName":         name,
+		"size":               size,
+		"availabilityDomain": ad,
+		"backupPolicyID":     policyID,
+	}
+	ctx.WithFields(fields).Debug("creating volume")
+
+	vol, err := d.client.CreateVolume(ctx, d.compartmentID, ad, name, size)
+	if err != nil {
+		return nil, goof.WithFieldsE(fields, "error creating volume", err)
+	}
+	fields["volumeID"] = vol.ID
+
+	if err := d.waitForVolume(ctx, vol.ID); err != nil {
+		return nil, goof.WithFieldsE(fields, "error creating volume", err)
+	}
+
+	if policyID != "" {
+		if err := d.client.AssignVolumeBackupPolicy(
+			ctx, vol.ID, policyID); err != nil {
+			// a volume without its backup policy is removed rather than
+			// left unprotected
+			if rerr := d.client.DeleteVolume(ctx, vol.ID); rerr != nil {
+				ctx.WithFields(fields).WithError(rerr).Error(
+					"error removing volume without backup policy")
+			}
+			return nil, goof.WithFieldsE(
+				fields, "error assigning backup policy", err)
+		}
+	}
+
+	return d.VolumeInspect(ctx, vol.ID, &types.VolumeInspectOpts{
+		Attachments: types.VolAttReqTrue,
+	})
+}
+
+func (d *driver) VolumeCreateFromSnapshot(
+	ctx types.Context,
+	snapshotID, volumeName string,
+	opts *types.VolumeCreateOpts) (*types.Volume, error) {
+	return nil, types.ErrNotImplemented
+}
+
+func (d *driver) VolumeCopy(
+	ctx types.Context,
+	volumeID, volumeName string,
+	opts types.Store) (*types.Volume, error) {
+	return nil, types.ErrNotImplemented
+}
+
+func (d *driver) VolumeSnapshot(
+	ctx types.Context,
+	volumeID, snapshotName string,
+	opts types.Store) (*types.Snapshot, error) {
+	return nil, types.ErrNotImplemented
+}
+
+func (d *driver) VolumeRemove(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeRemoveOpts) error {
+
+	if _, err := d.getVolume(ctx, volumeID); err != nil {
+		return err
+	}
+
+	atts, err := d.volumeAttachments(ctx, volumeID)
+	if err != nil {
+		return err
+	}
+	if len(atts) > 0 {
+		if !opts.Force {
+			return utils.NewVolumeInUseError(volumeID)
+		}
+		for _, att := range atts {
+			if err := d.detach(ctx, att); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := d.client.DeleteVolume(ctx, volumeID); err != nil {
+		return goof.WithFieldE(
+			"volumeID", volumeID, "error removing volume", err)
+	}
+	return nil
+}
+
+// VolumeAttach attaches the volume as a paravirtualized device at one of
+// the instance's consistent device paths, or as an iSCSI target. The token
+// is the device path or the target's IQN, which the executor maps to the
+// volume's device.
+func (d *driver) VolumeAttach(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeAttachOpts) (*types.Volume, string, error) {
+
+	if _, err := d.getVolume(ctx, volumeID); err != nil {
+		return nil, "", err
+	}
+
+	attType := d.attachmentType(opts.Opts)
+	switch attType {
+	case oci.AttachmentTypeParavirtualized, oci.AttachmentTypeISCSI:
+	default:
+		return nil, "", utils.NewInvalidRequestError(
+			oci.VolumeOptAttachmentType, attType, "invalid attachment type")
+	}
+
+	instanceID := context.MustInstanceID(ctx).ID
+
+	atts, err := d.volumeAttachments(ctx, volumeID)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, att := range atts {
+		if att.InstanceID == instanceID {
+			return nil, "", goof.New("volume already attached to instance")
+		}
+		if !opts.Force {
+			return nil, "", goof.New("volume already attached")
+		}
+		if err := d.detach(ctx, att); err != nil {
+			return nil, "", err
+		}
+	}
+
+	var device string
+	if attType == oci.AttachmentTypeParavirtualized {
+		if device, err = d.nextDevice(ctx, instanceID); err != nil {
+			return nil, "", err
+		}
+	}
+
+	fields := log.Fields{
+		"volumeID":       volumeID,
+		"instanceID":     instanceID,
+		"attachmentType": attType,
+		"device":         device,
+	}
+	ctx.WithFields(fields).Debug("attaching volume")
+
+	att, err := d.client.AttachVolume(
+		ctx, attType, instanceID, volumeID, device)
+	if err != nil {
+		return nil, "", goof.WithFieldsE(fields, "error attaching volume", err)
+	}
+	if att, err = d.waitForAttachment(
+		ctx, att.ID, ociUtils.LifecycleStateAttached); err != nil {
+		return nil, "", goof.WithFieldsE(fields, "error attaching volume", err)
+	}
+
+	attachedVol, err := d.VolumeInspect(
+		ctx, volumeID, &types.VolumeInspectOpts{
+			Attachments: types.VolAttReqTrue,
+			Opts:        opts.Opts,
+		})
+	if err != nil {
+		return nil, "", goof.WithError("error getting volume", err)
+	}
+
+	return attachedVol, attachmentToken(att), nil
+}
+
+func (d *driver) VolumeDetach(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeDetachOpts) (*types.Volume, error) {
+
+	atts, err := d.volumeAttachments(ctx, volumeID)
+	if err != nil {
+		return nil, err
+	}
+	if len(atts) == 0 {
+		return nil, goof.New("volume already detached")
+	}
+
+	for _, att := range atts {
+		if err := d.detach(ctx, att); err != nil {
+			return nil, err
+		}
+	}
+
+	return d.VolumeInspect(ctx, volumeID, &types.VolumeInspectOpts{
+		Attachments: types.VolAttReqTrue,
+		Opts:        opts.Opts,
+	})
+}
+
+func (d *driver) Snapshots(
+	ctx types.Context, opts types.Store) ([]*types.Snapshot, error) {
+	return nil, types.ErrNotImplemented
+}
+
+func (d *driver) SnapshotInspect(
+	ctx types.Context,
+	snapshotID string,
+	opts types.Store) (*types.Snapshot, error) {
+	return nil, types.ErrNotImplemented
+}
+
+func (d *driver) SnapshotCopy(
+	ctx types.Context,
+	snapshotID, snapshotName, destinationID string,
+	opts types.Store) (*types.Snapshot, error) {
+	return nil, types.ErrNotImplemented
+}
+
+func (d *driver) SnapshotRemove(
+	ctx types.Context, snapshotID string, opts types.Store) error {
+	return types.ErrNotImplemented
+}
+
+func (d *driver) getVolume(
+	ctx types.Context, volumeID string) (*ociUtils.Volume, error) {
+
+	vol, err := d.client.GetVolume(ctx, volumeID)
+	if err != nil {
+		if ociUtils.IsNotFound(err) {
+			return nil, utils.NewVolumeNotFoundError(volumeID)
+		}
+		return nil, goof.WithFieldE(
+			"volumeID", volumeID, "error getting volume", err)
+	}
+	if isTerminated(vol) {
+		return nil, utils.NewVolumeNotFoundError(volumeID)
+	}
+	return vol, nil
+}
+
+// volumeAttachments returns the volume's attachments that have not been
+// detached.
+func (d *driver) volumeAttachments(
+	ctx types.Context,
+	volumeID string) ([]*ociUtils.VolumeAttachment, error) {
+
+	atts, err := d.client.ListVolumeAttachments(
+		ctx, d.compartmentID, volumeID, "")
+	if err != nil {
+		return nil, goof.WithFieldE(
+			"volumeID", volumeID, "error listing attachments", err)
+	}
+	var active []*ociUtils.VolumeAttachment
+	for _, att := range atts {
+		if att.LifecycleState != ociUtils.LifecycleStateDetached {
+			active = append(active, att)
+		}
+	}
+	return active, nil
+}
+
+func (d *driver) detach(
+	ctx types.Context, att *ociUtils.VolumeAttachment) error {
+
+	fields := log.Fields{
+		"volumeID":     att.VolumeID,
+		"attachmentID": att.ID,
+	}
+	ctx.WithFields(fields).Debug("detaching volume")
+
+	if err := d.client.DetachVolume(ctx, att.ID); err != nil {
+		return goof.WithFieldsE(fields, "error detaching volume", err)
+	}
+	if _, err := d.waitForAttachment(
+		ctx, att.ID, ociUtils.LifecycleStateDetached); err != nil {
+		return goof.WithFieldsE(fields, "error detaching volume", err)
+	}
+	return nil
+}
+
+// nextDevice returns the first of the instance's available consistent
+// device paths.
+func (d *driver) nextDevice(
+	ctx types.Context, instanceID string) (string, error) {
+
+	devs, err := d.client.ListInstanceDevices(ctx, instanceID)
+	if err != nil {
+		return "", goof.WithFieldE(
+			"instanceID", instanceID, "error listing instance devices", err)
+	}
+	for _, dev := range devs {
+		if dev.IsAvailable {
+			return dev.Name, nil
+		}
+	}
+	return "", goof.WithField(
+		"instanceID", instanceID, "no available device paths")
+}
+
+// backupPolicyID returns the OCID of the backup policy with the name or
+// OCID. Oracle-defined policies, ex. "gold", are matched by their names.
+func (d *driver) backupPolicyID(
+	ctx types.Context, policy string) (string, error) {
+
+	if policy == "" || strings.HasPrefix(policy, oci.BackupPolicyOCIDPrefix) {
+		return policy, nil
+	}
+
+	pols, err := d.client.ListVolumeBackupPolicies(ctx)
+	if err != nil {
+		return "", goof.WithError("error listing backup policies", err)
+	}
+	for _, pol := range pols {
+		if strings.EqualFold(pol.DisplayName, policy) {
+			return pol.ID, nil
+		}
+	}
+	return "", utils.NewInvalidRequestError(
+		oci.VolumeOptBackupPolicy, policy, "backup policy not found")
+}
+
+// waitForVolume waits for the volume to become available.
+func (d *driver) waitForVolume(ctx types.Context, volumeID string) error {
+	return d.waitFor(ctx, func() (bool, error) {
+		vol, err := d.client.GetVolume(ctx, volumeID)
+		if err != nil {
+			return false, err
+		}
+		if isTerminated(vol) {
+			return false, goof.WithField(
+				"lifecycleState", vol.LifecycleState,
+				"volume terminated")
+		}
+		return vol.LifecycleState == ociUtils.LifecycleStateAvailable, nil
+	})
+}
+
+// waitForAttachment waits for the attachment to reach the state.
+func (d *driver) waitForAttachment(
+	ctx types.Context,
+	attachmentID, state string) (*ociUtils.VolumeAttachment, error) {
+
+	var att *ociUtils.VolumeAttachment
+	err := d.waitFor(ctx, func() (bool, error) {
+		var err error
+		if att, err = d.client.GetVolumeAttachment(
+			ctx, attachmentID); err != nil {
+			return false, err
+		}
+		return att.LifecycleState == state, nil
+	})
+	return att, err
+}
+
+// waitFor polls the function until it returns true or an error, or until
+// the action timeout elapses.
+func (d *driver) waitFor(
+	ctx types.Context, done func() (bool, error)) error {
+
+	timeout, err := time.ParseDuration(d.actionTimeout())
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		ok, err := done()
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return goof.WithField(
+				"timeout", timeout, "timed out waiting for state change")
+		}
+		time.Sleep(statePollInterval)
+	}
+}
+
+func (d *driver) toTypesVolume(
+	ctx types.Context,
+	vol *ociUtils.Volume,
+	atts []*ociUtils.VolumeAttachment,
+	attachments types.VolumeAttachmentsTypes) (*types.Volume, error) {
+
+	volume := &types.Volume{
+		Name:             vol.DisplayName,
+		ID:               vol.ID,
+		Size:             vol.SizeInGBs,
+		AvailabilityZone: vol.AvailabilityDomain,
+		Status:           vol.LifecycleState,
+		Fields:           map[string]string{},
+	}
+
+	if !attachments.Requested() {
+		return volume, nil
+	}
+
+	var ld *types.LocalDevices
+	if attachments.Devices() {
+		var ok bool
+		if ld, ok = context.LocalDevices(ctx); !ok {
+			return nil, goof.New("error getting local devices from context")
+		}
+	}
+
+	for _, att := range atts {
+		if att.VolumeID != vol.ID ||
+			att.LifecycleState != ociUtils.LifecycleStateAttached {
+			continue
+		}
+		va := &types.VolumeAttachment{
+			VolumeID: vol.ID,
+			InstanceID: &types.InstanceID{
+				ID:     att.InstanceID,
+				Driver: oci.Name,
+			},
+			Fields: map[string]string{
+				oci.AttachmentFieldType: att.AttachmentType,
+			},
+		}
+		if att.IQN != "" {
+			va.Fields[oci.AttachmentFieldIQN] = att.IQN
+		}
+		if ld != nil {
+			if dev, ok := ld.DeviceMap[attachmentToken(att)]; ok {
+				va.DeviceName = dev
+				va.BusType = "scsi"
+			}
+		}
+		volume.Attachments = append(volume.Attachments, va)
+	}
+
+	return volume, nil
+}
+
+// attachmentToken returns the key of the attachment's device in the map of
+// local devices, the device path of a paravirtualized attachment or the
+// IQN of an iSCSI attachment.
+func attachmentToken(att *ociUtils.VolumeAttachment) string {
+	if att.AttachmentType == oci.AttachmentTypeISCSI {
+		return att.IQN
+	}
+	return att.Device
+}
+
+func isTerminated(vol *ociUtils.Volume) bool {
+	return vol.LifecycleState == ociUtils.LifecycleStateTerminated ||
+		vol.LifecycleState == ociUtils.LifecycleStateTerminating
+}
+
+func (d *driver) attachmentType(opts types.Store) string {
+	if opts != nil {
+		if v := opts.GetString(oci.VolumeOptAttachmentType); v != "" {
+			return strings.ToLower(v)
+		}
+	}
+	return strings.ToLower(d.config.GetString(oci.ConfigOCIAttachmentType))
+}
+
+func (d *driver) backupPolicy(opts types.Store) string {
+	if opts != nil {
+		if v := opts.GetString(oci.VolumeOptBackupPolicy); v != "" {
+			return v
+		}
+	}
+	return d.config.GetString(oci.ConfigOCIBackupPolicy)
+}
+
+func (d *driver) availabilityDomain() string {
+	return d.config.GetString(oci.ConfigOCIAvailabilityDomain)
+}
+
+func (d *driver) useInstancePrincipals() bool {
+	return d.config.GetBool(oci.ConfigOCIUseInstancePrincipals)
+}
+
+func (d *driver) actionTimeout() string {
+	return d.config.GetString(oci.ConfigOCIActionTimeout)
+}
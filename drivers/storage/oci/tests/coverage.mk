@@ -0,0 +1,2 @@
+OCI_COVERPKG := $(ROOT_IMPORT_PATH)/drivers/storage/oci
+TEST_COVERPKG_./drivers/storage/oci/tests := $(OCI_COVERPKG),$(OCI_COVERPKG)/executor
@@ -0,0 +1,428 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_oci
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/drivers/storage/oci"
+)
+
+const (
+	coreAPIVersion = "20160918"
+
+	// LifecycleStateAvailable is the state of a volume that may be attached.
+	LifecycleStateAvailable = "AVAILABLE"
+
+	// LifecycleStateAttached is the state of an attached volume's
+	// attachment.
+	LifecycleStateAttached = "ATTACHED"
+
+	// LifecycleStateDetached is the state of a detached volume's attachment.
+	LifecycleStateDetached = "DETACHED"
+
+	// LifecycleStateTerminated is the state of a deleted volume.
+	LifecycleStateTerminated = "TERMINATED"
+
+	// LifecycleStateTerminating is the state of a volume being deleted.
+	LifecycleStateTerminating = "TERMINATING"
+)
+
+// Client is a client of the parts of the OCI Core Services API used by the
+// driver.
+type Client struct {
+	// Region is the region of the API endpoint.
+	Region string
+
+	endpoint string
+	signer   signer
+}
+
+// Volume is an OCI block volume.
+type Volume struct {
+	ID                 string `json:"id"`
+	DisplayName        string `json:"displayName"`
+	AvailabilityDomain string `json:"availabilityDomain"`
+	CompartmentID      string `json:"compartmentId"`
+	SizeInGBs          int64  `json:"sizeInGBs"`
+	LifecycleState     string `json:"lifecycleState"`
+}
+
+// VolumeAttachment is the attachment of a volume to an instance.
+type VolumeAttachment struct {
+	ID             string `json:"id"`
+	AttachmentType string `json:"attachmentType"`
+	InstanceID     string `json:"instanceId"`
+	VolumeID       string `json:"volumeId"`
+	LifecycleState string `json:"lifecycleState"`
+	Device         string `json:"device,omitempty"`
+	IQN            string `json:"iqn,omitempty"`
+	IPv4           string `json:"ipv4,omitempty"`
+	Port           int    `json:"port,omitempty"`
+}
+
+// VolumeBackupPolicy is a schedule of volume backups.
+type VolumeBackupPolicy struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+}
+
+// VolumeBackupPolicyAssignment assigns a backup policy to a volume.
+type VolumeBackupPolicyAssignment struct {
+	ID       string `json:"id,omitempty"`
+	AssetID  string `json:"assetId"`
+	PolicyID string `json:"policyId"`
+}
+
+// InstanceDevice is a consistent device path of an instance.
+type InstanceDevice struct {
+	Name        string `json:"name"`
+	IsAvailable bool   `json:"isAvailable"`
+}
+
+// apiError is an error returned by the OCI API.
+type apiError struct {
+	status  int
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("oci: %s (%s)", e.Message, e.Code)
+}
+
+// IsNotFound returns a flag indicating whether or not the error is the
+// OCI API's reply to a request for a resource that does not exist.
+func IsNotFound(err error) bool {
+	aerr, ok := err.(*apiError)
+	return ok && aerr.status == http.StatusNotFound
+}
+
+// NewClient returns a client of the region's API that authenticates as the
+// instance on which it runs if configured to use instance principals, or
+// otherwise as the configured user. The region defaults to the instance's
+// region.
+func NewClient(ctx types.Context, config gofig.Config) (*Client, error) {
+
+	region := config.GetString(oci.ConfigOCIRegion)
+	if region == "" {
+		md, err := GetInstanceMetadata(ctx)
+		if err != nil {
+			return nil, goof.WithError(
+				"region is required when not running on an instance", err)
+		}
+		region = md.CanonicalRegionName
+	}
+
+	c := &Client{
+		Region: region,
+		endpoint: fmt.Sprintf(
+			"https://iaas.%s.oraclecloud.com/%s", region, coreAPIVersion),
+	}
+
+	if config.GetBool(oci.ConfigOCIUseInstancePrincipals) {
+		c.signer = &instancePrincipalSigner{region: region}
+		return c, nil
+	}
+
+	var (
+		tenancyID   = config.GetString(oci.ConfigOCITenancyID)
+		userID      = config.GetString(oci.ConfigOCIUserID)
+		fingerprint = config.GetString(oci.ConfigOCIFingerprint)
+		keyFile     = config.GetString(oci.ConfigOCIKeyFile)
+	)
+	if tenancyID == "" || userID == "" || fingerprint == "" || keyFile == "" {
+		return nil, goof.New(
+			"tenancyID, userID, fingerprint, and keyFile are required " +
+				"when not using instance principals")
+	}
+	s, err := newAPIKeySigner(tenancyID, userID, fingerprint, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	c.signer = s
+	return c, nil
+}
+
+// do sends the signed request to the API and decodes the reply into v. The
+// value of the reply's opc-next-page header is returned.
+func (c *Client) do(
+	ctx types.Context,
+	method, path string,
+	query url.Values,
+	body, v interface{}) (string, error) {
+
+	var buf []byte
+	if body != nil {
+		var err error
+		if buf, err = json.Marshal(body); err != nil {
+			return "", err
+		}
+	}
+
+	u := c.endpoint + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var r io.Reader
+	if buf != nil {
+		r = bytes.NewReader(buf)
+	}
+	req, err := http.NewRequest(method, u, r)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "libstorage/"+api.Version.SemVer)
+	req.Header.Set("Accept", "application/json")
+	if err := c.signer.sign(ctx, req, buf); err != nil {
+		return "", err
+	}
+
+	res, err := DoRequest(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		aerr := &apiError{}
+		if err := json.NewDecoder(res.Body).Decode(aerr); err != nil ||
+			aerr.Message == "" {
+			aerr.Message = res.Status
+		}
+		aerr.status = res.StatusCode
+		return "", aerr
+	}
+
+	if v != nil && res.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+			return "", err
+		}
+	}
+	return res.Header.Get("opc-next-page"), nil
+}
+
+// ListVolumes returns the volumes in the compartment. The volumes are
+// limited to the availability domain if it is specified.
+func (c *Client) ListVolumes(
+	ctx types.Context,
+	compartmentID, availabilityDomain string) ([]*Volume, error) {
+
+	q := url.Values{}
+	q.Set("compartmentId", compartmentID)
+	if availabilityDomain != "" {
+		q.Set("availabilityDomain", availabilityDomain)
+	}
+
+	var vols []*Volume
+	for {
+		var page []*Volume
+		next, err := c.do(ctx, http.MethodGet, "/volumes", q, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+		vols = append(vols, page...)
+		if next == "" {
+			return vols, nil
+		}
+		q.Set("page", next)
+	}
+}
+
+// GetVolume returns the volume.
+func (c *Client) GetVolume(
+	ctx types.Context, volumeID string) (*Volume, error) {
+
+	vol := &Volume{}
+	_, err := c.do(ctx, http.MethodGet, "/volumes/"+volumeID, nil, nil, vol)
+	return vol, err
+}
+
+// CreateVolume creates a volume.
+func (c *Client) CreateVolume(
+	ctx types.Context,
+	compartmentID, availabilityDomain, displayName string,
+	sizeInGBs int64) (*Volume, error) {
+
+	vol := &Volume{}
+	_, err := c.do(ctx, http.MethodPost, "/volumes", nil,
+		map[string]interface{}{
+			"compartmentId":      compartmentID,
+			"availabilityDomain": availabilityDomain,
+			"displayName":        displayName,
+			"sizeInGBs":          sizeInGBs,
+		}, vol)
+	return vol, err
+}
+
+// DeleteVolume deletes the volume.
+func (c *Client) DeleteVolume(ctx types.Context, volumeID string) error {
+	_, err := c.do(ctx, http.MethodDelete, "/volumes/"+volumeID, nil, nil, nil)
+	return err
+}
+
+// ListVolumeAttachments returns the volume attachments in the compartment.
+// The attachments are limited to those of the volume and instance if they
+// are specified.
+func (c *Client) ListVolumeAttachments(
+	ctx types.Context,
+	compartmentID, volumeID, instanceID string) ([]*VolumeAttachment, error) {
+
+	q := url.Values{}
+	q.Set("compartmentId", compartmentID)
+	if volumeID != "" {
+		q.Set("volumeId", volumeID)
+	}
+	if instanceID != "" {
+		q.Set("instanceId", instanceID)
+	}
+
+	var atts []*VolumeAttachment
+	for {
+		var page []*VolumeAttachment
+		next, err := c.do(
+			ctx, http.MethodGet, "/volumeAttachments", q, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+		atts = append(atts, page...)
+		if next == "" {
+			return atts, nil
+		}
+		q.Set("page", next)
+	}
+}
+
+// GetVolumeAttachment returns the volume attachment.
+func (c *Client) GetVolumeAttachment(
+	ctx types.Context, attachmentID string) (*VolumeAttachment, error) {
+
+	att := &VolumeAttachment{}
+	_, err := c.do(
+		ctx, http.MethodGet, "/volumeAttachments/"+attachmentID,
+		nil, nil, att)
+	return att, err
+}
+
+// AttachVolume attaches the volume to the instance. A paravirtualized
+// volume is attached at the device, if specified.
+func (c *Client) AttachVolume(
+	ctx types.Context,
+	attachmentType, instanceID, volumeID, device string) (
+	*VolumeAttachment, error) {
+
+	body := map[string]interface{}{
+		"type":       attachmentType,
+		"instanceId": instanceID,
+		"volumeId":   volumeID,
+	}
+	if device != "" {
+		body["device"] = device
+	}
+
+	att := &VolumeAttachment{}
+	_, err := c.do(
+		ctx, http.MethodPost, "/volumeAttachments", nil, body, att)
+	return att, err
+}
+
+// DetachVolume deletes the volume attachment.
+func (c *Client) DetachVolume(ctx types.Context, attachmentID string) error {
+	_, err := c.do(
+		ctx, http.MethodDelete, "/volumeAttachments/"+attachmentID,
+		nil, nil, nil)
+	return err
+}
+
+// ListInstanceDevices returns the instance's available consistent device
+// paths.
+func (c *Client) ListInstanceDevices(
+	ctx types.Context, instanceID string) ([]*InstanceDevice, error) {
+
+	q := url.Values{}
+	q.Set("isAvailable", "true")
+
+	var devs []*InstanceDevice
+	for {
+		var page []*InstanceDevice
+		next, err := c.do(
+			ctx, http.MethodGet, "/instances/"+instanceID+"/devices",
+			q, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+		devs = append(devs, page...)
+		if next == "" {
+			return devs, nil
+		}
+		q.Set("page", next)
+	}
+}
+
+// ListVolumeBackupPolicies returns the Oracle-defined volume backup
+// policies, ex. "gold", "silver", and "bronze".
+func (c *Client) ListVolumeBackupPolicies(
+	ctx types.Context) ([]*VolumeBackupPolicy, error) {
+
+	q := url.Values{}
+
+	var pols []*VolumeBackupPolicy
+	for {
+		var page []*VolumeBackupPolicy
+		next, err := c.do(
+			ctx, http.MethodGet, "/volumeBackupPolicies", q, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+		pols = append(pols, page...)
+		if next == "" {
+			return pols, nil
+		}
+		q.Set("page", next)
+	}
+}
+
+// GetVolumeBackupPolicyAssignment returns the assignment of a backup policy
+// to the volume, or nil if no policy is assigned to the volume.
+func (c *Client) GetVolumeBackupPolicyAssignment(
+	ctx types.Context,
+	volumeID string) (*VolumeBackupPolicyAssignment, error) {
+
+	q := url.Values{}
+	q.Set("assetId", volumeID)
+
+	var asgs []*VolumeBackupPolicyAssignment
+	if _, err := c.do(
+		ctx, http.MethodGet, "/volumeBackupPolicyAssignments",
+		q, nil, &asgs); err != nil {
+		return nil, err
+	}
+	if len(asgs) == 0 {
+		return nil, nil
+	}
+	return asgs[0], nil
+}
+
+// AssignVolumeBackupPolicy assigns the backup policy to the volume.
+func (c *Client) AssignVolumeBackupPolicy(
+	ctx types.Context, volumeID, policyID string) error {
+
+	_, err := c.do(
+		ctx, http.MethodPost, "/volumeBackupPolicyAssignments", nil,
+		&VolumeBackupPolicyAssignment{
+			AssetID:  volumeID,
+			PolicyID: policyID,
+		}, nil)
+	return err
+}
@@ -0,0 +1,97 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_oci
+
+package utils
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/drivers/storage/oci"
+)
+
+const (
+	metadataBase     = "169.254.169.254"
+	metadataURL      = "http://" + metadataBase + "/opc/v2"
+	metadataInstance = metadataURL + "/instance/"
+	metadataIdentity = metadataURL + "/identity"
+
+	// metadataAuthorization is the header required by version 2 of the
+	// instance metadata service.
+	metadataAuthorization = "Bearer Oracle"
+)
+
+// InstanceMetadata is the instance's metadata.
+type InstanceMetadata struct {
+	ID                  string `json:"id"`
+	DisplayName         string `json:"displayName"`
+	CompartmentID       string `json:"compartmentId"`
+	AvailabilityDomain  string `json:"availabilityDomain"`
+	CanonicalRegionName string `json:"canonicalRegionName"`
+}
+
+// GetInstanceMetadata gets the metadata of the instance from the instance
+// metadata service.
+func GetInstanceMetadata(ctx types.Context) (*InstanceMetadata, error) {
+	buf, err := getMetadata(ctx, metadataInstance)
+	if err != nil {
+		return nil, err
+	}
+	md := &InstanceMetadata{}
+	if err := json.Unmarshal(buf, md); err != nil {
+		return nil, goof.WithError("error decoding instance metadata", err)
+	}
+	return md, nil
+}
+
+// InstanceID gets the instance information from the instance metadata
+// service.
+func InstanceID(ctx types.Context) (*types.InstanceID, error) {
+	md, err := GetInstanceMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &types.InstanceID{
+		ID:     md.ID,
+		Driver: oci.Name,
+		Fields: map[string]string{
+			oci.InstanceIDFieldRegion:             md.CanonicalRegionName,
+			oci.InstanceIDFieldAvailabilityDomain: md.AvailabilityDomain,
+			oci.InstanceIDFieldCompartmentID:      md.CompartmentID,
+		},
+	}, nil
+}
+
+// IsInstance is a simple check to see if code is being executed on an OCI
+// instance or not.
+func IsInstance(ctx types.Context) (bool, error) {
+	if _, err := GetInstanceMetadata(ctx); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
+
+func getMetadata(ctx types.Context, url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", metadataAuthorization)
+
+	res, err := DoRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, goof.WithFields(goof.Fields{
+			"url":    url,
+			"status": res.Status,
+		}, "error getting metadata")
+	}
+	return ioutil.ReadAll(res.Body)
+}
@@ -0,0 +1,326 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_oci
+
+package utils
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+const (
+	// tokenRefreshWindow is how long before a security token expires that
+	// the token is replaced so that requests are not sent with a token that
+	// expires in flight.
+	tokenRefreshWindow = 5 * time.Minute
+
+	tenancyOUPrefix = "opc-tenant:"
+)
+
+// signer signs requests to the OCI APIs.
+type signer interface {
+	sign(ctx types.Context, req *http.Request, body []byte) error
+}
+
+// keySigner signs requests with an RSA key as specified by the OCI API's
+// version of the HTTP signatures draft.
+type keySigner struct {
+	keyID string
+	key   *rsa.PrivateKey
+}
+
+// newAPIKeySigner returns a signer of requests from a user with an API
+// signing key.
+func newAPIKeySigner(
+	tenancyID, userID, fingerprint, keyFile string) (*keySigner, error) {
+
+	buf, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, goof.WithFieldE(
+			"keyFile", keyFile, "error reading api signing key", err)
+	}
+	key, err := parsePrivateKey(buf)
+	if err != nil {
+		return nil, goof.WithFieldE(
+			"keyFile", keyFile, "error parsing api signing key", err)
+	}
+	return &keySigner{
+		keyID: tenancyID + "/" + userID + "/" + fingerprint,
+		key:   key,
+	}, nil
+}
+
+func (s *keySigner) sign(
+	ctx types.Context, req *http.Request, body []byte) error {
+
+	if req.Header.Get("Date") == "" {
+		req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	}
+
+	headers := []string{"date", "(request-target)", "host"}
+	switch req.Method {
+	case http.MethodPost, http.MethodPut:
+		sum := sha256.Sum256(body)
+		req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+		req.Header.Set(
+			"X-Content-Sha256", base64.StdEncoding.EncodeToString(sum[:]))
+		if req.Header.Get("Content-Type") == "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		headers = append(
+			headers, "content-length", "content-type", "x-content-sha256")
+	}
+
+	var lines []string
+	for _, h := range headers {
+		switch h {
+		case "(request-target)":
+			lines = append(lines, fmt.Sprintf("%s: %s %s",
+				h, strings.ToLower(req.Method), req.URL.RequestURI()))
+		case "host":
+			lines = append(lines, h+": "+req.URL.Host)
+		default:
+			lines = append(lines, h+": "+req.Header.Get(h))
+		}
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, sum[:])
+	if err != nil {
+		return goof.WithError("error signing request", err)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		`Signature version="1",keyId="%s",algorithm="rsa-sha256",`+
+			`headers="%s",signature="%s"`,
+		s.keyID,
+		strings.Join(headers, " "),
+		base64.StdEncoding.EncodeToString(sig)))
+	return nil
+}
+
+// instancePrincipalSigner signs requests from the instance on which the
+// driver runs. The instance's certificate, which is rotated by OCI, is
+// exchanged for a security token with which requests are signed by a
+// session key. The token is replaced before it expires.
+type instancePrincipalSigner struct {
+	sync.Mutex
+	region  string
+	session *keySigner
+	expires time.Time
+}
+
+func (s *instancePrincipalSigner) sign(
+	ctx types.Context, req *http.Request, body []byte) error {
+
+	session, err := s.ensureFresh(ctx)
+	if err != nil {
+		return err
+	}
+	return session.sign(ctx, req, body)
+}
+
+// ensureFresh returns the signer of the session, which is replaced first if
+// its token expires within the refresh window.
+func (s *instancePrincipalSigner) ensureFresh(
+	ctx types.Context) (*keySigner, error) {
+
+	s.Lock()
+	defer s.Unlock()
+
+	if s.session != nil &&
+		time.Now().Add(tokenRefreshWindow).Before(s.expires) {
+		return s.session, nil
+	}
+	if err := s.refresh(ctx); err != nil {
+		return nil, err
+	}
+	return s.session, nil
+}
+
+// refresh requests a security token for a new session key from the
+// federation service with a request signed by the instance's certificate.
+func (s *instancePrincipalSigner) refresh(ctx types.Context) error {
+
+	certPEM, err := getMetadata(ctx, metadataIdentity+"/cert.pem")
+	if err != nil {
+		return goof.WithError("error getting instance certificate", err)
+	}
+	interPEM, err := getMetadata(ctx, metadataIdentity+"/intermediate.pem")
+	if err != nil {
+		return goof.WithError("error getting intermediate certificate", err)
+	}
+	keyPEM, err := getMetadata(ctx, metadataIdentity+"/key.pem")
+	if err != nil {
+		return goof.WithError("error getting instance key", err)
+	}
+
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		return goof.New("error decoding instance certificate")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return goof.WithError("error parsing instance certificate", err)
+	}
+	tenancyID := tenancyOfCertificate(cert)
+	if tenancyID == "" {
+		return goof.New("instance certificate does not identify tenancy")
+	}
+	instanceKey, err := parsePrivateKey(keyPEM)
+	if err != nil {
+		return goof.WithError("error parsing instance key", err)
+	}
+
+	sessionKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return goof.WithError("error generating session key", err)
+	}
+	sessionPub, err := x509.MarshalPKIXPublicKey(&sessionKey.PublicKey)
+	if err != nil {
+		return goof.WithError("error encoding session key", err)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"certificate":              pemBody(certPEM),
+		"intermediateCertificates": []string{pemBody(interPEM)},
+		"publicKey":                base64.StdEncoding.EncodeToString(sessionPub),
+		"purpose":                  "DEFAULT",
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(
+		http.MethodPost,
+		fmt.Sprintf("https://auth.%s.oraclecloud.com/v1/x509", s.region),
+		bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	fedSigner := &keySigner{
+		keyID: tenancyID + "/fed-x509/" + fingerprint(cert),
+		key:   instanceKey,
+	}
+	if err := fedSigner.sign(ctx, req, body); err != nil {
+		return err
+	}
+
+	res, err := DoRequest(ctx, req)
+	if err != nil {
+		return goof.WithError("error requesting security token", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return goof.WithField(
+			"status", res.Status, "error requesting security token")
+	}
+
+	var reply struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&reply); err != nil {
+		return goof.WithError("error decoding security token", err)
+	}
+	expires, err := tokenExpiry(reply.Token)
+	if err != nil {
+		return err
+	}
+
+	s.session = &keySigner{keyID: "ST$" + reply.Token, key: sessionKey}
+	s.expires = expires
+	return nil
+}
+
+// tenancyOfCertificate returns the OCID of the tenancy identified by the
+// instance's certificate.
+func tenancyOfCertificate(cert *x509.Certificate) string {
+	for _, ou := range cert.Subject.OrganizationalUnit {
+		if strings.HasPrefix(ou, tenancyOUPrefix) {
+			return strings.TrimPrefix(ou, tenancyOUPrefix)
+		}
+	}
+	return ""
+}
+
+// fingerprint returns the SHA-1 fingerprint of the certificate, formatted
+// as colon-separated hex digits.
+func fingerprint(cert *x509.Certificate) string {
+	sum := sha1.Sum(cert.Raw)
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":")
+}
+
+// tokenExpiry returns the expiry of the security token, which is a JWT.
+func tokenExpiry(token string) (time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, goof.New("invalid security token")
+	}
+	buf, err := base64.RawURLEncoding.DecodeString(
+		strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, goof.WithError("invalid security token", err)
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(buf, &claims); err != nil {
+		return time.Time{}, goof.WithError("invalid security token", err)
+	}
+	return time.Unix(claims.Exp, 0), nil
+}
+
+// pemBody returns the base64 encoded body of a PEM block without its
+// header, footer, and line breaks.
+func pemBody(buf []byte) string {
+	var lines []string
+	for _, l := range strings.Split(string(buf), "\n") {
+		l = strings.TrimSpace(l)
+		if l == "" || strings.HasPrefix(l, "-----") {
+			continue
+		}
+		lines = append(lines, l)
+	}
+	return strings.Join(lines, "")
+}
+
+func parsePrivateKey(buf []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(buf)
+	if block == nil {
+		return nil, goof.New("no pem block")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, goof.New("not an rsa key")
+	}
+	return rsaKey, nil
+}
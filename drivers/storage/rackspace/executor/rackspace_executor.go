@@ -4,7 +4,6 @@ package executor
 
 import (
 	"io/ioutil"
-	"os/exec"
 	"regexp"
 	"strings"
 
@@ -13,6 +12,7 @@ import (
 
 	"github.com/codedellemc/libstorage/api/registry"
 	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
 	"github.com/codedellemc/libstorage/drivers/storage/rackspace"
 )
 
@@ -49,7 +49,7 @@ func InstanceID(config gofig.Config) (*types.InstanceID, error) {
 func (d *driver) InstanceID(
 	ctx types.Context,
 	opts types.Store) (*types.InstanceID, error) {
-	cmd := exec.Command("xenstore-read", "name")
+	cmd := utils.Command("xenstore-read", "name")
 	cmd.Env = d.config.EnvVars()
 	cmdOut, err := cmd.Output()
 	if err != nil {
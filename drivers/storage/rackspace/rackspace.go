@@ -1,5 +1,13 @@
 // +build none
 
+// Package rackspace is the Rackspace, or OpenStack Cinder, storage driver.
+// The driver is excluded from every build by its build tag and is not
+// imported by the remote or executor packages, so libStorage does not
+// support Cinder: the driver's Keystone v3 application credential, trust,
+// and token refresh authentication, its volume type and availability zone
+// selection, and its implementation of the volume retype API are not
+// available until the driver's dependencies are restored and the driver is
+// re-enabled.
 package rackspace
 
 import (
@@ -10,6 +18,21 @@ import (
 // Name is the provider's name.
 const Name string = "rackspace"
 
+const (
+	// VolumeFieldVolumeType is the name of the volume field in which the
+	// name of a volume's Cinder volume type is reported.
+	VolumeFieldVolumeType = "volumeType"
+
+	// VolumeFieldAvailabilityZone is the name of the volume field in which
+	// the availability zone of a volume is reported.
+	VolumeFieldAvailabilityZone = "availabilityZone"
+
+	// DefaultMigrationPolicy is the migration policy used when a volume is
+	// retyped without one. Cinder does not migrate a volume's data unless
+	// the policy is "on-demand".
+	DefaultMigrationPolicy = "never"
+)
+
 func init() {
 	r := gofigCore.NewRegistration("Rackspace")
 	r.Key(gofig.String, "", "", "", "rackspace.authURL")
@@ -20,5 +43,11 @@ func init() {
 	r.Key(gofig.String, "", "", "", "rackspace.tenantName")
 	r.Key(gofig.String, "", "", "", "rackspace.domainID")
 	r.Key(gofig.String, "", "", "", "rackspace.domainName")
+	r.Key(gofig.String, "", "", "", "rackspace.applicationCredentialID")
+	r.Key(gofig.String, "", "", "", "rackspace.applicationCredentialName")
+	r.Key(gofig.String, "", "", "", "rackspace.applicationCredentialSecret")
+	r.Key(gofig.String, "", "", "", "rackspace.trustID")
+	r.Key(gofig.String, "", "", "", "rackspace.volumeType")
+	r.Key(gofig.String, "", "", "", "rackspace.availabilityZone")
 	gofigCore.Register(r)
 }
@@ -0,0 +1,276 @@
+// +build none
+
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akutz/goof"
+
+	"github.com/rackspace/gophercloud"
+	"github.com/rackspace/gophercloud/openstack"
+)
+
+// The Keystone v3 authentication below is built only with the rest of the
+// driver, which is disabled, so it is neither compiled nor tested.
+
+const (
+	// tokenRefreshWindow is how long before a token expires that the token
+	// is replaced so that requests are not sent with a token that expires
+	// in flight.
+	tokenRefreshWindow = 5 * time.Minute
+
+	subjectTokenHeader = "X-Subject-Token"
+)
+
+// keystoneToken is a Keystone v3 token and the service catalog returned
+// with it.
+type keystoneToken struct {
+	id        string
+	expiresAt time.Time
+	catalog   []keystoneService
+}
+
+type keystoneService struct {
+	Type      string             `json:"type"`
+	Name      string             `json:"name"`
+	Endpoints []keystoneEndpoint `json:"endpoints"`
+}
+
+type keystoneEndpoint struct {
+	Interface string `json:"interface"`
+	Region    string `json:"region"`
+	RegionID  string `json:"region_id"`
+	URL       string `json:"url"`
+}
+
+// tokenCache holds the Keystone v3 tokens of all of the services that use
+// the driver so that services with the same credentials share a token.
+var tokenCache = &keystoneTokenCache{tokens: map[string]*keystoneToken{}}
+
+type keystoneTokenCache struct {
+	sync.Mutex
+	tokens map[string]*keystoneToken
+}
+
+// get returns the cached token for the key if it does not expire within the
+// refresh window and is not the rejected token. Otherwise a new token is
+// issued with the function and cached. A token rejected by one service is
+// replaced once, and the other services that share it receive its
+// replacement when their requests are rejected.
+func (c *keystoneTokenCache) get(
+	key, rejected string,
+	issue func() (*keystoneToken, error)) (*keystoneToken, error) {
+
+	c.Lock()
+	defer c.Unlock()
+
+	if t, ok := c.tokens[key]; ok && t.id != rejected &&
+		time.Now().Add(tokenRefreshWindow).Before(t.expiresAt) {
+		return t, nil
+	}
+
+	t, err := issue()
+	if err != nil {
+		delete(c.tokens, key)
+		return nil, err
+	}
+	c.tokens[key] = t
+	return t, nil
+}
+
+// usesKeystoneV3Auth returns a flag indicating whether or not the driver
+// authenticates with Keystone v3 application credentials or a trust rather
+// than with gophercloud's password authentication.
+func (d *driver) usesKeystoneV3Auth() bool {
+	return d.applicationCredentialSecret() != "" || d.trustID() != ""
+}
+
+// keystoneV3Client returns a provider client authenticated with a cached
+// Keystone v3 token. The client re-authenticates when a request is rejected
+// with a 401, which replaces the token if it has expired or been revoked.
+func (d *driver) keystoneV3Client() (*gophercloud.ProviderClient, error) {
+
+	provider, err := openstack.NewClient(d.authURL())
+	if err != nil {
+		return nil, err
+	}
+
+	authenticate := func(rejected string) error {
+		t, err := tokenCache.get(d.tokenCacheKey(), rejected, d.issueToken)
+		if err != nil {
+			return err
+		}
+		provider.TokenID = t.id
+		provider.EndpointLocator = func(
+			opts gophercloud.EndpointOpts) (string, error) {
+			return locateEndpoint(t.catalog, opts)
+		}
+		return nil
+	}
+
+	if err := authenticate(""); err != nil {
+		return nil, err
+	}
+	provider.ReauthFunc = func() error {
+		return authenticate(provider.TokenID)
+	}
+	return provider, nil
+}
+
+// tokenCacheKey returns the key of the cached token for the driver's
+// credentials.
+func (d *driver) tokenCacheKey() string {
+	return strings.Join([]string{
+		d.authURL(),
+		d.applicationCredentialID(),
+		d.applicationCredentialName(),
+		d.userID(),
+		d.userName(),
+		d.domainID(),
+		d.domainName(),
+		d.trustID(),
+	}, "|")
+}
+
+// issueToken requests a new token from Keystone v3.
+func (d *driver) issueToken() (*keystoneToken, error) {
+
+	body, err := json.Marshal(map[string]interface{}{
+		"auth": d.keystoneV3AuthBody(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := http.Post(
+		keystoneV3URL(d.authURL())+"auth/tokens",
+		"application/json",
+		bytes.NewReader(body))
+	if err != nil {
+		return nil, goof.WithError("error requesting keystone token", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return nil, goof.WithField(
+			"status", res.Status, "error requesting keystone token")
+	}
+
+	var reply struct {
+		Token struct {
+			ExpiresAt time.Time         `json:"expires_at"`
+			Catalog   []keystoneService `json:"catalog"`
+		} `json:"token"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&reply); err != nil {
+		return nil, goof.WithError("error decoding keystone token", err)
+	}
+
+	return &keystoneToken{
+		id:        res.Header.Get(subjectTokenHeader),
+		expiresAt: reply.Token.ExpiresAt,
+		catalog:   reply.Token.Catalog,
+	}, nil
+}
+
+// keystoneV3AuthBody returns the auth object of a token request. An
+// application credential is identified by its ID, or by its name and the
+// user that owns it, and is scoped by Keystone. A trust is used with the
+// trustee's password.
+func (d *driver) keystoneV3AuthBody() map[string]interface{} {
+
+	user := map[string]interface{}{}
+	if d.userID() != "" {
+		user["id"] = d.userID()
+	} else {
+		user["name"] = d.userName()
+		if d.domainID() != "" {
+			user["domain"] = map[string]string{"id": d.domainID()}
+		} else {
+			user["domain"] = map[string]string{"name": d.domainName()}
+		}
+	}
+
+	if d.applicationCredentialSecret() != "" {
+		appCred := map[string]interface{}{
+			"secret": d.applicationCredentialSecret(),
+		}
+		if d.applicationCredentialID() != "" {
+			appCred["id"] = d.applicationCredentialID()
+		} else {
+			appCred["name"] = d.applicationCredentialName()
+			appCred["user"] = user
+		}
+		return map[string]interface{}{
+			"identity": map[string]interface{}{
+				"methods":                []string{"application_credential"},
+				"application_credential": appCred,
+			},
+		}
+	}
+
+	user["password"] = d.password()
+	return map[string]interface{}{
+		"identity": map[string]interface{}{
+			"methods":  []string{"password"},
+			"password": map[string]interface{}{"user": user},
+		},
+		"scope": map[string]interface{}{
+			"OS-TRUST:trust": map[string]string{"id": d.trustID()},
+		},
+	}
+}
+
+// keystoneV3URL returns the URL of the Keystone v3 API from the configured
+// identity endpoint, which may or may not include the API version.
+func keystoneV3URL(authURL string) string {
+	u := strings.TrimSuffix(authURL, "/")
+	if !strings.HasSuffix(u, "/v3") {
+		u += "/v3"
+	}
+	return u + "/"
+}
+
+// locateEndpoint returns the URL of the catalog's endpoint that matches the
+// options.
+func locateEndpoint(
+	catalog []keystoneService,
+	opts gophercloud.EndpointOpts) (string, error) {
+
+	availability := string(opts.Availability)
+	if availability == "" {
+		availability = string(gophercloud.AvailabilityPublic)
+	}
+
+	for _, svc := range catalog {
+		if svc.Type != opts.Type {
+			continue
+		}
+		if opts.Name != "" && svc.Name != opts.Name {
+			continue
+		}
+		for _, ep := range svc.Endpoints {
+			if ep.Interface != availability {
+				continue
+			}
+			if opts.Region != "" &&
+				!strings.EqualFold(ep.Region, opts.Region) &&
+				!strings.EqualFold(ep.RegionID, opts.Region) {
+				continue
+			}
+			return gophercloud.NormalizeURL(ep.URL), nil
+		}
+	}
+
+	return "", goof.WithFields(goof.Fields{
+		"type":   opts.Type,
+		"region": opts.Region,
+	}, fmt.Sprintf("no %s endpoint in service catalog", availability))
+}
@@ -3,7 +3,6 @@
 package storage
 
 import (
-	"os/exec"
 	"regexp"
 	"strings"
 	"time"
@@ -15,6 +14,7 @@ import (
 	"github.com/codedellemc/libstorage/api/context"
 	"github.com/codedellemc/libstorage/api/registry"
 	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
 	"github.com/codedellemc/libstorage/drivers/storage/rackspace"
 
 	"github.com/rackspace/gophercloud"
@@ -27,6 +27,10 @@ import (
 const (
 	providerName = "Rackspace"
 	minSize      = 75 //rackspace is 75
+
+	// metadataKeyPrefix is the prefix of the keys of the volume metadata
+	// with which the server stamps the volumes it creates
+	metadataKeyPrefix = "libstorage-"
 )
 
 type driver struct {
@@ -84,8 +88,17 @@ func (d *driver) Init(context types.Context, config gofig.Config) error {
 	fields["tenantName"] = d.tenantName()
 	fields["domainId"] = d.domainID()
 	fields["domainName"] = d.domainName()
-
-	if d.provider, err = openstack.AuthenticatedClient(authOpts); err != nil {
+	fields["applicationCredentialId"] = d.applicationCredentialID()
+	fields["applicationCredentialName"] = d.applicationCredentialName()
+	fields["trustId"] = d.trustID()
+
+	if d.usesKeystoneV3Auth() {
+		if d.provider, err = d.keystoneV3Client(); err != nil {
+			return goof.WithFieldsE(fields,
+				"error getting authenticated client", err)
+		}
+	} else if d.provider, err = openstack.AuthenticatedClient(
+		authOpts); err != nil {
 		return goof.WithFieldsE(fields,
 			"error getting authenticated client", err)
 	}
@@ -118,6 +131,17 @@ func (d *driver) NextDeviceInfo(
 	return nil, nil
 }
 
+// Capabilities returns the capabilities of the driver's storage platform.
+func (d *driver) Capabilities(
+	ctx types.Context) (*types.StorageCapabilities, error) {
+
+	return &types.StorageCapabilities{
+		Snapshots:     true,
+		Clone:         true,
+		MaxVolumeSize: 1024,
+	}, nil
+}
+
 // 	// InstanceInspect returns an instance.
 func (d *driver) InstanceInspect(
 	ctx types.Context,
@@ -227,6 +251,121 @@ func (d *driver) VolumeSnapshot(
 	return translateSnapshot(resp), nil
 }
 
+// VolumeRetype changes the volume's Cinder volume type, such as to a
+// replicated type. The volume's data is only migrated to another back end if
+// the migration policy option is "on-demand".
+func (d *driver) VolumeRetype(
+	ctx types.Context,
+	volumeID, volumeType string,
+	opts types.Store) (*types.Volume, error) {
+
+	policy := retypeMigrationPolicy(opts)
+	fields := eff(map[string]interface{}{
+		"volumeId":        volumeID,
+		"volumeType":      volumeType,
+		"migrationPolicy": policy,
+	})
+
+	body := map[string]interface{}{
+		"os-retype": map[string]string{
+			"new_type":         volumeType,
+			"migration_policy": policy,
+		},
+	}
+	if _, err := d.clientBlockStorage.Post(
+		d.clientBlockStorage.ServiceURL("volumes", volumeID, "action"),
+		body, nil, &gophercloud.RequestOpts{
+			OkCodes: []int{202},
+		}); err != nil {
+		return nil, goof.WithFieldsE(fields, "error retyping volume", err)
+	}
+
+	ctx.WithFields(fields).Debug("waiting for volume retype to complete")
+	if err := d.waitVolumeType(ctx, volumeID, volumeType); err != nil {
+		return nil, goof.WithFieldsE(
+			fields, "error waiting for volume retype to complete", err)
+	}
+
+	return d.VolumeInspect(ctx, volumeID,
+		&types.VolumeInspectOpts{Attachments: types.VolAttReqTrue})
+}
+
+// VolumeImport brings the existing Cinder volume with the UUID nativeID under
+// management, renaming the volume if a volume name is provided. If no volume
+// name is provided the volume keeps its existing name or, if it has none, is
+// named after its UUID.
+func (d *driver) VolumeImport(
+	ctx types.Context,
+	nativeID, volumeName string,
+	opts types.Store) (*types.Volume, error) {
+
+	fields := eff(map[string]interface{}{
+		"nativeID":   nativeID,
+		"volumeName": volumeName,
+	})
+
+	volume, err := volumes.Get(d.clientBlockStorage, nativeID).Extract()
+	if err != nil {
+		if e, ok := err.(*gophercloud.UnexpectedResponseCodeError); ok &&
+			e.Actual == 404 {
+			return nil, utils.NewVolumeNotFoundError(nativeID)
+		}
+		return nil, goof.WithFieldsE(fields, "error getting volume", err)
+	}
+
+	if volumeName == "" {
+		volumeName = volume.Name
+	}
+	if volumeName == "" {
+		volumeName = nativeID
+	}
+
+	vols, err := d.getVolume(ctx, "", volumeName, types.VolAttNone)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range vols {
+		if v.ID != nativeID {
+			return nil, goof.WithFields(fields, "volume name already exists")
+		}
+	}
+
+	// the imported volume is stamped with the server's metadata so it is
+	// listed as a managed volume. Cinder replaces a volume's metadata when
+	// it is updated, so the volume's existing metadata is preserved.
+	md := volumeMetadata(ctx, volume.Metadata)
+	if volumeName != volume.Name || md != nil {
+		if _, err := volumes.Update(
+			d.clientBlockStorage, nativeID,
+			volumes.UpdateOpts{
+				Name:     volumeName,
+				Metadata: md,
+			}).Extract(); err != nil {
+			return nil, goof.WithFieldsE(fields, "error updating volume", err)
+		}
+	}
+
+	vol, err := d.VolumeInspect(ctx, nativeID,
+		&types.VolumeInspectOpts{Attachments: types.VolAttReqTrue})
+	if err != nil {
+		return nil, err
+	}
+	vol.Fields[types.VolumeFieldNativeID] = nativeID
+	return vol, nil
+}
+
+// retypeMigrationPolicy returns the migration policy option or the default
+// policy if the option is not set.
+func retypeMigrationPolicy(opts types.Store) string {
+	if opts != nil {
+		if v := opts.GetString(
+			types.VolumeRetypeOptMigrationPolicy); v != "" {
+			return v
+		}
+	}
+	return rackspace.DefaultMigrationPolicy
+}
+
 // 	// VolumeRemove removes a volume.
 func (d *driver) VolumeRemove(
 	ctx types.Context,
@@ -420,11 +559,12 @@ func (d *driver) getAuthOptions() gophercloud.AuthOptions {
 		TenantName:       d.tenantName(),
 		DomainID:         d.domainID(),
 		DomainName:       d.domainName(),
+		AllowReauth:      true,
 	}
 }
 
 func (d *driver) getInstanceID() (string, error) {
-	cmd := exec.Command("xenstore-read", "name")
+	cmd := utils.Command("xenstore-read", "name")
 	cmd.Env = d.config.EnvVars()
 	cmdOut, err := cmd.Output()
 
@@ -446,7 +586,7 @@ func (d *driver) getInstanceID() (string, error) {
 }
 
 func (d *driver) getInstanceRegion() (string, error) {
-	cmd := exec.Command("xenstore-read",
+	cmd := utils.Command("xenstore-read",
 		"vm-data/provider_data/region")
 	cmd.Env = d.config.EnvVars()
 	cmdOut, err := cmd.Output()
@@ -538,18 +678,13 @@ func (d *driver) createVolume(
 		size             int64
 		availabilityZone string
 	)
-	if opts.Type != nil {
-		volumeType = *(opts.Type)
-	}
 	if opts.IOPS != nil {
 		IOPS = *(opts.IOPS)
 	}
 	if opts.Size != nil {
 		size = *(opts.Size)
 	}
-	if opts.AvailabilityZone != nil {
-		availabilityZone = *(opts.AvailabilityZone)
-	}
+	volumeType, availabilityZone = d.volumeTypeAndZone(opts)
 
 	//check some fields...
 	createVolumeEnsureSize(&size)
@@ -567,11 +702,12 @@ func (d *driver) createVolume(
 	}
 
 	options := &volumes.CreateOpts{
-		Name:       volumeName,
-		Size:       vsize,
-		SnapshotID: snapshotID,
-		VolumeType: volumeType,
-		//AvailabilityZone: availabilityZone, //Not in old Rackspace
+		Name:         volumeName,
+		Size:         vsize,
+		SnapshotID:   snapshotID,
+		VolumeType:   volumeType,
+		Availability: availabilityZone,
+		Metadata:     volumeMetadata(ctx, nil),
 		//SourceReplica:    volumeSourceID,
 	}
 	resp, err := volumes.Create(d.clientBlockStorage, options).Extract()
@@ -612,6 +748,16 @@ func translateVolume(
 		}
 	}
 
+	fields := map[string]string{
+		rackspace.VolumeFieldVolumeType:       volume.VolumeType,
+		rackspace.VolumeFieldAvailabilityZone: volume.AvailabilityZone,
+	}
+	for k, v := range volume.Metadata {
+		if strings.HasPrefix(k, metadataKeyPrefix) {
+			fields[k] = v
+		}
+	}
+
 	return &types.Volume{
 		Name:             volume.Name,
 		ID:               volume.ID,
@@ -621,9 +767,36 @@ func translateVolume(
 		IOPS:             0,
 		Size:             int64(volume.Size),
 		Attachments:      atts,
+		Fields:           fields,
 	}
 }
 
+// volumeMetadata returns the metadata of a volume stamped with the metadata
+// in the context, or nil if the context has none. The volume's existing
+// metadata, if any, is preserved.
+func volumeMetadata(
+	ctx types.Context, existing map[string]string) map[string]string {
+
+	md, ok := context.VolumeMetadata(ctx)
+	if !ok {
+		return nil
+	}
+	stamped := map[string]string{}
+	for k, v := range existing {
+		stamped[k] = v
+	}
+	for k, v := range md {
+		stamped[k] = v
+	}
+	return stamped
+}
+
+// StampsVolumeMetadata returns true since the driver records the server's
+// metadata in the Cinder metadata of the volumes it creates.
+func (d *driver) StampsVolumeMetadata(ctx types.Context) bool {
+	return true
+}
+
 //Reformats from snapshots.Snapshot to types.Snapshot credit to github.com/MatMaul
 func translateSnapshot(snapshot *snapshots.Snapshot) *types.Snapshot {
 	createAtEpoch := int64(0)
@@ -687,6 +860,61 @@ func (d *driver) waitSnapshotStatus(
 	return nil
 }
 
+// volumeTypeAndZone returns the volume type and availability zone with which
+// a volume is created. The service's configured type and zone are used when
+// the options do not specify them.
+func (d *driver) volumeTypeAndZone(
+	opts *types.VolumeCreateOpts) (volumeType, availabilityZone string) {
+
+	if opts.Type != nil {
+		volumeType = *(opts.Type)
+	}
+	if opts.AvailabilityZone != nil {
+		availabilityZone = *(opts.AvailabilityZone)
+	}
+	if volumeType == "" {
+		volumeType = d.volumeType()
+	}
+	if availabilityZone == "" {
+		availabilityZone = d.availabilityZone()
+	}
+	return volumeType, availabilityZone
+}
+
+// waitVolumeType waits for a retyped volume to have the new type.
+func (d *driver) waitVolumeType(
+	ctx types.Context, volumeID, volumeType string) error {
+
+	for {
+		volume, err := volumes.Get(d.clientBlockStorage, volumeID).Extract()
+		if err != nil {
+			return err
+		}
+		if done, err := volumeRetyped(volume, volumeType); done {
+			return err
+		}
+		time.Sleep(5 * time.Second)
+	}
+}
+
+// volumeRetyped returns a flag indicating whether or not the retype of the
+// volume is complete, and an error if the retype failed. A volume whose data
+// is migrated is "retyping" until the migration completes.
+func volumeRetyped(volume *volumes.Volume, volumeType string) (bool, error) {
+	if volume.Status == "error" {
+		return true, goof.New("volume is in error state")
+	}
+	if volume.Status == "retyping" {
+		return false, nil
+	}
+	if volume.VolumeType != volumeType {
+		// Cinder restores the volume's status without changing its type
+		// when the retype is rejected by the scheduler
+		return true, goof.New("volume type was not changed")
+	}
+	return true, nil
+}
+
 func (d *driver) waitVolumeAttachStatus(
 	ctx types.Context,
 	volumeID string,
@@ -768,3 +996,27 @@ func (d *driver) domainID() string {
 func (d *driver) domainName() string {
 	return d.config.GetString("rackspace.domainName")
 }
+
+func (d *driver) volumeType() string {
+	return d.config.GetString("rackspace.volumeType")
+}
+
+func (d *driver) availabilityZone() string {
+	return d.config.GetString("rackspace.availabilityZone")
+}
+
+func (d *driver) applicationCredentialID() string {
+	return d.config.GetString("rackspace.applicationCredentialID")
+}
+
+func (d *driver) applicationCredentialName() string {
+	return d.config.GetString("rackspace.applicationCredentialName")
+}
+
+func (d *driver) applicationCredentialSecret() string {
+	return d.config.GetString("rackspace.applicationCredentialSecret")
+}
+
+func (d *driver) trustID() string {
+	return d.config.GetString("rackspace.trustID")
+}
@@ -0,0 +1,107 @@
+// +build none
+
+package storage
+
+import (
+	"testing"
+
+	gofigCore "github.com/akutz/gofig"
+	"github.com/rackspace/gophercloud/openstack/blockstorage/v1/volumes"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+	"github.com/codedellemc/libstorage/drivers/storage/rackspace"
+)
+
+func TestVolumeTypeAndZone(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	config := gofigCore.New()
+	config.Set("rackspace.volumeType", "SSD")
+	config.Set("rackspace.availabilityZone", "nova")
+	configured := &driver{config: config}
+	unconfigured := &driver{config: gofigCore.New()}
+
+	tests := []struct {
+		name      string
+		d         *driver
+		opts      *types.VolumeCreateOpts
+		volType   string
+		availZone string
+	}{
+		{"configured defaults", configured,
+			&types.VolumeCreateOpts{}, "SSD", "nova"},
+		{"options override", configured,
+			&types.VolumeCreateOpts{
+				Type:             str("SATA"),
+				AvailabilityZone: str("az2"),
+			}, "SATA", "az2"},
+		{"empty options", configured,
+			&types.VolumeCreateOpts{
+				Type:             str(""),
+				AvailabilityZone: str(""),
+			}, "SSD", "nova"},
+		{"unconfigured", unconfigured,
+			&types.VolumeCreateOpts{}, "", ""},
+	}
+
+	for _, tt := range tests {
+		volType, availZone := tt.d.volumeTypeAndZone(tt.opts)
+		assert.Equal(t, tt.volType, volType, tt.name)
+		assert.Equal(t, tt.availZone, availZone, tt.name)
+	}
+}
+
+func TestRetypeMigrationPolicy(t *testing.T) {
+	assert.Equal(t, rackspace.DefaultMigrationPolicy,
+		retypeMigrationPolicy(nil))
+	assert.Equal(t, rackspace.DefaultMigrationPolicy,
+		retypeMigrationPolicy(utils.NewStore()))
+	assert.Equal(t, "on-demand", retypeMigrationPolicy(
+		utils.NewStoreWithData(map[string]interface{}{
+			types.VolumeRetypeOptMigrationPolicy: "on-demand",
+		})))
+}
+
+func TestVolumeRetyped(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		volType string
+		done    bool
+		err     bool
+	}{
+		{"retyped", "available", "replicated", true, false},
+		{"retyped in use", "in-use", "replicated", true, false},
+		{"migrating", "retyping", "standard", false, false},
+		{"rejected", "available", "standard", true, true},
+		{"failed", "error", "standard", true, true},
+	}
+
+	for _, tt := range tests {
+		done, err := volumeRetyped(&volumes.Volume{
+			Status:     tt.status,
+			VolumeType: tt.volType,
+		}, "replicated")
+		assert.Equal(t, tt.done, done, tt.name)
+		assert.Equal(t, tt.err, err != nil, tt.name)
+	}
+}
+
+func TestTranslateVolumeTypeAndZone(t *testing.T) {
+	v := translateVolume(&volumes.Volume{
+		ID:               "vol-1",
+		VolumeType:       "SSD",
+		AvailabilityZone: "nova",
+		Metadata: map[string]string{
+			metadataKeyPrefix + "tenant": "team1",
+			"other":                      "value",
+		},
+	}, types.VolAttNone)
+	assert.Equal(t, map[string]string{
+		rackspace.VolumeFieldVolumeType:       "SSD",
+		rackspace.VolumeFieldAvailabilityZone: "nova",
+		metadataKeyPrefix + "tenant":          "team1",
+	}, v.Fields)
+}
@@ -6,7 +6,6 @@ import (
 	"bufio"
 	"bytes"
 	"net"
-	"os/exec"
 	"strings"
 
 	gofig "github.com/akutz/gofig/types"
@@ -15,12 +14,14 @@ import (
 
 	"github.com/codedellemc/libstorage/api/registry"
 	"github.com/codedellemc/libstorage/api/types"
+	apiutils "github.com/codedellemc/libstorage/api/utils"
 	"github.com/codedellemc/libstorage/drivers/storage/rbd"
 	"github.com/codedellemc/libstorage/drivers/storage/rbd/utils"
 )
 
 type driver struct {
 	config gofig.Config
+	rbd    *utils.Client
 }
 
 func init() {
@@ -28,7 +29,7 @@ func init() {
 }
 
 func newdriver() types.StorageExecutor {
-	return &driver{}
+	return &driver{rbd: utils.NewClient(nil)}
 }
 
 func (d *driver) Init(context types.Context, config gofig.Config) error {
@@ -56,7 +57,7 @@ func (d *driver) Supported(
 		return false, nil
 	}
 
-	if err := exec.Command("modprobe", "rbd").Run(); err != nil {
+	if err := apiutils.Command("modprobe", "rbd").Run(); err != nil {
 		return false, nil
 	}
 
@@ -76,7 +77,7 @@ func (d *driver) LocalDevices(
 	ctx types.Context,
 	opts *types.LocalDevicesOpts) (*types.LocalDevices, error) {
 
-	devMap, err := utils.GetMappedRBDs(ctx)
+	devMap, err := d.rbd.GetMappedRBDs(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -94,7 +95,23 @@ func (d *driver) InstanceID(
 	ctx types.Context,
 	opts types.Store) (*types.InstanceID, error) {
 
-	return GetInstanceID(nil, nil)
+	id, err := apiutils.ResolveInstanceID(ctx, d.config)
+	if err != nil {
+		return nil, err
+	}
+	iid := &types.InstanceID{ID: id, Driver: rbd.Name}
+	if id == "" {
+		if iid, err = GetInstanceID(nil, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	// the rack is reported by the executor because it is configured on the
+	// host rather than on the server
+	if rack := d.config.GetString(rbd.ConfigRack); rack != "" {
+		iid.Fields = map[string]string{rbd.InstanceIDFieldRack: rack}
+	}
+	return iid, nil
 }
 
 // GetInstanceID returns the instance ID object
@@ -153,7 +170,7 @@ func GetInstanceID(
 }
 
 func getCephMonIPs() ([]net.IP, error) {
-	out, err := exec.Command("ceph-conf", "--lookup", "mon_host").Output()
+	out, err := apiutils.Command("ceph-conf", "--lookup", "mon_host").Output()
 	if err != nil {
 		return nil, goof.WithError("Unable to get Ceph monitors", err)
 	}
@@ -169,7 +186,7 @@ func getCephMonIPs() ([]net.IP, error) {
 }
 
 func getSrcIP(destIP string) (string, error) {
-	out, err := exec.Command(
+	out, err := apiutils.Command(
 		"ip", "-oneline", "route", "get", destIP).Output()
 	if err != nil {
 		return "", goof.WithError("Unable get IP routes", err)
@@ -5,11 +5,27 @@ package rbd
 import (
 	gofigCore "github.com/akutz/gofig"
 	gofig "github.com/akutz/gofig/types"
+
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
 )
 
 const (
 	// Name is the name of the storage driver
 	Name = "rbd"
+
+	// ConfigRack is the config key for the CRUSH rack in which the host
+	// resides.
+	ConfigRack = Name + ".rack"
+
+	// ConfigPoolRacks is the config key for the list of the pools whose
+	// CRUSH rules confine their images to a rack, each in the form
+	// "pool=rack".
+	ConfigPoolRacks = Name + ".poolRacks"
+
+	// InstanceIDFieldRack is the instance ID field in which the executor
+	// reports the host's rack.
+	InstanceIDFieldRack = "rack"
 )
 
 func init() {
@@ -19,5 +35,21 @@ func init() {
 func registerConfig() {
 	r := gofigCore.NewRegistration("RBD")
 	r.Key(gofig.String, "", "rbd", "", "rbd.defaultPool")
+	r.Key(gofig.String, "", "",
+		"The CRUSH rack in which the host resides", ConfigRack)
+	r.Key(gofig.String, "", "",
+		"List of pool=rack pairs of the pools confined to a CRUSH rack",
+		ConfigPoolRacks)
 	gofigCore.Register(r)
+
+	registry.RegisterConfigSchema(&types.ConfigSchema{
+		Name:       "RBD",
+		Namespaces: []string{Name},
+		Keys: map[string]types.ConfigKeyType{
+			"rbd.defaultPool": types.ConfigKeyString,
+			ConfigRack:        types.ConfigKeyString,
+			ConfigPoolRacks:   types.ConfigKeyAny,
+		},
+		StorageDriver: Name,
+	})
 }
@@ -3,7 +3,14 @@
 package storage
 
 import (
+	"crypto/sha1"
+	"fmt"
+	"net"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	gofig "github.com/akutz/gofig/types"
 	"github.com/akutz/goof"
@@ -11,6 +18,7 @@ import (
 	"github.com/codedellemc/libstorage/api/context"
 	"github.com/codedellemc/libstorage/api/registry"
 	"github.com/codedellemc/libstorage/api/types"
+	apiUtils "github.com/codedellemc/libstorage/api/utils"
 	"github.com/codedellemc/libstorage/drivers/storage/rbd"
 	"github.com/codedellemc/libstorage/drivers/storage/rbd/utils"
 )
@@ -28,6 +36,7 @@ var (
 
 type driver struct {
 	config gofig.Config
+	rbd    *utils.Client
 }
 
 func init() {
@@ -35,7 +44,7 @@ func init() {
 }
 
 func newDriver() types.StorageDriver {
-	return &driver{}
+	return &driver{rbd: utils.NewClient(nil)}
 }
 
 func (d *driver) Name() string {
@@ -53,6 +62,28 @@ func (d *driver) Type(ctx types.Context) (types.StorageType, error) {
 	return types.Block, nil
 }
 
+func (d *driver) Capabilities(
+	ctx types.Context) (*types.StorageCapabilities, error) {
+
+	caps := &types.StorageCapabilities{
+		ReadOnlyAttach: true,
+		// images are always thin-provisioned
+		Provisioning: []string{types.ProvisioningThin},
+	}
+	if len(d.poolRacks()) > 0 {
+		caps.Topology = []string{"rack"}
+	}
+	return caps, nil
+}
+
+func (d *driver) HealthCheck(ctx types.Context) error {
+	// listing the pools requires a connection to the cluster's monitors
+	if _, err := d.rbd.GetRadosPools(ctx); err != nil {
+		return goof.WithError("unable to reach ceph monitors", err)
+	}
+	return nil
+}
+
 func (d *driver) NextDeviceInfo(
 	ctx types.Context) (*types.NextDeviceInfo, error) {
 	return nil, nil
@@ -63,9 +94,14 @@ func (d *driver) InstanceInspect(
 	opts types.Store) (*types.Instance, error) {
 
 	iid := context.MustInstanceID(ctx)
-	return &types.Instance{
+	inst := &types.Instance{
 		InstanceID: iid,
-	}, nil
+		Rack:       iid.Fields[rbd.InstanceIDFieldRack],
+	}
+	if inst.Rack == "" {
+		inst.Rack = d.config.GetString(rbd.ConfigRack)
+	}
+	return inst, nil
 }
 
 func (d *driver) Volumes(
@@ -73,7 +109,7 @@ func (d *driver) Volumes(
 	opts *types.VolumesOpts) ([]*types.Volume, error) {
 
 	// Get all Volumes in all pools
-	pools, err := utils.GetRadosPools(ctx)
+	pools, err := d.rbd.GetRadosPools(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -81,7 +117,7 @@ func (d *driver) Volumes(
 	var volumes []*types.Volume
 
 	for _, pool := range pools {
-		images, err := utils.GetRBDImages(ctx, pool)
+		images, err := d.rbd.GetRBDImages(ctx, pool)
 		if err != nil {
 			return nil, err
 		}
@@ -108,7 +144,7 @@ func (d *driver) VolumeInspect(
 		return nil, err
 	}
 
-	info, err := utils.GetRBDInfo(ctx, pool, image)
+	info, err := d.rbd.GetRBDInfo(ctx, pool, image)
 	if err != nil {
 		return nil, err
 	}
@@ -154,7 +190,7 @@ func (d *driver) VolumeCreate(ctx types.Context, volumeName string,
 		return nil, err
 	}
 
-	info, err := utils.GetRBDInfo(ctx, pool, imageName)
+	info, err := d.rbd.GetRBDInfo(ctx, pool, imageName)
 	if err != nil {
 		return nil, err
 	}
@@ -168,7 +204,7 @@ func (d *driver) VolumeCreate(ctx types.Context, volumeName string,
 
 	features := []*string{&featureLayering}
 
-	err = utils.RBDCreate(
+	err = d.rbd.RBDCreate(
 		ctx,
 		pool,
 		imageName,
@@ -209,6 +245,87 @@ func (d *driver) VolumeSnapshot(
 	return nil, types.ErrNotImplemented
 }
 
+// SnapshotGroupCreate snapshots the images at the same point in time with an
+// RBD group snapshot. The images are added to an RBD group named for the set
+// of images, which is created in the pool of the first image if it does not
+// exist, so that later snapshots of the same images use the same group.
+func (d *driver) SnapshotGroupCreate(
+	ctx types.Context,
+	volumeIDs []string,
+	snapshotName string,
+	opts types.Store) (*types.SnapshotGroup, error) {
+
+	ids := append([]string{}, volumeIDs...)
+	sort.Strings(ids)
+
+	pools := make([]*string, len(ids))
+	images := make([]*string, len(ids))
+	for i := range ids {
+		pool, image, err := d.parseVolumeID(&ids[i])
+		if err != nil {
+			return nil, goof.WithError("Unable to set image name", err)
+		}
+		pools[i], images[i] = pool, image
+	}
+
+	pool := pools[0]
+	group := fmt.Sprintf(
+		"libstorage-%x", sha1.Sum([]byte(strings.Join(ids, ","))))[:24]
+	fields := map[string]interface{}{
+		"volumeIDs":    ids,
+		"group":        *pool + "/" + group,
+		"snapshotName": snapshotName,
+	}
+
+	created, err := d.rbd.RBDGroupCreate(ctx, pool, &group)
+	if err != nil {
+		return nil, err
+	}
+	rollback := func() {
+		if !created {
+			return
+		}
+		if err := d.rbd.RBDGroupRemove(ctx, pool, &group); err != nil {
+			ctx.WithFields(fields).WithError(err).Warn(
+				"Unable to remove RBD group of failed snapshot group")
+		}
+	}
+
+	for i := range ids {
+		if err := d.rbd.RBDGroupImageAdd(
+			ctx, pool, &group, pools[i], images[i]); err != nil {
+			rollback()
+			return nil, err
+		}
+	}
+
+	if err := d.rbd.RBDGroupSnapCreate(
+		ctx, pool, &group, &snapshotName); err != nil {
+		rollback()
+		return nil, err
+	}
+	ctx.WithFields(fields).Debug("created RBD group snapshot")
+
+	snapshotID := *pool + "/" + group + "@" + snapshotName
+	snapGroup := &types.SnapshotGroup{
+		ID:         snapshotID,
+		Name:       snapshotName,
+		Consistent: true,
+		Snapshots:  map[string]*types.Snapshot{},
+	}
+	startTime := time.Now().Unix()
+	for _, volumeID := range ids {
+		snapGroup.Snapshots[volumeID] = &types.Snapshot{
+			ID:        snapshotID,
+			VolumeID:  volumeID,
+			Name:      snapshotName,
+			Status:    "online",
+			StartTime: startTime,
+		}
+	}
+	return snapGroup, nil
+}
+
 func (d *driver) VolumeRemove(
 	ctx types.Context,
 	volumeID string,
@@ -226,7 +343,7 @@ func (d *driver) VolumeRemove(
 		return goof.WithError("Unable to set image name", err)
 	}
 
-	err = utils.RBDRemove(ctx, pool, imageName)
+	err = d.rbd.RBDRemove(ctx, pool, imageName)
 	if err != nil {
 		return goof.WithError("Error while deleting RBD image", err)
 	}
@@ -235,6 +352,228 @@ func (d *driver) VolumeRemove(
 	return nil
 }
 
+// VolumeRecycle moves the image to its pool's trash.
+func (d *driver) VolumeRecycle(
+	ctx types.Context,
+	volumeID string,
+	opts types.Store) error {
+
+	pool, imageName, err := d.parseVolumeID(&volumeID)
+	if err != nil {
+		return goof.WithError("Unable to set image name", err)
+	}
+
+	if err := d.rbd.RBDTrashMove(ctx, pool, imageName); err != nil {
+		return goof.WithError("Error while moving RBD image to trash", err)
+	}
+	ctx.WithField("volumeID", volumeID).Debug("moved volume to trash")
+
+	return nil
+}
+
+// RecycledVolumes returns the images in the trash of all pools.
+func (d *driver) RecycledVolumes(
+	ctx types.Context,
+	opts types.Store) ([]*types.Volume, error) {
+
+	pools, err := d.rbd.GetRadosPools(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var volumes []*types.Volume
+	for _, pool := range pools {
+		entries, err := d.rbd.GetRBDTrash(ctx, pool)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			// images moved to the trash by other means, such as the
+			// deferment of a clone's parent, are not recycled volumes
+			if !strings.EqualFold(e.Source, "USER") {
+				continue
+			}
+			vol := &types.Volume{
+				Name: e.Name,
+				ID:   *utils.GetVolumeID(&e.Pool, &e.Name),
+				Type: e.Pool,
+				Fields: map[string]string{
+					"trashID": e.ID,
+				},
+			}
+			if t, err := e.DeletedTime(); err == nil {
+				vol.Fields[types.VolumeFieldRecycledTime] =
+					strconv.FormatInt(t.Unix(), 10)
+			}
+			volumes = append(volumes, vol)
+		}
+	}
+
+	return volumes, nil
+}
+
+// RecycledVolumeRestore restores the image from its pool's trash.
+func (d *driver) RecycledVolumeRestore(
+	ctx types.Context,
+	volumeID string,
+	opts types.Store) (*types.Volume, error) {
+
+	pool, entry, err := d.getTrashEntry(ctx, volumeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.rbd.RBDTrashRestore(ctx, pool, &entry.ID); err != nil {
+		return nil, goof.WithError("Error while restoring RBD image", err)
+	}
+
+	return d.VolumeInspect(
+		ctx, volumeID, &types.VolumeInspectOpts{
+			Attachments: types.VolAttNone,
+		})
+}
+
+// RecycledVolumePurge deletes the image from its pool's trash.
+func (d *driver) RecycledVolumePurge(
+	ctx types.Context,
+	volumeID string,
+	opts types.Store) error {
+
+	pool, entry, err := d.getTrashEntry(ctx, volumeID)
+	if err != nil {
+		return err
+	}
+
+	if err := d.rbd.RBDTrashRemove(ctx, pool, &entry.ID); err != nil {
+		return goof.WithError("Error while deleting RBD image from trash", err)
+	}
+
+	return nil
+}
+
+// VolumeImport brings the existing RBD image with the native ID of the form
+// <pool>/<image> or <pool>.<image> under management, renaming the image if a
+// volume name is provided.
+func (d *driver) VolumeImport(
+	ctx types.Context,
+	nativeID, volumeName string,
+	opts types.Store) (*types.Volume, error) {
+
+	volumeID := strings.Replace(nativeID, "/", ".", 1)
+	pool, image, err := d.parseVolumeID(&volumeID)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := d.rbd.GetRBDInfo(ctx, pool, image)
+	if err != nil {
+		return nil, err
+	}
+	if info == nil {
+		return nil, apiUtils.NewVolumeNotFoundError(nativeID)
+	}
+
+	if volumeName != "" && volumeName != *image {
+		re, _ := regexp.Compile(`^` + validNameRX + `$`)
+		if !re.MatchString(volumeName) {
+			return nil, goof.New(
+				"Invalid character(s) found in volume name")
+		}
+		if err := d.rbd.RBDRename(ctx, pool, image, &volumeName); err != nil {
+			return nil, err
+		}
+		image = &volumeName
+	}
+
+	vol, err := d.VolumeInspect(
+		ctx, *utils.GetVolumeID(pool, image), &types.VolumeInspectOpts{
+			Attachments: types.VolAttReqTrue,
+		})
+	if err != nil {
+		return nil, err
+	}
+	if vol.Fields == nil {
+		vol.Fields = map[string]string{}
+	}
+	vol.Fields[types.VolumeFieldNativeID] = nativeID
+	return vol, nil
+}
+
+// VolumeStats returns the image's IO rates as reported by the rbd_support
+// manager module. The read and write latencies, in nanoseconds, are
+// returned as the readLatency and writeLatency fields.
+func (d *driver) VolumeStats(
+	ctx types.Context,
+	volumeID string,
+	opts types.Store) (*types.VolumeIOStats, error) {
+
+	pool, image, err := d.parseVolumeID(&volumeID)
+	if err != nil {
+		return nil, err
+	}
+
+	imageStats, err := d.rbd.GetRBDImageIOStats(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	// images without IO are omitted by iostat
+	stats := &types.VolumeIOStats{}
+	for _, s := range imageStats {
+		if s.Name != *image {
+			continue
+		}
+		stats.ReadOps = s.ReadOps
+		stats.WriteOps = s.WriteOps
+		stats.ReadBytes = s.ReadBytes
+		stats.WriteBytes = s.WriteBytes
+		stats.Fields = map[string]string{
+			"readLatency": strconv.FormatFloat(
+				s.ReadLatency, 'f', -1, 64),
+			"writeLatency": strconv.FormatFloat(
+				s.WriteLatency, 'f', -1, 64),
+		}
+		break
+	}
+	return stats, nil
+}
+
+// getTrashEntry returns the most recently trashed image with the volume's
+// name in the volume's pool.
+func (d *driver) getTrashEntry(
+	ctx types.Context,
+	volumeID string) (*string, *utils.RBDTrashEntry, error) {
+
+	pool, imageName, err := d.parseVolumeID(&volumeID)
+	if err != nil {
+		return nil, nil, goof.WithError("Unable to set image name", err)
+	}
+
+	entries, err := d.rbd.GetRBDTrash(ctx, pool)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		entry  *utils.RBDTrashEntry
+		latest time.Time
+	)
+	for _, e := range entries {
+		if e.Name != *imageName {
+			continue
+		}
+		t, _ := e.DeletedTime()
+		if entry == nil || t.After(latest) {
+			entry, latest = e, t
+		}
+	}
+	if entry == nil {
+		return nil, nil, apiUtils.NewVolumeNotFoundError(volumeID)
+	}
+
+	return pool, entry, nil
+}
+
 func (d *driver) VolumeAttach(
 	ctx types.Context,
 	volumeID string,
@@ -267,7 +606,8 @@ func (d *driver) VolumeAttach(
 		}
 	}
 
-	_, err = utils.RBDMap(ctx, pool, imageName)
+	_, err = d.rbd.RBDMap(
+		ctx, pool, imageName, opts.AccessMode.ReadOnly())
 	if err != nil {
 		return nil, "", err
 	}
@@ -297,17 +637,20 @@ func (d *driver) VolumeDetach(
 	ctx.WithFields(fields).Debug("detaching volume")
 
 	// Can't rely on local devices header, so get local attachments
-	localAttachMap, err := utils.GetMappedRBDs(ctx)
+	localAttachMap, err := d.rbd.GetMappedRBDs(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	dev, found := localAttachMap[volumeID]
 	if !found {
-		return nil, goof.New("Volume not attached")
+		if !opts.Force {
+			return nil, goof.New("Volume not attached")
+		}
+		return d.volumeFence(ctx, volumeID, opts.Opts)
 	}
 
-	err = utils.RBDUnmap(ctx, &dev)
+	err = d.rbd.RBDUnmap(ctx, &dev)
 	if err != nil {
 		return nil, goof.WithError("Unable to detach volume", err)
 	}
@@ -319,6 +662,72 @@ func (d *driver) VolumeDetach(
 	)
 }
 
+// volumeFence forcefully detaches a volume that is mapped by another host by
+// blacklisting the host's clients, since the host may be unreachable and so
+// unable to unmap the volume itself. Only the clients of the instance
+// specified by the instanceID option are blacklisted so that the other hosts
+// that have the volume mapped, such as its new owner, are not fenced.
+func (d *driver) volumeFence(
+	ctx types.Context,
+	volumeID string,
+	opts types.Store) (*types.Volume, error) {
+
+	// the instance must be specified explicitly, such as with the
+	// instanceID parameter of a forced detach request, so that the
+	// requesting client's own instance is never fenced by mistake
+	instanceID := ""
+	if opts != nil {
+		instanceID = opts.GetString("instanceID")
+	}
+	if instanceID == "" {
+		return nil, goof.New(
+			"Unable to fence volume: the instanceID is required")
+	}
+
+	pool, imageName, err := d.parseVolumeID(&volumeID)
+	if err != nil {
+		return nil, goof.WithError("Unable to set image name", err)
+	}
+
+	ips, err := instanceIPs(instanceID)
+	if err != nil {
+		return nil, goof.WithFieldE(
+			"instanceID", instanceID, "Unable to resolve instance", err)
+	}
+
+	addrs, err := d.rbd.RBDBlacklistWatchers(ctx, pool, imageName, ips)
+	if err != nil {
+		return nil, goof.WithError("Unable to fence volume", err)
+	}
+
+	fields := map[string]interface{}{
+		"volumeID":   volumeID,
+		"instanceID": instanceID,
+		"watchers":   addrs,
+	}
+	if len(addrs) == 0 {
+		ctx.WithFields(fields).Warn("instance has no volume watchers")
+	} else {
+		ctx.WithFields(fields).Warn("blacklisted volume watchers")
+	}
+
+	return d.VolumeInspect(
+		ctx, volumeID, &types.VolumeInspectOpts{
+			Attachments: types.VolAttReqTrue,
+		},
+	)
+}
+
+// instanceIPs returns the IPs of an instance. An RBD instance ID is the IP
+// of the host's client unless it is provided by an instance ID resolver, in
+// which case it is looked up as a host name.
+func instanceIPs(instanceID string) ([]net.IP, error) {
+	if ip := net.ParseIP(instanceID); ip != nil {
+		return []net.IP{ip}, nil
+	}
+	return net.LookupIP(instanceID)
+}
+
 func (d *driver) VolumeDetachAll(
 	ctx types.Context,
 	volumeID string,
@@ -357,12 +766,18 @@ func (d *driver) defaultPool() string {
 	return d.config.GetString("rbd.defaultPool")
 }
 
+// poolRacks returns the racks to which the configured pools are confined.
+func (d *driver) poolRacks() map[string]string {
+	return utils.ParsePoolRacks(d.config.GetStringSlice(rbd.ConfigPoolRacks))
+}
+
 func (d *driver) toTypeVolumes(
 	ctx types.Context,
 	images []*utils.RBDImage,
 	getAttachments types.VolumeAttachmentsTypes) ([]*types.Volume, error) {
 
 	lsVolumes := make([]*types.Volume, len(images))
+	racks := d.poolRacks()
 
 	var localAttachMap map[string]string
 
@@ -370,7 +785,7 @@ func (d *driver) toTypeVolumes(
 	// rely on that being present unless getAttachments.Devices is set
 	if getAttachments.Requested() {
 		var err error
-		localAttachMap, err = utils.GetMappedRBDs(ctx)
+		localAttachMap, err = d.rbd.GetMappedRBDs(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -383,6 +798,7 @@ func (d *driver) toTypeVolumes(
 			ID:   *rbdID,
 			Type: image.Pool,
 			Size: int64(image.Size / bytesPerGiB),
+			Rack: racks[image.Pool],
 		}
 
 		if getAttachments.Requested() && localAttachMap != nil {
@@ -410,7 +826,7 @@ func (d *driver) toTypeVolumes(
 			} else {
 				//Check if RBD has watchers to infer attachment
 				//to a different host
-				b, err := utils.RBDHasWatchers(
+				b, err := d.rbd.RBDHasWatchers(
 					ctx, &image.Pool, &image.Name,
 				)
 				if err != nil {
@@ -0,0 +1,98 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_rbd
+
+package utils
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/codedellemc/libstorage/api/types"
+	apiUtils "github.com/codedellemc/libstorage/api/utils"
+	"github.com/codedellemc/libstorage/api/utils/tracing"
+)
+
+//CommandRunner runs the ceph, rados, and rbd commands with which the driver
+//manages RBD images
+type CommandRunner interface {
+	//Output runs the named command and returns its standard output. An
+	//*ExitError is returned if the command exits with a non-zero status.
+	Output(ctx types.Context, name string, args ...string) ([]byte, error)
+}
+
+//ExitError is the error returned by a CommandRunner when a command exits
+//with a non-zero status
+type ExitError struct {
+	Status int
+	Stderr string
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Status)
+}
+
+type execRunner struct{}
+
+//NewCommandRunner returns a CommandRunner that executes the commands with
+//the configured execution settings
+func NewCommandRunner() CommandRunner {
+	return &execRunner{}
+}
+
+func (r *execRunner) Output(
+	ctx types.Context, name string, args ...string) ([]byte, error) {
+
+	cmd := apiUtils.CommandContext(ctx, name, args...)
+	ctx.WithFields(map[string]interface{}{
+		"cmd":  name,
+		"args": apiUtils.RedactArgs(cmd.Args),
+	}).Debug("running command")
+
+	_, finish := tracing.StartSpan(ctx, "exec "+name,
+		map[string]interface{}{
+			"args": strings.Join(apiUtils.RedactArgs(args), " "),
+		})
+	out, err := cmd.Output()
+	finish(err)
+
+	if exiterr, ok := err.(*exec.ExitError); ok {
+		status := -1
+		if ws, ok := exiterr.Sys().(syscall.WaitStatus); ok {
+			status = ws.ExitStatus()
+		}
+		return out, &ExitError{Status: status, Stderr: string(exiterr.Stderr)}
+	}
+	return out, err
+}
+
+//FakeRunner is a CommandRunner that records the commands it is asked to run
+//and returns the outputs and errors configured for them, so that the
+//parsing and error handling of the driver may be tested without a Ceph
+//cluster. The outputs and errors are keyed by the commands' lines, ex.
+//"rbd showmapped --format json". A command with neither returns no output.
+type FakeRunner struct {
+	sync.Mutex
+
+	//Outputs are the standard outputs of the commands
+	Outputs map[string]string
+
+	//Errors are the errors returned by the commands
+	Errors map[string]error
+
+	//Commands are the lines of the commands that were run, in order
+	Commands []string
+}
+
+//Output records the command and returns its configured output and error
+func (r *FakeRunner) Output(
+	ctx types.Context, name string, args ...string) ([]byte, error) {
+
+	line := strings.Join(append([]string{name}, args...), " ")
+
+	r.Lock()
+	defer r.Unlock()
+	r.Commands = append(r.Commands, line)
+	return []byte(r.Outputs[line]), r.Errors[line]
+}
@@ -8,11 +8,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
-	"os/exec"
 	"regexp"
 	"strconv"
 	"strings"
-	"syscall"
+	"time"
 
 	"github.com/akutz/goof"
 
@@ -20,6 +19,7 @@ import (
 )
 
 const (
+	cephCmd   = "ceph"
 	radosCmd  = "rados"
 	rbdCmd    = "rbd"
 	formatOpt = "--format"
@@ -36,6 +36,21 @@ type rbdMappedEntry struct {
 	Snap   string `json:"snap"`
 }
 
+//Client runs the ceph, rados, and rbd commands with which the driver manages
+//RBD images
+type Client struct {
+	runner CommandRunner
+}
+
+//NewClient returns a Client that runs its commands with the runner, or with
+//the default runner if the runner is nil
+func NewClient(runner CommandRunner) *Client {
+	if runner == nil {
+		runner = NewCommandRunner()
+	}
+	return &Client{runner: runner}
+}
+
 //RBDImage holds details about an RBD image
 type RBDImage struct {
 	Name   string `json:"image"`
@@ -58,18 +73,12 @@ type RBDInfo struct {
 }
 
 //GetRadosPools returns a slice containing all the pool names
-func GetRadosPools(ctx types.Context) ([]*string, error) {
+func (c *Client) GetRadosPools(ctx types.Context) ([]*string, error) {
 
-	cmd := exec.Command(radosCmd, "lspools")
-	ctx.WithFields(map[string]interface{}{
-		"cmd":  radosCmd,
-		"args": cmd.Args,
-	}).Debug("running command")
-
-	out, err := cmd.Output()
+	out, err := c.runner.Output(ctx, radosCmd, "lspools")
 	if err != nil {
-		if exiterr, ok := err.(*exec.ExitError); ok {
-			stderr := string(exiterr.Stderr)
+		if exiterr, ok := err.(*ExitError); ok {
+			stderr := exiterr.Stderr
 			ctx.WithError(
 				exiterr,
 			).WithField(
@@ -94,18 +103,14 @@ func GetRadosPools(ctx types.Context) ([]*string, error) {
 }
 
 //GetRBDImages returns a slice of RBD image info
-func GetRBDImages(ctx types.Context, pool *string) ([]*RBDImage, error) {
-
-	cmd := exec.Command(rbdCmd, "ls", "-p", *pool, "-l", formatOpt, jsonArg)
-	ctx.WithFields(map[string]interface{}{
-		"cmd":  rbdCmd,
-		"args": cmd.Args,
-	}).Debug("running command")
+func (c *Client) GetRBDImages(
+	ctx types.Context, pool *string) ([]*RBDImage, error) {
 
-	out, err := cmd.Output()
+	out, err := c.runner.Output(
+		ctx, rbdCmd, "ls", "-p", *pool, "-l", formatOpt, jsonArg)
 	if err != nil {
-		if exiterr, ok := err.(*exec.ExitError); ok {
-			stderr := string(exiterr.Stderr)
+		if exiterr, ok := err.(*ExitError); ok {
+			stderr := exiterr.Stderr
 			ctx.WithError(
 				exiterr,
 			).WithField(
@@ -134,30 +139,21 @@ func GetRBDImages(ctx types.Context, pool *string) ([]*RBDImage, error) {
 }
 
 //GetRBDInfo gets low-level details about an RBD image
-func GetRBDInfo(
+func (c *Client) GetRBDInfo(
 	ctx types.Context,
 	pool *string,
 	name *string) (*RBDInfo, error) {
 
-	cmd := exec.Command(
-		rbdCmd, "info", "-p", *pool, *name, formatOpt, jsonArg)
-
-	ctx.WithFields(map[string]interface{}{
-		"cmd":  rbdCmd,
-		"args": cmd.Args,
-	}).Debug("running command")
-
-	out, err := cmd.Output()
+	out, err := c.runner.Output(
+		ctx, rbdCmd, "info", "-p", *pool, *name, formatOpt, jsonArg)
 
 	if err != nil {
-		if exiterr, ok := err.(*exec.ExitError); ok {
-			if status, ok := exiterr.Sys().(syscall.WaitStatus); ok {
-				if status.ExitStatus() == 2 {
-					// image does not exist
-					return nil, nil
-				}
+		if exiterr, ok := err.(*ExitError); ok {
+			if exiterr.Status == 2 {
+				// image does not exist
+				return nil, nil
 			}
-			stderr := string(exiterr.Stderr)
+			stderr := exiterr.Stderr
 			ctx.WithError(
 				exiterr,
 			).WithField(
@@ -191,20 +187,14 @@ func GetVolumeID(pool, image *string) *string {
 }
 
 //GetMappedRBDs returns a map of RBDs currently mapped to the *local* host
-func GetMappedRBDs(ctx types.Context) (map[string]string, error) {
-
-	cmd := exec.Command(
-		rbdCmd, "showmapped", formatOpt, jsonArg)
-	ctx.WithFields(map[string]interface{}{
-		"cmd":  rbdCmd,
-		"args": cmd.Args,
-	}).Debug("running command")
-
-	out, err := cmd.Output()
+func (c *Client) GetMappedRBDs(
+	ctx types.Context) (map[string]string, error) {
 
+	out, err := c.runner.Output(
+		ctx, rbdCmd, "showmapped", formatOpt, jsonArg)
 	if err != nil {
-		if exiterr, ok := err.(*exec.ExitError); ok {
-			stderr := string(exiterr.Stderr)
+		if exiterr, ok := err.(*ExitError); ok {
+			stderr := exiterr.Stderr
 			ctx.WithError(
 				exiterr,
 			).WithField(
@@ -235,7 +225,7 @@ func GetMappedRBDs(ctx types.Context) (map[string]string, error) {
 }
 
 //RBDCreate creates a new RBD volume on the cluster
-func RBDCreate(
+func (c *Client) RBDCreate(
 	ctx types.Context,
 	pool *string,
 	image *string,
@@ -243,28 +233,23 @@ func RBDCreate(
 	objectSize *string,
 	features []*string) error {
 
-	cmd := exec.Command(
-		rbdCmd, "create", poolOpt, *pool,
+	args := []string{
+		"create", poolOpt, *pool,
 		"--object-size", *objectSize,
-		"--size", strconv.FormatInt(*sizeGB, 10)+"G",
-	)
+		"--size", strconv.FormatInt(*sizeGB, 10) + "G",
+	}
 
 	for _, feature := range features {
-		cmd.Args = append(cmd.Args, "--image-feature")
-		cmd.Args = append(cmd.Args, *feature)
+		args = append(args, "--image-feature")
+		args = append(args, *feature)
 	}
 
-	cmd.Args = append(cmd.Args, *image)
-	ctx.WithFields(map[string]interface{}{
-		"cmd":  rbdCmd,
-		"args": cmd.Args,
-	}).Debug("running command")
-
-	err := cmd.Run()
+	args = append(args, *image)
 
+	_, err := c.runner.Output(ctx, rbdCmd, args...)
 	if err != nil {
-		if exiterr, ok := err.(*exec.ExitError); ok {
-			stderr := string(exiterr.Stderr)
+		if exiterr, ok := err.(*ExitError); ok {
+			stderr := exiterr.Stderr
 			ctx.WithError(
 				exiterr,
 			).WithField(
@@ -280,19 +265,14 @@ func RBDCreate(
 }
 
 //RBDRemove deletes the RBD volume on the cluster
-func RBDRemove(ctx types.Context, pool *string, image *string) error {
-	cmd := exec.Command(rbdCmd, "rm", poolOpt, *pool, "--no-progress",
-		*image,
-	)
-	ctx.WithFields(map[string]interface{}{
-		"cmd":  rbdCmd,
-		"args": cmd.Args,
-	}).Debug("running command")
+func (c *Client) RBDRemove(
+	ctx types.Context, pool *string, image *string) error {
 
-	err := cmd.Run()
+	_, err := c.runner.Output(
+		ctx, rbdCmd, "rm", poolOpt, *pool, "--no-progress", *image)
 	if err != nil {
-		if exiterr, ok := err.(*exec.ExitError); ok {
-			stderr := string(exiterr.Stderr)
+		if exiterr, ok := err.(*ExitError); ok {
+			stderr := exiterr.Stderr
 			ctx.WithError(
 				exiterr,
 			).WithField(
@@ -307,19 +287,45 @@ func RBDRemove(ctx types.Context, pool *string, image *string) error {
 	return nil
 }
 
-//RBDMap attaches the given RBD image to the *local* host
-func RBDMap(ctx types.Context, pool, image *string) (string, error) {
+//RBDRename renames the RBD volume within its pool
+func (c *Client) RBDRename(
+	ctx types.Context, pool, image, name *string) error {
 
-	cmd := exec.Command(rbdCmd, "map", poolOpt, *pool, *image)
-	ctx.WithFields(map[string]interface{}{
-		"cmd":  rbdCmd,
-		"args": cmd.Args,
-	}).Debug("running command")
+	_, err := c.runner.Output(
+		ctx, rbdCmd, "mv", poolOpt, *pool, *image, *name)
+	if err != nil {
+		if exiterr, ok := err.(*ExitError); ok {
+			stderr := exiterr.Stderr
+			ctx.WithError(
+				exiterr,
+			).WithField(
+				"stderr", stderr,
+			).Error("Unable to rename RBD")
+			return goof.Newf("Error renaming RBD: %s",
+				stderr)
+		}
+		return goof.WithError("Error renaming RBD", err)
+	}
+
+	return nil
+}
+
+//RBDMap attaches the given RBD image to the *local* host. The image is
+//mapped read-only if readOnly is true.
+func (c *Client) RBDMap(
+	ctx types.Context,
+	pool, image *string,
+	readOnly bool) (string, error) {
+
+	args := []string{"map", poolOpt, *pool, *image}
+	if readOnly {
+		args = append(args, "--read-only")
+	}
 
-	out, err := cmd.Output()
+	out, err := c.runner.Output(ctx, rbdCmd, args...)
 	if err != nil {
-		if exiterr, ok := err.(*exec.ExitError); ok {
-			stderr := string(exiterr.Stderr)
+		if exiterr, ok := err.(*ExitError); ok {
+			stderr := exiterr.Stderr
 			ctx.WithError(
 				exiterr,
 			).WithField(
@@ -336,18 +342,12 @@ func RBDMap(ctx types.Context, pool, image *string) (string, error) {
 }
 
 //RBDUnmap detaches the given RBD device from the *local* host
-func RBDUnmap(ctx types.Context, device *string) error {
+func (c *Client) RBDUnmap(ctx types.Context, device *string) error {
 
-	cmd := exec.Command(rbdCmd, "unmap", *device)
-	ctx.WithFields(map[string]interface{}{
-		"cmd":  rbdCmd,
-		"args": cmd.Args,
-	}).Debug("running command")
-
-	err := cmd.Run()
+	_, err := c.runner.Output(ctx, rbdCmd, "unmap", *device)
 	if err != nil {
-		if exiterr, ok := err.(*exec.ExitError); ok {
-			stderr := string(exiterr.Stderr)
+		if exiterr, ok := err.(*ExitError); ok {
+			stderr := exiterr.Stderr
 			ctx.WithError(
 				exiterr,
 			).WithField(
@@ -363,22 +363,15 @@ func RBDUnmap(ctx types.Context, device *string) error {
 }
 
 //GetRBDStatus returns a map of RBD status info
-func GetRBDStatus(
+func (c *Client) GetRBDStatus(
 	ctx types.Context,
 	pool, image *string) (map[string]interface{}, error) {
 
-	cmd := exec.Command(
-		rbdCmd, "status", poolOpt, *pool, *image, formatOpt, jsonArg,
-	)
-	ctx.WithFields(map[string]interface{}{
-		"cmd":  rbdCmd,
-		"args": cmd.Args,
-	}).Debug("running command")
-
-	out, err := cmd.Output()
+	out, err := c.runner.Output(
+		ctx, rbdCmd, "status", poolOpt, *pool, *image, formatOpt, jsonArg)
 	if err != nil {
-		if exiterr, ok := err.(*exec.ExitError); ok {
-			stderr := string(exiterr.Stderr)
+		if exiterr, ok := err.(*ExitError); ok {
+			stderr := exiterr.Stderr
 			ctx.WithError(
 				exiterr,
 			).WithField(
@@ -402,12 +395,12 @@ func GetRBDStatus(
 }
 
 //RBDHasWatchers returns true if RBD image has watchers
-func RBDHasWatchers(
+func (c *Client) RBDHasWatchers(
 	ctx types.Context,
 	pool *string,
 	image *string) (bool, error) {
 
-	m, err := GetRBDStatus(ctx, pool, image)
+	m, err := c.GetRBDStatus(ctx, pool, image)
 	if err != nil {
 		return false, err
 	}
@@ -432,6 +425,314 @@ func RBDHasWatchers(
 	}
 }
 
+//GetRBDWatchers returns the addresses of the clients watching an RBD image
+func (c *Client) GetRBDWatchers(
+	ctx types.Context,
+	pool *string,
+	image *string) ([]string, error) {
+
+	m, err := c.GetRBDStatus(ctx, pool, image)
+	if err != nil {
+		return nil, err
+	}
+
+	// see RBDHasWatchers for the formats of the "watchers" key
+	var watchers []interface{}
+	switch v := m["watchers"].(type) {
+	case map[string]interface{}:
+		for _, w := range v {
+			watchers = append(watchers, w)
+		}
+	case []interface{}:
+		watchers = v
+	default:
+		return nil, goof.New("Unable to parse RBD status watchers")
+	}
+
+	var addrs []string
+	for _, w := range watchers {
+		if wm, ok := w.(map[string]interface{}); ok {
+			if addr, ok := wm["address"].(string); ok && addr != "" {
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+	return addrs, nil
+}
+
+//RBDBlacklistWatchers adds the clients of a host that are watching an RBD
+//image to the OSD blacklist so that a host that can no longer be reached, and
+//so cannot unmap the image, is unable to write to it after it is mapped
+//elsewhere. Only the watchers whose addresses are one of the host's IPs are
+//blacklisted, and their addresses are returned.
+func (c *Client) RBDBlacklistWatchers(
+	ctx types.Context,
+	pool *string,
+	image *string,
+	hostIPs []net.IP) ([]string, error) {
+
+	watchers, err := c.GetRBDWatchers(ctx, pool, image)
+	if err != nil {
+		return nil, err
+	}
+
+	var addrs []string
+	for _, addr := range watchers {
+		ip := watcherIP(addr)
+		for _, hostIP := range hostIPs {
+			if ip != nil && ip.Equal(hostIP) {
+				addrs = append(addrs, addr)
+				break
+			}
+		}
+	}
+
+	for _, addr := range addrs {
+		if _, err := c.runner.Output(
+			ctx, cephCmd, "osd", "blacklist", "add", addr); err != nil {
+			if exiterr, ok := err.(*ExitError); ok {
+				stderr := exiterr.Stderr
+				ctx.WithError(
+					exiterr,
+				).WithField(
+					"stderr", stderr,
+				).Error("Unable to blacklist RBD watcher")
+				return nil, goof.Newf(
+					"Unable to blacklist RBD watcher %s: %s", addr, stderr)
+			}
+			return nil, goof.WithError("Unable to blacklist RBD watcher", err)
+		}
+	}
+
+	return addrs, nil
+}
+
+//watcherIP returns the IP of a watcher's address, ex. 10.0.0.1:0/3012345678
+//or [fd00::1]:0/3012345678, or nil if the address cannot be parsed
+func watcherIP(addr string) net.IP {
+	if i := strings.LastIndex(addr, "/"); i >= 0 {
+		addr = addr[:i]
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil
+	}
+	return net.ParseIP(host)
+}
+
+//RBDTrashEntry holds details about an RBD image in the trash
+type RBDTrashEntry struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Source    string `json:"source"`
+	DeletedAt string `json:"deleted_at"`
+	Pool      string
+}
+
+//DeletedTime returns the time at which the image was moved to the trash
+func (e *RBDTrashEntry) DeletedTime() (time.Time, error) {
+	return time.ParseInLocation(time.ANSIC, e.DeletedAt, time.Local)
+}
+
+//GetRBDTrash returns the images in the pool's trash
+func (c *Client) GetRBDTrash(
+	ctx types.Context, pool *string) ([]*RBDTrashEntry, error) {
+
+	out, err := c.runner.Output(
+		ctx, rbdCmd, "trash", "ls", poolOpt, *pool, "-l", formatOpt, jsonArg)
+	if err != nil {
+		if exiterr, ok := err.(*ExitError); ok {
+			stderr := exiterr.Stderr
+			ctx.WithError(
+				exiterr,
+			).WithField(
+				"stderr", stderr,
+			).Error("Unable to get rbd trash")
+			return nil,
+				goof.Newf("Unable to get rbd trash: %s", stderr)
+		}
+		return nil, goof.WithError("Unable to get rbd trash", err)
+	}
+
+	var entries []*RBDTrashEntry
+	if err := json.Unmarshal(out, &entries); err != nil {
+		return nil, goof.WithError("Unable to parse rbd trash ls", err)
+	}
+
+	for _, e := range entries {
+		e.Pool = *pool
+	}
+
+	return entries, nil
+}
+
+//RBDTrashMove moves the RBD image to the pool's trash
+func (c *Client) RBDTrashMove(ctx types.Context, pool, image *string) error {
+	return c.runTrashCmd(ctx, "Unable to move RBD to trash",
+		"mv", poolOpt, *pool, *image)
+}
+
+//RBDTrashRestore restores the RBD image with the given ID from the trash
+func (c *Client) RBDTrashRestore(
+	ctx types.Context, pool, id *string) error {
+
+	return c.runTrashCmd(ctx, "Unable to restore RBD from trash",
+		"restore", poolOpt, *pool, *id)
+}
+
+//RBDTrashRemove deletes the RBD image with the given ID from the trash
+func (c *Client) RBDTrashRemove(ctx types.Context, pool, id *string) error {
+	return c.runTrashCmd(ctx, "Unable to delete RBD from trash",
+		"rm", poolOpt, *pool, "--no-progress", *id)
+}
+
+func (c *Client) runTrashCmd(
+	ctx types.Context, errMsg string, args ...string) error {
+
+	if _, err := c.runner.Output(
+		ctx, rbdCmd, append([]string{"trash"}, args...)...); err != nil {
+		if exiterr, ok := err.(*ExitError); ok {
+			stderr := exiterr.Stderr
+			ctx.WithError(
+				exiterr,
+			).WithField(
+				"stderr", stderr,
+			).Error(errMsg)
+			return goof.Newf("%s: %s", errMsg, stderr)
+		}
+		return goof.WithError(errMsg, err)
+	}
+
+	return nil
+}
+
+//errStatusExists is the exit status of an rbd command that creates something
+//that already exists
+const errStatusExists = 17
+
+//RBDGroupCreate creates the RBD group. False is returned if the group
+//already exists.
+func (c *Client) RBDGroupCreate(
+	ctx types.Context, pool, group *string) (bool, error) {
+
+	return c.runGroupCmd(ctx, "Unable to create RBD group",
+		"create", *pool+"/"+*group)
+}
+
+//RBDGroupImageAdd adds the RBD image to the group if it is not a member of
+//the group already
+func (c *Client) RBDGroupImageAdd(
+	ctx types.Context, pool, group, imagePool, image *string) error {
+
+	_, err := c.runGroupCmd(ctx, "Unable to add RBD to group",
+		"image", "add", *pool+"/"+*group, *imagePool+"/"+*image)
+	return err
+}
+
+//RBDGroupSnapCreate snapshots the images in the group at the same point in
+//time
+func (c *Client) RBDGroupSnapCreate(
+	ctx types.Context, pool, group, snap *string) error {
+
+	_, err := c.runGroupCmd(ctx, "Unable to snapshot RBD group",
+		"snap", "create", *pool+"/"+*group+"@"+*snap)
+	return err
+}
+
+//RBDGroupRemove removes the RBD group and its snapshots
+func (c *Client) RBDGroupRemove(
+	ctx types.Context, pool, group *string) error {
+
+	_, err := c.runGroupCmd(ctx, "Unable to remove RBD group",
+		"remove", *pool+"/"+*group)
+	return err
+}
+
+//runGroupCmd runs an rbd group command. False is returned if the command
+//failed because what it creates already exists.
+func (c *Client) runGroupCmd(
+	ctx types.Context, errMsg string, args ...string) (bool, error) {
+
+	if _, err := c.runner.Output(
+		ctx, rbdCmd, append([]string{"group"}, args...)...); err != nil {
+		if exiterr, ok := err.(*ExitError); ok {
+			if exiterr.Status == errStatusExists {
+				return false, nil
+			}
+			stderr := exiterr.Stderr
+			ctx.WithError(
+				exiterr,
+			).WithField(
+				"stderr", stderr,
+			).Error(errMsg)
+			return false, goof.Newf("%s: %s", errMsg, stderr)
+		}
+		return false, goof.WithError(errMsg, err)
+	}
+
+	return true, nil
+}
+
+//RBDImageIOStats holds the IO rates of an RBD image as reported by the
+//rbd_support manager module
+type RBDImageIOStats struct {
+	Name         string  `json:"image"`
+	ReadOps      float64 `json:"read_ops"`
+	WriteOps     float64 `json:"write_ops"`
+	ReadBytes    float64 `json:"read_bytes"`
+	WriteBytes   float64 `json:"write_bytes"`
+	ReadLatency  float64 `json:"read_latency"`
+	WriteLatency float64 `json:"write_latency"`
+}
+
+//GetRBDImageIOStats returns the IO rates of the images in the pool
+func (c *Client) GetRBDImageIOStats(
+	ctx types.Context, pool *string) ([]*RBDImageIOStats, error) {
+
+	out, err := c.runner.Output(
+		ctx, rbdCmd, "perf", "image", "iostat", "--iterations", "1",
+		formatOpt, jsonArg, *pool)
+	if err != nil {
+		if exiterr, ok := err.(*ExitError); ok {
+			stderr := exiterr.Stderr
+			ctx.WithError(
+				exiterr,
+			).WithField(
+				"stderr", stderr,
+			).Error("Unable to get rbd image iostat")
+			return nil,
+				goof.Newf("Unable to get rbd image iostat: %s", stderr)
+		}
+		return nil, goof.WithError("Unable to get rbd image iostat", err)
+	}
+
+	var stats []*RBDImageIOStats
+	if err := json.Unmarshal(out, &stats); err != nil {
+		return nil, goof.WithError("Unable to parse rbd image iostat", err)
+	}
+
+	return stats, nil
+}
+
+// ParsePoolRacks returns the racks of the pools from a list of "pool=rack"
+// pairs. Pairs that are not of that form are ignored.
+func ParsePoolRacks(pairs []string) map[string]string {
+	racks := map[string]string{}
+	for _, p := range pairs {
+		parts := strings.SplitN(p, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		pool := strings.TrimSpace(parts[0])
+		rack := strings.TrimSpace(parts[1])
+		if pool == "" || rack == "" {
+			continue
+		}
+		racks[pool] = rack
+	}
+	return racks
+}
+
 //ConvStrArrayToPtr converts the slice of strings to a slice of pointers to str
 func ConvStrArrayToPtr(strArr []string) []*string {
 	ptrArr := make([]*string, len(strArr))
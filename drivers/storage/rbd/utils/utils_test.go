@@ -0,0 +1,165 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_rbd
+
+package utils
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/context"
+)
+
+func TestGetMappedRBDs(t *testing.T) {
+	r := &FakeRunner{Outputs: map[string]string{
+		"rbd showmapped --format json": `{"0":{"pool":"rbd",` +
+			`"name":"vol1","snap":"-","device":"/dev/rbd0"}}`,
+	}}
+	c := NewClient(r)
+
+	devMap, err := c.GetMappedRBDs(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"rbd.vol1": "/dev/rbd0"}, devMap)
+}
+
+func TestGetRBDInfo(t *testing.T) {
+	pool, name := "rbd", "vol1"
+	line := "rbd info -p rbd vol1 --format json"
+
+	r := &FakeRunner{
+		Outputs: map[string]string{
+			line: `{"name":"vol1","size":1073741824,"format":2}`,
+		},
+	}
+	c := NewClient(r)
+	info, err := c.GetRBDInfo(context.Background(), &pool, &name)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1073741824), info.Size)
+	assert.Equal(t, "rbd", info.Pool)
+
+	// an image that does not exist is not an error
+	r.Errors = map[string]error{line: &ExitError{Status: 2}}
+	info, err = c.GetRBDInfo(context.Background(), &pool, &name)
+	assert.NoError(t, err)
+	assert.Nil(t, info)
+
+	r.Errors[line] = &ExitError{Status: 1, Stderr: "connection timed out"}
+	_, err = c.GetRBDInfo(context.Background(), &pool, &name)
+	assert.EqualError(t, err, "Unable to get rbd info: connection timed out")
+}
+
+func TestRBDHasWatchers(t *testing.T) {
+	pool, name := "rbd", "vol1"
+	line := "rbd status --pool rbd vol1 --format json"
+
+	for out, expected := range map[string]bool{
+		`{"watchers":{"watcher":{"address":"10.0.0.1:0/1"}}}`: true,
+		`{"watchers":[{"address":"10.0.0.1:0/1"}]}`:           true,
+		`{"watchers":[]}`: false,
+	} {
+		r := &FakeRunner{Outputs: map[string]string{line: out}}
+		ok, err := NewClient(r).RBDHasWatchers(
+			context.Background(), &pool, &name)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, ok, out)
+	}
+}
+
+func TestRBDCreate(t *testing.T) {
+	pool, name, objectSize := "rbd", "vol1", "4M"
+	size := int64(8)
+	layering := "layering"
+
+	r := &FakeRunner{}
+	err := NewClient(r).RBDCreate(context.Background(),
+		&pool, &name, &size, &objectSize, []*string{&layering})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"rbd create --pool rbd --object-size 4M --size 8G " +
+			"--image-feature layering vol1",
+	}, r.Commands)
+}
+
+func TestRBDGroupSnapCreate(t *testing.T) {
+	pool, group, imagePool, image, snap := "rbd", "g1", "ssd", "vol1", "s1"
+	ctx := context.Background()
+
+	// a group or group member that already exists is not an error
+	r := &FakeRunner{Errors: map[string]error{
+		"rbd group create rbd/g1":             &ExitError{Status: 17},
+		"rbd group image add rbd/g1 ssd/vol1": &ExitError{Status: 17},
+	}}
+	c := NewClient(r)
+	created, err := c.RBDGroupCreate(ctx, &pool, &group)
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.NoError(t, c.RBDGroupImageAdd(ctx, &pool, &group, &imagePool, &image))
+	assert.NoError(t, c.RBDGroupSnapCreate(ctx, &pool, &group, &snap))
+	assert.Equal(t, []string{
+		"rbd group create rbd/g1",
+		"rbd group image add rbd/g1 ssd/vol1",
+		"rbd group snap create rbd/g1@s1",
+	}, r.Commands)
+
+	r = &FakeRunner{Errors: map[string]error{
+		"rbd group snap create rbd/g1@s1": &ExitError{
+			Status: 1, Stderr: "operation not supported"},
+	}}
+	c = NewClient(r)
+	created, err = c.RBDGroupCreate(ctx, &pool, &group)
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.EqualError(t, c.RBDGroupSnapCreate(ctx, &pool, &group, &snap),
+		"Unable to snapshot RBD group: operation not supported")
+}
+
+func TestRBDBlacklistWatchers(t *testing.T) {
+	pool, name := "rbd", "vol1"
+	ctx := context.Background()
+
+	r := &FakeRunner{Outputs: map[string]string{
+		"rbd status --pool rbd vol1 --format json": `{"watchers":[` +
+			`{"address":"10.0.0.1:0/3012345678"},` +
+			`{"address":"10.0.0.2:0/1234567890"},` +
+			`{"address":"10.0.0.1:0/2012345678"},` +
+			`{"address":"[fd00::1]:0/3012345678"}]}`,
+	}}
+	c := NewClient(r)
+
+	// only the watchers of the fenced host are blacklisted
+	addrs, err := c.RBDBlacklistWatchers(
+		ctx, &pool, &name, []net.IP{net.ParseIP("10.0.0.1")})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"10.0.0.1:0/3012345678",
+		"10.0.0.1:0/2012345678",
+	}, addrs)
+	assert.Equal(t, []string{
+		"rbd status --pool rbd vol1 --format json",
+		"ceph osd blacklist add 10.0.0.1:0/3012345678",
+		"ceph osd blacklist add 10.0.0.1:0/2012345678",
+	}, r.Commands)
+
+	r.Commands = nil
+	addrs, err = c.RBDBlacklistWatchers(
+		ctx, &pool, &name, []net.IP{net.ParseIP("fd00::1")})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"[fd00::1]:0/3012345678"}, addrs)
+
+	// a host without watchers is not an error
+	r.Commands = nil
+	addrs, err = c.RBDBlacklistWatchers(
+		ctx, &pool, &name, []net.IP{net.ParseIP("10.0.0.3")})
+	assert.NoError(t, err)
+	assert.Empty(t, addrs)
+	assert.Len(t, r.Commands, 1)
+}
+
+func TestParsePoolRacks(t *testing.T) {
+	assert.Equal(t, map[string]string{}, ParsePoolRacks(nil))
+	assert.Equal(t,
+		map[string]string{"rbd": "rack1", "fast": "rack2"},
+		ParsePoolRacks([]string{
+			"rbd=rack1", " fast = rack2 ", "slow", "=rack3", "cold="}))
+}
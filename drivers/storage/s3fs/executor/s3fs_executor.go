@@ -5,16 +5,18 @@ package executor
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path"
+	"strconv"
+	"strings"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
 	gofig "github.com/akutz/gofig/types"
 	"github.com/akutz/goof"
-	"github.com/akutz/gotil"
 
 	"github.com/codedellemc/libstorage/api/registry"
 	"github.com/codedellemc/libstorage/api/types"
+	apiUtils "github.com/codedellemc/libstorage/api/utils"
 
 	"github.com/codedellemc/libstorage/drivers/storage/s3fs"
 	"github.com/codedellemc/libstorage/drivers/storage/s3fs/utils"
@@ -22,10 +24,13 @@ import (
 
 // driver is the storage executor for the s3fs storage driver.
 type driver struct {
-	config gofig.Config
-	cmd    string
-	opts   []string
-	szOpts string
+	config       gofig.Config
+	backend      string
+	cmd          string
+	opts         []string
+	szOpts       string
+	supervise    bool
+	restartDelay time.Duration
 }
 
 func init() {
@@ -40,9 +45,28 @@ func (d *driver) Init(ctx types.Context, config gofig.Config) error {
 	d.config = config
 
 	fields := log.Fields{"driver": s3fs.Name}
-	d.cmd = d.config.GetString(s3fs.ConfigS3FSCmd)
+
+	switch d.backend = d.getBackend(); d.backend {
+	case s3fs.BackendS3FS:
+		d.cmd = d.config.GetString(s3fs.ConfigS3FSCmd)
+	case s3fs.BackendGoofys:
+		d.cmd = d.config.GetString(s3fs.ConfigS3FSGoofysCmd)
+	default:
+		return goof.WithField("backend", d.backend, "invalid s3fs backend")
+	}
+	fields["backend"] = d.backend
 	fields["cmd"] = d.cmd
 
+	d.supervise = d.config.GetBool(s3fs.ConfigS3FSSupervise)
+	fields["supervise"] = d.supervise
+
+	delay, err := time.ParseDuration(d.getRestartDelay())
+	if err != nil {
+		return goof.WithError("invalid s3fs restart delay", err)
+	}
+	d.restartDelay = delay
+	fields["restartDelay"] = d.restartDelay
+
 	if v := d.config.GetStringSlice(s3fs.ConfigS3FSOptions); len(v) > 0 {
 		d.opts = v
 		fields["opts"] = d.opts
@@ -66,7 +90,8 @@ func (d *driver) Supported(
 	ctx types.Context,
 	opts types.Store) (bool, error) {
 
-	return gotil.FileExistsInPath(d.cmd), nil
+	_, err := apiUtils.LookPath(d.cmd)
+	return err == nil, nil
 }
 
 // InstanceID
@@ -145,33 +170,57 @@ func (d *driver) s3fsMount(
 	bucket, mountPoint string,
 	opts *types.DeviceMountOpts) error {
 
-	args := []string{bucket, mountPoint}
-	if len(d.opts) > 0 {
-		for _, o := range d.opts {
-			args = append(args, fmt.Sprintf("-o%s", o))
-		}
-	} else if d.szOpts != "" {
-		args = append(args, d.szOpts)
+	var mountOptions string
+	if opts != nil {
+		mountOptions = opts.MountOptions
+	}
+	args, err := d.mountArgs(bucket, mountPoint, mountOptions)
+	if err != nil {
+		return err
 	}
 
 	fields := map[string]interface{}{
 		"bucket":           bucket,
 		"mountPoint":       mountPoint,
+		"backend":          d.backend,
 		"cmd":              d.cmd,
-		"args":             args,
+		"args":             apiUtils.RedactArgs(args),
 		"isAWSAuthEnvVars": false,
+		"supervise":        d.supervise,
 	}
 
-	cmd := exec.Command(d.cmd, args...)
+	cmd := apiUtils.Command(d.cmd, args...)
 	if ak := d.getAccessKey(); ak != "" {
 		if sk := d.getSecretKey(); sk != "" {
-			cmd.Env = os.Environ()
-			cmd.Env = append(cmd.Env, fmt.Sprintf("AWSACCESSKEYID=%s", ak))
-			cmd.Env = append(cmd.Env, fmt.Sprintf("AWSSECRETACCESSKEY=%s", sk))
+			if cmd.Env == nil {
+				cmd.Env = os.Environ()
+			}
+			switch d.backend {
+			case s3fs.BackendGoofys:
+				cmd.Env = append(cmd.Env,
+					fmt.Sprintf("AWS_ACCESS_KEY_ID=%s", ak),
+					fmt.Sprintf("AWS_SECRET_ACCESS_KEY=%s", sk))
+			default:
+				cmd.Env = append(cmd.Env,
+					fmt.Sprintf("AWSACCESSKEYID=%s", ak),
+					fmt.Sprintf("AWSSECRETACCESSKEY=%s", sk))
+			}
 			fields["isAWSAuthEnvVars"] = true
 		}
 	}
 
+	// a supervised FUSE process runs in the foreground of the mount manager,
+	// which restarts it if it exits with an error
+	if d.supervise {
+		ctx.WithFields(fields).Debug("attempting supervised s3fs mount")
+		if err := startSupervised(
+			ctx, cmd, mountPoint, d.restartDelay); err != nil {
+			return goof.WithFieldsE(
+				fields, "error mounting s3fs bucket", err)
+		}
+		return nil
+	}
+
 	ctx.WithFields(fields).Debug("attempting s3fs mount")
 
 	out, err := cmd.CombinedOutput()
@@ -183,6 +232,103 @@ func (d *driver) s3fsMount(
 	return nil
 }
 
+// mountArgs returns the arguments with which the backend's command mounts a
+// bucket. The mount options are comma-separated, and the cache and
+// read-ahead options are translated to the backend's own options. Other
+// mount options are passed to the backend with -o.
+func (d *driver) mountArgs(
+	bucket, mountPoint, mountOptions string) ([]string, error) {
+
+	var (
+		args     []string
+		fuseOpts []string
+	)
+
+	for _, o := range strings.Split(mountOptions, ",") {
+		if o = strings.TrimSpace(o); o == "" {
+			continue
+		}
+		k, v := o, ""
+		if x := strings.Index(o, "="); x >= 0 {
+			k, v = o[:x], o[x+1:]
+		}
+		switch k {
+		case s3fs.MountOptStatCacheTTL:
+			ttl, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, goof.WithFieldE(
+					"option", o, "invalid mount option", err)
+			}
+			if d.backend == s3fs.BackendGoofys {
+				args = append(args, "--stat-cache-ttl", ttl.String())
+			} else {
+				fuseOpts = append(fuseOpts, "stat_cache_expire="+
+					strconv.Itoa(int(ttl.Seconds())))
+			}
+		case s3fs.MountOptTypeCacheTTL:
+			ttl, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, goof.WithFieldE(
+					"option", o, "invalid mount option", err)
+			}
+			// s3fs caches an object's type with its metadata
+			if d.backend == s3fs.BackendGoofys {
+				args = append(args, "--type-cache-ttl", ttl.String())
+			}
+		case s3fs.MountOptCacheDir:
+			if d.backend == s3fs.BackendGoofys {
+				args = append(args, "--cache", v)
+			} else {
+				fuseOpts = append(fuseOpts, "use_cache="+v)
+			}
+		case s3fs.MountOptReadAhead:
+			if _, err := strconv.ParseUint(v, 10, 64); err != nil {
+				return nil, goof.WithFieldE(
+					"option", o, "invalid mount option", err)
+			}
+			fuseOpts = append(fuseOpts, "max_readahead="+v)
+		default:
+			fuseOpts = append(fuseOpts, o)
+		}
+	}
+
+	if d.supervise {
+		args = append(args, "-f")
+	}
+
+	if d.backend == s3fs.BackendGoofys {
+		// goofys's own flags are passed as they are configured
+		for _, o := range d.opts {
+			if strings.HasPrefix(o, "-") {
+				args = append(args, strings.Fields(o)...)
+			} else {
+				fuseOpts = append(fuseOpts, o)
+			}
+		}
+		if d.szOpts != "" {
+			args = append(args, strings.Fields(d.szOpts)...)
+		}
+		for _, o := range fuseOpts {
+			args = append(args, "-o", o)
+		}
+		// goofys expects the bucket and mount point after its options
+		return append(args, bucket, mountPoint), nil
+	}
+
+	args = append([]string{bucket, mountPoint}, args...)
+	if len(d.opts) > 0 {
+		for _, o := range d.opts {
+			args = append(args, fmt.Sprintf("-o%s", o))
+		}
+	} else if d.szOpts != "" {
+		args = append(args, d.szOpts)
+	}
+	for _, o := range fuseOpts {
+		args = append(args, fmt.Sprintf("-o%s", o))
+	}
+	return args, nil
+}
+
 func (d *driver) findMountPoint(
 	ctx types.Context,
 	bucket string) (string, bool) {
@@ -197,7 +343,46 @@ func (d *driver) findMountPoint(
 func (d *driver) getMountedBuckets(
 	ctx types.Context) (map[string]string, error) {
 
-	return getMountedBuckets(ctx, path.Base(d.cmd))
+	return getMountedBuckets(ctx, path.Base(d.cmd), d.bucketMountArgs)
+}
+
+// bucketMountArgs returns the bucket and mount point from the command line
+// of a FUSE process. goofys expects them after its options, and s3fs before
+// them.
+func (d *driver) bucketMountArgs(args []string) (string, string, bool) {
+	if len(args) < 3 {
+		return "", "", false
+	}
+	if d.backend == s3fs.BackendGoofys {
+		// the command line read from /proc ends with an empty argument
+		if args[len(args)-1] == "" {
+			args = args[:len(args)-1]
+		}
+		if len(args) < 3 {
+			return "", "", false
+		}
+		bucket := args[len(args)-2]
+		// a bucket may be mounted with a prefix, ex. bucket:prefix
+		if x := strings.Index(bucket, ":"); x >= 0 {
+			bucket = bucket[:x]
+		}
+		return bucket, args[len(args)-1], true
+	}
+	return args[1], args[2], true
+}
+
+func (d *driver) getBackend() string {
+	if v := d.config.GetString(s3fs.ConfigS3FSBackend); v != "" {
+		return strings.ToLower(v)
+	}
+	return s3fs.BackendS3FS
+}
+
+func (d *driver) getRestartDelay() string {
+	if v := d.config.GetString(s3fs.ConfigS3FSRestartDelay); v != "" {
+		return v
+	}
+	return s3fs.DefaultRestartDelay
 }
 
 func (d *driver) getAccessKey() string {
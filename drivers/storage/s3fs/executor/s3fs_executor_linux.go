@@ -3,21 +3,131 @@
 package executor
 
 import (
+	"bufio"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path"
 	"regexp"
 	"runtime"
 	"strings"
 	"sync"
+	"syscall"
+	"time"
+
+	"github.com/akutz/goof"
 
 	"github.com/codedellemc/libstorage/api/types"
+	apiUtils "github.com/codedellemc/libstorage/api/utils"
+)
+
+const (
+	// supervisedMountTimeout is how long to wait for a supervised FUSE
+	// process to mount its bucket.
+	supervisedMountTimeout = 30 * time.Second
+
+	// superviseScript is the mount manager that runs a FUSE process in the
+	// foreground and restarts it when it exits with an error. The mount
+	// point is lazily unmounted before a restart since a crashed FUSE
+	// process leaves its mount point disconnected. The loop ends when the
+	// FUSE process exits cleanly, which it does when the bucket is
+	// unmounted.
+	superviseScript = `while true; do
+	"$@"
+	rc=$?
+	[ $rc -eq 0 ] && exit 0
+	echo "$1 exited with $rc, restarting" >&2
+	fusermount -uz "$MOUNT_POINT" 2>/dev/null || umount -l "$MOUNT_POINT"
+	sleep "$RESTART_DELAY"
+done`
 )
 
+// startSupervised starts the FUSE command under a mount manager that is
+// detached from the executor so that it outlives the executor's process.
+// startSupervised returns once the bucket is mounted.
+func startSupervised(
+	ctx types.Context,
+	cmd *exec.Cmd,
+	mountPoint string,
+	restartDelay time.Duration) error {
+
+	delay := int(restartDelay.Seconds())
+	if delay < 1 {
+		delay = 1
+	}
+
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+
+	sup := apiUtils.Command(
+		"/bin/sh",
+		append([]string{"-c", superviseScript, "s3fs-supervise", cmd.Path},
+			cmd.Args[1:]...)...)
+	sup.Env = append(env,
+		fmt.Sprintf("MOUNT_POINT=%s", mountPoint),
+		fmt.Sprintf("RESTART_DELAY=%d", delay))
+	if sup.SysProcAttr == nil {
+		sup.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	sup.SysProcAttr.Setsid = true
+
+	if err := sup.Start(); err != nil {
+		return err
+	}
+	pid := sup.Process.Pid
+	if err := sup.Process.Release(); err != nil {
+		return err
+	}
+	ctx.WithField("pid", pid).Debug("started s3fs mount manager")
+
+	timeout := time.After(supervisedMountTimeout)
+	for {
+		mounted, err := isMountPoint(mountPoint)
+		if err != nil {
+			return err
+		}
+		if mounted {
+			return nil
+		}
+		select {
+		case <-timeout:
+			return goof.WithFields(goof.Fields{
+				"mountPoint": mountPoint,
+				"pid":        pid,
+			}, "timed out waiting for supervised mount")
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// isMountPoint returns a flag indicating whether or not the path is the
+// mount point of a file system.
+func isMountPoint(mountPoint string) (bool, error) {
+	f, err := os.Open("/proc/mounts")
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 1 && fields[1] == mountPoint {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
 func getMountedBuckets(
 	ctx types.Context,
-	s3fsBinName string) (map[string]string, error) {
+	s3fsBinName string,
+	parseArgs func([]string) (string, string, bool)) (
+	map[string]string, error) {
 
 	s3fsBinRX := regexp.MustCompile(fmt.Sprintf(`^.*%s$`, s3fsBinName))
 
@@ -51,7 +161,9 @@ func getMountedBuckets(
 		if !s3fsBinRX.MatchString(args[0]) {
 			continue
 		}
-		m[args[1]] = args[2]
+		if bucket, mountPoint, ok := parseArgs(args); ok {
+			m[bucket] = mountPoint
+		}
 	}
 	if err := <-errc; err != nil {
 		return nil, err
@@ -2,11 +2,27 @@
 
 package executor
 
-import "github.com/codedellemc/libstorage/api/types"
+import (
+	"os/exec"
+	"time"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
 
 func getMountedBuckets(
 	ctx types.Context,
-	s3fsBinName string) (map[string]string, error) {
+	s3fsBinName string,
+	parseArgs func([]string) (string, string, bool)) (
+	map[string]string, error) {
 
 	return nil, types.ErrNotImplemented
 }
+
+func startSupervised(
+	ctx types.Context,
+	cmd *exec.Cmd,
+	mountPoint string,
+	restartDelay time.Duration) error {
+
+	return types.ErrNotImplemented
+}
@@ -0,0 +1,81 @@
+// +build !libstorage_storage_executor libstorage_storage_executor_s3fs
+
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/drivers/storage/s3fs"
+)
+
+func TestMountArgsS3FS(t *testing.T) {
+	d := &driver{backend: s3fs.BackendS3FS, opts: []string{"allow_other"}}
+	args, err := d.mountArgs(
+		"bucket", "/mnt/bucket",
+		"stat_cache_ttl=1m,cache_dir=/tmp/cache,read_ahead=4096,ro")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"bucket", "/mnt/bucket",
+		"-oallow_other",
+		"-ostat_cache_expire=60",
+		"-ouse_cache=/tmp/cache",
+		"-omax_readahead=4096",
+		"-oro",
+	}, args)
+
+	d.supervise = true
+	args, err = d.mountArgs("bucket", "/mnt/bucket", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"bucket", "/mnt/bucket", "-f", "-oallow_other",
+	}, args)
+}
+
+func TestMountArgsGoofys(t *testing.T) {
+	d := &driver{
+		backend:   s3fs.BackendGoofys,
+		opts:      []string{"--uid 1000", "allow_other"},
+		supervise: true,
+	}
+	args, err := d.mountArgs(
+		"bucket", "/mnt/bucket",
+		"stat_cache_ttl=1m,type_cache_ttl=30s,cache_dir=/tmp/cache,"+
+			"read_ahead=4096")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"--stat-cache-ttl", "1m0s",
+		"--type-cache-ttl", "30s",
+		"--cache", "/tmp/cache",
+		"-f",
+		"--uid", "1000",
+		"-o", "max_readahead=4096",
+		"-o", "allow_other",
+		"bucket", "/mnt/bucket",
+	}, args)
+}
+
+func TestMountArgsInvalid(t *testing.T) {
+	d := &driver{backend: s3fs.BackendS3FS}
+	_, err := d.mountArgs("bucket", "/mnt/bucket", "stat_cache_ttl=soon")
+	assert.Error(t, err)
+	_, err = d.mountArgs("bucket", "/mnt/bucket", "read_ahead=-1")
+	assert.Error(t, err)
+}
+
+func TestBucketMountArgs(t *testing.T) {
+	d := &driver{backend: s3fs.BackendS3FS}
+	b, mp, ok := d.bucketMountArgs(
+		[]string{"s3fs", "bucket", "/mnt/bucket", "-oro", ""})
+	assert.True(t, ok)
+	assert.Equal(t, "bucket", b)
+	assert.Equal(t, "/mnt/bucket", mp)
+
+	d.backend = s3fs.BackendGoofys
+	b, mp, ok = d.bucketMountArgs(
+		[]string{"goofys", "-f", "bucket:prefix", "/mnt/bucket", ""})
+	assert.True(t, ok)
+	assert.Equal(t, "bucket", b)
+	assert.Equal(t, "/mnt/bucket", mp)
+}
@@ -52,6 +52,50 @@ const (
 
 	// Tag is a key constant.
 	Tag = "tag"
+
+	// Backend is a key constant.
+	Backend = "backend"
+
+	// GoofysCmd is a key constant.
+	GoofysCmd = "goofysCmd"
+
+	// Supervise is a key constant.
+	Supervise = "supervise"
+
+	// RestartDelay is a key constant.
+	RestartDelay = "restartDelay"
+
+	// BackendS3FS mounts buckets with s3fs-fuse.
+	BackendS3FS = "s3fs"
+
+	// BackendGoofys mounts buckets with goofys.
+	BackendGoofys = "goofys"
+
+	// DefaultRestartDelay is how long the mount manager waits before it
+	// restarts a FUSE process that exited with an error.
+	DefaultRestartDelay = "5s"
+
+	// MountOptStatCacheTTL is the name of the mount option that sets how
+	// long the metadata of objects is cached, ex. stat_cache_ttl=1m.
+	MountOptStatCacheTTL = "stat_cache_ttl"
+
+	// MountOptTypeCacheTTL is the name of the mount option that sets how
+	// long the types of objects, file or directory, are cached.
+	MountOptTypeCacheTTL = "type_cache_ttl"
+
+	// MountOptCacheDir is the name of the mount option that sets the local
+	// directory in which the data of objects is cached.
+	MountOptCacheDir = "cache_dir"
+
+	// MountOptReadAhead is the name of the mount option that sets the
+	// maximum number of bytes the kernel reads ahead.
+	MountOptReadAhead = "read_ahead"
+
+	// TagMountOpts is the key of the bucket tag in which the options with
+	// which a bucket is mounted are recorded when the bucket is created.
+	// The options are separated by spaces since S3 tags may not contain
+	// commas.
+	TagMountOpts = "libstorage.mountOpts"
 )
 
 const (
@@ -84,6 +128,18 @@ const (
 
 	// ConfigS3FSDisablePathStyle is a config key.
 	ConfigS3FSDisablePathStyle = ConfigS3FS + "." + DisablePathStyle
+
+	// ConfigS3FSBackend is a config key.
+	ConfigS3FSBackend = ConfigS3FS + "." + Backend
+
+	// ConfigS3FSGoofysCmd is a config key.
+	ConfigS3FSGoofysCmd = ConfigS3FS + "." + GoofysCmd
+
+	// ConfigS3FSSupervise is a config key.
+	ConfigS3FSSupervise = ConfigS3FS + "." + Supervise
+
+	// ConfigS3FSRestartDelay is a config key.
+	ConfigS3FSRestartDelay = ConfigS3FS + "." + RestartDelay
 )
 
 func init() {
@@ -126,5 +182,25 @@ func init() {
 		false,
 		"A flag that disables the use of S3's path style for bucket endpoints",
 		ConfigS3FSDisablePathStyle)
+	r.Key(gofig.String,
+		"",
+		BackendS3FS,
+		`The FUSE file system that mounts buckets, "s3fs" or "goofys".`,
+		ConfigS3FSBackend)
+	r.Key(gofig.String,
+		"",
+		"goofys",
+		`The absolute path to the "goofys" binary.`,
+		ConfigS3FSGoofysCmd)
+	r.Key(gofig.Bool,
+		"",
+		false,
+		"A flag that restarts FUSE processes that exit with an error",
+		ConfigS3FSSupervise)
+	r.Key(gofig.String,
+		"",
+		DefaultRestartDelay,
+		"How long to wait before restarting a FUSE process",
+		ConfigS3FSRestartDelay)
 	gofigCore.Register(r)
 }
@@ -3,6 +3,7 @@
 package storage
 
 import (
+	"strings"
 	"sync"
 
 	log "github.com/Sirupsen/logrus"
@@ -195,6 +196,15 @@ func (d *driver) NextDeviceInfo(
 	return s3fsUtils.NextDeviceInfo, nil
 }
 
+// Capabilities returns the capabilities of the driver's storage platform.
+func (d *driver) Capabilities(
+	ctx types.Context) (*types.StorageCapabilities, error) {
+
+	return &types.StorageCapabilities{
+		MultiAttach: true,
+	}, nil
+}
+
 // Type returns the type of storage the driver provides.
 func (d *driver) Type(ctx types.Context) (types.StorageType, error) {
 	return types.Object, nil
@@ -262,7 +272,18 @@ func (d *driver) VolumeCreate(ctx types.Context, volumeName string,
 			"volumeName", volumeName, "error creating s3 bucket", err)
 	}
 
-	return d.toTypeVolume(ctx, volumeName, types.VolAttNone), nil
+	vol := d.toTypeVolume(ctx, volumeName, types.VolAttNone)
+
+	if opts.Opts != nil {
+		if mo := opts.Opts.GetString(types.VolumeFieldMountOpts); mo != "" {
+			if err := d.putMountOpts(ctx, volumeName, mo); err != nil {
+				return nil, err
+			}
+			vol.Fields = map[string]string{types.VolumeFieldMountOpts: mo}
+		}
+	}
+
+	return vol, nil
 }
 
 // VolumeCreateFromSnapshot creates a new volume from an existing snapshot.
@@ -591,9 +612,63 @@ func (d *driver) getVolume(
 	svc, _ := d.getService(ctx, "")
 	req, _ := svc.HeadBucketRequest(&awss3.HeadBucketInput{Bucket: &volumeID})
 	if err := req.Send(); err != nil && req.HTTPResponse.StatusCode != 301 {
-		return nil, utils.NewNotFoundError(volumeID)
+		return nil, utils.NewVolumeNotFoundError(volumeID)
+	}
+	vol := d.toTypeVolume(ctx, volumeID, attachments)
+	if mo := d.getMountOpts(ctx, volumeID); mo != "" {
+		vol.Fields = map[string]string{types.VolumeFieldMountOpts: mo}
+	}
+	return vol, nil
+}
+
+// putMountOpts records the options with which a bucket is mounted in one of
+// the bucket's tags. Commas are not valid in tag values, so the options are
+// separated by spaces in the tag.
+func (d *driver) putMountOpts(
+	ctx types.Context,
+	bucket, mountOpts string) error {
+
+	svc, err := d.getServiceForBucket(ctx, bucket)
+	if err != nil {
+		return err
+	}
+	_, err = svc.PutBucketTagging(&awss3.PutBucketTaggingInput{
+		Bucket: &bucket,
+		Tagging: &awss3.Tagging{
+			TagSet: []*awss3.Tag{
+				{
+					Key:   aws.String(s3fs.TagMountOpts),
+					Value: aws.String(strings.Replace(mountOpts, ",", " ", -1)),
+				},
+			},
+		},
+	})
+	if err != nil {
+		return goof.WithFieldE(
+			"bucket", bucket, "error tagging s3 bucket", err)
+	}
+	return nil
+}
+
+// getMountOpts returns the options with which a bucket is mounted. An empty
+// string is returned if the bucket has no mount options or its tags cannot
+// be read.
+func (d *driver) getMountOpts(ctx types.Context, bucket string) string {
+	svc, err := d.getServiceForBucket(ctx, bucket)
+	if err != nil {
+		return ""
+	}
+	res, err := svc.GetBucketTagging(
+		&awss3.GetBucketTaggingInput{Bucket: &bucket})
+	if err != nil {
+		return ""
+	}
+	for _, t := range res.TagSet {
+		if t.Key != nil && t.Value != nil && *t.Key == s3fs.TagMountOpts {
+			return strings.Join(strings.Fields(*t.Value), ",")
+		}
 	}
-	return d.toTypeVolume(ctx, volumeID, attachments), nil
+	return ""
 }
 
 func (d *driver) getServiceForBucket(
@@ -604,7 +679,7 @@ func (d *driver) getServiceForBucket(
 	res, err := svc.GetBucketLocation(
 		&awss3.GetBucketLocationInput{Bucket: &bucket})
 	if err != nil {
-		return nil, utils.NewNotFoundError(bucket)
+		return nil, utils.NewVolumeNotFoundError(bucket)
 	}
 	var region string
 	if res.LocationConstraint != nil {
@@ -4,7 +4,6 @@ package executor
 
 import (
 	"io/ioutil"
-	"os/exec"
 	"path"
 	"path/filepath"
 	"regexp"
@@ -16,6 +15,7 @@ import (
 
 	"github.com/codedellemc/libstorage/api/registry"
 	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
 	"github.com/codedellemc/libstorage/drivers/storage/scaleio"
 )
 
@@ -137,7 +137,7 @@ func (d *driver) getInstanceID() (*types.InstanceID, error) {
 		return iid, nil
 	}
 
-	out, err := exec.Command(d.drvCfg, "--query_guid").CombinedOutput()
+	out, err := utils.Command(d.drvCfg, "--query_guid").CombinedOutput()
 	if err != nil {
 		return nil, goof.WithError("error getting sdc guid", err)
 	}
@@ -11,6 +11,30 @@ import (
 const (
 	// Name is the name of the storage driver
 	Name = "scaleio"
+
+	// VolumeOptProfile is the name of the volume create option that selects
+	// the storage pools from which a volume is provisioned by the name of
+	// one of the configured profiles.
+	VolumeOptProfile = "profile"
+
+	// VolumeOptProtectionDomain is the name of the volume create option
+	// that selects the protection domain from which a volume is
+	// provisioned.
+	VolumeOptProtectionDomain = "protectionDomain"
+
+	// VolumeOptStoragePool is the name of the volume create option that
+	// selects the storage pool from which a volume is provisioned.
+	VolumeOptStoragePool = "storagePool"
+
+	// StoragePoolSeparator separates the names of a protection domain and
+	// one of its storage pools, ex. corp/gold.
+	StoragePoolSeparator = "/"
+
+	// VolumeTypeThin is the ScaleIO type of thin-provisioned volumes.
+	VolumeTypeThin = "ThinProvisioned"
+
+	// VolumeTypeThick is the ScaleIO type of thick-provisioned volumes.
+	VolumeTypeThick = "ThickProvisioned"
 )
 
 var (
@@ -46,6 +70,7 @@ func init() {
 	r.Key(gofig.String, "", "", "", "scaleio.protectionDomainName")
 	r.Key(gofig.String, "", "", "", "scaleio.storagePoolID")
 	r.Key(gofig.String, "", "", "", "scaleio.storagePoolName")
+	r.Key(gofig.String, "", "", "", "scaleio.storagePools")
 	r.Key(gofig.String, "", "", "", "scaleio.thinOrThick")
 	r.Key(gofig.String, "", "", "", "scaleio.version")
 	gofigCore.Register(r)
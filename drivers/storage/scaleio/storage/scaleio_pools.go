@@ -0,0 +1,253 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_scaleio
+
+package storage
+
+import (
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/akutz/goof"
+	sio "github.com/codedellemc/goscaleio"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/drivers/storage/scaleio"
+)
+
+// initStoragePools finds the storage pools the service spans. The default
+// storage pool is always spanned.
+func (d *driver) initStoragePools() error {
+	d.storagePools = []*sio.StoragePool{d.storagePool}
+	pd := d.protectionDomain.ProtectionDomain
+	d.protectionDomainNames = map[string]string{pd.ID: pd.Name}
+
+	for _, ref := range d.storagePoolRefs() {
+		sp, pdName, err := d.findStoragePool(ref)
+		if err != nil {
+			return err
+		}
+		if d.spansStoragePool(sp.StoragePool.ID) {
+			continue
+		}
+		d.storagePools = append(d.storagePools, sp)
+		d.protectionDomainNames[sp.StoragePool.ProtectionDomainID] = pdName
+	}
+	return nil
+}
+
+// findStoragePool finds the storage pool with the reference. A reference is
+// the name of a protection domain and the name of one of its storage pools
+// separated by a slash. A reference without a protection domain refers to a
+// storage pool in the default protection domain. The name of the storage
+// pool's protection domain is also returned.
+func (d *driver) findStoragePool(
+	ref string) (*sio.StoragePool, string, error) {
+
+	pd := d.protectionDomain
+	poolName := ref
+	if i := strings.Index(ref, scaleio.StoragePoolSeparator); i >= 0 {
+		pdName := ref[:i]
+		poolName = ref[i+1:]
+		sioPD, err := d.system.FindProtectionDomain("", pdName, "")
+		if err != nil {
+			return nil, "", goof.WithFieldsE(eff(goof.Fields{
+				"storagePool": ref,
+			}), "error finding protection domain", err)
+		}
+		pd = sio.NewProtectionDomain(d.client)
+		pd.ProtectionDomain = sioPD
+	}
+
+	sioSP, err := pd.FindStoragePool("", poolName, "")
+	if err != nil {
+		return nil, "", goof.WithFieldsE(eff(goof.Fields{
+			"storagePool": ref,
+		}), "error finding storage pool", err)
+	}
+
+	sp := sio.NewStoragePool(d.client)
+	sp.StoragePool = sioSP
+	return sp, pd.ProtectionDomain.Name, nil
+}
+
+func (d *driver) spansStoragePool(id string) bool {
+	for _, sp := range d.storagePools {
+		if sp.StoragePool.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// selectStoragePool returns the storage pool from which to provision the
+// volume. The candidate pools are those of the profile named by the profile
+// option or the volume's type, otherwise the spanned pools that match the
+// protection domain and storage pool options or the volume's availability
+// zone and type. The candidate with the most capacity available for volume
+// allocation is selected.
+func (d *driver) selectStoragePool(
+	ctx types.Context,
+	vol *types.Volume,
+	opts types.Store) (*sio.StoragePool, error) {
+
+	pdName, poolName, profile := d.storagePoolCriteria(vol, opts)
+
+	var candidates []*sio.StoragePool
+	if profile != "" {
+		refs := d.profile(profile)
+		if len(refs) == 0 {
+			return nil, goof.WithFields(eff(goof.Fields{
+				"profile": profile,
+			}), "unknown storage pool profile")
+		}
+		for _, ref := range refs {
+			sp, _, err := d.findStoragePool(ref)
+			if err != nil {
+				return nil, err
+			}
+			candidates = append(candidates, sp)
+		}
+	} else {
+		candidates = d.spannedStoragePools(pdName, poolName)
+		// a pool the service does not span may still be selected by name
+		if len(candidates) == 0 && poolName != "" {
+			ref := poolName
+			if pdName != "" {
+				ref = pdName + scaleio.StoragePoolSeparator + poolName
+			}
+			sp, _, err := d.findStoragePool(ref)
+			if err != nil {
+				return nil, err
+			}
+			candidates = append(candidates, sp)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, goof.WithFields(eff(goof.Fields{
+			"protectionDomain": pdName,
+			"storagePool":      poolName,
+		}), "no matching storage pool")
+	}
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+
+	return d.mostAvailableStoragePool(ctx, candidates, vol.Size)
+}
+
+// storagePoolCriteria returns the names of the protection domain, storage
+// pool, and profile with which the volume's storage pool is selected. The
+// options take precedence over the volume's availability zone and type, and
+// a type that names a profile selects the profile rather than a pool.
+func (d *driver) storagePoolCriteria(
+	vol *types.Volume,
+	opts types.Store) (pdName, poolName, profile string) {
+
+	pdName = vol.AvailabilityZone
+	poolName = vol.Type
+	if opts != nil {
+		profile = opts.GetString(scaleio.VolumeOptProfile)
+		if v := opts.GetString(scaleio.VolumeOptProtectionDomain); v != "" {
+			pdName = v
+		}
+		if v := opts.GetString(scaleio.VolumeOptStoragePool); v != "" {
+			poolName = v
+		}
+	}
+	if profile == "" && len(d.profile(vol.Type)) > 0 {
+		profile = vol.Type
+		poolName = ""
+	}
+	return pdName, poolName, profile
+}
+
+// spannedStoragePools returns the spanned storage pools that match the names
+// of the protection domain and storage pool. An empty name matches any.
+func (d *driver) spannedStoragePools(
+	pdName, poolName string) []*sio.StoragePool {
+
+	var pools []*sio.StoragePool
+	for _, sp := range d.storagePools {
+		if pdName != "" && !strings.EqualFold(pdName,
+			d.protectionDomainNames[sp.StoragePool.ProtectionDomainID]) {
+			continue
+		}
+		if poolName != "" &&
+			!strings.EqualFold(poolName, sp.StoragePool.Name) {
+			continue
+		}
+		pools = append(pools, sp)
+	}
+	return pools
+}
+
+// mostAvailableStoragePool returns the storage pool with the most capacity
+// available for volume allocation. An error is returned if none of the pools
+// has room for a volume of the size in GiB.
+func (d *driver) mostAvailableStoragePool(
+	ctx types.Context,
+	pools []*sio.StoragePool,
+	size int64) (*sio.StoragePool, error) {
+
+	avail := make([]int64, len(pools))
+	for i, sp := range pools {
+		stats, err := sp.GetStatistics()
+		if err != nil {
+			ctx.WithField("storagePool", sp.StoragePool.Name).WithError(
+				err).Warn("error getting storage pool statistics")
+			avail[i] = -1
+			continue
+		}
+		avail[i] = int64(stats.CapacityAvailableForVolumeAllocationInKb)
+		ctx.WithFields(log.Fields{
+			"storagePool":   sp.StoragePool.Name,
+			"availableInKb": avail[i],
+		}).Debug("storage pool capacity")
+	}
+
+	i, err := mostAvailable(avail, size)
+	if err != nil {
+		return nil, err
+	}
+	return pools[i], nil
+}
+
+// mostAvailable returns the index of the largest of the capacities, in KiB,
+// available for volume allocation. A negative capacity is unknown. An error
+// is returned if no capacity is known or none has room for a volume of the
+// size in GiB.
+func mostAvailable(avail []int64, size int64) (int, error) {
+	var (
+		selected       = -1
+		maxAvail int64 = -1
+	)
+	for i, a := range avail {
+		if a > maxAvail {
+			selected = i
+			maxAvail = a
+		}
+	}
+
+	if selected < 0 {
+		return 0, goof.New("error getting storage pool statistics")
+	}
+	if size*1024*1024 > maxAvail {
+		return 0, goof.WithFields(eff(goof.Fields{
+			"size":          size,
+			"availableInKb": maxAvail,
+		}), "no storage pool has enough capacity")
+	}
+	return selected, nil
+}
+
+// profile returns the storage pool references of the profile.
+func (d *driver) profile(name string) []string {
+	if name == "" {
+		return nil
+	}
+	return d.config.GetStringSlice("scaleio.profiles." + name)
+}
+
+func (d *driver) storagePoolRefs() []string {
+	return d.config.GetStringSlice("scaleio.storagePools")
+}
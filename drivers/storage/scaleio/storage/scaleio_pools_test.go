@@ -0,0 +1,135 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_scaleio
+
+package storage
+
+import (
+	"testing"
+
+	gofigCore "github.com/akutz/gofig"
+	sio "github.com/codedellemc/goscaleio"
+	siotypes "github.com/codedellemc/goscaleio/types/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+	"github.com/codedellemc/libstorage/drivers/storage/scaleio"
+)
+
+func newTestStoragePool(id, name, pdID string) *sio.StoragePool {
+	return &sio.StoragePool{StoragePool: &siotypes.StoragePool{
+		ID:                 id,
+		Name:               name,
+		ProtectionDomainID: pdID,
+	}}
+}
+
+func TestStoragePoolCriteria(t *testing.T) {
+	config := gofigCore.New()
+	config.Set("scaleio.profiles.gold", []string{"pd1/ssd", "pd2/ssd"})
+	d := &driver{config: config}
+
+	opts := func(kv ...string) types.Store {
+		store := utils.NewStore()
+		for i := 0; i+1 < len(kv); i += 2 {
+			store.Set(kv[i], kv[i+1])
+		}
+		return store
+	}
+
+	tests := []struct {
+		name     string
+		vol      *types.Volume
+		opts     types.Store
+		pdName   string
+		poolName string
+		profile  string
+	}{
+		{"none", &types.Volume{}, nil, "", "", ""},
+		{"zone and type",
+			&types.Volume{AvailabilityZone: "pd1", Type: "hdd"}, nil,
+			"pd1", "hdd", ""},
+		{"options override",
+			&types.Volume{AvailabilityZone: "pd1", Type: "hdd"},
+			opts(scaleio.VolumeOptProtectionDomain, "pd2",
+				scaleio.VolumeOptStoragePool, "ssd"),
+			"pd2", "ssd", ""},
+		{"type names profile",
+			&types.Volume{Type: "gold"}, nil,
+			"", "", "gold"},
+		{"profile option",
+			&types.Volume{Type: "hdd"},
+			opts(scaleio.VolumeOptProfile, "silver"),
+			"", "hdd", "silver"},
+	}
+
+	for _, tt := range tests {
+		pdName, poolName, profile := d.storagePoolCriteria(tt.vol, tt.opts)
+		assert.Equal(t, tt.pdName, pdName, tt.name)
+		assert.Equal(t, tt.poolName, poolName, tt.name)
+		assert.Equal(t, tt.profile, profile, tt.name)
+	}
+}
+
+func TestSpannedStoragePools(t *testing.T) {
+	d := &driver{
+		storagePools: []*sio.StoragePool{
+			newTestStoragePool("sp1", "hdd", "pd1"),
+			newTestStoragePool("sp2", "ssd", "pd1"),
+			newTestStoragePool("sp3", "ssd", "pd2"),
+		},
+		protectionDomainNames: map[string]string{
+			"pd1": "domain1",
+			"pd2": "domain2",
+		},
+	}
+
+	tests := []struct {
+		pdName   string
+		poolName string
+		ids      []string
+	}{
+		{"", "", []string{"sp1", "sp2", "sp3"}},
+		{"domain1", "", []string{"sp1", "sp2"}},
+		{"", "SSD", []string{"sp2", "sp3"}},
+		{"Domain2", "ssd", []string{"sp3"}},
+		{"domain2", "hdd", nil},
+		{"domain3", "", nil},
+	}
+
+	for _, tt := range tests {
+		var ids []string
+		for _, sp := range d.spannedStoragePools(tt.pdName, tt.poolName) {
+			ids = append(ids, sp.StoragePool.ID)
+		}
+		assert.Equal(t, tt.ids, ids, "%+v", tt)
+	}
+}
+
+func TestMostAvailable(t *testing.T) {
+	const gib = 1024 * 1024
+
+	tests := []struct {
+		name     string
+		avail    []int64
+		size     int64
+		selected int
+		err      bool
+	}{
+		{"most available",
+			[]int64{10 * gib, 30 * gib, 20 * gib}, 8, 1, false},
+		{"unknown skipped", []int64{-1, 5 * gib}, 4, 1, false},
+		{"exact fit", []int64{8 * gib}, 8, 0, false},
+		{"too large", []int64{10 * gib, 30 * gib}, 40, 0, true},
+		{"all unknown", []int64{-1, -1}, 1, 0, true},
+	}
+
+	for _, tt := range tests {
+		i, err := mostAvailable(tt.avail, tt.size)
+		if tt.err {
+			assert.Error(t, err, tt.name)
+			continue
+		}
+		assert.NoError(t, err, tt.name)
+		assert.Equal(t, tt.selected, i, tt.name)
+	}
+}
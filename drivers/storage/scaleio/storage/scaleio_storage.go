@@ -29,6 +29,12 @@ type driver struct {
 	system           *sio.System
 	protectionDomain *sio.ProtectionDomain
 	storagePool      *sio.StoragePool
+
+	// storagePools are the storage pools the service spans, and
+	// protectionDomainNames are the names of their protection domains
+	// keyed by ID.
+	storagePools          []*sio.StoragePool
+	protectionDomainNames map[string]string
 }
 
 func init() {
@@ -111,6 +117,12 @@ func (d *driver) Init(context types.Context, config gofig.Config) error {
 	d.storagePool = sio.NewStoragePool(d.client)
 	d.storagePool.StoragePool = sp
 
+	if err = d.initStoragePools(); err != nil {
+		log.WithFields(fields).Debug(err.Error())
+		return err
+	}
+	fields["storagePools"] = len(d.storagePools)
+
 	log.WithFields(fields).Info("storage driver initialized")
 
 	return nil
@@ -120,6 +132,16 @@ func (d *driver) Type(ctx types.Context) (types.StorageType, error) {
 	return types.Block, nil
 }
 
+func (d *driver) Capabilities(
+	ctx types.Context) (*types.StorageCapabilities, error) {
+
+	return &types.StorageCapabilities{
+		MaxVolumeSize: 1048576,
+		Provisioning: []string{
+			types.ProvisioningThin, types.ProvisioningThick},
+	}, nil
+}
+
 func (d *driver) NextDeviceInfo(
 	ctx types.Context) (*types.NextDeviceInfo, error) {
 	return nil, nil
@@ -371,7 +393,7 @@ func (d *driver) VolumeCreate(ctx types.Context, volumeName string,
 		volume.IOPS = *opts.IOPS
 	}
 
-	vol, err := d.createVolume(ctx, volumeName, volume)
+	vol, err := d.createVolume(ctx, volumeName, volume, opts.Thin, opts.Opts)
 	if err != nil {
 		return nil, err
 	}
@@ -655,7 +677,8 @@ func (d *driver) getVolume(
 }
 
 func (d *driver) createVolume(ctx types.Context, volumeName string,
-	vol *types.Volume) (*siotypes.VolumeResp, error) {
+	vol *types.Volume, thin *bool,
+	opts types.Store) (*siotypes.VolumeResp, error) {
 
 	volumeName = shrink(volumeName)
 
@@ -671,15 +694,17 @@ func (d *driver) createVolume(ctx types.Context, volumeName string,
 	volumeParam := &siotypes.VolumeParam{
 		Name:           volumeName,
 		VolumeSizeInKb: strconv.Itoa(int(vol.Size) * 1024 * 1024),
-		VolumeType:     d.thinOrThick(),
+		VolumeType:     d.volumeType(thin),
 	}
 
-	if vol.Type == "" {
-		vol.Type = d.storagePool.StoragePool.Name
-		fields["volumeType"] = vol.Type
+	sp, err := d.selectStoragePool(ctx, vol, opts)
+	if err != nil {
+		return nil, goof.WithFieldsE(fields, "error creating volume", err)
 	}
+	fields["storagePool"] = sp.StoragePool.Name
+	fields["storagePoolID"] = sp.StoragePool.ID
 
-	volumeResp, err := d.client.CreateVolume(volumeParam, vol.Type)
+	volumeResp, err := sp.CreateVolume(volumeParam)
 	if err != nil {
 		return nil, goof.WithFieldsE(fields, "error creating volume", err)
 	}
@@ -786,10 +811,22 @@ func (d *driver) storagePoolName() string {
 	return d.config.GetString("scaleio.storagePoolName")
 }
 
+// volumeType returns the ScaleIO type of a new volume, which is thin or
+// thick as requested or the configured default.
+func (d *driver) volumeType(thin *bool) string {
+	if thin == nil {
+		return d.thinOrThick()
+	}
+	if *thin {
+		return scaleio.VolumeTypeThin
+	}
+	return scaleio.VolumeTypeThick
+}
+
 func (d *driver) thinOrThick() string {
 	thinOrThick := d.config.GetString("scaleio.thinOrThick")
 	if thinOrThick == "" {
-		return "ThinProvisioned"
+		return scaleio.VolumeTypeThin
 	}
 	return thinOrThick
 }
@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
@@ -18,6 +17,7 @@ import (
 
 	"github.com/codedellemc/libstorage/api/registry"
 	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
 	"github.com/codedellemc/libstorage/drivers/storage/vbox"
 )
 
@@ -52,8 +52,8 @@ func (d *driver) Supported(
 	opts types.Store) (bool, error) {
 
 	// Use dmidecode if installed
-	if gotil.FileExistsInPath(dmidecodeCmd) {
-		out, err := exec.Command(
+	if _, err := utils.LookPath(dmidecodeCmd); err == nil {
+		out, err := utils.Command(
 			dmidecodeCmd, "-s", "system-product-name").Output()
 		if err == nil {
 			outStr := strings.ToLower(gotil.Trim(string(out)))
@@ -61,7 +61,7 @@ func (d *driver) Supported(
 				return true, nil
 			}
 		}
-		out, err = exec.Command(
+		out, err = utils.Command(
 			dmidecodeCmd, "-s", "system-manufacturer").Output()
 		if err == nil {
 			outStr := strings.ToLower(gotil.Trim(string(out)))
@@ -72,7 +72,7 @@ func (d *driver) Supported(
 	}
 
 	// No luck with dmidecode, try dmesg
-	out, err := exec.Command("dmesg").Output()
+	out, err := utils.Command("dmesg").Output()
 	if err != nil {
 		return false, nil
 	}
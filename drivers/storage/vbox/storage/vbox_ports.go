@@ -0,0 +1,136 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_vbox
+
+package storage
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/akutz/goof"
+	vboxc "github.com/appropriate/go-virtualboxclient/virtualboxclient"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/drivers/storage/vbox"
+)
+
+// portsL serializes hot-plugging media so that concurrent attachments are
+// not made to the same port of the storage controller.
+var portsL = &sync.Mutex{}
+
+// freePort returns the lowest port of the storage controller that has no
+// medium attached. An error is returned if all of the ports are in use.
+func (d *driver) freePort(m *vboxc.Machine) (int32, error) {
+
+	mas, err := m.GetMediumAttachments()
+	if err != nil {
+		return 0, err
+	}
+
+	used := map[int32]bool{}
+	for _, ma := range mas {
+		if ma.Controller == d.controllerName() {
+			used[ma.Port] = true
+		}
+	}
+
+	for port := int32(0); port < int32(d.maxPorts()); port++ {
+		if !used[port] {
+			return port, nil
+		}
+	}
+	return 0, goof.WithFields(log.Fields{
+		"controllerName": d.controllerName(),
+		"maxPorts":       d.maxPorts(),
+	}, "no free port on storage controller")
+}
+
+// hotPlug attaches the medium to the machine. A failed attempt is retried
+// after the configured delay, since hot-plugging fails while the machine's
+// session is locked by another operation. An attempt that failed after
+// attaching the medium is not retried.
+func (d *driver) hotPlug(
+	ctx types.Context,
+	m *vboxc.Machine,
+	medium *vboxc.Medium) error {
+
+	portsL.Lock()
+	defer portsL.Unlock()
+
+	delay, err := time.ParseDuration(d.attachRetryDelay())
+	if err != nil {
+		return goof.WithFieldE(
+			"attachRetryDelay", d.attachRetryDelay(),
+			"invalid attach retry delay", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := m.Refresh(); err != nil {
+			return err
+		}
+
+		// a full controller is reported rather than the error with which
+		// VirtualBox rejects the attachment
+		if _, err := d.freePort(m); err != nil {
+			return err
+		}
+
+		fields := log.Fields{
+			"volumeID": medium.ID,
+			"attempt":  attempt + 1,
+		}
+		ctx.WithFields(fields).Debug("hot-plugging volume")
+
+		err := m.AttachDevice(medium)
+		if err == nil {
+			return nil
+		}
+
+		if ok, aerr := d.isAttached(medium, m.ID); aerr == nil && ok {
+			ctx.WithFields(fields).WithError(err).Warn(
+				"hot-plug reported error but volume is attached")
+			return nil
+		}
+		if attempt >= d.attachRetries() {
+			return goof.WithFieldsE(fields, "error hot-plugging volume", err)
+		}
+
+		ctx.WithFields(fields).WithError(err).Warn(
+			"error hot-plugging volume, retrying")
+		time.Sleep(delay)
+	}
+}
+
+// isAttached returns a flag indicating whether or not the medium is
+// attached to the machine with the ID.
+func (d *driver) isAttached(
+	medium *vboxc.Medium, machineID string) (bool, error) {
+
+	media, err := d.vbox.GetMedium(medium.ID, "")
+	if err != nil {
+		return false, err
+	}
+	for _, med := range media {
+		for _, mid := range med.MachineIDs {
+			if mid == machineID {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (d *driver) maxPorts() int {
+	if v := d.config.GetInt("virtualbox.maxPorts"); v > 0 {
+		return v
+	}
+	return vbox.DefaultMaxPorts
+}
+
+func (d *driver) attachRetries() int {
+	return d.config.GetInt("virtualbox.attachRetries")
+}
+
+func (d *driver) attachRetryDelay() string {
+	return d.config.GetString("virtualbox.attachRetryDelay")
+}
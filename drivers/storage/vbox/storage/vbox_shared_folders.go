@@ -0,0 +1,84 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_vbox
+
+package storage
+
+import (
+	"strings"
+
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/drivers/storage/vbox"
+)
+
+// sharedFolders returns the names of the host shared folders that are
+// exposed as volumes. The folders are defined on the VMs, ex. by Vagrant,
+// and are mounted by the guest additions' vboxsf file system.
+func (d *driver) sharedFolders() []string {
+	return d.config.GetStringSlice("virtualbox.sharedFolders")
+}
+
+// isSharedFolderID returns a flag indicating whether or not the volume ID
+// is that of a host shared folder.
+func isSharedFolderID(volumeID string) bool {
+	return strings.HasPrefix(volumeID, vbox.SharedFolderIDPrefix)
+}
+
+// getSharedFolders returns the host shared folders that match the volume ID
+// or name. All of the shared folders are returned if neither is specified.
+func (d *driver) getSharedFolders(
+	ctx types.Context,
+	volumeID, volumeName string,
+	attachments types.VolumeAttachmentsTypes) []*types.Volume {
+
+	var vols []*types.Volume
+	for _, name := range d.sharedFolders() {
+		id := vbox.SharedFolderIDPrefix + name
+		if volumeID != "" && volumeID != id {
+			continue
+		}
+		if volumeName != "" && volumeName != name {
+			continue
+		}
+		vol := &types.Volume{
+			Name:   name,
+			ID:     id,
+			Status: "available",
+			Type:   vbox.SharedFolderType,
+		}
+		// a shared folder is available to the machine that defines it
+		// without being attached, so it is reported as attached to the
+		// requesting instance
+		if attachments.Requested() {
+			if iid, ok := context.InstanceID(ctx); ok && iid.ID != "" {
+				vol.Attachments = []*types.VolumeAttachment{
+					sharedFolderAttachment(vol, iid),
+				}
+			}
+		}
+		vols = append(vols, vol)
+	}
+	return vols
+}
+
+func sharedFolderAttachment(
+	vol *types.Volume, iid *types.InstanceID) *types.VolumeAttachment {
+
+	return &types.VolumeAttachment{
+		VolumeID:   vol.ID,
+		InstanceID: iid,
+		DeviceName: vol.Name,
+		Fields: map[string]string{
+			types.AttachmentFieldFsType: vbox.SharedFolderFsType,
+		},
+	}
+}
+
+// errSharedFolder is returned by the operations that do not apply to host
+// shared folders.
+func errSharedFolder(volumeID string) error {
+	return goof.WithField(
+		"volumeID", volumeID,
+		"operation not supported for host shared folders")
+}
@@ -97,6 +97,13 @@ func (d *driver) NextDeviceInfo(
 	return nil, nil
 }
 
+// Capabilities returns the capabilities of the driver's storage platform.
+func (d *driver) Capabilities(
+	ctx types.Context) (*types.StorageCapabilities, error) {
+
+	return &types.StorageCapabilities{}, nil
+}
+
 // InstanceInspect returns an instance.
 func (d *driver) InstanceInspect(
 	ctx types.Context,
@@ -296,6 +303,10 @@ func (d *driver) VolumeRemove(
 	volumeID string,
 	opts *types.VolumeRemoveOpts) error {
 
+	if isSharedFolderID(volumeID) {
+		return errSharedFolder(volumeID)
+	}
+
 	d.Lock()
 	defer d.Unlock()
 	if err := d.refreshSession(ctx); err != nil {
@@ -329,6 +340,17 @@ func (d *driver) VolumeAttach(
 		return nil, "", goof.New("missing volume id")
 	}
 
+	// a shared folder is mounted without being attached
+	if isSharedFolderID(volumeID) {
+		vol, err := d.VolumeInspect(
+			ctx, volumeID, &types.VolumeInspectOpts{
+				Attachments: types.VolAttReq})
+		if err != nil {
+			return nil, "", err
+		}
+		return vol, "", nil
+	}
+
 	// review volume with attachments to any host
 	volumes, err := d.getVolume(ctx, volumeID, "", types.VolAttReq)
 	if err != nil {
@@ -387,6 +409,12 @@ func (d *driver) VolumeDetach(
 		return nil, goof.New("missing volume id")
 	}
 
+	if isSharedFolderID(volumeID) {
+		return d.VolumeInspect(
+			ctx, volumeID, &types.VolumeInspectOpts{
+				Attachments: types.VolAttFalse})
+	}
+
 	volumes, err := d.getVolume(ctx, volumeID, "", types.VolAttFalse)
 	if err != nil {
 		return nil, err
@@ -489,13 +517,20 @@ func (d *driver) getVolume(
 		return nil, err
 	}
 
+	sharedFolders := d.getSharedFolders(
+		ctx, volumeID, volumeName, attachments)
+	if isSharedFolderID(volumeID) ||
+		(volumeName != "" && len(sharedFolders) > 0) {
+		return sharedFolders, nil
+	}
+
 	volumes, err := d.vbox.GetMedium(volumeID, volumeName)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(volumes) == 0 {
-		return nil, nil
+		return sharedFolders, nil
 	}
 
 	var mapDN map[string]string
@@ -544,7 +579,7 @@ func (d *driver) getVolume(
 		volumesSD = append(volumesSD, volumeSD)
 	}
 
-	return volumesSD, nil
+	return append(volumesSD, sharedFolders...), nil
 }
 
 func (d *driver) findMachineByInstanceID(
@@ -691,11 +726,7 @@ func (d *driver) attachVolume(
 		return goof.New("too many volumes returned")
 	}
 
-	if err := m.AttachDevice(medium[0]); err != nil {
-		return err
-	}
-
-	return nil
+	return d.hotPlug(ctx, m, medium[0])
 }
 
 func (d *driver) detachVolume(
@@ -10,6 +10,31 @@ import (
 const (
 	// Name is the provider's name.
 	Name = "virtualbox"
+
+	// DefaultMaxPorts is the default number of ports of the storage
+	// controller to which volumes are attached, the most a SATA controller
+	// has.
+	DefaultMaxPorts = 30
+
+	// DefaultAttachRetries is the default number of times a failed attempt
+	// to hot-plug a volume is retried.
+	DefaultAttachRetries = 3
+
+	// DefaultAttachRetryDelay is the default duration to wait before
+	// retrying a failed attempt to hot-plug a volume.
+	DefaultAttachRetryDelay = "2s"
+
+	// SharedFolderIDPrefix is the prefix of the IDs of the volumes that are
+	// host shared folders rather than media.
+	SharedFolderIDPrefix = "vboxsf-"
+
+	// SharedFolderType is the type of the volumes that are host shared
+	// folders.
+	SharedFolderType = "SharedFolder"
+
+	// SharedFolderFsType is the type of file system with which host shared
+	// folders are mounted.
+	SharedFolderFsType = "vboxsf"
 )
 
 func init() {
@@ -24,5 +49,16 @@ func init() {
 	r.Key(gofig.String, "", "/dev/disk/by-id", "", "virtualbox.diskIDPath")
 	r.Key(gofig.String,
 		"", "/sys/class/scsi_host/", "", "virtualbox.scsiHostPath")
+	r.Key(gofig.Int, "", DefaultMaxPorts,
+		"Number of ports of the storage controller", "virtualbox.maxPorts")
+	r.Key(gofig.Int, "", DefaultAttachRetries,
+		"Number of times a failed hot-plug is retried",
+		"virtualbox.attachRetries")
+	r.Key(gofig.String, "", DefaultAttachRetryDelay,
+		"Duration to wait before retrying a failed hot-plug",
+		"virtualbox.attachRetryDelay")
+	r.Key(gofig.String, "", "",
+		"Host shared folders to expose as volumes",
+		"virtualbox.sharedFolders")
 	gofigCore.Register(r)
 }
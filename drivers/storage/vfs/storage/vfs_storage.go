@@ -5,6 +5,8 @@ package storage
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
 	"time"
 
 	gofig "github.com/akutz/gofig/types"
@@ -27,8 +29,9 @@ type driver struct {
 	volCount         int64
 	snapCount        int64
 
-	volPath  string
-	snapPath string
+	volPath     string
+	snapPath    string
+	recyclePath string
 }
 
 func init() {
@@ -49,11 +52,13 @@ func (d *driver) Init(ctx types.Context, config gofig.Config) error {
 
 	d.volPath = vfs.VolumesDirPath(config)
 	d.snapPath = vfs.SnapshotsDirPath(config)
+	d.recyclePath = vfs.RecycleDirPath(config)
 
 	ctx.WithField("vfs.root.path", vfs.RootDir(config)).Info("vfs.root")
 
 	os.MkdirAll(d.volPath, 0755)
 	os.MkdirAll(d.snapPath, 0755)
+	os.MkdirAll(d.recyclePath, 0755)
 
 	d.volJSONGlobPatt = fmt.Sprintf("%s/*.json", d.volPath)
 	d.snapJSONGlobPatt = fmt.Sprintf("%s/*.json", d.snapPath)
@@ -62,7 +67,12 @@ func (d *driver) Init(ctx types.Context, config gofig.Config) error {
 	if err != nil {
 		return nil
 	}
-	d.volCount = int64(len(volJSONPaths)) - 1
+	// recycled volumes are counted so that their IDs are not reused
+	recycledJSONPaths, err := d.getRecycledVolJSONs()
+	if err != nil {
+		return nil
+	}
+	d.volCount = int64(len(volJSONPaths)+len(recycledJSONPaths)) - 1
 
 	snapJSONPaths, err := d.getSnapJSONs()
 	if err != nil {
@@ -90,6 +100,25 @@ func (d *driver) Type(ctx types.Context) (types.StorageType, error) {
 	return types.Object, nil
 }
 
+func (d *driver) Capabilities(
+	ctx types.Context) (*types.StorageCapabilities, error) {
+
+	return &types.StorageCapabilities{
+		Snapshots:    true,
+		Clone:        true,
+		MultiAttach:  true,
+		VolumeFields: true,
+	}, nil
+}
+
+func (d *driver) HealthCheck(ctx types.Context) error {
+	if _, err := os.Stat(d.volPath); err != nil {
+		return goof.WithFieldE(
+			"volPath", d.volPath, "volumes directory unavailable", err)
+	}
+	return nil
+}
+
 func (d *driver) NextDeviceInfo(
 	ctx types.Context) (*types.NextDeviceInfo, error) {
 	return &types.NextDeviceInfo{
@@ -235,6 +264,10 @@ func (d *driver) VolumeCreate(
 
 	context.MustSession(ctx)
 
+	if err := d.VolumeCreateValidate(ctx, name, opts); err != nil {
+		return nil, err
+	}
+
 	v := &types.Volume{
 		ID:     d.newVolumeID(),
 		Name:   name,
@@ -261,6 +294,7 @@ func (d *driver) VolumeCreate(
 			v.Fields[k] = customFields.GetString(k)
 		}
 	}
+	v.Fields = stampMetadata(ctx, v.Fields)
 
 	if err := d.writeVolume(v); err != nil {
 		return nil, err
@@ -269,6 +303,30 @@ func (d *driver) VolumeCreate(
 	return v, nil
 }
 
+// VolumeCreateValidate returns an invalid request error if the volume is
+// not named or if its requested size or IOPS are not positive.
+func (d *driver) VolumeCreateValidate(
+	ctx types.Context,
+	name string,
+	opts *types.VolumeCreateOpts) error {
+
+	if name == "" {
+		return utils.NewInvalidRequestError("name", name, "name is required")
+	}
+	if opts == nil {
+		return nil
+	}
+	if opts.Size != nil && *opts.Size <= 0 {
+		return utils.NewInvalidRequestError(
+			"size", *opts.Size, "size must be positive")
+	}
+	if opts.IOPS != nil && *opts.IOPS < 0 {
+		return utils.NewInvalidRequestError(
+			"iops", *opts.IOPS, "iops must not be negative")
+	}
+	return nil
+}
+
 func (d *driver) VolumeCreateFromSnapshot(
 	ctx types.Context,
 	snapshotID, volumeName string,
@@ -313,6 +371,7 @@ func (d *driver) VolumeCreateFromSnapshot(
 			v.Fields[k] = customFields.GetString(k)
 		}
 	}
+	v.Fields = stampMetadata(ctx, v.Fields)
 
 	if err := d.writeVolume(v); err != nil {
 		return nil, err
@@ -348,6 +407,7 @@ func (d *driver) VolumeCopy(
 			newVol.Fields[k] = customFields.GetString(k)
 		}
 	}
+	newVol.Fields = stampMetadata(ctx, newVol.Fields)
 
 	if err := d.writeVolume(newVol); err != nil {
 		return nil, err
@@ -356,6 +416,69 @@ func (d *driver) VolumeCopy(
 	return newVol, nil
 }
 
+// VolumeImport imports the directory at the path nativeID as a volume. If
+// no volume name is provided the volume is named after the directory.
+func (d *driver) VolumeImport(
+	ctx types.Context,
+	nativeID, volumeName string,
+	opts types.Store) (*types.Volume, error) {
+
+	context.MustSession(ctx)
+
+	fi, err := os.Stat(nativeID)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, utils.NewVolumeNotFoundError(nativeID)
+		}
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return nil, goof.WithField("nativeID", nativeID, "not a directory")
+	}
+
+	volJSONPaths, err := d.getVolJSONs()
+	if err != nil {
+		return nil, err
+	}
+	for _, volJSONPath := range volJSONPaths {
+		v, err := readVolume(volJSONPath)
+		if err != nil {
+			return nil, err
+		}
+		if v.Fields[types.VolumeFieldNativeID] == nativeID {
+			return nil, goof.WithFields(goof.Fields{
+				"nativeID": nativeID,
+				"volumeID": v.ID,
+			}, "volume already imported")
+		}
+	}
+
+	if volumeName == "" {
+		volumeName = filepath.Base(nativeID)
+	}
+
+	v := &types.Volume{
+		ID:   d.newVolumeID(),
+		Name: volumeName,
+		Fields: map[string]string{
+			types.VolumeFieldNativeID: nativeID,
+		},
+	}
+
+	if customFields := opts.GetStore("opts"); customFields != nil {
+		for _, k := range customFields.Keys() {
+			v.Fields[k] = customFields.GetString(k)
+		}
+	}
+	v.Fields = stampMetadata(ctx, v.Fields)
+
+	if err := d.writeVolume(v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
 func (d *driver) VolumeSnapshot(
 	ctx types.Context,
 	volumeID, snapshotName string,
@@ -383,6 +506,7 @@ func (d *driver) VolumeSnapshot(
 			s.Fields[k] = customFields.GetString(k)
 		}
 	}
+	s.Fields = stampMetadata(ctx, s.Fields)
 
 	if err := d.writeSnapshot(s); err != nil {
 		return nil, err
@@ -391,6 +515,55 @@ func (d *driver) VolumeSnapshot(
 	return s, nil
 }
 
+// SnapshotGroupCreate snapshots the volumes at the same point in time.
+func (d *driver) SnapshotGroupCreate(
+	ctx types.Context,
+	volumeIDs []string,
+	snapshotName string,
+	opts types.Store) (*types.SnapshotGroup, error) {
+
+	context.MustSession(ctx)
+
+	vols := make([]*types.Volume, len(volumeIDs))
+	for i, volumeID := range volumeIDs {
+		v, err := d.getVolumeByID(volumeID)
+		if err != nil {
+			return nil, err
+		}
+		vols[i] = v
+	}
+
+	group := &types.SnapshotGroup{
+		Name:       snapshotName,
+		Consistent: true,
+		Snapshots:  map[string]*types.Snapshot{},
+	}
+
+	startTime := time.Now().Unix()
+	for _, v := range vols {
+		s := &types.Snapshot{
+			ID:         d.newSnapshotID(v.ID),
+			VolumeID:   v.ID,
+			VolumeSize: v.Size,
+			Name:       snapshotName,
+			Status:     "online",
+			StartTime:  startTime,
+		}
+		if err := d.writeSnapshot(s); err != nil {
+			// the group's snapshots are removed so that a failed group
+			// does not leave partial snapshots behind
+			for _, gs := range group.Snapshots {
+				os.Remove(d.getSnapPath(gs.ID))
+			}
+			os.Remove(d.getSnapPath(s.ID))
+			return nil, err
+		}
+		group.Snapshots[v.ID] = s
+	}
+
+	return group, nil
+}
+
 func (d *driver) VolumeRemove(
 	ctx types.Context,
 	volumeID string,
@@ -400,12 +573,153 @@ func (d *driver) VolumeRemove(
 
 	volJSONPath := d.getVolPath(volumeID)
 	if !gotil.FileExists(volJSONPath) {
-		return utils.NewNotFoundError(volumeID)
+		return utils.NewVolumeNotFoundError(volumeID)
 	}
 	os.Remove(volJSONPath)
 	return nil
 }
 
+// VolumeRemoveValidate returns a not found error if the volume does not
+// exist.
+func (d *driver) VolumeRemoveValidate(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeRemoveOpts) error {
+
+	if !gotil.FileExists(d.getVolPath(volumeID)) {
+		return utils.NewVolumeNotFoundError(volumeID)
+	}
+	return nil
+}
+
+// StampsVolumeMetadata returns true since the driver records the server's
+// metadata in the fields of the volumes and snapshots it creates.
+func (d *driver) StampsVolumeMetadata(ctx types.Context) bool {
+	return true
+}
+
+// stampMetadata adds the metadata with which the server stamps the objects
+// it creates to the fields of a new volume or snapshot.
+func stampMetadata(
+	ctx types.Context, fields map[string]string) map[string]string {
+
+	md, ok := context.VolumeMetadata(ctx)
+	if !ok {
+		return fields
+	}
+	if fields == nil {
+		fields = map[string]string{}
+	}
+	for k, v := range md {
+		fields[k] = v
+	}
+	return fields
+}
+
+// VolumeUnmanage removes the volume's JSON file. The directory from which
+// an imported volume was imported is left intact.
+func (d *driver) VolumeUnmanage(
+	ctx types.Context,
+	volumeID string,
+	opts types.Store) error {
+
+	context.MustSession(ctx)
+
+	volJSONPath := d.getVolPath(volumeID)
+	if !gotil.FileExists(volJSONPath) {
+		return utils.NewVolumeNotFoundError(volumeID)
+	}
+	return os.Remove(volJSONPath)
+}
+
+func (d *driver) VolumeRecycle(
+	ctx types.Context,
+	volumeID string,
+	opts types.Store) error {
+
+	context.MustSession(ctx)
+
+	vol, err := d.getVolumeByID(volumeID)
+	if err != nil {
+		return err
+	}
+
+	if vol.Fields == nil {
+		vol.Fields = map[string]string{}
+	}
+	vol.Fields[types.VolumeFieldRecycledTime] = strconv.FormatInt(
+		time.Now().Unix(), 10)
+
+	if err := writeVolumeFile(d.getRecycledVolPath(volumeID), vol); err != nil {
+		return err
+	}
+	return os.Remove(d.getVolPath(volumeID))
+}
+
+func (d *driver) RecycledVolumes(
+	ctx types.Context,
+	opts types.Store) ([]*types.Volume, error) {
+
+	context.MustSession(ctx)
+
+	volJSONPaths, err := d.getRecycledVolJSONs()
+	if err != nil {
+		return nil, err
+	}
+
+	volumes := []*types.Volume{}
+	for _, volJSONPath := range volJSONPaths {
+		v, err := readVolume(volJSONPath)
+		if err != nil {
+			return nil, err
+		}
+		volumes = append(volumes, v)
+	}
+
+	return utils.SortVolumeByID(volumes), nil
+}
+
+func (d *driver) RecycledVolumeRestore(
+	ctx types.Context,
+	volumeID string,
+	opts types.Store) (*types.Volume, error) {
+
+	context.MustSession(ctx)
+
+	volJSONPath := d.getRecycledVolPath(volumeID)
+	if !gotil.FileExists(volJSONPath) {
+		return nil, utils.NewVolumeNotFoundError(volumeID)
+	}
+
+	vol, err := readVolume(volJSONPath)
+	if err != nil {
+		return nil, err
+	}
+	delete(vol.Fields, types.VolumeFieldRecycledTime)
+
+	if err := d.writeVolume(vol); err != nil {
+		return nil, err
+	}
+	if err := os.Remove(volJSONPath); err != nil {
+		return nil, err
+	}
+	return vol, nil
+}
+
+func (d *driver) RecycledVolumePurge(
+	ctx types.Context,
+	volumeID string,
+	opts types.Store) error {
+
+	context.MustSession(ctx)
+
+	volJSONPath := d.getRecycledVolPath(volumeID)
+	if !gotil.FileExists(volJSONPath) {
+		return utils.NewVolumeNotFoundError(volumeID)
+	}
+	return os.Remove(volJSONPath)
+}
+
 func (d *driver) VolumeAttach(
 	ctx types.Context,
 	volumeID string,
@@ -440,6 +754,31 @@ func (d *driver) VolumeAttach(
 	return vol, nextDevice, nil
 }
 
+// VolumeAttachValidate returns a not found error if the volume does not
+// exist and a volume attached error if the volume is already attached to the
+// instance and the attachment is not forced.
+func (d *driver) VolumeAttachValidate(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeAttachOpts) error {
+
+	vol, err := d.getVolumeByID(volumeID)
+	if err != nil {
+		return err
+	}
+
+	iid, ok := context.InstanceID(ctx)
+	if !ok || opts.Force {
+		return nil
+	}
+	for _, att := range vol.Attachments {
+		if att.InstanceID != nil && att.InstanceID.ID == iid.ID {
+			return utils.NewVolumeAttachedError(volumeID)
+		}
+	}
+	return nil
+}
+
 func (d *driver) VolumeDetach(
 	ctx types.Context,
 	volumeID string,
@@ -518,6 +857,11 @@ func (d *driver) SnapshotCopy(
 
 	context.MustSession(ctx)
 
+	if dest := opts.GetString("destination"); dest != "" {
+		return nil, utils.NewInvalidRequestError(
+			"destination", dest, "vfs snapshots have no other locations")
+	}
+
 	ogSnap, err := d.getSnapshotByID(snapshotID)
 	if err != nil {
 		return nil, err
@@ -538,6 +882,7 @@ func (d *driver) SnapshotCopy(
 			newSnap.Fields[k] = customFields.GetString(k)
 		}
 	}
+	newSnap.Fields = stampMetadata(ctx, newSnap.Fields)
 
 	if err := d.writeSnapshot(newSnap); err != nil {
 		return nil, err
@@ -555,7 +900,7 @@ func (d *driver) SnapshotRemove(
 
 	snapJSONPath := d.getSnapPath(snapshotID)
 	if !gotil.FileExists(snapJSONPath) {
-		return utils.NewNotFoundError(snapshotID)
+		return utils.NewSnapshotNotFoundError(snapshotID)
 	}
 	os.Remove(snapJSONPath)
 	return nil
@@ -22,7 +22,7 @@ func (d *driver) getSnapshotByID(snapshotID string) (*types.Snapshot, error) {
 	snapJSONPath := d.getSnapPath(snapshotID)
 
 	if !gotil.FileExists(snapJSONPath) {
-		return nil, utils.NewNotFoundError(snapshotID)
+		return nil, utils.NewSnapshotNotFoundError(snapshotID)
 	}
 
 	return readSnapshot(snapJSONPath)
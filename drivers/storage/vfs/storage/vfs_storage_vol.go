@@ -24,7 +24,7 @@ func (d *driver) getVolumeByID(volumeID string) (*types.Volume, error) {
 	volJSONPath := d.getVolPath(volumeID)
 
 	if !gotil.FileExists(volJSONPath) {
-		return nil, utils.NewNotFoundError(volumeID)
+		return nil, utils.NewVolumeNotFoundError(volumeID)
 	}
 
 	return readVolume(volJSONPath)
@@ -45,9 +45,15 @@ func readVolume(path string) (*types.Volume, error) {
 	return v, nil
 }
 
+func (d *driver) getRecycledVolPath(volumeID string) string {
+	return fmt.Sprintf("%s/%s.json", d.recyclePath, volumeID)
+}
+
 func (d *driver) writeVolume(v *types.Volume) error {
-	volJSONPath := d.getVolPath(v.ID)
+	return writeVolumeFile(d.getVolPath(v.ID), v)
+}
 
+func writeVolumeFile(volJSONPath string, v *types.Volume) error {
 	f, err := os.Create(volJSONPath)
 	if err != nil {
 		return err
@@ -67,6 +73,10 @@ func (d *driver) getVolJSONs() ([]string, error) {
 	return filepath.Glob(d.volJSONGlobPatt)
 }
 
+func (d *driver) getRecycledVolJSONs() ([]string, error) {
+	return filepath.Glob(fmt.Sprintf("%s/*.json", d.recyclePath))
+}
+
 func (d *driver) newVolumeID() string {
 	return fmt.Sprintf("vfs-%03d", atomic.AddInt64(&d.volCount, 1))
 }
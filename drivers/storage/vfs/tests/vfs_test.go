@@ -17,12 +17,15 @@ import (
 	"time"
 
 	log "github.com/Sirupsen/logrus"
+	gofigCore "github.com/akutz/gofig"
 	gofig "github.com/akutz/gofig/types"
 	"github.com/akutz/goof"
 	"github.com/akutz/gotil"
 	"github.com/stretchr/testify/assert"
 
+	apiclient "github.com/codedellemc/libstorage/api/client"
 	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/registry"
 	"github.com/codedellemc/libstorage/api/server"
 	apitests "github.com/codedellemc/libstorage/api/tests"
 	"github.com/codedellemc/libstorage/api/types"
@@ -78,6 +81,76 @@ func TestServicesWithControllerClient(t *testing.T) {
 		t, types.ControllerClient, vfs.Name, newTestConfig(t), testServicesFunc)
 }
 
+func TestServerReload(t *testing.T) {
+	host := fmt.Sprintf("tcp://127.0.0.1:%d", gotil.RandomTCPPort())
+	vfsConfig := newTestConfig(t)
+
+	newConfig := func(services ...string) gofig.Config {
+		config := gofigCore.New()
+		assert.NoError(t, config.ReadConfig(bytes.NewReader(vfsConfig)))
+		buf := &bytes.Buffer{}
+		fmt.Fprintf(buf, reloadConfigYAML, host)
+		for _, service := range services {
+			fmt.Fprintf(buf, reloadServiceYAML, service)
+		}
+		assert.NoError(t, config.ReadConfig(buf))
+		return config
+	}
+
+	config := newConfig(vfs.Name)
+	srv, errs, err := server.Serve(nil, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		if err := <-errs; err != nil {
+			t.Errorf("server error: %v", err)
+		}
+	}()
+	defer srv.Close()
+
+	client, err := apiclient.New(nil, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertServices := func(names ...string) {
+		reply, err := client.API().Services(nil)
+		assert.NoError(t, err)
+		assert.Len(t, reply, len(names))
+		for _, name := range names {
+			assert.Contains(t, reply, name)
+		}
+	}
+	assertServices(vfs.Name)
+
+	// a service added to the config is started while the unchanged service
+	// keeps running
+	assert.NoError(t, srv.Reload(newConfig(vfs.Name, "vfs2")))
+	assertServices(vfs.Name, "vfs2")
+
+	// a service removed from the config is stopped
+	assert.NoError(t, srv.Reload(newConfig(vfs.Name)))
+	assertServices(vfs.Name)
+}
+
+func TestServerBindInstanceIDsRequiresClientCerts(t *testing.T) {
+	host := fmt.Sprintf("tcp://127.0.0.1:%d", gotil.RandomTCPPort())
+
+	config := gofigCore.New()
+	assert.NoError(t, config.ReadConfig(bytes.NewReader(newTestConfig(t))))
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, reloadConfigYAML, host)
+	fmt.Fprintf(buf, reloadServiceYAML, vfs.Name)
+	fmt.Fprint(buf, "    bindInstanceIDs: true\n")
+	assert.NoError(t, config.ReadConfig(buf))
+
+	srv, _, err := server.Serve(nil, config)
+	if !assert.Error(t, err) {
+		srv.Close()
+	}
+}
+
 func TestServiceInpspect(t *testing.T) {
 	tf := func(config gofig.Config, client types.Client, t *testing.T) {
 
@@ -90,6 +163,53 @@ func TestServiceInpspect(t *testing.T) {
 	apitests.Run(t, vfs.Name, newTestConfig(t), tf)
 }
 
+func TestServiceInspectMountOptions(t *testing.T) {
+	tc := append(newTestConfig(t), []byte(mountOptionsConfigYAML)...)
+	tf := func(config gofig.Config, client types.Client, t *testing.T) {
+
+		reply, err := client.API().ServiceInspect(nil, vfs.Name)
+		assert.NoError(t, err)
+		assert.Equal(t, "noatime,discard", reply.MountOptions)
+
+		services, err := client.API().Services(nil)
+		assert.NoError(t, err)
+		if assert.Contains(t, services, vfs.Name) {
+			assert.Equal(t,
+				"noatime,discard", services[vfs.Name].MountOptions)
+		}
+	}
+	apitests.Run(t, vfs.Name, tc, tf)
+}
+
+func TestServiceCapabilities(t *testing.T) {
+	tf := func(config gofig.Config, client types.Client, t *testing.T) {
+
+		reply, err := client.API().ServiceCapabilities(nil, vfs.Name)
+		assert.NoError(t, err)
+		assert.True(t, reply.Snapshots)
+		assert.True(t, reply.Clone)
+		assert.False(t, reply.Resize)
+		assert.True(t, reply.VolumeFields)
+	}
+	apitests.Run(t, vfs.Name, newTestConfig(t), tf)
+}
+
+func TestHealth(t *testing.T) {
+	tf := func(config gofig.Config, client types.Client, t *testing.T) {
+
+		reply, err := client.API().Health(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, types.HealthStatusOK, reply.Status)
+		if assert.Contains(t, reply.Services, vfs.Name) {
+			svc := reply.Services[vfs.Name]
+			assert.Equal(t, types.HealthStatusOK, svc.Status)
+			assert.Equal(t, vfs.Name, svc.Driver)
+			assert.Empty(t, svc.Error)
+		}
+	}
+	apitests.Run(t, vfs.Name, newTestConfig(t), tf)
+}
+
 func TestExecutors(t *testing.T) {
 	apitests.Run(t, vfs.Name, newTestConfig(t), apitests.TestExecutors)
 }
@@ -505,6 +625,53 @@ func TestVolumeCreate(t *testing.T) {
 		assert.Equal(t, volType, reply.Type)
 		assert.Equal(t, "2", reply.Fields["priority"])
 		assert.Equal(t, "root@example.com", reply.Fields["owner"])
+
+		// the volume is stamped with the server's metadata
+		assert.Equal(t, vfs.Name, reply.Fields[types.VolumeMetadataService])
+		assert.Equal(t,
+			types.VolumeMetadataSchemaVersion,
+			reply.Fields[types.VolumeMetadataSchema])
+		assert.NotEmpty(t, reply.Fields[types.VolumeMetadataCreated])
+	}
+
+	apitests.Run(t, vfs.Name, newTestConfig(t), tf)
+}
+
+func TestVolumeCreateProvisioningNotSupported(t *testing.T) {
+	tf := func(config gofig.Config, client types.Client, t *testing.T) {
+		size := int64(10240)
+		thin := true
+
+		request := &types.VolumeCreateRequest{
+			Name: "Volume 003",
+			Size: &size,
+			Thin: &thin,
+		}
+
+		_, err := client.API().VolumeCreate(nil, vfs.Name, request)
+		assert.Error(t, err)
+	}
+
+	apitests.Run(t, vfs.Name, newTestConfig(t), tf)
+}
+
+func TestVolumeCreateIntegrationFields(t *testing.T) {
+	tf := func(config gofig.Config, client types.Client, t *testing.T) {
+		request := &types.VolumeCreateRequest{
+			Name: "Volume 003",
+			Opts: map[string]interface{}{
+				types.VolumeFieldFSType:    "xfs",
+				types.VolumeFieldMountOpts: "noatime",
+			},
+		}
+
+		reply, err := client.API().VolumeCreate(nil, vfs.Name, request)
+		assert.NoError(t, err)
+		if err != nil {
+			t.FailNow()
+		}
+		assert.Equal(t, "xfs", reply.Fields[types.VolumeFieldFSType])
+		assert.Equal(t, "noatime", reply.Fields[types.VolumeFieldMountOpts])
 	}
 
 	apitests.Run(t, vfs.Name, newTestConfig(t), tf)
@@ -585,6 +752,99 @@ func TestVolumeCopy(t *testing.T) {
 	apitests.Run(t, vfs.Name, newTestConfig(t), tf)
 }
 
+func TestVolumeStats(t *testing.T) {
+	tf := func(config gofig.Config, client types.Client, t *testing.T) {
+		reply, err := client.API().VolumeStats(nil, vfs.Name, "vfs-000")
+		assert.NoError(t, err)
+		if err != nil {
+			t.FailNow()
+		}
+		assert.Equal(t, "vfs-000", reply.VolumeID)
+		assert.NotZero(t, reply.Time)
+		assert.Nil(t, reply.IO)
+
+		_, err = client.API().VolumeStats(nil, vfs.Name, "vfs-999")
+		assert.True(t, apiclient.IsVolumeNotFound(err))
+	}
+
+	apitests.Run(t, vfs.Name, newTestConfig(t), tf)
+}
+
+func TestVolumeImport(t *testing.T) {
+	tf := func(config gofig.Config, client types.Client, t *testing.T) {
+		dir, err := ioutil.TempDir("", "vfs-import")
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		defer os.RemoveAll(dir)
+
+		request := &types.VolumeImportRequest{
+			NativeID:   dir,
+			VolumeName: "brownfield",
+			Opts: map[string]interface{}{
+				"owner": "user@example.com",
+			},
+		}
+
+		reply, err := client.API().VolumeImport(nil, vfs.Name, request)
+		assert.NoError(t, err)
+		if err != nil {
+			t.FailNow()
+		}
+
+		assert.Equal(t, "vfs-003", reply.ID)
+		assert.Equal(t, request.VolumeName, reply.Name)
+		assert.Equal(t, dir, reply.Fields[types.VolumeFieldNativeID])
+		assert.Equal(t, request.Opts["owner"], reply.Fields["owner"])
+
+		vol, err := client.API().VolumeInspect(nil, vfs.Name, reply.ID, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, request.VolumeName, vol.Name)
+
+		_, err = client.API().VolumeImport(nil, vfs.Name, request)
+		assert.Error(t, err)
+
+		_, err = client.API().VolumeImport(nil, vfs.Name,
+			&types.VolumeImportRequest{NativeID: path.Join(dir, "missing")})
+		assert.True(t, apiclient.IsVolumeNotFound(err))
+	}
+
+	apitests.Run(t, vfs.Name, newTestConfig(t), tf)
+}
+
+func TestVolumeUnmanage(t *testing.T) {
+	tf := func(config gofig.Config, client types.Client, t *testing.T) {
+		dir, err := ioutil.TempDir("", "vfs-unmanage")
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		defer os.RemoveAll(dir)
+
+		request := &types.VolumeImportRequest{NativeID: dir}
+		vol, err := client.API().VolumeImport(nil, vfs.Name, request)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+
+		err = client.API().VolumeUnmanage(nil, vfs.Name, vol.ID, false)
+		assert.NoError(t, err)
+		assert.True(t, gotil.FileExists(dir))
+
+		_, err = client.API().VolumeInspect(nil, vfs.Name, vol.ID, 0)
+		assert.True(t, apiclient.IsVolumeNotFound(err))
+
+		_, err = client.API().VolumeImport(nil, vfs.Name, request)
+		assert.NoError(t, err)
+
+		err = client.API().VolumeUnmanage(nil, vfs.Name, "vfs-001", false)
+		assert.True(t, apiclient.IsVolumeInUse(err))
+		err = client.API().VolumeUnmanage(nil, vfs.Name, "vfs-001", true)
+		assert.NoError(t, err)
+	}
+
+	apitests.Run(t, vfs.Name, newTestConfig(t), tf)
+}
+
 func TestVolumeRemove(t *testing.T) {
 
 	tf1 := func(config gofig.Config, client types.Client, t *testing.T) {
@@ -602,11 +862,130 @@ func TestVolumeRemove(t *testing.T) {
 		httpErr := err.(goof.HTTPError)
 		assert.Equal(t, "resource not found", httpErr.Error())
 		assert.Equal(t, 404, httpErr.Status())
+		assert.True(t, apiclient.IsVolumeNotFound(err))
 	}
 
 	apitests.RunGroup(t, vfs.Name, newTestConfig(t), tf1, tf2)
 }
 
+func TestVolumeRemoveInUse(t *testing.T) {
+	tf := func(config gofig.Config, client types.Client, t *testing.T) {
+		err := client.API().VolumeRemove(nil, vfs.Name, "vfs-001", false)
+		assert.Error(t, err)
+		assert.True(t, apiclient.IsVolumeInUse(err))
+		if httpErr, ok := err.(goof.HTTPError); ok {
+			assert.Equal(t, 409, httpErr.Status())
+		}
+		assertVolDir(t, config, "vfs-001", true)
+
+		err = client.API().VolumeRemove(nil, vfs.Name, "vfs-001", true)
+		assert.NoError(t, err)
+		assertVolDir(t, config, "vfs-001", false)
+	}
+	apitests.Run(t, vfs.Name, newTestConfig(t), tf)
+}
+
+func TestVolumeValidate(t *testing.T) {
+	tf := func(config gofig.Config, client types.Client, t *testing.T) {
+		sd, err := registry.NewStorageDriver(vfs.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx := context.Background()
+		if err := sd.Init(ctx, config); err != nil {
+			t.Fatal(err)
+		}
+		d, ok := sd.(types.ProvidesValidation)
+		if !ok {
+			t.Fatal("vfs driver does not provide validation")
+		}
+
+		size := int64(10240)
+		err = d.VolumeCreateValidate(
+			ctx, "Volume 003", &types.VolumeCreateOpts{Size: &size})
+		assert.NoError(t, err)
+
+		err = d.VolumeCreateValidate(
+			ctx, "", &types.VolumeCreateOpts{Size: &size})
+		assert.IsType(t, &types.ErrInvalidRequest{}, err)
+
+		size = 0
+		err = d.VolumeCreateValidate(
+			ctx, "Volume 003", &types.VolumeCreateOpts{Size: &size})
+		assert.IsType(t, &types.ErrInvalidRequest{}, err)
+
+		iid, err := instanceID()
+		assert.NoError(t, err)
+		ctx = ctx.WithValue(context.InstanceIDKey, iid)
+
+		err = d.VolumeAttachValidate(
+			ctx, "vfs-002", &types.VolumeAttachOpts{})
+		assert.NoError(t, err)
+
+		err = d.VolumeAttachValidate(
+			ctx, "vfs-001", &types.VolumeAttachOpts{})
+		assert.IsType(t, &types.ErrVolumeAttached{}, err)
+
+		err = d.VolumeAttachValidate(
+			ctx, "vfs-001", &types.VolumeAttachOpts{Force: true})
+		assert.NoError(t, err)
+
+		err = d.VolumeAttachValidate(
+			ctx, "vfs-999", &types.VolumeAttachOpts{})
+		assert.IsType(t, &types.ErrNotFound{}, err)
+
+		err = d.VolumeRemoveValidate(
+			ctx, "vfs-002", &types.VolumeRemoveOpts{})
+		assert.NoError(t, err)
+		assertVolDir(t, config, "vfs-002", true)
+
+		err = d.VolumeRemoveValidate(
+			ctx, "vfs-999", &types.VolumeRemoveOpts{})
+		assert.IsType(t, &types.ErrNotFound{}, err)
+	}
+	apitests.Run(t, vfs.Name, newTestConfig(t), tf)
+}
+
+func TestVolumeRemoveRecycled(t *testing.T) {
+	tc := append(newTestConfig(t), []byte(recycleConfigYAML)...)
+	tf := func(config gofig.Config, client types.Client, t *testing.T) {
+		err := client.API().VolumeRemove(nil, vfs.Name, "vfs-002", false)
+		assert.NoError(t, err)
+
+		_, err = client.API().VolumeInspect(nil, vfs.Name, "vfs-002", 0)
+		assert.True(t, apiclient.IsVolumeNotFound(err))
+
+		reply, err := client.API().RecycledVolumes(nil)
+		assert.NoError(t, err)
+		if !assert.Contains(t, reply["vfs"], "vfs-002") {
+			t.FailNow()
+		}
+		assert.NotEmpty(t,
+			reply["vfs"]["vfs-002"].Fields[types.VolumeFieldRecycledTime])
+
+		vol, err := client.API().VolumeUndelete(nil, vfs.Name, "vfs-002")
+		assert.NoError(t, err)
+		assert.Equal(t, "vfs-002", vol.ID)
+		assert.Empty(t, vol.Fields[types.VolumeFieldRecycledTime])
+
+		_, err = client.API().VolumeInspect(nil, vfs.Name, "vfs-002", 0)
+		assert.NoError(t, err)
+
+		err = client.API().VolumeRemove(nil, vfs.Name, "vfs-002", false)
+		assert.NoError(t, err)
+		err = client.API().VolumePurge(nil, vfs.Name, "vfs-002")
+		assert.NoError(t, err)
+
+		reply, err = client.API().RecycledVolumes(nil)
+		assert.NoError(t, err)
+		assert.NotContains(t, reply["vfs"], "vfs-002")
+
+		_, err = client.API().VolumeUndelete(nil, vfs.Name, "vfs-002")
+		assert.Error(t, err)
+	}
+	apitests.Run(t, vfs.Name, tc, tf)
+}
+
 func TestVolumeSnapshot(t *testing.T) {
 	tf := func(config gofig.Config, client types.Client, t *testing.T) {
 		volumeID := "vfs-000"
@@ -655,6 +1034,40 @@ func TestVolumeSnapshot(t *testing.T) {
 	apitests.Run(t, vfs.Name, newTestConfig(t), tf)
 }
 
+func TestSnapshotGroupCreate(t *testing.T) {
+	tf := func(config gofig.Config, client types.Client, t *testing.T) {
+		request := &types.SnapshotGroupCreateRequest{
+			SnapshotName: "group1",
+			VolumeIDs:    []string{"vfs-000", "vfs-001"},
+		}
+
+		reply, err := client.API().SnapshotGroupCreate(nil, vfs.Name, request)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assert.Equal(t, "group1", reply.Name)
+		assert.True(t, reply.Consistent)
+		assert.False(t, reply.Quiesced)
+		assert.Len(t, reply.Snapshots, 2)
+
+		for _, volumeID := range request.VolumeIDs {
+			s := reply.Snapshots[volumeID]
+			if !assert.NotNil(t, s) {
+				continue
+			}
+			assert.Equal(t, volumeID, s.VolumeID)
+			snap, err := client.API().SnapshotInspect(nil, vfs.Name, s.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, s.StartTime, snap.StartTime)
+		}
+
+		request.VolumeIDs = []string{"vfs-000", "vfs-999"}
+		_, err = client.API().SnapshotGroupCreate(nil, vfs.Name, request)
+		assert.Error(t, err)
+	}
+	apitests.Run(t, vfs.Name, newTestConfig(t), tf)
+}
+
 func TestVolumeCreateFromSnapshot(t *testing.T) {
 	tf := func(config gofig.Config, client types.Client, t *testing.T) {
 
@@ -773,6 +1186,84 @@ func TestVolumeDetachWithControllerClient(t *testing.T) {
 		t, types.ControllerClient, vfs.Name, newTestConfig(t), tf)
 }
 
+func TestVolumeForceDetach(t *testing.T) {
+	tf := func(config gofig.Config, client types.Client, t *testing.T) {
+		reply, err := client.API().VolumeInspect(
+			nil, vfs.Name, "vfs-001", types.VolAttReqTrue)
+		assert.NoError(t, err)
+		if !assert.Equal(t, 1, len(reply.Attachments)) {
+			t.FailNow()
+		}
+		iid := reply.Attachments[0].InstanceID.ID
+
+		_, err = client.API().VolumeForceDetach(
+			nil, vfs.Name, "vfs-001", "not-my-instance")
+		assert.NoError(t, err)
+		reply, err = client.API().VolumeInspect(
+			nil, vfs.Name, "vfs-001", types.VolAttReqTrue)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(reply.Attachments))
+
+		_, err = client.API().VolumeForceDetach(nil, vfs.Name, "vfs-001", "")
+		assert.NoError(t, err)
+		reply, err = client.API().VolumeInspect(
+			nil, vfs.Name, "vfs-001", types.VolAttReqTrue)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(reply.Attachments))
+
+		events, err := client.API().Events(nil, vfs.Name, 0, 0)
+		assert.NoError(t, err)
+		var ev *types.Event
+		for _, e := range events {
+			if e.Type == types.EventVolumeForceDetached {
+				ev = e
+			}
+		}
+		if assert.NotNil(t, ev) {
+			assert.Equal(t, "vfs-001", ev.VolumeID)
+			assert.Equal(t, iid, ev.Fields[types.EventFieldInstanceID])
+		}
+	}
+	tc := append(newTestConfig(t), []byte(adminTokenConfigYAML)...)
+	apitests.RunWithClientType(t, types.ControllerClient, vfs.Name, tc, tf)
+}
+
+func TestVolumeForceDetachNotAdmin(t *testing.T) {
+	tf := func(config gofig.Config, client types.Client, t *testing.T) {
+		_, err := client.API().VolumeForceDetach(nil, vfs.Name, "vfs-001", "")
+		assert.Error(t, err)
+		if httpErr, ok := err.(goof.HTTPError); ok {
+			assert.Equal(t, 401, httpErr.Status())
+		}
+		reply, err := client.API().VolumeInspect(
+			nil, vfs.Name, "vfs-001", types.VolAttReqTrue)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(reply.Attachments))
+	}
+	apitests.RunWithClientType(
+		t, types.ControllerClient, vfs.Name, newTestConfig(t), tf)
+}
+
+func TestEventsForService(t *testing.T) {
+	tf := func(config gofig.Config, client types.Client, t *testing.T) {
+		size := int64(10240)
+		_, err := client.API().VolumeCreate(nil, vfs.Name,
+			&types.VolumeCreateRequest{Name: "Volume 003", Size: &size})
+		assert.NoError(t, err)
+
+		events, err := client.API().Events(nil, vfs.Name, 0, 0)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, events)
+		for _, ev := range events {
+			assert.Equal(t, vfs.Name, ev.Service)
+		}
+
+		_, err = client.API().Events(nil, "notAService", 0, 0)
+		assert.Error(t, err)
+	}
+	apitests.Run(t, vfs.Name, newTestConfig(t), tf)
+}
+
 func TestVolumeDetachAllForService(t *testing.T) {
 	tc, _, vols, _ := newTestConfigAll(t)
 	tf := func(config gofig.Config, client types.Client, t *testing.T) {
@@ -877,6 +1368,23 @@ func TestSnapshotCopy(t *testing.T) {
 	apitests.Run(t, vfs.Name, newTestConfig(t), tf)
 }
 
+func TestSnapshotCopyToDestination(t *testing.T) {
+	tf := func(config gofig.Config, client types.Client, t *testing.T) {
+		request := &types.SnapshotCopyRequest{
+			SnapshotName: "Snapshot from vfs-000-000",
+			Destination:  "us-west-2",
+		}
+
+		reply, err := client.API().SnapshotCopy(
+			nil, vfs.Name, "vfs-000-000", request)
+		assert.Error(t, err)
+		assert.Nil(t, reply)
+		httpErr := err.(goof.HTTPError)
+		assert.Equal(t, 400, httpErr.Status())
+	}
+	apitests.Run(t, vfs.Name, newTestConfig(t), tf)
+}
+
 func TestSnapshotRemove(t *testing.T) {
 	tf := func(config gofig.Config, client types.Client, t *testing.T) {
 		reply, err := client.API().SnapshotInspect(nil, "vfs", "vfs-000-002")
@@ -1142,6 +1650,51 @@ func newTestConfigAll(
 
 const configYAML = "vfs:\n  root: %s"
 
+const recycleConfigYAML = `
+libstorage:
+  server:
+    volume:
+      recycle:
+        retention: 1h
+`
+
+const adminTokenConfigYAML = `
+libstorage:
+  server:
+    adminToken: vfs-admin-token
+  client:
+    adminToken: vfs-admin-token
+`
+
+const mountOptionsConfigYAML = `
+libstorage:
+  server:
+    services:
+      vfs:
+        libstorage:
+          integration:
+            volume:
+              operations:
+                mount:
+                  options: noatime,discard
+`
+
+const reloadConfigYAML = `
+libstorage:
+  host: %[1]s
+  server:
+    endpoints:
+      localhost:
+        address: %[1]s
+    services:
+`
+
+const reloadServiceYAML = `      %[1]s:
+        libstorage:
+          storage:
+            driver: vfs
+`
+
 const volJSON = `{
     "availabilityZone": "US",
     "iops":             1000,
@@ -38,6 +38,11 @@ func VolumesDirPath(config gofig.Config) string {
 	return path.Join(RootDir(config), "vol")
 }
 
+// RecycleDirPath returns the path to the VFS recycle bin directory.
+func RecycleDirPath(config gofig.Config) string {
+	return path.Join(RootDir(config), "recycle")
+}
+
 // SnapshotsDirPath returns the path to the VFS volumes directory.
 func SnapshotsDirPath(config gofig.Config) string {
 	return path.Join(RootDir(config), "snap")
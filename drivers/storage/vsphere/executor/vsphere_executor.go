@@ -0,0 +1,90 @@
+// +build !libstorage_storage_executor libstorage_storage_executor_vsphere
+
+package executor
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+
+	gofig "github.com/akutz/gofig/types"
+
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/drivers/storage/vsphere"
+	vsUtils "github.com/codedellemc/libstorage/drivers/storage/vsphere/utils"
+)
+
+const diskIDPath = "/dev/disk/by-id"
+
+var (
+	diskPrefix = regexp.MustCompile(
+		`^` + vsphere.DiskIDPrefix + `([0-9a-f]{32})$`)
+)
+
+type driver struct {
+	config gofig.Config
+}
+
+func init() {
+	registry.RegisterStorageExecutor(vsphere.Name, newDriver)
+}
+
+func newDriver() types.StorageExecutor {
+	return &driver{}
+}
+
+func (d *driver) Name() string {
+	return vsphere.Name
+}
+
+func (d *driver) Init(ctx types.Context, config gofig.Config) error {
+	d.config = config
+	return nil
+}
+
+func (d *driver) InstanceID(
+	ctx types.Context, opts types.Store) (*types.InstanceID, error) {
+	return vsUtils.InstanceID(ctx)
+}
+
+func (d *driver) NextDevice(
+	ctx types.Context, opts types.Store) (string, error) {
+	return "", types.ErrNotImplemented
+}
+
+// LocalDevices maps the UUIDs of the attached VMDKs to their devices, which
+// are resolved from the WWNs derived from the UUIDs rather than from the
+// order in which the VMDKs were attached. The WWNs are only exposed to the
+// guest when the VM's disk.EnableUUID option is set.
+func (d *driver) LocalDevices(
+	ctx types.Context,
+	opts *types.LocalDevicesOpts) (*types.LocalDevices, error) {
+
+	deviceMap := map[string]string{}
+
+	dir, _ := ioutil.ReadDir(diskIDPath)
+	for _, device := range dir {
+		m := diskPrefix.FindStringSubmatch(device.Name())
+		if m == nil {
+			continue
+		}
+		devPath, err := filepath.EvalSymlinks(
+			filepath.Join(diskIDPath, device.Name()))
+		if err != nil {
+			return nil, err
+		}
+		deviceMap[m[1]] = devPath
+	}
+
+	ld := &types.LocalDevices{Driver: d.Name()}
+	if len(deviceMap) > 0 {
+		ld.DeviceMap = deviceMap
+	}
+
+	return ld, nil
+}
+
+func (d *driver) Supported(ctx types.Context, opts types.Store) (bool, error) {
+	return vsUtils.IsVM(ctx)
+}
@@ -0,0 +1,419 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_vsphere
+
+package storage
+
+import (
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/akutz/goof"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/pbm"
+	pbmTypes "github.com/vmware/govmomi/pbm/types"
+	"github.com/vmware/govmomi/task"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	vimTypes "github.com/vmware/govmomi/vim25/types"
+	"golang.org/x/net/context"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+	"github.com/codedellemc/libstorage/drivers/storage/vsphere"
+	vsUtils "github.com/codedellemc/libstorage/drivers/storage/vsphere/utils"
+)
+
+// session is a logged in client and the datacenter and datastore in which
+// the driver manages VMDKs.
+type session struct {
+	client *govmomi.Client
+	dc     *object.Datacenter
+	ds     *object.Datastore
+}
+
+// vmdk is a VMDK in the driver's datastore folder.
+type vmdk struct {
+	name       string
+	path       string
+	uuid       string
+	capacityKB int64
+	thin       bool
+}
+
+// vmdkAttachment is a VM to which a VMDK is attached.
+type vmdkAttachment struct {
+	vmUUID string
+	vmName string
+}
+
+// session returns a session with the vSphere SDK. The client is logged in
+// again if its session has expired.
+func (d *driver) session(ctx types.Context) (*session, error) {
+	c, err := d.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	finder := find.NewFinder(c.Client, true)
+
+	var dc *object.Datacenter
+	if name := d.datacenter(); name != "" {
+		dc, err = finder.Datacenter(ctx, name)
+	} else {
+		dc, err = finder.DefaultDatacenter(ctx)
+	}
+	if err != nil {
+		return nil, goof.WithFieldE(
+			"datacenter", d.datacenter(), "error finding datacenter", err)
+	}
+	finder.SetDatacenter(dc)
+
+	var ds *object.Datastore
+	if name := d.datastore(); name != "" {
+		ds, err = finder.Datastore(ctx, name)
+	} else {
+		ds, err = finder.DefaultDatastore(ctx)
+	}
+	if err != nil {
+		return nil, goof.WithFieldE(
+			"datastore", d.datastore(), "error finding datastore", err)
+	}
+
+	return &session{client: c, dc: dc, ds: ds}, nil
+}
+
+func (d *driver) connect(ctx types.Context) (*govmomi.Client, error) {
+	d.Lock()
+	defer d.Unlock()
+
+	u, err := url.Parse(d.url())
+	if err != nil {
+		return nil, goof.WithFieldE("url", d.url(), "invalid url", err)
+	}
+	u.User = url.UserPassword(d.username(), d.password())
+
+	if d.client != nil {
+		if s, err := d.client.SessionManager.UserSession(ctx); err == nil &&
+			s != nil {
+			return d.client, nil
+		}
+		ctx.Debug("vsphere session expired, logging in")
+		if err := d.client.Login(ctx, u.User); err == nil {
+			return d.client, nil
+		}
+	}
+
+	c, err := govmomi.NewClient(ctx, u, d.insecure())
+	if err != nil {
+		return nil, goof.WithFieldE(
+			"url", d.url(), "error connecting to vsphere", err)
+	}
+	d.client = c
+	return c, nil
+}
+
+// diskPath returns the datastore path of the VMDK with the given name.
+func (d *driver) diskPath(s *session, name string) string {
+	return s.ds.Path(path.Join(d.folder(), name+".vmdk"))
+}
+
+// ensureFolder creates the datastore folder in which VMDKs are created if
+// it does not exist. The top-level folders of vSAN datastores are
+// namespaces that are created with the namespace manager.
+func (d *driver) ensureFolder(ctx types.Context, s *session) error {
+	var mds mo.Datastore
+	if err := s.ds.Properties(
+		ctx, s.ds.Reference(), []string{"summary"}, &mds); err != nil {
+		return goof.WithError("error getting datastore summary", err)
+	}
+
+	vsan := string(vimTypes.HostFileSystemVolumeFileSystemTypeVsan)
+	if mds.Summary.Type == vsan {
+		if _, err := s.ds.Stat(ctx, d.folder()); err == nil {
+			return nil
+		}
+		nm := object.NewDatastoreNamespaceManager(s.client.Client)
+		_, err := nm.CreateDirectory(ctx, s.ds, d.folder(), "")
+		if err != nil {
+			return goof.WithFieldE(
+				"folder", d.folder(), "error creating namespace", err)
+		}
+		return nil
+	}
+
+	fm := object.NewFileManager(s.client.Client)
+	err := fm.MakeDirectory(ctx, s.ds.Path(d.folder()), s.dc, true)
+	if err != nil && !isFileAlreadyExists(err) {
+		return goof.WithFieldE(
+			"folder", d.folder(), "error creating folder", err)
+	}
+	return nil
+}
+
+// searchDisks returns the VMDKs in the driver's datastore folder whose file
+// names match the pattern.
+func (d *driver) searchDisks(
+	ctx types.Context, s *session, pattern string) ([]*vmdk, error) {
+
+	browser, err := s.ds.Browser(ctx)
+	if err != nil {
+		return nil, goof.WithError("error getting datastore browser", err)
+	}
+
+	spec := &vimTypes.HostDatastoreBrowserSearchSpec{
+		MatchPattern: []string{pattern},
+		Query: []vimTypes.BaseFileQuery{
+			&vimTypes.VmDiskFileQuery{
+				Details: &vimTypes.VmDiskFileQueryFlags{
+					CapacityKb: true,
+					DiskType:   true,
+					Thin:       vimTypes.NewBool(true),
+				},
+			},
+		},
+		Details: &vimTypes.FileQueryFlags{
+			FileType: true,
+		},
+	}
+
+	t, err := browser.SearchDatastore(ctx, s.ds.Path(d.folder()), spec)
+	if err != nil {
+		return nil, goof.WithError("error searching datastore", err)
+	}
+	info, err := t.WaitForResult(ctx, nil)
+	if err != nil {
+		// the folder is created with the first VMDK
+		if isFileNotFound(err) {
+			return nil, nil
+		}
+		return nil, goof.WithError("error searching datastore", err)
+	}
+
+	results, ok := info.Result.(vimTypes.HostDatastoreBrowserSearchResults)
+	if !ok {
+		return nil, nil
+	}
+
+	vdm := object.NewVirtualDiskManager(s.client.Client)
+
+	var disks []*vmdk
+	for _, f := range results.File {
+		fi, ok := f.(*vimTypes.VmDiskFileInfo)
+		if !ok {
+			continue
+		}
+		disk := &vmdk{
+			name:       strings.TrimSuffix(fi.Path, ".vmdk"),
+			capacityKB: fi.CapacityKb,
+			thin:       fi.Thin != nil && *fi.Thin,
+		}
+		disk.path = d.diskPath(s, disk.name)
+		uuid, err := vdm.QueryVirtualDiskUuid(ctx, disk.path, s.dc)
+		if err != nil {
+			return nil, goof.WithFieldE(
+				"path", disk.path, "error getting vmdk uuid", err)
+		}
+		disk.uuid = normalizeUUID(uuid)
+		disks = append(disks, disk)
+	}
+	return disks, nil
+}
+
+// getDisk returns the VMDK with the given name.
+func (d *driver) getDisk(
+	ctx types.Context, s *session, name string) (*vmdk, error) {
+
+	disks, err := d.searchDisks(ctx, s, name+".vmdk")
+	if err != nil {
+		return nil, err
+	}
+	for _, disk := range disks {
+		if disk.name == name {
+			return disk, nil
+		}
+	}
+	return nil, utils.NewVolumeNotFoundError(name)
+}
+
+// attachments returns the VMs in the datacenter to which VMDKs are
+// attached, keyed by the VMDKs' UUIDs.
+func (d *driver) attachments(
+	ctx types.Context, s *session) (map[string][]*vmdkAttachment, error) {
+
+	m := view.NewManager(s.client.Client)
+	v, err := m.CreateContainerView(
+		ctx, s.dc.Reference(), []string{"VirtualMachine"}, true)
+	if err != nil {
+		return nil, goof.WithError("error creating vm view", err)
+	}
+	defer v.Destroy(ctx)
+
+	var vms []mo.VirtualMachine
+	if err := v.Retrieve(
+		ctx,
+		[]string{"VirtualMachine"},
+		[]string{"name", "config.uuid", "config.hardware.device"},
+		&vms); err != nil {
+		return nil, goof.WithError("error listing vms", err)
+	}
+
+	atts := map[string][]*vmdkAttachment{}
+	for _, vm := range vms {
+		if vm.Config == nil {
+			continue
+		}
+		for _, dev := range vm.Config.Hardware.Device {
+			uuid := diskUUID(dev)
+			if uuid == "" {
+				continue
+			}
+			atts[uuid] = append(atts[uuid], &vmdkAttachment{
+				vmUUID: vm.Config.Uuid,
+				vmName: vm.Name,
+			})
+		}
+	}
+	return atts, nil
+}
+
+// findVM returns the VM with the given BIOS UUID.
+func (d *driver) findVM(
+	ctx types.Context,
+	s *session,
+	uuid string) (*object.VirtualMachine, error) {
+
+	si := object.NewSearchIndex(s.client.Client)
+	for _, id := range []string{uuid, vsUtils.SwapUUIDByteOrder(uuid)} {
+		ref, err := si.FindByUuid(ctx, s.dc, id, true, nil)
+		if err != nil {
+			return nil, goof.WithFieldE(
+				"uuid", id, "error finding vm", err)
+		}
+		if ref != nil {
+			return object.NewVirtualMachine(
+				s.client.Client, ref.Reference()), nil
+		}
+	}
+	return nil, goof.WithField("uuid", uuid, "vm not found")
+}
+
+// sameVM returns whether or not the BIOS UUIDs identify the same VM, which
+// they do if they are equal in either byte order.
+func sameVM(a, b string) bool {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	return a == b || a == vsUtils.SwapUUIDByteOrder(b)
+}
+
+// findVMDisk returns the device of the VMDK with the given UUID that is
+// attached to the VM, or nil if the VMDK is not attached to the VM.
+func findVMDisk(
+	ctx types.Context,
+	vm *object.VirtualMachine,
+	uuid string) (*vimTypes.VirtualDisk, object.VirtualDeviceList, error) {
+
+	devices, err := vm.Device(ctx)
+	if err != nil {
+		return nil, nil, goof.WithError("error getting vm devices", err)
+	}
+	for _, dev := range devices.SelectByType((*vimTypes.VirtualDisk)(nil)) {
+		if diskUUID(dev) == uuid {
+			return dev.(*vimTypes.VirtualDisk), devices, nil
+		}
+	}
+	return nil, devices, nil
+}
+
+// policyID returns the ID of the storage policy with the given name.
+func (d *driver) policyID(
+	ctx types.Context, s *session, name string) (string, error) {
+
+	pc, err := pbm.NewClient(ctx, s.client.Client)
+	if err != nil {
+		return "", goof.WithError("error creating pbm client", err)
+	}
+
+	ids, err := pc.QueryProfile(
+		ctx,
+		pbmTypes.PbmProfileResourceType{
+			ResourceType: string(
+				pbmTypes.PbmProfileResourceTypeEnumSTORAGE),
+		},
+		string(pbmTypes.PbmProfileCategoryEnumREQUIREMENT))
+	if err != nil {
+		return "", goof.WithError("error listing storage policies", err)
+	}
+	profiles, err := pc.RetrieveContent(ctx, ids)
+	if err != nil {
+		return "", goof.WithError("error getting storage policies", err)
+	}
+	for _, p := range profiles {
+		if profile := p.GetPbmProfile(); profile.Name == name {
+			return profile.ProfileId.UniqueId, nil
+		}
+	}
+	return "", utils.NewInvalidRequestError(
+		"storagePolicy", name, "storage policy not found")
+}
+
+// taskContext returns a context that is canceled when the task timeout
+// elapses.
+func (d *driver) taskContext(
+	ctx types.Context) (context.Context, context.CancelFunc) {
+
+	timeout, err := time.ParseDuration(d.taskTimeout())
+	if err != nil {
+		timeout, _ = time.ParseDuration(vsphere.DefaultTaskTimeout)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// diskUUID returns the normalized UUID of the device if it is a VMDK.
+func diskUUID(dev vimTypes.BaseVirtualDevice) string {
+	disk, ok := dev.(*vimTypes.VirtualDisk)
+	if !ok {
+		return ""
+	}
+	backing, ok := disk.Backing.(*vimTypes.VirtualDiskFlatVer2BackingInfo)
+	if !ok {
+		return ""
+	}
+	return normalizeUUID(backing.Uuid)
+}
+
+// normalizeUUID returns the UUID of a VMDK as lower-case hex digits, which
+// is how the UUID appears in the Linux device ID of the attached VMDK. The
+// UUIDs returned by the SDK are formatted as "6000C29a-2b3c-..." or
+// "60 00 C2 9a 2b 3c ...".
+func normalizeUUID(uuid string) string {
+	return strings.ToLower(strings.NewReplacer(
+		" ", "", "-", "").Replace(uuid))
+}
+
+func isFileNotFound(err error) bool {
+	if terr, ok := err.(task.Error); ok {
+		_, ok := terr.Fault().(*vimTypes.FileNotFound)
+		return ok
+	}
+	if soap.IsSoapFault(err) {
+		_, ok := soap.ToSoapFault(err).VimFault().(vimTypes.FileNotFound)
+		return ok
+	}
+	return false
+}
+
+func isFileAlreadyExists(err error) bool {
+	if terr, ok := err.(task.Error); ok {
+		_, ok := terr.Fault().(*vimTypes.FileAlreadyExists)
+		return ok
+	}
+	if soap.IsSoapFault(err) {
+		f := soap.ToSoapFault(err).VimFault()
+		_, ok := f.(vimTypes.FileAlreadyExists)
+		return ok
+	}
+	return false
+}
@@ -0,0 +1,609 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_vsphere
+
+package storage
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	vimTypes "github.com/vmware/govmomi/vim25/types"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+
+	"github.com/codedellemc/libstorage/drivers/storage/vsphere"
+)
+
+const (
+	defaultVolumeSize = 16
+
+	kbPerGB = 1024 * 1024
+)
+
+type driver struct {
+	sync.Mutex
+	config gofig.Config
+	client *govmomi.Client
+}
+
+func init() {
+	registry.RegisterStorageDriver(vsphere.Name, newDriver)
+}
+
+func newDriver() types.StorageDriver {
+	return &driver{}
+}
+
+func (d *driver) Name() string {
+	return vsphere.Name
+}
+
+func (d *driver) Init(ctx types.Context, config gofig.Config) error {
+	d.config = config
+
+	if d.url() == "" {
+		return goof.New("vsphere.url is required")
+	}
+	if d.username() == "" {
+		return goof.New("vsphere.username is required")
+	}
+	if _, err := time.ParseDuration(d.taskTimeout()); err != nil {
+		return goof.WithFieldE(
+			"taskTimeout", d.taskTimeout(), "invalid task timeout", err)
+	}
+
+	ctx.WithFields(log.Fields{
+		"url":           d.url(),
+		"username":      d.username(),
+		"password":      "******",
+		"insecure":      d.insecure(),
+		"datacenter":    d.datacenter(),
+		"datastore":     d.datastore(),
+		"folder":        d.folder(),
+		"storagePolicy": d.storagePolicy(nil),
+	}).Info("storage driver initialized")
+
+	return nil
+}
+
+func (d *driver) Type(ctx types.Context) (types.StorageType, error) {
+	return types.Block, nil
+}
+
+func (d *driver) Capabilities(
+	ctx types.Context) (*types.StorageCapabilities, error) {
+
+	return &types.StorageCapabilities{
+		MaxVolumeSize: vsphere.MaxVolumeSize,
+		Provisioning: []string{
+			types.ProvisioningThin, types.ProvisioningThick},
+	}, nil
+}
+
+func (d *driver) NextDeviceInfo(
+	ctx types.Context) (*types.NextDeviceInfo, error) {
+	return nil, nil
+}
+
+// InstanceInspect resolves the name of the VM identified by the instance
+// ID's BIOS UUID.
+func (d *driver) InstanceInspect(
+	ctx types.Context, opts types.Store) (*types.Instance, error) {
+
+	iid := context.MustInstanceID(ctx)
+	instance := &types.Instance{
+		InstanceID:   iid,
+		Name:         iid.Fields[vsphere.InstanceIDFieldName],
+		ProviderName: iid.Driver,
+	}
+
+	s, err := d.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	instance.Region = s.dc.Name()
+
+	if instance.Name == "" {
+		vm, err := d.findVM(ctx, s, iid.ID)
+		if err != nil {
+			return nil, err
+		}
+		name, err := vm.ObjectName(ctx)
+		if err != nil {
+			return nil, goof.WithFieldE(
+				"uuid", iid.ID, "error getting vm name", err)
+		}
+		instance.Name = name
+	}
+
+	return instance, nil
+}
+
+func (d *driver) Volumes(
+	ctx types.Context, opts *types.VolumesOpts) ([]*types.Volume, error) {
+
+	s, err := d.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	disks, err := d.searchDisks(ctx, s, "*.vmdk")
+	if err != nil {
+		return nil, goof.WithError("error listing volumes", err)
+	}
+
+	var atts map[string][]*vmdkAttachment
+	if opts.Attachments.Requested() {
+		if atts, err = d.attachments(ctx, s); err != nil {
+			return nil, err
+		}
+	}
+
+	var volumes []*types.Volume
+	for _, disk := range disks {
+		v, err := d.toTypesVolume(
+			ctx, s, disk, atts[disk.uuid], opts.Attachments)
+		if err != nil {
+			return nil, err
+		}
+		volumes = append(volumes, v)
+	}
+
+	return volumes, nil
+}
+
+func (d *driver) VolumeInspect(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeInspectOpts) (*types.Volume, error) {
+
+	s, err := d.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.volumeInspect(ctx, s, volumeID, opts.Attachments)
+}
+
+// VolumeCreate creates a VMDK in the driver's datastore folder. The VMDK is
+// created with the requested storage policy, ex. a vSAN policy, if any.
+func (d *driver) VolumeCreate(
+	ctx types.Context,
+	name string,
+	opts *types.VolumeCreateOpts) (*types.Volume, error) {
+
+	size := int64(defaultVolumeSize)
+	if opts.Size != nil && *opts.Size != 0 {
+		size = *opts.Size
+	}
+	if size > vsphere.MaxVolumeSize {
+		return nil, utils.NewInvalidRequestError(
+			"size", size, "size exceeds the maximum volume size")
+	}
+
+	s, err := d.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := d.getDisk(ctx, s, name); err == nil {
+		return nil, goof.WithField(
+			"volumeName", name, "volume already exists")
+	} else if _, ok := err.(*types.ErrNotFound); !ok {
+		return nil, err
+	}
+
+	diskType := vimTypes.VirtualDiskTypeThin
+	if !d.thin(opts.Thin) {
+		diskType = vimTypes.VirtualDiskTypeThick
+	}
+
+	spec := &vimTypes.FileBackedVirtualDiskSpec{
+		VirtualDiskSpec: vimTypes.VirtualDiskSpec{
+			DiskType:    string(diskType),
+			AdapterType: string(vimTypes.VirtualDiskAdapterTypeLsiLogic),
+		},
+		CapacityKb: size * kbPerGB,
+	}
+
+	policy := d.storagePolicy(opts.Opts)
+	if policy != "" {
+		id, err := d.policyID(ctx, s, policy)
+		if err != nil {
+			return nil, err
+		}
+		spec.Profile = []vimTypes.BaseVirtualMachineProfileSpec{
+			&vimTypes.VirtualMachineDefinedProfileSpec{ProfileId: id},
+		}
+	}
+
+	diskPath := d.diskPath(s, name)
+	fields := log.Fields{
+		"volumeName":    name,
+		"size":          size,
+		"diskType":      diskType,
+		"storagePolicy": policy,
+		"path":          diskPath,
+	}
+	ctx.WithFields(fields).Debug("creating volume")
+
+	if err := d.ensureFolder(ctx, s); err != nil {
+		return nil, err
+	}
+
+	tctx, cancel := d.taskContext(ctx)
+	defer cancel()
+
+	vdm := object.NewVirtualDiskManager(s.client.Client)
+	task, err := vdm.CreateVirtualDisk(tctx, diskPath, s.dc, spec)
+	if err != nil {
+		return nil, goof.WithFieldsE(fields, "error creating volume", err)
+	}
+	if err := task.Wait(tctx); err != nil {
+		return nil, goof.WithFieldsE(fields, "error creating volume", err)
+	}
+
+	return d.volumeInspect(ctx, s, name, types.VolAttReqTrue)
+}
+
+func (d *driver) VolumeCreateFromSnapshot(
+	ctx types.Context,
+	snapshotID, volumeName string,
+	opts *types.VolumeCreateOpts) (*types.Volume, error) {
+	return nil, types.ErrNotImplemented
+}
+
+func (d *driver) VolumeCopy(
+	ctx types.Context,
+	volumeID, volumeName string,
+	opts types.Store) (*types.Volume, error) {
+	return nil, types.ErrNotImplemented
+}
+
+func (d *driver) VolumeSnapshot(
+	ctx types.Context,
+	volumeID, snapshotName string,
+	opts types.Store) (*types.Snapshot, error) {
+	return nil, types.ErrNotImplemented
+}
+
+func (d *driver) VolumeRemove(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeRemoveOpts) error {
+
+	s, err := d.session(ctx)
+	if err != nil {
+		return err
+	}
+	disk, err := d.getDisk(ctx, s, volumeID)
+	if err != nil {
+		return err
+	}
+
+	atts, err := d.attachments(ctx, s)
+	if err != nil {
+		return err
+	}
+	if len(atts[disk.uuid]) > 0 {
+		if !opts.Force {
+			return utils.NewVolumeInUseError(volumeID)
+		}
+		for _, att := range atts[disk.uuid] {
+			if err := d.detach(ctx, s, att.vmUUID, disk); err != nil {
+				return err
+			}
+		}
+	}
+
+	tctx, cancel := d.taskContext(ctx)
+	defer cancel()
+
+	vdm := object.NewVirtualDiskManager(s.client.Client)
+	task, err := vdm.DeleteVirtualDisk(tctx, disk.path, s.dc)
+	if err == nil {
+		err = task.Wait(tctx)
+	}
+	if err != nil {
+		return goof.WithFieldE(
+			"volumeID", volumeID, "error removing volume", err)
+	}
+	return nil
+}
+
+// VolumeAttach hot-adds the VMDK to the VM's first SCSI controller. The
+// VMDK is attached as an independent disk so that it is not included in
+// the VM's snapshots.
+func (d *driver) VolumeAttach(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeAttachOpts) (*types.Volume, string, error) {
+
+	s, err := d.session(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	disk, err := d.getDisk(ctx, s, volumeID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	vmUUID := context.MustInstanceID(ctx).ID
+	vm, err := d.findVM(ctx, s, vmUUID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	atts, err := d.attachments(ctx, s)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, att := range atts[disk.uuid] {
+		if sameVM(att.vmUUID, vmUUID) {
+			return nil, "", goof.New("volume already attached to instance")
+		}
+		if !opts.Force {
+			return nil, "", goof.New("volume already attached")
+		}
+		if err := d.detach(ctx, s, att.vmUUID, disk); err != nil {
+			return nil, "", err
+		}
+	}
+
+	_, devices, err := findVMDisk(ctx, vm, disk.uuid)
+	if err != nil {
+		return nil, "", err
+	}
+	controller, err := devices.FindSCSIController("")
+	if err != nil {
+		return nil, "", goof.WithFieldE(
+			"uuid", vmUUID, "error finding scsi controller", err)
+	}
+
+	vd := devices.CreateDisk(controller, s.ds.Reference(), disk.path)
+	if b, ok := vd.Backing.(*vimTypes.VirtualDiskFlatVer2BackingInfo); ok {
+		b.DiskMode = string(
+			vimTypes.VirtualDiskModeIndependent_persistent)
+	}
+
+	fields := log.Fields{
+		"volumeID": volumeID,
+		"vmUUID":   vmUUID,
+		"path":     disk.path,
+	}
+	ctx.WithFields(fields).Debug("attaching volume")
+
+	tctx, cancel := d.taskContext(ctx)
+	defer cancel()
+
+	if err := vm.AddDevice(tctx, vd); err != nil {
+		return nil, "", goof.WithFieldsE(fields, "error attaching volume", err)
+	}
+
+	attachedVol, err := d.volumeInspect(
+		ctx, s, volumeID, types.VolAttReqTrue)
+	if err != nil {
+		return nil, "", goof.WithError("error getting volume", err)
+	}
+
+	// the executor maps the UUIDs of the attached VMDKs to their devices
+	return attachedVol, disk.uuid, nil
+}
+
+func (d *driver) VolumeDetach(
+	ctx types.Context,
+	volumeID string,
+	opts *types.VolumeDetachOpts) (*types.Volume, error) {
+
+	s, err := d.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	disk, err := d.getDisk(ctx, s, volumeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.detach(
+		ctx, s, context.MustInstanceID(ctx).ID, disk); err != nil {
+		return nil, err
+	}
+
+	return d.volumeInspect(ctx, s, volumeID, types.VolAttReqTrue)
+}
+
+func (d *driver) Snapshots(
+	ctx types.Context, opts types.Store) ([]*types.Snapshot, error) {
+	return nil, types.ErrNotImplemented
+}
+
+func (d *driver) SnapshotInspect(
+	ctx types.Context,
+	snapshotID string,
+	opts types.Store) (*types.Snapshot, error) {
+	return nil, types.ErrNotImplemented
+}
+
+func (d *driver) SnapshotCopy(
+	ctx types.Context,
+	snapshotID, snapshotName, destinationID string,
+	opts types.Store) (*types.Snapshot, error) {
+	return nil, types.ErrNotImplemented
+}
+
+func (d *driver) SnapshotRemove(
+	ctx types.Context, snapshotID string, opts types.Store) error {
+	return types.ErrNotImplemented
+}
+
+func (d *driver) volumeInspect(
+	ctx types.Context,
+	s *session,
+	volumeID string,
+	attachments types.VolumeAttachmentsTypes) (*types.Volume, error) {
+
+	disk, err := d.getDisk(ctx, s, volumeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var atts map[string][]*vmdkAttachment
+	if attachments.Requested() {
+		if atts, err = d.attachments(ctx, s); err != nil {
+			return nil, err
+		}
+	}
+
+	return d.toTypesVolume(ctx, s, disk, atts[disk.uuid], attachments)
+}
+
+// detach removes the VMDK from the VM with the given BIOS UUID without
+// deleting the VMDK's files.
+func (d *driver) detach(
+	ctx types.Context, s *session, vmUUID string, disk *vmdk) error {
+
+	fields := log.Fields{
+		"volumeID": disk.name,
+		"vmUUID":   vmUUID,
+	}
+
+	vm, err := d.findVM(ctx, s, vmUUID)
+	if err != nil {
+		return err
+	}
+	vd, _, err := findVMDisk(ctx, vm, disk.uuid)
+	if err != nil {
+		return err
+	}
+	if vd == nil {
+		return goof.WithFields(fields, "volume already detached")
+	}
+
+	ctx.WithFields(fields).Debug("detaching volume")
+
+	tctx, cancel := d.taskContext(ctx)
+	defer cancel()
+
+	if err := vm.RemoveDevice(tctx, true, vd); err != nil {
+		return goof.WithFieldsE(fields, "error detaching volume", err)
+	}
+	return nil
+}
+
+func (d *driver) toTypesVolume(
+	ctx types.Context,
+	s *session,
+	disk *vmdk,
+	atts []*vmdkAttachment,
+	attachments types.VolumeAttachmentsTypes) (*types.Volume, error) {
+
+	status := "detached"
+	if len(atts) > 0 {
+		status = "attached"
+	}
+
+	volumeType := types.ProvisioningThick
+	if disk.thin {
+		volumeType = types.ProvisioningThin
+	}
+
+	volume := &types.Volume{
+		Name:             disk.name,
+		ID:               disk.name,
+		Size:             disk.capacityKB / kbPerGB,
+		Type:             volumeType,
+		AvailabilityZone: s.ds.Name(),
+		Status:           status,
+		Fields: map[string]string{
+			vsphere.VolumeFieldDatastorePath: disk.path,
+			vsphere.VolumeFieldUUID:          disk.uuid,
+		},
+	}
+
+	if !attachments.Requested() {
+		return volume, nil
+	}
+
+	for _, att := range atts {
+		va := &types.VolumeAttachment{
+			VolumeID: disk.name,
+			InstanceID: &types.InstanceID{
+				ID:     att.vmUUID,
+				Driver: vsphere.Name,
+				Fields: map[string]string{
+					vsphere.InstanceIDFieldName: att.vmName,
+				},
+			},
+		}
+		if attachments.Devices() {
+			ld, ok := context.LocalDevices(ctx)
+			if !ok {
+				return nil, goof.New(
+					"error getting local devices from context")
+			}
+			if dev, ok := ld.DeviceMap[disk.uuid]; ok {
+				va.DeviceName = dev
+				va.BusType = "scsi"
+			}
+		}
+		volume.Attachments = append(volume.Attachments, va)
+	}
+
+	return volume, nil
+}
+
+// thin returns whether or not a new VMDK is thin-provisioned, as requested
+// or else as configured.
+func (d *driver) thin(thin *bool) bool {
+	if thin != nil {
+		return *thin
+	}
+	return d.config.GetBool(vsphere.ConfigVSphereThin)
+}
+
+func (d *driver) storagePolicy(opts types.Store) string {
+	if opts != nil {
+		if v := opts.GetString(vsphere.VolumeOptStoragePolicy); v != "" {
+			return v
+		}
+	}
+	return d.config.GetString(vsphere.ConfigVSphereStoragePolicy)
+}
+
+func (d *driver) url() string {
+	return d.config.GetString(vsphere.ConfigVSphereURL)
+}
+
+func (d *driver) username() string {
+	return d.config.GetString(vsphere.ConfigVSphereUsername)
+}
+
+func (d *driver) password() string {
+	return d.config.GetString(vsphere.ConfigVSpherePassword)
+}
+
+func (d *driver) insecure() bool {
+	return d.config.GetBool(vsphere.ConfigVSphereInsecure)
+}
+
+func (d *driver) datacenter() string {
+	return d.config.GetString(vsphere.ConfigVSphereDatacenter)
+}
+
+func (d *driver) datastore() string {
+	return d.config.GetString(vsphere.ConfigVSphereDatastore)
+}
+
+func (d *driver) folder() string {
+	return d.config.GetString(vsphere.ConfigVSphereFolder)
+}
+
+func (d *driver) taskTimeout() string {
+	return d.config.GetString(vsphere.ConfigVSphereTaskTimeout)
+}
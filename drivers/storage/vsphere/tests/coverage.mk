@@ -0,0 +1,2 @@
+VSPHERE_COVERPKG := $(ROOT_IMPORT_PATH)/drivers/storage/vsphere
+TEST_COVERPKG_./drivers/storage/vsphere/tests := $(VSPHERE_COVERPKG),$(VSPHERE_COVERPKG)/executor
@@ -0,0 +1,221 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_vsphere
+
+package vsphere
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	log "github.com/Sirupsen/logrus"
+	gofigCore "github.com/akutz/gofig"
+	gofig "github.com/akutz/gofig/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/server"
+	apitests "github.com/codedellemc/libstorage/api/tests"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+	"github.com/codedellemc/libstorage/drivers/storage/vsphere"
+	vsUtils "github.com/codedellemc/libstorage/drivers/storage/vsphere/utils"
+)
+
+var (
+	configYAML = []byte(`
+vsphere:
+  url: https://vcenter/sdk
+  username: administrator@vsphere.local
+  password: password
+  insecure: true
+  datastore: datastore1`)
+)
+
+func skipTests() bool {
+	travis, _ := strconv.ParseBool(os.Getenv("TRAVIS"))
+	noTest, _ := strconv.ParseBool(os.Getenv("TEST_SKIP_VSPHERE"))
+	return travis || noTest
+}
+
+var volumeName string
+
+func init() {
+	uuid, _ := types.NewUUID()
+	volumeName = "ls-" + strings.Split(uuid.String(), "-")[0]
+}
+
+func TestMain(m *testing.M) {
+	server.CloseOnAbort()
+	ec := m.Run()
+	os.Exit(ec)
+}
+
+func TestConfig(t *testing.T) {
+	if skipTests() {
+		t.SkipNow()
+	}
+
+	tf := func(config gofig.Config, client types.Client, t *testing.T) {
+		assert.NotEqual(t, config.GetString(vsphere.ConfigVSphereURL), "")
+		assert.Equal(t, vsphere.DefaultFolder,
+			config.GetString(vsphere.ConfigVSphereFolder))
+		assert.True(t, config.GetBool(vsphere.ConfigVSphereThin))
+	}
+
+	apitests.Run(t, vsphere.Name, configYAML, tf)
+}
+
+func TestInstanceID(t *testing.T) {
+	if skipTests() {
+		t.SkipNow()
+	}
+
+	sd, err := registry.NewStorageDriver(vsphere.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	config := gofigCore.New()
+	if err := config.ReadConfig(bytes.NewReader(configYAML)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sd.Init(ctx, config); err != nil {
+		t.Fatal(err)
+	}
+
+	iid, err := vsUtils.InstanceID(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx = ctx.WithValue(context.InstanceIDKey, iid)
+	i, err := sd.InstanceInspect(ctx, utils.NewStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iid = i.InstanceID
+	apitests.Run(
+		t, vsphere.Name, nil,
+		(&apitests.InstanceIDTest{
+			Driver:   vsphere.Name,
+			Expected: iid,
+		}).Test)
+}
+
+func TestSwapUUIDByteOrder(t *testing.T) {
+	assert.Equal(t,
+		"4229d2a1-1c0b-3e2d-8f90-a1b2c3d4e5f6",
+		vsUtils.SwapUUIDByteOrder("a1d22942-0b1c-2d3e-8f90-a1b2c3d4e5f6"))
+	assert.Equal(t, "invalid", vsUtils.SwapUUIDByteOrder("invalid"))
+}
+
+func TestServices(t *testing.T) {
+	if skipTests() {
+		t.SkipNow()
+	}
+
+	tf := func(config gofig.Config, client types.Client, t *testing.T) {
+		reply, err := client.API().Services(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, len(reply), 1)
+
+		_, ok := reply[vsphere.Name]
+		assert.True(t, ok)
+	}
+
+	apitests.Run(t, vsphere.Name, configYAML, tf)
+}
+
+func TestVolumeCreateRemove(t *testing.T) {
+	if skipTests() {
+		t.SkipNow()
+	}
+
+	tf := func(config gofig.Config, client types.Client, t *testing.T) {
+		vol := volumeCreate(t, client, volumeName)
+		volumeRemove(t, client, vol.ID)
+	}
+
+	apitests.Run(t, vsphere.Name, configYAML, tf)
+}
+
+func TestVolumeAttach(t *testing.T) {
+	if skipTests() {
+		t.SkipNow()
+	}
+
+	tf := func(config gofig.Config, client types.Client, t *testing.T) {
+		vol := volumeCreate(t, client, volumeName)
+		_ = volumeAttach(t, client, vol.ID)
+		_ = volumeDetach(t, client, vol.ID)
+		volumeRemove(t, client, vol.ID)
+	}
+
+	apitests.Run(t, vsphere.Name, configYAML, tf)
+}
+
+func volumeCreate(
+	t *testing.T, client types.Client, volumeName string) *types.Volume {
+	log.WithField("volumeName", volumeName).Info("creating volume")
+
+	size := int64(1)
+	reply, err := client.API().VolumeCreate(
+		nil, vsphere.Name, &types.VolumeCreateRequest{
+			Name: volumeName,
+			Size: &size,
+		})
+	assert.NoError(t, err)
+	if err != nil {
+		t.FailNow()
+	}
+	apitests.LogAsJSON(reply, t)
+
+	assert.Equal(t, volumeName, reply.Name)
+	assert.Equal(t, size, reply.Size)
+	return reply
+}
+
+func volumeRemove(t *testing.T, client types.Client, volumeID string) {
+	log.WithField("volumeID", volumeID).Info("removing volume")
+	err := client.API().VolumeRemove(nil, vsphere.Name, volumeID, false)
+	assert.NoError(t, err)
+	if err != nil {
+		t.FailNow()
+	}
+}
+
+func volumeAttach(
+	t *testing.T, client types.Client, volumeID string) *types.Volume {
+	log.WithField("volumeID", volumeID).Info("attaching volume")
+
+	reply, token, err := client.API().VolumeAttach(
+		nil, vsphere.Name, volumeID, &types.VolumeAttachRequest{})
+	assert.NoError(t, err)
+	if err != nil {
+		t.FailNow()
+	}
+	apitests.LogAsJSON(reply, t)
+	assert.Equal(t, reply.Fields[vsphere.VolumeFieldUUID], token)
+	assert.Len(t, reply.Attachments, 1)
+	return reply
+}
+
+func volumeDetach(
+	t *testing.T, client types.Client, volumeID string) *types.Volume {
+	log.WithField("volumeID", volumeID).Info("detaching volume")
+
+	reply, err := client.API().VolumeDetach(
+		nil, vsphere.Name, volumeID, &types.VolumeDetachRequest{})
+	assert.NoError(t, err)
+	if err != nil {
+		t.FailNow()
+	}
+	apitests.LogAsJSON(reply, t)
+	assert.Len(t, reply.Attachments, 0)
+	return reply
+}
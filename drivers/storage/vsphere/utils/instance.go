@@ -0,0 +1,68 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_vsphere
+
+package utils
+
+import (
+	"io/ioutil"
+	"strings"
+
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/drivers/storage/vsphere"
+)
+
+const (
+	dmiPath        = "/sys/class/dmi/id"
+	dmiProductUUID = dmiPath + "/product_uuid"
+	dmiSysVendor   = dmiPath + "/sys_vendor"
+
+	vmwareVendor = "VMware, Inc."
+)
+
+// InstanceID returns the BIOS UUID of the VM, which identifies the VM to
+// vSphere. The VM's name is resolved by the storage driver.
+func InstanceID(ctx types.Context) (*types.InstanceID, error) {
+	buf, err := ioutil.ReadFile(dmiProductUUID)
+	if err != nil {
+		return nil, goof.WithError("error reading bios uuid", err)
+	}
+	id := strings.ToLower(strings.TrimSpace(string(buf)))
+	if id == "" {
+		return nil, goof.New("bios uuid is empty")
+	}
+	return &types.InstanceID{
+		ID:     id,
+		Driver: vsphere.Name,
+	}, nil
+}
+
+// IsVM is a simple check to see if code is being executed on a VMware VM or
+// not.
+func IsVM(ctx types.Context) (bool, error) {
+	buf, err := ioutil.ReadFile(dmiSysVendor)
+	if err != nil {
+		return false, nil
+	}
+	return strings.TrimSpace(string(buf)) == vmwareVendor, nil
+}
+
+// SwapUUIDByteOrder returns the UUID with the byte order of its first three
+// fields reversed. Older kernels report the BIOS UUIDs of VMs with a
+// hardware version prior to 13 in this order, ex.
+// "a1d22942-0b1c-2d3e-..." rather than "4229d2a1-1c0b-3e2d-...".
+func SwapUUIDByteOrder(uuid string) string {
+	parts := strings.Split(uuid, "-")
+	if len(parts) != 5 {
+		return uuid
+	}
+	for i := 0; i < 3; i++ {
+		p := parts[i]
+		b := make([]byte, 0, len(p))
+		for j := len(p); j >= 2; j -= 2 {
+			b = append(b, p[j-2:j]...)
+		}
+		parts[i] = string(b)
+	}
+	return strings.Join(parts, "-")
+}
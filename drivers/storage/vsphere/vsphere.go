@@ -0,0 +1,153 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_vsphere
+
+package vsphere
+
+import (
+	gofigCore "github.com/akutz/gofig"
+	gofig "github.com/akutz/gofig/types"
+)
+
+const (
+	// Name is the name of the driver
+	Name = "vsphere"
+
+	// InstanceIDFieldName is the key used to retrieve the VM's name from the
+	// instance id map
+	InstanceIDFieldName = "name"
+
+	// DiskIDPrefix is the prefix of the Linux device IDs of the VM's virtual
+	// disks, which are followed by the disk's UUID, for example:
+	//
+	//     /dev/disk/by-id/wwn-0x6000c29a2b3c4d5e6f708192a3b4c5d6
+	//
+	// The IDs are only assigned when the VM's disk.EnableUUID option is set.
+	DiskIDPrefix = "wwn-0x"
+
+	// DefaultFolder is the default value of ConfigVSphereFolder
+	DefaultFolder = "libstorage"
+
+	// DefaultTaskTimeout is the default value of ConfigVSphereTaskTimeout
+	DefaultTaskTimeout = "5m"
+
+	// MaxVolumeSize is the maximum size of a VMDK in GiB
+	MaxVolumeSize = 63488
+
+	// VolumeOptStoragePolicy is the volume create option that specifies the
+	// name of the storage policy, ex. a vSAN policy, applied to the VMDK
+	VolumeOptStoragePolicy = "storagePolicy"
+
+	// VolumeFieldDatastorePath is the key of the volume field that contains
+	// the datastore path of the VMDK
+	VolumeFieldDatastorePath = "datastorePath"
+
+	// VolumeFieldUUID is the key of the volume field that contains the UUID
+	// of the VMDK
+	VolumeFieldUUID = "uuid"
+
+	// ConfigVSphereURL is the key for the URL of the vCenter or ESXi SDK in
+	// the config file
+	ConfigVSphereURL = Name + ".url"
+
+	// ConfigVSphereUsername is the key for the username in the config file
+	ConfigVSphereUsername = Name + ".username"
+
+	// ConfigVSpherePassword is the key for the password in the config file
+	ConfigVSpherePassword = Name + ".password"
+
+	// ConfigVSphereInsecure is the key for whether or not the SDK's
+	// certificate is verified in the config file
+	ConfigVSphereInsecure = Name + ".insecure"
+
+	// ConfigVSphereDatacenter is the key for the datacenter in the config
+	// file
+	ConfigVSphereDatacenter = Name + ".datacenter"
+
+	// ConfigVSphereDatastore is the key for the datastore on which VMDKs are
+	// created in the config file
+	ConfigVSphereDatastore = Name + ".datastore"
+
+	// ConfigVSphereFolder is the key for the datastore folder in which VMDKs
+	// are created in the config file
+	ConfigVSphereFolder = Name + ".folder"
+
+	// ConfigVSphereStoragePolicy is the key for the default storage policy
+	// applied to new VMDKs in the config file
+	ConfigVSphereStoragePolicy = Name + ".storagePolicy"
+
+	// ConfigVSphereThin is the key for whether or not new VMDKs are
+	// thin-provisioned by default in the config file
+	ConfigVSphereThin = Name + ".thin"
+
+	// ConfigVSphereTaskTimeout is the key for how long the driver waits for
+	// a task, such as attaching a VMDK, to complete in the config file
+	ConfigVSphereTaskTimeout = Name + ".taskTimeout"
+)
+
+func init() {
+	registerConfig()
+}
+
+func registerConfig() {
+	r := gofigCore.NewRegistration("vSphere")
+	r.Key(
+		gofig.String,
+		"",
+		"",
+		"The URL of the vCenter or ESXi SDK, ex. https://vcenter/sdk",
+		ConfigVSphereURL)
+	r.Key(
+		gofig.String,
+		"",
+		"",
+		"The vSphere username",
+		ConfigVSphereUsername)
+	r.Key(
+		gofig.String,
+		"",
+		"",
+		"The vSphere password",
+		ConfigVSpherePassword)
+	r.Key(
+		gofig.Bool,
+		"",
+		false,
+		"A flag that indicates whether or not to skip certificate checks",
+		ConfigVSphereInsecure)
+	r.Key(
+		gofig.String,
+		"",
+		"",
+		"The datacenter, defaults to the only datacenter",
+		ConfigVSphereDatacenter)
+	r.Key(
+		gofig.String,
+		"",
+		"",
+		"The datastore on which VMDKs are created",
+		ConfigVSphereDatastore)
+	r.Key(
+		gofig.String,
+		"",
+		DefaultFolder,
+		"The datastore folder in which VMDKs are created",
+		ConfigVSphereFolder)
+	r.Key(
+		gofig.String,
+		"",
+		"",
+		"The name of the storage policy applied to new VMDKs",
+		ConfigVSphereStoragePolicy)
+	r.Key(
+		gofig.Bool,
+		"",
+		true,
+		"A flag that indicates whether or not VMDKs are thin-provisioned",
+		ConfigVSphereThin)
+	r.Key(
+		gofig.String,
+		"",
+		DefaultTaskTimeout,
+		"How long to wait for a task to complete",
+		ConfigVSphereTaskTimeout)
+	gofigCore.Register(r)
+}
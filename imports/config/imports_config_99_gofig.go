@@ -5,16 +5,206 @@ package config
 import (
 	"os"
 	"runtime"
+	"strings"
 
 	log "github.com/Sirupsen/logrus"
 	gofigCore "github.com/akutz/gofig"
 	gofig "github.com/akutz/gofig/types"
+	"github.com/codedellemc/libstorage/api/registry"
 	"github.com/codedellemc/libstorage/api/types"
 )
 
 const (
 	logStdoutDesc = "The file to which to log os.Stdout"
 	logStderrDesc = "The file to which to log os.Stderr"
+	logFormatDesc = "The log format, either text or json"
+	logLevelsDesc = "The log levels for components such as server, " +
+		"client, driver.rbd, and os.mount, as component=level pairs"
+
+	localSocketDesc = "The UNIX socket on which a server also listens and " +
+		"which a co-located client uses in place of a loopback TCP address"
+	localSocketModeDesc = "The octal file mode of the local UNIX socket, " +
+		"which limits the users that may connect to it"
+
+	logSlowOpThresholdDesc = "The duration above which storage and OS " +
+		"driver operations are logged as warnings. Zero disables the logging"
+
+	tracingExporterDesc = "The exporter to which trace spans are sent. " +
+		"Valid values are jaeger or empty to disable tracing"
+
+	secretsRefreshDesc = "How often secrets referenced from the config, ex. " +
+		"secret://vault/secret/aws#secretKey, are checked for rotation"
+
+	tasksConcurrencyDesc = "The number of a service's tasks that are " +
+		"executed at once"
+
+	tasksQueueSizeDesc = "The number of a service's tasks that may wait " +
+		"to be executed before more tasks are rejected"
+
+	shutdownTimeoutDesc = "How long the server waits for in-flight tasks " +
+		"to complete when it is shut down"
+
+	circuitBreakerThresholdDesc = "The number of consecutive driver " +
+		"failures after which a service fails requests fast. Zero disables " +
+		"the circuit breaker"
+
+	maxRequestBodySizeDesc = "The maximum size, in bytes, of a request's " +
+		"body. Zero disables the limit"
+
+	compressionMinSizeDesc = "The minimum size, in bytes, of a response " +
+		"that is compressed for clients that accept gzip encoding"
+
+	serverAdminTokenDesc = "The server's admin token. A random token is " +
+		"generated at startup if none is configured"
+
+	bindInstanceIDsDesc = "A flag indicating whether or not instance IDs " +
+		"are bound to the client certificate that first claims them"
+
+	executorEndpointDesc = "The address of an executor daemon, ex. " +
+		"unix:///var/run/libstorage/lsx.sock, that is called in place of " +
+		"invoking the executor binary for each operation"
+
+	executorVerifyKeyDesc = "The Ed25519 public key, base64 or PEM encoded, " +
+		"with which a downloaded executor's signature is verified"
+
+	executorVerifyRequiredDesc = "A flag indicating whether or not a " +
+		"downloaded executor is rejected if no verify key is configured"
+
+	describeDevicesDesc = "A flag indicating whether or not the executor " +
+		"reports the size, serial number, file system, and mount point of " +
+		"the local devices"
+
+	freezeTimeoutDesc = "How long a file system frozen for a snapshot may " +
+		"remain frozen before the executor thaws it"
+
+	iidResolversDesc = "The resolvers, in order, with which executors " +
+		"that support them derive the instance ID: static, machineID, " +
+		"dmiUUID, or command"
+
+	iidStaticDesc = "The instance ID returned by the static resolver"
+
+	iidCommandDesc = "The command whose output is the instance ID " +
+		"returned by the command resolver"
+
+	execSearchPathDesc = "The directories, separated by colons, in which " +
+		"the executables run by drivers are found, or empty to use the " +
+		"PATH of the process"
+
+	execEnvDesc = "The environment variables, ex. CEPH_ARGS=--id admin, " +
+		"added to the environment of the executables run by drivers"
+
+	execAllowDesc = "The names of the only executables that drivers may " +
+		"run, or empty to allow any executable"
+
+	execChrootDesc = "The directory to which the root directory of the " +
+		"executables run by drivers is changed, or empty to not change it"
+
+	execNamespacesDesc = "The Linux namespaces, ex. ipc, net, or uts, in " +
+		"new instances of which the executables run by drivers are run"
+
+	topologyValidateDesc = "A flag indicating whether or not the server " +
+		"rejects attaching a volume to an instance in another availability " +
+		"zone or rack, which requires inspecting the volume before each attach"
+
+	stableNamesDesc = "A flag indicating whether or not the persistent " +
+		"/dev/disk/by-id or /dev/disk/by-path symlinks of attached devices " +
+		"are returned in place of their names, which may change across reboots"
+
+	multipathDesc = "A flag indicating whether or not the executor returns " +
+		"the dm-multipath device for an attached device that is one of its paths"
+
+	multipathTimeoutDesc = "How long the executor waits for the paths of " +
+		"an attached device to be coalesced into a dm-multipath device"
+
+	nextDeviceLeaseDesc = "How long a device name chosen for a volume " +
+		"being attached to an instance is reserved for the volume"
+
+	volumeRecycleRetentionDesc = "How long a removed volume is kept in the " +
+		"recycle bin before it is purged, or 0 to remove volumes immediately"
+
+	clientCacheInstanceDesc = "How long the client caches the instance " +
+		"returned by the server for each service, or 0 to disable the cache"
+
+	clientAdminTokenDesc = "The server's admin token, sent with requests " +
+		"that only administrators may make, such as force detach"
+
+	forceDetachCleanupDesc = "A flag indicating whether or not the client " +
+		"removes the mounts and mappings of volumes that are forcefully " +
+		"detached from its instance"
+
+	serverCacheInstanceDesc = "How long the server caches the instance " +
+		"returned by a storage driver for an instance ID, or 0 to disable " +
+		"the cache"
+
+	fsckPolicyDesc = "When a volume's file system is checked before it is " +
+		"mounted: never, auto if it was not cleanly unmounted, or always"
+
+	fsckActionDesc = "What to do when a volume's file system has errors: " +
+		"repair, warn, or fail the mount"
+
+	reconcileIntervalDesc = "How often the integration driver detaches " +
+		"and unmounts the volumes that are no longer in use, or 0 to " +
+		"disable reconciliation"
+
+	reconcileDryRunDesc = "A flag indicating whether or not the orphaned " +
+		"volumes found by reconciliation are only reported. Set it to " +
+		"false to detach and unmount them"
+
+	mountJournalDesc = "The file in which the steps of volume mounts in " +
+		"progress are recorded so that interrupted mounts are rolled " +
+		"back, or empty to disable the journal"
+
+	validateConfigDesc = "A flag indicating whether or not the server " +
+		"fails to start, rather than logging a warning, when its config " +
+		"has unknown keys, values of the wrong types, or missing keys " +
+		"required by storage drivers"
+
+	registeredServicesFileDesc = "The file in which the storage services " +
+		"registered with the admin API are persisted"
+
+	volumeHistoryMaxDesc = "The maximum number of events recorded in the " +
+		"history of each volume, or 0 to disable the histories"
+
+	volumeHistoryVolumesDesc = "The maximum number of volumes whose " +
+		"histories are kept; the histories of the volumes with the oldest " +
+		"events are dropped first"
+
+	policyURLDesc = "The URL of the OPA policy decision, ex. " +
+		"http://localhost:8181/v1/data/libstorage/allow, that admits or " +
+		"denies mutating requests, or empty to admit all requests"
+
+	mountTokenTTLDesc = "How long a client may report whether or not a " +
+		"volume attached to its instance was mounted, or 0 to disable the " +
+		"mount tokens"
+
+	mountTokenFileDesc = "The file in which the outstanding mount tokens " +
+		"are recorded so that they survive a restart of the server, or " +
+		"empty to keep them only in memory"
+
+	tenancyEnabledDesc = "A flag indicating whether or not the volumes " +
+		"visible to and mutable by an authenticated client are scoped to " +
+		"the client's tenant"
+
+	tenancyPatternDesc = "The regular expression whose first group " +
+		"captures the tenant from the client's identity, or empty to use " +
+		"the client's identity as its tenant"
+
+	volumeQuotaDesc = "The maximum total size, in GiB, of a service's " +
+		"volumes, or of the volumes of each tenant if tenancy is enabled, " +
+		"or 0 for no quota"
+
+	volumeManagedOnlyDesc = "A flag indicating whether or not volume " +
+		"listings include only the volumes stamped with libStorage metadata " +
+		"by drivers that stamp the volumes they create"
+
+	volumeNamingPatternDesc = "The regular expression that the names of new " +
+		"volumes must match"
+
+	volumeNamingTemplateDesc = "The template with which the names of new " +
+		"volumes are expanded, ex. {tenant}-{name}"
+
+	policyFailOpenDesc = "A flag indicating whether or not requests are " +
+		"admitted when the policy decision cannot be obtained"
 )
 
 func init() {
@@ -31,6 +221,42 @@ func init() {
 
 	r := gofigCore.NewRegistration("libStorage")
 
+	// the schema describes the keys under the namespaces in which every key
+	// is registered below
+	schema := &types.ConfigSchema{
+		Name: "libStorage",
+		Namespaces: []string{
+			types.ConfigServer,
+			types.ConfigClient,
+			types.ConfigLogging,
+			types.ConfigRoot + ".http",
+			types.ConfigTracing,
+		},
+		Keys: map[string]types.ConfigKeyType{
+			types.ConfigServices:               types.ConfigKeyAny,
+			types.ConfigEndpoints:              types.ConfigKeyAny,
+			types.ConfigServer + ".libstorage": types.ConfigKeyAny,
+			types.ConfigClient + ".libstorage": types.ConfigKeyAny,
+			types.ConfigTLS:                    types.ConfigKeyString,
+			types.ConfigTLSDisabled:            types.ConfigKeyBool,
+			types.ConfigTLSInsecure:            types.ConfigKeyBool,
+			types.ConfigTLSServerName:          types.ConfigKeyString,
+			types.ConfigTLSClientCertRequired:  types.ConfigKeyBool,
+			types.ConfigTLSTrustedCertsFile:    types.ConfigKeyString,
+			types.ConfigTLSCertFile:            types.ConfigKeyString,
+			types.ConfigTLSKeyFile:             types.ConfigKeyString,
+			types.ConfigTLSReloadInterval:      types.ConfigKeyString,
+			types.ConfigTLSSPIFFETrustDomain:   types.ConfigKeyString,
+			types.ConfigTLSSPIFFEIDs:           types.ConfigKeyAny,
+			types.ConfigTLSSPIFFEWorkloadAPI:   types.ConfigKeyString,
+			types.ConfigServerTenancyAdmins:    types.ConfigKeyAny,
+			types.ConfigExecPaths:              types.ConfigKeyAny,
+			types.ConfigRoot + ".driver":       types.ConfigKeyString,
+
+			types.ConfigSchemaResponseValidationEnabled: types.ConfigKeyBool,
+		},
+	}
+
 	rk := func(
 		keyType gofig.ConfigKeyTypes,
 		defaultVal interface{},
@@ -45,6 +271,15 @@ func init() {
 		}
 
 		r.Key(keyType, "", defaultVal, description, args...)
+
+		switch keyType {
+		case gofig.Int:
+			schema.Keys[string(keyVal)] = types.ConfigKeyInt
+		case gofig.Bool:
+			schema.Keys[string(keyVal)] = types.ConfigKeyBool
+		default:
+			schema.Keys[string(keyVal)] = types.ConfigKeyString
+		}
 	}
 
 	defaultAEM := types.UnixEndpoint.String()
@@ -64,24 +299,164 @@ func init() {
 	rk(gofig.String, "", logStderrDesc, types.ConfigLogStdout)
 	rk(gofig.Bool, false, "", types.ConfigLogHTTPRequests)
 	rk(gofig.Bool, false, "", types.ConfigLogHTTPResponses)
+	rk(gofig.String, "text", logFormatDesc, types.ConfigLogFormat)
+	rk(gofig.String, "", logLevelsDesc, types.ConfigLogLevels)
+	rk(gofig.String, "10s", logSlowOpThresholdDesc,
+		types.ConfigLogSlowOpThreshold)
+	rk(gofig.String, "", tracingExporterDesc, types.ConfigTracingExporter)
+	rk(gofig.String, "127.0.0.1:6831", "", types.ConfigTracingJaegerAgent)
+	rk(gofig.String, "const", "", types.ConfigTracingSamplerType)
+	rk(gofig.String, "1", "", types.ConfigTracingSamplerParam)
 	rk(gofig.Bool, false, "", types.ConfigHTTPDisableKeepAlive)
+	rk(gofig.Int, 100, "", types.ConfigHTTPMaxIdleConns)
+	rk(gofig.Int, 10, "", types.ConfigHTTPMaxIdleConnsPerHost)
+	rk(gofig.String, "90s", "", types.ConfigHTTPIdleConnTimeout)
+	rk(gofig.String, "30s", "", types.ConfigHTTPKeepAlive)
+	rk(gofig.String, "", localSocketDesc, types.ConfigHTTPLocalSocket)
+	rk(gofig.String, "0600", localSocketModeDesc,
+		types.ConfigHTTPLocalSocketMode)
 	rk(gofig.Int, 300, "", types.ConfigHTTPWriteTimeout)
 	rk(gofig.Int, 300, "", types.ConfigHTTPReadTimeout)
 	rk(gofig.String, types.LSX.String(), "", types.ConfigExecutorPath)
 	rk(gofig.Bool, false, "", types.ConfigExecutorNoDownload)
+	rk(gofig.String, "", executorEndpointDesc, types.ConfigExecutorEndpoint)
+	rk(gofig.String, "", executorVerifyKeyDesc, types.ConfigExecutorVerifyKey)
+	rk(gofig.Bool, false, executorVerifyRequiredDesc,
+		types.ConfigExecutorVerifyRequired)
+	rk(gofig.Bool, true, describeDevicesDesc,
+		types.ConfigExecutorDescribeDevices)
+	rk(gofig.String, "30s", freezeTimeoutDesc,
+		types.ConfigExecutorFreezeTimeout)
+	rk(gofig.String, "", iidResolversDesc,
+		types.ConfigExecutorInstanceIDResolvers)
+	rk(gofig.String, "", iidStaticDesc, types.ConfigExecutorInstanceIDStatic)
+	rk(gofig.String, "", iidCommandDesc, types.ConfigExecutorInstanceIDCommand)
+	rk(gofig.Bool, false, multipathDesc, types.ConfigExecutorMultipathEnabled)
+	rk(gofig.String, "10s", multipathTimeoutDesc,
+		types.ConfigExecutorMultipathTimeout)
 	rk(gofig.Bool, false, "", types.ConfigIgVolOpsMountPreempt)
+	rk(gofig.Bool, false, "", types.ConfigIgVolOpsMountEncrypt)
+	rk(gofig.Bool, false, "", types.ConfigIgVolOpsMountGrowFS)
+	rk(gofig.String, types.Lib.Join("mount-journal.json"), mountJournalDesc,
+		types.ConfigIgVolOpsMountJournal)
+	rk(gofig.String, "", "", types.ConfigIgVolOpsMountOptions)
+	rk(gofig.String, "", "", types.ConfigIgVolOpsMountSELinuxLabel)
+	rk(gofig.String, "never", fsckPolicyDesc,
+		types.ConfigIgVolOpsMountFsckPolicy)
+	rk(gofig.String, "fail", fsckActionDesc,
+		types.ConfigIgVolOpsMountFsckAction)
+	rk(gofig.String, "", "", types.ConfigIgVolOpsMountEncryptionKey)
 	rk(gofig.Bool, false, "", types.ConfigIgVolOpsCreateDisable)
 	rk(gofig.Bool, false, "", types.ConfigIgVolOpsRemoveDisable)
 	rk(gofig.Bool, false, "", types.ConfigIgVolOpsUnmountIgnoreUsed)
 	rk(gofig.Bool, true, "", types.ConfigIgVolOpsPathCacheEnabled)
 	rk(gofig.Bool, true, "", types.ConfigIgVolOpsPathCacheAsync)
+	rk(gofig.String, "0", reconcileIntervalDesc,
+		types.ConfigIgVolOpsReconcileInterval)
+	rk(gofig.Bool, true, reconcileDryRunDesc,
+		types.ConfigIgVolOpsReconcileDryRun)
 	rk(gofig.String, "30m", "", types.ConfigClientCacheInstanceID)
+	rk(gofig.String, "5m", clientCacheInstanceDesc,
+		types.ConfigClientCacheInstance)
+	rk(gofig.Bool, false, "", types.ConfigClientCacheVolumes)
+	rk(gofig.String, "1m", "", types.ConfigClientCacheVolumesTTL)
+	rk(gofig.Bool, true, "", types.ConfigClientCacheETags)
+	rk(gofig.String, "", clientAdminTokenDesc, types.ConfigClientAdminToken)
+	rk(gofig.Bool, false, forceDetachCleanupDesc,
+		types.ConfigClientForceDetachCleanup)
+	rk(gofig.Int, 3, "", types.ConfigClientRetryMaxAttempts)
+	rk(gofig.String, "100ms", "", types.ConfigClientRetryInitialBackoff)
+	rk(gofig.String, "5s", "", types.ConfigClientRetryMaxBackoff)
+	rk(gofig.Int, 10, "", types.ConfigClientRetryBudget)
 	rk(gofig.String, "30s", "", types.ConfigDeviceAttachTimeout)
 	rk(gofig.Int, 0, "", types.ConfigDeviceScanType)
+	rk(gofig.Bool, true, stableNamesDesc, types.ConfigDeviceStableNames)
+	rk(gofig.String, "", execSearchPathDesc, types.ConfigExecSearchPath)
+	rk(gofig.String, "", execEnvDesc, types.ConfigExecEnv)
+	rk(gofig.String, "", execAllowDesc, types.ConfigExecAllow)
+	rk(gofig.String, "", execChrootDesc, types.ConfigExecChroot)
+	rk(gofig.String, "", execNamespacesDesc, types.ConfigExecNamespaces)
 	rk(gofig.Bool, false, "", types.ConfigEmbedded)
 	rk(gofig.String, "1m", "", types.ConfigServerTasksExeTimeout)
 	rk(gofig.String, "0s", "", types.ConfigServerTasksLogTimeout)
+	rk(gofig.Int, 1, tasksConcurrencyDesc, types.ConfigServerTasksConcurrency)
+	rk(gofig.Int, 1000, tasksQueueSizeDesc, types.ConfigServerTasksQueueSize)
+	rk(gofig.String, types.Lib.Join("interrupted-tasks.json"), "",
+		types.ConfigServerTasksInterruptedFile)
 	rk(gofig.Bool, false, "", types.ConfigServerParseRequestOpts)
+	rk(gofig.String, "0s", "", types.ConfigServerRequestTimeout)
+	rk(gofig.String, "5s", "", types.ConfigServerHealthTimeout)
+	rk(gofig.Int, 5, circuitBreakerThresholdDesc,
+		types.ConfigServerCircuitBreakerThreshold)
+	rk(gofig.String, "30s", "", types.ConfigServerCircuitBreakerCooldown)
+	rk(gofig.String, "0s", "", types.ConfigServerReloadInterval)
+	rk(gofig.String, "30s", shutdownTimeoutDesc,
+		types.ConfigServerShutdownTimeout)
+	rk(gofig.String, "5m", secretsRefreshDesc,
+		types.ConfigSecretsRefreshInterval)
+	rk(gofig.String, "", serverAdminTokenDesc, types.ConfigServerAdminToken)
+	rk(gofig.Bool, false, bindInstanceIDsDesc, types.ConfigServerBindInstanceIDs)
+	rk(gofig.String, types.Lib.Join("instance-id-bindings.json"), "",
+		types.ConfigServerInstanceIDBindingsFile)
+	rk(gofig.String, types.Lib.Join("registered-services.json"),
+		registeredServicesFileDesc, types.ConfigServerRegisteredServicesFile)
+	rk(gofig.Bool, true, topologyValidateDesc,
+		types.ConfigServerTopologyValidate)
+	rk(gofig.Int, 1048576, maxRequestBodySizeDesc,
+		types.ConfigServerMaxRequestBodySize)
+	rk(gofig.Bool, true, "", types.ConfigServerCompressionEnabled)
+	rk(gofig.Int, 1024, compressionMinSizeDesc,
+		types.ConfigServerCompressionMinSize)
+	rk(gofig.Int, 1000, "", types.ConfigServerEventsMax)
+	rk(gofig.Int, 50, volumeHistoryMaxDesc,
+		types.ConfigServerEventsVolumeHistoryMax)
+	rk(gofig.Int, 10000, volumeHistoryVolumesDesc,
+		types.ConfigServerEventsVolumeHistoryVolumes)
+	rk(gofig.String, types.Lib.Join("volume-events.json"), "",
+		types.ConfigServerEventsVolumeHistoryFile)
+	rk(gofig.String, "", policyURLDesc, types.ConfigServerPolicyURL)
+	rk(gofig.String, "5s", "", types.ConfigServerPolicyTimeout)
+	rk(gofig.Bool, false, policyFailOpenDesc, types.ConfigServerPolicyFailOpen)
+	rk(gofig.Bool, false, tenancyEnabledDesc, types.ConfigServerTenancyEnabled)
+	rk(gofig.String, "", tenancyPatternDesc, types.ConfigServerTenancyPattern)
+	rk(gofig.String, "5m", mountTokenTTLDesc, types.ConfigServerMountTokenTTL)
+	rk(gofig.String, types.Lib.Join("mount-tokens.json"), mountTokenFileDesc,
+		types.ConfigServerMountTokenFile)
+	rk(gofig.String, "1m", serverCacheInstanceDesc,
+		types.ConfigServerCacheInstance)
+	rk(gofig.String, "2m", nextDeviceLeaseDesc,
+		types.ConfigServerNextDeviceLease)
+	rk(gofig.String, "0", volumeRecycleRetentionDesc,
+		types.ConfigServerVolumeRecycleRetention)
+	rk(gofig.Int, 0, volumeQuotaDesc, types.ConfigServerVolumeQuota)
+	rk(gofig.Bool, false, volumeManagedOnlyDesc,
+		types.ConfigServerVolumeManagedOnly)
+	rk(gofig.String, "", volumeNamingPatternDesc,
+		types.ConfigServerVolumeNamingPattern)
+	rk(gofig.String, "", volumeNamingTemplateDesc,
+		types.ConfigServerVolumeNamingTemplate)
+	rk(gofig.Bool, false, validateConfigDesc, types.ConfigServerValidateConfig)
 
 	gofigCore.Register(r)
+
+	// the levels may be a map of components to levels
+	schema.Keys[types.ConfigLogLevels] = types.ConfigKeyAny
+
+	// the logging and TLS keys may also be set for the server or client
+	aliases := map[string]types.ConfigKeyType{}
+	for k, t := range schema.Keys {
+		for _, p := range []string{types.ConfigLogging, types.ConfigTLS} {
+			if k != p && !strings.HasPrefix(k, p+".") {
+				continue
+			}
+			sk := strings.TrimPrefix(k, types.ConfigRoot+".")
+			aliases[types.ConfigServer+"."+sk] = t
+			aliases[types.ConfigClient+"."+sk] = t
+		}
+	}
+	for k, t := range aliases {
+		schema.Keys[k] = t
+	}
+
+	registry.RegisterConfigSchema(schema)
 }
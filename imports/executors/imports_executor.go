@@ -10,10 +10,14 @@ import (
 	_ "github.com/codedellemc/libstorage/drivers/storage/efs/executor"
 	_ "github.com/codedellemc/libstorage/drivers/storage/fittedcloud/executor"
 	_ "github.com/codedellemc/libstorage/drivers/storage/gcepd/executor"
+	_ "github.com/codedellemc/libstorage/drivers/storage/hcloud/executor"
+	_ "github.com/codedellemc/libstorage/drivers/storage/iscsi/executor"
 	_ "github.com/codedellemc/libstorage/drivers/storage/isilon/executor"
+	_ "github.com/codedellemc/libstorage/drivers/storage/oci/executor"
 	_ "github.com/codedellemc/libstorage/drivers/storage/rbd/executor"
 	_ "github.com/codedellemc/libstorage/drivers/storage/s3fs/executor"
 	_ "github.com/codedellemc/libstorage/drivers/storage/scaleio/executor"
 	_ "github.com/codedellemc/libstorage/drivers/storage/vbox/executor"
 	_ "github.com/codedellemc/libstorage/drivers/storage/vfs/executor"
+	_ "github.com/codedellemc/libstorage/drivers/storage/vsphere/executor"
 )
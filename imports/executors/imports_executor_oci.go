@@ -0,0 +1,8 @@
+// +build libstorage_storage_executor,libstorage_storage_executor_oci
+
+package executors
+
+import (
+	// load the packages
+	_ "github.com/codedellemc/libstorage/drivers/storage/oci/executor"
+)
@@ -5,4 +5,7 @@ package local
 import (
 	// load the packages
 	_ "github.com/codedellemc/libstorage/drivers/integration/linux"
+
+	// load the secrets providers used to fetch volume encryption keys
+	_ "github.com/codedellemc/libstorage/imports/secrets"
 )
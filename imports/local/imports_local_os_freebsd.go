@@ -0,0 +1,6 @@
+package local
+
+import (
+	// load the packages
+	_ "github.com/codedellemc/libstorage/drivers/os/freebsd"
+)
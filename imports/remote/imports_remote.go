@@ -5,15 +5,21 @@ package remote
 import (
 	// import to load
 	_ "github.com/codedellemc/libstorage/drivers/storage/azureud/storage"
+	_ "github.com/codedellemc/libstorage/drivers/storage/composite/storage"
 	_ "github.com/codedellemc/libstorage/drivers/storage/dobs/storage"
 	_ "github.com/codedellemc/libstorage/drivers/storage/ebs/storage"
 	_ "github.com/codedellemc/libstorage/drivers/storage/efs/storage"
 	_ "github.com/codedellemc/libstorage/drivers/storage/fittedcloud/storage"
 	_ "github.com/codedellemc/libstorage/drivers/storage/gcepd/storage"
+	_ "github.com/codedellemc/libstorage/drivers/storage/hcloud/storage"
+	_ "github.com/codedellemc/libstorage/drivers/storage/iscsi/storage"
 	_ "github.com/codedellemc/libstorage/drivers/storage/isilon/storage"
+	_ "github.com/codedellemc/libstorage/drivers/storage/mirror/storage"
+	_ "github.com/codedellemc/libstorage/drivers/storage/oci/storage"
 	_ "github.com/codedellemc/libstorage/drivers/storage/rbd/storage"
 	_ "github.com/codedellemc/libstorage/drivers/storage/s3fs/storage"
 	_ "github.com/codedellemc/libstorage/drivers/storage/scaleio/storage"
 	_ "github.com/codedellemc/libstorage/drivers/storage/vbox/storage"
 	_ "github.com/codedellemc/libstorage/drivers/storage/vfs/storage"
+	_ "github.com/codedellemc/libstorage/drivers/storage/vsphere/storage"
 )
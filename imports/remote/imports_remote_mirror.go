@@ -0,0 +1,8 @@
+// +build libstorage_storage_driver,libstorage_storage_driver_mirror
+
+package remote
+
+import (
+	// load the packages
+	_ "github.com/codedellemc/libstorage/drivers/storage/mirror/storage"
+)
@@ -0,0 +1,8 @@
+package secrets
+
+import (
+	// import to load
+	_ "github.com/codedellemc/libstorage/drivers/secrets/env"
+	_ "github.com/codedellemc/libstorage/drivers/secrets/file"
+	_ "github.com/codedellemc/libstorage/drivers/secrets/vault"
+)
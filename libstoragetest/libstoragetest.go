@@ -0,0 +1,208 @@
+/*
+Package libstoragetest provides a libStorage server that runs in the test's
+process along with a client connected to it so that projects that embed
+libStorage, such as REX-Ray and its plug-ins, can write integration tests
+without starting external processes or maintaining fixtures.
+
+The server's services use the mock storage driver by default. The mock driver
+is only compiled when the "mock" build tag is present, so tests that use the
+default driver are run with:
+
+	go test -tags mock ./...
+
+A typical test starts a server, uses its client, and closes the server:
+
+	func TestVolumes(t *testing.T) {
+	    s := libstoragetest.New(t, nil)
+	    defer s.Close()
+
+	    vols, err := s.Client.API().Volumes(nil, 0)
+	    ...
+	}
+*/
+package libstoragetest
+
+import (
+	"bytes"
+	"testing"
+
+	gofigCore "github.com/akutz/gofig"
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+	"golang.org/x/net/context"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/codedellemc/libstorage/api/server"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/client"
+)
+
+// DefaultDriver is the storage driver of a server's services when the
+// options do not specify a driver.
+const DefaultDriver = "mock"
+
+// Options are the options with which a server is started.
+type Options struct {
+
+	// Driver is the name of the storage driver of the server's services.
+	// DefaultDriver is used if the driver is empty.
+	Driver string
+
+	// Services are the names of the server's services. A single service
+	// named after the driver is created if no services are specified.
+	Services []string
+
+	// Socket indicates whether the server listens on a temporary UNIX
+	// socket rather than a random TCP port.
+	Socket bool
+
+	// ClientType is the type of the server's client. An integration client
+	// is created if the type is unknown.
+	ClientType types.ClientType
+
+	// Config is YAML that is merged into the configuration of the server
+	// and its client, ex. to inject faults into the mock driver.
+	Config []byte
+}
+
+// Server is a libStorage server running in the test's process.
+type Server struct {
+
+	// Addr is the address on which the server listens, ex.
+	// tcp://127.0.0.1:7981.
+	Addr string
+
+	// Config is the configuration of the server and its client.
+	Config gofig.Config
+
+	// Client is a client connected to the server.
+	Client types.Client
+
+	server types.Server
+	errs   <-chan error
+}
+
+// New starts a server and returns it once its client is connected. The
+// test fails immediately if the server cannot be started. The caller must
+// close the server when the test completes.
+func New(t testing.TB, opts *Options) *Server {
+	s, err := NewServer(nil, opts)
+	if err != nil {
+		t.Fatalf("error starting libStorage test server: %v", err)
+	}
+	return s
+}
+
+// NewServer starts a server and returns it once its client is connected.
+// The caller must close the server when it is no longer needed.
+func NewServer(ctx context.Context, opts *Options) (*Server, error) {
+
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	config, err := newConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	srv, errs, err := server.Serve(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	addrs := srv.Addrs()
+	if len(addrs) == 0 {
+		srv.Close()
+		return nil, goof.New("libStorage test server has no endpoints")
+	}
+	config.Set(types.ConfigHost, addrs[0])
+
+	c, err := client.New(ctx, config)
+	if err != nil {
+		srv.Close()
+		return nil, err
+	}
+
+	return &Server{
+		Addr:   addrs[0],
+		Config: config,
+		Client: c,
+		server: srv,
+		errs:   errs,
+	}, nil
+}
+
+// Errs returns the channel on which the server's errors are received.
+func (s *Server) Errs() <-chan error {
+	return s.errs
+}
+
+// Close stops the server.
+func (s *Server) Close() error {
+	return s.server.Close()
+}
+
+func newConfig(opts *Options) (gofig.Config, error) {
+
+	driver := opts.Driver
+	if driver == "" {
+		driver = DefaultDriver
+	}
+
+	serviceNames := opts.Services
+	if len(serviceNames) == 0 {
+		serviceNames = []string{driver}
+	}
+	services := map[string]interface{}{}
+	for _, name := range serviceNames {
+		services[name] = map[string]interface{}{
+			"libstorage": map[string]interface{}{
+				"storage": map[string]interface{}{
+					"driver": driver,
+				},
+			},
+		}
+	}
+
+	// the server chooses a random port or a temporary socket for an
+	// endpoint whose address is only a protocol
+	address := "tcp"
+	if opts.Socket {
+		address = "unix"
+	}
+
+	clientType := opts.ClientType
+	if clientType == types.UnknownClientType {
+		clientType = types.IntegrationClient
+	}
+
+	buf, err := yaml.Marshal(map[string]interface{}{
+		"libstorage": map[string]interface{}{
+			"client": map[string]interface{}{
+				"type": clientType.String(),
+			},
+			"server": map[string]interface{}{
+				"endpoints": map[string]interface{}{
+					"localhost": map[string]interface{}{
+						"address": address,
+					},
+				},
+				"services": services,
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	config := gofigCore.New()
+	if err := config.ReadConfig(bytes.NewReader(buf)); err != nil {
+		return nil, err
+	}
+	if opts.Config != nil {
+		if err := config.ReadConfig(bytes.NewReader(opts.Config)); err != nil {
+			return nil, err
+		}
+	}
+	return config, nil
+}
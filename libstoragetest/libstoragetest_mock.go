@@ -0,0 +1,8 @@
+// +build mock
+
+package libstoragetest
+
+import (
+	// load the mock storage driver
+	_ "github.com/codedellemc/libstorage/drivers/storage/mock"
+)
@@ -0,0 +1,59 @@
+// +build mock
+
+package libstoragetest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+func TestNew(t *testing.T) {
+	s := New(t, nil)
+	defer s.Close()
+
+	assert.True(t, strings.HasPrefix(s.Addr, "tcp://"))
+
+	vols, err := s.Client.API().Volumes(nil, 0)
+	assert.NoError(t, err)
+	assert.Contains(t, vols, DefaultDriver)
+	assert.Len(t, vols[DefaultDriver], 3)
+}
+
+func TestNewSocket(t *testing.T) {
+	s := New(t, &Options{
+		Services:   []string{"mock1", "mock2"},
+		Socket:     true,
+		ClientType: types.ControllerClient,
+	})
+	defer s.Close()
+
+	assert.True(t, strings.HasPrefix(s.Addr, "unix://"))
+
+	services, err := s.Client.API().Services(nil)
+	assert.NoError(t, err)
+	assert.Len(t, services, 2)
+	assert.Contains(t, services, "mock1")
+	assert.Contains(t, services, "mock2")
+}
+
+func TestNewWithFaults(t *testing.T) {
+	s := New(t, &Options{
+		Config: []byte(`
+mock:
+  faults:
+    errorRate: 1
+    ops: Volumes
+`),
+	})
+	defer s.Close()
+
+	_, err := s.Client.API().Volumes(nil, 0)
+	assert.Error(t, err)
+
+	_, err = s.Client.API().Services(nil)
+	assert.NoError(t, err)
+}